@@ -8,6 +8,7 @@ import (
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/internal/httputil"
 )
 
 func addEndpoint(svc bootstrap.Service) endpoint.Endpoint {
@@ -163,9 +164,12 @@ func listEndpoint(svc bootstrap.Service) endpoint.Endpoint {
 			return nil, err
 		}
 		res := listRes{
-			Total:   page.Total,
-			Offset:  page.Offset,
-			Limit:   page.Limit,
+			PageRes: httputil.PageRes{
+				Total:  page.Total,
+				Offset: page.Offset,
+				Limit:  page.Limit,
+				Links:  httputil.PageLinks(ctx, page.Total, page.Offset, page.Limit),
+			},
 			Configs: []viewRes{},
 		}
 
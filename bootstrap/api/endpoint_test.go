@@ -23,6 +23,7 @@ import (
 	"github.com/mainflux/mainflux/bootstrap"
 	bsapi "github.com/mainflux/mainflux/bootstrap/api"
 	"github.com/mainflux/mainflux/bootstrap/mocks"
+	"github.com/mainflux/mainflux/internal/retry"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/mainflux/mainflux/things"
 	thingsapi "github.com/mainflux/mainflux/things/api/things/http"
@@ -164,7 +165,7 @@ func newService(auth mainflux.AuthServiceClient, url string) bootstrap.Service {
 	}
 
 	sdk := mfsdk.NewSDK(config)
-	return bootstrap.New(auth, things, sdk, encKey)
+	return bootstrap.New(auth, things, sdk, encKey, retry.Config{})
 }
 
 func generateChannels() map[string]things.Channel {
@@ -185,7 +186,7 @@ func newThingsService(auth mainflux.AuthServiceClient) things.Service {
 }
 
 func newThingsServer(svc things.Service) *httptest.Server {
-	mux := thingsapi.MakeHandler(mocktracer.New(), svc)
+	mux := thingsapi.MakeHandler(mocktracer.New(), svc, nil)
 	return httptest.NewServer(mux)
 }
 
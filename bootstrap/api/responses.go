@@ -9,6 +9,7 @@ import (
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/internal/httputil"
 )
 
 var (
@@ -90,9 +91,7 @@ func (res viewRes) Empty() bool {
 }
 
 type listRes struct {
-	Total   uint64    `json:"total"`
-	Offset  uint64    `json:"offset"`
-	Limit   uint64    `json:"limit"`
+	httputil.PageRes
 	Configs []viewRes `json:"configs"`
 }
 
@@ -101,7 +100,7 @@ func (res listRes) Code() int {
 }
 
 func (res listRes) Headers() map[string]string {
-	return map[string]string{}
+	return res.Links.Headers()
 }
 
 func (res listRes) Empty() bool {
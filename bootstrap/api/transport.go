@@ -16,6 +16,7 @@ import (
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/internal/httputil"
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -37,6 +38,7 @@ var (
 func MakeHandler(svc bootstrap.Service, reader bootstrap.ConfigReader) http.Handler {
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerBefore(httputil.WithRequestURL),
 	}
 	r := bone.New()
 
@@ -174,6 +174,10 @@ func (svc *mainfluxThings) ListThings(context.Context, string, things.PageMetada
 	panic("not implemented")
 }
 
+func (svc *mainfluxThings) ListChannelsByMetadataKey(context.Context, string, things.PageMetadata) (things.ChannelsPage, error) {
+	panic("not implemented")
+}
+
 func (svc *mainfluxThings) ListChannelsByThing(context.Context, string, string, things.PageMetadata) (things.ChannelsPage, error) {
 	panic("not implemented")
 }
@@ -212,6 +216,10 @@ func (svc *mainfluxThings) RemoveChannel(context.Context, string, string) error
 	panic("not implemented")
 }
 
+func (svc *mainfluxThings) UpdateChannelSchema(context.Context, string, string, map[string]interface{}) (things.Channel, error) {
+	panic("not implemented")
+}
+
 func (svc *mainfluxThings) CanAccessByKey(context.Context, string, string) (string, error) {
 	panic("not implemented")
 }
@@ -241,3 +249,11 @@ func findIndex(list []string, val string) int {
 func (svc *mainfluxThings) ListMembers(ctx context.Context, token, groupID string, pm things.PageMetadata) (things.Page, error) {
 	panic("not implemented")
 }
+
+func (svc *mainfluxThings) ViewThingStats(context.Context, string, string) (things.Stats, error) {
+	panic("not implemented")
+}
+
+func (svc *mainfluxThings) ViewChannelStats(context.Context, string, string) (things.Stats, error) {
+	panic("not implemented")
+}
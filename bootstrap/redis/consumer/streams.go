@@ -6,10 +6,11 @@ package consumer
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux/bootstrap"
+	"github.com/mainflux/mainflux/internal/clients/events"
+	eventsredis "github.com/mainflux/mainflux/internal/clients/events/redis"
 	"github.com/mainflux/mainflux/logger"
 )
 
@@ -25,74 +26,62 @@ const (
 	channelUpdate = channelPrefix + "update"
 	channelRemove = channelPrefix + "remove"
 
-	exists = "BUSYGROUP Consumer Group name already exists"
+	parking = group + ".parked"
 )
 
 // Subscriber represents event source for things and channels provisioning.
 type Subscriber interface {
 	// Subscribes to given subject and receives events.
 	Subscribe(context.Context, string) error
+	// Replay rewinds the subscription to the event ID, so the events after
+	// it are received again.
+	Replay(context.Context, string) error
 }
 
 type eventStore struct {
 	svc      bootstrap.Service
-	client   *redis.Client
-	consumer string
-	logger   logger.Logger
+	consumer events.Consumer
 }
 
-// NewEventStore returns new event store instance.
+// NewEventStore returns new event store instance. The events failed to be
+// handled are parked after the retries, and the last handled one is
+// checkpointed, so the subscription resumes from it.
 func NewEventStore(svc bootstrap.Service, client *redis.Client, consumer string, log logger.Logger) Subscriber {
+	s := eventsredis.NewStream(client, stream, group, consumer, parking)
+	checkpoints := eventsredis.NewCheckpointRepository(client, stream, group)
 	return eventStore{
 		svc:      svc,
-		client:   client,
-		consumer: consumer,
-		logger:   log,
+		consumer: events.NewConsumer(s, checkpoints, events.Config{}, log),
 	}
 }
 
 func (es eventStore) Subscribe(ctx context.Context, subject string) error {
-	err := es.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
-	if err != nil && err.Error() != exists {
-		return err
-	}
+	return es.consumer.Consume(ctx, es)
+}
+
+func (es eventStore) Replay(ctx context.Context, id string) error {
+	return es.consumer.Replay(ctx, id)
+}
 
-	for {
-		streams, err := es.client.XReadGroup(ctx, &redis.XReadGroupArgs{
-			Group:    group,
-			Consumer: es.consumer,
-			Streams:  []string{stream, ">"},
-			Count:    100,
-		}).Result()
-		if err != nil || len(streams) == 0 {
-			continue
-		}
-
-		for _, msg := range streams[0].Messages {
-			event := msg.Values
-
-			var err error
-			switch event["operation"] {
-			case thingRemove:
-				rte := decodeRemoveThing(event)
-				err = es.svc.RemoveConfigHandler(ctx, rte.id)
-			case thingDisconnect:
-				dte := decodeDisconnectThing(event)
-				err = es.svc.DisconnectThingHandler(ctx, dte.channelID, dte.thingID)
-			case channelUpdate:
-				uce := decodeUpdateChannel(event)
-				err = es.handleUpdateChannel(ctx, uce)
-			case channelRemove:
-				rce := decodeRemoveChannel(event)
-				err = es.svc.RemoveChannelHandler(ctx, rce.id)
-			}
-			if err != nil {
-				es.logger.Warn(fmt.Sprintf("Failed to handle event sourcing: %s", err.Error()))
-				break
-			}
-			es.client.XAck(ctx, stream, group, msg.ID)
-		}
+func (es eventStore) Handle(ctx context.Context, ev events.Event) error {
+	event := ev.Values
+
+	var err error
+	switch event["operation"] {
+	case thingRemove:
+		rte := decodeRemoveThing(event)
+		err = es.svc.RemoveConfigHandler(ctx, rte.id)
+	case thingDisconnect:
+		dte := decodeDisconnectThing(event)
+		err = es.svc.DisconnectThingHandler(ctx, dte.channelID, dte.thingID)
+	case channelUpdate:
+		uce := decodeUpdateChannel(event)
+		err = es.handleUpdateChannel(ctx, uce)
+	case channelRemove:
+		rce := decodeRemoveChannel(event)
+		err = es.svc.RemoveChannelHandler(ctx, rce.id)
 	}
+	return err
 }
 
 func decodeRemoveThing(event map[string]interface{}) removeEvent {
@@ -20,6 +20,7 @@ import (
 	"github.com/mainflux/mainflux/bootstrap"
 	"github.com/mainflux/mainflux/bootstrap/mocks"
 	"github.com/mainflux/mainflux/bootstrap/redis/producer"
+	"github.com/mainflux/mainflux/internal/retry"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/mainflux/mainflux/things"
 	httpapi "github.com/mainflux/mainflux/things/api/things/http"
@@ -69,7 +70,7 @@ func newService(auth mainflux.AuthServiceClient, url string) bootstrap.Service {
 	}
 
 	sdk := mfsdk.NewSDK(config)
-	return bootstrap.New(auth, configs, sdk, encKey)
+	return bootstrap.New(auth, configs, sdk, encKey, retry.Config{})
 }
 
 func newThingsService(auth mainflux.AuthServiceClient) things.Service {
@@ -87,7 +88,7 @@ func newThingsService(auth mainflux.AuthServiceClient) things.Service {
 }
 
 func newThingsServer(svc things.Service) *httptest.Server {
-	mux := httpapi.MakeHandler(mocktracer.New(), svc)
+	mux := httpapi.MakeHandler(mocktracer.New(), svc, nil)
 	return httptest.NewServer(mux)
 }
 func TestAdd(t *testing.T) {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/retry"
 	"github.com/mainflux/mainflux/pkg/errors"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 )
@@ -121,15 +122,18 @@ type bootstrapService struct {
 	sdk     mfsdk.SDK
 	encKey  []byte
 	reader  ConfigReader
+	retry   retry.Config
 }
 
-// New returns new Bootstrap service.
-func New(auth mainflux.AuthServiceClient, configs ConfigRepository, sdk mfsdk.SDK, encKey []byte) Service {
+// New returns new Bootstrap service. The calls to the things service failed
+// because it's unavailable are retried using the retry policy.
+func New(auth mainflux.AuthServiceClient, configs ConfigRepository, sdk mfsdk.SDK, encKey []byte, rc retry.Config) Service {
 	return &bootstrapService{
 		configs: configs,
 		sdk:     sdk,
 		auth:    auth,
 		encKey:  encKey,
+		retry:   rc,
 	}
 }
 
@@ -147,14 +151,14 @@ func (bs bootstrapService) Add(ctx context.Context, token string, cfg Config) (C
 		return Config{}, errors.Wrap(errCheckChannels, err)
 	}
 
-	cfg.MFChannels, err = bs.connectionChannels(toConnect, bs.toIDList(existing), token)
+	cfg.MFChannels, err = bs.connectionChannels(ctx, toConnect, bs.toIDList(existing), token)
 
 	if err != nil {
 		return Config{}, errors.Wrap(errConnectionChannels, err)
 	}
 
 	id := cfg.MFThing
-	mfThing, err := bs.thing(token, id)
+	mfThing, err := bs.thing(ctx, token, id)
 	if err != nil {
 		return Config{}, errors.Wrap(errAddBootstrap, err)
 	}
@@ -167,7 +171,7 @@ func (bs bootstrapService) Add(ctx context.Context, token string, cfg Config) (C
 	saved, err := bs.configs.Save(cfg, toConnect)
 	if err != nil {
 		if id == "" {
-			if errT := bs.sdk.DeleteThing(cfg.MFThing, token); errT != nil {
+			if errT := bs.deleteThing(ctx, cfg.MFThing, token); errT != nil {
 				err = errors.Wrap(err, errT)
 			}
 		}
@@ -230,7 +234,7 @@ func (bs bootstrapService) UpdateConnections(ctx context.Context, token, id stri
 		return errors.Wrap(errUpdateConnections, err)
 	}
 
-	channels, err := bs.connectionChannels(connections, bs.toIDList(existing), token)
+	channels, err := bs.connectionChannels(ctx, connections, bs.toIDList(existing), token)
 	if err != nil {
 		return errors.Wrap(errUpdateConnections, err)
 	}
@@ -244,24 +248,20 @@ func (bs bootstrapService) UpdateConnections(ctx context.Context, token, id stri
 	}
 
 	for _, c := range disconnect {
-		if err := bs.sdk.DisconnectThing(id, c, token); err != nil {
+		if err := bs.disconnect(ctx, id, c, token); err != nil {
 			if errors.Contains(err, mfsdk.ErrFailedDisconnect) {
 				continue
 			}
-			return ErrThings
+			return errors.Wrap(ErrThings, err)
 		}
 	}
 
 	for _, c := range connect {
-		conIDs := mfsdk.ConnectionIDs{
-			ChannelIDs: []string{c},
-			ThingIDs:   []string{id},
-		}
-		if err := bs.sdk.Connect(conIDs, token); err != nil {
+		if err := bs.connect(ctx, id, c, token); err != nil {
 			if errors.Contains(err, mfsdk.ErrFailedConnect) {
 				return ErrMalformedEntity
 			}
-			return ErrThings
+			return errors.Wrap(ErrThings, err)
 		}
 	}
 
@@ -327,21 +327,17 @@ func (bs bootstrapService) ChangeState(ctx context.Context, token, id string, st
 	switch state {
 	case Active:
 		for _, c := range cfg.MFChannels {
-			conIDs := mfsdk.ConnectionIDs{
-				ChannelIDs: []string{c.ID},
-				ThingIDs:   []string{cfg.MFThing},
-			}
-			if err := bs.sdk.Connect(conIDs, token); err != nil {
-				return ErrThings
+			if err := bs.connect(ctx, cfg.MFThing, c.ID, token); err != nil {
+				return errors.Wrap(ErrThings, err)
 			}
 		}
 	case Inactive:
 		for _, c := range cfg.MFChannels {
-			if err := bs.sdk.DisconnectThing(cfg.MFThing, c.ID, token); err != nil {
+			if err := bs.disconnect(ctx, cfg.MFThing, c.ID, token); err != nil {
 				if errors.Contains(err, mfsdk.ErrFailedDisconnect) {
 					continue
 				}
-				return ErrThings
+				return errors.Wrap(ErrThings, err)
 			}
 		}
 	}
@@ -392,10 +388,12 @@ func (bs bootstrapService) identify(token string) (string, error) {
 }
 
 // Method thing retrieves Mainflux Thing creating one if an empty ID is passed.
-func (bs bootstrapService) thing(token, id string) (mfsdk.Thing, error) {
+func (bs bootstrapService) thing(ctx context.Context, token, id string) (mfsdk.Thing, error) {
 	thingID := id
 	var err error
 
+	// Creation isn't retried, since the failed response doesn't mean the
+	// thing wasn't created.
 	if id == "" {
 		thingID, err = bs.sdk.CreateThing(mfsdk.Thing{}, token)
 		if err != nil {
@@ -403,14 +401,18 @@ func (bs bootstrapService) thing(token, id string) (mfsdk.Thing, error) {
 		}
 	}
 
-	thing, err := bs.sdk.Thing(thingID, token)
+	var thing mfsdk.Thing
+	err = bs.call(ctx, func() (err error) {
+		thing, err = bs.sdk.Thing(thingID, token)
+		return err
+	})
 	if err != nil {
 		if errors.Contains(err, mfsdk.ErrFailedFetch) {
 			return mfsdk.Thing{}, errors.Wrap(errThingNotFound, ErrNotFound)
 		}
 
 		if id != "" {
-			if errT := bs.sdk.DeleteThing(thingID, token); errT != nil {
+			if errT := bs.deleteThing(ctx, thingID, token); errT != nil {
 				err = errors.Wrap(err, errT)
 			}
 		}
@@ -421,7 +423,7 @@ func (bs bootstrapService) thing(token, id string) (mfsdk.Thing, error) {
 	return thing, nil
 }
 
-func (bs bootstrapService) connectionChannels(channels, existing []string, token string) ([]Channel, error) {
+func (bs bootstrapService) connectionChannels(ctx context.Context, channels, existing []string, token string) ([]Channel, error) {
 	add := make(map[string]bool, len(channels))
 	for _, ch := range channels {
 		add[ch] = true
@@ -435,7 +437,11 @@ func (bs bootstrapService) connectionChannels(channels, existing []string, token
 
 	var ret []Channel
 	for id := range add {
-		ch, err := bs.sdk.Channel(id, token)
+		var ch mfsdk.Channel
+		err := bs.call(ctx, func() (err error) {
+			ch, err = bs.sdk.Channel(id, token)
+			return err
+		})
 		if err != nil {
 			return nil, errors.Wrap(ErrMalformedEntity, err)
 		}
@@ -450,6 +456,41 @@ func (bs bootstrapService) connectionChannels(channels, existing []string, token
 	return ret, nil
 }
 
+func (bs bootstrapService) connect(ctx context.Context, thingID, chanID, token string) error {
+	conIDs := mfsdk.ConnectionIDs{
+		ChannelIDs: []string{chanID},
+		ThingIDs:   []string{thingID},
+	}
+	return bs.call(ctx, func() error {
+		return bs.sdk.Connect(conIDs, token)
+	})
+}
+
+func (bs bootstrapService) disconnect(ctx context.Context, thingID, chanID, token string) error {
+	return bs.call(ctx, func() error {
+		return bs.sdk.DisconnectThing(thingID, chanID, token)
+	})
+}
+
+func (bs bootstrapService) deleteThing(ctx context.Context, id, token string) error {
+	return bs.call(ctx, func() error {
+		return bs.sdk.DeleteThing(id, token)
+	})
+}
+
+// call calls the things service, retrying the calls that failed because the
+// service is unavailable, rather than the ones it rejected.
+func (bs bootstrapService) call(ctx context.Context, op func() error) error {
+	return retry.Do(ctx, bs.retry, transient, op)
+}
+
+func transient(err error) bool {
+	if code := mfsdk.StatusCode(err); code != 0 {
+		return retry.TransientStatus(code)
+	}
+	return retry.TransientNetwork(err)
+}
+
 // Method updateList accepts config and channel IDs and returns three lists:
 // 1) IDs of Channels to be added
 // 2) IDs of Channels to be removed
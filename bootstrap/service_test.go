@@ -11,9 +11,12 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/opentracing/opentracing-go/mocktracer"
 
@@ -21,6 +24,7 @@ import (
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/bootstrap"
 	"github.com/mainflux/mainflux/bootstrap/mocks"
+	"github.com/mainflux/mainflux/internal/retry"
 	"github.com/mainflux/mainflux/pkg/errors"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/mainflux/mainflux/things"
@@ -55,13 +59,17 @@ var (
 )
 
 func newService(auth mainflux.AuthServiceClient, url string) bootstrap.Service {
+	return newRetryingService(auth, url, retry.Config{})
+}
+
+func newRetryingService(auth mainflux.AuthServiceClient, url string, rc retry.Config) bootstrap.Service {
 	things := mocks.NewConfigsRepository()
 	config := mfsdk.Config{
 		BaseURL: url,
 	}
 
 	sdk := mfsdk.NewSDK(config)
-	return bootstrap.New(auth, things, sdk, encKey)
+	return bootstrap.New(auth, things, sdk, encKey, rc)
 }
 
 func newThingsService(auth mainflux.AuthServiceClient) things.Service {
@@ -79,10 +87,49 @@ func newThingsService(auth mainflux.AuthServiceClient) things.Service {
 }
 
 func newThingsServer(svc things.Service) *httptest.Server {
-	mux := httpapi.MakeHandler(mocktracer.New(), svc)
+	mux := httpapi.MakeHandler(mocktracer.New(), svc, nil)
 	return httptest.NewServer(mux)
 }
 
+// flakyHandler fails the requests to the path with the status code, until
+// the configured number of failures is reached.
+type flakyHandler struct {
+	mu       sync.Mutex
+	handler  http.Handler
+	path     string
+	code     int
+	failures int
+	calls    int
+}
+
+func (h *flakyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == h.path {
+		h.mu.Lock()
+		h.calls++
+		fail := h.calls <= h.failures
+		h.mu.Unlock()
+		if fail {
+			w.WriteHeader(h.code)
+			return
+		}
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
+func (h *flakyHandler) reset(code, failures int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.code = code
+	h.failures = failures
+	h.calls = 0
+}
+
+func (h *flakyHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls
+}
+
 func enc(in []byte) ([]byte, error) {
 	block, err := aes.NewCipher(encKey)
 	if err != nil {
@@ -650,6 +697,77 @@ func TestChangeState(t *testing.T) {
 	}
 }
 
+func TestChangeStateRetry(t *testing.T) {
+	users := mocks.NewUsersService(map[string]string{validToken: email})
+
+	flaky := &flakyHandler{
+		handler: httpapi.MakeHandler(mocktracer.New(), newThingsService(users), nil),
+		path:    "/connect",
+	}
+	server := httptest.NewServer(flaky)
+	defer server.Close()
+
+	var schedule []time.Duration
+	rc := retry.Config{
+		InitialInterval: 10 * time.Millisecond,
+		Multiplier:      2,
+		MaxElapsedTime:  100 * time.Millisecond,
+		Notify: func(err error, attempt int, next time.Duration) {
+			schedule = append(schedule, next)
+		},
+	}
+	svc := newRetryingService(users, server.URL, rc)
+
+	cases := []struct {
+		desc     string
+		code     int
+		failures int
+		err      error
+		calls    int
+		schedule []time.Duration
+	}{
+		{
+			desc:     "change state with things service recovering",
+			code:     http.StatusServiceUnavailable,
+			failures: 2,
+			err:      nil,
+			calls:    3,
+			schedule: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond},
+		},
+		{
+			desc:     "change state with things service unavailable",
+			code:     http.StatusServiceUnavailable,
+			failures: 10,
+			err:      retry.ErrExhausted,
+			calls:    4,
+			schedule: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond},
+		},
+		{
+			desc:     "change state with connection rejected by things service",
+			code:     http.StatusForbidden,
+			failures: 1,
+			err:      bootstrap.ErrThings,
+			calls:    1,
+		},
+	}
+
+	for _, tc := range cases {
+		c := config
+		externalID, err := uuid.NewV4()
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s.\n", tc.desc, err))
+		c.ExternalID = externalID.String()
+		saved, err := svc.Add(context.Background(), validToken, c)
+		require.Nil(t, err, fmt.Sprintf("%s: saving config expected to succeed: %s.\n", tc.desc, err))
+
+		schedule = nil
+		flaky.reset(tc.code, tc.failures)
+		err = svc.ChangeState(context.Background(), validToken, saved.MFThing, bootstrap.Active)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		assert.Equal(t, tc.calls, flaky.count(), fmt.Sprintf("%s: expected %d calls got %d\n", tc.desc, tc.calls, flaky.count()))
+		assert.Equal(t, tc.schedule, schedule, fmt.Sprintf("%s: expected schedule %v got %v\n", tc.desc, tc.schedule, schedule))
+	}
+}
+
 func TestUpdateChannelHandler(t *testing.T) {
 	users := mocks.NewUsersService(map[string]string{validToken: email})
 
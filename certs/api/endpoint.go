@@ -26,6 +26,7 @@ func issueCert(svc certs.Service) endpoint.Endpoint {
 			CertKey:    res.ClientKey,
 			Cert:       res.ClientCert,
 			CACert:     res.IssuingCA,
+			Expire:     res.Expire,
 		}, nil
 	}
 }
@@ -51,14 +52,7 @@ func listCerts(svc certs.Service) endpoint.Endpoint {
 		}
 
 		for _, cert := range page.Certs {
-			view := certsRes{
-				CertSerial: cert.Serial,
-				ThingID:    cert.ThingID,
-				CertKey:    cert.ClientKey,
-				Cert:       cert.ClientCert,
-				CACert:     cert.IssuingCA,
-			}
-			res.Certs = append(res.Certs, view)
+			res.Certs = append(res.Certs, toCertsRes(cert))
 		}
 		return res, nil
 	}
@@ -73,3 +67,41 @@ func revokeCert(svc certs.Service) endpoint.Endpoint {
 		return svc.RevokeCert(ctx, req.token, req.certID)
 	}
 }
+
+func revokeCertBySerial(svc certs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+		return svc.RevokeCertBySerial(ctx, req.token, req.serialID)
+	}
+}
+
+func viewCert(svc certs.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		cert, err := svc.ViewCert(ctx, req.token, req.serialID)
+		if err != nil {
+			return viewCertRes{}, err
+		}
+
+		return viewCertRes{toCertsRes(cert)}, nil
+	}
+}
+
+func toCertsRes(cert certs.Cert) certsRes {
+	return certsRes{
+		CertSerial: cert.Serial,
+		ThingID:    cert.ThingID,
+		CertKey:    cert.ClientKey,
+		Cert:       cert.ClientCert,
+		CACert:     cert.IssuingCA,
+		Expire:     cert.Expire,
+		Revoked:    cert.Revoked,
+	}
+}
@@ -54,3 +54,21 @@ func (ms *metricsMiddleware) RevokeCert(ctx context.Context, token, thingID stri
 
 	return ms.svc.RevokeCert(ctx, token, thingID)
 }
+
+func (ms *metricsMiddleware) RevokeCertBySerial(ctx context.Context, token, serialID string) (certs.Revoke, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "revoke_cert_by_serial").Add(1)
+		ms.latency.With("method", "revoke_cert_by_serial").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.RevokeCertBySerial(ctx, token, serialID)
+}
+
+func (ms *metricsMiddleware) ViewCert(ctx context.Context, token, serialID string) (certs.Cert, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "view_cert").Add(1)
+		ms.latency.With("method", "view_cert").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ViewCert(ctx, token, serialID)
+}
@@ -51,3 +51,19 @@ func (req *revokeReq) validate() error {
 
 	return nil
 }
+
+type viewReq struct {
+	token    string
+	serialID string
+}
+
+func (req *viewReq) validate() error {
+	if req.token == "" {
+		return certs.ErrUnauthorizedAccess
+	}
+	if req.serialID == "" {
+		return certs.ErrMalformedEntity
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ package api
 
 import (
 	"net/http"
+	"time"
 )
 
 type pageRes struct {
@@ -19,11 +20,13 @@ type certsPageRes struct {
 }
 
 type certsRes struct {
-	ThingID    string `json:"thing_id"`
-	Cert       string `json:"cert"`
-	CertKey    string `json:"cert_key"`
-	CertSerial string `json:"cert_serial"`
-	CACert     string `json:"ca_cert"`
+	ThingID    string     `json:"thing_id"`
+	Cert       string     `json:"cert"`
+	CertKey    string     `json:"cert_key"`
+	CertSerial string     `json:"cert_serial"`
+	CACert     string     `json:"ca_cert"`
+	Expire     time.Time  `json:"expire,omitempty"`
+	Revoked    *time.Time `json:"revoked,omitempty"`
 }
 
 func (res certsPageRes) Code() int {
@@ -49,3 +52,15 @@ func (res certsRes) Headers() map[string]string {
 func (res certsRes) Empty() bool {
 	return false
 }
+
+type viewCertRes struct {
+	certsRes
+}
+
+func (res viewCertRes) Code() int {
+	return http.StatusOK
+}
+
+type errorRes struct {
+	Err string `json:"error"`
+}
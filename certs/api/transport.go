@@ -46,6 +46,20 @@ func MakeHandler(svc certs.Service) http.Handler {
 		opts...,
 	))
 
+	r.Get("/certs/serial/:serialId", kithttp.NewServer(
+		viewCert(svc),
+		decodeViewCert,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Delete("/certs/serial/:serialId", kithttp.NewServer(
+		revokeCertBySerial(svc),
+		decodeViewCert,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Get("/certs/:thingId", kithttp.NewServer(
 		listCerts(svc),
 		decodeListCerts,
@@ -102,6 +116,15 @@ func decodeListCerts(_ context.Context, r *http.Request) (interface{}, error) {
 	return req, nil
 }
 
+func decodeViewCert(_ context.Context, r *http.Request) (interface{}, error) {
+	req := viewReq{
+		token:    r.Header.Get("Authorization"),
+		serialID: bone.GetValue(r, "serialId"),
+	}
+
+	return req, nil
+}
+
 func decodeCerts(_ context.Context, r *http.Request) (interface{}, error) {
 	if r.Header.Get("Content-Type") != contentType {
 		return nil, errors.ErrUnsupportedContentType
@@ -127,22 +150,44 @@ func decodeRevokeCerts(_ context.Context, r *http.Request) (interface{}, error)
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", contentType)
 
-	switch err {
-	case errors.ErrUnsupportedContentType:
-		w.WriteHeader(http.StatusUnsupportedMediaType)
-	case io.EOF, errors.ErrMalformedEntity,
-		errors.ErrInvalidQueryParams:
-		w.WriteHeader(http.StatusBadRequest)
-	case errConflict:
-		w.WriteHeader(http.StatusConflict)
-	default:
-		switch err.(type) {
-		case *json.SyntaxError:
-			w.WriteHeader(http.StatusBadRequest)
-		case *json.UnmarshalTypeError:
+	switch errorVal := err.(type) {
+	case errors.Error:
+		switch {
+		case errors.Contains(errorVal, errors.ErrUnsupportedContentType):
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		case errors.Contains(errorVal, errors.ErrMalformedEntity),
+			errors.Contains(errorVal, errors.ErrInvalidQueryParams),
+			errors.Contains(errorVal, certs.ErrMalformedEntity):
 			w.WriteHeader(http.StatusBadRequest)
+		case errors.Contains(errorVal, errUnauthorized),
+			errors.Contains(errorVal, certs.ErrUnauthorizedAccess):
+			w.WriteHeader(http.StatusUnauthorized)
+		case errors.Contains(errorVal, certs.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Contains(errorVal, errConflict),
+			errors.Contains(errorVal, certs.ErrCertRevoked):
+			w.WriteHeader(http.StatusConflict)
 		default:
 			w.WriteHeader(http.StatusInternalServerError)
 		}
+		if errorVal.Msg() != "" {
+			if err := json.NewEncoder(w).Encode(errorRes{Err: errorVal.Msg()}); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+	default:
+		switch err {
+		case io.EOF:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			switch err.(type) {
+			case *json.SyntaxError:
+				w.WriteHeader(http.StatusBadRequest)
+			case *json.UnmarshalTypeError:
+				w.WriteHeader(http.StatusBadRequest)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}
 	}
 }
@@ -3,7 +3,10 @@
 
 package certs
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // ConfigsPage contains page related metadata as well as list
 type Page struct {
@@ -18,12 +21,19 @@ type Repository interface {
 	// Save  saves cert for thing into database
 	Save(ctx context.Context, cert Cert) (string, error)
 
-	// RetrieveAll retrieve all issued certificates for given owner and thing id
+	// RetrieveAll retrieve all issued certificates for given owner and thing id.
+	// Certificates of all owner's things are retrieved if thing id is empty.
 	RetrieveAll(ctx context.Context, ownerID, thingID string, offset, limit uint64) (Page, error)
 
 	// Remove certificate from DB for given thing
 	Remove(ctx context.Context, thingID string) error
 
-	// RetrieveByThing certificate by given thing
+	// Revoke marks certificate with given serial as revoked
+	Revoke(ctx context.Context, serial string, revoked time.Time) error
+
+	// RetrieveByThing certificate by given thing, preferring non-revoked ones
 	RetrieveByThing(ctx context.Context, thingID string) (Cert, error)
+
+	// RetrieveBySerial certificate with given serial belonging to the owner
+	RetrieveBySerial(ctx context.Context, ownerID, serialID string) (Cert, error)
 }
@@ -6,6 +6,7 @@ package mocks
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/mainflux/mainflux/certs"
 )
@@ -49,6 +50,9 @@ func (c *certsRepoMock) RetrieveAll(ctx context.Context, ownerID, thingID string
 	var crts []certs.Cert
 	i := uint64(1)
 	for _, v := range c.certs {
+		if v.OwnerID != ownerID || (thingID != "" && v.ThingID != thingID) {
+			continue
+		}
 		if i >= first && i < last {
 			crts = append(crts, v)
 		}
@@ -57,7 +61,7 @@ func (c *certsRepoMock) RetrieveAll(ctx context.Context, ownerID, thingID string
 
 	page := certs.Page{
 		Certs:  crts,
-		Total:  c.counter,
+		Total:  i - 1,
 		Offset: offset,
 		Limit:  limit,
 	}
@@ -85,3 +89,28 @@ func (c *certsRepoMock) RetrieveByThing(ctx context.Context, thingID string) (ce
 	}
 	return crt, nil
 }
+
+func (c *certsRepoMock) Revoke(ctx context.Context, serial string, revoked time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	crt, ok := c.certs[serial]
+	if !ok {
+		return certs.ErrNotFound
+	}
+	crt.Revoked = &revoked
+	c.certs[serial] = crt
+	if c.certsByThingID[crt.ThingID].Serial == serial {
+		c.certsByThingID[crt.ThingID] = crt
+	}
+	return nil
+}
+
+func (c *certsRepoMock) RetrieveBySerial(ctx context.Context, ownerID, serialID string) (certs.Cert, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	crt, ok := c.certs[serialID]
+	if !ok || crt.OwnerID != ownerID {
+		return certs.Cert{}, certs.ErrNotFound
+	}
+	return crt, nil
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/mainflux/mainflux/certs"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/errors"
-	"github.com/mainflux/mainflux/things"
 )
 
 const duplicateErr = "unique_violation"
@@ -23,6 +22,7 @@ var (
 	errSaveDB     = errors.New("failed to save certificate to database")
 	errRetrieveDB = errors.New("failed to retrieve certificate from db")
 	errRemove     = errors.New("failed to remove certificate from database")
+	errRevoke     = errors.New("failed to revoke certificate in database")
 	errInvalid    = "invalid_text_representation"
 )
 
@@ -46,8 +46,8 @@ func NewRepository(db *sqlx.DB, log logger.Logger) certs.Repository {
 }
 
 func (cr certsRepository) RetrieveAll(ctx context.Context, ownerID, thingID string, offset, limit uint64) (certs.Page, error) {
-	q := `SELECT thing_id, owner_id, serial, expire FROM certs WHERE owner_id = $1 ORDER BY expire LIMIT $2 OFFSET $3;`
-	rows, err := cr.db.Query(q, ownerID, limit, offset)
+	q := `SELECT thing_id, owner_id, serial, expire, revoked FROM certs WHERE owner_id = $1 AND ($2 = '' OR thing_id = $2) ORDER BY expire LIMIT $3 OFFSET $4;`
+	rows, err := cr.db.Query(q, ownerID, thingID, limit, offset)
 	if err != nil {
 		cr.log.Error(fmt.Sprintf("Failed to retrieve configs due to %s", err))
 		return certs.Page{}, err
@@ -57,18 +57,18 @@ func (cr certsRepository) RetrieveAll(ctx context.Context, ownerID, thingID stri
 	certificates := []certs.Cert{}
 
 	for rows.Next() {
-		c := certs.Cert{}
-		if err := rows.Scan(&c.ThingID, &c.OwnerID, &c.Serial, &c.Expire); err != nil {
+		var dbcrt dbCert
+		if err := rows.Scan(&dbcrt.ThingID, &dbcrt.OwnerID, &dbcrt.Serial, &dbcrt.Expire, &dbcrt.Revoked); err != nil {
 			cr.log.Error(fmt.Sprintf("Failed to read retrieved config due to %s", err))
 			return certs.Page{}, err
 
 		}
-		certificates = append(certificates, c)
+		certificates = append(certificates, toCert(dbcrt))
 	}
 
-	q = `SELECT COUNT(*) FROM certs WHERE owner_id = $1`
+	q = `SELECT COUNT(*) FROM certs WHERE owner_id = $1 AND ($2 = '' OR thing_id = $2)`
 	var total uint64
-	if err := cr.db.QueryRow(q, ownerID).Scan(&total); err != nil {
+	if err := cr.db.QueryRow(q, ownerID, thingID).Scan(&total); err != nil {
 		cr.log.Error(fmt.Sprintf("Failed to count certs due to %s", err))
 		return certs.Page{}, err
 	}
@@ -123,35 +123,47 @@ func (cr certsRepository) Remove(ctx context.Context, serial string) error {
 	return nil
 }
 
-func (cr certsRepository) RetrieveByThing(ctx context.Context, thingID string) (certs.Cert, error) {
-	q := `SELECT thing_id, owner_id, serial, expire FROM certs WHERE thing_id = $1`
-	var dbcrt dbCert
-	var c certs.Cert
-
-	if err := cr.db.QueryRowxContext(ctx, q, thingID).StructScan(&dbcrt); err != nil {
-
-		pqErr, ok := err.(*pq.Error)
-		if err == sql.ErrNoRows || ok && errInvalid == pqErr.Code.Name() {
-			return c, errors.Wrap(things.ErrNotFound, err)
-		}
-
-		return c, errors.Wrap(errRetrieveDB, err)
+func (cr certsRepository) Revoke(ctx context.Context, serial string, revoked time.Time) error {
+	q := `UPDATE certs SET revoked = $1 WHERE serial = $2`
+	res, err := cr.db.ExecContext(ctx, q, revoked, serial)
+	if err != nil {
+		return errors.Wrap(errRevoke, err)
 	}
-	c = toCert(dbcrt)
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(errRevoke, err)
+	}
+	if cnt == 0 {
+		return certs.ErrNotFound
+	}
+	return nil
+}
 
-	return c, nil
+func (cr certsRepository) RetrieveByThing(ctx context.Context, thingID string) (certs.Cert, error) {
+	q := `SELECT thing_id, owner_id, serial, expire, revoked FROM certs WHERE thing_id = $1
+		ORDER BY revoked NULLS FIRST, expire DESC LIMIT 1`
+	return cr.retrieve(ctx, q, thingID)
+}
+
+func (cr certsRepository) RetrieveBySerial(ctx context.Context, ownerID, serialID string) (certs.Cert, error) {
+	q := `SELECT thing_id, owner_id, serial, expire, revoked FROM certs WHERE owner_id = $1 AND serial = $2`
+	return cr.retrieve(ctx, q, ownerID, serialID)
 }
 
 func (cr certsRepository) retrieveBySerial(ctx context.Context, serial string) (certs.Cert, error) {
-	q := `SELECT thing_id, owner_id, serial, expire FROM certs WHERE serial = $1`
+	q := `SELECT thing_id, owner_id, serial, expire, revoked FROM certs WHERE serial = $1`
+	return cr.retrieve(ctx, q, serial)
+}
+
+func (cr certsRepository) retrieve(ctx context.Context, q string, args ...interface{}) (certs.Cert, error) {
 	var dbcrt dbCert
 	var c certs.Cert
 
-	if err := cr.db.QueryRowxContext(ctx, q, serial).StructScan(&dbcrt); err != nil {
+	if err := cr.db.QueryRowxContext(ctx, q, args...).StructScan(&dbcrt); err != nil {
 
 		pqErr, ok := err.(*pq.Error)
 		if err == sql.ErrNoRows || ok && errInvalid == pqErr.Code.Name() {
-			return c, errors.Wrap(things.ErrNotFound, err)
+			return c, errors.Wrap(certs.ErrNotFound, err)
 		}
 
 		return c, errors.Wrap(errRetrieveDB, err)
@@ -170,10 +182,11 @@ func (cr certsRepository) rollback(content string, tx *sqlx.Tx, err error) {
 }
 
 type dbCert struct {
-	ThingID string    `db:"thing_id"`
-	Serial  string    `db:"serial"`
-	Expire  time.Time `db:"expire"`
-	OwnerID string    `db:"owner_id"`
+	ThingID string       `db:"thing_id"`
+	Serial  string       `db:"serial"`
+	Expire  time.Time    `db:"expire"`
+	Revoked sql.NullTime `db:"revoked"`
+	OwnerID string       `db:"owner_id"`
 }
 
 func toDBCert(c certs.Cert) dbCert {
@@ -191,5 +204,9 @@ func toCert(cdb dbCert) certs.Cert {
 	c.ThingID = cdb.ThingID
 	c.Serial = cdb.Serial
 	c.Expire = cdb.Expire
+	if cdb.Revoked.Valid {
+		revoked := cdb.Revoked.Time
+		c.Revoked = &revoked
+	}
 	return c
 }
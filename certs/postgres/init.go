@@ -71,6 +71,15 @@ func migrateDB(db *sqlx.DB) error {
 					"DROP TABLE IF EXISTS certs;",
 				},
 			},
+			{
+				Id: "certs_2",
+				Up: []string{
+					`ALTER TABLE IF EXISTS certs ADD COLUMN IF NOT EXISTS revoked TIMESTAMPTZ`,
+				},
+				Down: []string{
+					`ALTER TABLE IF EXISTS certs DROP COLUMN IF EXISTS revoked`,
+				},
+			},
 		},
 	}
 
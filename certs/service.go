@@ -32,7 +32,10 @@ var (
 	// ErrFailedCertRevocation failed to revoke certificate
 	ErrFailedCertRevocation = errors.New("failed to revoke certificate")
 
-	errFailedToRemoveCertFromDB = errors.New("failed to remove cert serial from db")
+	// ErrCertRevoked indicates that the certificate is already revoked.
+	ErrCertRevoked = errors.New("certificate already revoked")
+
+	errFailedToRevokeCertInDB = errors.New("failed to mark cert serial as revoked in db")
 )
 
 var _ Service = (*certsService)(nil)
@@ -46,8 +49,14 @@ type Service interface {
 	// ListCerts lists all certificates issued for given owner
 	ListCerts(ctx context.Context, token, thingID string, offset, limit uint64) (Page, error)
 
+	// ViewCert retrieves the certificate with given serial number
+	ViewCert(ctx context.Context, token, serialID string) (Cert, error)
+
 	// RevokeCert revokes certificate for given thing
 	RevokeCert(ctx context.Context, token, thingID string) (Revoke, error)
+
+	// RevokeCertBySerial revokes the certificate with given serial number
+	RevokeCertBySerial(ctx context.Context, token, serialID string) (Revoke, error)
 }
 
 // Config defines the service parameters
@@ -99,15 +108,16 @@ type Revoke struct {
 
 // Cert defines the certificate paremeters
 type Cert struct {
-	OwnerID        string    `json:"owner_id" mapstructure:"owner_id"`
-	ThingID        string    `json:"thing_id" mapstructure:"thing_id"`
-	ClientCert     string    `json:"client_cert" mapstructure:"certificate"`
-	IssuingCA      string    `json:"issuing_ca" mapstructure:"issuing_ca"`
-	CAChain        []string  `json:"ca_chain" mapstructure:"ca_chain"`
-	ClientKey      string    `json:"client_key" mapstructure:"private_key"`
-	PrivateKeyType string    `json:"private_key_type" mapstructure:"private_key_type"`
-	Serial         string    `json:"serial" mapstructure:"serial_number"`
-	Expire         time.Time `json:"expire" mapstructure:"-"`
+	OwnerID        string     `json:"owner_id" mapstructure:"owner_id"`
+	ThingID        string     `json:"thing_id" mapstructure:"thing_id"`
+	ClientCert     string     `json:"client_cert" mapstructure:"certificate"`
+	IssuingCA      string     `json:"issuing_ca" mapstructure:"issuing_ca"`
+	CAChain        []string   `json:"ca_chain" mapstructure:"ca_chain"`
+	ClientKey      string     `json:"client_key" mapstructure:"private_key"`
+	PrivateKeyType string     `json:"private_key_type" mapstructure:"private_key_type"`
+	Serial         string     `json:"serial" mapstructure:"serial_number"`
+	Expire         time.Time  `json:"expire" mapstructure:"-"`
+	Revoked        *time.Time `json:"revoked,omitempty" mapstructure:"-"`
 }
 
 func (cs *certsService) IssueCert(ctx context.Context, token, thingID string, daysValid string, keyBits int, keyType string) (Cert, error) {
@@ -150,7 +160,7 @@ func (cs *certsService) RevokeCert(ctx context.Context, token, thingID string) (
 	}
 	thing, err := cs.sdk.Thing(thingID, token)
 	if err != nil {
-		return revoke, errors.Wrap(ErrFailedCertRevocation, err)
+		return revoke, errors.Wrap(ErrFailedCertRevocation, errors.Wrap(ErrNotFound, err))
 	}
 
 	cert, err := cs.certsRepo.RetrieveByThing(ctx, thing.ID)
@@ -158,13 +168,36 @@ func (cs *certsService) RevokeCert(ctx context.Context, token, thingID string) (
 		return revoke, errors.Wrap(ErrFailedCertRevocation, err)
 	}
 
+	return cs.revoke(cert)
+}
+
+func (cs *certsService) RevokeCertBySerial(ctx context.Context, token, serialID string) (Revoke, error) {
+	u, err := cs.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return Revoke{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+
+	cert, err := cs.certsRepo.RetrieveBySerial(ctx, u.GetEmail(), serialID)
+	if err != nil {
+		return Revoke{}, errors.Wrap(ErrFailedCertRevocation, err)
+	}
+
+	return cs.revoke(cert)
+}
+
+func (cs *certsService) revoke(cert Cert) (Revoke, error) {
+	var revoke Revoke
+	if cert.Revoked != nil {
+		return revoke, ErrCertRevoked
+	}
+
 	revTime, err := cs.pki.Revoke(cert.Serial)
 	if err != nil {
 		return revoke, errors.Wrap(ErrFailedCertRevocation, err)
 	}
 	revoke.RevocationTime = revTime
-	if err = cs.certsRepo.Remove(context.Background(), cert.Serial); err != nil {
-		return revoke, errors.Wrap(errFailedToRemoveCertFromDB, err)
+	if err = cs.certsRepo.Revoke(context.Background(), cert.Serial, revTime); err != nil {
+		return revoke, errors.Wrap(errFailedToRevokeCertInDB, err)
 	}
 	return revoke, nil
 }
@@ -177,3 +210,12 @@ func (cs *certsService) ListCerts(ctx context.Context, token, thingID string, of
 
 	return cs.certsRepo.RetrieveAll(ctx, u.GetEmail(), thingID, offset, limit)
 }
+
+func (cs *certsService) ViewCert(ctx context.Context, token, serialID string) (Cert, error) {
+	u, err := cs.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return Cert{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+
+	return cs.certsRepo.RetrieveBySerial(ctx, u.GetEmail(), serialID)
+}
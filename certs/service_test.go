@@ -217,6 +217,12 @@ func TestRevokeCert(t *testing.T) {
 			thingID: "2",
 			err:     certs.ErrFailedCertRevocation,
 		},
+		{
+			desc:    "revoke already revoked cert",
+			token:   token,
+			thingID: thingID,
+			err:     certs.ErrCertRevoked,
+		},
 	}
 
 	for _, tc := range cases {
@@ -226,6 +232,63 @@ func TestRevokeCert(t *testing.T) {
 
 }
 
+func TestRevokeCertBySerial(t *testing.T) {
+	svc, err := newService(map[string]string{token: email, "other-token": "other@example.com"})
+	require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
+
+	c1, err := svc.IssueCert(context.Background(), token, thingID, daysValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+	c2, err := svc.IssueCert(context.Background(), token, thingID, daysValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+
+	cases := []struct {
+		desc     string
+		token    string
+		serialID string
+		err      error
+	}{
+		{
+			desc:     "revoke cert by serial for invalid token",
+			token:    wrongValue,
+			serialID: c1.Serial,
+			err:      certs.ErrUnauthorizedAccess,
+		},
+		{
+			desc:     "revoke cert by serial of other user",
+			token:    "other-token",
+			serialID: c1.Serial,
+			err:      certs.ErrNotFound,
+		},
+		{
+			desc:     "revoke cert by non-existing serial",
+			token:    token,
+			serialID: wrongValue,
+			err:      certs.ErrNotFound,
+		},
+		{
+			desc:     "revoke cert by serial",
+			token:    token,
+			serialID: c1.Serial,
+			err:      nil,
+		},
+		{
+			desc:     "revoke already revoked cert by serial",
+			token:    token,
+			serialID: c1.Serial,
+			err:      certs.ErrCertRevoked,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := svc.RevokeCertBySerial(context.Background(), tc.token, tc.serialID)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+
+	cert, err := svc.ViewCert(context.Background(), token, c2.Serial)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error viewing cert: %s\n", err))
+	assert.Nil(t, cert.Revoked, "expected other cert of the thing to not be revoked")
+}
+
 func TestListCerts(t *testing.T) {
 	svc, err := newService(map[string]string{token: email})
 	require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
@@ -291,8 +354,57 @@ func TestListCerts(t *testing.T) {
 
 }
 
+func TestViewCert(t *testing.T) {
+	svc, err := newService(map[string]string{token: email})
+	require.Nil(t, err, fmt.Sprintf("unexpected service creation error: %s\n", err))
+
+	c, err := svc.IssueCert(context.Background(), token, thingID, daysValid, keyBits, key)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert creation error: %s\n", err))
+
+	cases := []struct {
+		token    string
+		desc     string
+		serialID string
+		revoked  bool
+		err      error
+	}{
+		{
+			desc:     "view cert",
+			token:    token,
+			serialID: c.Serial,
+			err:      nil,
+		},
+		{
+			desc:     "view cert with invalid token",
+			token:    wrongValue,
+			serialID: c.Serial,
+			err:      certs.ErrUnauthorizedAccess,
+		},
+		{
+			desc:     "view non-existing cert",
+			token:    token,
+			serialID: "invalid",
+			err:      certs.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		cert, err := svc.ViewCert(context.Background(), tc.token, tc.serialID)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, thingID, cert.ThingID, fmt.Sprintf("%s: expected thing %s got %s\n", tc.desc, thingID, cert.ThingID))
+		}
+	}
+
+	_, err = svc.RevokeCert(context.Background(), token, thingID)
+	require.Nil(t, err, fmt.Sprintf("unexpected cert revocation error: %s\n", err))
+	cert, err := svc.ViewCert(context.Background(), token, c.Serial)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error viewing revoked cert: %s\n", err))
+	assert.NotNil(t, cert.Revoked, "expected revoked cert to have revocation time")
+}
+
 func newThingsServer(svc things.Service) *httptest.Server {
-	mux := httpapi.MakeHandler(mocktracer.New(), svc)
+	mux := httpapi.MakeHandler(mocktracer.New(), svc, nil)
 	return httptest.NewServer(mux)
 }
 
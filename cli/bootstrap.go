@@ -5,29 +5,63 @@ package cli
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"strings"
 
+	"github.com/mainflux/mainflux/pkg/errors"
 	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/spf13/cobra"
 )
 
-var cmdBootstrap = []cobra.Command{
-	cobra.Command{
-		Use:   "add",
-		Short: "add <JSON_config> <user_auth_token>",
-		Long:  `Adds new Thing Bootstrap Config to the user identified by the provided key`,
+const (
+	stateInactive = "inactive"
+	stateActive   = "active"
+)
+
+var errInvalidState = errors.New("invalid state, expected active or inactive")
+
+var configColumns = []string{"mainflux_id", "external_id", "name", "state", "channels", "content"}
+
+// NewBootstrapCmd returns bootstrap command.
+func NewBootstrapCmd() *cobra.Command {
+	var createFile string
+	var create mfxsdk.BootstrapConfig
+
+	createCmd := cobra.Command{
+		Use:     "create",
+		Aliases: []string{"add"},
+		Short:   "create [<JSON_config>] <user_auth_token> [--file=<config_file>] [--external-id=<id>] [--external-key=<key>] [--thing=<thing_id>] [--name=<name>] [--content=<content>] [--channels=<id>,...]",
+		Long: `Adds new Thing Bootstrap Config to the user identified by the provided key.
+Config is read from the JSON argument, the JSON file or built from flags`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			fromFlags := create.ExternalID != "" || create.ExternalKey != ""
+			n := 2
+			if createFile != "" || fromFlags {
+				n = 1
+			}
+			if args = withToken(args, n); len(args) != n {
 				logUsage(cmd.Short)
 				return
 			}
 
-			var cfg mfxsdk.BootstrapConfig
-			if err := json.Unmarshal([]byte(args[0]), &cfg); err != nil {
-				logError(err)
-				return
+			cfg := create
+			if !fromFlags {
+				data := []byte(args[0])
+				if createFile != "" {
+					var err error
+					if data, err = ioutil.ReadFile(createFile); err != nil {
+						logError(err)
+						return
+					}
+				}
+				if err := json.Unmarshal(data, &cfg); err != nil {
+					logError(errors.Wrap(errInvalidArgs, err))
+					return
+				}
 			}
 
-			id, err := sdk.AddBootstrap(args[1], cfg)
+			id, err := sdk.AddBootstrap(args[n-1], cfg)
 			if err != nil {
 				logError(err)
 				return
@@ -35,13 +69,22 @@ var cmdBootstrap = []cobra.Command{
 
 			logCreated(id)
 		},
-	},
-	cobra.Command{
+	}
+
+	createCmd.Flags().StringVar(&createFile, "file", "", "JSON file containing the config")
+	createCmd.Flags().StringVar(&create.ExternalID, "external-id", "", "external ID of the device")
+	createCmd.Flags().StringVar(&create.ExternalKey, "external-key", "", "external key of the device")
+	createCmd.Flags().StringVar(&create.ThingID, "thing", "", "ID of an existing thing, a new one is created if omitted")
+	createCmd.Flags().StringVar(&create.Name, "name", "", "config name")
+	createCmd.Flags().StringVar(&create.Content, "content", "", "config content")
+	createCmd.Flags().StringSliceVar(&create.Channels, "channels", nil, "IDs of channels the thing is connected to")
+
+	viewCmd := cobra.Command{
 		Use:   "view",
 		Short: "view <thing_id> <user_auth_token>",
 		Long:  `Returns Thing Config with given ID belonging to the user identified by the given key`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -52,39 +95,142 @@ var cmdBootstrap = []cobra.Command{
 				return
 			}
 
-			logJSON(c)
+			logRecords(c, configColumns, [][]string{configRow(c)})
 		},
-	},
-	cobra.Command{
+	}
+
+	var listState, listChannel string
+
+	listCmd := cobra.Command{
+		Use:   "list",
+		Short: "list <user_auth_token> [--state=active|inactive] [--channel=<channel_id>]",
+		Long: `Lists Configs belonging to the user identified by the given key. Channel filter
+is applied to the retrieved page`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 1); len(args) != 1 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			filter := map[string]string{}
+			if Name != "" {
+				filter["name"] = Name
+			}
+			if listState != "" {
+				s, err := parseState(listState)
+				if err != nil {
+					logError(err)
+					return
+				}
+				filter["state"] = strconv.Itoa(s)
+			}
+
+			page, err := sdk.Bootstraps(args[0], uint64(Offset), uint64(Limit), filter)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			page.Configs = filterByChannel(page.Configs, listChannel)
+			var rows [][]string
+			for _, c := range page.Configs {
+				rows = append(rows, configRow(c))
+			}
+
+			logRecords(page, configColumns, rows)
+		},
+	}
+
+	listCmd.Flags().StringVar(&listState, "state", "", "state filter: active or inactive")
+	listCmd.Flags().StringVar(&listChannel, "channel", "", "channel ID filter")
+
+	var updateName, updateContent, updateContentFile string
+
+	updateCmd := cobra.Command{
 		Use:   "update",
-		Short: "update <JSON_config> <user_auth_token>",
-		Long:  `Updates editable fields of the provided Config`,
+		Short: "update <JSON_config> <user_auth_token> | update <thing_id> <user_auth_token> [--name=<name>] [--content=<content>] [--content-file=<file>]",
+		Long: `Updates editable fields of the provided Config. If name or content flags are
+used, the first argument is the thing ID and other fields are kept unchanged`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
+			fromFlags := updateName != "" || updateContent != "" || updateContentFile != ""
 			var cfg mfxsdk.BootstrapConfig
-			if err := json.Unmarshal([]byte(args[0]), &cfg); err != nil {
+			if !fromFlags {
+				if err := json.Unmarshal([]byte(args[0]), &cfg); err != nil {
+					logError(errors.Wrap(errInvalidArgs, err))
+					return
+				}
+			} else {
+				c, err := sdk.ViewBootstrap(args[1], args[0])
+				if err != nil {
+					logError(err)
+					return
+				}
+				cfg = mfxsdk.BootstrapConfig{MFThing: args[0], Name: c.Name, Content: c.Content}
+				if updateName != "" {
+					cfg.Name = updateName
+				}
+				if updateContent != "" {
+					cfg.Content = updateContent
+				}
+				if updateContentFile != "" {
+					data, err := ioutil.ReadFile(updateContentFile)
+					if err != nil {
+						logError(err)
+						return
+					}
+					cfg.Content = string(data)
+				}
+			}
+
+			if err := sdk.UpdateBootstrap(args[1], cfg); err != nil {
 				logError(err)
 				return
 			}
 
-			if err := sdk.UpdateBootstrap(args[1], cfg); err != nil {
+			logOK()
+		},
+	}
+
+	updateCmd.Flags().StringVar(&updateName, "name", "", "new config name")
+	updateCmd.Flags().StringVar(&updateContent, "content", "", "new config content")
+	updateCmd.Flags().StringVar(&updateContentFile, "content-file", "", "file containing new config content")
+
+	whitelistCmd := cobra.Command{
+		Use:   "whitelist",
+		Short: "whitelist <thing_id> <active|inactive> <user_auth_token>",
+		Long:  `Changes state of the Config, allowing or disabling the thing to exchange messages`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 3); len(args) != 3 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			s, err := parseState(args[1])
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			if err := sdk.Whitelist(args[2], mfxsdk.BootstrapConfig{MFThing: args[0], State: s}); err != nil {
 				logError(err)
 				return
 			}
 
 			logOK()
 		},
-	},
-	cobra.Command{
+	}
+
+	removeCmd := cobra.Command{
 		Use:   "remove",
 		Short: "remove <thing_id> <user_auth_token>",
 		Long:  `Removes Config with specified key that belongs to the user identified by the given key`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -96,42 +242,115 @@ var cmdBootstrap = []cobra.Command{
 
 			logOK()
 		},
-	},
-	cobra.Command{
-		Use:   "bootstrap",
-		Short: "bootstrap <external_id> <external_key>",
-		Long:  `Returns Config to the Thing with provided external ID using external key`,
+	}
+
+	var encKey string
+
+	fetchCmd := cobra.Command{
+		Use:     "fetch",
+		Aliases: []string{"bootstrap"},
+		Short:   "fetch <external_id> <external_key> [--encryption-key=<key>]",
+		Long: `Returns Config to the Thing with provided external ID using external key, the same
+way a device does. If the encryption key is provided, the secure endpoint is used`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
-			c, err := sdk.Bootstrap(args[1], args[0])
+			var c mfxsdk.BootstrapConfig
+			var err error
+			if encKey != "" {
+				c, err = sdk.BootstrapSecure(args[1], args[0], encKey)
+			} else {
+				c, err = sdk.Bootstrap(args[1], args[0])
+			}
 			if err != nil {
 				logError(err)
 				return
 			}
 
-			logJSON(c)
+			logRecords(c, configColumns, [][]string{configRow(c)})
 		},
-	},
-}
+	}
+
+	fetchCmd.Flags().StringVar(&encKey, "encryption-key", "", "bootstrap service encryption key")
 
-// NewBootstrapCmd returns bootstrap command.
-func NewBootstrapCmd() *cobra.Command {
 	cmd := cobra.Command{
 		Use:   "bootstrap",
 		Short: "Bootstrap management",
-		Long:  `Bootstrap management: create, get, update or delete Bootstrap config`,
+		Long:  `Bootstrap management: create, get, update, whitelist or delete Bootstrap config`,
 		Run: func(cmd *cobra.Command, args []string) {
-			logUsage("bootstrap [add | view | update | remove | bootstrap]")
+			logUsage("bootstrap [create | view | list | update | whitelist | remove | fetch]")
 		},
 	}
 
+	cmdBootstrap := []cobra.Command{
+		createCmd,
+		viewCmd,
+		listCmd,
+		updateCmd,
+		whitelistCmd,
+		removeCmd,
+		fetchCmd,
+	}
+
 	for i := range cmdBootstrap {
 		cmd.AddCommand(&cmdBootstrap[i])
 	}
 
 	return &cmd
 }
+
+func parseState(s string) (int, error) {
+	switch strings.ToLower(s) {
+	case stateInactive, "0":
+		return 0, nil
+	case stateActive, "1":
+		return 1, nil
+	default:
+		return 0, errors.Wrap(errInvalidState, errors.New(s))
+	}
+}
+
+func configChannels(c mfxsdk.BootstrapConfig) []string {
+	if len(c.MFChannels) == 0 {
+		return c.Channels
+	}
+	var ids []string
+	for _, ch := range c.MFChannels {
+		ids = append(ids, ch.ID)
+	}
+	return ids
+}
+
+func filterByChannel(cfgs []mfxsdk.BootstrapConfig, chanID string) []mfxsdk.BootstrapConfig {
+	if chanID == "" {
+		return cfgs
+	}
+	ret := []mfxsdk.BootstrapConfig{}
+	for _, c := range cfgs {
+		for _, id := range configChannels(c) {
+			if id == chanID {
+				ret = append(ret, c)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+func configRow(c mfxsdk.BootstrapConfig) []string {
+	state := stateInactive
+	if c.State == 1 {
+		state = stateActive
+	}
+	return []string{
+		c.MFThing,
+		c.ExternalID,
+		c.Name,
+		state,
+		strings.Join(configChannels(c), ","),
+		c.Content,
+	}
+}
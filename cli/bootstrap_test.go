@@ -0,0 +1,247 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureStdout returns everything written to the standard output while
+// running the function.
+func captureStdout(t *testing.T, fn func()) string {
+	r, w, err := os.Pipe()
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating pipe: %s", err))
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	out, err := ioutil.ReadAll(r)
+	require.Nil(t, err, fmt.Sprintf("unexpected error reading output: %s", err))
+	return string(out)
+}
+
+func runBootstrapCmd(t *testing.T, args ...string) string {
+	out, _ := runCmd(t, NewBootstrapCmd(), nil, args...)
+	return out
+}
+
+func newBootstrapMock() *sdkMock {
+	mock := newSDKMock()
+	mock.configs = []mfxsdk.BootstrapConfig{
+		{MFThing: "1", ExternalID: "ext-1", ExternalKey: "key-1", Name: "sensor", Content: "config-1", State: 1, Channels: []string{"ch-1"}},
+		{MFThing: "2", ExternalID: "ext-2", ExternalKey: "key-2", Name: "gateway", Content: "config-2", MFChannels: []mfxsdk.Channel{{ID: "ch-1"}, {ID: "ch-2"}}},
+		{MFThing: "3", ExternalID: "ext-3", ExternalKey: "key-3", Name: "relay", Content: "config-3", Channels: []string{"ch-3"}},
+	}
+	mock.counter = 3
+	return mock
+}
+
+func TestBootstrapCreateCmd(t *testing.T) {
+	file := filepath.Join(tempDir(t), "config.json")
+	err := ioutil.WriteFile(file, []byte(`{"external_id":"ext-file","external_key":"key","name":"from file"}`), 0600)
+	require.Nil(t, err, fmt.Sprintf("unexpected error writing config: %s", err))
+
+	cases := []struct {
+		desc     string
+		args     []string
+		expected mfxsdk.BootstrapConfig
+	}{
+		{
+			desc:     "create config from JSON argument",
+			args:     []string{"create", `{"external_id":"ext-json","external_key":"key","name":"from json"}`, userToken},
+			expected: mfxsdk.BootstrapConfig{MFThing: "004", ExternalID: "ext-json", ExternalKey: "key", Name: "from json"},
+		},
+		{
+			desc:     "create config from file",
+			args:     []string{"create", userToken, "--file", file},
+			expected: mfxsdk.BootstrapConfig{MFThing: "004", ExternalID: "ext-file", ExternalKey: "key", Name: "from file"},
+		},
+		{
+			desc:     "create config from flags",
+			args:     []string{"add", userToken, "--external-id=ext-flags", "--external-key=key", "--thing=th", "--content=c", "--channels=ch-1,ch-2"},
+			expected: mfxsdk.BootstrapConfig{MFThing: "th", ThingID: "th", ExternalID: "ext-flags", ExternalKey: "key", Content: "c", Channels: []string{"ch-1", "ch-2"}},
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newBootstrapMock()
+		SetSDK(mock)
+		out := runBootstrapCmd(t, tc.args...)
+		assert.Contains(t, out, fmt.Sprintf("created: %s", tc.expected.MFThing), fmt.Sprintf("%s: unexpected output %s", tc.desc, out))
+		require.Len(t, mock.configs, 4, fmt.Sprintf("%s: expected config to be created", tc.desc))
+		assert.Equal(t, tc.expected, mock.configs[3], fmt.Sprintf("%s: expected config %v got %v", tc.desc, tc.expected, mock.configs[3]))
+	}
+}
+
+func TestBootstrapViewCmd(t *testing.T) {
+	SetSDK(newBootstrapMock())
+	defer func() { Format = formatJSON }()
+
+	Format = formatJSON
+	out := runBootstrapCmd(t, "view", "1", userToken)
+	assert.Contains(t, out, `"external_id": "ext-1"`, fmt.Sprintf("view json: unexpected output %s", out))
+
+	Format = formatCSV
+	out = runBootstrapCmd(t, "view", "2", userToken)
+	expected := "mainflux_id,external_id,name,state,channels,content\n" +
+		"2,ext-2,gateway,inactive,\"ch-1,ch-2\",config-2\n"
+	assert.Equal(t, expected, out, "view csv: unexpected output")
+}
+
+func TestBootstrapListCmd(t *testing.T) {
+	Format = formatTable
+	defer func() { Format = formatJSON }()
+
+	cases := []struct {
+		desc     string
+		args     []string
+		filter   map[string]string
+		expected string
+	}{
+		{
+			desc:   "list all configs",
+			args:   []string{"list", userToken},
+			filter: map[string]string{},
+			expected: "MAINFLUX_ID  EXTERNAL_ID  NAME     STATE     CHANNELS   CONTENT\n" +
+				"1            ext-1        sensor   active    ch-1       config-1\n" +
+				"2            ext-2        gateway  inactive  ch-1,ch-2  config-2\n" +
+				"3            ext-3        relay    inactive  ch-3       config-3\n",
+		},
+		{
+			desc:   "list configs by state",
+			args:   []string{"list", userToken, "--state=active"},
+			filter: map[string]string{"state": "1"},
+			expected: "MAINFLUX_ID  EXTERNAL_ID  NAME    STATE   CHANNELS  CONTENT\n" +
+				"1            ext-1        sensor  active  ch-1      config-1\n",
+		},
+		{
+			desc:   "list configs by channel",
+			args:   []string{"list", userToken, "--channel=ch-1", "--state=inactive"},
+			filter: map[string]string{"state": "0"},
+			expected: "MAINFLUX_ID  EXTERNAL_ID  NAME     STATE     CHANNELS   CONTENT\n" +
+				"2            ext-2        gateway  inactive  ch-1,ch-2  config-2\n",
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newBootstrapMock()
+		SetSDK(mock)
+		out := runBootstrapCmd(t, tc.args...)
+		assert.Equal(t, tc.expected, out, fmt.Sprintf("%s: unexpected output", tc.desc))
+		require.Len(t, mock.filters, 1, fmt.Sprintf("%s: expected one list request", tc.desc))
+		assert.Equal(t, tc.filter, mock.filters[0], fmt.Sprintf("%s: expected filter %v got %v", tc.desc, tc.filter, mock.filters[0]))
+	}
+}
+
+func TestBootstrapUpdateCmd(t *testing.T) {
+	file := filepath.Join(tempDir(t), "content")
+	err := ioutil.WriteFile(file, []byte("file content"), 0600)
+	require.Nil(t, err, fmt.Sprintf("unexpected error writing content: %s", err))
+
+	cases := []struct {
+		desc    string
+		args    []string
+		name    string
+		content string
+	}{
+		{
+			desc:    "update config from JSON argument",
+			args:    []string{"update", `{"mainflux_id":"1","name":"new","content":"new content"}`, userToken},
+			name:    "new",
+			content: "new content",
+		},
+		{
+			desc:    "update config content",
+			args:    []string{"update", "1", userToken, "--content=new content"},
+			name:    "sensor",
+			content: "new content",
+		},
+		{
+			desc:    "update config content from file",
+			args:    []string{"update", "1", userToken, "--content-file", file},
+			name:    "sensor",
+			content: "file content",
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newBootstrapMock()
+		SetSDK(mock)
+		out := runBootstrapCmd(t, tc.args...)
+		assert.Contains(t, out, "ok", fmt.Sprintf("%s: unexpected output %s", tc.desc, out))
+		assert.Equal(t, tc.name, mock.configs[0].Name, fmt.Sprintf("%s: expected name %s got %s", tc.desc, tc.name, mock.configs[0].Name))
+		assert.Equal(t, tc.content, mock.configs[0].Content, fmt.Sprintf("%s: expected content %s got %s", tc.desc, tc.content, mock.configs[0].Content))
+	}
+}
+
+func TestBootstrapWhitelistCmd(t *testing.T) {
+	mock := newBootstrapMock()
+	SetSDK(mock)
+
+	runBootstrapCmd(t, "whitelist", "2", "active", userToken)
+	assert.Equal(t, 1, mock.configs[1].State, "expected config to be active")
+
+	runBootstrapCmd(t, "whitelist", "1", "inactive", userToken)
+	assert.Equal(t, 0, mock.configs[0].State, "expected config to be inactive")
+
+	out := runBootstrapCmd(t, "whitelist", "1", "enabled", userToken)
+	assert.Contains(t, out, errInvalidState.Error(), fmt.Sprintf("unexpected output %s", out))
+}
+
+func TestBootstrapRemoveCmd(t *testing.T) {
+	mock := newBootstrapMock()
+	SetSDK(mock)
+
+	runBootstrapCmd(t, "remove", "2", userToken)
+	require.Len(t, mock.configs, 2, "expected config to be removed")
+	assert.Equal(t, "3", mock.configs[1].MFThing, "expected other configs to be kept")
+}
+
+func TestBootstrapFetchCmd(t *testing.T) {
+	mock := newBootstrapMock()
+	mock.encKey = "v7aT0HGxJxt2gULzr3RHwf4WIf6DusPp"
+	SetSDK(mock)
+
+	cases := []struct {
+		desc     string
+		args     []string
+		expected string
+	}{
+		{
+			desc:     "fetch config",
+			args:     []string{"fetch", "ext-1", "key-1"},
+			expected: `"content": "config-1"`,
+		},
+		{
+			desc:     "fetch encrypted config",
+			args:     []string{"fetch", "ext-2", "key-2", "--encryption-key", mock.encKey},
+			expected: `"content": "config-2"`,
+		},
+		{
+			desc:     "fetch encrypted config with wrong key",
+			args:     []string{"bootstrap", "ext-2", "key-2", "--encryption-key", "wrong"},
+			expected: mfxsdk.ErrFailedDecrypt.Error(),
+		},
+		{
+			desc:     "fetch config with wrong external key",
+			args:     []string{"fetch", "ext-1", "key-2"},
+			expected: mfxsdk.ErrFailedFetch.Error(),
+		},
+	}
+
+	for _, tc := range cases {
+		out := runBootstrapCmd(t, tc.args...)
+		assert.Contains(t, out, tc.expected, fmt.Sprintf("%s: unexpected output %s", tc.desc, out))
+	}
+}
@@ -1,54 +1,202 @@
 package cli
 
 import (
-	"strconv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/spf13/cobra"
 )
 
+const (
+	certFile = "cert.pem"
+	keyFile  = "key.pem"
+	caFile   = "ca.pem"
+)
+
+var errInvalidKeyType = errors.New("invalid key type, expected rsa-2048, rsa-4096, ec-p224, ec-p256, ec-p384 or ec-p521")
+
+var keyTypes = map[string]struct {
+	keyType string
+	keyBits int
+}{
+	"rsa-2048": {"rsa", 2048},
+	"rsa-4096": {"rsa", 4096},
+	"ec-p224":  {"ec", 224},
+	"ec-p256":  {"ec", 256},
+	"ec-p384":  {"ec", 384},
+	"ec-p521":  {"ec", 521},
+}
+
+var certColumns = []string{"serial", "thing_id", "expire", "revoked"}
+
+// issuedCert describes certificate written to the output directory.
+type issuedCert struct {
+	ThingID string            `json:"thing_id"`
+	Serial  string            `json:"cert_serial"`
+	Expire  time.Time         `json:"expire"`
+	Files   map[string]string `json:"files"`
+}
+
 // NewCertsCmd returns certificate command.
 func NewCertsCmd() *cobra.Command {
-	var keySize uint16
-	var keyType string
-	var ttl uint32
+	var keyType, outDir, legacyKeyType string
+	var ttl time.Duration
+	var legacyKeySize uint16
 
 	issueCmd := cobra.Command{
 		Use:   "issue",
-		Short: "issue <thing_id> <user_auth_token> [--keysize=2048] [--keytype=rsa] [--ttl=8760]",
-		Long:  `Issues new certificate for a thing`,
+		Short: "issue <thing_id> <user_auth_token> [--ttl=8760h] [--key-type=rsa-2048] [--out-dir=<dir>]",
+		Long: `Issues new certificate for a thing. If the output directory is set, certificate,
+key and CA are written to cert.pem, key.pem and ca.pem files in it`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 2); len(args) != 2 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			kt, ok := keyTypes[strings.ToLower(keyType)]
+			if legacyKeySize != 0 || legacyKeyType != "" {
+				kt.keyType, kt.keyBits, ok = "rsa", 2048, true
+				if legacyKeyType != "" {
+					kt.keyType = strings.ToLower(legacyKeyType)
+				}
+				if legacyKeySize != 0 {
+					kt.keyBits = int(legacyKeySize)
+				}
+			}
+			if !ok {
+				logError(errors.Wrap(errInvalidKeyType, errors.New(keyType)))
+				return
+			}
+
+			c, err := sdk.IssueCert(args[0], kt.keyBits, kt.keyType, ttl.String(), args[1])
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			if outDir == "" {
+				logJSON(c)
+				return
+			}
+
+			files, err := writeCert(outDir, c)
+			if err != nil {
+				logError(err)
+				return
+			}
+			logJSON(issuedCert{
+				ThingID: c.ThingID,
+				Serial:  c.Serial,
+				Expire:  c.Expire,
+				Files:   files,
+			})
+		},
+	}
+
+	issueCmd.Flags().DurationVar(&ttl, "ttl", 8760*time.Hour, "certificate time to live")
+	issueCmd.Flags().StringVar(&keyType, "key-type", "rsa-2048", "certificate key type: rsa-2048, rsa-4096, ec-p224, ec-p256, ec-p384 or ec-p521")
+	issueCmd.Flags().StringVar(&outDir, "out-dir", "", "directory to write certificate, key and CA files to")
+	issueCmd.Flags().Uint16Var(&legacyKeySize, "keysize", 0, "certificate key strength in bits")
+	issueCmd.Flags().StringVar(&legacyKeyType, "keytype", "", "certificate key type: RSA or EC")
+	issueCmd.Flags().MarkDeprecated("keysize", "use --key-type instead")
+	issueCmd.Flags().MarkDeprecated("keytype", "use --key-type instead")
+
+	viewCmd := cobra.Command{
+		Use:   "view",
+		Short: "view <serial> <user_auth_token>",
+		Long:  `Returns certificate with the given serial`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 2); len(args) != 2 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			c, err := sdk.ViewCert(args[0], args[1])
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			logRecords(c, certColumns, [][]string{certRow(c)})
+		},
+	}
+
+	revokeCmd := cobra.Command{
+		Use:   "revoke",
+		Short: "revoke <serial> <user_auth_token>",
+		Long:  `Revokes certificate with the given serial`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
-			thingID := args[0]
-			valid := strconv.FormatUint(uint64(ttl), 10)
+			c, err := sdk.ViewCert(args[0], args[1])
+			if err != nil {
+				logError(err)
+				return
+			}
+			if c.Revoked != nil {
+				err := errors.Wrap(mfxsdk.ErrCertRevoked, errors.New(c.Revoked.Format(time.RFC3339)))
+				logError(err)
+				return
+			}
 
-			c, err := sdk.IssueCert(thingID, int(keySize), keyType, valid, args[1])
+			t, err := sdk.RevokeCertBySerial(args[0], args[1])
 			if err != nil {
 				logError(err)
 				return
 			}
-			logJSON(c)
+
+			c.Revoked = &t
+			logRecords(c, certColumns, [][]string{certRow(c)})
 		},
 	}
 
-	issueCmd.Flags().Uint16Var(&keySize, "keysize", 2048, "certificate key strength in bits: 2048, 4096 (RSA) or 224, 256, 384, 512 (EC)")
-	issueCmd.Flags().StringVar(&keyType, "keytype", "rsa", "certificate key type: RSA or EC")
-	issueCmd.Flags().Uint32Var(&ttl, "ttl", 8760, "certificate time to live in hours")
+	listCmd := cobra.Command{
+		Use:   "list",
+		Short: "list <thing_id> <user_auth_token>",
+		Long:  `Lists certificates issued for the thing`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 2); len(args) != 2 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			cp, err := sdk.ListCerts(args[0], args[1], uint64(Offset), uint64(Limit))
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			var rows [][]string
+			for _, c := range cp.Certs {
+				rows = append(rows, certRow(c))
+			}
+			logRecords(cp, certColumns, rows)
+		},
+	}
 
 	cmd := cobra.Command{
 		Use:   "certs",
 		Short: "Certificates management",
-		Long:  `Certificates management: create certificates for things"`,
+		Long:  `Certificates management: issue, view, list and revoke certificates for things`,
 		Run: func(cmd *cobra.Command, args []string) {
-			logUsage("certs [issue]")
+			logUsage("certs [issue | view | revoke | list]")
 		},
 	}
 
 	cmdCerts := []cobra.Command{
 		issueCmd,
+		viewCmd,
+		revokeCmd,
+		listCmd,
 	}
 
 	for i := range cmdCerts {
@@ -57,3 +205,41 @@ func NewCertsCmd() *cobra.Command {
 
 	return &cmd
 }
+
+// writeCert writes certificate, key and CA to the directory, returning
+// paths of the written files.
+func writeCert(dir string, c mfxsdk.Cert) (map[string]string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{}
+	for _, f := range []struct {
+		name string
+		data string
+		perm os.FileMode
+	}{
+		{certFile, c.ClientCert, 0644},
+		{keyFile, c.ClientKey, 0600},
+		{caFile, c.CACert, 0644},
+	} {
+		if f.data == "" {
+			continue
+		}
+		p := filepath.Join(dir, f.name)
+		if err := ioutil.WriteFile(p, []byte(f.data), f.perm); err != nil {
+			return nil, err
+		}
+		files[strings.TrimSuffix(f.name, ".pem")] = p
+	}
+
+	return files, nil
+}
+
+func certRow(c mfxsdk.Cert) []string {
+	revoked := ""
+	if c.Revoked != nil {
+		revoked = c.Revoked.Format(time.RFC3339)
+	}
+	return []string{c.Serial, c.ThingID, c.Expire.Format(time.RFC3339), revoked}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runCertsCmd(t *testing.T, args ...string) (string, int) {
+	return runCmd(t, NewCertsCmd(), nil, args...)
+}
+
+func TestCertsIssueCmd(t *testing.T) {
+	cases := []struct {
+		desc   string
+		args   []string
+		issued []string
+		code   int
+	}{
+		{
+			desc:   "issue cert with defaults",
+			args:   []string{"issue", "thing", userToken},
+			issued: []string{"rsa-2048 8760h0m0s"},
+			code:   0,
+		},
+		{
+			desc:   "issue cert with key type and ttl",
+			args:   []string{"issue", "thing", userToken, "--key-type=ec-p256", "--ttl=24h"},
+			issued: []string{"ec-256 24h0m0s"},
+			code:   0,
+		},
+		{
+			desc:   "issue cert with deprecated key flags",
+			args:   []string{"issue", "thing", userToken, "--keysize=4096"},
+			issued: []string{"rsa-4096 8760h0m0s"},
+			code:   0,
+		},
+		{
+			desc: "issue cert with invalid key type",
+			args: []string{"issue", "thing", userToken, "--key-type=dsa"},
+			code: ExitInvalidArgs,
+		},
+		{
+			desc: "issue cert with missing arguments",
+			args: []string{"issue", "thing"},
+			code: ExitInvalidArgs,
+		},
+		{
+			desc: "issue cert with invalid token",
+			args: []string{"issue", "thing", "invalid"},
+			code: ExitUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newSDKMock()
+		SetSDK(mock)
+		_, code := runCertsCmd(t, tc.args...)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected exit code %d got %d", tc.desc, tc.code, code))
+		assert.Equal(t, tc.issued, mock.issued, fmt.Sprintf("%s: expected issued %v got %v", tc.desc, tc.issued, mock.issued))
+	}
+}
+
+func TestCertsIssueOutDir(t *testing.T) {
+	SetSDK(newSDKMock())
+	dir := filepath.Join(tempDir(t), "certs")
+
+	out, code := runCertsCmd(t, "issue", "thing", userToken, "--out-dir", dir)
+	require.Equal(t, 0, code, fmt.Sprintf("unexpected exit code %d: %s", code, out))
+
+	info, err := os.Stat(dir)
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm(), "expected output directory to be private")
+
+	files := []struct {
+		name    string
+		content string
+		perm    os.FileMode
+	}{
+		{certFile, "cert thing", 0644},
+		{keyFile, "key thing", 0600},
+		{caFile, "ca", 0644},
+	}
+	for _, f := range files {
+		p := filepath.Join(dir, f.name)
+		info, err := os.Stat(p)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", f.name, err))
+		assert.Equal(t, f.perm, info.Mode().Perm(), fmt.Sprintf("%s: expected mode %s got %s", f.name, f.perm, info.Mode().Perm()))
+		data, err := ioutil.ReadFile(p)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", f.name, err))
+		assert.Equal(t, f.content, string(data), fmt.Sprintf("%s: unexpected content", f.name))
+		assert.Contains(t, out, p, fmt.Sprintf("%s: expected path in output %s", f.name, out))
+	}
+	assert.NotContains(t, out, "key thing", "expected key to not be printed")
+}
+
+func TestCertsViewCmd(t *testing.T) {
+	revoked := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	mock := newSDKMock()
+	mock.certs = []mfxsdk.Cert{{ThingID: "thing", Serial: "001"}, {ThingID: "thing", Serial: "002", Revoked: &revoked}}
+	SetSDK(mock)
+	defer func() { Format = formatJSON }()
+
+	Format = formatCSV
+	out, code := runCertsCmd(t, "view", "001", userToken)
+	assert.Equal(t, 0, code, fmt.Sprintf("view cert: unexpected exit code %d", code))
+	expected := "serial,thing_id,expire,revoked\n" +
+		"001,thing,0001-01-01T00:00:00Z,\n"
+	assert.Equal(t, expected, out, "view cert: unexpected output")
+
+	out, code = runCertsCmd(t, "view", "002", userToken)
+	assert.Equal(t, 0, code, fmt.Sprintf("view revoked cert: unexpected exit code %d", code))
+	expected = "serial,thing_id,expire,revoked\n" +
+		"002,thing,0001-01-01T00:00:00Z,2021-05-01T00:00:00Z\n"
+	assert.Equal(t, expected, out, "view revoked cert: unexpected output")
+
+	Format = formatJSON
+	out, code = runCertsCmd(t, "view", "001", userToken)
+	assert.Equal(t, 0, code, fmt.Sprintf("view cert as JSON: unexpected exit code %d", code))
+	assert.NotContains(t, out, `"revoked"`, "view cert as JSON: expected revoked to be omitted")
+
+	out, code = runCertsCmd(t, "view", "002", userToken)
+	assert.Equal(t, 0, code, fmt.Sprintf("view revoked cert as JSON: unexpected exit code %d", code))
+	assert.Contains(t, out, `"revoked": "2021-05-01T00:00:00Z"`, "view revoked cert as JSON: expected revocation time")
+
+	_, code = runCertsCmd(t, "view", "003", userToken)
+	assert.Equal(t, ExitNotFound, code, fmt.Sprintf("view non-existing cert: expected exit code %d got %d", ExitNotFound, code))
+}
+
+func TestCertsRevokeCmd(t *testing.T) {
+	mock := newSDKMock()
+	mock.certs = []mfxsdk.Cert{{ThingID: "thing", Serial: "001"}, {ThingID: "thing", Serial: "002"}}
+	SetSDK(mock)
+
+	cases := []struct {
+		desc string
+		args []string
+		code int
+	}{
+		{
+			desc: "revoke cert with invalid token",
+			args: []string{"revoke", "001", "invalid"},
+			code: ExitUnauthorized,
+		},
+		{
+			desc: "revoke non-existing cert",
+			args: []string{"revoke", "003", userToken},
+			code: ExitNotFound,
+		},
+		{
+			desc: "revoke cert",
+			args: []string{"revoke", "002", userToken},
+			code: 0,
+		},
+		{
+			desc: "revoke already revoked cert",
+			args: []string{"revoke", "002", userToken},
+			code: ExitConflict,
+		},
+	}
+
+	for _, tc := range cases {
+		_, code := runCertsCmd(t, tc.args...)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected exit code %d got %d", tc.desc, tc.code, code))
+	}
+	assert.Nil(t, mock.certs[0].Revoked, "expected other cert of the thing to not be revoked")
+	assert.NotNil(t, mock.certs[1].Revoked, "expected cert to be revoked")
+}
+
+func TestCertsListCmd(t *testing.T) {
+	mock := newSDKMock()
+	mock.certs = []mfxsdk.Cert{
+		{ThingID: "thing", Serial: "001"},
+		{ThingID: "other", Serial: "002"},
+		{ThingID: "thing", Serial: "003"},
+	}
+	SetSDK(mock)
+	Format = formatTable
+	defer func() { Format = formatJSON }()
+
+	out, code := runCertsCmd(t, "list", "thing", userToken)
+	assert.Equal(t, 0, code, fmt.Sprintf("list certs: unexpected exit code %d", code))
+	expected := "SERIAL  THING_ID  EXPIRE                REVOKED\n" +
+		"001     thing     0001-01-01T00:00:00Z  \n" +
+		"003     thing     0001-01-01T00:00:00Z  \n"
+	assert.Equal(t, expected, out, "list certs: unexpected output")
+}
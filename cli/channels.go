@@ -6,6 +6,7 @@ package cli
 import (
 	"encoding/json"
 
+	"github.com/mainflux/mainflux/pkg/errors"
 	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/spf13/cobra"
 )
@@ -16,14 +17,14 @@ var cmdChannels = []cobra.Command{
 		Short: "create <JSON_channel> <user_auth_token>",
 		Long:  `Creates new channel and generates it's UUID`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
 			var channel mfxsdk.Channel
 			if err := json.Unmarshal([]byte(args[0]), &channel); err != nil {
-				logError(err)
+				logError(errors.Wrap(errInvalidArgs, err))
 				return
 			}
 
@@ -41,7 +42,7 @@ var cmdChannels = []cobra.Command{
 		Short: "get [all | <channel_id>] <user_auth_token>",
 		Long:  `Gets list of all channels or gets channel by id`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -53,7 +54,7 @@ var cmdChannels = []cobra.Command{
 					return
 				}
 
-				logJSON(l)
+				logRecords(l, channelColumns, channelRows(l.Channels))
 				return
 			}
 
@@ -63,7 +64,7 @@ var cmdChannels = []cobra.Command{
 				return
 			}
 
-			logJSON(c)
+			logRecords(c, channelColumns, channelRows([]mfxsdk.Channel{c}))
 		},
 	},
 	cobra.Command{
@@ -71,14 +72,14 @@ var cmdChannels = []cobra.Command{
 		Short: "update <JSON_string> <user_auth_token>",
 		Long:  `Updates channel record`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
 			var channel mfxsdk.Channel
 			if err := json.Unmarshal([]byte(args[0]), &channel); err != nil {
-				logError(err)
+				logError(errors.Wrap(errInvalidArgs, err))
 				return
 			}
 
@@ -95,7 +96,7 @@ var cmdChannels = []cobra.Command{
 		Short: "delete <channel_id> <user_auth_token>",
 		Long:  `Delete channel by ID`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -113,7 +114,7 @@ var cmdChannels = []cobra.Command{
 		Short: "connections <channel_id> <user_auth_token>",
 		Long:  `List of Things connected to a Channel`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -124,7 +125,7 @@ var cmdChannels = []cobra.Command{
 				return
 			}
 
-			logJSON(cl)
+			logRecords(cl, thingColumns, thingRows(cl.Things))
 		},
 	},
 	cobra.Command{
@@ -132,7 +133,7 @@ var cmdChannels = []cobra.Command{
 		Short: "not-connected <channel_id> <user_auth_token>",
 		Long:  `List of Things not connected to a Channel`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -143,7 +144,7 @@ var cmdChannels = []cobra.Command{
 				return
 			}
 
-			logJSON(cl)
+			logRecords(cl, thingColumns, thingRows(cl.Things))
 		},
 	},
 }
@@ -165,3 +166,13 @@ func NewChannelsCmd() *cobra.Command {
 
 	return &cmd
 }
+
+var channelColumns = []string{"id", "name", "metadata"}
+
+func channelRows(chs []mfxsdk.Channel) [][]string {
+	rows := [][]string{}
+	for _, c := range chs {
+		rows = append(rows, []string{c.ID, c.Name, metadataValue(c.Metadata)})
+	}
+	return rows
+}
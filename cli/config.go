@@ -3,19 +3,180 @@ package cli
 import (
 	"fmt"
 	"io/ioutil"
-	"log"
 	"os"
 	"path"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/pelletier/go-toml"
+	"github.com/spf13/cobra"
 )
 
+const (
+	envPrefix   = "MF_CLI_"
+	envProfile  = envPrefix + "PROFILE"
+	defProfile  = "default"
+	configPerm  = 0600
+	configDir   = ".mainflux"
+	configFile  = "config.toml"
+	insecureMsk = 0077
+)
+
+var (
+	errUnknownProfile = errors.New("unknown profile")
+	errUnknownKey     = errors.New("unknown config key")
+	errInsecureConfig = errors.New("config file containing token must not be accessible by other users, set its permissions to 0600")
+)
+
+// Profile contains connection settings of a single Mainflux deployment.
+type Profile struct {
+	MainfluxURL     string `toml:"mainflux_url,omitempty"`
+	ReaderURL       string `toml:"reader_url,omitempty"`
+	BootstrapURL    string `toml:"bootstrap_url,omitempty"`
+	CertsURL        string `toml:"certs_url,omitempty"`
+	UsersPrefix     string `toml:"users_prefix,omitempty"`
+	ThingsPrefix    string `toml:"things_prefix,omitempty"`
+	GroupsPrefix    string `toml:"groups_prefix,omitempty"`
+	HTTPPrefix      string `toml:"http_prefix,omitempty"`
+	ContentType     string `toml:"content_type,omitempty"`
+	TLSVerification string `toml:"tls_verification,omitempty"`
+	CACert          string `toml:"ca_cert,omitempty"`
+	ClientCert      string `toml:"client_cert,omitempty"`
+	ClientKey       string `toml:"client_key,omitempty"`
+	Token           string `toml:"token,omitempty"`
+	Output          string `toml:"output,omitempty"`
+}
+
+// Config contains CLI configuration stored in the config file.
 type Config struct {
-	Offset    uint   `toml:"offset"`
-	Limit     uint   `toml:"limit"`
-	Name      string `toml:"name"`
-	RawOutput bool   `toml:"raw_output"`
+	Offset        uint               `toml:"offset"`
+	Limit         uint               `toml:"limit"`
+	Name          string             `toml:"name"`
+	RawOutput     bool               `toml:"raw_output"`
+	ActiveProfile string             `toml:"active_profile,omitempty"`
+	Profiles      map[string]Profile `toml:"profiles,omitempty"`
+}
+
+// setting binds a profile field to the command line flag overriding it,
+// the environment variable MF_CLI_<KEY> and the SDK configuration field.
+type setting struct {
+	key   string
+	flag  string
+	alias string
+	field func(p *Profile) *string
+	apply func(c *mfxsdk.Config, v string) error
+}
+
+var settings = []setting{
+	{
+		key:   "mainflux_url",
+		flag:  "mainflux-url",
+		field: func(p *Profile) *string { return &p.MainfluxURL },
+		apply: func(c *mfxsdk.Config, v string) error { c.BaseURL = v; return nil },
+	},
+	{
+		key:   "reader_url",
+		flag:  "reader-url",
+		field: func(p *Profile) *string { return &p.ReaderURL },
+		apply: func(c *mfxsdk.Config, v string) error { c.ReaderURL = v; return nil },
+	},
+	{
+		key:   "bootstrap_url",
+		flag:  "bootstrap-url",
+		field: func(p *Profile) *string { return &p.BootstrapURL },
+		apply: func(c *mfxsdk.Config, v string) error { c.BootstrapURL = v; return nil },
+	},
+	{
+		key:   "certs_url",
+		flag:  "certs-url",
+		field: func(p *Profile) *string { return &p.CertsURL },
+		apply: func(c *mfxsdk.Config, v string) error { c.CertsURL = v; return nil },
+	},
+	{
+		key:   "users_prefix",
+		flag:  "users-prefix",
+		field: func(p *Profile) *string { return &p.UsersPrefix },
+		apply: func(c *mfxsdk.Config, v string) error { c.UsersPrefix = v; return nil },
+	},
+	{
+		key:   "things_prefix",
+		flag:  "things-prefix",
+		field: func(p *Profile) *string { return &p.ThingsPrefix },
+		apply: func(c *mfxsdk.Config, v string) error { c.ThingsPrefix = v; return nil },
+	},
+	{
+		key:   "groups_prefix",
+		flag:  "groups-prefix",
+		field: func(p *Profile) *string { return &p.GroupsPrefix },
+		apply: func(c *mfxsdk.Config, v string) error { c.GroupsPrefix = v; return nil },
+	},
+	{
+		key:   "http_prefix",
+		flag:  "http-prefix",
+		field: func(p *Profile) *string { return &p.HTTPPrefix },
+		apply: func(c *mfxsdk.Config, v string) error { c.HTTPAdapterPrefix = v; return nil },
+	},
+	{
+		key:   "content_type",
+		flag:  "content-type",
+		field: func(p *Profile) *string { return &p.ContentType },
+		apply: func(c *mfxsdk.Config, v string) error { c.MsgContentType = mfxsdk.ContentType(v); return nil },
+	},
+	{
+		key:   "tls_verification",
+		flag:  "insecure",
+		field: func(p *Profile) *string { return &p.TLSVerification },
+		apply: func(c *mfxsdk.Config, v string) error {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return errors.Wrap(errors.New("invalid tls_verification value"), err)
+			}
+			c.TLSVerification = b
+			return nil
+		},
+	},
+	{
+		key:   "ca_cert",
+		flag:  "ca-cert",
+		field: func(p *Profile) *string { return &p.CACert },
+		apply: func(c *mfxsdk.Config, v string) error { c.CACertPath = v; return nil },
+	},
+	{
+		key:   "client_cert",
+		flag:  "client-cert",
+		field: func(p *Profile) *string { return &p.ClientCert },
+		apply: func(c *mfxsdk.Config, v string) error { c.ClientCertPath = v; return nil },
+	},
+	{
+		key:   "client_key",
+		flag:  "client-key",
+		field: func(p *Profile) *string { return &p.ClientKey },
+		apply: func(c *mfxsdk.Config, v string) error { c.ClientKeyPath = v; return nil },
+	},
+	{
+		key:   "token",
+		field: func(p *Profile) *string { return &p.Token },
+		apply: func(c *mfxsdk.Config, v string) error { Token = v; return nil },
+	},
+	{
+		key:   "output",
+		flag:  "output",
+		alias: "format",
+		field: func(p *Profile) *string { return &p.Output },
+		apply: func(c *mfxsdk.Config, v string) error { Format = v; return nil },
+	},
+}
+
+func lookupSetting(key string) (setting, error) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, errors.Wrap(errUnknownKey, errors.New(key))
 }
 
 // save - store config in a file
@@ -24,10 +185,14 @@ func save(c Config, file string) error {
 	if err != nil {
 		return errors.New(fmt.Sprintf("failed to read config file: %s", err))
 	}
-	if err := ioutil.WriteFile(file, b, 0644); err != nil {
+	if err := os.MkdirAll(path.Dir(file), 0700); err != nil {
+		return errors.New(fmt.Sprintf("failed to create config directory: %s", err))
+	}
+	if err := ioutil.WriteFile(file, b, configPerm); err != nil {
 		return errors.New(fmt.Sprintf("failed to write config TOML: %s", err))
 	}
-	return nil
+	// WriteFile doesn't change permissions of the existing file.
+	return os.Chmod(file, configPerm)
 }
 
 // read - retrieve config from a file
@@ -44,53 +209,313 @@ func read(file string) (Config, error) {
 	return c, nil
 }
 
+// getConfigPath returns path of the config file. If the path is not passed
+// by user, ~/.mainflux/config.toml is used, falling back to the legacy
+// <user_config_dir>/mainflux/cli.toml if only that one exists.
 func getConfigPath() (string, error) {
 	// Check if a config path passed by user exists.
 	if ConfigPath != "" {
 		if _, err := os.Stat(ConfigPath); os.IsNotExist(err) {
-			errConfigNotFound := errors.Wrap(errors.New("config file was not found"), err)
-			logError(errConfigNotFound)
-			return "", err
+			return ConfigPath, errors.Wrap(errors.New("config file was not found"), err)
 		}
+		return ConfigPath, nil
 	}
 
-	// If not, then read it from the user config directory.
-	if ConfigPath == "" {
-		userConfigDir, _ := os.UserConfigDir()
-		ConfigPath = path.Join(userConfigDir, "mainflux", "cli.toml")
+	home, _ := os.UserHomeDir()
+	p := path.Join(home, configDir, configFile)
+	if _, err := os.Stat(p); err == nil {
+		return p, nil
 	}
 
-	if _, err := os.Stat(ConfigPath); os.IsNotExist(err) {
-		return "", err
+	userConfigDir, _ := os.UserConfigDir()
+	legacy := path.Join(userConfigDir, "mainflux", "cli.toml")
+	if _, err := os.Stat(legacy); err == nil {
+		return legacy, nil
 	}
 
-	return ConfigPath, nil
+	return p, os.ErrNotExist
 }
 
-func ParseConfig() {
-	path, err := getConfigPath()
+// loadConfig reads the config file, returning empty config if the default
+// file doesn't exist. Missing file passed by user is only accepted if the
+// config is about to be created.
+func loadConfig(create bool) (Config, string, error) {
+	p, err := getConfigPath()
+	if os.IsNotExist(err) || (err != nil && create) {
+		return Config{}, p, nil
+	}
 	if err != nil {
-		return
+		return Config{}, p, err
+	}
+
+	c, err := read(p)
+	return c, p, err
+}
+
+// profileName returns name of the profile selected by the --profile flag,
+// MF_CLI_PROFILE environment variable or the active config profile.
+func profileName(c Config) string {
+	if ProfileName != "" {
+		return ProfileName
+	}
+	if p := os.Getenv(envProfile); p != "" {
+		return p
 	}
+	if c.ActiveProfile != "" {
+		return c.ActiveProfile
+	}
+	return defProfile
+}
 
-	config, err := read(path)
+// ParseConfig applies the config file and environment to the SDK
+// configuration. Settings are resolved in the order: command line flag,
+// MF_CLI_<KEY> environment variable, selected config profile.
+func ParseConfig(cmd *cobra.Command, sdkConf *mfxsdk.Config) error {
+	config, p, err := loadConfig(false)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	if config.Offset != 0 {
+	if config.Offset != 0 && !flagChanged(cmd, "offset") {
 		Offset = config.Offset
 	}
 
-	if config.Limit != 0 {
+	if config.Limit != 0 && !flagChanged(cmd, "limit") {
 		Limit = config.Limit
 	}
 
-	if config.Name != "" {
+	if config.Name != "" && !flagChanged(cmd, "name") {
 		Name = config.Name
 	}
 
 	if config.RawOutput {
 		RawOutput = config.RawOutput
 	}
+
+	name := profileName(config)
+	profile, ok := config.Profiles[name]
+	if !ok && name != defProfile {
+		return errors.Wrap(errUnknownProfile, errors.New(name))
+	}
+
+	if profile.Token != "" {
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm()&insecureMsk != 0 {
+			return errInsecureConfig
+		}
+	}
+
+	for _, s := range settings {
+		if flagChanged(cmd, s.flag) || flagChanged(cmd, s.alias) {
+			continue
+		}
+		v := os.Getenv(envPrefix + strings.ToUpper(s.key))
+		if v == "" {
+			v = *s.field(&profile)
+		}
+		if v == "" {
+			continue
+		}
+		if err := s.apply(sdkConf, v); err != nil {
+			return err
+		}
+	}
+
+	switch Format {
+	case formatJSON, formatTable, formatCSV:
+		return nil
+	default:
+		return errors.Wrap(errInvalidFormat, errors.New(Format))
+	}
+}
+
+func flagChanged(cmd *cobra.Command, name string) bool {
+	if name == "" {
+		return false
+	}
+	f := cmd.Flags().Lookup(name)
+	return f != nil && f.Changed
+}
+
+// NewConfigCmd returns config command.
+func NewConfigCmd() *cobra.Command {
+	getCmd := cobra.Command{
+		Use:   "get",
+		Short: "get <key>",
+		Long:  `Returns value of the key in the selected profile`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			v, err := getConfigValue(args[0])
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			fmt.Println(v)
+		},
+	}
+
+	setCmd := cobra.Command{
+		Use:   "set",
+		Short: "set <key> <value>",
+		Long: `Sets value of the key in the selected profile. Keys: mainflux_url, reader_url, bootstrap_url,
+certs_url, users_prefix, things_prefix, groups_prefix, http_prefix, content_type,
+tls_verification, ca_cert, client_cert, client_key and token. Instead of storing
+the token, consider using the MF_CLI_TOKEN environment variable`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 2 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if err := setConfigValue(args[0], args[1]); err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		},
+	}
+
+	useCmd := cobra.Command{
+		Use:   "use",
+		Short: "use <profile>",
+		Long:  `Switches the active profile`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 1 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if err := useProfile(args[0]); err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		},
+	}
+
+	profilesCmd := cobra.Command{
+		Use:   "profiles",
+		Short: "profiles",
+		Long:  `Lists profiles, marking the active one`,
+		Run: func(cmd *cobra.Command, args []string) {
+			c, _, err := loadConfig(false)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			active := profileName(c)
+			for _, n := range profileNames(c) {
+				mark := " "
+				if n == active {
+					mark = "*"
+				}
+				fmt.Printf("%s %s\n", mark, n)
+			}
+		},
+	}
+
+	cmd := cobra.Command{
+		Use:   "config",
+		Short: "CLI configuration",
+		Long:  `CLI configuration: get and set profile values and switch the active profile`,
+		Run: func(cmd *cobra.Command, args []string) {
+			logUsage("config [get | set | use | profiles]")
+		},
+	}
+
+	cmdConfig := []cobra.Command{
+		getCmd,
+		setCmd,
+		useCmd,
+		profilesCmd,
+	}
+
+	for i := range cmdConfig {
+		cmd.AddCommand(&cmdConfig[i])
+	}
+
+	return &cmd
+}
+
+func getConfigValue(key string) (string, error) {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return "", err
+	}
+
+	c, _, err := loadConfig(false)
+	if err != nil {
+		return "", err
+	}
+
+	p := c.Profiles[profileName(c)]
+	return *s.field(&p), nil
+}
+
+func setConfigValue(key, value string) error {
+	s, err := lookupSetting(key)
+	if err != nil {
+		return err
+	}
+
+	c, p, err := loadConfig(true)
+	if err != nil {
+		return err
+	}
+
+	name := profileName(c)
+	if c.Profiles == nil {
+		c.Profiles = map[string]Profile{}
+	}
+	profile := c.Profiles[name]
+	*s.field(&profile) = value
+	c.Profiles[name] = profile
+	if c.ActiveProfile == "" {
+		c.ActiveProfile = name
+	}
+
+	return save(c, p)
+}
+
+func useProfile(name string) error {
+	c, p, err := loadConfig(false)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := c.Profiles[name]; !ok {
+		return errors.Wrap(errUnknownProfile, errors.New(name))
+	}
+	c.ActiveProfile = name
+
+	return save(c, p)
+}
+
+func profileNames(c Config) []string {
+	var names []string
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withToken appends the configured token to the command arguments if it is
+// the only missing argument.
+func withToken(args []string, n int) []string {
+	if len(args) == n-1 && Token != "" {
+		return append(args, Token)
+	}
+	return args
 }
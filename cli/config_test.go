@@ -0,0 +1,316 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const profilesConfig = `limit = 20
+active_profile = "dev"
+
+[profiles.dev]
+mainflux_url = "http://dev.example.com"
+reader_url = "http://dev.example.com:8905"
+things_prefix = "things"
+
+[profiles.prod]
+mainflux_url = "https://prod.example.com"
+tls_verification = "true"
+`
+
+func writeConfig(t *testing.T, content string, perm os.FileMode) string {
+	p := filepath.Join(tempDir(t), "config.toml")
+	err := ioutil.WriteFile(p, []byte(content), perm)
+	require.Nil(t, err, fmt.Sprintf("unexpected error writing config: %s", err))
+	err = os.Chmod(p, perm)
+	require.Nil(t, err, fmt.Sprintf("unexpected error changing config permissions: %s", err))
+	return p
+}
+
+// newConfigTestCmd returns command with the flags bound the same way
+// the CLI root command binds them.
+func newConfigTestCmd(conf *mfxsdk.Config) *cobra.Command {
+	cmd := &cobra.Command{Use: "test", Run: func(cmd *cobra.Command, args []string) {}}
+	cmd.Flags().StringVarP(&conf.BaseURL, "mainflux-url", "m", conf.BaseURL, "")
+	cmd.Flags().StringVar(&conf.ReaderURL, "reader-url", conf.ReaderURL, "")
+	cmd.Flags().StringVarP(&conf.ThingsPrefix, "things-prefix", "t", conf.ThingsPrefix, "")
+	cmd.Flags().BoolVarP(&conf.TLSVerification, "insecure", "i", conf.TLSVerification, "")
+	cmd.Flags().UintVarP(&Limit, "limit", "l", 100, "")
+	cmd.Flags().StringVar(&Format, "output", formatJSON, "")
+	cmd.Flags().StringVar(&Format, "format", formatJSON, "")
+	return cmd
+}
+
+func resetConfigGlobals() {
+	ConfigPath = ""
+	ProfileName = ""
+	Token = ""
+	Limit = 10
+	Format = formatJSON
+}
+
+func TestParseConfigPrecedence(t *testing.T) {
+	defer resetConfigGlobals()
+
+	cases := []struct {
+		desc     string
+		args     []string
+		profile  string
+		env      map[string]string
+		expected mfxsdk.Config
+		limit    uint
+	}{
+		{
+			desc:    "parse active profile",
+			profile: "",
+			expected: mfxsdk.Config{
+				BaseURL:      "http://dev.example.com",
+				ReaderURL:    "http://dev.example.com:8905",
+				ThingsPrefix: "things",
+			},
+			limit: 20,
+		},
+		{
+			desc:    "parse profile selected by flag",
+			profile: "prod",
+			expected: mfxsdk.Config{
+				BaseURL:         "https://prod.example.com",
+				ReaderURL:       "http://localhost:8905",
+				TLSVerification: true,
+			},
+			limit: 20,
+		},
+		{
+			desc: "parse profile selected by env",
+			env:  map[string]string{envProfile: "prod"},
+			expected: mfxsdk.Config{
+				BaseURL:         "https://prod.example.com",
+				ReaderURL:       "http://localhost:8905",
+				TLSVerification: true,
+			},
+			limit: 20,
+		},
+		{
+			desc: "parse env overriding profile",
+			env:  map[string]string{"MF_CLI_MAINFLUX_URL": "http://env.example.com"},
+			expected: mfxsdk.Config{
+				BaseURL:      "http://env.example.com",
+				ReaderURL:    "http://dev.example.com:8905",
+				ThingsPrefix: "things",
+			},
+			limit: 20,
+		},
+		{
+			desc: "parse flags overriding env and profile",
+			args: []string{"--mainflux-url=http://flag.example.com", "--limit=5"},
+			env:  map[string]string{"MF_CLI_MAINFLUX_URL": "http://env.example.com"},
+			expected: mfxsdk.Config{
+				BaseURL:      "http://flag.example.com",
+				ReaderURL:    "http://dev.example.com:8905",
+				ThingsPrefix: "things",
+			},
+			limit: 5,
+		},
+	}
+
+	for _, tc := range cases {
+		resetConfigGlobals()
+		ConfigPath = writeConfig(t, profilesConfig, 0600)
+		ProfileName = tc.profile
+		for k, v := range tc.env {
+			os.Setenv(k, v)
+		}
+
+		conf := mfxsdk.Config{
+			BaseURL:   "http://localhost",
+			ReaderURL: "http://localhost:8905",
+		}
+		cmd := newConfigTestCmd(&conf)
+		cmd.SetArgs(tc.args)
+		err := cmd.Execute()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		err = ParseConfig(cmd, &conf)
+		for k := range tc.env {
+			os.Unsetenv(k)
+		}
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.expected, conf, fmt.Sprintf("%s: expected config %v got %v", tc.desc, tc.expected, conf))
+		assert.Equal(t, tc.limit, Limit, fmt.Sprintf("%s: expected limit %d got %d", tc.desc, tc.limit, Limit))
+	}
+}
+
+func TestParseConfigToken(t *testing.T) {
+	defer resetConfigGlobals()
+
+	content := "[profiles.default]\ntoken = \"profile-token\"\n"
+
+	cases := []struct {
+		desc  string
+		perm  os.FileMode
+		env   string
+		token string
+		err   error
+	}{
+		{
+			desc:  "parse token from private config file",
+			perm:  0600,
+			token: "profile-token",
+			err:   nil,
+		},
+		{
+			desc:  "parse token from env",
+			perm:  0600,
+			env:   "env-token",
+			token: "env-token",
+			err:   nil,
+		},
+		{
+			desc: "parse token from config file readable by others",
+			perm: 0644,
+			err:  errInsecureConfig,
+		},
+	}
+
+	for _, tc := range cases {
+		resetConfigGlobals()
+		ConfigPath = writeConfig(t, content, tc.perm)
+		if tc.env != "" {
+			os.Setenv("MF_CLI_TOKEN", tc.env)
+		}
+
+		conf := mfxsdk.Config{}
+		err := ParseConfig(newConfigTestCmd(&conf), &conf)
+		os.Unsetenv("MF_CLI_TOKEN")
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.token, Token, fmt.Sprintf("%s: expected token %s got %s", tc.desc, tc.token, Token))
+	}
+}
+
+func TestParseConfigUnknownProfile(t *testing.T) {
+	defer resetConfigGlobals()
+	ConfigPath = writeConfig(t, profilesConfig, 0600)
+	ProfileName = "staging"
+
+	conf := mfxsdk.Config{}
+	err := ParseConfig(newConfigTestCmd(&conf), &conf)
+	assert.True(t, errors.Contains(err, errUnknownProfile), fmt.Sprintf("expected error %s got %s", errUnknownProfile, err))
+}
+
+func TestParseConfigOutput(t *testing.T) {
+	defer resetConfigGlobals()
+
+	content := "[profiles.default]\noutput = \"table\"\n"
+
+	cases := []struct {
+		desc   string
+		args   []string
+		output string
+		err    error
+	}{
+		{
+			desc:   "parse output from profile",
+			output: formatTable,
+			err:    nil,
+		},
+		{
+			desc:   "parse output flag overriding profile",
+			args:   []string{"--output=csv"},
+			output: formatCSV,
+			err:    nil,
+		},
+		{
+			desc:   "parse format alias overriding profile",
+			args:   []string{"--format=json"},
+			output: formatJSON,
+			err:    nil,
+		},
+		{
+			desc:   "parse invalid output",
+			args:   []string{"--output=yaml"},
+			output: "yaml",
+			err:    errInvalidFormat,
+		},
+	}
+
+	for _, tc := range cases {
+		resetConfigGlobals()
+		ConfigPath = writeConfig(t, content, 0600)
+
+		conf := mfxsdk.Config{}
+		cmd := newConfigTestCmd(&conf)
+		cmd.SetArgs(tc.args)
+		err := cmd.Execute()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		err = ParseConfig(cmd, &conf)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.output, Format, fmt.Sprintf("%s: expected output %s got %s", tc.desc, tc.output, Format))
+	}
+}
+
+func TestProfileSwitching(t *testing.T) {
+	defer resetConfigGlobals()
+	resetConfigGlobals()
+	ConfigPath = filepath.Join(tempDir(t), "config.toml")
+
+	err := setConfigValue("mainflux_url", "http://dev.example.com")
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+
+	ProfileName = "prod"
+	err = setConfigValue("mainflux_url", "https://prod.example.com")
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	err = setConfigValue("token", "prod-token")
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	ProfileName = ""
+
+	info, err := os.Stat(ConfigPath)
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, os.FileMode(configPerm), info.Mode().Perm(), "expected config file to be private")
+
+	v, err := getConfigValue("mainflux_url")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, "http://dev.example.com", v, "expected value of the active profile")
+
+	err = setConfigValue("unknown", "value")
+	assert.True(t, errors.Contains(err, errUnknownKey), fmt.Sprintf("expected error %s got %s", errUnknownKey, err))
+
+	err = useProfile("staging")
+	assert.True(t, errors.Contains(err, errUnknownProfile), fmt.Sprintf("expected error %s got %s", errUnknownProfile, err))
+
+	err = useProfile("prod")
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+
+	conf := mfxsdk.Config{}
+	err = ParseConfig(newConfigTestCmd(&conf), &conf)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, "https://prod.example.com", conf.BaseURL, "expected URL of the switched profile")
+	assert.Equal(t, "prod-token", Token, "expected token of the switched profile")
+
+	c, err := read(ConfigPath)
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, []string{"default", "prod"}, profileNames(c), "expected both profiles to be stored")
+}
+
+func TestWithToken(t *testing.T) {
+	defer resetConfigGlobals()
+
+	Token = ""
+	assert.Equal(t, []string{"a"}, withToken([]string{"a"}, 2), "expected args without token")
+
+	Token = "token"
+	assert.Equal(t, []string{"a", "token"}, withToken([]string{"a"}, 2), "expected token to be appended")
+	assert.Equal(t, []string{"a", "b"}, withToken([]string{"a", "b"}, 2), "expected explicit token to be kept")
+}
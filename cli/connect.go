@@ -0,0 +1,242 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/spf13/cobra"
+)
+
+const defMaxPairs = 1000
+
+var (
+	errMissingIDs        = errors.New("both --things and --channels must contain at least one ID")
+	errPairCap           = errors.New("number of connections exceeds --max-pairs, use --yes to proceed")
+	errPartialConnection = errors.New("failed to update some of the connections")
+)
+
+var connectionColumns = []string{"thing_id", "channel_id", "status", "error"}
+
+// connectionAction describes the bulk operation executed on the pairs.
+type connectionAction struct {
+	name   string
+	status string
+	bulk   func(conns mfxsdk.ConnectionIDs, token string) error
+	single func(thingID, chanID, token string) error
+}
+
+// connectionResult is the outcome of the action for a single pair.
+type connectionResult struct {
+	ThingID   string `json:"thing_id"`
+	ChannelID string `json:"channel_id"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// connectionPlan lists pairs the action would be executed on.
+type connectionPlan struct {
+	Action      string             `json:"action"`
+	Pairs       int                `json:"pairs"`
+	Connections []connectionResult `json:"connections"`
+}
+
+// NewConnectCmd returns bulk connect command.
+func NewConnectCmd() *cobra.Command {
+	return newConnectionsCmd(connectionAction{
+		name:   "connect",
+		status: "connected",
+		bulk:   func(conns mfxsdk.ConnectionIDs, token string) error { return sdk.Connect(conns, token) },
+		single: func(thingID, chanID, token string) error {
+			return sdk.Connect(mfxsdk.ConnectionIDs{ThingIDs: []string{thingID}, ChannelIDs: []string{chanID}}, token)
+		},
+	})
+}
+
+// NewDisconnectCmd returns bulk disconnect command.
+func NewDisconnectCmd() *cobra.Command {
+	return newConnectionsCmd(connectionAction{
+		name:   "disconnect",
+		status: "disconnected",
+		bulk:   func(conns mfxsdk.ConnectionIDs, token string) error { return sdk.Disconnect(conns, token) },
+		single: func(thingID, chanID, token string) error { return sdk.DisconnectThing(thingID, chanID, token) },
+	})
+}
+
+func newConnectionsCmd(action connectionAction) *cobra.Command {
+	var things, channels string
+	var maxPairs int
+	var dryRun, yes bool
+
+	cmd := cobra.Command{
+		Use:   action.name,
+		Short: fmt.Sprintf("%s --things <file|ids> --channels <file|ids> [--dry-run] [--max-pairs=%d] [--yes] <user_auth_token>", action.name, defMaxPairs),
+		Long: fmt.Sprintf(`Bulk %ss every thing to every channel. Things and channels are given as
+comma separated IDs or files containing IDs separated by new lines or commas`, action.name),
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 1); len(args) != 1 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			conns, err := parseConnections(things, channels)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			pairs := len(conns.ThingIDs) * len(conns.ChannelIDs)
+			if dryRun {
+				logPlan(action.name, conns)
+				return
+			}
+			if pairs > maxPairs && !yes {
+				logError(errors.Wrap(errPairCap, errors.New(fmt.Sprintf("%d > %d", pairs, maxPairs))))
+				return
+			}
+
+			res, err := updateConnections(action, conns, args[0])
+			logConnections(res)
+			if err != nil {
+				logError(err)
+				return
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&things, "things", "", "file or comma separated list of thing IDs")
+	cmd.Flags().StringVar(&channels, "channels", "", "file or comma separated list of channel IDs")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the connection plan without executing it")
+	cmd.Flags().IntVar(&maxPairs, "max-pairs", defMaxPairs, "maximum number of pairs updated without confirmation")
+	cmd.Flags().BoolVar(&yes, "yes", false, "update connections even if their number exceeds --max-pairs")
+
+	return &cmd
+}
+
+// parseConnections reads thing and channel IDs from files or comma
+// separated lists.
+func parseConnections(things, channels string) (mfxsdk.ConnectionIDs, error) {
+	thIDs, err := parseIDs(things)
+	if err != nil {
+		return mfxsdk.ConnectionIDs{}, err
+	}
+	chIDs, err := parseIDs(channels)
+	if err != nil {
+		return mfxsdk.ConnectionIDs{}, err
+	}
+	if len(thIDs) == 0 || len(chIDs) == 0 {
+		return mfxsdk.ConnectionIDs{}, errors.Wrap(errInvalidArgs, errMissingIDs)
+	}
+	return mfxsdk.ConnectionIDs{ThingIDs: thIDs, ChannelIDs: chIDs}, nil
+}
+
+// parseIDs returns IDs listed in the file with the given path or, if there
+// is no such file, in the comma separated list. Empty values, duplicates
+// and file lines starting with # are skipped.
+func parseIDs(src string) ([]string, error) {
+	var values []string
+	switch f, err := os.Open(src); {
+	case err == nil:
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			values = append(values, strings.Split(line, ",")...)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	case os.IsNotExist(err) || src == "":
+		values = strings.Split(src, ",")
+	default:
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	ids := []string{}
+	for _, v := range values {
+		id := strings.TrimSpace(v)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// updateConnections executes the action on all pairs using the bulk
+// endpoint. Bulk updates are atomic, so if it fails, pairs are updated one
+// by one to report which of them failed.
+func updateConnections(action connectionAction, conns mfxsdk.ConnectionIDs, token string) ([]connectionResult, error) {
+	res := []connectionResult{}
+	err := action.bulk(conns, token)
+	if err == nil {
+		for _, thID := range conns.ThingIDs {
+			for _, chID := range conns.ChannelIDs {
+				res = append(res, connectionResult{ThingID: thID, ChannelID: chID, Status: action.status})
+			}
+		}
+		return res, nil
+	}
+	if c := ExitCode(err); c == ExitUnauthorized || c == ExitServerError {
+		return res, err
+	}
+
+	var failed error
+	for _, thID := range conns.ThingIDs {
+		for _, chID := range conns.ChannelIDs {
+			r := connectionResult{ThingID: thID, ChannelID: chID, Status: action.status}
+			if err := action.single(thID, chID, token); err != nil {
+				r.Status, r.Error = "failed", err.Error()
+				if failed == nil {
+					failed = err
+				}
+			}
+			res = append(res, r)
+		}
+	}
+	if failed != nil {
+		return res, errors.Wrap(errPartialConnection, failed)
+	}
+	return res, nil
+}
+
+func connectionRows(res []connectionResult) [][]string {
+	rows := [][]string{}
+	for _, r := range res {
+		rows = append(rows, []string{r.ThingID, r.ChannelID, r.Status, r.Error})
+	}
+	return rows
+}
+
+func logConnections(res []connectionResult) {
+	if len(res) == 0 {
+		return
+	}
+	logRecords(res, connectionColumns, connectionRows(res))
+}
+
+func logPlan(action string, conns mfxsdk.ConnectionIDs) {
+	plan := connectionPlan{Action: action, Connections: []connectionResult{}}
+	for _, thID := range conns.ThingIDs {
+		for _, chID := range conns.ChannelIDs {
+			plan.Connections = append(plan.Connections, connectionResult{ThingID: thID, ChannelID: chID, Status: "planned"})
+		}
+	}
+	plan.Pairs = len(plan.Connections)
+
+	logRecords(plan, connectionColumns, connectionRows(plan.Connections))
+	if Format == formatTable {
+		fmt.Printf("\n%d pairs to %s\n", plan.Pairs, action)
+	}
+}
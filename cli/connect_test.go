@@ -0,0 +1,154 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIDs(t *testing.T) {
+	dir := tempDir(t)
+	file := filepath.Join(dir, "ids.txt")
+	err := ioutil.WriteFile(file, []byte("# things\nth-1\n\n  th-2  \nth-3,th-4\nth-1\n"), 0600)
+	require.Nil(t, err, fmt.Sprintf("unexpected error writing IDs: %s", err))
+
+	cases := []struct {
+		desc string
+		src  string
+		ids  []string
+		err  error
+	}{
+		{
+			desc: "parse IDs from file",
+			src:  file,
+			ids:  []string{"th-1", "th-2", "th-3", "th-4"},
+		},
+		{
+			desc: "parse comma separated IDs",
+			src:  "ch-1, ch-2,,ch-1",
+			ids:  []string{"ch-1", "ch-2"},
+		},
+		{
+			desc: "parse single ID",
+			src:  "ch-1",
+			ids:  []string{"ch-1"},
+		},
+		{
+			desc: "parse empty list",
+			src:  "",
+			ids:  []string{},
+		},
+	}
+
+	for _, tc := range cases {
+		ids, err := parseIDs(tc.src)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.ids, ids, fmt.Sprintf("%s: expected IDs %v got %v", tc.desc, tc.ids, ids))
+	}
+
+	_, err = parseConnections(file, "")
+	assert.True(t, errors.Contains(err, errMissingIDs), fmt.Sprintf("expected error %s got %s", errMissingIDs, err))
+}
+
+func TestConnectCmd(t *testing.T) {
+	file := filepath.Join(tempDir(t), "channels.txt")
+	err := ioutil.WriteFile(file, []byte("ch-1\nch-2\n"), 0600)
+	require.Nil(t, err, fmt.Sprintf("unexpected error writing IDs: %s", err))
+	defer func() { Format = formatJSON }()
+
+	mock := newSDKMock()
+	SetSDK(mock)
+	Format = formatCSV
+
+	out, code := runCmd(t, NewConnectCmd(), nil, "--things=th-1,th-2", "--channels", file, userToken)
+	assert.Equal(t, 0, code, fmt.Sprintf("connect: unexpected exit code %d: %s", code, out))
+	expected := "thing_id,channel_id,status,error\n" +
+		"th-1,ch-1,connected,\n" +
+		"th-1,ch-2,connected,\n" +
+		"th-2,ch-1,connected,\n" +
+		"th-2,ch-2,connected,\n"
+	assert.Equal(t, expected, out, "connect: unexpected output")
+	assert.Equal(t, []string{"th-1-ch-1", "th-1-ch-2", "th-2-ch-1", "th-2-ch-2"}, mock.connections(), "connect: expected all pairs to be connected")
+
+	out, code = runCmd(t, NewDisconnectCmd(), nil, "--things=th-1", "--channels", file, userToken)
+	assert.Equal(t, 0, code, fmt.Sprintf("disconnect: unexpected exit code %d: %s", code, out))
+	assert.Equal(t, []string{"th-2-ch-1", "th-2-ch-2"}, mock.connections(), "disconnect: expected pairs of the thing to be disconnected")
+}
+
+func TestConnectCmdDryRun(t *testing.T) {
+	defer func() { Format = formatJSON }()
+
+	mock := newSDKMock()
+	SetSDK(mock)
+	Format = formatTable
+
+	out, code := runCmd(t, NewConnectCmd(), nil, "--things=th-1,th-2", "--channels=ch-1", "--dry-run", "--max-pairs=1", userToken)
+	assert.Equal(t, 0, code, fmt.Sprintf("unexpected exit code %d: %s", code, out))
+	expected := "THING_ID  CHANNEL_ID  STATUS   ERROR\n" +
+		"th-1      ch-1        planned  \n" +
+		"th-2      ch-1        planned  \n" +
+		"\n2 pairs to connect\n"
+	assert.Equal(t, expected, out, "unexpected plan")
+	assert.Empty(t, mock.connections(), "expected dry run to not connect anything")
+}
+
+func TestConnectCmdPairCap(t *testing.T) {
+	cases := []struct {
+		desc  string
+		args  []string
+		code  int
+		conns int
+	}{
+		{
+			desc:  "connect pairs below the cap",
+			args:  []string{"--things=th-1,th-2", "--channels=ch-1,ch-2", "--max-pairs=4", userToken},
+			code:  0,
+			conns: 4,
+		},
+		{
+			desc:  "connect pairs above the cap",
+			args:  []string{"--things=th-1,th-2", "--channels=ch-1,ch-2", "--max-pairs=3", userToken},
+			code:  ExitInvalidArgs,
+			conns: 0,
+		},
+		{
+			desc:  "connect confirmed pairs above the cap",
+			args:  []string{"--things=th-1,th-2", "--channels=ch-1,ch-2", "--max-pairs=3", "--yes", userToken},
+			code:  0,
+			conns: 4,
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newSDKMock()
+		SetSDK(mock)
+		out, code := runCmd(t, NewConnectCmd(), nil, tc.args...)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected exit code %d got %d: %s", tc.desc, tc.code, code, out))
+		assert.Len(t, mock.connections(), tc.conns, fmt.Sprintf("%s: expected %d connections", tc.desc, tc.conns))
+	}
+}
+
+func TestConnectCmdPartialFailure(t *testing.T) {
+	defer func() { Format = formatJSON }()
+
+	mock := newSDKMock()
+	mock.conns["th-1"] = map[string]bool{"ch-2": true}
+	SetSDK(mock)
+	Format = formatCSV
+
+	out, code := runCmd(t, NewConnectCmd(), nil, "--things=th-1,th-2", "--channels=ch-1,ch-2", userToken)
+	assert.Equal(t, ExitConflict, code, fmt.Sprintf("expected exit code %d got %d", ExitConflict, code))
+	assert.Contains(t, out, "th-1,ch-1,connected,\n", "expected connected pair to be reported")
+	assert.Contains(t, out, "th-1,ch-2,failed,failed to connect thing to channel : 409 Conflict\n", "expected failed pair to be reported")
+	assert.Contains(t, out, "th-2,ch-2,connected,\n", "expected connected pair to be reported")
+	assert.Contains(t, out, errPartialConnection.Error(), "expected partial failure error")
+	assert.Equal(t, []string{"th-1-ch-1", "th-1-ch-2", "th-2-ch-1", "th-2-ch-2"}, mock.connections(), "expected remaining pairs to be connected")
+}
@@ -0,0 +1,147 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Keys handled by the line editor.
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyTab       = 9
+	keyCtrlU     = 21
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// editor reads lines from the terminal in raw mode, supporting history
+// and tab completion. The cursor is always at the end of the line.
+type editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	complete func(line string) []string
+	history  func() []string
+}
+
+func newEditor(in io.Reader, out io.Writer, complete func(string) []string, history func() []string) *editor {
+	return &editor{
+		in:       bufio.NewReader(in),
+		out:      out,
+		complete: complete,
+		history:  history,
+	}
+}
+
+func (e *editor) readLine(prompt string) (string, error) {
+	hist := e.history()
+	pos := len(hist)
+	var buf []rune
+
+	fmt.Fprint(e.out, prompt)
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			if err == io.EOF && len(buf) > 0 {
+				return string(buf), nil
+			}
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(buf), nil
+		case keyCtrlC:
+			fmt.Fprint(e.out, "^C\r\n"+prompt)
+			buf, pos = nil, len(hist)
+		case keyCtrlD:
+			if len(buf) == 0 {
+				return "", io.EOF
+			}
+		case keyBackspace, keyDelete:
+			if len(buf) > 0 {
+				buf = buf[:len(buf)-1]
+				fmt.Fprint(e.out, "\b \b")
+			}
+		case keyCtrlU:
+			buf = nil
+			e.redraw(prompt, buf)
+		case keyTab:
+			buf = e.tab(prompt, buf)
+		case keyEscape:
+			// Arrow keys are sent as ESC [ A and ESC [ B.
+			if b, _ := e.in.ReadByte(); b != '[' {
+				continue
+			}
+			switch b, _ := e.in.ReadByte(); {
+			case b == 'A' && pos > 0:
+				pos--
+				buf = []rune(hist[pos])
+			case b == 'B' && pos < len(hist):
+				pos++
+				buf = nil
+				if pos < len(hist) {
+					buf = []rune(hist[pos])
+				}
+			default:
+				continue
+			}
+			e.redraw(prompt, buf)
+		default:
+			if r >= ' ' {
+				buf = append(buf, r)
+				fmt.Fprint(e.out, string(r))
+			}
+		}
+	}
+}
+
+// tab completes the last word of the line to the longest common prefix
+// of the candidates. If it can't be extended, candidates are listed.
+func (e *editor) tab(prompt string, buf []rune) []rune {
+	line := string(buf)
+	cands := e.complete(line)
+	if len(cands) == 0 {
+		fmt.Fprint(e.out, "\a")
+		return buf
+	}
+
+	word := ""
+	if i := strings.LastIndexAny(line, " \t"); i < len(line)-1 {
+		word = line[i+1:]
+	}
+	prefix := commonPrefix(cands)
+	if len(cands) == 1 {
+		prefix += " "
+	}
+	if len(prefix) > len(word) {
+		buf = []rune(line[:len(line)-len(word)] + prefix)
+		e.redraw(prompt, buf)
+		return buf
+	}
+
+	fmt.Fprint(e.out, "\r\n"+strings.Join(cands, "  ")+"\r\n")
+	e.redraw(prompt, buf)
+	return buf
+}
+
+func (e *editor) redraw(prompt string, buf []rune) {
+	fmt.Fprint(e.out, "\r\x1b[K"+prompt+string(buf))
+}
+
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,90 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+)
+
+// Exit codes of failed commands.
+const (
+	// ExitFailure is returned on errors not covered by other codes.
+	ExitFailure = 1
+	// ExitInvalidArgs is returned on invalid arguments, flags or config.
+	ExitInvalidArgs = 2
+	// ExitUnauthorized is returned if the credentials are missing or invalid.
+	ExitUnauthorized = 3
+	// ExitNotFound is returned if the entity doesn't exist.
+	ExitNotFound = 4
+	// ExitConflict is returned if the entity already exists or is in
+	// conflicting state.
+	ExitConflict = 5
+	// ExitServerError is returned if the service failed to handle the request.
+	ExitServerError = 6
+)
+
+// errInvalidArgs wraps errors caused by malformed command arguments.
+var errInvalidArgs = errors.New("invalid arguments")
+
+// argErrors are errors returned on invalid arguments, flags or config.
+var argErrors = []error{
+	errInvalidArgs,
+	errInvalidFormat,
+	errInvalidTime,
+	errInvalidState,
+	errInvalidStatus,
+	errInvalidKeyType,
+	errInvalidPair,
+	errInvalidRepeat,
+	errPayloadSource,
+	errPairCap,
+	errUnknownProfile,
+	errUnknownKey,
+	errInvalidManifest,
+	errUnknownManifest,
+	mfxsdk.ErrInvalidContentType,
+	mfxsdk.ErrInvalidEncKey,
+}
+
+// exit terminates the CLI with the given code. It's replaced in tests.
+var exit = os.Exit
+
+// ExitCode returns exit code describing the class of the error, derived
+// from the SDK errors and the status of the response they were caused by.
+func ExitCode(err error) int {
+	for _, e := range argErrors {
+		if errors.Contains(err, e) {
+			return ExitInvalidArgs
+		}
+	}
+
+	switch {
+	case errors.Contains(err, mfxsdk.ErrUnauthorized):
+		return ExitUnauthorized
+	case errors.Contains(err, mfxsdk.ErrNotFound):
+		return ExitNotFound
+	case errors.Contains(err, mfxsdk.ErrCertRevoked):
+		return ExitConflict
+	}
+
+	code := mfxsdk.StatusCode(err)
+	switch {
+	case code == http.StatusBadRequest, code == http.StatusUnsupportedMediaType, code == http.StatusUnprocessableEntity:
+		return ExitInvalidArgs
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return ExitUnauthorized
+	case code == http.StatusNotFound:
+		return ExitNotFound
+	case code == http.StatusConflict:
+		return ExitConflict
+	case code >= http.StatusInternalServerError:
+		return ExitServerError
+	default:
+		return ExitFailure
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runCmd executes the command and returns its output and the code it
+// exited with, or 0 if it didn't exit.
+func runCmd(t *testing.T, cmd *cobra.Command, stdin io.Reader, args ...string) (string, int) {
+	code := 0
+	prev := exit
+	exit = func(c int) {
+		if code == 0 {
+			code = c
+		}
+	}
+	defer func() { exit = prev }()
+
+	out := captureStdout(t, func() {
+		cmd.SetArgs(args)
+		if stdin != nil {
+			cmd.SetIn(stdin)
+		}
+		err := cmd.Execute()
+		require.Nil(t, err, fmt.Sprintf("%v: unexpected error %s", args, err))
+	})
+	return out, code
+}
+
+func statusError(e error, statusCode int) error {
+	status := fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))
+	return errors.Wrap(e, errors.New(status))
+}
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		desc string
+		err  error
+		code int
+	}{
+		{
+			desc: "malformed argument",
+			err:  errors.Wrap(errInvalidArgs, errors.New("unexpected end of JSON input")),
+			code: ExitInvalidArgs,
+		},
+		{
+			desc: "invalid flag value",
+			err:  errors.Wrap(errInvalidState, errors.New("enabled")),
+			code: ExitInvalidArgs,
+		},
+		{
+			desc: "bad request",
+			err:  statusError(mfxsdk.ErrFailedCreation, http.StatusBadRequest),
+			code: ExitInvalidArgs,
+		},
+		{
+			desc: "unauthorized",
+			err:  statusError(mfxsdk.ErrFailedFetch, http.StatusUnauthorized),
+			code: ExitUnauthorized,
+		},
+		{
+			desc: "forbidden",
+			err:  statusError(mfxsdk.ErrFailedFetch, http.StatusForbidden),
+			code: ExitUnauthorized,
+		},
+		{
+			desc: "typed unauthorized",
+			err:  errors.Wrap(mfxsdk.ErrCerts, mfxsdk.ErrUnauthorized),
+			code: ExitUnauthorized,
+		},
+		{
+			desc: "not found",
+			err:  statusError(mfxsdk.ErrFailedFetch, http.StatusNotFound),
+			code: ExitNotFound,
+		},
+		{
+			desc: "typed not found",
+			err:  errors.Wrap(mfxsdk.ErrCerts, mfxsdk.ErrNotFound),
+			code: ExitNotFound,
+		},
+		{
+			desc: "conflict",
+			err:  statusError(mfxsdk.ErrFailedCreation, http.StatusConflict),
+			code: ExitConflict,
+		},
+		{
+			desc: "typed conflict",
+			err:  errors.Wrap(mfxsdk.ErrCertsRevoke, mfxsdk.ErrCertRevoked),
+			code: ExitConflict,
+		},
+		{
+			desc: "server error",
+			err:  statusError(mfxsdk.ErrFailedUpdate, http.StatusInternalServerError),
+			code: ExitServerError,
+		},
+		{
+			desc: "unavailable service",
+			err:  statusError(mfxsdk.ErrFailedRemoval, http.StatusServiceUnavailable),
+			code: ExitServerError,
+		},
+		{
+			desc: "transport error",
+			err:  errors.New("dial tcp: connection refused"),
+			code: ExitFailure,
+		},
+	}
+
+	for _, tc := range cases {
+		code := ExitCode(tc.err)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected exit code %d got %d", tc.desc, tc.code, code))
+	}
+}
+
+func TestCommandExitCodes(t *testing.T) {
+	cases := []struct {
+		desc string
+		cmd  func() *cobra.Command
+		args []string
+		err  error
+		code int
+	}{
+		{
+			desc: "get thing",
+			cmd:  NewThingsCmd,
+			args: []string{"get", "001", userToken},
+			code: 0,
+		},
+		{
+			desc: "get thing with missing arguments",
+			cmd:  NewThingsCmd,
+			args: []string{"get", "001"},
+			code: ExitInvalidArgs,
+		},
+		{
+			desc: "create thing with malformed JSON",
+			cmd:  NewThingsCmd,
+			args: []string{"create", `{"name":`, userToken},
+			code: ExitInvalidArgs,
+		},
+		{
+			desc: "get thing with invalid token",
+			cmd:  NewThingsCmd,
+			args: []string{"get", "001", "invalid"},
+			err:  statusError(mfxsdk.ErrFailedFetch, http.StatusForbidden),
+			code: ExitUnauthorized,
+		},
+		{
+			desc: "get non-existing thing",
+			cmd:  NewThingsCmd,
+			args: []string{"get", "002", userToken},
+			code: ExitNotFound,
+		},
+		{
+			desc: "create existing thing",
+			cmd:  NewThingsCmd,
+			args: []string{"create", `{"name":"thing"}`, userToken},
+			code: ExitConflict,
+		},
+		{
+			desc: "get thing with failing service",
+			cmd:  NewThingsCmd,
+			args: []string{"get", "001", userToken},
+			err:  statusError(mfxsdk.ErrFailedFetch, http.StatusInternalServerError),
+			code: ExitServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newSDKMock()
+		mock.things = []mfxsdk.Thing{{ID: "001", Name: "thing", Key: "key"}}
+		mock.fetchErr = tc.err
+		SetSDK(mock)
+		out, code := runCmd(t, tc.cmd(), nil, tc.args...)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected exit code %d got %d: %s", tc.desc, tc.code, code, out))
+	}
+}
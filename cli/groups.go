@@ -6,6 +6,8 @@ package cli
 import (
 	"encoding/json"
 
+	"github.com/mainflux/mainflux/auth"
+	"github.com/mainflux/mainflux/pkg/errors"
 	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/spf13/cobra"
 )
@@ -26,13 +28,13 @@ var cmdGroups = []cobra.Command{
 		ParentID - ID of a group that is a parent to the creating group
 		Metadata - JSON structured string`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 			var group mfxsdk.Group
 			if err := json.Unmarshal([]byte(args[0]), &group); err != nil {
-				logError(err)
+				logError(errors.Wrap(errInvalidArgs, err))
 				return
 			}
 			id, err := sdk.CreateGroup(group, args[1])
@@ -65,7 +67,7 @@ var cmdGroups = []cobra.Command{
 					logError(err)
 					return
 				}
-				logJSON(l)
+				logGroups(l)
 				return
 			}
 			if args[0] == "children" {
@@ -78,7 +80,7 @@ var cmdGroups = []cobra.Command{
 					logError(err)
 					return
 				}
-				logJSON(l)
+				logGroups(l)
 				return
 			}
 			if args[0] == "parents" {
@@ -91,7 +93,7 @@ var cmdGroups = []cobra.Command{
 					logError(err)
 					return
 				}
-				logJSON(l)
+				logGroups(l)
 				return
 			}
 			if len(args) > 2 {
@@ -103,7 +105,7 @@ var cmdGroups = []cobra.Command{
 				logError(err)
 				return
 			}
-			logJSON(t)
+			logRecords(t, groupColumns, groupRows([]mfxsdk.Group{t}))
 		},
 	},
 	cobra.Command{
@@ -112,13 +114,13 @@ var cmdGroups = []cobra.Command{
 		Long: `Assign members to a group.
 				member_ids - '["member_id",...]`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 4 {
+			if args = withToken(args, 4); len(args) != 4 {
 				logUsage(cmd.Short)
 				return
 			}
 			var ids []string
 			if err := json.Unmarshal([]byte(args[0]), &ids); err != nil {
-				logError(err)
+				logError(errors.Wrap(errInvalidArgs, err))
 				return
 			}
 			if err := sdk.Assign(ids, args[1], args[2], args[3]); err != nil {
@@ -134,13 +136,13 @@ var cmdGroups = []cobra.Command{
 		Long: `Unassign members from a group
 				member_ids - '["member_id",...]`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 3 {
+			if args = withToken(args, 3); len(args) != 3 {
 				logUsage(cmd.Short)
 				return
 			}
 			var ids []string
 			if err := json.Unmarshal([]byte(args[0]), &ids); err != nil {
-				logError(err)
+				logError(errors.Wrap(errInvalidArgs, err))
 				return
 			}
 			if err := sdk.Unassign(args[2], args[1], ids...); err != nil {
@@ -155,7 +157,7 @@ var cmdGroups = []cobra.Command{
 		Short: "delete <group_id> <user_auth_token>",
 		Long:  `Delete group.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -171,7 +173,7 @@ var cmdGroups = []cobra.Command{
 		Short: "members <group_id> <user_auth_token>",
 		Long:  `Lists all members of a group.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -180,7 +182,7 @@ var cmdGroups = []cobra.Command{
 				logError(err)
 				return
 			}
-			logJSON(up)
+			logMembers(up)
 		},
 	},
 	cobra.Command{
@@ -188,7 +190,7 @@ var cmdGroups = []cobra.Command{
 		Short: "membership <member_id> <user_auth_token>",
 		Long:  `List member group's membership`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -197,7 +199,7 @@ var cmdGroups = []cobra.Command{
 				logError(err)
 				return
 			}
-			logJSON(up)
+			logRecords(up, groupColumns, groupRows(up.Groups))
 		},
 	},
 }
@@ -217,3 +219,55 @@ func NewGroupsCmd() *cobra.Command {
 	}
 	return &cmd
 }
+
+var (
+	groupColumns  = []string{"id", "name", "parent_id", "description", "metadata"}
+	memberColumns = []string{"id", "type"}
+)
+
+// membersPage is the group members page rendered by the CLI.
+type membersPage struct {
+	Total   uint64   `json:"total"`
+	Offset  uint64   `json:"offset"`
+	Limit   uint64   `json:"limit"`
+	Members []member `json:"members"`
+}
+
+type member struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+func groupRows(grs []mfxsdk.Group) [][]string {
+	rows := [][]string{}
+	for _, g := range grs {
+		rows = append(rows, []string{g.ID, g.Name, g.ParentID, g.Description, metadataValue(g.Metadata)})
+	}
+	return rows
+}
+
+// logGroups renders groups page using the SDK group fields.
+func logGroups(gp auth.GroupPage) {
+	page := mfxsdk.GroupsPage{Groups: []mfxsdk.Group{}}
+	page.Total, page.Offset, page.Limit = gp.Total, gp.Offset, gp.Limit
+	for _, g := range gp.Groups {
+		page.Groups = append(page.Groups, mfxsdk.Group{
+			ID:          g.ID,
+			Name:        g.Name,
+			Description: g.Description,
+			ParentID:    g.ParentID,
+			Metadata:    g.Metadata,
+		})
+	}
+	logRecords(page, groupColumns, groupRows(page.Groups))
+}
+
+func logMembers(mp auth.MemberPage) {
+	page := membersPage{Total: mp.Total, Offset: mp.Offset, Limit: mp.Limit, Members: []member{}}
+	rows := [][]string{}
+	for _, m := range mp.Members {
+		page.Members = append(page.Members, member{ID: m.ID, Type: m.Type})
+		rows = append(rows, []string{m.ID, m.Type})
+	}
+	logRecords(page, memberColumns, rows)
+}
@@ -0,0 +1,409 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+)
+
+// externalRefKey is the metadata key used to store manifest references
+// of the provisioned things and channels.
+const externalRefKey = "external_ref"
+
+const (
+	kindThing   = "thing"
+	kindChannel = "channel"
+)
+
+var (
+	errUnknownManifest = errors.New("unknown manifest file format")
+	errInvalidManifest = errors.New("invalid manifest")
+	errUnknownKind     = errors.New("unknown entry kind")
+	errMissingRef      = errors.New("missing entry reference")
+	errDuplicateRef    = errors.New("duplicate entry reference")
+	errUnknownChannel  = errors.New("unknown channel reference")
+	errNotCreated      = errors.New("entity not created")
+)
+
+type manifestThing struct {
+	Ref      string                 `json:"ref"`
+	Name     string                 `json:"name,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Channels []string               `json:"channels,omitempty"`
+	row      int
+}
+
+type manifestChannel struct {
+	Ref      string                 `json:"ref"`
+	Name     string                 `json:"name,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	row      int
+}
+
+// manifest describes things and channels to be provisioned. Things are
+// connected to the channels referenced in their channels list.
+type manifest struct {
+	Things   []manifestThing   `json:"things"`
+	Channels []manifestChannel `json:"channels"`
+}
+
+type provisionedThing struct {
+	Ref      string   `json:"ref"`
+	ID       string   `json:"id"`
+	Key      string   `json:"key"`
+	Name     string   `json:"name,omitempty"`
+	Channels []string `json:"channels,omitempty"`
+}
+
+type provisionedChannel struct {
+	Ref  string `json:"ref"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// provisioned contains generated IDs and keys of the manifest entries.
+type provisioned struct {
+	Things   []provisionedThing   `json:"things"`
+	Channels []provisionedChannel `json:"channels"`
+}
+
+// rowError describes failure of a single manifest entry. Row is the line
+// number for CSV manifests and the position in the things or channels
+// list for JSON manifests.
+type rowError struct {
+	Row int
+	Ref string
+	Err error
+}
+
+func (re rowError) Error() string {
+	return fmt.Sprintf("row %d (%s): %s", re.Row, re.Ref, re.Err)
+}
+
+// manifestFromFile reads a manifest from JSON or CSV file. Each CSV line
+// has the form: <thing|channel>,<ref>,<name>[,<channel_ref>...], where the
+// channel references are used only for things.
+func manifestFromFile(path string) (manifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return manifest{}, err
+	}
+	defer file.Close()
+
+	var m manifest
+	switch filepath.Ext(path) {
+	case csvExt:
+		if m, err = manifestFromCSV(file); err != nil {
+			return manifest{}, err
+		}
+	case jsonExt:
+		if err := json.NewDecoder(file).Decode(&m); err != nil {
+			return manifest{}, errors.Wrap(errInvalidManifest, err)
+		}
+		for i := range m.Things {
+			m.Things[i].row = i + 1
+		}
+		for i := range m.Channels {
+			m.Channels[i].row = i + 1
+		}
+	default:
+		return manifest{}, errUnknownManifest
+	}
+
+	return m, m.validate()
+}
+
+func manifestFromCSV(r io.Reader) (manifest, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var m manifest
+	for row := 1; ; row++ {
+		l, err := reader.Read()
+		if err == io.EOF {
+			return m, nil
+		}
+		if err != nil {
+			return manifest{}, errors.Wrap(errMalformedCSV, err)
+		}
+
+		if len(l) < 2 {
+			return manifest{}, rowError{Row: row, Err: errMalformedCSV}
+		}
+		name := ""
+		if len(l) > 2 {
+			name = l[2]
+		}
+
+		switch strings.TrimSpace(l[0]) {
+		case kindThing:
+			th := manifestThing{Ref: l[1], Name: name, row: row}
+			if len(l) > 3 {
+				for _, c := range l[3:] {
+					if c != "" {
+						th.Channels = append(th.Channels, c)
+					}
+				}
+			}
+			m.Things = append(m.Things, th)
+		case kindChannel:
+			m.Channels = append(m.Channels, manifestChannel{Ref: l[1], Name: name, row: row})
+		default:
+			return manifest{}, rowError{Row: row, Ref: l[1], Err: errUnknownKind}
+		}
+	}
+}
+
+func (m manifest) validate() error {
+	chs := map[string]bool{}
+	for _, c := range m.Channels {
+		if c.Ref == "" {
+			return rowError{Row: c.row, Err: errMissingRef}
+		}
+		if chs[c.Ref] {
+			return rowError{Row: c.row, Ref: c.Ref, Err: errDuplicateRef}
+		}
+		chs[c.Ref] = true
+	}
+
+	ths := map[string]bool{}
+	for _, t := range m.Things {
+		if t.Ref == "" {
+			return rowError{Row: t.row, Err: errMissingRef}
+		}
+		if ths[t.Ref] {
+			return rowError{Row: t.row, Ref: t.Ref, Err: errDuplicateRef}
+		}
+		ths[t.Ref] = true
+		for _, c := range t.Channels {
+			if !chs[c] {
+				return rowError{Row: t.row, Ref: t.Ref, Err: errors.Wrap(errUnknownChannel, errors.New(c))}
+			}
+		}
+	}
+
+	return nil
+}
+
+// provisioner creates manifest entries using the SDK. Entries already
+// provisioned, i.e. having the same external reference, are skipped.
+type provisioner struct {
+	sdk      mfxsdk.SDK
+	token    string
+	things   map[string]mfxsdk.Thing
+	channels map[string]mfxsdk.Channel
+}
+
+func newProvisioner(s mfxsdk.SDK, token string) (*provisioner, error) {
+	p := &provisioner{
+		sdk:      s,
+		token:    token,
+		things:   map[string]mfxsdk.Thing{},
+		channels: map[string]mfxsdk.Channel{},
+	}
+
+	ctx := context.Background()
+	ths, err := mfxsdk.NewThingsIterator(mfxsdk.ListThings(s, token, ""), 0).All(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range ths {
+		if ref, ok := t.Metadata[externalRefKey].(string); ok {
+			p.things[ref] = t
+		}
+	}
+
+	chs, err := mfxsdk.NewChannelsIterator(mfxsdk.ListChannels(s, token, ""), 0).All(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range chs {
+		if ref, ok := c.Metadata[externalRefKey].(string); ok {
+			p.channels[ref] = c
+		}
+	}
+
+	return p, nil
+}
+
+// plan writes the list of actions required to provision the manifest.
+func (p *provisioner) plan(m manifest, w io.Writer) {
+	for _, c := range m.Channels {
+		action := "create"
+		if _, ok := p.channels[c.Ref]; ok {
+			action = "skip"
+		}
+		fmt.Fprintf(w, "%s channel %s\n", action, c.Ref)
+	}
+	for _, t := range m.Things {
+		action := "create"
+		if _, ok := p.things[t.Ref]; ok {
+			action = "skip"
+		}
+		fmt.Fprintf(w, "%s thing %s\n", action, t.Ref)
+		for _, c := range t.Channels {
+			fmt.Fprintf(w, "connect thing %s to channel %s\n", t.Ref, c)
+		}
+	}
+}
+
+// provision creates manifest entries and returns the provisioned ones
+// together with the errors of the failed entries.
+func (p *provisioner) provision(m manifest) (provisioned, []rowError) {
+	var errs []rowError
+
+	var newChs []mfxsdk.Channel
+	var newChRows []manifestChannel
+	for _, c := range m.Channels {
+		if _, ok := p.channels[c.Ref]; ok {
+			continue
+		}
+		newChs = append(newChs, mfxsdk.Channel{Name: c.Name, Metadata: withRef(c.Metadata, c.Ref)})
+		newChRows = append(newChRows, c)
+	}
+	if len(newChs) > 0 {
+		created, err := p.sdk.CreateChannels(newChs, p.token)
+		if err == nil && len(created) != len(newChs) {
+			err = errNotCreated
+		}
+		for i, c := range newChRows {
+			if err != nil {
+				errs = append(errs, rowError{Row: c.row, Ref: c.Ref, Err: err})
+				continue
+			}
+			p.channels[c.Ref] = created[i]
+		}
+	}
+
+	var newThs []mfxsdk.Thing
+	var newThRows []manifestThing
+	for _, t := range m.Things {
+		if _, ok := p.things[t.Ref]; ok {
+			continue
+		}
+		newThs = append(newThs, mfxsdk.Thing{Name: t.Name, Metadata: withRef(t.Metadata, t.Ref)})
+		newThRows = append(newThRows, t)
+	}
+	created := map[string]bool{}
+	if len(newThs) > 0 {
+		ths, err := p.sdk.CreateThings(newThs, p.token)
+		if err == nil && len(ths) != len(newThs) {
+			err = errNotCreated
+		}
+		for i, t := range newThRows {
+			if err != nil {
+				errs = append(errs, rowError{Row: t.row, Ref: t.Ref, Err: err})
+				continue
+			}
+			p.things[t.Ref] = ths[i]
+			created[t.Ref] = true
+		}
+	}
+
+	res := provisioned{
+		Things:   []provisionedThing{},
+		Channels: []provisionedChannel{},
+	}
+	for _, c := range m.Channels {
+		if ch, ok := p.channels[c.Ref]; ok {
+			res.Channels = append(res.Channels, provisionedChannel{Ref: c.Ref, ID: ch.ID, Name: ch.Name})
+		}
+	}
+	for _, t := range m.Things {
+		th, ok := p.things[t.Ref]
+		if !ok {
+			continue
+		}
+		chIDs, err := p.connect(th, t, created[t.Ref])
+		if err != nil {
+			errs = append(errs, rowError{Row: t.row, Ref: t.Ref, Err: err})
+		}
+		res.Things = append(res.Things, provisionedThing{Ref: t.Ref, ID: th.ID, Key: th.Key, Name: th.Name, Channels: chIDs})
+	}
+
+	return res, errs
+}
+
+// connect connects the thing to the manifest channels it's not connected
+// to yet and returns IDs of all its manifest channels.
+func (p *provisioner) connect(th mfxsdk.Thing, t manifestThing, created bool) ([]string, error) {
+	connected := map[string]bool{}
+	if !created {
+		chs, err := mfxsdk.NewChannelsIterator(mfxsdk.ListChannelsByThing(p.sdk, p.token, th.ID, false), 0).All(context.Background(), 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range chs {
+			connected[c.ID] = true
+		}
+	}
+
+	var ids, pending []string
+	for _, ref := range t.Channels {
+		ch, ok := p.channels[ref]
+		if !ok {
+			return ids, errors.Wrap(errUnknownChannel, errors.New(ref))
+		}
+		ids = append(ids, ch.ID)
+		if !connected[ch.ID] {
+			pending = append(pending, ch.ID)
+		}
+	}
+	if len(pending) == 0 {
+		return ids, nil
+	}
+
+	conns := mfxsdk.ConnectionIDs{
+		ThingIDs:   []string{th.ID},
+		ChannelIDs: pending,
+	}
+
+	return ids, p.sdk.Connect(conns, p.token)
+}
+
+func withRef(metadata map[string]interface{}, ref string) map[string]interface{} {
+	md := map[string]interface{}{}
+	for k, v := range metadata {
+		md[k] = v
+	}
+	md[externalRefKey] = ref
+	return md
+}
+
+// writeProvisioned writes provisioned entries as JSON or CSV, depending on
+// the file extension. CSV lines have the form: <kind>,<ref>,<id>,<key>.
+func writeProvisioned(path string, res provisioned) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch filepath.Ext(path) {
+	case csvExt:
+		w := csv.NewWriter(file)
+		for _, c := range res.Channels {
+			w.Write([]string{kindChannel, c.Ref, c.ID, ""})
+		}
+		for _, t := range res.Things {
+			w.Write([]string{kindThing, t.Ref, t.ID, t.Key})
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		enc := json.NewEncoder(file)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+}
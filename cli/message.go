@@ -3,48 +3,232 @@
 
 package cli
 
-import "github.com/spf13/cobra"
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/senml"
+	"github.com/spf13/cobra"
+)
 
 const contentTypeSenml = "application/senml+json"
 
-var cmdMessages = []cobra.Command{
-	cobra.Command{
+var (
+	errInvalidPair   = errors.New("invalid SenML pair, expected name=value")
+	errInvalidRepeat = errors.New("repeat count must be greater than zero")
+	errPayloadSource = errors.New("payload must be given either as argument, --file or --senml")
+)
+
+// sendOptions describe the payload source and publishing loop of the
+// send command.
+type sendOptions struct {
+	file     string
+	senml    []string
+	repeat   int
+	interval time.Duration
+}
+
+func newSendMessageCmd() *cobra.Command {
+	var opts sendOptions
+
+	cmd := cobra.Command{
 		Use:   "send",
-		Short: "send <channel_id>[.<subtopic>...] <JSON_string> <thing_key>",
-		Long:  `Sends message on the channel`,
+		Short: "send <channel_id>[.<subtopic>...] [<payload> | -] <thing_key> [--file=<path>] [--senml=<name>=<value>,...] [--repeat=N] [--interval=1s]",
+		Long: `Sends message on the channel. The payload is given inline, read from the
+standard input if it is "-", read from a file with --file or rendered as
+SenML pack with the current time from name=value pairs with --senml`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 3 {
+			inline := opts.file == "" && len(opts.senml) == 0
+			if (inline && len(args) != 3) || (!inline && len(args) != 2) {
 				logUsage(cmd.Short)
 				return
 			}
+			if opts.repeat < 1 {
+				logError(errInvalidRepeat)
+				return
+			}
 
-			if err := sdk.SendMessage(args[0], args[1], args[2]); err != nil {
+			payload, err := opts.payload(cmd, args)
+			if err != nil {
 				logError(err)
 				return
 			}
+			if len(opts.senml) > 0 {
+				if err := sdk.SetContentType(mfxsdk.CTJSONSenML); err != nil {
+					logError(err)
+					return
+				}
+			}
+
+			chanName, key := args[0], args[len(args)-1]
+			for i := 0; i < opts.repeat; i++ {
+				if i > 0 {
+					time.Sleep(opts.interval)
+				}
+				msg, err := payload(time.Now())
+				if err != nil {
+					logError(err)
+					return
+				}
+				if err := sdk.SendMessage(chanName, msg, key); err != nil {
+					logError(err)
+					return
+				}
+			}
 
 			logOK()
 		},
-	},
-	cobra.Command{
+	}
+
+	cmd.Flags().StringVar(&opts.file, "file", "", "file to read the payload from")
+	cmd.Flags().StringSliceVar(&opts.senml, "senml", nil, "name=value pairs rendered as SenML records")
+	cmd.Flags().IntVar(&opts.repeat, "repeat", 1, "number of times to send the message")
+	cmd.Flags().DurationVar(&opts.interval, "interval", time.Second, "interval between repeated messages")
+
+	return &cmd
+}
+
+// payload returns function rendering the message sent at the given time.
+// File and standard input payloads are read once and sent as they are.
+func (opts sendOptions) payload(cmd *cobra.Command, args []string) (func(time.Time) (string, error), error) {
+	if len(opts.senml) > 0 {
+		if opts.file != "" {
+			return nil, errPayloadSource
+		}
+		if _, err := senmlPayload(opts.senml, time.Now()); err != nil {
+			return nil, err
+		}
+		return func(t time.Time) (string, error) {
+			return senmlPayload(opts.senml, t)
+		}, nil
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case opts.file != "":
+		data, err = ioutil.ReadFile(opts.file)
+	case args[1] == "-":
+		data, err = ioutil.ReadAll(cmd.InOrStdin())
+	default:
+		data = []byte(args[1])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	msg := string(data)
+	return func(time.Time) (string, error) {
+		return msg, nil
+	}, nil
+}
+
+// senmlPayload renders name=value pairs as SenML pack with records
+// timestamped with the given time. Numeric values are sent as values,
+// true and false as boolean values and anything else as string values.
+func senmlPayload(pairs []string, t time.Time) (string, error) {
+	ts := float64(t.UnixNano()) / 1e9
+
+	var pack senml.Pack
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return "", errors.Wrap(errInvalidPair, errors.New(p))
+		}
+
+		r := senml.Record{Name: kv[0], Time: ts}
+		switch v := kv[1]; {
+		case v == "true" || v == "false":
+			b := v == "true"
+			r.BoolValue = &b
+		default:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				r.Value = &f
+				break
+			}
+			r.StringValue = &v
+		}
+		pack.Records = append(pack.Records, r)
+	}
+
+	if err := senml.Validate(pack); err != nil {
+		return "", err
+	}
+	data, err := senml.Encode(pack, senml.JSON)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func newReadMessagesCmd() *cobra.Command {
+	var opts readOptions
+
+	cmd := cobra.Command{
 		Use:   "read",
-		Short: "read <channel_id>[.<subtopic>...] <thing_key>",
-		Long:  `Reads all channel messages`,
+		Short: "read <channel_id>[.<subtopic>...] <thing_key> [--subtopic=<subtopic>] [--publisher=<publisher>] [--from=<time>] [--to=<time>] [--follow]",
+		Long: `Reads channel messages. Time filters are RFC3339 timestamps or durations relative
+to now, e.g. -1h. Use --limit=0 to read all matching messages.
+
+The reader has no subscription API, so --follow polls it. Every --interval it
+reads the messages from the time of the latest message printed so far, ignoring
+--to. The messages with that time which were already printed are skipped, so
+every message is printed once, in time order. Press Ctrl+C to stop`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
-			m, err := sdk.ReadMessages(args[0], args[1])
+			if opts.format == "" {
+				opts.format = Format
+			}
+
+			q, err := opts.query(args[0], time.Now())
 			if err != nil {
 				logError(err)
 				return
 			}
+			chanID := strings.SplitN(args[0], ".", 2)[0]
 
-			logJSON(m)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			c := make(chan os.Signal, 1)
+			signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+			defer signal.Stop(c)
+			go func() {
+				select {
+				case <-c:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+
+			r := newMessagesReader(sdk, chanID, args[1], q, uint64(Limit))
+			if err := r.read(ctx, os.Stdout, opts); err != nil {
+				logError(err)
+				return
+			}
 		},
-	},
+	}
+
+	cmd.Flags().StringVar(&opts.subtopic, "subtopic", "", "subtopic filter")
+	cmd.Flags().StringVar(&opts.publisher, "publisher", "", "publisher filter")
+	cmd.Flags().StringVar(&opts.from, "from", "", "lower time boundary, RFC3339 or relative to now, e.g. -1h")
+	cmd.Flags().StringVar(&opts.to, "to", "", "upper time boundary, RFC3339 or relative to now, e.g. -5m")
+	cmd.Flags().StringVar(&opts.format, "format", "", "output format: json, table or csv, defaults to --output")
+	cmd.Flags().BoolVar(&opts.follow, "follow", false, "keep polling the reader for new messages")
+	cmd.Flags().DurationVar(&opts.interval, "interval", time.Second, "interval between the reader polls with --follow")
+
+	return &cmd
 }
 
 // NewMessagesCmd returns messages command.
@@ -58,9 +242,8 @@ func NewMessagesCmd() *cobra.Command {
 		},
 	}
 
-	for i := range cmdMessages {
-		cmd.AddCommand(&cmdMessages[i])
-	}
+	cmd.AddCommand(newSendMessageCmd())
+	cmd.AddCommand(newReadMessagesCmd())
 
 	return &cmd
 }
@@ -0,0 +1,127 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const thingKey = "thing-key"
+
+func runSendCmd(t *testing.T, stdin []byte, args ...string) string {
+	out, _ := runCmd(t, newSendMessageCmd(), bytes.NewReader(stdin), args...)
+	return out
+}
+
+func TestSendMessageCmd(t *testing.T) {
+	binary := []byte{0x00, 0xff, 0xfe, '\n', 0x80, 0x01}
+	file := filepath.Join(tempDir(t), "payload.bin")
+	err := ioutil.WriteFile(file, binary, 0600)
+	require.Nil(t, err, fmt.Sprintf("unexpected error writing payload: %s", err))
+
+	cases := []struct {
+		desc    string
+		args    []string
+		stdin   []byte
+		payload string
+	}{
+		{
+			desc:    "send inline payload",
+			args:    []string{"ch.sub", `[{"n":"temp","v":21}]`, thingKey},
+			payload: `[{"n":"temp","v":21}]`,
+		},
+		{
+			desc:    "send payload from stdin",
+			args:    []string{"ch.sub", "-", thingKey},
+			stdin:   binary,
+			payload: string(binary),
+		},
+		{
+			desc:    "send payload from file",
+			args:    []string{"ch.sub", thingKey, "--file", file},
+			payload: string(binary),
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newSDKMock()
+		SetSDK(mock)
+		out := runSendCmd(t, tc.stdin, tc.args...)
+		assert.Contains(t, out, "ok", fmt.Sprintf("%s: unexpected output %s", tc.desc, out))
+		require.Len(t, mock.sent, 1, fmt.Sprintf("%s: expected one message to be sent", tc.desc))
+		expected := sentMessage{chanID: "ch.sub", payload: tc.payload, key: thingKey}
+		assert.Equal(t, expected, mock.sent[0], fmt.Sprintf("%s: expected message %v got %v", tc.desc, expected, mock.sent[0]))
+	}
+}
+
+func TestSendSenMLCmd(t *testing.T) {
+	mock := newSDKMock()
+	SetSDK(mock)
+
+	before := time.Now()
+	runSendCmd(t, nil, "ch", thingKey, "--senml=temp=21.5,on=true", "--senml", "status=ok")
+	after := time.Now()
+
+	require.Len(t, mock.sent, 1, "expected one message to be sent")
+	assert.Equal(t, mfxsdk.CTJSONSenML, mock.sent[0].contentType, "expected SenML content type")
+
+	pack, err := senml.Decode([]byte(mock.sent[0].payload), senml.JSON)
+	require.Nil(t, err, fmt.Sprintf("unexpected error decoding SenML: %s", err))
+	require.Len(t, pack.Records, 3, "expected a record per pair")
+
+	v, b, s := 21.5, true, "ok"
+	expected := []senml.Record{
+		{Name: "temp", Value: &v},
+		{Name: "on", BoolValue: &b},
+		{Name: "status", StringValue: &s},
+	}
+	for i, r := range pack.Records {
+		ts := time.Unix(0, int64(r.Time*1e9))
+		assert.False(t, ts.Before(before.Add(-time.Millisecond)) || ts.After(after.Add(time.Millisecond)), fmt.Sprintf("record %d: unexpected time %s", i, ts))
+		r.Time = 0
+		assert.Equal(t, expected[i], r, fmt.Sprintf("record %d: expected %v got %v", i, expected[i], r))
+	}
+
+	mock = newSDKMock()
+	SetSDK(mock)
+	out := runSendCmd(t, nil, "ch", thingKey, "--senml=temp")
+	assert.Contains(t, out, errInvalidPair.Error(), fmt.Sprintf("invalid pair: unexpected output %s", out))
+	assert.Empty(t, mock.sent, "expected no message to be sent for invalid pair")
+}
+
+func TestSendRepeatCmd(t *testing.T) {
+	mock := newSDKMock()
+	SetSDK(mock)
+
+	start := time.Now()
+	runSendCmd(t, nil, "ch", thingKey, "--senml=temp=1", "--repeat=3", "--interval=20ms")
+	elapsed := time.Since(start)
+
+	require.Len(t, mock.sent, 3, "expected message to be sent three times")
+	assert.True(t, elapsed >= 40*time.Millisecond, fmt.Sprintf("expected messages to be spaced by interval, took %s", elapsed))
+
+	var times []float64
+	for _, m := range mock.sent {
+		pack, err := senml.Decode([]byte(m.payload), senml.JSON)
+		require.Nil(t, err, fmt.Sprintf("unexpected error decoding SenML: %s", err))
+		times = append(times, pack.Records[0].Time)
+	}
+	assert.True(t, times[0] < times[1] && times[1] < times[2], fmt.Sprintf("expected each message to be timestamped when sent, got %v", times))
+
+	mock = newSDKMock()
+	SetSDK(mock)
+	out := runSendCmd(t, nil, "ch", "payload", thingKey, "--repeat=0")
+	assert.Contains(t, out, errInvalidRepeat.Error(), fmt.Sprintf("invalid repeat: unexpected output %s", out))
+	assert.Empty(t, mock.sent, "expected no message to be sent for invalid repeat")
+}
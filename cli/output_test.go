@@ -0,0 +1,86 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"testing"
+
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputFormats(t *testing.T) {
+	defer func() { Format = formatJSON }()
+
+	cases := []struct {
+		desc   string
+		cmd    func() *cobra.Command
+		args   []string
+		format string
+		golden string
+	}{
+		{
+			desc:   "list things as JSON",
+			cmd:    NewThingsCmd,
+			args:   []string{"get", "all", userToken},
+			format: formatJSON,
+			golden: "things_json.golden",
+		},
+		{
+			desc:   "list things as table",
+			cmd:    NewThingsCmd,
+			args:   []string{"get", "all", userToken},
+			format: formatTable,
+			golden: "things_table.golden",
+		},
+		{
+			desc:   "list things as CSV",
+			cmd:    NewThingsCmd,
+			args:   []string{"get", "all", userToken},
+			format: formatCSV,
+			golden: "things_csv.golden",
+		},
+		{
+			desc:   "get thing as JSON",
+			cmd:    NewThingsCmd,
+			args:   []string{"get", "001", userToken},
+			format: formatJSON,
+			golden: "thing_json.golden",
+		},
+		{
+			desc:   "list channels as table",
+			cmd:    NewChannelsCmd,
+			args:   []string{"get", "all", userToken},
+			format: formatTable,
+			golden: "channels_table.golden",
+		},
+		{
+			desc:   "get channel as JSON",
+			cmd:    NewChannelsCmd,
+			args:   []string{"get", "003", userToken},
+			format: formatJSON,
+			golden: "channel_json.golden",
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newSDKMock()
+		mock.things = []mfxsdk.Thing{
+			{ID: "001", Name: "sensor", Key: "key-001", Metadata: map[string]interface{}{"room": "kitchen"}},
+			{ID: "002", Name: "gateway", Key: "key-002"},
+		}
+		mock.channels = []mfxsdk.Channel{
+			{ID: "003", Name: "telemetry", Metadata: map[string]interface{}{"qos": 1}},
+			{ID: "004", Name: "commands"},
+		}
+		SetSDK(mock)
+		Format = tc.format
+
+		out, code := runCmd(t, tc.cmd(), nil, tc.args...)
+		assert.Equal(t, 0, code, fmt.Sprintf("%s: unexpected exit code %d", tc.desc, code))
+		assertGolden(t, tc.golden, []byte(out))
+	}
+}
@@ -17,7 +17,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var errMalformedCSV = errors.New("malformed CSV")
+var (
+	errMalformedCSV     = errors.New("malformed CSV")
+	errPartialProvision = errors.New("failed to provision some of the manifest entries")
+)
 
 const jsonExt = ".json"
 const csvExt = ".csv"
@@ -28,7 +31,7 @@ var cmdProvision = []cobra.Command{
 		Short: "things <things_file> <user_token>",
 		Long:  `Bulk create things`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -50,7 +53,7 @@ var cmdProvision = []cobra.Command{
 				return
 			}
 
-			logJSON(things)
+			logRecords(things, thingColumns, thingRows(things))
 		},
 	},
 	cobra.Command{
@@ -58,7 +61,7 @@ var cmdProvision = []cobra.Command{
 		Short: "channels <channels_file> <user_token>",
 		Long:  `Bulk create channels`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -75,7 +78,7 @@ var cmdProvision = []cobra.Command{
 				return
 			}
 
-			logJSON(channels)
+			logRecords(channels, channelColumns, channelRows(channels))
 		},
 	},
 	cobra.Command{
@@ -83,7 +86,7 @@ var cmdProvision = []cobra.Command{
 		Short: "connect <connections_file> <user_token>",
 		Long:  `Bulk connect things to channels`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -193,12 +196,30 @@ var cmdProvision = []cobra.Command{
 
 // NewProvisionCmd returns provision command.
 func NewProvisionCmd() *cobra.Command {
+	var file, out string
+	var dryRun bool
+
 	cmd := cobra.Command{
 		Use:   "provision",
-		Short: "Provision things and channels from a config file",
-		Long:  `Provision things and channels: use json or csv file to bulk provision things and channels`,
+		Short: "provision --file <manifest_file> [--out <output_file>] [--dry-run] <user_token>",
+		Long: `Provision things and channels: use json or csv file to bulk provision things and channels, \
+				or a manifest file to provision things, channels and their connections`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 1); len(args) != 1 || file == "" {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if err := provisionManifest(file, out, args[0], dryRun, os.Stdout); err != nil {
+				logError(err)
+			}
+		},
 	}
 
+	cmd.Flags().StringVar(&file, "file", "", "manifest file (json or csv)")
+	cmd.Flags().StringVar(&out, "out", "provisioned.json", "output file with generated IDs and keys (json or csv)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "validate the manifest and print the plan without provisioning")
+
 	for i := range cmdProvision {
 		cmd.AddCommand(&cmdProvision[i])
 	}
@@ -343,3 +364,34 @@ func connectionsFromFile(path string) (mfxsdk.ConnectionIDs, error) {
 
 	return connections, nil
 }
+
+func provisionManifest(file, out, token string, dryRun bool, w io.Writer) error {
+	m, err := manifestFromFile(file)
+	if err != nil {
+		return err
+	}
+
+	p, err := newProvisioner(sdk, token)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		p.plan(m, w)
+		return nil
+	}
+
+	res, errs := p.provision(m)
+	if err := writeProvisioned(out, res); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Fprintln(w, e.Error())
+		}
+		return errPartialProvision
+	}
+
+	return nil
+}
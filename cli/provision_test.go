@@ -0,0 +1,191 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userToken = "token"
+
+var update = flag.Bool("update", false, "update golden files")
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	golden := filepath.Join("testdata", name)
+	if *update {
+		err := ioutil.WriteFile(golden, actual, 0644)
+		require.Nil(t, err, fmt.Sprintf("unexpected error updating golden file %s: %s", name, err))
+	}
+	expected, err := ioutil.ReadFile(golden)
+	require.Nil(t, err, fmt.Sprintf("unexpected error reading golden file %s: %s", name, err))
+	assert.Equal(t, string(expected), string(actual), fmt.Sprintf("%s: output doesn't match golden file", name))
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "mainflux-cli")
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating temp dir: %s", err))
+	return dir
+}
+
+func TestManifestFromFile(t *testing.T) {
+	cases := []struct {
+		desc     string
+		path     string
+		things   []string
+		channels []string
+		err      error
+	}{
+		{
+			desc:     "read CSV manifest",
+			path:     "testdata/manifest.csv",
+			things:   []string{"dev-1", "dev-2"},
+			channels: []string{"ch-temp", "ch-hum"},
+			err:      nil,
+		},
+		{
+			desc:     "read CSV manifest with short rows",
+			path:     "testdata/manifest_short.csv",
+			things:   []string{"dev-1", "dev-2"},
+			channels: []string{"ch-temp", "ch-hum"},
+			err:      nil,
+		},
+		{
+			desc:     "read JSON manifest",
+			path:     "testdata/manifest.json",
+			things:   []string{"dev-1", "dev-2"},
+			channels: []string{"ch-temp", "ch-hum"},
+			err:      nil,
+		},
+		{
+			desc: "read manifest with unknown channel reference",
+			path: "testdata/manifest_invalid.csv",
+			err:  errUnknownChannel,
+		},
+		{
+			desc: "read manifest with unknown format",
+			path: "testdata/plan.golden",
+			err:  errUnknownManifest,
+		},
+	}
+
+	for _, tc := range cases {
+		m, err := manifestFromFile(tc.path)
+		if tc.err != nil {
+			re, ok := err.(rowError)
+			if ok {
+				err = re.Err
+			}
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+			continue
+		}
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		var things, channels []string
+		for _, th := range m.Things {
+			things = append(things, th.Ref)
+		}
+		for _, ch := range m.Channels {
+			channels = append(channels, ch.Ref)
+		}
+		assert.Equal(t, tc.things, things, fmt.Sprintf("%s: expected things %v got %v", tc.desc, tc.things, things))
+		assert.Equal(t, tc.channels, channels, fmt.Sprintf("%s: expected channels %v got %v", tc.desc, tc.channels, channels))
+		assert.Equal(t, []string{"ch-temp", "ch-hum"}, m.Things[0].Channels, fmt.Sprintf("%s: unexpected thing channels", tc.desc))
+	}
+}
+
+func TestProvisionManifest(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		desc   string
+		file   string
+		out    string
+		golden string
+	}{
+		{
+			desc:   "provision CSV manifest",
+			file:   "testdata/manifest.csv",
+			out:    "provisioned.csv",
+			golden: "provisioned.csv.golden",
+		},
+		{
+			desc:   "provision JSON manifest",
+			file:   "testdata/manifest.json",
+			out:    "provisioned.json",
+			golden: "provisioned.json.golden",
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newSDKMock()
+		SetSDK(mock)
+		out := filepath.Join(dir, tc.out)
+
+		var buf bytes.Buffer
+		err := provisionManifest(tc.file, out, userToken, false, &buf)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		data, err := ioutil.ReadFile(out)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error reading output %s", tc.desc, err))
+		assertGolden(t, tc.golden, data)
+		conns := []string{"003-001", "003-002", "004-001"}
+		assert.Equal(t, conns, mock.connections(), fmt.Sprintf("%s: unexpected connections", tc.desc))
+
+		// Rerun must skip already provisioned entries.
+		err = provisionManifest(tc.file, out, userToken, false, &buf)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error on rerun %s", tc.desc, err))
+		assert.Len(t, mock.things, 2, fmt.Sprintf("%s: expected 2 things after rerun got %d", tc.desc, len(mock.things)))
+		assert.Len(t, mock.channels, 2, fmt.Sprintf("%s: expected 2 channels after rerun got %d", tc.desc, len(mock.channels)))
+		data, err = ioutil.ReadFile(out)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error reading output %s", tc.desc, err))
+		assertGolden(t, tc.golden, data)
+		assert.Empty(t, buf.String(), fmt.Sprintf("%s: expected no errors reported got %s", tc.desc, buf.String()))
+	}
+}
+
+func TestProvisionManifestDryRun(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	mock := newSDKMock()
+	SetSDK(mock)
+	out := filepath.Join(dir, "provisioned.json")
+
+	var buf bytes.Buffer
+	err := provisionManifest("testdata/manifest.csv", out, userToken, true, &buf)
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assertGolden(t, "plan.golden", buf.Bytes())
+	assert.Empty(t, mock.things, "dry run: expected no things to be created")
+	assert.Empty(t, mock.channels, "dry run: expected no channels to be created")
+	_, err = os.Stat(out)
+	assert.True(t, os.IsNotExist(err), "dry run: expected no output file to be written")
+}
+
+func TestProvisionManifestPartialFailure(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	mock := newSDKMock()
+	mock.thingsErr = errors.New("service unavailable")
+	SetSDK(mock)
+	out := filepath.Join(dir, "provisioned.json")
+
+	var buf bytes.Buffer
+	err := provisionManifest("testdata/manifest.csv", out, userToken, false, &buf)
+	assert.Equal(t, errPartialProvision, err, fmt.Sprintf("expected error %s got %s", errPartialProvision, err))
+	assertGolden(t, "partial.golden", buf.Bytes())
+	data, err := ioutil.ReadFile(out)
+	require.Nil(t, err, fmt.Sprintf("unexpected error reading output %s", err))
+	assertGolden(t, "partial.json.golden", data)
+}
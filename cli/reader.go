@@ -0,0 +1,327 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+const (
+	formatJSON  = "json"
+	formatTable = "table"
+	formatCSV   = "csv"
+
+	readPageSize = 100
+)
+
+var (
+	errInvalidTime   = errors.New("invalid time, expected RFC3339 timestamp or duration")
+	errInvalidFormat = errors.New("invalid output format")
+)
+
+var messageColumns = []string{"time", "publisher", "subtopic", "name", "value", "unit"}
+
+type readOptions struct {
+	subtopic  string
+	publisher string
+	from      string
+	to        string
+	format    string
+	follow    bool
+	interval  time.Duration
+}
+
+// query returns messages query for the given channel name, which may
+// contain the subtopic in the <channel_id>.<subtopic> form.
+func (o readOptions) query(chanName string, now time.Time) (mfxsdk.MessagesQuery, error) {
+	q := mfxsdk.MessagesQuery{
+		Subtopic:  o.subtopic,
+		Publisher: o.publisher,
+	}
+	if parts := strings.SplitN(chanName, ".", 2); len(parts) == 2 && q.Subtopic == "" {
+		q.Subtopic = strings.Replace(parts[1], ".", "/", -1)
+	}
+
+	var err error
+	if q.From, err = parseTime(o.from, now); err != nil {
+		return mfxsdk.MessagesQuery{}, err
+	}
+	if q.To, err = parseTime(o.to, now); err != nil {
+		return mfxsdk.MessagesQuery{}, err
+	}
+
+	return q, nil
+}
+
+// parseTime converts RFC3339 timestamp or duration relative to now
+// into seconds since the Unix epoch. Empty string yields zero.
+func parseTime(s string, now time.Time) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return toSeconds(now.Add(d)), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, errors.Wrap(errInvalidTime, errors.New(s))
+	}
+
+	return toSeconds(t), nil
+}
+
+func toSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+func fromSeconds(s float64) time.Time {
+	sec, frac := math.Modf(s)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))).UTC()
+}
+
+// messagesRenderer writes messages to the output as soon as they're read.
+type messagesRenderer interface {
+	render(msgs []senml.Message) error
+}
+
+func newMessagesRenderer(format string, w io.Writer) (messagesRenderer, error) {
+	switch format {
+	case formatJSON:
+		return jsonRenderer{enc: json.NewEncoder(w)}, nil
+	case formatTable:
+		return &tableRenderer{w: w}, nil
+	case formatCSV:
+		return &csvRenderer{w: csv.NewWriter(w)}, nil
+	default:
+		return nil, errors.Wrap(errInvalidFormat, errors.New(format))
+	}
+}
+
+// jsonRenderer writes one JSON object per line.
+type jsonRenderer struct {
+	enc *json.Encoder
+}
+
+func (jr jsonRenderer) render(msgs []senml.Message) error {
+	for _, m := range msgs {
+		if err := jr.enc.Encode(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type tableRenderer struct {
+	w      io.Writer
+	header bool
+}
+
+func (tr *tableRenderer) render(msgs []senml.Message) error {
+	tw := tabwriter.NewWriter(tr.w, 0, 0, 2, ' ', 0)
+	if !tr.header {
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(messageColumns, "\t")))
+		tr.header = true
+	}
+	for _, m := range msgs {
+		fmt.Fprintln(tw, strings.Join(messageRow(m), "\t"))
+	}
+	return tw.Flush()
+}
+
+type csvRenderer struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (cr *csvRenderer) render(msgs []senml.Message) error {
+	if !cr.header {
+		cr.w.Write(messageColumns)
+		cr.header = true
+	}
+	for _, m := range msgs {
+		cr.w.Write(messageRow(m))
+	}
+	cr.w.Flush()
+	return cr.w.Error()
+}
+
+func messageRow(m senml.Message) []string {
+	return []string{
+		fromSeconds(m.Time).Format(time.RFC3339Nano),
+		m.Publisher,
+		m.Subtopic,
+		m.Name,
+		messageValue(m),
+		m.Unit,
+	}
+}
+
+func messageValue(m senml.Message) string {
+	switch {
+	case m.Value != nil:
+		return strconv.FormatFloat(*m.Value, 'f', -1, 64)
+	case m.StringValue != nil:
+		return *m.StringValue
+	case m.BoolValue != nil:
+		return strconv.FormatBool(*m.BoolValue)
+	case m.DataValue != nil:
+		return *m.DataValue
+	case m.Sum != nil:
+		return strconv.FormatFloat(*m.Sum, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// messagesReader reads messages page by page, rendering each page as soon
+// as it's received so that large exports are not buffered in memory.
+type messagesReader struct {
+	sdk    mfxsdk.SDK
+	chanID string
+	token  string
+	query  mfxsdk.MessagesQuery
+	limit  uint64
+	last   float64
+	seen   map[string]bool
+}
+
+func newMessagesReader(s mfxsdk.SDK, chanID, token string, q mfxsdk.MessagesQuery, limit uint64) *messagesReader {
+	return &messagesReader{
+		sdk:    s,
+		chanID: chanID,
+		token:  token,
+		query:  q,
+		limit:  limit,
+		seen:   map[string]bool{},
+	}
+}
+
+func (mr *messagesReader) read(ctx context.Context, w io.Writer, opts readOptions) error {
+	rend, err := newMessagesRenderer(opts.format, w)
+	if err != nil {
+		return err
+	}
+
+	if err := mr.readPages(ctx, mr.query, mr.limit, rend.render); err != nil {
+		return err
+	}
+	if !opts.follow {
+		return nil
+	}
+
+	return mr.follow(ctx, rend, opts.interval)
+}
+
+// readPages reads up to limit messages matching the query, or all of them
+// if limit is zero, and passes every page to the handler.
+func (mr *messagesReader) readPages(ctx context.Context, q mfxsdk.MessagesQuery, limit uint64, handle func([]senml.Message) error) error {
+	var n uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+
+		size := uint64(readPageSize)
+		if limit > 0 && limit-n < size {
+			size = limit - n
+		}
+		q.Limit = size
+
+		mp, err := mr.sdk.QueryMessages(mr.chanID, mr.token, q)
+		if err != nil {
+			return err
+		}
+		for _, m := range mp.Messages {
+			mr.track(m)
+		}
+		if err := handle(mp.Messages); err != nil {
+			return err
+		}
+
+		got := uint64(len(mp.Messages))
+		n += got
+		q.Offset += got
+		if got == 0 || got < size || q.Offset >= mp.Total || (limit > 0 && n >= limit) {
+			return nil
+		}
+	}
+}
+
+// follow periodically reads messages newer than the last seen one and
+// renders them in chronological order until the context is canceled.
+func (mr *messagesReader) follow(ctx context.Context, rend messagesRenderer, interval time.Duration) error {
+	if mr.last == 0 {
+		mr.last = toSeconds(time.Now())
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		q := mr.query
+		q.Offset = 0
+		q.From = mr.last
+		q.To = 0
+
+		var fresh []senml.Message
+		seen := map[string]bool{}
+		for k := range mr.seen {
+			seen[k] = true
+		}
+		err := mr.readPages(ctx, q, 0, func(msgs []senml.Message) error {
+			for _, m := range msgs {
+				if !seen[messageKey(m)] {
+					fresh = append(fresh, m)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		sort.SliceStable(fresh, func(i, j int) bool {
+			return fresh[i].Time < fresh[j].Time
+		})
+		if err := rend.render(fresh); err != nil {
+			return err
+		}
+	}
+}
+
+// track records the latest message time and messages received at that
+// time, so that messages with equal timestamps are not repeated.
+func (mr *messagesReader) track(m senml.Message) {
+	switch {
+	case m.Time > mr.last:
+		mr.last = m.Time
+		mr.seen = map[string]bool{messageKey(m): true}
+	case m.Time == mr.last:
+		mr.seen[messageKey(m)] = true
+	}
+}
+
+func messageKey(m senml.Message) string {
+	return fmt.Sprintf("%v|%s|%s|%s|%s", m.Time, m.Publisher, m.Subtopic, m.Name, messageValue(m))
+}
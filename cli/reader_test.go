@@ -0,0 +1,217 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixtureMessages(t *testing.T) []senml.Message {
+	data, err := ioutil.ReadFile("testdata/messages.json")
+	require.Nil(t, err, fmt.Sprintf("unexpected error reading fixture: %s", err))
+	var msgs []senml.Message
+	err = json.Unmarshal(data, &msgs)
+	require.Nil(t, err, fmt.Sprintf("unexpected error decoding fixture: %s", err))
+	return msgs
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		desc     string
+		value    string
+		expected float64
+		err      error
+	}{
+		{
+			desc:     "parse empty time",
+			value:    "",
+			expected: 0,
+			err:      nil,
+		},
+		{
+			desc:     "parse relative time in the past",
+			value:    "-1h",
+			expected: toSeconds(now.Add(-time.Hour)),
+			err:      nil,
+		},
+		{
+			desc:     "parse relative time in the future",
+			value:    "30m",
+			expected: toSeconds(now.Add(30 * time.Minute)),
+			err:      nil,
+		},
+		{
+			desc:     "parse RFC3339 time",
+			value:    "2021-04-30T10:00:00Z",
+			expected: toSeconds(time.Date(2021, 4, 30, 10, 0, 0, 0, time.UTC)),
+			err:      nil,
+		},
+		{
+			desc:     "parse RFC3339 time with fraction and offset",
+			value:    "2021-04-30T12:00:00.5+02:00",
+			expected: toSeconds(time.Date(2021, 4, 30, 10, 0, 0, int(time.Second/2), time.UTC)),
+			err:      nil,
+		},
+		{
+			desc:  "parse invalid time",
+			value: "yesterday",
+			err:   errInvalidTime,
+		},
+	}
+
+	for _, tc := range cases {
+		v, err := parseTime(tc.value, now)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.expected, v, fmt.Sprintf("%s: expected %f got %f", tc.desc, tc.expected, v))
+	}
+}
+
+func TestReadMessagesCmd(t *testing.T) {
+	mock := newSDKMock()
+	SetSDK(mock)
+	Limit = 10
+	defer func() { Limit = 10 }()
+
+	cases := []struct {
+		desc  string
+		args  []string
+		query mfxsdk.MessagesQuery
+	}{
+		{
+			desc:  "read messages without filters",
+			args:  []string{"1", "key"},
+			query: mfxsdk.MessagesQuery{Limit: 10},
+		},
+		{
+			desc:  "read messages with subtopic in channel name",
+			args:  []string{"1.room.1", "key"},
+			query: mfxsdk.MessagesQuery{Limit: 10, Subtopic: "room/1"},
+		},
+		{
+			desc:  "read messages with filters",
+			args:  []string{"1", "key", "--subtopic=room/2", "--publisher=dev-2", "--from=2021-04-30T10:00:00Z", "--to=2021-04-30T11:00:00Z", "--format=csv"},
+			query: mfxsdk.MessagesQuery{Limit: 10, Subtopic: "room/2", Publisher: "dev-2", From: 1619776800, To: 1619780400},
+		},
+	}
+
+	for _, tc := range cases {
+		mock.queries = nil
+		cmd := newReadMessagesCmd()
+		cmd.SetArgs(tc.args)
+		err := cmd.Execute()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		require.Len(t, mock.queries, 1, fmt.Sprintf("%s: expected 1 query got %d", tc.desc, len(mock.queries)))
+		assert.Equal(t, tc.query, mock.queries[0], fmt.Sprintf("%s: expected query %v got %v", tc.desc, tc.query, mock.queries[0]))
+	}
+}
+
+func TestMessagesRenderer(t *testing.T) {
+	msgs := fixtureMessages(t)
+
+	cases := []struct {
+		format string
+		golden string
+	}{
+		{format: formatTable, golden: "messages_table.golden"},
+		{format: formatCSV, golden: "messages_csv.golden"},
+		{format: formatJSON, golden: "messages_json.golden"},
+	}
+
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		rend, err := newMessagesRenderer(tc.format, &buf)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.format, err))
+		// Render in two pages to make sure the header is written once.
+		err = rend.render(msgs[:2])
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.format, err))
+		err = rend.render(msgs[2:])
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.format, err))
+		assertGolden(t, tc.golden, buf.Bytes())
+	}
+
+	_, err := newMessagesRenderer("xml", &bytes.Buffer{})
+	assert.True(t, errors.Contains(err, errInvalidFormat), fmt.Sprintf("expected error %s got %s", errInvalidFormat, err))
+}
+
+func TestReadPages(t *testing.T) {
+	mock := newSDKMock()
+	for i := 0; i < 250; i++ {
+		mock.messages = append(mock.messages, senml.Message{Name: "m", Time: float64(i)})
+	}
+
+	cases := []struct {
+		desc    string
+		limit   uint64
+		total   int
+		queries int
+	}{
+		{desc: "read all messages", limit: 0, total: 250, queries: 3},
+		{desc: "read limited number of messages", limit: 150, total: 150, queries: 2},
+		{desc: "read less than a page", limit: 5, total: 5, queries: 1},
+	}
+
+	for _, tc := range cases {
+		mock.queries = nil
+		var pages []int
+		mr := newMessagesReader(mock, "1", "key", mfxsdk.MessagesQuery{}, tc.limit)
+		err := mr.readPages(context.Background(), mr.query, tc.limit, func(msgs []senml.Message) error {
+			pages = append(pages, len(msgs))
+			return nil
+		})
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		total := 0
+		for _, p := range pages {
+			total += p
+		}
+		assert.Equal(t, tc.total, total, fmt.Sprintf("%s: expected %d messages got %d", tc.desc, tc.total, total))
+		assert.Len(t, mock.queries, tc.queries, fmt.Sprintf("%s: expected %d queries got %d", tc.desc, tc.queries, len(mock.queries)))
+		assert.Len(t, pages, tc.queries, fmt.Sprintf("%s: expected every page to be rendered separately", tc.desc))
+	}
+}
+
+func TestFollowMessages(t *testing.T) {
+	mock := newSDKMock()
+	mock.messages = []senml.Message{{Name: "a", Time: 10}}
+	var buf bytes.Buffer
+
+	mr := newMessagesReader(mock, "1", "key", mfxsdk.MessagesQuery{}, 0)
+	rend, err := newMessagesRenderer(formatCSV, &buf)
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	err = mr.readPages(context.Background(), mr.query, 0, rend.render)
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- mr.follow(ctx, rend, 10*time.Millisecond)
+	}()
+
+	mock.mu.Lock()
+	mock.messages = append(mock.messages, senml.Message{Name: "b", Time: 10}, senml.Message{Name: "c", Time: 11})
+	mock.mu.Unlock()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	err = <-done
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+
+	expected := "time,publisher,subtopic,name,value,unit\n" +
+		"1970-01-01T00:00:10Z,,,a,,\n" +
+		"1970-01-01T00:00:10Z,,,b,,\n" +
+		"1970-01-01T00:00:11Z,,,c,,\n"
+	assert.Equal(t, expected, buf.String(), "follow: unexpected output")
+}
@@ -3,12 +3,23 @@
 
 package cli
 
-import mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+import (
+	"context"
+
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+)
 
 // Keep SDK handle in global var
 var sdk mfxsdk.SDK
 
+// reqCtx, if set, cancels requests of the SDK set by SetSDK. The shell
+// uses it to cancel the running command.
+var reqCtx context.Context
+
 // SetSDK sets mainflux SDK instance.
 func SetSDK(s mfxsdk.SDK) {
+	if reqCtx != nil {
+		s = s.WithContext(reqCtx)
+	}
 	sdk = s
 }
@@ -0,0 +1,567 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+var _ mfxsdk.SDK = (*sdkMock)(nil)
+
+// sdkMock keeps things and channels in memory. Methods not overridden
+// by the mock panic when called.
+type sdkMock struct {
+	mfxsdk.SDK
+
+	mu       sync.Mutex
+	counter  int
+	things   []mfxsdk.Thing
+	channels []mfxsdk.Channel
+	conns    map[string]map[string]bool
+	messages []senml.Message
+	queries  []mfxsdk.MessagesQuery
+	configs  []mfxsdk.BootstrapConfig
+	filters  []map[string]string
+	certs    []mfxsdk.Cert
+	// issued records key type, key bits and TTL passed to IssueCert.
+	issued []string
+	sent   []sentMessage
+	// contentType is the content type set by SetContentType.
+	contentType mfxsdk.ContentType
+	// encKey is the key BootstrapSecure expects.
+	encKey string
+	// thingsErr, if set, is returned by CreateThings.
+	thingsErr error
+	// fetchErr, if set, is returned by Thing.
+	fetchErr error
+	users    []mfxsdk.User
+	events   []mfxsdk.UserEvent
+	// pages records offset and limit of the requested pages of users
+	// and user events.
+	pages []string
+	// ctx is the context set by WithContext.
+	ctx context.Context
+}
+
+// sentMessage is a message published through the mock.
+type sentMessage struct {
+	chanID      string
+	payload     string
+	key         string
+	contentType mfxsdk.ContentType
+}
+
+func newSDKMock() *sdkMock {
+	return &sdkMock{conns: map[string]map[string]bool{}}
+}
+
+func (sm *sdkMock) nextID() string {
+	sm.counter++
+	return fmt.Sprintf("%03d", sm.counter)
+}
+
+func (sm *sdkMock) CreateThings(ths []mfxsdk.Thing, token string) ([]mfxsdk.Thing, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.thingsErr != nil {
+		return []mfxsdk.Thing{}, sm.thingsErr
+	}
+	var res []mfxsdk.Thing
+	for _, th := range ths {
+		th.ID = sm.nextID()
+		th.Key = fmt.Sprintf("key-%s", th.ID)
+		sm.things = append(sm.things, th)
+		res = append(res, th)
+	}
+	return res, nil
+}
+
+func (sm *sdkMock) CreateThing(th mfxsdk.Thing, token string) (string, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, t := range sm.things {
+		if t.Name == th.Name {
+			return "", statusError(mfxsdk.ErrFailedCreation, http.StatusConflict)
+		}
+	}
+	th.ID = sm.nextID()
+	sm.things = append(sm.things, th)
+	return th.ID, nil
+}
+
+func (sm *sdkMock) Thing(id, token string) (mfxsdk.Thing, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.fetchErr != nil {
+		return mfxsdk.Thing{}, sm.fetchErr
+	}
+	for _, t := range sm.things {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return mfxsdk.Thing{}, statusError(mfxsdk.ErrFailedFetch, http.StatusNotFound)
+}
+
+func (sm *sdkMock) Channel(id, token string) (mfxsdk.Channel, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, c := range sm.channels {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return mfxsdk.Channel{}, statusError(mfxsdk.ErrFailedFetch, http.StatusNotFound)
+}
+
+func (sm *sdkMock) CreateChannels(chs []mfxsdk.Channel, token string) ([]mfxsdk.Channel, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var res []mfxsdk.Channel
+	for _, ch := range chs {
+		ch.ID = sm.nextID()
+		sm.channels = append(sm.channels, ch)
+		res = append(res, ch)
+	}
+	return res, nil
+}
+
+func (sm *sdkMock) Things(token string, offset, limit uint64, name string) (mfxsdk.ThingsPage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	tp := mfxsdk.ThingsPage{Things: []mfxsdk.Thing{}}
+	tp.Total = uint64(len(sm.things))
+	for i := offset; i < offset+limit && i < uint64(len(sm.things)); i++ {
+		tp.Things = append(tp.Things, sm.things[i])
+	}
+	return tp, nil
+}
+
+func (sm *sdkMock) Channels(token string, offset, limit uint64, name string) (mfxsdk.ChannelsPage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	return sm.channelsPage(sm.channels, offset, limit), nil
+}
+
+func (sm *sdkMock) ChannelsByThing(token, thingID string, offset, limit uint64, disconn bool) (mfxsdk.ChannelsPage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var chs []mfxsdk.Channel
+	for _, ch := range sm.channels {
+		if sm.conns[thingID][ch.ID] != disconn {
+			chs = append(chs, ch)
+		}
+	}
+	return sm.channelsPage(chs, offset, limit), nil
+}
+
+func (sm *sdkMock) channelsPage(chs []mfxsdk.Channel, offset, limit uint64) mfxsdk.ChannelsPage {
+	cp := mfxsdk.ChannelsPage{Channels: []mfxsdk.Channel{}}
+	cp.Total = uint64(len(chs))
+	for i := offset; i < offset+limit && i < uint64(len(chs)); i++ {
+		cp.Channels = append(cp.Channels, chs[i])
+	}
+	return cp
+}
+
+func (sm *sdkMock) Connect(conns mfxsdk.ConnectionIDs, token string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, thID := range conns.ThingIDs {
+		for _, chID := range conns.ChannelIDs {
+			if sm.conns[thID][chID] {
+				return statusError(mfxsdk.ErrFailedConnect, http.StatusConflict)
+			}
+		}
+	}
+	for _, thID := range conns.ThingIDs {
+		for _, chID := range conns.ChannelIDs {
+			if sm.conns[thID] == nil {
+				sm.conns[thID] = map[string]bool{}
+			}
+			sm.conns[thID][chID] = true
+		}
+	}
+	return nil
+}
+
+func (sm *sdkMock) Disconnect(conns mfxsdk.ConnectionIDs, token string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, thID := range conns.ThingIDs {
+		for _, chID := range conns.ChannelIDs {
+			if !sm.conns[thID][chID] {
+				return statusError(mfxsdk.ErrFailedDisconnect, http.StatusNotFound)
+			}
+		}
+	}
+	for _, thID := range conns.ThingIDs {
+		for _, chID := range conns.ChannelIDs {
+			delete(sm.conns[thID], chID)
+		}
+	}
+	return nil
+}
+
+func (sm *sdkMock) DisconnectThing(thingID, chanID, token string) error {
+	return sm.Disconnect(mfxsdk.ConnectionIDs{ThingIDs: []string{thingID}, ChannelIDs: []string{chanID}}, token)
+}
+
+// connections returns sorted list of thing-channel pairs.
+func (sm *sdkMock) connections() []string {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	var res []string
+	for thID, chs := range sm.conns {
+		for chID := range chs {
+			res = append(res, fmt.Sprintf("%s-%s", thID, chID))
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (sm *sdkMock) QueryMessages(chanID, token string, q mfxsdk.MessagesQuery) (mfxsdk.MessagesPage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.queries = append(sm.queries, q)
+	var msgs []senml.Message
+	for _, m := range sm.messages {
+		if (q.Subtopic != "" && m.Subtopic != q.Subtopic) ||
+			(q.Publisher != "" && m.Publisher != q.Publisher) ||
+			(q.From != 0 && m.Time < q.From) ||
+			(q.To != 0 && m.Time >= q.To) {
+			continue
+		}
+		msgs = append(msgs, m)
+	}
+
+	mp := mfxsdk.MessagesPage{}
+	mp.Total = uint64(len(msgs))
+	for i := q.Offset; i < q.Offset+q.Limit && i < uint64(len(msgs)); i++ {
+		mp.Messages = append(mp.Messages, msgs[i])
+	}
+	return mp, nil
+}
+
+func (sm *sdkMock) AddBootstrap(token string, cfg mfxsdk.BootstrapConfig) (string, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	cfg.MFThing = sm.nextID()
+	if cfg.ThingID != "" {
+		cfg.MFThing = cfg.ThingID
+	}
+	sm.configs = append(sm.configs, cfg)
+	return cfg.MFThing, nil
+}
+
+func (sm *sdkMock) config(match func(mfxsdk.BootstrapConfig) bool) (int, error) {
+	for i, c := range sm.configs {
+		if match(c) {
+			return i, nil
+		}
+	}
+	return 0, mfxsdk.ErrFailedFetch
+}
+
+func (sm *sdkMock) ViewBootstrap(token, id string) (mfxsdk.BootstrapConfig, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	i, err := sm.config(func(c mfxsdk.BootstrapConfig) bool { return c.MFThing == id })
+	if err != nil {
+		return mfxsdk.BootstrapConfig{}, err
+	}
+	return sm.configs[i], nil
+}
+
+func (sm *sdkMock) Bootstraps(token string, offset, limit uint64, filter map[string]string) (mfxsdk.BootstrapPage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.filters = append(sm.filters, filter)
+	var cfgs []mfxsdk.BootstrapConfig
+	for _, c := range sm.configs {
+		if s, ok := filter["state"]; ok && s != fmt.Sprintf("%d", c.State) {
+			continue
+		}
+		cfgs = append(cfgs, c)
+	}
+
+	bp := mfxsdk.BootstrapPage{}
+	bp.Total = uint64(len(cfgs))
+	for i := offset; i < offset+limit && i < uint64(len(cfgs)); i++ {
+		bp.Configs = append(bp.Configs, cfgs[i])
+	}
+	return bp, nil
+}
+
+func (sm *sdkMock) UpdateBootstrap(token string, cfg mfxsdk.BootstrapConfig) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	i, err := sm.config(func(c mfxsdk.BootstrapConfig) bool { return c.MFThing == cfg.MFThing })
+	if err != nil {
+		return mfxsdk.ErrFailedUpdate
+	}
+	sm.configs[i].Name = cfg.Name
+	sm.configs[i].Content = cfg.Content
+	return nil
+}
+
+func (sm *sdkMock) Whitelist(token string, cfg mfxsdk.BootstrapConfig) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	i, err := sm.config(func(c mfxsdk.BootstrapConfig) bool { return c.MFThing == cfg.MFThing })
+	if err != nil {
+		return mfxsdk.ErrFailedWhitelist
+	}
+	sm.configs[i].State = cfg.State
+	return nil
+}
+
+func (sm *sdkMock) RemoveBootstrap(token, id string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	i, err := sm.config(func(c mfxsdk.BootstrapConfig) bool { return c.MFThing == id })
+	if err != nil {
+		return mfxsdk.ErrFailedRemoval
+	}
+	sm.configs = append(sm.configs[:i], sm.configs[i+1:]...)
+	return nil
+}
+
+func (sm *sdkMock) Bootstrap(externalKey, externalID string) (mfxsdk.BootstrapConfig, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	i, err := sm.config(func(c mfxsdk.BootstrapConfig) bool {
+		return c.ExternalID == externalID && c.ExternalKey == externalKey
+	})
+	if err != nil {
+		return mfxsdk.BootstrapConfig{}, err
+	}
+	return sm.configs[i], nil
+}
+
+func (sm *sdkMock) BootstrapSecure(externalKey, externalID, encKey string) (mfxsdk.BootstrapConfig, error) {
+	if encKey != sm.encKey {
+		return mfxsdk.BootstrapConfig{}, mfxsdk.ErrFailedDecrypt
+	}
+	return sm.Bootstrap(externalKey, externalID)
+}
+
+func (sm *sdkMock) IssueCert(thingID string, keyBits int, keyType, valid, token string) (mfxsdk.Cert, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if token != userToken {
+		return mfxsdk.Cert{}, mfxsdk.ErrUnauthorized
+	}
+	sm.issued = append(sm.issued, fmt.Sprintf("%s-%d %s", keyType, keyBits, valid))
+	c := mfxsdk.Cert{
+		ThingID:    thingID,
+		Serial:     sm.nextID(),
+		ClientCert: "cert " + thingID,
+		ClientKey:  "key " + thingID,
+		CACert:     "ca",
+	}
+	sm.certs = append(sm.certs, c)
+	return c, nil
+}
+
+func (sm *sdkMock) ViewCert(serialID, token string) (mfxsdk.Cert, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if token != userToken {
+		return mfxsdk.Cert{}, mfxsdk.ErrUnauthorized
+	}
+	for _, c := range sm.certs {
+		if c.Serial == serialID {
+			return c, nil
+		}
+	}
+	return mfxsdk.Cert{}, mfxsdk.ErrNotFound
+}
+
+func (sm *sdkMock) ListCerts(thingID, token string, offset, limit uint64) (mfxsdk.CertsPage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if token != userToken {
+		return mfxsdk.CertsPage{}, mfxsdk.ErrUnauthorized
+	}
+	var certs []mfxsdk.Cert
+	for _, c := range sm.certs {
+		if c.ThingID == thingID {
+			certs = append(certs, c)
+		}
+	}
+	cp := mfxsdk.CertsPage{Certs: []mfxsdk.Cert{}}
+	cp.Total, cp.Offset, cp.Limit = uint64(len(certs)), offset, limit
+	for i := offset; i < uint64(len(certs)) && i < offset+limit; i++ {
+		cp.Certs = append(cp.Certs, certs[i])
+	}
+	return cp, nil
+}
+
+func (sm *sdkMock) RevokeCert(thingID, token string) (time.Time, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if token != userToken {
+		return time.Time{}, mfxsdk.ErrUnauthorized
+	}
+	for i, c := range sm.certs {
+		if c.ThingID == thingID && c.Revoked == nil {
+			t := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+			sm.certs[i].Revoked = &t
+			return t, nil
+		}
+	}
+	return time.Time{}, mfxsdk.ErrNotFound
+}
+
+func (sm *sdkMock) RevokeCertBySerial(serialID, token string) (time.Time, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if token != userToken {
+		return time.Time{}, mfxsdk.ErrUnauthorized
+	}
+	for i, c := range sm.certs {
+		if c.Serial != serialID {
+			continue
+		}
+		if c.Revoked != nil {
+			return time.Time{}, mfxsdk.ErrCertRevoked
+		}
+		t := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+		sm.certs[i].Revoked = &t
+		return t, nil
+	}
+	return time.Time{}, mfxsdk.ErrNotFound
+}
+
+func (sm *sdkMock) SendMessage(chanID, msg, token string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.sent = append(sm.sent, sentMessage{chanID: chanID, payload: msg, key: token, contentType: sm.contentType})
+	return nil
+}
+
+func (sm *sdkMock) SetContentType(ct mfxsdk.ContentType) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.contentType = ct
+	return nil
+}
+
+// Users and user events are visible only to the admin.
+const adminToken = "admin-token"
+
+func (sm *sdkMock) Users(token string, offset, limit uint64, email, status string) (mfxsdk.UsersPage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if token != adminToken {
+		return mfxsdk.UsersPage{}, statusError(mfxsdk.ErrFailedFetch, http.StatusForbidden)
+	}
+	sm.pages = append(sm.pages, fmt.Sprintf("%d-%d", offset, limit))
+
+	var users []mfxsdk.User
+	for _, u := range sm.users {
+		if strings.Contains(u.Email, email) && (status == "" || u.Status == status) {
+			users = append(users, u)
+		}
+	}
+	up := mfxsdk.UsersPage{Users: []mfxsdk.User{}}
+	up.Total = uint64(len(users))
+	for i := offset; i < offset+limit && i < uint64(len(users)); i++ {
+		up.Users = append(up.Users, users[i])
+	}
+	return up, nil
+}
+
+func (sm *sdkMock) DisableUser(id, token string) error {
+	return sm.setUserStatus(id, statusDisabled, token)
+}
+
+func (sm *sdkMock) EnableUser(id, token string) error {
+	return sm.setUserStatus(id, statusEnabled, token)
+}
+
+func (sm *sdkMock) setUserStatus(id, status, token string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if token != adminToken {
+		return statusError(mfxsdk.ErrFailedUpdate, http.StatusForbidden)
+	}
+	for i, u := range sm.users {
+		if u.ID == id {
+			sm.users[i].Status = status
+			return nil
+		}
+	}
+	return statusError(mfxsdk.ErrFailedUpdate, http.StatusNotFound)
+}
+
+func (sm *sdkMock) UserEvents(id, token string, from time.Time, offset, limit uint64) (mfxsdk.UserEventsPage, error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if token != adminToken {
+		return mfxsdk.UserEventsPage{}, statusError(mfxsdk.ErrFailedFetch, http.StatusForbidden)
+	}
+	sm.pages = append(sm.pages, fmt.Sprintf("%d-%d", offset, limit))
+
+	var events []mfxsdk.UserEvent
+	for _, e := range sm.events {
+		if e.UserID == id && !e.Time.Before(from) {
+			events = append(events, e)
+		}
+	}
+	ep := mfxsdk.UserEventsPage{Events: []mfxsdk.UserEvent{}}
+	ep.Total = uint64(len(events))
+	for i := offset; i < offset+limit && i < uint64(len(events)); i++ {
+		ep.Events = append(ep.Events, events[i])
+	}
+	return ep, nil
+}
+
+func (sm *sdkMock) WithContext(ctx context.Context) mfxsdk.SDK {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	sm.ctx = ctx
+	return sm
+}
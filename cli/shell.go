@@ -0,0 +1,518 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// maxSeenIDs is the number of recently seen IDs offered for completion.
+const maxSeenIDs = 100
+
+var (
+	errUnknownVariable = errors.New("unknown shell variable")
+	errInvalidVariable = errors.New("invalid shell variable name")
+	errUnclosedQuote   = errors.New("unclosed quote")
+	errNestedShell     = errors.New("already running the shell")
+)
+
+var (
+	idRegexp  = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	varRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+var shellBuiltins = []string{"exit", "help", "history", "ids", "quit", "set", "unset", "vars"}
+
+// lineReader reads lines of the shell input.
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+// plainReader reads lines without editing support, used if the input
+// isn't a terminal.
+type plainReader struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (r plainReader) readLine(prompt string) (string, error) {
+	fmt.Fprint(r.out, prompt)
+	line, err := r.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// shell executes CLI commands read line by line. It keeps session
+// variables, history and IDs seen in the output of the executed commands.
+type shell struct {
+	newRoot func() *cobra.Command
+	// flags are root flags the shell was started with, passed to every
+	// executed command.
+	flags   []string
+	reader  lineReader
+	out     io.Writer
+	vars    map[string]string
+	history []string
+	ids     []string
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewShellCmd returns command starting the interactive shell. Commands
+// entered in the shell are executed using fresh commands tree returned
+// by newRoot.
+func NewShellCmd(newRoot func() *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:   "shell",
+		Short: "shell",
+		Long: `Starts interactive shell executing CLI commands with the active
+profile. Session variables are set using "set <name> <value>" and used
+in commands as $name. Tab completes commands and recently seen IDs and
+Ctrl-C cancels the running request`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) != 0 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			s := newShell(newRoot, cmd.InOrStdin(), os.Stdout)
+			s.flags = changedFlags(cmd.Root().PersistentFlags())
+			if f, ok := cmd.InOrStdin().(*os.File); ok {
+				if r, restore, ok := newTerminalReader(f, os.Stdout, s.complete, s.previous); ok {
+					defer restore()
+					s.reader = r
+				}
+			}
+
+			sigs := make(chan os.Signal, 1)
+			signal.Notify(sigs, os.Interrupt)
+			defer signal.Stop(sigs)
+			go func() {
+				for range sigs {
+					s.interrupt()
+				}
+			}()
+
+			s.run()
+		},
+	}
+}
+
+func newShell(newRoot func() *cobra.Command, in io.Reader, out io.Writer) *shell {
+	return &shell{
+		newRoot: newRoot,
+		reader:  plainReader{in: bufio.NewReader(in), out: out},
+		out:     out,
+		vars:    map[string]string{},
+	}
+}
+
+// run executes lines until the input ends or the shell is exited.
+func (s *shell) run() {
+	for {
+		line, err := s.reader.readLine(s.prompt())
+		if err != nil {
+			fmt.Fprintln(s.out)
+			return
+		}
+		if quit := s.exec(line); quit {
+			return
+		}
+	}
+}
+
+func (s *shell) prompt() string {
+	name := defProfile
+	if c, _, err := loadConfig(false); err == nil {
+		name = profileName(c)
+	}
+	return fmt.Sprintf("mainflux-cli (%s)> ", name)
+}
+
+// exec executes the line and reports whether the shell should exit.
+func (s *shell) exec(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+	if n := len(s.history); n == 0 || s.history[n-1] != line {
+		s.history = append(s.history, line)
+	}
+
+	args, err := s.parse(line)
+	if err != nil {
+		s.logError(err)
+		return false
+	}
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return true
+	case "help":
+		s.help()
+	case "history":
+		for i, h := range s.history {
+			fmt.Fprintf(s.out, "%5d  %s\n", i+1, h)
+		}
+	case "ids":
+		for _, id := range s.ids {
+			fmt.Fprintln(s.out, id)
+		}
+	case "vars":
+		names := s.varNames()
+		for _, n := range names {
+			fmt.Fprintf(s.out, "%s=%s\n", n, s.vars[n])
+		}
+	case "set":
+		s.set(args[1:])
+	case "unset":
+		for _, n := range args[1:] {
+			delete(s.vars, n)
+		}
+	case "shell":
+		s.logError(errNestedShell)
+	default:
+		s.execCmd(args)
+	}
+	return false
+}
+
+// execCmd executes the CLI command. Commands exiting with an error don't
+// terminate the shell and their output is scanned for IDs.
+func (s *shell) execCmd(args []string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.cancel = nil
+		s.mu.Unlock()
+		cancel()
+	}()
+
+	prevExit, prevCtx := exit, reqCtx
+	exit = func(int) {}
+	reqCtx = ctx
+	defer func() { exit, reqCtx = prevExit, prevCtx }()
+
+	root := s.newRoot()
+	root.SetArgs(append(append([]string{}, s.flags...), args...))
+	root.SetOut(s.out)
+	root.SetErr(s.out)
+	root.SilenceErrors = true
+	out := s.capture(func() {
+		if err := root.Execute(); err != nil {
+			s.logError(err)
+		}
+	})
+	s.remember(out)
+}
+
+// interrupt cancels the running command, if any.
+func (s *shell) interrupt() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// capture copies what fn writes to the standard output to the shell
+// output and returns it.
+func (s *shell) capture(fn func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		io.Copy(io.MultiWriter(s.out, &buf), r)
+		r.Close()
+		done <- buf.String()
+	}()
+
+	fn()
+	os.Stdout = stdout
+	w.Close()
+	return <-done
+}
+
+// remember caches IDs found in the output, most recent first.
+func (s *shell) remember(out string) {
+	found := idRegexp.FindAllString(out, -1)
+	ids := make([]string, 0, maxSeenIDs)
+	seen := map[string]bool{}
+	for i := len(found) - 1; i >= 0; i-- {
+		if !seen[found[i]] {
+			seen[found[i]] = true
+			ids = append(ids, found[i])
+		}
+	}
+	for _, id := range s.ids {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxSeenIDs {
+		ids = ids[:maxSeenIDs]
+	}
+	s.ids = ids
+}
+
+// set sets session variables given as "name value" or "name=value".
+func (s *shell) set(args []string) {
+	if len(args) == 1 && strings.Contains(args[0], "=") {
+		args = strings.SplitN(args[0], "=", 2)
+	}
+	if len(args) != 2 {
+		fmt.Fprintf(s.out, color.YellowString("\nusage: %s\n\n"), "set <name> <value>")
+		return
+	}
+	if !varRegexp.MatchString(args[0]) {
+		s.logError(errors.Wrap(errInvalidVariable, errors.New(args[0])))
+		return
+	}
+	s.vars[args[0]] = args[1]
+}
+
+func (s *shell) varNames() []string {
+	names := []string{}
+	for n := range s.vars {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *shell) help() {
+	fmt.Fprintln(s.out, "Shell commands:")
+	fmt.Fprintln(s.out, "  set <name> <value>  set session variable used as $name")
+	fmt.Fprintln(s.out, "  unset <name>        remove session variable")
+	fmt.Fprintln(s.out, "  vars                list session variables")
+	fmt.Fprintln(s.out, "  ids                 list recently seen IDs")
+	fmt.Fprintln(s.out, "  history             list entered lines")
+	fmt.Fprintln(s.out, "  exit, quit          exit the shell")
+	fmt.Fprintln(s.out, "CLI commands:")
+	for _, c := range s.newRoot().Commands() {
+		if c.IsAvailableCommand() && c.Name() != "shell" {
+			fmt.Fprintf(s.out, "  %-20s%s\n", c.Name(), c.Short)
+		}
+	}
+}
+
+func (s *shell) logError(err error) {
+	fmt.Fprintf(s.out, "\n%s\n\n", color.RedString(err.Error()))
+}
+
+// previous returns the entered lines, the oldest first.
+func (s *shell) previous() []string {
+	return s.history
+}
+
+// parse splits the line into arguments as a POSIX shell does, handling
+// quotes and escapes, and substitutes session variables. Variables aren't
+// substituted in single quoted text.
+func (s *shell) parse(line string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			cur.WriteRune(r)
+		case r == '\\' && i+1 < len(runes):
+			i++
+			cur.WriteRune(runes[i])
+			inArg = true
+		case r == '$':
+			v, n, err := s.expand(runes[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			cur.WriteString(v)
+			i += n
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+				continue
+			}
+			cur.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errUnclosedQuote
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// expand returns value of the variable referenced as $name or ${name} at
+// the start of the text following $ and the number of consumed runes.
+// If there is no variable name, $ is kept as is.
+func (s *shell) expand(text []rune) (string, int, error) {
+	name, n := "", 0
+	if len(text) > 0 && text[0] == '{' {
+		end := 1
+		for end < len(text) && text[end] != '}' {
+			end++
+		}
+		if end == len(text) {
+			return "$", 0, nil
+		}
+		name, n = string(text[1:end]), end+1
+	} else {
+		for n < len(text) && varRegexp.MatchString(string(text[:n+1])) {
+			n++
+		}
+		name = string(text[:n])
+	}
+	if name == "" {
+		return "$", 0, nil
+	}
+	v, ok := s.vars[name]
+	if !ok {
+		return "", 0, errors.Wrap(errUnknownVariable, errors.New(name))
+	}
+	return v, n, nil
+}
+
+// complete returns completion candidates for the last word of the line.
+// Variables are completed after $, flags after - and subcommands while
+// the command isn't complete. Otherwise, recently seen IDs are offered.
+func (s *shell) complete(line string) []string {
+	words := strings.Fields(line)
+	word := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\t") {
+		word = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	if strings.HasPrefix(word, "$") {
+		var cands []string
+		for _, n := range s.varNames() {
+			if strings.HasPrefix("$"+n, word) {
+				cands = append(cands, "$"+n)
+			}
+		}
+		return cands
+	}
+
+	cmd, cmdWords := s.newRoot(), 0
+	for _, w := range words {
+		sub := subcommand(cmd, w)
+		if sub == nil {
+			break
+		}
+		cmd, cmdWords = sub, cmdWords+1
+	}
+
+	if strings.HasPrefix(word, "-") {
+		var cands []string
+		add := func(f *pflag.Flag) {
+			if !f.Hidden && strings.HasPrefix("--"+f.Name, word) {
+				cands = append(cands, "--"+f.Name)
+			}
+		}
+		cmd.LocalFlags().VisitAll(add)
+		cmd.InheritedFlags().VisitAll(add)
+		sort.Strings(cands)
+		return cands
+	}
+
+	if cmdWords == len(words) && (cmd.HasAvailableSubCommands() || len(words) == 0) {
+		var cands []string
+		for _, c := range cmd.Commands() {
+			if c.IsAvailableCommand() && strings.HasPrefix(c.Name(), word) {
+				cands = append(cands, c.Name())
+			}
+		}
+		if len(words) == 0 {
+			for _, b := range shellBuiltins {
+				if strings.HasPrefix(b, word) {
+					cands = append(cands, b)
+				}
+			}
+		}
+		sort.Strings(cands)
+		return cands
+	}
+
+	var cands []string
+	for _, id := range s.ids {
+		if strings.HasPrefix(id, word) {
+			cands = append(cands, id)
+		}
+	}
+	return cands
+}
+
+func subcommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, c := range cmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// changedFlags returns flags set on the command line as arguments.
+func changedFlags(fs *pflag.FlagSet) []string {
+	var args []string
+	fs.Visit(func(f *pflag.Flag) {
+		args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+	})
+	return args
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalReader switches the terminal to raw mode while the line is
+// edited, so commands are executed with the terminal in its original mode.
+type terminalReader struct {
+	fd    int
+	state unix.Termios
+	ed    *editor
+}
+
+// newTerminalReader returns line reader with editing support if the file
+// is a terminal, and the function restoring the terminal mode.
+func newTerminalReader(f *os.File, out io.Writer, complete func(string) []string, history func() []string) (lineReader, func(), bool) {
+	fd := int(f.Fd())
+	state, err := unix.IoctlGetTermios(fd, unix.TCGETS)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	r := &terminalReader{
+		fd:    fd,
+		state: *state,
+		ed:    newEditor(f, out, complete, history),
+	}
+	restore := func() {
+		unix.IoctlSetTermios(fd, unix.TCSETS, &r.state)
+	}
+	return r, restore, true
+}
+
+func (r *terminalReader) readLine(prompt string) (string, error) {
+	raw := r.state
+	raw.Iflag &^= unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cc[unix.VMIN], raw.Cc[unix.VTIME] = 1, 0
+	if err := unix.IoctlSetTermios(r.fd, unix.TCSETS, &raw); err != nil {
+		return "", err
+	}
+	defer unix.IoctlSetTermios(r.fd, unix.TCSETS, &r.state)
+
+	return r.ed.readLine(prompt)
+}
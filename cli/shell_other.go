@@ -0,0 +1,17 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// +build !linux
+
+package cli
+
+import (
+	"io"
+	"os"
+)
+
+// newTerminalReader reports that line editing isn't supported, so lines
+// are read without history and completion.
+func newTerminalReader(f *os.File, out io.Writer, complete func(string) []string, history func() []string) (lineReader, func(), bool) {
+	return nil, nil, false
+}
@@ -0,0 +1,278 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	thingID   = "6b2cd5d6-5a1f-4bcb-9a46-6b4f2e0b1a01"
+	thingID2  = "6b2cd5d6-5a1f-4bcb-9a46-6b4f2e0b1a02"
+	channelID = "0f3a8a2e-7c1d-4e55-8d0c-1f5c7b6e2c03"
+)
+
+// newTestRoot returns function creating root command that uses the SDK.
+func newTestRoot(s mfxsdk.SDK) func() *cobra.Command {
+	var newRoot func() *cobra.Command
+	newRoot = func() *cobra.Command {
+		root := &cobra.Command{
+			Use: "mainflux-cli",
+			PersistentPreRun: func(cmd *cobra.Command, args []string) {
+				SetSDK(s)
+			},
+		}
+		root.AddCommand(NewThingsCmd(), NewChannelsCmd(), NewUsersCmd(), NewShellCmd(newRoot))
+		return root
+	}
+	return newRoot
+}
+
+func newShellMock() *sdkMock {
+	mock := newSDKMock()
+	mock.things = []mfxsdk.Thing{
+		{ID: thingID, Name: "sensor", Key: "key-1"},
+		{ID: thingID2, Name: "gateway", Key: "key-2"},
+	}
+	mock.channels = []mfxsdk.Channel{{ID: channelID, Name: "telemetry"}}
+	return mock
+}
+
+func runShell(t *testing.T, s *shell, input string) string {
+	defer func(path string) { ConfigPath = path }(ConfigPath)
+	ConfigPath = filepath.Join(tempDir(t), "cli.toml")
+
+	var out bytes.Buffer
+	s.out = &out
+	s.reader = plainReader{in: bufio.NewReader(strings.NewReader(input)), out: &out}
+	s.run()
+	return out.String()
+}
+
+func TestShellParse(t *testing.T) {
+	s := newShell(newTestRoot(newShellMock()), strings.NewReader(""), &bytes.Buffer{})
+	s.vars["thing"] = thingID
+	s.vars["channel"] = channelID
+
+	cases := []struct {
+		desc string
+		line string
+		args []string
+		err  error
+	}{
+		{
+			desc: "parse line with variables",
+			line: "things connect $thing ${channel} token",
+			args: []string{"things", "connect", thingID, channelID, "token"},
+		},
+		{
+			desc: "parse line with variable in double quotes",
+			line: `messages send "$channel" '[{"bn":"$thing"}]' key`,
+			args: []string{"messages", "send", channelID, `[{"bn":"$thing"}]`, "key"},
+		},
+		{
+			desc: "parse line with escaped characters",
+			line: `things create {\"name\":\ \"x\"} \$thing`,
+			args: []string{"things", "create", `{"name": "x"}`, "$thing"},
+		},
+		{
+			desc: "parse line with dollar sign",
+			line: "things get $ $1",
+			args: []string{"things", "get", "$", "$1"},
+		},
+		{
+			desc: "parse line with unknown variable",
+			line: "things get $device",
+			err:  errUnknownVariable,
+		},
+		{
+			desc: "parse line with unclosed quote",
+			line: `things create '{"name":"x"}`,
+			err:  errUnclosedQuote,
+		},
+	}
+
+	for _, tc := range cases {
+		args, err := s.parse(tc.line)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.args, args, fmt.Sprintf("%s: expected args %v got %v", tc.desc, tc.args, args))
+	}
+}
+
+func TestShellRun(t *testing.T) {
+	defer func() { Format = formatJSON }()
+	Format = formatCSV
+
+	mock := newShellMock()
+	s := newShell(newTestRoot(mock), strings.NewReader(""), &bytes.Buffer{})
+	input := fmt.Sprintf("set thing %s\n", thingID) +
+		"things get $thing token\n" +
+		"things get unknown-id token\n" +
+		"set channel=" + channelID + "\n" +
+		"vars\n" +
+		"shell\n" +
+		"exit\n" +
+		"vars\n"
+	out := runShell(t, s, input)
+
+	assert.Contains(t, out, fmt.Sprintf("%s,sensor,key-1,\n", thingID), "expected thing referenced by the variable")
+	assert.Contains(t, out, "404 Not Found", "expected failed command to not exit the shell")
+	assert.Contains(t, out, fmt.Sprintf("channel=%s\nthing=%s\n", channelID, thingID), "expected session variables")
+	assert.Contains(t, out, errNestedShell.Error(), "expected nested shell to be rejected")
+	assert.Equal(t, 1, strings.Count(out, "channel="), "expected lines after exit to not be executed")
+	assert.Equal(t, 7, len(s.history), "expected entered lines in history")
+	assert.NotNil(t, mock.ctx, "expected SDK to use the shell request context")
+	assert.Nil(t, reqCtx, "expected request context to be reset after the command")
+}
+
+func TestShellComplete(t *testing.T) {
+	s := newShell(newTestRoot(newShellMock()), strings.NewReader(""), &bytes.Buffer{})
+	runShell(t, s, "things get all token\nset thing "+thingID+"\nset token token\n")
+
+	cases := []struct {
+		desc  string
+		line  string
+		cands []string
+	}{
+		{
+			desc:  "complete commands and builtins",
+			line:  "",
+			cands: []string{"channels", "exit", "help", "history", "ids", "quit", "set", "shell", "things", "unset", "users", "vars"},
+		},
+		{
+			desc:  "complete command prefix",
+			line:  "th",
+			cands: []string{"things"},
+		},
+		{
+			desc:  "complete subcommands",
+			line:  "things ",
+			cands: []string{"connect", "connections", "create", "delete", "disconnect", "get", "not-connected", "update"},
+		},
+		{
+			desc:  "complete subcommand prefix",
+			line:  "users e",
+			cands: []string{"enable", "events"},
+		},
+		{
+			desc:  "complete seen IDs",
+			line:  "things get ",
+			cands: []string{thingID2, thingID},
+		},
+		{
+			desc:  "complete seen ID prefix",
+			line:  "things get 6b2cd5d6-5a1f-4bcb-9a46-6b4f2e0b1a01",
+			cands: []string{thingID},
+		},
+		{
+			desc:  "complete variables",
+			line:  "things get $t",
+			cands: []string{"$thing", "$token"},
+		},
+		{
+			desc:  "complete flags",
+			line:  "users list --e",
+			cands: []string{"--email"},
+		},
+		{
+			desc:  "complete unknown command",
+			line:  "devices g",
+			cands: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		cands := s.complete(tc.line)
+		assert.Equal(t, tc.cands, cands, fmt.Sprintf("%s: expected candidates %v got %v", tc.desc, tc.cands, cands))
+	}
+}
+
+func TestEditor(t *testing.T) {
+	s := newShell(newTestRoot(newShellMock()), strings.NewReader(""), &bytes.Buffer{})
+	s.history = []string{"things get all token", "channels get all token"}
+	s.ids = []string{thingID}
+
+	cases := []struct {
+		desc  string
+		input string
+		line  string
+	}{
+		{
+			desc:  "complete line using tab",
+			input: "thi\tg\t6b\t\r",
+			line:  "things get " + thingID + " ",
+		},
+		{
+			desc:  "edit line",
+			input: "thingx\x7fs\r",
+			line:  "things",
+		},
+		{
+			desc:  "recall previous lines",
+			input: "\x1b[A\x1b[A\x1b[B\r",
+			line:  "channels get all token",
+		},
+		{
+			desc:  "cancel line",
+			input: "things delete\x03users\r",
+			line:  "users",
+		},
+	}
+
+	for _, tc := range cases {
+		var out bytes.Buffer
+		ed := newEditor(strings.NewReader(tc.input), &out, s.complete, s.previous)
+		line, err := ed.readLine("> ")
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.line, line, fmt.Sprintf("%s: expected line %q got %q", tc.desc, tc.line, line))
+	}
+
+	var out bytes.Buffer
+	ed := newEditor(strings.NewReader("things \t"), &out, s.complete, s.previous)
+	ed.readLine("> ")
+	assert.Contains(t, out.String(), "connect  connections  create  delete  disconnect  get  not-connected  update", "expected candidates to be listed")
+}
+
+// blockingSDK blocks fetching things until the request is canceled.
+type blockingSDK struct {
+	*sdkMock
+	ctx     context.Context
+	started chan struct{}
+}
+
+func (bs *blockingSDK) WithContext(ctx context.Context) mfxsdk.SDK {
+	bs.ctx = ctx
+	return bs
+}
+
+func (bs *blockingSDK) Thing(id, token string) (mfxsdk.Thing, error) {
+	close(bs.started)
+	<-bs.ctx.Done()
+	return mfxsdk.Thing{}, errors.Wrap(mfxsdk.ErrFailedFetch, bs.ctx.Err())
+}
+
+func TestShellInterrupt(t *testing.T) {
+	bs := &blockingSDK{sdkMock: newShellMock(), started: make(chan struct{})}
+	s := newShell(newTestRoot(bs), strings.NewReader(""), &bytes.Buffer{})
+
+	go func() {
+		<-bs.started
+		s.interrupt()
+	}()
+	out := runShell(t, s, "things get "+thingID+" token\nvars\nset thing x\nvars\n")
+
+	assert.Contains(t, out, context.Canceled.Error(), "expected request to be canceled")
+	assert.Contains(t, out, "thing=x\n", "expected shell to keep running after the request is canceled")
+}
@@ -6,6 +6,7 @@ package cli
 import (
 	"encoding/json"
 
+	"github.com/mainflux/mainflux/pkg/errors"
 	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/spf13/cobra"
 )
@@ -16,14 +17,14 @@ var cmdThings = []cobra.Command{
 		Short: "create <JSON_thing> <user_auth_token>",
 		Long:  `Create new thing, generate his UUID and store it`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
 			var thing mfxsdk.Thing
 			if err := json.Unmarshal([]byte(args[0]), &thing); err != nil {
-				logError(err)
+				logError(errors.Wrap(errInvalidArgs, err))
 				return
 			}
 
@@ -41,7 +42,7 @@ var cmdThings = []cobra.Command{
 		Short: "get [all | <thing_id>] <user_auth_token>",
 		Long:  `Get all things or thing by id`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -52,7 +53,7 @@ var cmdThings = []cobra.Command{
 					logError(err)
 					return
 				}
-				logJSON(l)
+				logRecords(l, thingColumns, thingRows(l.Things))
 				return
 			}
 
@@ -62,7 +63,7 @@ var cmdThings = []cobra.Command{
 				return
 			}
 
-			logJSON(t)
+			logRecords(t, thingColumns, thingRows([]mfxsdk.Thing{t}))
 		},
 	},
 	cobra.Command{
@@ -70,7 +71,7 @@ var cmdThings = []cobra.Command{
 		Short: "delete <thing_id> <user_auth_token>",
 		Long:  `Removes thing from database`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -88,14 +89,14 @@ var cmdThings = []cobra.Command{
 		Short: "update <JSON_string> <user_auth_token>",
 		Long:  `Update thing record`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
 			var thing mfxsdk.Thing
 			if err := json.Unmarshal([]byte(args[0]), &thing); err != nil {
-				logError(err)
+				logError(errors.Wrap(errInvalidArgs, err))
 				return
 			}
 
@@ -112,7 +113,7 @@ var cmdThings = []cobra.Command{
 		Short: "connect <thing_id> <channel_id> <user_auth_token>",
 		Long:  `Connect thing to the channel`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 3 {
+			if args = withToken(args, 3); len(args) != 3 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -134,7 +135,7 @@ var cmdThings = []cobra.Command{
 		Short: "disconnect <thing_id> <channel_id> <user_auth_token>",
 		Long:  `Disconnect thing to the channel`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 3 {
+			if args = withToken(args, 3); len(args) != 3 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -152,7 +153,7 @@ var cmdThings = []cobra.Command{
 		Short: "connections <thing_id> <user_auth_token>",
 		Long:  `List of Channels connected to Thing`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -163,7 +164,7 @@ var cmdThings = []cobra.Command{
 				return
 			}
 
-			logJSON(cl)
+			logRecords(cl, channelColumns, channelRows(cl.Channels))
 		},
 	},
 	cobra.Command{
@@ -171,7 +172,7 @@ var cmdThings = []cobra.Command{
 		Short: "not-connected <thing_id> <user_auth_token>",
 		Long:  `List of Channels not connected to a Thing`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -182,7 +183,7 @@ var cmdThings = []cobra.Command{
 				return
 			}
 
-			logJSON(cl)
+			logRecords(cl, channelColumns, channelRows(cl.Channels))
 		},
 	},
 }
@@ -204,3 +205,13 @@ func NewThingsCmd() *cobra.Command {
 
 	return &cmd
 }
+
+var thingColumns = []string{"id", "name", "key", "metadata"}
+
+func thingRows(ths []mfxsdk.Thing) [][]string {
+	rows := [][]string{}
+	for _, t := range ths {
+		rows = append(rows, []string{t.ID, t.Name, t.Key, metadataValue(t.Metadata)})
+	}
+	return rows
+}
@@ -5,11 +5,24 @@ package cli
 
 import (
 	"encoding/json"
+	"strings"
+	"time"
 
+	"github.com/mainflux/mainflux/pkg/errors"
 	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/spf13/cobra"
 )
 
+const (
+	statusEnabled  = "enabled"
+	statusDisabled = "disabled"
+
+	// maxUsersPage is the largest page requested while auto-paging.
+	maxUsersPage = 100
+)
+
+var errInvalidStatus = errors.New("invalid status, expected enabled or disabled")
+
 // NewUsersCmd returns users command.
 func NewUsersCmd() *cobra.Command {
 	createCmd := cobra.Command{
@@ -41,7 +54,7 @@ func NewUsersCmd() *cobra.Command {
 		Short: "get <user_auth_token>",
 		Long:  `Returns user object`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 1 {
+			if args = withToken(args, 1); len(args) != 1 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -52,7 +65,7 @@ func NewUsersCmd() *cobra.Command {
 				return
 			}
 
-			logJSON(u)
+			logRecords(u, userColumns, [][]string{userRow(u)})
 		},
 	}
 
@@ -86,14 +99,14 @@ func NewUsersCmd() *cobra.Command {
 		Short: "update <JSON_string> <user_auth_token>",
 		Long:  `Update user metadata`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 2 {
+			if args = withToken(args, 2); len(args) != 2 {
 				logUsage(cmd.Short)
 				return
 			}
 
 			var user mfxsdk.User
 			if err := json.Unmarshal([]byte(args[0]), &user.Metadata); err != nil {
-				logError(err)
+				logError(errors.Wrap(errInvalidArgs, err))
 				return
 			}
 
@@ -111,7 +124,7 @@ func NewUsersCmd() *cobra.Command {
 		Short: "password <old_password> <password> <user_auth_token>",
 		Long:  `Update user password`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) != 3 {
+			if args = withToken(args, 3); len(args) != 3 {
 				logUsage(cmd.Short)
 				return
 			}
@@ -125,17 +138,126 @@ func NewUsersCmd() *cobra.Command {
 		},
 	}
 
+	var email, status string
+	listCmd := cobra.Command{
+		Use:   "list",
+		Short: "list [--email <email>] [--status enabled|disabled] <admin_auth_token>",
+		Long: `Lists users matching the email and status. Pages are fetched
+until --limit users are listed`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 1); len(args) != 1 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if err := validateStatus(status); err != nil {
+				logError(err)
+				return
+			}
+
+			up, err := listUsers(args[0], email, status)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			rows := [][]string{}
+			for _, u := range up.Users {
+				rows = append(rows, userRow(u))
+			}
+			logRecords(up, userColumns, rows)
+		},
+	}
+	listCmd.Flags().StringVar(&email, "email", "", "list users with email containing the given value")
+	listCmd.Flags().StringVar(&status, "status", "", "list users with the given status: enabled or disabled")
+
+	disableCmd := cobra.Command{
+		Use:   "disable",
+		Short: "disable <user_id> <admin_auth_token>",
+		Long:  `Disables user account`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 2); len(args) != 2 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if err := sdk.DisableUser(args[0], args[1]); err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		},
+	}
+
+	enableCmd := cobra.Command{
+		Use:   "enable",
+		Short: "enable <user_id> <admin_auth_token>",
+		Long:  `Enables disabled user account`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 2); len(args) != 2 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			if err := sdk.EnableUser(args[0], args[1]); err != nil {
+				logError(err)
+				return
+			}
+
+			logOK()
+		},
+	}
+
+	var from string
+	eventsCmd := cobra.Command{
+		Use:   "events",
+		Short: "events <user_id> [--from <time>] <admin_auth_token>",
+		Long: `Lists security events of the user recorded since the RFC3339
+timestamp or duration relative to now, e.g. -24h. Pages are fetched
+until --limit events are listed`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if args = withToken(args, 2); len(args) != 2 {
+				logUsage(cmd.Short)
+				return
+			}
+
+			s, err := parseTime(from, time.Now())
+			if err != nil {
+				logError(err)
+				return
+			}
+			var since time.Time
+			if s != 0 {
+				since = fromSeconds(s)
+			}
+
+			ep, err := listUserEvents(args[0], args[1], since)
+			if err != nil {
+				logError(err)
+				return
+			}
+
+			rows := [][]string{}
+			for _, e := range ep.Events {
+				rows = append(rows, userEventRow(e))
+			}
+			logRecords(ep, userEventColumns, rows)
+		},
+	}
+	eventsCmd.Flags().StringVar(&from, "from", "-24h", "list events recorded since the RFC3339 timestamp or relative duration")
+
 	cmd := cobra.Command{
 		Use:   "users",
 		Short: "Users management",
-		Long:  `Users management: create accounts and tokens"`,
+		Long:  `Users management: create accounts and tokens, administer user accounts"`,
 		Run: func(cmd *cobra.Command, args []string) {
-			logUsage("users [create | get | update | token | password]")
+			logUsage("users [create | get | update | token | password | list | disable | enable | events]")
 		},
 	}
 
 	cmdUsers := []cobra.Command{
-		createCmd, getCmd, tokenCmd, updateCmd, passwordCmd,
+		createCmd, getCmd, tokenCmd, updateCmd, passwordCmd, listCmd, disableCmd, enableCmd, eventsCmd,
 	}
 
 	for i := range cmdUsers {
@@ -144,3 +266,84 @@ func NewUsersCmd() *cobra.Command {
 
 	return &cmd
 }
+
+func validateStatus(s string) error {
+	switch strings.ToLower(s) {
+	case "", statusEnabled, statusDisabled:
+		return nil
+	default:
+		return errors.Wrap(errInvalidStatus, errors.New(s))
+	}
+}
+
+// pageSize returns the size of the pages fetched to list up to Limit items.
+func pageSize() uint64 {
+	if Limit > maxUsersPage {
+		return maxUsersPage
+	}
+	return uint64(Limit)
+}
+
+// listUsers fetches pages of users starting at Offset until Limit users
+// are listed or there are no more users.
+func listUsers(token, email, status string) (mfxsdk.UsersPage, error) {
+	up := mfxsdk.UsersPage{Users: []mfxsdk.User{}}
+	up.Offset, up.Limit = uint64(Offset), uint64(Limit)
+
+	offset, size := uint64(Offset), pageSize()
+	for uint64(len(up.Users)) < uint64(Limit) {
+		if rem := uint64(Limit) - uint64(len(up.Users)); rem < size {
+			size = rem
+		}
+		page, err := sdk.Users(token, offset, size, email, strings.ToLower(status))
+		if err != nil {
+			return mfxsdk.UsersPage{}, err
+		}
+		up.Users = append(up.Users, page.Users...)
+		up.Total = page.Total
+		offset += uint64(len(page.Users))
+		if uint64(len(page.Users)) < size || offset >= page.Total {
+			break
+		}
+	}
+
+	return up, nil
+}
+
+// listUserEvents fetches pages of user events the same way listUsers
+// fetches users.
+func listUserEvents(id, token string, from time.Time) (mfxsdk.UserEventsPage, error) {
+	ep := mfxsdk.UserEventsPage{Events: []mfxsdk.UserEvent{}}
+	ep.Offset, ep.Limit = uint64(Offset), uint64(Limit)
+
+	offset, size := uint64(Offset), pageSize()
+	for uint64(len(ep.Events)) < uint64(Limit) {
+		if rem := uint64(Limit) - uint64(len(ep.Events)); rem < size {
+			size = rem
+		}
+		page, err := sdk.UserEvents(id, token, from, offset, size)
+		if err != nil {
+			return mfxsdk.UserEventsPage{}, err
+		}
+		ep.Events = append(ep.Events, page.Events...)
+		ep.Total = page.Total
+		offset += uint64(len(page.Events))
+		if uint64(len(page.Events)) < size || offset >= page.Total {
+			break
+		}
+	}
+
+	return ep, nil
+}
+
+var userColumns = []string{"id", "email", "status", "metadata"}
+
+func userRow(u mfxsdk.User) []string {
+	return []string{u.ID, u.Email, u.Status, metadataValue(u.Metadata)}
+}
+
+var userEventColumns = []string{"id", "type", "time", "metadata"}
+
+func userEventRow(e mfxsdk.UserEvent) []string {
+	return []string{e.ID, e.Type, e.Time.UTC().Format(time.RFC3339), metadataValue(e.Metadata)}
+}
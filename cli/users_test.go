@@ -0,0 +1,244 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cli
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	mfxsdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newUsersMock() *sdkMock {
+	mock := newSDKMock()
+	for i := 1; i <= 5; i++ {
+		status := statusEnabled
+		if i%2 == 0 {
+			status = statusDisabled
+		}
+		mock.users = append(mock.users, mfxsdk.User{
+			ID:     fmt.Sprintf("user-%d", i),
+			Email:  fmt.Sprintf("user%d@acme.com", i),
+			Status: status,
+		})
+	}
+	mock.users = append(mock.users, mfxsdk.User{ID: "user-6", Email: "user6@example.com", Status: statusDisabled})
+	return mock
+}
+
+func TestUsersListCmd(t *testing.T) {
+	defer func(limit uint) { Format, Limit = formatJSON, limit }(Limit)
+	Format = formatCSV
+
+	cases := []struct {
+		desc  string
+		args  []string
+		limit uint
+		out   string
+		pages []string
+		code  int
+	}{
+		{
+			desc:  "list users by email and status",
+			args:  []string{"list", "--email", "@acme.com", "--status", "disabled", adminToken},
+			limit: 10,
+			out:   "id,email,status,metadata\nuser-2,user2@acme.com,disabled,\nuser-4,user4@acme.com,disabled,\n",
+			pages: []string{"0-10"},
+		},
+		{
+			desc:  "list users auto-paging up to the limit",
+			args:  []string{"list", adminToken},
+			limit: 250,
+			out: "id,email,status,metadata\nuser-1,user1@acme.com,enabled,\nuser-2,user2@acme.com,disabled,\n" +
+				"user-3,user3@acme.com,enabled,\nuser-4,user4@acme.com,disabled,\nuser-5,user5@acme.com,enabled,\n" +
+				"user-6,user6@example.com,disabled,\n",
+			pages: []string{"0-100"},
+		},
+		{
+			desc:  "list users capped by the limit",
+			args:  []string{"list", "--email", "@acme.com", adminToken},
+			limit: 3,
+			out:   "id,email,status,metadata\nuser-1,user1@acme.com,enabled,\nuser-2,user2@acme.com,disabled,\nuser-3,user3@acme.com,enabled,\n",
+			pages: []string{"0-3"},
+		},
+		{
+			desc:  "list users with invalid status",
+			args:  []string{"list", "--status", "locked", adminToken},
+			limit: 10,
+			code:  ExitInvalidArgs,
+		},
+		{
+			desc:  "list users with non-admin token",
+			args:  []string{"list", userToken},
+			limit: 10,
+			code:  ExitUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newUsersMock()
+		SetSDK(mock)
+		Limit = tc.limit
+
+		out, code := runCmd(t, NewUsersCmd(), nil, tc.args...)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected exit code %d got %d: %s", tc.desc, tc.code, code, out))
+		if tc.code != 0 {
+			continue
+		}
+		assert.Equal(t, tc.out, out, fmt.Sprintf("%s: unexpected output", tc.desc))
+		assert.Equal(t, tc.pages, mock.pages, fmt.Sprintf("%s: unexpected pages", tc.desc))
+	}
+}
+
+func TestUsersListCmdPages(t *testing.T) {
+	defer func(limit uint) { Format, Limit = formatJSON, limit }(Limit)
+	Format = formatCSV
+
+	mock := newSDKMock()
+	for i := 0; i < 230; i++ {
+		mock.users = append(mock.users, mfxsdk.User{ID: fmt.Sprintf("user-%03d", i), Email: fmt.Sprintf("user%03d@acme.com", i)})
+	}
+	SetSDK(mock)
+
+	cases := []struct {
+		desc  string
+		limit uint
+		pages []string
+	}{
+		{
+			desc:  "fetch all pages",
+			limit: 1000,
+			pages: []string{"0-100", "100-100", "200-100"},
+		},
+		{
+			desc:  "fetch pages up to the limit",
+			limit: 150,
+			pages: []string{"0-100", "100-50"},
+		},
+	}
+
+	for _, tc := range cases {
+		mock.pages = nil
+		Limit = tc.limit
+
+		_, code := runCmd(t, NewUsersCmd(), nil, "list", adminToken)
+		assert.Equal(t, 0, code, fmt.Sprintf("%s: unexpected exit code %d", tc.desc, code))
+		assert.Equal(t, tc.pages, mock.pages, fmt.Sprintf("%s: unexpected pages", tc.desc))
+	}
+}
+
+func TestUsersStatusCmd(t *testing.T) {
+	cases := []struct {
+		desc   string
+		args   []string
+		status string
+		code   int
+	}{
+		{
+			desc:   "disable user",
+			args:   []string{"disable", "user-1", adminToken},
+			status: statusDisabled,
+		},
+		{
+			desc:   "enable user",
+			args:   []string{"enable", "user-2", adminToken},
+			status: statusEnabled,
+		},
+		{
+			desc: "disable non-existing user",
+			args: []string{"disable", "user-9", adminToken},
+			code: ExitNotFound,
+		},
+		{
+			desc:   "disable user with non-admin token",
+			args:   []string{"disable", "user-1", userToken},
+			status: statusEnabled,
+			code:   ExitUnauthorized,
+		},
+		{
+			desc:   "enable user with non-admin token",
+			args:   []string{"enable", "user-2", userToken},
+			status: statusDisabled,
+			code:   ExitUnauthorized,
+		},
+		{
+			desc: "disable user without ID",
+			args: []string{"disable", adminToken},
+			code: ExitInvalidArgs,
+		},
+	}
+
+	for _, tc := range cases {
+		mock := newUsersMock()
+		SetSDK(mock)
+
+		out, code := runCmd(t, NewUsersCmd(), nil, tc.args...)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected exit code %d got %d: %s", tc.desc, tc.code, code, out))
+		if tc.status == "" {
+			continue
+		}
+		status := ""
+		for _, u := range mock.users {
+			if u.ID == tc.args[1] {
+				status = u.Status
+			}
+		}
+		assert.Equal(t, tc.status, status, fmt.Sprintf("%s: expected status %s got %s", tc.desc, tc.status, status))
+	}
+}
+
+func TestUsersEventsCmd(t *testing.T) {
+	defer func(limit uint) { Format, Limit = formatJSON, limit }(Limit)
+	Format = formatCSV
+	Limit = 10
+
+	now := time.Now().UTC().Truncate(time.Second)
+	mock := newUsersMock()
+	mock.events = []mfxsdk.UserEvent{
+		{ID: "event-1", UserID: "user-1", Type: "login_failed", Time: now.Add(-48 * time.Hour)},
+		{ID: "event-2", UserID: "user-1", Type: "password_changed", Time: now.Add(-time.Hour)},
+		{ID: "event-3", UserID: "user-2", Type: "login_failed", Time: now.Add(-time.Minute)},
+	}
+	SetSDK(mock)
+
+	cases := []struct {
+		desc string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			desc: "list events of the last day",
+			args: []string{"events", "user-1", adminToken},
+			out:  fmt.Sprintf("id,type,time,metadata\nevent-2,password_changed,%s,\n", now.Add(-time.Hour).Format(time.RFC3339)),
+		},
+		{
+			desc: "list events since the timestamp",
+			args: []string{"events", "user-1", "--from", now.Add(-72 * time.Hour).Format(time.RFC3339), adminToken},
+			out: fmt.Sprintf("id,type,time,metadata\nevent-1,login_failed,%s,\nevent-2,password_changed,%s,\n",
+				now.Add(-48*time.Hour).Format(time.RFC3339), now.Add(-time.Hour).Format(time.RFC3339)),
+		},
+		{
+			desc: "list events with invalid start time",
+			args: []string{"events", "user-1", "--from", "yesterday", adminToken},
+			code: ExitInvalidArgs,
+		},
+		{
+			desc: "list events with non-admin token",
+			args: []string{"events", "user-1", userToken},
+			code: ExitUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		out, code := runCmd(t, NewUsersCmd(), nil, tc.args...)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected exit code %d got %d: %s", tc.desc, tc.code, code, out))
+		if tc.code != 0 {
+			continue
+		}
+		assert.Equal(t, tc.out, out, fmt.Sprintf("%s: unexpected output", tc.desc))
+	}
+}
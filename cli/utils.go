@@ -4,8 +4,12 @@
 package cli
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/fatih/color"
 	prettyjson "github.com/hokaccha/go-prettyjson"
@@ -22,16 +26,29 @@ var (
 	ConfigPath string = ""
 	// RawOutput raw output mode
 	RawOutput bool = false
+	// Format output format parameter
+	Format string = formatJSON
+	// ProfileName config profile parameter
+	ProfileName string = ""
+	// Token user token used when the token argument is omitted
+	Token string = ""
 )
 
+// logJSON prints values as JSON. Output is colored only if it's written to
+// a terminal, so it stays machine readable when piped.
 func logJSON(iList ...interface{}) {
 	for _, i := range iList {
-		m, err := json.Marshal(i)
+		m, err := json.MarshalIndent(i, "", "  ")
 		if err != nil {
 			logError(err)
 			return
 		}
 
+		if color.NoColor {
+			fmt.Println(string(m))
+			continue
+		}
+
 		pj, err := prettyjson.Format(m)
 		if err != nil {
 			logError(err)
@@ -42,15 +59,39 @@ func logJSON(iList ...interface{}) {
 	}
 }
 
+// logRecords prints the value as JSON, or its rows as a table or CSV,
+// depending on the output format.
+func logRecords(v interface{}, columns []string, rows [][]string) {
+	switch Format {
+	case formatTable:
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, r := range rows {
+			fmt.Fprintln(tw, strings.Join(r, "\t"))
+		}
+		tw.Flush()
+	case formatCSV:
+		w := csv.NewWriter(os.Stdout)
+		w.Write(columns)
+		w.WriteAll(rows)
+	default:
+		logJSON(v)
+	}
+}
+
+// logUsage prints the command usage and exits with ExitInvalidArgs.
 func logUsage(u string) {
 	fmt.Printf(color.YellowString("\nusage: %s\n\n"), u)
+	exit(ExitInvalidArgs)
 }
 
+// logError prints the error and exits with the code of its class.
 func logError(err error) {
 	boldRed := color.New(color.FgRed, color.Bold)
 	boldRed.Print("\nerror: ")
 
 	fmt.Printf("%s\n\n", color.RedString(err.Error()))
+	exit(ExitCode(err))
 }
 
 func logOK() {
@@ -64,3 +105,15 @@ func logCreated(e string) {
 		fmt.Printf(color.BlueString("\ncreated: %s\n\n"), e)
 	}
 }
+
+// metadataValue renders metadata as compact JSON table or CSV cell.
+func metadataValue(m map[string]interface{}) string {
+	if len(m) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -91,6 +91,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("auth"))
+
 	db := connectToDB(cfg.dbConfig, logger)
 	defer db.Close()
 
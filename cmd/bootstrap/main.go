@@ -30,6 +30,7 @@ import (
 	"github.com/mainflux/mainflux/bootstrap"
 	api "github.com/mainflux/mainflux/bootstrap/api"
 	"github.com/mainflux/mainflux/bootstrap/postgres"
+	"github.com/mainflux/mainflux/internal/retry"
 	mflog "github.com/mainflux/mainflux/logger"
 	mfsdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -64,9 +65,14 @@ const (
 	defESPass         = ""
 	defESDB           = "0"
 	defESConsumerName = "bootstrap"
+	defESReplayFrom   = ""
 	defJaegerURL      = ""
 	defAuthURL        = "localhost:8181"
 	defAuthTimeout    = "1s"
+	defRetryInterval  = "100ms"
+	defRetryMaxInt    = "2s"
+	defRetryJitter    = "0.5"
+	defRetryMaxTime   = "10s"
 
 	envLogLevel       = "MF_BOOTSTRAP_LOG_LEVEL"
 	envDBHost         = "MF_BOOTSTRAP_DB_HOST"
@@ -93,9 +99,14 @@ const (
 	envESPass         = "MF_BOOTSTRAP_ES_PASS"
 	envESDB           = "MF_BOOTSTRAP_ES_DB"
 	envESConsumerName = "MF_BOOTSTRAP_EVENT_CONSUMER"
+	envESReplayFrom   = "MF_BOOTSTRAP_EVENT_REPLAY_FROM"
 	envJaegerURL      = "MF_JAEGER_URL"
 	envAuthURL        = "MF_AUTH_GRPC_URL"
 	envAuthTimeout    = "MF_AUTH_GRPC_TIMEOUT"
+	envRetryInterval  = "MF_BOOTSTRAP_RETRY_INITIAL_INTERVAL"
+	envRetryMaxInt    = "MF_BOOTSTRAP_RETRY_MAX_INTERVAL"
+	envRetryJitter    = "MF_BOOTSTRAP_RETRY_JITTER"
+	envRetryMaxTime   = "MF_BOOTSTRAP_RETRY_MAX_ELAPSED_TIME"
 )
 
 type config struct {
@@ -116,9 +127,11 @@ type config struct {
 	esPass         string
 	esDB           string
 	esConsumerName string
+	esReplayFrom   string
 	jaegerURL      string
 	authURL        string
 	authTimeout    time.Duration
+	retry          retry.Config
 }
 
 func main() {
@@ -129,6 +142,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("bootstrap"))
+
 	db := connectToDB(cfg.dbConfig, logger)
 	defer db.Close()
 
@@ -150,7 +165,7 @@ func main() {
 	errs := make(chan error, 2)
 
 	go startHTTPServer(svc, cfg, logger, errs)
-	go subscribeToThingsES(svc, thingsESConn, cfg.esConsumerName, logger)
+	go subscribeToThingsES(svc, thingsESConn, cfg.esConsumerName, cfg.esReplayFrom, logger)
 
 	go func() {
 		c := make(chan os.Signal)
@@ -194,6 +209,11 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envEncryptKey, err.Error())
 	}
 
+	rc, err := loadRetryConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	return config{
 		logLevel:       mainflux.Env(envLogLevel, defLogLevel),
 		dbConfig:       dbConfig,
@@ -212,10 +232,38 @@ func loadConfig() config {
 		esPass:         mainflux.Env(envESPass, defESPass),
 		esDB:           mainflux.Env(envESDB, defESDB),
 		esConsumerName: mainflux.Env(envESConsumerName, defESConsumerName),
+		esReplayFrom:   mainflux.Env(envESReplayFrom, defESReplayFrom),
 		jaegerURL:      mainflux.Env(envJaegerURL, defJaegerURL),
 		authURL:        mainflux.Env(envAuthURL, defAuthURL),
 		authTimeout:    authTimeout,
+		retry:          rc,
+	}
+}
+
+func loadRetryConfig() (retry.Config, error) {
+	interval, err := time.ParseDuration(mainflux.Env(envRetryInterval, defRetryInterval))
+	if err != nil {
+		return retry.Config{}, fmt.Errorf("Invalid %s value: %s", envRetryInterval, err.Error())
+	}
+	maxInterval, err := time.ParseDuration(mainflux.Env(envRetryMaxInt, defRetryMaxInt))
+	if err != nil {
+		return retry.Config{}, fmt.Errorf("Invalid %s value: %s", envRetryMaxInt, err.Error())
+	}
+	jitter, err := strconv.ParseFloat(mainflux.Env(envRetryJitter, defRetryJitter), 64)
+	if err != nil || jitter < 0 || jitter > 1 {
+		return retry.Config{}, fmt.Errorf("Invalid %s value: expected number between 0 and 1", envRetryJitter)
 	}
+	maxTime, err := time.ParseDuration(mainflux.Env(envRetryMaxTime, defRetryMaxTime))
+	if err != nil {
+		return retry.Config{}, fmt.Errorf("Invalid %s value: %s", envRetryMaxTime, err.Error())
+	}
+
+	return retry.Config{
+		InitialInterval: interval,
+		MaxInterval:     maxInterval,
+		Jitter:          jitter,
+		MaxElapsedTime:  maxTime,
+	}, nil
 }
 
 func connectToDB(cfg postgres.Config, logger mflog.Logger) *sqlx.DB {
@@ -275,7 +323,12 @@ func newService(auth mainflux.AuthServiceClient, db *sqlx.DB, logger mflog.Logge
 
 	sdk := mfsdk.NewSDK(config)
 
-	svc := bootstrap.New(auth, thingsRepo, sdk, cfg.encKey)
+	rc := cfg.retry
+	rc.Notify = func(err error, attempt int, next time.Duration) {
+		logger.Warn(fmt.Sprintf("Things service call failed at attempt %d, retrying in %s: %s", attempt, next, err))
+	}
+
+	svc := bootstrap.New(auth, thingsRepo, sdk, cfg.encKey, rc)
 	svc = redisprod.NewEventStoreMiddleware(svc, esClient)
 	svc = api.NewLoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
@@ -333,8 +386,14 @@ func startHTTPServer(svc bootstrap.Service, cfg config, logger mflog.Logger, err
 	errs <- http.ListenAndServe(p, api.MakeHandler(svc, bootstrap.NewConfigReader(cfg.encKey)))
 }
 
-func subscribeToThingsES(svc bootstrap.Service, client *r.Client, consumer string, logger mflog.Logger) {
+func subscribeToThingsES(svc bootstrap.Service, client *r.Client, consumer, replayFrom string, logger mflog.Logger) {
 	eventStore := rediscons.NewEventStore(svc, client, consumer, logger)
+	if replayFrom != "" {
+		if err := eventStore.Replay(context.Background(), replayFrom); err != nil {
+			logger.Error(fmt.Sprintf("Failed to replay events from %s: %s", replayFrom, err))
+			os.Exit(1)
+		}
+	}
 	logger.Info("Subscribed to Redis Event Store")
 	if err := eventStore.Subscribe(context.Background(), "mainflux.things"); err != nil {
 		logger.Warn(fmt.Sprintf("Bootstrap service failed to subscribe to event sourcing: %s", err))
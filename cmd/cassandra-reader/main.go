@@ -19,10 +19,12 @@ import (
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/gocql/gocql"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
 	"github.com/mainflux/mainflux/readers/cassandra"
+	"github.com/mainflux/mainflux/readers/tracing"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -48,6 +50,9 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defAdminEmail        = ""
 
 	envLogLevel          = "MF_CASSANDRA_READER_LOG_LEVEL"
 	envPort              = "MF_CASSANDRA_READER_PORT"
@@ -63,6 +68,9 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envAdminEmail        = "MF_CASSANDRA_READER_ADMIN_EMAIL"
 )
 
 type config struct {
@@ -76,6 +84,9 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	adminEmail        string
 }
 
 func main() {
@@ -86,21 +97,38 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("cassandra-reader"))
+
 	session := connectToCassandra(cfg.dbCfg, logger)
 	defer session.Close()
 
-	conn := connectToThings(cfg, logger)
+	conn := connectToGRPC(cfg.thingsAuthURL, "things", cfg, logger)
 	defer conn.Close()
 
 	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
 	defer thingsCloser.Close()
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
-	repo := newService(session, logger)
+
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	admin, authConn := createAdmin(cfg, authTracer, logger)
+	if authConn != nil {
+		defer authConn.Close()
+	}
+
+	tracer, closer := initJaeger("cassandra-reader", cfg.jaegerURL, logger)
+	defer closer.Close()
+
+	dbTracer, dbCloser := initJaeger("cassandra-reader_db", cfg.jaegerURL, logger)
+	defer dbCloser.Close()
+
+	repo := newService(session, dbTracer, logger)
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(repo, tc, cfg, errs, logger)
+	go startHTTPServer(tracer, repo, tc, admin, cfg, errs, logger)
 
 	go func() {
 		c := make(chan os.Signal)
@@ -136,6 +164,11 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	usersAuthTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
 	return config{
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
 		port:              mainflux.Env(envPort, defPort),
@@ -147,6 +180,9 @@ func loadConfig() config {
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		thingsAuthTimeout: authTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       usersAuthTimeout,
+		adminEmail:        mainflux.Env(envAdminEmail, defAdminEmail),
 	}
 }
 
@@ -160,7 +196,23 @@ func connectToCassandra(dbCfg cassandra.DBConfig, logger logger.Logger) *gocql.S
 	return session
 }
 
-func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
+// createAdmin returns the reader admin identified by the auth service, along
+// with the auth service gRPC connection. The connection is nil if the admin
+// email isn't set, which disables the filtering by tenant.
+func createAdmin(cfg config, tracer opentracing.Tracer, logger logger.Logger) (api.Admin, *grpc.ClientConn) {
+	if cfg.adminEmail == "" {
+		return api.Admin{}, nil
+	}
+
+	conn := connectToGRPC(cfg.authURL, "auth", cfg, logger)
+	admin := api.Admin{
+		Auth:  authapi.NewClient(tracer, conn, cfg.authTimeout),
+		Email: cfg.adminEmail,
+	}
+	return admin, conn
+}
+
+func connectToGRPC(url, svc string, cfg config, logger logger.Logger) *grpc.ClientConn {
 	var opts []grpc.DialOption
 	if cfg.clientTLS {
 		if cfg.caCerts != "" {
@@ -176,9 +228,9 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 		opts = append(opts, grpc.WithInsecure())
 	}
 
-	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	conn, err := grpc.Dial(url, opts...)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		logger.Error(fmt.Sprintf("Failed to connect to %s service: %s", svc, err))
 		os.Exit(1)
 	}
 	return conn
@@ -208,8 +260,8 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 	return tracer, closer
 }
 
-func newService(session *gocql.Session, logger logger.Logger) readers.MessageRepository {
-	repo := cassandra.New(session)
+func newService(session *gocql.Session, tracer opentracing.Tracer, logger logger.Logger) readers.MessageRepository {
+	repo := tracing.MessageRepositoryMiddleware(tracer, cassandra.New(session))
 	repo = api.LoggingMiddleware(repo, logger)
 	repo = api.MetricsMiddleware(
 		repo,
@@ -230,14 +282,14 @@ func newService(session *gocql.Session, logger logger.Logger) readers.MessageRep
 	return repo
 }
 
-func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, cfg config, errs chan error, logger logger.Logger) {
+func startHTTPServer(tracer opentracing.Tracer, repo readers.MessageRepository, tc mainflux.ThingsServiceClient, admin api.Admin, cfg config, errs chan error, logger logger.Logger) {
 	p := fmt.Sprintf(":%s", cfg.port)
 	if cfg.serverCert != "" || cfg.serverKey != "" {
 		logger.Info(fmt.Sprintf("Cassandra reader service started using https on port %s with cert %s key %s",
 			cfg.port, cfg.serverCert, cfg.serverKey))
-		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, "cassandra-reader"))
+		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tracer, tc, admin, "cassandra-reader"))
 		return
 	}
 	logger.Info(fmt.Sprintf("Cassandra reader service started, exposed port %s", cfg.port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, "cassandra-reader"))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tracer, tc, admin, "cassandra-reader"))
 }
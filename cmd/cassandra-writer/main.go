@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,58 +13,98 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	"github.com/gocql/gocql"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/consumers/schemas"
 	"github.com/mainflux/mainflux/consumers/writers/api"
 	"github.com/mainflux/mainflux/consumers/writers/cassandra"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/decompress"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/limits"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/things"
+	authhttpapi "github.com/mainflux/mainflux/things/api/auth/http"
+	broker "github.com/nats-io/nats.go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 )
 
 const (
-	svcName = "cassandra-writer"
-	sep     = ","
-
-	defNatsURL     = "nats://localhost:4222"
-	defLogLevel    = "error"
-	defPort        = "8180"
-	defCluster     = "127.0.0.1"
-	defKeyspace    = "mainflux"
-	defDBUser      = "mainflux"
-	defDBPass      = "mainflux"
-	defDBPort      = "9042"
-	defConfigPath  = "/config.toml"
-	defContentType = "application/senml+json"
-	defTransformer = "senml"
-
-	envNatsURL     = "MF_NATS_URL"
-	envLogLevel    = "MF_CASSANDRA_WRITER_LOG_LEVEL"
-	envPort        = "MF_CASSANDRA_WRITER_PORT"
-	envCluster     = "MF_CASSANDRA_WRITER_DB_CLUSTER"
-	envKeyspace    = "MF_CASSANDRA_WRITER_DB_KEYSPACE"
-	envDBUser      = "MF_CASSANDRA_WRITER_DB_USER"
-	envDBPass      = "MF_CASSANDRA_WRITER_DB_PASS"
-	envDBPort      = "MF_CASSANDRA_WRITER_DB_PORT"
-	envConfigPath  = "MF_CASSANDRA_WRITER_CONFIG_PATH"
-	envContentType = "MF_CASSANDRA_WRITER_CONTENT_TYPE"
-	envTransformer = "MF_CASSANDRA_WRITER_TRANSFORMER"
+	svcName       = "cassandra-writer"
+	thingsTimeout = 5 * time.Second
+	sep           = ","
+
+	defNatsURL           = "nats://localhost:4222"
+	defLogLevel          = "error"
+	defPort              = "8180"
+	defCluster           = "127.0.0.1"
+	defKeyspace          = "mainflux"
+	defDBUser            = "mainflux"
+	defDBPass            = "mainflux"
+	defDBPort            = "9042"
+	defConfigPath        = "/config.toml"
+	defContentType       = "application/senml+json"
+	defTransformer       = "senml"
+	defProtoDescriptors  = "/descriptors.pb"
+	defDecompressLimit   = "10485760"
+	defThingsESURL       = ""
+	defThingsESPass      = ""
+	defThingsESDB        = "0"
+	defThingsURL         = ""
+	defThingsSecret      = ""
+	defRetention         = "0"
+	defRetentionInterval = "1h"
+
+	envNatsURL           = "MF_NATS_URL"
+	envLogLevel          = "MF_CASSANDRA_WRITER_LOG_LEVEL"
+	envPort              = "MF_CASSANDRA_WRITER_PORT"
+	envCluster           = "MF_CASSANDRA_WRITER_DB_CLUSTER"
+	envKeyspace          = "MF_CASSANDRA_WRITER_DB_KEYSPACE"
+	envDBUser            = "MF_CASSANDRA_WRITER_DB_USER"
+	envDBPass            = "MF_CASSANDRA_WRITER_DB_PASS"
+	envDBPort            = "MF_CASSANDRA_WRITER_DB_PORT"
+	envConfigPath        = "MF_CASSANDRA_WRITER_CONFIG_PATH"
+	envContentType       = "MF_CASSANDRA_WRITER_CONTENT_TYPE"
+	envTransformer       = "MF_CASSANDRA_WRITER_TRANSFORMER"
+	envProtoDescriptors  = "MF_CASSANDRA_WRITER_PROTOBUF_DESCRIPTORS"
+	envDecompressLimit   = "MF_CASSANDRA_WRITER_DECOMPRESS_LIMIT"
+	envThingsESURL       = "MF_CASSANDRA_WRITER_THINGS_ES_URL"
+	envThingsESPass      = "MF_CASSANDRA_WRITER_THINGS_ES_PASS"
+	envThingsESDB        = "MF_CASSANDRA_WRITER_THINGS_ES_DB"
+	envThingsURL         = "MF_CASSANDRA_WRITER_THINGS_URL"
+	envThingsSecret      = "MF_CASSANDRA_WRITER_THINGS_SECRET"
+	envRetention         = "MF_CASSANDRA_WRITER_RETENTION"
+	envRetentionInterval = "MF_CASSANDRA_WRITER_RETENTION_INTERVAL"
 )
 
 type config struct {
-	natsURL     string
-	logLevel    string
-	port        string
-	configPath  string
-	contentType string
-	transformer string
-	dbCfg       cassandra.DBConfig
+	natsURL           string
+	logLevel          string
+	port              string
+	configPath        string
+	contentType       string
+	transformer       string
+	protoDescriptors  string
+	decompressLimit   int64
+	thingsESURL       string
+	thingsESPass      string
+	thingsESDB        string
+	thingsURL         string
+	thingsSecret      string
+	retention         time.Duration
+	retentionInterval time.Duration
+	dbCfg             cassandra.DBConfig
 }
 
 func main() {
@@ -74,6 +115,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo(svcName))
+
 	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
@@ -85,12 +128,18 @@ func main() {
 	defer session.Close()
 
 	repo := newService(session, logger)
-	t := makeTransformer(cfg, logger)
+	t := decompress.New(cfg.decompressLimit, makeLimits(makeTransformer(cfg, logger), cfg, logger))
+	t, errsConn := makeErrorsMiddleware(t, cfg, logger)
+	if errsConn != nil {
+		defer errsConn.Close()
+	}
 
-	if err := consumers.Start(pubSub, repo, t, cfg.configPath, logger); err != nil {
+	if err := consumers.Start(makeLatencySubscriber(pubSub), repo, t, cfg.configPath, logger); err != nil {
 		logger.Error(fmt.Sprintf("Failed to create Cassandra writer: %s", err))
 	}
 
+	startRetention(cassandra.NewRetentionStore(session, cfg.dbCfg.Keyspace), cfg, logger)
+
 	errs := make(chan error, 2)
 
 	go startHTTPServer(cfg.port, errs, logger)
@@ -106,6 +155,21 @@ func main() {
 }
 
 func loadConfig() config {
+	decompressLimit, err := strconv.ParseInt(mainflux.Env(envDecompressLimit, defDecompressLimit), 10, 64)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defaultRetention, err := time.ParseDuration(mainflux.Env(envRetention, defRetention))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetention, err.Error())
+	}
+
+	retentionInterval, err := time.ParseDuration(mainflux.Env(envRetentionInterval, defRetentionInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetentionInterval, err.Error())
+	}
+
 	dbPort, err := strconv.Atoi(mainflux.Env(envDBPort, defDBPort))
 	if err != nil {
 		log.Fatal(err)
@@ -120,13 +184,22 @@ func loadConfig() config {
 	}
 
 	return config{
-		natsURL:     mainflux.Env(envNatsURL, defNatsURL),
-		logLevel:    mainflux.Env(envLogLevel, defLogLevel),
-		port:        mainflux.Env(envPort, defPort),
-		configPath:  mainflux.Env(envConfigPath, defConfigPath),
-		contentType: mainflux.Env(envContentType, defContentType),
-		transformer: mainflux.Env(envTransformer, defTransformer),
-		dbCfg:       dbCfg,
+		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
+		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
+		port:              mainflux.Env(envPort, defPort),
+		configPath:        mainflux.Env(envConfigPath, defConfigPath),
+		contentType:       mainflux.Env(envContentType, defContentType),
+		transformer:       mainflux.Env(envTransformer, defTransformer),
+		protoDescriptors:  mainflux.Env(envProtoDescriptors, defProtoDescriptors),
+		decompressLimit:   decompressLimit,
+		thingsESURL:       mainflux.Env(envThingsESURL, defThingsESURL),
+		thingsESPass:      mainflux.Env(envThingsESPass, defThingsESPass),
+		thingsESDB:        mainflux.Env(envThingsESDB, defThingsESDB),
+		thingsURL:         mainflux.Env(envThingsURL, defThingsURL),
+		thingsSecret:      mainflux.Env(envThingsSecret, defThingsSecret),
+		retention:         defaultRetention,
+		retentionInterval: retentionInterval,
+		dbCfg:             dbCfg,
 	}
 }
 
@@ -169,7 +242,20 @@ func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer
 		return senml.New(cfg.contentType)
 	case "JSON":
 		logger.Info("Using JSON transformer")
-		return json.New()
+		return makeJSONTransformer(cfg, logger)
+	case "PROTOBUF":
+		logger.Info("Using protobuf transformer")
+		pc, err := consumers.LoadProtobufConfig(cfg.configPath)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Continue without protobuf message types, failed to load them: %s", err))
+		}
+		pc.Descriptors = cfg.protoDescriptors
+		t, err := protobuf.New(pc, makeJSONTransformer(cfg, logger))
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to create protobuf transformer: %s", err))
+			os.Exit(1)
+		}
+		return t
 	default:
 		logger.Error(fmt.Sprintf("Can't create transformer: unknown transformer type %s", cfg.transformer))
 		os.Exit(1)
@@ -177,8 +263,158 @@ func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer
 	}
 }
 
+func makeJSONTransformer(cfg config, logger logger.Logger) transformers.Transformer {
+	sc := makeSchemas(cfg, logger)
+	tc, err := consumers.LoadTransformerConfig(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default JSON transformer settings, failed to load them: %s", err))
+		return json.NewWithSchemas(json.Config{}, nil, sc)
+	}
+	fallbacks := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "cassandra",
+		Subsystem: "message_writer",
+		Name:      "time_fallback_count",
+		Help:      "Number of messages stored with arrival time instead of payload time.",
+	}, []string{"reason"})
+	return json.NewWithSchemas(tc, fallbacks, sc)
+}
+
+// makeSchemas returns the channel schemas kept up to date using the things
+// events, or nil if the things event store isn't set.
+func makeSchemas(cfg config, logger logger.Logger) json.Schemas {
+	if cfg.thingsESURL == "" {
+		return nil
+	}
+	client := connectToThingsES(cfg, logger)
+
+	cache := schemas.NewCache()
+	sub := schemas.NewSubscriber(client, cache, logger)
+	if err := loadChannels(sub, things.SchemaKey, cfg); err != nil {
+		logger.Error(fmt.Sprintf("Failed to load channel schemas: %s", err))
+		os.Exit(1)
+	}
+	go func() {
+		if err := sub.Subscribe(context.Background()); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to subscribe to things event store: %s", err))
+		}
+	}()
+
+	logger.Info("Validating JSON messages against channel schemas")
+	return cache
+}
+
+// loadChannels brings the handler of the subscriber up to date. The channels
+// having the metadata key set are seeded from the things service, if it's
+// set, and the events are used only for the later changes. Otherwise the
+// events stored in the stream are replayed.
+func loadChannels(sub *schemas.Subscriber, key string, cfg config) error {
+	if cfg.thingsURL == "" {
+		return sub.Load(context.Background())
+	}
+	return sub.Seed(context.Background(), authhttpapi.NewClient(cfg.thingsURL, cfg.thingsSecret, thingsTimeout), key)
+}
+
+// startRetention purges the expired messages in the background. The channels
+// retention hints are kept up to date using the things events, if the things
+// event store is set.
+func startRetention(store retention.Store, cfg config, logger logger.Logger) {
+	if cfg.retention == 0 && cfg.thingsESURL == "" {
+		return
+	}
+
+	cache := retention.NewCache()
+	if cfg.thingsESURL != "" {
+		sub := schemas.NewSubscriber(connectToThingsES(cfg, logger), cache, logger)
+		if err := loadChannels(sub, things.RetentionKey, cfg); err != nil {
+			logger.Error(fmt.Sprintf("Failed to load channel retention hints: %s", err))
+			os.Exit(1)
+		}
+		go func() {
+			if err := sub.Subscribe(context.Background()); err != nil {
+				logger.Warn(fmt.Sprintf("Failed to subscribe to things event store: %s", err))
+			}
+		}()
+	}
+
+	p := retention.NewPurger(store, cache, cfg.retention, logger)
+	go func() {
+		if err := p.Run(context.Background(), cfg.retentionInterval); err != nil {
+			logger.Warn(fmt.Sprintf("Messages purger stopped: %s", err))
+		}
+	}()
+	logger.Info(fmt.Sprintf("Purging expired messages every %s", cfg.retentionInterval))
+}
+
+func connectToThingsES(cfg config, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(cfg.thingsESDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to things event store: %s", err))
+		os.Exit(1)
+	}
+	return r.NewClient(&r.Options{
+		Addr:     cfg.thingsESURL,
+		Password: cfg.thingsESPass,
+		DB:       db,
+	})
+}
+
 func startHTTPServer(port string, errs chan error, logger logger.Logger) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("Cassandra writer service started, exposed port %s", port))
 	errs <- http.ListenAndServe(p, api.MakeHandler(svcName))
 }
+
+// makeLimits rejects the messages exceeding the limits before they reach
+// the database.
+func makeLimits(t transformers.Transformer, cfg config, logger logger.Logger) transformers.Transformer {
+	lc, err := consumers.LoadLimitsConfig(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default message limits, failed to load them: %s", err))
+	}
+	return limits.New(lc, t)
+}
+
+// makeErrorsMiddleware returns the transformer reporting the transform errors
+// and the NATS connection the error events are published to, if any.
+func makeErrorsMiddleware(t transformers.Transformer, cfg config, logger logger.Logger) (transformers.Transformer, *broker.Conn) {
+	ec, err := consumers.LoadErrorsConfig(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default transform errors settings, failed to load them: %s", err))
+	}
+
+	var pub consumers.EventPublisher
+	var conn *broker.Conn
+	if ec.Subject != "" {
+		conn, err = broker.Connect(cfg.natsURL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to connect to NATS for transform error events: %s", err))
+			os.Exit(1)
+		}
+		pub = conn
+	}
+
+	counter := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "cassandra",
+		Subsystem: "message_writer",
+		Name:      "transform_error_count",
+		Help:      "Number of messages failed to transform.",
+	}, []string{"transformer", "channel", "reason"})
+	return consumers.ErrorsMiddleware(t, strings.ToLower(cfg.transformer), counter, pub, ec, logger), conn
+}
+
+func makeLatencySubscriber(sub messaging.Subscriber) messaging.Subscriber {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "cassandra",
+		Subsystem: "message_writer",
+		Name:      "write_latency_seconds",
+		Help:      "Time between the message creation by the adapter and its storage.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "cassandra",
+		Subsystem: "message_writer",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than stored due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewSubscriber(sub, hist, skewed)
+}
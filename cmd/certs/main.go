@@ -148,6 +148,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("certs"))
+
 	tlsCert, caCert, err := loadCertificates(cfg)
 	if err != nil {
 		logger.Error("Failed to load CA certificates for issuing client certs")
@@ -4,14 +4,33 @@
 package main
 
 import (
+	"errors"
 	"log"
+	"os"
 
 	"github.com/mainflux/mainflux/cli"
 	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/spf13/cobra"
 )
 
+// configError is returned if the config can't be applied.
+type configError struct {
+	error
+}
+
 func main() {
+	if err := newRootCmd().Execute(); err != nil {
+		log.Println(err)
+		var ce configError
+		if errors.As(err, &ce) {
+			os.Exit(cli.ExitCode(ce.error))
+		}
+		os.Exit(cli.ExitInvalidArgs)
+	}
+}
+
+// newRootCmd returns the root command with all the commands and flags.
+func newRootCmd() *cobra.Command {
 	msgContentType := string(sdk.CTJSONSenML)
 	sdkConf := sdk.Config{
 		BaseURL:           "http://localhost",
@@ -31,12 +50,16 @@ func main() {
 	// Root
 	var rootCmd = &cobra.Command{
 		Use: "mainflux-cli",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			cli.ParseConfig()
-
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			sdkConf.MsgContentType = sdk.ContentType(msgContentType)
+			if err := cli.ParseConfig(cmd, &sdkConf); err != nil {
+				cmd.SilenceUsage, cmd.SilenceErrors = true, true
+				return configError{err}
+			}
+
 			s := sdk.NewSDK(sdkConf)
 			cli.SetSDK(s)
+			return nil
 		},
 	}
 
@@ -50,6 +73,10 @@ func main() {
 	provisionCmd := cli.NewProvisionCmd()
 	bootstrapCmd := cli.NewBootstrapCmd()
 	certsCmd := cli.NewCertsCmd()
+	configCmd := cli.NewConfigCmd()
+	connectCmd := cli.NewConnectCmd()
+	disconnectCmd := cli.NewDisconnectCmd()
+	shellCmd := cli.NewShellCmd(newRootCmd)
 
 	// Root Commands
 	rootCmd.AddCommand(versionCmd)
@@ -61,6 +88,10 @@ func main() {
 	rootCmd.AddCommand(provisionCmd)
 	rootCmd.AddCommand(bootstrapCmd)
 	rootCmd.AddCommand(certsCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(connectCmd)
+	rootCmd.AddCommand(disconnectCmd)
+	rootCmd.AddCommand(shellCmd)
 
 	// Root Flags
 	rootCmd.PersistentFlags().StringVarP(
@@ -71,6 +102,27 @@ func main() {
 		"Mainflux host URL",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&sdkConf.ReaderURL,
+		"reader-url",
+		sdkConf.ReaderURL,
+		"Mainflux reader URL",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&sdkConf.BootstrapURL,
+		"bootstrap-url",
+		sdkConf.BootstrapURL,
+		"Mainflux bootstrap service URL",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&sdkConf.CertsURL,
+		"certs-url",
+		sdkConf.CertsURL,
+		"Mainflux certs service URL",
+	)
+
 	rootCmd.PersistentFlags().StringVarP(
 		&sdkConf.UsersPrefix,
 		"users-prefix",
@@ -119,6 +171,27 @@ func main() {
 		"Do not check for TLS cert",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&sdkConf.CACertPath,
+		"ca-cert",
+		"",
+		"Path to the CA certificate used to verify Mainflux services",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&sdkConf.ClientCertPath,
+		"client-cert",
+		"",
+		"Path to the client certificate",
+	)
+
+	rootCmd.PersistentFlags().StringVar(
+		&sdkConf.ClientKeyPath,
+		"client-key",
+		"",
+		"Path to the client certificate key",
+	)
+
 	rootCmd.PersistentFlags().StringVar(
 		&cli.ConfigPath,
 		"config",
@@ -126,6 +199,13 @@ func main() {
 		"Mainflux config path",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&cli.ProfileName,
+		"profile",
+		"",
+		"Mainflux config profile",
+	)
+
 	rootCmd.PersistentFlags().BoolVar(
 		&cli.RawOutput,
 		"raw",
@@ -133,6 +213,22 @@ func main() {
 		"Enables raw output mode for easier parsing of output",
 	)
 
+	rootCmd.PersistentFlags().StringVar(
+		&cli.Format,
+		"output",
+		"json",
+		"Output format: json, table or csv",
+	)
+
+	// Alias of the output flag kept for compatibility
+	rootCmd.PersistentFlags().StringVar(
+		&cli.Format,
+		"format",
+		"json",
+		"Output format: json, table or csv",
+	)
+	rootCmd.PersistentFlags().MarkHidden("format")
+
 	// Client and Channels Flags
 	rootCmd.PersistentFlags().UintVarP(
 		&cli.Limit,
@@ -158,7 +254,5 @@ func main() {
 		"name query parameter",
 	)
 
-	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
-	}
+	return rootCmd
 }
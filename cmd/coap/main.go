@@ -16,12 +16,20 @@ import (
 	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/coap"
 	"github.com/mainflux/mainflux/coap/api"
+	"github.com/mainflux/mainflux/internal/topics"
 	logger "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/fanout"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/mainflux/mainflux/pkg/quota"
+	quotaredis "github.com/mainflux/mainflux/pkg/quota/redis"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
-	broker "github.com/nats-io/nats.go"
 	opentracing "github.com/opentracing/opentracing-go"
 	gocoap "github.com/plgd-dev/go-coap/v2"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -39,6 +47,17 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defQueueSize         = "64"
+	defTopicTemplate     = topics.DefaultTemplate
+	defQuotaURL          = ""
+	defQuotaPass         = ""
+	defQuotaDB           = "0"
+	defQuotaDefault      = "0"
+	defQuotaBatch        = "100"
+	defQuotaFailOpen     = "false"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defAdminEmail        = ""
 
 	envPort              = "MF_COAP_ADAPTER_PORT"
 	envNatsURL           = "MF_NATS_URL"
@@ -48,6 +67,17 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envQueueSize         = "MF_COAP_ADAPTER_QUEUE_SIZE"
+	envTopicTemplate     = "MF_TOPIC_TEMPLATE"
+	envQuotaURL          = "MF_COAP_ADAPTER_QUOTA_URL"
+	envQuotaPass         = "MF_COAP_ADAPTER_QUOTA_PASS"
+	envQuotaDB           = "MF_COAP_ADAPTER_QUOTA_DB"
+	envQuotaDefault      = "MF_COAP_ADAPTER_QUOTA_DEFAULT"
+	envQuotaBatch        = "MF_COAP_ADAPTER_QUOTA_BATCH"
+	envQuotaFailOpen     = "MF_COAP_ADAPTER_QUOTA_FAIL_OPEN"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envAdminEmail        = "MF_COAP_ADAPTER_ADMIN_EMAIL"
 )
 
 type config struct {
@@ -59,6 +89,15 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	queueSize         int
+	topicTemplate     topics.Template
+	quotaURL          string
+	quotaPass         string
+	quotaDB           int
+	quota             quota.Config
+	authURL           string
+	authTimeout       time.Duration
+	adminEmail        string
 }
 
 func main() {
@@ -69,7 +108,9 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
-	conn := connectToThings(cfg, logger)
+	stdprometheus.MustRegister(mainflux.BuildInfo("coap"))
+
+	conn := connectToGRPC(cfg.thingsAuthURL, "things", cfg, logger)
 	defer conn.Close()
 
 	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
@@ -77,13 +118,44 @@ func main() {
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
-	nc, err := broker.Connect(cfg.natsURL)
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	admin, authConn := createAdmin(cfg, authTracer, logger)
+	if authConn != nil {
+		defer authConn.Close()
+	}
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
 	if err != nil {
-		log.Fatalf(err.Error())
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
 	}
-	defer nc.Close()
+	defer pubSub.Close()
+	stdprometheus.MustRegister(nats.NewCollector("coap_adapter", pubSub))
 
-	svc := coap.New(tc, nc)
+	sub := fanout.New(pubSub, cfg.queueSize, fanout.Metrics{
+		Subscriptions: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "coap_adapter",
+			Subsystem: "fanout",
+			Name:      "subscriptions",
+			Help:      "Number of distinct broker subscriptions.",
+		}, []string{}),
+		FanOut: kitprometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "coap_adapter",
+			Subsystem: "fanout",
+			Name:      "ratio",
+			Help:      "Average number of observers per broker subscription.",
+		}, []string{}),
+	}, logger)
+	publisher := makeLatencyPublisher(pubSub)
+	if cfg.quotaURL != "" {
+		qc := connectToRedis(cfg)
+		defer qc.Close()
+		publisher = quota.NewPublisher(publisher, quota.NewChecker(quotaredis.NewStore(qc), cfg.quota, time.Now))
+		logger.Info(fmt.Sprintf("Channel quotas are enforced using %s", cfg.quotaURL))
+	}
+	svc := coap.New(tc, publisher, sub, coap.NewRegistry(), admin)
 
 	svc = api.LoggingMiddleware(svc, logger)
 
@@ -105,7 +177,7 @@ func main() {
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(cfg.port, logger, errs)
+	go startHTTPServer(cfg.port, svc, nats.HealthHandler(pubSub), logger, errs)
 	go startCOAPServer(cfg, svc, nil, logger, errs)
 
 	go func() {
@@ -129,6 +201,41 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	queueSize, err := strconv.Atoi(mainflux.Env(envQueueSize, defQueueSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQueueSize, err.Error())
+	}
+
+	tmpl, err := topics.New(mainflux.Env(envTopicTemplate, defTopicTemplate))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envTopicTemplate, err.Error())
+	}
+
+	quotaDB, err := strconv.Atoi(mainflux.Env(envQuotaDB, defQuotaDB))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQuotaDB, err.Error())
+	}
+
+	quotaDefault, err := strconv.ParseUint(mainflux.Env(envQuotaDefault, defQuotaDefault), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQuotaDefault, err.Error())
+	}
+
+	quotaBatch, err := strconv.ParseUint(mainflux.Env(envQuotaBatch, defQuotaBatch), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQuotaBatch, err.Error())
+	}
+
+	quotaFailOpen, err := strconv.ParseBool(mainflux.Env(envQuotaFailOpen, defQuotaFailOpen))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQuotaFailOpen, err.Error())
+	}
+
+	usersAuthTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
 	return config{
 		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
 		port:              mainflux.Env(envPort, defPort),
@@ -138,10 +245,39 @@ func loadConfig() config {
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		thingsAuthTimeout: authTimeout,
+		queueSize:         queueSize,
+		topicTemplate:     tmpl,
+		quotaURL:          mainflux.Env(envQuotaURL, defQuotaURL),
+		quotaPass:         mainflux.Env(envQuotaPass, defQuotaPass),
+		quotaDB:           quotaDB,
+		quota: quota.Config{
+			Default:  quotaDefault,
+			Batch:    quotaBatch,
+			FailOpen: quotaFailOpen,
+		},
+		authURL:     mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout: usersAuthTimeout,
+		adminEmail:  mainflux.Env(envAdminEmail, defAdminEmail),
+	}
+}
+
+// createAdmin returns the adapter admin identified by the auth service,
+// along with the auth service connection. The admin is disabled unless its
+// email is set.
+func createAdmin(cfg config, tracer opentracing.Tracer, logger logger.Logger) (coap.Admin, *grpc.ClientConn) {
+	if cfg.adminEmail == "" {
+		return coap.Admin{}, nil
+	}
+
+	conn := connectToGRPC(cfg.authURL, "auth", cfg, logger)
+	admin := coap.Admin{
+		Auth:  authapi.NewClient(tracer, conn, cfg.authTimeout),
+		Email: cfg.adminEmail,
 	}
+	return admin, conn
 }
 
-func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
+func connectToGRPC(url, svc string, cfg config, logger logger.Logger) *grpc.ClientConn {
 	var opts []grpc.DialOption
 	if cfg.clientTLS {
 		if cfg.caCerts != "" {
@@ -157,14 +293,22 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 		opts = append(opts, grpc.WithInsecure())
 	}
 
-	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	conn, err := grpc.Dial(url, opts...)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		logger.Error(fmt.Sprintf("Failed to connect to %s service: %s", svc, err))
 		os.Exit(1)
 	}
 	return conn
 }
 
+func connectToRedis(cfg config) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.quotaURL,
+		Password: cfg.quotaPass,
+		DB:       cfg.quotaDB,
+	})
+}
+
 func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
 	if url == "" {
 		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
@@ -189,14 +333,31 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 	return tracer, closer
 }
 
-func startHTTPServer(port string, logger logger.Logger, errs chan error) {
+func startHTTPServer(port string, svc coap.Service, health http.Handler, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("CoAP service started, exposed port %s", port))
-	errs <- http.ListenAndServe(p, api.MakeHTTPHandler())
+	errs <- http.ListenAndServe(p, api.MakeHTTPHandler(svc, health))
 }
 
 func startCOAPServer(cfg config, svc coap.Service, auth mainflux.ThingsServiceClient, l logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", cfg.port)
 	l.Info(fmt.Sprintf("CoAP adapter service started, exposed port %s", cfg.port))
-	errs <- gocoap.ListenAndServe("udp", p, api.MakeCoAPHandler(svc, l))
+	errs <- gocoap.ListenAndServe("udp", p, api.MakeCoAPHandler(svc, l, cfg.topicTemplate))
+}
+
+func makeLatencyPublisher(pub messaging.Publisher) messaging.Publisher {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "coap_adapter",
+		Subsystem: "api",
+		Name:      "publish_latency_seconds",
+		Help:      "Time between the message creation and its publish to the broker.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "coap_adapter",
+		Subsystem: "api",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than published due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewPublisher(pub, hist, skewed)
 }
@@ -11,18 +11,27 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
-	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"google.golang.org/grpc/credentials"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
 	adapter "github.com/mainflux/mainflux/http"
 	"github.com/mainflux/mainflux/http/api"
+	mfconfig "github.com/mainflux/mainflux/internal/config"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/internal/topics"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/mainflux/mainflux/pkg/messaging/shedding"
+	"github.com/mainflux/mainflux/pkg/quota"
+	quotaredis "github.com/mainflux/mainflux/pkg/quota/redis"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	"github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -31,62 +40,97 @@ import (
 )
 
 const (
-	defLogLevel          = "error"
-	defClientTLS         = "false"
-	defCACerts           = ""
-	defPort              = "8180"
-	defNatsURL           = "nats://localhost:4222"
-	defJaegerURL         = ""
-	defThingsAuthURL     = "localhost:8181"
-	defThingsAuthTimeout = "1s"
-
-	envLogLevel          = "MF_HTTP_ADAPTER_LOG_LEVEL"
-	envClientTLS         = "MF_HTTP_ADAPTER_CLIENT_TLS"
-	envCACerts           = "MF_HTTP_ADAPTER_CA_CERTS"
-	envPort              = "MF_HTTP_ADAPTER_PORT"
-	envNatsURL           = "MF_NATS_URL"
-	envJaegerURL         = "MF_JAEGER_URL"
-	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
-	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envPrefix     = "MF_HTTP_ADAPTER_"
+	envNatsPrefix = "MF_NATS_"
 )
 
 type config struct {
-	natsURL           string
-	logLevel          string
-	port              string
-	clientTLS         bool
-	caCerts           string
-	jaegerURL         string
-	thingsAuthURL     string
-	thingsAuthTimeout time.Duration
+	LogLevel          string        `env:"MF_HTTP_ADAPTER_LOG_LEVEL" default:"error"`
+	ClientTLS         bool          `env:"MF_HTTP_ADAPTER_CLIENT_TLS" default:"false"`
+	CACerts           string        `env:"MF_HTTP_ADAPTER_CA_CERTS"`
+	Port              string        `env:"MF_HTTP_ADAPTER_PORT" default:"8180"`
+	NatsURLs          []string      `env:"MF_NATS_URL" default:"nats://localhost:4222"`
+	JaegerURL         string        `env:"MF_JAEGER_URL"`
+	ThingsAuthURL     string        `env:"MF_THINGS_AUTH_GRPC_URL" default:"localhost:8181"`
+	ThingsAuthTimeout time.Duration `env:"MF_THINGS_AUTH_GRPC_TIMEOUT" default:"1s"`
+	PublishDeadline   time.Duration `env:"MF_HTTP_ADAPTER_PUBLISH_DEADLINE" default:"5s"`
+	MaxInFlight       int           `env:"MF_HTTP_ADAPTER_MAX_IN_FLIGHT" default:"1000"`
+	TenantHeader      string        `env:"MF_HTTP_ADAPTER_TENANT_HEADER"`
+	TopicTemplate     string        `env:"MF_TOPIC_TEMPLATE" default:"channels/{channel}/messages/{subtopic}"`
+	ValidateRate      float64       `env:"MF_HTTP_ADAPTER_VALIDATE_RATE" default:"10"`
+	ValidateBurst     int           `env:"MF_HTTP_ADAPTER_VALIDATE_BURST" default:"20"`
+	QuotaURL          string        `env:"MF_HTTP_ADAPTER_QUOTA_URL"`
+	QuotaPass         string        `env:"MF_HTTP_ADAPTER_QUOTA_PASS,secret"`
+	QuotaDB           int           `env:"MF_HTTP_ADAPTER_QUOTA_DB" default:"0"`
+	QuotaDefault      uint64        `env:"MF_HTTP_ADAPTER_QUOTA_DEFAULT" default:"0"`
+	QuotaBatch        uint64        `env:"MF_HTTP_ADAPTER_QUOTA_BATCH" default:"100"`
+	QuotaFailOpen     bool          `env:"MF_HTTP_ADAPTER_QUOTA_FAIL_OPEN" default:"false"`
+	TrustedProxies    []string      `env:"MF_HTTP_ADAPTER_TRUSTED_PROXIES"`
 }
 
 func main() {
 	cfg := loadConfig()
 
-	logger, err := logger.New(os.Stdout, cfg.logLevel)
+	logger, err := logger.New(os.Stdout, cfg.LogLevel)
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("http"))
+
+	logger.Info(fmt.Sprintf("HTTP adapter configuration: %s", mfconfig.Redact(&cfg)))
+
+	tmpl, err := topics.New(cfg.TopicTemplate)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Invalid topic template: %s", err))
+		os.Exit(1)
+	}
+
+	proxies, err := reqctx.ParseProxies(cfg.TrustedProxies)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Invalid trusted proxies: %s", err))
+		os.Exit(1)
+	}
+
 	conn := connectToThings(cfg, logger)
 	defer conn.Close()
 
-	tracer, closer := initJaeger("http_adapter", cfg.jaegerURL, logger)
+	tracer, closer := initJaeger("http_adapter", cfg.JaegerURL, logger)
 	defer closer.Close()
 
-	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
+	thingsTracer, thingsCloser := initJaeger("things", cfg.JaegerURL, logger)
 	defer thingsCloser.Close()
 
-	pub, err := nats.NewPublisher(cfg.natsURL)
+	pub, err := nats.NewPublisher(strings.Join(cfg.NatsURLs, ","))
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
 		os.Exit(1)
 	}
 	defer pub.Close()
+	stdprometheus.MustRegister(nats.NewCollector("http_adapter", pub))
+
+	sc := shedding.Config{
+		Deadline:    cfg.PublishDeadline,
+		MaxInFlight: cfg.MaxInFlight,
+	}
+	shed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "http_adapter",
+		Subsystem: "api",
+		Name:      "shed_messages_count",
+		Help:      "Number of messages rejected due to the publish deadline or in-flight limit.",
+	}, []string{"reason"})
+
+	var publisher messaging.Publisher = makeLatencyPublisher(shedding.NewPublisher(pub, sc, shed))
+	if cfg.QuotaURL != "" {
+		qc := connectToRedis(cfg)
+		defer qc.Close()
+		publisher = quota.NewPublisher(publisher, newQuotaChecker(qc, cfg))
+		logger.Info(fmt.Sprintf("Channel quotas are enforced using %s", cfg.QuotaURL))
+	}
 
-	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
-	svc := adapter.New(pub, tc)
+	tc := thingsapi.NewClient(conn, thingsTracer, cfg.ThingsAuthTimeout)
+	svc := adapter.New(publisher, tc)
+	svc = api.ValidationLimitMiddleware(svc, cfg.ValidateRate, cfg.ValidateBurst)
 
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
@@ -108,9 +152,9 @@ func main() {
 	errs := make(chan error, 2)
 
 	go func() {
-		p := fmt.Sprintf(":%s", cfg.port)
-		logger.Info(fmt.Sprintf("HTTP adapter service started on port %s", cfg.port))
-		errs <- http.ListenAndServe(p, api.MakeHandler(svc, tracer))
+		p := fmt.Sprintf(":%s", cfg.Port)
+		logger.Info(fmt.Sprintf("HTTP adapter service started on port %s", cfg.Port))
+		errs <- http.ListenAndServe(p, api.MakeHandler(svc, tracer, cfg.TenantHeader, tmpl, nats.HealthHandler(pub), proxies))
 	}()
 
 	go func() {
@@ -124,26 +168,11 @@ func main() {
 }
 
 func loadConfig() config {
-	tls, err := strconv.ParseBool(mainflux.Env(envClientTLS, defClientTLS))
-	if err != nil {
-		log.Fatalf("Invalid value passed for %s\n", envClientTLS)
-	}
-
-	authTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
-	if err != nil {
-		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
-	}
-
-	return config{
-		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
-		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
-		port:              mainflux.Env(envPort, defPort),
-		clientTLS:         tls,
-		caCerts:           mainflux.Env(envCACerts, defCACerts),
-		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
-		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
-		thingsAuthTimeout: authTimeout,
+	var cfg config
+	if err := mfconfig.Load(&cfg, envPrefix, envNatsPrefix); err != nil {
+		log.Fatalf(err.Error())
 	}
+	return cfg
 }
 
 func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
@@ -172,9 +201,9 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 
 func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 	var opts []grpc.DialOption
-	if cfg.clientTLS {
-		if cfg.caCerts != "" {
-			tpc, err := credentials.NewClientTLSFromFile(cfg.caCerts, "")
+	if cfg.ClientTLS {
+		if cfg.CACerts != "" {
+			tpc, err := credentials.NewClientTLSFromFile(cfg.CACerts, "")
 			if err != nil {
 				logger.Error(fmt.Sprintf("Failed to load certs: %s", err))
 				os.Exit(1)
@@ -186,10 +215,46 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 		opts = append(opts, grpc.WithInsecure())
 	}
 
-	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	conn, err := grpc.Dial(cfg.ThingsAuthURL, opts...)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
 		os.Exit(1)
 	}
 	return conn
 }
+
+func connectToRedis(cfg config) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.QuotaURL,
+		Password: cfg.QuotaPass,
+		DB:       cfg.QuotaDB,
+	})
+}
+
+// newQuotaChecker returns the checker of the channel quotas set by the
+// things service in the same Redis.
+func newQuotaChecker(client *redis.Client, cfg config) quota.Checker {
+	qc := quota.Config{
+		Default:  cfg.QuotaDefault,
+		Batch:    cfg.QuotaBatch,
+		FailOpen: cfg.QuotaFailOpen,
+	}
+	return quota.NewChecker(quotaredis.NewStore(client), qc, time.Now)
+}
+
+func makeLatencyPublisher(pub messaging.Publisher) messaging.Publisher {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "http_adapter",
+		Subsystem: "api",
+		Name:      "publish_latency_seconds",
+		Help:      "Time between the message creation and its publish to the broker.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "http_adapter",
+		Subsystem: "api",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than published due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewPublisher(pub, hist, skewed)
+}
@@ -15,10 +15,12 @@ import (
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	influxdata "github.com/influxdata/influxdb/client/v2"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
 	"github.com/mainflux/mainflux/readers/influxdb"
+	"github.com/mainflux/mainflux/readers/tracing"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -42,6 +44,9 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defAdminEmail        = ""
 
 	envLogLevel          = "MF_INFLUX_READER_LOG_LEVEL"
 	envPort              = "MF_INFLUX_READER_PORT"
@@ -57,6 +62,9 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envAdminEmail        = "MF_INFLUX_READER_ADMIN_EMAIL"
 )
 
 type config struct {
@@ -74,6 +82,9 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	adminEmail        string
 }
 
 func main() {
@@ -82,7 +93,10 @@ func main() {
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
-	conn := connectToThings(cfg, logger)
+
+	stdprometheus.MustRegister(mainflux.BuildInfo("influxdb-reader"))
+
+	conn := connectToGRPC(cfg.thingsAuthURL, "things", cfg, logger)
 	defer conn.Close()
 
 	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
@@ -90,6 +104,20 @@ func main() {
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	admin, authConn := createAdmin(cfg, authTracer, logger)
+	if authConn != nil {
+		defer authConn.Close()
+	}
+
+	tracer, closer := initJaeger("influxdb-reader", cfg.jaegerURL, logger)
+	defer closer.Close()
+
+	dbTracer, dbCloser := initJaeger("influxdb-reader_db", cfg.jaegerURL, logger)
+	defer dbCloser.Close()
+
 	client, err := influxdata.NewHTTPClient(clientCfg)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to create InfluxDB client: %s", err))
@@ -97,7 +125,7 @@ func main() {
 	}
 	defer client.Close()
 
-	repo := newService(client, cfg.dbName, logger)
+	repo := newService(client, cfg.dbName, dbTracer, logger)
 
 	errs := make(chan error, 2)
 	go func() {
@@ -106,7 +134,7 @@ func main() {
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
-	go startHTTPServer(repo, tc, cfg, logger, errs)
+	go startHTTPServer(tracer, repo, tc, admin, cfg, logger, errs)
 
 	err = <-errs
 	logger.Error(fmt.Sprintf("InfluxDB writer service terminated: %s", err))
@@ -123,6 +151,11 @@ func loadConfigs() (config, influxdata.HTTPConfig) {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	usersAuthTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
 	cfg := config{
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
 		port:              mainflux.Env(envPort, defPort),
@@ -138,6 +171,9 @@ func loadConfigs() (config, influxdata.HTTPConfig) {
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		thingsAuthTimeout: authTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       usersAuthTimeout,
+		adminEmail:        mainflux.Env(envAdminEmail, defAdminEmail),
 	}
 
 	clientCfg := influxdata.HTTPConfig{
@@ -149,7 +185,23 @@ func loadConfigs() (config, influxdata.HTTPConfig) {
 	return cfg, clientCfg
 }
 
-func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
+// createAdmin returns the reader admin identified by the auth service, along
+// with the auth service gRPC connection. The connection is nil if the admin
+// email isn't set, which disables the filtering by tenant.
+func createAdmin(cfg config, tracer opentracing.Tracer, logger logger.Logger) (api.Admin, *grpc.ClientConn) {
+	if cfg.adminEmail == "" {
+		return api.Admin{}, nil
+	}
+
+	conn := connectToGRPC(cfg.authURL, "auth", cfg, logger)
+	admin := api.Admin{
+		Auth:  authapi.NewClient(tracer, conn, cfg.authTimeout),
+		Email: cfg.adminEmail,
+	}
+	return admin, conn
+}
+
+func connectToGRPC(url, svc string, cfg config, logger logger.Logger) *grpc.ClientConn {
 	var opts []grpc.DialOption
 	if cfg.clientTLS {
 		if cfg.caCerts != "" {
@@ -165,9 +217,9 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 		opts = append(opts, grpc.WithInsecure())
 	}
 
-	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	conn, err := grpc.Dial(url, opts...)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		logger.Error(fmt.Sprintf("Failed to connect to %s service: %s", svc, err))
 		os.Exit(1)
 	}
 	return conn
@@ -197,8 +249,8 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 	return tracer, closer
 }
 
-func newService(client influxdata.Client, dbName string, logger logger.Logger) readers.MessageRepository {
-	repo := influxdb.New(client, dbName)
+func newService(client influxdata.Client, dbName string, tracer opentracing.Tracer, logger logger.Logger) readers.MessageRepository {
+	repo := tracing.MessageRepositoryMiddleware(tracer, influxdb.New(client, dbName))
 	repo = api.LoggingMiddleware(repo, logger)
 	repo = api.MetricsMiddleware(
 		repo,
@@ -219,14 +271,14 @@ func newService(client influxdata.Client, dbName string, logger logger.Logger) r
 	return repo
 }
 
-func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, cfg config, logger logger.Logger, errs chan error) {
+func startHTTPServer(tracer opentracing.Tracer, repo readers.MessageRepository, tc mainflux.ThingsServiceClient, admin api.Admin, cfg config, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", cfg.port)
 	if cfg.serverCert != "" || cfg.serverKey != "" {
 		logger.Info(fmt.Sprintf("InfluxDB reader service started using https on port %s with cert %s key %s",
 			cfg.port, cfg.serverCert, cfg.serverKey))
-		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, "influxdb-reader"))
+		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tracer, tc, admin, "influxdb-reader"))
 		return
 	}
 	logger.Info(fmt.Sprintf("InfluxDB reader service started, exposed port %s", cfg.port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, "influxdb-reader"))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tracer, tc, admin, "influxdb-reader"))
 }
@@ -4,68 +4,110 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	influxdata "github.com/influxdata/influxdb/client/v2"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/consumers/schemas"
 	"github.com/mainflux/mainflux/consumers/writers/api"
 	"github.com/mainflux/mainflux/consumers/writers/influxdb"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/decompress"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/limits"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/things"
+	authhttpapi "github.com/mainflux/mainflux/things/api/auth/http"
+	broker "github.com/nats-io/nats.go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 )
 
 const (
-	svcName = "influxdb-writer"
-
-	defNatsURL     = "nats://localhost:4222"
-	defLogLevel    = "error"
-	defPort        = "8180"
-	defDB          = "mainflux"
-	defDBHost      = "localhost"
-	defDBPort      = "8086"
-	defDBUser      = "mainflux"
-	defDBPass      = "mainflux"
-	defConfigPath  = "/config.toml"
-	defContentType = "application/senml+json"
-	defTransformer = "senml"
-
-	envNatsURL     = "MF_NATS_URL"
-	envLogLevel    = "MF_INFLUX_WRITER_LOG_LEVEL"
-	envPort        = "MF_INFLUX_WRITER_PORT"
-	envDB          = "MF_INFLUXDB_DB"
-	envDBHost      = "MF_INFLUX_WRITER_DB_HOST"
-	envDBPort      = "MF_INFLUXDB_PORT"
-	envDBUser      = "MF_INFLUXDB_ADMIN_USER"
-	envDBPass      = "MF_INFLUXDB_ADMIN_PASSWORD"
-	envConfigPath  = "MF_INFLUX_WRITER_CONFIG_PATH"
-	envContentType = "MF_INFLUX_WRITER_CONTENT_TYPE"
-	envTransformer = "MF_INFLUX_WRITER_TRANSFORMER"
+	svcName       = "influxdb-writer"
+	thingsTimeout = 5 * time.Second
+
+	defNatsURL           = "nats://localhost:4222"
+	defLogLevel          = "error"
+	defPort              = "8180"
+	defDB                = "mainflux"
+	defDBHost            = "localhost"
+	defDBPort            = "8086"
+	defDBUser            = "mainflux"
+	defDBPass            = "mainflux"
+	defConfigPath        = "/config.toml"
+	defContentType       = "application/senml+json"
+	defTransformer       = "senml"
+	defProtoDescriptors  = "/descriptors.pb"
+	defDecompressLimit   = "10485760"
+	defThingsESURL       = ""
+	defThingsESPass      = ""
+	defThingsESDB        = "0"
+	defThingsURL         = ""
+	defThingsSecret      = ""
+	defRetention         = "0"
+	defRetentionInterval = "1h"
+
+	envNatsURL           = "MF_NATS_URL"
+	envLogLevel          = "MF_INFLUX_WRITER_LOG_LEVEL"
+	envPort              = "MF_INFLUX_WRITER_PORT"
+	envDB                = "MF_INFLUXDB_DB"
+	envDBHost            = "MF_INFLUX_WRITER_DB_HOST"
+	envDBPort            = "MF_INFLUXDB_PORT"
+	envDBUser            = "MF_INFLUXDB_ADMIN_USER"
+	envDBPass            = "MF_INFLUXDB_ADMIN_PASSWORD"
+	envConfigPath        = "MF_INFLUX_WRITER_CONFIG_PATH"
+	envContentType       = "MF_INFLUX_WRITER_CONTENT_TYPE"
+	envTransformer       = "MF_INFLUX_WRITER_TRANSFORMER"
+	envProtoDescriptors  = "MF_INFLUX_WRITER_PROTOBUF_DESCRIPTORS"
+	envDecompressLimit   = "MF_INFLUX_WRITER_DECOMPRESS_LIMIT"
+	envThingsESURL       = "MF_INFLUX_WRITER_THINGS_ES_URL"
+	envThingsESPass      = "MF_INFLUX_WRITER_THINGS_ES_PASS"
+	envThingsESDB        = "MF_INFLUX_WRITER_THINGS_ES_DB"
+	envThingsURL         = "MF_INFLUX_WRITER_THINGS_URL"
+	envThingsSecret      = "MF_INFLUX_WRITER_THINGS_SECRET"
+	envRetention         = "MF_INFLUX_WRITER_RETENTION"
+	envRetentionInterval = "MF_INFLUX_WRITER_RETENTION_INTERVAL"
 )
 
 type config struct {
-	natsURL     string
-	logLevel    string
-	port        string
-	dbName      string
-	dbHost      string
-	dbPort      string
-	dbUser      string
-	dbPass      string
-	configPath  string
-	contentType string
-	transformer string
+	natsURL           string
+	logLevel          string
+	port              string
+	dbName            string
+	dbHost            string
+	dbPort            string
+	dbUser            string
+	dbPass            string
+	configPath        string
+	contentType       string
+	transformer       string
+	protoDescriptors  string
+	decompressLimit   int64
+	thingsESURL       string
+	thingsESPass      string
+	thingsESDB        string
+	thingsURL         string
+	thingsSecret      string
+	retention         time.Duration
+	retentionInterval time.Duration
 }
 
 func main() {
@@ -76,6 +118,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo(svcName))
+
 	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
@@ -95,13 +139,19 @@ func main() {
 	counter, latency := makeMetrics()
 	repo = api.LoggingMiddleware(repo, logger)
 	repo = api.MetricsMiddleware(repo, counter, latency)
-	t := makeTransformer(cfg, logger)
+	t := decompress.New(cfg.decompressLimit, makeLimits(makeTransformer(cfg, logger), cfg, logger))
+	t, errsConn := makeErrorsMiddleware(t, cfg, logger)
+	if errsConn != nil {
+		defer errsConn.Close()
+	}
 
-	if err := consumers.Start(pubSub, repo, t, cfg.configPath, logger); err != nil {
+	if err := consumers.Start(makeLatencySubscriber(pubSub), repo, t, cfg.configPath, logger); err != nil {
 		logger.Error(fmt.Sprintf("Failed to start InfluxDB writer: %s", err))
 		os.Exit(1)
 	}
 
+	startRetention(influxdb.NewRetentionStore(client, cfg.dbName), cfg, logger)
+
 	errs := make(chan error, 2)
 	go func() {
 		c := make(chan os.Signal)
@@ -116,18 +166,42 @@ func main() {
 }
 
 func loadConfigs() (config, influxdata.HTTPConfig) {
+	decompressLimit, err := strconv.ParseInt(mainflux.Env(envDecompressLimit, defDecompressLimit), 10, 64)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defaultRetention, err := time.ParseDuration(mainflux.Env(envRetention, defRetention))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetention, err.Error())
+	}
+
+	retentionInterval, err := time.ParseDuration(mainflux.Env(envRetentionInterval, defRetentionInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRetentionInterval, err.Error())
+	}
+
 	cfg := config{
-		natsURL:     mainflux.Env(envNatsURL, defNatsURL),
-		logLevel:    mainflux.Env(envLogLevel, defLogLevel),
-		port:        mainflux.Env(envPort, defPort),
-		dbName:      mainflux.Env(envDB, defDB),
-		dbHost:      mainflux.Env(envDBHost, defDBHost),
-		dbPort:      mainflux.Env(envDBPort, defDBPort),
-		dbUser:      mainflux.Env(envDBUser, defDBUser),
-		dbPass:      mainflux.Env(envDBPass, defDBPass),
-		configPath:  mainflux.Env(envConfigPath, defConfigPath),
-		contentType: mainflux.Env(envContentType, defContentType),
-		transformer: mainflux.Env(envTransformer, defTransformer),
+		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
+		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
+		port:              mainflux.Env(envPort, defPort),
+		dbName:            mainflux.Env(envDB, defDB),
+		dbHost:            mainflux.Env(envDBHost, defDBHost),
+		dbPort:            mainflux.Env(envDBPort, defDBPort),
+		dbUser:            mainflux.Env(envDBUser, defDBUser),
+		dbPass:            mainflux.Env(envDBPass, defDBPass),
+		configPath:        mainflux.Env(envConfigPath, defConfigPath),
+		contentType:       mainflux.Env(envContentType, defContentType),
+		transformer:       mainflux.Env(envTransformer, defTransformer),
+		protoDescriptors:  mainflux.Env(envProtoDescriptors, defProtoDescriptors),
+		decompressLimit:   decompressLimit,
+		thingsESURL:       mainflux.Env(envThingsESURL, defThingsESURL),
+		thingsESPass:      mainflux.Env(envThingsESPass, defThingsESPass),
+		thingsESDB:        mainflux.Env(envThingsESDB, defThingsESDB),
+		thingsURL:         mainflux.Env(envThingsURL, defThingsURL),
+		thingsSecret:      mainflux.Env(envThingsSecret, defThingsSecret),
+		retention:         defaultRetention,
+		retentionInterval: retentionInterval,
 	}
 
 	clientCfg := influxdata.HTTPConfig{
@@ -164,7 +238,20 @@ func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer
 		return senml.New(cfg.contentType)
 	case "JSON":
 		logger.Info("Using JSON transformer")
-		return json.New()
+		return makeJSONTransformer(cfg, logger)
+	case "PROTOBUF":
+		logger.Info("Using protobuf transformer")
+		pc, err := consumers.LoadProtobufConfig(cfg.configPath)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Continue without protobuf message types, failed to load them: %s", err))
+		}
+		pc.Descriptors = cfg.protoDescriptors
+		t, err := protobuf.New(pc, makeJSONTransformer(cfg, logger))
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to create protobuf transformer: %s", err))
+			os.Exit(1)
+		}
+		return t
 	default:
 		logger.Error(fmt.Sprintf("Can't create transformer: unknown transformer type %s", cfg.transformer))
 		os.Exit(1)
@@ -172,8 +259,158 @@ func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer
 	}
 }
 
+func makeJSONTransformer(cfg config, logger logger.Logger) transformers.Transformer {
+	sc := makeSchemas(cfg, logger)
+	tc, err := consumers.LoadTransformerConfig(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default JSON transformer settings, failed to load them: %s", err))
+		return json.NewWithSchemas(json.Config{}, nil, sc)
+	}
+	fallbacks := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "influxdb",
+		Subsystem: "message_writer",
+		Name:      "time_fallback_count",
+		Help:      "Number of messages stored with arrival time instead of payload time.",
+	}, []string{"reason"})
+	return json.NewWithSchemas(tc, fallbacks, sc)
+}
+
+// makeSchemas returns the channel schemas kept up to date using the things
+// events, or nil if the things event store isn't set.
+func makeSchemas(cfg config, logger logger.Logger) json.Schemas {
+	if cfg.thingsESURL == "" {
+		return nil
+	}
+	client := connectToThingsES(cfg, logger)
+
+	cache := schemas.NewCache()
+	sub := schemas.NewSubscriber(client, cache, logger)
+	if err := loadChannels(sub, things.SchemaKey, cfg); err != nil {
+		logger.Error(fmt.Sprintf("Failed to load channel schemas: %s", err))
+		os.Exit(1)
+	}
+	go func() {
+		if err := sub.Subscribe(context.Background()); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to subscribe to things event store: %s", err))
+		}
+	}()
+
+	logger.Info("Validating JSON messages against channel schemas")
+	return cache
+}
+
+// loadChannels brings the handler of the subscriber up to date. The channels
+// having the metadata key set are seeded from the things service, if it's
+// set, and the events are used only for the later changes. Otherwise the
+// events stored in the stream are replayed.
+func loadChannels(sub *schemas.Subscriber, key string, cfg config) error {
+	if cfg.thingsURL == "" {
+		return sub.Load(context.Background())
+	}
+	return sub.Seed(context.Background(), authhttpapi.NewClient(cfg.thingsURL, cfg.thingsSecret, thingsTimeout), key)
+}
+
+// startRetention purges the expired messages in the background. The channels
+// retention hints are kept up to date using the things events, if the things
+// event store is set.
+func startRetention(store retention.Store, cfg config, logger logger.Logger) {
+	if cfg.retention == 0 && cfg.thingsESURL == "" {
+		return
+	}
+
+	cache := retention.NewCache()
+	if cfg.thingsESURL != "" {
+		sub := schemas.NewSubscriber(connectToThingsES(cfg, logger), cache, logger)
+		if err := loadChannels(sub, things.RetentionKey, cfg); err != nil {
+			logger.Error(fmt.Sprintf("Failed to load channel retention hints: %s", err))
+			os.Exit(1)
+		}
+		go func() {
+			if err := sub.Subscribe(context.Background()); err != nil {
+				logger.Warn(fmt.Sprintf("Failed to subscribe to things event store: %s", err))
+			}
+		}()
+	}
+
+	p := retention.NewPurger(store, cache, cfg.retention, logger)
+	go func() {
+		if err := p.Run(context.Background(), cfg.retentionInterval); err != nil {
+			logger.Warn(fmt.Sprintf("Messages purger stopped: %s", err))
+		}
+	}()
+	logger.Info(fmt.Sprintf("Purging expired messages every %s", cfg.retentionInterval))
+}
+
+func connectToThingsES(cfg config, logger logger.Logger) *r.Client {
+	db, err := strconv.Atoi(cfg.thingsESDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to things event store: %s", err))
+		os.Exit(1)
+	}
+	return r.NewClient(&r.Options{
+		Addr:     cfg.thingsESURL,
+		Password: cfg.thingsESPass,
+		DB:       db,
+	})
+}
+
 func startHTTPService(port string, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("InfluxDB writer service started, exposed port %s", p))
 	errs <- http.ListenAndServe(p, api.MakeHandler(svcName))
 }
+
+// makeLimits rejects the messages exceeding the limits before they reach
+// the database.
+func makeLimits(t transformers.Transformer, cfg config, logger logger.Logger) transformers.Transformer {
+	lc, err := consumers.LoadLimitsConfig(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default message limits, failed to load them: %s", err))
+	}
+	return limits.New(lc, t)
+}
+
+// makeErrorsMiddleware returns the transformer reporting the transform errors
+// and the NATS connection the error events are published to, if any.
+func makeErrorsMiddleware(t transformers.Transformer, cfg config, logger logger.Logger) (transformers.Transformer, *broker.Conn) {
+	ec, err := consumers.LoadErrorsConfig(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default transform errors settings, failed to load them: %s", err))
+	}
+
+	var pub consumers.EventPublisher
+	var conn *broker.Conn
+	if ec.Subject != "" {
+		conn, err = broker.Connect(cfg.natsURL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to connect to NATS for transform error events: %s", err))
+			os.Exit(1)
+		}
+		pub = conn
+	}
+
+	counter := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "influxdb",
+		Subsystem: "message_writer",
+		Name:      "transform_error_count",
+		Help:      "Number of messages failed to transform.",
+	}, []string{"transformer", "channel", "reason"})
+	return consumers.ErrorsMiddleware(t, strings.ToLower(cfg.transformer), counter, pub, ec, logger), conn
+}
+
+func makeLatencySubscriber(sub messaging.Subscriber) messaging.Subscriber {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "influxdb",
+		Subsystem: "message_writer",
+		Name:      "write_latency_seconds",
+		Help:      "Time between the message creation by the adapter and its storage.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "influxdb",
+		Subsystem: "message_writer",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than stored due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewSubscriber(sub, hist, skewed)
+}
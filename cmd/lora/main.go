@@ -21,6 +21,7 @@ import (
 	"github.com/mainflux/mainflux/lora"
 	"github.com/mainflux/mainflux/lora/api"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
 	"github.com/mainflux/mainflux/pkg/messaging/mqtt"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 
@@ -39,6 +40,7 @@ const (
 	defESPass         = ""
 	defESDB           = "0"
 	defESConsumerName = "lora"
+	defESReplayFrom   = ""
 	defRouteMapURL    = "localhost:6379"
 	defRouteMapPass   = ""
 	defRouteMapDB     = "0"
@@ -52,6 +54,7 @@ const (
 	envESPass         = "MF_THINGS_ES_PASS"
 	envESDB           = "MF_THINGS_ES_DB"
 	envESConsumerName = "MF_LORA_ADAPTER_EVENT_CONSUMER"
+	envESReplayFrom   = "MF_LORA_ADAPTER_EVENT_REPLAY_FROM"
 	envRouteMapURL    = "MF_LORA_ADAPTER_ROUTE_MAP_URL"
 	envRouteMapPass   = "MF_LORA_ADAPTER_ROUTE_MAP_PASS"
 	envRouteMapDB     = "MF_LORA_ADAPTER_ROUTE_MAP_DB"
@@ -73,6 +76,7 @@ type config struct {
 	esPass         string
 	esDB           string
 	esConsumerName string
+	esReplayFrom   string
 	routeMapURL    string
 	routeMapPass   string
 	routeMapDB     string
@@ -86,6 +90,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("lora-adapter"))
+
 	rmConn := connectToRedis(cfg.routeMapURL, cfg.routeMapPass, cfg.routeMapDB, logger)
 	defer rmConn.Close()
 
@@ -103,7 +109,7 @@ func main() {
 	chansRM := newRouteMapRepository(rmConn, channelsRMPrefix, logger)
 	connsRM := newRouteMapRepository(rmConn, connsRMPrefix, logger)
 
-	svc := lora.New(pub, thingsRM, chansRM, connsRM)
+	svc := lora.New(makeLatencyPublisher(pub), thingsRM, chansRM, connsRM)
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
@@ -129,7 +135,7 @@ func main() {
 
 	go subscribeToLoRaBroker(svc, msub, logger)
 
-	go subscribeToThingsES(svc, esConn, cfg.esConsumerName, logger)
+	go subscribeToThingsES(svc, esConn, cfg.esConsumerName, cfg.esReplayFrom, logger)
 
 	errs := make(chan error, 2)
 
@@ -160,6 +166,7 @@ func loadConfig() config {
 		esPass:         mainflux.Env(envESPass, defESPass),
 		esDB:           mainflux.Env(envESDB, defESDB),
 		esConsumerName: mainflux.Env(envESConsumerName, defESConsumerName),
+		esReplayFrom:   mainflux.Env(envESReplayFrom, defESReplayFrom),
 		routeMapURL:    mainflux.Env(envRouteMapURL, defRouteMapURL),
 		routeMapPass:   mainflux.Env(envRouteMapPass, defRouteMapPass),
 		routeMapDB:     mainflux.Env(envRouteMapDB, defRouteMapDB),
@@ -199,8 +206,14 @@ func subscribeToLoRaBroker(svc lora.Service, msub messaging.Subscriber, logger l
 	logger.Info("Subscribed to LoRa MQTT broker")
 }
 
-func subscribeToThingsES(svc lora.Service, client *r.Client, consumer string, logger logger.Logger) {
+func subscribeToThingsES(svc lora.Service, client *r.Client, consumer, replayFrom string, logger logger.Logger) {
 	eventStore := redis.NewEventStore(svc, client, consumer, logger)
+	if replayFrom != "" {
+		if err := eventStore.Replay(context.Background(), replayFrom); err != nil {
+			logger.Error(fmt.Sprintf("Failed to replay events from %s: %s", replayFrom, err))
+			os.Exit(1)
+		}
+	}
 	logger.Info("Subscribed to Redis Event Store")
 	if err := eventStore.Subscribe(context.Background(), "mainflux.things"); err != nil {
 		logger.Warn(fmt.Sprintf("LoRa-adapter service failed to subscribe to Redis event source: %s", err))
@@ -217,3 +230,20 @@ func startHTTPServer(cfg config, logger logger.Logger, errs chan error) {
 	logger.Info(fmt.Sprintf("LoRa-adapter service started, exposed port %s", cfg.httpPort))
 	errs <- http.ListenAndServe(p, api.MakeHandler())
 }
+
+func makeLatencyPublisher(pub messaging.Publisher) messaging.Publisher {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "lora_adapter",
+		Subsystem: "api",
+		Name:      "publish_latency_seconds",
+		Help:      "Time between the message creation and its publish to the broker.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "lora_adapter",
+		Subsystem: "api",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than published due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewPublisher(pub, hist, skewed)
+}
@@ -0,0 +1,245 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/consumers"
+	mongowriter "github.com/mainflux/mainflux/consumers/writers/mongodb"
+	pgwriter "github.com/mainflux/mainflux/consumers/writers/postgres"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/readers"
+	"github.com/mainflux/mainflux/readers/migrate"
+	mongoreader "github.com/mainflux/mainflux/readers/mongodb"
+	pgreader "github.com/mainflux/mainflux/readers/postgres"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	svcName = "messages-migrate"
+	sep     = ","
+
+	postgresStore = "postgres"
+	mongoStore    = "mongodb"
+
+	defLogLevel      = "info"
+	defSrc           = mongoStore
+	defDst           = postgresStore
+	defChannels      = ""
+	defBatchSize     = "1000"
+	defStateFile     = "messages-migrate.json"
+	defDryRun        = "false"
+	defPGHost        = "localhost"
+	defPGPort        = "5432"
+	defPGUser        = "mainflux"
+	defPGPass        = "mainflux"
+	defPGDB          = "messages"
+	defPGSSLMode     = "disable"
+	defPGSSLCert     = ""
+	defPGSSLKey      = ""
+	defPGSSLRootCert = ""
+	defMongoHost     = "localhost"
+	defMongoPort     = "27017"
+	defMongoDB       = "mainflux"
+
+	envLogLevel      = "MF_MESSAGES_MIGRATE_LOG_LEVEL"
+	envSrc           = "MF_MESSAGES_MIGRATE_SRC"
+	envDst           = "MF_MESSAGES_MIGRATE_DST"
+	envChannels      = "MF_MESSAGES_MIGRATE_CHANNELS"
+	envBatchSize     = "MF_MESSAGES_MIGRATE_BATCH_SIZE"
+	envStateFile     = "MF_MESSAGES_MIGRATE_STATE_FILE"
+	envDryRun        = "MF_MESSAGES_MIGRATE_DRY_RUN"
+	envPGHost        = "MF_MESSAGES_MIGRATE_POSTGRES_HOST"
+	envPGPort        = "MF_MESSAGES_MIGRATE_POSTGRES_PORT"
+	envPGUser        = "MF_MESSAGES_MIGRATE_POSTGRES_USER"
+	envPGPass        = "MF_MESSAGES_MIGRATE_POSTGRES_PASS"
+	envPGDB          = "MF_MESSAGES_MIGRATE_POSTGRES_DB"
+	envPGSSLMode     = "MF_MESSAGES_MIGRATE_POSTGRES_SSL_MODE"
+	envPGSSLCert     = "MF_MESSAGES_MIGRATE_POSTGRES_SSL_CERT"
+	envPGSSLKey      = "MF_MESSAGES_MIGRATE_POSTGRES_SSL_KEY"
+	envPGSSLRootCert = "MF_MESSAGES_MIGRATE_POSTGRES_SSL_ROOT_CERT"
+	envMongoHost     = "MF_MESSAGES_MIGRATE_MONGO_HOST"
+	envMongoPort     = "MF_MESSAGES_MIGRATE_MONGO_PORT"
+	envMongoDB       = "MF_MESSAGES_MIGRATE_MONGO_DB"
+)
+
+const usage = `Usage: mainflux-messages-migrate
+
+Copies the SenML messages of the channels from one message store to another.
+The tool is configured using the MF_MESSAGES_MIGRATE_* environment variables.
+
+Limitations:
+  - Only the postgres (also serving TimescaleDB) and mongodb stores are
+    supported, Cassandra and InfluxDB can't be the source or the destination.
+  - Only SenML messages are migrated, the migration stops at the first JSON
+    message.
+  - The channels are not discovered, they have to be listed in
+    MF_MESSAGES_MIGRATE_CHANNELS.
+`
+
+type config struct {
+	logLevel  string
+	src       string
+	dst       string
+	channels  []string
+	batchSize uint64
+	stateFile string
+	dryRun    bool
+	pgConfig  pgwriter.Config
+	mongoHost string
+	mongoPort string
+	mongoDB   string
+}
+
+// store is the message store the messages are migrated from or to.
+type store struct {
+	reader readers.MessageRepository
+	writer consumers.Consumer
+	close  func()
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+	}
+	flag.Parse()
+
+	cfg := loadConfig()
+
+	logger, err := logger.New(os.Stdout, cfg.logLevel)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	src := connectToStore(cfg.src, cfg, logger)
+	defer src.close()
+	dst := connectToStore(cfg.dst, cfg, logger)
+	defer dst.close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		<-c
+		cancel()
+	}()
+
+	mcfg := migrate.Config{
+		Channels:  cfg.channels,
+		BatchSize: cfg.batchSize,
+		DryRun:    cfg.dryRun,
+	}
+	m := migrate.New(src.reader, dst.writer, dst.reader, migrate.NewFileStore(cfg.stateFile), mcfg, logger)
+	n, err := m.Run(ctx)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Migration from %s to %s stopped after %d messages: %s", cfg.src, cfg.dst, n, err))
+		os.Exit(1)
+	}
+	if cfg.dryRun {
+		logger.Info(fmt.Sprintf("%d messages left to migrate from %s to %s", n, cfg.src, cfg.dst))
+		return
+	}
+	logger.Info(fmt.Sprintf("Migrated %d messages from %s to %s", n, cfg.src, cfg.dst))
+}
+
+func loadConfig() config {
+	src := mainflux.Env(envSrc, defSrc)
+	dst := mainflux.Env(envDst, defDst)
+	for _, s := range []string{src, dst} {
+		if s != postgresStore && s != mongoStore {
+			log.Fatalf("Invalid message store %s, expected %s or %s, other stores are not supported", s, postgresStore, mongoStore)
+		}
+	}
+	if src == dst {
+		log.Fatalf("Invalid value passed for %s, expected store other than %s", envDst, src)
+	}
+
+	channels := []string{}
+	for _, ch := range strings.Split(mainflux.Env(envChannels, defChannels), sep) {
+		if ch = strings.TrimSpace(ch); ch != "" {
+			channels = append(channels, ch)
+		}
+	}
+	if len(channels) == 0 {
+		log.Fatalf("No channels passed in %s, the channels to migrate have to be listed", envChannels)
+	}
+
+	batchSize, err := strconv.ParseUint(mainflux.Env(envBatchSize, defBatchSize), 10, 64)
+	if err != nil || batchSize == 0 {
+		log.Fatalf("Invalid value passed for %s\n", envBatchSize)
+	}
+
+	dryRun, err := strconv.ParseBool(mainflux.Env(envDryRun, defDryRun))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envDryRun)
+	}
+
+	pgConfig := pgwriter.Config{
+		Host:        mainflux.Env(envPGHost, defPGHost),
+		Port:        mainflux.Env(envPGPort, defPGPort),
+		User:        mainflux.Env(envPGUser, defPGUser),
+		Pass:        mainflux.Env(envPGPass, defPGPass),
+		Name:        mainflux.Env(envPGDB, defPGDB),
+		SSLMode:     mainflux.Env(envPGSSLMode, defPGSSLMode),
+		SSLCert:     mainflux.Env(envPGSSLCert, defPGSSLCert),
+		SSLKey:      mainflux.Env(envPGSSLKey, defPGSSLKey),
+		SSLRootCert: mainflux.Env(envPGSSLRootCert, defPGSSLRootCert),
+	}
+
+	return config{
+		logLevel:  mainflux.Env(envLogLevel, defLogLevel),
+		src:       src,
+		dst:       dst,
+		channels:  channels,
+		batchSize: batchSize,
+		stateFile: mainflux.Env(envStateFile, defStateFile),
+		dryRun:    dryRun,
+		pgConfig:  pgConfig,
+		mongoHost: mainflux.Env(envMongoHost, defMongoHost),
+		mongoPort: mainflux.Env(envMongoPort, defMongoPort),
+		mongoDB:   mainflux.Env(envMongoDB, defMongoDB),
+	}
+}
+
+func connectToStore(name string, cfg config, logger logger.Logger) store {
+	switch name {
+	case postgresStore:
+		// The writer connection creates the messages table if it's missing.
+		db, err := pgwriter.Connect(cfg.pgConfig)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to connect to Postgres: %s", err))
+			os.Exit(1)
+		}
+		return store{
+			reader: pgreader.New(db),
+			writer: pgwriter.New(db),
+			close:  func() { db.Close() },
+		}
+	default:
+		addr := fmt.Sprintf("mongodb://%s:%s", cfg.mongoHost, cfg.mongoPort)
+		client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to connect to database: %s", err))
+			os.Exit(1)
+		}
+		db := client.Database(cfg.mongoDB)
+		return store{
+			reader: mongoreader.New(db),
+			writer: mongowriter.New(db),
+			close:  func() { client.Disconnect(context.Background()) },
+		}
+	}
+}
@@ -18,10 +18,12 @@ import (
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
 	"github.com/mainflux/mainflux/readers/mongodb"
+	"github.com/mainflux/mainflux/readers/tracing"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -45,6 +47,9 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defAdminEmail        = ""
 
 	envLogLevel          = "MF_MONGO_READER_LOG_LEVEL"
 	envPort              = "MF_MONGO_READER_PORT"
@@ -58,6 +63,9 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envAdminEmail        = "MF_MONGO_READER_ADMIN_EMAIL"
 )
 
 type config struct {
@@ -73,6 +81,9 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	adminEmail        string
 }
 
 func main() {
@@ -82,7 +93,9 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
-	conn := connectToThings(cfg, logger)
+	stdprometheus.MustRegister(mainflux.BuildInfo("mongodb-reader"))
+
+	conn := connectToGRPC(cfg.thingsAuthURL, "things", cfg, logger)
 	defer conn.Close()
 
 	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
@@ -90,9 +103,23 @@ func main() {
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	admin, authConn := createAdmin(cfg, authTracer, logger)
+	if authConn != nil {
+		defer authConn.Close()
+	}
+
+	tracer, closer := initJaeger("mongodb-reader", cfg.jaegerURL, logger)
+	defer closer.Close()
+
+	dbTracer, dbCloser := initJaeger("mongodb-reader_db", cfg.jaegerURL, logger)
+	defer dbCloser.Close()
+
 	db := connectToMongoDB(cfg.dbHost, cfg.dbPort, cfg.dbName, logger)
 
-	repo := newService(db, logger)
+	repo := newService(db, dbTracer, logger)
 
 	errs := make(chan error, 2)
 	go func() {
@@ -101,7 +128,7 @@ func main() {
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
-	go startHTTPServer(repo, tc, cfg, logger, errs)
+	go startHTTPServer(tracer, repo, tc, admin, cfg, logger, errs)
 
 	err = <-errs
 	logger.Error(fmt.Sprintf("MongoDB reader service terminated: %s", err))
@@ -118,6 +145,11 @@ func loadConfigs() config {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	usersAuthTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
 	return config{
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
 		port:              mainflux.Env(envPort, defPort),
@@ -131,6 +163,9 @@ func loadConfigs() config {
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		thingsAuthTimeout: authTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       usersAuthTimeout,
+		adminEmail:        mainflux.Env(envAdminEmail, defAdminEmail),
 	}
 }
 
@@ -169,7 +204,23 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 	return tracer, closer
 }
 
-func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
+// createAdmin returns the reader admin identified by the auth service, along
+// with the auth service gRPC connection. The connection is nil if the admin
+// email isn't set, which disables the filtering by tenant.
+func createAdmin(cfg config, tracer opentracing.Tracer, logger logger.Logger) (api.Admin, *grpc.ClientConn) {
+	if cfg.adminEmail == "" {
+		return api.Admin{}, nil
+	}
+
+	conn := connectToGRPC(cfg.authURL, "auth", cfg, logger)
+	admin := api.Admin{
+		Auth:  authapi.NewClient(tracer, conn, cfg.authTimeout),
+		Email: cfg.adminEmail,
+	}
+	return admin, conn
+}
+
+func connectToGRPC(url, svc string, cfg config, logger logger.Logger) *grpc.ClientConn {
 	var opts []grpc.DialOption
 	if cfg.clientTLS {
 		if cfg.caCerts != "" {
@@ -185,16 +236,16 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 		opts = append(opts, grpc.WithInsecure())
 	}
 
-	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	conn, err := grpc.Dial(url, opts...)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		logger.Error(fmt.Sprintf("Failed to connect to %s service: %s", svc, err))
 		os.Exit(1)
 	}
 	return conn
 }
 
-func newService(db *mongo.Database, logger logger.Logger) readers.MessageRepository {
-	repo := mongodb.New(db)
+func newService(db *mongo.Database, tracer opentracing.Tracer, logger logger.Logger) readers.MessageRepository {
+	repo := tracing.MessageRepositoryMiddleware(tracer, mongodb.New(db))
 	repo = api.LoggingMiddleware(repo, logger)
 	repo = api.MetricsMiddleware(
 		repo,
@@ -215,14 +266,14 @@ func newService(db *mongo.Database, logger logger.Logger) readers.MessageReposit
 	return repo
 }
 
-func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, cfg config, logger logger.Logger, errs chan error) {
+func startHTTPServer(tracer opentracing.Tracer, repo readers.MessageRepository, tc mainflux.ThingsServiceClient, admin api.Admin, cfg config, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", cfg.port)
 	if cfg.serverCert != "" || cfg.serverKey != "" {
 		logger.Info(fmt.Sprintf("Mongo reader service started using https on port %s with cert %s key %s",
 			cfg.port, cfg.serverCert, cfg.serverKey))
-		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tc, "mongodb-reader"))
+		errs <- http.ListenAndServeTLS(p, cfg.serverCert, cfg.serverKey, api.MakeHandler(repo, tracer, tc, admin, "mongodb-reader"))
 		return
 	}
 	logger.Info(fmt.Sprintf("Mongo reader service started, exposed port %s", cfg.port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, "mongodb-reader"))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tracer, tc, admin, "mongodb-reader"))
 }
@@ -12,93 +12,109 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	r "github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/consumers/schemas"
 	"github.com/mainflux/mainflux/consumers/writers/api"
 	"github.com/mainflux/mainflux/consumers/writers/mongodb"
+	mfconfig "github.com/mainflux/mainflux/internal/config"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/decompress"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/limits"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/things"
+	authhttpapi "github.com/mainflux/mainflux/things/api/auth/http"
+	broker "github.com/nats-io/nats.go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
-	svcName = "mongodb-writer"
-
-	defLogLevel    = "error"
-	defNatsURL     = "nats://localhost:4222"
-	defPort        = "8180"
-	defDB          = "mainflux"
-	defDBHost      = "localhost"
-	defDBPort      = "27017"
-	defConfigPath  = "/config.toml"
-	defContentType = "application/senml+json"
-	defTransformer = "senml"
-
-	envNatsURL     = "MF_NATS_URL"
-	envLogLevel    = "MF_MONGO_WRITER_LOG_LEVEL"
-	envPort        = "MF_MONGO_WRITER_PORT"
-	envDB          = "MF_MONGO_WRITER_DB"
-	envDBHost      = "MF_MONGO_WRITER_DB_HOST"
-	envDBPort      = "MF_MONGO_WRITER_DB_PORT"
-	envConfigPath  = "MF_MONGO_WRITER_CONFIG_PATH"
-	envContentType = "MF_MONGO_WRITER_CONTENT_TYPE"
-	envTransformer = "MF_MONGO_WRITER_TRANSFORMER"
+	svcName       = "mongodb-writer"
+	thingsTimeout = 5 * time.Second
+
+	envPrefix     = "MF_MONGO_WRITER_"
+	envNatsPrefix = "MF_NATS_"
 )
 
 type config struct {
-	natsURL     string
-	logLevel    string
-	port        string
-	dbName      string
-	dbHost      string
-	dbPort      string
-	configPath  string
-	contentType string
-	transformer string
+	NatsURLs          []string      `env:"MF_NATS_URL" default:"nats://localhost:4222"`
+	LogLevel          string        `env:"MF_MONGO_WRITER_LOG_LEVEL" default:"error"`
+	Port              string        `env:"MF_MONGO_WRITER_PORT" default:"8180"`
+	DBName            string        `env:"MF_MONGO_WRITER_DB" default:"mainflux"`
+	DBHost            string        `env:"MF_MONGO_WRITER_DB_HOST" default:"localhost"`
+	DBPort            string        `env:"MF_MONGO_WRITER_DB_PORT" default:"27017"`
+	ConfigPath        string        `env:"MF_MONGO_WRITER_CONFIG_PATH" default:"/config.toml"`
+	ContentType       string        `env:"MF_MONGO_WRITER_CONTENT_TYPE" default:"application/senml+json"`
+	Transformer       string        `env:"MF_MONGO_WRITER_TRANSFORMER" default:"senml"`
+	ProtoDescriptors  string        `env:"MF_MONGO_WRITER_PROTOBUF_DESCRIPTORS" default:"/descriptors.pb"`
+	DecompressLimit   int64         `env:"MF_MONGO_WRITER_DECOMPRESS_LIMIT" default:"10485760"`
+	ThingsESURL       string        `env:"MF_MONGO_WRITER_THINGS_ES_URL"`
+	ThingsESPass      string        `env:"MF_MONGO_WRITER_THINGS_ES_PASS,secret"`
+	ThingsESDB        int           `env:"MF_MONGO_WRITER_THINGS_ES_DB" default:"0"`
+	ThingsURL         string        `env:"MF_MONGO_WRITER_THINGS_URL"`
+	ThingsSecret      string        `env:"MF_MONGO_WRITER_THINGS_SECRET,secret"`
+	Retention         time.Duration `env:"MF_MONGO_WRITER_RETENTION" default:"0"`
+	RetentionInterval time.Duration `env:"MF_MONGO_WRITER_RETENTION_INTERVAL" default:"1h"`
 }
 
 func main() {
 	cfg := loadConfigs()
 
-	logger, err := logger.New(os.Stdout, cfg.logLevel)
+	logger, err := logger.New(os.Stdout, cfg.LogLevel)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
+	stdprometheus.MustRegister(mainflux.BuildInfo(svcName))
+
+	logger.Info(fmt.Sprintf("MongoDB writer configuration: %s", mfconfig.Redact(&cfg)))
+
+	pubSub, err := nats.NewPubSub(strings.Join(cfg.NatsURLs, ","), "", logger)
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
 		os.Exit(1)
 	}
 	defer pubSub.Close()
 
-	addr := fmt.Sprintf("mongodb://%s:%s", cfg.dbHost, cfg.dbPort)
+	addr := fmt.Sprintf("mongodb://%s:%s", cfg.DBHost, cfg.DBPort)
 	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
 	if err != nil {
 		logger.Error(fmt.Sprintf("Failed to connect to database: %s", err))
 		os.Exit(1)
 	}
 
-	db := client.Database(cfg.dbName)
+	db := client.Database(cfg.DBName)
 	repo := mongodb.New(db)
 
 	counter, latency := makeMetrics()
 	repo = api.LoggingMiddleware(repo, logger)
 	repo = api.MetricsMiddleware(repo, counter, latency)
-	t := makeTransformer(cfg, logger)
+	t := decompress.New(cfg.DecompressLimit, makeLimits(makeTransformer(cfg, logger), cfg, logger))
+	t, errsConn := makeErrorsMiddleware(t, cfg, logger)
+	if errsConn != nil {
+		defer errsConn.Close()
+	}
 
-	if err := consumers.Start(pubSub, repo, t, cfg.configPath, logger); err != nil {
+	if err := consumers.Start(makeLatencySubscriber(pubSub), repo, t, cfg.ConfigPath, logger); err != nil {
 		logger.Error(fmt.Sprintf("Failed to start MongoDB writer: %s", err))
 		os.Exit(1)
 	}
 
+	startRetention(mongodb.NewRetentionStore(db), cfg, logger)
+
 	errs := make(chan error, 2)
 	go func() {
 		c := make(chan os.Signal)
@@ -106,24 +122,18 @@ func main() {
 		errs <- fmt.Errorf("%s", <-c)
 	}()
 
-	go startHTTPService(cfg.port, logger, errs)
+	go startHTTPService(cfg.Port, logger, errs)
 
 	err = <-errs
 	logger.Error(fmt.Sprintf("MongoDB writer service terminated: %s", err))
 }
 
 func loadConfigs() config {
-	return config{
-		natsURL:     mainflux.Env(envNatsURL, defNatsURL),
-		logLevel:    mainflux.Env(envLogLevel, defLogLevel),
-		port:        mainflux.Env(envPort, defPort),
-		dbName:      mainflux.Env(envDB, defDB),
-		dbHost:      mainflux.Env(envDBHost, defDBHost),
-		dbPort:      mainflux.Env(envDBPort, defDBPort),
-		configPath:  mainflux.Env(envConfigPath, defConfigPath),
-		contentType: mainflux.Env(envContentType, defContentType),
-		transformer: mainflux.Env(envTransformer, defTransformer),
+	var cfg config
+	if err := mfconfig.Load(&cfg, envPrefix, envNatsPrefix); err != nil {
+		log.Fatal(err)
 	}
+	return cfg
 }
 
 func makeMetrics() (*kitprometheus.Counter, *kitprometheus.Summary) {
@@ -145,22 +155,178 @@ func makeMetrics() (*kitprometheus.Counter, *kitprometheus.Summary) {
 }
 
 func makeTransformer(cfg config, logger logger.Logger) transformers.Transformer {
-	switch strings.ToUpper(cfg.transformer) {
+	switch strings.ToUpper(cfg.Transformer) {
 	case "SENML":
 		logger.Info("Using SenML transformer")
-		return senml.New(cfg.contentType)
+		return senml.New(cfg.ContentType)
 	case "JSON":
 		logger.Info("Using JSON transformer")
-		return json.New()
+		return makeJSONTransformer(cfg, logger)
+	case "PROTOBUF":
+		logger.Info("Using protobuf transformer")
+		pc, err := consumers.LoadProtobufConfig(cfg.ConfigPath)
+		if err != nil {
+			logger.Warn(fmt.Sprintf("Continue without protobuf message types, failed to load them: %s", err))
+		}
+		pc.Descriptors = cfg.ProtoDescriptors
+		t, err := protobuf.New(pc, makeJSONTransformer(cfg, logger))
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to create protobuf transformer: %s", err))
+			os.Exit(1)
+		}
+		return t
 	default:
-		logger.Error(fmt.Sprintf("Can't create transformer: unknown transformer type %s", cfg.transformer))
+		logger.Error(fmt.Sprintf("Can't create transformer: unknown transformer type %s", cfg.Transformer))
 		os.Exit(1)
 		return nil
 	}
 }
 
+func makeJSONTransformer(cfg config, logger logger.Logger) transformers.Transformer {
+	sc := makeSchemas(cfg, logger)
+	tc, err := consumers.LoadTransformerConfig(cfg.ConfigPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default JSON transformer settings, failed to load them: %s", err))
+		return json.NewWithSchemas(json.Config{}, nil, sc)
+	}
+	fallbacks := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mongodb",
+		Subsystem: "message_writer",
+		Name:      "time_fallback_count",
+		Help:      "Number of messages stored with arrival time instead of payload time.",
+	}, []string{"reason"})
+	return json.NewWithSchemas(tc, fallbacks, sc)
+}
+
+// makeSchemas returns the channel schemas kept up to date using the things
+// events, or nil if the things event store isn't set.
+func makeSchemas(cfg config, logger logger.Logger) json.Schemas {
+	if cfg.ThingsESURL == "" {
+		return nil
+	}
+	cache := schemas.NewCache()
+	sub := schemas.NewSubscriber(connectToThingsES(cfg), cache, logger)
+	if err := loadChannels(sub, things.SchemaKey, cfg); err != nil {
+		logger.Error(fmt.Sprintf("Failed to load channel schemas: %s", err))
+		os.Exit(1)
+	}
+	go func() {
+		if err := sub.Subscribe(context.Background()); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to subscribe to things event store: %s", err))
+		}
+	}()
+
+	logger.Info("Validating JSON messages against channel schemas")
+	return cache
+}
+
+// loadChannels brings the handler of the subscriber up to date. The channels
+// having the metadata key set are seeded from the things service, if it's
+// set, and the events are used only for the later changes. Otherwise the
+// events stored in the stream are replayed.
+func loadChannels(sub *schemas.Subscriber, key string, cfg config) error {
+	if cfg.ThingsURL == "" {
+		return sub.Load(context.Background())
+	}
+	return sub.Seed(context.Background(), authhttpapi.NewClient(cfg.ThingsURL, cfg.ThingsSecret, thingsTimeout), key)
+}
+
+// startRetention purges the expired messages in the background. The channels
+// retention hints are kept up to date using the things events, if the things
+// event store is set.
+func startRetention(store retention.Store, cfg config, logger logger.Logger) {
+	if cfg.Retention == 0 && cfg.ThingsESURL == "" {
+		return
+	}
+
+	cache := retention.NewCache()
+	if cfg.ThingsESURL != "" {
+		sub := schemas.NewSubscriber(connectToThingsES(cfg), cache, logger)
+		if err := loadChannels(sub, things.RetentionKey, cfg); err != nil {
+			logger.Error(fmt.Sprintf("Failed to load channel retention hints: %s", err))
+			os.Exit(1)
+		}
+		go func() {
+			if err := sub.Subscribe(context.Background()); err != nil {
+				logger.Warn(fmt.Sprintf("Failed to subscribe to things event store: %s", err))
+			}
+		}()
+	}
+
+	p := retention.NewPurger(store, cache, cfg.Retention, logger)
+	go func() {
+		if err := p.Run(context.Background(), cfg.RetentionInterval); err != nil {
+			logger.Warn(fmt.Sprintf("Messages purger stopped: %s", err))
+		}
+	}()
+	logger.Info(fmt.Sprintf("Purging expired messages every %s", cfg.RetentionInterval))
+}
+
+func connectToThingsES(cfg config) *r.Client {
+	return r.NewClient(&r.Options{
+		Addr:     cfg.ThingsESURL,
+		Password: cfg.ThingsESPass,
+		DB:       cfg.ThingsESDB,
+	})
+}
+
 func startHTTPService(port string, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", port)
 	logger.Info(fmt.Sprintf("Mongodb writer service started, exposed port %s", p))
 	errs <- http.ListenAndServe(p, api.MakeHandler(svcName))
 }
+
+// makeLimits rejects the messages exceeding the limits before they reach
+// the database.
+func makeLimits(t transformers.Transformer, cfg config, logger logger.Logger) transformers.Transformer {
+	lc, err := consumers.LoadLimitsConfig(cfg.ConfigPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default message limits, failed to load them: %s", err))
+	}
+	return limits.New(lc, t)
+}
+
+// makeErrorsMiddleware returns the transformer reporting the transform errors
+// and the NATS connection the error events are published to, if any.
+func makeErrorsMiddleware(t transformers.Transformer, cfg config, logger logger.Logger) (transformers.Transformer, *broker.Conn) {
+	ec, err := consumers.LoadErrorsConfig(cfg.ConfigPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default transform errors settings, failed to load them: %s", err))
+	}
+
+	var pub consumers.EventPublisher
+	var conn *broker.Conn
+	if ec.Subject != "" {
+		conn, err = broker.Connect(strings.Join(cfg.NatsURLs, ","))
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to connect to NATS for transform error events: %s", err))
+			os.Exit(1)
+		}
+		pub = conn
+	}
+
+	counter := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mongodb",
+		Subsystem: "message_writer",
+		Name:      "transform_error_count",
+		Help:      "Number of messages failed to transform.",
+	}, []string{"transformer", "channel", "reason"})
+	return consumers.ErrorsMiddleware(t, strings.ToLower(cfg.Transformer), counter, pub, ec, logger), conn
+}
+
+func makeLatencySubscriber(sub messaging.Subscriber) messaging.Subscriber {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "mongodb",
+		Subsystem: "message_writer",
+		Name:      "write_latency_seconds",
+		Help:      "Time between the message creation by the adapter and its storage.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mongodb",
+		Subsystem: "message_writer",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than stored due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewSubscriber(sub, hist, skewed)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -9,25 +10,36 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/clients/events"
+	eventsredis "github.com/mainflux/mainflux/internal/clients/events/redis"
+	"github.com/mainflux/mainflux/internal/topics"
 	mflog "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/mqtt"
 	mqttredis "github.com/mainflux/mainflux/mqtt/redis"
 	"github.com/mainflux/mainflux/pkg/auth"
+	authredis "github.com/mainflux/mainflux/pkg/auth/redis"
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
 	mqttpub "github.com/mainflux/mainflux/pkg/messaging/mqtt"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/mainflux/mainflux/pkg/quota"
+	quotaredis "github.com/mainflux/mainflux/pkg/quota/redis"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	mp "github.com/mainflux/mproxy/pkg/mqtt"
 	"github.com/mainflux/mproxy/pkg/session"
 	ws "github.com/mainflux/mproxy/pkg/websocket"
 	opentracing "github.com/opentracing/opentracing-go"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	jconfig "github.com/uber/jaeger-client-go/config"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
@@ -35,8 +47,10 @@ import (
 
 const (
 	// Logging
-	defLogLevel = "error"
-	envLogLevel = "MF_MQTT_ADAPTER_LOG_LEVEL"
+	defLogLevel      = "error"
+	defLogLevelToken = ""
+	envLogLevel      = "MF_MQTT_ADAPTER_LOG_LEVEL"
+	envLogLevelToken = "MF_MQTT_ADAPTER_LOG_LEVEL_TOKEN"
 	// MQTT
 	defMQTTPort              = "1883"
 	defMQTTTargetHost        = "0.0.0.0"
@@ -90,6 +104,55 @@ const (
 	defAuthcacheURL  = "localhost:6379"
 	defAuthCachePass = ""
 	defAuthCacheDB   = "0"
+	// Access decisions cache
+	envAuthCacheTTL           = "MF_MQTT_ADAPTER_AUTH_CACHE_TTL"
+	envAuthCacheLocalTTL      = "MF_MQTT_ADAPTER_AUTH_CACHE_LOCAL_TTL"
+	envAuthCacheSubtopicDepth = "MF_MQTT_ADAPTER_AUTH_CACHE_SUBTOPIC_DEPTH"
+	envESConsumerName         = "MF_MQTT_ADAPTER_EVENT_CONSUMER"
+	defAuthCacheTTL           = "5m"
+	defAuthCacheLocalTTL      = "5s"
+	defAuthCacheSubtopicDepth = "0"
+	defESConsumerName         = ""
+	// Topics
+	envTopicTemplate = "MF_TOPIC_TEMPLATE"
+	defTopicTemplate = topics.DefaultTemplate
+	// CONNECT flood protection
+	envConnectThreshold = "MF_MQTT_ADAPTER_CONNECT_THRESHOLD"
+	envConnectWindow    = "MF_MQTT_ADAPTER_CONNECT_WINDOW"
+	envConnectExempt    = "MF_MQTT_ADAPTER_CONNECT_EXEMPT"
+	defConnectThreshold = "0"
+	defConnectWindow    = "1m"
+	defConnectExempt    = ""
+	// Channel quotas
+	envQuotaURL      = "MF_MQTT_ADAPTER_QUOTA_URL"
+	envQuotaPass     = "MF_MQTT_ADAPTER_QUOTA_PASS"
+	envQuotaDB       = "MF_MQTT_ADAPTER_QUOTA_DB"
+	envQuotaDefault  = "MF_MQTT_ADAPTER_QUOTA_DEFAULT"
+	envQuotaBatch    = "MF_MQTT_ADAPTER_QUOTA_BATCH"
+	envQuotaFailOpen = "MF_MQTT_ADAPTER_QUOTA_FAIL_OPEN"
+	defQuotaURL      = ""
+	defQuotaPass     = ""
+	defQuotaDB       = "0"
+	defQuotaDefault  = "0"
+	defQuotaBatch    = "100"
+	defQuotaFailOpen = "false"
+	// Per-hook concurrency limits
+	envConnectConcurrency   = "MF_MQTT_ADAPTER_CONNECT_CONCURRENCY"
+	envPublishConcurrency   = "MF_MQTT_ADAPTER_PUBLISH_CONCURRENCY"
+	envSubscribeConcurrency = "MF_MQTT_ADAPTER_SUBSCRIBE_CONCURRENCY"
+	envConcurrencyWait      = "MF_MQTT_ADAPTER_CONCURRENCY_WAIT"
+	defConnectConcurrency   = "0"
+	defPublishConcurrency   = "0"
+	defSubscribeConcurrency = "0"
+	defConcurrencyWait      = "100ms"
+	// Dry run
+	envDryRun        = "MF_MQTT_ADAPTER_DRY_RUN"
+	envDryRunClients = "MF_MQTT_ADAPTER_DRY_RUN_CLIENTS"
+	defDryRun        = "false"
+	defDryRunClients = ""
+	// Things events
+	thingsStream  = "mainflux.things"
+	esGroupPrefix = "mainflux.mqtt.auth"
 )
 
 type config struct {
@@ -104,6 +167,7 @@ type config struct {
 	httpTargetPath        string
 	jaegerURL             string
 	logLevel              string
+	logLevelToken         string
 	thingsURL             string
 	thingsAuthURL         string
 	thingsAuthTimeout     time.Duration
@@ -117,6 +181,16 @@ type config struct {
 	authURL               string
 	authPass              string
 	authDB                string
+	authCache             auth.CacheConfig
+	esConsumerName        string
+	topicTemplate         topics.Template
+	flood                 mqtt.FloodConfig
+	quotaURL              string
+	quotaPass             string
+	quotaDB               string
+	quota                 quota.Config
+	concurrency           mqtt.ConcurrencyConfig
+	dryRun                mqtt.DryRunConfig
 }
 
 func main() {
@@ -127,6 +201,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("mqtt"))
+
 	if cfg.mqttTargetHealthCheck != "" {
 		notify := func(e error, next time.Duration) {
 			logger.Info(fmt.Sprintf("Broker not ready: %s, next try in %s", e.Error(), next))
@@ -158,7 +234,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	fwd := mqtt.NewForwarder(nats.SubjectAllChannels, logger)
+	fwd := mqtt.NewForwarder(nats.SubjectAllChannels, cfg.topicTemplate, logger)
 	if err := fwd.Forward(nps, mpub); err != nil {
 		logger.Error(fmt.Sprintf("Failed to forward NATS messages: %s", err))
 		os.Exit(1)
@@ -180,13 +256,34 @@ func main() {
 	defer thingsCloser.Close()
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
-	authClient := auth.New(ac, tc)
+	cache := authredis.NewAccessCache(ac, cfg.authCache.TTL)
+	authClient := auth.New(ac, tc, cache, cfg.authCache)
+	go subscribeToThingsES(authClient, ec, cfg.esConsumerName, logger)
 
 	// Event handler for MQTT hooks
-	h := mqtt.NewHandler([]messaging.Publisher{np}, es, logger, authClient)
+	h := mqtt.NewHandler([]messaging.Publisher{makeLatencyPublisher(np)}, es, logger, authClient, cfg.topicTemplate)
+	h = newFloodLimiter(h, cfg)
+	if cfg.quotaURL != "" {
+		qc := connectToRedis(cfg.quotaURL, cfg.quotaPass, cfg.quotaDB, logger)
+		defer qc.Close()
+		checker := quota.NewChecker(quotaredis.NewStore(qc), cfg.quota, time.Now)
+		h = mqtt.NewQuotaLimiter(h, checker, cfg.topicTemplate)
+		logger.Info(fmt.Sprintf("Channel quotas are enforced using %s", cfg.quotaURL))
+	}
+	h = newDryRunHandler(h, cfg, logger)
+	if cfg.dryRun.Enabled || len(cfg.dryRun.Clients) > 0 {
+		logger.Info("Dry run - the authorized messages of the dry run clients aren't published")
+	}
+	if c := cfg.concurrency; c.Connect > 0 || c.Publish > 0 || c.Subscribe > 0 {
+		h = newConcurrencyLimiter(h, cfg)
+	}
 
 	errs := make(chan error, 2)
 
+	// The metrics are served on the MQTT over WS port.
+	http.Handle("/metrics", promhttp.Handler())
+	handleLogLevel(logger, cfg.logLevelToken)
+
 	logger.Info(fmt.Sprintf("Starting MQTT proxy on port %s", cfg.mqttPort))
 	go proxyMQTT(cfg, logger, h, errs)
 
@@ -219,6 +316,95 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envMQTTForwarderTimeout, err.Error())
 	}
 
+	tmpl, err := topics.New(mainflux.Env(envTopicTemplate, defTopicTemplate))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envTopicTemplate, err.Error())
+	}
+
+	threshold, err := strconv.Atoi(mainflux.Env(envConnectThreshold, defConnectThreshold))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envConnectThreshold, err.Error())
+	}
+
+	window, err := time.ParseDuration(mainflux.Env(envConnectWindow, defConnectWindow))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envConnectWindow, err.Error())
+	}
+
+	cacheTTL, err := time.ParseDuration(mainflux.Env(envAuthCacheTTL, defAuthCacheTTL))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthCacheTTL, err.Error())
+	}
+
+	cacheLocalTTL, err := time.ParseDuration(mainflux.Env(envAuthCacheLocalTTL, defAuthCacheLocalTTL))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthCacheLocalTTL, err.Error())
+	}
+
+	subtopicDepth, err := strconv.Atoi(mainflux.Env(envAuthCacheSubtopicDepth, defAuthCacheSubtopicDepth))
+	if err != nil || subtopicDepth < 0 {
+		log.Fatalf("Invalid value passed for %s\n", envAuthCacheSubtopicDepth)
+	}
+
+	// Each instance caches the access decisions on its own, so it needs to
+	// receive all the events, i.e. to be the only consumer of its group.
+	consumer := mainflux.Env(envESConsumerName, defESConsumerName)
+	if consumer == "" {
+		consumer = mainflux.Env(envInstance, defInstance)
+	}
+	if consumer == "" {
+		if consumer, err = os.Hostname(); err != nil {
+			log.Fatalf("Failed to name event consumer: %s", err.Error())
+		}
+	}
+
+	quotaDefault, err := strconv.ParseUint(mainflux.Env(envQuotaDefault, defQuotaDefault), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQuotaDefault, err.Error())
+	}
+
+	quotaBatch, err := strconv.ParseUint(mainflux.Env(envQuotaBatch, defQuotaBatch), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQuotaBatch, err.Error())
+	}
+
+	quotaFailOpen, err := strconv.ParseBool(mainflux.Env(envQuotaFailOpen, defQuotaFailOpen))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envQuotaFailOpen, err.Error())
+	}
+
+	var concurrency [3]int64
+	for i, env := range [][2]string{
+		{envConnectConcurrency, defConnectConcurrency},
+		{envPublishConcurrency, defPublishConcurrency},
+		{envSubscribeConcurrency, defSubscribeConcurrency},
+	} {
+		concurrency[i], err = strconv.ParseInt(mainflux.Env(env[0], env[1]), 10, 64)
+		if err != nil || concurrency[i] < 0 {
+			log.Fatalf("Invalid value passed for %s\n", env[0])
+		}
+	}
+
+	concurrencyWait, err := time.ParseDuration(mainflux.Env(envConcurrencyWait, defConcurrencyWait))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envConcurrencyWait, err.Error())
+	}
+
+	dryRun, err := strconv.ParseBool(mainflux.Env(envDryRun, defDryRun))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envDryRun, err.Error())
+	}
+
+	var dryRunClients []string
+	if ids := mainflux.Env(envDryRunClients, defDryRunClients); ids != "" {
+		dryRunClients = strings.Split(ids, ",")
+	}
+
+	var exempt []string
+	if ids := mainflux.Env(envConnectExempt, defConnectExempt); ids != "" {
+		exempt = strings.Split(ids, ",")
+	}
+
 	return config{
 		mqttPort:              mainflux.Env(envMQTTPort, defMQTTPort),
 		mqttTargetHost:        mainflux.Env(envMQTTTargetHost, defMQTTTargetHost),
@@ -235,6 +421,7 @@ func loadConfig() config {
 		thingsURL:             mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		natsURL:               mainflux.Env(envNatsURL, defNatsURL),
 		logLevel:              mainflux.Env(envLogLevel, defLogLevel),
+		logLevelToken:         mainflux.Env(envLogLevelToken, defLogLevelToken),
 		clientTLS:             tls,
 		caCerts:               mainflux.Env(envCACerts, defCACerts),
 		instance:              mainflux.Env(envInstance, defInstance),
@@ -244,7 +431,105 @@ func loadConfig() config {
 		authURL:               mainflux.Env(envAuthCacheURL, defAuthcacheURL),
 		authPass:              mainflux.Env(envAuthCachePass, defAuthCachePass),
 		authDB:                mainflux.Env(envAuthCacheDB, defAuthCacheDB),
+		authCache:             auth.CacheConfig{TTL: cacheTTL, LocalTTL: cacheLocalTTL, SubtopicDepth: subtopicDepth},
+		esConsumerName:        consumer,
+		topicTemplate:         tmpl,
+		flood: mqtt.FloodConfig{
+			Threshold: threshold,
+			Window:    window,
+			Exempt:    exempt,
+		},
+		quotaURL:  mainflux.Env(envQuotaURL, defQuotaURL),
+		quotaPass: mainflux.Env(envQuotaPass, defQuotaPass),
+		quotaDB:   mainflux.Env(envQuotaDB, defQuotaDB),
+		quota: quota.Config{
+			Default:  quotaDefault,
+			Batch:    quotaBatch,
+			FailOpen: quotaFailOpen,
+		},
+		concurrency: mqtt.ConcurrencyConfig{
+			Connect:   concurrency[0],
+			Publish:   concurrency[1],
+			Subscribe: concurrency[2],
+			Wait:      concurrencyWait,
+		},
+		dryRun: mqtt.DryRunConfig{
+			Enabled: dryRun,
+			Clients: dryRunClients,
+		},
+	}
+}
+
+// newFloodLimiter protects the things service from the CONNECT floods, and
+// counts the rejections.
+func newFloodLimiter(h session.Handler, cfg config) session.Handler {
+	rejected := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mqtt_adapter",
+		Subsystem: "api",
+		Name:      "connect_rejected_count",
+		Help:      "Number of rejected CONNECT requests.",
+	}, []string{"reason"})
+
+	return mqtt.NewFloodLimiter(h, cfg.flood, rejected, time.Now)
+}
+
+func makeLatencyPublisher(pub messaging.Publisher) messaging.Publisher {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "mqtt_adapter",
+		Subsystem: "api",
+		Name:      "publish_latency_seconds",
+		Help:      "Time between the message creation and its publish to the broker.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mqtt_adapter",
+		Subsystem: "api",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than published due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewPublisher(pub, hist, skewed)
+}
+
+// handleLogLevel mounts the log level endpoint on the MQTT over WS port. The
+// port is public, so the endpoint is not mounted without token.
+func handleLogLevel(l mflog.Logger, token string) {
+	lvl, ok := l.(mflog.Leveler)
+	if !ok || token == "" {
+		return
 	}
+	http.Handle("/loglevel", mflog.LevelHandler(lvl, token))
+}
+
+// newDryRunHandler counts the PUBLISH authorization decisions, and skips
+// publishing the messages of the clients in the dry run.
+func newDryRunHandler(h session.Handler, cfg config, logger mflog.Logger) session.Handler {
+	decisions := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mqtt_adapter",
+		Subsystem: "api",
+		Name:      "publish_decisions_count",
+		Help:      "Number of PUBLISH authorization decisions.",
+	}, []string{"decision"})
+
+	return mqtt.NewDryRunHandler(h, cfg.dryRun, decisions, logger)
+}
+
+// newConcurrencyLimiter bounds the authorizations in flight per hook, and
+// measures the wait time and the rejections.
+func newConcurrencyLimiter(h session.Handler, cfg config) session.Handler {
+	waited := kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+		Namespace: "mqtt_adapter",
+		Subsystem: "api",
+		Name:      "hook_wait_seconds",
+		Help:      "Time waited for the hook concurrency slot in seconds.",
+	}, []string{"hook"})
+	rejected := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "mqtt_adapter",
+		Subsystem: "api",
+		Name:      "hook_rejected_count",
+		Help:      "Number of requests rejected over the hook concurrency limit.",
+	}, []string{"hook"})
+
+	return mqtt.NewConcurrencyLimiter(h, cfg.concurrency, waited, rejected)
 }
 
 func initJaeger(svcName, url string, logger mflog.Logger) (opentracing.Tracer, io.Closer) {
@@ -295,6 +580,20 @@ func connectToThings(cfg config, logger mflog.Logger) *grpc.ClientConn {
 	return conn
 }
 
+// subscribeToThingsES invalidates the cached access decisions on the things
+// events changing the channel access.
+func subscribeToThingsES(h events.Handler, client *redis.Client, consumer string, logger mflog.Logger) {
+	group := fmt.Sprintf("%s.%s", esGroupPrefix, consumer)
+	stream := eventsredis.NewStream(client, thingsStream, group, consumer, group+".parked")
+	checkpoints := eventsredis.NewCheckpointRepository(client, thingsStream, group)
+	c := events.NewConsumer(stream, checkpoints, events.Config{}, logger)
+
+	logger.Info(fmt.Sprintf("Subscribed to Redis Event Store as %s", group))
+	if err := c.Consume(context.Background(), h); err != nil {
+		logger.Warn(fmt.Sprintf("MQTT adapter failed to subscribe to Redis event source: %s", err))
+	}
+}
+
 func connectToRedis(redisURL, redisPass, redisDB string, logger mflog.Logger) *redis.Client {
 	db, err := strconv.Atoi(redisDB)
 	if err != nil {
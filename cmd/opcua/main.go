@@ -21,6 +21,8 @@ import (
 	"github.com/mainflux/mainflux/opcua/db"
 	"github.com/mainflux/mainflux/opcua/gopcua"
 	"github.com/mainflux/mainflux/opcua/redis"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
@@ -87,6 +89,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("opcua-adapter"))
+
 	rmConn := connectToRedis(cfg.routeMapURL, cfg.routeMapPass, cfg.routeMapDB, logger)
 	defer rmConn.Close()
 
@@ -105,7 +109,7 @@ func main() {
 	defer pubSub.Close()
 
 	ctx := context.Background()
-	sub := gopcua.NewSubscriber(ctx, pubSub, thingRM, chanRM, connRM, logger)
+	sub := gopcua.NewSubscriber(ctx, makeLatencyPublisher(pubSub), thingRM, chanRM, connRM, logger)
 	browser := gopcua.NewBrowser(ctx, logger)
 
 	svc := opcua.New(sub, browser, thingRM, chanRM, connRM, cfg.opcuaConfig, logger)
@@ -221,3 +225,20 @@ func startHTTPServer(svc opcua.Service, cfg config, logger logger.Logger, errs c
 	logger.Info(fmt.Sprintf("opcua-adapter service started, exposed port %s", cfg.httpPort))
 	errs <- http.ListenAndServe(p, api.MakeHandler(svc))
 }
+
+func makeLatencyPublisher(pub messaging.Publisher) messaging.Publisher {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "opc_adapter",
+		Subsystem: "api",
+		Name:      "publish_latency_seconds",
+		Help:      "Time between the message creation and its publish to the broker.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "opc_adapter",
+		Subsystem: "api",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than published due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewPublisher(pub, hist, skewed)
+}
@@ -18,10 +18,12 @@ import (
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	"github.com/jmoiron/sqlx"
 	"github.com/mainflux/mainflux"
+	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
 	"github.com/mainflux/mainflux/readers/postgres"
+	"github.com/mainflux/mainflux/readers/tracing"
 	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
@@ -50,6 +52,9 @@ const (
 	defJaegerURL         = ""
 	defThingsAuthURL     = "localhost:8181"
 	defThingsAuthTimeout = "1s"
+	defAuthURL           = "localhost:8181"
+	defAuthTimeout       = "1s"
+	defAdminEmail        = ""
 
 	envLogLevel          = "MF_POSTGRES_READER_LOG_LEVEL"
 	envPort              = "MF_POSTGRES_READER_PORT"
@@ -67,6 +72,9 @@ const (
 	envJaegerURL         = "MF_JAEGER_URL"
 	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
 	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+	envAuthURL           = "MF_AUTH_GRPC_URL"
+	envAuthTimeout       = "MF_AUTH_GRPC_TIMEOUT"
+	envAdminEmail        = "MF_POSTGRES_READER_ADMIN_EMAIL"
 )
 
 type config struct {
@@ -78,6 +86,9 @@ type config struct {
 	jaegerURL         string
 	thingsAuthURL     string
 	thingsAuthTimeout time.Duration
+	authURL           string
+	authTimeout       time.Duration
+	adminEmail        string
 }
 
 func main() {
@@ -88,7 +99,9 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
-	conn := connectToThings(cfg, logger)
+	stdprometheus.MustRegister(mainflux.BuildInfo(svcName))
+
+	conn := connectToGRPC(cfg.thingsAuthURL, "things", cfg, logger)
 	defer conn.Close()
 
 	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
@@ -96,14 +109,28 @@ func main() {
 
 	tc := thingsapi.NewClient(conn, thingsTracer, cfg.thingsAuthTimeout)
 
+	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
+	defer authCloser.Close()
+
+	admin, authConn := createAdmin(cfg, authTracer, logger)
+	if authConn != nil {
+		defer authConn.Close()
+	}
+
+	tracer, closer := initJaeger("postgres-reader", cfg.jaegerURL, logger)
+	defer closer.Close()
+
+	dbTracer, dbCloser := initJaeger("postgres-reader_db", cfg.jaegerURL, logger)
+	defer dbCloser.Close()
+
 	db := connectToDB(cfg.dbConfig, logger)
 	defer db.Close()
 
-	repo := newService(db, logger)
+	repo := newService(db, dbTracer, logger)
 
 	errs := make(chan error, 2)
 
-	go startHTTPServer(repo, tc, cfg.port, logger, errs)
+	go startHTTPServer(tracer, repo, tc, admin, cfg, logger, errs)
 
 	go func() {
 		c := make(chan os.Signal)
@@ -138,6 +165,11 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
 	}
 
+	usersAuthTimeout, err := time.ParseDuration(mainflux.Env(envAuthTimeout, defAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
+	}
+
 	return config{
 		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
 		port:              mainflux.Env(envPort, defPort),
@@ -147,6 +179,9 @@ func loadConfig() config {
 		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
 		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
 		thingsAuthTimeout: authTimeout,
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       usersAuthTimeout,
+		adminEmail:        mainflux.Env(envAdminEmail, defAdminEmail),
 	}
 }
 
@@ -183,7 +218,23 @@ func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer,
 	return tracer, closer
 }
 
-func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
+// createAdmin returns the reader admin identified by the auth service, along
+// with the auth service gRPC connection. The connection is nil if the admin
+// email isn't set, which disables the filtering by tenant.
+func createAdmin(cfg config, tracer opentracing.Tracer, logger logger.Logger) (api.Admin, *grpc.ClientConn) {
+	if cfg.adminEmail == "" {
+		return api.Admin{}, nil
+	}
+
+	conn := connectToGRPC(cfg.authURL, "auth", cfg, logger)
+	admin := api.Admin{
+		Auth:  authapi.NewClient(tracer, conn, cfg.authTimeout),
+		Email: cfg.adminEmail,
+	}
+	return admin, conn
+}
+
+func connectToGRPC(url, svc string, cfg config, logger logger.Logger) *grpc.ClientConn {
 	var opts []grpc.DialOption
 	if cfg.clientTLS {
 		if cfg.caCerts != "" {
@@ -199,16 +250,16 @@ func connectToThings(cfg config, logger logger.Logger) *grpc.ClientConn {
 		opts = append(opts, grpc.WithInsecure())
 	}
 
-	conn, err := grpc.Dial(cfg.thingsAuthURL, opts...)
+	conn, err := grpc.Dial(url, opts...)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to connect to things service: %s", err))
+		logger.Error(fmt.Sprintf("Failed to connect to %s service: %s", svc, err))
 		os.Exit(1)
 	}
 	return conn
 }
 
-func newService(db *sqlx.DB, logger logger.Logger) readers.MessageRepository {
-	svc := postgres.New(db)
+func newService(db *sqlx.DB, tracer opentracing.Tracer, logger logger.Logger) readers.MessageRepository {
+	svc := tracing.MessageRepositoryMiddleware(tracer, postgres.New(db))
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
@@ -229,8 +280,8 @@ func newService(db *sqlx.DB, logger logger.Logger) readers.MessageRepository {
 	return svc
 }
 
-func startHTTPServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient, port string, logger logger.Logger, errs chan error) {
-	p := fmt.Sprintf(":%s", port)
-	logger.Info(fmt.Sprintf("Postgres reader service started, exposed port %s", port))
-	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tc, svcName))
+func startHTTPServer(tracer opentracing.Tracer, repo readers.MessageRepository, tc mainflux.ThingsServiceClient, admin api.Admin, cfg config, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", cfg.port)
+	logger.Info(fmt.Sprintf("Postgres reader service started, exposed port %s", cfg.port))
+	errs <- http.ListenAndServe(p, api.MakeHandler(repo, tracer, tc, admin, svcName))
 }
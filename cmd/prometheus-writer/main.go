@@ -0,0 +1,281 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/writers/api"
+	"github.com/mainflux/mainflux/consumers/writers/prometheus"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/decompress"
+	"github.com/mainflux/mainflux/pkg/transformers/limits"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	broker "github.com/nats-io/nats.go"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	svcName = "prometheus-writer"
+
+	defNatsURL         = "nats://localhost:4222"
+	defLogLevel        = "error"
+	defPort            = "8907"
+	defConfigPath      = "/config.toml"
+	defContentType     = "application/senml+json"
+	defDecompressLimit = "10485760"
+	defRemoteURL       = "http://localhost:9090/api/v1/write"
+	defPrefix          = "mainflux_"
+	defBatchSize       = "500"
+	defFlushInterval   = "5s"
+	defUsername        = ""
+	defPassword        = ""
+	defBearerToken     = ""
+	defHeaders         = ""
+	defMaxRetries      = "5"
+	defRetryInterval   = "500ms"
+	defTimeout         = "10s"
+
+	envNatsURL         = "MF_NATS_URL"
+	envLogLevel        = "MF_PROMETHEUS_WRITER_LOG_LEVEL"
+	envPort            = "MF_PROMETHEUS_WRITER_PORT"
+	envConfigPath      = "MF_PROMETHEUS_WRITER_CONFIG_PATH"
+	envContentType     = "MF_PROMETHEUS_WRITER_CONTENT_TYPE"
+	envDecompressLimit = "MF_PROMETHEUS_WRITER_DECOMPRESS_LIMIT"
+	envRemoteURL       = "MF_PROMETHEUS_WRITER_REMOTE_URL"
+	envPrefix          = "MF_PROMETHEUS_WRITER_PREFIX"
+	envBatchSize       = "MF_PROMETHEUS_WRITER_BATCH_SIZE"
+	envFlushInterval   = "MF_PROMETHEUS_WRITER_FLUSH_INTERVAL"
+	envUsername        = "MF_PROMETHEUS_WRITER_USERNAME"
+	envPassword        = "MF_PROMETHEUS_WRITER_PASSWORD"
+	envBearerToken     = "MF_PROMETHEUS_WRITER_BEARER_TOKEN"
+	envHeaders         = "MF_PROMETHEUS_WRITER_HEADERS"
+	envMaxRetries      = "MF_PROMETHEUS_WRITER_MAX_RETRIES"
+	envRetryInterval   = "MF_PROMETHEUS_WRITER_RETRY_INTERVAL"
+	envTimeout         = "MF_PROMETHEUS_WRITER_TIMEOUT"
+)
+
+type config struct {
+	natsURL         string
+	logLevel        string
+	port            string
+	configPath      string
+	contentType     string
+	decompressLimit int64
+	timeout         time.Duration
+	remote          prometheus.Config
+}
+
+func main() {
+	cfg := loadConfig()
+
+	logger, err := logger.New(os.Stdout, cfg.logLevel)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	stdprometheus.MustRegister(mainflux.BuildInfo(svcName))
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	defer pubSub.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg.remote.HTTPClient = &http.Client{Timeout: cfg.timeout}
+	dropped := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "prometheus",
+		Subsystem: "message_writer",
+		Name:      "dropped_samples_count",
+		Help:      "Number of samples dropped before the remote write.",
+	}, []string{"reason"})
+	repo := prometheus.New(ctx, cfg.remote, dropped, logger)
+
+	counter, latency := makeMetrics()
+	repo = api.LoggingMiddleware(repo, logger)
+	repo = api.MetricsMiddleware(repo, counter, latency)
+	t := decompress.New(cfg.decompressLimit, makeLimits(senml.New(cfg.contentType), cfg, logger))
+	t, errsConn := makeErrorsMiddleware(t, cfg, logger)
+	if errsConn != nil {
+		defer errsConn.Close()
+	}
+
+	if err := consumers.Start(makeLatencySubscriber(pubSub), repo, t, cfg.configPath, logger); err != nil {
+		logger.Error(fmt.Sprintf("Failed to start Prometheus writer: %s", err))
+		os.Exit(1)
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT)
+		errs <- fmt.Errorf("%s", <-c)
+	}()
+
+	go startHTTPService(cfg.port, logger, errs)
+
+	err = <-errs
+	logger.Error(fmt.Sprintf("Prometheus writer service terminated: %s", err))
+}
+
+func loadConfig() config {
+	decompressLimit, err := strconv.ParseInt(mainflux.Env(envDecompressLimit, defDecompressLimit), 10, 64)
+	if err != nil {
+		log.Fatal(err)
+	}
+	batchSize, err := strconv.Atoi(mainflux.Env(envBatchSize, defBatchSize))
+	if err != nil {
+		log.Fatal(err)
+	}
+	flushInterval, err := time.ParseDuration(mainflux.Env(envFlushInterval, defFlushInterval))
+	if err != nil {
+		log.Fatal(err)
+	}
+	maxRetries, err := strconv.ParseUint(mainflux.Env(envMaxRetries, defMaxRetries), 10, 64)
+	if err != nil {
+		log.Fatal(err)
+	}
+	retryInterval, err := time.ParseDuration(mainflux.Env(envRetryInterval, defRetryInterval))
+	if err != nil {
+		log.Fatal(err)
+	}
+	timeout, err := time.ParseDuration(mainflux.Env(envTimeout, defTimeout))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return config{
+		natsURL:         mainflux.Env(envNatsURL, defNatsURL),
+		logLevel:        mainflux.Env(envLogLevel, defLogLevel),
+		port:            mainflux.Env(envPort, defPort),
+		configPath:      mainflux.Env(envConfigPath, defConfigPath),
+		contentType:     mainflux.Env(envContentType, defContentType),
+		decompressLimit: decompressLimit,
+		timeout:         timeout,
+		remote: prometheus.Config{
+			URL:           mainflux.Env(envRemoteURL, defRemoteURL),
+			Prefix:        mainflux.Env(envPrefix, defPrefix),
+			BatchSize:     batchSize,
+			FlushInterval: flushInterval,
+			Username:      mainflux.Env(envUsername, defUsername),
+			Password:      mainflux.Env(envPassword, defPassword),
+			BearerToken:   mainflux.Env(envBearerToken, defBearerToken),
+			Headers:       parseHeaders(mainflux.Env(envHeaders, defHeaders)),
+			MaxRetries:    maxRetries,
+			RetryInterval: retryInterval,
+		},
+	}
+}
+
+// parseHeaders parses the comma separated list of name:value headers.
+func parseHeaders(s string) map[string]string {
+	headers := make(map[string]string)
+	for _, h := range strings.Split(s, ",") {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			continue
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return headers
+}
+
+func makeMetrics() (*kitprometheus.Counter, *kitprometheus.Summary) {
+	counter := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "prometheus",
+		Subsystem: "message_writer",
+		Name:      "request_count",
+		Help:      "Number of consumed messages.",
+	}, []string{"method"})
+
+	latency := kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+		Namespace: "prometheus",
+		Subsystem: "message_writer",
+		Name:      "request_latency_microseconds",
+		Help:      "Total duration of consuming in microseconds.",
+	}, []string{"method"})
+
+	return counter, latency
+}
+
+func startHTTPService(port string, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", port)
+	logger.Info(fmt.Sprintf("Prometheus writer service started, exposed port %s", p))
+	errs <- http.ListenAndServe(p, api.MakeHandler(svcName))
+}
+
+// makeLimits rejects the messages exceeding the limits before they reach
+// the database.
+func makeLimits(t transformers.Transformer, cfg config, logger logger.Logger) transformers.Transformer {
+	lc, err := consumers.LoadLimitsConfig(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default message limits, failed to load them: %s", err))
+	}
+	return limits.New(lc, t)
+}
+
+// makeErrorsMiddleware returns the transformer reporting the transform errors
+// and the NATS connection the error events are published to, if any.
+func makeErrorsMiddleware(t transformers.Transformer, cfg config, logger logger.Logger) (transformers.Transformer, *broker.Conn) {
+	ec, err := consumers.LoadErrorsConfig(cfg.configPath)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Continue with default transform errors settings, failed to load them: %s", err))
+	}
+
+	var pub consumers.EventPublisher
+	var conn *broker.Conn
+	if ec.Subject != "" {
+		conn, err = broker.Connect(cfg.natsURL)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to connect to NATS for transform error events: %s", err))
+			os.Exit(1)
+		}
+		pub = conn
+	}
+
+	counter := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "prometheus",
+		Subsystem: "message_writer",
+		Name:      "transform_error_count",
+		Help:      "Number of messages failed to transform.",
+	}, []string{"transformer", "channel", "reason"})
+	return consumers.ErrorsMiddleware(t, "senml", counter, pub, ec, logger), conn
+}
+
+func makeLatencySubscriber(sub messaging.Subscriber) messaging.Subscriber {
+	hist := kitprometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+		Namespace: "prometheus",
+		Subsystem: "message_writer",
+		Name:      "write_latency_seconds",
+		Help:      "Time between the message creation by the adapter and its storage.",
+		Buckets:   latency.Buckets,
+	}, []string{"protocol"})
+	skewed := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "prometheus",
+		Subsystem: "message_writer",
+		Name:      "clock_skew_count",
+		Help:      "Number of messages created later than stored due to the clock skew.",
+	}, []string{"protocol"})
+	return latency.NewSubscriber(sub, hist, skewed)
+}
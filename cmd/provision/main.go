@@ -10,13 +10,17 @@ import (
 	"reflect"
 	"strconv"
 	"syscall"
+	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/errors"
 	mfSDK "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/mainflux/mainflux/provision"
 	"github.com/mainflux/mainflux/provision/api"
+	rediscache "github.com/mainflux/mainflux/provision/redis"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -35,11 +39,21 @@ const (
 	defMfWhiteListURL  = "http://localhost:8202/things/state"
 	defMfCertsURL      = "http://localhost:8204"
 	defProvisionCerts  = "false"
+	defCertsPartial    = "false"
 	defProvisionBS     = "true"
 	defBSAutoWhitelist = "true"
 	defBSContent       = ""
 	defCertsHoursValid = "2400h"
 	defCertsKeyBits    = "4096"
+	defIdempotencyTTL  = "24h"
+	defCacheURL        = "localhost:6379"
+	defCachePass       = ""
+	defCacheDB         = "0"
+	defBulkMaxSize     = "100"
+	defBulkWorkers     = "10"
+	defBulkTimeout     = "5m"
+	defRollbackRetries = "3"
+	defRollbackBackoff = "100ms"
 
 	envConfigFile       = "MF_PROVISION_CONFIG_FILE"
 	envLogLevel         = "MF_PROVISION_LOG_LEVEL"
@@ -54,6 +68,7 @@ const (
 	envMfAPIKey         = "MF_PROVISION_API_KEY"
 	envMfCertsURL       = "MF_PROVISION_CERTS_SVC_URL"
 	envProvisionCerts   = "MF_PROVISION_X509_PROVISIONING"
+	envCertsPartial     = "MF_PROVISION_X509_PARTIAL_SUCCESS"
 	envMfBSURL          = "MF_PROVISION_BS_SVC_URL"
 	envMfBSWhiteListURL = "MF_PROVISION_BS_SVC_WHITELIST_URL"
 	envProvisionBS      = "MF_PROVISION_BS_CONFIG_PROVISIONING"
@@ -61,6 +76,15 @@ const (
 	envBSContent        = "MF_PROVISION_BS_CONTENT"
 	envCertsHoursValid  = "MF_PROVISION_CERTS_HOURS_VALID"
 	envCertsKeyBits     = "MF_PROVISION_CERTS_RSA_BITS"
+	envIdempotencyTTL   = "MF_PROVISION_IDEMPOTENCY_TTL"
+	envCacheURL         = "MF_PROVISION_CACHE_URL"
+	envCachePass        = "MF_PROVISION_CACHE_PASS"
+	envCacheDB          = "MF_PROVISION_CACHE_DB"
+	envBulkMaxSize      = "MF_PROVISION_BULK_MAX_SIZE"
+	envBulkWorkers      = "MF_PROVISION_BULK_WORKERS"
+	envBulkTimeout      = "MF_PROVISION_BULK_TIMEOUT"
+	envRollbackRetries  = "MF_PROVISION_ROLLBACK_RETRIES"
+	envRollbackBackoff  = "MF_PROVISION_ROLLBACK_BACKOFF"
 )
 
 var (
@@ -72,6 +96,9 @@ var (
 	errFailGettingProvBS            = errors.New("failed to get BS url setting")
 	errFailSettingKeyBits           = errors.New("failed to set rsa number of bits")
 	errFailedToReadBootstrapContent = errors.New("failed to read bootstrap content from envs")
+	errFailGettingIdempotencyTTL    = errors.New("failed to get idempotency TTL setting")
+	errFailGettingBulkSettings      = errors.New("failed to get bulk provisioning setting")
+	errFailGettingRollbackSettings  = errors.New("failed to get rollback setting")
 )
 
 func main() {
@@ -83,6 +110,9 @@ func main() {
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
+
+	stdprometheus.MustRegister(mainflux.BuildInfo("provision"))
+
 	if cfgFromFile, err := loadConfigFromFile(cfg.File); err != nil {
 		logger.Warn(fmt.Sprintf("Continue with settings from env, failed to load from: %s: %s", cfg.File, err))
 	} else {
@@ -102,7 +132,10 @@ func main() {
 	}
 	SDK := mfSDK.NewSDK(SDKCfg)
 
-	svc := provision.New(cfg, SDK, logger)
+	cacheClient := connectToRedis(mainflux.Env(envCacheURL, defCacheURL), mainflux.Env(envCachePass, defCachePass), mainflux.Env(envCacheDB, defCacheDB), logger)
+	defer cacheClient.Close()
+
+	svc := provision.New(cfg, SDK, rediscache.NewResultCache(cacheClient, cfg.Idempotency.TTL), logger)
 	svc = api.NewLoggingMiddleware(svc, logger)
 
 	errs := make(chan error, 2)
@@ -131,6 +164,20 @@ func startHTTPServer(svc provision.Service, cfg provision.Config, logger logger.
 	errs <- http.ListenAndServe(p, api.MakeHandler(svc))
 }
 
+func connectToRedis(cacheURL, cachePass string, cacheDB string, logger logger.Logger) *redis.Client {
+	db, err := strconv.Atoi(cacheDB)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to cache: %s", err))
+		os.Exit(1)
+	}
+
+	return redis.NewClient(&redis.Options{
+		Addr:     cacheURL,
+		Password: cachePass,
+		DB:       db,
+	})
+}
+
 func loadConfigFromFile(file string) (provision.Config, error) {
 	_, err := os.Stat(file)
 	if os.IsNotExist(err) {
@@ -152,6 +199,10 @@ func loadConfig() (provision.Config, error) {
 	if err != nil {
 		return provision.Config{}, errors.Wrap(errFailGettingCertSettings, err)
 	}
+	certsPartial, err := strconv.ParseBool(mainflux.Env(envCertsPartial, defCertsPartial))
+	if err != nil {
+		return provision.Config{}, errors.Wrap(errFailGettingCertSettings, fmt.Errorf(" for %s", envCertsPartial))
+	}
 	provisionBS, err := strconv.ParseBool(mainflux.Env(envProvisionBS, defProvisionBS))
 	if err != nil {
 		return provision.Config{}, errors.Wrap(errFailGettingProvBS, fmt.Errorf(" for %s", envProvisionBS))
@@ -169,6 +220,33 @@ func loadConfig() (provision.Config, error) {
 		return provision.Config{}, errFailSettingKeyBits
 	}
 
+	idempotencyTTL, err := time.ParseDuration(mainflux.Env(envIdempotencyTTL, defIdempotencyTTL))
+	if err != nil {
+		return provision.Config{}, errors.Wrap(errFailGettingIdempotencyTTL, err)
+	}
+
+	bulkMaxSize, err := strconv.Atoi(mainflux.Env(envBulkMaxSize, defBulkMaxSize))
+	if err != nil {
+		return provision.Config{}, errors.Wrap(errFailGettingBulkSettings, fmt.Errorf(" for %s", envBulkMaxSize))
+	}
+	bulkWorkers, err := strconv.Atoi(mainflux.Env(envBulkWorkers, defBulkWorkers))
+	if err != nil {
+		return provision.Config{}, errors.Wrap(errFailGettingBulkSettings, fmt.Errorf(" for %s", envBulkWorkers))
+	}
+	bulkTimeout, err := time.ParseDuration(mainflux.Env(envBulkTimeout, defBulkTimeout))
+	if err != nil {
+		return provision.Config{}, errors.Wrap(errFailGettingBulkSettings, fmt.Errorf(" for %s", envBulkTimeout))
+	}
+
+	rollbackRetries, err := strconv.Atoi(mainflux.Env(envRollbackRetries, defRollbackRetries))
+	if err != nil {
+		return provision.Config{}, errors.Wrap(errFailGettingRollbackSettings, fmt.Errorf(" for %s", envRollbackRetries))
+	}
+	rollbackBackoff, err := time.ParseDuration(mainflux.Env(envRollbackBackoff, defRollbackBackoff))
+	if err != nil {
+		return provision.Config{}, errors.Wrap(errFailGettingRollbackSettings, fmt.Errorf(" for %s", envRollbackBackoff))
+	}
+
 	var content map[string]interface{}
 	if c := mainflux.Env(envBSContent, defBSContent); c != "" {
 		if err = json.Unmarshal([]byte(c), content); err != nil {
@@ -197,10 +275,23 @@ func loadConfig() (provision.Config, error) {
 			KeyBits:    keyBits,
 		},
 		Bootstrap: provision.Bootstrap{
-			X509Provision: provisionX509,
-			Provision:     provisionBS,
-			AutoWhiteList: autoWhiteList,
-			Content:       content,
+			X509Provision:      provisionX509,
+			X509PartialSuccess: certsPartial,
+			Provision:          provisionBS,
+			AutoWhiteList:      autoWhiteList,
+			Content:            content,
+		},
+		Idempotency: provision.Idempotency{
+			TTL: idempotencyTTL,
+		},
+		Bulk: provision.Bulk{
+			MaxSize: bulkMaxSize,
+			Workers: bulkWorkers,
+			Timeout: bulkTimeout,
+		},
+		Rollback: provision.Rollback{
+			Retries: rollbackRetries,
+			Backoff: rollbackBackoff,
 		},
 
 		// This is default conf for provision if there is no config file
@@ -248,6 +339,10 @@ func mergeConfigs(dst, src interface{}) interface{} {
 			if dField.Interface() == 0 {
 				dField.Set(reflect.ValueOf(sField.Interface()))
 			}
+		case reflect.Int64:
+			if dField.Int() == 0 {
+				dField.Set(sField)
+			}
 		case reflect.String:
 			if dField.Interface() == "" {
 				dField.Set(reflect.ValueOf(sField.Interface()))
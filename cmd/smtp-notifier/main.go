@@ -23,12 +23,14 @@ import (
 	"github.com/mainflux/mainflux/consumers/notifiers"
 	"github.com/mainflux/mainflux/consumers/notifiers/api"
 	"github.com/mainflux/mainflux/consumers/notifiers/postgres"
+	"github.com/mainflux/mainflux/consumers/notifiers/sms"
 	"github.com/mainflux/mainflux/consumers/notifiers/smtp"
 	"github.com/mainflux/mainflux/consumers/notifiers/tracing"
 	"github.com/mainflux/mainflux/internal/email"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/ulid"
+	thingsapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	opentracing "github.com/opentracing/opentracing-go"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	jconfig "github.com/uber/jaeger-client-go/config"
@@ -53,6 +55,8 @@ const (
 	defServerKey     = ""
 	defJaegerURL     = ""
 	defNatsURL       = "nats://localhost:4222"
+	defRate          = "0.1"
+	defBurst         = "5"
 
 	defEmailHost        = "localhost"
 	defEmailPort        = "25"
@@ -68,6 +72,15 @@ const (
 	defAuthURL     = "localhost:8181"
 	defAuthTimeout = "1s"
 
+	defThingsAuthURL     = "localhost:8183"
+	defThingsAuthTimeout = "1s"
+
+	defSMSURL     = ""
+	defSMSToken   = ""
+	defSMSFrom    = "Mainflux"
+	defSMSMaxLen  = "160"
+	defSMSTimeout = "5s"
+
 	envLogLevel      = "MF_SMTP_NOTIFIER_LOG_LEVEL"
 	envDBHost        = "MF_SMTP_NOTIFIER_DB_HOST"
 	envDBPort        = "MF_SMTP_NOTIFIER_DB_PORT"
@@ -84,6 +97,8 @@ const (
 	envServerKey     = "MF_SMTP_NOTIFIER_SERVER_KEY"
 	envJaegerURL     = "MF_JAEGER_URL"
 	envNatsURL       = "MF_NATS_URL"
+	envRate          = "MF_SMTP_NOTIFIER_RATE"
+	envBurst         = "MF_SMTP_NOTIFIER_BURST"
 
 	envEmailHost        = "MF_EMAIL_HOST"
 	envEmailPort        = "MF_EMAIL_PORT"
@@ -98,22 +113,44 @@ const (
 	envAuthCACerts = "MF_AUTH_CA_CERTS"
 	envAuthURL     = "MF_AUTH_GRPC_URL"
 	envAuthTimeout = "MF_AUTH_GRPC_TIMEOUT"
+
+	envThingsAuthURL     = "MF_THINGS_AUTH_GRPC_URL"
+	envThingsAuthTimeout = "MF_THINGS_AUTH_GRPC_TIMEOUT"
+
+	envSMSURL     = "MF_SMTP_NOTIFIER_SMS_URL"
+	envSMSToken   = "MF_SMTP_NOTIFIER_SMS_TOKEN"
+	envSMSFrom    = "MF_SMTP_NOTIFIER_SMS_FROM"
+	envSMSMaxLen  = "MF_SMTP_NOTIFIER_SMS_MAX_LEN"
+	envSMSTimeout = "MF_SMTP_NOTIFIER_SMS_TIMEOUT"
 )
 
 type config struct {
-	natsURL     string
-	configPath  string
-	logLevel    string
-	dbConfig    postgres.Config
-	emailConf   email.Config
-	httpPort    string
-	serverCert  string
-	serverKey   string
-	jaegerURL   string
-	authTLS     bool
-	authCACerts string
-	authURL     string
-	authTimeout time.Duration
+	natsURL           string
+	configPath        string
+	logLevel          string
+	dbConfig          postgres.Config
+	emailConf         email.Config
+	smsConf           smsConfig
+	rate              float64
+	burst             int
+	httpPort          string
+	serverCert        string
+	serverKey         string
+	jaegerURL         string
+	authTLS           bool
+	authCACerts       string
+	authURL           string
+	authTimeout       time.Duration
+	thingsAuthURL     string
+	thingsAuthTimeout time.Duration
+}
+
+type smsConfig struct {
+	url     string
+	token   string
+	from    string
+	maxLen  int
+	timeout time.Duration
 }
 
 func main() {
@@ -124,6 +161,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("notifier"))
+
 	db := connectToDB(cfg.dbConfig, logger)
 	defer db.Close()
 
@@ -142,13 +181,19 @@ func main() {
 		defer close()
 	}
 
+	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
+	defer thingsCloser.Close()
+
+	things, thingsClose := connectToThings(cfg, thingsTracer, logger)
+	defer thingsClose()
+
 	tracer, closer := initJaeger("smtp-notifier", cfg.jaegerURL, logger)
 	defer closer.Close()
 
 	dbTracer, dbCloser := initJaeger("smtp-notifier_db", cfg.jaegerURL, logger)
 	defer dbCloser.Close()
 
-	svc := newService(db, dbTracer, auth, cfg, logger)
+	svc := newService(db, dbTracer, auth, things, cfg, logger)
 	errs := make(chan error, 2)
 
 	if err = consumers.Start(pubSub, svc, nil, cfg.configPath, logger); err != nil {
@@ -178,6 +223,31 @@ func loadConfig() config {
 		log.Fatalf("Invalid value passed for %s\n", envAuthTLS)
 	}
 
+	thingsAuthTimeout, err := time.ParseDuration(mainflux.Env(envThingsAuthTimeout, defThingsAuthTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envThingsAuthTimeout, err.Error())
+	}
+
+	rate, err := strconv.ParseFloat(mainflux.Env(envRate, defRate), 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envRate, err.Error())
+	}
+
+	burst, err := strconv.Atoi(mainflux.Env(envBurst, defBurst))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envBurst, err.Error())
+	}
+
+	smsMaxLen, err := strconv.Atoi(mainflux.Env(envSMSMaxLen, defSMSMaxLen))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSMSMaxLen, err.Error())
+	}
+
+	smsTimeout, err := time.ParseDuration(mainflux.Env(envSMSTimeout, defSMSTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envSMSTimeout, err.Error())
+	}
+
 	dbConfig := postgres.Config{
 		Host:        mainflux.Env(envDBHost, defDBHost),
 		Port:        mainflux.Env(envDBPort, defDBPort),
@@ -201,20 +271,33 @@ func loadConfig() config {
 		Template:    mainflux.Env(envEmailTemplate, defEmailTemplate),
 	}
 
+	smsConf := smsConfig{
+		url:     mainflux.Env(envSMSURL, defSMSURL),
+		token:   mainflux.Env(envSMSToken, defSMSToken),
+		from:    mainflux.Env(envSMSFrom, defSMSFrom),
+		maxLen:  smsMaxLen,
+		timeout: smsTimeout,
+	}
+
 	return config{
-		logLevel:    mainflux.Env(envLogLevel, defLogLevel),
-		natsURL:     mainflux.Env(envNatsURL, defNatsURL),
-		configPath:  mainflux.Env(envConfigPath, defConfigPath),
-		dbConfig:    dbConfig,
-		emailConf:   emailConf,
-		httpPort:    mainflux.Env(envHTTPPort, defHTTPPort),
-		serverCert:  mainflux.Env(envServerCert, defServerCert),
-		serverKey:   mainflux.Env(envServerKey, defServerKey),
-		jaegerURL:   mainflux.Env(envJaegerURL, defJaegerURL),
-		authTLS:     tls,
-		authCACerts: mainflux.Env(envAuthCACerts, defAuthCACerts),
-		authURL:     mainflux.Env(envAuthURL, defAuthURL),
-		authTimeout: authTimeout,
+		logLevel:          mainflux.Env(envLogLevel, defLogLevel),
+		natsURL:           mainflux.Env(envNatsURL, defNatsURL),
+		configPath:        mainflux.Env(envConfigPath, defConfigPath),
+		dbConfig:          dbConfig,
+		emailConf:         emailConf,
+		smsConf:           smsConf,
+		rate:              rate,
+		burst:             burst,
+		httpPort:          mainflux.Env(envHTTPPort, defHTTPPort),
+		serverCert:        mainflux.Env(envServerCert, defServerCert),
+		serverKey:         mainflux.Env(envServerKey, defServerKey),
+		jaegerURL:         mainflux.Env(envJaegerURL, defJaegerURL),
+		authTLS:           tls,
+		authCACerts:       mainflux.Env(envAuthCACerts, defAuthCACerts),
+		authURL:           mainflux.Env(envAuthURL, defAuthURL),
+		authTimeout:       authTimeout,
+		thingsAuthURL:     mainflux.Env(envThingsAuthURL, defThingsAuthURL),
+		thingsAuthTimeout: thingsAuthTimeout,
 	}
 
 }
@@ -253,6 +336,18 @@ func connectToDB(dbConfig postgres.Config, logger logger.Logger) *sqlx.DB {
 }
 
 func connectToAuth(cfg config, tracer opentracing.Tracer, logger logger.Logger) (mainflux.AuthServiceClient, func() error) {
+	conn := connectToGRPC(cfg, cfg.authURL, logger)
+	return authapi.NewClient(tracer, conn, cfg.authTimeout), conn.Close
+}
+
+func connectToThings(cfg config, tracer opentracing.Tracer, logger logger.Logger) (mainflux.ThingsServiceClient, func() error) {
+	conn := connectToGRPC(cfg, cfg.thingsAuthURL, logger)
+	return thingsapi.NewClient(conn, tracer, cfg.thingsAuthTimeout), conn.Close
+}
+
+// connectToGRPC connects to the gRPC service using the client TLS settings
+// shared by the auth and the things clients.
+func connectToGRPC(cfg config, url string, logger logger.Logger) *grpc.ClientConn {
 	var opts []grpc.DialOption
 	if cfg.authTLS {
 		if cfg.authCACerts != "" {
@@ -268,16 +363,15 @@ func connectToAuth(cfg config, tracer opentracing.Tracer, logger logger.Logger)
 		logger.Info("gRPC communication is not encrypted")
 	}
 
-	conn, err := grpc.Dial(cfg.authURL, opts...)
+	conn, err := grpc.Dial(url, opts...)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Failed to connect to auth service: %s", err))
+		logger.Error(fmt.Sprintf("Failed to connect to %s: %s", url, err))
 		os.Exit(1)
 	}
-
-	return authapi.NewClient(tracer, conn, cfg.authTimeout), conn.Close
+	return conn
 }
 
-func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServiceClient, c config, logger logger.Logger) notifiers.Service {
+func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServiceClient, things mainflux.ThingsServiceClient, c config, logger logger.Logger) notifiers.Service {
 	database := postgres.NewDatabase(db)
 	repo := tracing.New(postgres.New(database), tracer)
 	idp := ulid.New()
@@ -288,8 +382,8 @@ func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServic
 		os.Exit(1)
 	}
 
-	notifier := smtp.New(agent)
-	svc := notifiers.New(auth, repo, idp, notifier)
+	notifier := newNotifier(agent, c, logger)
+	svc := notifiers.New(auth, things, repo, idp, notifier)
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
@@ -309,6 +403,38 @@ func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServic
 	return svc
 }
 
+// newNotifier returns the notifier sending emails and, if the SMS gateway
+// is set, text messages, rate limited per contact.
+func newNotifier(agent *email.Agent, c config, logger logger.Logger) notifiers.Notifier {
+	deliveries := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "notifier",
+		Subsystem: "smtp",
+		Name:      "delivery_count",
+		Help:      "Number of sent and failed notifications.",
+	}, []string{"notifier", "status"})
+
+	ns := map[string]notifiers.Notifier{
+		notifiers.EmailContact: api.NotifierMetricsMiddleware(smtp.New(agent), "smtp", deliveries),
+	}
+	if c.smsConf.url != "" {
+		provider := sms.NewWebhook(c.smsConf.url, c.smsConf.token, c.smsConf.timeout)
+		ns[notifiers.PhoneContact] = api.NotifierMetricsMiddleware(sms.New(provider, c.smsConf.from, c.smsConf.maxLen), "sms", deliveries)
+		logger.Info("Sending SMS notifications using the SMS gateway")
+	}
+	notifier := notifiers.NewDispatcher(ns)
+
+	if c.rate <= 0 {
+		return notifier
+	}
+	dropped := kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+		Namespace: "notifier",
+		Subsystem: "smtp",
+		Name:      "rate_limited_count",
+		Help:      "Number of notifications not sent due to the contact rate limit.",
+	}, []string{})
+	return notifiers.NewRateLimiter(notifier, c.rate, c.burst, dropped)
+}
+
 func startHTTPServer(tracer opentracing.Tracer, svc notifiers.Service, port string, certFile string, keyFile string, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", port)
 	if certFile != "" || keyFile != "" {
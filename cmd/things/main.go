@@ -4,6 +4,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -26,13 +28,18 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
 	authapi "github.com/mainflux/mainflux/auth/api/grpc"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/internal/startup"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/idprovider"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/mainflux/mainflux/things"
 	"github.com/mainflux/mainflux/things/api"
 	authgrpcapi "github.com/mainflux/mainflux/things/api/auth/grpc"
 	authhttpapi "github.com/mainflux/mainflux/things/api/auth/http"
 	thhttpapi "github.com/mainflux/mainflux/things/api/things/http"
+	"github.com/mainflux/mainflux/things/authz"
 	"github.com/mainflux/mainflux/things/postgres"
 	rediscache "github.com/mainflux/mainflux/things/redis"
 	localusers "github.com/mainflux/mainflux/things/users"
@@ -43,6 +50,7 @@ import (
 
 const (
 	defLogLevel        = "error"
+	defLogLevelToken   = ""
 	defDBHost          = "localhost"
 	defDBPort          = "5432"
 	defDBUser          = "mainflux"
@@ -62,6 +70,7 @@ const (
 	defESDB            = "0"
 	defHTTPPort        = "8182"
 	defAuthHTTPPort    = "8989"
+	defAuthHTTPSecret  = ""
 	defAuthGRPCPort    = "8181"
 	defServerCert      = ""
 	defServerKey       = ""
@@ -70,8 +79,22 @@ const (
 	defJaegerURL       = ""
 	defAuthURL         = "localhost:8181"
 	defAuthTimeout     = "1s"
+	defIDProvider      = "uuid"
+	defNodeID          = "0"
+	defAuthzURL        = ""
+	defAuthzMode       = "deny-overrides"
+	defAuthzTimeout    = "100ms"
+	defAuthzCacheTTL   = "5s"
+	defAuthzFailOpen   = "false"
+	defAuthzThreshold  = "5"
+	defAuthzBreakerTTL = "30s"
+	defStartupTimeout  = "1m"
+	defStatsNatsURL    = ""
+	defStatsTTL        = "720h"
+	defTrustedProxies  = ""
 
 	envLogLevel        = "MF_THINGS_LOG_LEVEL"
+	envLogLevelToken   = "MF_THINGS_LOG_LEVEL_TOKEN"
 	envDBHost          = "MF_THINGS_DB_HOST"
 	envDBPort          = "MF_THINGS_DB_PORT"
 	envDBUser          = "MF_THINGS_DB_USER"
@@ -91,6 +114,7 @@ const (
 	envESDB            = "MF_THINGS_ES_DB"
 	envHTTPPort        = "MF_THINGS_HTTP_PORT"
 	envAuthHTTPPort    = "MF_THINGS_AUTH_HTTP_PORT"
+	envAuthHTTPSecret  = "MF_THINGS_AUTH_HTTP_SECRET"
 	envAuthGRPCPort    = "MF_THINGS_AUTH_GRPC_PORT"
 	envServerCert      = "MF_THINGS_SERVER_CERT"
 	envServerKey       = "MF_THINGS_SERVER_KEY"
@@ -99,10 +123,24 @@ const (
 	envJaegerURL       = "MF_JAEGER_URL"
 	envAuthURL         = "MF_AUTH_GRPC_URL"
 	envAuthTimeout     = "MF_AUTH_GRPC_TIMEOUT"
+	envIDProvider      = "MF_THINGS_ID_PROVIDER"
+	envNodeID          = "MF_THINGS_NODE_ID"
+	envAuthzURL        = "MF_THINGS_AUTHZ_URL"
+	envAuthzMode       = "MF_THINGS_AUTHZ_MODE"
+	envAuthzTimeout    = "MF_THINGS_AUTHZ_TIMEOUT"
+	envAuthzCacheTTL   = "MF_THINGS_AUTHZ_CACHE_TTL"
+	envAuthzFailOpen   = "MF_THINGS_AUTHZ_FAIL_OPEN"
+	envAuthzThreshold  = "MF_THINGS_AUTHZ_BREAKER_THRESHOLD"
+	envAuthzBreakerTTL = "MF_THINGS_AUTHZ_BREAKER_TIMEOUT"
+	envStartupTimeout  = "MF_THINGS_STARTUP_TIMEOUT"
+	envStatsNatsURL    = "MF_THINGS_STATS_NATS_URL"
+	envStatsTTL        = "MF_THINGS_STATS_TTL"
+	envTrustedProxies  = "MF_THINGS_TRUSTED_PROXIES"
 )
 
 type config struct {
 	logLevel        string
+	logLevelToken   string
 	dbConfig        postgres.Config
 	clientTLS       bool
 	caCerts         string
@@ -114,6 +152,7 @@ type config struct {
 	esDB            string
 	httpPort        string
 	authHTTPPort    string
+	authHTTPSecret  string
 	authGRPCPort    string
 	serverCert      string
 	serverKey       string
@@ -122,6 +161,13 @@ type config struct {
 	jaegerURL       string
 	authURL         string
 	authTimeout     time.Duration
+	idProvider      string
+	nodeID          int64
+	authzConfig     authz.Config
+	startupTimeout  time.Duration
+	statsNatsURL    string
+	statsTTL        time.Duration
+	trustedProxies  reqctx.Proxies
 }
 
 func main() {
@@ -132,35 +178,65 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("things"))
+
 	thingsTracer, thingsCloser := initJaeger("things", cfg.jaegerURL, logger)
 	defer thingsCloser.Close()
 
+	// The API is served behind the gate, so the health check reports the
+	// dependencies the service is waiting for.
+	gate := startup.NewGate(startup.Config{MaxWait: cfg.startupTimeout}, logger)
+	errs := make(chan error, 2)
+	go startHTTPServer(gate, cfg.httpPort, cfg, logger, errs)
+
 	cacheClient := connectToRedis(cfg.cacheURL, cfg.cachePass, cfg.cacheDB, logger)
 
 	esClient := connectToRedis(cfg.esURL, cfg.esPass, cfg.esDB, logger)
 
-	db := connectToDB(cfg.dbConfig, logger)
-	defer db.Close()
-
 	authTracer, authCloser := initJaeger("auth", cfg.jaegerURL, logger)
 	defer authCloser.Close()
 
-	auth, close := createAuthClient(cfg, authTracer, logger)
-	if close != nil {
-		defer close()
+	auth, authConn := createAuthClient(cfg, authTracer, logger)
+	checks := []startup.Check{
+		startup.Postgres(cfg.dbConfig.URL()),
+		startup.Redis("cache", cacheClient),
+		startup.Redis("es", esClient),
+	}
+	if authConn != nil {
+		defer authConn.Close()
+		checks = append(checks, startup.GRPC("auth", authConn))
+	}
+	if err := gate.WaitFor(context.Background(), checks...); err != nil {
+		logger.Error(fmt.Sprintf("Failed to start things service: %s", err))
+		os.Exit(1)
 	}
 
+	db := connectToDB(cfg.dbConfig, logger)
+	defer db.Close()
+
 	dbTracer, dbCloser := initJaeger("things_db", cfg.jaegerURL, logger)
 	defer dbCloser.Close()
 
 	cacheTracer, cacheCloser := initJaeger("things_cache", cfg.jaegerURL, logger)
 	defer cacheCloser.Close()
 
-	svc := newService(auth, dbTracer, cacheTracer, db, cacheClient, esClient, logger)
-	errs := make(chan error, 2)
+	idp, err := idprovider.New(cfg.idProvider, cfg.nodeID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create ID provider: %s", err))
+		os.Exit(1)
+	}
 
-	go startHTTPServer(thhttpapi.MakeHandler(thingsTracer, svc), cfg.httpPort, cfg, logger, errs)
-	go startHTTPServer(authhttpapi.MakeHandler(thingsTracer, svc), cfg.authHTTPPort, cfg, logger, errs)
+	statsRepo := rediscache.NewStatsRepository(cacheClient, cfg.statsTTL)
+	statsRepo = tracing.StatsRepositoryMiddleware(cacheTracer, statsRepo)
+	if cfg.statsNatsURL != "" {
+		pubSub := subscribeToStats(cfg.statsNatsURL, statsRepo, logger)
+		defer pubSub.Close()
+	}
+
+	svc := newService(auth, idp, dbTracer, cacheTracer, db, cacheClient, esClient, statsRepo, cfg.authzConfig, logger)
+	gate.Open(withLogLevel(thhttpapi.MakeHandler(thingsTracer, svc, cfg.trustedProxies), logger, cfg.logLevelToken))
+
+	go startHTTPServer(authhttpapi.MakeHandler(thingsTracer, svc, cfg.authHTTPSecret), cfg.authHTTPPort, cfg, logger, errs)
 	go startGRPCServer(svc, thingsTracer, cfg, logger, errs)
 
 	go func() {
@@ -184,6 +260,26 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
 	}
 
+	nodeID, err := strconv.ParseInt(mainflux.Env(envNodeID, defNodeID), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envNodeID, err.Error())
+	}
+
+	startupTimeout, err := time.ParseDuration(mainflux.Env(envStartupTimeout, defStartupTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envStartupTimeout, err.Error())
+	}
+
+	statsTTL, err := time.ParseDuration(mainflux.Env(envStatsTTL, defStatsTTL))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envStatsTTL, err.Error())
+	}
+
+	proxies, err := reqctx.ParseProxies(strings.Split(mainflux.Env(envTrustedProxies, defTrustedProxies), ","))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envTrustedProxies, err.Error())
+	}
+
 	dbConfig := postgres.Config{
 		Host:        mainflux.Env(envDBHost, defDBHost),
 		Port:        mainflux.Env(envDBPort, defDBPort),
@@ -198,6 +294,7 @@ func loadConfig() config {
 
 	return config{
 		logLevel:        mainflux.Env(envLogLevel, defLogLevel),
+		logLevelToken:   mainflux.Env(envLogLevelToken, defLogLevelToken),
 		dbConfig:        dbConfig,
 		clientTLS:       tls,
 		caCerts:         mainflux.Env(envCACerts, defCACerts),
@@ -209,6 +306,7 @@ func loadConfig() config {
 		esDB:            mainflux.Env(envESDB, defESDB),
 		httpPort:        mainflux.Env(envHTTPPort, defHTTPPort),
 		authHTTPPort:    mainflux.Env(envAuthHTTPPort, defAuthHTTPPort),
+		authHTTPSecret:  mainflux.Env(envAuthHTTPSecret, defAuthHTTPSecret),
 		authGRPCPort:    mainflux.Env(envAuthGRPCPort, defAuthGRPCPort),
 		serverCert:      mainflux.Env(envServerCert, defServerCert),
 		serverKey:       mainflux.Env(envServerKey, defServerKey),
@@ -217,6 +315,50 @@ func loadConfig() config {
 		jaegerURL:       mainflux.Env(envJaegerURL, defJaegerURL),
 		authURL:         mainflux.Env(envAuthURL, defAuthURL),
 		authTimeout:     authTimeout,
+		idProvider:      mainflux.Env(envIDProvider, defIDProvider),
+		nodeID:          nodeID,
+		authzConfig:     loadAuthzConfig(),
+		startupTimeout:  startupTimeout,
+		statsNatsURL:    mainflux.Env(envStatsNatsURL, defStatsNatsURL),
+		statsTTL:        statsTTL,
+		trustedProxies:  proxies,
+	}
+}
+
+func loadAuthzConfig() authz.Config {
+	timeout, err := time.ParseDuration(mainflux.Env(envAuthzTimeout, defAuthzTimeout))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthzTimeout, err.Error())
+	}
+
+	cacheTTL, err := time.ParseDuration(mainflux.Env(envAuthzCacheTTL, defAuthzCacheTTL))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthzCacheTTL, err.Error())
+	}
+
+	failOpen, err := strconv.ParseBool(mainflux.Env(envAuthzFailOpen, defAuthzFailOpen))
+	if err != nil {
+		log.Fatalf("Invalid value passed for %s\n", envAuthzFailOpen)
+	}
+
+	threshold, err := strconv.Atoi(mainflux.Env(envAuthzThreshold, defAuthzThreshold))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthzThreshold, err.Error())
+	}
+
+	breakerTimeout, err := time.ParseDuration(mainflux.Env(envAuthzBreakerTTL, defAuthzBreakerTTL))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envAuthzBreakerTTL, err.Error())
+	}
+
+	return authz.Config{
+		URL:              mainflux.Env(envAuthzURL, defAuthzURL),
+		Mode:             authz.Mode(mainflux.Env(envAuthzMode, defAuthzMode)),
+		Timeout:          timeout,
+		CacheTTL:         cacheTTL,
+		FailOpen:         failOpen,
+		BreakerThreshold: threshold,
+		BreakerTimeout:   breakerTimeout,
 	}
 }
 
@@ -267,13 +409,15 @@ func connectToDB(dbConfig postgres.Config, logger logger.Logger) *sqlx.DB {
 	return db
 }
 
-func createAuthClient(cfg config, tracer opentracing.Tracer, logger logger.Logger) (mainflux.AuthServiceClient, func() error) {
+// createAuthClient returns the auth service client, along with its gRPC
+// connection, which is nil in the single user mode.
+func createAuthClient(cfg config, tracer opentracing.Tracer, logger logger.Logger) (mainflux.AuthServiceClient, *grpc.ClientConn) {
 	if cfg.singleUserEmail != "" && cfg.singleUserToken != "" {
 		return localusers.NewSingleUserService(cfg.singleUserEmail, cfg.singleUserToken), nil
 	}
 
 	conn := connectToAuth(cfg, logger)
-	return authapi.NewClient(tracer, conn, cfg.authTimeout), conn.Close
+	return authapi.NewClient(tracer, conn, cfg.authTimeout), conn
 }
 
 func connectToAuth(cfg config, logger logger.Logger) *grpc.ClientConn {
@@ -301,7 +445,24 @@ func connectToAuth(cfg config, logger logger.Logger) *grpc.ClientConn {
 	return conn
 }
 
-func newService(auth mainflux.AuthServiceClient, dbTracer opentracing.Tracer, cacheTracer opentracing.Tracer, db *sqlx.DB, cacheClient *redis.Client, esClient *redis.Client, logger logger.Logger) things.Service {
+// subscribeToStats records the messages published by the adapters. The
+// subscription uses the queue, so the replicas of the service record each
+// message once.
+func subscribeToStats(url string, repo things.StatsRepository, logger logger.Logger) nats.PubSub {
+	pubSub, err := nats.NewPubSub(url, "things-stats", logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	if err := pubSub.Subscribe(nats.SubjectAllChannels, things.NewStatsHandler(repo)); err != nil {
+		logger.Error(fmt.Sprintf("Failed to subscribe to NATS: %s", err))
+		os.Exit(1)
+	}
+	logger.Info("Publishing statistics are recorded from NATS")
+	return pubSub
+}
+
+func newService(auth mainflux.AuthServiceClient, idProvider mainflux.IDProvider, dbTracer opentracing.Tracer, cacheTracer opentracing.Tracer, db *sqlx.DB, cacheClient *redis.Client, esClient *redis.Client, statsRepo things.StatsRepository, authzConfig authz.Config, logger logger.Logger) things.Service {
 	database := postgres.NewDatabase(db)
 
 	thingsRepo := postgres.NewThingRepository(database)
@@ -315,9 +476,17 @@ func newService(auth mainflux.AuthServiceClient, dbTracer opentracing.Tracer, ca
 
 	thingCache := rediscache.NewThingCache(cacheClient)
 	thingCache = tracing.ThingCacheMiddleware(cacheTracer, thingCache)
-	idProvider := uuid.New()
 
-	svc := things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, idProvider)
+	svc := things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, statsRepo, idProvider, uuid.New())
+	if authzConfig.URL != "" {
+		var err error
+		svc, err = authz.NewMiddleware(svc, authz.NewAuthorizer(authzConfig), authzConfig.Mode, authzConfig.FailOpen, logger)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to create external authorizer: %s", err))
+			os.Exit(1)
+		}
+		logger.Info(fmt.Sprintf("Channel access is authorized by %s in %s mode", authzConfig.URL, authzConfig.Mode))
+	}
 	svc = rediscache.NewEventStoreMiddleware(svc, esClient)
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
@@ -338,6 +507,19 @@ func newService(auth mainflux.AuthServiceClient, dbTracer opentracing.Tracer, ca
 	return svc
 }
 
+// withLogLevel mounts the log level endpoint next to the API handler. The
+// endpoint is served on the public port, so it's not mounted without token.
+func withLogLevel(handler http.Handler, l logger.Logger, token string) http.Handler {
+	lvl, ok := l.(logger.Leveler)
+	if !ok || token == "" {
+		return handler
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/loglevel", logger.LevelHandler(lvl, token))
+	mux.Handle("/", handler)
+	return mux
+}
+
 func startHTTPServer(handler http.Handler, port string, cfg config, logger logger.Logger, errs chan error) {
 	p := fmt.Sprintf(":%s", port)
 	if cfg.serverCert != "" || cfg.serverKey != "" {
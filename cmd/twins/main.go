@@ -20,9 +20,9 @@ import (
 	"github.com/mainflux/mainflux"
 	authapi "github.com/mainflux/mainflux/auth/api/grpc"
 	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/idprovider"
 	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/messaging/nats"
-	"github.com/mainflux/mainflux/pkg/uuid"
 	localusers "github.com/mainflux/mainflux/things/users"
 	"github.com/mainflux/mainflux/twins"
 	"github.com/mainflux/mainflux/twins/api"
@@ -61,6 +61,8 @@ const (
 	defNatsURL         = "nats://localhost:4222"
 	defAuthURL         = "localhost:8181"
 	defAuthTimeout     = "1s"
+	defIDProvider      = "uuid"
+	defNodeID          = "0"
 
 	envLogLevel        = "MF_TWINS_LOG_LEVEL"
 	envHTTPPort        = "MF_TWINS_HTTP_PORT"
@@ -81,6 +83,8 @@ const (
 	envNatsURL         = "MF_NATS_URL"
 	envAuthURL         = "MF_AUTH_GRPC_URL"
 	envAuthTimeout     = "MF_AUTH_GRPC_TIMEOUT"
+	envIDProvider      = "MF_TWINS_ID_PROVIDER"
+	envNodeID          = "MF_TWINS_NODE_ID"
 )
 
 type config struct {
@@ -102,6 +106,9 @@ type config struct {
 
 	authURL     string
 	authTimeout time.Duration
+
+	idProvider string
+	nodeID     int64
 }
 
 func main() {
@@ -112,6 +119,8 @@ func main() {
 		log.Fatalf(err.Error())
 	}
 
+	stdprometheus.MustRegister(mainflux.BuildInfo("twins"))
+
 	cacheClient := connectToRedis(cfg.cacheURL, cfg.cachePass, cfg.cacheDB, logger)
 	cacheTracer, cacheCloser := initJaeger("twins_cache", cfg.jaegerURL, logger)
 	defer cacheCloser.Close()
@@ -135,7 +144,13 @@ func main() {
 	}
 	defer pubSub.Close()
 
-	svc := newService(pubSub, cfg.channelID, auth, dbTracer, db, cacheTracer, cacheClient, logger)
+	idp, err := idprovider.New(cfg.idProvider, cfg.nodeID)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to create ID provider: %s", err))
+		os.Exit(1)
+	}
+
+	svc := newService(pubSub, cfg.channelID, auth, idp, dbTracer, db, cacheTracer, cacheClient, logger)
 
 	tracer, closer := initJaeger("twins", cfg.jaegerURL, logger)
 	defer closer.Close()
@@ -163,6 +178,11 @@ func loadConfig() config {
 		log.Fatalf("Invalid %s value: %s", envAuthTimeout, err.Error())
 	}
 
+	nodeID, err := strconv.ParseInt(mainflux.Env(envNodeID, defNodeID), 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envNodeID, err.Error())
+	}
+
 	dbCfg := twmongodb.Config{
 		Name: mainflux.Env(envDB, defDB),
 		Host: mainflux.Env(envDBHost, defDBHost),
@@ -187,6 +207,8 @@ func loadConfig() config {
 		natsURL:         mainflux.Env(envNatsURL, defNatsURL),
 		authURL:         mainflux.Env(envAuthURL, defAuthURL),
 		authTimeout:     authTimeout,
+		idProvider:      mainflux.Env(envIDProvider, defIDProvider),
+		nodeID:          nodeID,
 	}
 }
 
@@ -262,14 +284,13 @@ func connectToRedis(cacheURL, cachePass, cacheDB string, logger logger.Logger) *
 	})
 }
 
-func newService(ps messaging.PubSub, chanID string, users mainflux.AuthServiceClient, dbTracer opentracing.Tracer, db *mongo.Database, cacheTracer opentracing.Tracer, cacheClient *redis.Client, logger logger.Logger) twins.Service {
+func newService(ps messaging.PubSub, chanID string, users mainflux.AuthServiceClient, idProvider mainflux.IDProvider, dbTracer opentracing.Tracer, db *mongo.Database, cacheTracer opentracing.Tracer, cacheClient *redis.Client, logger logger.Logger) twins.Service {
 	twinRepo := twmongodb.NewTwinRepository(db)
 	twinRepo = tracing.TwinRepositoryMiddleware(dbTracer, twinRepo)
 
 	stateRepo := twmongodb.NewStateRepository(db)
 	stateRepo = tracing.StateRepositoryMiddleware(dbTracer, stateRepo)
 
-	idProvider := uuid.New()
 	twinCache := rediscache.NewTwinCache(cacheClient)
 	twinCache = tracing.TwinCacheMiddleware(cacheTracer, twinCache)
 
@@ -0,0 +1,245 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
+	"github.com/jmoiron/sqlx"
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/usage"
+	"github.com/mainflux/mainflux/consumers/usage/api"
+	"github.com/mainflux/mainflux/consumers/usage/postgres"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	opentracing "github.com/opentracing/opentracing-go"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	jconfig "github.com/uber/jaeger-client-go/config"
+)
+
+const (
+	defLogLevel      = "error"
+	defDBHost        = "localhost"
+	defDBPort        = "5432"
+	defDBUser        = "mainflux"
+	defDBPass        = "mainflux"
+	defDB            = "usage"
+	defConfigPath    = "/config.toml"
+	defDBSSLMode     = "disable"
+	defDBSSLCert     = ""
+	defDBSSLKey      = ""
+	defDBSSLRootCert = ""
+	defHTTPPort      = "8908"
+	defServerCert    = ""
+	defServerKey     = ""
+	defJaegerURL     = ""
+	defNatsURL       = "nats://localhost:4222"
+	defAdminKey      = ""
+	defBatchSize     = "1000"
+	defFlushInterval = "10s"
+
+	envLogLevel      = "MF_USAGE_LOG_LEVEL"
+	envDBHost        = "MF_USAGE_DB_HOST"
+	envDBPort        = "MF_USAGE_DB_PORT"
+	envDBUser        = "MF_USAGE_DB_USER"
+	envDBPass        = "MF_USAGE_DB_PASS"
+	envDB            = "MF_USAGE_DB"
+	envConfigPath    = "MF_USAGE_CONFIG_PATH"
+	envDBSSLMode     = "MF_USAGE_DB_SSL_MODE"
+	envDBSSLCert     = "MF_USAGE_DB_SSL_CERT"
+	envDBSSLKey      = "MF_USAGE_DB_SSL_KEY"
+	envDBSSLRootCert = "MF_USAGE_DB_SSL_ROOT_CERT"
+	envHTTPPort      = "MF_USAGE_PORT"
+	envServerCert    = "MF_USAGE_SERVER_CERT"
+	envServerKey     = "MF_USAGE_SERVER_KEY"
+	envJaegerURL     = "MF_JAEGER_URL"
+	envNatsURL       = "MF_NATS_URL"
+	envAdminKey      = "MF_USAGE_ADMIN_KEY"
+	envBatchSize     = "MF_USAGE_BATCH_SIZE"
+	envFlushInterval = "MF_USAGE_FLUSH_INTERVAL"
+)
+
+type config struct {
+	natsURL    string
+	configPath string
+	logLevel   string
+	dbConfig   postgres.Config
+	aggConfig  usage.AggregatorConfig
+	httpPort   string
+	serverCert string
+	serverKey  string
+	jaegerURL  string
+	adminKey   string
+}
+
+func main() {
+	cfg := loadConfig()
+
+	logger, err := logger.New(os.Stdout, cfg.logLevel)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+
+	stdprometheus.MustRegister(mainflux.BuildInfo("usage"))
+
+	if cfg.adminKey == "" {
+		logger.Error(fmt.Sprintf("Missing %s, usage report can't be accessed", envAdminKey))
+		os.Exit(1)
+	}
+
+	db := connectToDB(cfg.dbConfig, logger)
+	defer db.Close()
+
+	pubSub, err := nats.NewPubSub(cfg.natsURL, "", logger)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to NATS: %s", err))
+		os.Exit(1)
+	}
+	defer pubSub.Close()
+
+	tracer, closer := initJaeger("usage", cfg.jaegerURL, logger)
+	defer closer.Close()
+
+	repo := postgres.New(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	agg := usage.NewAggregator(ctx, cfg.aggConfig, repo, logger)
+	if err = consumers.Start(pubSub, agg, nil, cfg.configPath, logger); err != nil {
+		logger.Error(fmt.Sprintf("Failed to create usage aggregator: %s", err))
+		os.Exit(1)
+	}
+
+	svc := newService(cfg.adminKey, repo, logger)
+	errs := make(chan error, 2)
+
+	go startHTTPServer(tracer, svc, cfg.httpPort, cfg.serverCert, cfg.serverKey, logger, errs)
+
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		errs <- fmt.Errorf("%s", <-c)
+	}()
+
+	err = <-errs
+	logger.Error(fmt.Sprintf("Usage service terminated: %s", err))
+}
+
+func loadConfig() config {
+	batchSize, err := strconv.Atoi(mainflux.Env(envBatchSize, defBatchSize))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envBatchSize, err.Error())
+	}
+
+	flushInterval, err := time.ParseDuration(mainflux.Env(envFlushInterval, defFlushInterval))
+	if err != nil {
+		log.Fatalf("Invalid %s value: %s", envFlushInterval, err.Error())
+	}
+
+	dbConfig := postgres.Config{
+		Host:        mainflux.Env(envDBHost, defDBHost),
+		Port:        mainflux.Env(envDBPort, defDBPort),
+		User:        mainflux.Env(envDBUser, defDBUser),
+		Pass:        mainflux.Env(envDBPass, defDBPass),
+		Name:        mainflux.Env(envDB, defDB),
+		SSLMode:     mainflux.Env(envDBSSLMode, defDBSSLMode),
+		SSLCert:     mainflux.Env(envDBSSLCert, defDBSSLCert),
+		SSLKey:      mainflux.Env(envDBSSLKey, defDBSSLKey),
+		SSLRootCert: mainflux.Env(envDBSSLRootCert, defDBSSLRootCert),
+	}
+
+	return config{
+		logLevel:   mainflux.Env(envLogLevel, defLogLevel),
+		natsURL:    mainflux.Env(envNatsURL, defNatsURL),
+		configPath: mainflux.Env(envConfigPath, defConfigPath),
+		dbConfig:   dbConfig,
+		aggConfig: usage.AggregatorConfig{
+			BatchSize:     batchSize,
+			FlushInterval: flushInterval,
+		},
+		httpPort:   mainflux.Env(envHTTPPort, defHTTPPort),
+		serverCert: mainflux.Env(envServerCert, defServerCert),
+		serverKey:  mainflux.Env(envServerKey, defServerKey),
+		jaegerURL:  mainflux.Env(envJaegerURL, defJaegerURL),
+		adminKey:   mainflux.Env(envAdminKey, defAdminKey),
+	}
+}
+
+func initJaeger(svcName, url string, logger logger.Logger) (opentracing.Tracer, io.Closer) {
+	if url == "" {
+		return opentracing.NoopTracer{}, ioutil.NopCloser(nil)
+	}
+
+	tracer, closer, err := jconfig.Configuration{
+		ServiceName: svcName,
+		Sampler: &jconfig.SamplerConfig{
+			Type:  "const",
+			Param: 1,
+		},
+		Reporter: &jconfig.ReporterConfig{
+			LocalAgentHostPort: url,
+			LogSpans:           true,
+		},
+	}.NewTracer()
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to init Jaeger: %s", err))
+		os.Exit(1)
+	}
+
+	return tracer, closer
+}
+
+func connectToDB(dbConfig postgres.Config, logger logger.Logger) *sqlx.DB {
+	db, err := postgres.Connect(dbConfig)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to connect to postgres: %s", err))
+		os.Exit(1)
+	}
+	return db
+}
+
+func newService(adminKey string, repo usage.Repository, logger logger.Logger) usage.Service {
+	svc := usage.New(adminKey, repo)
+	svc = api.LoggingMiddleware(svc, logger)
+	svc = api.MetricsMiddleware(
+		svc,
+		kitprometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "usage",
+			Subsystem: "api",
+			Name:      "request_count",
+			Help:      "Number of requests received.",
+		}, []string{"method"}),
+		kitprometheus.NewSummaryFrom(stdprometheus.SummaryOpts{
+			Namespace: "usage",
+			Subsystem: "api",
+			Name:      "request_latency_microseconds",
+			Help:      "Total duration of requests in microseconds.",
+		}, []string{"method"}),
+	)
+	return svc
+}
+
+func startHTTPServer(tracer opentracing.Tracer, svc usage.Service, port string, certFile string, keyFile string, logger logger.Logger, errs chan error) {
+	p := fmt.Sprintf(":%s", port)
+	if certFile != "" || keyFile != "" {
+		logger.Info(fmt.Sprintf("Usage service started using https, cert %s key %s, exposed port %s", certFile, keyFile, port))
+		errs <- http.ListenAndServeTLS(p, certFile, keyFile, api.MakeHandler(svc, tracer))
+	} else {
+		logger.Info(fmt.Sprintf("Usage service started using http, exposed port %s", port))
+		errs <- http.ListenAndServe(p, api.MakeHandler(svc, tracer))
+	}
+}
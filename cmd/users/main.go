@@ -136,6 +136,9 @@ func main() {
 	if err != nil {
 		log.Fatalf(err.Error())
 	}
+
+	stdprometheus.MustRegister(mainflux.BuildInfo("users"))
+
 	db := connectToDB(cfg.dbConfig, logger)
 	defer db.Close()
 
@@ -288,6 +291,7 @@ func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServic
 	database := postgres.NewDatabase(db)
 	hasher := bcrypt.New()
 	userRepo := tracing.UserRepositoryMiddleware(postgres.NewUserRepo(database), tracer)
+	eventRepo := tracing.EventRepositoryMiddleware(postgres.NewEventRepository(database), tracer)
 
 	emailer, err := emailer.New(c.resetURL, &c.emailConf)
 	if err != nil {
@@ -296,7 +300,7 @@ func newService(db *sqlx.DB, tracer opentracing.Tracer, auth mainflux.AuthServic
 
 	idProvider := uuid.New()
 
-	svc := users.New(userRepo, hasher, auth, emailer, idProvider, c.passRegex)
+	svc := users.New(userRepo, eventRepo, hasher, auth, emailer, idProvider, c.passRegex, c.adminEmail)
 	svc = api.LoggingMiddleware(svc, logger)
 	svc = api.MetricsMiddleware(
 		svc,
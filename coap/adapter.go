@@ -10,13 +10,17 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
-	"github.com/gogo/protobuf/proto"
 	"github.com/mainflux/mainflux/pkg/errors"
-	broker "github.com/nats-io/nats.go"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/fanout"
+	"github.com/mainflux/mainflux/things/authz"
+	"github.com/plgd-dev/go-coap/v2/message/codes"
+	grpccodes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const chansPrefix = "channels"
@@ -24,9 +28,17 @@ const chansPrefix = "channels"
 // Exported errors
 var (
 	ErrUnauthorized = errors.New("unauthorized access")
+	ErrForbidden    = errors.New("forbidden access")
 	ErrUnsubscribe  = errors.New("unable to unsubscribe")
 )
 
+// Admin identifies the adapter admin, the only user allowed to manage the
+// connections. The empty email disables the connections management.
+type Admin struct {
+	Auth  mainflux.AuthServiceClient
+	Email string
+}
+
 // Service specifies CoAP service API.
 type Service interface {
 	// Publish Messssage
@@ -38,6 +50,15 @@ type Service interface {
 
 	// Unsubscribe method is used to stop observing resource.
 	Unsubscribe(ctx context.Context, key, chanID, subptopic, token string) error
+
+	// ListConnections retrieves the page of the observers' connections. Only
+	// the admin is allowed to list them.
+	ListConnections(ctx context.Context, token string, offset, limit uint64) (ConnectionsPage, error)
+
+	// CloseConnection force-closes the connection, sending the 4.03
+	// Forbidden notification to the client. Only the admin is allowed to
+	// close it.
+	CloseConnection(ctx context.Context, token, id string) error
 }
 
 var _ Service = (*adapterService)(nil)
@@ -45,16 +66,24 @@ var _ Service = (*adapterService)(nil)
 // Observers is a map of maps,
 type adapterService struct {
 	auth      mainflux.ThingsServiceClient
-	conn      *broker.Conn
+	pub       messaging.Publisher
+	sub       fanout.Subscriber
+	conns     Registry
+	admin     Admin
 	observers map[string]observers
 	obsLock   sync.Mutex
 }
 
-// New instantiates the CoAP adapter implementation.
-func New(auth mainflux.ThingsServiceClient, nc *broker.Conn) Service {
+// New instantiates the CoAP adapter implementation. Observers of the same
+// channel and subtopic share the subscription, and their connections are
+// kept in the registry until they terminate.
+func New(auth mainflux.ThingsServiceClient, pub messaging.Publisher, sub fanout.Subscriber, conns Registry, admin Admin) Service {
 	as := &adapterService{
 		auth:      auth,
-		conn:      nc,
+		pub:       pub,
+		sub:       sub,
+		conns:     conns,
+		admin:     admin,
 		observers: make(map[string]observers),
 		obsLock:   sync.Mutex{},
 	}
@@ -63,6 +92,7 @@ func New(auth mainflux.ThingsServiceClient, nc *broker.Conn) Service {
 }
 
 func (svc *adapterService) Publish(ctx context.Context, key string, msg messaging.Message) error {
+	ctx = authz.WithAccess(ctx, authz.Access{Action: authz.Publish, Subtopic: msg.Subtopic})
 	ar := &mainflux.AccessByKeyReq{
 		Token:  key,
 		ChanID: msg.Channel,
@@ -73,25 +103,17 @@ func (svc *adapterService) Publish(ctx context.Context, key string, msg messagin
 	}
 	msg.Publisher = thid.GetValue()
 
-	data, err := proto.Marshal(&msg)
-	if err != nil {
-		return err
-	}
-
-	subject := fmt.Sprintf("%s.%s", chansPrefix, msg.Channel)
-	if msg.Subtopic != "" {
-		subject = fmt.Sprintf("%s.%s", subject, msg.Subtopic)
-	}
-
-	return svc.conn.Publish(subject, data)
+	return svc.pub.Publish(msg.Channel, msg)
 }
 
 func (svc *adapterService) Subscribe(ctx context.Context, key, chanID, subtopic string, c Client) error {
+	ctx = authz.WithAccess(ctx, authz.Access{Action: authz.Subscribe, Subtopic: subtopic})
 	ar := &mainflux.AccessByKeyReq{
 		Token:  key,
 		ChanID: chanID,
 	}
-	if _, err := svc.auth.CanAccessByKey(ctx, ar); err != nil {
+	thid, err := svc.auth.CanAccessByKey(ctx, ar)
+	if err != nil {
 		return errors.Wrap(ErrUnauthorized, err)
 	}
 
@@ -100,12 +122,31 @@ func (svc *adapterService) Subscribe(ctx context.Context, key, chanID, subtopic
 		subject = fmt.Sprintf("%s.%s", subject, subtopic)
 	}
 
+	// The connection is registered before the client can terminate, so
+	// every termination unregisters it, including the abnormal ones.
+	id := observerID(c.Token())
+	conn := Connection{
+		ID:          id,
+		ThingID:     thid.GetValue(),
+		ChanID:      chanID,
+		Subtopic:    subtopic,
+		RemoteAddr:  c.RemoteAddr(),
+		ConnectedAt: time.Now(),
+	}
+	svc.conns.Register(conn, func() error {
+		if err := c.Terminate(codes.Forbidden); err != nil {
+			return err
+		}
+		return svc.remove(subject, c.Token())
+	})
+
 	go func() {
 		<-c.Done()
+		svc.conns.Unregister(id)
 		svc.remove(subject, c.Token())
 	}()
 
-	obs, err := NewObserver(subject, c, svc.conn)
+	obs, err := NewObserver(id, subject, countingClient{Client: c, id: id, conns: svc.conns}, svc.sub)
 	if err != nil {
 		c.Cancel()
 		return err
@@ -114,6 +155,7 @@ func (svc *adapterService) Subscribe(ctx context.Context, key, chanID, subtopic
 }
 
 func (svc *adapterService) Unsubscribe(ctx context.Context, key, chanID, subtopic, token string) error {
+	ctx = authz.WithAccess(ctx, authz.Access{Action: authz.Subscribe, Subtopic: subtopic})
 	ar := &mainflux.AccessByKeyReq{
 		Token:  key,
 		ChanID: chanID,
@@ -129,6 +171,43 @@ func (svc *adapterService) Unsubscribe(ctx context.Context, key, chanID, subtopi
 	return svc.remove(subject, token)
 }
 
+func (svc *adapterService) ListConnections(ctx context.Context, token string, offset, limit uint64) (ConnectionsPage, error) {
+	if err := svc.identifyAdmin(ctx, token); err != nil {
+		return ConnectionsPage{}, err
+	}
+
+	return svc.conns.List(offset, limit), nil
+}
+
+func (svc *adapterService) CloseConnection(ctx context.Context, token, id string) error {
+	if err := svc.identifyAdmin(ctx, token); err != nil {
+		return err
+	}
+
+	return svc.conns.Close(id)
+}
+
+// identifyAdmin checks if the token belongs to the admin user.
+func (svc *adapterService) identifyAdmin(ctx context.Context, token string) error {
+	if svc.admin.Auth == nil || svc.admin.Email == "" {
+		return ErrForbidden
+	}
+
+	id, err := svc.admin.Auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		e, ok := status.FromError(err)
+		if ok && (e.Code() == grpccodes.Unauthenticated || e.Code() == grpccodes.PermissionDenied) {
+			return errors.Wrap(ErrUnauthorized, err)
+		}
+		return err
+	}
+	if id.GetEmail() != svc.admin.Email {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
 func (svc *adapterService) put(endpoint, token string, o Observer) error {
 	svc.obsLock.Lock()
 	defer svc.obsLock.Unlock()
@@ -170,3 +249,18 @@ func (svc *adapterService) remove(endpoint, token string) error {
 	}
 	return nil
 }
+
+// countingClient counts the messages sent over the connection.
+type countingClient struct {
+	Client
+	id    string
+	conns Registry
+}
+
+func (c countingClient) SendMessage(msg messaging.Message) error {
+	if err := c.Client.SendMessage(msg); err != nil {
+		return err
+	}
+	c.conns.Count(c.id)
+	return nil
+}
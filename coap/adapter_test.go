@@ -0,0 +1,246 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package coap_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/coap"
+	"github.com/mainflux/mainflux/coap/mocks"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/fanout"
+	msgmocks "github.com/mainflux/mainflux/pkg/messaging/mocks"
+	"github.com/mainflux/mainflux/things/authz"
+	"github.com/plgd-dev/go-coap/v2/message/codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const (
+	adminToken = "admin-token"
+	adminEmail = "admin@example.com"
+	thingKey   = "thing-key"
+	thingID    = "thing"
+	chanID     = "1"
+	waitFor    = time.Second
+	tick       = 10 * time.Millisecond
+)
+
+// fakeClient is the client terminated by closing its done channel, like the
+// CoAP session closed by the server.
+type fakeClient struct {
+	token string
+	mu    sync.Mutex
+	code  codes.Code
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newClient(token string) *fakeClient {
+	return &fakeClient{token: token, done: make(chan struct{})}
+}
+
+func (c *fakeClient) Token() string {
+	return c.token
+}
+
+func (c *fakeClient) SendMessage(messaging.Message) error {
+	return nil
+}
+
+func (c *fakeClient) Terminate(code codes.Code) error {
+	c.mu.Lock()
+	c.code = code
+	c.mu.Unlock()
+	return c.Cancel()
+}
+
+func (c *fakeClient) Cancel() error {
+	c.once.Do(func() { close(c.done) })
+	return nil
+}
+
+func (c *fakeClient) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *fakeClient) RemoteAddr() string {
+	return "127.0.0.1:5683"
+}
+
+func (c *fakeClient) terminatedWith() codes.Code {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.code
+}
+
+func newService(t *testing.T) coap.Service {
+	log, err := logger.New(ioutil.Discard, "info")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	things := mocks.NewThingsService(map[string]string{thingKey: thingID})
+	auth := mocks.NewAuthService(map[string]string{adminToken: adminEmail})
+	ps := msgmocks.NewPubSub()
+	sub := fanout.New(ps, 10, fanout.Metrics{}, log)
+
+	return coap.New(things, ps, sub, coap.NewRegistry(), coap.Admin{Auth: auth, Email: adminEmail})
+}
+
+func TestSubscribeRegistersConnection(t *testing.T) {
+	svc := newService(t)
+
+	cases := []struct {
+		desc      string
+		terminate func(svc coap.Service, c *fakeClient, id string) error
+	}{
+		{
+			desc: "unsubscribe",
+			terminate: func(svc coap.Service, c *fakeClient, id string) error {
+				return svc.Unsubscribe(context.Background(), thingKey, chanID, "", c.Token())
+			},
+		},
+		{
+			desc: "abnormal termination",
+			terminate: func(svc coap.Service, c *fakeClient, id string) error {
+				return c.Cancel()
+			},
+		},
+		{
+			desc: "force close",
+			terminate: func(svc coap.Service, c *fakeClient, id string) error {
+				return svc.CloseConnection(context.Background(), adminToken, id)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		c := newClient(tc.desc)
+		err := svc.Subscribe(context.Background(), thingKey, chanID, "", c)
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+
+		page, err := svc.ListConnections(context.Background(), adminToken, 0, 10)
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+		require.Len(t, page.Connections, 1, fmt.Sprintf("%s: expected 1 connection got %d", tc.desc, len(page.Connections)))
+		conn := page.Connections[0]
+		assert.Equal(t, thingID, conn.ThingID, fmt.Sprintf("%s: expected thing ID %s got %s", tc.desc, thingID, conn.ThingID))
+		assert.Equal(t, c.RemoteAddr(), conn.RemoteAddr, fmt.Sprintf("%s: expected remote address %s got %s", tc.desc, c.RemoteAddr(), conn.RemoteAddr))
+
+		err = tc.terminate(svc, c, conn.ID)
+		assert.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+		assert.Eventually(t, func() bool {
+			page, err := svc.ListConnections(context.Background(), adminToken, 0, 10)
+			return err == nil && page.Total == 0
+		}, waitFor, tick, fmt.Sprintf("%s: expected connection unregistered", tc.desc))
+	}
+}
+
+func TestCloseConnectionCode(t *testing.T) {
+	svc := newService(t)
+	c := newClient("client")
+	err := svc.Subscribe(context.Background(), thingKey, chanID, "", c)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	page, err := svc.ListConnections(context.Background(), adminToken, 0, 10)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	require.Len(t, page.Connections, 1, fmt.Sprintf("expected 1 connection got %d", len(page.Connections)))
+
+	cases := []struct {
+		desc  string
+		token string
+		id    string
+		err   error
+	}{
+		{desc: "close connection with invalid token", token: "invalid", id: page.Connections[0].ID, err: coap.ErrUnauthorized},
+		{desc: "close unknown connection", token: adminToken, id: "unknown", err: coap.ErrConnectionNotFound},
+		{desc: "close connection", token: adminToken, id: page.Connections[0].ID, err: nil},
+	}
+
+	for _, tc := range cases {
+		err := svc.CloseConnection(context.Background(), tc.token, tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+	assert.Equal(t, codes.Forbidden, c.terminatedWith(), fmt.Sprintf("expected close code %s got %s", codes.Forbidden, c.terminatedWith()))
+}
+
+// accessClient records the channel access the things service is asked to
+// authorize.
+type accessClient struct {
+	mainflux.ThingsServiceClient
+
+	mu     sync.Mutex
+	access []authz.Access
+}
+
+func (ac *accessClient) CanAccessByKey(ctx context.Context, req *mainflux.AccessByKeyReq, opts ...grpc.CallOption) (*mainflux.ThingID, error) {
+	ac.mu.Lock()
+	ac.access = append(ac.access, authz.AccessFromContext(ctx))
+	ac.mu.Unlock()
+	return ac.ThingsServiceClient.CanAccessByKey(ctx, req, opts...)
+}
+
+func (ac *accessClient) last() authz.Access {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if len(ac.access) == 0 {
+		return authz.Access{}
+	}
+	return ac.access[len(ac.access)-1]
+}
+
+func TestAuthorizeAccess(t *testing.T) {
+	log, err := logger.New(ioutil.Discard, "info")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	things := &accessClient{ThingsServiceClient: mocks.NewThingsService(map[string]string{thingKey: thingID})}
+	auth := mocks.NewAuthService(map[string]string{adminToken: adminEmail})
+	ps := msgmocks.NewPubSub()
+	sub := fanout.New(ps, 10, fanout.Metrics{}, log)
+	svc := coap.New(things, ps, sub, coap.NewRegistry(), coap.Admin{Auth: auth, Email: adminEmail})
+	c := newClient("client")
+
+	cases := []struct {
+		desc      string
+		authorize func() error
+		access    authz.Access
+	}{
+		{
+			desc: "publish to subtopic",
+			authorize: func() error {
+				msg := messaging.Message{Channel: chanID, Subtopic: "data.temperature"}
+				return svc.Publish(context.Background(), thingKey, msg)
+			},
+			access: authz.Access{Action: authz.Publish, Subtopic: "data.temperature"},
+		},
+		{
+			desc: "subscribe to subtopic",
+			authorize: func() error {
+				return svc.Subscribe(context.Background(), thingKey, chanID, "data.humidity", c)
+			},
+			access: authz.Access{Action: authz.Subscribe, Subtopic: "data.humidity"},
+		},
+		{
+			desc: "unsubscribe from subtopic",
+			authorize: func() error {
+				return svc.Unsubscribe(context.Background(), thingKey, chanID, "data.humidity", c.Token())
+			},
+			access: authz.Access{Action: authz.Subscribe, Subtopic: "data.humidity"},
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.authorize()
+		assert.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+		access := things.last()
+		assert.Equal(t, tc.access, access, fmt.Sprintf("%s: expected access %v got %v", tc.desc, tc.access, access))
+	}
+}
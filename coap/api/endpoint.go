@@ -0,0 +1,60 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux/coap"
+)
+
+func listConnectionsEndpoint(svc coap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listConnectionsReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ListConnections(ctx, req.token, req.offset, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := connectionsPageRes{
+			Total:       page.Total,
+			Offset:      page.Offset,
+			Limit:       page.Limit,
+			Connections: []connectionRes{},
+		}
+		for _, conn := range page.Connections {
+			res.Connections = append(res.Connections, connectionRes{
+				ID:          conn.ID,
+				ThingID:     conn.ThingID,
+				ChanID:      conn.ChanID,
+				Subtopic:    conn.Subtopic,
+				RemoteAddr:  conn.RemoteAddr,
+				ConnectedAt: conn.ConnectedAt,
+				Messages:    conn.Messages,
+			})
+		}
+
+		return res, nil
+	}
+}
+
+func closeConnectionEndpoint(svc coap.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(closeConnectionReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if err := svc.CloseConnection(ctx, req.token, req.id); err != nil {
+			return nil, err
+		}
+
+		return closeConnectionRes{}, nil
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/coap"
+	"github.com/mainflux/mainflux/coap/api"
+	"github.com/mainflux/mainflux/coap/mocks"
+	"github.com/mainflux/mainflux/internal/topics"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/fanout"
+	msgmocks "github.com/mainflux/mainflux/pkg/messaging/mocks"
+	"github.com/plgd-dev/go-coap/v2/message"
+	"github.com/plgd-dev/go-coap/v2/message/codes"
+	coapnet "github.com/plgd-dev/go-coap/v2/net"
+	"github.com/plgd-dev/go-coap/v2/udp"
+	"github.com/plgd-dev/go-coap/v2/udp/client"
+	"github.com/plgd-dev/go-coap/v2/udp/message/pool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	adminToken = "admin-token"
+	userToken  = "user-token"
+	adminEmail = "admin@example.com"
+	thingKey   = "thing-key"
+	thingID    = "thing"
+	chanID     = "1"
+	waitFor    = 2 * time.Second
+	tick       = 10 * time.Millisecond
+)
+
+type observer struct {
+	conn  *client.ClientConn
+	codes chan codes.Code
+}
+
+func newService(t *testing.T) coap.Service {
+	log, err := logger.New(ioutil.Discard, "info")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	things := mocks.NewThingsService(map[string]string{thingKey: thingID})
+	auth := mocks.NewAuthService(map[string]string{adminToken: adminEmail, userToken: "user@example.com"})
+	ps := msgmocks.NewPubSub()
+	sub := fanout.New(ps, 10, fanout.Metrics{}, log)
+
+	return coap.New(things, ps, sub, coap.NewRegistry(), coap.Admin{Auth: auth, Email: adminEmail})
+}
+
+func startCoAP(t *testing.T, svc coap.Service) (string, func()) {
+	log, err := logger.New(ioutil.Discard, "info")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	tmpl, err := topics.New(topics.DefaultTemplate)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	l, err := coapnet.NewListenUDP("udp", "127.0.0.1:0")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	s := udp.NewServer(udp.WithMux(api.MakeCoAPHandler(svc, log, tmpl)))
+	go s.Serve(l)
+
+	return l.LocalAddr().String(), func() {
+		s.Stop()
+		l.Close()
+	}
+}
+
+func observe(t *testing.T, addr string) observer {
+	conn, err := udp.Dial(addr)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	o := observer{conn: conn, codes: make(chan codes.Code, 10)}
+	query := message.Option{ID: message.URIQuery, Value: []byte("auth=" + thingKey)}
+	ctx, cancel := context.WithTimeout(context.Background(), waitFor)
+	defer cancel()
+	_, err = conn.Observe(ctx, fmt.Sprintf("/channels/%s/messages", chanID), func(m *pool.Message) {
+		o.codes <- m.Code()
+	}, query)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	// The response to the observe request is the first notification.
+	<-o.codes
+
+	return o
+}
+
+func listConnections(t *testing.T, ts *httptest.Server, token, query string) (int, []map[string]interface{}) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/connections%s", ts.URL, query), nil)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	req.Header.Set("Authorization", token)
+
+	res, err := ts.Client().Do(req)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	defer res.Body.Close()
+
+	var page struct {
+		Total       uint64                   `json:"total"`
+		Connections []map[string]interface{} `json:"connections"`
+	}
+	if res.StatusCode == http.StatusOK {
+		err := json.NewDecoder(res.Body).Decode(&page)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+	return res.StatusCode, page.Connections
+}
+
+func closeConnection(t *testing.T, ts *httptest.Server, token, id string) int {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/connections/%s", ts.URL, id), nil)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	req.Header.Set("Authorization", token)
+
+	res, err := ts.Client().Do(req)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	res.Body.Close()
+
+	return res.StatusCode
+}
+
+func TestListConnections(t *testing.T) {
+	svc := newService(t)
+	addr, stop := startCoAP(t, svc)
+	defer stop()
+	ts := httptest.NewServer(api.MakeHTTPHandler(svc, nil))
+	defer ts.Close()
+
+	observers := []observer{observe(t, addr), observe(t, addr)}
+	for _, o := range observers {
+		defer o.conn.Close()
+	}
+
+	msg := messaging.Message{Channel: chanID, Payload: []byte("payload")}
+	err := svc.Publish(context.Background(), thingKey, msg)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	for _, o := range observers {
+		code := <-o.codes
+		assert.Equal(t, codes.Content, code, fmt.Sprintf("expected notification code %s got %s", codes.Content, code))
+	}
+
+	cases := []struct {
+		desc   string
+		token  string
+		query  string
+		status int
+		total  int
+	}{
+		{
+			desc:   "list connections",
+			token:  adminToken,
+			status: http.StatusOK,
+			total:  2,
+		},
+		{
+			desc:   "list connections page",
+			token:  adminToken,
+			query:  "?offset=1&limit=1",
+			status: http.StatusOK,
+			total:  1,
+		},
+		{
+			desc:   "list connections with limit over max",
+			token:  adminToken,
+			query:  "?limit=101",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "list connections with invalid offset",
+			token:  adminToken,
+			query:  "?offset=invalid",
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "list connections with non-admin token",
+			token:  userToken,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "list connections with invalid token",
+			token:  "invalid",
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "list connections without token",
+			token:  "",
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range cases {
+		status, conns := listConnections(t, ts, tc.token, tc.query)
+		assert.Equal(t, tc.status, status, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, status))
+		assert.Equal(t, tc.total, len(conns), fmt.Sprintf("%s: expected %d connections got %d", tc.desc, tc.total, len(conns)))
+	}
+
+	// The message is counted once it's sent, so the client may see it first.
+	var conns []map[string]interface{}
+	assert.Eventually(t, func() bool {
+		_, conns = listConnections(t, ts, adminToken, "")
+		for _, conn := range conns {
+			if conn["messages"] != float64(1) {
+				return false
+			}
+		}
+		return true
+	}, waitFor, tick, "expected 1 message sent over each connection")
+	for _, conn := range conns {
+		assert.Equal(t, thingID, conn["thing_id"], fmt.Sprintf("expected thing ID %s got %v", thingID, conn["thing_id"]))
+		assert.Equal(t, chanID, conn["channel_id"], fmt.Sprintf("expected channel ID %s got %v", chanID, conn["channel_id"]))
+		assert.NotEmpty(t, conn["remote_addr"], "expected remote address set")
+		assert.NotEmpty(t, conn["connected_at"], "expected connection time set")
+	}
+}
+
+func TestCloseConnection(t *testing.T) {
+	svc := newService(t)
+	addr, stop := startCoAP(t, svc)
+	defer stop()
+	ts := httptest.NewServer(api.MakeHTTPHandler(svc, nil))
+	defer ts.Close()
+
+	observers := []observer{observe(t, addr), observe(t, addr)}
+	for _, o := range observers {
+		defer o.conn.Close()
+	}
+
+	_, conns := listConnections(t, ts, adminToken, "")
+	require.Len(t, conns, 2, fmt.Sprintf("expected 2 connections got %d", len(conns)))
+	id := conns[0]["id"].(string)
+
+	cases := []struct {
+		desc   string
+		token  string
+		id     string
+		status int
+	}{
+		{
+			desc:   "close connection with non-admin token",
+			token:  userToken,
+			id:     id,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "close connection with invalid token",
+			token:  "invalid",
+			id:     id,
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "close unknown connection",
+			token:  adminToken,
+			id:     "unknown",
+			status: http.StatusNotFound,
+		},
+		{
+			desc:   "close connection",
+			token:  adminToken,
+			id:     id,
+			status: http.StatusNoContent,
+		},
+		{
+			desc:   "close closed connection",
+			token:  adminToken,
+			id:     id,
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		status := closeConnection(t, ts, tc.token, tc.id)
+		assert.Equal(t, tc.status, status, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, status))
+	}
+
+	// The connections are listed the oldest one first.
+	select {
+	case code := <-observers[0].codes:
+		assert.Equal(t, codes.Forbidden, code, fmt.Sprintf("expected close code %s got %s", codes.Forbidden, code))
+	case <-time.After(waitFor):
+		assert.Fail(t, "expected close code not received")
+	}
+
+	_, conns = listConnections(t, ts, adminToken, "")
+	require.Len(t, conns, 1, fmt.Sprintf("expected 1 connection got %d", len(conns)))
+	assert.NotEqual(t, id, conns[0]["id"], "expected closed connection removed")
+}
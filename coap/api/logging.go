@@ -69,3 +69,29 @@ func (lm *loggingMiddleware) Unsubscribe(ctx context.Context, key, chanID, subto
 
 	return lm.svc.Unsubscribe(ctx, key, chanID, subtopic, token)
 }
+
+func (lm *loggingMiddleware) ListConnections(ctx context.Context, token string, offset, limit uint64) (page coap.ConnectionsPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_connections took %s to complete", time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListConnections(ctx, token, offset, limit)
+}
+
+func (lm *loggingMiddleware) CloseConnection(ctx context.Context, token, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method close_connection for the connection %s took %s to complete", id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.CloseConnection(ctx, token, id)
+}
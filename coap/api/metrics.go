@@ -57,3 +57,21 @@ func (mm *metricsMiddleware) Unsubscribe(ctx context.Context, key, chanID, subto
 
 	return mm.svc.Unsubscribe(ctx, key, chanID, subtopic, token)
 }
+
+func (mm *metricsMiddleware) ListConnections(ctx context.Context, token string, offset, limit uint64) (coap.ConnectionsPage, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "list_connections").Add(1)
+		mm.latency.With("method", "list_connections").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.ListConnections(ctx, token, offset, limit)
+}
+
+func (mm *metricsMiddleware) CloseConnection(ctx context.Context, token, id string) error {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "close_connection").Add(1)
+		mm.latency.With("method", "close_connection").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.CloseConnection(ctx, token, id)
+}
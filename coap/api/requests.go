@@ -0,0 +1,46 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"github.com/mainflux/mainflux/coap"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const maxLimitSize = 100
+
+type listConnectionsReq struct {
+	token  string
+	offset uint64
+	limit  uint64
+}
+
+func (req listConnectionsReq) validate() error {
+	if req.token == "" {
+		return coap.ErrUnauthorized
+	}
+
+	if req.limit == 0 || req.limit > maxLimitSize {
+		return errors.ErrInvalidQueryParams
+	}
+
+	return nil
+}
+
+type closeConnectionReq struct {
+	token string
+	id    string
+}
+
+func (req closeConnectionReq) validate() error {
+	if req.token == "" {
+		return coap.ErrUnauthorized
+	}
+
+	if req.id == "" {
+		return errMalformedData
+	}
+
+	return nil
+}
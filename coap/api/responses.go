@@ -0,0 +1,52 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+type connectionRes struct {
+	ID          string    `json:"id"`
+	ThingID     string    `json:"thing_id"`
+	ChanID      string    `json:"channel_id"`
+	Subtopic    string    `json:"subtopic,omitempty"`
+	RemoteAddr  string    `json:"remote_addr"`
+	ConnectedAt time.Time `json:"connected_at"`
+	Messages    uint64    `json:"messages"`
+}
+
+type connectionsPageRes struct {
+	Total       uint64          `json:"total"`
+	Offset      uint64          `json:"offset"`
+	Limit       uint64          `json:"limit"`
+	Connections []connectionRes `json:"connections"`
+}
+
+func (res connectionsPageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res connectionsPageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res connectionsPageRes) Empty() bool {
+	return false
+}
+
+type closeConnectionRes struct{}
+
+func (res closeConnectionRes) Code() int {
+	return http.StatusNoContent
+}
+
+func (res closeConnectionRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res closeConnectionRes) Empty() bool {
+	return true
+}
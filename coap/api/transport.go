@@ -5,21 +5,25 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
-	"net/url"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/mainflux/mainflux/pkg/errors"
 
+	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/coap"
+	"github.com/mainflux/mainflux/internal/httputil"
+	"github.com/mainflux/mainflux/internal/topics"
 	log "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/quota"
 	"github.com/plgd-dev/go-coap/v2/message"
 	"github.com/plgd-dev/go-coap/v2/message/codes"
 	"github.com/plgd-dev/go-coap/v2/mux"
@@ -27,32 +31,125 @@ import (
 )
 
 const (
-	protocol  = "coap"
-	authQuery = "auth"
-)
+	protocol    = "coap"
+	authQuery   = "auth"
+	contentType = "application/json"
+	offsetKey   = "offset"
+	limitKey    = "limit"
+	defOffset   = 0
+	defLimit    = 10
 
-var channelPartRegExp = regexp.MustCompile(`^channels/([\w\-]+)/messages(/[^?]*)?(\?.*)?$`)
+	// tooManyRequests is the 4.29 response code defined by RFC 8516.
+	tooManyRequests codes.Code = 157
+)
 
-var errMalformedSubtopic = errors.New("malformed subtopic")
+var errMalformedData = errors.New("malformed request data")
 
 var (
-	logger  log.Logger
-	service coap.Service
+	logger   log.Logger
+	service  coap.Service
+	template topics.Template
 )
 
-//MakeHTTPHandler creates handler for version endpoint.
-func MakeHTTPHandler() http.Handler {
+// MakeHTTPHandler creates handler for version endpoint and the connections
+// management endpoints. If the health handler is set, it's served at the
+// /health path.
+func MakeHTTPHandler(svc coap.Service, health http.Handler) http.Handler {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(encodeError),
+	}
+
 	b := bone.New()
+	b.Get("/connections", kithttp.NewServer(
+		listConnectionsEndpoint(svc),
+		decodeListConnections,
+		encodeResponse,
+		opts...,
+	))
+	b.Delete("/connections/:id", kithttp.NewServer(
+		closeConnectionEndpoint(svc),
+		decodeCloseConnection,
+		encodeResponse,
+		opts...,
+	))
 	b.GetFunc("/version", mainflux.Version(protocol))
 	b.Handle("/metrics", promhttp.Handler())
+	if health != nil {
+		b.Get("/health", health)
+	}
 
 	return b
 }
 
-// MakeCoAPHandler creates handler for CoAP messages.
-func MakeCoAPHandler(svc coap.Service, l log.Logger) mux.HandlerFunc {
+func decodeListConnections(_ context.Context, r *http.Request) (interface{}, error) {
+	o, err := httputil.ReadUintQuery(r, offsetKey, defOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := httputil.ReadUintQuery(r, limitKey, defLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	req := listConnectionsReq{
+		token:  r.Header.Get("Authorization"),
+		offset: o,
+		limit:  l,
+	}
+
+	return req, nil
+}
+
+func decodeCloseConnection(_ context.Context, r *http.Request) (interface{}, error) {
+	req := closeConnectionReq{
+		token: r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+	}
+
+	return req, nil
+}
+
+func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	if ar, ok := response.(mainflux.Response); ok {
+		for k, v := range ar.Headers() {
+			w.Header().Set(k, v)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(ar.Code())
+
+		if ar.Empty() {
+			return nil
+		}
+	}
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+func encodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", contentType)
+
+	switch {
+	case errors.Contains(err, errors.ErrInvalidQueryParams),
+		errors.Contains(err, errMalformedData):
+		w.WriteHeader(http.StatusBadRequest)
+	case errors.Contains(err, coap.ErrUnauthorized):
+		w.WriteHeader(http.StatusUnauthorized)
+	case errors.Contains(err, coap.ErrForbidden):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.Contains(err, coap.ErrConnectionNotFound):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// MakeCoAPHandler creates handler for CoAP messages sent to the paths set by
+// the topic template.
+func MakeCoAPHandler(svc coap.Service, l log.Logger, tmpl topics.Template) mux.HandlerFunc {
 	logger = l
 	service = svc
+	template = tmpl
 
 	return handler
 }
@@ -110,6 +207,13 @@ func handler(w mux.ResponseWriter, m *mux.Message) {
 		return
 	}
 	if err != nil {
+		if reset, ok := quota.IsExceeded(err); ok {
+			// Max-Age tells the client how long to wait before retrying.
+			resp.Code = tooManyRequests
+			buf := make([]byte, 4)
+			resp.Options, _, _ = resp.Options.SetUint32(buf, message.MaxAge, uint32(math.Ceil(time.Until(reset).Seconds())))
+			return
+		}
 		switch {
 		case errors.Contains(err, coap.ErrUnauthorized):
 			resp.Code = codes.Unauthorized
@@ -125,18 +229,13 @@ func decodeMessage(msg *mux.Message) (messaging.Message, error) {
 	if err != nil {
 		return messaging.Message{}, err
 	}
-	channelParts := channelPartRegExp.FindStringSubmatch(path)
-	if len(channelParts) < 2 {
-		return messaging.Message{}, errMalformedSubtopic
-	}
-
-	st, err := parseSubtopic(channelParts[2])
+	chanID, st, err := template.Parse(path)
 	if err != nil {
 		return messaging.Message{}, err
 	}
 	ret := messaging.Message{
 		Protocol: protocol,
-		Channel:  parseID(path),
+		Channel:  chanID,
 		Subtopic: st,
 		Payload:  []byte{},
 		Created:  time.Now().UnixNano(),
@@ -152,14 +251,6 @@ func decodeMessage(msg *mux.Message) (messaging.Message, error) {
 	return ret, nil
 }
 
-func parseID(path string) string {
-	vars := strings.Split(path, "/")
-	if len(vars) > 1 {
-		return vars[1]
-	}
-	return ""
-}
-
 func parseKey(msg *mux.Message) (string, error) {
 	auth, err := msg.Options.GetString(message.URIQuery)
 	if err != nil {
@@ -171,32 +262,3 @@ func parseKey(msg *mux.Message) (string, error) {
 	}
 	return vars[1], nil
 }
-
-func parseSubtopic(subtopic string) (string, error) {
-	if subtopic == "" {
-		return subtopic, nil
-	}
-
-	subtopic, err := url.QueryUnescape(subtopic)
-	if err != nil {
-		return "", errMalformedSubtopic
-	}
-	subtopic = strings.ReplaceAll(subtopic, "/", ".")
-
-	elems := strings.Split(subtopic, ".")
-	filteredElems := []string{}
-	for _, elem := range elems {
-		if elem == "" {
-			continue
-		}
-
-		if len(elem) > 1 && (strings.Contains(elem, "*") || strings.Contains(elem, ">")) {
-			return "", errMalformedSubtopic
-		}
-
-		filteredElems = append(filteredElems, elem)
-	}
-
-	subtopic = strings.Join(filteredElems, ".")
-	return subtopic, nil
-}
@@ -20,8 +20,15 @@ type Client interface {
 	// In CoAP terminology, Token similar to the Session ID.
 	Token() string
 	SendMessage(m messaging.Message) error
+
+	// Terminate sends the notification with the error code, which ends the
+	// observation (RFC 7641), and cancels the client.
+	Terminate(code codes.Code) error
 	Cancel() error
 	Done() <-chan struct{}
+
+	// RemoteAddr returns the address of the client.
+	RemoteAddr() string
 }
 
 type observers map[string]Observer
@@ -52,6 +59,25 @@ func (c *client) Cancel() error {
 	return c.client.Close()
 }
 
+func (c *client) RemoteAddr() string {
+	if addr := c.client.RemoteAddr(); addr != nil {
+		return addr.String()
+	}
+	return ""
+}
+
+func (c *client) Terminate(code codes.Code) error {
+	m := message.Message{
+		Code:    code,
+		Token:   c.token,
+		Context: c.client.Context(),
+	}
+	if err := c.client.WriteMessage(&m); err != nil {
+		c.logger.Error(fmt.Sprintf("Error sending termination: %s.", err))
+	}
+	return c.Cancel()
+}
+
 func (c *client) Token() string {
 	return c.token.String()
 }
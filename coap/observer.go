@@ -4,9 +4,11 @@
 package coap
 
 import (
-	"github.com/gogo/protobuf/proto"
+	"fmt"
+	"sync/atomic"
+
 	"github.com/mainflux/mainflux/pkg/messaging"
-	broker "github.com/nats-io/nats.go"
+	"github.com/mainflux/mainflux/pkg/messaging/fanout"
 )
 
 // Observer represents an internal observer used to handle CoAP observe messages.
@@ -14,33 +16,44 @@ type Observer interface {
 	Cancel() error
 }
 
-// NewObserver returns a new Observer instance.
-func NewObserver(subject string, c Client, conn *broker.Conn) (Observer, error) {
-	sub, err := conn.Subscribe(subject, func(m *broker.Msg) {
-		var msg messaging.Message
-		if err := proto.Unmarshal(m.Data, &msg); err != nil {
-			return
-		}
+// observerSeq makes the observer IDs unique, so the observer replaced under
+// the same token detaches only itself.
+var observerSeq uint64
+
+// observerID returns the unique ID of the observer of the client token.
+func observerID(token string) string {
+	return fmt.Sprintf("%s-%d", token, atomic.AddUint64(&observerSeq, 1))
+}
+
+// NewObserver returns a new Observer instance attached to the subject under
+// the ID.
+func NewObserver(id, subject string, c Client, sub fanout.Subscriber) (Observer, error) {
+	err := sub.Attach(subject, id, func(msg messaging.Message) error {
 		// There is no error handling, but the client takes care to log the error.
 		c.SendMessage(msg)
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 	ret := &observer{
-		client: c,
-		sub:    sub,
+		id:      id,
+		subject: subject,
+		client:  c,
+		sub:     sub,
 	}
 	return ret, nil
 }
 
 type observer struct {
-	client Client
-	sub    *broker.Subscription
+	id      string
+	subject string
+	client  Client
+	sub     fanout.Subscriber
 }
 
 func (o *observer) Cancel() error {
-	if err := o.sub.Unsubscribe(); err != nil && err != broker.ErrConnectionClosed {
+	if err := o.sub.Detach(o.subject, o.id); err != nil && err != fanout.ErrNotAttached {
 		return err
 	}
 	return o.client.Cancel()
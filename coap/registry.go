@@ -0,0 +1,147 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package coap
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// ErrConnectionNotFound indicates the connection missing from the registry.
+var ErrConnectionNotFound = errors.New("connection not found")
+
+// Connection represents the observation of the client, which is the CoAP
+// counterpart of the long-lived subscription.
+type Connection struct {
+	ID          string
+	ThingID     string
+	ChanID      string
+	Subtopic    string
+	RemoteAddr  string
+	ConnectedAt time.Time
+
+	// Messages is the number of the messages sent to the client.
+	Messages uint64
+}
+
+// ConnectionsPage contains the page of the connections.
+type ConnectionsPage struct {
+	Total       uint64
+	Offset      uint64
+	Limit       uint64
+	Connections []Connection
+}
+
+// Registry keeps the connections of the adapter in memory.
+type Registry interface {
+	// Register adds the connection, which is closed by the close function
+	// once it's force-closed.
+	Register(conn Connection, close func() error)
+
+	// Unregister removes the connection. The unknown ID is ignored, so the
+	// connection can be unregistered on every termination.
+	Unregister(id string)
+
+	// Count records the message sent over the connection.
+	Count(id string)
+
+	// List retrieves the page of the connections, the oldest one first.
+	List(offset, limit uint64) ConnectionsPage
+
+	// Close closes and removes the connection.
+	Close(id string) error
+}
+
+var _ Registry = (*registry)(nil)
+
+type entry struct {
+	conn     Connection
+	close    func() error
+	messages uint64
+}
+
+type registry struct {
+	mu    sync.Mutex
+	conns map[string]*entry
+}
+
+// NewRegistry returns the empty connection registry.
+func NewRegistry() Registry {
+	return &registry{conns: make(map[string]*entry)}
+}
+
+func (r *registry) Register(conn Connection, close func() error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conns[conn.ID] = &entry{conn: conn, close: close}
+}
+
+func (r *registry) Unregister(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.conns, id)
+}
+
+func (r *registry) Count(id string) {
+	r.mu.Lock()
+	e, ok := r.conns[id]
+	r.mu.Unlock()
+
+	if ok {
+		atomic.AddUint64(&e.messages, 1)
+	}
+}
+
+func (r *registry) List(offset, limit uint64) ConnectionsPage {
+	r.mu.Lock()
+	conns := make([]Connection, 0, len(r.conns))
+	for _, e := range r.conns {
+		conn := e.conn
+		conn.Messages = atomic.LoadUint64(&e.messages)
+		conns = append(conns, conn)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(conns, func(i, j int) bool {
+		if conns[i].ConnectedAt.Equal(conns[j].ConnectedAt) {
+			return conns[i].ID < conns[j].ID
+		}
+		return conns[i].ConnectedAt.Before(conns[j].ConnectedAt)
+	})
+
+	page := ConnectionsPage{
+		Total:       uint64(len(conns)),
+		Offset:      offset,
+		Limit:       limit,
+		Connections: []Connection{},
+	}
+	if offset >= page.Total {
+		return page
+	}
+	end := offset + limit
+	if end > page.Total {
+		end = page.Total
+	}
+	page.Connections = conns[offset:end]
+
+	return page
+}
+
+func (r *registry) Close(id string) error {
+	r.mu.Lock()
+	e, ok := r.conns[id]
+	delete(r.conns, id)
+	r.mu.Unlock()
+
+	if !ok {
+		return ErrConnectionNotFound
+	}
+	return e.close()
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package coap_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/coap"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryList(t *testing.T) {
+	reg := coap.NewRegistry()
+	now := time.Now()
+	var conns []coap.Connection
+	for i := 0; i < 5; i++ {
+		conn := coap.Connection{
+			ID:          fmt.Sprintf("conn-%d", i),
+			ThingID:     "thing",
+			ChanID:      "channel",
+			ConnectedAt: now.Add(time.Duration(i) * time.Second),
+		}
+		reg.Register(conn, func() error { return nil })
+		conns = append(conns, conn)
+	}
+	reg.Count(conns[0].ID)
+	reg.Count(conns[0].ID)
+	reg.Count("unknown")
+	conns[0].Messages = 2
+
+	reg.Unregister(conns[4].ID)
+	reg.Unregister("unknown")
+
+	cases := []struct {
+		desc   string
+		offset uint64
+		limit  uint64
+		conns  []coap.Connection
+	}{
+		{
+			desc:   "list all connections",
+			offset: 0,
+			limit:  10,
+			conns:  conns[:4],
+		},
+		{
+			desc:   "list connections page",
+			offset: 1,
+			limit:  2,
+			conns:  conns[1:3],
+		},
+		{
+			desc:   "list connections with offset over total",
+			offset: 10,
+			limit:  10,
+			conns:  []coap.Connection{},
+		},
+	}
+
+	for _, tc := range cases {
+		page := reg.List(tc.offset, tc.limit)
+		assert.Equal(t, uint64(4), page.Total, fmt.Sprintf("%s: expected total 4 got %d", tc.desc, page.Total))
+		assert.Equal(t, tc.conns, page.Connections, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.conns, page.Connections))
+	}
+}
+
+func TestRegistryClose(t *testing.T) {
+	reg := coap.NewRegistry()
+	closed := 0
+	reg.Register(coap.Connection{ID: "conn"}, func() error {
+		closed++
+		return nil
+	})
+
+	cases := []struct {
+		desc string
+		id   string
+		err  error
+	}{
+		{desc: "close connection", id: "conn", err: nil},
+		{desc: "close closed connection", id: "conn", err: coap.ErrConnectionNotFound},
+		{desc: "close unknown connection", id: "unknown", err: coap.ErrConnectionNotFound},
+	}
+
+	for _, tc := range cases {
+		err := reg.Close(tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+	assert.Equal(t, 1, closed, fmt.Sprintf("expected connection closed once got %d", closed))
+	assert.Equal(t, uint64(0), reg.List(0, 10).Total, "expected closed connection removed")
+}
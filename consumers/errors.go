@@ -0,0 +1,194 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package consumers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/pelletier/go-toml"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// OtherChannels is the channel label of the channels exceeding the
+	// channels limit.
+	OtherChannels = "other"
+
+	// PayloadPrefix is the number of payload bytes sent in the error event.
+	PayloadPrefix = 256
+
+	defMaxChannels = 100
+	defRate        = 1
+	defBurst       = 5
+)
+
+// ErrorsConfig represents the transform errors reporting settings.
+type ErrorsConfig struct {
+	// Subject is the message broker subject the error events are published
+	// to. If it's empty, the events are not published.
+	Subject string `toml:"subject"`
+
+	// Rate is the number of error events per second published per channel.
+	Rate float64 `toml:"rate"`
+
+	// Burst is the maximum number of error events published at once per
+	// channel.
+	Burst int `toml:"burst"`
+
+	// MaxChannels limits the number of channels tracked separately, both in
+	// the counters and by the rate limiter. Other channels share the label
+	// and the rate limit.
+	MaxChannels int `toml:"max_channels"`
+}
+
+// ErrorEvent represents the failed message transformation.
+type ErrorEvent struct {
+	Channel     string `json:"channel"`
+	Subtopic    string `json:"subtopic,omitempty"`
+	Transformer string `json:"transformer"`
+	Reason      string `json:"reason"`
+	PayloadHash string `json:"payload_hash"`
+	Payload     string `json:"payload,omitempty"`
+	Created     int64  `json:"created"`
+}
+
+// EventPublisher publishes the data to the message broker subject.
+type EventPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+type errorsConfig struct {
+	Errors ErrorsConfig `toml:"errors"`
+}
+
+// LoadErrorsConfig loads the transform errors reporting settings from the
+// configuration file. Defaults are returned if the file can't be loaded.
+func LoadErrorsConfig(path string) (ErrorsConfig, error) {
+	cfg := errorsConfig{
+		Errors: ErrorsConfig{
+			Rate:        defRate,
+			Burst:       defBurst,
+			MaxChannels: defMaxChannels,
+		},
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg.Errors, errors.Wrap(errOpenConfFile, err)
+	}
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return cfg.Errors, errors.Wrap(errParseConfFile, err)
+	}
+
+	return cfg.Errors, nil
+}
+
+var _ transformers.Transformer = (*errorsMiddleware)(nil)
+
+type errorsMiddleware struct {
+	transformer transformers.Transformer
+	name        string
+	counter     metrics.Counter
+	pub         EventPublisher
+	cfg         ErrorsConfig
+	logger      logger.Logger
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// ErrorsMiddleware counts failed transformations by transformer name,
+// channel and reason, and publishes the error events using the publisher,
+// if the errors subject is set. Reason is the message of the outermost
+// transform error.
+func ErrorsMiddleware(t transformers.Transformer, name string, counter metrics.Counter, pub EventPublisher, cfg ErrorsConfig, logger logger.Logger) transformers.Transformer {
+	return &errorsMiddleware{
+		transformer: t,
+		name:        name,
+		counter:     counter,
+		pub:         pub,
+		cfg:         cfg,
+		logger:      logger,
+		limiters:    make(map[string]*rate.Limiter),
+	}
+}
+
+func (em *errorsMiddleware) Transform(msg messaging.Message) (interface{}, error) {
+	res, err := em.transformer.Transform(msg)
+	if err == nil {
+		return res, nil
+	}
+
+	reason := err.Error()
+	if e, ok := err.(errors.Error); ok {
+		reason = e.Msg()
+	}
+
+	limiter, label := em.limiter(msg.Channel)
+	em.counter.With("transformer", em.name, "channel", label, "reason", reason).Add(1)
+
+	if em.pub != nil && em.cfg.Subject != "" && limiter.Allow() {
+		em.publish(msg, reason)
+	}
+
+	return nil, err
+}
+
+// limiter returns the rate limiter and the metrics label of the channel.
+func (em *errorsMiddleware) limiter(channel string) (*rate.Limiter, string) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if l, ok := em.limiters[channel]; ok {
+		return l, channel
+	}
+	label := channel
+	if len(em.limiters) >= em.cfg.MaxChannels {
+		label = OtherChannels
+		if l, ok := em.limiters[label]; ok {
+			return l, label
+		}
+	}
+
+	l := rate.NewLimiter(rate.Limit(em.cfg.Rate), em.cfg.Burst)
+	em.limiters[label] = l
+	return l, label
+}
+
+func (em *errorsMiddleware) publish(msg messaging.Message, reason string) {
+	hash := sha256.Sum256(msg.Payload)
+	prefix := msg.Payload
+	if len(prefix) > PayloadPrefix {
+		prefix = prefix[:PayloadPrefix]
+	}
+
+	ev := ErrorEvent{
+		Channel:     msg.Channel,
+		Subtopic:    msg.Subtopic,
+		Transformer: em.name,
+		Reason:      reason,
+		PayloadHash: hex.EncodeToString(hash[:]),
+		Payload:     base64.StdEncoding.EncodeToString(prefix),
+		Created:     msg.Created,
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		em.logger.Warn(fmt.Sprintf("Failed to encode transform error event: %s", err))
+		return
+	}
+	if err := em.pub.Publish(em.cfg.Subject, data); err != nil {
+		em.logger.Warn(fmt.Sprintf("Failed to publish transform error event: %s", err))
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package consumers_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/consumers/schemas"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/decompress"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/limits"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const subject = "errors.transformers"
+
+// counter is the metrics counter storing values by joined label values.
+type counter struct {
+	mu     *sync.Mutex
+	labels []string
+	values map[string]float64
+}
+
+func newCounter() *counter {
+	return &counter{mu: &sync.Mutex{}, values: make(map[string]float64)}
+}
+
+func (c *counter) With(labelValues ...string) metrics.Counter {
+	return &counter{mu: c.mu, labels: labelValues, values: c.values}
+}
+
+func (c *counter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[strings.Join(c.labels, ",")] += delta
+}
+
+func (c *counter) value(transformer, channel, reason string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[fmt.Sprintf("transformer,%s,channel,%s,reason,%s", transformer, channel, reason)]
+}
+
+// publisher records the published error events.
+type publisher struct {
+	subjects []string
+	events   []consumers.ErrorEvent
+}
+
+func (p *publisher) Publish(subject string, data []byte) error {
+	var ev consumers.ErrorEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return err
+	}
+	p.subjects = append(p.subjects, subject)
+	p.events = append(p.events, ev)
+	return nil
+}
+
+func newLogger(t *testing.T) logger.Logger {
+	l, err := logger.New(ioutil.Discard, "error")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return l
+}
+
+func TestErrorsMiddleware(t *testing.T) {
+	cfg := consumers.ErrorsConfig{
+		Subject:     subject,
+		Rate:        0.001,
+		Burst:       2,
+		MaxChannels: 2,
+	}
+	c := newCounter()
+	pub := &publisher{}
+	tr := consumers.ErrorsMiddleware(senml.New(senml.JSON), "senml", c, pub, cfg, newLogger(t))
+
+	large := []byte(`[{"n":"` + strings.Repeat("a", 300) + `","v":1,"vs":"a"}]`)
+	cases := []struct {
+		desc    string
+		channel string
+		payload []byte
+		reason  string
+		event   bool
+	}{
+		{
+			desc:    "transform malformed payload",
+			channel: "ch1",
+			payload: []byte("{"),
+			reason:  "failed to decode senml",
+			event:   true,
+		},
+		{
+			desc:    "transform payload with too many values",
+			channel: "ch1",
+			payload: large,
+			reason:  "failed to normalize senml",
+			event:   true,
+		},
+		{
+			desc:    "transform malformed payload exceeding channel rate",
+			channel: "ch1",
+			payload: []byte("{"),
+			reason:  "failed to decode senml",
+			event:   false,
+		},
+		{
+			desc:    "transform valid payload",
+			channel: "ch1",
+			payload: []byte(`[{"n":"a","v":1}]`),
+		},
+		{
+			desc:    "transform malformed payload on other channel",
+			channel: "ch2",
+			payload: []byte("["),
+			reason:  "failed to decode senml",
+			event:   true,
+		},
+		{
+			desc:    "transform malformed payload on channel over the limit",
+			channel: "ch3",
+			payload: []byte("["),
+			reason:  "failed to decode senml",
+			event:   true,
+		},
+		{
+			desc:    "transform malformed payload on another channel over the limit",
+			channel: "ch4",
+			payload: []byte("["),
+			reason:  "failed to decode senml",
+			event:   true,
+		},
+		{
+			desc:    "transform malformed payload exceeding the rate of channels over the limit",
+			channel: "ch5",
+			payload: []byte("["),
+			reason:  "failed to decode senml",
+			event:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel:  tc.channel,
+			Subtopic: "subtopic",
+			Payload:  tc.payload,
+			Created:  1,
+		}
+		events := len(pub.events)
+		_, err := tr.Transform(msg)
+		if tc.reason == "" {
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			assert.Len(t, pub.events, events, fmt.Sprintf("%s: expected no error events", tc.desc))
+			continue
+		}
+		assert.NotNil(t, err, fmt.Sprintf("%s: expected error", tc.desc))
+		if !tc.event {
+			assert.Len(t, pub.events, events, fmt.Sprintf("%s: expected no error events", tc.desc))
+			continue
+		}
+
+		require.Len(t, pub.events, events+1, fmt.Sprintf("%s: expected error event", tc.desc))
+		hash := sha256.Sum256(tc.payload)
+		prefix := tc.payload
+		if len(prefix) > consumers.PayloadPrefix {
+			prefix = prefix[:consumers.PayloadPrefix]
+		}
+		expected := consumers.ErrorEvent{
+			Channel:     tc.channel,
+			Subtopic:    msg.Subtopic,
+			Transformer: "senml",
+			Reason:      tc.reason,
+			PayloadHash: hex.EncodeToString(hash[:]),
+			Payload:     base64.StdEncoding.EncodeToString(prefix),
+			Created:     msg.Created,
+		}
+		assert.Equal(t, expected, pub.events[events], fmt.Sprintf("%s: expected %v got %v", tc.desc, expected, pub.events[events]))
+		assert.Equal(t, subject, pub.subjects[events], fmt.Sprintf("%s: expected subject %s got %s", tc.desc, subject, pub.subjects[events]))
+	}
+
+	counters := []struct {
+		channel string
+		reason  string
+		value   float64
+	}{
+		{channel: "ch1", reason: "failed to decode senml", value: 2},
+		{channel: "ch1", reason: "failed to normalize senml", value: 1},
+		{channel: "ch2", reason: "failed to decode senml", value: 1},
+		{channel: "ch3", reason: "failed to decode senml", value: 0},
+		{channel: consumers.OtherChannels, reason: "failed to decode senml", value: 3},
+	}
+	for _, cnt := range counters {
+		v := c.value("senml", cnt.channel, cnt.reason)
+		assert.Equal(t, cnt.value, v, fmt.Sprintf("%s %s: expected count %f got %f", cnt.channel, cnt.reason, cnt.value, v))
+	}
+}
+
+func TestErrorsMiddlewareReasons(t *testing.T) {
+	c := newCounter()
+	cache := schemas.NewCache()
+	err := cache.Handle(map[string]interface{}{
+		"id":        "ch",
+		"operation": "channel.update",
+		"metadata":  `{"schema":{"type":"object"}}`,
+	})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	lc := limits.Config{MaxPayloadSize: 16, MaxFields: 1}
+	tr := decompress.New(16, limits.New(lc, mfjson.NewWithSchemas(mfjson.Config{}, nil, cache)))
+	tr = consumers.ErrorsMiddleware(tr, "json", c, nil, consumers.ErrorsConfig{MaxChannels: 10}, newLogger(t))
+
+	cases := []struct {
+		desc   string
+		msg    messaging.Message
+		reason string
+		err    error
+	}{
+		{
+			desc:   "transform payload with unsupported encoding",
+			msg:    messaging.Message{Channel: "ch", Subtopic: "s", Payload: []byte("{}"), Headers: map[string]string{messaging.ContentEncodingHeader: "br"}},
+			reason: "unsupported content encoding",
+			err:    decompress.ErrUnsupportedEncoding,
+		},
+		{
+			desc:   "transform corrupted payload",
+			msg:    messaging.Message{Channel: "ch", Subtopic: "s", Payload: []byte("{}"), Headers: map[string]string{messaging.ContentEncodingHeader: "gzip"}},
+			reason: "corrupted compressed payload",
+			err:    decompress.ErrCorrupted,
+		},
+		{
+			desc:   "transform malformed JSON payload",
+			msg:    messaging.Message{Channel: "ch", Subtopic: "s", Payload: []byte("{")},
+			reason: "unable to parse JSON object",
+			err:    mfjson.ErrTransform,
+		},
+		{
+			desc:   "transform payload violating channel schema",
+			msg:    messaging.Message{Channel: "ch", Subtopic: "s", Payload: []byte("[]")},
+			reason: "payload violates channel schema",
+			err:    mfjson.ErrSchemaViolation,
+		},
+		{
+			desc:   "transform payload exceeding the size limit",
+			msg:    messaging.Message{Channel: "ch", Subtopic: "s", Payload: []byte(`{"a":"0123456789"}`)},
+			reason: "payload too large",
+			err:    limits.ErrPayloadTooLarge,
+		},
+		{
+			desc:   "transform payload exceeding the fields limit",
+			msg:    messaging.Message{Channel: "ch", Subtopic: "s", Payload: []byte(`{"a":1,"b":2}`)},
+			reason: "too many fields",
+			err:    limits.ErrTooManyFields,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := tr.Transform(tc.msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		v := c.value("json", "ch", tc.reason)
+		assert.Equal(t, float64(1), v, fmt.Sprintf("%s: expected count 1 got %f", tc.desc, v))
+	}
+}
+
+func TestLoadErrorsConfig(t *testing.T) {
+	f, err := ioutil.TempFile("", "config-*.toml")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("[errors]\nsubject = \"errors.writer\"\nrate = 10.0\n")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	require.Nil(t, f.Close(), "closing file expected to succeed")
+
+	cases := []struct {
+		desc     string
+		path     string
+		expected consumers.ErrorsConfig
+		err      bool
+	}{
+		{
+			desc:     "load settings",
+			path:     f.Name(),
+			expected: consumers.ErrorsConfig{Subject: "errors.writer", Rate: 10, Burst: 5, MaxChannels: 100},
+		},
+		{
+			desc:     "load settings from missing file",
+			path:     f.Name() + ".missing",
+			expected: consumers.ErrorsConfig{Rate: 1, Burst: 5, MaxChannels: 100},
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		cfg, err := consumers.LoadErrorsConfig(tc.path)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.expected, cfg, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.expected, cfg))
+	}
+}
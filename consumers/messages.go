@@ -14,6 +14,9 @@ import (
 	"github.com/mainflux/mainflux/pkg/messaging"
 	pubsub "github.com/mainflux/mainflux/pkg/messaging/nats"
 	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/limits"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
 )
 
 var (
@@ -60,6 +63,69 @@ type subjectsConfig struct {
 	Subjects filterConfig `toml:"subjects"`
 }
 
+type transformerConfig struct {
+	Transformer json.Config `toml:"transformer"`
+}
+
+type limitsConfig struct {
+	Limits limits.Config `toml:"limits"`
+}
+
+type protobufConfig struct {
+	Transformer struct {
+		Protobuf protobuf.Config `toml:"protobuf"`
+	} `toml:"transformer"`
+}
+
+// LoadTransformerConfig loads the JSON transformer settings from the
+// configuration file.
+func LoadTransformerConfig(path string) (json.Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return json.Config{}, errors.Wrap(errOpenConfFile, err)
+	}
+
+	var cfg transformerConfig
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return json.Config{}, errors.Wrap(errParseConfFile, err)
+	}
+
+	return cfg.Transformer, nil
+}
+
+// LoadLimitsConfig loads the message limits from the configuration file.
+// Limits missing from the file, as well as all the limits if the file can't
+// be loaded, are set to defaults.
+func LoadLimitsConfig(path string) (limits.Config, error) {
+	cfg := limitsConfig{Limits: limits.Default()}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg.Limits, errors.Wrap(errOpenConfFile, err)
+	}
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return limits.Default(), errors.Wrap(errParseConfFile, err)
+	}
+
+	return cfg.Limits, nil
+}
+
+// LoadProtobufConfig loads the protobuf transformer message types from the
+// configuration file.
+func LoadProtobufConfig(path string) (protobuf.Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return protobuf.Config{}, errors.Wrap(errOpenConfFile, err)
+	}
+
+	var cfg protobufConfig
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return protobuf.Config{}, errors.Wrap(errParseConfFile, err)
+	}
+
+	return cfg.Transformer.Protobuf, nil
+}
+
 func loadSubjectsConfig(subjectsConfigPath string) ([]string, error) {
 	data, err := ioutil.ReadFile(subjectsConfigPath)
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-kit/kit/metrics"
 	notifiers "github.com/mainflux/mainflux/consumers/notifiers"
+	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
 var _ notifiers.Service = (*metricsMiddleware)(nil)
@@ -72,3 +73,31 @@ func (ms *metricsMiddleware) Consume(msg interface{}) error {
 
 	return ms.svc.Consume(msg)
 }
+
+var _ notifiers.Notifier = (*notifierMetricsMiddleware)(nil)
+
+type notifierMetricsMiddleware struct {
+	name       string
+	deliveries metrics.Counter
+	notifier   notifiers.Notifier
+}
+
+// NotifierMetricsMiddleware instruments the notifier by counting the sent
+// and the failed notifications, labeled by the notifier name and the status.
+func NotifierMetricsMiddleware(n notifiers.Notifier, name string, deliveries metrics.Counter) notifiers.Notifier {
+	return &notifierMetricsMiddleware{
+		name:       name,
+		deliveries: deliveries,
+		notifier:   n,
+	}
+}
+
+func (nm *notifierMetricsMiddleware) Notify(from string, to []string, msg messaging.Message) error {
+	status := "sent"
+	err := nm.notifier.Notify(from, to, msg)
+	if err != nil {
+		status = "failed"
+	}
+	nm.deliveries.With("notifier", nm.name, "status", status).Add(1)
+	return err
+}
@@ -140,6 +140,8 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		case errors.Contains(errorVal, errors.ErrMalformedEntity),
 			errors.Contains(errorVal, errInvalidContact),
 			errors.Contains(errorVal, errInvalidTopic),
+			errors.Contains(errorVal, notifiers.ErrInvalidContact),
+			errors.Contains(errorVal, notifiers.ErrInvalidTopic),
 			errors.Contains(errorVal, errors.ErrInvalidQueryParams):
 			w.WriteHeader(http.StatusBadRequest)
 		case errors.Contains(errorVal, notifiers.ErrNotFound),
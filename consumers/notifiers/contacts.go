@@ -0,0 +1,31 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package notifiers
+
+import (
+	"net/mail"
+	"regexp"
+)
+
+// Types of the subscription contacts.
+const (
+	// EmailContact is the email address contact.
+	EmailContact = "email"
+	// PhoneContact is the phone number contact in E.164 format.
+	PhoneContact = "phone"
+)
+
+var phoneRegexp = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// ContactType returns the type of the contact, or an empty string if the
+// contact is neither an email address nor a phone number.
+func ContactType(contact string) string {
+	if phoneRegexp.MatchString(contact) {
+		return PhoneContact
+	}
+	if addr, err := mail.ParseAddress(contact); err == nil && addr.Address == contact {
+		return EmailContact
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package notifiers
+
+import (
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+var errUnsupportedContact = errors.New("no notifier for the contact type")
+
+var _ Notifier = (*dispatcher)(nil)
+
+type dispatcher struct {
+	notifiers map[string]Notifier
+}
+
+// NewDispatcher returns the notifier sending the notification to every
+// receiver using the notifier of its contact type, e.g. EmailContact or
+// PhoneContact. The other receivers are notified even if some of them fail,
+// and the first error is returned.
+func NewDispatcher(notifiers map[string]Notifier) Notifier {
+	return dispatcher{notifiers: notifiers}
+}
+
+func (d dispatcher) Notify(from string, to []string, msg messaging.Message) error {
+	var types []string
+	receivers := make(map[string][]string)
+	for _, t := range to {
+		typ := ContactType(t)
+		if _, ok := receivers[typ]; !ok {
+			types = append(types, typ)
+		}
+		receivers[typ] = append(receivers[typ], t)
+	}
+
+	var err error
+	for _, typ := range types {
+		n, ok := d.notifiers[typ]
+		if !ok {
+			if err == nil {
+				err = errors.Wrap(errUnsupportedContact, errors.New(typ))
+			}
+			continue
+		}
+		if e := n.Notify(from, receivers[typ], msg); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
@@ -6,6 +6,7 @@ package mocks
 import (
 	"context"
 	"sort"
+	"strings"
 	"sync"
 
 	notifiers "github.com/mainflux/mainflux/consumers/notifiers"
@@ -67,6 +68,9 @@ func (srm *subRepoMock) RetrieveAll(_ context.Context, pm notifiers.PageMetadata
 	offset := int(pm.Offset)
 	for _, k := range keys {
 		v := srm.subs[k]
+		if pm.Channel != "" && strings.SplitN(v.Topic, ".", 2)[0] != pm.Channel {
+			continue
+		}
 		if pm.Topic == "" {
 			if pm.Contact == "" {
 				if total < offset {
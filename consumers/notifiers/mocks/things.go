@@ -0,0 +1,47 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/mainflux/mainflux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errNotOwner = status.Error(codes.PermissionDenied, "channel not owned by the user")
+
+var _ mainflux.ThingsServiceClient = (*thingsServiceMock)(nil)
+
+type thingsServiceMock struct {
+	owners map[string]string
+}
+
+// NewThings creates mock of things service, using the channel owners
+// emails by channel ID.
+func NewThings(owners map[string]string) mainflux.ThingsServiceClient {
+	return thingsServiceMock{owners: owners}
+}
+
+func (svc thingsServiceMock) IsChannelOwner(_ context.Context, req *mainflux.ChannelOwnerReq, _ ...grpc.CallOption) (*empty.Empty, error) {
+	if owner, ok := svc.owners[req.GetChanID()]; ok && owner == req.GetOwner() {
+		return &empty.Empty{}, nil
+	}
+	return nil, errNotOwner
+}
+
+func (svc thingsServiceMock) CanAccessByKey(context.Context, *mainflux.AccessByKeyReq, ...grpc.CallOption) (*mainflux.ThingID, error) {
+	panic("not implemented")
+}
+
+func (svc thingsServiceMock) CanAccessByID(context.Context, *mainflux.AccessByIDReq, ...grpc.CallOption) (*empty.Empty, error) {
+	panic("not implemented")
+}
+
+func (svc thingsServiceMock) Identify(context.Context, *mainflux.Token, ...grpc.CallOption) (*mainflux.ThingID, error) {
+	panic("not implemented")
+}
@@ -73,12 +73,16 @@ func (repo subscriptionsRepo) RetrieveAll(ctx context.Context, pm notifiers.Page
 	if pm.Contact != "" {
 		args["contact"] = pm.Contact
 	}
+	var cond []string
+	for k := range args {
+		cond = append(cond, fmt.Sprintf("%s = :%s", k, k))
+	}
+	if pm.Channel != "" {
+		args["channel"] = pm.Channel
+		cond = append(cond, "split_part(topic, '.', 1) = :channel")
+	}
 	var condition string
-	if len(args) > 0 {
-		var cond []string
-		for k := range args {
-			cond = append(cond, fmt.Sprintf("%s = :%s", k, k))
-		}
+	if len(cond) > 0 {
 		condition = fmt.Sprintf(" WHERE %s", strings.Join(cond, " AND "))
 		q = fmt.Sprintf("%s%s", q, condition)
 	}
@@ -197,6 +197,34 @@ func TestRetrieveAll(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			desc: "retrieve with channel",
+			pageMeta: notifiers.PageMetadata{
+				Offset:  0,
+				Limit:   2,
+				Channel: "list",
+			},
+			page: notifiers.Page{
+				Total: numSubs,
+				PageMetadata: notifiers.PageMetadata{
+					Offset:  0,
+					Limit:   2,
+					Channel: "list",
+				},
+				Subscriptions: subs[:2],
+			},
+			err: nil,
+		},
+		{
+			desc: "retrieve with channel prefix",
+			pageMeta: notifiers.PageMetadata{
+				Offset:  0,
+				Limit:   2,
+				Channel: "lis",
+			},
+			page: notifiers.Page{},
+			err:  notifiers.ErrNotFound,
+		},
 		{
 			desc: "retrieve with no limit",
 			pageMeta: notifiers.PageMetadata{
@@ -0,0 +1,65 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package notifiers
+
+import (
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"golang.org/x/time/rate"
+)
+
+var _ Notifier = (*rateLimiter)(nil)
+
+type rateLimiter struct {
+	notifier Notifier
+	rate     rate.Limit
+	burst    int
+	dropped  metrics.Counter
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewRateLimiter returns the notifier limiting the number of notifications
+// per second sent to each contact. Notifications exceeding the rate and the
+// burst are not sent to the contact, and they are counted by the dropped
+// counter.
+func NewRateLimiter(n Notifier, r float64, burst int, dropped metrics.Counter) Notifier {
+	return &rateLimiter{
+		notifier: n,
+		rate:     rate.Limit(r),
+		burst:    burst,
+		dropped:  dropped,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+func (rl *rateLimiter) Notify(from string, to []string, msg messaging.Message) error {
+	var allowed []string
+	for _, t := range to {
+		if !rl.limiter(t).Allow() {
+			rl.dropped.Add(1)
+			continue
+		}
+		allowed = append(allowed, t)
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	return rl.notifier.Notify(from, allowed, msg)
+}
+
+func (rl *rateLimiter) limiter(contact string) *rate.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	l, ok := rl.limiters[contact]
+	if !ok {
+		l = rate.NewLimiter(rl.rate, rl.burst)
+		rl.limiters[contact] = l
+	}
+	return l
+}
@@ -0,0 +1,139 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package notifiers_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	notifiers "github.com/mainflux/mainflux/consumers/notifiers"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+)
+
+var errFailed = errors.New("failed to notify")
+
+// counter counts the additions.
+type counter struct {
+	value float64
+}
+
+func (c *counter) With(...string) metrics.Counter {
+	return c
+}
+
+func (c *counter) Add(delta float64) {
+	c.value += delta
+}
+
+// failingNotifier records the receivers, failing if configured to.
+type failingNotifier struct {
+	fail bool
+	to   []string
+}
+
+func (n *failingNotifier) Notify(_ string, to []string, _ messaging.Message) error {
+	n.to = append(n.to, to...)
+	if n.fail {
+		return errFailed
+	}
+	return nil
+}
+
+func TestRateLimiter(t *testing.T) {
+	rec := &failingNotifier{}
+	dropped := &counter{}
+	// Negligible rate allows only the burst of notifications per contact.
+	n := notifiers.NewRateLimiter(rec, 1e-6, 2, dropped)
+
+	cases := []struct {
+		desc    string
+		to      []string
+		sent    []string
+		dropped float64
+	}{
+		{
+			desc:    "notify contacts within burst",
+			to:      []string{exampleUser1, exampleUser2},
+			sent:    []string{exampleUser1, exampleUser2},
+			dropped: 0,
+		},
+		{
+			desc:    "notify contact reaching burst",
+			to:      []string{exampleUser1},
+			sent:    []string{exampleUser1},
+			dropped: 0,
+		},
+		{
+			desc:    "notify contact exceeding burst",
+			to:      []string{exampleUser1, exampleUser2},
+			sent:    []string{exampleUser2},
+			dropped: 1,
+		},
+		{
+			desc:    "notify contacts exceeding burst",
+			to:      []string{exampleUser1, exampleUser2},
+			sent:    nil,
+			dropped: 3,
+		},
+	}
+
+	for _, tc := range cases {
+		rec.to = nil
+		err := n.Notify("", tc.to, messaging.Message{})
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.sent, rec.to, fmt.Sprintf("%s: expected receivers %v got %v", tc.desc, tc.sent, rec.to))
+		assert.Equal(t, tc.dropped, dropped.value, fmt.Sprintf("%s: expected %v dropped got %v", tc.desc, tc.dropped, dropped.value))
+	}
+}
+
+func TestDispatcher(t *testing.T) {
+	phone := "+38160123456"
+
+	cases := []struct {
+		desc   string
+		fail   bool
+		to     []string
+		emails []string
+		phones []string
+		err    bool
+	}{
+		{
+			desc:   "dispatch to email and phone contacts",
+			to:     []string{exampleUser1, phone, exampleUser2},
+			emails: []string{exampleUser1, exampleUser2},
+			phones: []string{phone},
+		},
+		{
+			desc:   "dispatch to unsupported contact",
+			to:     []string{"contact", exampleUser1},
+			emails: []string{exampleUser1},
+			err:    true,
+		},
+		{
+			desc:   "dispatch with failing notifier",
+			fail:   true,
+			to:     []string{phone, exampleUser1},
+			emails: []string{exampleUser1},
+			phones: []string{phone},
+			err:    true,
+		},
+	}
+
+	for _, tc := range cases {
+		email := &failingNotifier{}
+		sms := &failingNotifier{fail: tc.fail}
+		n := notifiers.NewDispatcher(map[string]notifiers.Notifier{
+			notifiers.EmailContact: email,
+			notifiers.PhoneContact: sms,
+		})
+
+		err := n.Notify("", tc.to, messaging.Message{})
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.emails, email.to, fmt.Sprintf("%s: expected emails %v got %v", tc.desc, tc.emails, email.to))
+		assert.Equal(t, tc.phones, sms.to, fmt.Sprintf("%s: expected phones %v got %v", tc.desc, tc.phones, sms.to))
+	}
+}
@@ -38,6 +38,13 @@ var (
 
 	// ErrMessage indicates an error converting a message to Mainflux message.
 	ErrMessage = errors.New("failed to convert to Mainflux message")
+
+	// ErrInvalidTopic indicates malformed subscription topic.
+	ErrInvalidTopic = errors.New("invalid subscription topic")
+
+	// ErrInvalidContact indicates the contact which is neither an email
+	// address nor a phone number.
+	ErrInvalidContact = errors.New("invalid subscription contact")
 )
 
 // Service reprents a notification service.
@@ -62,15 +69,18 @@ var _ Service = (*notifierService)(nil)
 
 type notifierService struct {
 	auth     mainflux.AuthServiceClient
+	things   mainflux.ThingsServiceClient
 	subs     SubscriptionsRepository
 	idp      mainflux.IDProvider
 	notifier Notifier
 }
 
-// New instantiates the subscriptions service implementation.
-func New(auth mainflux.AuthServiceClient, subs SubscriptionsRepository, idp mainflux.IDProvider, notifier Notifier) Service {
+// New instantiates the subscriptions service implementation. Users can
+// subscribe only to the channels they own.
+func New(auth mainflux.AuthServiceClient, things mainflux.ThingsServiceClient, subs SubscriptionsRepository, idp mainflux.IDProvider, notifier Notifier) Service {
 	return &notifierService{
 		auth:     auth,
+		things:   things,
 		subs:     subs,
 		idp:      idp,
 		notifier: notifier,
@@ -82,6 +92,19 @@ func (ns *notifierService) CreateSubscription(ctx context.Context, token string,
 	if err != nil {
 		return "", errors.Wrap(ErrUnauthorizedAccess, err)
 	}
+
+	sub.Topic = NormalizeTopic(sub.Topic)
+	if !ValidTopic(sub.Topic) {
+		return "", ErrInvalidTopic
+	}
+	if ContactType(sub.Contact) == "" {
+		return "", ErrInvalidContact
+	}
+	req := &mainflux.ChannelOwnerReq{Owner: res.GetEmail(), ChanID: channel(sub.Topic)}
+	if _, err := ns.things.IsChannelOwner(ctx, req); err != nil {
+		return "", errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+
 	sub.ID, err = ns.idp.ID()
 	if err != nil {
 		return "", errors.Wrap(ErrCreateID, err)
@@ -125,17 +148,23 @@ func (ns *notifierService) Consume(message interface{}) error {
 		topic = fmt.Sprintf("%s.%s", msg.Channel, msg.Subtopic)
 	}
 	pm := PageMetadata{
-		Topic:  topic,
-		Offset: 0,
-		Limit:  -1,
+		Channel: msg.Channel,
+		Offset:  0,
+		Limit:   -1,
 	}
 	page, err := ns.subs.RetrieveAll(context.Background(), pm)
 	if err != nil {
 		return err
 	}
 
+	// The contact subscribed using multiple patterns is notified once.
 	var to []string
+	contacts := make(map[string]bool)
 	for _, sub := range page.Subscriptions {
+		if !Match(sub.Topic, topic) || contacts[sub.Contact] {
+			continue
+		}
+		contacts[sub.Contact] = true
 		to = append(to, sub.Contact)
 	}
 	if len(to) > 0 {
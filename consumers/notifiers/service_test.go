@@ -6,6 +6,7 @@ package notifiers_test
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 
 	notifiers "github.com/mainflux/mainflux/consumers/notifiers"
@@ -25,11 +26,15 @@ const (
 )
 
 func newService() notifiers.Service {
+	return newServiceWithNotifier(mocks.NewNotifier())
+}
+
+func newServiceWithNotifier(notifier notifiers.Notifier) notifiers.Service {
 	repo := mocks.NewRepo(make(map[string]notifiers.Subscription))
 	auth := mocks.NewAuth(map[string]string{exampleUser1: exampleUser1, exampleUser2: exampleUser2, invalidUser: invalidUser})
-	notifier := mocks.NewNotifier()
+	things := mocks.NewThings(map[string]string{"valid": exampleUser1, "topic": exampleUser1, "other": exampleUser2})
 	idp := uuid.NewMock()
-	return notifiers.New(auth, repo, idp, notifier)
+	return notifiers.New(auth, things, repo, idp, notifier)
 }
 
 func TestCreateSubscription(t *testing.T) {
@@ -63,6 +68,41 @@ func TestCreateSubscription(t *testing.T) {
 			id:    "",
 			err:   notifiers.ErrUnauthorizedAccess,
 		},
+		{
+			desc:  "test channel owned by other user",
+			token: exampleUser1,
+			sub:   notifiers.Subscription{Contact: exampleUser1, Topic: "other.topic"},
+			id:    "",
+			err:   notifiers.ErrUnauthorizedAccess,
+		},
+		{
+			desc:  "test pattern with slashes",
+			token: exampleUser1,
+			sub:   notifiers.Subscription{Contact: exampleUser1, Topic: "valid/alerts/*"},
+			id:    uuid.Prefix + fmt.Sprintf("%012d", 3),
+			err:   nil,
+		},
+		{
+			desc:  "test phone contact",
+			token: exampleUser1,
+			sub:   notifiers.Subscription{Contact: "+38160123456", Topic: "valid.alerts.>"},
+			id:    uuid.Prefix + fmt.Sprintf("%012d", 4),
+			err:   nil,
+		},
+		{
+			desc:  "test invalid pattern",
+			token: exampleUser1,
+			sub:   notifiers.Subscription{Contact: exampleUser1, Topic: "valid.>.alerts"},
+			id:    "",
+			err:   notifiers.ErrInvalidTopic,
+		},
+		{
+			desc:  "test invalid contact",
+			token: exampleUser1,
+			sub:   notifiers.Subscription{Contact: "invalid", Topic: "valid.topic"},
+			id:    "",
+			err:   notifiers.ErrInvalidContact,
+		},
 	}
 
 	for _, tc := range cases {
@@ -125,12 +165,13 @@ func TestListSubscriptions(t *testing.T) {
 	for i := 0; i < total; i++ {
 		tmp := sub
 		token := exampleUser1
+		tmp.Topic = fmt.Sprintf("%s.%d", topic, i)
 		if i%2 == 0 {
 			tmp.Contact = exampleUser2
 			tmp.OwnerID = exampleUser2
+			tmp.Topic = fmt.Sprintf("other.subtopic.%d", i)
 			token = exampleUser2
 		}
-		tmp.Topic = fmt.Sprintf("%s.%d", topic, i)
 		id, err := svc.CreateSubscription(context.Background(), token, tmp)
 		require.Nil(t, err, "Saving a Subscription must succeed")
 		tmp.ID = id
@@ -192,14 +233,14 @@ func TestListSubscriptions(t *testing.T) {
 			token: exampleUser1,
 			pageMeta: notifiers.PageMetadata{
 				Limit: 10,
-				Topic: fmt.Sprintf("%s.%d", topic, 4),
+				Topic: fmt.Sprintf("%s.%d", topic, 5),
 			},
 			page: notifiers.Page{
 				PageMetadata: notifiers.PageMetadata{
 					Limit: 10,
-					Topic: fmt.Sprintf("%s.%d", topic, 4),
+					Topic: fmt.Sprintf("%s.%d", topic, 5),
 				},
-				Subscriptions: subs[4:5],
+				Subscriptions: subs[5:6],
 				Total:         1,
 			},
 			err: nil,
@@ -324,3 +365,71 @@ func TestConsume(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
 	}
 }
+
+// recorder records the receivers of the notifications.
+type recorder struct {
+	to []string
+}
+
+func (r *recorder) Notify(_ string, to []string, _ messaging.Message) error {
+	r.to = append(r.to, to...)
+	return nil
+}
+
+func TestConsumePatterns(t *testing.T) {
+	subs := []notifiers.Subscription{
+		{Contact: "channel@example.com", Topic: "topic"},
+		{Contact: "alerts@example.com", Topic: "topic.alerts"},
+		{Contact: "single@example.com", Topic: "topic.alerts.*"},
+		{Contact: "tail@example.com", Topic: "topic/alerts/>"},
+		{Contact: "any@example.com", Topic: "topic.*.fire"},
+		{Contact: "tail@example.com", Topic: "topic.alerts.fire"},
+		{Contact: "valid@example.com", Topic: "valid.alerts.>"},
+	}
+
+	cases := []struct {
+		desc     string
+		msg      messaging.Message
+		contacts []string
+	}{
+		{
+			desc:     "consume message without subtopic",
+			msg:      messaging.Message{Channel: "topic"},
+			contacts: []string{"channel@example.com"},
+		},
+		{
+			desc:     "consume message matching exact subtopic",
+			msg:      messaging.Message{Channel: "topic", Subtopic: "alerts"},
+			contacts: []string{"alerts@example.com"},
+		},
+		{
+			desc:     "consume message matching wildcards once per contact",
+			msg:      messaging.Message{Channel: "topic", Subtopic: "alerts.fire"},
+			contacts: []string{"any@example.com", "single@example.com", "tail@example.com"},
+		},
+		{
+			desc:     "consume message matching tail wildcard",
+			msg:      messaging.Message{Channel: "topic", Subtopic: "alerts.fire.room"},
+			contacts: []string{"tail@example.com"},
+		},
+		{
+			desc:     "consume message of other channel",
+			msg:      messaging.Message{Channel: "valid", Subtopic: "alerts.fire"},
+			contacts: []string{"valid@example.com"},
+		},
+	}
+
+	for _, tc := range cases {
+		rec := &recorder{}
+		svc := newServiceWithNotifier(rec)
+		for _, sub := range subs {
+			_, err := svc.CreateSubscription(context.Background(), exampleUser1, sub)
+			require.Nil(t, err, fmt.Sprintf("%s: saving a Subscription must succeed: %s", tc.desc, err))
+		}
+
+		err := svc.Consume(tc.msg)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		sort.Strings(rec.to)
+		assert.Equal(t, tc.contacts, rec.to, fmt.Sprintf("%s: expected contacts %v got %v", tc.desc, tc.contacts, rec.to))
+	}
+}
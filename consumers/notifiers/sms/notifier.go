@@ -0,0 +1,62 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sms
+
+import (
+	"fmt"
+
+	notifiers "github.com/mainflux/mainflux/consumers/notifiers"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+const contentTemplate = "Mainflux notification for channel %s%s: %s"
+
+// Provider represents the SMS gateway sending the text messages.
+type Provider interface {
+	// Send sends the text message from the sender to the phone number.
+	Send(from, to, text string) error
+}
+
+var _ notifiers.Notifier = (*notifier)(nil)
+
+type notifier struct {
+	provider Provider
+	from     string
+	maxLen   int
+}
+
+// New instantiates SMS message notifier, sending the messages using the
+// provider from the default sender. The message payload is truncated so that
+// the text is at most maxLen bytes long, unless maxLen is zero.
+func New(provider Provider, from string, maxLen int) notifiers.Notifier {
+	return &notifier{
+		provider: provider,
+		from:     from,
+		maxLen:   maxLen,
+	}
+}
+
+func (n *notifier) Notify(from string, to []string, msg messaging.Message) error {
+	if from == "" {
+		from = n.from
+	}
+
+	var subtopic string
+	if msg.Subtopic != "" {
+		subtopic = fmt.Sprintf(" and subtopic %s", msg.Subtopic)
+	}
+	text := fmt.Sprintf(contentTemplate, msg.Channel, subtopic, msg.Payload)
+	if n.maxLen > 0 && len(text) > n.maxLen {
+		text = text[:n.maxLen]
+	}
+
+	// Every receiver is sent the message, even if sending to some fails.
+	var err error
+	for _, t := range to {
+		if e := n.provider.Send(from, t, text); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
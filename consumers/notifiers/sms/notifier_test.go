@@ -0,0 +1,121 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sms_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/notifiers/sms"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	token    = "token"
+	sender   = "Mainflux"
+	phone1   = "+38160123456"
+	phone2   = "+38160654321"
+	rejected = "+38160000000"
+)
+
+type smsReq struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+// gateway stores the received SMS requests, rejecting the ones sent to the
+// rejected number or without the token.
+type gateway struct {
+	mu   sync.Mutex
+	reqs []smsReq
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var req smsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if req.To == rejected {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	g.mu.Lock()
+	g.reqs = append(g.reqs, req)
+	g.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestNotify(t *testing.T) {
+	msg := messaging.Message{Channel: "ch", Subtopic: "alerts", Payload: []byte("temp 90")}
+	text := "Mainflux notification for channel ch and subtopic alerts: temp 90"
+
+	cases := []struct {
+		desc   string
+		token  string
+		maxLen int
+		from   string
+		to     []string
+		reqs   []smsReq
+		err    bool
+	}{
+		{
+			desc:  "notify phone numbers",
+			token: token,
+			to:    []string{phone1, phone2},
+			reqs:  []smsReq{{From: sender, To: phone1, Text: text}, {From: sender, To: phone2, Text: text}},
+		},
+		{
+			desc:  "notify phone number from sender",
+			token: token,
+			from:  "Alerts",
+			to:    []string{phone1},
+			reqs:  []smsReq{{From: "Alerts", To: phone1, Text: text}},
+		},
+		{
+			desc:   "notify phone number with truncated text",
+			token:  token,
+			maxLen: 10,
+			to:     []string{phone1},
+			reqs:   []smsReq{{From: sender, To: phone1, Text: text[:10]}},
+		},
+		{
+			desc:  "notify rejected phone number",
+			token: token,
+			to:    []string{rejected, phone1},
+			reqs:  []smsReq{{From: sender, To: phone1, Text: text}},
+			err:   true,
+		},
+		{
+			desc:  "notify with invalid token",
+			token: "invalid",
+			to:    []string{phone1},
+			err:   true,
+		},
+	}
+
+	for _, tc := range cases {
+		gw := &gateway{}
+		ts := httptest.NewServer(gw)
+		n := sms.New(sms.NewWebhook(ts.URL, tc.token, time.Second), sender, tc.maxLen)
+
+		err := n.Notify(tc.from, tc.to, msg)
+		ts.Close()
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", tc.desc, tc.err, err))
+		require.Len(t, gw.reqs, len(tc.reqs), fmt.Sprintf("%s: expected %d requests got %d", tc.desc, len(tc.reqs), len(gw.reqs)))
+		assert.Equal(t, tc.reqs, gw.reqs, fmt.Sprintf("%s: expected requests %v got %v", tc.desc, tc.reqs, gw.reqs))
+	}
+}
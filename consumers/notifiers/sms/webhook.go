@@ -0,0 +1,73 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const contentType = "application/json"
+
+var (
+	errSendSMS        = errors.New("failed to send SMS")
+	errUnexpectedCode = errors.New("unexpected SMS gateway response code")
+)
+
+type smsReq struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+	Text string `json:"text"`
+}
+
+var _ Provider = (*webhook)(nil)
+
+type webhook struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewWebhook returns the provider posting the JSON object with the "from",
+// "to" and "text" fields to the SMS gateway URL. If the token is set, it's
+// sent as the bearer token. Any response code other than 2xx is an error.
+func NewWebhook(url, token string, timeout time.Duration) Provider {
+	return &webhook{
+		url:    url,
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *webhook) Send(from, to, text string) error {
+	data, err := json.Marshal(smsReq{From: from, To: to, Text: text})
+	if err != nil {
+		return errors.Wrap(errSendSMS, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(errSendSMS, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if w.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.token))
+	}
+
+	res, err := w.client.Do(req)
+	if err != nil {
+		return errors.Wrap(errSendSMS, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return errors.Wrap(errSendSMS, errors.Wrap(errUnexpectedCode, fmt.Errorf("%d", res.StatusCode)))
+	}
+	return nil
+}
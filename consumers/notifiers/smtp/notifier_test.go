@@ -0,0 +1,201 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package smtp_test
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers/notifiers/smtp"
+	"github.com/mainflux/mainflux/internal/email"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	from     = "notifier@example.com"
+	tmplText = "To: {{range .To}}{{.}},{{end}}\r\nFrom: {{.From}}\r\nSubject: {{.Subject}}\r\n\r\n{{.Content}}\r\n{{.Footer}}\r\n"
+)
+
+// mail represents the mail received by the server.
+type mail struct {
+	from string
+	to   []string
+	data string
+}
+
+// server is a minimal SMTP server storing the received mails, which
+// rejects the recipients of the reject domain.
+type server struct {
+	ln     net.Listener
+	reject string
+
+	mu    sync.Mutex
+	mails []mail
+}
+
+func newServer(t *testing.T, reject string) *server {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	s := &server{ln: ln, reject: reject}
+	go s.serve()
+	return s
+}
+
+func (s *server) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *server) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		fmt.Fprintf(conn, "%s\r\n", line)
+	}
+
+	reply("220 localhost ESMTP")
+	var m mail
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			m = mail{from: strings.Trim(line[len("MAIL FROM:"):], "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			rcpt := strings.Trim(line[len("RCPT TO:"):], "<>")
+			if s.reject != "" && strings.HasSuffix(rcpt, s.reject) {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			m.to = append(m.to, rcpt)
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data []string
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data = append(data, l)
+			}
+			m.data = strings.Join(data, "")
+			s.mu.Lock()
+			s.mails = append(s.mails, m)
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *server) received() []mail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mails
+}
+
+func newAgent(t *testing.T, addr string) *email.Agent {
+	f, err := ioutil.TempFile("", "email-*.tmpl")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(tmplText)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	require.Nil(t, f.Close(), "closing file expected to succeed")
+
+	host, port, err := net.SplitHostPort(addr)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	agent, err := email.New(&email.Config{
+		Host:        host,
+		Port:        port,
+		FromAddress: from,
+		FromName:    "Notifier",
+		Template:    f.Name(),
+	})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return agent
+}
+
+func TestNotify(t *testing.T) {
+	srv := newServer(t, "@rejected.com")
+	defer srv.ln.Close()
+	n := smtp.New(newAgent(t, srv.ln.Addr().String()))
+
+	cases := []struct {
+		desc     string
+		to       []string
+		msg      messaging.Message
+		contains []string
+		err      bool
+	}{
+		{
+			desc: "notify about message with subtopic",
+			to:   []string{"user1@example.com", "user2@example.com"},
+			msg:  messaging.Message{Channel: "ch", Subtopic: "alerts.fire", Publisher: "pub", Protocol: "mqtt", Payload: []byte("temp 90")},
+			contains: []string{
+				"Subject: Notification for Channel ch and subtopic alerts.fire",
+				"A publisher with an id pub sent the message over mqtt",
+				"temp 90",
+			},
+		},
+		{
+			desc:     "notify about message without subtopic",
+			to:       []string{"user1@example.com"},
+			msg:      messaging.Message{Channel: "ch", Publisher: "pub", Protocol: "http", Payload: []byte("on")},
+			contains: []string{"Subject: Notification for Channel ch\r\n", "over http"},
+		},
+		{
+			desc: "notify rejected recipient",
+			to:   []string{"user@rejected.com"},
+			msg:  messaging.Message{Channel: "ch"},
+			err:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		before := len(srv.received())
+		err := n.Notify("", tc.to, tc.msg)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", tc.desc, tc.err, err))
+
+		mails := srv.received()
+		if tc.err {
+			assert.Len(t, mails, before, fmt.Sprintf("%s: expected no mail sent", tc.desc))
+			continue
+		}
+		require.Len(t, mails, before+1, fmt.Sprintf("%s: expected mail sent", tc.desc))
+		m := mails[before]
+		assert.Equal(t, from, m.from, fmt.Sprintf("%s: expected sender %s got %s", tc.desc, from, m.from))
+		assert.Equal(t, tc.to, m.to, fmt.Sprintf("%s: expected recipients %v got %v", tc.desc, tc.to, m.to))
+		for _, c := range tc.contains {
+			assert.Contains(t, m.data, c, fmt.Sprintf("%s: expected mail to contain %q", tc.desc, c))
+		}
+	}
+}
@@ -27,6 +27,8 @@ type PageMetadata struct {
 	Limit   int
 	Topic   string
 	Contact string
+	// Channel filters the subscriptions to the topics of the channel.
+	Channel string
 }
 
 // SubscriptionsRepository specifies a Subscription persistence API.
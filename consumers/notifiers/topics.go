@@ -0,0 +1,62 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package notifiers
+
+import "strings"
+
+const (
+	topicSep     = "."
+	wildcardOne  = "*"
+	wildcardTail = ">"
+)
+
+// NormalizeTopic returns the subscription topic using the message broker
+// separator, so "<channel_id>/alerts/*" becomes "<channel_id>.alerts.*".
+func NormalizeTopic(topic string) string {
+	topic = strings.Replace(topic, "/", topicSep, -1)
+	return strings.Trim(topic, topicSep)
+}
+
+// ValidTopic checks that the subscription topic consists of the channel ID,
+// optionally followed by the subtopic pattern. In the pattern, "*" matches a
+// single subtopic token and the trailing ">" matches one or more tokens.
+func ValidTopic(topic string) bool {
+	tokens := strings.Split(topic, topicSep)
+	for i, t := range tokens {
+		switch {
+		case t == "":
+			return false
+		case i == 0 && (t == wildcardOne || t == wildcardTail):
+			return false
+		case t == wildcardTail && i != len(tokens)-1:
+			return false
+		case t != wildcardOne && t != wildcardTail && strings.ContainsAny(t, wildcardOne+wildcardTail):
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether the message topic, i.e. the channel ID followed by
+// the subtopic, matches the subscription topic.
+func Match(pattern, topic string) bool {
+	pt := strings.Split(pattern, topicSep)
+	tt := strings.Split(topic, topicSep)
+	for i, p := range pt {
+		switch {
+		case p == wildcardTail:
+			return len(tt) > i
+		case i >= len(tt):
+			return false
+		case p != wildcardOne && p != tt[i]:
+			return false
+		}
+	}
+	return len(pt) == len(tt)
+}
+
+// channel returns the channel ID of the subscription topic.
+func channel(topic string) string {
+	return strings.SplitN(topic, topicSep, 2)[0]
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package notifiers_test
+
+import (
+	"fmt"
+	"testing"
+
+	notifiers "github.com/mainflux/mainflux/consumers/notifiers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidTopic(t *testing.T) {
+	cases := []struct {
+		desc  string
+		topic string
+		valid bool
+	}{
+		{desc: "validate channel", topic: "ch", valid: true},
+		{desc: "validate channel with subtopic", topic: "ch.alerts.fire", valid: true},
+		{desc: "validate single token wildcard", topic: "ch.*.fire", valid: true},
+		{desc: "validate tail wildcard", topic: "ch.alerts.>", valid: true},
+		{desc: "validate empty topic", topic: "", valid: false},
+		{desc: "validate empty token", topic: "ch..fire", valid: false},
+		{desc: "validate channel wildcard", topic: "*.alerts", valid: false},
+		{desc: "validate tail wildcard in the middle", topic: "ch.>.fire", valid: false},
+		{desc: "validate wildcard within token", topic: "ch.alerts*", valid: false},
+	}
+
+	for _, tc := range cases {
+		valid := notifiers.ValidTopic(tc.topic)
+		assert.Equal(t, tc.valid, valid, fmt.Sprintf("%s: expected %t got %t", tc.desc, tc.valid, valid))
+	}
+}
+
+func TestNormalizeTopic(t *testing.T) {
+	cases := []struct {
+		desc     string
+		topic    string
+		expected string
+	}{
+		{desc: "normalize dot separated topic", topic: "ch.alerts.*", expected: "ch.alerts.*"},
+		{desc: "normalize slash separated topic", topic: "ch/alerts/*", expected: "ch.alerts.*"},
+		{desc: "normalize topic with leading and trailing slashes", topic: "/ch/alerts/", expected: "ch.alerts"},
+	}
+
+	for _, tc := range cases {
+		topic := notifiers.NormalizeTopic(tc.topic)
+		assert.Equal(t, tc.expected, topic, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.expected, topic))
+	}
+}
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		desc    string
+		pattern string
+		topic   string
+		match   bool
+	}{
+		{desc: "match channel", pattern: "ch", topic: "ch", match: true},
+		{desc: "match channel with subtopic", pattern: "ch", topic: "ch.alerts", match: false},
+		{desc: "match other channel", pattern: "ch", topic: "ch2", match: false},
+		{desc: "match exact subtopic", pattern: "ch.alerts", topic: "ch.alerts", match: true},
+		{desc: "match subtopic prefix", pattern: "ch.alerts", topic: "ch.alerts.fire", match: false},
+		{desc: "match single token wildcard", pattern: "ch.alerts.*", topic: "ch.alerts.fire", match: true},
+		{desc: "match single token wildcard with more tokens", pattern: "ch.alerts.*", topic: "ch.alerts.fire.room", match: false},
+		{desc: "match single token wildcard without token", pattern: "ch.alerts.*", topic: "ch.alerts", match: false},
+		{desc: "match single token wildcard in the middle", pattern: "ch.*.fire", topic: "ch.alerts.fire", match: true},
+		{desc: "match tail wildcard", pattern: "ch.alerts.>", topic: "ch.alerts.fire.room", match: true},
+		{desc: "match tail wildcard without token", pattern: "ch.alerts.>", topic: "ch.alerts", match: false},
+		{desc: "match tail wildcard of other channel", pattern: "ch.>", topic: "ch2.alerts", match: false},
+	}
+
+	for _, tc := range cases {
+		match := notifiers.Match(tc.pattern, tc.topic)
+		assert.Equal(t, tc.match, match, fmt.Sprintf("%s: expected %t got %t", tc.desc, tc.match, match))
+	}
+}
+
+func TestContactType(t *testing.T) {
+	cases := []struct {
+		desc    string
+		contact string
+		typ     string
+	}{
+		{desc: "email address", contact: "user@example.com", typ: notifiers.EmailContact},
+		{desc: "phone number", contact: "+38160123456", typ: notifiers.PhoneContact},
+		{desc: "phone number without plus", contact: "38160123456", typ: ""},
+		{desc: "email address with name", contact: "User <user@example.com>", typ: ""},
+		{desc: "invalid contact", contact: "user", typ: ""},
+	}
+
+	for _, tc := range cases {
+		typ := notifiers.ContactType(tc.contact)
+		assert.Equal(t, tc.typ, typ, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.typ, typ))
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retention contains the purger removing the messages older than the
+// retention of their channel. The channels retention hints are kept up to
+// date using the things service events.
+package retention
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/schemas"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+)
+
+const (
+	channelPrefix = "channel."
+	channelCreate = channelPrefix + "create"
+	channelUpdate = channelPrefix + "update"
+	channelRemove = channelPrefix + "remove"
+)
+
+// ErrMalformedEvent indicates the things event that can't be decoded.
+var ErrMalformedEvent = errors.New("malformed things event")
+
+var _ schemas.Handler = (*Cache)(nil)
+
+// Cache contains the retention hints of the channels.
+type Cache struct {
+	mu    sync.RWMutex
+	hints map[string]time.Duration
+}
+
+// NewCache returns an empty retention hints cache.
+func NewCache() *Cache {
+	return &Cache{
+		hints: make(map[string]time.Duration),
+	}
+}
+
+// Hints returns the copy of the channels retention hints.
+func (c *Cache) Hints() map[string]time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	hints := make(map[string]time.Duration, len(c.hints))
+	for ch, d := range c.hints {
+		hints[ch] = d
+	}
+	return hints
+}
+
+// Handle updates the cache using the things service event. Only the channel
+// events are handled, the retention being read from the channel metadata.
+// The hint of the channel is removed if the event metadata doesn't contain
+// one, or if it's invalid.
+func (c *Cache) Handle(event map[string]interface{}) error {
+	id, _ := event["id"].(string)
+	switch event["operation"] {
+	case channelCreate, channelUpdate:
+		if id == "" {
+			return ErrMalformedEvent
+		}
+		d, err := decodeRetention(event)
+		c.set(id, d)
+		return err
+	case channelRemove:
+		c.set(id, 0)
+	}
+	return nil
+}
+
+func (c *Cache) set(channel string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if d == 0 {
+		delete(c.hints, channel)
+		return
+	}
+	c.hints[channel] = d
+}
+
+func decodeRetention(event map[string]interface{}) (time.Duration, error) {
+	raw, ok := event["metadata"].(string)
+	if !ok {
+		return 0, nil
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		return 0, errors.Wrap(ErrMalformedEvent, err)
+	}
+	v, ok := metadata[things.RetentionKey]
+	if !ok {
+		return 0, nil
+	}
+
+	d, err := things.ParseRetention(v)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.New(fmt.Sprintf("channel %s", event["id"])))
+	}
+	return d, nil
+}
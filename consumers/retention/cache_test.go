@@ -0,0 +1,121 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package retention_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/consumers/schemas"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chanID = "chan-1"
+	other  = "chan-2"
+	week   = 7 * 24 * time.Hour
+)
+
+func TestHandle(t *testing.T) {
+	cache := retention.NewCache()
+
+	cases := []struct {
+		desc  string
+		event map[string]interface{}
+		hints map[string]time.Duration
+		err   error
+	}{
+		{
+			desc:  "handle channel creation with retention",
+			event: map[string]interface{}{"id": chanID, "operation": "channel.create", "metadata": `{"location":"lab","retention":"7d"}`},
+			hints: map[string]time.Duration{chanID: week},
+		},
+		{
+			desc:  "handle creation of other channel without retention",
+			event: map[string]interface{}{"id": other, "operation": "channel.create", "name": "other"},
+			hints: map[string]time.Duration{chanID: week},
+		},
+		{
+			desc:  "handle thing event",
+			event: map[string]interface{}{"id": other, "operation": "thing.update", "metadata": `{"retention":"1h"}`},
+			hints: map[string]time.Duration{chanID: week},
+		},
+		{
+			desc:  "handle update of other channel with retention",
+			event: map[string]interface{}{"id": other, "operation": "channel.update", "metadata": `{"retention":"36h"}`},
+			hints: map[string]time.Duration{chanID: week, other: 36 * time.Hour},
+		},
+		{
+			desc:  "handle channel update without retention",
+			event: map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": `{"location":"lab"}`},
+			hints: map[string]time.Duration{other: 36 * time.Hour},
+		},
+		{
+			desc:  "handle channel update with invalid retention",
+			event: map[string]interface{}{"id": other, "operation": "channel.update", "metadata": `{"retention":"week"}`},
+			hints: map[string]time.Duration{},
+			err:   things.ErrInvalidRetention,
+		},
+		{
+			desc:  "handle channel update with malformed metadata",
+			event: map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": `{`},
+			hints: map[string]time.Duration{},
+			err:   retention.ErrMalformedEvent,
+		},
+		{
+			desc:  "handle channel update without id",
+			event: map[string]interface{}{"operation": "channel.update", "metadata": `{"retention":"1h"}`},
+			hints: map[string]time.Duration{},
+			err:   retention.ErrMalformedEvent,
+		},
+		{
+			desc:  "handle channel removal",
+			event: map[string]interface{}{"id": chanID, "operation": "channel.remove"},
+			hints: map[string]time.Duration{},
+		},
+	}
+
+	for _, tc := range cases {
+		err := cache.Handle(tc.event)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		hints := cache.Hints()
+		assert.Equal(t, tc.hints, hints, fmt.Sprintf("%s: expected hints %v got %v", tc.desc, tc.hints, hints))
+	}
+}
+
+// lister returns all the channels in a single page.
+type lister []things.Channel
+
+func (l lister) ListChannelsByMetadataKey(context.Context, string, things.PageMetadata) (things.ChannelsPage, error) {
+	page := things.ChannelsPage{
+		PageMetadata: things.PageMetadata{Total: uint64(len(l))},
+		Channels:     l,
+	}
+	return page, nil
+}
+
+func TestSeed(t *testing.T) {
+	log, err := logger.New(ioutil.Discard, "info")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	chs := lister{
+		{ID: chanID, Metadata: things.Metadata{things.RetentionKey: "7d"}},
+		{ID: other, Metadata: things.Metadata{things.RetentionKey: "36h"}},
+		{ID: "invalid", Metadata: things.Metadata{things.RetentionKey: "week"}},
+	}
+	cache := retention.NewCache()
+	err = schemas.Seed(context.Background(), chs, things.RetentionKey, cache, log)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	hints := map[string]time.Duration{chanID: week, other: 36 * time.Hour}
+	assert.Equal(t, hints, cache.Hints(), fmt.Sprintf("expected hints %v got %v", hints, cache.Hints()))
+}
@@ -0,0 +1,98 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var errPurge = errors.New("failed to purge messages")
+
+// Store specifies the removal of the stored messages.
+type Store interface {
+	// Purge removes the messages of the channel created before the given
+	// time.
+	Purge(ctx context.Context, channel string, before time.Time) error
+
+	// PurgeExcept removes the messages of all channels but the given ones
+	// created before the given time.
+	PurgeExcept(ctx context.Context, channels []string, before time.Time) error
+}
+
+// Hints specifies the source of the channels retention hints.
+type Hints interface {
+	// Hints returns the retention hints of the channels.
+	Hints() map[string]time.Duration
+}
+
+// Purger periodically removes the messages older than the retention of their
+// channel. The channels without the retention hint are kept for the default
+// retention, or forever if it isn't set.
+type Purger struct {
+	store     Store
+	hints     Hints
+	retention time.Duration
+	logger    logger.Logger
+}
+
+// NewPurger returns the purger of the store messages. Zero default retention
+// keeps the messages of the channels without the hint.
+func NewPurger(store Store, hints Hints, retention time.Duration, logger logger.Logger) *Purger {
+	return &Purger{
+		store:     store,
+		hints:     hints,
+		retention: retention,
+		logger:    logger,
+	}
+}
+
+// Run purges the messages once per interval until the context is canceled.
+// The interval must be positive.
+func (p *Purger) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Purge(ctx, time.Now()); err != nil {
+			p.logger.Warn(fmt.Sprintf("Failed to purge expired messages: %s", err))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge removes the messages expired at the given time, using the current
+// retention hints. The purge continues if removing the messages of the
+// channel fails, returning the last error.
+func (p *Purger) Purge(ctx context.Context, now time.Time) error {
+	hints := p.hints.Hints()
+	channels := make([]string, 0, len(hints))
+	for ch := range hints {
+		channels = append(channels, ch)
+	}
+	sort.Strings(channels)
+
+	var err error
+	for _, ch := range channels {
+		if e := p.store.Purge(ctx, ch, now.Add(-hints[ch])); e != nil {
+			err = errors.Wrap(errPurge, errors.Wrap(errors.New(fmt.Sprintf("channel %s", ch)), e))
+		}
+	}
+
+	if p.retention > 0 {
+		if e := p.store.PurgeExcept(ctx, channels, now.Add(-p.retention)); e != nil {
+			err = errors.Wrap(errPurge, e)
+		}
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package retention_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errPurge = errors.New("purge failed")
+
+// store records the purge cutoffs, failing for the given channel.
+type store struct {
+	fail    string
+	cutoffs map[string]time.Time
+	except  []string
+	before  time.Time
+}
+
+func (s *store) Purge(_ context.Context, channel string, before time.Time) error {
+	if channel == s.fail {
+		return errPurge
+	}
+	s.cutoffs[channel] = before
+	return nil
+}
+
+func (s *store) PurgeExcept(_ context.Context, channels []string, before time.Time) error {
+	s.except = channels
+	s.before = before
+	return nil
+}
+
+func newLogger(t *testing.T) logger.Logger {
+	l, err := logger.New(ioutil.Discard, "error")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return l
+}
+
+func TestPurge(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	def := 30 * 24 * time.Hour
+
+	cases := []struct {
+		desc      string
+		events    []map[string]interface{}
+		retention time.Duration
+		fail      string
+		cutoffs   map[string]time.Time
+		except    []string
+		before    time.Time
+		err       error
+	}{
+		{
+			desc:      "purge without hints",
+			retention: def,
+			cutoffs:   map[string]time.Time{},
+			except:    []string{},
+			before:    now.Add(-def),
+		},
+		{
+			desc: "purge channel with retention",
+			events: []map[string]interface{}{
+				{"id": chanID, "operation": "channel.create", "metadata": `{"retention":"7d"}`},
+			},
+			retention: def,
+			cutoffs:   map[string]time.Time{chanID: now.Add(-week)},
+			except:    []string{chanID},
+			before:    now.Add(-def),
+		},
+		{
+			desc: "purge channel with changed retention",
+			events: []map[string]interface{}{
+				{"id": chanID, "operation": "channel.create", "metadata": `{"retention":"7d"}`},
+				{"id": other, "operation": "channel.create", "metadata": `{"retention":"90d"}`},
+				{"id": chanID, "operation": "channel.update", "metadata": `{"retention":"12h"}`},
+			},
+			retention: def,
+			cutoffs:   map[string]time.Time{chanID: now.Add(-12 * time.Hour), other: now.Add(-90 * 24 * time.Hour)},
+			except:    []string{chanID, other},
+			before:    now.Add(-def),
+		},
+		{
+			desc: "purge channel with removed retention",
+			events: []map[string]interface{}{
+				{"id": chanID, "operation": "channel.create", "metadata": `{"retention":"7d"}`},
+				{"id": chanID, "operation": "channel.update", "metadata": `{"location":"lab"}`},
+			},
+			retention: def,
+			cutoffs:   map[string]time.Time{},
+			except:    []string{},
+			before:    now.Add(-def),
+		},
+		{
+			desc: "purge channel without default retention",
+			events: []map[string]interface{}{
+				{"id": chanID, "operation": "channel.create", "metadata": `{"retention":"7d"}`},
+			},
+			cutoffs: map[string]time.Time{chanID: now.Add(-week)},
+		},
+		{
+			desc: "purge with failing channel",
+			events: []map[string]interface{}{
+				{"id": chanID, "operation": "channel.create", "metadata": `{"retention":"7d"}`},
+				{"id": other, "operation": "channel.create", "metadata": `{"retention":"1d"}`},
+			},
+			retention: def,
+			fail:      chanID,
+			cutoffs:   map[string]time.Time{other: now.Add(-24 * time.Hour)},
+			except:    []string{chanID, other},
+			before:    now.Add(-def),
+			err:       errPurge,
+		},
+	}
+
+	for _, tc := range cases {
+		cache := retention.NewCache()
+		s := &store{fail: tc.fail, cutoffs: map[string]time.Time{}}
+		p := retention.NewPurger(s, cache, tc.retention, newLogger(t))
+		for _, event := range tc.events {
+			err := cache.Handle(event)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		}
+
+		err := p.Purge(context.Background(), now)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.cutoffs, s.cutoffs, fmt.Sprintf("%s: expected cutoffs %v got %v", tc.desc, tc.cutoffs, s.cutoffs))
+		assert.Equal(t, tc.except, s.except, fmt.Sprintf("%s: expected excluded channels %v got %v", tc.desc, tc.except, s.except))
+		assert.Equal(t, tc.before, s.before, fmt.Sprintf("%s: expected default cutoff %s got %s", tc.desc, tc.before, s.before))
+	}
+}
+
+func TestPurgeNextRun(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	cache := retention.NewCache()
+	s := &store{cutoffs: map[string]time.Time{}}
+	p := retention.NewPurger(s, cache, 0, newLogger(t))
+
+	err := cache.Handle(map[string]interface{}{"id": chanID, "operation": "channel.create", "metadata": `{"retention":"7d"}`})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = p.Purge(context.Background(), now)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, now.Add(-week), s.cutoffs[chanID], fmt.Sprintf("expected cutoff %s got %s", now.Add(-week), s.cutoffs[chanID]))
+
+	err = cache.Handle(map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": `{"retention":"1d"}`})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	next := now.Add(time.Hour)
+	err = p.Purge(context.Background(), next)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	expected := next.Add(-24 * time.Hour)
+	assert.Equal(t, expected, s.cutoffs[chanID], fmt.Sprintf("expected cutoff %s got %s", expected, s.cutoffs[chanID]))
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package schemas contains the cache of the channel JSON schemas, kept up to
+// date using the things service events.
+package schemas
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/jsonschema"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/things"
+)
+
+const (
+	channelPrefix = "channel."
+	channelCreate = channelPrefix + "create"
+	channelUpdate = channelPrefix + "update"
+	channelRemove = channelPrefix + "remove"
+)
+
+var (
+	// ErrMalformedEvent indicates the things event that can't be decoded.
+	ErrMalformedEvent = errors.New("malformed things event")
+
+	// ErrInvalidSchema indicates the channel schema that can't be compiled.
+	ErrInvalidSchema = errors.New("invalid channel schema")
+)
+
+var _ mfjson.Schemas = (*Cache)(nil)
+
+// Cache contains the compiled JSON schemas of the channels.
+type Cache struct {
+	mu      sync.RWMutex
+	schemas map[string]*jsonschema.Schema
+}
+
+// NewCache returns an empty schemas cache.
+func NewCache() *Cache {
+	return &Cache{
+		schemas: make(map[string]*jsonschema.Schema),
+	}
+}
+
+// Schema returns the schema of the channel, or nil if the channel has none.
+func (c *Cache) Schema(channel string) *jsonschema.Schema {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.schemas[channel]
+}
+
+// Handle updates the cache using the things service event. Only the channel
+// events are handled, the schema being read from the channel metadata. The
+// schema of the channel is removed if the event metadata doesn't contain one,
+// or if it can't be compiled.
+func (c *Cache) Handle(event map[string]interface{}) error {
+	id, _ := event["id"].(string)
+	switch event["operation"] {
+	case channelCreate, channelUpdate:
+		if id == "" {
+			return ErrMalformedEvent
+		}
+		schema, err := decodeSchema(event)
+		c.set(id, schema)
+		return err
+	case channelRemove:
+		c.set(id, nil)
+	}
+	return nil
+}
+
+func (c *Cache) set(channel string, schema *jsonschema.Schema) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if schema == nil {
+		delete(c.schemas, channel)
+		return
+	}
+	c.schemas[channel] = schema
+}
+
+func decodeSchema(event map[string]interface{}) (*jsonschema.Schema, error) {
+	raw, ok := event["metadata"].(string)
+	if !ok {
+		return nil, nil
+	}
+
+	var metadata map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+		return nil, errors.Wrap(ErrMalformedEvent, err)
+	}
+	data, ok := metadata[things.SchemaKey]
+	if !ok {
+		return nil, nil
+	}
+
+	schema, err := jsonschema.Compile(data)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidSchema, errors.New(fmt.Sprintf("channel %s: %s", event["id"], err)))
+	}
+	return schema, nil
+}
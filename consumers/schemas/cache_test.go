@@ -0,0 +1,172 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package schemas_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers/schemas"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	chanID   = "chan-1"
+	other    = "chan-2"
+	metadata = `{"location":"lab","schema":{"type":"object","required":["temperature"],"properties":{"temperature":{"type":"number"}}}}`
+)
+
+func TestHandle(t *testing.T) {
+	cache := schemas.NewCache()
+
+	cases := []struct {
+		desc    string
+		event   map[string]interface{}
+		channel string
+		schema  bool
+		err     error
+	}{
+		{
+			desc:    "handle channel creation with schema",
+			event:   map[string]interface{}{"id": chanID, "operation": "channel.create", "metadata": metadata},
+			channel: chanID,
+			schema:  true,
+		},
+		{
+			desc:    "handle creation of other channel without schema",
+			event:   map[string]interface{}{"id": other, "operation": "channel.create", "name": "other"},
+			channel: other,
+			schema:  false,
+		},
+		{
+			desc:    "handle thing event",
+			event:   map[string]interface{}{"id": chanID, "operation": "thing.remove"},
+			channel: chanID,
+			schema:  true,
+		},
+		{
+			desc:    "handle channel update without schema",
+			event:   map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": `{"location":"lab"}`},
+			channel: chanID,
+			schema:  false,
+		},
+		{
+			desc:    "handle channel update with schema",
+			event:   map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": metadata},
+			channel: chanID,
+			schema:  true,
+		},
+		{
+			desc:    "handle channel update with invalid schema",
+			event:   map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": `{"schema":{"type":"float"}}`},
+			channel: chanID,
+			schema:  false,
+			err:     schemas.ErrInvalidSchema,
+		},
+		{
+			desc:    "handle channel update with malformed metadata",
+			event:   map[string]interface{}{"id": other, "operation": "channel.update", "metadata": `{`},
+			channel: other,
+			schema:  false,
+			err:     schemas.ErrMalformedEvent,
+		},
+		{
+			desc:    "handle channel update without id",
+			event:   map[string]interface{}{"operation": "channel.update", "metadata": metadata},
+			channel: "",
+			schema:  false,
+			err:     schemas.ErrMalformedEvent,
+		},
+		{
+			desc:    "handle channel removal",
+			event:   map[string]interface{}{"id": chanID, "operation": "channel.remove"},
+			channel: chanID,
+			schema:  false,
+		},
+	}
+
+	for _, tc := range cases {
+		err := cache.Handle(tc.event)
+		if tc.err == nil {
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		} else {
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		}
+		s := cache.Schema(tc.channel)
+		assert.Equal(t, tc.schema, s != nil, fmt.Sprintf("%s: expected schema %t got %v", tc.desc, tc.schema, s))
+	}
+}
+
+func TestTransformReload(t *testing.T) {
+	cache := schemas.NewCache()
+	tr := mfjson.NewWithSchemas(mfjson.Config{}, nil, cache)
+
+	valid := []byte(`{"temperature": 21.5}`)
+	invalid := []byte(`{"temperature": "21.5"}`)
+
+	cases := []struct {
+		desc    string
+		event   map[string]interface{}
+		channel string
+		payload []byte
+		err     error
+	}{
+		{
+			desc:    "transform invalid payload before schema is set",
+			channel: chanID,
+			payload: invalid,
+		},
+		{
+			desc:    "transform invalid payload after schema is set",
+			event:   map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": metadata},
+			channel: chanID,
+			payload: invalid,
+			err:     mfjson.ErrSchemaViolation,
+		},
+		{
+			desc:    "transform valid payload after schema is set",
+			channel: chanID,
+			payload: valid,
+		},
+		{
+			desc:    "transform invalid payload on channel without schema",
+			channel: other,
+			payload: invalid,
+		},
+		{
+			desc:    "transform valid payload after schema is changed",
+			event:   map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": `{"schema":{"properties":{"temperature":{"type":"string"}}}}`},
+			channel: chanID,
+			payload: valid,
+			err:     mfjson.ErrSchemaViolation,
+		},
+		{
+			desc:    "transform invalid payload after schema is removed",
+			event:   map[string]interface{}{"id": chanID, "operation": "channel.update", "metadata": `{}`},
+			channel: chanID,
+			payload: invalid,
+		},
+	}
+
+	for _, tc := range cases {
+		if tc.event != nil {
+			err := cache.Handle(tc.event)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		}
+		msg := messaging.Message{
+			Channel:  tc.channel,
+			Subtopic: "sensors",
+			Payload:  tc.payload,
+		}
+		_, err := tr.Transform(msg)
+		if tc.err == nil {
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			continue
+		}
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+}
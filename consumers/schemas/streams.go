@@ -0,0 +1,158 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package schemas
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/things"
+)
+
+const (
+	stream = "mainflux.things"
+	batch  = 100
+	block  = time.Second
+)
+
+// Handler handles the things events.
+type Handler interface {
+	// Handle handles the things event.
+	Handle(event map[string]interface{}) error
+}
+
+// Lister lists the channels having the metadata key set, such as the things
+// service internal API client.
+type Lister interface {
+	// ListChannelsByMetadataKey retrieves the page of the channels of all
+	// the users having the metadata key set.
+	ListChannelsByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error)
+}
+
+// Seed hands the channels having the metadata key set to the handler as the
+// channel update events, so the handler gets the current state of the
+// channels regardless of the events trimmed from the stream. The channels the
+// handler fails to handle are logged and skipped, like the invalid events.
+func Seed(ctx context.Context, lister Lister, key string, handler Handler, logger logger.Logger) error {
+	pm := things.PageMetadata{Limit: batch}
+	for {
+		page, err := lister.ListChannelsByMetadataKey(ctx, key, pm)
+		if err != nil {
+			return err
+		}
+		for _, ch := range page.Channels {
+			metadata, err := json.Marshal(ch.Metadata)
+			if err != nil {
+				return err
+			}
+			event := map[string]interface{}{
+				"id":        ch.ID,
+				"operation": channelUpdate,
+				"metadata":  string(metadata),
+			}
+			if err := handler.Handle(event); err != nil {
+				logger.Warn(fmt.Sprintf("Failed to handle channel %s: %s", ch.ID, err))
+			}
+		}
+		pm.Offset += uint64(len(page.Channels))
+		if len(page.Channels) == 0 || pm.Offset >= page.Total {
+			return nil
+		}
+	}
+}
+
+// Subscriber keeps the handler, such as the schemas cache, up to date using
+// the things events.
+type Subscriber struct {
+	client  *redis.Client
+	handler Handler
+	logger  logger.Logger
+	lastID  string
+}
+
+// NewSubscriber returns the subscriber reading the things events stream
+// from the beginning.
+func NewSubscriber(client *redis.Client, handler Handler, logger logger.Logger) *Subscriber {
+	return &Subscriber{
+		client:  client,
+		handler: handler,
+		logger:  logger,
+		lastID:  "0",
+	}
+}
+
+// Load replays the events stored in the stream, so the handler is up to
+// date when the consumer starts receiving messages.
+//
+// Events are read without a consumer group, since every consumer instance
+// needs all of them.
+func (s *Subscriber) Load(ctx context.Context) error {
+	for {
+		n, err := s.read(ctx, -1)
+		if err != nil {
+			return err
+		}
+		if n < batch {
+			return nil
+		}
+	}
+}
+
+// Seed seeds the handler with the channels listed by the lister, see Seed,
+// and makes the subscriber handle only the events added to the stream after
+// the seeding began. Unlike Load, the seeding doesn't depend on the stream
+// holding every event since the channels were created.
+func (s *Subscriber) Seed(ctx context.Context, lister Lister, key string) error {
+	msgs, err := s.client.XRevRangeN(ctx, stream, "+", "-", 1).Result()
+	if err != nil {
+		return err
+	}
+	if len(msgs) > 0 {
+		s.lastID = msgs[0].ID
+	}
+
+	return Seed(ctx, lister, key, s.handler, s.logger)
+}
+
+// Subscribe handles the new events until the context is canceled.
+func (s *Subscriber) Subscribe(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := s.read(ctx, block); err != nil {
+			s.logger.Warn(fmt.Sprintf("Failed to read things events: %s", err))
+			time.Sleep(block)
+		}
+	}
+}
+
+// read handles the next batch of events, blocking for the given duration if
+// there are none. Negative duration doesn't block.
+func (s *Subscriber) read(ctx context.Context, d time.Duration) (int, error) {
+	streams, err := s.client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{stream, s.lastID},
+		Count:   batch,
+		Block:   d,
+	}).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil || len(streams) == 0 {
+		return 0, err
+	}
+
+	msgs := streams[0].Messages
+	for _, msg := range msgs {
+		if err := s.handler.Handle(msg.Values); err != nil {
+			s.logger.Warn(fmt.Sprintf("Failed to handle things event %s: %s", msg.ID, err))
+		}
+		s.lastID = msg.ID
+	}
+	return len(msgs), nil
+}
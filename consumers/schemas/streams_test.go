@@ -0,0 +1,90 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package schemas_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers/schemas"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errList = errors.New("list failed")
+
+// lister pages the channels like the things service does.
+type lister struct {
+	channels []things.Channel
+	err      error
+}
+
+func (l lister) ListChannelsByMetadataKey(_ context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	if l.err != nil {
+		return things.ChannelsPage{}, l.err
+	}
+
+	page := things.ChannelsPage{PageMetadata: things.PageMetadata{Total: uint64(len(l.channels))}}
+	if pm.Offset >= page.Total {
+		return page, nil
+	}
+	end := pm.Offset + pm.Limit
+	if end > page.Total {
+		end = page.Total
+	}
+	page.Channels = l.channels[pm.Offset:end]
+	return page, nil
+}
+
+func TestSeed(t *testing.T) {
+	log, err := logger.New(ioutil.Discard, "info")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	var meta things.Metadata
+	err = json.Unmarshal([]byte(metadata), &meta)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// More channels than the page, so the seeding pages through them.
+	var chs []things.Channel
+	for i := 0; i < 150; i++ {
+		chs = append(chs, things.Channel{ID: fmt.Sprintf("chan-%d", i), Metadata: meta})
+	}
+	invalid := things.Channel{ID: "invalid", Metadata: things.Metadata{things.SchemaKey: map[string]interface{}{"type": "float"}}}
+	chs = append(chs, invalid)
+
+	cases := []struct {
+		desc     string
+		lister   lister
+		channels []string
+		err      error
+	}{
+		{
+			desc:     "seed channel schemas",
+			lister:   lister{channels: chs},
+			channels: []string{chs[0].ID, chs[99].ID, chs[149].ID},
+			err:      nil,
+		},
+		{
+			desc:   "seed channel schemas with failed listing",
+			lister: lister{err: errList},
+			err:    errList,
+		},
+	}
+
+	for _, tc := range cases {
+		cache := schemas.NewCache()
+		err := schemas.Seed(context.Background(), tc.lister, things.SchemaKey, cache, log)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		for _, ch := range tc.channels {
+			assert.NotNil(t, cache.Schema(ch), fmt.Sprintf("%s: expected schema of channel %s", tc.desc, ch))
+		}
+		assert.Nil(t, cache.Schema(invalid.ID), fmt.Sprintf("%s: expected no schema of invalid channel", tc.desc))
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+const (
+	defBatchSize     = 1000
+	defFlushInterval = 10 * time.Second
+)
+
+// AggregatorConfig represents the counters batching settings.
+type AggregatorConfig struct {
+	// BatchSize is the number of counted messages triggering the flush.
+	BatchSize int
+
+	// FlushInterval is the longest period the counted messages are kept
+	// before they're flushed, regardless of the batch size.
+	FlushInterval time.Duration
+}
+
+type countKey struct {
+	channel string
+	day     int64
+}
+
+var _ consumers.Consumer = (*aggregator)(nil)
+
+type aggregator struct {
+	ctx    context.Context
+	cfg    AggregatorConfig
+	repo   Repository
+	logger logger.Logger
+
+	// flushMu keeps a single flush in progress, so the failed flush can
+	// return its counts to the buffer.
+	flushMu sync.Mutex
+
+	mu       sync.Mutex
+	counts   map[countKey]uint64
+	messages int
+}
+
+// NewAggregator returns the consumer counting messages per channel and day.
+// The counts are kept in memory and added to the repository when the batch is
+// full or periodically, until the context is canceled, when the remaining
+// counts are flushed. Counts failed to be flushed are kept for the next
+// flush.
+func NewAggregator(ctx context.Context, cfg AggregatorConfig, repo Repository, logger logger.Logger) consumers.Consumer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defFlushInterval
+	}
+	a := &aggregator{
+		ctx:    ctx,
+		cfg:    cfg,
+		repo:   repo,
+		logger: logger,
+		counts: make(map[countKey]uint64),
+	}
+
+	go a.flushLoop()
+	return a
+}
+
+func (a *aggregator) Consume(message interface{}) error {
+	msg, ok := message.(messaging.Message)
+	if !ok {
+		return ErrMessage
+	}
+
+	// The message is counted by the day it was received by the adapter.
+	created := time.Unix(0, msg.Created)
+	if msg.Created == 0 {
+		created = time.Now()
+	}
+	key := countKey{
+		channel: msg.Channel,
+		day:     created.UTC().Truncate(Day).Unix(),
+	}
+
+	a.mu.Lock()
+	a.counts[key]++
+	a.messages++
+	full := a.messages >= a.cfg.BatchSize
+	a.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	return a.flush(a.ctx)
+}
+
+func (a *aggregator) flushLoop() {
+	ticker := time.NewTicker(a.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			if err := a.flush(context.Background()); err != nil {
+				a.logger.Warn(fmt.Sprintf("Failed to flush remaining usage counts: %s", err))
+			}
+			return
+		case <-ticker.C:
+			if err := a.flush(a.ctx); err != nil {
+				a.logger.Warn(fmt.Sprintf("Failed to flush usage counts: %s", err))
+			}
+		}
+	}
+}
+
+func (a *aggregator) flush(ctx context.Context) error {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	a.mu.Lock()
+	pending := a.counts
+	a.counts = make(map[countKey]uint64)
+	a.messages = 0
+	a.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	counts := make([]Count, 0, len(pending))
+	for k, n := range pending {
+		counts = append(counts, Count{
+			Channel:  k.channel,
+			Day:      time.Unix(k.day, 0).UTC(),
+			Messages: n,
+		})
+	}
+	if err := a.repo.Add(ctx, counts); err != nil {
+		// The batch size isn't restored, so the repository outage doesn't
+		// trigger a flush per message.
+		a.mu.Lock()
+		for k, n := range pending {
+			a.counts[k] += n
+		}
+		a.mu.Unlock()
+		return errors.Wrap(ErrSave, err)
+	}
+	return nil
+}
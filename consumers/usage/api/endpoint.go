@@ -0,0 +1,48 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux/consumers/usage"
+)
+
+func usageEndpoint(svc usage.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(usageReq)
+		if err := req.validate(); err != nil {
+			return usageRes{}, err
+		}
+
+		q := usage.Query{
+			From:    req.from,
+			To:      req.to,
+			Channel: req.channel,
+		}
+		r, err := svc.Usage(ctx, req.key, q)
+		if err != nil {
+			return usageRes{}, err
+		}
+
+		res := usageRes{
+			From:     r.From.Format(dayFormat),
+			To:       r.To.Format(dayFormat),
+			Total:    r.Total,
+			Channels: []channelRes{},
+		}
+		for _, ch := range r.Channels {
+			cr := channelRes{
+				Channel: ch.Channel,
+				Total:   ch.Total,
+			}
+			for _, d := range ch.Days {
+				cr.Days = append(cr.Days, dayRes{Day: d.Day.Format(dayFormat), Messages: d.Messages})
+			}
+			res.Channels = append(res.Channels, cr)
+		}
+		return res, nil
+	}
+}
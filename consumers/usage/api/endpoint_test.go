@@ -0,0 +1,249 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/usage"
+	httpapi "github.com/mainflux/mainflux/consumers/usage/api"
+	"github.com/mainflux/mainflux/consumers/usage/mocks"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	adminKey    = "admin"
+	contentType = "application/json"
+	csvType     = "text/csv"
+)
+
+var (
+	day  = time.Date(2021, 5, 31, 0, 0, 0, 0, time.UTC)
+	next = day.Add(usage.Day)
+
+	unauthRes  = toJSON(errorRes{usage.ErrUnauthorizedAccess.Error()})
+	rangeRes   = toJSON(errorRes{usage.ErrInvalidRange.Error()})
+	invalidRes = toJSON(errorRes{errors.ErrInvalidQueryParams.Error()})
+)
+
+type errorRes struct {
+	Err string `json:"error"`
+}
+
+type dayRes struct {
+	Day      string `json:"day"`
+	Messages uint64 `json:"messages"`
+}
+
+type channelRes struct {
+	Channel string   `json:"channel"`
+	Total   uint64   `json:"total"`
+	Days    []dayRes `json:"days"`
+}
+
+type usageRes struct {
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Total    uint64       `json:"total"`
+	Channels []channelRes `json:"channels"`
+}
+
+func toJSON(data interface{}) string {
+	jsonData, _ := json.Marshal(data)
+	return string(jsonData)
+}
+
+func newServer(t *testing.T) *httptest.Server {
+	repo := mocks.NewRepository()
+	err := repo.Add(context.Background(), []usage.Count{
+		{Channel: "1", Day: day, Messages: 10},
+		{Channel: "1", Day: next, Messages: 20},
+		{Channel: "2", Day: next, Messages: 5},
+	})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	svc := usage.New(adminKey, repo)
+	return httptest.NewServer(httpapi.MakeHandler(svc, mocktracer.New()))
+}
+
+func get(client *http.Client, url, key string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		req.Header.Set("Authorization", key)
+	}
+	return client.Do(req)
+}
+
+func TestUsage(t *testing.T) {
+	ts := newServer(t)
+	defer ts.Close()
+
+	all := usageRes{
+		From:  "2021-05-31",
+		To:    "2021-06-01",
+		Total: 35,
+		Channels: []channelRes{
+			{
+				Channel: "1",
+				Total:   30,
+				Days:    []dayRes{{Day: "2021-05-31", Messages: 10}, {Day: "2021-06-01", Messages: 20}},
+			},
+			{
+				Channel: "2",
+				Total:   5,
+				Days:    []dayRes{{Day: "2021-06-01", Messages: 5}},
+			},
+		},
+	}
+	channel := usageRes{
+		From:  "2021-06-01",
+		To:    "2021-06-01",
+		Total: 5,
+		Channels: []channelRes{
+			{
+				Channel: "2",
+				Total:   5,
+				Days:    []dayRes{{Day: "2021-06-01", Messages: 5}},
+			},
+		},
+	}
+	empty := usageRes{
+		From:     "2021-01-01",
+		To:       "2021-01-31",
+		Channels: []channelRes{},
+	}
+
+	cases := []struct {
+		desc   string
+		url    string
+		key    string
+		status int
+		res    string
+	}{
+		{
+			desc:   "get usage of all channels",
+			url:    fmt.Sprintf("%s/usage?from=2021-05-31&to=2021-06-01", ts.URL),
+			key:    adminKey,
+			status: http.StatusOK,
+			res:    toJSON(all),
+		},
+		{
+			desc:   "get usage of the channel",
+			url:    fmt.Sprintf("%s/usage?from=2021-06-01&to=2021-06-01&channel=2", ts.URL),
+			key:    adminKey,
+			status: http.StatusOK,
+			res:    toJSON(channel),
+		},
+		{
+			desc:   "get usage without traffic",
+			url:    fmt.Sprintf("%s/usage?from=2021-01-01&to=2021-01-31", ts.URL),
+			key:    adminKey,
+			status: http.StatusOK,
+			res:    toJSON(empty),
+		},
+		{
+			desc:   "get usage with invalid range",
+			url:    fmt.Sprintf("%s/usage?from=2021-06-01&to=2021-05-31", ts.URL),
+			key:    adminKey,
+			status: http.StatusBadRequest,
+			res:    rangeRes,
+		},
+		{
+			desc:   "get usage with invalid day",
+			url:    fmt.Sprintf("%s/usage?from=31.05.2021", ts.URL),
+			key:    adminKey,
+			status: http.StatusBadRequest,
+			res:    invalidRes,
+		},
+		{
+			desc:   "get usage with invalid key",
+			url:    fmt.Sprintf("%s/usage?from=2021-05-31&to=2021-06-01", ts.URL),
+			key:    "invalid",
+			status: http.StatusUnauthorized,
+			res:    unauthRes,
+		},
+		{
+			desc:   "get usage without key",
+			url:    fmt.Sprintf("%s/usage?from=2021-05-31&to=2021-06-01", ts.URL),
+			key:    "",
+			status: http.StatusUnauthorized,
+			res:    unauthRes,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := get(ts.Client(), tc.url, tc.key)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body, err := ioutil.ReadAll(res.Body)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		res.Body.Close()
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, contentType, res.Header.Get("Content-Type"), fmt.Sprintf("%s: expected content type %s got %s", tc.desc, contentType, res.Header.Get("Content-Type")))
+		assert.Equal(t, tc.res, strings.TrimSpace(string(body)), fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, body))
+	}
+}
+
+func TestExportUsage(t *testing.T) {
+	ts := newServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		desc        string
+		url         string
+		key         string
+		status      int
+		contentType string
+		res         string
+	}{
+		{
+			desc:        "export usage of all channels",
+			url:         fmt.Sprintf("%s/usage/export?from=2021-05-31&to=2021-06-01", ts.URL),
+			key:         adminKey,
+			status:      http.StatusOK,
+			contentType: csvType,
+			res:         "channel,day,messages\n1,2021-05-31,10\n1,2021-06-01,20\n2,2021-06-01,5\n",
+		},
+		{
+			desc:        "export usage of the channel",
+			url:         fmt.Sprintf("%s/usage/export?from=2021-05-31&to=2021-06-01&channel=1", ts.URL),
+			key:         adminKey,
+			status:      http.StatusOK,
+			contentType: csvType,
+			res:         "channel,day,messages\n1,2021-05-31,10\n1,2021-06-01,20\n",
+		},
+		{
+			desc:        "export usage with invalid key",
+			url:         fmt.Sprintf("%s/usage/export?from=2021-05-31&to=2021-06-01", ts.URL),
+			key:         "invalid",
+			status:      http.StatusUnauthorized,
+			contentType: contentType,
+			res:         unauthRes + "\n",
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := get(ts.Client(), tc.url, tc.key)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body, err := ioutil.ReadAll(res.Body)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		res.Body.Close()
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.contentType, res.Header.Get("Content-Type"), fmt.Sprintf("%s: expected content type %s got %s", tc.desc, tc.contentType, res.Header.Get("Content-Type")))
+		assert.Equal(t, tc.res, string(body), fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, body))
+	}
+}
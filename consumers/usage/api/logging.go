@@ -0,0 +1,38 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/usage"
+	log "github.com/mainflux/mainflux/logger"
+)
+
+var _ usage.Service = (*loggingMiddleware)(nil)
+
+type loggingMiddleware struct {
+	logger log.Logger
+	svc    usage.Service
+}
+
+// LoggingMiddleware adds logging facilities to the core service.
+func LoggingMiddleware(svc usage.Service, logger log.Logger) usage.Service {
+	return &loggingMiddleware{logger, svc}
+}
+
+func (lm *loggingMiddleware) Usage(ctx context.Context, key string, q usage.Query) (r usage.Report, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method usage from %s to %s for channel %q took %s to complete", q.From.Format(dayFormat), q.To.Format(dayFormat), q.Channel, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.Usage(ctx, key, q)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/consumers/usage"
+)
+
+var _ usage.Service = (*metricsMiddleware)(nil)
+
+type metricsMiddleware struct {
+	counter metrics.Counter
+	latency metrics.Histogram
+	svc     usage.Service
+}
+
+// MetricsMiddleware instruments core service by tracking request count and latency.
+func MetricsMiddleware(svc usage.Service, counter metrics.Counter, latency metrics.Histogram) usage.Service {
+	return &metricsMiddleware{
+		counter: counter,
+		latency: latency,
+		svc:     svc,
+	}
+}
+
+func (ms *metricsMiddleware) Usage(ctx context.Context, key string, q usage.Query) (usage.Report, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "usage").Add(1)
+		ms.latency.With("method", "usage").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.Usage(ctx, key, q)
+}
@@ -0,0 +1,27 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/usage"
+)
+
+type usageReq struct {
+	key     string
+	from    time.Time
+	to      time.Time
+	channel string
+}
+
+func (req usageReq) validate() error {
+	if req.key == "" {
+		return usage.ErrUnauthorizedAccess
+	}
+	if req.from.After(req.to) {
+		return usage.ErrInvalidRange
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/mainflux/mainflux"
+)
+
+var _ mainflux.Response = (*usageRes)(nil)
+
+type dayRes struct {
+	Day      string `json:"day"`
+	Messages uint64 `json:"messages"`
+}
+
+type channelRes struct {
+	Channel string   `json:"channel"`
+	Total   uint64   `json:"total"`
+	Days    []dayRes `json:"days"`
+}
+
+type usageRes struct {
+	From     string       `json:"from"`
+	To       string       `json:"to"`
+	Total    uint64       `json:"total"`
+	Channels []channelRes `json:"channels"`
+}
+
+func (res usageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res usageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res usageRes) Empty() bool {
+	return false
+}
+
+type errorRes struct {
+	Err string `json:"error"`
+}
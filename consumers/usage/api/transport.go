@@ -0,0 +1,163 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	kitot "github.com/go-kit/kit/tracing/opentracing"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"github.com/go-zoo/bone"
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/consumers/usage"
+	"github.com/mainflux/mainflux/internal/httputil"
+	"github.com/mainflux/mainflux/pkg/errors"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	contentType    = "application/json"
+	csvContentType = "text/csv"
+	dayFormat      = "2006-01-02"
+	fromKey        = "from"
+	toKey          = "to"
+	channelKey     = "channel"
+	defRange       = 30
+)
+
+// MakeHandler returns a HTTP handler for API endpoints.
+func MakeHandler(svc usage.Service, tracer opentracing.Tracer) http.Handler {
+	opts := []kithttp.ServerOption{
+		kithttp.ServerErrorEncoder(encodeError),
+	}
+
+	mux := bone.New()
+
+	mux.Get("/usage", kithttp.NewServer(
+		kitot.TraceServer(tracer, "usage")(usageEndpoint(svc)),
+		decodeUsage,
+		encodeResponse,
+		opts...,
+	))
+
+	mux.Get("/usage/export", kithttp.NewServer(
+		kitot.TraceServer(tracer, "export_usage")(usageEndpoint(svc)),
+		decodeUsage,
+		encodeCSV,
+		opts...,
+	))
+
+	mux.GetFunc("/version", mainflux.Version("usage"))
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return mux
+}
+
+// decodeUsage reads the report range, which defaults to the last 30 days
+// including today.
+func decodeUsage(_ context.Context, r *http.Request) (interface{}, error) {
+	today := time.Now().UTC().Truncate(usage.Day)
+	to, err := readDayQuery(r, toKey, today)
+	if err != nil {
+		return nil, err
+	}
+	from, err := readDayQuery(r, fromKey, to.AddDate(0, 0, 1-defRange))
+	if err != nil {
+		return nil, err
+	}
+	channel, err := httputil.ReadStringQuery(r, channelKey, "")
+	if err != nil {
+		return nil, err
+	}
+
+	req := usageReq{
+		key:     r.Header.Get("Authorization"),
+		from:    from,
+		to:      to,
+		channel: channel,
+	}
+	return req, nil
+}
+
+func readDayQuery(r *http.Request, key string, def time.Time) (time.Time, error) {
+	val, err := httputil.ReadStringQuery(r, key, "")
+	if err != nil {
+		return time.Time{}, err
+	}
+	if val == "" {
+		return def, nil
+	}
+	day, err := time.Parse(dayFormat, val)
+	if err != nil {
+		return time.Time{}, errors.Wrap(errors.ErrInvalidQueryParams, err)
+	}
+	return day, nil
+}
+
+func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	if ar, ok := response.(mainflux.Response); ok {
+		for k, v := range ar.Headers() {
+			w.Header().Set(k, v)
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(ar.Code())
+
+		if ar.Empty() {
+			return nil
+		}
+	}
+
+	return json.NewEncoder(w).Encode(response)
+}
+
+// encodeCSV writes the report as a row per channel and day.
+func encodeCSV(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(usageRes)
+	w.Header().Set("Content-Type", csvContentType)
+	w.Header().Set("Content-Disposition", "attachment; filename=\"usage_"+res.From+"_"+res.To+".csv\"")
+	w.WriteHeader(http.StatusOK)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"channel", "day", "messages"}); err != nil {
+		return err
+	}
+	for _, ch := range res.Channels {
+		for _, d := range ch.Days {
+			if err := cw.Write([]string{ch.Channel, d.Day, strconv.FormatUint(d.Messages, 10)}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func encodeError(_ context.Context, err error, w http.ResponseWriter) {
+	switch errorVal := err.(type) {
+	case errors.Error:
+		w.Header().Set("Content-Type", contentType)
+		switch {
+		case errors.Contains(errorVal, usage.ErrUnauthorizedAccess):
+			w.WriteHeader(http.StatusUnauthorized)
+		case errors.Contains(errorVal, usage.ErrInvalidRange),
+			errors.Contains(errorVal, errors.ErrInvalidQueryParams):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		if errorVal.Msg() != "" {
+			if err := json.NewEncoder(w).Encode(errorRes{Err: errorVal.Msg()}); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,7 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package usage contains the domain concept definitions needed to support
+// Mainflux usage service functionality. The service counts the messages
+// published to each channel per day and reports them to the platform admin.
+package usage
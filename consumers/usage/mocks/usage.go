@@ -0,0 +1,97 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/usage"
+)
+
+var _ usage.Repository = (*usageRepositoryMock)(nil)
+
+type usageRepositoryMock struct {
+	mu     sync.Mutex
+	counts map[string]map[int64]uint64
+	adds   int
+	err    error
+}
+
+// Repository is the usage repository mock exposing the number of Add calls.
+type Repository interface {
+	usage.Repository
+
+	// Adds returns the number of Add calls.
+	Adds() int
+
+	// Fail makes the subsequent Add calls fail with the error, or succeed if
+	// the error is nil.
+	Fail(err error)
+}
+
+// NewRepository returns the in-memory usage repository.
+func NewRepository() Repository {
+	return &usageRepositoryMock{
+		counts: make(map[string]map[int64]uint64),
+	}
+}
+
+func (repo *usageRepositoryMock) Add(_ context.Context, counts []usage.Count) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if repo.err != nil {
+		return repo.err
+	}
+	repo.adds++
+	for _, c := range counts {
+		days, ok := repo.counts[c.Channel]
+		if !ok {
+			days = make(map[int64]uint64)
+			repo.counts[c.Channel] = days
+		}
+		days[c.Day.Unix()] += c.Messages
+	}
+	return nil
+}
+
+func (repo *usageRepositoryMock) Retrieve(_ context.Context, q usage.Query) ([]usage.Count, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	var counts []usage.Count
+	for ch, days := range repo.counts {
+		if q.Channel != "" && ch != q.Channel {
+			continue
+		}
+		for day, n := range days {
+			if day < q.From.Unix() || day > q.To.Unix() {
+				continue
+			}
+			counts = append(counts, usage.Count{Channel: ch, Day: time.Unix(day, 0).UTC(), Messages: n})
+		}
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Channel != counts[j].Channel {
+			return counts[i].Channel < counts[j].Channel
+		}
+		return counts[i].Day.Before(counts[j].Day)
+	})
+	return counts, nil
+}
+
+func (repo *usageRepositoryMock) Adds() int {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	return repo.adds
+}
+
+func (repo *usageRepositoryMock) Fail(err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	repo.err = err
+}
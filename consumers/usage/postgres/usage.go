@@ -0,0 +1,102 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mainflux/mainflux/consumers/usage"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const dayFormat = "2006-01-02"
+
+var _ usage.Repository = (*usageRepo)(nil)
+
+type usageRepo struct {
+	db *sqlx.DB
+}
+
+// New instantiates a PostgreSQL implementation of usage repository.
+func New(db *sqlx.DB) usage.Repository {
+	return &usageRepo{
+		db: db,
+	}
+}
+
+func (repo usageRepo) Add(ctx context.Context, counts []usage.Count) (err error) {
+	q := `INSERT INTO daily_usage (channel, day, messages) VALUES (:channel, :day, :messages)
+		ON CONFLICT (channel, day) DO UPDATE SET messages = daily_usage.messages + EXCLUDED.messages`
+
+	tx, err := repo.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(usage.ErrSave, err)
+	}
+	defer func() {
+		if err != nil {
+			if txErr := tx.Rollback(); txErr != nil {
+				err = errors.Wrap(err, txErr)
+			}
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			err = errors.Wrap(usage.ErrSave, err)
+		}
+	}()
+
+	for _, c := range counts {
+		dbc := dbCount{
+			Channel:  c.Channel,
+			Day:      c.Day.UTC().Format(dayFormat),
+			Messages: int64(c.Messages),
+		}
+		if _, err := tx.NamedExecContext(ctx, q, dbc); err != nil {
+			return errors.Wrap(usage.ErrSave, err)
+		}
+	}
+	return nil
+}
+
+func (repo usageRepo) Retrieve(ctx context.Context, q usage.Query) ([]usage.Count, error) {
+	query := `SELECT channel, to_char(day, 'YYYY-MM-DD') AS day, messages FROM daily_usage
+		WHERE day >= :from AND day <= :to AND (:channel = '' OR channel = :channel)
+		ORDER BY channel, day`
+
+	params := map[string]interface{}{
+		"from":    q.From.UTC().Format(dayFormat),
+		"to":      q.To.UTC().Format(dayFormat),
+		"channel": q.Channel,
+	}
+	rows, err := repo.db.NamedQueryContext(ctx, query, params)
+	if err != nil {
+		return nil, errors.Wrap(usage.ErrRetrieve, err)
+	}
+	defer rows.Close()
+
+	var counts []usage.Count
+	for rows.Next() {
+		var dbc dbCount
+		if err := rows.StructScan(&dbc); err != nil {
+			return nil, errors.Wrap(usage.ErrRetrieve, err)
+		}
+		day, err := time.Parse(dayFormat, dbc.Day)
+		if err != nil {
+			return nil, errors.Wrap(usage.ErrRetrieve, err)
+		}
+		counts = append(counts, usage.Count{
+			Channel:  dbc.Channel,
+			Day:      day,
+			Messages: uint64(dbc.Messages),
+		})
+	}
+	return counts, nil
+}
+
+type dbCount struct {
+	Channel  string `db:"channel"`
+	Day      string `db:"day"`
+	Messages int64  `db:"messages"`
+}
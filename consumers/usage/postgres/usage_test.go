@@ -0,0 +1,79 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/usage"
+	"github.com/mainflux/mainflux/consumers/usage/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	repo := postgres.New(db)
+	day := time.Date(2021, 5, 31, 0, 0, 0, 0, time.UTC)
+	next := day.Add(usage.Day)
+
+	batches := [][]usage.Count{
+		{
+			{Channel: "1", Day: day, Messages: 10},
+			{Channel: "2", Day: day, Messages: 5},
+		},
+		{
+			{Channel: "1", Day: day, Messages: 3},
+			{Channel: "1", Day: next, Messages: 7},
+		},
+	}
+	for _, b := range batches {
+		err := repo.Add(context.Background(), b)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc   string
+		query  usage.Query
+		counts []usage.Count
+	}{
+		{
+			desc:  "retrieve usage of all channels",
+			query: usage.Query{From: day, To: next},
+			counts: []usage.Count{
+				{Channel: "1", Day: day, Messages: 13},
+				{Channel: "1", Day: next, Messages: 7},
+				{Channel: "2", Day: day, Messages: 5},
+			},
+		},
+		{
+			desc:  "retrieve usage of the channel",
+			query: usage.Query{From: day, To: next, Channel: "1"},
+			counts: []usage.Count{
+				{Channel: "1", Day: day, Messages: 13},
+				{Channel: "1", Day: next, Messages: 7},
+			},
+		},
+		{
+			desc:  "retrieve usage of the day",
+			query: usage.Query{From: next, To: next},
+			counts: []usage.Count{
+				{Channel: "1", Day: next, Messages: 7},
+			},
+		},
+		{
+			desc:   "retrieve usage out of range",
+			query:  usage.Query{From: next.Add(usage.Day), To: next.Add(2 * usage.Day)},
+			counts: nil,
+		},
+	}
+
+	for _, tc := range cases {
+		counts, err := repo.Retrieve(context.Background(), tc.query)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.counts, counts, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.counts, counts))
+	}
+}
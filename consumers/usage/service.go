@@ -0,0 +1,80 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package usage
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var (
+	// ErrUnauthorizedAccess indicates missing or invalid credentials provided
+	// when accessing a protected resource.
+	ErrUnauthorizedAccess = errors.New("missing or invalid credentials provided")
+
+	// ErrInvalidRange indicates the report starting after it ends.
+	ErrInvalidRange = errors.New("invalid usage report range")
+
+	// ErrRetrieve indicates failure to retrieve the usage counters.
+	ErrRetrieve = errors.New("failed to retrieve usage")
+
+	// ErrSave indicates failure to save the usage counters.
+	ErrSave = errors.New("failed to save usage")
+
+	// ErrMessage indicates an error converting a message to Mainflux message.
+	ErrMessage = errors.New("failed to convert to Mainflux message")
+)
+
+// Service specifies usage service API.
+type Service interface {
+	// Usage returns the report of the daily message counts. Only the admin
+	// key is accepted.
+	Usage(ctx context.Context, key string, q Query) (Report, error)
+}
+
+var _ Service = (*usageService)(nil)
+
+type usageService struct {
+	adminKey string
+	repo     Repository
+}
+
+// New instantiates the usage service implementation. Empty admin key denies
+// all the requests.
+func New(adminKey string, repo Repository) Service {
+	return &usageService{
+		adminKey: adminKey,
+		repo:     repo,
+	}
+}
+
+func (us *usageService) Usage(ctx context.Context, key string, q Query) (Report, error) {
+	if us.adminKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(us.adminKey)) != 1 {
+		return Report{}, ErrUnauthorizedAccess
+	}
+
+	q.From, q.To = q.From.UTC().Truncate(Day), q.To.UTC().Truncate(Day)
+	if q.From.After(q.To) {
+		return Report{}, ErrInvalidRange
+	}
+
+	counts, err := us.repo.Retrieve(ctx, q)
+	if err != nil {
+		return Report{}, errors.Wrap(ErrRetrieve, err)
+	}
+
+	r := Report{From: q.From, To: q.To}
+	for _, c := range counts {
+		if n := len(r.Channels); n == 0 || r.Channels[n-1].Channel != c.Channel {
+			r.Channels = append(r.Channels, ChannelUsage{Channel: c.Channel})
+		}
+		ch := &r.Channels[len(r.Channels)-1]
+		ch.Days = append(ch.Days, DayUsage{Day: c.Day.UTC(), Messages: c.Messages})
+		ch.Total += c.Messages
+		r.Total += c.Messages
+	}
+	return r, nil
+}
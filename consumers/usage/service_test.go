@@ -0,0 +1,186 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package usage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/consumers/usage"
+	"github.com/mainflux/mainflux/consumers/usage/mocks"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const adminKey = "admin"
+
+var (
+	testLog, _ = log.New(os.Stdout, log.Info.String())
+	day        = time.Date(2021, 5, 31, 0, 0, 0, 0, time.UTC)
+	next       = day.Add(usage.Day)
+	midnight   = next.Add(-time.Minute)
+)
+
+// traffic returns n messages per channel published during the minute around
+// the midnight, the first half before it.
+func traffic(n int, channels ...string) []messaging.Message {
+	var msgs []messaging.Message
+	step := 2 * time.Minute / time.Duration(n)
+	for i := 0; i < n; i++ {
+		for _, ch := range channels {
+			msgs = append(msgs, messaging.Message{
+				Channel: ch,
+				Created: midnight.Add(time.Duration(i) * step).UnixNano(),
+			})
+		}
+	}
+	return msgs
+}
+
+func TestAggregator(t *testing.T) {
+	repo := mocks.NewRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	agg := usage.NewAggregator(ctx, usage.AggregatorConfig{BatchSize: 50, FlushInterval: time.Hour}, repo, testLog)
+
+	msgs := traffic(100, "1", "2")
+	for _, msg := range msgs {
+		err := agg.Consume(msg)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+	assert.Equal(t, len(msgs)/50, repo.Adds(), fmt.Sprintf("expected %d batched adds got %d", len(msgs)/50, repo.Adds()))
+
+	// The remaining counts are flushed when the context is canceled.
+	err := agg.Consume(messaging.Message{Channel: "1", Created: next.Add(time.Hour).UnixNano()})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	cancel()
+	assert.Eventually(t, func() bool {
+		return repo.Adds() == len(msgs)/50+1
+	}, time.Second, time.Millisecond, "expected remaining counts to be flushed")
+
+	counts, err := repo.Retrieve(context.Background(), usage.Query{From: day, To: next})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	expected := []usage.Count{
+		{Channel: "1", Day: day, Messages: 50},
+		{Channel: "1", Day: next, Messages: 51},
+		{Channel: "2", Day: day, Messages: 50},
+		{Channel: "2", Day: next, Messages: 50},
+	}
+	assert.Equal(t, expected, counts, fmt.Sprintf("expected daily counts %v got %v", expected, counts))
+}
+
+func TestAggregatorFailedFlush(t *testing.T) {
+	repo := mocks.NewRepository()
+	agg := usage.NewAggregator(context.Background(), usage.AggregatorConfig{BatchSize: 10, FlushInterval: time.Hour}, repo, testLog)
+
+	repo.Fail(errors.New("unavailable"))
+	msgs := traffic(10, "1")
+	for i, msg := range msgs {
+		err := agg.Consume(msg)
+		if i < len(msgs)-1 {
+			assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		}
+	}
+
+	// The failed counts are kept and flushed with the next batch.
+	repo.Fail(nil)
+	for _, msg := range traffic(10, "1") {
+		err := agg.Consume(msg)
+		assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+	counts, err := repo.Retrieve(context.Background(), usage.Query{From: day, To: next})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	expected := []usage.Count{
+		{Channel: "1", Day: day, Messages: 10},
+		{Channel: "1", Day: next, Messages: 10},
+	}
+	assert.Equal(t, expected, counts, fmt.Sprintf("expected daily counts %v got %v", expected, counts))
+}
+
+func TestUsage(t *testing.T) {
+	repo := mocks.NewRepository()
+	err := repo.Add(context.Background(), []usage.Count{
+		{Channel: "1", Day: day, Messages: 10},
+		{Channel: "1", Day: next, Messages: 20},
+		{Channel: "2", Day: next, Messages: 5},
+	})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	svc := usage.New(adminKey, repo)
+
+	cases := []struct {
+		desc   string
+		key    string
+		query  usage.Query
+		report usage.Report
+		err    error
+	}{
+		{
+			desc:  "usage of all channels",
+			key:   adminKey,
+			query: usage.Query{From: day, To: next},
+			report: usage.Report{
+				From:  day,
+				To:    next,
+				Total: 35,
+				Channels: []usage.ChannelUsage{
+					{
+						Channel: "1",
+						Total:   30,
+						Days:    []usage.DayUsage{{Day: day, Messages: 10}, {Day: next, Messages: 20}},
+					},
+					{
+						Channel: "2",
+						Total:   5,
+						Days:    []usage.DayUsage{{Day: next, Messages: 5}},
+					},
+				},
+			},
+		},
+		{
+			desc:  "usage of the channel on the day",
+			key:   adminKey,
+			query: usage.Query{From: midnight, To: midnight, Channel: "1"},
+			report: usage.Report{
+				From:  day,
+				To:    day,
+				Total: 10,
+				Channels: []usage.ChannelUsage{
+					{
+						Channel: "1",
+						Total:   10,
+						Days:    []usage.DayUsage{{Day: day, Messages: 10}},
+					},
+				},
+			},
+		},
+		{
+			desc:   "usage with invalid range",
+			key:    adminKey,
+			query:  usage.Query{From: next, To: day},
+			report: usage.Report{},
+			err:    usage.ErrInvalidRange,
+		},
+		{
+			desc:   "usage with invalid key",
+			key:    "invalid",
+			query:  usage.Query{From: day, To: next},
+			report: usage.Report{},
+			err:    usage.ErrUnauthorizedAccess,
+		},
+	}
+
+	for _, tc := range cases {
+		r, err := svc.Usage(context.Background(), tc.key, tc.query)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.report, r, fmt.Sprintf("%s: expected report %v got %v", tc.desc, tc.report, r))
+	}
+
+	_, err = usage.New("", repo).Usage(context.Background(), "", usage.Query{From: day, To: next})
+	assert.Equal(t, usage.ErrUnauthorizedAccess, err, fmt.Sprintf("usage without admin key: expected error %s got %s", usage.ErrUnauthorizedAccess, err))
+}
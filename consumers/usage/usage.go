@@ -0,0 +1,61 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package usage
+
+import (
+	"context"
+	"time"
+)
+
+// Day is the length of the usage counting period. Days are in UTC.
+const Day = 24 * time.Hour
+
+// Count represents the number of messages published to the channel during
+// the day.
+type Count struct {
+	Channel  string
+	Day      time.Time
+	Messages uint64
+}
+
+// Query represents the usage report filter.
+type Query struct {
+	// From and To are the first and the last day of the report.
+	From time.Time
+	To   time.Time
+
+	// Channel limits the report to the single channel, if it's set.
+	Channel string
+}
+
+// DayUsage represents the number of messages published during the day.
+type DayUsage struct {
+	Day      time.Time
+	Messages uint64
+}
+
+// ChannelUsage represents the channel daily usage.
+type ChannelUsage struct {
+	Channel string
+	Total   uint64
+	Days    []DayUsage
+}
+
+// Report represents the usage of the channels in the queried period.
+type Report struct {
+	From     time.Time
+	To       time.Time
+	Total    uint64
+	Channels []ChannelUsage
+}
+
+// Repository specifies the daily counters persistence API.
+type Repository interface {
+	// Add increments the counters by the counts.
+	Add(ctx context.Context, counts []Count) error
+
+	// Retrieve returns the counts matching the query, ordered by the
+	// channel and the day.
+	Retrieve(ctx context.Context, q Query) ([]Count, error)
+}
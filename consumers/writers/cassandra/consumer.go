@@ -14,9 +14,12 @@ import (
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 )
 
+const errUndefinedColumn = "Undefined column name tenant"
+
 var (
 	errSaveMessage = errors.New("failed to save message to cassandra database")
 	errNoTable     = errors.New("table does not exist")
+	errNoColumn    = errors.New("column does not exist")
 )
 var _ consumers.Consumer = (*cassandraRepository)(nil)
 
@@ -44,14 +47,14 @@ func (cr *cassandraRepository) saveSenml(messages interface{}) error {
 		return errSaveMessage
 	}
 	cql := `INSERT INTO messages (id, channel, subtopic, publisher, protocol,
-            name, unit, value, string_value, bool_value, data_value, sum,
-            time, update_time)
-            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+            tenant, name, unit, value, string_value, bool_value, data_value,
+            sum, time, update_time)
+            VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	id := gocql.TimeUUID()
 
 	for _, msg := range msgs {
 		err := cr.session.Query(cql, id, msg.Channel, msg.Subtopic, msg.Publisher,
-			msg.Protocol, msg.Name, msg.Unit, msg.Value, msg.StringValue,
+			msg.Protocol, msg.Tenant, msg.Name, msg.Unit, msg.Value, msg.StringValue,
 			msg.BoolValue, msg.DataValue, msg.Sum, msg.Time, msg.UpdateTime).Exec()
 		if err != nil {
 			return errors.Wrap(errSaveMessage, err)
@@ -63,7 +66,9 @@ func (cr *cassandraRepository) saveSenml(messages interface{}) error {
 
 func (cr *cassandraRepository) saveJSON(msgs mfjson.Messages) error {
 	if err := cr.insertJSON(msgs); err != nil {
-		if err == errNoTable {
+		// Tables created before the tenant column was introduced are
+		// migrated the same way the missing ones are created.
+		if err == errNoTable || err == errNoColumn {
 			if err := cr.createTable(msgs.Format); err != nil {
 				return err
 			}
@@ -80,15 +85,18 @@ func (cr *cassandraRepository) insertJSON(msgs mfjson.Messages) error {
 		if err != nil {
 			return err
 		}
-		cql := `INSERT INTO %s (id, channel, created, subtopic, publisher, protocol, payload) VALUES (?, ?, ?, ?, ?, ?, ?)`
+		cql := `INSERT INTO %s (id, channel, created, subtopic, publisher, protocol, tenant, payload) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
 		cql = fmt.Sprintf(cql, msgs.Format)
 		id := gocql.TimeUUID()
 
-		err = cr.session.Query(cql, id, msg.Channel, msg.Created, msg.Subtopic, msg.Publisher, msg.Protocol, string(pld)).Exec()
+		err = cr.session.Query(cql, id, msg.Channel, msg.Created, msg.Subtopic, msg.Publisher, msg.Protocol, msg.Tenant, string(pld)).Exec()
 		if err != nil {
 			if err.Error() == fmt.Sprintf("unconfigured table %s", msgs.Format) {
 				return errNoTable
 			}
+			if err.Error() == errUndefinedColumn {
+				return errNoColumn
+			}
 			return errors.Wrap(errSaveMessage, err)
 		}
 	}
@@ -97,5 +105,8 @@ func (cr *cassandraRepository) insertJSON(msgs mfjson.Messages) error {
 
 func (cr *cassandraRepository) createTable(name string) error {
 	q := fmt.Sprintf(jsonTable, name)
-	return cr.session.Query(q).Exec()
+	if err := cr.session.Query(q).Exec(); err != nil {
+		return err
+	}
+	return migrateTenant(cr.session, name)
 }
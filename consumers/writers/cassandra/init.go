@@ -3,15 +3,25 @@
 
 package cassandra
 
-import "github.com/gocql/gocql"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gocql/gocql"
+)
 
 const (
+	// errColumnExists is the message of the error returned when adding the
+	// column the table already contains.
+	errColumnExists = "conflicts with an existing column"
+
 	table = `CREATE TABLE IF NOT EXISTS messages (
         id uuid,
         channel text,
         subtopic text,
         publisher text,
         protocol text,
+        tenant text,
         name text,
         unit text,
         value double,
@@ -30,10 +40,14 @@ const (
         subtopic text,
         publisher text,
         protocol text,
+        tenant text,
         created bigint,
         payload text,
         PRIMARY KEY (channel, created, id)
     ) WITH CLUSTERING ORDER BY (created DESC)`
+
+	tenantColumn = `ALTER TABLE %s ADD tenant text`
+	tenantIndex  = `CREATE INDEX IF NOT EXISTS ON %s (tenant)`
 )
 
 // DBConfig contains Cassandra DB specific parameters.
@@ -64,6 +78,19 @@ func Connect(cfg DBConfig) (*gocql.Session, error) {
 	if err := session.Query(table).Exec(); err != nil {
 		return nil, err
 	}
+	if err := migrateTenant(session, "messages"); err != nil {
+		return nil, err
+	}
 
 	return session, nil
 }
+
+// migrateTenant adds the indexed tenant column to the tables created before
+// it was introduced.
+func migrateTenant(session *gocql.Session, name string) error {
+	err := session.Query(fmt.Sprintf(tenantColumn, name)).Exec()
+	if err != nil && !strings.Contains(err.Error(), errColumnExists) {
+		return err
+	}
+	return session.Query(fmt.Sprintf(tenantIndex, name)).Exec()
+}
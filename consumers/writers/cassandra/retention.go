@@ -0,0 +1,108 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cassandra
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const senmlTable = "messages"
+
+var errPurgeMessages = errors.New("failed to purge messages from cassandra database")
+
+var _ retention.Store = (*retentionStore)(nil)
+
+type retentionStore struct {
+	session  *gocql.Session
+	keyspace string
+}
+
+// NewRetentionStore returns the store removing the expired messages from the
+// SenML messages table and the JSON formats tables of the keyspace.
+func NewRetentionStore(session *gocql.Session, keyspace string) retention.Store {
+	return &retentionStore{
+		session:  session,
+		keyspace: keyspace,
+	}
+}
+
+func (rs *retentionStore) Purge(ctx context.Context, channel string, before time.Time) error {
+	tables, err := rs.tables(ctx)
+	if err != nil {
+		return errors.Wrap(errPurgeMessages, err)
+	}
+	for _, table := range tables {
+		if err := rs.purge(ctx, table, channel, before); err != nil {
+			return errors.Wrap(errPurgeMessages, err)
+		}
+	}
+	return nil
+}
+
+// PurgeExcept removes the messages partition by partition, since the range
+// deletion requires the channel.
+func (rs *retentionStore) PurgeExcept(ctx context.Context, channels []string, before time.Time) error {
+	except := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		except[ch] = true
+	}
+
+	tables, err := rs.tables(ctx)
+	if err != nil {
+		return errors.Wrap(errPurgeMessages, err)
+	}
+	for _, table := range tables {
+		iter := rs.session.Query(fmt.Sprintf(`SELECT DISTINCT channel FROM %s`, table)).WithContext(ctx).Iter()
+		var ch string
+		for iter.Scan(&ch) {
+			if except[ch] {
+				continue
+			}
+			if err := rs.purge(ctx, table, ch, before); err != nil {
+				iter.Close()
+				return errors.Wrap(errPurgeMessages, err)
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return errors.Wrap(errPurgeMessages, err)
+		}
+	}
+	return nil
+}
+
+// purge removes the channel messages from the table. The SenML time is in
+// seconds, while the JSON creation time is in nanoseconds.
+func (rs *retentionStore) purge(ctx context.Context, table, channel string, before time.Time) error {
+	if table == senmlTable {
+		cql := `DELETE FROM messages WHERE channel = ? AND time < ?`
+		return rs.session.Query(cql, channel, float64(before.UnixNano())/1e9).WithContext(ctx).Exec()
+	}
+	cql := fmt.Sprintf(`DELETE FROM %s WHERE channel = ? AND created < ?`, table)
+	return rs.session.Query(cql, channel, before.UnixNano()).WithContext(ctx).Exec()
+}
+
+// tables returns the SenML messages table and the JSON formats tables,
+// recognized by the payload column.
+func (rs *retentionStore) tables(ctx context.Context) ([]string, error) {
+	cql := `SELECT table_name, column_name FROM system_schema.columns WHERE keyspace_name = ?`
+	iter := rs.session.Query(cql, rs.keyspace).WithContext(ctx).Iter()
+
+	tables := []string{senmlTable}
+	var table, column string
+	for iter.Scan(&table, &column) {
+		if column == "payload" && table != senmlTable {
+			tables = append(tables, table)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package cassandra_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/mainflux/mainflux/consumers/writers/cassandra"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurge(t *testing.T) {
+	session, err := cassandra.Connect(cassandra.DBConfig{
+		Hosts:    []string{addr},
+		Keyspace: keyspace,
+	})
+	require.Nil(t, err, fmt.Sprintf("failed to connect to Cassandra: %s", err))
+	repo := cassandra.New(session)
+	store := cassandra.NewRetentionStore(session, keyspace)
+
+	now := time.Now()
+	var chans []string
+	for i := 0; i < 3; i++ {
+		id, err := uuid.NewV4()
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+		chans = append(chans, id.String())
+
+		var msgs []senml.Message
+		for j := 0; j < msgsNum; j++ {
+			created := now.Add(-time.Duration(j) * time.Hour)
+			msgs = append(msgs, senml.Message{Channel: id.String(), Value: &v, Time: float64(created.Unix())})
+		}
+		err = repo.Consume(msgs)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+
+	err = store.Purge(context.Background(), chans[0], now.Add(-10*time.Hour+time.Minute))
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+	err = store.PurgeExcept(context.Background(), chans[:2], now.Add(-20*time.Hour+time.Minute))
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s\n", err))
+
+	cases := []struct {
+		desc    string
+		channel string
+		count   int
+	}{
+		{desc: "purge channel", channel: chans[0], count: 10},
+		{desc: "purge excluded channel", channel: chans[1], count: msgsNum},
+		{desc: "purge other channel", channel: chans[2], count: 20},
+	}
+
+	for _, tc := range cases {
+		var count int
+		err := session.Query(`SELECT COUNT(*) FROM messages WHERE channel = ?`, tc.channel).Scan(&count)
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d messages got %d", tc.desc, tc.count, count))
+	}
+}
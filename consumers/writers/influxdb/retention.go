@@ -0,0 +1,65 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	influxdata "github.com/influxdata/influxdb/client/v2"
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var errPurgeMessages = errors.New("failed to purge messages from influxdb database")
+
+var _ retention.Store = (*retentionStore)(nil)
+
+type retentionStore struct {
+	client   influxdata.Client
+	database string
+}
+
+// NewRetentionStore returns the store removing the expired points of all
+// measurements by the channel tag.
+func NewRetentionStore(client influxdata.Client, database string) retention.Store {
+	return &retentionStore{
+		client:   client,
+		database: database,
+	}
+}
+
+func (rs *retentionStore) Purge(ctx context.Context, channel string, before time.Time) error {
+	return rs.purge(fmt.Sprintf(`"channel" = '%s'`, escape(channel)), before)
+}
+
+func (rs *retentionStore) PurgeExcept(ctx context.Context, channels []string, before time.Time) error {
+	var conds []string
+	for _, ch := range channels {
+		conds = append(conds, fmt.Sprintf(`"channel" != '%s'`, escape(ch)))
+	}
+	return rs.purge(strings.Join(conds, " AND "), before)
+}
+
+func (rs *retentionStore) purge(cond string, before time.Time) error {
+	q := fmt.Sprintf(`DELETE WHERE time < %d`, before.UnixNano())
+	if cond != "" {
+		q = fmt.Sprintf(`DELETE WHERE %s AND time < %d`, cond, before.UnixNano())
+	}
+
+	res, err := rs.client.Query(influxdata.NewQuery(q, rs.database, ""))
+	if err != nil {
+		return errors.Wrap(errPurgeMessages, err)
+	}
+	if err := res.Error(); err != nil {
+		return errors.Wrap(errPurgeMessages, err)
+	}
+	return nil
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
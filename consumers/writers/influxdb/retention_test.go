@@ -0,0 +1,61 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package influxdb_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	writer "github.com/mainflux/mainflux/consumers/writers/influxdb"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurge(t *testing.T) {
+	_, err := queryDB(dropMsgs)
+	require.Nil(t, err, fmt.Sprintf("Cleaning data from InfluxDB expected to succeed: %s.\n", err))
+
+	repo := writer.New(client, testDB)
+	store := writer.NewRetentionStore(client, testDB)
+
+	now := time.Now()
+	chans := []string{"purged", "excluded", "other"}
+	for _, ch := range chans {
+		var msgs []senml.Message
+		for i := 0; i < valueFields*10; i++ {
+			created := now.Add(-time.Duration(i) * time.Hour)
+			msgs = append(msgs, senml.Message{Channel: ch, Name: "test name", Value: &v, Time: float64(created.Unix())})
+		}
+		err := repo.Consume(msgs)
+		require.Nil(t, err, fmt.Sprintf("Save operation expected to succeed: %s.\n", err))
+	}
+
+	err = store.Purge(context.Background(), chans[0], now.Add(-10*time.Hour+time.Minute))
+	assert.Nil(t, err, fmt.Sprintf("Purge operation expected to succeed: %s.\n", err))
+	err = store.PurgeExcept(context.Background(), chans[:2], now.Add(-20*time.Hour+time.Minute))
+	assert.Nil(t, err, fmt.Sprintf("Purge operation expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc    string
+		channel string
+		count   int64
+	}{
+		{desc: "purge channel", channel: chans[0], count: 10},
+		{desc: "purge excluded channel", channel: chans[1], count: valueFields * 10},
+		{desc: "purge other channel", channel: chans[2], count: 20},
+	}
+
+	for _, tc := range cases {
+		rows, err := queryDB(fmt.Sprintf(`SELECT COUNT(value) FROM test..messages WHERE "channel" = '%s'`, tc.channel))
+		require.Nil(t, err, fmt.Sprintf("%s: querying InfluxDB expected to succeed: %s.\n", tc.desc, err))
+		require.Len(t, rows, 1, fmt.Sprintf("%s: expected a single row", tc.desc))
+		count, err := rows[0][1].(json.Number).Int64()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected count %v: %s", tc.desc, rows[0][1], err))
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d messages got %d", tc.desc, tc.count, count))
+	}
+}
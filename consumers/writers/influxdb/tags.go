@@ -12,6 +12,7 @@ func senmlTags(msg senml.Message) tags {
 		"channel":   msg.Channel,
 		"subtopic":  msg.Subtopic,
 		"publisher": msg.Publisher,
+		"tenant":    msg.Tenant,
 		"name":      msg.Name,
 	}
 }
@@ -21,5 +22,6 @@ func jsonTags(msg json.Message) tags {
 		"channel":   msg.Channel,
 		"subtopic":  msg.Subtopic,
 		"publisher": msg.Publisher,
+		"tenant":    msg.Tenant,
 	}
 }
@@ -5,7 +5,9 @@ package mongodb
 
 import (
 	"context"
+	"sync"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/mainflux/mainflux/consumers"
@@ -22,11 +24,14 @@ var _ consumers.Consumer = (*mongoRepo)(nil)
 
 type mongoRepo struct {
 	db *mongo.Database
+
+	// indexed contains the names of the collections with the tenant index.
+	indexed sync.Map
 }
 
 // New returns new MongoDB writer.
 func New(db *mongo.Database) consumers.Consumer {
-	return &mongoRepo{db}
+	return &mongoRepo{db: db}
 }
 
 func (repo *mongoRepo) Consume(message interface{}) error {
@@ -45,6 +50,9 @@ func (repo *mongoRepo) saveSenml(messages interface{}) error {
 		return errSaveMessage
 	}
 	coll := repo.db.Collection(senmlCollection)
+	if err := repo.ensureIndex(coll); err != nil {
+		return err
+	}
 	var dbMsgs []interface{}
 	for _, msg := range msgs {
 		dbMsgs = append(dbMsgs, msg)
@@ -65,6 +73,9 @@ func (repo *mongoRepo) saveJSON(msgs json.Messages) error {
 	}
 
 	coll := repo.db.Collection(msgs.Format)
+	if err := repo.ensureIndex(coll); err != nil {
+		return err
+	}
 
 	_, err := coll.InsertMany(context.Background(), m)
 	if err != nil {
@@ -73,3 +84,18 @@ func (repo *mongoRepo) saveJSON(msgs json.Messages) error {
 
 	return nil
 }
+
+// ensureIndex creates the tenant index, unless it's already created by this
+// writer. Creating the existing index has no effect.
+func (repo *mongoRepo) ensureIndex(coll *mongo.Collection) error {
+	if _, ok := repo.indexed.Load(coll.Name()); ok {
+		return nil
+	}
+
+	idx := mongo.IndexModel{Keys: bson.D{{Key: "tenant", Value: 1}}}
+	if _, err := coll.Indexes().CreateOne(context.Background(), idx); err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+	repo.indexed.Store(coll.Name(), struct{}{})
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var errPurgeMessages = errors.New("failed to purge messages from mongodb database")
+
+var _ retention.Store = (*retentionStore)(nil)
+
+type retentionStore struct {
+	db *mongo.Database
+}
+
+// NewRetentionStore returns the store removing the expired messages from the
+// SenML messages collection and the JSON formats collections.
+func NewRetentionStore(db *mongo.Database) retention.Store {
+	return &retentionStore{db: db}
+}
+
+func (rs *retentionStore) Purge(ctx context.Context, channel string, before time.Time) error {
+	return rs.purge(ctx, channel, before)
+}
+
+func (rs *retentionStore) PurgeExcept(ctx context.Context, channels []string, before time.Time) error {
+	return rs.purge(ctx, bson.M{"$nin": channels}, before)
+}
+
+// purge removes the messages of the channels matching the filter. The SenML
+// time is in seconds, while the JSON creation time is in nanoseconds.
+func (rs *retentionStore) purge(ctx context.Context, channel interface{}, before time.Time) error {
+	names, err := rs.db.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return errors.Wrap(errPurgeMessages, err)
+	}
+
+	for _, name := range names {
+		filter := bson.M{"channel": channel, "created": bson.M{"$lt": before.UnixNano()}}
+		if name == senmlCollection {
+			filter = bson.M{"channel": channel, "time": bson.M{"$lt": float64(before.UnixNano()) / 1e9}}
+		}
+		if _, err := rs.db.Collection(name).DeleteMany(ctx, filter); err != nil {
+			return errors.Wrap(errPurgeMessages, err)
+		}
+	}
+	return nil
+}
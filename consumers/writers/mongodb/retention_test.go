@@ -0,0 +1,63 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mongodb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mainflux/mainflux/consumers/writers/mongodb"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestPurge(t *testing.T) {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
+	require.Nil(t, err, fmt.Sprintf("Creating new MongoDB client expected to succeed: %s.\n", err))
+
+	db := client.Database(testDB)
+	repo := mongodb.New(db)
+	store := mongodb.NewRetentionStore(db)
+
+	now := time.Now()
+	chans := []string{"purged", "excluded", "other"}
+	for _, ch := range chans {
+		var msgs []senml.Message
+		for i := 0; i < msgsNum; i++ {
+			created := now.Add(-time.Duration(i) * time.Hour)
+			msgs = append(msgs, senml.Message{Channel: ch, Value: &v, Time: float64(created.Unix())})
+		}
+		err := repo.Consume(msgs)
+		require.Nil(t, err, fmt.Sprintf("Save operation expected to succeed: %s.\n", err))
+	}
+
+	err = store.Purge(context.Background(), chans[0], now.Add(-10*time.Hour+time.Minute))
+	assert.Nil(t, err, fmt.Sprintf("Purge operation expected to succeed: %s.\n", err))
+	err = store.PurgeExcept(context.Background(), chans[:2], now.Add(-20*time.Hour+time.Minute))
+	assert.Nil(t, err, fmt.Sprintf("Purge operation expected to succeed: %s.\n", err))
+
+	cases := []struct {
+		desc    string
+		channel string
+		count   int64
+	}{
+		{desc: "purge channel", channel: chans[0], count: 10},
+		{desc: "purge excluded channel", channel: chans[1], count: int64(msgsNum)},
+		{desc: "purge other channel", channel: chans[2], count: 20},
+	}
+
+	for _, tc := range cases {
+		count, err := db.Collection(collection).CountDocuments(context.Background(), bson.M{"channel": tc.channel})
+		require.Nil(t, err, fmt.Sprintf("%s: querying MongoDB expected to succeed: %s.\n", tc.desc, err))
+		assert.Equal(t, tc.count, count, fmt.Sprintf("%s: expected %d messages got %d", tc.desc, tc.count, count))
+	}
+}
@@ -18,8 +18,9 @@ import (
 )
 
 const (
-	errInvalid        = "invalid_text_representation"
-	errUndefinedTable = "undefined_table"
+	errInvalid         = "invalid_text_representation"
+	errUndefinedTable  = "undefined_table"
+	errUndefinedColumn = "undefined_column"
 )
 
 var (
@@ -27,6 +28,7 @@ var (
 	errSaveMessage    = errors.New("failed to save message to postgres database")
 	errTransRollback  = errors.New("failed to rollback transaction")
 	errNoTable        = errors.New("relation does not exist")
+	errNoColumn       = errors.New("column does not exist")
 )
 
 var _ consumers.Consumer = (*postgresRepo)(nil)
@@ -55,11 +57,11 @@ func (pr postgresRepo) saveSenml(messages interface{}) (err error) {
 		return errSaveMessage
 	}
 	q := `INSERT INTO messages (id, channel, subtopic, publisher, protocol,
-          name, unit, value, string_value, bool_value, data_value, sum,
-          time, update_time)
-          VALUES (:id, :channel, :subtopic, :publisher, :protocol, :name, :unit,
-          :value, :string_value, :bool_value, :data_value, :sum,
-          :time, :update_time);`
+          tenant, message_id, name, unit, value, string_value, bool_value,
+          data_value, sum, time, update_time)
+          VALUES (:id, :channel, :subtopic, :publisher, :protocol, :tenant,
+          :message_id, :name, :unit, :value, :string_value, :bool_value,
+          :data_value, :sum, :time, :update_time);`
 
 	tx, err := pr.db.BeginTxx(context.Background(), nil)
 	if err != nil {
@@ -101,7 +103,9 @@ func (pr postgresRepo) saveSenml(messages interface{}) (err error) {
 
 func (pr postgresRepo) saveJSON(msgs mfjson.Messages) error {
 	if err := pr.insertJSON(msgs); err != nil {
-		if err == errNoTable {
+		// Tables created before the tenant column was introduced are
+		// migrated the same way the missing ones are created.
+		if err == errNoTable || err == errNoColumn {
 			if err := pr.createTable(msgs.Format); err != nil {
 				return err
 			}
@@ -130,8 +134,8 @@ func (pr postgresRepo) insertJSON(msgs mfjson.Messages) error {
 		}
 	}()
 
-	q := `INSERT INTO %s (id, channel, created, subtopic, publisher, protocol, payload)
-          VALUES (:id, :channel, :created, :subtopic, :publisher, :protocol, :payload);`
+	q := `INSERT INTO %s (id, channel, created, subtopic, publisher, protocol, tenant, message_id, payload)
+          VALUES (:id, :channel, :created, :subtopic, :publisher, :protocol, :tenant, :message_id, :payload);`
 	q = fmt.Sprintf(q, msgs.Format)
 
 	for _, m := range msgs.Data {
@@ -148,6 +152,8 @@ func (pr postgresRepo) insertJSON(msgs mfjson.Messages) error {
 					return errors.Wrap(errSaveMessage, errInvalidMessage)
 				case errUndefinedTable:
 					return errNoTable
+				case errUndefinedColumn:
+					return errNoColumn
 				}
 			}
 			return err
@@ -157,20 +163,30 @@ func (pr postgresRepo) insertJSON(msgs mfjson.Messages) error {
 }
 
 func (pr postgresRepo) createTable(name string) error {
-	q := `CREATE TABLE IF NOT EXISTS %s (
+	qs := []string{
+		`CREATE TABLE IF NOT EXISTS %[1]s (
                         id            UUID,
                         created       BIGINT,
                         channel       VARCHAR(254),
                         subtopic      VARCHAR(254),
                         publisher     VARCHAR(254),
                         protocol      TEXT,
+                        tenant        TEXT NOT NULL DEFAULT 'default',
                         payload       JSONB,
                         PRIMARY KEY (id)
-                    )`
-	q = fmt.Sprintf(q, name)
+                    )`,
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS tenant TEXT NOT NULL DEFAULT 'default'`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_tenant_idx ON %[1]s (tenant)`,
+		`ALTER TABLE %[1]s ADD COLUMN IF NOT EXISTS message_id TEXT NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS %[1]s_message_id_idx ON %[1]s (message_id) WHERE message_id <> ''`,
+	}
 
-	_, err := pr.db.Exec(q)
-	return err
+	for _, q := range qs {
+		if _, err := pr.db.Exec(fmt.Sprintf(q, name)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 type senmlMessage struct {
@@ -185,6 +201,8 @@ type jsonMessage struct {
 	Subtopic  string `db:"subtopic"`
 	Publisher string `db:"publisher"`
 	Protocol  string `db:"protocol"`
+	Tenant    string `db:"tenant"`
+	MessageID string `db:"message_id"`
 	Payload   []byte `db:"payload"`
 }
 
@@ -210,6 +228,8 @@ func toJSONMessage(msg mfjson.Message) (jsonMessage, error) {
 		Subtopic:  msg.Subtopic,
 		Publisher: msg.Publisher,
 		Protocol:  msg.Protocol,
+		Tenant:    msg.Tenant,
+		MessageID: msg.MessageID,
 		Payload:   data,
 	}
 
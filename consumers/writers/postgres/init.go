@@ -24,13 +24,16 @@ type Config struct {
 	SSLRootCert string
 }
 
+// URL returns the connection string of the PostgreSQL instance.
+func (cfg Config) URL() string {
+	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
+}
+
 // Connect creates a connection to the PostgreSQL instance and applies any
 // unapplied database migrations. A non-nil error is returned to indicate
 // failure.
 func Connect(cfg Config) (*sqlx.DB, error) {
-	url := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
-
-	db, err := sqlx.Open("postgres", url)
+	db, err := sqlx.Open("postgres", cfg.URL())
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +73,28 @@ func migrateDB(db *sqlx.DB) error {
 					"DROP TABLE messages",
 				},
 			},
+			{
+				Id: "messages_2",
+				Up: []string{
+					`ALTER TABLE messages ADD COLUMN IF NOT EXISTS tenant TEXT NOT NULL DEFAULT 'default'`,
+					`CREATE INDEX IF NOT EXISTS messages_tenant_idx ON messages (tenant)`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS messages_tenant_idx`,
+					`ALTER TABLE messages DROP COLUMN IF EXISTS tenant`,
+				},
+			},
+			{
+				Id: "messages_3",
+				Up: []string{
+					`ALTER TABLE messages ADD COLUMN IF NOT EXISTS message_id TEXT NOT NULL DEFAULT ''`,
+					`CREATE INDEX IF NOT EXISTS messages_message_id_idx ON messages (message_id) WHERE message_id <> ''`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS messages_message_id_idx`,
+					`ALTER TABLE messages DROP COLUMN IF EXISTS message_id`,
+				},
+			},
 		},
 	}
 
@@ -0,0 +1,69 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/consumers/retention"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var errPurgeMessages = errors.New("failed to purge messages from postgres database")
+
+var _ retention.Store = (*retentionStore)(nil)
+
+type retentionStore struct {
+	db *sqlx.DB
+}
+
+// NewRetentionStore returns the store removing the expired messages from the
+// SenML messages table and the JSON formats tables.
+func NewRetentionStore(db *sqlx.DB) retention.Store {
+	return &retentionStore{db: db}
+}
+
+func (rs retentionStore) Purge(ctx context.Context, channel string, before time.Time) error {
+	return rs.purge(ctx, "channel::text = $1", channel, before)
+}
+
+func (rs retentionStore) PurgeExcept(ctx context.Context, channels []string, before time.Time) error {
+	return rs.purge(ctx, "NOT (channel::text = ANY($1))", pq.Array(channels), before)
+}
+
+// purge removes the messages of the channels matching the condition. The
+// SenML time is in seconds, while the JSON creation time is in nanoseconds.
+func (rs retentionStore) purge(ctx context.Context, cond string, channels interface{}, before time.Time) error {
+	q := fmt.Sprintf(`DELETE FROM messages WHERE %s AND time < $2`, cond)
+	if _, err := rs.db.ExecContext(ctx, q, channels, float64(before.UnixNano())/1e9); err != nil {
+		return errors.Wrap(errPurgeMessages, err)
+	}
+
+	tables, err := rs.jsonTables(ctx)
+	if err != nil {
+		return errors.Wrap(errPurgeMessages, err)
+	}
+	for _, table := range tables {
+		q := fmt.Sprintf(`DELETE FROM %s WHERE %s AND created < $2`, pq.QuoteIdentifier(table), cond)
+		if _, err := rs.db.ExecContext(ctx, q, channels, before.UnixNano()); err != nil {
+			return errors.Wrap(errPurgeMessages, err)
+		}
+	}
+	return nil
+}
+
+func (rs retentionStore) jsonTables(ctx context.Context) ([]string, error) {
+	q := `SELECT table_name FROM information_schema.columns
+          WHERE table_schema = current_schema() AND column_name = 'payload' AND table_name <> 'messages'`
+
+	var tables []string
+	if err := rs.db.SelectContext(ctx, &tables, q); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
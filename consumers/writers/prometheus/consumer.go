@@ -0,0 +1,359 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/go-kit/kit/metrics"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+const (
+	nameLabel      = "__name__"
+	reasonLabel    = "reason"
+	reasonOrder    = "out_of_order"
+	reasonValue    = "non_numeric"
+	reasonName     = "invalid_name"
+	remoteVersion  = "0.1.0"
+	userAgent      = "mainflux-prometheus-writer"
+	defBatchSize   = 500
+	defFlush       = 5 * time.Second
+	defRetry       = 500 * time.Millisecond
+	maxErrBodySize = 512
+)
+
+var (
+	errSaveMessage = errors.New("failed to save message to prometheus remote-write endpoint")
+	errRequest     = errors.New("remote-write request rejected")
+)
+
+// Config represents the remote-write writer settings.
+type Config struct {
+	// URL is the remote-write endpoint URL.
+	URL string
+
+	// Prefix is prepended to the metric names.
+	Prefix string
+
+	// BatchSize is the number of samples pushed in a single request.
+	BatchSize int
+
+	// FlushInterval is the longest period samples are buffered before
+	// they're pushed, regardless of the batch size.
+	FlushInterval time.Duration
+
+	// Username and Password are used for the basic authentication.
+	Username string
+	Password string
+
+	// BearerToken is sent as the Authorization header, if it's set.
+	BearerToken string
+
+	// Headers are added to each request, e.g. the tenant ID header of the
+	// multi-tenant remote-write receivers.
+	Headers map[string]string
+
+	// MaxRetries is the number of retries of the request failed with 429,
+	// 5xx or a network error.
+	MaxRetries uint64
+
+	// RetryInterval is the initial interval between the retries, which is
+	// increased exponentially.
+	RetryInterval time.Duration
+
+	// HTTPClient is used to send the requests. It defaults to the
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+var _ consumers.Consumer = (*remoteWriter)(nil)
+
+type remoteWriter struct {
+	ctx     context.Context
+	cfg     Config
+	http    *http.Client
+	dropped metrics.Counter
+	logger  logger.Logger
+
+	// pushMu keeps the batches in order, since the receiver rejects samples
+	// older than the ones it already has.
+	pushMu sync.Mutex
+
+	mu      sync.Mutex
+	last    map[string]int64
+	series  map[string]*TimeSeries
+	keys    []string
+	samples int
+}
+
+// New returns the writer converting SenML messages into Prometheus
+// remote-write samples. Samples are buffered and pushed when the batch is
+// full, or periodically until the context is canceled, when the remaining
+// samples are pushed. Samples not newer than the latest sample of their
+// series, as well as non-numeric values, are dropped and counted by reason.
+func New(ctx context.Context, cfg Config, dropped metrics.Counter, logger logger.Logger) consumers.Consumer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defFlush
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defRetry
+	}
+	rw := &remoteWriter{
+		ctx:     ctx,
+		cfg:     cfg,
+		http:    cfg.HTTPClient,
+		dropped: dropped,
+		logger:  logger,
+		last:    make(map[string]int64),
+		series:  make(map[string]*TimeSeries),
+	}
+	if rw.http == nil {
+		rw.http = http.DefaultClient
+	}
+
+	go rw.flushLoop()
+	return rw
+}
+
+func (rw *remoteWriter) Consume(message interface{}) error {
+	msgs, ok := message.([]senml.Message)
+	if !ok {
+		return errSaveMessage
+	}
+
+	rw.mu.Lock()
+	for _, msg := range msgs {
+		rw.add(msg)
+	}
+	full := rw.samples >= rw.cfg.BatchSize
+	rw.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	if err := rw.flush(rw.ctx); err != nil {
+		return errors.Wrap(errSaveMessage, err)
+	}
+	return nil
+}
+
+func (rw *remoteWriter) flushLoop() {
+	ticker := time.NewTicker(rw.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rw.ctx.Done():
+			// The context is done, so the remaining samples are pushed
+			// without retries.
+			if err := rw.flush(context.Background()); err != nil {
+				rw.logger.Warn(fmt.Sprintf("Failed to push remaining samples: %s", err))
+			}
+			return
+		case <-ticker.C:
+			if err := rw.flush(rw.ctx); err != nil {
+				rw.logger.Warn(fmt.Sprintf("Failed to push samples: %s", err))
+			}
+		}
+	}
+}
+
+// add buffers the message sample. It's called with the lock held.
+func (rw *remoteWriter) add(msg senml.Message) {
+	value, ok := sampleValue(msg)
+	if !ok {
+		rw.dropped.With(reasonLabel, reasonValue).Add(1)
+		return
+	}
+	name := metricName(rw.cfg.Prefix, msg.Name)
+	if name == "" {
+		rw.dropped.With(reasonLabel, reasonName).Add(1)
+		return
+	}
+
+	lbls := labels(name, msg)
+	key := seriesKey(lbls)
+	ts := int64(math.Round(msg.Time * 1e3))
+	if last, ok := rw.last[key]; ok && ts <= last {
+		rw.dropped.With(reasonLabel, reasonOrder).Add(1)
+		return
+	}
+	rw.last[key] = ts
+
+	s, ok := rw.series[key]
+	if !ok {
+		s = &TimeSeries{Labels: lbls}
+		rw.series[key] = s
+		rw.keys = append(rw.keys, key)
+	}
+	s.Samples = append(s.Samples, &Sample{Value: value, Timestamp: ts})
+	rw.samples++
+}
+
+// flush pushes the buffered samples.
+func (rw *remoteWriter) flush(ctx context.Context) error {
+	rw.pushMu.Lock()
+	defer rw.pushMu.Unlock()
+
+	rw.mu.Lock()
+	req := &WriteRequest{}
+	for _, key := range rw.keys {
+		req.Timeseries = append(req.Timeseries, rw.series[key])
+	}
+	rw.series = make(map[string]*TimeSeries)
+	rw.keys = nil
+	rw.samples = 0
+	rw.mu.Unlock()
+
+	if len(req.Timeseries) == 0 {
+		return nil
+	}
+	return rw.push(ctx, req)
+}
+
+func (rw *remoteWriter) push(ctx context.Context, req *WriteRequest) error {
+	data, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	body := snappy.Encode(nil, data)
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = rw.cfg.RetryInterval
+	b.MaxElapsedTime = 0
+	op := func() error {
+		return rw.send(ctx, body)
+	}
+	return backoff.Retry(op, backoff.WithContext(backoff.WithMaxRetries(b, rw.cfg.MaxRetries), ctx))
+}
+
+// send sends the request, marking the errors that shouldn't be retried as
+// permanent.
+func (rw *remoteWriter) send(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rw.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("X-Prometheus-Remote-Write-Version", remoteVersion)
+	for k, v := range rw.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	switch {
+	case rw.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+rw.cfg.BearerToken)
+	case rw.cfg.Username != "":
+		req.SetBasicAuth(rw.cfg.Username, rw.cfg.Password)
+	}
+
+	resp, err := rw.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		io.Copy(ioutil.Discard, resp.Body)
+		return nil
+	}
+
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrBodySize))
+	err = errors.Wrap(errRequest, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg))))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return err
+	}
+	return backoff.Permanent(err)
+}
+
+func sampleValue(msg senml.Message) (float64, bool) {
+	switch {
+	case msg.Value != nil:
+		return *msg.Value, true
+	case msg.Sum != nil:
+		return *msg.Sum, true
+	case msg.BoolValue != nil:
+		if *msg.BoolValue {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// metricName returns the prefixed SenML name with the characters not
+// allowed in the metric names replaced by underscores.
+func metricName(prefix, name string) string {
+	if name == "" {
+		return ""
+	}
+	var sb strings.Builder
+	for i, r := range prefix + name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// labels returns the series labels sorted by name, omitting the empty ones.
+func labels(name string, msg senml.Message) []*Label {
+	lbls := []*Label{{Name: nameLabel, Value: name}}
+	for _, l := range []Label{
+		{Name: "channel", Value: msg.Channel},
+		{Name: "subtopic", Value: msg.Subtopic},
+		{Name: "publisher", Value: msg.Publisher},
+		{Name: "unit", Value: msg.Unit},
+	} {
+		if l.Value != "" {
+			l := l
+			lbls = append(lbls, &l)
+		}
+	}
+	sort.Slice(lbls, func(i, j int) bool {
+		return lbls[i].Name < lbls[j].Name
+	})
+	return lbls
+}
+
+func seriesKey(lbls []*Label) string {
+	var sb strings.Builder
+	for _, l := range lbls {
+		sb.WriteString(l.Name)
+		sb.WriteByte(0xff)
+		sb.WriteString(l.Value)
+		sb.WriteByte(0xff)
+	}
+	return sb.String()
+}
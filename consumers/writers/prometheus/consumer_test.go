@@ -0,0 +1,398 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package prometheus_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	proto "github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+	"github.com/mainflux/mainflux/consumers/writers/prometheus"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	prefix    = "mainflux_"
+	chanID    = "chan"
+	subtopic  = "sub"
+	publisher = "pub"
+	token     = "token"
+)
+
+var testLog, _ = log.New(os.Stdout, log.Info.String())
+
+// receiver is the remote-write endpoint storing the decoded requests. It
+// responds with the queued statuses first.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []prometheus.WriteRequest
+	headers  []http.Header
+	attempts int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.attempts++
+	if len(rc.statuses) > 0 {
+		status := rc.statuses[0]
+		rc.statuses = rc.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var req prometheus.WriteRequest
+	if err := proto.Unmarshal(data, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	rc.requests = append(rc.requests, req)
+	rc.headers = append(rc.headers, r.Header)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (rc *receiver) received() ([]prometheus.WriteRequest, int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.requests, rc.attempts
+}
+
+// counter is the metrics counter storing values by joined label values.
+type counter struct {
+	mu     *sync.Mutex
+	labels []string
+	values map[string]float64
+}
+
+func newCounter() *counter {
+	return &counter{mu: &sync.Mutex{}, values: make(map[string]float64)}
+}
+
+func (c *counter) With(labelValues ...string) metrics.Counter {
+	return &counter{mu: c.mu, labels: labelValues, values: c.values}
+}
+
+func (c *counter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[strings.Join(c.labels, ",")] += delta
+}
+
+func (c *counter) value(reason string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values["reason,"+reason]
+}
+
+func newServer(statuses ...int) (*receiver, *httptest.Server) {
+	rc := &receiver{statuses: statuses}
+	return rc, httptest.NewServer(rc)
+}
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func boolean(v bool) *bool {
+	return &v
+}
+
+func str(v string) *string {
+	return &v
+}
+
+func TestConsume(t *testing.T) {
+	msg := senml.Message{
+		Channel:   chanID,
+		Subtopic:  subtopic,
+		Publisher: publisher,
+		Name:      "urn:dev:temp",
+		Unit:      "Cel",
+		Time:      1600000000.5,
+	}
+
+	cases := []struct {
+		desc    string
+		msgs    []senml.Message
+		series  []prometheus.TimeSeries
+		dropped map[string]float64
+	}{
+		{
+			desc: "consume numeric values",
+			msgs: []senml.Message{
+				withValue(msg, 1600000000.5, float(20)),
+				withValue(msg, 1600000001, float(21.5)),
+			},
+			series: []prometheus.TimeSeries{
+				{
+					Labels: []*prometheus.Label{
+						{Name: "__name__", Value: "mainflux_urn:dev:temp"},
+						{Name: "channel", Value: chanID},
+						{Name: "publisher", Value: publisher},
+						{Name: "subtopic", Value: subtopic},
+						{Name: "unit", Value: "Cel"},
+					},
+					Samples: []*prometheus.Sample{
+						{Value: 20, Timestamp: 1600000000500},
+						{Value: 21.5, Timestamp: 1600000001000},
+					},
+				},
+			},
+		},
+		{
+			desc: "consume sum and bool values",
+			msgs: []senml.Message{
+				{Channel: chanID, Name: "energy", Time: 1, Sum: float(42)},
+				{Channel: chanID, Name: "door-open", Time: 1, BoolValue: boolean(true)},
+			},
+			series: []prometheus.TimeSeries{
+				{
+					Labels: []*prometheus.Label{
+						{Name: "__name__", Value: "mainflux_energy"},
+						{Name: "channel", Value: chanID},
+					},
+					Samples: []*prometheus.Sample{{Value: 42, Timestamp: 1000}},
+				},
+				{
+					Labels: []*prometheus.Label{
+						{Name: "__name__", Value: "mainflux_door_open"},
+						{Name: "channel", Value: chanID},
+					},
+					Samples: []*prometheus.Sample{{Value: 1, Timestamp: 1000}},
+				},
+			},
+		},
+		{
+			desc: "consume out of order and non-numeric values",
+			msgs: []senml.Message{
+				withValue(msg, 10, float(1)),
+				withValue(msg, 9, float(2)),
+				withValue(msg, 10, float(3)),
+				{Channel: chanID, Name: "status", Time: 11, StringValue: str("ok")},
+				{Channel: chanID, Time: 11, Value: float(1)},
+				withValue(msg, 11, float(4)),
+			},
+			series: []prometheus.TimeSeries{
+				{
+					Labels: []*prometheus.Label{
+						{Name: "__name__", Value: "mainflux_urn:dev:temp"},
+						{Name: "channel", Value: chanID},
+						{Name: "publisher", Value: publisher},
+						{Name: "subtopic", Value: subtopic},
+						{Name: "unit", Value: "Cel"},
+					},
+					Samples: []*prometheus.Sample{
+						{Value: 1, Timestamp: 10000},
+						{Value: 4, Timestamp: 11000},
+					},
+				},
+			},
+			dropped: map[string]float64{
+				"out_of_order": 2,
+				"non_numeric":  1,
+				"invalid_name": 1,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		rc, ts := newServer()
+		c := newCounter()
+		ctx, cancel := context.WithCancel(context.Background())
+		cfg := prometheus.Config{
+			URL:           ts.URL,
+			Prefix:        prefix,
+			BatchSize:     len(tc.msgs),
+			FlushInterval: time.Hour,
+		}
+		writer := prometheus.New(ctx, cfg, c, testLog)
+
+		for _, msg := range tc.msgs {
+			err := writer.Consume([]senml.Message{msg})
+			assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		}
+		cancel()
+
+		assert.Eventually(t, func() bool {
+			reqs, _ := rc.received()
+			return len(reqs) > 0
+		}, time.Second, time.Millisecond, fmt.Sprintf("%s: expected remote-write request", tc.desc))
+		reqs, _ := rc.received()
+		require.Len(t, reqs, 1, fmt.Sprintf("%s: expected single remote-write request got %d", tc.desc, len(reqs)))
+
+		series := reqs[0].Timeseries
+		assert.Equal(t, len(tc.series), len(series), fmt.Sprintf("%s: expected %d series got %d", tc.desc, len(tc.series), len(series)))
+		for i := range tc.series {
+			if i >= len(series) {
+				break
+			}
+			assert.Equal(t, tc.series[i].Labels, series[i].Labels, fmt.Sprintf("%s: expected labels %v got %v", tc.desc, tc.series[i].Labels, series[i].Labels))
+			assert.Equal(t, tc.series[i].Samples, series[i].Samples, fmt.Sprintf("%s: expected samples %v got %v", tc.desc, tc.series[i].Samples, series[i].Samples))
+		}
+		for reason, count := range tc.dropped {
+			assert.Equal(t, count, c.value(reason), fmt.Sprintf("%s: expected %v samples dropped as %s got %v", tc.desc, count, reason, c.value(reason)))
+		}
+		ts.Close()
+	}
+}
+
+func TestConsumeRetry(t *testing.T) {
+	msg := senml.Message{Channel: chanID, Name: "temp", Time: 1, Value: float(1)}
+
+	cases := []struct {
+		desc     string
+		statuses []int
+		retries  uint64
+		attempts int
+		received int
+		err      bool
+	}{
+		{
+			desc:     "push after too many requests and server errors",
+			statuses: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+			retries:  3,
+			attempts: 3,
+			received: 1,
+		},
+		{
+			desc:     "push failing after max retries",
+			statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			retries:  2,
+			attempts: 3,
+			err:      true,
+		},
+		{
+			desc:     "push rejected by the receiver",
+			statuses: []int{http.StatusBadRequest},
+			retries:  3,
+			attempts: 1,
+			err:      true,
+		},
+	}
+
+	for _, tc := range cases {
+		rc, ts := newServer(tc.statuses...)
+		cfg := prometheus.Config{
+			URL:           ts.URL,
+			Prefix:        prefix,
+			BatchSize:     1,
+			FlushInterval: time.Hour,
+			MaxRetries:    tc.retries,
+			RetryInterval: time.Millisecond,
+		}
+		writer := prometheus.New(context.Background(), cfg, newCounter(), testLog)
+
+		err := writer.Consume([]senml.Message{msg})
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", tc.desc, tc.err, err))
+		reqs, attempts := rc.received()
+		assert.Equal(t, tc.attempts, attempts, fmt.Sprintf("%s: expected %d attempts got %d", tc.desc, tc.attempts, attempts))
+		assert.Equal(t, tc.received, len(reqs), fmt.Sprintf("%s: expected %d received requests got %d", tc.desc, tc.received, len(reqs)))
+		ts.Close()
+	}
+}
+
+func TestConsumeHeaders(t *testing.T) {
+	msg := senml.Message{Channel: chanID, Name: "temp", Time: 1, Value: float(1)}
+
+	cases := []struct {
+		desc    string
+		cfg     prometheus.Config
+		headers map[string]string
+	}{
+		{
+			desc: "push with bearer token",
+			cfg:  prometheus.Config{BearerToken: token},
+			headers: map[string]string{
+				"Authorization": "Bearer " + token,
+			},
+		},
+		{
+			desc: "push with basic authentication",
+			cfg:  prometheus.Config{Username: "user", Password: "pass"},
+			headers: map[string]string{
+				"Authorization": "Basic dXNlcjpwYXNz",
+			},
+		},
+		{
+			desc: "push with custom headers",
+			cfg:  prometheus.Config{Headers: map[string]string{"X-Scope-OrgID": "tenant"}},
+			headers: map[string]string{
+				"X-Scope-OrgID":    "tenant",
+				"Content-Encoding": "snappy",
+				"Content-Type":     "application/x-protobuf",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		rc, ts := newServer()
+		cfg := tc.cfg
+		cfg.URL = ts.URL
+		cfg.BatchSize = 1
+		writer := prometheus.New(context.Background(), cfg, newCounter(), testLog)
+
+		err := writer.Consume([]senml.Message{msg})
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		rc.mu.Lock()
+		require.Len(t, rc.headers, 1, fmt.Sprintf("%s: expected single request", tc.desc))
+		for k, v := range tc.headers {
+			assert.Equal(t, v, rc.headers[0].Get(k), fmt.Sprintf("%s: expected header %s to be %s got %s", tc.desc, k, v, rc.headers[0].Get(k)))
+		}
+		rc.mu.Unlock()
+		ts.Close()
+	}
+}
+
+func TestConsumeFlushInterval(t *testing.T) {
+	rc, ts := newServer()
+	defer ts.Close()
+
+	cfg := prometheus.Config{
+		URL:           ts.URL,
+		BatchSize:     100,
+		FlushInterval: 10 * time.Millisecond,
+	}
+	writer := prometheus.New(context.Background(), cfg, newCounter(), testLog)
+
+	err := writer.Consume([]senml.Message{{Channel: chanID, Name: "temp", Time: 1, Value: float(1)}})
+	assert.Nil(t, err, fmt.Sprintf("expected no error got %s", err))
+	reqs, _ := rc.received()
+	assert.Empty(t, reqs, "expected samples to be buffered until the batch is full")
+
+	assert.Eventually(t, func() bool {
+		reqs, _ := rc.received()
+		return len(reqs) == 1
+	}, time.Second, time.Millisecond, "expected samples to be pushed after the flush interval")
+}
+
+func withValue(msg senml.Message, t float64, v *float64) senml.Message {
+	msg.Time = t
+	msg.Value = v
+	return msg
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package prometheus contains the writer pushing SenML messages to the
+// Prometheus remote-write endpoint.
+package prometheus
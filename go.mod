@@ -8,6 +8,7 @@ require (
 	github.com/docker/docker v20.10.6+incompatible
 	github.com/eclipse/paho.mqtt.golang v1.3.4
 	github.com/fatih/color v1.10.0
+	github.com/fxamacker/cbor/v2 v2.2.0
 	github.com/go-kit/kit v0.10.0
 	github.com/go-redis/redis/v8 v8.8.2
 	github.com/go-zoo/bone v1.3.0
@@ -15,6 +16,7 @@ require (
 	github.com/gofrs/uuid v4.0.0+incompatible
 	github.com/gogo/protobuf v1.3.2
 	github.com/golang/protobuf v1.4.3
+	github.com/golang/snappy v0.0.1
 	github.com/gopcua/opcua v0.1.6
 	github.com/hashicorp/vault/api v1.1.0
 	github.com/hokaccha/go-prettyjson v0.0.0-20210113012101-fb4e108d2519
@@ -34,6 +36,7 @@ require (
 	github.com/prometheus/client_golang v1.10.0
 	github.com/rubenv/sql-migrate v0.0.0-20210408115534-a32ed26c37ea
 	github.com/spf13/cobra v1.1.3
+	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.7.0
 	github.com/subosito/gotenv v1.2.0
@@ -41,8 +44,11 @@ require (
 	go.mongodb.org/mongo-driver v1.4.0-beta2.0.20210512200446-5f449ba049cc
 	golang.org/x/crypto v0.0.0-20210314154223-e6e6c4f2bb5b
 	golang.org/x/net v0.0.0-20210510120150-4163338589ed
-	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba // indirect
+	golang.org/x/sync v0.0.0-20201207232520-09787c993a3a
+	golang.org/x/sys v0.0.0-20210423082822-04245dca01da
+	golang.org/x/time v0.0.0-20210220033141-f8bda1e9f3ba
 	golang.org/x/tools v0.1.0 // indirect
 	gonum.org/v1/gonum v0.9.1
 	google.golang.org/grpc v1.36.0
+	google.golang.org/protobuf v1.25.0
 )
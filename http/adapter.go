@@ -9,13 +9,44 @@ import (
 	"context"
 
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/internal/topics"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/things/authz"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Verdict is the result of the publish validation.
+type Verdict string
+
+const (
+	// VerdictOK indicates the thing key allowed to publish to the channel.
+	VerdictOK Verdict = "ok"
+
+	// VerdictUnknownKey indicates the thing key that doesn't exist.
+	VerdictUnknownKey Verdict = "unknown_key"
+
+	// VerdictNotConnected indicates the thing not connected to the channel.
+	// It's also the verdict for the non-existent channel, so the existence
+	// of the channel is never revealed.
+	VerdictNotConnected Verdict = "not_connected"
+
+	// VerdictMalformedSubtopic indicates the subtopic that would be rejected
+	// on publish.
+	VerdictMalformedSubtopic Verdict = "malformed_subtopic"
 )
 
 // Service specifies coap service API.
 type Service interface {
 	// Publish Messssage
 	Publish(ctx context.Context, token string, msg messaging.Message) error
+
+	// Validate checks whether the thing identified by the key can publish
+	// to the channel subtopic, using the same checks as Publish, without
+	// publishing a message. The error is returned only if the verdict can't
+	// be reached, e.g. when the things service is unavailable.
+	Validate(ctx context.Context, token, chanID, subtopic string) (Verdict, error)
 }
 
 var _ Service = (*adapterService)(nil)
@@ -34,15 +65,74 @@ func New(publisher messaging.Publisher, things mainflux.ThingsServiceClient) Ser
 }
 
 func (as *adapterService) Publish(ctx context.Context, token string, msg messaging.Message) error {
+	ctx = authz.WithAccess(ctx, authz.Access{Action: authz.Publish, Subtopic: msg.Subtopic})
+	thid, err := as.authorize(ctx, token, msg.Channel)
+	if err != nil {
+		return err
+	}
+	msg.Publisher = thid
+	if id := reqctx.RequestID(ctx); id != "" {
+		if msg.Headers == nil {
+			msg.Headers = map[string]string{}
+		}
+		msg.Headers[messaging.RequestIDHeader] = id
+	}
+
+	return as.publisher.Publish(msg.Channel, msg)
+}
+
+func (as *adapterService) Validate(ctx context.Context, token, chanID, subtopic string) (Verdict, error) {
+	// The key is checked first, so the unknown keys can't tell the
+	// malformed subtopic or the missing channel.
+	if token == "" {
+		return VerdictUnknownKey, nil
+	}
+	if _, err := as.things.Identify(ctx, &mainflux.Token{Value: token}); err != nil {
+		if denied(err) {
+			return VerdictUnknownKey, nil
+		}
+		return "", err
+	}
+
+	st, err := topics.ParseSubtopic(subtopic)
+	if err != nil {
+		return VerdictMalformedSubtopic, nil
+	}
+
+	ctx = authz.WithAccess(ctx, authz.Access{Action: authz.Publish, Subtopic: st})
+	if _, err := as.authorize(ctx, token, chanID); err != nil {
+		if denied(err) {
+			return VerdictNotConnected, nil
+		}
+		return "", err
+	}
+	return VerdictOK, nil
+}
+
+func (as *adapterService) authorize(ctx context.Context, token, chanID string) (string, error) {
 	ar := &mainflux.AccessByKeyReq{
 		Token:  token,
-		ChanID: msg.Channel,
+		ChanID: chanID,
 	}
 	thid, err := as.things.CanAccessByKey(ctx, ar)
 	if err != nil {
-		return err
+		return "", err
 	}
-	msg.Publisher = thid.GetValue()
+	reqctx.SetSubject(ctx, reqctx.Thing(thid.GetValue()))
+	return thid.GetValue(), nil
+}
 
-	return as.publisher.Publish(msg.Channel, msg)
+// denied returns true for the things service errors denying the access, as
+// opposed to the ones preventing the decision.
+func denied(err error) bool {
+	e, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch e.Code() {
+	case codes.NotFound, codes.PermissionDenied, codes.Unauthenticated, codes.InvalidArgument:
+		return true
+	default:
+		return false
+	}
 }
@@ -8,12 +8,33 @@ import (
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/mainflux/mainflux/http"
+	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
 func sendMessageEndpoint(svc http.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(publishReq)
-		err := svc.Publish(ctx, req.token, req.msg)
-		return nil, err
+		if err := svc.Publish(ctx, req.token, req.msg); err != nil {
+			return nil, err
+		}
+		if req.sync {
+			return publishRes{Token: messaging.NewToken(req.msg).String()}, nil
+		}
+		return nil, nil
+	}
+}
+
+func validateEndpoint(svc http.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(validateReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		verdict, err := svc.Validate(ctx, req.ThingKey, req.ChanID, req.Subtopic)
+		if err != nil {
+			return nil, err
+		}
+		return validateRes{Valid: verdict == http.VerdictOK, Verdict: verdict}, nil
 	}
 }
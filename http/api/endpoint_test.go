@@ -4,20 +4,35 @@
 package api_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/opentracing/opentracing-go/mocktracer"
 
+	"github.com/go-kit/kit/metrics"
 	"github.com/mainflux/mainflux"
 	adapter "github.com/mainflux/mainflux/http"
 	"github.com/mainflux/mainflux/http/api"
 	"github.com/mainflux/mainflux/http/mocks"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/internal/topics"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/shedding"
+	"github.com/mainflux/mainflux/pkg/quota"
+	quotamocks "github.com/mainflux/mainflux/pkg/quota/mocks"
+	"github.com/mainflux/mainflux/things/authz"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 )
 
 func newService(cc mainflux.ThingsServiceClient) adapter.Service {
@@ -25,8 +40,10 @@ func newService(cc mainflux.ThingsServiceClient) adapter.Service {
 	return adapter.New(pub, cc)
 }
 
+const tenantHeader = "X-Tenant"
+
 func newHTTPServer(svc adapter.Service) *httptest.Server {
-	mux := api.MakeHandler(svc, mocktracer.New())
+	mux := api.MakeHandler(svc, mocktracer.New(), tenantHeader, topics.Default(), nil, nil)
 	return httptest.NewServer(mux)
 }
 
@@ -36,6 +53,7 @@ type testRequest struct {
 	url         string
 	contentType string
 	token       string
+	headers     map[string]string
 	body        io.Reader
 }
 
@@ -50,6 +68,9 @@ func (tr testRequest) make() (*http.Response, error) {
 	if tr.contentType != "" {
 		req.Header.Set("Content-Type", tr.contentType)
 	}
+	for k, v := range tr.headers {
+		req.Header.Set(k, v)
+	}
 	return tr.client.Do(req)
 }
 
@@ -129,3 +150,622 @@ func TestPublish(t *testing.T) {
 		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", desc, tc.status, res.StatusCode))
 	}
 }
+
+// blockingPublisher publishes when released.
+type blockingPublisher struct {
+	release chan struct{}
+}
+
+func (p blockingPublisher) Publish(topic string, msg messaging.Message) error {
+	<-p.release
+	return nil
+}
+
+type nopCounter struct{}
+
+func (c nopCounter) With(...string) metrics.Counter { return c }
+
+func (c nopCounter) Add(float64) {}
+
+func TestPublishShedding(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	bp := blockingPublisher{release: make(chan struct{})}
+	cfg := shedding.Config{Deadline: 10 * time.Millisecond, MaxInFlight: 1}
+	svc := adapter.New(shedding.NewPublisher(bp, cfg, nopCounter{}), thingsClient)
+	ts := newHTTPServer(svc)
+	defer ts.Close()
+
+	cases := []struct {
+		desc    string
+		release bool
+		status  int
+		err     error
+	}{
+		{
+			desc:   "publish message exceeding deadline",
+			status: http.StatusServiceUnavailable,
+			err:    shedding.ErrDeadline,
+		},
+		{
+			desc:   "publish message over in-flight limit",
+			status: http.StatusServiceUnavailable,
+			err:    shedding.ErrOverloaded,
+		},
+		{
+			desc:    "publish message after stuck publish returns",
+			release: true,
+			status:  http.StatusAccepted,
+		},
+	}
+
+	for _, tc := range cases {
+		if tc.release {
+			close(bp.release)
+			// Wait for the stuck publish to release its slot.
+			time.Sleep(50 * time.Millisecond)
+		}
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			contentType: "application/senml+json",
+			token:       token,
+			body:        strings.NewReader(msg),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.err != nil {
+			body, err := ioutil.ReadAll(res.Body)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.JSONEq(t, fmt.Sprintf(`{"error":"%s"}`, tc.err), string(body), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, body))
+		}
+		res.Body.Close()
+	}
+}
+
+func TestPublishQuota(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	store := quotamocks.NewStore(map[string]uint64{chanID: 2})
+	checker := quota.NewChecker(store, quota.Config{Batch: 10}, time.Now)
+	ts := newHTTPServer(adapter.New(quota.NewPublisher(mocks.NewPublisher(), checker), thingsClient))
+	defer ts.Close()
+
+	cases := []struct {
+		desc   string
+		status int
+	}{
+		{
+			desc:   "publish message under quota",
+			status: http.StatusAccepted,
+		},
+		{
+			desc:   "publish last message of quota",
+			status: http.StatusAccepted,
+		},
+		{
+			desc:   "publish message over quota",
+			status: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			contentType: "application/senml+json",
+			token:       token,
+			body:        strings.NewReader(msg),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusTooManyRequests {
+			continue
+		}
+
+		var body struct {
+			Reset time.Time `json:"reset"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		now := time.Now().UTC()
+		reset := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1, 0)
+		assert.True(t, reset.Equal(body.Reset), fmt.Sprintf("%s: expected reset at %s got %s", tc.desc, reset, body.Reset))
+		assert.NotEmpty(t, res.Header.Get("Retry-After"), fmt.Sprintf("%s: expected Retry-After header", tc.desc))
+	}
+}
+
+// recordingPublisher stores the last published message.
+type recordingPublisher struct {
+	mu  sync.Mutex
+	msg messaging.Message
+}
+
+func (p *recordingPublisher) Publish(topic string, msg messaging.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.msg = msg
+	return nil
+}
+
+func (p *recordingPublisher) last() messaging.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.msg
+}
+
+func TestPublishTenant(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	pub := &recordingPublisher{}
+	untrusted := newHTTPServer(adapter.New(pub, thingsClient))
+	defer untrusted.Close()
+
+	// The test client connects over loopback, acting as the trusted proxy.
+	proxies, err := reqctx.ParseProxies([]string{"127.0.0.1"})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	trusted := httptest.NewServer(api.MakeHandler(adapter.New(pub, thingsClient), mocktracer.New(), tenantHeader, topics.Default(), nil, proxies))
+	defer trusted.Close()
+
+	cases := []struct {
+		desc    string
+		server  *httptest.Server
+		headers map[string]string
+		tenant  string
+	}{
+		{
+			desc:    "publish message with tenant header",
+			server:  trusted,
+			headers: map[string]string{tenantHeader: "acme"},
+			tenant:  "acme",
+		},
+		{
+			desc:    "publish message with blank tenant header",
+			server:  trusted,
+			headers: map[string]string{tenantHeader: " "},
+			tenant:  messaging.DefaultTenant,
+		},
+		{
+			desc:    "publish message without tenant header",
+			server:  trusted,
+			headers: nil,
+			tenant:  messaging.DefaultTenant,
+		},
+		{
+			desc:    "publish message with message tenant header",
+			server:  trusted,
+			headers: map[string]string{messaging.TenantHeader: "acme"},
+			tenant:  messaging.DefaultTenant,
+		},
+		{
+			desc:    "publish message with tenant header from untrusted address",
+			server:  untrusted,
+			headers: map[string]string{tenantHeader: "acme"},
+			tenant:  messaging.DefaultTenant,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      tc.server.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/messages", tc.server.URL, chanID),
+			contentType: "application/senml+json",
+			token:       token,
+			headers:     tc.headers,
+			body:        strings.NewReader(msg),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, http.StatusAccepted, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusAccepted, res.StatusCode))
+		tenant := messaging.Tenant(pub.last())
+		assert.Equal(t, tc.tenant, tenant, fmt.Sprintf("%s: expected tenant %s got %s", tc.desc, tc.tenant, tenant))
+	}
+}
+
+func TestPublishSync(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	pub := &recordingPublisher{}
+	ts := newHTTPServer(adapter.New(pub, thingsClient))
+	defer ts.Close()
+
+	cases := []struct {
+		desc   string
+		query  string
+		status int
+		sync   bool
+	}{
+		{
+			desc:   "publish message in sync mode",
+			query:  "?sync=true",
+			status: http.StatusAccepted,
+			sync:   true,
+		},
+		{
+			desc:   "publish message with sync mode disabled",
+			query:  "?sync=false",
+			status: http.StatusAccepted,
+			sync:   false,
+		},
+		{
+			desc:   "publish message without sync mode",
+			query:  "",
+			status: http.StatusAccepted,
+			sync:   false,
+		},
+		{
+			desc:   "publish message with invalid sync mode",
+			query:  "?sync=invalid",
+			status: http.StatusBadRequest,
+			sync:   false,
+		},
+	}
+
+	for _, tc := range cases {
+		pub.Publish("", messaging.Message{})
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/channels/%s/messages%s", ts.URL, chanID, tc.query),
+			contentType: "application/senml+json",
+			token:       token,
+			body:        strings.NewReader(msg),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		var body struct {
+			Token string `json:"token"`
+		}
+		json.NewDecoder(res.Body).Decode(&body)
+		published := pub.last()
+		if !tc.sync {
+			assert.Empty(t, body.Token, fmt.Sprintf("%s: expected no token got %s", tc.desc, body.Token))
+			assert.Empty(t, messaging.ID(published), fmt.Sprintf("%s: expected no message ID got %s", tc.desc, messaging.ID(published)))
+			continue
+		}
+		assert.NotEmpty(t, messaging.ID(published), fmt.Sprintf("%s: expected message ID to be set", tc.desc))
+		token := messaging.NewToken(published).String()
+		assert.Equal(t, token, body.Token, fmt.Sprintf("%s: expected token %s got %s", tc.desc, token, body.Token))
+	}
+}
+
+func TestPublishTopicTemplate(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	msg := `[{"n":"current","t":-1,"v":1.6}]`
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	pub := &recordingPublisher{}
+	tmpl, err := topics.New("devices/{channel}/{subtopic}/data")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	ts := httptest.NewServer(api.MakeHandler(adapter.New(pub, thingsClient), mocktracer.New(), tenantHeader, tmpl, nil, nil))
+	defer ts.Close()
+
+	cases := []struct {
+		desc     string
+		url      string
+		status   int
+		subtopic string
+	}{
+		{
+			desc:   "publish message to custom topic",
+			url:    fmt.Sprintf("%s/devices/%s/data", ts.URL, chanID),
+			status: http.StatusAccepted,
+		},
+		{
+			desc:     "publish message to custom topic with subtopic",
+			url:      fmt.Sprintf("%s/devices/%s/a/b/data", ts.URL, chanID),
+			status:   http.StatusAccepted,
+			subtopic: "a.b",
+		},
+		{
+			desc:   "publish message to default topic",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         tc.url,
+			contentType: "application/senml+json",
+			token:       token,
+			body:        strings.NewReader(msg),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status == http.StatusAccepted {
+			sent := pub.last()
+			assert.Equal(t, chanID, sent.Channel, fmt.Sprintf("%s: expected channel %s got %s", tc.desc, chanID, sent.Channel))
+			assert.Equal(t, tc.subtopic, sent.Subtopic, fmt.Sprintf("%s: expected subtopic %s got %s", tc.desc, tc.subtopic, sent.Subtopic))
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	contentType := "application/json"
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	pub := &recordingPublisher{}
+	svc := adapter.New(pub, thingsClient)
+	ts := httptest.NewServer(api.MakeHandler(svc, mocktracer.New(), tenantHeader, topics.Default(), nil, nil))
+	defer ts.Close()
+
+	cases := []struct {
+		desc        string
+		body        string
+		auth        string
+		contentType string
+		status      int
+		res         string
+	}{
+		{
+			desc:        "validate connected thing key",
+			body:        fmt.Sprintf(`{"thing_key":"%s","channel_id":"%s","subtopic":"a.b"}`, token, chanID),
+			contentType: contentType,
+			status:      http.StatusOK,
+			res:         `{"valid":true,"verdict":"ok"}`,
+		},
+		{
+			desc:        "validate thing key from authorization header",
+			body:        fmt.Sprintf(`{"channel_id":"%s"}`, chanID),
+			auth:        token,
+			contentType: contentType,
+			status:      http.StatusOK,
+			res:         `{"valid":true,"verdict":"ok"}`,
+		},
+		{
+			desc:        "validate unknown thing key",
+			body:        fmt.Sprintf(`{"thing_key":"invalid","channel_id":"%s"}`, chanID),
+			contentType: contentType,
+			status:      http.StatusOK,
+			res:         `{"valid":false,"verdict":"unknown_key"}`,
+		},
+		{
+			desc:        "validate unknown thing key with malformed subtopic",
+			body:        fmt.Sprintf(`{"thing_key":"invalid","channel_id":"%s","subtopic":"a*b"}`, chanID),
+			contentType: contentType,
+			status:      http.StatusOK,
+			res:         `{"valid":false,"verdict":"unknown_key"}`,
+		},
+		{
+			desc:        "validate without thing key",
+			body:        fmt.Sprintf(`{"channel_id":"%s"}`, chanID),
+			contentType: contentType,
+			status:      http.StatusOK,
+			res:         `{"valid":false,"verdict":"unknown_key"}`,
+		},
+		{
+			desc:        "validate thing key not connected to channel",
+			body:        fmt.Sprintf(`{"thing_key":"%s","channel_id":"2"}`, token),
+			contentType: contentType,
+			status:      http.StatusOK,
+			res:         `{"valid":false,"verdict":"not_connected"}`,
+		},
+		{
+			desc:        "validate malformed subtopic",
+			body:        fmt.Sprintf(`{"thing_key":"%s","channel_id":"%s","subtopic":"a*b"}`, token, chanID),
+			contentType: contentType,
+			status:      http.StatusOK,
+			res:         `{"valid":false,"verdict":"malformed_subtopic"}`,
+		},
+		{
+			desc:        "validate without channel",
+			body:        fmt.Sprintf(`{"thing_key":"%s"}`, token),
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "validate malformed request",
+			body:        "{",
+			contentType: contentType,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:   "validate without content type",
+			body:   fmt.Sprintf(`{"thing_key":"%s","channel_id":"%s"}`, token, chanID),
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:        "validate unable to authorize",
+			body:        fmt.Sprintf(`{"thing_key":"%s","channel_id":"%s"}`, mocks.ServiceErrToken, chanID),
+			contentType: contentType,
+			status:      http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/validate", ts.URL),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.body),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.res != "" {
+			body, err := ioutil.ReadAll(res.Body)
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.JSONEq(t, tc.res, string(body), fmt.Sprintf("%s: expected response %s got %s", tc.desc, tc.res, body))
+		}
+		res.Body.Close()
+		sent := pub.last()
+		assert.Empty(t, sent.Channel, fmt.Sprintf("%s: expected no message published got %v", tc.desc, sent))
+	}
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	thingsClient := mocks.NewThingsClient(map[string]string{token: chanID})
+	// Negligible rate allows only the burst of validations.
+	svc := api.ValidationLimitMiddleware(newService(thingsClient), 1e-6, 2)
+	// The test client connects over loopback, acting as the trusted proxy
+	// forwarding the requests of different clients.
+	proxies, err := reqctx.ParseProxies([]string{"127.0.0.1"})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	ts := httptest.NewServer(api.MakeHandler(svc, mocktracer.New(), tenantHeader, topics.Default(), nil, proxies))
+	defer ts.Close()
+
+	client := map[string]string{"X-Forwarded-For": "10.0.0.1"}
+	other := map[string]string{"X-Forwarded-For": "10.0.0.2"}
+
+	cases := []struct {
+		desc    string
+		url     string
+		headers map[string]string
+		body    string
+		status  int
+	}{
+		{
+			desc:    "validate within burst",
+			url:     fmt.Sprintf("%s/validate", ts.URL),
+			headers: client,
+			body:    fmt.Sprintf(`{"thing_key":"%s","channel_id":"%s"}`, token, chanID),
+			status:  http.StatusOK,
+		},
+		{
+			desc:    "validate unknown key within burst",
+			url:     fmt.Sprintf("%s/validate", ts.URL),
+			headers: client,
+			body:    fmt.Sprintf(`{"thing_key":"invalid","channel_id":"%s"}`, chanID),
+			status:  http.StatusOK,
+		},
+		{
+			desc:    "validate exceeding burst",
+			url:     fmt.Sprintf("%s/validate", ts.URL),
+			headers: client,
+			body:    fmt.Sprintf(`{"thing_key":"%s","channel_id":"%s"}`, token, chanID),
+			status:  http.StatusTooManyRequests,
+		},
+		{
+			desc:    "validate from another client within burst",
+			url:     fmt.Sprintf("%s/validate", ts.URL),
+			headers: other,
+			body:    fmt.Sprintf(`{"thing_key":"%s","channel_id":"%s"}`, token, chanID),
+			status:  http.StatusOK,
+		},
+		{
+			desc:    "publish exceeding validation burst",
+			url:     fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			headers: client,
+			body:    `[{"n":"current","t":-1,"v":1.6}]`,
+			status:  http.StatusAccepted,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         tc.url,
+			contentType: "application/json",
+			token:       token,
+			headers:     tc.headers,
+			body:        strings.NewReader(tc.body),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+// accessClient records the channel access the things service is asked to
+// authorize.
+type accessClient struct {
+	mainflux.ThingsServiceClient
+
+	mu     sync.Mutex
+	access []authz.Access
+}
+
+func (ac *accessClient) CanAccessByKey(ctx context.Context, req *mainflux.AccessByKeyReq, opts ...grpc.CallOption) (*mainflux.ThingID, error) {
+	ac.mu.Lock()
+	ac.access = append(ac.access, authz.AccessFromContext(ctx))
+	ac.mu.Unlock()
+	return ac.ThingsServiceClient.CanAccessByKey(ctx, req, opts...)
+}
+
+func (ac *accessClient) last() authz.Access {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if len(ac.access) == 0 {
+		return authz.Access{}
+	}
+	return ac.access[len(ac.access)-1]
+}
+
+func TestAuthorizeAccess(t *testing.T) {
+	chanID := "1"
+	token := "auth_token"
+	thingsClient := &accessClient{ThingsServiceClient: mocks.NewThingsClient(map[string]string{token: chanID})}
+	ts := newHTTPServer(newService(thingsClient))
+	defer ts.Close()
+
+	cases := []struct {
+		desc   string
+		url    string
+		body   string
+		status int
+		access authz.Access
+	}{
+		{
+			desc:   "publish to channel",
+			url:    fmt.Sprintf("%s/channels/%s/messages", ts.URL, chanID),
+			body:   `[{"n":"current","t":-1,"v":1.6}]`,
+			status: http.StatusAccepted,
+			access: authz.Access{Action: authz.Publish},
+		},
+		{
+			desc:   "publish to subtopic",
+			url:    fmt.Sprintf("%s/channels/%s/messages/data/temperature", ts.URL, chanID),
+			body:   `[{"n":"current","t":-1,"v":1.6}]`,
+			status: http.StatusAccepted,
+			access: authz.Access{Action: authz.Publish, Subtopic: "data.temperature"},
+		},
+		{
+			desc:   "validate subtopic",
+			url:    fmt.Sprintf("%s/validate", ts.URL),
+			body:   fmt.Sprintf(`{"thing_key":"%s","channel_id":"%s","subtopic":"data/humidity"}`, token, chanID),
+			status: http.StatusOK,
+			access: authz.Access{Action: authz.Publish, Subtopic: "data.humidity"},
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPost,
+			url:         tc.url,
+			contentType: "application/json",
+			token:       token,
+			body:        strings.NewReader(tc.body),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		access := thingsClient.last()
+		assert.Equal(t, tc.access, access, fmt.Sprintf("%s: expected access %v got %v", tc.desc, tc.access, access))
+	}
+}
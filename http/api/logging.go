@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/mainflux/mainflux/http"
+	"github.com/mainflux/mainflux/internal/reqctx"
 	log "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging"
 )
@@ -34,6 +35,7 @@ func (lm *loggingMiddleware) Publish(ctx context.Context, token string, msg mess
 			destChannel = fmt.Sprintf("%s.%s", destChannel, msg.Subtopic)
 		}
 		message := fmt.Sprintf("Method publish to channel %s took %s to complete", destChannel, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -43,3 +45,17 @@ func (lm *loggingMiddleware) Publish(ctx context.Context, token string, msg mess
 
 	return lm.svc.Publish(ctx, token, msg)
 }
+
+func (lm *loggingMiddleware) Validate(ctx context.Context, token, chanID, subtopic string) (verdict http.Verdict, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method validate for channel %s took %s to complete", chanID, time.Since(begin))
+		message += reqctx.Describe(ctx)
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s with verdict %s.", message, verdict))
+	}(time.Now())
+
+	return lm.svc.Validate(ctx, token, chanID, subtopic)
+}
@@ -39,3 +39,12 @@ func (mm *metricsMiddleware) Publish(ctx context.Context, token string, msg mess
 
 	return mm.svc.Publish(ctx, token, msg)
 }
+
+func (mm *metricsMiddleware) Validate(ctx context.Context, token, chanID, subtopic string) (http.Verdict, error) {
+	defer func(begin time.Time) {
+		mm.counter.With("method", "validate").Add(1)
+		mm.latency.With("method", "validate").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return mm.svc.Validate(ctx, token, chanID, subtopic)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/http"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"golang.org/x/time/rate"
+)
+
+// maxValidationClients is the maximum number of clients whose validation
+// rate is tracked at once.
+const maxValidationClients = 10000
+
+// ErrTooManyValidations indicates the validation requests over the rate
+// limit.
+var ErrTooManyValidations = errors.New("too many validation requests")
+
+var _ http.Service = (*validationLimiter)(nil)
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type validationLimiter struct {
+	rate  rate.Limit
+	burst int
+	idle  time.Duration
+	svc   http.Service
+
+	mu      sync.Mutex
+	clients map[string]*clientLimiter
+}
+
+// ValidationLimitMiddleware limits the rate of the validation requests of
+// each client, so the validation can't be used to probe the thing keys.
+// Clients are identified by the remote address of the request. Publishing
+// isn't limited. Non-positive rate disables the limit.
+func ValidationLimitMiddleware(svc http.Service, r float64, burst int) http.Service {
+	if r <= 0 {
+		return svc
+	}
+	return &validationLimiter{
+		rate:    rate.Limit(r),
+		burst:   burst,
+		idle:    time.Duration(float64(burst) / r * float64(time.Second)),
+		svc:     svc,
+		clients: make(map[string]*clientLimiter),
+	}
+}
+
+func (vl *validationLimiter) Publish(ctx context.Context, token string, msg messaging.Message) error {
+	return vl.svc.Publish(ctx, token, msg)
+}
+
+func (vl *validationLimiter) Validate(ctx context.Context, token, chanID, subtopic string) (http.Verdict, error) {
+	info, _ := reqctx.FromContext(ctx)
+	if !vl.limiter(info.RemoteAddr, time.Now()).Allow() {
+		return "", ErrTooManyValidations
+	}
+	return vl.svc.Validate(ctx, token, chanID, subtopic)
+}
+
+func (vl *validationLimiter) limiter(client string, now time.Time) *rate.Limiter {
+	vl.mu.Lock()
+	defer vl.mu.Unlock()
+
+	c, ok := vl.clients[client]
+	if !ok {
+		if len(vl.clients) >= maxValidationClients {
+			vl.evict(now)
+		}
+		c = &clientLimiter{limiter: rate.NewLimiter(vl.rate, vl.burst)}
+		vl.clients[client] = c
+	}
+	c.lastSeen = now
+	return c.limiter
+}
+
+// evict removes the clients idle long enough to refill their burst, since
+// their new limiters are equivalent. If none of them is, the least recently
+// seen client is removed.
+func (vl *validationLimiter) evict(now time.Time) {
+	var oldest string
+	var lastSeen time.Time
+	for id, c := range vl.clients {
+		if now.Sub(c.lastSeen) >= vl.idle {
+			delete(vl.clients, id)
+			continue
+		}
+		if lastSeen.IsZero() || c.lastSeen.Before(lastSeen) {
+			oldest, lastSeen = id, c.lastSeen
+		}
+	}
+	if len(vl.clients) >= maxValidationClients {
+		delete(vl.clients, oldest)
+	}
+}
@@ -10,4 +10,18 @@ import (
 type publishReq struct {
 	msg   messaging.Message
 	token string
+	sync  bool
+}
+
+type validateReq struct {
+	ThingKey string `json:"thing_key"`
+	ChanID   string `json:"channel_id"`
+	Subtopic string `json:"subtopic"`
+}
+
+func (req validateReq) validate() error {
+	if req.ChanID == "" {
+		return errMalformedData
+	}
+	return nil
 }
@@ -0,0 +1,31 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package api
+
+import (
+	"time"
+
+	adapter "github.com/mainflux/mainflux/http"
+)
+
+type validateRes struct {
+	Valid   bool            `json:"valid"`
+	Verdict adapter.Verdict `json:"verdict"`
+}
+
+// quotaRes is the response to the message over the channel quota.
+type quotaRes struct {
+	Error string    `json:"error"`
+	Reset time.Time `json:"reset"`
+}
+
+// errorRes is the response to the shed message.
+type errorRes struct {
+	Error string `json:"error"`
+}
+
+// publishRes is the response to the message published in the sync mode.
+type publishRes struct {
+	Token string `json:"token"`
+}
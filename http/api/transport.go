@@ -5,12 +5,13 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
-	"net/url"
-	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,7 +20,13 @@ import (
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
 	adapter "github.com/mainflux/mainflux/http"
+	"github.com/mainflux/mainflux/internal/httputil"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/internal/topics"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/shedding"
+	"github.com/mainflux/mainflux/pkg/quota"
+	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/mainflux/mainflux/things"
 	opentracing "github.com/opentracing/opentracing-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -27,102 +34,156 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-const protocol = "http"
+const (
+	protocol     = "http"
+	contentType  = "application/json"
+	validatePath = "/validate"
+	syncKey      = "sync"
+)
 
 var (
-	errMalformedData     = errors.New("malformed request data")
-	errMalformedSubtopic = errors.New("malformed subtopic")
+	errMalformedData          = errors.New("malformed request data")
+	errUnsupportedContentType = errors.New("unsupported content type")
+	idProvider                = uuid.New()
 )
 
-var channelPartRegExp = regexp.MustCompile(`^/channels/([\w\-]+)/messages(/[^?]*)?(\?.*)?$`)
-
-// MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler(svc adapter.Service, tracer opentracing.Tracer) http.Handler {
+// MakeHandler returns a HTTP handler for API endpoints. The messages are
+// published to the paths set by the topic template. If the tenant header
+// is set, its value is used as the tenant of the published messages sent
+// by the trusted proxies, and ignored for the rest of the requests. If the
+// health handler is set, it's served at the /health path. The messages
+// published with the sync query parameter get the ID, and the consistency
+// token the readers wait for the message with is returned. The remote
+// address of the requests sent by the trusted proxies is taken from the
+// X-Forwarded-For header.
+func MakeHandler(svc adapter.Service, tracer opentracing.Tracer, tenantHeader string, tmpl topics.Template, health http.Handler, proxies reqctx.Proxies) http.Handler {
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
 	}
 
-	r := bone.New()
-	r.Post("/channels/:id/messages", kithttp.NewServer(
+	publish := kithttp.NewServer(
 		kitot.TraceServer(tracer, "publish")(sendMessageEndpoint(svc)),
-		decodeRequest,
+		decodeRequest(tenantHeader, tmpl, proxies),
 		encodeResponse,
 		opts...,
-	))
+	)
 
-	r.Post("/channels/:id/messages/*", kithttp.NewServer(
-		kitot.TraceServer(tracer, "publish")(sendMessageEndpoint(svc)),
-		decodeRequest,
-		encodeResponse,
+	validate := kithttp.NewServer(
+		kitot.TraceServer(tracer, "validate")(validateEndpoint(svc)),
+		decodeValidate,
+		encodeValidateResponse,
 		opts...,
-	))
+	)
 
+	r := bone.New()
 	r.GetFunc("/version", mainflux.Version("http"))
 	r.Handle("/metrics", promhttp.Handler())
+	if health != nil {
+		r.Get("/health", health)
+	}
+	r.Post(validatePath, validate)
 
-	return r
+	return reqctx.Middleware(routePublish(publish, r), proxies)
 }
 
-func parseSubtopic(subtopic string) (string, error) {
-	if subtopic == "" {
-		return subtopic, nil
-	}
+// routePublish passes the POST requests to the publish handler, since their
+// paths are set by the topic template, and the rest to the next handler.
+// The paths not matching the template are rejected as malformed.
+func routePublish(publish, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path != validatePath {
+			publish.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-	subtopic, err := url.QueryUnescape(subtopic)
-	if err != nil {
-		return "", errMalformedSubtopic
-	}
-	subtopic = strings.Replace(subtopic, "/", ".", -1)
+func decodeRequest(tenantHeader string, tmpl topics.Template, proxies reqctx.Proxies) kithttp.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		chanID, subtopic, err := tmpl.Parse(r.RequestURI)
+		if err != nil {
+			if err == topics.ErrMalformedSubtopic {
+				return nil, err
+			}
+			return nil, errMalformedData
+		}
 
-	elems := strings.Split(subtopic, ".")
-	filteredElems := []string{}
-	for _, elem := range elems {
-		if elem == "" {
-			continue
+		sync, err := httputil.ReadBoolQuery(r, syncKey, false)
+		if err != nil {
+			return nil, errMalformedData
 		}
 
-		if len(elem) > 1 && (strings.Contains(elem, "*") || strings.Contains(elem, ">")) {
-			return "", errMalformedSubtopic
+		payload, err := decodePayload(r.Body)
+		if err != nil {
+			return nil, err
 		}
 
-		filteredElems = append(filteredElems, elem)
-	}
+		msg := messaging.Message{
+			Protocol: protocol,
+			Channel:  chanID,
+			Subtopic: subtopic,
+			Payload:  payload,
+			Created:  time.Now().UnixNano(),
+			Headers:  decodeHeaders(r, tenantHeader, proxies),
+		}
+		if sync {
+			id, err := idProvider.ID()
+			if err != nil {
+				return nil, err
+			}
+			if msg.Headers == nil {
+				msg.Headers = map[string]string{}
+			}
+			msg.Headers[messaging.IDHeader] = id
+		}
 
-	subtopic = strings.Join(filteredElems, ".")
-	return subtopic, nil
-}
+		req := publishReq{
+			msg:   msg,
+			token: r.Header.Get("Authorization"),
+			sync:  sync,
+		}
 
-func decodeRequest(ctx context.Context, r *http.Request) (interface{}, error) {
-	channelParts := channelPartRegExp.FindStringSubmatch(r.RequestURI)
-	if len(channelParts) < 2 {
-		return nil, errMalformedData
+		return req, nil
 	}
+}
 
-	chanID := bone.GetValue(r, "id")
-	subtopic, err := parseSubtopic(channelParts[2])
-	if err != nil {
-		return nil, err
+func decodeValidate(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errUnsupportedContentType
 	}
 
-	payload, err := decodePayload(r.Body)
-	if err != nil {
-		return nil, err
+	var req validateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, errMalformedData
 	}
-
-	msg := messaging.Message{
-		Protocol: protocol,
-		Channel:  chanID,
-		Subtopic: subtopic,
-		Payload:  payload,
-		Created:  time.Now().UnixNano(),
+	if req.ThingKey == "" {
+		req.ThingKey = r.Header.Get("Authorization")
 	}
 
-	req := publishReq{
-		msg:   msg,
-		token: r.Header.Get("Authorization"),
+	return req, nil
+}
+
+// decodeHeaders copies the message headers from the request. The tenant
+// header is honored only if the request is sent by a trusted proxy, since
+// the clients could otherwise publish messages on behalf of other tenants.
+func decodeHeaders(r *http.Request, tenantHeader string, proxies reqctx.Proxies) map[string]string {
+	headers := map[string]string{}
+	for _, k := range []string{messaging.ContentTypeHeader, messaging.ContentEncodingHeader} {
+		if v := r.Header.Get(k); v != "" {
+			headers[k] = v
+		}
+	}
+	if tenantHeader != "" && proxies.Trusted(r) {
+		if v := strings.TrimSpace(r.Header.Get(tenantHeader)); v != "" {
+			headers[messaging.TenantHeader] = v
+		}
+	}
+	if len(headers) == 0 {
+		return nil
 	}
 
-	return req, nil
+	return headers
 }
 
 func decodePayload(body io.ReadCloser) ([]byte, error) {
@@ -136,16 +197,44 @@ func decodePayload(body io.ReadCloser) ([]byte, error) {
 }
 
 func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	if res, ok := response.(publishRes); ok {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusAccepted)
+		return json.NewEncoder(w).Encode(res)
+	}
 	w.WriteHeader(http.StatusAccepted)
 	return nil
 }
 
+func encodeValidateResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
+	w.Header().Set("Content-Type", contentType)
+	return json.NewEncoder(w).Encode(response)
+}
+
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
+	// The client over the quota is told when to retry.
+	if reset, ok := quota.IsExceeded(err); ok {
+		retry := int(math.Ceil(time.Until(reset).Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(quotaRes{Error: err.Error(), Reset: reset})
+		return
+	}
+
 	switch err {
-	case errMalformedData, errMalformedSubtopic:
+	case errMalformedData, topics.ErrMalformedSubtopic:
 		w.WriteHeader(http.StatusBadRequest)
+	case errUnsupportedContentType:
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	case ErrTooManyValidations:
+		w.WriteHeader(http.StatusTooManyRequests)
 	case things.ErrUnauthorizedAccess:
 		w.WriteHeader(http.StatusForbidden)
+	case shedding.ErrDeadline, shedding.ErrOverloaded:
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(errorRes{Error: err.Error()})
 	default:
 		if e, ok := status.FromError(err); ok {
 			switch e.Code() {
@@ -24,6 +24,7 @@ type thingsClient struct {
 }
 
 // NewThingsClient returns mock implementation of things service client.
+// The data maps the thing keys to the channels the things are connected to.
 func NewThingsClient(data map[string]string) mainflux.ThingsServiceClient {
 	return &thingsClient{data}
 }
@@ -46,6 +47,9 @@ func (tc thingsClient) CanAccessByKey(ctx context.Context, req *mainflux.AccessB
 	if !ok {
 		return nil, status.Error(codes.PermissionDenied, "invalid credentials provided")
 	}
+	if req.GetChanID() != id {
+		return nil, status.Error(codes.PermissionDenied, "thing is not connected to the channel")
+	}
 
 	return &mainflux.ThingID{Value: id}, nil
 }
@@ -59,5 +63,15 @@ func (tc thingsClient) IsChannelOwner(context.Context, *mainflux.ChannelOwnerReq
 }
 
 func (tc thingsClient) Identify(ctx context.Context, req *mainflux.Token, opts ...grpc.CallOption) (*mainflux.ThingID, error) {
-	panic("not implemented")
+	key := req.GetValue()
+	if key == ServiceErrToken {
+		return nil, status.Error(codes.Internal, "internal server error")
+	}
+
+	id, ok := tc.things[key]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "entity does not exist")
+	}
+
+	return &mainflux.ThingID{Value: id}, nil
 }
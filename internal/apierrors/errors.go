@@ -0,0 +1,166 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package apierrors contains the structured errors shared by the services
+// and the mappers translating them to HTTP and gRPC status codes.
+package apierrors
+
+import (
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// Code is a stable identifier of the error category.
+type Code string
+
+const (
+	// CodeAuthentication indicates missing or invalid credentials.
+	CodeAuthentication Code = "authentication"
+
+	// CodeAuthorization indicates that the credentials don't grant access
+	// to the requested resource.
+	CodeAuthorization Code = "authorization"
+
+	// CodeMalformedEntity indicates malformed entity specification.
+	CodeMalformedEntity Code = "malformed_entity"
+
+	// CodeUnsupportedContentType indicates unacceptable or lack of Content-Type.
+	CodeUnsupportedContentType Code = "unsupported_content_type"
+
+	// CodeNotFound indicates a non-existent entity request.
+	CodeNotFound Code = "not_found"
+
+	// CodeConflict indicates that entity already exists.
+	CodeConflict Code = "conflict"
+
+	// CodeUnprocessableEntity indicates that stored entity can't be processed.
+	CodeUnprocessableEntity Code = "unprocessable_entity"
+
+	// CodeInvalidRequest indicates that the requested operation failed
+	// due to the request.
+	CodeInvalidRequest Code = "invalid_request"
+
+	// CodeUnavailable indicates that a dependency of the service is unavailable.
+	CodeUnavailable Code = "unavailable"
+
+	// CodeInternal indicates an unexpected internal error.
+	CodeInternal Code = "internal"
+)
+
+var _ errors.Error = (*Error)(nil)
+
+// Error represents an error with a stable code, message and wrapped cause.
+// It implements errors.Error, so errors.Wrap and errors.Contains work with
+// it the same way they do with the errors created using errors.New.
+type Error struct {
+	code  Code
+	msg   string
+	cause error
+}
+
+// New returns an Error with the given code and message.
+func New(code Code, msg string) *Error {
+	return &Error{
+		code: code,
+		msg:  msg,
+	}
+}
+
+// MalformedEntity returns malformed entity error.
+func MalformedEntity(msg string) *Error {
+	return New(CodeMalformedEntity, msg)
+}
+
+// Authentication returns authentication error.
+func Authentication(msg string) *Error {
+	return New(CodeAuthentication, msg)
+}
+
+// Authorization returns authorization error.
+func Authorization(msg string) *Error {
+	return New(CodeAuthorization, msg)
+}
+
+// NotFound returns not found error.
+func NotFound(msg string) *Error {
+	return New(CodeNotFound, msg)
+}
+
+// Conflict returns conflict error.
+func Conflict(msg string) *Error {
+	return New(CodeConflict, msg)
+}
+
+// UnprocessableEntity returns unprocessable entity error.
+func UnprocessableEntity(msg string) *Error {
+	return New(CodeUnprocessableEntity, msg)
+}
+
+// InvalidRequest returns invalid request error.
+func InvalidRequest(msg string) *Error {
+	return New(CodeInvalidRequest, msg)
+}
+
+// Unavailable returns unavailable error.
+func Unavailable(msg string) *Error {
+	return New(CodeUnavailable, msg)
+}
+
+// Internal returns internal error.
+func Internal(msg string) *Error {
+	return New(CodeInternal, msg)
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return e.msg
+	}
+	return e.msg + " : " + e.cause.Error()
+}
+
+// Msg returns error message.
+func (e *Error) Msg() string {
+	return e.msg
+}
+
+// Err returns wrapped error.
+func (e *Error) Err() errors.Error {
+	if e.cause == nil {
+		return nil
+	}
+	if err, ok := e.cause.(errors.Error); ok {
+		return err
+	}
+	return errors.New(e.cause.Error())
+}
+
+// Code returns error code.
+func (e *Error) Code() Code {
+	return e.code
+}
+
+// Unwrap returns wrapped error.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether the target is an Error with the same code and message,
+// which makes a wrapped Error match its sentinel.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.code == e.code && t.msg == e.msg
+}
+
+// WithCause returns a copy of the Error wrapping the given cause. It's
+// used by errors.Wrap to preserve the code of the wrapper.
+func (e *Error) WithCause(err error) error {
+	cause := err
+	if e.cause != nil {
+		cause = errors.Wrap(e.cause, err)
+	}
+	return &Error{
+		code:  e.code,
+		msg:   e.msg,
+		cause: cause,
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package apierrors_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errNotFound = apierrors.NotFound("non-existent entity")
+	errCreate   = apierrors.InvalidRequest("create entity failed")
+	errAuthn    = apierrors.Authentication("missing or invalid credentials provided")
+	errLegacy   = errors.New("legacy error")
+)
+
+func TestMapping(t *testing.T) {
+	cases := []struct {
+		desc string
+		err  error
+		code apierrors.Code
+		http int
+		grpc codes.Code
+	}{
+		{
+			desc: "malformed entity error",
+			err:  apierrors.MalformedEntity("malformed"),
+			code: apierrors.CodeMalformedEntity,
+			http: http.StatusBadRequest,
+			grpc: codes.InvalidArgument,
+		},
+		{
+			desc: "authentication error",
+			err:  errAuthn,
+			code: apierrors.CodeAuthentication,
+			http: http.StatusUnauthorized,
+			grpc: codes.Unauthenticated,
+		},
+		{
+			desc: "authorization error",
+			err:  apierrors.Authorization("forbidden"),
+			code: apierrors.CodeAuthorization,
+			http: http.StatusForbidden,
+			grpc: codes.PermissionDenied,
+		},
+		{
+			desc: "not found error",
+			err:  errNotFound,
+			code: apierrors.CodeNotFound,
+			http: http.StatusNotFound,
+			grpc: codes.NotFound,
+		},
+		{
+			desc: "conflict error",
+			err:  apierrors.Conflict("conflict"),
+			code: apierrors.CodeConflict,
+			http: http.StatusConflict,
+			grpc: codes.AlreadyExists,
+		},
+		{
+			desc: "unprocessable entity error",
+			err:  apierrors.UnprocessableEntity("unprocessable"),
+			code: apierrors.CodeUnprocessableEntity,
+			http: http.StatusUnprocessableEntity,
+			grpc: codes.FailedPrecondition,
+		},
+		{
+			desc: "invalid request error",
+			err:  errCreate,
+			code: apierrors.CodeInvalidRequest,
+			http: http.StatusBadRequest,
+			grpc: codes.InvalidArgument,
+		},
+		{
+			desc: "unavailable error",
+			err:  apierrors.Unavailable("unavailable"),
+			code: apierrors.CodeUnavailable,
+			http: http.StatusServiceUnavailable,
+			grpc: codes.Unavailable,
+		},
+		{
+			desc: "internal error",
+			err:  apierrors.Internal("internal"),
+			code: apierrors.CodeInternal,
+			http: http.StatusInternalServerError,
+			grpc: codes.Internal,
+		},
+		{
+			desc: "wrapped error",
+			err:  errors.Wrap(errNotFound, sql.ErrNoRows),
+			code: apierrors.CodeNotFound,
+			http: http.StatusNotFound,
+			grpc: codes.NotFound,
+		},
+		{
+			desc: "legacy error wrapping error",
+			err:  errors.Wrap(errLegacy, errNotFound),
+			code: apierrors.CodeNotFound,
+			http: http.StatusNotFound,
+			grpc: codes.NotFound,
+		},
+		{
+			desc: "error wrapping error with higher precedence",
+			err:  errors.Wrap(errCreate, errors.Wrap(errNotFound, sql.ErrNoRows)),
+			code: apierrors.CodeNotFound,
+			http: http.StatusNotFound,
+			grpc: codes.NotFound,
+		},
+		{
+			desc: "error wrapping error with lower precedence",
+			err:  errors.Wrap(errAuthn, errNotFound),
+			code: apierrors.CodeAuthentication,
+			http: http.StatusUnauthorized,
+			grpc: codes.Unauthenticated,
+		},
+		{
+			desc: "malformed entity sentinel",
+			err:  errors.ErrMalformedEntity,
+			code: apierrors.CodeMalformedEntity,
+			http: http.StatusBadRequest,
+			grpc: codes.InvalidArgument,
+		},
+		{
+			desc: "invalid query params sentinel",
+			err:  errors.Wrap(errors.ErrInvalidQueryParams, io.EOF),
+			code: apierrors.CodeMalformedEntity,
+			http: http.StatusBadRequest,
+			grpc: codes.InvalidArgument,
+		},
+		{
+			desc: "unsupported content type sentinel",
+			err:  errors.ErrUnsupportedContentType,
+			code: apierrors.CodeUnsupportedContentType,
+			http: http.StatusUnsupportedMediaType,
+			grpc: codes.InvalidArgument,
+		},
+		{
+			desc: "unexpected EOF",
+			err:  io.ErrUnexpectedEOF,
+			code: apierrors.CodeMalformedEntity,
+			http: http.StatusBadRequest,
+			grpc: codes.InvalidArgument,
+		},
+		{
+			desc: "JSON syntax error",
+			err:  &json.SyntaxError{},
+			code: apierrors.CodeMalformedEntity,
+			http: http.StatusBadRequest,
+			grpc: codes.InvalidArgument,
+		},
+		{
+			desc: "legacy error",
+			err:  errLegacy,
+			code: apierrors.CodeInternal,
+			http: http.StatusInternalServerError,
+			grpc: codes.Internal,
+		},
+		{
+			desc: "standard library error",
+			err:  sql.ErrNoRows,
+			code: apierrors.CodeInternal,
+			http: http.StatusInternalServerError,
+			grpc: codes.Internal,
+		},
+	}
+
+	for _, tc := range cases {
+		code := apierrors.CodeOf(tc.err)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected code %s got %s\n", tc.desc, tc.code, code))
+		httpStatus := apierrors.HTTPStatus(tc.err)
+		assert.Equal(t, tc.http, httpStatus, fmt.Sprintf("%s: expected HTTP status %d got %d\n", tc.desc, tc.http, httpStatus))
+		grpcCode := apierrors.GRPCCode(tc.err)
+		assert.Equal(t, tc.grpc, grpcCode, fmt.Sprintf("%s: expected gRPC code %s got %s\n", tc.desc, tc.grpc, grpcCode))
+	}
+}
+
+func TestGRPCStatus(t *testing.T) {
+	cases := []struct {
+		desc string
+		err  error
+		code codes.Code
+		msg  string
+	}{
+		{
+			desc: "not found error",
+			err:  errors.Wrap(errNotFound, sql.ErrNoRows),
+			code: codes.NotFound,
+			msg:  errNotFound.Msg(),
+		},
+		{
+			desc: "internal error",
+			err:  errors.Wrap(errLegacy, sql.ErrNoRows),
+			code: codes.Internal,
+			msg:  "internal server error",
+		},
+	}
+
+	for _, tc := range cases {
+		st, _ := status.FromError(apierrors.GRPCStatus(tc.err))
+		assert.Equal(t, tc.code, st.Code(), fmt.Sprintf("%s: expected code %s got %s\n", tc.desc, tc.code, st.Code()))
+		assert.Equal(t, tc.msg, st.Message(), fmt.Sprintf("%s: expected message %s got %s\n", tc.desc, tc.msg, st.Message()))
+	}
+	assert.Nil(t, apierrors.GRPCStatus(nil), "expected nil status for nil error")
+}
+
+func TestIs(t *testing.T) {
+	cases := []struct {
+		desc   string
+		err    error
+		target error
+		is     bool
+	}{
+		{
+			desc:   "sentinel is sentinel",
+			err:    errNotFound,
+			target: errNotFound,
+			is:     true,
+		},
+		{
+			desc:   "wrapped sentinel is sentinel",
+			err:    errors.Wrap(errNotFound, sql.ErrNoRows),
+			target: errNotFound,
+			is:     true,
+		},
+		{
+			desc:   "wrapped sentinel is cause",
+			err:    errors.Wrap(errNotFound, sql.ErrNoRows),
+			target: sql.ErrNoRows,
+			is:     true,
+		},
+		{
+			desc:   "sentinel wrapped by legacy error is sentinel",
+			err:    errors.Wrap(errLegacy, errors.Wrap(errNotFound, sql.ErrNoRows)),
+			target: errNotFound,
+			is:     true,
+		},
+		{
+			desc:   "sentinel wrapping legacy error is legacy error",
+			err:    errors.Wrap(errCreate, errLegacy),
+			target: errLegacy,
+			is:     true,
+		},
+		{
+			desc:   "sentinel is not error with the same message and different code",
+			err:    errNotFound,
+			target: apierrors.Conflict(errNotFound.Msg()),
+			is:     false,
+		},
+		{
+			desc:   "sentinel is not other sentinel",
+			err:    errors.Wrap(errCreate, sql.ErrNoRows),
+			target: errNotFound,
+			is:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		is := goerrors.Is(tc.err, tc.target)
+		assert.Equal(t, tc.is, is, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.is, is))
+	}
+}
+
+func TestWrap(t *testing.T) {
+	err := errors.Wrap(errNotFound, sql.ErrNoRows)
+	assert.Equal(t, "non-existent entity : "+sql.ErrNoRows.Error(), err.Error(), "expected wrapped error message")
+	assert.True(t, errors.Contains(err, errNotFound), "expected wrapped error to contain sentinel")
+	assert.True(t, errors.Contains(err, sql.ErrNoRows), "expected wrapped error to contain cause")
+
+	var e *apierrors.Error
+	assert.True(t, goerrors.As(err, &e), "expected wrapped error to keep the type")
+	assert.Equal(t, apierrors.CodeNotFound, e.Code(), fmt.Sprintf("expected code %s got %s\n", apierrors.CodeNotFound, e.Code()))
+
+	err = errors.Wrap(err, io.EOF)
+	assert.True(t, errors.Contains(err, sql.ErrNoRows), "expected error wrapped twice to contain the first cause")
+	assert.True(t, errors.Contains(err, io.EOF), "expected error wrapped twice to contain the second cause")
+	assert.Nil(t, errNotFound.Unwrap(), "expected sentinel to stay unwrapped")
+}
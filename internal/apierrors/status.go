@@ -0,0 +1,141 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package apierrors
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mapping struct {
+	http int
+	grpc codes.Code
+}
+
+// precedence lists the codes in order in which they are resolved when
+// the error chain contains errors with different codes.
+var precedence = []Code{
+	CodeAuthentication,
+	CodeAuthorization,
+	CodeMalformedEntity,
+	CodeUnsupportedContentType,
+	CodeNotFound,
+	CodeConflict,
+	CodeUnprocessableEntity,
+	CodeInvalidRequest,
+	CodeUnavailable,
+	CodeInternal,
+}
+
+var mappings = map[Code]mapping{
+	CodeAuthentication:         {http.StatusUnauthorized, codes.Unauthenticated},
+	CodeAuthorization:          {http.StatusForbidden, codes.PermissionDenied},
+	CodeMalformedEntity:        {http.StatusBadRequest, codes.InvalidArgument},
+	CodeUnsupportedContentType: {http.StatusUnsupportedMediaType, codes.InvalidArgument},
+	CodeNotFound:               {http.StatusNotFound, codes.NotFound},
+	CodeConflict:               {http.StatusConflict, codes.AlreadyExists},
+	CodeUnprocessableEntity:    {http.StatusUnprocessableEntity, codes.FailedPrecondition},
+	CodeInvalidRequest:         {http.StatusBadRequest, codes.InvalidArgument},
+	CodeUnavailable:            {http.StatusServiceUnavailable, codes.Unavailable},
+	CodeInternal:               {http.StatusInternalServerError, codes.Internal},
+}
+
+// Errors from outside of this package that are mapped by their message,
+// the same way errors.Contains matches them.
+var known = []struct {
+	err  error
+	code Code
+}{
+	{errors.ErrMalformedEntity, CodeMalformedEntity},
+	{errors.ErrInvalidQueryParams, CodeMalformedEntity},
+	{errors.ErrUnsupportedContentType, CodeUnsupportedContentType},
+	{io.ErrUnexpectedEOF, CodeMalformedEntity},
+	{io.EOF, CodeMalformedEntity},
+}
+
+// CodeOf returns the code of the error. If the error chain contains
+// errors with different codes, the one with the highest precedence
+// is returned. Errors without code are internal.
+func CodeOf(err error) Code {
+	code := CodeInternal
+	for ; err != nil; err = unwrap(err) {
+		if c, ok := lookup(err); ok && rank(c) < rank(code) {
+			code = c
+		}
+	}
+	return code
+}
+
+// HTTPStatus returns HTTP status code of the error.
+func HTTPStatus(err error) int {
+	return mappings[CodeOf(err)].http
+}
+
+// GRPCCode returns gRPC status code of the error.
+func GRPCCode(err error) codes.Code {
+	return mappings[CodeOf(err)].grpc
+}
+
+// GRPCStatus returns gRPC status error carrying the error message. The
+// message of internal errors is not exposed.
+func GRPCStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	code := CodeOf(err)
+	msg := "internal server error"
+	if code != CodeInternal {
+		msg = message(err)
+	}
+	return status.Error(mappings[code].grpc, msg)
+}
+
+func lookup(err error) (Code, bool) {
+	switch e := err.(type) {
+	case *Error:
+		return e.code, true
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		return CodeMalformedEntity, true
+	}
+	msg := message(err)
+	for _, k := range known {
+		if k.err.Error() == msg {
+			return k.code, true
+		}
+	}
+	return "", false
+}
+
+func unwrap(err error) error {
+	if e, ok := err.(interface{ Unwrap() error }); ok {
+		return e.Unwrap()
+	}
+	if e, ok := err.(errors.Error); ok {
+		if next := e.Err(); next != nil {
+			return next
+		}
+	}
+	return nil
+}
+
+func message(err error) string {
+	if e, ok := err.(errors.Error); ok {
+		return e.Msg()
+	}
+	return err.Error()
+}
+
+func rank(code Code) int {
+	for i, c := range precedence {
+		if c == code {
+			return i
+		}
+	}
+	return len(precedence)
+}
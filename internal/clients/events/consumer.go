@@ -0,0 +1,199 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	defBatchSize     = 100
+	defMaxFailures   = 5
+	defRetryInterval = time.Second
+
+	// Latest is the start ID of the group reading only the events added
+	// after the group is created.
+	Latest = "$"
+)
+
+// ErrRetrieveCheckpoint indicates failure to retrieve the checkpoint the
+// consumer resumes from.
+var ErrRetrieveCheckpoint = errors.New("failed to retrieve checkpoint")
+
+// Config represents the consumer configuration. Zero values are replaced by
+// the defaults.
+type Config struct {
+	// BatchSize is the number of events read at once.
+	BatchSize int64
+
+	// MaxFailures is the number of the transient handler failures after
+	// which the event is parked.
+	MaxFailures int
+
+	// RetryInterval is the delay before handling the failed event again, or
+	// reading the stream after the read failed.
+	RetryInterval time.Duration
+}
+
+// Consumer represents the consumer of the event sourcing stream.
+type Consumer interface {
+	// Consume passes the events to the handler in the order they're read,
+	// until the context is canceled. The events the consumer didn't
+	// acknowledge before it stopped are handled first.
+	Consume(ctx context.Context, h Handler) error
+
+	// Replay rewinds the consumer group to the event ID, so the events
+	// after it are handled again. The replay from the same ID is applied
+	// once, so it isn't repeated on each start of the service.
+	Replay(ctx context.Context, id string) error
+}
+
+type consumer struct {
+	stream      Stream
+	checkpoints CheckpointRepository
+	cfg         Config
+	logger      logger.Logger
+}
+
+// NewConsumer returns new consumer of the stream. If the consumer group
+// doesn't exist, it's created at the checkpoint, or at the end of the stream
+// if there isn't one.
+func NewConsumer(stream Stream, checkpoints CheckpointRepository, cfg Config, logger logger.Logger) Consumer {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defBatchSize
+	}
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = defMaxFailures
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defRetryInterval
+	}
+
+	return consumer{
+		stream:      stream,
+		checkpoints: checkpoints,
+		cfg:         cfg,
+		logger:      logger,
+	}
+}
+
+func (c consumer) Consume(ctx context.Context, h Handler) error {
+	start, err := c.checkpoints.Retrieve(ctx)
+	if err != nil {
+		return errors.Wrap(ErrRetrieveCheckpoint, err)
+	}
+	if start == "" {
+		start = Latest
+	}
+	if err := c.stream.Join(ctx, start); err != nil {
+		return err
+	}
+
+	pending := true
+	for {
+		evs, err := c.stream.Read(ctx, pending, c.cfg.BatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			c.logger.Warn(fmt.Sprintf("Failed to read events: %s", err))
+			if err := c.wait(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		if pending && len(evs) == 0 {
+			pending = false
+			continue
+		}
+
+		for _, ev := range evs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := c.handle(ctx, h, ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (c consumer) Replay(ctx context.Context, id string) error {
+	applied, err := c.checkpoints.RetrieveReplay(ctx)
+	if err != nil {
+		return errors.Wrap(ErrRetrieveCheckpoint, err)
+	}
+	if applied == id {
+		c.logger.Info(fmt.Sprintf("Replay from %s already applied, skipping", id))
+		return nil
+	}
+
+	if err := c.stream.Rewind(ctx, id); err != nil {
+		return err
+	}
+	if err := c.checkpoints.Save(ctx, id); err != nil {
+		return err
+	}
+	if err := c.checkpoints.SaveReplay(ctx, id); err != nil {
+		return err
+	}
+	c.logger.Info(fmt.Sprintf("Replaying events from %s", id))
+	return nil
+}
+
+// handle passes the event to the handler until it's handled, or parked on
+// the permanent failure or after the maximum number of transient ones. The
+// following events are held back meanwhile, to keep them in order. The error
+// is returned only if the context is canceled.
+func (c consumer) handle(ctx context.Context, h Handler, ev Event) error {
+	for failures := 1; ; failures++ {
+		err := h.Handle(ctx, ev)
+		if err == nil {
+			if err := c.stream.Ack(ctx, ev.ID); err != nil {
+				c.logger.Warn(fmt.Sprintf("Failed to acknowledge event %s: %s", ev.ID, err))
+				return nil
+			}
+			c.checkpoint(ctx, ev.ID)
+			return nil
+		}
+
+		c.logger.Warn(fmt.Sprintf("Failed to handle event %s at attempt %d: %s", ev.ID, failures, err))
+		if !transient(err) || failures >= c.cfg.MaxFailures {
+			if err := c.stream.Park(ctx, ev, err, failures); err != nil {
+				c.logger.Error(fmt.Sprintf("Failed to park event %s: %s", ev.ID, err))
+				return nil
+			}
+			c.logger.Error(fmt.Sprintf("Parked event %s after %d failures", ev.ID, failures))
+			c.checkpoint(ctx, ev.ID)
+			return nil
+		}
+
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (c consumer) checkpoint(ctx context.Context, id string) {
+	if err := c.checkpoints.Save(ctx, id); err != nil {
+		c.logger.Warn(fmt.Sprintf("Failed to save checkpoint %s: %s", id, err))
+	}
+}
+
+func (c consumer) wait(ctx context.Context) error {
+	t := time.NewTimer(c.cfg.RetryInterval)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
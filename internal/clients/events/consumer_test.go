@@ -0,0 +1,347 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package events_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/internal/clients/events"
+	"github.com/mainflux/mainflux/internal/clients/events/mocks"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	timeout = 5 * time.Second
+	always  = -1
+)
+
+var (
+	testLog, _ = log.New(os.Stdout, log.Info.String())
+
+	cfg = events.Config{
+		MaxFailures:   3,
+		RetryInterval: time.Millisecond,
+	}
+
+	errHandle    = errors.New("failed to handle event")
+	errMalformed = apierrors.MalformedEntity("malformed event")
+)
+
+// handler records the handled events. It fails the events the configured
+// number of times, and stops the consumer once the last event is handled, or
+// the crashing one is received.
+type handler struct {
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	failures map[string]int
+	errs     map[string]error
+	calls    map[string]int
+	handled  []string
+	last     string
+	crash    string
+}
+
+func newHandler(cancel context.CancelFunc, last string) *handler {
+	return &handler{
+		cancel:   cancel,
+		failures: map[string]int{},
+		errs:     map[string]error{},
+		calls:    map[string]int{},
+		last:     last,
+	}
+}
+
+func (h *handler) Handle(_ context.Context, ev events.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.calls[ev.ID]++
+	if ev.ID == h.crash {
+		h.cancel()
+		return errHandle
+	}
+	if f := h.failures[ev.ID]; f == always || h.calls[ev.ID] <= f {
+		if ev.ID == h.last {
+			h.cancel()
+		}
+		if err, ok := h.errs[ev.ID]; ok {
+			return err
+		}
+		return errHandle
+	}
+
+	h.handled = append(h.handled, ev.ID)
+	if ev.ID == h.last {
+		h.cancel()
+	}
+	return nil
+}
+
+func publish(stream *mocks.Stream, n int) []string {
+	var ids []string
+	for i := 0; i < n; i++ {
+		ids = append(ids, stream.Publish(map[string]interface{}{"seq": i}))
+	}
+	return ids
+}
+
+func consume(t *testing.T, stream *mocks.Stream, checkpoints events.CheckpointRepository, h func(context.CancelFunc) *handler) (*handler, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	hdl := h(cancel)
+	err := events.NewConsumer(stream, checkpoints, cfg, testLog).Consume(ctx, hdl)
+	require.NotEqual(t, context.DeadlineExceeded, err, "consumer expected to be stopped by the handler")
+	return hdl, err
+}
+
+func TestConsumeOrdered(t *testing.T) {
+	stream := mocks.NewStream()
+	ids := publish(stream, 10)
+	checkpoints := mocks.NewCheckpointRepository()
+	err := events.NewConsumer(stream, checkpoints, cfg, testLog).Replay(context.Background(), "0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	h, err := consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		h := newHandler(cancel, ids[9])
+		h.failures[ids[2]] = 2
+		return h
+	})
+	assert.Equal(t, context.Canceled, err, fmt.Sprintf("expected error %s got %s", context.Canceled, err))
+	assert.Equal(t, ids, h.handled, fmt.Sprintf("expected events handled in order %v got %v", ids, h.handled))
+	assert.Equal(t, 3, h.calls[ids[2]], fmt.Sprintf("expected failed event handled %d times got %d", 3, h.calls[ids[2]]))
+	assert.Empty(t, stream.Pending(), fmt.Sprintf("expected no pending events got %v", stream.Pending()))
+
+	checkpoint, err := checkpoints.Retrieve(context.Background())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, ids[9], checkpoint, fmt.Sprintf("expected checkpoint %s got %s", ids[9], checkpoint))
+}
+
+func TestConsumePoison(t *testing.T) {
+	stream := mocks.NewStream()
+	ids := publish(stream, 3)
+	checkpoints := mocks.NewCheckpointRepository()
+	err := checkpoints.Save(context.Background(), "0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	h, _ := consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		h := newHandler(cancel, ids[2])
+		h.failures[ids[1]] = always
+		return h
+	})
+	handled := []string{ids[0], ids[2]}
+	assert.Equal(t, handled, h.handled, fmt.Sprintf("expected handled events %v got %v", handled, h.handled))
+	assert.Equal(t, cfg.MaxFailures, h.calls[ids[1]], fmt.Sprintf("expected poison event handled %d times got %d", cfg.MaxFailures, h.calls[ids[1]]))
+
+	parked := stream.Parked()
+	require.Len(t, parked, 1, fmt.Sprintf("expected one parked event got %d", len(parked)))
+	assert.Equal(t, ids[1], parked[0].ID, fmt.Sprintf("expected parked event %s got %s", ids[1], parked[0].ID))
+	assert.Empty(t, stream.Pending(), fmt.Sprintf("expected no pending events got %v", stream.Pending()))
+}
+
+func TestConsumePermanent(t *testing.T) {
+	cases := []struct {
+		desc  string
+		err   error
+		calls int
+	}{
+		{
+			desc:  "park event after transient failures",
+			err:   errHandle,
+			calls: cfg.MaxFailures,
+		},
+		{
+			desc:  "park event after unavailable failures",
+			err:   apierrors.Unavailable("service unavailable"),
+			calls: cfg.MaxFailures,
+		},
+		{
+			desc:  "park event on permanent failure",
+			err:   errMalformed,
+			calls: 1,
+		},
+		{
+			desc:  "park event on wrapped permanent failure",
+			err:   errors.Wrap(errMalformed, errHandle),
+			calls: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		stream := mocks.NewStream()
+		ids := publish(stream, 2)
+		checkpoints := mocks.NewCheckpointRepository()
+		err := checkpoints.Save(context.Background(), "0")
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		h, _ := consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+			h := newHandler(cancel, ids[1])
+			h.failures[ids[0]] = always
+			h.errs[ids[0]] = tc.err
+			return h
+		})
+		assert.Equal(t, tc.calls, h.calls[ids[0]], fmt.Sprintf("%s: expected failed event handled %d times got %d", tc.desc, tc.calls, h.calls[ids[0]]))
+		assert.Equal(t, ids[1:], h.handled, fmt.Sprintf("%s: expected handled events %v got %v", tc.desc, ids[1:], h.handled))
+
+		parked := stream.Parked()
+		require.Len(t, parked, 1, fmt.Sprintf("%s: expected one parked event got %d", tc.desc, len(parked)))
+		assert.Equal(t, ids[0], parked[0].ID, fmt.Sprintf("%s: expected parked event %s got %s", tc.desc, ids[0], parked[0].ID))
+	}
+}
+
+func TestConsumeResume(t *testing.T) {
+	cases := []struct {
+		desc      string
+		events    int
+		crash     int
+		handled   []int
+		redeliver []int
+	}{
+		{
+			desc:      "resume from pending events after crash",
+			events:    4,
+			crash:     1,
+			handled:   []int{0},
+			redeliver: []int{1, 2, 3},
+		},
+		{
+			desc:      "resume from last event after crash",
+			events:    4,
+			crash:     3,
+			handled:   []int{0, 1, 2},
+			redeliver: []int{3},
+		},
+	}
+
+	for _, tc := range cases {
+		stream := mocks.NewStream()
+		ids := publish(stream, tc.events)
+		checkpoints := mocks.NewCheckpointRepository()
+		err := checkpoints.Save(context.Background(), "0")
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		h, err := consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+			h := newHandler(cancel, "")
+			h.crash = ids[tc.crash]
+			return h
+		})
+		assert.Equal(t, context.Canceled, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, context.Canceled, err))
+		handled := pick(ids, tc.handled)
+		assert.Equal(t, handled, h.handled, fmt.Sprintf("%s: expected handled events %v got %v", tc.desc, handled, h.handled))
+
+		h, _ = consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+			return newHandler(cancel, ids[len(ids)-1])
+		})
+		redelivered := pick(ids, tc.redeliver)
+		assert.Equal(t, redelivered, h.handled, fmt.Sprintf("%s: expected handled events %v got %v", tc.desc, redelivered, h.handled))
+		assert.Empty(t, stream.Pending(), fmt.Sprintf("%s: expected no pending events got %v", tc.desc, stream.Pending()))
+	}
+}
+
+func TestConsumeCheckpoint(t *testing.T) {
+	// The consumer group is lost, e.g. the stream is restored from the
+	// backup, so the consumer resumes from the checkpoint.
+	stream := mocks.NewStream()
+	ids := publish(stream, 4)
+	checkpoints := mocks.NewCheckpointRepository()
+	err := checkpoints.Save(context.Background(), ids[1])
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	h, _ := consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		return newHandler(cancel, ids[3])
+	})
+	handled := ids[2:]
+	assert.Equal(t, handled, h.handled, fmt.Sprintf("expected handled events %v got %v", handled, h.handled))
+}
+
+func TestConsumeLatest(t *testing.T) {
+	stream := mocks.NewStream()
+	publish(stream, 2)
+	checkpoints := mocks.NewCheckpointRepository()
+
+	// Only the events published once the consumer group is created are
+	// handled.
+	id := "3-0"
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		stream.Publish(map[string]interface{}{"seq": 2})
+	}()
+	h, _ := consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		return newHandler(cancel, id)
+	})
+	assert.Equal(t, []string{id}, h.handled, fmt.Sprintf("expected handled events %v got %v", []string{id}, h.handled))
+}
+
+func TestReplay(t *testing.T) {
+	stream := mocks.NewStream()
+	ids := publish(stream, 4)
+	checkpoints := mocks.NewCheckpointRepository()
+	err := checkpoints.Save(context.Background(), "0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	h, _ := consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		return newHandler(cancel, ids[3])
+	})
+	assert.Equal(t, ids, h.handled, fmt.Sprintf("expected handled events %v got %v", ids, h.handled))
+
+	err = events.NewConsumer(stream, checkpoints, cfg, testLog).Replay(context.Background(), ids[1])
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	h, _ = consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		return newHandler(cancel, ids[3])
+	})
+	replayed := ids[2:]
+	assert.Equal(t, replayed, h.handled, fmt.Sprintf("expected replayed events %v got %v", replayed, h.handled))
+}
+
+func pick(ids []string, indices []int) []string {
+	var picked []string
+	for _, i := range indices {
+		picked = append(picked, ids[i])
+	}
+	return picked
+}
+
+func TestReplayOnce(t *testing.T) {
+	stream := mocks.NewStream()
+	ids := publish(stream, 4)
+	checkpoints := mocks.NewCheckpointRepository()
+	err := checkpoints.Save(context.Background(), "0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	err = events.NewConsumer(stream, checkpoints, cfg, testLog).Replay(context.Background(), ids[1])
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	h, _ := consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		return newHandler(cancel, ids[3])
+	})
+	replayed := ids[2:]
+	assert.Equal(t, replayed, h.handled, fmt.Sprintf("expected replayed events %v got %v", replayed, h.handled))
+
+	// The service restarted with the same replay ID resumes from the last
+	// handled event.
+	err = events.NewConsumer(stream, checkpoints, cfg, testLog).Replay(context.Background(), ids[1])
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	id := stream.Publish(map[string]interface{}{"seq": 4})
+	h, _ = consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		return newHandler(cancel, id)
+	})
+	assert.Equal(t, []string{id}, h.handled, fmt.Sprintf("expected handled events %v got %v", []string{id}, h.handled))
+
+	// The replay from another ID is applied.
+	err = events.NewConsumer(stream, checkpoints, cfg, testLog).Replay(context.Background(), ids[2])
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	h, _ = consume(t, stream, checkpoints, func(cancel context.CancelFunc) *handler {
+		return newHandler(cancel, id)
+	})
+	replayed = []string{ids[3], id}
+	assert.Equal(t, replayed, h.handled, fmt.Sprintf("expected replayed events %v got %v", replayed, h.handled))
+}
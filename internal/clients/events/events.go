@@ -0,0 +1,79 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package events contains the consumer of the event sourcing streams shared
+// by the services subscribed to the events of other services. The events are
+// read by the consumer group, handled in order, and acknowledged once handled,
+// so the consumer resumes where it stopped after the restart.
+package events
+
+import (
+	"context"
+
+	"github.com/mainflux/mainflux/internal/apierrors"
+)
+
+// Event represents the event read from the stream.
+type Event struct {
+	ID     string
+	Values map[string]interface{}
+}
+
+// Handler handles the events read from the stream.
+type Handler interface {
+	// Handle handles the event. The event is acknowledged once it's handled
+	// without error. The unavailable and internal errors, including the
+	// errors without code, are transient and the event is retried. The
+	// event failed with any other error is parked without retries, since
+	// handling it again fails the same way.
+	Handle(ctx context.Context, event Event) error
+}
+
+// Stream represents the stream read by the consumer of the consumer group.
+type Stream interface {
+	// Join creates the consumer group reading the events after the start ID,
+	// unless the group already exists.
+	Join(ctx context.Context, start string) error
+
+	// Read returns up to count events, either the new ones or the pending
+	// ones, i.e. delivered to the consumer but not acknowledged.
+	Read(ctx context.Context, pending bool, count int64) ([]Event, error)
+
+	// Ack acknowledges the event.
+	Ack(ctx context.Context, id string) error
+
+	// Park moves the event failed to be handled to the parking stream and
+	// acknowledges it.
+	Park(ctx context.Context, event Event, cause error, failures int) error
+
+	// Rewind sets the consumer group to read the events after the ID again.
+	Rewind(ctx context.Context, id string) error
+}
+
+// CheckpointRepository stores ID of the last event handled by the consumer
+// group.
+type CheckpointRepository interface {
+	// Save persists the checkpoint.
+	Save(ctx context.Context, id string) error
+
+	// Retrieve returns the checkpoint, or empty string if it's not saved.
+	Retrieve(ctx context.Context) (string, error)
+
+	// SaveReplay persists the ID the consumer group was last replayed from.
+	SaveReplay(ctx context.Context, id string) error
+
+	// RetrieveReplay returns the ID the consumer group was last replayed
+	// from, or empty string if it was never replayed.
+	RetrieveReplay(ctx context.Context) (string, error)
+}
+
+// transient reports whether handling the event failed with the error may
+// succeed if retried.
+func transient(err error) bool {
+	switch apierrors.CodeOf(err) {
+	case apierrors.CodeUnavailable, apierrors.CodeInternal:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/clients/events"
+)
+
+const readBlock = 10 * time.Millisecond
+
+var _ events.Stream = (*Stream)(nil)
+
+// Stream is the in-memory stream read by the single consumer group.
+type Stream struct {
+	mu      sync.Mutex
+	events  []events.Event
+	joined  bool
+	next    int
+	pending []events.Event
+	parked  []events.Event
+}
+
+// NewStream returns the stream containing the events with the values, and
+// no consumer group.
+func NewStream(values ...map[string]interface{}) *Stream {
+	s := &Stream{}
+	for _, v := range values {
+		s.Publish(v)
+	}
+	return s
+}
+
+// Publish adds the event to the stream and returns its ID.
+func (s *Stream) Publish(values map[string]interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := fmt.Sprintf("%d-0", len(s.events)+1)
+	s.events = append(s.events, events.Event{ID: id, Values: values})
+	return id
+}
+
+// Parked returns the parked events.
+func (s *Stream) Parked() []events.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]events.Event{}, s.parked...)
+}
+
+// Pending returns IDs of the events delivered, but not acknowledged.
+func (s *Stream) Pending() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []string{}
+	for _, ev := range s.pending {
+		ids = append(ids, ev.ID)
+	}
+	return ids
+}
+
+func (s *Stream) Join(_ context.Context, start string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.joined {
+		return nil
+	}
+	s.joined = true
+	s.next = s.position(start)
+	return nil
+}
+
+func (s *Stream) Read(ctx context.Context, pending bool, count int64) ([]events.Event, error) {
+	s.mu.Lock()
+	if pending {
+		defer s.mu.Unlock()
+		n := min(int(count), len(s.pending))
+		return append([]events.Event{}, s.pending[:n]...), nil
+	}
+
+	n := min(int(count), len(s.events)-s.next)
+	evs := append([]events.Event{}, s.events[s.next:s.next+n]...)
+	s.next += n
+	s.pending = append(s.pending, evs...)
+	s.mu.Unlock()
+
+	if len(evs) == 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(readBlock):
+		}
+	}
+	return evs, nil
+}
+
+func (s *Stream) Ack(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ack(id)
+	return nil
+}
+
+func (s *Stream) Park(_ context.Context, event events.Event, cause error, failures int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.parked = append(s.parked, event)
+	s.ack(event.ID)
+	return nil
+}
+
+func (s *Stream) Rewind(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.joined = true
+	s.next = s.position(id)
+	return nil
+}
+
+func (s *Stream) ack(id string) {
+	for i, ev := range s.pending {
+		if ev.ID == id {
+			s.pending = append(s.pending[:i], s.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// position returns index of the first event after the ID.
+func (s *Stream) position(id string) int {
+	if id == events.Latest {
+		return len(s.events)
+	}
+	seq := sequence(id)
+	for i, ev := range s.events {
+		if sequence(ev.ID) > seq {
+			return i
+		}
+	}
+	return len(s.events)
+}
+
+func sequence(id string) int {
+	seq, _ := strconv.Atoi(strings.SplitN(id, "-", 2)[0])
+	return seq
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+var _ events.CheckpointRepository = (*checkpointRepositoryMock)(nil)
+
+type checkpointRepositoryMock struct {
+	mu     sync.Mutex
+	id     string
+	replay string
+}
+
+// NewCheckpointRepository returns the in-memory checkpoint repository.
+func NewCheckpointRepository() events.CheckpointRepository {
+	return &checkpointRepositoryMock{}
+}
+
+func (cr *checkpointRepositoryMock) Save(_ context.Context, id string) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.id = id
+	return nil
+}
+
+func (cr *checkpointRepositoryMock) Retrieve(context.Context) (string, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	return cr.id, nil
+}
+
+func (cr *checkpointRepositoryMock) SaveReplay(_ context.Context, id string) error {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.replay = id
+	return nil
+}
+
+func (cr *checkpointRepositoryMock) RetrieveReplay(context.Context) (string, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	return cr.replay, nil
+}
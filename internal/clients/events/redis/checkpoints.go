@@ -0,0 +1,56 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/internal/clients/events"
+)
+
+const checkpointsKey = "mainflux.checkpoints"
+
+var _ events.CheckpointRepository = (*checkpointRepository)(nil)
+
+type checkpointRepository struct {
+	client      *redis.Client
+	field       string
+	replayField string
+}
+
+// NewCheckpointRepository returns the repository storing the checkpoint of
+// the consumer group of the stream in the Redis hash shared by the groups.
+func NewCheckpointRepository(client *redis.Client, stream, group string) events.CheckpointRepository {
+	return &checkpointRepository{
+		client:      client,
+		field:       fmt.Sprintf("%s:%s", stream, group),
+		replayField: fmt.Sprintf("%s:%s:replay", stream, group),
+	}
+}
+
+func (cr *checkpointRepository) Save(ctx context.Context, id string) error {
+	return cr.client.HSet(ctx, checkpointsKey, cr.field, id).Err()
+}
+
+func (cr *checkpointRepository) Retrieve(ctx context.Context) (string, error) {
+	id, err := cr.client.HGet(ctx, checkpointsKey, cr.field).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return id, err
+}
+
+func (cr *checkpointRepository) SaveReplay(ctx context.Context, id string) error {
+	return cr.client.HSet(ctx, checkpointsKey, cr.replayField, id).Err()
+}
+
+func (cr *checkpointRepository) RetrieveReplay(ctx context.Context) (string, error) {
+	id, err := cr.client.HGet(ctx, checkpointsKey, cr.replayField).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return id, err
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis contains the Redis Streams implementation of the event
+// sourcing stream read by the consumer group.
+package redis
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/internal/clients/events"
+)
+
+const (
+	groupExists   = "BUSYGROUP"
+	newEvents     = ">"
+	pendingEvents = "0"
+
+	// The blocked read is bounded, so the consumer notices the canceled
+	// context.
+	readBlock = time.Second
+
+	parkedID       = "parked_id"
+	parkedError    = "parked_error"
+	parkedFailures = "parked_failures"
+)
+
+var _ events.Stream = (*stream)(nil)
+
+type stream struct {
+	client   *redis.Client
+	name     string
+	group    string
+	consumer string
+	parking  string
+}
+
+// NewStream returns the stream read by the consumer of the consumer group.
+// The events failed to be handled are moved to the parking stream, along
+// with the original event ID, the error and the number of failures.
+func NewStream(client *redis.Client, name, group, consumer, parking string) events.Stream {
+	return &stream{
+		client:   client,
+		name:     name,
+		group:    group,
+		consumer: consumer,
+		parking:  parking,
+	}
+}
+
+func (s *stream) Join(ctx context.Context, start string) error {
+	err := s.client.XGroupCreateMkStream(ctx, s.name, s.group, start).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), groupExists) {
+		return err
+	}
+	return nil
+}
+
+func (s *stream) Read(ctx context.Context, pending bool, count int64) ([]events.Event, error) {
+	id := newEvents
+	if pending {
+		id = pendingEvents
+	}
+
+	streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    s.group,
+		Consumer: s.consumer,
+		Streams:  []string{s.name, id},
+		Count:    count,
+		Block:    readBlock,
+	}).Result()
+	switch {
+	case err == redis.Nil:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	case len(streams) == 0:
+		return nil, nil
+	}
+
+	evs := make([]events.Event, len(streams[0].Messages))
+	for i, msg := range streams[0].Messages {
+		evs[i] = events.Event{
+			ID:     msg.ID,
+			Values: msg.Values,
+		}
+	}
+	return evs, nil
+}
+
+func (s *stream) Ack(ctx context.Context, id string) error {
+	return s.client.XAck(ctx, s.name, s.group, id).Err()
+}
+
+func (s *stream) Park(ctx context.Context, event events.Event, cause error, failures int) error {
+	values := make(map[string]interface{}, len(event.Values)+3)
+	for k, v := range event.Values {
+		values[k] = v
+	}
+	values[parkedID] = event.ID
+	values[parkedError] = cause.Error()
+	values[parkedFailures] = failures
+
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: s.parking,
+			Values: values,
+		})
+		pipe.XAck(ctx, s.name, s.group, event.ID)
+		return nil
+	})
+	return err
+}
+
+func (s *stream) Rewind(ctx context.Context, id string) error {
+	if err := s.Join(ctx, id); err != nil {
+		return err
+	}
+	return s.client.XGroupSetID(ctx, s.name, s.group, id).Err()
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/internal/clients/events"
+	eventsredis "github.com/mainflux/mainflux/internal/clients/events/redis"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	streamName = "mainflux.test"
+	group      = "mainflux.test.group"
+	consumer   = "consumer"
+	parking    = "mainflux.test.parked"
+)
+
+func publish(t *testing.T, n int) []string {
+	var ids []string
+	for i := 0; i < n; i++ {
+		id, err := redisClient.XAdd(context.Background(), &redis.XAddArgs{
+			Stream: streamName,
+			Values: map[string]interface{}{"seq": i},
+		}).Result()
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func eventIDs(evs []events.Event) []string {
+	var ids []string
+	for _, ev := range evs {
+		ids = append(ids, ev.ID)
+	}
+	return ids
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+	redisClient.FlushAll(ctx)
+	stream := eventsredis.NewStream(redisClient, streamName, group, consumer, parking)
+
+	ids := publish(t, 2)
+	err := stream.Join(ctx, events.Latest)
+	require.Nil(t, err, fmt.Sprintf("joining stream expected to succeed: %s", err))
+	err = stream.Join(ctx, events.Latest)
+	require.Nil(t, err, fmt.Sprintf("joining stream again expected to succeed: %s", err))
+	ids = append(ids, publish(t, 3)...)
+
+	evs, err := stream.Read(ctx, false, 2)
+	require.Nil(t, err, fmt.Sprintf("reading new events expected to succeed: %s", err))
+	assert.Equal(t, ids[2:4], eventIDs(evs), fmt.Sprintf("expected new events %v got %v", ids[2:4], eventIDs(evs)))
+
+	err = stream.Ack(ctx, ids[2])
+	require.Nil(t, err, fmt.Sprintf("acknowledging event expected to succeed: %s", err))
+	evs, err = stream.Read(ctx, true, 10)
+	require.Nil(t, err, fmt.Sprintf("reading pending events expected to succeed: %s", err))
+	assert.Equal(t, ids[3:4], eventIDs(evs), fmt.Sprintf("expected pending events %v got %v", ids[3:4], eventIDs(evs)))
+
+	errPoison := errors.New("poison")
+	err = stream.Park(ctx, evs[0], errPoison, 3)
+	require.Nil(t, err, fmt.Sprintf("parking event expected to succeed: %s", err))
+	evs, err = stream.Read(ctx, true, 10)
+	require.Nil(t, err, fmt.Sprintf("reading pending events expected to succeed: %s", err))
+	assert.Empty(t, evs, fmt.Sprintf("expected no pending events got %v", eventIDs(evs)))
+
+	parked, err := redisClient.XRange(ctx, parking, "-", "+").Result()
+	require.Nil(t, err, fmt.Sprintf("reading parked events expected to succeed: %s", err))
+	require.Len(t, parked, 1, fmt.Sprintf("expected one parked event got %d", len(parked)))
+	assert.Equal(t, ids[3], parked[0].Values["parked_id"], fmt.Sprintf("expected parked event %s got %v", ids[3], parked[0].Values["parked_id"]))
+	assert.Equal(t, errPoison.Error(), parked[0].Values["parked_error"], fmt.Sprintf("expected parking cause %s got %v", errPoison, parked[0].Values["parked_error"]))
+
+	err = stream.Rewind(ctx, ids[0])
+	require.Nil(t, err, fmt.Sprintf("rewinding stream expected to succeed: %s", err))
+	evs, err = stream.Read(ctx, false, 10)
+	require.Nil(t, err, fmt.Sprintf("reading new events expected to succeed: %s", err))
+	assert.Equal(t, ids[1:], eventIDs(evs), fmt.Sprintf("expected replayed events %v got %v", ids[1:], eventIDs(evs)))
+}
+
+func TestCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	redisClient.FlushAll(ctx)
+	checkpoints := eventsredis.NewCheckpointRepository(redisClient, streamName, group)
+
+	id, err := checkpoints.Retrieve(ctx)
+	assert.Nil(t, err, fmt.Sprintf("retrieving missing checkpoint expected to succeed: %s", err))
+	assert.Equal(t, "", id, fmt.Sprintf("expected empty checkpoint got %s", id))
+
+	err = checkpoints.Save(ctx, "1-0")
+	require.Nil(t, err, fmt.Sprintf("saving checkpoint expected to succeed: %s", err))
+	id, err = checkpoints.Retrieve(ctx)
+	assert.Nil(t, err, fmt.Sprintf("retrieving checkpoint expected to succeed: %s", err))
+	assert.Equal(t, "1-0", id, fmt.Sprintf("expected checkpoint %s got %s", "1-0", id))
+
+	other := eventsredis.NewCheckpointRepository(redisClient, streamName, "other")
+	id, err = other.Retrieve(ctx)
+	assert.Nil(t, err, fmt.Sprintf("retrieving checkpoint of other group expected to succeed: %s", err))
+	assert.Equal(t, "", id, fmt.Sprintf("expected empty checkpoint of other group got %s", id))
+
+	err = checkpoints.SaveReplay(ctx, "2-0")
+	require.Nil(t, err, fmt.Sprintf("saving replay expected to succeed: %s", err))
+	id, err = checkpoints.RetrieveReplay(ctx)
+	assert.Nil(t, err, fmt.Sprintf("retrieving replay expected to succeed: %s", err))
+	assert.Equal(t, "2-0", id, fmt.Sprintf("expected replay %s got %s", "2-0", id))
+	id, err = checkpoints.Retrieve(ctx)
+	assert.Nil(t, err, fmt.Sprintf("retrieving checkpoint expected to succeed: %s", err))
+	assert.Equal(t, "1-0", id, fmt.Sprintf("expected checkpoint %s got %s", "1-0", id))
+}
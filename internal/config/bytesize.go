@@ -0,0 +1,72 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ByteSize represents the size in bytes.
+type ByteSize uint64
+
+// Byte size units. They are powers of 1024, so KB and KiB are the same.
+const (
+	B  ByteSize = 1
+	KB          = B << 10
+	MB          = KB << 10
+	GB          = MB << 10
+	TB          = GB << 10
+)
+
+var units = map[string]ByteSize{
+	"":    B,
+	"B":   B,
+	"K":   KB,
+	"KB":  KB,
+	"KIB": KB,
+	"M":   MB,
+	"MB":  MB,
+	"MIB": MB,
+	"G":   GB,
+	"GB":  GB,
+	"GIB": GB,
+	"T":   TB,
+	"TB":  TB,
+	"TIB": TB,
+}
+
+// ParseByteSize parses size such as 512, 64KB or 1.5MiB.
+func ParseByteSize(val string) (ByteSize, error) {
+	s := strings.TrimSpace(val)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i == -1 {
+		i = len(s)
+	}
+
+	unit, ok := units[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok || i == 0 {
+		return 0, fmt.Errorf("invalid byte size %q", val)
+	}
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", val)
+	}
+	return ByteSize(n * float64(unit)), nil
+}
+
+func (s ByteSize) String() string {
+	for _, u := range []struct {
+		size ByteSize
+		name string
+	}{{TB, "TB"}, {GB, "GB"}, {MB, "MB"}, {KB, "KB"}} {
+		if s >= u.size && s%u.size == 0 {
+			return fmt.Sprintf("%d%s", s/u.size, u.name)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(s))
+}
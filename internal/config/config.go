@@ -0,0 +1,328 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package config loads service configuration from the environment
+// variables into the tagged struct.
+//
+// Fields are bound to the variables using the env tag, optionally followed
+// by the required and secret options, and the default value is set using
+// the default tag:
+//
+//	type config struct {
+//		Port     string        `env:"MF_HTTP_ADAPTER_PORT" default:"8180"`
+//		NatsURLs []string      `env:"MF_NATS_URL,required"`
+//		Timeout  time.Duration `env:"MF_THINGS_AUTH_GRPC_TIMEOUT" default:"1s"`
+//		Pass     string        `env:"MF_DB_PASS,secret"`
+//	}
+//
+// As with mainflux.Env, variables set to an empty value are treated as unset.
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	optRequired = "required"
+	optSecret   = "secret"
+	redacted    = "***"
+)
+
+var (
+	// ErrMissingVariable indicates that the required variable is not set.
+	ErrMissingVariable = errors.New("missing required variable")
+
+	// ErrInvalidValue indicates that the variable value can't be parsed.
+	ErrInvalidValue = errors.New("invalid value")
+
+	// ErrUnknownVariable indicates that the variable with one of the service
+	// prefixes is set, but it's not used by the service, e.g. due to a typo.
+	ErrUnknownVariable = errors.New("unknown variable")
+
+	// ErrInvalidConfig indicates that the configuration struct can't be loaded.
+	ErrInvalidConfig = errors.New("invalid configuration struct")
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	urlType      = reflect.TypeOf(url.URL{})
+	sizeType     = reflect.TypeOf(ByteSize(0))
+)
+
+// VarError represents an error of the single variable.
+type VarError struct {
+	Name string
+	Err  error
+}
+
+func (ve VarError) Error() string {
+	return fmt.Sprintf("%s: %s", ve.Name, ve.Err)
+}
+
+// Errors reports all the invalid, missing and unknown variables at once.
+type Errors []VarError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, ve := range e {
+		msgs[i] = ve.Error()
+	}
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+type field struct {
+	name     string
+	def      string
+	required bool
+	secret   bool
+	value    reflect.Value
+}
+
+// Load populates the struct pointed to by cfg from the environment. If
+// any of the variables is missing or invalid, Errors listing all of them
+// is returned. Variables starting with one of the prefixes that are not
+// bound to any of the fields are reported as unknown.
+func Load(cfg interface{}, prefixes ...string) error {
+	fields, err := parseStruct(cfg)
+	if err != nil {
+		return err
+	}
+
+	var errs Errors
+	known := map[string]bool{}
+	for _, f := range fields {
+		known[f.name] = true
+		val := os.Getenv(f.name)
+		if val == "" {
+			if f.required {
+				errs = append(errs, VarError{f.name, ErrMissingVariable})
+				continue
+			}
+			val = f.def
+		}
+		if val == "" {
+			continue
+		}
+		if err := set(f.value, val); err != nil {
+			errs = append(errs, VarError{f.name, errors.Wrap(ErrInvalidValue, err)})
+		}
+	}
+
+	var unknown []string
+	for _, kv := range os.Environ() {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if !known[name] && hasPrefix(name, prefixes) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs = append(errs, VarError{name, ErrUnknownVariable})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Redact returns the loaded configuration formatted as NAME=value pairs
+// suitable for the startup log. Values of the secret fields are redacted.
+func Redact(cfg interface{}) string {
+	fields, err := parseStruct(cfg)
+	if err != nil {
+		return ""
+	}
+
+	pairs := make([]string, len(fields))
+	for i, f := range fields {
+		val := format(f.value)
+		if f.secret && val != "" {
+			val = redacted
+		}
+		pairs[i] = fmt.Sprintf("%s=%s", f.name, val)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func parseStruct(cfg interface{}) ([]field, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.Wrap(ErrInvalidConfig, errors.New("expected pointer to struct"))
+	}
+	return parseFields(v.Elem())
+}
+
+func parseFields(v reflect.Value) ([]field, error) {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup("env")
+		if !ok {
+			if sf.Type.Kind() == reflect.Struct && sf.Type != urlType {
+				nested, err := parseFields(v.Field(i))
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, nested...)
+			}
+			continue
+		}
+		if sf.PkgPath != "" {
+			return nil, errors.Wrap(ErrInvalidConfig, fmt.Errorf("field %s is not exported", sf.Name))
+		}
+
+		opts := strings.Split(tag, ",")
+		f := field{
+			name:  opts[0],
+			def:   sf.Tag.Get("default"),
+			value: v.Field(i),
+		}
+		for _, opt := range opts[1:] {
+			switch opt {
+			case optRequired:
+				f.required = true
+			case optSecret:
+				f.secret = true
+			default:
+				return nil, errors.Wrap(ErrInvalidConfig, fmt.Errorf("unknown option %s of field %s", opt, sf.Name))
+			}
+		}
+		if !supported(sf.Type) {
+			return nil, errors.Wrap(ErrInvalidConfig, fmt.Errorf("unsupported type %s of field %s", sf.Type, sf.Name))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func supported(t reflect.Type) bool {
+	switch t {
+	case durationType, urlType, sizeType:
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float64,
+		reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func set(v reflect.Value, val string) error {
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	case urlType:
+		u, err := parseURL(val)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(*u))
+		return nil
+	case sizeType:
+		s, err := ParseByteSize(val)
+		if err != nil {
+			return err
+		}
+		v.SetUint(uint64(s))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		v.Set(reflect.ValueOf(parseSlice(val)))
+	}
+	return nil
+}
+
+func format(v reflect.Value) string {
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String()
+	case urlType:
+		u := v.Interface().(url.URL)
+		return u.String()
+	case sizeType:
+		return ByteSize(v.Uint()).String()
+	}
+	if v.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// parseURL parses absolute URL, e.g. nats://localhost:4222.
+func parseURL(val string) (*url.URL, error) {
+	u, err := url.Parse(val)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("%q is not an absolute URL", val)
+	}
+	return u, nil
+}
+
+// parseSlice parses comma separated values, ignoring the empty ones.
+func parseSlice(val string) []string {
+	vals := []string{}
+	for _, s := range strings.Split(val, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			vals = append(vals, s)
+		}
+	}
+	return vals
+}
+
+func hasPrefix(name string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
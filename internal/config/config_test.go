@@ -0,0 +1,270 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package config_test
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/config"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const prefix = "MF_CONFIG_TEST_"
+
+type nested struct {
+	Timeout time.Duration `env:"MF_CONFIG_TEST_TIMEOUT" default:"1s"`
+}
+
+type testConfig struct {
+	Port     string          `env:"MF_CONFIG_TEST_PORT" default:"8180"`
+	TLS      bool            `env:"MF_CONFIG_TEST_TLS" default:"false"`
+	Retries  int             `env:"MF_CONFIG_TEST_RETRIES" default:"3"`
+	Limit    uint64          `env:"MF_CONFIG_TEST_LIMIT"`
+	Ratio    float64         `env:"MF_CONFIG_TEST_RATIO" default:"0.5"`
+	NatsURL  url.URL         `env:"MF_CONFIG_TEST_NATS_URL,required"`
+	Size     config.ByteSize `env:"MF_CONFIG_TEST_SIZE" default:"1MB"`
+	Topics   []string        `env:"MF_CONFIG_TEST_TOPICS"`
+	Password string          `env:"MF_CONFIG_TEST_PASS,secret"`
+	nested
+}
+
+// setEnv sets the variables and returns function restoring them.
+func setEnv(vars map[string]string) func() {
+	for k, v := range vars {
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k := range vars {
+			os.Unsetenv(k)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	natsURL, err := url.Parse("nats://localhost:4222")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc string
+		env  map[string]string
+		cfg  testConfig
+	}{
+		{
+			desc: "load defaults",
+			env: map[string]string{
+				"MF_CONFIG_TEST_NATS_URL": "nats://localhost:4222",
+			},
+			cfg: testConfig{
+				Port:    "8180",
+				Retries: 3,
+				Ratio:   0.5,
+				NatsURL: *natsURL,
+				Size:    config.MB,
+				nested:  nested{Timeout: time.Second},
+			},
+		},
+		{
+			desc: "load all variables",
+			env: map[string]string{
+				"MF_CONFIG_TEST_PORT":     "9000",
+				"MF_CONFIG_TEST_TLS":      "true",
+				"MF_CONFIG_TEST_RETRIES":  "-1",
+				"MF_CONFIG_TEST_LIMIT":    "100",
+				"MF_CONFIG_TEST_RATIO":    "0.25",
+				"MF_CONFIG_TEST_NATS_URL": "nats://localhost:4222",
+				"MF_CONFIG_TEST_SIZE":     "512",
+				"MF_CONFIG_TEST_TOPICS":   "channels.*, ,channels.1.>",
+				"MF_CONFIG_TEST_PASS":     "secret",
+				"MF_CONFIG_TEST_TIMEOUT":  "1m30s",
+			},
+			cfg: testConfig{
+				Port:     "9000",
+				TLS:      true,
+				Retries:  -1,
+				Limit:    100,
+				Ratio:    0.25,
+				NatsURL:  *natsURL,
+				Size:     512,
+				Topics:   []string{"channels.*", "channels.1.>"},
+				Password: "secret",
+				nested:   nested{Timeout: 90 * time.Second},
+			},
+		},
+		{
+			desc: "load empty variable as unset",
+			env: map[string]string{
+				"MF_CONFIG_TEST_PORT":     "",
+				"MF_CONFIG_TEST_NATS_URL": "nats://localhost:4222",
+			},
+			cfg: testConfig{
+				Port:    "8180",
+				Retries: 3,
+				Ratio:   0.5,
+				NatsURL: *natsURL,
+				Size:    config.MB,
+				nested:  nested{Timeout: time.Second},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		restore := setEnv(tc.env)
+		var cfg testConfig
+		err := config.Load(&cfg, prefix)
+		restore()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.cfg, cfg, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.cfg, cfg))
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	restore := setEnv(map[string]string{
+		"MF_CONFIG_TEST_TLS":      "yes",
+		"MF_CONFIG_TEST_RETRIES":  "three",
+		"MF_CONFIG_TEST_LIMIT":    "-1",
+		"MF_CONFIG_TEST_RATIO":    "half",
+		"MF_CONFIG_TEST_SIZE":     "1XB",
+		"MF_CONFIG_TEST_TIMEOUT":  "1 minute",
+		"MF_CONFIG_TEST_NATS_ULR": "nats://nats:4222",
+		"MF_CONFIG_TEST_PROT":     "9000",
+	})
+	defer restore()
+
+	var cfg testConfig
+	err := config.Load(&cfg, prefix)
+	require.NotNil(t, err, "expected error loading invalid configuration")
+
+	errs, ok := err.(config.Errors)
+	require.True(t, ok, fmt.Sprintf("expected config.Errors got %T", err))
+
+	expected := []struct {
+		name string
+		err  error
+	}{
+		{"MF_CONFIG_TEST_TLS", config.ErrInvalidValue},
+		{"MF_CONFIG_TEST_RETRIES", config.ErrInvalidValue},
+		{"MF_CONFIG_TEST_LIMIT", config.ErrInvalidValue},
+		{"MF_CONFIG_TEST_RATIO", config.ErrInvalidValue},
+		{"MF_CONFIG_TEST_NATS_URL", config.ErrMissingVariable},
+		{"MF_CONFIG_TEST_SIZE", config.ErrInvalidValue},
+		{"MF_CONFIG_TEST_TIMEOUT", config.ErrInvalidValue},
+		{"MF_CONFIG_TEST_NATS_ULR", config.ErrUnknownVariable},
+		{"MF_CONFIG_TEST_PROT", config.ErrUnknownVariable},
+	}
+	require.Equal(t, len(expected), len(errs), fmt.Sprintf("expected %d errors got %d: %s", len(expected), len(errs), err))
+	for i, e := range expected {
+		assert.Equal(t, e.name, errs[i].Name, fmt.Sprintf("expected error of %s got %s", e.name, errs[i].Name))
+		assert.True(t, errors.Contains(errs[i].Err, e.err), fmt.Sprintf("%s: expected error %s got %s", e.name, e.err, errs[i].Err))
+		assert.Contains(t, err.Error(), e.name, fmt.Sprintf("expected report to contain %s", e.name))
+	}
+}
+
+func TestLoadURL(t *testing.T) {
+	cases := []struct {
+		desc string
+		val  string
+		err  error
+	}{
+		{desc: "load URL", val: "tcp://localhost:1883", err: nil},
+		{desc: "load URL without scheme", val: "localhost:4222", err: config.ErrInvalidValue},
+		{desc: "load relative URL", val: "/nats", err: config.ErrInvalidValue},
+		{desc: "load malformed URL", val: "nats://local host:%zz", err: config.ErrInvalidValue},
+	}
+
+	for _, tc := range cases {
+		restore := setEnv(map[string]string{"MF_CONFIG_TEST_NATS_URL": tc.val})
+		var cfg testConfig
+		err := config.Load(&cfg)
+		restore()
+		if tc.err == nil {
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			assert.Equal(t, tc.val, cfg.NatsURL.String(), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.val, cfg.NatsURL.String()))
+			continue
+		}
+		errs, ok := err.(config.Errors)
+		require.True(t, ok, fmt.Sprintf("%s: expected config.Errors got %T", tc.desc, err))
+		assert.True(t, errors.Contains(errs[0].Err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, errs[0].Err))
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	cases := []struct {
+		val  string
+		size config.ByteSize
+		err  bool
+	}{
+		{val: "512", size: 512},
+		{val: "512B", size: 512},
+		{val: "64K", size: 64 * config.KB},
+		{val: "64KB", size: 64 * config.KB},
+		{val: "64kib", size: 64 * config.KB},
+		{val: "1.5MiB", size: config.MB + config.MB/2},
+		{val: "2 GB", size: 2 * config.GB},
+		{val: "1TB", size: config.TB},
+		{val: "", err: true},
+		{val: "MB", err: true},
+		{val: "10XB", err: true},
+		{val: "1.2.3MB", err: true},
+	}
+
+	for _, tc := range cases {
+		size, err := config.ParseByteSize(tc.val)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%q: unexpected error result %v", tc.val, err))
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%q: expected %d got %d", tc.val, tc.size, size))
+	}
+
+	assert.Equal(t, "1536KB", (config.MB + config.MB/2).String(), "expected byte size formatted in the largest whole unit")
+	assert.Equal(t, "100B", config.ByteSize(100).String(), "expected byte size formatted in bytes")
+}
+
+func TestRedact(t *testing.T) {
+	restore := setEnv(map[string]string{
+		"MF_CONFIG_TEST_NATS_URL": "nats://localhost:4222",
+		"MF_CONFIG_TEST_TOPICS":   "a,b",
+		"MF_CONFIG_TEST_PASS":     "secret",
+	})
+	defer restore()
+
+	var cfg testConfig
+	err := config.Load(&cfg, prefix)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	expected := "MF_CONFIG_TEST_PORT=8180 MF_CONFIG_TEST_TLS=false MF_CONFIG_TEST_RETRIES=3 MF_CONFIG_TEST_LIMIT=0 " +
+		"MF_CONFIG_TEST_RATIO=0.5 MF_CONFIG_TEST_NATS_URL=nats://localhost:4222 MF_CONFIG_TEST_SIZE=1MB " +
+		"MF_CONFIG_TEST_TOPICS=a,b MF_CONFIG_TEST_PASS=*** MF_CONFIG_TEST_TIMEOUT=1s"
+	assert.Equal(t, expected, config.Redact(&cfg), "expected configuration with redacted secrets")
+}
+
+func TestLoadInvalidStruct(t *testing.T) {
+	var unexported struct {
+		port string `env:"MF_CONFIG_TEST_PORT"`
+	}
+	var unsupported struct {
+		Ports map[string]string `env:"MF_CONFIG_TEST_PORT"`
+	}
+	var unknownOpt struct {
+		Port string `env:"MF_CONFIG_TEST_PORT,optional"`
+	}
+
+	cases := []struct {
+		desc string
+		cfg  interface{}
+	}{
+		{desc: "load into non-pointer", cfg: testConfig{}},
+		{desc: "load into unexported field", cfg: &unexported},
+		{desc: "load into unsupported type", cfg: &unsupported},
+		{desc: "load using unknown option", cfg: &unknownOpt},
+	}
+
+	for _, tc := range cases {
+		err := config.Load(tc.cfg)
+		assert.True(t, errors.Contains(err, config.ErrInvalidConfig), fmt.Sprintf("%s: expected error %s got %s", tc.desc, config.ErrInvalidConfig, err))
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	offsetKey = "offset"
+	limitKey  = "limit"
+)
+
+type requestURLKey struct{}
+
+// PageRes represents the pagination part of the list response.
+type PageRes struct {
+	Total  uint64 `json:"total"`
+	Offset uint64 `json:"offset"`
+	Limit  uint64 `json:"limit"`
+	Order  string `json:"order"`
+	Dir    string `json:"direction"`
+	Links
+}
+
+// Links contains URLs of the next and the previous page.
+type Links struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// Headers returns RFC 5988 Link header referencing the next and the
+// previous page.
+func (l Links) Headers() map[string]string {
+	var links []string
+	if l.Next != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, l.Next))
+	}
+	if l.Prev != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, l.Prev))
+	}
+	if len(links) == 0 {
+		return map[string]string{}
+	}
+	return map[string]string{"Link": strings.Join(links, ", ")}
+}
+
+// WithRequestURL stores URL of the GET request to the context, so that
+// the links to the other pages can be created. It's intended to be used
+// as go-kit HTTP server before function.
+func WithRequestURL(ctx context.Context, r *http.Request) context.Context {
+	if r.Method != http.MethodGet {
+		return ctx
+	}
+	return context.WithValue(ctx, requestURLKey{}, r.URL)
+}
+
+// PageLinks returns links to the next and the previous page of the request
+// stored in the context. The links keep all the query parameters of the
+// request, except offset, which is changed to point to the other page.
+func PageLinks(ctx context.Context, total, offset, limit uint64) Links {
+	u, ok := ctx.Value(requestURLKey{}).(*url.URL)
+	if !ok || limit == 0 {
+		return Links{}
+	}
+
+	var links Links
+	if offset+limit < total {
+		links.Next = pageURL(u, offset+limit, limit)
+	}
+	if offset > 0 {
+		prev := uint64(0)
+		if offset > limit {
+			prev = offset - limit
+		}
+		if prev >= total && total > 0 {
+			// Offset is past the last page, so reference the last page.
+			prev = (total - 1) / limit * limit
+		}
+		links.Prev = pageURL(u, prev, limit)
+	}
+	return links
+}
+
+func pageURL(u *url.URL, offset, limit uint64) string {
+	q := u.Query()
+	q.Set(offsetKey, strconv.FormatUint(offset, 10))
+	q.Set(limitKey, strconv.FormatUint(limit, 10))
+	p := url.URL{Path: u.Path, RawQuery: q.Encode()}
+	return p.String()
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package httputil_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mainflux/mainflux/internal/httputil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const filters = `name=lamp&metadata=%7B%22room%22%3A%22kitchen%22%7D&order=name&dir=desc`
+
+func TestPageLinks(t *testing.T) {
+	cases := []struct {
+		desc   string
+		method string
+		offset uint64
+		limit  uint64
+		total  uint64
+		next   string
+		prev   string
+	}{
+		{
+			desc:   "first page",
+			method: http.MethodGet,
+			offset: 0,
+			limit:  10,
+			total:  25,
+			next:   "offset=10&limit=10",
+		},
+		{
+			desc:   "middle page",
+			method: http.MethodGet,
+			offset: 10,
+			limit:  10,
+			total:  25,
+			next:   "offset=20&limit=10",
+			prev:   "offset=0&limit=10",
+		},
+		{
+			desc:   "last page",
+			method: http.MethodGet,
+			offset: 20,
+			limit:  10,
+			total:  25,
+			prev:   "offset=10&limit=10",
+		},
+		{
+			desc:   "page not aligned to the limit",
+			method: http.MethodGet,
+			offset: 5,
+			limit:  10,
+			total:  25,
+			next:   "offset=15&limit=10",
+			prev:   "offset=0&limit=10",
+		},
+		{
+			desc:   "page past the last page",
+			method: http.MethodGet,
+			offset: 40,
+			limit:  10,
+			total:  25,
+			prev:   "offset=20&limit=10",
+		},
+		{
+			desc:   "single page",
+			method: http.MethodGet,
+			offset: 0,
+			limit:  10,
+			total:  10,
+		},
+		{
+			desc:   "zero limit",
+			method: http.MethodGet,
+			offset: 10,
+			limit:  0,
+			total:  25,
+		},
+		{
+			desc:   "non GET request",
+			method: http.MethodPost,
+			offset: 10,
+			limit:  10,
+			total:  25,
+		},
+	}
+
+	for _, tc := range cases {
+		r := httptest.NewRequest(tc.method, fmt.Sprintf("/things?%s&offset=%d&limit=%d", filters, tc.offset, tc.limit), nil)
+		ctx := httputil.WithRequestURL(context.Background(), r)
+
+		links := httputil.PageLinks(ctx, tc.total, tc.offset, tc.limit)
+		assertLink(t, tc.desc, "next", tc.next, links.Next)
+		assertLink(t, tc.desc, "prev", tc.prev, links.Prev)
+	}
+}
+
+func TestLinksHeaders(t *testing.T) {
+	cases := []struct {
+		desc    string
+		links   httputil.Links
+		headers map[string]string
+	}{
+		{
+			desc:    "no links",
+			links:   httputil.Links{},
+			headers: map[string]string{},
+		},
+		{
+			desc:    "next link",
+			links:   httputil.Links{Next: "/things?offset=10"},
+			headers: map[string]string{"Link": `</things?offset=10>; rel="next"`},
+		},
+		{
+			desc:    "next and prev links",
+			links:   httputil.Links{Next: "/things?offset=20", Prev: "/things?offset=0"},
+			headers: map[string]string{"Link": `</things?offset=20>; rel="next", </things?offset=0>; rel="prev"`},
+		},
+	}
+
+	for _, tc := range cases {
+		headers := tc.links.Headers()
+		assert.Equal(t, tc.headers, headers, fmt.Sprintf("%s: expected headers %v got %v", tc.desc, tc.headers, headers))
+	}
+}
+
+// assertLink checks that the link points to the same path, keeps all the
+// filters and changes only the page parameters.
+func assertLink(t *testing.T, desc, rel, page, link string) {
+	if page == "" {
+		assert.Empty(t, link, fmt.Sprintf("%s: expected no %s link got %s", desc, rel, link))
+		return
+	}
+
+	u, err := url.Parse(link)
+	require.Nil(t, err, fmt.Sprintf("%s: unexpected error parsing %s link: %s", desc, rel, err))
+	assert.Equal(t, "/things", u.Path, fmt.Sprintf("%s: expected %s link path /things got %s", desc, rel, u.Path))
+
+	expected, err := url.ParseQuery(fmt.Sprintf("%s&%s", filters, page))
+	require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", desc, err))
+	assert.Equal(t, expected, u.Query(), fmt.Sprintf("%s: expected %s link query %v got %v", desc, rel, expected, u.Query()))
+}
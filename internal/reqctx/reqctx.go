@@ -0,0 +1,134 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package reqctx contains the request scoped data, such as request ID,
+// authenticated subject and remote address, that is populated once by the
+// transport and shared by the middlewares of the API, service and
+// repository layers, as well as by the services called over gRPC.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SubjectType represents the type of the authenticated subject.
+type SubjectType string
+
+const (
+	// UserSubject is the type of the subject authenticated using user token.
+	UserSubject SubjectType = "user"
+	// ThingSubject is the type of the subject authenticated using thing key.
+	ThingSubject SubjectType = "thing"
+)
+
+// Subject represents the authenticated caller.
+type Subject struct {
+	Type SubjectType
+	ID   string
+}
+
+// User returns user subject.
+func User(id string) Subject {
+	return Subject{Type: UserSubject, ID: id}
+}
+
+// Thing returns thing subject.
+func Thing(id string) Subject {
+	return Subject{Type: ThingSubject, ID: id}
+}
+
+func (s Subject) String() string {
+	if s.ID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s", s.Type, s.ID)
+}
+
+// Info represents request scoped data.
+type Info struct {
+	RequestID  string
+	RemoteAddr string
+	Subject    Subject
+}
+
+type infoKey struct{}
+
+// info is shared by all the contexts derived from the request context,
+// so the subject authenticated by the service is visible to the outer
+// middlewares as well.
+type info struct {
+	mu   sync.RWMutex
+	data Info
+}
+
+// With returns context carrying the request info.
+func With(ctx context.Context, i Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, &info{data: i})
+}
+
+// FromContext returns the request info carried by the context.
+func FromContext(ctx context.Context) (Info, bool) {
+	i, ok := ctx.Value(infoKey{}).(*info)
+	if !ok {
+		return Info{}, false
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.data, true
+}
+
+// RequestID returns ID of the request, or empty string if the context
+// doesn't carry the request info.
+func RequestID(ctx context.Context) string {
+	i, _ := FromContext(ctx)
+	return i.RequestID
+}
+
+// SetSubject sets the authenticated subject of the request. It's a no-op
+// if the context doesn't carry the request info.
+func SetSubject(ctx context.Context, s Subject) {
+	i, ok := ctx.Value(infoKey{}).(*info)
+	if !ok {
+		return
+	}
+	i.mu.Lock()
+	i.data.Subject = s
+	i.mu.Unlock()
+}
+
+// Describe returns request info formatted to be appended to log messages.
+func Describe(ctx context.Context) string {
+	i, ok := FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	var fields []string
+	if i.RequestID != "" {
+		fields = append(fields, "request_id="+i.RequestID)
+	}
+	if s := i.Subject.String(); s != "" {
+		fields = append(fields, "subject="+s)
+	}
+	if i.RemoteAddr != "" {
+		fields = append(fields, "remote_addr="+i.RemoteAddr)
+	}
+	if len(fields) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", strings.Join(fields, " "))
+}
+
+// NewRequestID returns random request ID.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,201 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package reqctx_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const requestID = "3f6c1f5a-request"
+
+func TestMiddleware(t *testing.T) {
+	cases := []struct {
+		desc       string
+		header     map[string]string
+		remoteAddr string
+		id         string
+		addr       string
+	}{
+		{
+			desc:       "reuse request ID sent by the client",
+			header:     map[string]string{reqctx.RequestIDHeader: requestID},
+			remoteAddr: "10.0.0.1:51234",
+			id:         requestID,
+			addr:       "10.0.0.1",
+		},
+		{
+			desc:       "generate missing request ID",
+			remoteAddr: "10.0.0.1:51234",
+			addr:       "10.0.0.1",
+		},
+		{
+			desc:       "replace invalid request ID",
+			header:     map[string]string{reqctx.RequestIDHeader: "id with spaces"},
+			remoteAddr: "10.0.0.1:51234",
+			addr:       "10.0.0.1",
+		},
+		{
+			desc:       "replace too long request ID",
+			header:     map[string]string{reqctx.RequestIDHeader: strings.Repeat("a", 129)},
+			remoteAddr: "10.0.0.1:51234",
+			addr:       "10.0.0.1",
+		},
+		{
+			desc: "use forwarded remote address",
+			header: map[string]string{
+				reqctx.RequestIDHeader: requestID,
+				"X-Forwarded-For":      "192.168.1.10, 10.0.0.2",
+			},
+			remoteAddr: "10.0.0.1:51234",
+			id:         requestID,
+			addr:       "192.168.1.10",
+		},
+		{
+			desc: "ignore forwarded remote address spoofed by the client",
+			header: map[string]string{
+				reqctx.RequestIDHeader: requestID,
+				"X-Forwarded-For":      "10.0.0.5",
+			},
+			remoteAddr: "192.168.1.10:51234",
+			id:         requestID,
+			addr:       "192.168.1.10",
+		},
+		{
+			desc: "ignore forwarded remote address spoofed before the trusted proxy",
+			header: map[string]string{
+				reqctx.RequestIDHeader: requestID,
+				"X-Forwarded-For":      "10.0.0.5, 192.168.1.10",
+			},
+			remoteAddr: "10.0.0.1:51234",
+			id:         requestID,
+			addr:       "192.168.1.10",
+		},
+	}
+
+	proxies, err := reqctx.ParseProxies([]string{"10.0.0.0/24"})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	for _, tc := range cases {
+		var info reqctx.Info
+		h := reqctx.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			info, _ = reqctx.FromContext(r.Context())
+		}), proxies)
+
+		req := httptest.NewRequest(http.MethodGet, "/things", nil)
+		req.RemoteAddr = tc.remoteAddr
+		for k, v := range tc.header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if tc.id != "" {
+			assert.Equal(t, tc.id, info.RequestID, fmt.Sprintf("%s: expected request ID %s got %s", tc.desc, tc.id, info.RequestID))
+		}
+		assert.NotEmpty(t, info.RequestID, fmt.Sprintf("%s: expected request ID", tc.desc))
+		assert.Equal(t, info.RequestID, w.Header().Get(reqctx.RequestIDHeader), fmt.Sprintf("%s: expected request ID in response header", tc.desc))
+		assert.Equal(t, tc.addr, info.RemoteAddr, fmt.Sprintf("%s: expected remote address %s got %s", tc.desc, tc.addr, info.RemoteAddr))
+	}
+}
+
+func TestParseProxies(t *testing.T) {
+	cases := []struct {
+		desc    string
+		addrs   []string
+		trusted string
+		err     error
+	}{
+		{
+			desc:    "parse IPv4 address",
+			addrs:   []string{"10.0.0.1"},
+			trusted: "10.0.0.1/32",
+		},
+		{
+			desc:    "parse CIDR ranges",
+			addrs:   []string{" 10.0.0.0/8", "fd00::/8", ""},
+			trusted: "10.0.0.0/8,fd00::/8",
+		},
+		{
+			desc:    "parse IPv6 address",
+			addrs:   []string{"fd00::1"},
+			trusted: "fd00::1/128",
+		},
+		{
+			desc:  "parse malformed address",
+			addrs: []string{"10.0.0.256"},
+			err:   reqctx.ErrMalformedProxy,
+		},
+		{
+			desc:  "parse malformed CIDR range",
+			addrs: []string{"10.0.0.0/33"},
+			err:   reqctx.ErrMalformedProxy,
+		},
+	}
+
+	for _, tc := range cases {
+		proxies, err := reqctx.ParseProxies(tc.addrs)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		var trusted []string
+		for _, p := range proxies {
+			trusted = append(trusted, p.String())
+		}
+		assert.Equal(t, tc.trusted, strings.Join(trusted, ","), fmt.Sprintf("%s: expected proxies %s got %v", tc.desc, tc.trusted, trusted))
+	}
+}
+
+func TestSetSubject(t *testing.T) {
+	ctx := reqctx.With(context.Background(), reqctx.Info{RequestID: requestID})
+	inner, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reqctx.SetSubject(inner, reqctx.Thing("thing-id"))
+	info, ok := reqctx.FromContext(ctx)
+	assert.True(t, ok, "expected context to carry request info")
+	assert.Equal(t, reqctx.Thing("thing-id"), info.Subject, fmt.Sprintf("expected subject set using derived context got %v", info.Subject))
+	assert.Equal(t, " [request_id=3f6c1f5a-request subject=thing:thing-id]", reqctx.Describe(ctx), "expected request info description")
+
+	reqctx.SetSubject(context.Background(), reqctx.User("user-id"))
+	_, ok = reqctx.FromContext(context.Background())
+	assert.False(t, ok, "expected context without request info")
+	assert.Equal(t, "", reqctx.Describe(context.Background()), "expected empty description without request info")
+	assert.Equal(t, "", reqctx.RequestID(context.Background()), "expected empty request ID without request info")
+}
+
+func TestGRPCMetadata(t *testing.T) {
+	ctx := reqctx.With(context.Background(), reqctx.Info{
+		RequestID:  requestID,
+		RemoteAddr: "192.168.1.10",
+		Subject:    reqctx.Thing("thing-id"),
+	})
+	md := metadata.MD{}
+	reqctx.ContextToGRPC(ctx, &md)
+
+	info, ok := reqctx.FromContext(reqctx.GRPCToContext(context.Background(), md))
+	assert.True(t, ok, "expected context to carry request info")
+	assert.Equal(t, requestID, info.RequestID, fmt.Sprintf("expected request ID %s got %s", requestID, info.RequestID))
+	assert.Equal(t, "192.168.1.10", info.RemoteAddr, fmt.Sprintf("expected remote address %s got %s", "192.168.1.10", info.RemoteAddr))
+	assert.Equal(t, reqctx.Subject{}, info.Subject, "expected subject to be authenticated by the callee")
+
+	md = metadata.MD{}
+	reqctx.ContextToGRPC(context.Background(), &md)
+	assert.Empty(t, md, "expected no metadata without request info")
+
+	addr := &net.TCPAddr{IP: net.ParseIP("10.0.0.3"), Port: 40000}
+	pctx := peer.NewContext(context.Background(), &peer.Peer{Addr: addr})
+	info, _ = reqctx.FromContext(reqctx.GRPCToContext(pctx, md))
+	assert.NotEmpty(t, info.RequestID, "expected request ID to be generated")
+	assert.Equal(t, "10.0.0.3", info.RemoteAddr, fmt.Sprintf("expected peer address got %s", info.RemoteAddr))
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package reqctx
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// RequestIDHeader is HTTP header carrying the request ID.
+	RequestIDHeader = "X-Request-ID"
+
+	forwardedForHeader = "X-Forwarded-For"
+	requestIDKey       = "x-request-id"
+	remoteAddrKey      = "x-forwarded-for"
+	maxRequestIDLen    = 128
+)
+
+// ErrMalformedProxy indicates the trusted proxy that is neither IP address
+// nor CIDR range.
+var ErrMalformedProxy = errors.New("malformed trusted proxy address")
+
+// Proxies contains the address ranges of the proxies trusted to set the
+// X-Forwarded-For header.
+type Proxies []*net.IPNet
+
+// ParseProxies parses the IP addresses and CIDR ranges of the trusted
+// proxies. The empty values are ignored.
+func ParseProxies(addrs []string) (Proxies, error) {
+	var proxies Proxies
+	for _, addr := range addrs {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if !strings.Contains(addr, "/") {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return nil, errors.Wrap(ErrMalformedProxy, errors.New(addr))
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipnet, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, errors.Wrap(ErrMalformedProxy, err)
+		}
+		proxies = append(proxies, ipnet)
+	}
+	return proxies, nil
+}
+
+// Trusted returns true if the request is sent by one of the trusted proxies.
+func (p Proxies) Trusted(r *http.Request) bool {
+	return p.trusted(host(r.RemoteAddr))
+}
+
+func (p Proxies) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipnet := range p {
+		if ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware populates the request context with the request info and
+// returns the request ID in the response header. The request ID sent by
+// the client is reused, otherwise a new one is generated. The remote
+// address is taken from the X-Forwarded-For header only if the request
+// is sent by one of the trusted proxies.
+func Middleware(next http.Handler, proxies Proxies) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := validID(r.Header.Get(RequestIDHeader))
+		if id == "" {
+			id = NewRequestID()
+		}
+
+		ctx := With(r.Context(), Info{
+			RequestID:  id,
+			RemoteAddr: remoteAddr(r, proxies),
+		})
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ContextToGRPC adds the request info carried by the context to the
+// outgoing gRPC metadata. It's intended to be used as go-kit gRPC client
+// before function.
+func ContextToGRPC(ctx context.Context, md *metadata.MD) context.Context {
+	i, ok := FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	if i.RequestID != "" {
+		md.Set(requestIDKey, i.RequestID)
+	}
+	if i.RemoteAddr != "" {
+		md.Set(remoteAddrKey, i.RemoteAddr)
+	}
+	return ctx
+}
+
+// GRPCToContext populates the context with the request info received in
+// the incoming gRPC metadata. Missing request ID is generated and missing
+// remote address is set to the address of the caller. It's intended to be
+// used as go-kit gRPC server before function.
+func GRPCToContext(ctx context.Context, md metadata.MD) context.Context {
+	i := Info{
+		RequestID:  validID(first(md, requestIDKey)),
+		RemoteAddr: first(md, remoteAddrKey),
+	}
+	if i.RequestID == "" {
+		i.RequestID = NewRequestID()
+	}
+	if p, ok := peer.FromContext(ctx); ok && i.RemoteAddr == "" && p.Addr != nil {
+		i.RemoteAddr = host(p.Addr.String())
+	}
+	return With(ctx, i)
+}
+
+// remoteAddr returns the address of the client. The forwarded addresses
+// are appended by each proxy, so they're checked from the last one, and
+// the first address not set by a trusted proxy is the client.
+func remoteAddr(r *http.Request, proxies Proxies) string {
+	addr := host(r.RemoteAddr)
+	if !proxies.trusted(addr) {
+		return addr
+	}
+	fwd := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+	for i := len(fwd) - 1; i >= 0; i-- {
+		if f := strings.TrimSpace(fwd[i]); f != "" {
+			addr = f
+			if !proxies.trusted(addr) {
+				break
+			}
+		}
+	}
+	return addr
+}
+
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return addr
+}
+
+func first(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+// validID rejects the IDs that are too long or not printable, so that
+// clients can't inject arbitrary content into logs.
+func validID(id string) string {
+	if len(id) > maxRequestIDLen {
+		return ""
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return ""
+		}
+	}
+	return id
+}
@@ -0,0 +1,132 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package retry contains the retry policy shared by the clients calling
+// other services, so the calls survive the restarts of the called service.
+package retry
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	defInitialInterval = 100 * time.Millisecond
+	defMaxInterval     = 2 * time.Second
+	defMultiplier      = 2
+	defMaxElapsedTime  = 10 * time.Second
+)
+
+// ErrExhausted indicates the operation that kept failing with transient
+// errors until the retry budget was exhausted.
+var ErrExhausted = errors.New("retry budget exhausted")
+
+// Config represents the retry policy. Zero values, except the Jitter, are
+// replaced by the defaults.
+type Config struct {
+	// InitialInterval is the delay before the first retry.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the delay between the retries.
+	MaxInterval time.Duration
+
+	// Multiplier is the factor the delay is increased by after each retry.
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to the given fraction of it,
+	// e.g. 0.5 picks the delay between the half and 1.5 of the interval.
+	// Zero disables the randomization.
+	Jitter float64
+
+	// MaxElapsedTime bounds the total duration of the operation, so no
+	// retry is made if its delay would exceed it.
+	MaxElapsedTime time.Duration
+
+	// Notify is called before each retry with the error of the attempt,
+	// its number and the delay before the next one.
+	Notify func(err error, attempt int, next time.Duration)
+}
+
+// Do calls the operation until it succeeds, fails with the error that is not
+// transient, or the retry budget is exhausted. In the last case, the error
+// of the last attempt is returned wrapped by ErrExhausted, along with the
+// number of attempts made.
+func Do(ctx context.Context, cfg Config, transient func(error) bool, op func() error) error {
+	cfg = withDefaults(cfg)
+	b := &backoff.ExponentialBackOff{
+		InitialInterval:     cfg.InitialInterval,
+		RandomizationFactor: cfg.Jitter,
+		Multiplier:          cfg.Multiplier,
+		MaxInterval:         cfg.MaxInterval,
+		Stop:                backoff.Stop,
+		Clock:               backoff.SystemClock,
+	}
+	b.Reset()
+	deadline := time.Now().Add(cfg.MaxElapsedTime)
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || !transient(err) {
+			return err
+		}
+
+		next := b.NextBackOff()
+		if time.Now().Add(next).After(deadline) {
+			return exhausted(attempt, err)
+		}
+		if cfg.Notify != nil {
+			cfg.Notify(err, attempt, next)
+		}
+
+		t := time.NewTimer(next)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return exhausted(attempt, err)
+		case <-t.C:
+		}
+	}
+}
+
+// TransientStatus reports whether the response with the status code is
+// worth retrying, which are the server errors and the throttled requests.
+func TransientStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// TransientNetwork reports whether the error is caused by failure to reach
+// the service, such as refused connection or timeout.
+func TransientNetwork(err error) bool {
+	var ne net.Error
+	return stderrors.As(err, &ne)
+}
+
+func exhausted(attempts int, err error) error {
+	return errors.Wrap(ErrExhausted, errors.Wrap(fmt.Errorf("after %d attempts", attempts), err))
+}
+
+func withDefaults(cfg Config) Config {
+	if cfg.InitialInterval <= 0 {
+		cfg.InitialInterval = defInitialInterval
+	}
+	if cfg.MaxInterval <= 0 {
+		cfg.MaxInterval = defMaxInterval
+	}
+	if cfg.Multiplier <= 0 {
+		cfg.Multiplier = defMultiplier
+	}
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	}
+	if cfg.MaxElapsedTime <= 0 {
+		cfg.MaxElapsedTime = defMaxElapsedTime
+	}
+	return cfg
+}
@@ -0,0 +1,196 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package retry_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/retry"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errRejected  = errors.New("rejected")
+)
+
+func transient(err error) bool {
+	return errors.Contains(err, errTransient)
+}
+
+// flaky returns the operation failing with the errors in order, and
+// succeeding once they're all returned.
+func flaky(errs ...error) (func() error, *int) {
+	calls := 0
+	return func() error {
+		calls++
+		if calls > len(errs) {
+			return nil
+		}
+		return errs[calls-1]
+	}, &calls
+}
+
+func TestDo(t *testing.T) {
+	cases := []struct {
+		desc     string
+		cfg      retry.Config
+		errs     []error
+		err      error
+		calls    int
+		schedule []time.Duration
+	}{
+		{
+			desc:  "succeed at first attempt",
+			cfg:   retry.Config{InitialInterval: 10 * time.Millisecond},
+			calls: 1,
+		},
+		{
+			desc:     "succeed after transient failures",
+			cfg:      retry.Config{InitialInterval: 10 * time.Millisecond, Multiplier: 2, MaxElapsedTime: time.Second},
+			errs:     []error{errTransient, errTransient, errTransient},
+			calls:    4,
+			schedule: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond},
+		},
+		{
+			desc:     "cap retry interval",
+			cfg:      retry.Config{InitialInterval: 10 * time.Millisecond, Multiplier: 3, MaxInterval: 20 * time.Millisecond, MaxElapsedTime: time.Second},
+			errs:     []error{errTransient, errTransient, errTransient},
+			calls:    4,
+			schedule: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 20 * time.Millisecond},
+		},
+		{
+			desc:  "fail without retrying rejected operation",
+			cfg:   retry.Config{InitialInterval: 10 * time.Millisecond},
+			errs:  []error{errRejected},
+			err:   errRejected,
+			calls: 1,
+		},
+		{
+			desc:     "fail with rejected operation after transient failure",
+			cfg:      retry.Config{InitialInterval: 10 * time.Millisecond},
+			errs:     []error{errTransient, errRejected},
+			err:      errRejected,
+			calls:    2,
+			schedule: []time.Duration{10 * time.Millisecond},
+		},
+		{
+			desc:     "fail with exhausted budget",
+			cfg:      retry.Config{InitialInterval: 10 * time.Millisecond, Multiplier: 2, MaxElapsedTime: 50 * time.Millisecond},
+			errs:     []error{errTransient, errTransient, errTransient, errTransient},
+			err:      retry.ErrExhausted,
+			calls:    3,
+			schedule: []time.Duration{10 * time.Millisecond, 20 * time.Millisecond},
+		},
+	}
+
+	for _, tc := range cases {
+		var schedule []time.Duration
+		tc.cfg.Notify = func(err error, attempt int, next time.Duration) {
+			schedule = append(schedule, next)
+		}
+		op, calls := flaky(tc.errs...)
+		err := retry.Do(context.Background(), tc.cfg, transient, op)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.calls, *calls, fmt.Sprintf("%s: expected %d calls got %d", tc.desc, tc.calls, *calls))
+		assert.Equal(t, tc.schedule, schedule, fmt.Sprintf("%s: expected schedule %v got %v", tc.desc, tc.schedule, schedule))
+	}
+}
+
+func TestDoExhausted(t *testing.T) {
+	cfg := retry.Config{InitialInterval: 10 * time.Millisecond, MaxElapsedTime: 50 * time.Millisecond}
+	start := time.Now()
+	err := retry.Do(context.Background(), cfg, transient, func() error { return errTransient })
+	elapsed := time.Since(start)
+
+	assert.True(t, errors.Contains(err, retry.ErrExhausted), fmt.Sprintf("expected error %s got %s", retry.ErrExhausted, err))
+	assert.True(t, errors.Contains(err, errTransient), fmt.Sprintf("expected error %s got %s", errTransient, err))
+	assert.Equal(t, "retry budget exhausted : after 3 attempts : transient", err.Error(), fmt.Sprintf("expected attempts count in error got %s", err))
+	assert.Less(t, int64(elapsed), int64(cfg.MaxElapsedTime), fmt.Sprintf("expected retries within %s got %s", cfg.MaxElapsedTime, elapsed))
+}
+
+func TestDoJitter(t *testing.T) {
+	cfg := retry.Config{
+		InitialInterval: 10 * time.Millisecond,
+		Multiplier:      2,
+		Jitter:          0.5,
+		MaxElapsedTime:  time.Second,
+	}
+	var schedule []time.Duration
+	cfg.Notify = func(err error, attempt int, next time.Duration) {
+		schedule = append(schedule, next)
+	}
+	op, _ := flaky(errTransient, errTransient, errTransient)
+	err := retry.Do(context.Background(), cfg, transient, op)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	interval := cfg.InitialInterval
+	for i, next := range schedule {
+		min, max := interval/2, interval*3/2
+		assert.True(t, next >= min && next <= max, fmt.Sprintf("retry %d: expected delay in [%s, %s] got %s", i+1, min, max, next))
+		interval *= 2
+	}
+}
+
+func TestDoCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cfg := retry.Config{
+		InitialInterval: time.Second,
+		Notify: func(error, int, time.Duration) {
+			cancel()
+		},
+	}
+	err := retry.Do(ctx, cfg, transient, func() error { return errTransient })
+	assert.True(t, errors.Contains(err, retry.ErrExhausted), fmt.Sprintf("expected error %s got %s", retry.ErrExhausted, err))
+}
+
+func TestTransient(t *testing.T) {
+	_, err := net.Dial("tcp", "localhost:1")
+
+	cases := []struct {
+		desc      string
+		code      int
+		err       error
+		transient bool
+	}{
+		{
+			desc:      "service unavailable",
+			code:      503,
+			transient: true,
+		},
+		{
+			desc:      "too many requests",
+			code:      429,
+			transient: true,
+		},
+		{
+			desc:      "not found",
+			code:      404,
+			transient: false,
+		},
+		{
+			desc:      "refused connection",
+			err:       err,
+			transient: true,
+		},
+		{
+			desc:      "rejected operation",
+			err:       errRejected,
+			transient: false,
+		},
+	}
+
+	for _, tc := range cases {
+		transient := retry.TransientStatus(tc.code)
+		if tc.err != nil {
+			transient = retry.TransientNetwork(tc.err)
+		}
+		assert.Equal(t, tc.transient, transient, fmt.Sprintf("%s: expected transient %t got %t", tc.desc, tc.transient, transient))
+	}
+}
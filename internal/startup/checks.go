@@ -0,0 +1,77 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package startup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-redis/redis/v8"
+	_ "github.com/lib/pq" // required for SQL access
+	"github.com/mainflux/mainflux/pkg/errors"
+	broker "github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+var errNotConnected = errors.New("not connected")
+
+// Postgres returns the check connecting to the PostgreSQL instance with
+// the connection string and pinging it.
+func Postgres(url string) Check {
+	return Check{
+		Name: "postgres",
+		Probe: func(ctx context.Context) error {
+			db, err := sql.Open("postgres", url)
+			if err != nil {
+				return err
+			}
+			defer db.Close()
+			return db.PingContext(ctx)
+		},
+	}
+}
+
+// NATS returns the check connecting to the NATS instance.
+func NATS(url string) Check {
+	return Check{
+		Name: "nats",
+		Probe: func(context.Context) error {
+			nc, err := broker.Connect(url)
+			if err != nil {
+				return err
+			}
+			nc.Close()
+			return nil
+		},
+	}
+}
+
+// Redis returns the check pinging the Redis instance using the client.
+// The name tells apart the instances used for different purposes, such as
+// the cache and the event store.
+func Redis(name string, client *redis.Client) Check {
+	return Check{
+		Name: name,
+		Probe: func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		},
+	}
+}
+
+// GRPC returns the check waiting for the gRPC client connection to become
+// ready. The connection keeps reconnecting on its own, so the probe only
+// reports its state.
+func GRPC(name string, conn *grpc.ClientConn) Check {
+	return Check{
+		Name: name,
+		Probe: func(ctx context.Context) error {
+			if s := conn.GetState(); s != connectivity.Ready {
+				return errors.Wrap(errNotConnected, fmt.Errorf("connection state %s", s))
+			}
+			return nil
+		},
+	}
+}
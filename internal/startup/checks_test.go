@@ -0,0 +1,193 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package startup_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/internal/startup"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const delay = 300 * time.Millisecond
+
+// stub is the listener coming up after the delay.
+type stub struct {
+	addr string
+
+	mu sync.Mutex
+	up time.Time
+	ln net.Listener
+}
+
+// freeAddr returns the address nothing listens on.
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+// newStub starts listening on the free address after the delay, serving
+// the connections using the handler, or the gRPC server if it's set.
+func newStub(t *testing.T, handle func(net.Conn), srv *grpc.Server) *stub {
+	s := &stub{addr: freeAddr(t)}
+	time.AfterFunc(delay, func() {
+		ln, err := net.Listen("tcp", s.addr)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.ln = ln
+		s.up = time.Now()
+		s.mu.Unlock()
+
+		if srv != nil {
+			go srv.Serve(ln)
+			return
+		}
+		go func() {
+			for {
+				conn, err := ln.Accept()
+				if err != nil {
+					return
+				}
+				go handle(conn)
+			}
+		}()
+	})
+	return s
+}
+
+func (s *stub) upAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.up
+}
+
+func (s *stub) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ln != nil {
+		s.ln.Close()
+	}
+}
+
+// serveRedis replies to each command with PONG.
+func serveRedis(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if !strings.HasPrefix(line, "*") {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return
+		}
+		// Each argument is sent as the length line and the value line.
+		for i := 0; i < 2*n; i++ {
+			if _, err := r.ReadString('\n'); err != nil {
+				return
+			}
+		}
+		io.WriteString(conn, "+PONG\r\n")
+	}
+}
+
+// serveNATS sends the server info and replies to the pings.
+func serveNATS(conn net.Conn) {
+	defer conn.Close()
+	io.WriteString(conn, `INFO {"server_id":"stub","version":"2.2.0","max_payload":1048576}`+"\r\n")
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if strings.HasPrefix(line, "PING") {
+			io.WriteString(conn, "PONG\r\n")
+		}
+	}
+}
+
+func TestChecks(t *testing.T) {
+	cases := []struct {
+		desc    string
+		start   func() (startup.Check, *stub)
+		maxWait time.Duration
+		err     error
+	}{
+		{
+			desc: "wait for redis",
+			start: func() (startup.Check, *stub) {
+				s := newStub(t, serveRedis, nil)
+				return startup.Redis("cache", redis.NewClient(&redis.Options{Addr: s.addr})), s
+			},
+			maxWait: 3 * time.Second,
+		},
+		{
+			desc: "wait for nats",
+			start: func() (startup.Check, *stub) {
+				s := newStub(t, serveNATS, nil)
+				return startup.NATS(fmt.Sprintf("nats://%s", s.addr)), s
+			},
+			maxWait: 3 * time.Second,
+		},
+		{
+			desc: "wait for grpc",
+			start: func() (startup.Check, *stub) {
+				srv := grpc.NewServer()
+				s := newStub(t, nil, srv)
+				conn, err := grpc.Dial(s.addr, grpc.WithInsecure())
+				require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+				return startup.GRPC("auth", conn), s
+			},
+			maxWait: 3 * time.Second,
+		},
+		{
+			desc: "wait for unreachable postgres",
+			start: func() (startup.Check, *stub) {
+				host, port, err := net.SplitHostPort(freeAddr(t))
+				require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+				return startup.Postgres(fmt.Sprintf("host=%s port=%s user=mainflux dbname=mainflux sslmode=disable", host, port)), nil
+			},
+			maxWait: 500 * time.Millisecond,
+			err:     startup.ErrNotReady,
+		},
+	}
+
+	for _, tc := range cases {
+		check, s := tc.start()
+		gate := startup.NewGate(startup.Config{MaxWait: tc.maxWait, MaxInterval: 100 * time.Millisecond}, newLogger(t))
+		err := gate.WaitFor(context.Background(), check)
+		done := time.Now()
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		if s == nil {
+			continue
+		}
+		up := s.upAt()
+		s.close()
+		assert.False(t, up.IsZero(), fmt.Sprintf("%s: expected wait to return after the dependency came up", tc.desc))
+		assert.False(t, done.Before(up), fmt.Sprintf("%s: expected wait to return after %s got %s", tc.desc, up, done))
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package startup contains the readiness gate shared by the service mains,
+// which waits for the dependencies, such as the database or the message
+// broker, to come up before the service starts serving, so the services
+// survive the bringups in which they start first.
+package startup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/retry"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	healthPath = "/health"
+
+	defMaxWait     = time.Minute
+	defMaxInterval = 5 * time.Second
+
+	statusReady    = "ready"
+	statusStarting = "starting"
+)
+
+// ErrNotReady indicates the dependencies that didn't come up within the
+// maximum wait.
+var ErrNotReady = errors.New("dependencies not ready")
+
+// Check represents the dependency the service waits for.
+type Check struct {
+	// Name identifies the dependency in the logs and the health state.
+	Name string
+
+	// Probe returns nil once the dependency is reachable.
+	Probe func(context.Context) error
+}
+
+// Config represents the gate waiting policy. Zero values are replaced by
+// the defaults.
+type Config struct {
+	// MaxWait bounds the total duration of waiting for the dependencies.
+	MaxWait time.Duration
+
+	// MaxInterval caps the delay between the probes of a dependency.
+	MaxInterval time.Duration
+}
+
+// Gate tracks the dependencies the service waits for and serves the
+// readiness state. The gate opens once all the dependencies are up and
+// the service handler is set.
+type Gate struct {
+	cfg    Config
+	logger logger.Logger
+
+	mu      sync.Mutex
+	pending map[string]bool
+	waited  bool
+	next    atomic.Value
+}
+
+// NewGate returns the closed gate.
+func NewGate(cfg Config, logger logger.Logger) *Gate {
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = defMaxWait
+	}
+	if cfg.MaxInterval <= 0 {
+		cfg.MaxInterval = defMaxInterval
+	}
+	return &Gate{
+		cfg:     cfg,
+		logger:  logger,
+		pending: map[string]bool{},
+	}
+}
+
+// WaitFor probes the dependencies concurrently, with the backoff between
+// the failed probes, until they're all up. If any of them doesn't come up
+// within the maximum wait or the context is done, the names of the ones
+// still down are returned wrapped by ErrNotReady.
+func (g *Gate) WaitFor(ctx context.Context, checks ...Check) error {
+	g.mu.Lock()
+	for _, c := range checks {
+		g.pending[c.Name] = true
+	}
+	g.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, g.cfg.MaxWait)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c Check) {
+			defer wg.Done()
+			if err := g.wait(ctx, c); err != nil {
+				g.logger.Error(fmt.Sprintf("Gave up waiting for %s: %s", c.Name, err))
+				return
+			}
+			g.mu.Lock()
+			delete(g.pending, c.Name)
+			g.mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.pending) > 0 {
+		return errors.Wrap(ErrNotReady, fmt.Errorf("%s", strings.Join(g.pendingNames(), ", ")))
+	}
+	g.waited = true
+	return nil
+}
+
+func (g *Gate) wait(ctx context.Context, c Check) error {
+	rc := retry.Config{
+		MaxInterval:    g.cfg.MaxInterval,
+		MaxElapsedTime: g.cfg.MaxWait,
+		Notify: func(err error, attempt int, next time.Duration) {
+			g.logger.Warn(fmt.Sprintf("Waiting for %s, attempt %d failed, retrying in %s: %s", c.Name, attempt, next, err))
+		},
+	}
+	always := func(error) bool { return true }
+	return retry.Do(ctx, rc, always, func() error {
+		return c.Probe(ctx)
+	})
+}
+
+// Open sets the handler serving the requests once the dependencies are up.
+// Until then, the requests other than the health check are rejected.
+func (g *Gate) Open(h http.Handler) {
+	g.next.Store(h)
+}
+
+// Ready reports whether the gate is open.
+func (g *Gate) Ready() bool {
+	g.mu.Lock()
+	waited := g.waited
+	g.mu.Unlock()
+	return waited && g.next.Load() != nil
+}
+
+// ServeHTTP serves the readiness state on the /health path, responding with
+// 503 Service Unavailable and the pending dependencies until the gate
+// opens, and passes the rest of the requests to the handler once it's open.
+func (g *Gate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ready := g.Ready()
+	if r.URL.Path == healthPath {
+		g.serveHealth(w, ready)
+		return
+	}
+	if !ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	g.next.Load().(http.Handler).ServeHTTP(w, r)
+}
+
+type healthRes struct {
+	Status  string   `json:"status"`
+	Pending []string `json:"pending,omitempty"`
+}
+
+func (g *Gate) serveHealth(w http.ResponseWriter, ready bool) {
+	res := healthRes{Status: statusReady}
+	code := http.StatusOK
+	if !ready {
+		g.mu.Lock()
+		res = healthRes{Status: statusStarting, Pending: g.pendingNames()}
+		g.mu.Unlock()
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(res)
+}
+
+// pendingNames returns the sorted names of the dependencies still down.
+// It's called with the mutex locked.
+func (g *Gate) pendingNames() []string {
+	names := []string{}
+	for name := range g.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,146 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package startup_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/internal/startup"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogger(t *testing.T) logger.Logger {
+	l, err := logger.New(ioutil.Discard, "error")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return l
+}
+
+type healthRes struct {
+	Status  string   `json:"status"`
+	Pending []string `json:"pending"`
+}
+
+func health(t *testing.T, ts *httptest.Server) (int, healthRes) {
+	res, err := http.Get(fmt.Sprintf("%s/health", ts.URL))
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	defer res.Body.Close()
+	var body healthRes
+	err = json.NewDecoder(res.Body).Decode(&body)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return res.StatusCode, body
+}
+
+func status(t *testing.T, url string) int {
+	res, err := http.Get(url)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	res.Body.Close()
+	return res.StatusCode
+}
+
+func TestGate(t *testing.T) {
+	s := newStub(t, serveRedis, nil)
+	defer s.close()
+	check := startup.Redis("cache", redis.NewClient(&redis.Options{Addr: s.addr}))
+
+	gate := startup.NewGate(startup.Config{MaxWait: 3 * time.Second, MaxInterval: 50 * time.Millisecond}, newLogger(t))
+	gate.Open(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ts := httptest.NewServer(gate)
+	defer ts.Close()
+
+	done := make(chan error)
+	go func() {
+		done <- gate.WaitFor(context.Background(), check)
+	}()
+
+	// Probe the gate until the wait is over, checking that it's closed as
+	// long as the dependency is down.
+	var err error
+	for waiting := true; waiting; {
+		select {
+		case err = <-done:
+			waiting = false
+		case <-time.After(20 * time.Millisecond):
+			code, res := health(t, ts)
+			apiCode := status(t, fmt.Sprintf("%s/things", ts.URL))
+			if !s.upAt().IsZero() {
+				continue
+			}
+			assert.Equal(t, http.StatusServiceUnavailable, code, fmt.Sprintf("expected health status %d got %d while dependency is down", http.StatusServiceUnavailable, code))
+			assert.Equal(t, healthRes{Status: "starting", Pending: []string{"cache"}}, res, fmt.Sprintf("expected pending cache got %v", res))
+			assert.Equal(t, http.StatusServiceUnavailable, apiCode, fmt.Sprintf("expected API status %d got %d while dependency is down", http.StatusServiceUnavailable, apiCode))
+		}
+	}
+
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.False(t, s.upAt().IsZero(), "expected gate to open after the dependency came up")
+	assert.True(t, gate.Ready(), "expected gate to be open")
+	code, res := health(t, ts)
+	assert.Equal(t, http.StatusOK, code, fmt.Sprintf("expected health status %d got %d", http.StatusOK, code))
+	assert.Equal(t, healthRes{Status: "ready"}, res, fmt.Sprintf("expected ready got %v", res))
+	apiCode := status(t, fmt.Sprintf("%s/things", ts.URL))
+	assert.Equal(t, http.StatusNoContent, apiCode, fmt.Sprintf("expected API status %d got %d", http.StatusNoContent, apiCode))
+}
+
+func TestWaitFor(t *testing.T) {
+	errDown := errors.New("down")
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errDown }
+
+	cases := []struct {
+		desc    string
+		checks  []startup.Check
+		handler http.Handler
+		err     error
+		ready   bool
+		pending []string
+	}{
+		{
+			desc:    "wait for dependencies up",
+			checks:  []startup.Check{{Name: "postgres", Probe: up}, {Name: "nats", Probe: up}},
+			handler: http.NotFoundHandler(),
+			ready:   true,
+		},
+		{
+			desc:   "wait for dependencies up without handler",
+			checks: []startup.Check{{Name: "postgres", Probe: up}},
+			ready:  false,
+		},
+		{
+			desc:    "wait for dependencies down",
+			checks:  []startup.Check{{Name: "postgres", Probe: down}, {Name: "nats", Probe: up}, {Name: "cache", Probe: down}},
+			handler: http.NotFoundHandler(),
+			err:     startup.ErrNotReady,
+			ready:   false,
+			pending: []string{"cache", "postgres"},
+		},
+	}
+
+	for _, tc := range cases {
+		gate := startup.NewGate(startup.Config{MaxWait: 300 * time.Millisecond, MaxInterval: 50 * time.Millisecond}, newLogger(t))
+		if tc.handler != nil {
+			gate.Open(tc.handler)
+		}
+		ts := httptest.NewServer(gate)
+
+		err := gate.WaitFor(context.Background(), tc.checks...)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.ready, gate.Ready(), fmt.Sprintf("%s: expected ready %t got %t", tc.desc, tc.ready, gate.Ready()))
+		_, res := health(t, ts)
+		assert.Equal(t, tc.pending, res.Pending, fmt.Sprintf("%s: expected pending %v got %v", tc.desc, tc.pending, res.Pending))
+		ts.Close()
+	}
+}
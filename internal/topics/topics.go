@@ -0,0 +1,170 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package topics contains the topic template the adapters address the
+// channels with. The template defines where the channel ID and the subtopic
+// are placed in the topic, e.g. devices/{channel}/data/{subtopic}, and both
+// parsing and formatting of the topics is derived from it.
+package topics
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	// Channel is the placeholder of the channel ID.
+	Channel = "{channel}"
+
+	// Subtopic is the placeholder of the subtopic. It's appended to the end
+	// of the template if omitted.
+	Subtopic = "{subtopic}"
+
+	// DefaultTemplate is the channels/<channel_id>/messages/<subtopic>
+	// layout used unless configured otherwise.
+	DefaultTemplate = "channels/" + Channel + "/messages/" + Subtopic
+
+	channelPattern  = `([\w\-]+)`
+	subtopicPattern = `(/[^?]*)?`
+	queryPattern    = `(\?.*)?`
+)
+
+var (
+	// ErrInvalidTemplate indicates the template without exactly one channel
+	// placeholder, or with the misplaced subtopic placeholder.
+	ErrInvalidTemplate = errors.New("invalid topic template")
+
+	// ErrMalformedTopic indicates the topic not matching the template.
+	ErrMalformedTopic = errors.New("malformed topic")
+
+	// ErrMalformedSubtopic indicates the subtopic with invalid elements.
+	ErrMalformedSubtopic = errors.New("malformed subtopic")
+)
+
+// Template represents the parsed topic template.
+type Template struct {
+	raw    string
+	regexp *regexp.Regexp
+}
+
+// Default returns the default template.
+func Default() Template {
+	t, err := New(DefaultTemplate)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// New parses the template. The leading slash is optional, both in the
+// template and in the topics parsed by it.
+func New(template string) (Template, error) {
+	raw := strings.TrimPrefix(template, "/")
+	if strings.Count(raw, Channel) != 1 || strings.Count(raw, Subtopic) > 1 {
+		return Template{}, ErrInvalidTemplate
+	}
+	switch {
+	case !strings.Contains(raw, Subtopic):
+		raw = strings.TrimSuffix(raw, "/") + "/" + Subtopic
+	case !strings.Contains(raw, "/"+Subtopic):
+		return Template{}, ErrInvalidTemplate
+	}
+
+	parts := strings.SplitN(raw, "/"+Subtopic, 2)
+	chanParts := strings.SplitN(parts[0], Channel, 2)
+	if len(chanParts) != 2 {
+		// The channel placeholder follows the subtopic.
+		return Template{}, ErrInvalidTemplate
+	}
+	pattern := "^/?" + regexp.QuoteMeta(chanParts[0]) + channelPattern + regexp.QuoteMeta(chanParts[1]) +
+		subtopicPattern + regexp.QuoteMeta(parts[1]) + queryPattern + "$"
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Template{}, errors.Wrap(ErrInvalidTemplate, err)
+	}
+
+	return Template{
+		raw:    raw,
+		regexp: re,
+	}, nil
+}
+
+// String returns the template, including the subtopic placeholder.
+func (t Template) String() string {
+	return t.raw
+}
+
+// Match reports whether the topic matches the template.
+func (t Template) Match(topic string) bool {
+	return t.regexp.MatchString(topic)
+}
+
+// Parse returns the channel ID and the subtopic of the topic, stripping the
+// query string. The subtopic elements are unescaped and separated by dots.
+func (t Template) Parse(topic string) (string, string, error) {
+	chanID, subtopic, err := t.Split(topic)
+	if err != nil {
+		return "", "", err
+	}
+
+	subtopic, err = ParseSubtopic(subtopic)
+	if err != nil {
+		return "", "", err
+	}
+	return chanID, subtopic, nil
+}
+
+// Split returns the channel ID and the subtopic of the topic as they are,
+// i.e. the subtopic isn't parsed, and starts with the slash unless empty.
+func (t Template) Split(topic string) (string, string, error) {
+	parts := t.regexp.FindStringSubmatch(topic)
+	if len(parts) < 3 {
+		return "", "", ErrMalformedTopic
+	}
+	return parts[1], parts[2], nil
+}
+
+// Format returns the topic of the channel and the dot separated subtopic.
+func (t Template) Format(chanID, subtopic string) string {
+	st := ""
+	if subtopic != "" {
+		st = "/" + strings.ReplaceAll(subtopic, ".", "/")
+	}
+	topic := strings.Replace(t.raw, Channel, chanID, 1)
+	return strings.Replace(topic, "/"+Subtopic, st, 1)
+}
+
+// ParseSubtopic unescapes the slash or dot separated subtopic, and returns
+// its non-empty elements separated by dots. Wildcards are allowed only as
+// the whole elements.
+func ParseSubtopic(subtopic string) (string, error) {
+	if subtopic == "" {
+		return subtopic, nil
+	}
+
+	subtopic, err := url.QueryUnescape(subtopic)
+	if err != nil {
+		return "", ErrMalformedSubtopic
+	}
+	subtopic = strings.Replace(subtopic, "/", ".", -1)
+
+	elems := strings.Split(subtopic, ".")
+	filteredElems := []string{}
+	for _, elem := range elems {
+		if elem == "" {
+			continue
+		}
+
+		if len(elem) > 1 && (strings.Contains(elem, "*") || strings.Contains(elem, ">")) {
+			return "", ErrMalformedSubtopic
+		}
+
+		filteredElems = append(filteredElems, elem)
+	}
+
+	return strings.Join(filteredElems, "."), nil
+}
@@ -0,0 +1,234 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package topics_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/internal/topics"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const chanID = "123e4567-e89b-12d3-a456-000000000001"
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		desc     string
+		template string
+		raw      string
+		err      error
+	}{
+		{
+			desc:     "create default template",
+			template: topics.DefaultTemplate,
+			raw:      topics.DefaultTemplate,
+			err:      nil,
+		},
+		{
+			desc:     "create template with leading slash",
+			template: "/devices/{channel}/{subtopic}",
+			raw:      "devices/{channel}/{subtopic}",
+			err:      nil,
+		},
+		{
+			desc:     "create template without subtopic",
+			template: "devices/{channel}/data",
+			raw:      "devices/{channel}/data/{subtopic}",
+			err:      nil,
+		},
+		{
+			desc:     "create template without subtopic with trailing slash",
+			template: "devices/{channel}/data/",
+			raw:      "devices/{channel}/data/{subtopic}",
+			err:      nil,
+		},
+		{
+			desc:     "create template with subtopic in the middle",
+			template: "devices/{channel}/{subtopic}/data",
+			raw:      "devices/{channel}/{subtopic}/data",
+			err:      nil,
+		},
+		{
+			desc:     "create template without channel",
+			template: "devices/data/{subtopic}",
+			err:      topics.ErrInvalidTemplate,
+		},
+		{
+			desc:     "create template with two channels",
+			template: "{channel}/{channel}/{subtopic}",
+			err:      topics.ErrInvalidTemplate,
+		},
+		{
+			desc:     "create template with two subtopics",
+			template: "{channel}/{subtopic}/{subtopic}",
+			err:      topics.ErrInvalidTemplate,
+		},
+		{
+			desc:     "create template with subtopic not following slash",
+			template: "devices/{channel}/data{subtopic}",
+			err:      topics.ErrInvalidTemplate,
+		},
+		{
+			desc:     "create template with channel following subtopic",
+			template: "devices/{subtopic}/{channel}",
+			err:      topics.ErrInvalidTemplate,
+		},
+	}
+
+	for _, tc := range cases {
+		tmpl, err := topics.New(tc.template)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		if err == nil {
+			assert.Equal(t, tc.raw, tmpl.String(), fmt.Sprintf("%s: expected template %s got %s", tc.desc, tc.raw, tmpl.String()))
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	custom, err := topics.New("devices/{channel}/{subtopic}/data")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc     string
+		tmpl     topics.Template
+		topic    string
+		chanID   string
+		subtopic string
+		err      error
+	}{
+		{
+			desc:   "parse default topic",
+			tmpl:   topics.Default(),
+			topic:  fmt.Sprintf("channels/%s/messages", chanID),
+			chanID: chanID,
+			err:    nil,
+		},
+		{
+			desc:     "parse default topic with leading slash and subtopic",
+			tmpl:     topics.Default(),
+			topic:    fmt.Sprintf("/channels/%s/messages/a/b", chanID),
+			chanID:   chanID,
+			subtopic: "a.b",
+			err:      nil,
+		},
+		{
+			desc:     "parse default topic with query string",
+			tmpl:     topics.Default(),
+			topic:    fmt.Sprintf("channels/%s/messages/a?ct=json", chanID),
+			chanID:   chanID,
+			subtopic: "a",
+			err:      nil,
+		},
+		{
+			desc:     "parse default topic with escaped subtopic and wildcards",
+			tmpl:     topics.Default(),
+			topic:    fmt.Sprintf("channels/%s/messages/a%%2Fb/*/>", chanID),
+			chanID:   chanID,
+			subtopic: "a.b.*.>",
+			err:      nil,
+		},
+		{
+			desc:  "parse default topic with invalid wildcard",
+			tmpl:  topics.Default(),
+			topic: fmt.Sprintf("channels/%s/messages/a*", chanID),
+			err:   topics.ErrMalformedSubtopic,
+		},
+		{
+			desc:  "parse default topic with invalid escape",
+			tmpl:  topics.Default(),
+			topic: fmt.Sprintf("channels/%s/messages/%%zz", chanID),
+			err:   topics.ErrMalformedSubtopic,
+		},
+		{
+			desc:  "parse topic of other template",
+			tmpl:  topics.Default(),
+			topic: fmt.Sprintf("devices/%s/data", chanID),
+			err:   topics.ErrMalformedTopic,
+		},
+		{
+			desc:  "parse default topic with invalid channel",
+			tmpl:  topics.Default(),
+			topic: "channels/a.b/messages",
+			err:   topics.ErrMalformedTopic,
+		},
+		{
+			desc:   "parse custom topic",
+			tmpl:   custom,
+			topic:  fmt.Sprintf("devices/%s/data", chanID),
+			chanID: chanID,
+			err:    nil,
+		},
+		{
+			desc:     "parse custom topic with subtopic",
+			tmpl:     custom,
+			topic:    fmt.Sprintf("/devices/%s/a/b/data?ct=json", chanID),
+			chanID:   chanID,
+			subtopic: "a.b",
+			err:      nil,
+		},
+		{
+			desc:  "parse default topic using custom template",
+			tmpl:  custom,
+			topic: fmt.Sprintf("channels/%s/messages", chanID),
+			err:   topics.ErrMalformedTopic,
+		},
+	}
+
+	for _, tc := range cases {
+		id, subtopic, err := tc.tmpl.Parse(tc.topic)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.chanID, id, fmt.Sprintf("%s: expected channel %s got %s", tc.desc, tc.chanID, id))
+		assert.Equal(t, tc.subtopic, subtopic, fmt.Sprintf("%s: expected subtopic %s got %s", tc.desc, tc.subtopic, subtopic))
+		match := tc.err != topics.ErrMalformedTopic
+		assert.Equal(t, match, tc.tmpl.Match(tc.topic), fmt.Sprintf("%s: expected match %t got %t", tc.desc, match, !match))
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		desc     string
+		template string
+		subtopic string
+		topic    string
+	}{
+		{
+			desc:     "format default topic",
+			template: topics.DefaultTemplate,
+			topic:    fmt.Sprintf("channels/%s/messages", chanID),
+		},
+		{
+			desc:     "format default topic with subtopic",
+			template: topics.DefaultTemplate,
+			subtopic: "a.b",
+			topic:    fmt.Sprintf("channels/%s/messages/a/b", chanID),
+		},
+		{
+			desc:     "format custom topic with subtopic",
+			template: "/devices/{channel}/{subtopic}/data",
+			subtopic: "a.b",
+			topic:    fmt.Sprintf("devices/%s/a/b/data", chanID),
+		},
+		{
+			desc:     "format custom topic without subtopic",
+			template: "devices/{channel}/{subtopic}/data",
+			topic:    fmt.Sprintf("devices/%s/data", chanID),
+		},
+	}
+
+	for _, tc := range cases {
+		tmpl, err := topics.New(tc.template)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		topic := tmpl.Format(chanID, tc.subtopic)
+		assert.Equal(t, tc.topic, topic, fmt.Sprintf("%s: expected topic %s got %s", tc.desc, tc.topic, topic))
+
+		id, subtopic, err := tmpl.Parse(topic)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, chanID, id, fmt.Sprintf("%s: expected channel %s got %s", tc.desc, chanID, id))
+		assert.Equal(t, tc.subtopic, subtopic, fmt.Sprintf("%s: expected subtopic %s got %s", tc.desc, tc.subtopic, subtopic))
+	}
+}
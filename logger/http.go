@@ -0,0 +1,72 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package logger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const contentType = "application/json"
+
+type levelReq struct {
+	Level string `json:"level"`
+}
+
+type levelRes struct {
+	Level string `json:"level"`
+}
+
+type errorRes struct {
+	Err string `json:"error"`
+}
+
+// LevelHandler returns HTTP handler that reports the current log level on
+// GET and changes it on PUT with body {"level":"debug"}. If token is not
+// empty, requests must provide it in the Authorization header.
+func LevelHandler(l Leveler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+
+		if token != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(token)) != 1 {
+			encodeError(w, http.StatusUnauthorized, "missing or invalid credentials provided")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+				encodeError(w, http.StatusUnsupportedMediaType, "unsupported content type")
+				return
+			}
+
+			var req levelReq
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				encodeError(w, http.StatusBadRequest, "failed to decode request body")
+				return
+			}
+
+			var level Level
+			if err := level.UnmarshalText(req.Level); err != nil {
+				encodeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			l.SetLevel(level)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			encodeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		json.NewEncoder(w).Encode(levelRes{Level: l.Level().String()})
+	})
+}
+
+func encodeError(w http.ResponseWriter, status int, msg string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorRes{Err: msg})
+}
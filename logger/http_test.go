@@ -0,0 +1,218 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	levelToken  = "level-token"
+	contentType = "application/json"
+)
+
+type levelReq struct {
+	client      *http.Client
+	method      string
+	url         string
+	contentType string
+	token       string
+	body        string
+}
+
+func (lr levelReq) make() (*http.Response, error) {
+	req, err := http.NewRequest(lr.method, lr.url, strings.NewReader(lr.body))
+	if err != nil {
+		return nil, err
+	}
+	if lr.token != "" {
+		req.Header.Set("Authorization", lr.token)
+	}
+	if lr.contentType != "" {
+		req.Header.Set("Content-Type", lr.contentType)
+	}
+	return lr.client.Do(req)
+}
+
+// syncBuffer is safe for concurrent writes and reads.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
+
+func newLevelServer(t *testing.T, out *syncBuffer, token string) (log.Logger, *httptest.Server) {
+	l, err := log.New(out, log.Info.String())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	lvl, ok := l.(log.Leveler)
+	require.True(t, ok, "expected logger to implement Leveler")
+	return l, httptest.NewServer(log.LevelHandler(lvl, token))
+}
+
+func TestLevelHandlerChangesLevel(t *testing.T) {
+	var out syncBuffer
+	l, ts := newLevelServer(t, &out, "")
+	defer ts.Close()
+
+	l.Debug("before change")
+	assert.NotContains(t, out.String(), "before change", "expected debug line to not be logged before the change")
+
+	// Goroutine started before the change must observe the new level.
+	logged := make(chan struct{})
+	changed := make(chan struct{})
+	go func() {
+		<-changed
+		l.Debug("from goroutine")
+		close(logged)
+	}()
+
+	req := levelReq{
+		client:      ts.Client(),
+		method:      http.MethodPut,
+		url:         ts.URL,
+		contentType: contentType,
+		body:        `{"level":"debug"}`,
+	}
+	res, err := req.make()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("expected status %d got %d", http.StatusOK, res.StatusCode))
+	assert.Equal(t, `{"level":"debug"}`, strings.TrimSpace(string(body)), "expected changed level in response")
+
+	close(changed)
+	<-logged
+	l.Debug("after change")
+	assert.Contains(t, out.String(), `"message":"from goroutine"`, "expected debug line from goroutine after the change")
+	assert.Contains(t, out.String(), `"message":"after change"`, "expected debug line after the change")
+
+	req.body = `{"level":"error"}`
+	_, err = req.make()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	l.Info("after revert")
+	assert.NotContains(t, out.String(), "after revert", "expected info line to not be logged at error level")
+}
+
+func TestLevelHandler(t *testing.T) {
+	var out syncBuffer
+	_, ts := newLevelServer(t, &out, levelToken)
+	defer ts.Close()
+
+	cases := []struct {
+		desc        string
+		method      string
+		contentType string
+		token       string
+		body        string
+		status      int
+		res         string
+	}{
+		{
+			desc:   "view level",
+			method: http.MethodGet,
+			token:  levelToken,
+			status: http.StatusOK,
+			res:    `{"level":"info"}`,
+		},
+		{
+			desc:        "change level with invalid token",
+			method:      http.MethodPut,
+			contentType: contentType,
+			token:       "invalid",
+			body:        `{"level":"debug"}`,
+			status:      http.StatusUnauthorized,
+		},
+		{
+			desc:        "change level without token",
+			method:      http.MethodPut,
+			contentType: contentType,
+			body:        `{"level":"debug"}`,
+			status:      http.StatusUnauthorized,
+		},
+		{
+			desc:        "change level to invalid level",
+			method:      http.MethodPut,
+			contentType: contentType,
+			token:       levelToken,
+			body:        `{"level":"verbose"}`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "change level with malformed body",
+			method:      http.MethodPut,
+			contentType: contentType,
+			token:       levelToken,
+			body:        `{"level":`,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:   "change level without content type",
+			method: http.MethodPut,
+			token:  levelToken,
+			body:   `{"level":"debug"}`,
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:   "change level using unsupported method",
+			method: http.MethodPost,
+			token:  levelToken,
+			status: http.StatusMethodNotAllowed,
+		},
+		{
+			desc:        "change level",
+			method:      http.MethodPut,
+			contentType: contentType,
+			token:       levelToken,
+			body:        `{"level":"WARN"}`,
+			status:      http.StatusOK,
+			res:         `{"level":"warn"}`,
+		},
+		{
+			desc:   "view changed level",
+			method: http.MethodGet,
+			token:  levelToken,
+			status: http.StatusOK,
+			res:    `{"level":"warn"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		req := levelReq{
+			client:      ts.Client(),
+			method:      tc.method,
+			url:         ts.URL,
+			contentType: tc.contentType,
+			token:       tc.token,
+			body:        tc.body,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.res != "" {
+			assert.Equal(t, tc.res, strings.TrimSpace(string(body)), fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, body))
+		}
+	}
+}
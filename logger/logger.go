@@ -5,9 +5,11 @@ package logger
 
 import (
 	"fmt"
-	"github.com/go-kit/kit/log"
 	"io"
+	"sync/atomic"
 	"time"
+
+	"github.com/go-kit/kit/log"
 )
 
 // Logger specifies logging API.
@@ -22,11 +24,22 @@ type Logger interface {
 	Error(string)
 }
 
-var _ Logger = (*logger)(nil)
+// Leveler specifies API of the logger whose level can be changed at runtime.
+type Leveler interface {
+	// Level returns current log level.
+	Level() Level
+	// SetLevel changes log level. The change takes effect immediately.
+	SetLevel(Level)
+}
+
+var (
+	_ Logger  = (*logger)(nil)
+	_ Leveler = (*logger)(nil)
+)
 
 type logger struct {
 	kitLogger log.Logger
-	level     Level
+	level     *int32
 }
 
 // New returns wrapped go kit logger.
@@ -38,29 +51,38 @@ func New(out io.Writer, levelText string) (Logger, error) {
 	}
 	l := log.NewJSONLogger(log.NewSyncWriter(out))
 	l = log.With(l, "ts", log.DefaultTimestampUTC)
-	return &logger{l, level}, err
+	lvl := int32(level)
+	return &logger{l, &lvl}, err
+}
+
+func (l logger) Level() Level {
+	return Level(atomic.LoadInt32(l.level))
+}
+
+func (l logger) SetLevel(level Level) {
+	atomic.StoreInt32(l.level, int32(level))
 }
 
 func (l logger) Debug(msg string) {
-	if Debug.isAllowed(l.level) {
+	if Debug.isAllowed(l.Level()) {
 		l.kitLogger.Log("level", Debug.String(), "message", msg)
 	}
 }
 
 func (l logger) Info(msg string) {
-	if Info.isAllowed(l.level) {
+	if Info.isAllowed(l.Level()) {
 		l.kitLogger.Log("level", Info.String(), "message", msg)
 	}
 }
 
 func (l logger) Warn(msg string) {
-	if Warn.isAllowed(l.level) {
+	if Warn.isAllowed(l.Level()) {
 		l.kitLogger.Log("level", Warn.String(), "message", msg)
 	}
 }
 
 func (l logger) Error(msg string) {
-	if Error.isAllowed(l.level) {
+	if Error.isAllowed(l.Level()) {
 		l.kitLogger.Log("level", Error.String(), "message", msg)
 	}
 }
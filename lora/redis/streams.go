@@ -3,10 +3,11 @@ package redis
 import (
 	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/internal/clients/events"
+	eventsredis "github.com/mainflux/mainflux/internal/clients/events/redis"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/lora"
 )
@@ -31,113 +32,106 @@ const (
 	channelUpdate = channelPrefix + "update"
 	channelRemove = channelPrefix + "remove"
 
-	exists = "BUSYGROUP Consumer Group name already exists"
+	parking = group + ".parked"
 )
 
+// The events with malformed metadata are parked without retries.
 var (
-	errMetadataType = errors.New("field lora is missing in the metadata")
+	errMetadataType = apierrors.MalformedEntity("field lora is missing in the metadata")
 
-	errMetadataFormat = errors.New("malformed metadata")
+	errMetadataFormat = apierrors.MalformedEntity("malformed metadata")
 
-	errMetadataAppID = errors.New("application ID not found in channel metadatada")
+	errMetadataAppID = apierrors.MalformedEntity("application ID not found in channel metadatada")
 
-	errMetadataDevEUI = errors.New("device EUI not found in thing metadatada")
+	errMetadataDevEUI = apierrors.MalformedEntity("device EUI not found in thing metadatada")
 )
 
 // Subscriber represents event source for things and channels provisioning.
 type Subscriber interface {
 	// Subscribes to geven subject and receives events.
 	Subscribe(context.Context, string) error
+	// Replay rewinds the subscription to the event ID, so the events after
+	// it are received again.
+	Replay(context.Context, string) error
 }
 
 type eventStore struct {
 	svc      lora.Service
-	client   *redis.Client
-	consumer string
-	logger   logger.Logger
+	consumer events.Consumer
 }
 
-// NewEventStore returns new event store instance.
+// NewEventStore returns new event store instance. The events failed to be
+// handled are parked after the retries, and the last handled one is
+// checkpointed, so the subscription resumes from it.
 func NewEventStore(svc lora.Service, client *redis.Client, consumer string, log logger.Logger) Subscriber {
+	s := eventsredis.NewStream(client, stream, group, consumer, parking)
+	checkpoints := eventsredis.NewCheckpointRepository(client, stream, group)
 	return eventStore{
 		svc:      svc,
-		client:   client,
-		consumer: consumer,
-		logger:   log,
+		consumer: events.NewConsumer(s, checkpoints, events.Config{}, log),
 	}
 }
 
 func (es eventStore) Subscribe(ctx context.Context, subject string) error {
-	err := es.client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
-	if err != nil && err.Error() != exists {
-		return err
-	}
-
-	for {
-		streams, err := es.client.XReadGroup(ctx, &redis.XReadGroupArgs{
-			Group:    group,
-			Consumer: es.consumer,
-			Streams:  []string{stream, ">"},
-			Count:    100,
-		}).Result()
-		if err != nil || len(streams) == 0 {
-			continue
+	return es.consumer.Consume(ctx, es)
+}
+
+func (es eventStore) Replay(ctx context.Context, id string) error {
+	return es.consumer.Replay(ctx, id)
+}
+
+func (es eventStore) Handle(ctx context.Context, ev events.Event) error {
+	event := ev.Values
+
+	var err error
+	switch event["operation"] {
+	case thingCreate:
+		cte, derr := decodeCreateThing(event)
+		if derr != nil {
+			err = derr
+			break
+		}
+		err = es.svc.CreateThing(ctx, cte.id, cte.loraDevEUI)
+	case thingUpdate:
+		ute, derr := decodeCreateThing(event)
+		if derr != nil {
+			err = derr
+			break
 		}
+		err = es.svc.CreateThing(ctx, ute.id, ute.loraDevEUI)
 
-		for _, msg := range streams[0].Messages {
-			event := msg.Values
-
-			var err error
-			switch event["operation"] {
-			case thingCreate:
-				cte, derr := decodeCreateThing(event)
-				if derr != nil {
-					err = derr
-					break
-				}
-				err = es.svc.CreateThing(ctx, cte.id, cte.loraDevEUI)
-			case thingUpdate:
-				ute, derr := decodeCreateThing(event)
-				if derr != nil {
-					err = derr
-					break
-				}
-				err = es.svc.CreateThing(ctx, ute.id, ute.loraDevEUI)
-
-			case channelCreate:
-				cce, derr := decodeCreateChannel(event)
-				if derr != nil {
-					err = derr
-					break
-				}
-				err = es.svc.CreateChannel(ctx, cce.id, cce.loraAppID)
-			case channelUpdate:
-				uce, derr := decodeCreateChannel(event)
-				if derr != nil {
-					err = derr
-					break
-				}
-				err = es.svc.CreateChannel(ctx, uce.id, uce.loraAppID)
-			case thingRemove:
-				rte := decodeRemoveThing(event)
-				err = es.svc.RemoveThing(ctx, rte.id)
-			case channelRemove:
-				rce := decodeRemoveChannel(event)
-				err = es.svc.RemoveChannel(ctx, rce.id)
-			case thingConnect:
-				tce := decodeConnectionThing(event)
-				err = es.svc.ConnectThing(ctx, tce.chanID, tce.thingID)
-			case thingDisconnect:
-				tde := decodeConnectionThing(event)
-				err = es.svc.DisconnectThing(ctx, tde.chanID, tde.thingID)
-			}
-			if err != nil && err != errMetadataType {
-				es.logger.Warn(fmt.Sprintf("Failed to handle event sourcing: %s", err.Error()))
-				break
-			}
-			es.client.XAck(ctx, stream, group, msg.ID)
+	case channelCreate:
+		cce, derr := decodeCreateChannel(event)
+		if derr != nil {
+			err = derr
+			break
 		}
-	}
+		err = es.svc.CreateChannel(ctx, cce.id, cce.loraAppID)
+	case channelUpdate:
+		uce, derr := decodeCreateChannel(event)
+		if derr != nil {
+			err = derr
+			break
+		}
+		err = es.svc.CreateChannel(ctx, uce.id, uce.loraAppID)
+	case thingRemove:
+		rte := decodeRemoveThing(event)
+		err = es.svc.RemoveThing(ctx, rte.id)
+	case channelRemove:
+		rce := decodeRemoveChannel(event)
+		err = es.svc.RemoveChannel(ctx, rce.id)
+	case thingConnect:
+		tce := decodeConnectionThing(event)
+		err = es.svc.ConnectThing(ctx, tce.chanID, tce.thingID)
+	case thingDisconnect:
+		tde := decodeConnectionThing(event)
+		err = es.svc.DisconnectThing(ctx, tde.chanID, tde.thingID)
+	}
+	// The things and channels not provisioned for LoRa are skipped.
+	if err == errMetadataType {
+		return nil
+	}
+	return err
 }
 
 func decodeCreateThing(event map[string]interface{}) (createThingEvent, error) {
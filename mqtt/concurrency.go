@@ -0,0 +1,126 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mproxy/pkg/session"
+	"golang.org/x/sync/semaphore"
+)
+
+const (
+	hookConnect   = "connect"
+	hookPublish   = "publish"
+	hookSubscribe = "subscribe"
+)
+
+// ErrHookBusy indicates the request over the concurrency limit of its hook.
+// The request is expected to be retried.
+var ErrHookBusy = apierrors.Unavailable("too many concurrent requests, retry later")
+
+// ConcurrencyConfig contains the per-hook concurrency limits. The hooks are
+// limited separately, so the PUBLISH requests can't starve the CONNECT ones.
+// Zero limit disables the limit of the hook.
+type ConcurrencyConfig struct {
+	Connect   int64
+	Publish   int64
+	Subscribe int64
+
+	// Wait is the longest the request waits for the hook slot before it's
+	// rejected. Zero wait rejects the request at once.
+	Wait time.Duration
+}
+
+var _ session.Handler = (*concurrencyLimiter)(nil)
+
+type concurrencyLimiter struct {
+	session.Handler
+	connect   *semaphore.Weighted
+	publish   *semaphore.Weighted
+	subscribe *semaphore.Weighted
+	wait      time.Duration
+	waited    metrics.Histogram
+	rejected  metrics.Counter
+}
+
+// NewConcurrencyLimiter returns the handler bounding the number of the
+// CONNECT, PUBLISH and SUBSCRIBE authorizations in flight, each holding the
+// things service call, e.g. during the reconnect storms. The request over
+// the limit fails with ErrHookBusy. The time waited for the slot and the
+// rejections are recorded by the hook: "connect", "publish" or "subscribe".
+func NewConcurrencyLimiter(h session.Handler, cfg ConcurrencyConfig, waited metrics.Histogram, rejected metrics.Counter) session.Handler {
+	return &concurrencyLimiter{
+		Handler:   h,
+		connect:   newSemaphore(cfg.Connect),
+		publish:   newSemaphore(cfg.Publish),
+		subscribe: newSemaphore(cfg.Subscribe),
+		wait:      cfg.Wait,
+		waited:    waited,
+		rejected:  rejected,
+	}
+}
+
+func (cl *concurrencyLimiter) AuthConnect(c *session.Client) error {
+	release, err := cl.acquire(hookConnect, cl.connect)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return cl.Handler.AuthConnect(c)
+}
+
+func (cl *concurrencyLimiter) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	release, err := cl.acquire(hookPublish, cl.publish)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return cl.Handler.AuthPublish(c, topic, payload)
+}
+
+func (cl *concurrencyLimiter) AuthSubscribe(c *session.Client, topics *[]string) error {
+	release, err := cl.acquire(hookSubscribe, cl.subscribe)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return cl.Handler.AuthSubscribe(c, topics)
+}
+
+// acquire takes the slot of the hook, waiting for it at most for the
+// configured wait, and returns the function releasing it.
+func (cl *concurrencyLimiter) acquire(hook string, sem *semaphore.Weighted) (func(), error) {
+	if sem == nil {
+		return func() {}, nil
+	}
+
+	start := time.Now()
+	ok := sem.TryAcquire(1)
+	if !ok && cl.wait > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), cl.wait)
+		ok = sem.Acquire(ctx, 1) == nil
+		cancel()
+	}
+	cl.waited.With("hook", hook).Observe(time.Since(start).Seconds())
+
+	if !ok {
+		cl.rejected.With("hook", hook).Add(1)
+		return nil, ErrHookBusy
+	}
+	return func() { sem.Release(1) }, nil
+}
+
+func newSemaphore(limit int64) *semaphore.Weighted {
+	if limit <= 0 {
+		return nil
+	}
+	return semaphore.NewWeighted(limit)
+}
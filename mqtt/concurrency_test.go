@@ -0,0 +1,127 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/mqtt"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const publishLimit = 4
+
+// slowHandler blocks the PUBLISH authorizations until it's released, like
+// the things service call stuck during the reconnect storm.
+type slowHandler struct {
+	session.Handler
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h *slowHandler) AuthConnect(c *session.Client) error {
+	return nil
+}
+
+func (h *slowHandler) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	h.started <- struct{}{}
+	<-h.release
+	return nil
+}
+
+func (h *slowHandler) AuthSubscribe(c *session.Client, topics *[]string) error {
+	return nil
+}
+
+// histogram counts the observations per hook.
+type histogram struct {
+	mu     *sync.Mutex
+	hook   string
+	values map[string]int
+}
+
+func (h *histogram) With(lvs ...string) metrics.Histogram {
+	return &histogram{mu: h.mu, hook: lvs[1], values: h.values}
+}
+
+func (h *histogram) Observe(float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.values[h.hook]++
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	h := &slowHandler{started: make(chan struct{}, publishLimit), release: make(chan struct{})}
+	waited := &histogram{mu: &sync.Mutex{}, values: map[string]int{}}
+	rejected := &counter{values: map[string]float64{}}
+	cfg := mqtt.ConcurrencyConfig{
+		Connect: 1,
+		Publish: publishLimit,
+		Wait:    50 * time.Millisecond,
+	}
+	cl := mqtt.NewConcurrencyLimiter(h, cfg, waited, rejected)
+
+	client := &session.Client{ID: "client", Username: "thing"}
+	topic := "channels/1/messages"
+	payload := []byte("payload")
+
+	// Saturate the publish limit.
+	var wg sync.WaitGroup
+	for i := 0; i < publishLimit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := cl.AuthPublish(client, &topic, &payload)
+			assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		}()
+	}
+	for i := 0; i < publishLimit; i++ {
+		<-h.started
+	}
+
+	cases := []struct {
+		desc string
+		auth func() error
+		err  error
+	}{
+		{
+			desc: "publish over saturated publish limit",
+			auth: func() error { return cl.AuthPublish(client, &topic, &payload) },
+			err:  mqtt.ErrHookBusy,
+		},
+		{
+			desc: "connect with saturated publish limit",
+			auth: func() error { return cl.AuthConnect(client) },
+			err:  nil,
+		},
+		{
+			desc: "subscribe without limit with saturated publish limit",
+			auth: func() error { return cl.AuthSubscribe(client, &[]string{topic}) },
+			err:  nil,
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.auth()
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+
+	close(h.release)
+	wg.Wait()
+
+	err := cl.AuthPublish(client, &topic, &payload)
+	require.Nil(t, err, fmt.Sprintf("publish after release: unexpected error: %s", err))
+
+	assert.Equal(t, float64(1), rejected.values["publish"], fmt.Sprintf("expected 1 publish rejection got %v", rejected.values["publish"]))
+	assert.Equal(t, float64(0), rejected.values["connect"], fmt.Sprintf("expected no connect rejections got %v", rejected.values["connect"]))
+	assert.Equal(t, publishLimit+2, waited.values["publish"], fmt.Sprintf("expected %d publish wait observations got %d", publishLimit+2, waited.values["publish"]))
+	assert.Equal(t, 0, waited.values["subscribe"], fmt.Sprintf("expected no subscribe wait observations got %d", waited.values["subscribe"]))
+}
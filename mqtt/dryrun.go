@@ -0,0 +1,90 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"fmt"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mproxy/pkg/session"
+)
+
+const (
+	decisionAllow = "allow"
+	decisionDeny  = "deny"
+)
+
+// DryRunConfig contains the dry run settings, used to run the adapter in
+// the shadow mode next to the existing MQTT path, e.g. while migrating the
+// brokers, without duplicating the messages.
+type DryRunConfig struct {
+	// Enabled puts all the clients in the dry run.
+	Enabled bool
+
+	// Clients contains the IDs of the clients put in the dry run if it
+	// isn't enabled for all, for the selective testing.
+	Clients []string
+}
+
+// ErrDryRun indicates the authorized PUBLISH request of the client in the
+// dry run, which is rejected so that it's neither published to Mainflux nor
+// forwarded to the MQTT broker.
+var ErrDryRun = apierrors.InvalidRequest("publish rejected in dry run")
+
+var _ session.Handler = (*dryRunHandler)(nil)
+
+type dryRunHandler struct {
+	session.Handler
+	enabled   bool
+	clients   map[string]bool
+	decisions metrics.Counter
+	logger    logger.Logger
+}
+
+// NewDryRunHandler returns the handler counting the PUBLISH authorization
+// decisions by the decision: "allow" or "deny". The messages of the clients
+// in the dry run are authorized as usual and the decision is logged, but
+// the authorized ones are rejected with ErrDryRun, so mProxy doesn't forward
+// them to the broker and they aren't published to Mainflux.
+func NewDryRunHandler(h session.Handler, cfg DryRunConfig, decisions metrics.Counter, logger logger.Logger) session.Handler {
+	clients := make(map[string]bool, len(cfg.Clients))
+	for _, id := range cfg.Clients {
+		clients[id] = true
+	}
+
+	return &dryRunHandler{
+		Handler:   h,
+		enabled:   cfg.Enabled,
+		clients:   clients,
+		decisions: decisions,
+		logger:    logger,
+	}
+}
+
+func (dh *dryRunHandler) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	err := dh.Handler.AuthPublish(c, topic, payload)
+
+	decision := decisionAllow
+	if err != nil {
+		decision = decisionDeny
+	}
+	dh.decisions.With("decision", decision).Add(1)
+
+	if !dh.dryRun(c) {
+		return err
+	}
+	if topic != nil {
+		dh.logger.Info(fmt.Sprintf("Dry run - %s publish of client ID %s to the topic: %s", decision, c.ID, *topic))
+	}
+	if err != nil {
+		return err
+	}
+	return ErrDryRun
+}
+
+func (dh *dryRunHandler) dryRun(c *session.Client) bool {
+	return c != nil && (dh.enabled || dh.clients[c.ID])
+}
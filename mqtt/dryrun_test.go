@@ -0,0 +1,85 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/mqtt"
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// forwardingHandler counts the messages published to Mainflux once the
+// PUBLISH request of the valid client is authorized.
+type forwardingHandler struct {
+	publishHandler
+	published map[string]int
+}
+
+func (h *forwardingHandler) Publish(c *session.Client, topic *string, payload *[]byte) {
+	h.published[c.ID]++
+}
+
+func TestDryRunHandler(t *testing.T) {
+	log, err := logger.New(ioutil.Discard, "info")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	clients := []*session.Client{
+		{ID: "shadow", Password: []byte("valid")},
+		{ID: "client", Password: []byte("valid")},
+		{ID: "denied", Password: []byte("invalid")},
+	}
+	topic := "channels/1/messages"
+	payload := []byte("payload")
+
+	cases := []struct {
+		desc      string
+		cfg       mqtt.DryRunConfig
+		errs      []error
+		published map[string]int
+	}{
+		{
+			desc:      "publish without dry run",
+			cfg:       mqtt.DryRunConfig{},
+			errs:      []error{nil, nil, errUnauthorized},
+			published: map[string]int{"shadow": 1, "client": 1},
+		},
+		{
+			desc:      "publish in dry run",
+			cfg:       mqtt.DryRunConfig{Enabled: true},
+			errs:      []error{mqtt.ErrDryRun, mqtt.ErrDryRun, errUnauthorized},
+			published: map[string]int{},
+		},
+		{
+			desc:      "publish in dry run of client",
+			cfg:       mqtt.DryRunConfig{Clients: []string{"shadow"}},
+			errs:      []error{mqtt.ErrDryRun, nil, errUnauthorized},
+			published: map[string]int{"client": 1},
+		},
+	}
+
+	for _, tc := range cases {
+		h := &forwardingHandler{published: map[string]int{}}
+		decisions := &counter{values: map[string]float64{}}
+		dh := mqtt.NewDryRunHandler(h, tc.cfg, decisions, log)
+
+		var errs []error
+		for _, c := range clients {
+			err := dh.AuthPublish(c, &topic, &payload)
+			if err == nil {
+				dh.Publish(c, &topic, &payload)
+			}
+			errs = append(errs, err)
+		}
+
+		assert.Equal(t, tc.errs, errs, fmt.Sprintf("%s: expected errors %v got %v", tc.desc, tc.errs, errs))
+		assert.Equal(t, map[string]float64{"allow": 2, "deny": 1}, decisions.values, fmt.Sprintf("%s: expected 2 allowed and 1 denied got %v", tc.desc, decisions.values))
+		assert.Equal(t, tc.published, h.published, fmt.Sprintf("%s: expected published %v got %v", tc.desc, tc.published, h.published))
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	goerrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/mqtt"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestErrorCode(t *testing.T) {
+	cases := []struct {
+		desc string
+		err  error
+		code codes.Code
+	}{
+		{desc: "hook busy", err: mqtt.ErrHookBusy, code: codes.Unavailable},
+		{desc: "connect flood", err: mqtt.ErrConnectFlood, code: codes.Unavailable},
+		{desc: "dry run", err: mqtt.ErrDryRun, code: codes.InvalidArgument},
+		{desc: "wrapped connect flood", err: errors.Wrap(mqtt.ErrConnectFlood, errors.New("retry after 1s")), code: codes.Unavailable},
+	}
+
+	for _, tc := range cases {
+		code := apierrors.GRPCCode(tc.err)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected code %s got %s\n", tc.desc, tc.code, code))
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	err := errors.Wrap(mqtt.ErrConnectFlood, errors.New("retry after 1s"))
+	assert.True(t, goerrors.Is(err, mqtt.ErrConnectFlood), "wrapped connect flood: expected errors.Is to match the sentinel")
+	assert.True(t, errors.Contains(err, mqtt.ErrConnectFlood), "wrapped connect flood: expected errors.Contains to match the sentinel")
+}
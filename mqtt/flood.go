@@ -0,0 +1,128 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mproxy/pkg/session"
+)
+
+const (
+	reasonFlood        = "flood"
+	reasonUnauthorized = "unauthorized"
+)
+
+// ErrConnectFlood indicates the client ID exceeding the CONNECT threshold.
+var ErrConnectFlood = apierrors.Unavailable("too many CONNECT requests for client ID")
+
+// FloodConfig contains the CONNECT flood protection settings.
+type FloodConfig struct {
+	// Threshold is the number of CONNECT requests allowed per client ID
+	// within the window. Zero threshold disables the protection.
+	Threshold int
+
+	// Window is the duration of the sliding window.
+	Window time.Duration
+
+	// Exempt contains the client IDs of the known gateways, which are never
+	// limited.
+	Exempt []string
+}
+
+var _ session.Handler = (*floodLimiter)(nil)
+
+type floodLimiter struct {
+	session.Handler
+	threshold int
+	window    time.Duration
+	exempt    map[string]bool
+	rejected  metrics.Counter
+	now       func() time.Time
+
+	mu        sync.Mutex
+	attempts  map[string][]time.Time
+	lastSweep time.Time
+}
+
+// NewFloodLimiter returns the handler rejecting the CONNECT requests of the
+// client ID once it exceeds the threshold within the sliding window, before
+// they reach the things service. The rejection error contains the time
+// after which the client is allowed to connect again. Rejections are
+// counted by the reason: "flood" for the limited requests, and
+// "unauthorized" for the ones rejected by the wrapped handler.
+func NewFloodLimiter(h session.Handler, cfg FloodConfig, rejected metrics.Counter, now func() time.Time) session.Handler {
+	exempt := make(map[string]bool, len(cfg.Exempt))
+	for _, id := range cfg.Exempt {
+		exempt[id] = true
+	}
+
+	return &floodLimiter{
+		Handler:   h,
+		threshold: cfg.Threshold,
+		window:    cfg.Window,
+		exempt:    exempt,
+		rejected:  rejected,
+		now:       now,
+		attempts:  make(map[string][]time.Time),
+		lastSweep: now(),
+	}
+}
+
+func (fl *floodLimiter) AuthConnect(c *session.Client) error {
+	if c != nil && fl.threshold > 0 && !fl.exempt[c.ID] {
+		if wait := fl.allow(c.ID); wait > 0 {
+			fl.rejected.With("reason", reasonFlood).Add(1)
+			return errors.Wrap(ErrConnectFlood, errors.New(fmt.Sprintf("retry after %s", wait)))
+		}
+	}
+
+	if err := fl.Handler.AuthConnect(c); err != nil {
+		fl.rejected.With("reason", reasonUnauthorized).Add(1)
+		return err
+	}
+	return nil
+}
+
+// allow records the CONNECT request of the client ID if it's under the
+// threshold, or returns the duration until it is.
+func (fl *floodLimiter) allow(id string) time.Duration {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+
+	now := fl.now()
+	start := now.Add(-fl.window)
+	if fl.lastSweep.Before(start) {
+		fl.sweep(start)
+		fl.lastSweep = now
+	}
+
+	attempts := fl.attempts[id]
+	i := 0
+	for i < len(attempts) && !attempts[i].After(start) {
+		i++
+	}
+	attempts = attempts[i:]
+
+	if len(attempts) >= fl.threshold {
+		fl.attempts[id] = attempts
+		return attempts[0].Sub(start)
+	}
+	fl.attempts[id] = append(attempts, now)
+	return 0
+}
+
+// sweep removes the client IDs without the requests within the window.
+func (fl *floodLimiter) sweep(start time.Time) {
+	for id, attempts := range fl.attempts {
+		if !attempts[len(attempts)-1].After(start) {
+			delete(fl.attempts, id)
+		}
+	}
+}
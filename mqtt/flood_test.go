@@ -0,0 +1,135 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/mqtt"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	threshold = 3
+	window    = time.Minute
+	gateway   = "gateway"
+)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// handler counts the CONNECT requests reaching the things service,
+// rejecting the ones with the invalid password.
+type handler struct {
+	session.Handler
+	connects int
+}
+
+func (h *handler) AuthConnect(c *session.Client) error {
+	h.connects++
+	if string(c.Password) == "invalid" {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// counter counts the additions per reason.
+type counter struct {
+	reason string
+	values map[string]float64
+}
+
+func (c *counter) With(lvs ...string) metrics.Counter {
+	return &counter{reason: lvs[1], values: c.values}
+}
+
+func (c *counter) Add(delta float64) {
+	c.values[c.reason] += delta
+}
+
+// clock is the fake clock advanced by the tests.
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestFloodLimiter(t *testing.T) {
+	h := &handler{}
+	rejected := &counter{values: map[string]float64{}}
+	clk := &clock{now: time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)}
+	cfg := mqtt.FloodConfig{
+		Threshold: threshold,
+		Window:    window,
+		Exempt:    []string{gateway},
+	}
+	fl := mqtt.NewFloodLimiter(h, cfg, rejected, clk.Now)
+
+	cases := []struct {
+		desc     string
+		client   string
+		password string
+		elapsed  time.Duration
+		count    int
+		err      error
+		retry    string
+	}{
+		{
+			desc:    "flood with client ID",
+			client:  "broken",
+			elapsed: time.Second,
+			count:   10,
+			err:     mqtt.ErrConnectFlood,
+			retry:   "retry after 51s",
+		},
+		{
+			desc:    "connect with other client ID during flood",
+			client:  "legitimate",
+			elapsed: 10 * time.Second,
+			count:   threshold,
+		},
+		{
+			desc:    "flood with exempt client ID",
+			client:  gateway,
+			elapsed: time.Millisecond,
+			count:   100,
+		},
+		{
+			desc:     "connect with invalid password under the threshold",
+			client:   "invalid",
+			password: "invalid",
+			count:    threshold,
+			err:      errUnauthorized,
+		},
+		{
+			desc:    "connect with flooding client ID after the window",
+			client:  "broken",
+			elapsed: window,
+			count:   1,
+		},
+	}
+
+	for _, tc := range cases {
+		var err error
+		for i := 0; i < tc.count; i++ {
+			clk.now = clk.now.Add(tc.elapsed)
+			err = fl.AuthConnect(&session.Client{ID: tc.client, Password: []byte(tc.password)})
+		}
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		if tc.retry != "" {
+			assert.Contains(t, err.Error(), tc.retry, fmt.Sprintf("%s: expected retry hint %q in %s", tc.desc, tc.retry, err))
+		}
+	}
+
+	connects := threshold + threshold + 100 + threshold + 1
+	assert.Equal(t, connects, h.connects, fmt.Sprintf("expected %d CONNECT requests to reach things got %d", connects, h.connects))
+	expected := map[string]float64{"flood": 10 - threshold, "unauthorized": threshold}
+	assert.Equal(t, expected, rejected.values, fmt.Sprintf("expected rejections %v got %v", expected, rejected.values))
+}
@@ -5,17 +5,12 @@ package mqtt
 
 import (
 	"fmt"
-	"strings"
 
+	"github.com/mainflux/mainflux/internal/topics"
 	log "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/pkg/messaging"
 )
 
-const (
-	channels = "channels"
-	messages = "messages"
-)
-
 // Forwarder specifies MQTT forwarder interface API.
 type Forwarder interface {
 	// Forward subscribes to the Subscriber and
@@ -25,13 +20,16 @@ type Forwarder interface {
 
 type forwarder struct {
 	topic  string
+	topics topics.Template
 	logger log.Logger
 }
 
-// NewForwarder returns new Forwarder implementation.
-func NewForwarder(topic string, logger log.Logger) Forwarder {
+// NewForwarder returns new Forwarder implementation. The messages are
+// forwarded to the MQTT topics formatted by the topic template.
+func NewForwarder(topic string, tmpl topics.Template, logger log.Logger) Forwarder {
 	return forwarder{
 		topic:  topic,
+		topics: tmpl,
 		logger: logger,
 	}
 }
@@ -45,12 +43,7 @@ func (f forwarder) handle(pub messaging.Publisher) messaging.MessageHandler {
 		if msg.Protocol == protocol {
 			return nil
 		}
-		// Use concatenation instead of mft.Sprintf for the
-		// sake of simplicity and performance.
-		topic := channels + "/" + msg.Channel + "/" + messages
-		if msg.Subtopic != "" {
-			topic += "/" + strings.ReplaceAll(msg.Subtopic, ".", "/")
-		}
+		topic := f.topics.Format(msg.Channel, msg.Subtopic)
 		go func() {
 			if err := pub.Publish(topic, msg); err != nil {
 				f.logger.Warn(fmt.Sprintf("Failed to forward message: %s", err))
@@ -5,16 +5,18 @@ package mqtt
 
 import (
 	"context"
-	"errors"
-	"net/url"
-	"regexp"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/internal/topics"
 	"github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/mqtt/redis"
 	"github.com/mainflux/mainflux/pkg/auth"
 	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/things/authz"
 	"github.com/mainflux/mproxy/pkg/session"
 )
 
@@ -23,15 +25,11 @@ var _ session.Handler = (*handler)(nil)
 const protocol = "mqtt"
 
 var (
-	channelRegExp         = regexp.MustCompile(`^\/?channels\/([\w\-]+)\/messages(\/[^?]*)?(\?.*)?$`)
-	errMalformedTopic     = errors.New("malformed topic")
-	errMalformedData      = errors.New("malformed request data")
-	errMalformedSubtopic  = errors.New("malformed subtopic")
-	errUnauthorizedAccess = errors.New("missing or invalid credentials provided")
-	errNilClient          = errors.New("using nil client")
-	errInvalidConnect     = errors.New("CONNECT request with invalid username or client ID")
-	errNilTopicPub        = errors.New("PUBLISH to nil topic")
-	errNilTopicSub        = errors.New("SUB to nil topic")
+	errUnauthorizedAccess = apierrors.Authentication("missing or invalid credentials provided")
+	errNilClient          = apierrors.MalformedEntity("using nil client")
+	errInvalidConnect     = apierrors.MalformedEntity("CONNECT request with invalid username or client ID")
+	errNilTopicPub        = apierrors.MalformedEntity("PUBLISH to nil topic")
+	errNilTopicSub        = apierrors.MalformedEntity("SUB to nil topic")
 )
 
 // Event implements events.Event interface
@@ -40,16 +38,26 @@ type handler struct {
 	auth       auth.Client
 	logger     logger.Logger
 	es         redis.EventStore
+	topics     topics.Template
+
+	// requests holds the ID of the request authorized for the session, so
+	// the notification following the authorization logs and publishes
+	// the message under the same ID.
+	mu       sync.Mutex
+	requests map[*session.Client]string
 }
 
-// NewHandler creates new Handler entity
+// NewHandler creates new Handler entity handling the topics set by the
+// topic template.
 func NewHandler(publishers []messaging.Publisher, es redis.EventStore,
-	logger logger.Logger, auth auth.Client) session.Handler {
+	logger logger.Logger, auth auth.Client, tmpl topics.Template) session.Handler {
 	return &handler{
 		es:         es,
 		logger:     logger,
 		publishers: publishers,
 		auth:       auth,
+		topics:     tmpl,
+		requests:   make(map[*session.Client]string),
 	}
 }
 
@@ -60,17 +68,21 @@ func (h *handler) AuthConnect(c *session.Client) error {
 		return errInvalidConnect
 	}
 
-	thid, err := h.auth.Identify(context.Background(), string(c.Password))
+	ctx := h.begin(c, reqctx.Subject{})
+	thid, err := h.auth.Identify(ctx, string(c.Password))
 	if err != nil {
+		h.logger.Warn("Failed to identify client with ID " + c.ID + ": " + err.Error() + reqctx.Describe(ctx))
 		return err
 	}
+	reqctx.SetSubject(ctx, reqctx.Thing(thid))
 
 	if thid != c.Username {
+		h.logger.Warn("Client with ID " + c.ID + " connected with invalid username" + reqctx.Describe(ctx))
 		return errUnauthorizedAccess
 	}
 
 	if err := h.es.Connect(c.Username); err != nil {
-		h.logger.Warn("Failed to publish connect event: " + err.Error())
+		h.logger.Warn("Failed to publish connect event: " + err.Error() + reqctx.Describe(ctx))
 	}
 
 	return nil
@@ -86,7 +98,8 @@ func (h *handler) AuthPublish(c *session.Client, topic *string, payload *[]byte)
 		return errNilTopicPub
 	}
 
-	return h.authAccess(c.Username, *topic)
+	ctx := h.begin(c, reqctx.Thing(c.Username))
+	return h.authAccess(ctx, c.Username, *topic, authz.Publish)
 }
 
 // AuthSubscribe is called on device publish,
@@ -99,8 +112,9 @@ func (h *handler) AuthSubscribe(c *session.Client, topics *[]string) error {
 		return errNilTopicSub
 	}
 
+	ctx := h.begin(c, reqctx.Thing(c.Username))
 	for _, v := range *topics {
-		if err := h.authAccess(c.Username, v); err != nil {
+		if err := h.authAccess(ctx, c.Username, v, authz.Subscribe); err != nil {
 			return err
 		}
 
@@ -115,7 +129,8 @@ func (h *handler) Connect(c *session.Client) {
 		h.logger.Error("Nil client connect")
 		return
 	}
-	h.logger.Info("Connect - client with ID: " + c.ID)
+	ctx := h.resume(c)
+	h.logger.Info("Connect - client with ID: " + c.ID + reqctx.Describe(ctx))
 }
 
 // Publish - after client successfully published
@@ -124,22 +139,12 @@ func (h *handler) Publish(c *session.Client, topic *string, payload *[]byte) {
 		h.logger.Error("Nil client publish")
 		return
 	}
-	h.logger.Info("Publish - client ID " + c.ID + " to the topic: " + *topic)
-	// Topics are in the format:
-	// channels/<channel_id>/messages/<subtopic>/.../ct/<content_type>
-
-	channelParts := channelRegExp.FindStringSubmatch(*topic)
-	if len(channelParts) < 1 {
-		h.logger.Info("Error in mqtt publish %s" + errMalformedData.Error())
-		return
-	}
-
-	chanID := channelParts[1]
-	subtopic := channelParts[2]
+	ctx := h.resume(c)
+	h.logger.Info("Publish - client ID " + c.ID + " to the topic: " + *topic + reqctx.Describe(ctx))
 
-	subtopic, err := parseSubtopic(subtopic)
+	chanID, subtopic, err := h.topics.Parse(*topic)
 	if err != nil {
-		h.logger.Info("Error parsing subtopic: " + err.Error())
+		h.logger.Info("Error in mqtt publish: " + err.Error() + reqctx.Describe(ctx))
 		return
 	}
 
@@ -151,10 +156,13 @@ func (h *handler) Publish(c *session.Client, topic *string, payload *[]byte) {
 		Payload:   *payload,
 		Created:   time.Now().UnixNano(),
 	}
+	if id := reqctx.RequestID(ctx); id != "" {
+		msg.Headers = map[string]string{messaging.RequestIDHeader: id}
+	}
 
 	for _, pub := range h.publishers {
 		if err := pub.Publish(msg.Channel, msg); err != nil {
-			h.logger.Info("Error publishing to Mainflux " + err.Error())
+			h.logger.Info("Error publishing to Mainflux " + err.Error() + reqctx.Describe(ctx))
 		}
 	}
 }
@@ -165,7 +173,8 @@ func (h *handler) Subscribe(c *session.Client, topics *[]string) {
 		h.logger.Error("Nil client subscribe")
 		return
 	}
-	h.logger.Info("Subscribe - client ID: " + c.ID + ", to topics: " + strings.Join(*topics, ","))
+	ctx := h.resume(c)
+	h.logger.Info("Subscribe - client ID: " + c.ID + ", to topics: " + strings.Join(*topics, ",") + reqctx.Describe(ctx))
 }
 
 // Unsubscribe - after client unsubscribed
@@ -183,54 +192,63 @@ func (h *handler) Disconnect(c *session.Client) {
 		h.logger.Error("Nil client disconnect")
 		return
 	}
+	h.mu.Lock()
+	delete(h.requests, c)
+	h.mu.Unlock()
+
 	h.logger.Info("Disconnect - Client with ID: " + c.ID + " and username " + c.Username + " disconnected")
 	if err := h.es.Disconnect(c.Username); err != nil {
 		h.logger.Warn("Failed to publish disconnect event: " + err.Error())
 	}
 }
 
-func (h *handler) authAccess(username string, topic string) error {
-	// Topics are in the format:
-	// channels/<channel_id>/messages/<subtopic>/.../ct/<content_type>
-	if !channelRegExp.Match([]byte(topic)) {
-		h.logger.Info("Malformed topic: " + topic)
-		return errMalformedTopic
-	}
+// begin assigns new request ID to the packet being authorized and returns
+// the context carrying it. The session authorizes and forwards the packets
+// of a client one at a time, so the ID is picked up by the notification
+// following the authorization.
+func (h *handler) begin(c *session.Client, s reqctx.Subject) context.Context {
+	id := reqctx.NewRequestID()
 
-	channelParts := channelRegExp.FindStringSubmatch(topic)
-	if len(channelParts) < 1 {
-		return errMalformedData
-	}
+	h.mu.Lock()
+	h.requests[c] = id
+	h.mu.Unlock()
 
-	chanID := channelParts[1]
-	return h.auth.Authorize(context.Background(), chanID, username)
+	return reqctx.With(context.Background(), reqctx.Info{RequestID: id, Subject: s})
 }
 
-func parseSubtopic(subtopic string) (string, error) {
-	if subtopic == "" {
-		return subtopic, nil
+// resume returns the context carrying the ID of the request authorized
+// for the client. New ID is assigned if the packet wasn't authorized by
+// this handler.
+func (h *handler) resume(c *session.Client) context.Context {
+	h.mu.Lock()
+	id, ok := h.requests[c]
+	delete(h.requests, c)
+	h.mu.Unlock()
+
+	if !ok {
+		id = reqctx.NewRequestID()
 	}
+	return reqctx.With(context.Background(), reqctx.Info{RequestID: id, Subject: reqctx.Thing(c.Username)})
+}
 
-	subtopic, err := url.QueryUnescape(subtopic)
+func (h *handler) authAccess(ctx context.Context, username, topic, action string) error {
+	chanID, st, err := h.topics.Split(topic)
 	if err != nil {
-		return "", errMalformedSubtopic
+		h.logger.Info("Malformed topic: " + topic + reqctx.Describe(ctx))
+		return err
 	}
-	subtopic = strings.Replace(subtopic, "/", ".", -1)
-
-	elems := strings.Split(subtopic, ".")
-	filteredElems := []string{}
-	for _, elem := range elems {
-		if elem == "" {
-			continue
-		}
-
-		if len(elem) > 1 && (strings.Contains(elem, "*") || strings.Contains(elem, ">")) {
-			return "", errMalformedSubtopic
-		}
 
-		filteredElems = append(filteredElems, elem)
+	// Messages with malformed subtopic are dropped on publish, so the
+	// subtopic is passed to the authorizer as is.
+	subtopic, err := topics.ParseSubtopic(st)
+	if err != nil {
+		subtopic = st
 	}
 
-	subtopic = strings.Join(filteredElems, ".")
-	return subtopic, nil
+	ctx = authz.WithAccess(ctx, authz.Access{Action: action, Subtopic: subtopic})
+	if err := h.auth.Authorize(ctx, chanID, username); err != nil {
+		h.logger.Warn("Failed to authorize client on topic " + topic + ": " + err.Error() + reqctx.Describe(ctx))
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,85 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/mainflux/mainflux/internal/clients/events"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/internal/topics"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/mqtt"
+	"github.com/mainflux/mainflux/mqtt/redis"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authClient records the request info of the authorizations.
+type authClient struct {
+	requests []reqctx.Info
+}
+
+func (a *authClient) Authorize(ctx context.Context, chanID, thingID string) error {
+	info, _ := reqctx.FromContext(ctx)
+	a.requests = append(a.requests, info)
+	return nil
+}
+
+func (a *authClient) Identify(ctx context.Context, thingKey string) (string, error) {
+	return "", errUnauthorized
+}
+
+func (a *authClient) Handle(ctx context.Context, event events.Event) error {
+	return nil
+}
+
+type publisher struct {
+	msgs []messaging.Message
+}
+
+func (p *publisher) Publish(topic string, msg messaging.Message) error {
+	p.msgs = append(p.msgs, msg)
+	return nil
+}
+
+func TestHandlerRequestID(t *testing.T) {
+	log, err := logger.New(ioutil.Discard, "info")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	auth := &authClient{}
+	pub := &publisher{}
+	h := mqtt.NewHandler([]messaging.Publisher{pub}, redis.EventStore{}, log, auth, topics.Default())
+
+	clients := []*session.Client{
+		{ID: "client1", Username: "thing1"},
+		{ID: "client2", Username: "thing2"},
+	}
+	topic := "channels/1/messages"
+	payload := []byte("payload")
+
+	for _, c := range clients {
+		err := h.AuthPublish(c, &topic, &payload)
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", c.ID, err))
+		h.Publish(c, &topic, &payload)
+	}
+
+	require.Len(t, auth.requests, len(clients), fmt.Sprintf("expected %d authorizations got %d", len(clients), len(auth.requests)))
+	require.Len(t, pub.msgs, len(clients), fmt.Sprintf("expected %d messages got %d", len(clients), len(pub.msgs)))
+
+	for i, c := range clients {
+		info := auth.requests[i]
+		assert.NotEmpty(t, info.RequestID, fmt.Sprintf("%s: expected request ID to be assigned", c.ID))
+		assert.Equal(t, reqctx.Thing(c.Username), info.Subject, fmt.Sprintf("%s: expected subject %s got %s", c.ID, reqctx.Thing(c.Username), info.Subject))
+
+		id := messaging.RequestID(pub.msgs[i])
+		assert.Equal(t, info.RequestID, id, fmt.Sprintf("%s: expected message request ID %s got %s", c.ID, info.RequestID, id))
+	}
+	assert.NotEqual(t, auth.requests[0].RequestID, auth.requests[1].RequestID, "expected distinct request IDs per publish")
+}
@@ -0,0 +1,50 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/topics"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/quota"
+	"github.com/mainflux/mproxy/pkg/session"
+)
+
+var _ session.Handler = (*quotaLimiter)(nil)
+
+type quotaLimiter struct {
+	session.Handler
+	checker quota.Checker
+	topics  topics.Template
+}
+
+// NewQuotaLimiter returns the handler rejecting the PUBLISH requests over
+// the monthly quota of the channel, once they're authorized by the wrapped
+// handler. The rejection error contains the time the quota resets at.
+func NewQuotaLimiter(h session.Handler, checker quota.Checker, tmpl topics.Template) session.Handler {
+	return &quotaLimiter{
+		Handler: h,
+		checker: checker,
+		topics:  tmpl,
+	}
+}
+
+func (ql *quotaLimiter) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	if err := ql.Handler.AuthPublish(c, topic, payload); err != nil {
+		return err
+	}
+
+	chanID, _, err := ql.topics.Split(*topic)
+	if err != nil {
+		return err
+	}
+	err = ql.checker.CheckAndIncrement(context.Background(), chanID)
+	if reset, ok := quota.IsExceeded(err); ok {
+		return errors.Wrap(quota.ErrQuotaExceeded, errors.New(fmt.Sprintf("quota resets at %s", reset.Format(time.RFC3339))))
+	}
+	return err
+}
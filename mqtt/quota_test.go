@@ -0,0 +1,83 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mqtt_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/topics"
+	"github.com/mainflux/mainflux/mqtt"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/quota"
+	"github.com/mainflux/mainflux/pkg/quota/mocks"
+	"github.com/mainflux/mproxy/pkg/session"
+	"github.com/stretchr/testify/assert"
+)
+
+// publishHandler authorizes the PUBLISH requests of the valid client.
+type publishHandler struct {
+	session.Handler
+}
+
+func (h *publishHandler) AuthPublish(c *session.Client, topic *string, payload *[]byte) error {
+	if string(c.Password) == "invalid" {
+		return errUnauthorized
+	}
+	return nil
+}
+
+func TestQuotaLimiter(t *testing.T) {
+	chanID := "1"
+	store := mocks.NewStore(map[string]uint64{chanID: 2})
+	clk := &clock{now: time.Date(2021, 6, 30, 12, 0, 0, 0, time.UTC)}
+	ql := mqtt.NewQuotaLimiter(&publishHandler{}, quota.NewChecker(store, quota.Config{Batch: 10}, clk.Now), topics.Default())
+
+	cases := []struct {
+		desc     string
+		topic    string
+		password string
+		count    int
+		err      error
+		reset    string
+	}{
+		{
+			desc:     "publish with invalid password",
+			topic:    fmt.Sprintf("channels/%s/messages", chanID),
+			password: "invalid",
+			count:    5,
+			err:      errUnauthorized,
+		},
+		{
+			desc:  "publish under quota",
+			topic: fmt.Sprintf("channels/%s/messages/sensors", chanID),
+			count: 2,
+		},
+		{
+			desc:  "publish over quota",
+			topic: fmt.Sprintf("channels/%s/messages", chanID),
+			count: 1,
+			err:   quota.ErrQuotaExceeded,
+			reset: "quota resets at 2021-07-01T00:00:00Z",
+		},
+		{
+			desc:  "publish to other channel",
+			topic: "channels/2/messages",
+			count: 10,
+		},
+	}
+
+	for _, tc := range cases {
+		var err error
+		for i := 0; i < tc.count; i++ {
+			topic := tc.topic
+			err = ql.AuthPublish(&session.Client{ID: "client", Password: []byte(tc.password)}, &topic, nil)
+		}
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		if tc.reset != "" {
+			assert.Contains(t, err.Error(), tc.reset, fmt.Sprintf("%s: expected reset hint %q in %s", tc.desc, tc.reset, err))
+		}
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import "context"
+
+// AccessKey identifies the cached access decision of the channel.
+type AccessKey struct {
+	ThingID  string
+	Action   string
+	Subtopic string
+}
+
+// AccessCache is the access decisions cache shared by the adapter instances.
+// The decisions are stamped with the policy version the things service made
+// them at, so the decisions older than the channel version are stale.
+type AccessCache interface {
+	// Retrieve returns the policy version the allowed access was cached
+	// at. False is returned if the access isn't cached, it expired, or the
+	// channel was invalidated at a newer version since.
+	Retrieve(ctx context.Context, chanID string, key AccessKey) (uint64, bool, error)
+
+	// Save caches the allowed access decided at the policy version, unless
+	// the channel was invalidated at a newer version meanwhile.
+	Save(ctx context.Context, chanID string, key AccessKey, version uint64) error
+
+	// Invalidate drops the channel decisions older than the version. Zero
+	// version drops all the channel decisions.
+	Invalidate(ctx context.Context, chanID string, version uint64) error
+
+	// InvalidateAll drops the decisions of all the channels.
+	InvalidateAll(ctx context.Context, version uint64) error
+}
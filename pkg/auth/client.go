@@ -5,36 +5,105 @@ package auth
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/clients/events"
+	"github.com/mainflux/mainflux/things/authz"
 )
 
 // Client represents Auth cache.
 type Client interface {
 	Authorize(ctx context.Context, chanID, thingID string) error
 	Identify(ctx context.Context, thingKey string) (string, error)
+
+	// Handle invalidates the access decisions affected by the things
+	// event. It's intended to be used as the things events handler.
+	Handle(ctx context.Context, event events.Event) error
 }
 
 const (
-	chanPrefix = "channel"
-	keyPrefix  = "thing_key"
+	keyPrefix = "thing_key"
+
+	maxCacheSize = 10000
+
+	thingRemove     = "thing.remove"
+	thingConnect    = "thing.connect"
+	thingDisconnect = "thing.disconnect"
+	channelRemove   = "channel.remove"
 )
 
+// CacheConfig represents the access decisions cache settings.
+type CacheConfig struct {
+	// TTL is the period the access decisions are reused for from the shared
+	// cache, unless they're invalidated by the things events first. Zero
+	// disables the cache.
+	TTL time.Duration
+
+	// LocalTTL is the period the access decisions are reused for from the
+	// local cache in front of the shared one. The local cache of the other
+	// instances isn't invalidated by the events this instance handles, so
+	// it's kept short. It's capped by TTL, and zero disables the local
+	// cache.
+	LocalTTL time.Duration
+
+	// SubtopicDepth is the number of the leading subtopic tokens the
+	// decisions are cached by, so the subtopics sharing them share the
+	// decision. Zero caches the decisions by the whole subtopic. It's
+	// expected to match the depth the authorization policies are set at.
+	SubtopicDepth int
+}
+
+type cacheEntry struct {
+	version uint64
+	expires time.Time
+}
+
+// channelCache holds the channel access decisions of the local cache. The version is the one
+// of the last event changing the channel access, so the decisions made at
+// the older version are stale, which invalidates them all at once.
+type channelCache struct {
+	version uint64
+	entries map[AccessKey]cacheEntry
+}
+
 type client struct {
 	redisClient  *redis.Client
 	thingsClient mainflux.ThingsServiceClient
+	cache        AccessCache
+	cfg          CacheConfig
+
+	mu       sync.Mutex
+	channels map[string]*channelCache
+	// floor is the version of the last event invalidating all the channels.
+	floor uint64
+	size  int
 }
 
-// New returns redis channel cache implementation.
-func New(redisClient *redis.Client, thingsClient mainflux.ThingsServiceClient) Client {
-	return client{
+// New returns redis thing identity cache implementation. The channel access
+// decisions are cached by the thing, the action and the subtopic bucket, and
+// stamped with the policy version the things service made them at. The
+// decisions are kept in the cache shared by the adapter instances, and
+// briefly in the local cache in front of it. The things events with a newer
+// version invalidate the channel decisions in both.
+func New(redisClient *redis.Client, thingsClient mainflux.ThingsServiceClient, cache AccessCache, cfg CacheConfig) Client {
+	if cfg.LocalTTL > cfg.TTL {
+		cfg.LocalTTL = cfg.TTL
+	}
+	return &client{
 		redisClient:  redisClient,
 		thingsClient: thingsClient,
+		cache:        cache,
+		cfg:          cfg,
+		channels:     make(map[string]*channelCache),
 	}
 }
 
-func (c client) Identify(ctx context.Context, thingKey string) (string, error) {
+func (c *client) Identify(ctx context.Context, thingKey string) (string, error) {
 	tkey := keyPrefix + ":" + thingKey
 	thingID, err := c.redisClient.Get(ctx, tkey).Result()
 	if err != nil {
@@ -51,15 +120,173 @@ func (c client) Identify(ctx context.Context, thingKey string) (string, error) {
 	return thingID, nil
 }
 
-func (c client) Authorize(ctx context.Context, chanID, thingID string) error {
-	if c.redisClient.SIsMember(ctx, chanPrefix+":"+chanID, thingID).Val() {
+func (c *client) Authorize(ctx context.Context, chanID, thingID string) error {
+	key := c.key(ctx, thingID)
+	if c.cached(chanID, key) {
 		return nil
 	}
+	// The shared cache failures only cost the things service call.
+	if c.cfg.TTL > 0 {
+		if version, ok, err := c.cache.Retrieve(ctx, chanID, key); err == nil && ok {
+			c.save(chanID, key, version)
+			return nil
+		}
+	}
 
+	ctx = authz.WithPolicyVersion(ctx)
 	ar := &mainflux.AccessByIDReq{
 		ThingID: thingID,
 		ChanID:  chanID,
 	}
-	_, err := c.thingsClient.CanAccessByID(ctx, ar)
-	return err
+	if _, err := c.thingsClient.CanAccessByID(ctx, ar); err != nil {
+		return err
+	}
+
+	// Only the allowed access is cached, so the thing denied the access is
+	// allowed as soon as the policy allows it.
+	version := authz.PolicyVersionFromContext(ctx)
+	if c.cfg.TTL > 0 {
+		c.cache.Save(ctx, chanID, key, version)
+	}
+	c.save(chanID, key, version)
+	return nil
+}
+
+func (c *client) Handle(ctx context.Context, event events.Event) error {
+	op, _ := event.Values["operation"].(string)
+	var chanID string
+	switch op {
+	case thingConnect, thingDisconnect:
+		chanID, _ = event.Values["chan_id"].(string)
+	case channelRemove:
+		chanID, _ = event.Values["id"].(string)
+	case thingRemove:
+	default:
+		return nil
+	}
+	version := policyVersion(event)
+	c.invalidate(op, chanID, version)
+
+	if c.cfg.TTL <= 0 {
+		return nil
+	}
+	// The removed thing may be cached in any channel.
+	if op == thingRemove {
+		return c.cache.InvalidateAll(ctx, version)
+	}
+	return c.cache.Invalidate(ctx, chanID, version)
+}
+
+// invalidate drops the local channel decisions older than the version.
+func (c *client) invalidate(op, chanID string, version uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if op == thingRemove {
+		c.reset(version)
+		return
+	}
+
+	cc, ok := c.channels[chanID]
+	if !ok {
+		c.channels[chanID] = &channelCache{version: version, entries: make(map[AccessKey]cacheEntry)}
+		return
+	}
+	// The event without the version is sent by the things service which
+	// doesn't stamp the decisions, so the decisions are dropped.
+	if version == 0 {
+		c.size -= len(cc.entries)
+		cc.entries = make(map[AccessKey]cacheEntry)
+		return
+	}
+	if version > cc.version {
+		cc.version = version
+	}
+}
+
+func (c *client) key(ctx context.Context, thingID string) AccessKey {
+	a := authz.AccessFromContext(ctx)
+	subtopic := a.Subtopic
+	if c.cfg.SubtopicDepth > 0 {
+		tokens := strings.SplitN(subtopic, ".", c.cfg.SubtopicDepth+1)
+		if len(tokens) > c.cfg.SubtopicDepth {
+			tokens = tokens[:c.cfg.SubtopicDepth]
+		}
+		subtopic = strings.Join(tokens, ".")
+	}
+	return AccessKey{ThingID: thingID, Action: a.Action, Subtopic: subtopic}
+}
+
+func (c *client) cached(chanID string, key AccessKey) bool {
+	if c.cfg.LocalTTL <= 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cc, ok := c.channels[chanID]
+	if !ok {
+		return false
+	}
+	e, ok := cc.entries[key]
+	if !ok || e.version < cc.version || time.Now().After(e.expires) {
+		return false
+	}
+	return true
+}
+
+func (c *client) save(chanID string, key AccessKey, version uint64) {
+	if c.cfg.LocalTTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cc, ok := c.channels[chanID]
+	if !ok {
+		cc = &channelCache{version: c.floor, entries: make(map[AccessKey]cacheEntry)}
+		c.channels[chanID] = cc
+	}
+	// The decision made before the last policy change the cache knows of
+	// might be stale already.
+	if version < cc.version || version < c.floor {
+		return
+	}
+
+	// Too many decisions are dropped rather than growing the cache.
+	if c.size >= maxCacheSize {
+		c.reset(version)
+		cc = &channelCache{version: c.floor, entries: make(map[AccessKey]cacheEntry)}
+		c.channels[chanID] = cc
+	}
+	if _, ok := cc.entries[key]; !ok {
+		c.size++
+	}
+	cc.entries[key] = cacheEntry{version: version, expires: time.Now().Add(c.cfg.LocalTTL)}
+}
+
+// reset drops all the decisions. The floor is raised to the newest version
+// known, so the decisions made before it aren't cached anymore.
+func (c *client) reset(version uint64) {
+	for _, cc := range c.channels {
+		if cc.version > version {
+			version = cc.version
+		}
+	}
+	if version > c.floor {
+		c.floor = version
+	}
+	c.channels = make(map[string]*channelCache)
+	c.size = 0
+}
+
+func policyVersion(event events.Event) uint64 {
+	switch v := event.Values["policy_version"].(type) {
+	case string:
+		ret, _ := strconv.ParseUint(v, 10, 64)
+		return ret
+	case uint64:
+		return v
+	default:
+		return 0
+	}
 }
@@ -0,0 +1,355 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/clients/events"
+	"github.com/mainflux/mainflux/pkg/auth"
+	"github.com/mainflux/mainflux/pkg/auth/mocks"
+	"github.com/mainflux/mainflux/things/authz"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	thingID  = "thing"
+	thingID2 = "thing2"
+	chanID   = "channel"
+	chanID2  = "channel2"
+)
+
+var errDenied = status.Error(codes.PermissionDenied, "denied")
+
+type policy struct {
+	thingID  string
+	chanID   string
+	subtopic string
+}
+
+// thingsClient allows the access by the policies, stamping the decisions
+// with the policy version like the things service does.
+type thingsClient struct {
+	mainflux.ThingsServiceClient
+
+	mu       sync.Mutex
+	version  uint64
+	policies map[policy]bool
+	calls    int
+}
+
+func (tc *thingsClient) CanAccessByID(ctx context.Context, req *mainflux.AccessByIDReq, _ ...grpc.CallOption) (*empty.Empty, error) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.calls++
+	authz.SetPolicyVersion(ctx, tc.version)
+	p := policy{thingID: req.GetThingID(), chanID: req.GetChanID(), subtopic: authz.AccessFromContext(ctx).Subtopic}
+	if !tc.policies[p] {
+		return nil, errDenied
+	}
+	return &empty.Empty{}, nil
+}
+
+// change sets the policy and returns the policy version after the change.
+func (tc *thingsClient) change(p policy, allow bool) uint64 {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	tc.policies[p] = allow
+	tc.version++
+	return tc.version
+}
+
+func (tc *thingsClient) callCount() int {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.calls
+}
+
+func newThingsClient(policies ...policy) *thingsClient {
+	tc := &thingsClient{version: 1, policies: map[policy]bool{}}
+	for _, p := range policies {
+		tc.policies[p] = true
+	}
+	return tc
+}
+
+func event(op, id string, version uint64) events.Event {
+	values := map[string]interface{}{"operation": op}
+	switch op {
+	case "thing.connect", "thing.disconnect":
+		values["chan_id"] = id
+		values["thing_id"] = thingID
+	default:
+		values["id"] = id
+	}
+	if version > 0 {
+		values["policy_version"] = strconv.FormatUint(version, 10)
+	}
+	return events.Event{ID: "1-0", Values: values}
+}
+
+func publish(subtopic string) context.Context {
+	return authz.WithAccess(context.Background(), authz.Access{Action: authz.Publish, Subtopic: subtopic})
+}
+
+func TestAuthorize(t *testing.T) {
+	ths := newThingsClient(policy{thingID: thingID, chanID: chanID, subtopic: "data"})
+	client := auth.New(nil, ths, mocks.NewAccessCache(time.Minute), auth.CacheConfig{TTL: time.Minute, LocalTTL: time.Minute})
+
+	cases := []struct {
+		desc    string
+		ctx     context.Context
+		thingID string
+		chanID  string
+		err     error
+		calls   int
+	}{
+		{
+			desc:    "authorize allowed access",
+			ctx:     publish("data"),
+			thingID: thingID,
+			chanID:  chanID,
+			calls:   1,
+		},
+		{
+			desc:    "authorize cached allowed access",
+			ctx:     publish("data"),
+			thingID: thingID,
+			chanID:  chanID,
+			calls:   1,
+		},
+		{
+			desc:    "authorize access denied on another subtopic",
+			ctx:     publish("commands"),
+			thingID: thingID,
+			chanID:  chanID,
+			err:     errDenied,
+			calls:   2,
+		},
+		{
+			desc:    "authorize denied access again",
+			ctx:     publish("commands"),
+			thingID: thingID,
+			chanID:  chanID,
+			err:     errDenied,
+			calls:   3,
+		},
+		{
+			desc:    "authorize access of another thing",
+			ctx:     publish("data"),
+			thingID: thingID2,
+			chanID:  chanID,
+			err:     errDenied,
+			calls:   4,
+		},
+		{
+			desc:    "authorize access to another channel",
+			ctx:     publish("data"),
+			thingID: thingID,
+			chanID:  chanID2,
+			err:     errDenied,
+			calls:   5,
+		},
+		{
+			desc:    "authorize subscription on the allowed subtopic",
+			ctx:     authz.WithAccess(context.Background(), authz.Access{Action: authz.Subscribe, Subtopic: "data"}),
+			thingID: thingID,
+			chanID:  chanID,
+			calls:   6,
+		},
+	}
+
+	for _, tc := range cases {
+		err := client.Authorize(tc.ctx, tc.chanID, tc.thingID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.calls, ths.callCount(), fmt.Sprintf("%s: expected %d things calls got %d", tc.desc, tc.calls, ths.callCount()))
+	}
+}
+
+func TestAuthorizeSubtopicDepth(t *testing.T) {
+	ths := newThingsClient(
+		policy{thingID: thingID, chanID: chanID, subtopic: "data.temperature"},
+		policy{thingID: thingID, chanID: chanID, subtopic: "data.humidity"},
+	)
+	client := auth.New(nil, ths, mocks.NewAccessCache(time.Minute), auth.CacheConfig{TTL: time.Minute, LocalTTL: time.Minute, SubtopicDepth: 1})
+
+	cases := []struct {
+		desc     string
+		subtopic string
+		err      error
+		calls    int
+	}{
+		{desc: "authorize access to subtopic", subtopic: "data.temperature", calls: 1},
+		{desc: "authorize access to subtopic of the same bucket", subtopic: "data.humidity", calls: 1},
+		{desc: "authorize access to subtopic of another bucket", subtopic: "commands.reset", err: errDenied, calls: 2},
+	}
+
+	for _, tc := range cases {
+		err := client.Authorize(publish(tc.subtopic), chanID, thingID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.calls, ths.callCount(), fmt.Sprintf("%s: expected %d things calls got %d", tc.desc, tc.calls, ths.callCount()))
+	}
+}
+
+func TestHandle(t *testing.T) {
+	allowed := policy{thingID: thingID, chanID: chanID, subtopic: "data"}
+
+	cases := []struct {
+		desc  string
+		event func(version uint64) events.Event
+		// stale sends the event with the version preceding the change.
+		stale bool
+		err   error
+	}{
+		{
+			desc:  "deny cached access after disconnect event",
+			event: func(v uint64) events.Event { return event("thing.disconnect", chanID, v) },
+			err:   errDenied,
+		},
+		{
+			desc:  "deny cached access after connect event",
+			event: func(v uint64) events.Event { return event("thing.connect", chanID, v) },
+			err:   errDenied,
+		},
+		{
+			desc:  "deny cached access after channel remove event",
+			event: func(v uint64) events.Event { return event("channel.remove", chanID, v) },
+			err:   errDenied,
+		},
+		{
+			desc:  "deny cached access after thing remove event",
+			event: func(v uint64) events.Event { return event("thing.remove", thingID, v) },
+			err:   errDenied,
+		},
+		{
+			desc:  "deny cached access after event without version",
+			event: func(uint64) events.Event { return event("thing.disconnect", chanID, 0) },
+			err:   errDenied,
+		},
+		{
+			desc:  "keep cached access after event of another channel",
+			event: func(v uint64) events.Event { return event("thing.disconnect", chanID2, v) },
+			err:   nil,
+		},
+		{
+			desc:  "keep cached access after stale event",
+			event: func(v uint64) events.Event { return event("thing.disconnect", chanID, v) },
+			stale: true,
+			err:   nil,
+		},
+		{
+			desc:  "keep cached access after unrelated event",
+			event: func(v uint64) events.Event { return event("channel.update", chanID, v) },
+			err:   nil,
+		},
+	}
+
+	for _, tc := range cases {
+		ths := newThingsClient(allowed)
+		client := auth.New(nil, ths, mocks.NewAccessCache(time.Minute), auth.CacheConfig{TTL: time.Minute, LocalTTL: time.Minute})
+		err := client.Authorize(publish("data"), chanID, thingID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		before := ths.version
+		version := ths.change(allowed, false)
+		if tc.stale {
+			version = before
+		}
+		err = client.Handle(context.Background(), tc.event(version))
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		err = client.Authorize(publish("data"), chanID, thingID)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+}
+
+// versionedClient makes the decision at the version preceding the change
+// which is applied while the decision is on the way.
+type versionedClient struct {
+	*thingsClient
+	onDecision func()
+}
+
+func (vc versionedClient) CanAccessByID(ctx context.Context, req *mainflux.AccessByIDReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+	res, err := vc.thingsClient.CanAccessByID(ctx, req, opts...)
+	vc.onDecision()
+	return res, err
+}
+
+func TestHandleInFlight(t *testing.T) {
+	allowed := policy{thingID: thingID, chanID: chanID, subtopic: "data"}
+	ths := newThingsClient(allowed)
+
+	var client auth.Client
+	client = auth.New(nil, versionedClient{thingsClient: ths, onDecision: func() {
+		// The first decision is stale by the time it reaches the cache.
+		if ths.version == 1 {
+			v := ths.change(allowed, false)
+			client.Handle(context.Background(), event("thing.disconnect", chanID, v))
+		}
+	}}, mocks.NewAccessCache(time.Minute), auth.CacheConfig{TTL: time.Minute, LocalTTL: time.Minute})
+
+	err := client.Authorize(publish("data"), chanID, thingID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = client.Authorize(publish("data"), chanID, thingID)
+	assert.Equal(t, errDenied, err, fmt.Sprintf("expected error %s got %s", errDenied, err))
+}
+
+func TestAuthorizeExpired(t *testing.T) {
+	ths := newThingsClient(policy{thingID: thingID, chanID: chanID, subtopic: "data"})
+	client := auth.New(nil, ths, mocks.NewAccessCache(50*time.Millisecond), auth.CacheConfig{TTL: 50 * time.Millisecond, LocalTTL: 50 * time.Millisecond})
+
+	err := client.Authorize(publish("data"), chanID, thingID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	time.Sleep(100 * time.Millisecond)
+	err = client.Authorize(publish("data"), chanID, thingID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, 2, ths.callCount(), fmt.Sprintf("expected expired access authorized again, got %d things calls", ths.callCount()))
+}
+
+func TestAuthorizeShared(t *testing.T) {
+	allowed := policy{thingID: thingID, chanID: chanID, subtopic: "data"}
+	ths := newThingsClient(allowed)
+	cache := mocks.NewAccessCache(time.Minute)
+	cfg := auth.CacheConfig{TTL: time.Minute, LocalTTL: 50 * time.Millisecond}
+	first := auth.New(nil, ths, cache, cfg)
+	second := auth.New(nil, ths, cache, cfg)
+
+	err := first.Authorize(publish("data"), chanID, thingID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = second.Authorize(publish("data"), chanID, thingID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, 1, ths.callCount(), fmt.Sprintf("expected access shared by instances, got %d things calls", ths.callCount()))
+	assert.Equal(t, 1, cache.Hits(), fmt.Sprintf("expected 1 shared cache hit got %d", cache.Hits()))
+
+	// The local decision is reused until it expires, after which the
+	// shared one is used.
+	err = second.Authorize(publish("data"), chanID, thingID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, 1, cache.Hits(), fmt.Sprintf("expected local decision reused, got %d shared cache hits", cache.Hits()))
+	time.Sleep(100 * time.Millisecond)
+	err = second.Authorize(publish("data"), chanID, thingID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, 2, cache.Hits(), fmt.Sprintf("expected expired local decision retrieved from shared cache, got %d hits", cache.Hits()))
+
+	// The event handled by one instance invalidates the shared decision, so
+	// the other one is denied once its local decision expires.
+	v := ths.change(allowed, false)
+	err = first.Handle(context.Background(), event("thing.disconnect", chanID, v))
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	time.Sleep(100 * time.Millisecond)
+	err = second.Authorize(publish("data"), chanID, thingID)
+	assert.Equal(t, errDenied, err, fmt.Sprintf("expected error %s got %s", errDenied, err))
+	assert.Equal(t, 2, ths.callCount(), fmt.Sprintf("expected invalidated access authorized again, got %d things calls", ths.callCount()))
+}
@@ -0,0 +1,114 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/auth"
+)
+
+var _ auth.AccessCache = (*AccessCache)(nil)
+
+type entry struct {
+	version uint64
+	epoch   uint64
+	expires time.Time
+}
+
+type channel struct {
+	version uint64
+	entries map[auth.AccessKey]entry
+}
+
+// AccessCache is the in-memory access decisions cache.
+type AccessCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	channels map[string]*channel
+	floor    uint64
+	epoch    uint64
+	hits     int
+}
+
+// NewAccessCache returns the in-memory access decisions cache keeping the
+// decisions for the TTL.
+func NewAccessCache(ttl time.Duration) *AccessCache {
+	return &AccessCache{
+		ttl:      ttl,
+		channels: make(map[string]*channel),
+	}
+}
+
+// Hits returns the number of the decisions retrieved from the cache.
+func (ac *AccessCache) Hits() int {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	return ac.hits
+}
+
+// Retrieve returns the cached decision the same way the Redis cache does.
+func (ac *AccessCache) Retrieve(_ context.Context, chanID string, key auth.AccessKey) (uint64, bool, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ch, ok := ac.channels[chanID]
+	if !ok {
+		return 0, false, nil
+	}
+	e, ok := ch.entries[key]
+	if !ok || !time.Now().Before(e.expires) || e.epoch != ac.epoch || e.version < ch.version || e.version < ac.floor {
+		return 0, false, nil
+	}
+	ac.hits++
+	return e.version, true, nil
+}
+
+func (ac *AccessCache) Save(_ context.Context, chanID string, key auth.AccessKey, version uint64) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ch, ok := ac.channels[chanID]
+	if !ok {
+		ch = &channel{entries: make(map[auth.AccessKey]entry)}
+		ac.channels[chanID] = ch
+	}
+	if version < ch.version || version < ac.floor {
+		return nil
+	}
+	ch.entries[key] = entry{version: version, epoch: ac.epoch, expires: time.Now().Add(ac.ttl)}
+	return nil
+}
+
+func (ac *AccessCache) Invalidate(_ context.Context, chanID string, version uint64) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ch, ok := ac.channels[chanID]
+	if !ok {
+		ch = &channel{}
+	}
+	if version > 0 && version <= ch.version {
+		return nil
+	}
+	if version < ch.version {
+		version = ch.version
+	}
+	ac.channels[chanID] = &channel{version: version, entries: make(map[auth.AccessKey]entry)}
+	return nil
+}
+
+func (ac *AccessCache) InvalidateAll(_ context.Context, version uint64) error {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if version > ac.floor {
+		ac.floor = version
+	}
+	ac.epoch++
+	return nil
+}
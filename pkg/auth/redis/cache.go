@@ -0,0 +1,169 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis contains the access decisions cache implementation using
+// Redis, shared by the adapter instances.
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/pkg/auth"
+)
+
+const (
+	keyPrefix     = "access"
+	channelPrefix = keyPrefix + ":channel"
+	floorKey      = keyPrefix + ":floor"
+	epochKey      = keyPrefix + ":epoch"
+)
+
+// The decisions of the channel are kept in the hash holding the channel
+// version and the decisions, each one stored as the version it was made at,
+// the epoch it was saved in and the expiration as Unix time in milliseconds.
+// The floor is the version all the channels were last invalidated at, while
+// the epoch is incremented on each such invalidation, which drops the
+// decisions without the version as well.
+
+// retrieveScript returns the version of the cached decision, or nil if it's
+// missing, expired or stale.
+//
+// KEYS: channel, floor, epoch. ARGV: decision field, current Unix time in
+// milliseconds.
+var retrieveScript = redis.NewScript(`
+local entry = redis.call('HGET', KEYS[1], ARGV[1])
+if not entry then
+	return false
+end
+local version, epoch, expires = string.match(entry, '^(%d+):(%d+):(%d+)$')
+if not version or tonumber(expires) <= tonumber(ARGV[2]) then
+	return false
+end
+if epoch ~= (redis.call('GET', KEYS[3]) or '0') then
+	return false
+end
+local v = tonumber(version)
+if v < tonumber(redis.call('HGET', KEYS[1], 'version') or '0') then
+	return false
+end
+if v < tonumber(redis.call('GET', KEYS[2]) or '0') then
+	return false
+end
+return version
+`)
+
+// saveScript caches the decision unless it's older than the channel or the
+// floor version.
+//
+// KEYS: channel, floor, epoch. ARGV: decision field, decision version,
+// expiration as Unix time in milliseconds, TTL in milliseconds.
+var saveScript = redis.NewScript(`
+local v = tonumber(ARGV[2])
+if v < tonumber(redis.call('HGET', KEYS[1], 'version') or '0') then
+	return 0
+end
+if v < tonumber(redis.call('GET', KEYS[2]) or '0') then
+	return 0
+end
+local epoch = redis.call('GET', KEYS[3]) or '0'
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2] .. ':' .. epoch .. ':' .. ARGV[3])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return 1
+`)
+
+// invalidateScript drops the channel decisions if the version is newer than
+// the channel one, or zero.
+//
+// KEYS: channel. ARGV: version, TTL in milliseconds.
+var invalidateScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], 'version') or '0'
+local v = ARGV[1]
+if tonumber(v) > 0 and tonumber(v) <= tonumber(cur) then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+if tonumber(v) < tonumber(cur) then
+	v = cur
+end
+if tonumber(v) > 0 then
+	redis.call('HSET', KEYS[1], 'version', v)
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// invalidateAllScript raises the floor to the version and starts the new
+// epoch.
+//
+// KEYS: floor, epoch. ARGV: version.
+var invalidateAllScript = redis.NewScript(`
+if tonumber(ARGV[1]) > tonumber(redis.call('GET', KEYS[1]) or '0') then
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+redis.call('INCR', KEYS[2])
+return 1
+`)
+
+var _ auth.AccessCache = (*accessCache)(nil)
+
+type accessCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewAccessCache returns Redis access decisions cache, keeping the decisions
+// for the TTL.
+func NewAccessCache(client *redis.Client, ttl time.Duration) auth.AccessCache {
+	return accessCache{client: client, ttl: ttl}
+}
+
+func (ac accessCache) Retrieve(ctx context.Context, chanID string, key auth.AccessKey) (uint64, bool, error) {
+	keys := []string{channelKey(chanID), floorKey, epochKey}
+	version, err := retrieveScript.Run(ctx, ac.client, keys, field(key), millis(time.Now())).Text()
+	if err == redis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	v, err := strconv.ParseUint(version, 10, 64)
+	if err != nil {
+		return 0, false, err
+	}
+	return v, true, nil
+}
+
+func (ac accessCache) Save(ctx context.Context, chanID string, key auth.AccessKey, version uint64) error {
+	keys := []string{channelKey(chanID), floorKey, epochKey}
+	expires := millis(time.Now().Add(ac.ttl))
+	return saveScript.Run(ctx, ac.client, keys, field(key), strconv.FormatUint(version, 10), expires, ac.ttl.Milliseconds()).Err()
+}
+
+func (ac accessCache) Invalidate(ctx context.Context, chanID string, version uint64) error {
+	keys := []string{channelKey(chanID)}
+	return invalidateScript.Run(ctx, ac.client, keys, strconv.FormatUint(version, 10), ac.ttl.Milliseconds()).Err()
+}
+
+func (ac accessCache) InvalidateAll(ctx context.Context, version uint64) error {
+	keys := []string{floorKey, epochKey}
+	return invalidateAllScript.Run(ctx, ac.client, keys, strconv.FormatUint(version, 10)).Err()
+}
+
+func channelKey(chanID string) string {
+	return fmt.Sprintf("%s:%s", channelPrefix, chanID)
+}
+
+// field returns the hash field of the decision. The channel version is kept
+// in the "version" field, which doesn't collide with the decisions since
+// their fields contain the separators.
+func field(key auth.AccessKey) string {
+	return fmt.Sprintf("%s:%s:%s", key.ThingID, key.Action, key.Subtopic)
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
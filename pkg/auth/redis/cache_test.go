@@ -0,0 +1,150 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/auth"
+	authredis "github.com/mainflux/mainflux/pkg/auth/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chanID  = "channel"
+	chanID2 = "channel2"
+)
+
+var key = auth.AccessKey{ThingID: "thing", Action: "publish", Subtopic: "data"}
+
+func TestAccessCache(t *testing.T) {
+	cases := []struct {
+		desc       string
+		saved      uint64
+		invalidate func(ctx context.Context, cache auth.AccessCache) error
+		version    uint64
+		cached     bool
+	}{
+		{
+			desc:       "retrieve saved decision",
+			saved:      2,
+			invalidate: func(context.Context, auth.AccessCache) error { return nil },
+			version:    2,
+			cached:     true,
+		},
+		{
+			desc:  "retrieve decision after channel invalidated at older version",
+			saved: 2,
+			invalidate: func(ctx context.Context, cache auth.AccessCache) error {
+				return cache.Invalidate(ctx, chanID, 1)
+			},
+			version: 2,
+			cached:  true,
+		},
+		{
+			desc:  "retrieve decision after channel invalidated at newer version",
+			saved: 2,
+			invalidate: func(ctx context.Context, cache auth.AccessCache) error {
+				return cache.Invalidate(ctx, chanID, 3)
+			},
+			cached: false,
+		},
+		{
+			desc:  "retrieve decision after channel invalidated without version",
+			saved: 2,
+			invalidate: func(ctx context.Context, cache auth.AccessCache) error {
+				return cache.Invalidate(ctx, chanID, 0)
+			},
+			cached: false,
+		},
+		{
+			desc:  "retrieve decision after another channel invalidated",
+			saved: 2,
+			invalidate: func(ctx context.Context, cache auth.AccessCache) error {
+				return cache.Invalidate(ctx, chanID2, 3)
+			},
+			version: 2,
+			cached:  true,
+		},
+		{
+			desc:  "retrieve decision after all channels invalidated",
+			saved: 2,
+			invalidate: func(ctx context.Context, cache auth.AccessCache) error {
+				return cache.InvalidateAll(ctx, 3)
+			},
+			cached: false,
+		},
+		{
+			desc:  "retrieve decision after all channels invalidated without version",
+			saved: 2,
+			invalidate: func(ctx context.Context, cache auth.AccessCache) error {
+				return cache.InvalidateAll(ctx, 0)
+			},
+			cached: false,
+		},
+	}
+
+	for _, tc := range cases {
+		ctx := context.Background()
+		redisClient.FlushAll(ctx)
+		cache := authredis.NewAccessCache(redisClient, time.Minute)
+
+		err := cache.Save(ctx, chanID, key, tc.saved)
+		require.Nil(t, err, fmt.Sprintf("%s: saving decision expected to succeed: %s", tc.desc, err))
+		err = tc.invalidate(ctx, cache)
+		require.Nil(t, err, fmt.Sprintf("%s: invalidating decisions expected to succeed: %s", tc.desc, err))
+
+		version, cached, err := cache.Retrieve(ctx, chanID, key)
+		assert.Nil(t, err, fmt.Sprintf("%s: retrieving decision expected to succeed: %s", tc.desc, err))
+		assert.Equal(t, tc.cached, cached, fmt.Sprintf("%s: expected cached %t got %t", tc.desc, tc.cached, cached))
+		assert.Equal(t, tc.version, version, fmt.Sprintf("%s: expected version %d got %d", tc.desc, tc.version, version))
+	}
+}
+
+func TestAccessCacheStale(t *testing.T) {
+	ctx := context.Background()
+	redisClient.FlushAll(ctx)
+	cache := authredis.NewAccessCache(redisClient, time.Minute)
+
+	// The decision made before the invalidation reaches the cache after it.
+	err := cache.Invalidate(ctx, chanID, 3)
+	require.Nil(t, err, fmt.Sprintf("invalidating decisions expected to succeed: %s", err))
+	err = cache.Save(ctx, chanID, key, 2)
+	require.Nil(t, err, fmt.Sprintf("saving decision expected to succeed: %s", err))
+	_, cached, err := cache.Retrieve(ctx, chanID, key)
+	assert.Nil(t, err, fmt.Sprintf("retrieving decision expected to succeed: %s", err))
+	assert.False(t, cached, "expected stale decision not to be cached")
+
+	err = cache.InvalidateAll(ctx, 5)
+	require.Nil(t, err, fmt.Sprintf("invalidating all decisions expected to succeed: %s", err))
+	err = cache.Save(ctx, chanID2, key, 4)
+	require.Nil(t, err, fmt.Sprintf("saving decision expected to succeed: %s", err))
+	_, cached, err = cache.Retrieve(ctx, chanID2, key)
+	assert.Nil(t, err, fmt.Sprintf("retrieving decision expected to succeed: %s", err))
+	assert.False(t, cached, "expected decision older than floor not to be cached")
+
+	err = cache.Save(ctx, chanID2, key, 5)
+	require.Nil(t, err, fmt.Sprintf("saving decision expected to succeed: %s", err))
+	version, cached, err := cache.Retrieve(ctx, chanID2, key)
+	assert.Nil(t, err, fmt.Sprintf("retrieving decision expected to succeed: %s", err))
+	assert.True(t, cached, "expected decision at floor version to be cached")
+	assert.Equal(t, uint64(5), version, fmt.Sprintf("expected version %d got %d", 5, version))
+}
+
+func TestAccessCacheExpired(t *testing.T) {
+	ctx := context.Background()
+	redisClient.FlushAll(ctx)
+	cache := authredis.NewAccessCache(redisClient, 50*time.Millisecond)
+
+	err := cache.Save(ctx, chanID, key, 1)
+	require.Nil(t, err, fmt.Sprintf("saving decision expected to succeed: %s", err))
+	time.Sleep(100 * time.Millisecond)
+	_, cached, err := cache.Retrieve(ctx, chanID, key)
+	assert.Nil(t, err, fmt.Sprintf("retrieving decision expected to succeed: %s", err))
+	assert.False(t, cached, "expected expired decision not to be cached")
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"crypto/cipher"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// cacheEntry is the content of the cache file.
+type cacheEntry struct {
+	Bootstrap Bootstrap `json:"bootstrap"`
+	Updated   time.Time `json:"updated"`
+	ETag      string    `json:"etag,omitempty"`
+	Hash      string    `json:"hash"`
+}
+
+// cache persists the configuration, encrypting it if the block is set, since
+// it contains the thing key.
+type cache struct {
+	path  string
+	block cipher.Block
+}
+
+// load returns the cached configuration, or false if there is no cache file.
+func (c *cache) load() (cacheEntry, bool, error) {
+	data, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return cacheEntry{}, false, nil
+	}
+	if err != nil {
+		return cacheEntry{}, false, errors.Wrap(ErrCache, err)
+	}
+	if c.block != nil {
+		if data, err = decrypt(c.block, data); err != nil {
+			return cacheEntry{}, false, errors.Wrap(ErrCache, err)
+		}
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return cacheEntry{}, false, errors.Wrap(ErrCache, err)
+	}
+	return entry, true, nil
+}
+
+// save replaces the cache file, so the interrupted write doesn't leave it
+// corrupted.
+func (c *cache) save(entry cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(ErrCache, err)
+	}
+	if c.block != nil {
+		if data, err = encrypt(c.block, data); err != nil {
+			return errors.Wrap(ErrCache, err)
+		}
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return errors.Wrap(ErrCache, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(ErrCache, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(ErrCache, err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return errors.Wrap(ErrCache, err)
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package client contains the bootstrap client used by the device agents to
+// fetch their configuration from the Mainflux bootstrap service.
+package client
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	bootstrapPath       = "things/bootstrap"
+	secureBootstrapPath = "things/bootstrap/secure"
+	defInterval         = time.Minute
+)
+
+var (
+	// ErrFetch indicates that the bootstrap service rejected the request or
+	// returned a malformed configuration.
+	ErrFetch = errors.New("failed to fetch bootstrap configuration")
+
+	// ErrUnavailable indicates that the bootstrap service can't be reached.
+	ErrUnavailable = errors.New("bootstrap service unavailable")
+
+	// ErrNoConfig indicates that the configuration can't be fetched and
+	// there is no cached one.
+	ErrNoConfig = errors.New("bootstrap configuration not available")
+
+	// ErrCache indicates failure to read or write the cache file.
+	ErrCache = errors.New("failed to access bootstrap configuration cache")
+
+	// ErrInvalidEncKey indicates the encryption key of the wrong size.
+	ErrInvalidEncKey = errors.New("invalid encryption key")
+
+	errDecrypt = errors.New("failed to decrypt bootstrap configuration")
+)
+
+// Config represents the bootstrap client settings.
+type Config struct {
+	// URL is the bootstrap service URL, e.g. http://localhost:8202.
+	URL string
+
+	// ExternalID and ExternalKey identify the device.
+	ExternalID  string
+	ExternalKey string
+
+	// EncKey is the AES key shared with the bootstrap service. If it's set,
+	// the secure bootstrap endpoint is used and the cache is encrypted.
+	EncKey []byte
+
+	// CachePath is the path of the local cache file. If it's empty, the
+	// configuration is not cached.
+	CachePath string
+
+	// Interval is the period of checking for the configuration changes. It
+	// defaults to one minute.
+	Interval time.Duration
+
+	// HTTPClient is used to send the requests. It defaults to the
+	// http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Channel represents the Mainflux channel the device uses.
+type Channel struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Bootstrap represents the device configuration fetched from the bootstrap
+// service.
+type Bootstrap struct {
+	ThingID    string    `json:"mainflux_id"`
+	ThingKey   string    `json:"mainflux_key"`
+	Channels   []Channel `json:"mainflux_channels"`
+	Content    string    `json:"content,omitempty"`
+	ClientCert string    `json:"client_cert,omitempty"`
+	ClientKey  string    `json:"client_key,omitempty"`
+	CACert     string    `json:"ca_cert,omitempty"`
+}
+
+// Channel returns the channel with the given name.
+func (b Bootstrap) Channel(name string) (Channel, bool) {
+	for _, ch := range b.Channels {
+		if ch.Name == name {
+			return ch, true
+		}
+	}
+	return Channel{}, false
+}
+
+// DecodeContent decodes the JSON encoded custom content into v.
+func (b Bootstrap) DecodeContent(v interface{}) error {
+	return json.Unmarshal([]byte(b.Content), v)
+}
+
+// State represents the configuration known to the client.
+type State struct {
+	Bootstrap
+
+	// Updated is the time the configuration was last confirmed by the
+	// bootstrap service.
+	Updated time.Time
+
+	// Stale is set if the configuration couldn't be confirmed by the latest
+	// request, so it comes from the cache or an earlier request.
+	Stale bool
+
+	// Err is the reason the configuration is stale.
+	Err error
+}
+
+// Client fetches the device configuration from the bootstrap service.
+type Client interface {
+	// Fetch fetches the configuration. If the service is unavailable, the
+	// last known configuration is returned as stale, and ErrNoConfig is
+	// returned if there is none. Requests rejected by the service are not
+	// answered from the cache.
+	Fetch(ctx context.Context) (State, error)
+
+	// Watch fetches the configuration periodically until the context is
+	// canceled, calling fn whenever the configuration changes. The
+	// configuration known before the call is not passed to fn.
+	Watch(ctx context.Context, fn func(State)) error
+
+	// State returns the last known configuration.
+	State() (State, bool)
+}
+
+type client struct {
+	cfg   Config
+	http  *http.Client
+	block cipher.Block
+	cache *cache
+
+	mu    sync.Mutex
+	state State
+	known bool
+	etag  string
+	hash  string
+}
+
+// New returns the bootstrap client. If the cache file exists, its
+// configuration is loaded as a stale one, until it's confirmed by the
+// service.
+func New(cfg Config) (Client, error) {
+	c := &client{
+		cfg:  cfg,
+		http: cfg.HTTPClient,
+	}
+	if c.http == nil {
+		c.http = http.DefaultClient
+	}
+	if c.cfg.Interval <= 0 {
+		c.cfg.Interval = defInterval
+	}
+	if cfg.EncKey != nil {
+		block, err := aes.NewCipher(cfg.EncKey)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidEncKey, err)
+		}
+		c.block = block
+	}
+	if cfg.CachePath == "" {
+		return c, nil
+	}
+
+	c.cache = &cache{path: cfg.CachePath, block: c.block}
+	entry, ok, err := c.cache.load()
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		c.state = State{
+			Bootstrap: entry.Bootstrap,
+			Updated:   entry.Updated,
+			Stale:     true,
+		}
+		c.known = true
+		c.etag = entry.ETag
+		c.hash = entry.Hash
+	}
+
+	return c, nil
+}
+
+func (c *client) Fetch(ctx context.Context) (State, error) {
+	s, _, err := c.fetch(ctx)
+	return s, err
+}
+
+func (c *client) Watch(ctx context.Context, fn func(State)) error {
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		// The change is reported even if it couldn't be cached.
+		if s, changed, _ := c.fetch(ctx); changed {
+			fn(s)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *client) State() (State, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state, c.known
+}
+
+// fetch fetches the configuration and reports whether it differs from the
+// previously known one.
+func (c *client) fetch(ctx context.Context) (State, bool, error) {
+	// The lock isn't held during the request, so the state can be read
+	// while the service is slow to respond.
+	c.mu.Lock()
+	etag := c.etag
+	if !c.known {
+		etag = ""
+	}
+	c.mu.Unlock()
+
+	resp, err := c.request(ctx, etag)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case errors.Contains(err, ErrUnavailable):
+		if !c.known {
+			return State{}, false, errors.Wrap(ErrNoConfig, err)
+		}
+		c.state.Stale = true
+		c.state.Err = err
+		return c.state, false, nil
+	case err != nil:
+		return State{}, false, err
+	}
+
+	now := time.Now()
+	if resp.notModified {
+		if !c.known {
+			return State{}, false, errors.Wrap(ErrFetch, errors.New("not modified response to unconditional request"))
+		}
+		c.state.Updated = now
+		c.state.Stale = false
+		c.state.Err = nil
+		return c.state, false, nil
+	}
+
+	changed := !c.known || resp.hash != c.hash
+	c.state = State{Bootstrap: resp.bootstrap, Updated: now}
+	c.known = true
+	c.etag = resp.etag
+	c.hash = resp.hash
+
+	if c.cache != nil && changed {
+		entry := cacheEntry{
+			Bootstrap: resp.bootstrap,
+			Updated:   now,
+			ETag:      resp.etag,
+			Hash:      resp.hash,
+		}
+		if err := c.cache.save(entry); err != nil {
+			return c.state, changed, err
+		}
+	}
+
+	return c.state, changed, nil
+}
+
+type response struct {
+	bootstrap   Bootstrap
+	etag        string
+	hash        string
+	notModified bool
+}
+
+func (c *client) request(ctx context.Context, etag string) (response, error) {
+	path, key := bootstrapPath, c.cfg.ExternalKey
+	if c.block != nil {
+		enc, err := encrypt(c.block, []byte(key))
+		if err != nil {
+			return response{}, errors.Wrap(ErrFetch, err)
+		}
+		path, key = secureBootstrapPath, hex.EncodeToString(enc)
+	}
+
+	url := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(c.cfg.URL, "/"), path, c.cfg.ExternalID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return response{}, errors.Wrap(ErrFetch, err)
+	}
+	req.Header.Set("Authorization", key)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return response{}, errors.Wrap(ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return response{notModified: true}, nil
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return response{}, errors.Wrap(ErrUnavailable, errors.New(resp.Status))
+	case resp.StatusCode != http.StatusOK:
+		return response{}, errors.Wrap(ErrFetch, errors.New(resp.Status))
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return response{}, errors.Wrap(ErrUnavailable, err)
+	}
+	if c.block != nil {
+		if body, err = decrypt(c.block, body); err != nil {
+			return response{}, errors.Wrap(ErrFetch, err)
+		}
+	}
+
+	var bs Bootstrap
+	if err := json.Unmarshal(body, &bs); err != nil {
+		return response{}, errors.Wrap(ErrFetch, err)
+	}
+
+	// Changes are detected using the decoded configuration, since the
+	// secure responses differ on each request.
+	data, err := json.Marshal(bs)
+	if err != nil {
+		return response{}, errors.Wrap(ErrFetch, err)
+	}
+	sum := sha256.Sum256(data)
+
+	return response{
+		bootstrap: bs,
+		etag:      resp.Header.Get("ETag"),
+		hash:      hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// encrypt encrypts data using AES CFB the same way bootstrap service does,
+// prepending the random IV to the ciphertext.
+func encrypt(block cipher.Block, in []byte) ([]byte, error) {
+	ciphertext := make([]byte, aes.BlockSize+len(in))
+	iv := ciphertext[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	stream := cipher.NewCFBEncrypter(block, iv)
+	stream.XORKeyStream(ciphertext[aes.BlockSize:], in)
+	return ciphertext, nil
+}
+
+func decrypt(block cipher.Block, in []byte) ([]byte, error) {
+	if len(in) < aes.BlockSize {
+		return nil, errDecrypt
+	}
+	iv := in[:aes.BlockSize]
+	out := make([]byte, len(in)-aes.BlockSize)
+	stream := cipher.NewCFBDecrypter(block, iv)
+	stream.XORKeyStream(out, in[aes.BlockSize:])
+	return out, nil
+}
@@ -0,0 +1,350 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package client_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/bootstrap/client"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	externalID  = "external-id"
+	externalKey = "external-key"
+	encKey      = "v7aT0HGxJxt2gULzr3RHwf4WIf6DusPp"
+)
+
+var config = client.Bootstrap{
+	ThingID:  "thing-id",
+	ThingKey: "thing-key",
+	Channels: []client.Channel{
+		{ID: "ctrl-id", Name: "control", Metadata: map[string]interface{}{"type": "control"}},
+		{ID: "data-id", Name: "data"},
+	},
+	Content: `{"interval": 10}`,
+}
+
+// server is the bootstrap service serving a single configuration.
+type server struct {
+	mu       sync.Mutex
+	config   client.Bootstrap
+	etags    bool
+	block    cipher.Block
+	status   int
+	requests []*http.Request
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r)
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+
+	path, key := "/things/bootstrap/"+externalID, r.Header.Get("Authorization")
+	if s.block != nil {
+		path = "/things/bootstrap/secure/" + externalID
+		enc, err := hex.DecodeString(key)
+		if err != nil || len(enc) < aes.BlockSize {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		key = string(decrypt(s.block, enc[:aes.BlockSize], enc[aes.BlockSize:]))
+	}
+	if r.URL.Path != path {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if key != externalKey {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	body, _ := json.Marshal(s.config)
+	if s.etags {
+		sum := sha256.Sum256(body)
+		etag := fmt.Sprintf(`"%x"`, sum[:8])
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+	}
+	if s.block != nil {
+		iv := make([]byte, aes.BlockSize)
+		io.ReadFull(rand.Reader, iv)
+		out := make([]byte, len(body))
+		cipher.NewCFBEncrypter(s.block, iv).XORKeyStream(out, body)
+		body = append(iv, out...)
+	}
+	w.Write(body)
+}
+
+func (s *server) set(cfg client.Bootstrap) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = cfg
+}
+
+func (s *server) fail(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *server) last() *http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+func decrypt(block cipher.Block, iv, in []byte) []byte {
+	out := make([]byte, len(in))
+	cipher.NewCFBDecrypter(block, iv).XORKeyStream(out, in)
+	return out
+}
+
+func newServer(t *testing.T, etags, secure bool) (*server, *httptest.Server) {
+	s := &server{config: config, etags: etags}
+	if secure {
+		block, err := aes.NewCipher([]byte(encKey))
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		s.block = block
+	}
+	return s, httptest.NewServer(s)
+}
+
+func newClient(t *testing.T, url, cache string, secure bool) client.Client {
+	cfg := client.Config{
+		URL:         url,
+		ExternalID:  externalID,
+		ExternalKey: externalKey,
+		CachePath:   cache,
+		Interval:    10 * time.Millisecond,
+	}
+	if secure {
+		cfg.EncKey = []byte(encKey)
+	}
+	c, err := client.New(cfg)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return c
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "bootstrap-client")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return dir
+}
+
+func TestFetch(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		desc   string
+		etags  bool
+		secure bool
+	}{
+		{desc: "fetch config", etags: true},
+		{desc: "fetch config without entity tags", etags: false},
+		{desc: "fetch secure config", etags: true, secure: true},
+	}
+
+	for i, tc := range cases {
+		srv, ts := newServer(t, tc.etags, tc.secure)
+		c := newClient(t, ts.URL, filepath.Join(dir, fmt.Sprintf("cache-%d.json", i)), tc.secure)
+
+		_, ok := c.State()
+		assert.False(t, ok, fmt.Sprintf("%s: expected no config before the first fetch", tc.desc))
+
+		s, err := c.Fetch(context.Background())
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, config, s.Bootstrap, fmt.Sprintf("%s: expected %v got %v", tc.desc, config, s.Bootstrap))
+		assert.False(t, s.Stale, fmt.Sprintf("%s: expected fresh config", tc.desc))
+		assert.Empty(t, srv.last().Header.Get("If-None-Match"), fmt.Sprintf("%s: expected unconditional first request", tc.desc))
+
+		ch, ok := s.Channel("data")
+		assert.True(t, ok, fmt.Sprintf("%s: expected data channel", tc.desc))
+		assert.Equal(t, "data-id", ch.ID, fmt.Sprintf("%s: expected data channel ID data-id got %s", tc.desc, ch.ID))
+		var content struct {
+			Interval int `json:"interval"`
+		}
+		require.Nil(t, s.DecodeContent(&content), fmt.Sprintf("%s: unexpected content decoding error", tc.desc))
+		assert.Equal(t, 10, content.Interval, fmt.Sprintf("%s: expected interval 10 got %d", tc.desc, content.Interval))
+
+		// The second fetch is conditional if the service sends entity tags.
+		updated := s.Updated
+		s, err = c.Fetch(context.Background())
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, config, s.Bootstrap, fmt.Sprintf("%s: expected %v got %v", tc.desc, config, s.Bootstrap))
+		assert.False(t, s.Updated.Before(updated), fmt.Sprintf("%s: expected update time to advance", tc.desc))
+		assert.Equal(t, tc.etags, srv.last().Header.Get("If-None-Match") != "", fmt.Sprintf("%s: expected conditional request %t", tc.desc, tc.etags))
+
+		ts.Close()
+	}
+}
+
+func TestFetchNotModified(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	cache := filepath.Join(dir, "cache.json")
+
+	srv, ts := newServer(t, true, false)
+	defer ts.Close()
+
+	c := newClient(t, ts.URL, cache, false)
+	_, err := c.Fetch(context.Background())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	// The restarted client uses the entity tag of the cached config.
+	c = newClient(t, ts.URL, cache, false)
+	s, ok := c.State()
+	assert.True(t, ok, "expected cached config")
+	assert.True(t, s.Stale, "expected cached config to be stale")
+
+	s, err = c.Fetch(context.Background())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.NotEmpty(t, srv.last().Header.Get("If-None-Match"), "expected conditional request")
+	assert.False(t, s.Stale, "expected config confirmed by the service")
+	assert.Equal(t, config, s.Bootstrap, fmt.Sprintf("expected %v got %v", config, s.Bootstrap))
+}
+
+func TestFetchOffline(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		desc   string
+		secure bool
+		status int
+		cached bool
+		err    error
+	}{
+		{desc: "fetch from unreachable service with cache", cached: true},
+		{desc: "fetch secure config from unreachable service with cache", secure: true, cached: true},
+		{desc: "fetch from failing service with cache", status: http.StatusServiceUnavailable, cached: true},
+		{desc: "fetch from unreachable service without cache", err: client.ErrNoConfig},
+		{desc: "fetch rejected by service with cache", status: http.StatusForbidden, cached: true, err: client.ErrFetch},
+	}
+
+	for i, tc := range cases {
+		cache := filepath.Join(dir, fmt.Sprintf("cache-%d.json", i))
+		srv, ts := newServer(t, true, tc.secure)
+		if tc.cached {
+			_, err := newClient(t, ts.URL, cache, tc.secure).Fetch(context.Background())
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		}
+		if tc.status != 0 {
+			srv.fail(tc.status)
+		} else {
+			ts.Close()
+		}
+
+		c := newClient(t, ts.URL, cache, tc.secure)
+		s, err := c.Fetch(context.Background())
+		ts.Close()
+		if tc.err != nil {
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+			continue
+		}
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.True(t, s.Stale, fmt.Sprintf("%s: expected stale config", tc.desc))
+		assert.True(t, errors.Contains(s.Err, client.ErrUnavailable), fmt.Sprintf("%s: expected stale reason %s got %s", tc.desc, client.ErrUnavailable, s.Err))
+		assert.Equal(t, config, s.Bootstrap, fmt.Sprintf("%s: expected %v got %v", tc.desc, config, s.Bootstrap))
+	}
+}
+
+func TestCacheEncrypted(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	cache := filepath.Join(dir, "cache.json")
+
+	_, ts := newServer(t, true, true)
+	defer ts.Close()
+	_, err := newClient(t, ts.URL, cache, true).Fetch(context.Background())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	data, err := ioutil.ReadFile(cache)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.False(t, strings.Contains(string(data), config.ThingKey), "expected thing key not to be stored in plain text")
+
+	_, err = client.New(client.Config{URL: ts.URL, ExternalID: externalID, ExternalKey: externalKey, CachePath: cache})
+	assert.True(t, errors.Contains(err, client.ErrCache), fmt.Sprintf("expected error %s got %s", client.ErrCache, err))
+}
+
+func TestWatch(t *testing.T) {
+	cases := []struct {
+		desc  string
+		etags bool
+	}{
+		{desc: "watch config changes", etags: true},
+		{desc: "watch config changes without entity tags", etags: false},
+	}
+
+	for _, tc := range cases {
+		srv, ts := newServer(t, tc.etags, false)
+		c := newClient(t, ts.URL, "", false)
+		_, err := c.Fetch(context.Background())
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		changes := make(chan client.State, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- c.Watch(ctx, func(s client.State) { changes <- s })
+		}()
+
+		// Unchanged config is not reported.
+		select {
+		case s := <-changes:
+			assert.Fail(t, fmt.Sprintf("%s: unexpected change %v", tc.desc, s.Bootstrap))
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		updated := config
+		updated.Content = `{"interval": 20}`
+		srv.set(updated)
+		select {
+		case s := <-changes:
+			assert.Equal(t, updated, s.Bootstrap, fmt.Sprintf("%s: expected %v got %v", tc.desc, updated, s.Bootstrap))
+		case <-time.After(time.Second):
+			assert.Fail(t, fmt.Sprintf("%s: expected config change", tc.desc))
+		}
+
+		// Service outage is not reported as a change.
+		srv.fail(http.StatusBadGateway)
+		time.Sleep(50 * time.Millisecond)
+		assert.Len(t, changes, 0, fmt.Sprintf("%s: expected no changes during outage", tc.desc))
+		s, ok := c.State()
+		assert.True(t, ok && s.Stale, fmt.Sprintf("%s: expected stale config during outage", tc.desc))
+
+		cancel()
+		err = <-done
+		assert.True(t, errors.Contains(err, context.Canceled), fmt.Sprintf("%s: expected error %s got %s", tc.desc, context.Canceled, err))
+		ts.Close()
+	}
+}
@@ -42,6 +42,21 @@ func (ce *customError) Err() Error {
 	return ce.err
 }
 
+// Unwrap returns wrapped error, which makes it usable with the standard
+// library errors.Is and errors.As functions.
+func (ce *customError) Unwrap() error {
+	if ce.err == nil {
+		return nil
+	}
+	return ce.err
+}
+
+// Is reports whether the target is an unwrapped Error with the same message.
+func (ce *customError) Is(target error) bool {
+	t, ok := target.(Error)
+	return ok && t.Err() == nil && ce.msg == t.Msg()
+}
+
 // Contains inspects if e2 error is contained in any layer of e1 error
 func Contains(e1 error, e2 error) bool {
 	if e1 == nil || e2 == nil {
@@ -62,6 +77,10 @@ func Wrap(wrapper error, err error) error {
 	if wrapper == nil || err == nil {
 		return wrapper
 	}
+	// Wrapper carrying additional data (e.g. error code) wraps err itself.
+	if w, ok := wrapper.(interface{ WithCause(error) error }); ok {
+		return w.WithCause(err)
+	}
 	if w, ok := wrapper.(Error); ok {
 		return &customError{
 			msg: w.Msg(),
@@ -4,6 +4,7 @@
 package errors_test
 
 import (
+	goerrors "errors"
 	"fmt"
 	"strconv"
 	"testing"
@@ -183,6 +184,51 @@ func TestWrap(t *testing.T) {
 	}
 }
 
+func TestIs(t *testing.T) {
+	cases := []struct {
+		desc   string
+		err    error
+		target error
+		is     bool
+	}{
+		{
+			desc:   "err0 is err0",
+			err:    err0,
+			target: err0,
+			is:     true,
+		},
+		{
+			desc:   "res of errors.Wrap(err1, err0) is err1",
+			err:    errors.Wrap(err1, err0),
+			target: err1,
+			is:     true,
+		},
+		{
+			desc:   "res of errors.Wrap(err2, errors.Wrap(err1, err0)) is err0",
+			err:    errors.Wrap(err2, errors.Wrap(err1, err0)),
+			target: err0,
+			is:     true,
+		},
+		{
+			desc:   "res of errors.Wrap(err1, err0) is not err2",
+			err:    errors.Wrap(err1, err0),
+			target: err2,
+			is:     false,
+		},
+		{
+			desc:   "err1 is not wrapper error with the same message",
+			err:    err1,
+			target: errors.Wrap(err1, err0),
+			is:     false,
+		},
+	}
+
+	for _, tc := range cases {
+		is := goerrors.Is(tc.err, tc.target)
+		assert.Equal(t, tc.is, is, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.is, is))
+	}
+}
+
 func wrap(level int) error {
 	if level == 0 {
 		return errors.New(strconv.Itoa(level))
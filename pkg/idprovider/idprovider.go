@@ -0,0 +1,56 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package idprovider selects one of the supported identity providers and
+// validates IDs generated by any of them.
+package idprovider
+
+import (
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/snowflake"
+	"github.com/mainflux/mainflux/pkg/ulid"
+	"github.com/mainflux/mainflux/pkg/uuid"
+)
+
+const (
+	// UUID is the name of the UUIDv4 identity provider.
+	UUID = "uuid"
+	// ULID is the name of the ULID identity provider.
+	ULID = "ulid"
+	// Snowflake is the name of the snowflake identity provider.
+	Snowflake = "snowflake"
+)
+
+var (
+	// ErrUnsupportedProvider indicates unknown identity provider name.
+	ErrUnsupportedProvider = errors.New("unsupported id provider")
+
+	// ErrInvalidID indicates that the ID is not generated by any of the
+	// supported identity providers.
+	ErrInvalidID = errors.New("invalid id")
+)
+
+// New returns the identity provider with the given name. Node ID is used
+// only by the snowflake provider.
+func New(name string, node int64) (mainflux.IDProvider, error) {
+	switch name {
+	case UUID:
+		return uuid.New(), nil
+	case ULID:
+		return ulid.New(), nil
+	case Snowflake:
+		return snowflake.New(node)
+	default:
+		return nil, errors.Wrap(ErrUnsupportedProvider, errors.New(name))
+	}
+}
+
+// Validate checks if the given string is UUID, ULID or snowflake ID.
+func Validate(id string) error {
+	if uuid.Validate(id) == nil || ulid.Validate(id) == nil || snowflake.Validate(id) == nil {
+		return nil
+	}
+
+	return ErrInvalidID
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package idprovider_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/idprovider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		desc string
+		name string
+		node int64
+		err  error
+	}{
+		{
+			desc: "create UUID provider",
+			name: idprovider.UUID,
+		},
+		{
+			desc: "create ULID provider",
+			name: idprovider.ULID,
+		},
+		{
+			desc: "create snowflake provider",
+			name: idprovider.Snowflake,
+			node: 7,
+		},
+		{
+			desc: "create snowflake provider with invalid node",
+			name: idprovider.Snowflake,
+			node: -1,
+			err:  errors.New("invalid node id"),
+		},
+		{
+			desc: "create unsupported provider",
+			name: "sequence",
+			err:  idprovider.ErrUnsupportedProvider,
+		},
+	}
+
+	for _, tc := range cases {
+		idp, err := idprovider.New(tc.name, tc.node)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		if err != nil {
+			continue
+		}
+		id, err := idp.ID()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Nil(t, idprovider.Validate(id), fmt.Sprintf("%s: expected generated ID %s to be valid", tc.desc, id))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		desc string
+		id   string
+		err  error
+	}{
+		{
+			desc: "validate UUID",
+			id:   "123e4567-e89b-12d3-a456-000000000001",
+		},
+		{
+			desc: "validate ULID",
+			id:   "01EWW6K5KSA4YV1BXBQ0YQZEDK",
+		},
+		{
+			desc: "validate snowflake ID",
+			id:   "0808493096173572096",
+		},
+		{
+			desc: "validate empty ID",
+			id:   "",
+			err:  idprovider.ErrInvalidID,
+		},
+		{
+			desc: "validate malformed ID",
+			id:   "not-an-id",
+			err:  idprovider.ErrInvalidID,
+		},
+	}
+
+	for _, tc := range cases {
+		err := idprovider.Validate(tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
+
+func benchmarkID(b *testing.B, name string) {
+	idp, err := idprovider.New(name, 1)
+	require.Nil(b, err, fmt.Sprintf("unexpected error: %s", err))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := idp.ID(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkParallelID(b *testing.B, name string) {
+	idp, err := idprovider.New(name, 1)
+	require.Nil(b, err, fmt.Sprintf("unexpected error: %s", err))
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := idp.ID(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkUUID(b *testing.B)              { benchmarkID(b, idprovider.UUID) }
+func BenchmarkULID(b *testing.B)              { benchmarkID(b, idprovider.ULID) }
+func BenchmarkSnowflake(b *testing.B)         { benchmarkID(b, idprovider.Snowflake) }
+func BenchmarkUUIDParallel(b *testing.B)      { benchmarkParallelID(b, idprovider.UUID) }
+func BenchmarkULIDParallel(b *testing.B)      { benchmarkParallelID(b, idprovider.ULID) }
+func BenchmarkSnowflakeParallel(b *testing.B) { benchmarkParallelID(b, idprovider.Snowflake) }
@@ -0,0 +1,484 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jsonschema implements validation of decoded JSON values against
+// JSON Schema documents. It supports the subset of the validation keywords
+// that don't need resolving of other schemas: type, enum, const, properties,
+// required, additionalProperties, items, minItems, maxItems, minimum,
+// maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, minLength,
+// maxLength and pattern. Annotation keywords, such as title and description,
+// are ignored, while schemas using any other keyword, e.g. references or
+// combinators such as allOf, are rejected, so they're never silently ignored.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var (
+	// ErrSchema indicates malformed or unsupported schema.
+	ErrSchema = errors.New("invalid JSON schema")
+
+	// ErrViolation indicates the value not matching the schema.
+	ErrViolation = errors.New("value violates JSON schema")
+
+	// ErrUnsupportedKeyword indicates the schema using the keyword that isn't
+	// implemented. It's always wrapped by ErrSchema.
+	ErrUnsupportedKeyword = errors.New("unsupported JSON schema keyword")
+)
+
+// keywords contains the supported keywords. The annotations are accepted,
+// but don't affect the validation.
+var keywords = map[string]bool{
+	"type":                 true,
+	"enum":                 true,
+	"const":                true,
+	"properties":           true,
+	"required":             true,
+	"additionalProperties": true,
+	"items":                true,
+	"minItems":             true,
+	"maxItems":             true,
+	"minimum":              true,
+	"maximum":              true,
+	"exclusiveMinimum":     true,
+	"exclusiveMaximum":     true,
+	"multipleOf":           true,
+	"minLength":            true,
+	"maxLength":            true,
+	"pattern":              true,
+
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"default":     true,
+	"examples":    true,
+	"deprecated":  true,
+	"readOnly":    true,
+	"writeOnly":   true,
+}
+
+var types = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"integer": true,
+	"string":  true,
+}
+
+// Schema represents the compiled JSON schema.
+type Schema struct {
+	always     *bool
+	types      []string
+	enum       []interface{}
+	constant   interface{}
+	hasConst   bool
+	properties map[string]*Schema
+	required   []string
+	additional *Schema
+	items      *Schema
+	minItems   *int
+	maxItems   *int
+	minimum    *float64
+	maximum    *float64
+	exclMin    *float64
+	exclMax    *float64
+	multipleOf *float64
+	minLength  *int
+	maxLength  *int
+	pattern    *regexp.Regexp
+}
+
+// Compile compiles the JSON encoded schema.
+func Compile(data []byte) (*Schema, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrap(ErrSchema, err)
+	}
+	return compile(v, "")
+}
+
+func compile(v interface{}, path string) (*Schema, error) {
+	if b, ok := v.(bool); ok {
+		return &Schema{always: &b}, nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, schemaErr(path, "schema must be an object or a boolean")
+	}
+
+	// Keywords are checked in order so the reported one is stable.
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !keywords[name] {
+			return nil, unsupportedErr(path, name)
+		}
+	}
+
+	s := &Schema{}
+	var err error
+	if s.types, err = compileTypes(obj["type"], path); err != nil {
+		return nil, err
+	}
+	if e, ok := obj["enum"]; ok {
+		if s.enum, ok = e.([]interface{}); !ok {
+			return nil, schemaErr(path, "enum must be an array")
+		}
+	}
+	s.constant, s.hasConst = obj["const"]
+
+	if p, ok := obj["properties"]; ok {
+		props, ok := p.(map[string]interface{})
+		if !ok {
+			return nil, schemaErr(path, "properties must be an object")
+		}
+		s.properties = make(map[string]*Schema, len(props))
+		for name, prop := range props {
+			if s.properties[name], err = compile(prop, path+"/properties/"+name); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if r, ok := obj["required"]; ok {
+		req, ok := r.([]interface{})
+		if !ok {
+			return nil, schemaErr(path, "required must be an array of strings")
+		}
+		for _, name := range req {
+			n, ok := name.(string)
+			if !ok {
+				return nil, schemaErr(path, "required must be an array of strings")
+			}
+			s.required = append(s.required, n)
+		}
+	}
+	if a, ok := obj["additionalProperties"]; ok {
+		if s.additional, err = compile(a, path+"/additionalProperties"); err != nil {
+			return nil, err
+		}
+	}
+	if i, ok := obj["items"]; ok {
+		if s.items, err = compile(i, path+"/items"); err != nil {
+			return nil, err
+		}
+	}
+
+	ints := []struct {
+		key string
+		dst **int
+	}{
+		{"minItems", &s.minItems},
+		{"maxItems", &s.maxItems},
+		{"minLength", &s.minLength},
+		{"maxLength", &s.maxLength},
+	}
+	for _, i := range ints {
+		if *i.dst, err = compileCount(obj, i.key, path); err != nil {
+			return nil, err
+		}
+	}
+
+	nums := []struct {
+		key string
+		dst **float64
+	}{
+		{"minimum", &s.minimum},
+		{"maximum", &s.maximum},
+		{"exclusiveMinimum", &s.exclMin},
+		{"exclusiveMaximum", &s.exclMax},
+		{"multipleOf", &s.multipleOf},
+	}
+	for _, n := range nums {
+		if *n.dst, err = compileNumber(obj, n.key, path); err != nil {
+			return nil, err
+		}
+	}
+	if s.multipleOf != nil && *s.multipleOf <= 0 {
+		return nil, schemaErr(path, "multipleOf must be greater than 0")
+	}
+
+	if p, ok := obj["pattern"]; ok {
+		pattern, ok := p.(string)
+		if !ok {
+			return nil, schemaErr(path, "pattern must be a string")
+		}
+		if s.pattern, err = regexp.Compile(pattern); err != nil {
+			return nil, schemaErr(path, fmt.Sprintf("invalid pattern: %s", err))
+		}
+	}
+
+	return s, nil
+}
+
+func compileTypes(v interface{}, path string) ([]string, error) {
+	var names []interface{}
+	switch t := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		names = []interface{}{t}
+	case []interface{}:
+		names = t
+	default:
+		return nil, schemaErr(path, "type must be a string or an array of strings")
+	}
+
+	ret := make([]string, len(names))
+	for i, n := range names {
+		name, ok := n.(string)
+		if !ok || !types[name] {
+			return nil, schemaErr(path, fmt.Sprintf("unknown type %v", n))
+		}
+		ret[i] = name
+	}
+	return ret, nil
+}
+
+func compileCount(obj map[string]interface{}, key, path string) (*int, error) {
+	v, ok := obj[key]
+	if !ok {
+		return nil, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return nil, schemaErr(path, fmt.Sprintf("%s must be a non-negative integer", key))
+	}
+	i := int(f)
+	return &i, nil
+}
+
+func compileNumber(obj map[string]interface{}, key, path string) (*float64, error) {
+	v, ok := obj[key]
+	if !ok {
+		return nil, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, schemaErr(path, fmt.Sprintf("%s must be a number", key))
+	}
+	return &f, nil
+}
+
+// Validate validates the value decoded using the encoding/json package,
+// so the numbers are expected to be float64 values. The returned error
+// describes the first violation found.
+func (s *Schema) Validate(v interface{}) error {
+	return s.validate(v, "")
+}
+
+func (s *Schema) validate(v interface{}, path string) error {
+	if s.always != nil {
+		if !*s.always {
+			return violation(path, "no value is allowed")
+		}
+		return nil
+	}
+
+	if len(s.types) > 0 && !s.hasType(v) {
+		return violation(path, fmt.Sprintf("expected %v, got %s", s.types, typeOf(v)))
+	}
+	if s.enum != nil && !contains(s.enum, v) {
+		return violation(path, "value is not one of the enumerated values")
+	}
+	if s.hasConst && !equal(s.constant, v) {
+		return violation(path, "value doesn't match the constant")
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return s.validateObject(val, path)
+	case []interface{}:
+		return s.validateArray(val, path)
+	case float64:
+		return s.validateNumber(val, path)
+	case string:
+		return s.validateString(val, path)
+	}
+	return nil
+}
+
+func (s *Schema) validateObject(obj map[string]interface{}, path string) error {
+	for _, name := range s.required {
+		if _, ok := obj[name]; !ok {
+			return violation(path, fmt.Sprintf("missing required property %q", name))
+		}
+	}
+
+	// Properties are checked in order so the reported violation is stable.
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, ok := s.properties[name]
+		if !ok {
+			prop = s.additional
+		}
+		if prop == nil {
+			continue
+		}
+		if err := prop.validate(obj[name], path+"/"+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateArray(arr []interface{}, path string) error {
+	if s.minItems != nil && len(arr) < *s.minItems {
+		return violation(path, fmt.Sprintf("expected at least %d items, got %d", *s.minItems, len(arr)))
+	}
+	if s.maxItems != nil && len(arr) > *s.maxItems {
+		return violation(path, fmt.Sprintf("expected at most %d items, got %d", *s.maxItems, len(arr)))
+	}
+	if s.items == nil {
+		return nil
+	}
+	for i, item := range arr {
+		if err := s.items.validate(item, fmt.Sprintf("%s/%d", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateNumber(n float64, path string) error {
+	switch {
+	case s.minimum != nil && n < *s.minimum:
+		return violation(path, fmt.Sprintf("%v is less than the minimum %v", n, *s.minimum))
+	case s.maximum != nil && n > *s.maximum:
+		return violation(path, fmt.Sprintf("%v is greater than the maximum %v", n, *s.maximum))
+	case s.exclMin != nil && n <= *s.exclMin:
+		return violation(path, fmt.Sprintf("%v is not greater than %v", n, *s.exclMin))
+	case s.exclMax != nil && n >= *s.exclMax:
+		return violation(path, fmt.Sprintf("%v is not less than %v", n, *s.exclMax))
+	case s.multipleOf != nil && !isInteger(n / *s.multipleOf):
+		return violation(path, fmt.Sprintf("%v is not a multiple of %v", n, *s.multipleOf))
+	}
+	return nil
+}
+
+func (s *Schema) validateString(str string, path string) error {
+	// Length is the number of characters, not bytes.
+	l := utf8.RuneCountInString(str)
+	switch {
+	case s.minLength != nil && l < *s.minLength:
+		return violation(path, fmt.Sprintf("expected at least %d characters, got %d", *s.minLength, l))
+	case s.maxLength != nil && l > *s.maxLength:
+		return violation(path, fmt.Sprintf("expected at most %d characters, got %d", *s.maxLength, l))
+	case s.pattern != nil && !s.pattern.MatchString(str):
+		return violation(path, fmt.Sprintf("value doesn't match the pattern %s", s.pattern))
+	}
+	return nil
+}
+
+func (s *Schema) hasType(v interface{}) bool {
+	actual := typeOf(v)
+	for _, t := range s.types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case float64:
+		if isInteger(val) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func isInteger(f float64) bool {
+	return !math.IsInf(f, 0) && f == math.Trunc(f)
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	for _, val := range values {
+		if equal(val, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			if w, ok := bv[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func schemaErr(path, msg string) error {
+	return errors.Wrap(ErrSchema, errors.New(fmt.Sprintf("%s: %s", pointer(path), msg)))
+}
+
+func unsupportedErr(path, keyword string) error {
+	err := errors.Wrap(ErrUnsupportedKeyword, errors.New(fmt.Sprintf("%s: %s", pointer(path), keyword)))
+	return errors.Wrap(ErrSchema, err)
+}
+
+func violation(path, msg string) error {
+	return errors.Wrap(ErrViolation, errors.New(fmt.Sprintf("%s: %s", pointer(path), msg)))
+}
+
+// pointer returns the JSON pointer of the location, using "/" for the root.
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
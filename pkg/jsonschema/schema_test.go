@@ -0,0 +1,143 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reading = `{
+	"type": "object",
+	"required": ["temperature", "unit"],
+	"properties": {
+		"temperature": {"type": "number", "minimum": -50, "exclusiveMaximum": 150},
+		"unit": {"enum": ["C", "F"]},
+		"sensor": {"type": "string", "minLength": 2, "maxLength": 8, "pattern": "^[a-z]+-[0-9]+$"},
+		"count": {"type": "integer", "multipleOf": 2},
+		"tags": {"type": "array", "items": {"type": "string"}, "minItems": 1, "maxItems": 2},
+		"version": {"const": 1},
+		"location": {
+			"type": "object",
+			"properties": {"lat": {"type": "number"}, "lon": {"type": "number"}},
+			"additionalProperties": false
+		}
+	}
+}`
+
+func TestCompile(t *testing.T) {
+	cases := []struct {
+		desc   string
+		schema string
+		err    error
+	}{
+		{desc: "compile schema", schema: reading},
+		{desc: "compile empty schema", schema: `{}`},
+		{desc: "compile boolean schema", schema: `true`},
+		{desc: "compile schema with annotations", schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "title": "reading"}`},
+		{desc: "compile schema with multiple types", schema: `{"type": ["string", "null"]}`},
+		{desc: "compile malformed JSON", schema: `{"type":`, err: jsonschema.ErrSchema},
+		{desc: "compile non-object schema", schema: `[]`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with unknown type", schema: `{"type": "float"}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with invalid type", schema: `{"type": 1}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with reference", schema: `{"properties": {"a": {"$ref": "#/definitions/a"}}}`, err: jsonschema.ErrUnsupportedKeyword},
+		{desc: "compile schema with allOf", schema: `{"allOf": [{"type": "object"}, {"required": ["a"]}]}`, err: jsonschema.ErrUnsupportedKeyword},
+		{desc: "compile schema with anyOf", schema: `{"anyOf": [{"type": "string"}, {"type": "number"}]}`, err: jsonschema.ErrUnsupportedKeyword},
+		{desc: "compile schema with oneOf", schema: `{"oneOf": [{"minimum": 0}, {"maximum": 10}]}`, err: jsonschema.ErrUnsupportedKeyword},
+		{desc: "compile schema with not", schema: `{"not": {"type": "null"}}`, err: jsonschema.ErrUnsupportedKeyword},
+		{desc: "compile schema with nested unsupported keyword", schema: `{"properties": {"a": {"if": {"type": "string"}}}}`, err: jsonschema.ErrUnsupportedKeyword},
+		{desc: "compile schema with unknown keyword", schema: `{"patternProperties": {"^a": {"type": "string"}}}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with misspelled keyword", schema: `{"requried": ["a"]}`, err: jsonschema.ErrUnsupportedKeyword},
+		{desc: "compile schema with invalid properties", schema: `{"properties": []}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with invalid required", schema: `{"required": [1]}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with negative length", schema: `{"minLength": -1}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with fractional items count", schema: `{"maxItems": 1.5}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with invalid minimum", schema: `{"minimum": "0"}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with zero multiple", schema: `{"multipleOf": 0}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with invalid pattern", schema: `{"pattern": "["}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with invalid enum", schema: `{"enum": "C"}`, err: jsonschema.ErrSchema},
+		{desc: "compile schema with invalid nested schema", schema: `{"items": 1}`, err: jsonschema.ErrSchema},
+	}
+
+	for _, tc := range cases {
+		_, err := jsonschema.Compile([]byte(tc.schema))
+		if tc.err == nil {
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			continue
+		}
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s, err := jsonschema.Compile([]byte(reading))
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		value string
+		valid bool
+	}{
+		{desc: "validate minimal value", value: `{"temperature": 21.5, "unit": "C"}`, valid: true},
+		{desc: "validate full value", value: `{"temperature": -50, "unit": "F", "sensor": "th-1", "count": 4, "tags": ["a"], "version": 1, "location": {"lat": 45.2, "lon": 19.8}, "extra": null}`, valid: true},
+		{desc: "validate non-object value", value: `[{"temperature": 21.5, "unit": "C"}]`},
+		{desc: "validate value without required property", value: `{"temperature": 21.5}`},
+		{desc: "validate value with wrong type", value: `{"temperature": "21.5", "unit": "C"}`},
+		{desc: "validate value below minimum", value: `{"temperature": -50.1, "unit": "C"}`},
+		{desc: "validate value equal to exclusive maximum", value: `{"temperature": 150, "unit": "C"}`},
+		{desc: "validate value not enumerated", value: `{"temperature": 21.5, "unit": "K"}`},
+		{desc: "validate too short string", value: `{"temperature": 21.5, "unit": "C", "sensor": "t"}`},
+		{desc: "validate too long string", value: `{"temperature": 21.5, "unit": "C", "sensor": "thermo-123"}`},
+		{desc: "validate string not matching pattern", value: `{"temperature": 21.5, "unit": "C", "sensor": "th1"}`},
+		{desc: "validate fractional integer", value: `{"temperature": 21.5, "unit": "C", "count": 2.5}`},
+		{desc: "validate integer not a multiple", value: `{"temperature": 21.5, "unit": "C", "count": 3}`},
+		{desc: "validate empty array", value: `{"temperature": 21.5, "unit": "C", "tags": []}`},
+		{desc: "validate too long array", value: `{"temperature": 21.5, "unit": "C", "tags": ["a", "b", "c"]}`},
+		{desc: "validate array with invalid item", value: `{"temperature": 21.5, "unit": "C", "tags": ["a", 1]}`},
+		{desc: "validate value not matching constant", value: `{"temperature": 21.5, "unit": "C", "version": 2}`},
+		{desc: "validate additional nested property", value: `{"temperature": 21.5, "unit": "C", "location": {"lat": 45.2, "alt": 80}}`},
+	}
+
+	for _, tc := range cases {
+		var v interface{}
+		require.Nil(t, json.Unmarshal([]byte(tc.value), &v), fmt.Sprintf("%s: unexpected decoding error", tc.desc))
+		err := s.Validate(v)
+		if tc.valid {
+			assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+			continue
+		}
+		assert.True(t, errors.Contains(err, jsonschema.ErrViolation), fmt.Sprintf("%s: expected error %s got %s", tc.desc, jsonschema.ErrViolation, err))
+	}
+}
+
+func TestValidateEdgeCases(t *testing.T) {
+	cases := []struct {
+		desc   string
+		schema string
+		value  interface{}
+		valid  bool
+	}{
+		{desc: "validate using true schema", schema: `true`, value: "value", valid: true},
+		{desc: "validate using false schema", schema: `false`, value: "value"},
+		{desc: "validate property using false schema", schema: `{"properties": {"a": false}}`, value: map[string]interface{}{"a": 1.0}},
+		{desc: "validate missing property using false schema", schema: `{"properties": {"a": false}}`, value: map[string]interface{}{"b": 1.0}, valid: true},
+		{desc: "validate integer as number", schema: `{"type": "number"}`, value: 1.0, valid: true},
+		{desc: "validate null using multiple types", schema: `{"type": ["string", "null"]}`, value: nil, valid: true},
+		{desc: "validate object constant", schema: `{"const": {"a": [1, "b"]}}`, value: map[string]interface{}{"a": []interface{}{1.0, "b"}}, valid: true},
+		{desc: "validate mismatched object constant", schema: `{"const": {"a": [1, "b"]}}`, value: map[string]interface{}{"a": []interface{}{1.0}}},
+	}
+
+	for _, tc := range cases {
+		s, err := jsonschema.Compile([]byte(tc.schema))
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		err = s.Validate(tc.value)
+		assert.Equal(t, tc.valid, err == nil, fmt.Sprintf("%s: expected valid %t got error %s", tc.desc, tc.valid, err))
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fanout contains the subscriber sharing a single broker
+// subscription among all the adapter clients observing the same topic.
+package fanout
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+const defQueueSize = 64
+
+var (
+	// ErrEmptyID indicates the client attached without the ID.
+	ErrEmptyID = errors.New("empty client id")
+
+	// ErrNotAttached indicates the client isn't attached to the topic.
+	ErrNotAttached = errors.New("client not attached to topic")
+)
+
+// Subscriber attaches the clients to the topics, keeping a single broker
+// subscription per topic regardless of the number of clients.
+type Subscriber interface {
+	// Attach attaches the client handler to the topic, subscribing to it if
+	// the client is the first one. The client already attached to the topic
+	// under the same ID is replaced.
+	Attach(topic, id string, handler messaging.MessageHandler) error
+
+	// Detach detaches the client from the topic, unsubscribing from it if
+	// the client is the last one.
+	Detach(topic, id string) error
+}
+
+// Metrics represents the fan-out metrics.
+type Metrics struct {
+	// Subscriptions is the number of distinct broker subscriptions.
+	Subscriptions metrics.Gauge
+
+	// FanOut is the average number of clients per broker subscription.
+	FanOut metrics.Gauge
+}
+
+var _ Subscriber = (*subscriber)(nil)
+
+type subscriber struct {
+	sub       messaging.Subscriber
+	queueSize int
+	metrics   Metrics
+	logger    logger.Logger
+
+	mu      sync.Mutex
+	topics  map[string]*topic
+	clients int
+}
+
+// topic holds the clients attached to the broker subscription.
+type topic struct {
+	mu      sync.RWMutex
+	clients map[string]*client
+}
+
+// client delivers the messages from its queue, so a slow client can't hold
+// up the others sharing the subscription.
+type client struct {
+	queue   chan messaging.Message
+	handler messaging.MessageHandler
+	logger  logger.Logger
+}
+
+// New returns the fan-out subscriber over the subscriber. Each client gets
+// a queue of the queueSize messages, and the messages received while the
+// queue is full are dropped for that client only.
+func New(sub messaging.Subscriber, queueSize int, m Metrics, logger logger.Logger) Subscriber {
+	if queueSize <= 0 {
+		queueSize = defQueueSize
+	}
+	return &subscriber{
+		sub:       sub,
+		queueSize: queueSize,
+		metrics:   m,
+		logger:    logger,
+		topics:    make(map[string]*topic),
+	}
+}
+
+func (s *subscriber) Attach(name, id string, handler messaging.MessageHandler) error {
+	if id == "" {
+		return ErrEmptyID
+	}
+	c := &client{
+		queue:   make(chan messaging.Message, s.queueSize),
+		handler: handler,
+		logger:  s.logger,
+	}
+
+	// The lock is held while subscribing, so the concurrent attaches to the
+	// new topic don't subscribe twice.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.topics[name]
+	if !ok {
+		t = &topic{clients: make(map[string]*client)}
+		if err := s.sub.Subscribe(name, t.dispatch); err != nil {
+			return err
+		}
+		s.topics[name] = t
+	}
+
+	t.mu.Lock()
+	old, replaced := t.clients[id]
+	t.clients[id] = c
+	t.mu.Unlock()
+
+	if replaced {
+		close(old.queue)
+	} else {
+		s.clients++
+	}
+	go c.run()
+	s.updateMetrics()
+	return nil
+}
+
+func (s *subscriber) Detach(name, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.topics[name]
+	if !ok {
+		return ErrNotAttached
+	}
+
+	t.mu.Lock()
+	c, ok := t.clients[id]
+	delete(t.clients, id)
+	left := len(t.clients)
+	t.mu.Unlock()
+	if !ok {
+		return ErrNotAttached
+	}
+	close(c.queue)
+	s.clients--
+
+	if left == 0 {
+		delete(s.topics, name)
+		defer s.updateMetrics()
+		return s.sub.Unsubscribe(name)
+	}
+	s.updateMetrics()
+	return nil
+}
+
+// updateMetrics is called with the lock held.
+func (s *subscriber) updateMetrics() {
+	if s.metrics.Subscriptions != nil {
+		s.metrics.Subscriptions.Set(float64(len(s.topics)))
+	}
+	if s.metrics.FanOut != nil {
+		ratio := 0.0
+		if len(s.topics) > 0 {
+			ratio = float64(s.clients) / float64(len(s.topics))
+		}
+		s.metrics.FanOut.Set(ratio)
+	}
+}
+
+func (t *topic) dispatch(msg messaging.Message) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for id, c := range t.clients {
+		select {
+		case c.queue <- msg:
+		default:
+			c.logger.Warn(fmt.Sprintf("Dropped message for client %s, its queue is full", id))
+		}
+	}
+	return nil
+}
+
+func (c *client) run() {
+	for msg := range c.queue {
+		if err := c.handler(msg); err != nil {
+			c.logger.Warn(fmt.Sprintf("Failed to deliver message to client: %s", err))
+		}
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package fanout_test
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/fanout"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	topic   = "channels.1.sub"
+	clients = 500
+)
+
+var testLog, _ = log.New(os.Stdout, log.Info.String())
+
+// broker is the subscriber keeping the handlers, so the test can publish
+// to them directly.
+type broker struct {
+	mu           sync.Mutex
+	handlers     map[string]messaging.MessageHandler
+	subscribes   int
+	unsubscribes int
+}
+
+func newBroker() *broker {
+	return &broker{handlers: make(map[string]messaging.MessageHandler)}
+}
+
+func (b *broker) Subscribe(topic string, handler messaging.MessageHandler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.handlers[topic]; ok {
+		return fmt.Errorf("already subscribed to %s", topic)
+	}
+	b.handlers[topic] = handler
+	b.subscribes++
+	return nil
+}
+
+func (b *broker) Unsubscribe(topic string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.handlers[topic]; !ok {
+		return fmt.Errorf("not subscribed to %s", topic)
+	}
+	delete(b.handlers, topic)
+	b.unsubscribes++
+	return nil
+}
+
+func (b *broker) publish(topic string, msg messaging.Message) {
+	b.mu.Lock()
+	h, ok := b.handlers[topic]
+	b.mu.Unlock()
+	if ok {
+		h(msg)
+	}
+}
+
+func (b *broker) counts() (int, int, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.handlers), b.subscribes, b.unsubscribes
+}
+
+type gauge struct {
+	mu    sync.Mutex
+	value float64
+}
+
+func (g *gauge) With(labelValues ...string) metrics.Gauge {
+	return g
+}
+
+func (g *gauge) Set(value float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value = value
+}
+
+func (g *gauge) Add(delta float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.value += delta
+}
+
+func (g *gauge) get() float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.value
+}
+
+// recorder counts the messages delivered to the client.
+type recorder struct {
+	mu       sync.Mutex
+	received int
+}
+
+func (r *recorder) handle(msg messaging.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received++
+	return nil
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.received
+}
+
+func TestFanOut(t *testing.T) {
+	b := newBroker()
+	subs, ratio := &gauge{}, &gauge{}
+	sub := fanout.New(b, 0, fanout.Metrics{Subscriptions: subs, FanOut: ratio}, testLog)
+
+	// Clients attach concurrently, the same way the adapter's observers do.
+	recs := make([]*recorder, clients)
+	var wg sync.WaitGroup
+	for i := range recs {
+		recs[i] = &recorder{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := sub.Attach(topic, fmt.Sprintf("client-%d", i), recs[i].handle)
+			assert.Nil(t, err, fmt.Sprintf("attach client %d: unexpected error: %s", i, err))
+		}(i)
+	}
+	wg.Wait()
+
+	active, subscribes, _ := b.counts()
+	assert.Equal(t, 1, active, fmt.Sprintf("expected single broker subscription got %d", active))
+	assert.Equal(t, 1, subscribes, fmt.Sprintf("expected single subscribe call got %d", subscribes))
+	assert.Equal(t, float64(1), subs.get(), fmt.Sprintf("expected subscriptions gauge 1 got %v", subs.get()))
+	assert.Equal(t, float64(clients), ratio.get(), fmt.Sprintf("expected fan-out ratio %d got %v", clients, ratio.get()))
+
+	b.publish(topic, messaging.Message{Channel: "1", Subtopic: "sub", Payload: []byte("payload")})
+	for i, r := range recs {
+		assert.Eventually(t, func() bool { return r.count() == 1 }, time.Second, time.Millisecond, fmt.Sprintf("expected client %d to receive the message", i))
+	}
+
+	for i := 0; i < clients-1; i++ {
+		err := sub.Detach(topic, fmt.Sprintf("client-%d", i))
+		require.Nil(t, err, fmt.Sprintf("detach client %d: unexpected error: %s", i, err))
+	}
+	active, _, unsubscribes := b.counts()
+	assert.Equal(t, 1, active, fmt.Sprintf("expected subscription kept for the last client got %d", active))
+	assert.Equal(t, 0, unsubscribes, fmt.Sprintf("expected no unsubscribe calls got %d", unsubscribes))
+	assert.Equal(t, float64(1), ratio.get(), fmt.Sprintf("expected fan-out ratio 1 got %v", ratio.get()))
+
+	err := sub.Detach(topic, fmt.Sprintf("client-%d", clients-1))
+	require.Nil(t, err, fmt.Sprintf("detach last client: unexpected error: %s", err))
+	active, _, unsubscribes = b.counts()
+	assert.Equal(t, 0, active, fmt.Sprintf("expected no broker subscriptions got %d", active))
+	assert.Equal(t, 1, unsubscribes, fmt.Sprintf("expected single unsubscribe call got %d", unsubscribes))
+	assert.Equal(t, float64(0), subs.get(), fmt.Sprintf("expected subscriptions gauge 0 got %v", subs.get()))
+	assert.Equal(t, float64(0), ratio.get(), fmt.Sprintf("expected fan-out ratio 0 got %v", ratio.get()))
+
+	err = sub.Attach(topic, "client-0", recs[0].handle)
+	assert.Nil(t, err, fmt.Sprintf("attach after teardown: unexpected error: %s", err))
+	_, subscribes, _ = b.counts()
+	assert.Equal(t, 2, subscribes, fmt.Sprintf("expected resubscribe after teardown got %d subscribe calls", subscribes))
+}
+
+func TestAttach(t *testing.T) {
+	b := newBroker()
+	sub := fanout.New(b, 0, fanout.Metrics{}, testLog)
+	first, second := &recorder{}, &recorder{}
+
+	cases := []struct {
+		desc    string
+		topic   string
+		id      string
+		handler messaging.MessageHandler
+		err     error
+	}{
+		{
+			desc:    "attach client",
+			topic:   topic,
+			id:      "client",
+			handler: first.handle,
+			err:     nil,
+		},
+		{
+			desc:    "attach client with the same id",
+			topic:   topic,
+			id:      "client",
+			handler: second.handle,
+			err:     nil,
+		},
+		{
+			desc:    "attach client to another topic",
+			topic:   "channels.2",
+			id:      "client",
+			handler: first.handle,
+			err:     nil,
+		},
+		{
+			desc:    "attach client without id",
+			topic:   topic,
+			id:      "",
+			handler: first.handle,
+			err:     fanout.ErrEmptyID,
+		},
+	}
+
+	for _, tc := range cases {
+		err := sub.Attach(tc.topic, tc.id, tc.handler)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+
+	active, subscribes, _ := b.counts()
+	assert.Equal(t, 2, active, fmt.Sprintf("expected 2 broker subscriptions got %d", active))
+	assert.Equal(t, 2, subscribes, fmt.Sprintf("expected 2 subscribe calls got %d", subscribes))
+
+	b.publish(topic, messaging.Message{Channel: "1"})
+	assert.Eventually(t, func() bool { return second.count() == 1 }, time.Second, time.Millisecond, "expected replacing client to receive the message")
+	assert.Equal(t, 0, first.count(), fmt.Sprintf("expected replaced client to receive no messages got %d", first.count()))
+}
+
+func TestDetach(t *testing.T) {
+	b := newBroker()
+	sub := fanout.New(b, 0, fanout.Metrics{}, testLog)
+	r := &recorder{}
+	err := sub.Attach(topic, "client", r.handle)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		topic string
+		id    string
+		err   error
+	}{
+		{
+			desc:  "detach client from unknown topic",
+			topic: "channels.2",
+			id:    "client",
+			err:   fanout.ErrNotAttached,
+		},
+		{
+			desc:  "detach unknown client",
+			topic: topic,
+			id:    "unknown",
+			err:   fanout.ErrNotAttached,
+		},
+		{
+			desc:  "detach client",
+			topic: topic,
+			id:    "client",
+			err:   nil,
+		},
+		{
+			desc:  "detach detached client",
+			topic: topic,
+			id:    "client",
+			err:   fanout.ErrNotAttached,
+		},
+	}
+
+	for _, tc := range cases {
+		err := sub.Detach(tc.topic, tc.id)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+
+	b.publish(topic, messaging.Message{Channel: "1"})
+	assert.Equal(t, 0, r.count(), fmt.Sprintf("expected detached client to receive no messages got %d", r.count()))
+}
@@ -0,0 +1,49 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+const (
+	// ContentTypeHeader is the message header containing the payload
+	// content type.
+	ContentTypeHeader = "content-type"
+
+	// ContentEncodingHeader is the message header containing the payload
+	// encoding, such as gzip or deflate.
+	ContentEncodingHeader = "content-encoding"
+
+	// TenantHeader is the message header containing the label of the tenant
+	// the message belongs to.
+	TenantHeader = "tenant"
+
+	// DefaultTenant is the tenant of the messages without the tenant header.
+	DefaultTenant = "default"
+
+	// IDHeader is the message header containing the ID the adapter assigned
+	// to the message, so the writers store it and the readers can find it.
+	IDHeader = "message-id"
+
+	// RequestIDHeader is the message header containing the ID of the
+	// request the message was published in, so the message can be matched
+	// with the adapter logs.
+	RequestIDHeader = "request-id"
+)
+
+// Tenant returns the tenant the message belongs to.
+func Tenant(msg Message) string {
+	if t := msg.Headers[TenantHeader]; t != "" {
+		return t
+	}
+	return DefaultTenant
+}
+
+// ID returns the ID assigned to the message, empty if none is.
+func ID(msg Message) string {
+	return msg.Headers[IDHeader]
+}
+
+// RequestID returns the ID of the request the message was published in,
+// empty if none is.
+func RequestID(msg Message) string {
+	return msg.Headers[RequestIDHeader]
+}
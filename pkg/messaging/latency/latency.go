@@ -0,0 +1,99 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package latency contains the message publisher and subscriber measuring
+// the time elapsed since the message was created by the adapter, so the
+// publish and the write latency of the pipeline can be compared.
+package latency
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+const protocolLabel = "protocol"
+
+// Buckets are the latency histogram buckets in seconds.
+var Buckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+var (
+	_ messaging.Publisher  = (*publisher)(nil)
+	_ messaging.Subscriber = (*subscriber)(nil)
+)
+
+// observer records the latency of the message, clamping the negative ones
+// caused by the clock skew between the services to zero.
+type observer struct {
+	latency metrics.Histogram
+	skewed  metrics.Counter
+}
+
+func (o observer) observe(msg messaging.Message) {
+	// Messages created by the adapters not stamping the creation time are
+	// not measured.
+	if msg.Created == 0 {
+		return
+	}
+
+	d := time.Since(time.Unix(0, msg.Created))
+	if d < 0 {
+		o.skewed.With(protocolLabel, msg.Protocol).Add(1)
+		d = 0
+	}
+	o.latency.With(protocolLabel, msg.Protocol).Observe(d.Seconds())
+}
+
+type publisher struct {
+	observer
+	pub messaging.Publisher
+}
+
+// NewPublisher wraps the publisher, observing the time between the message
+// creation and its successful publish by protocol. Messages created in the
+// future are counted as skewed and observed with zero latency.
+func NewPublisher(pub messaging.Publisher, latency metrics.Histogram, skewed metrics.Counter) messaging.Publisher {
+	return &publisher{
+		observer: observer{latency: latency, skewed: skewed},
+		pub:      pub,
+	}
+}
+
+func (p *publisher) Publish(topic string, msg messaging.Message) error {
+	if err := p.pub.Publish(topic, msg); err != nil {
+		return err
+	}
+	p.observe(msg)
+	return nil
+}
+
+type subscriber struct {
+	observer
+	sub messaging.Subscriber
+}
+
+// NewSubscriber wraps the subscriber, observing the time between the message
+// creation and its successful handling by protocol, e.g. the message being
+// stored by the writer. Messages created in the future are counted as skewed
+// and observed with zero latency.
+func NewSubscriber(sub messaging.Subscriber, latency metrics.Histogram, skewed metrics.Counter) messaging.Subscriber {
+	return &subscriber{
+		observer: observer{latency: latency, skewed: skewed},
+		sub:      sub,
+	}
+}
+
+func (s *subscriber) Subscribe(topic string, handler messaging.MessageHandler) error {
+	return s.sub.Subscribe(topic, func(msg messaging.Message) error {
+		if err := handler(msg); err != nil {
+			return err
+		}
+		s.observe(msg)
+		return nil
+	})
+}
+
+func (s *subscriber) Unsubscribe(topic string) error {
+	return s.sub.Unsubscribe(topic)
+}
@@ -0,0 +1,194 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package latency_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/consumers"
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/latency"
+	"github.com/mainflux/mainflux/pkg/messaging/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chanID   = "1"
+	protocol = "http"
+	// Tolerance of the measured latency, covering the test execution.
+	tolerance = time.Second
+)
+
+var (
+	testLog, _ = log.New(os.Stdout, log.Info.String())
+	errWrite   = errors.New("failed to write message")
+)
+
+// histogram stores the observations by joined label values.
+type histogram struct {
+	mu     *sync.Mutex
+	labels []string
+	values map[string][]float64
+}
+
+func newHistogram() *histogram {
+	return &histogram{mu: &sync.Mutex{}, values: make(map[string][]float64)}
+}
+
+func (h *histogram) With(labelValues ...string) metrics.Histogram {
+	return &histogram{mu: h.mu, labels: labelValues, values: h.values}
+}
+
+func (h *histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := strings.Join(h.labels, ",")
+	h.values[key] = append(h.values[key], value)
+}
+
+func (h *histogram) observations() []float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.values["protocol,"+protocol]
+}
+
+// counter stores the values by joined label values.
+type counter struct {
+	mu     *sync.Mutex
+	labels []string
+	values map[string]float64
+}
+
+func newCounter() *counter {
+	return &counter{mu: &sync.Mutex{}, values: make(map[string]float64)}
+}
+
+func (c *counter) With(labelValues ...string) metrics.Counter {
+	return &counter{mu: c.mu, labels: labelValues, values: c.values}
+}
+
+func (c *counter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[strings.Join(c.labels, ",")] += delta
+}
+
+func (c *counter) value() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values["protocol,"+protocol]
+}
+
+// repository stores the consumed messages, failing the configured ones.
+type repository struct {
+	mu       sync.Mutex
+	fail     bool
+	messages []messaging.Message
+}
+
+func (r *repository) Consume(msgs interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.fail {
+		return errWrite
+	}
+	r.messages = append(r.messages, msgs.(messaging.Message))
+	return nil
+}
+
+var cases = []struct {
+	desc    string
+	created int64
+	fail    bool
+	latency []time.Duration
+	skewed  float64
+}{
+	{
+		desc:    "message created in the past",
+		created: time.Now().Add(-2 * time.Second).UnixNano(),
+		latency: []time.Duration{2 * time.Second},
+	},
+	{
+		desc:    "message created in the future",
+		created: time.Now().Add(time.Hour).UnixNano(),
+		latency: []time.Duration{0},
+		skewed:  1,
+	},
+	{
+		desc:    "message without creation time",
+		created: 0,
+	},
+	{
+		desc:    "message failed to be handled",
+		created: time.Now().Add(-2 * time.Second).UnixNano(),
+		fail:    true,
+	},
+}
+
+func TestSubscriber(t *testing.T) {
+	for _, tc := range cases {
+		h := newHistogram()
+		c := newCounter()
+		pubSub := mocks.NewPubSub()
+		repo := &repository{fail: tc.fail}
+		err := consumers.Start(latency.NewSubscriber(pubSub, h, c), repo, nil, "", testLog)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		msg := messaging.Message{Channel: chanID, Subtopic: "temperature", Protocol: protocol, Created: tc.created}
+		err = pubSub.Publish(chanID, msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		if !tc.fail {
+			assert.Equal(t, []messaging.Message{msg}, repo.messages, fmt.Sprintf("%s: expected message stored", tc.desc))
+		}
+		assertLatency(t, tc.desc, tc.latency, h.observations())
+		assert.Equal(t, tc.skewed, c.value(), fmt.Sprintf("%s: expected %v skewed messages got %v", tc.desc, tc.skewed, c.value()))
+	}
+}
+
+// failingPublisher fails to publish the messages if configured to.
+type failingPublisher struct {
+	fail bool
+}
+
+func (p failingPublisher) Publish(string, messaging.Message) error {
+	if p.fail {
+		return errWrite
+	}
+	return nil
+}
+
+func TestPublisher(t *testing.T) {
+	for _, tc := range cases {
+		h := newHistogram()
+		c := newCounter()
+		pub := latency.NewPublisher(failingPublisher{fail: tc.fail}, h, c)
+
+		msg := messaging.Message{Channel: chanID, Protocol: protocol, Created: tc.created}
+		err := pub.Publish(chanID, msg)
+		if tc.fail {
+			assert.Equal(t, errWrite, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, errWrite, err))
+		}
+		assertLatency(t, tc.desc, tc.latency, h.observations())
+		assert.Equal(t, tc.skewed, c.value(), fmt.Sprintf("%s: expected %v skewed messages got %v", tc.desc, tc.skewed, c.value()))
+	}
+}
+
+func assertLatency(t *testing.T, desc string, expected []time.Duration, observed []float64) {
+	require.Len(t, observed, len(expected), fmt.Sprintf("%s: expected %d observations got %d", desc, len(expected), len(observed)))
+	for i, e := range expected {
+		assert.InDelta(t, e.Seconds(), observed[i], tolerance.Seconds(), fmt.Sprintf("%s: expected latency %s got %vs", desc, e, observed[i]))
+		assert.GreaterOrEqual(t, observed[i], e.Seconds(), fmt.Sprintf("%s: expected latency at least %s got %vs", desc, e, observed[i]))
+	}
+}
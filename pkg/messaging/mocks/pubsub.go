@@ -0,0 +1,98 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+const chansPrefix = "channels"
+
+var (
+	errAlreadySubscribed = errors.New("already subscribed to topic")
+	errNotSubscribed     = errors.New("not subscribed")
+)
+
+var _ messaging.PubSub = (*pubSub)(nil)
+
+type pubSub struct {
+	mu       sync.RWMutex
+	handlers map[string]messaging.MessageHandler
+}
+
+// NewPubSub returns the in-memory broker delivering the messages to the
+// handlers synchronously. The messages are published to the NATS subjects,
+// and the subscriptions support the NATS wildcards.
+func NewPubSub() messaging.PubSub {
+	return &pubSub{
+		handlers: make(map[string]messaging.MessageHandler),
+	}
+}
+
+func (ps *pubSub) Publish(topic string, msg messaging.Message) error {
+	subject := fmt.Sprintf("%s.%s", chansPrefix, topic)
+	if msg.Subtopic != "" {
+		subject = fmt.Sprintf("%s.%s", subject, msg.Subtopic)
+	}
+
+	ps.mu.RLock()
+	var handlers []messaging.MessageHandler
+	for topic, h := range ps.handlers {
+		if match(topic, subject) {
+			handlers = append(handlers, h)
+		}
+	}
+	ps.mu.RUnlock()
+
+	// As with NATS, the handler errors are not returned to the publisher.
+	for _, h := range handlers {
+		h(msg)
+	}
+	return nil
+}
+
+func (ps *pubSub) Subscribe(topic string, handler messaging.MessageHandler) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if _, ok := ps.handlers[topic]; ok {
+		return errAlreadySubscribed
+	}
+	ps.handlers[topic] = handler
+	return nil
+}
+
+func (ps *pubSub) Unsubscribe(topic string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if _, ok := ps.handlers[topic]; !ok {
+		return errNotSubscribed
+	}
+	delete(ps.handlers, topic)
+	return nil
+}
+
+// match reports whether the subject matches the topic containing the "*"
+// token wildcard and the trailing ">" wildcard.
+func match(topic, subject string) bool {
+	tt := strings.Split(topic, ".")
+	st := strings.Split(subject, ".")
+	for i, t := range tt {
+		switch {
+		case t == ">":
+			return len(st) > i
+		case i >= len(st):
+			return false
+		case t != "*" && t != st[i]:
+			return false
+		}
+	}
+	return len(tt) == len(st)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// +build test
+
+package nats_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	dockertest "github.com/ory/dockertest/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const failoverTimeout = 5 * time.Second
+
+func TestFailover(t *testing.T) {
+	servers := map[string]*dockertest.Resource{}
+	for i := 0; i < 2; i++ {
+		container, err := pool.Run("nats", "1.3.0", []string{})
+		require.Nil(t, err, fmt.Sprintf("Could not start container: %s", err))
+		defer pool.Purge(container)
+
+		url := fmt.Sprintf("%s:%s", "localhost", container.GetPort("4222/tcp"))
+		err = pool.Retry(func() error {
+			pub, err := nats.NewPublisher(url)
+			if err != nil {
+				return err
+			}
+			pub.Close()
+			return nil
+		})
+		require.Nil(t, err, fmt.Sprintf("Could not connect to docker: %s", err))
+		servers[url] = container
+	}
+	urls := []string{}
+	for url := range servers {
+		urls = append(urls, url)
+	}
+
+	ps := newPubSub(t, urls...)
+	defer ps.Close()
+
+	msgs := make(chan messaging.Message, 1)
+	err := ps.Subscribe(fmt.Sprintf("%s.%s", chansPrefix, channel), func(msg messaging.Message) error {
+		msgs <- msg
+		return nil
+	})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	st := ps.Status()
+	require.True(t, st.Connected, "expected connection to be up")
+	connected, ok := servers[st.Server]
+	require.True(t, ok, fmt.Sprintf("expected connection to one of %v got %s", urls, st.Server))
+
+	cases := []struct {
+		desc     string
+		failover func()
+		server   func(string) bool
+	}{
+		{
+			desc:     "publish message before failover",
+			failover: func() {},
+			server:   func(s string) bool { return s == st.Server },
+		},
+		{
+			desc:     "publish message after the connected server is down",
+			failover: func() { pool.Purge(connected) },
+			server:   func(s string) bool { return s != "" && s != st.Server },
+		},
+	}
+
+	for _, tc := range cases {
+		tc.failover()
+		deadline := time.Now().Add(failoverTimeout)
+		for !tc.server(ps.Status().Server) && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		server := ps.Status().Server
+		assert.True(t, tc.server(server), fmt.Sprintf("%s: unexpected connected server %s", tc.desc, server))
+
+		expected := messaging.Message{Channel: channel, Payload: data}
+		err := ps.Publish(channel, expected)
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+
+		select {
+		case msg := <-msgs:
+			assert.Equal(t, expected, msg, fmt.Sprintf("%s: expected %+v got %+v", tc.desc, expected, msg))
+		case <-time.After(failoverTimeout):
+			assert.Fail(t, fmt.Sprintf("%s: expected message to be received", tc.desc))
+		}
+	}
+
+	st = ps.Status()
+	assert.Equal(t, uint64(1), st.Reconnects, fmt.Sprintf("expected 1 reconnect got %d", st.Reconnects))
+}
@@ -21,12 +21,14 @@ type publisher struct {
 // Close() method for NATS connection.
 type Publisher interface {
 	messaging.Publisher
+	StatusReporter
 	Close()
 }
 
-// NewPublisher returns NATS message Publisher.
+// NewPublisher returns NATS message Publisher. The url may list the
+// comma-separated URLs of the cluster servers.
 func NewPublisher(url string) (Publisher, error) {
-	conn, err := broker.Connect(url)
+	conn, err := connect(url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -53,6 +55,10 @@ func (pub *publisher) Publish(topic string, msg messaging.Message) error {
 	return nil
 }
 
+func (pub *publisher) Status() Status {
+	return status(pub.conn)
+}
+
 func (pub *publisher) Close() {
 	pub.conn.Close()
 }
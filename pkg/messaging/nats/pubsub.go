@@ -32,6 +32,7 @@ var _ messaging.PubSub = (*pubsub)(nil)
 // Close() method for NATS connection.
 type PubSub interface {
 	messaging.PubSub
+	StatusReporter
 	Close()
 }
 
@@ -50,8 +51,9 @@ type pubsub struct {
 // from ordinary subscribe. For more information, please take a look
 // here: https://docs.nats.io/developing-with-nats/receiving/queues.
 // If the queue is empty, Subscribe will be used.
+// The url may list the comma-separated URLs of the cluster servers.
 func NewPubSub(url, queue string, logger log.Logger) (PubSub, error) {
-	conn, err := broker.Connect(url)
+	conn, err := connect(url, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -128,6 +130,10 @@ func (ps *pubsub) Unsubscribe(topic string) error {
 	return nil
 }
 
+func (ps *pubsub) Status() Status {
+	return status(ps.conn)
+}
+
 func (ps *pubsub) Close() {
 	ps.conn.Close()
 }
@@ -20,10 +20,13 @@ import (
 var (
 	publisher messaging.Publisher
 	pubsub    messaging.PubSub
+	pool      *dockertest.Pool
+	address   string
 )
 
 func TestMain(m *testing.M) {
-	pool, err := dockertest.NewPool("")
+	var err error
+	pool, err = dockertest.NewPool("")
 	if err != nil {
 		log.Fatalf("Could not connect to docker: %s", err)
 	}
@@ -34,7 +37,7 @@ func TestMain(m *testing.M) {
 	}
 	handleInterrupt(pool, container)
 
-	address := fmt.Sprintf("%s:%s", "localhost", container.GetPort("4222/tcp"))
+	address = fmt.Sprintf("%s:%s", "localhost", container.GetPort("4222/tcp"))
 	if err := pool.Retry(func() error {
 		publisher, err = nats.NewPublisher(address)
 		return err
@@ -0,0 +1,134 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package nats
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	log "github.com/mainflux/mainflux/logger"
+	broker "github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const healthContentType = "application/health+json"
+
+// Status represents the state of the connection to the NATS cluster.
+type Status struct {
+	Connected  bool   `json:"connected"`
+	Server     string `json:"server,omitempty"`
+	Reconnects uint64 `json:"reconnects"`
+}
+
+// StatusReporter reports the state of the connection to the NATS cluster.
+type StatusReporter interface {
+	// Status returns the current connection state.
+	Status() Status
+}
+
+// connect connects to one of the comma-separated server URLs, picked at
+// random, so the clients spread over the cluster. Once the connection is
+// lost, the client keeps reconnecting to the servers, and restores the
+// subscriptions after it reconnects, so the callers don't subscribe again.
+func connect(urls string, logger log.Logger) (*broker.Conn, error) {
+	opts := []broker.Option{broker.MaxReconnects(-1)}
+	if logger != nil {
+		opts = append(opts,
+			broker.DisconnectErrHandler(func(_ *broker.Conn, err error) {
+				if err != nil {
+					logger.Warn("Disconnected from NATS: " + err.Error())
+				}
+			}),
+			broker.ReconnectHandler(func(c *broker.Conn) {
+				logger.Info("Reconnected to NATS server " + server(c))
+			}),
+		)
+	}
+	return broker.Connect(urls, opts...)
+}
+
+func status(conn *broker.Conn) Status {
+	st := Status{
+		Connected:  conn.IsConnected(),
+		Reconnects: conn.Stats().Reconnects,
+	}
+	if st.Connected {
+		st.Server = server(conn)
+	}
+	return st
+}
+
+// server returns the address of the connected server, without the
+// credentials the URL may contain.
+func server(conn *broker.Conn) string {
+	u, err := url.Parse(conn.ConnectedUrl())
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+type healthRes struct {
+	Status string `json:"status"`
+	NATS   Status `json:"nats"`
+}
+
+// HealthHandler serves the connection state, responding with 200 if the
+// connection is up, and with 503 while it's being reestablished.
+func HealthHandler(r StatusReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		res := healthRes{Status: "pass", NATS: r.Status()}
+		code := http.StatusOK
+		if !res.NATS.Connected {
+			res.Status = "fail"
+			code = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", healthContentType)
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(res)
+	})
+}
+
+var _ prometheus.Collector = (*collector)(nil)
+
+type collector struct {
+	r          StatusReporter
+	connected  *prometheus.Desc
+	reconnects *prometheus.Desc
+}
+
+// NewCollector returns the collector of the connection state metrics, i.e.
+// whether the connection is up, labeled by the connected server, and the
+// number of the reconnects.
+func NewCollector(namespace string, r StatusReporter) prometheus.Collector {
+	return collector{
+		r: r,
+		connected: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nats", "connected"),
+			"Whether the connection to NATS is up, labeled by the connected server.",
+			[]string{"server"}, nil,
+		),
+		reconnects: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "nats", "reconnects_total"),
+			"Number of the reconnects to NATS.",
+			nil, nil,
+		),
+	}
+}
+
+func (c collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.connected
+	ch <- c.reconnects
+}
+
+func (c collector) Collect(ch chan<- prometheus.Metric) {
+	st := c.r.Status()
+	connected := 0.0
+	if st.Connected {
+		connected = 1
+	}
+	ch <- prometheus.MustNewConstMetric(c.connected, prometheus.GaugeValue, connected, st.Server)
+	ch <- prometheus.MustNewConstMetric(c.reconnects, prometheus.CounterValue, float64(st.Reconnects))
+}
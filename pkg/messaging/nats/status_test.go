@@ -0,0 +1,75 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package nats_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/messaging/nats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPubSub(t *testing.T, urls ...string) nats.PubSub {
+	l, err := logger.New(ioutil.Discard, "error")
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	ps, err := nats.NewPubSub(strings.Join(urls, ","), "", l)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	return ps
+}
+
+func TestHealthHandler(t *testing.T) {
+	connected := newPubSub(t, address)
+	defer connected.Close()
+	closed := newPubSub(t, address)
+	closed.Close()
+
+	cases := []struct {
+		desc      string
+		reporter  nats.StatusReporter
+		code      int
+		status    string
+		connected bool
+		server    string
+	}{
+		{
+			desc:      "check health of connection that is up",
+			reporter:  connected,
+			code:      http.StatusOK,
+			status:    "pass",
+			connected: true,
+			server:    address,
+		},
+		{
+			desc:      "check health of connection that is down",
+			reporter:  closed,
+			code:      http.StatusServiceUnavailable,
+			status:    "fail",
+			connected: false,
+		},
+	}
+
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		nats.HealthHandler(tc.reporter).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		assert.Equal(t, tc.code, rec.Code, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.code, rec.Code))
+
+		var res struct {
+			Status string      `json:"status"`
+			NATS   nats.Status `json:"nats"`
+		}
+		err := json.NewDecoder(rec.Body).Decode(&res)
+		assert.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.Status, fmt.Sprintf("%s: expected status %s got %s", tc.desc, tc.status, res.Status))
+		assert.Equal(t, tc.connected, res.NATS.Connected, fmt.Sprintf("%s: expected connected %t got %t", tc.desc, tc.connected, res.NATS.Connected))
+		assert.Equal(t, tc.server, res.NATS.Server, fmt.Sprintf("%s: expected server %s got %s", tc.desc, tc.server, res.NATS.Server))
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package shedding contains the message publisher shedding the load when
+// the underlying publisher is slow or saturated, so the adapters fail fast
+// instead of waiting on the broker indefinitely.
+package shedding
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+const (
+	reasonLabel      = "reason"
+	reasonDeadline   = "deadline"
+	reasonOverloaded = "overloaded"
+)
+
+var (
+	// ErrDeadline indicates that the message wasn't published within the
+	// publish deadline. The publish can't be canceled, so the message may
+	// still be published, and the retried one may be duplicated.
+	ErrDeadline = errors.New("publish deadline exceeded, message may still be published")
+
+	// ErrOverloaded indicates that the in-flight publish limit is reached.
+	ErrOverloaded = errors.New("too many messages in flight")
+)
+
+// Config represents the shedding publisher settings.
+type Config struct {
+	// Deadline is the maximum duration of a single publish. Zero disables
+	// the deadline.
+	Deadline time.Duration
+
+	// MaxInFlight is the maximum number of concurrent publishes. Zero
+	// disables the limit.
+	MaxInFlight int
+}
+
+var _ messaging.Publisher = (*publisher)(nil)
+
+type publisher struct {
+	pub      messaging.Publisher
+	deadline time.Duration
+	sem      chan struct{}
+	shed     metrics.Counter
+}
+
+// NewPublisher wraps the publisher, failing the publishes exceeding the
+// deadline with ErrDeadline and the ones over the in-flight limit with
+// ErrOverloaded. Shed messages are counted by reason.
+//
+// The publish exceeding the deadline keeps its in-flight slot until the
+// underlying publisher returns, so stuck publishes can't pile up beyond
+// the limit. Since the underlying publish can't be canceled, the message
+// is delivered at least once if the publish completes after the deadline.
+func NewPublisher(pub messaging.Publisher, cfg Config, shed metrics.Counter) messaging.Publisher {
+	p := &publisher{
+		pub:      pub,
+		deadline: cfg.Deadline,
+		shed:     shed,
+	}
+	if cfg.MaxInFlight > 0 {
+		p.sem = make(chan struct{}, cfg.MaxInFlight)
+	}
+	return p
+}
+
+func (p *publisher) Publish(topic string, msg messaging.Message) error {
+	if !p.acquire() {
+		p.shed.With(reasonLabel, reasonOverloaded).Add(1)
+		return ErrOverloaded
+	}
+
+	if p.deadline <= 0 {
+		defer p.release()
+		return p.pub.Publish(topic, msg)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		defer p.release()
+		done <- p.pub.Publish(topic, msg)
+	}()
+
+	timer := time.NewTimer(p.deadline)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		p.shed.With(reasonLabel, reasonDeadline).Add(1)
+		return ErrDeadline
+	}
+}
+
+func (p *publisher) acquire() bool {
+	if p.sem == nil {
+		return true
+	}
+	select {
+	case p.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *publisher) release() {
+	if p.sem != nil {
+		<-p.sem
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package shedding_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/messaging/shedding"
+	"github.com/stretchr/testify/assert"
+)
+
+const topic = "topic"
+
+var msg = messaging.Message{Channel: "1", Payload: []byte("payload")}
+
+// slowPublisher publishes after the delay, or when released if the delay is
+// negative.
+type slowPublisher struct {
+	delay   time.Duration
+	release chan struct{}
+
+	mu        sync.Mutex
+	published int
+}
+
+func (p *slowPublisher) Publish(topic string, msg messaging.Message) error {
+	if p.delay < 0 {
+		<-p.release
+	} else {
+		time.Sleep(p.delay)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.published++
+	return nil
+}
+
+func (p *slowPublisher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.published
+}
+
+// counter is the metrics counter storing values by joined label values.
+type counter struct {
+	mu     *sync.Mutex
+	labels []string
+	values map[string]float64
+}
+
+func newCounter() *counter {
+	return &counter{mu: &sync.Mutex{}, values: make(map[string]float64)}
+}
+
+func (c *counter) With(labelValues ...string) metrics.Counter {
+	return &counter{mu: c.mu, labels: labelValues, values: c.values}
+}
+
+func (c *counter) Add(delta float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[strings.Join(c.labels, ",")] += delta
+}
+
+func (c *counter) value(reason string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values["reason,"+reason]
+}
+
+func TestPublishDeadline(t *testing.T) {
+	cases := []struct {
+		desc     string
+		deadline time.Duration
+		delay    time.Duration
+		err      error
+		shed     float64
+	}{
+		{
+			desc:     "publish within deadline",
+			deadline: 500 * time.Millisecond,
+			delay:    10 * time.Millisecond,
+			err:      nil,
+		},
+		{
+			desc:     "publish exceeding deadline",
+			deadline: 10 * time.Millisecond,
+			delay:    200 * time.Millisecond,
+			err:      shedding.ErrDeadline,
+			shed:     1,
+		},
+		{
+			desc:     "publish without deadline",
+			deadline: 0,
+			delay:    50 * time.Millisecond,
+			err:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		c := newCounter()
+		sp := &slowPublisher{delay: tc.delay}
+		pub := shedding.NewPublisher(sp, shedding.Config{Deadline: tc.deadline}, c)
+
+		start := time.Now()
+		err := pub.Publish(topic, msg)
+		elapsed := time.Since(start)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.shed, c.value("deadline"), fmt.Sprintf("%s: expected %v shed messages got %v", tc.desc, tc.shed, c.value("deadline")))
+		if tc.err != nil {
+			assert.True(t, elapsed < tc.delay, fmt.Sprintf("%s: expected publish to return before %s got %s", tc.desc, tc.delay, elapsed))
+		}
+	}
+}
+
+func TestPublishInFlightLimit(t *testing.T) {
+	limit := 3
+	c := newCounter()
+	sp := &slowPublisher{delay: -1, release: make(chan struct{})}
+	pub := shedding.NewPublisher(sp, shedding.Config{MaxInFlight: limit}, c)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, limit)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- pub.Publish(topic, msg)
+		}()
+	}
+
+	// Wait for all the publishes to take their slots.
+	assert.Eventually(t, func() bool {
+		return pub.Publish(topic, msg) == shedding.ErrOverloaded
+	}, time.Second, time.Millisecond, "expected publish over the limit to fail")
+	shed := c.value("overloaded")
+
+	err := pub.Publish(topic, msg)
+	assert.Equal(t, shedding.ErrOverloaded, err, fmt.Sprintf("publish over the limit: expected error %s got %s", shedding.ErrOverloaded, err))
+	assert.Equal(t, shed+1, c.value("overloaded"), fmt.Sprintf("publish over the limit: expected %v shed messages got %v", shed+1, c.value("overloaded")))
+
+	close(sp.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.Nil(t, err, fmt.Sprintf("publish within the limit: unexpected error: %s", err))
+	}
+	assert.Equal(t, limit, sp.count(), fmt.Sprintf("expected %d published messages got %d", limit, sp.count()))
+
+	err = pub.Publish(topic, msg)
+	assert.Nil(t, err, fmt.Sprintf("publish after slots are released: unexpected error: %s", err))
+}
+
+func TestPublishDeadlineKeepsSlot(t *testing.T) {
+	c := newCounter()
+	sp := &slowPublisher{delay: -1, release: make(chan struct{})}
+	pub := shedding.NewPublisher(sp, shedding.Config{Deadline: 10 * time.Millisecond, MaxInFlight: 1}, c)
+
+	err := pub.Publish(topic, msg)
+	assert.Equal(t, shedding.ErrDeadline, err, fmt.Sprintf("publish exceeding deadline: expected error %s got %s", shedding.ErrDeadline, err))
+
+	err = pub.Publish(topic, msg)
+	assert.Equal(t, shedding.ErrOverloaded, err, fmt.Sprintf("publish while stuck publish holds the slot: expected error %s got %s", shedding.ErrOverloaded, err))
+
+	close(sp.release)
+	assert.Eventually(t, func() bool {
+		return pub.Publish(topic, msg) == nil
+	}, time.Second, time.Millisecond, "expected publish to succeed after stuck publish returns")
+	assert.Equal(t, float64(1), c.value("deadline"), fmt.Sprintf("expected 1 message shed by deadline got %v", c.value("deadline")))
+}
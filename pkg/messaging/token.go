@@ -0,0 +1,46 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// ErrMalformedToken indicates the consistency token that can't be parsed.
+var ErrMalformedToken = errors.New("malformed consistency token")
+
+// Token identifies the published message, so the client can wait for the
+// message to be stored before reading the channel.
+type Token struct {
+	ID      string
+	Created int64
+}
+
+// NewToken returns the token of the message with the assigned ID.
+func NewToken(msg Message) Token {
+	return Token{ID: ID(msg), Created: msg.Created}
+}
+
+// String returns the token as the message ID and the creation time in
+// nanoseconds since the epoch, separated by the dot.
+func (t Token) String() string {
+	return fmt.Sprintf("%s.%d", t.ID, t.Created)
+}
+
+// ParseToken parses the token formatted by the Token.String.
+func ParseToken(s string) (Token, error) {
+	i := strings.LastIndex(s, ".")
+	if i <= 0 {
+		return Token{}, ErrMalformedToken
+	}
+	created, err := strconv.ParseInt(s[i+1:], 10, 64)
+	if err != nil {
+		return Token{}, ErrMalformedToken
+	}
+	return Token{ID: s[:i], Created: created}, nil
+}
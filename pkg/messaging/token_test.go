@@ -0,0 +1,37 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package messaging_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseToken(t *testing.T) {
+	id := "123e4567-e89b-12d3-a456-426614174000"
+	msg := messaging.Message{Created: 1600000000000000000, Headers: map[string]string{messaging.IDHeader: id}}
+
+	cases := []struct {
+		desc  string
+		token string
+		res   messaging.Token
+		err   error
+	}{
+		{desc: "parse token", token: messaging.NewToken(msg).String(), res: messaging.Token{ID: id, Created: msg.Created}},
+		{desc: "parse token with dotted ID", token: "a.b.1", res: messaging.Token{ID: "a.b", Created: 1}},
+		{desc: "parse token without ID", token: ".1", err: messaging.ErrMalformedToken},
+		{desc: "parse token without creation time", token: id, err: messaging.ErrMalformedToken},
+		{desc: "parse token with invalid creation time", token: id + ".now", err: messaging.ErrMalformedToken},
+	}
+
+	for _, tc := range cases {
+		res, err := messaging.ParseToken(tc.token)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.res, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.res, res))
+	}
+}
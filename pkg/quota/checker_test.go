@@ -0,0 +1,71 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package quota
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unlimitedStore reserves all the messages requested.
+type unlimitedStore struct{}
+
+func (unlimitedStore) Reserve(_ context.Context, _ string, _ time.Time, n, _ uint64) (uint64, error) {
+	return n, nil
+}
+
+func TestChannelsEviction(t *testing.T) {
+	now := time.Date(2021, time.January, 31, 23, 0, 0, 0, time.UTC)
+	c := NewChecker(unlimitedStore{}, Config{Batch: 10}, func() time.Time { return now }).(*checker)
+
+	for i := 0; i < 5; i++ {
+		err := c.CheckAndIncrement(context.Background(), fmt.Sprintf("chan-%d", i))
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+	assert.Len(t, c.channels, 5, fmt.Sprintf("expected 5 channels got %d", len(c.channels)))
+
+	cases := []struct {
+		desc     string
+		now      time.Time
+		chanID   string
+		channels int
+	}{
+		{
+			desc:     "check channel in the same month",
+			now:      now.Add(30 * time.Minute),
+			chanID:   "chan-0",
+			channels: 5,
+		},
+		{
+			desc:     "check channel in the new month",
+			now:      now.Add(2 * time.Hour),
+			chanID:   "chan-0",
+			channels: 1,
+		},
+		{
+			desc:     "check other channel in the new month",
+			now:      now.Add(3 * time.Hour),
+			chanID:   "chan-1",
+			channels: 2,
+		},
+		{
+			desc:     "check channel with clock set back to the past month",
+			now:      now,
+			chanID:   "chan-2",
+			channels: 3,
+		},
+	}
+
+	for _, tc := range cases {
+		now = tc.now
+		err := c.CheckAndIncrement(context.Background(), tc.chanID)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Len(t, c.channels, tc.channels, fmt.Sprintf("%s: expected %d channels got %d", tc.desc, tc.channels, len(c.channels)))
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/quota"
+)
+
+var _ quota.Store = (*Store)(nil)
+
+type counterKey struct {
+	chanID string
+	month  time.Time
+}
+
+// Store is the in-memory quota store.
+type Store struct {
+	mu       sync.Mutex
+	quotas   map[string]uint64
+	counters map[counterKey]uint64
+	calls    int
+}
+
+// NewStore returns the in-memory quota store with the given channel quotas.
+func NewStore(quotas map[string]uint64) *Store {
+	return &Store{
+		quotas:   quotas,
+		counters: make(map[counterKey]uint64),
+	}
+}
+
+// Reserve adds the messages to the counter the same way the Redis store
+// does.
+func (s *Store) Reserve(_ context.Context, chanID string, month time.Time, n, def uint64) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	limit, ok := s.quotas[chanID]
+	if !ok {
+		limit = def
+	}
+	key := counterKey{chanID: chanID, month: month}
+	used := s.counters[key]
+	if limit > 0 {
+		if used >= limit {
+			return 0, nil
+		}
+		if limit-used < n {
+			n = limit - used
+		}
+	}
+	s.counters[key] = used + n
+	return n, nil
+}
+
+// Count returns the counter of the channel for the month.
+func (s *Store) Count(chanID string, month time.Time) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.counters[counterKey{chanID: chanID, month: month}]
+}
+
+// Calls returns the number of the reservations made.
+func (s *Store) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
@@ -0,0 +1,34 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package quota
+
+import (
+	"context"
+
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+var _ messaging.Publisher = (*publisher)(nil)
+
+type publisher struct {
+	pub     messaging.Publisher
+	checker Checker
+}
+
+// NewPublisher wraps the publisher, counting the messages against the quota
+// of their channel before publishing them. The messages over the quota
+// aren't published, and ExceededError is returned instead.
+func NewPublisher(pub messaging.Publisher, checker Checker) messaging.Publisher {
+	return &publisher{
+		pub:     pub,
+		checker: checker,
+	}
+}
+
+func (p *publisher) Publish(topic string, msg messaging.Message) error {
+	if err := p.checker.CheckAndIncrement(context.Background(), msg.Channel); err != nil {
+		return err
+	}
+	return p.pub.Publish(topic, msg)
+}
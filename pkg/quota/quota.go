@@ -0,0 +1,183 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package quota contains the per-channel monthly message quota the adapters
+// enforce before publishing. The counters are kept in the shared store, and
+// each adapter instance reserves the messages from it in batches, so the
+// store isn't hit on every message.
+package quota
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// recheck is the period the channel over its quota isn't checked in the
+// store for, so the rejected messages don't hit the store either. The
+// rejection is lifted earlier only by the new month.
+const recheck = 10 * time.Second
+
+var (
+	// ErrQuotaExceeded indicates the message over the monthly quota of its
+	// channel.
+	ErrQuotaExceeded = errors.New("channel quota exceeded")
+
+	// ErrCheck indicates failure to check the quota in the store.
+	ErrCheck = errors.New("failed to check channel quota")
+)
+
+// ExceededError is returned for the message over the channel quota. It
+// carries the time the quota resets at, and matches ErrQuotaExceeded, so
+// it's found by errors.Contains.
+type ExceededError struct {
+	Reset time.Time
+}
+
+func (e *ExceededError) Error() string {
+	return ErrQuotaExceeded.Error()
+}
+
+// Is makes the error match ErrQuotaExceeded.
+func (e *ExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// IsExceeded reports whether the error is the quota rejection, returning
+// the time the quota resets at.
+func IsExceeded(err error) (time.Time, bool) {
+	var e *ExceededError
+	if stderrors.As(err, &e) {
+		return e.Reset, true
+	}
+	return time.Time{}, false
+}
+
+// Config represents the quota settings.
+type Config struct {
+	// Default is the monthly number of messages allowed to the channels
+	// that don't set their own quota. Zero is unlimited.
+	Default uint64
+
+	// Batch is the number of messages reserved from the store at once. The
+	// messages reserved but not published, e.g. when the adapter stops, are
+	// counted anyway, so the counter is over by at most a batch per adapter
+	// instance.
+	Batch uint64
+
+	// FailOpen allows the messages when the store can't be reached, instead
+	// of failing them with ErrCheck.
+	FailOpen bool
+}
+
+// Store keeps the monthly counters of the channels.
+type Store interface {
+	// Reserve adds up to n messages to the counter of the channel for the
+	// month starting at the given time, without exceeding the quota of the
+	// channel, which is def unless the channel sets its own. Zero quota is
+	// unlimited. The number of the messages added is returned, zero once
+	// the quota is reached.
+	Reserve(ctx context.Context, chanID string, month time.Time, n, def uint64) (uint64, error)
+}
+
+// Checker specifies the quota check API used by the adapters.
+type Checker interface {
+	// CheckAndIncrement counts the message published to the channel. The
+	// message over the quota isn't counted, and ExceededError is returned.
+	CheckAndIncrement(ctx context.Context, chanID string) error
+}
+
+// channel holds the messages reserved for the channel in the month.
+type channel struct {
+	mu      sync.Mutex
+	month   time.Time
+	tokens  uint64
+	blocked time.Time
+}
+
+var _ Checker = (*checker)(nil)
+
+type checker struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+
+	mu       sync.Mutex
+	month    time.Time
+	channels map[string]*channel
+}
+
+// NewChecker returns the checker reserving the messages from the store in
+// batches. The month is the calendar month in UTC.
+func NewChecker(store Store, cfg Config, now func() time.Time) Checker {
+	if cfg.Batch == 0 {
+		cfg.Batch = 1
+	}
+	return &checker{
+		store:    store,
+		cfg:      cfg,
+		now:      now,
+		channels: make(map[string]*channel),
+	}
+}
+
+func (c *checker) CheckAndIncrement(ctx context.Context, chanID string) error {
+	now := c.now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	reset := month.AddDate(0, 1, 0)
+
+	// The channel is locked while reserving, so the concurrent messages of
+	// the channel wait for the batch instead of reserving their own.
+	ch := c.channel(chanID, month)
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	if !ch.month.Equal(month) {
+		ch.month = month
+		ch.tokens = 0
+		ch.blocked = time.Time{}
+	}
+	if ch.tokens > 0 {
+		ch.tokens--
+		return nil
+	}
+	if now.Before(ch.blocked) {
+		return &ExceededError{Reset: reset}
+	}
+
+	n, err := c.store.Reserve(ctx, chanID, month, c.cfg.Batch, c.cfg.Default)
+	if err != nil {
+		if c.cfg.FailOpen {
+			return nil
+		}
+		return errors.Wrap(ErrCheck, err)
+	}
+	if n == 0 {
+		ch.blocked = now.Add(recheck)
+		return &ExceededError{Reset: reset}
+	}
+	ch.tokens = n - 1
+	return nil
+}
+
+// channel returns the reservations of the channel. The channels of the past
+// months are evicted once the month changes, so the channels no longer
+// publishing don't pile up.
+func (c *checker) channel(chanID string, month time.Time) *channel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if month.After(c.month) {
+		c.month = month
+		c.channels = make(map[string]*channel)
+	}
+	ch, ok := c.channels[chanID]
+	if !ok {
+		ch = &channel{}
+		c.channels[chanID] = ch
+	}
+	return ch
+}
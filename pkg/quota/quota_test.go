@@ -0,0 +1,252 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package quota_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/quota"
+	"github.com/mainflux/mainflux/pkg/quota/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chanID    = "channel"
+	unlimited = "unlimited"
+)
+
+var (
+	now   = time.Date(2021, time.January, 31, 23, 0, 0, 0, time.UTC)
+	month = time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	reset = time.Date(2021, time.February, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+type failingStore struct{}
+
+func (failingStore) Reserve(context.Context, string, time.Time, uint64, uint64) (uint64, error) {
+	return 0, errors.New("unavailable")
+}
+
+func TestCheckAndIncrement(t *testing.T) {
+	cases := []struct {
+		desc     string
+		chanID   string
+		messages int
+		allowed  int
+		calls    int
+	}{
+		{
+			desc:     "check messages under channel quota",
+			chanID:   chanID,
+			messages: 8,
+			allowed:  8,
+			calls:    2,
+		},
+		{
+			desc:     "check messages over channel quota",
+			chanID:   chanID,
+			messages: 14,
+			allowed:  10,
+			calls:    4,
+		},
+		{
+			desc:     "check messages over default quota",
+			chanID:   "default",
+			messages: 25,
+			allowed:  20,
+			calls:    6,
+		},
+		{
+			desc:     "check messages of unlimited channel",
+			chanID:   unlimited,
+			messages: 100,
+			allowed:  100,
+			calls:    25,
+		},
+	}
+
+	for _, tc := range cases {
+		store := mocks.NewStore(map[string]uint64{chanID: 10, unlimited: 0})
+		checker := quota.NewChecker(store, quota.Config{Default: 20, Batch: 4}, (&clock{now: now}).Now)
+
+		allowed := 0
+		for i := 0; i < tc.messages; i++ {
+			err := checker.CheckAndIncrement(context.Background(), tc.chanID)
+			if err == nil {
+				allowed++
+				continue
+			}
+			r, ok := quota.IsExceeded(err)
+			assert.True(t, ok, fmt.Sprintf("%s: expected quota exceeded error got %s", tc.desc, err))
+			assert.Equal(t, reset, r, fmt.Sprintf("%s: expected reset at %s got %s", tc.desc, reset, r))
+			assert.True(t, errors.Contains(err, quota.ErrQuotaExceeded), fmt.Sprintf("%s: expected error %s got %s", tc.desc, quota.ErrQuotaExceeded, err))
+		}
+		assert.Equal(t, tc.allowed, allowed, fmt.Sprintf("%s: expected %d messages allowed got %d", tc.desc, tc.allowed, allowed))
+		assert.Equal(t, tc.calls, store.Calls(), fmt.Sprintf("%s: expected %d store calls got %d", tc.desc, tc.calls, store.Calls()))
+	}
+}
+
+func TestCheckAndIncrementReset(t *testing.T) {
+	store := mocks.NewStore(map[string]uint64{chanID: 1})
+	c := &clock{now: now}
+	checker := quota.NewChecker(store, quota.Config{Batch: 4}, c.Now)
+
+	err := checker.CheckAndIncrement(context.Background(), chanID)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = checker.CheckAndIncrement(context.Background(), chanID)
+	assert.True(t, errors.Contains(err, quota.ErrQuotaExceeded), fmt.Sprintf("expected error %s got %s", quota.ErrQuotaExceeded, err))
+
+	// The rejection is cached, except when the month changes.
+	c.add(time.Second)
+	err = checker.CheckAndIncrement(context.Background(), chanID)
+	assert.True(t, errors.Contains(err, quota.ErrQuotaExceeded), fmt.Sprintf("expected error %s got %s", quota.ErrQuotaExceeded, err))
+	assert.Equal(t, 2, store.Calls(), fmt.Sprintf("expected rejection cached, got %d store calls", store.Calls()))
+
+	c.add(time.Hour)
+	err = checker.CheckAndIncrement(context.Background(), chanID)
+	assert.Nil(t, err, fmt.Sprintf("expected message allowed in the new month got %s", err))
+}
+
+func TestCheckAndIncrementStoreFailure(t *testing.T) {
+	cases := []struct {
+		desc     string
+		failOpen bool
+		err      error
+	}{
+		{desc: "check quota with unavailable store", err: quota.ErrCheck},
+		{desc: "check quota with unavailable store failing open", failOpen: true, err: nil},
+	}
+
+	for _, tc := range cases {
+		checker := quota.NewChecker(failingStore{}, quota.Config{Batch: 4, FailOpen: tc.failOpen}, time.Now)
+		err := checker.CheckAndIncrement(context.Background(), chanID)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+}
+
+// publish checks the messages concurrently using the checkers sharing the
+// store, returning the number of the messages allowed.
+func publish(checkers []quota.Checker, workers, messages int) int {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for _, checker := range checkers {
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func(checker quota.Checker) {
+				defer wg.Done()
+				for j := 0; j < messages; j++ {
+					if err := checker.CheckAndIncrement(context.Background(), chanID); err != nil {
+						continue
+					}
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}(checker)
+		}
+	}
+	wg.Wait()
+	return allowed
+}
+
+func TestCheckAndIncrementConcurrent(t *testing.T) {
+	const (
+		limit     = 1000
+		batch     = 16
+		instances = 4
+		workers   = 8
+	)
+
+	// The instance finishing with the unused part of its batch doesn't
+	// pass it to the others, so fewer messages may be allowed than the
+	// quota, by at most a batch per instance.
+	cases := []struct {
+		desc     string
+		messages int
+		min      int
+		max      int
+	}{
+		{
+			desc:     "check messages crossing quota concurrently",
+			messages: 50,
+			min:      limit - instances*(batch-1),
+			max:      limit,
+		},
+		{
+			desc:     "check messages under quota concurrently",
+			messages: 10,
+			min:      instances * workers * 10,
+			max:      instances * workers * 10,
+		},
+	}
+
+	for _, tc := range cases {
+		store := mocks.NewStore(map[string]uint64{chanID: limit})
+		var checkers []quota.Checker
+		for i := 0; i < instances; i++ {
+			checkers = append(checkers, quota.NewChecker(store, quota.Config{Batch: batch}, (&clock{now: now}).Now))
+		}
+
+		allowed := publish(checkers, workers, tc.messages)
+		assert.GreaterOrEqual(t, allowed, tc.min, fmt.Sprintf("%s: expected at least %d messages allowed got %d", tc.desc, tc.min, allowed))
+		assert.LessOrEqual(t, allowed, tc.max, fmt.Sprintf("%s: expected at most %d messages allowed got %d", tc.desc, tc.max, allowed))
+
+		// Each instance may hold the unused part of its last batch.
+		count := store.Count(chanID, month)
+		require.GreaterOrEqual(t, count, uint64(allowed), fmt.Sprintf("%s: expected count at least %d got %d", tc.desc, allowed, count))
+		assert.LessOrEqual(t, count-uint64(allowed), uint64(instances*(batch-1)), fmt.Sprintf("%s: expected count over by at most a batch per instance got %d over", tc.desc, count-uint64(allowed)))
+		assert.LessOrEqual(t, count, uint64(limit), fmt.Sprintf("%s: expected count at most %d got %d", tc.desc, limit, count))
+	}
+}
+
+type publisher struct {
+	published int
+}
+
+func (p *publisher) Publish(string, messaging.Message) error {
+	p.published++
+	return nil
+}
+
+func TestPublish(t *testing.T) {
+	pub := &publisher{}
+	store := mocks.NewStore(map[string]uint64{chanID: 2})
+	qp := quota.NewPublisher(pub, quota.NewChecker(store, quota.Config{Batch: 4}, (&clock{now: now}).Now))
+
+	msg := messaging.Message{Channel: chanID, Payload: []byte("payload")}
+	for i := 0; i < 3; i++ {
+		err := qp.Publish(chanID, msg)
+		if i < 2 {
+			assert.Nil(t, err, fmt.Sprintf("message %d: unexpected error: %s", i, err))
+			continue
+		}
+		assert.True(t, errors.Contains(err, quota.ErrQuotaExceeded), fmt.Sprintf("message %d: expected error %s got %s", i, quota.ErrQuotaExceeded, err))
+	}
+	assert.Equal(t, 2, pub.published, fmt.Sprintf("expected 2 messages published got %d", pub.published))
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis contains the quota store implementation using Redis. The
+// quotas of the channels are set in the same Redis by the things service.
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/pkg/quota"
+)
+
+const (
+	keyPrefix   = "quota"
+	monthFormat = "200601"
+
+	// retention is the period the counter is kept for after its month ends,
+	// so the usage can be read for billing.
+	retention = 31 * 24 * time.Hour
+)
+
+// reserveScript adds up to the requested number of the messages to the
+// counter, without exceeding the quota of the channel.
+//
+// KEYS: counter, quota. ARGV: number of messages, default quota and the
+// counter expiration as Unix time.
+var reserveScript = redis.NewScript(`
+local limit = tonumber(redis.call('GET', KEYS[2]) or ARGV[2])
+local n = tonumber(ARGV[1])
+if limit > 0 then
+	local used = tonumber(redis.call('GET', KEYS[1]) or '0')
+	if used >= limit then
+		return 0
+	end
+	if limit - used < n then
+		n = limit - used
+	end
+end
+redis.call('INCRBY', KEYS[1], n)
+redis.call('EXPIREAT', KEYS[1], ARGV[3])
+return n
+`)
+
+var _ quota.Store = (*store)(nil)
+
+type store struct {
+	client *redis.Client
+}
+
+// NewStore returns Redis quota store.
+func NewStore(client *redis.Client) quota.Store {
+	return store{client: client}
+}
+
+func (s store) Reserve(ctx context.Context, chanID string, month time.Time, n, def uint64) (uint64, error) {
+	keys := []string{counterKey(chanID, month), QuotaKey(chanID)}
+	expire := month.AddDate(0, 1, 0).Add(retention).Unix()
+	return reserveScript.Run(ctx, s.client, keys, n, def, expire).Uint64()
+}
+
+// QuotaKey returns the key holding the monthly quota of the channel.
+func QuotaKey(chanID string) string {
+	return fmt.Sprintf("%s:%s", keyPrefix, chanID)
+}
+
+func counterKey(chanID string, month time.Time) string {
+	return fmt.Sprintf("%s:%s:%s", keyPrefix, chanID, month.UTC().Format(monthFormat))
+}
@@ -5,10 +5,16 @@ package sdk
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/mainflux/mainflux/pkg/errors"
@@ -16,6 +22,7 @@ import (
 
 const configsEndpoint = "configs"
 const bootstrapEndpoint = "bootstrap"
+const secureBootstrapEndpoint = "bootstrap/secure"
 const whitelistEndpoint = "state"
 const bootstrapCertsEndpoint = "configs/certs"
 
@@ -136,6 +143,44 @@ func (sdk mfSDK) ViewBootstrap(token, id string) (BootstrapConfig, error) {
 	return bc, nil
 }
 
+func (sdk mfSDK) Bootstraps(token string, offset, limit uint64, filter map[string]string) (BootstrapPage, error) {
+	q := url.Values{}
+	q.Set("offset", fmt.Sprintf("%d", offset))
+	q.Set("limit", fmt.Sprintf("%d", limit))
+	for k, v := range filter {
+		q.Set(k, v)
+	}
+	endpoint := fmt.Sprintf("%s?%s", configsEndpoint, q.Encode())
+	url := createURL(sdk.bootstrapURL, sdk.bootstrapPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return BootstrapPage{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return BootstrapPage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return BootstrapPage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return BootstrapPage{}, errors.Wrap(ErrFailedFetch, errors.New(resp.Status))
+	}
+
+	var bp BootstrapPage
+	if err := json.Unmarshal(body, &bp); err != nil {
+		return BootstrapPage{}, err
+	}
+
+	return bp, nil
+}
+
 func (sdk mfSDK) UpdateBootstrap(token string, cfg BootstrapConfig) error {
 	data, err := json.Marshal(cfg)
 	if err != nil {
@@ -243,3 +288,74 @@ func (sdk mfSDK) Bootstrap(externalKey, externalID string) (BootstrapConfig, err
 
 	return bc, nil
 }
+
+func (sdk mfSDK) BootstrapSecure(externalKey, externalID, encKey string) (BootstrapConfig, error) {
+	block, err := aes.NewCipher([]byte(encKey))
+	if err != nil {
+		return BootstrapConfig{}, errors.Wrap(ErrInvalidEncKey, err)
+	}
+
+	key, err := encrypt(block, []byte(externalKey))
+	if err != nil {
+		return BootstrapConfig{}, err
+	}
+
+	endpoint := fmt.Sprintf("%s/%s", secureBootstrapEndpoint, externalID)
+	url := createURL(sdk.bootstrapURL, sdk.bootstrapPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return BootstrapConfig{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, hex.EncodeToString(key), string(CTJSON))
+	if err != nil {
+		return BootstrapConfig{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return BootstrapConfig{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return BootstrapConfig{}, errors.Wrap(ErrFailedFetch, errors.New(resp.Status))
+	}
+
+	body, err = decrypt(block, body)
+	if err != nil {
+		return BootstrapConfig{}, err
+	}
+
+	var bc BootstrapConfig
+	if err := json.Unmarshal(body, &bc); err != nil {
+		return BootstrapConfig{}, errors.Wrap(ErrFailedDecrypt, err)
+	}
+
+	return bc, nil
+}
+
+// encrypt encrypts data using AES CFB the same way bootstrap service does,
+// prepending the random IV to the ciphertext.
+func encrypt(block cipher.Block, in []byte) ([]byte, error) {
+	ciphertext := make([]byte, aes.BlockSize+len(in))
+	iv := ciphertext[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	stream := cipher.NewCFBEncrypter(block, iv)
+	stream.XORKeyStream(ciphertext[aes.BlockSize:], in)
+	return ciphertext, nil
+}
+
+func decrypt(block cipher.Block, in []byte) ([]byte, error) {
+	if len(in) < aes.BlockSize {
+		return nil, ErrFailedDecrypt
+	}
+	iv := in[:aes.BlockSize]
+	out := make([]byte, len(in)-aes.BlockSize)
+	stream := cipher.NewCFBDecrypter(block, iv)
+	stream.XORKeyStream(out, in[aes.BlockSize:])
+	return out, nil
+}
@@ -0,0 +1,137 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mainflux/mainflux/bootstrap"
+	bsapi "github.com/mainflux/mainflux/bootstrap/api"
+	"github.com/mainflux/mainflux/bootstrap/mocks"
+	"github.com/mainflux/mainflux/internal/retry"
+	"github.com/mainflux/mainflux/pkg/errors"
+	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bsEncKey = "v7aT0HGxJxt2gULzr3RHwf4WIf6DusPp"
+
+func newBootstrapServer(t *testing.T, configs ...bootstrap.Config) *httptest.Server {
+	repo := mocks.NewConfigsRepository()
+	for _, c := range configs {
+		_, err := repo.Save(c, nil)
+		require.Nil(t, err, fmt.Sprintf("unexpected error saving config: %s", err))
+	}
+	auth := mocks.NewUsersService(map[string]string{token: email})
+	svc := bootstrap.New(auth, repo, nil, []byte(bsEncKey), retry.Config{})
+	return httptest.NewServer(bsapi.MakeHandler(svc, bootstrap.NewConfigReader([]byte(bsEncKey))))
+}
+
+func TestBootstraps(t *testing.T) {
+	ts := newBootstrapServer(t,
+		bootstrap.Config{Owner: email, Name: "sensor", ExternalID: "ext-1", ExternalKey: "key-1", State: bootstrap.Active},
+		bootstrap.Config{Owner: email, Name: "gateway", ExternalID: "ext-2", ExternalKey: "key-2", State: bootstrap.Inactive},
+	)
+	defer ts.Close()
+
+	mainfluxSDK := sdk.NewSDK(sdk.Config{BootstrapURL: ts.URL, BootstrapPrefix: "things"})
+
+	cases := []struct {
+		desc   string
+		token  string
+		filter map[string]string
+		names  []string
+		err    error
+	}{
+		{
+			desc:  "list all configs",
+			token: token,
+			names: []string{"sensor", "gateway"},
+			err:   nil,
+		},
+		{
+			desc:   "list configs filtered by state",
+			token:  token,
+			filter: map[string]string{"state": "1"},
+			names:  []string{"sensor"},
+			err:    nil,
+		},
+		{
+			desc:   "list configs filtered by name",
+			token:  token,
+			filter: map[string]string{"name": "gate"},
+			names:  []string{"gateway"},
+			err:    nil,
+		},
+		{
+			desc:  "list configs with invalid token",
+			token: wrongValue,
+			err:   createError(sdk.ErrFailedFetch, 403),
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := mainfluxSDK.Bootstraps(tc.token, 0, 10, tc.filter)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		var names []string
+		for _, c := range page.Configs {
+			names = append(names, c.Name)
+		}
+		assert.Equal(t, tc.names, names, fmt.Sprintf("%s: expected configs %v got %v", tc.desc, tc.names, names))
+	}
+}
+
+func TestBootstrapSecure(t *testing.T) {
+	ts := newBootstrapServer(t, bootstrap.Config{
+		Owner:       email,
+		MFKey:       "thing-key",
+		ExternalID:  "ext-1",
+		ExternalKey: "key-1",
+		Content:     "config",
+	})
+	defer ts.Close()
+
+	mainfluxSDK := sdk.NewSDK(sdk.Config{BootstrapURL: ts.URL, BootstrapPrefix: "things"})
+
+	cases := []struct {
+		desc    string
+		key     string
+		encKey  string
+		content string
+		err     error
+	}{
+		{
+			desc:    "fetch config with encryption",
+			key:     "key-1",
+			encKey:  bsEncKey,
+			content: "config",
+			err:     nil,
+		},
+		{
+			desc:   "fetch config with wrong external key",
+			key:    "key-2",
+			encKey: bsEncKey,
+			err:    sdk.ErrFailedFetch,
+		},
+		{
+			desc:   "fetch config with invalid encryption key",
+			key:    "key-1",
+			encKey: "short",
+			err:    sdk.ErrInvalidEncKey,
+		},
+	}
+
+	for _, tc := range cases {
+		cfg, err := mainfluxSDK.BootstrapSecure(tc.key, "ext-1", tc.encKey)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.content, cfg.Content, fmt.Sprintf("%s: expected content %s got %s", tc.desc, tc.content, cfg.Content))
+	}
+
+	cfg, err := mainfluxSDK.Bootstrap("key-1", "ext-1")
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, "thing-key", cfg.MFKey, "expected plain config to match the secure one")
+}
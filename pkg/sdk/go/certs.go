@@ -6,15 +6,28 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
 )
 
 const certsEndpoint = "certs"
 
 // Cert represents certs data.
 type Cert struct {
-	CACert     string `json:"issuing_ca,omitempty"`
-	ClientKey  string `json:"client_key,omitempty"`
-	ClientCert string `json:"client_cert,omitempty"`
+	ThingID    string     `json:"thing_id,omitempty"`
+	Serial     string     `json:"cert_serial,omitempty"`
+	CACert     string     `json:"ca_cert,omitempty"`
+	ClientKey  string     `json:"cert_key,omitempty"`
+	ClientCert string     `json:"cert,omitempty"`
+	Expire     time.Time  `json:"expire,omitempty"`
+	Revoked    *time.Time `json:"revoked,omitempty"`
+}
+
+// CertsPage contains list of certificates in a page with proper metadata.
+type CertsPage struct {
+	Certs []Cert `json:"certs"`
+	pageRes
 }
 
 func (sdk mfSDK) IssueCert(thingID string, keyBits int, keyType, valid, token string) (Cert, error) {
@@ -30,25 +43,81 @@ func (sdk mfSDK) IssueCert(thingID string, keyBits int, keyType, valid, token st
 		return Cert{}, err
 	}
 	url := createURL(sdk.certsURL, sdk.certsPrefix, certsEndpoint)
-	res, err := request(http.MethodPost, token, url, d)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(d))
+	if err != nil {
+		return Cert{}, err
+	}
+	res, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return Cert{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return Cert{}, certsError(ErrCerts, res)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return Cert{}, err
+	}
+	if err := json.Unmarshal(body, &c); err != nil {
+		return Cert{}, err
+	}
+	return c, nil
+}
+
+func (sdk mfSDK) ViewCert(serialID, token string) (Cert, error) {
+	endpoint := fmt.Sprintf("%s/serial/%s", certsEndpoint, serialID)
+	url := createURL(sdk.certsURL, sdk.certsPrefix, endpoint)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return Cert{}, err
+	}
+	res, err := sdk.sendRequest(req, token, string(CTJSON))
 	if err != nil {
 		return Cert{}, err
 	}
 	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK {
-		return Cert{}, ErrCerts
+		return Cert{}, certsError(ErrCerts, res)
 	}
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		println(err.Error())
 		return Cert{}, err
 	}
+	var c Cert
 	if err := json.Unmarshal(body, &c); err != nil {
 		return Cert{}, err
 	}
 	return c, nil
 }
 
+func (sdk mfSDK) ListCerts(thingID, token string, offset, limit uint64) (CertsPage, error) {
+	endpoint := fmt.Sprintf("%s/%s?offset=%d&limit=%d", certsEndpoint, thingID, offset, limit)
+	url := createURL(sdk.certsURL, sdk.certsPrefix, endpoint)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return CertsPage{}, err
+	}
+	res, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return CertsPage{}, err
+	}
+	defer res.Body.Close()
+	// Certs service responds to listing with 201.
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return CertsPage{}, certsError(ErrCerts, res)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return CertsPage{}, err
+	}
+	var cp CertsPage
+	if err := json.Unmarshal(body, &cp); err != nil {
+		return CertsPage{}, err
+	}
+	return cp, nil
+}
+
 func (sdk mfSDK) RemoveCert(id, token string) error {
 	res, err := request(http.MethodDelete, token, fmt.Sprintf("%s/%s", sdk.certsURL, id), nil)
 	if res != nil {
@@ -67,8 +136,54 @@ func (sdk mfSDK) RemoveCert(id, token string) error {
 	}
 }
 
-func (sdk mfSDK) RevokeCert(thingID, certID string, token string) error {
-	panic("not implemented")
+func (sdk mfSDK) RevokeCert(thingID, token string) (time.Time, error) {
+	endpoint := fmt.Sprintf("%s/%s", certsEndpoint, thingID)
+	return sdk.revokeCert(endpoint, token)
+}
+
+func (sdk mfSDK) RevokeCertBySerial(serialID, token string) (time.Time, error) {
+	endpoint := fmt.Sprintf("%s/serial/%s", certsEndpoint, serialID)
+	return sdk.revokeCert(endpoint, token)
+}
+
+func (sdk mfSDK) revokeCert(endpoint, token string) (time.Time, error) {
+	url := createURL(sdk.certsURL, sdk.certsPrefix, endpoint)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	res, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return time.Time{}, certsError(ErrCertsRevoke, res)
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var rr revokeCertRes
+	if err := json.Unmarshal(body, &rr); err != nil {
+		return time.Time{}, err
+	}
+	return rr.RevocationTime, nil
+}
+
+// certsError wraps the operation error with the error matching the
+// response status, so that callers can tell the failure reason.
+func certsError(op error, res *http.Response) error {
+	switch res.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.Wrap(op, ErrUnauthorized)
+	case http.StatusNotFound:
+		return errors.Wrap(op, ErrNotFound)
+	case http.StatusConflict:
+		return errors.Wrap(op, ErrCertRevoked)
+	default:
+		return errors.Wrap(op, errors.New(res.Status))
+	}
 }
 
 func request(method, jwt, url string, data []byte) (*http.Response, error) {
@@ -0,0 +1,243 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk_test
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/certs"
+	certsapi "github.com/mainflux/mainflux/certs/api"
+	"github.com/mainflux/mainflux/pkg/errors"
+	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	serial  = "01:02:03"
+	thingID = "thing"
+)
+
+var revoked = time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// certsServiceStub serves a single certificate of a single thing.
+type certsServiceStub struct {
+	revoked *time.Time
+}
+
+func (css *certsServiceStub) IssueCert(ctx context.Context, tkn, thID, daysValid string, keyBits int, keyType string) (certs.Cert, error) {
+	if tkn != token {
+		return certs.Cert{}, certs.ErrUnauthorizedAccess
+	}
+	return certs.Cert{ThingID: thID, Serial: serial, ClientCert: "cert", ClientKey: "key", IssuingCA: "ca"}, nil
+}
+
+func (css *certsServiceStub) ListCerts(ctx context.Context, tkn, thID string, offset, limit uint64) (certs.Page, error) {
+	if tkn != token {
+		return certs.Page{}, certs.ErrUnauthorizedAccess
+	}
+	return certs.Page{Total: 1, Offset: offset, Limit: limit, Certs: []certs.Cert{{ThingID: thID, Serial: serial}}}, nil
+}
+
+func (css *certsServiceStub) ViewCert(ctx context.Context, tkn, serialID string) (certs.Cert, error) {
+	if tkn != token {
+		return certs.Cert{}, certs.ErrUnauthorizedAccess
+	}
+	if serialID != serial {
+		return certs.Cert{}, certs.ErrNotFound
+	}
+	return certs.Cert{ThingID: thingID, Serial: serial, Revoked: css.revoked}, nil
+}
+
+func (css *certsServiceStub) RevokeCert(ctx context.Context, tkn, thID string) (certs.Revoke, error) {
+	if tkn != token {
+		return certs.Revoke{}, certs.ErrUnauthorizedAccess
+	}
+	if thID != thingID {
+		return certs.Revoke{}, errors.Wrap(certs.ErrFailedCertRevocation, certs.ErrNotFound)
+	}
+	if css.revoked != nil {
+		return certs.Revoke{}, certs.ErrCertRevoked
+	}
+	css.revoked = &revoked
+	return certs.Revoke{RevocationTime: revoked}, nil
+}
+
+func (css *certsServiceStub) RevokeCertBySerial(ctx context.Context, tkn, serialID string) (certs.Revoke, error) {
+	if tkn != token {
+		return certs.Revoke{}, certs.ErrUnauthorizedAccess
+	}
+	if serialID != serial {
+		return certs.Revoke{}, errors.Wrap(certs.ErrFailedCertRevocation, certs.ErrNotFound)
+	}
+	if css.revoked != nil {
+		return certs.Revoke{}, certs.ErrCertRevoked
+	}
+	css.revoked = &revoked
+	return certs.Revoke{RevocationTime: revoked}, nil
+}
+
+func newCertsSDK() (sdk.SDK, *httptest.Server) {
+	ts := httptest.NewServer(certsapi.MakeHandler(&certsServiceStub{}))
+	return sdk.NewSDK(sdk.Config{CertsURL: ts.URL}), ts
+}
+
+func TestIssueCert(t *testing.T) {
+	mainfluxSDK, ts := newCertsSDK()
+	defer ts.Close()
+
+	c, err := mainfluxSDK.IssueCert(thingID, 2048, "rsa", "1h", token)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	expected := sdk.Cert{ThingID: thingID, Serial: serial, ClientCert: "cert", ClientKey: "key", CACert: "ca"}
+	assert.Equal(t, expected, c, fmt.Sprintf("expected cert %v got %v", expected, c))
+
+	_, err = mainfluxSDK.IssueCert(thingID, 2048, "rsa", "1h", wrongValue)
+	assert.True(t, errors.Contains(err, sdk.ErrUnauthorized), fmt.Sprintf("expected error %s got %s", sdk.ErrUnauthorized, err))
+}
+
+func TestViewCert(t *testing.T) {
+	mainfluxSDK, ts := newCertsSDK()
+	defer ts.Close()
+
+	cases := []struct {
+		desc   string
+		serial string
+		token  string
+		err    error
+	}{
+		{
+			desc:   "view cert",
+			serial: serial,
+			token:  token,
+			err:    nil,
+		},
+		{
+			desc:   "view cert with invalid token",
+			serial: serial,
+			token:  wrongValue,
+			err:    sdk.ErrUnauthorized,
+		},
+		{
+			desc:   "view non-existing cert",
+			serial: wrongValue,
+			token:  token,
+			err:    sdk.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		c, err := mainfluxSDK.ViewCert(tc.serial, tc.token)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		if tc.err == nil {
+			assert.Equal(t, thingID, c.ThingID, fmt.Sprintf("%s: expected thing %s got %s", tc.desc, thingID, c.ThingID))
+		}
+	}
+}
+
+func TestListCerts(t *testing.T) {
+	mainfluxSDK, ts := newCertsSDK()
+	defer ts.Close()
+
+	cp, err := mainfluxSDK.ListCerts(thingID, token, 0, 10)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, []sdk.Cert{{ThingID: thingID, Serial: serial}}, cp.Certs, "unexpected certs page")
+	assert.Equal(t, uint64(1), cp.Total, fmt.Sprintf("expected total 1 got %d", cp.Total))
+}
+
+func TestRevokeCert(t *testing.T) {
+	mainfluxSDK, ts := newCertsSDK()
+	defer ts.Close()
+
+	cases := []struct {
+		desc    string
+		thingID string
+		token   string
+		time    time.Time
+		err     error
+	}{
+		{
+			desc:    "revoke cert with invalid token",
+			thingID: thingID,
+			token:   wrongValue,
+			err:     sdk.ErrUnauthorized,
+		},
+		{
+			desc:    "revoke cert of non-existing thing",
+			thingID: wrongValue,
+			token:   token,
+			err:     sdk.ErrNotFound,
+		},
+		{
+			desc:    "revoke cert",
+			thingID: thingID,
+			token:   token,
+			time:    revoked,
+			err:     nil,
+		},
+		{
+			desc:    "revoke already revoked cert",
+			thingID: thingID,
+			token:   token,
+			err:     sdk.ErrCertRevoked,
+		},
+	}
+
+	for _, tc := range cases {
+		rt, err := mainfluxSDK.RevokeCert(tc.thingID, tc.token)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.True(t, tc.time.Equal(rt), fmt.Sprintf("%s: expected revocation time %s got %s", tc.desc, tc.time, rt))
+	}
+
+	c, err := mainfluxSDK.ViewCert(serial, token)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.NotNil(t, c.Revoked, "expected revoked cert to have revocation time")
+}
+
+func TestRevokeCertBySerial(t *testing.T) {
+	mainfluxSDK, ts := newCertsSDK()
+	defer ts.Close()
+
+	cases := []struct {
+		desc   string
+		serial string
+		token  string
+		time   time.Time
+		err    error
+	}{
+		{
+			desc:   "revoke cert by serial with invalid token",
+			serial: serial,
+			token:  wrongValue,
+			err:    sdk.ErrUnauthorized,
+		},
+		{
+			desc:   "revoke cert by non-existing serial",
+			serial: wrongValue,
+			token:  token,
+			err:    sdk.ErrNotFound,
+		},
+		{
+			desc:   "revoke cert by serial",
+			serial: serial,
+			token:  token,
+			time:   revoked,
+			err:    nil,
+		},
+		{
+			desc:   "revoke already revoked cert by serial",
+			serial: serial,
+			token:  token,
+			err:    sdk.ErrCertRevoked,
+		},
+	}
+
+	for _, tc := range cases {
+		rt, err := mainfluxSDK.RevokeCertBySerial(tc.serial, tc.token)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.True(t, tc.time.Equal(rt), fmt.Sprintf("%s: expected revocation time %s got %s", tc.desc, tc.time, rt))
+	}
+}
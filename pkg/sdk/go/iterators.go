@@ -0,0 +1,202 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"context"
+	"errors"
+)
+
+const defPageLimit = 100
+
+// ErrIteratorDone is returned by iterators when there are no more pages.
+var ErrIteratorDone = errors.New("no more pages")
+
+// ThingsPageFunc fetches a page of things starting at offset.
+type ThingsPageFunc func(offset, limit uint64) (ThingsPage, error)
+
+// ChannelsPageFunc fetches a page of channels starting at offset.
+type ChannelsPageFunc func(offset, limit uint64) (ChannelsPage, error)
+
+// ListThings returns ThingsPageFunc that lists things with the given name.
+func ListThings(s SDK, token, name string) ThingsPageFunc {
+	return func(offset, limit uint64) (ThingsPage, error) {
+		return s.Things(token, offset, limit, name)
+	}
+}
+
+// ListThingsByChannel returns ThingsPageFunc that lists things connected
+// or not connected to the given channel.
+func ListThingsByChannel(s SDK, token, chanID string, disconn bool) ThingsPageFunc {
+	return func(offset, limit uint64) (ThingsPage, error) {
+		return s.ThingsByChannel(token, chanID, offset, limit, disconn)
+	}
+}
+
+// ListChannels returns ChannelsPageFunc that lists channels with the given name.
+func ListChannels(s SDK, token, name string) ChannelsPageFunc {
+	return func(offset, limit uint64) (ChannelsPage, error) {
+		return s.Channels(token, offset, limit, name)
+	}
+}
+
+// ListChannelsByThing returns ChannelsPageFunc that lists channels connected
+// or not connected to the given thing.
+func ListChannelsByThing(s SDK, token, thingID string, disconn bool) ChannelsPageFunc {
+	return func(offset, limit uint64) (ChannelsPage, error) {
+		return s.ChannelsByThing(token, thingID, offset, limit, disconn)
+	}
+}
+
+// cursor keeps track of iteration progress. The offset is advanced by the
+// number of received items and iteration ends on the first empty or short
+// page or once the smallest reported total is reached. Items created during
+// the iteration can't grow the total, so iteration always terminates.
+type cursor struct {
+	offset uint64
+	limit  uint64
+	total  uint64
+	init   bool
+	done   bool
+}
+
+func newCursor(limit uint64) cursor {
+	if limit == 0 {
+		limit = defPageLimit
+	}
+	return cursor{limit: limit}
+}
+
+func (c *cursor) advance(n int, total uint64) {
+	if !c.init || total < c.total {
+		c.total = total
+		c.init = true
+	}
+	c.offset += uint64(n)
+	if n == 0 || uint64(n) < c.limit || c.offset >= c.total {
+		c.done = true
+	}
+}
+
+// ThingsIterator iterates over pages of things.
+type ThingsIterator struct {
+	fetch ThingsPageFunc
+	cur   cursor
+}
+
+// NewThingsIterator returns new things iterator fetching pages of the
+// given size. If limit is zero, default page size is used.
+func NewThingsIterator(fetch ThingsPageFunc, limit uint64) *ThingsIterator {
+	return &ThingsIterator{
+		fetch: fetch,
+		cur:   newCursor(limit),
+	}
+}
+
+// Next returns next page of things or ErrIteratorDone if there are no more pages.
+func (it *ThingsIterator) Next(ctx context.Context) (ThingsPage, error) {
+	if it.cur.done {
+		return ThingsPage{}, ErrIteratorDone
+	}
+	if err := ctx.Err(); err != nil {
+		return ThingsPage{}, err
+	}
+
+	tp, err := it.fetch(it.cur.offset, it.cur.limit)
+	if err != nil {
+		return ThingsPage{}, err
+	}
+	it.cur.advance(len(tp.Things), tp.Total)
+
+	return tp, nil
+}
+
+// All returns all the remaining things, up to max items. If max is zero,
+// the number of items is not limited. Things reported more than once due
+// to changes during the iteration are returned only once.
+func (it *ThingsIterator) All(ctx context.Context, max uint64) ([]Thing, error) {
+	var ths []Thing
+	seen := make(map[string]bool)
+	for {
+		tp, err := it.Next(ctx)
+		if err == ErrIteratorDone {
+			return ths, nil
+		}
+		if err != nil {
+			return ths, err
+		}
+		for _, th := range tp.Things {
+			if seen[th.ID] {
+				continue
+			}
+			seen[th.ID] = true
+			ths = append(ths, th)
+			if max > 0 && uint64(len(ths)) >= max {
+				it.cur.done = true
+				return ths, nil
+			}
+		}
+	}
+}
+
+// ChannelsIterator iterates over pages of channels.
+type ChannelsIterator struct {
+	fetch ChannelsPageFunc
+	cur   cursor
+}
+
+// NewChannelsIterator returns new channels iterator fetching pages of the
+// given size. If limit is zero, default page size is used.
+func NewChannelsIterator(fetch ChannelsPageFunc, limit uint64) *ChannelsIterator {
+	return &ChannelsIterator{
+		fetch: fetch,
+		cur:   newCursor(limit),
+	}
+}
+
+// Next returns next page of channels or ErrIteratorDone if there are no more pages.
+func (it *ChannelsIterator) Next(ctx context.Context) (ChannelsPage, error) {
+	if it.cur.done {
+		return ChannelsPage{}, ErrIteratorDone
+	}
+	if err := ctx.Err(); err != nil {
+		return ChannelsPage{}, err
+	}
+
+	cp, err := it.fetch(it.cur.offset, it.cur.limit)
+	if err != nil {
+		return ChannelsPage{}, err
+	}
+	it.cur.advance(len(cp.Channels), cp.Total)
+
+	return cp, nil
+}
+
+// All returns all the remaining channels, up to max items. If max is zero,
+// the number of items is not limited. Channels reported more than once due
+// to changes during the iteration are returned only once.
+func (it *ChannelsIterator) All(ctx context.Context, max uint64) ([]Channel, error) {
+	var chs []Channel
+	seen := make(map[string]bool)
+	for {
+		cp, err := it.Next(ctx)
+		if err == ErrIteratorDone {
+			return chs, nil
+		}
+		if err != nil {
+			return chs, err
+		}
+		for _, ch := range cp.Channels {
+			if seen[ch.ID] {
+				continue
+			}
+			seen[ch.ID] = true
+			chs = append(chs, ch)
+			if max > 0 && uint64(len(chs)) >= max {
+				it.cur.done = true
+				return chs, nil
+			}
+		}
+	}
+}
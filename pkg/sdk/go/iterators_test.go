@@ -0,0 +1,209 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// shiftingThings simulates a listing in which delta items are created
+// (or deleted, if negative) after each request.
+func shiftingThings(total, delta int) (sdk.ThingsPageFunc, *int) {
+	calls := 0
+	return func(offset, limit uint64) (sdk.ThingsPage, error) {
+		calls++
+		tp := sdk.ThingsPage{}
+		for i := int(offset); i < total && i < int(offset+limit); i++ {
+			tp.Things = append(tp.Things, sdk.Thing{ID: fmt.Sprintf("%03d", i)})
+		}
+		tp.Total = uint64(total)
+		tp.Offset = offset
+		tp.Limit = limit
+		total += delta
+		if total < 0 {
+			total = 0
+		}
+		return tp, nil
+	}, &calls
+}
+
+func shiftingChannels(total, delta int) sdk.ChannelsPageFunc {
+	return func(offset, limit uint64) (sdk.ChannelsPage, error) {
+		cp := sdk.ChannelsPage{}
+		for i := int(offset); i < total && i < int(offset+limit); i++ {
+			cp.Channels = append(cp.Channels, sdk.Channel{ID: fmt.Sprintf("%03d", i)})
+		}
+		cp.Total = uint64(total)
+		total += delta
+		if total < 0 {
+			total = 0
+		}
+		return cp, nil
+	}
+}
+
+func TestThingsIterator(t *testing.T) {
+	cases := []struct {
+		desc  string
+		total int
+		delta int
+		limit uint64
+		max   uint64
+		size  int
+		calls int
+	}{
+		{
+			desc:  "iterate over empty list",
+			total: 0,
+			limit: 10,
+			size:  0,
+			calls: 1,
+		},
+		{
+			desc:  "iterate over list with total divisible by limit",
+			total: 20,
+			limit: 10,
+			size:  20,
+			calls: 2,
+		},
+		{
+			desc:  "iterate over list with last short page",
+			total: 25,
+			limit: 10,
+			size:  25,
+			calls: 3,
+		},
+		{
+			desc:  "iterate with max items cap",
+			total: 25,
+			limit: 10,
+			max:   15,
+			size:  15,
+			calls: 2,
+		},
+		{
+			desc:  "iterate over list with items created mid-iteration",
+			total: 20,
+			delta: 5,
+			limit: 10,
+			size:  20,
+			calls: 2,
+		},
+		{
+			desc:  "iterate over endlessly growing list",
+			total: 10,
+			delta: 10,
+			limit: 10,
+			size:  10,
+			calls: 1,
+		},
+		{
+			desc:  "iterate over list with items deleted mid-iteration",
+			total: 30,
+			delta: -10,
+			limit: 10,
+			size:  20,
+			calls: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		fetch, calls := shiftingThings(tc.total, tc.delta)
+		it := sdk.NewThingsIterator(fetch, tc.limit)
+		ths, err := it.All(context.Background(), tc.max)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Len(t, ths, tc.size, fmt.Sprintf("%s: expected %d things got %d", tc.desc, tc.size, len(ths)))
+		assert.Equal(t, tc.calls, *calls, fmt.Sprintf("%s: expected %d requests got %d", tc.desc, tc.calls, *calls))
+
+		_, err = it.Next(context.Background())
+		assert.Equal(t, sdk.ErrIteratorDone, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, sdk.ErrIteratorDone, err))
+	}
+}
+
+func TestThingsIteratorErrors(t *testing.T) {
+	fetchErr := errors.New("fetch failed")
+	it := sdk.NewThingsIterator(func(offset, limit uint64) (sdk.ThingsPage, error) {
+		return sdk.ThingsPage{}, fetchErr
+	}, 10)
+	_, err := it.All(context.Background(), 0)
+	assert.Equal(t, fetchErr, err, fmt.Sprintf("fetch error: expected %s got %s", fetchErr, err))
+
+	fetch, calls := shiftingThings(10, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = sdk.NewThingsIterator(fetch, 10).Next(ctx)
+	assert.Equal(t, context.Canceled, err, fmt.Sprintf("canceled context: expected %s got %s", context.Canceled, err))
+	assert.Equal(t, 0, *calls, fmt.Sprintf("canceled context: expected no requests got %d", *calls))
+}
+
+func TestChannelsIterator(t *testing.T) {
+	cases := []struct {
+		desc  string
+		total int
+		delta int
+		limit uint64
+		max   uint64
+		size  int
+	}{
+		{
+			desc:  "iterate over list with default limit",
+			total: 150,
+			size:  150,
+		},
+		{
+			desc:  "iterate with max items cap",
+			total: 30,
+			limit: 7,
+			max:   10,
+			size:  10,
+		},
+		{
+			desc:  "iterate over list with items created mid-iteration",
+			total: 10,
+			delta: 1,
+			limit: 5,
+			size:  10,
+		},
+		{
+			desc:  "iterate over list with items deleted mid-iteration",
+			total: 10,
+			delta: -3,
+			limit: 5,
+			size:  7,
+		},
+	}
+
+	for _, tc := range cases {
+		it := sdk.NewChannelsIterator(shiftingChannels(tc.total, tc.delta), tc.limit)
+		chs, err := it.All(context.Background(), tc.max)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Len(t, chs, tc.size, fmt.Sprintf("%s: expected %d channels got %d", tc.desc, tc.size, len(chs)))
+	}
+}
+
+func TestListThingsIterator(t *testing.T) {
+	svc := newThingsService(map[string]string{token: email})
+	ts := newThingsServer(svc)
+	defer ts.Close()
+
+	mainfluxSDK := sdk.NewSDK(sdk.Config{BaseURL: ts.URL, MsgContentType: contentType})
+
+	n := 25
+	for i := 0; i < n; i++ {
+		_, err := mainfluxSDK.CreateThing(sdk.Thing{Name: fmt.Sprintf("thing-%d", i)}, token)
+		require.Nil(t, err, fmt.Sprintf("unexpected error creating thing: %s", err))
+	}
+
+	it := sdk.NewThingsIterator(sdk.ListThings(mainfluxSDK, token, ""), 10)
+	ths, err := it.All(context.Background(), 0)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Len(t, ths, n, fmt.Sprintf("expected %d things got %d", n, len(ths)))
+}
@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/mainflux/mainflux/pkg/errors"
@@ -79,7 +81,55 @@ func (sdk mfSDK) ReadMessages(chanName, token string) (MessagesPage, error) {
 	return mp, nil
 }
 
-func (sdk mfSDK) SetContentType(ct ContentType) error {
+func (sdk mfSDK) QueryMessages(chanID, token string, q MessagesQuery) (MessagesPage, error) {
+	query := url.Values{}
+	query.Set("offset", strconv.FormatUint(q.Offset, 10))
+	query.Set("limit", strconv.FormatUint(q.Limit, 10))
+	if q.Subtopic != "" {
+		query.Set("subtopic", q.Subtopic)
+	}
+	if q.Publisher != "" {
+		query.Set("publisher", q.Publisher)
+	}
+	if q.From != 0 {
+		query.Set("from", strconv.FormatFloat(q.From, 'f', -1, 64))
+	}
+	if q.To != 0 {
+		query.Set("to", strconv.FormatFloat(q.To, 'f', -1, 64))
+	}
+
+	endpoint := fmt.Sprintf("channels/%s/messages?%s", chanID, query.Encode())
+	url := createURL(sdk.readerURL, "", endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return MessagesPage{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(sdk.msgContentType))
+	if err != nil {
+		return MessagesPage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return MessagesPage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return MessagesPage{}, errors.Wrap(ErrFailedRead, errors.New(resp.Status))
+	}
+
+	var mp MessagesPage
+	if err := json.Unmarshal(body, &mp); err != nil {
+		return MessagesPage{}, err
+	}
+
+	return mp, nil
+}
+
+func (sdk *mfSDK) SetContentType(ct ContentType) error {
 	if ct != CTJSON && ct != CTJSONSenML && ct != CTBinary {
 		return ErrInvalidContentType
 	}
@@ -7,12 +7,14 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/mainflux/mainflux"
 	adapter "github.com/mainflux/mainflux/http"
 	"github.com/mainflux/mainflux/http/api"
 	"github.com/mainflux/mainflux/http/mocks"
+	"github.com/mainflux/mainflux/internal/topics"
 	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
@@ -24,7 +26,7 @@ func newMessageService(cc mainflux.ThingsServiceClient) adapter.Service {
 }
 
 func newMessageServer(svc adapter.Service) *httptest.Server {
-	mux := api.MakeHandler(svc, mocktracer.New())
+	mux := api.MakeHandler(svc, mocktracer.New(), "", topics.Default(), nil, nil)
 	return httptest.NewServer(mux)
 }
 
@@ -139,3 +141,71 @@ func TestSetContentType(t *testing.T) {
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
 	}
 }
+
+func TestQueryMessages(t *testing.T) {
+	var query url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.Header.Get("Authorization") != token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"total":1,"offset":0,"limit":10,"messages":[{"channel":"1","name":"temp","time":1}]}`))
+	}))
+	defer ts.Close()
+
+	mainfluxSDK := sdk.NewSDK(sdk.Config{ReaderURL: ts.URL, MsgContentType: contentType})
+
+	cases := []struct {
+		desc  string
+		token string
+		q     sdk.MessagesQuery
+		query url.Values
+		total uint64
+		err   error
+	}{
+		{
+			desc:  "query messages with all filters",
+			token: token,
+			q:     sdk.MessagesQuery{Offset: 5, Limit: 10, Subtopic: "a/b", Publisher: "pub", From: 1.5, To: 2},
+			query: url.Values{
+				"offset":    []string{"5"},
+				"limit":     []string{"10"},
+				"subtopic":  []string{"a/b"},
+				"publisher": []string{"pub"},
+				"from":      []string{"1.5"},
+				"to":        []string{"2"},
+			},
+			total: 1,
+			err:   nil,
+		},
+		{
+			desc:  "query messages without filters",
+			token: token,
+			q:     sdk.MessagesQuery{Limit: 10},
+			query: url.Values{
+				"offset": []string{"0"},
+				"limit":  []string{"10"},
+			},
+			total: 1,
+			err:   nil,
+		},
+		{
+			desc:  "query messages with invalid token",
+			token: wrongValue,
+			q:     sdk.MessagesQuery{Limit: 10},
+			query: url.Values{
+				"offset": []string{"0"},
+				"limit":  []string{"10"},
+			},
+			total: 0,
+			err:   createError(sdk.ErrFailedRead, http.StatusForbidden),
+		},
+	}
+	for _, tc := range cases {
+		mp, err := mainfluxSDK.QueryMessages("1", tc.token, tc.q)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.query, query, fmt.Sprintf("%s: expected query %v, got %v", tc.desc, tc.query, query))
+		assert.Equal(t, tc.total, mp.Total, fmt.Sprintf("%s: expected total %d, got %d", tc.desc, tc.total, mp.Total))
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	defQueueSize     = 1024
+	defBatchSize     = 1
+	defConcurrency   = 1
+	defFlushInterval = time.Second
+)
+
+// OverflowPolicy defines publisher behaviour when its queue is full.
+type OverflowPolicy int
+
+const (
+	// Block makes Publish wait until there is room in the queue.
+	Block OverflowPolicy = iota
+
+	// Drop makes Publish return ErrQueueFull immediately.
+	Drop
+)
+
+// Message represents a message queued for asynchronous publishing.
+type Message struct {
+	Channel string
+	Payload string
+	Token   string
+}
+
+// PublishResult contains the outcome of publishing a single message.
+type PublishResult struct {
+	Message Message
+	Err     error
+}
+
+// AsyncConfig contains asynchronous publisher configuration parameters.
+type AsyncConfig struct {
+	// QueueSize is the maximum number of messages waiting to be published.
+	QueueSize int
+
+	// BatchSize is the maximum number of messages sent to a single channel
+	// in one request. If greater than one, payloads that are JSON arrays
+	// (e.g. SenML packs) are merged into a single array.
+	BatchSize int
+
+	// FlushInterval is the maximum time a message waits for its batch
+	// to fill up.
+	FlushInterval time.Duration
+
+	// Concurrency is the maximum number of requests in flight.
+	Concurrency int
+
+	// Overflow is the policy applied when the queue is full.
+	Overflow OverflowPolicy
+
+	// OnResult, if set, is called with the outcome of every published message.
+	OnResult func(PublishResult)
+}
+
+// AsyncPublisher publishes messages in the background.
+type AsyncPublisher interface {
+	// Publish enqueues message for publishing.
+	Publish(msg Message) error
+
+	// Flush publishes all the enqueued messages and waits for them to be sent.
+	Flush() error
+
+	// Close flushes the enqueued messages and stops the publisher.
+	Close() error
+}
+
+var _ AsyncPublisher = (*asyncPublisher)(nil)
+
+type batchKey struct {
+	channel string
+	token   string
+}
+
+type asyncPublisher struct {
+	sdk     SDK
+	cfg     AsyncConfig
+	queue   chan Message
+	flushes chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	sem     chan struct{}
+	pending map[batchKey][]Message
+	wg      sync.WaitGroup
+	// inflight tracks the Publish calls sending to the queue, so Close
+	// closes the queue once none of them can send to it.
+	inflight sync.WaitGroup
+	mu       sync.RWMutex
+	closed   bool
+}
+
+// NewAsyncPublisher returns new asynchronous publisher that sends messages
+// using the given SDK instance.
+func NewAsyncPublisher(sdk SDK, cfg AsyncConfig) AsyncPublisher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defQueueSize
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defBatchSize
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = defConcurrency
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defFlushInterval
+	}
+
+	p := &asyncPublisher{
+		sdk:     sdk,
+		cfg:     cfg,
+		queue:   make(chan Message, cfg.QueueSize),
+		flushes: make(chan chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		sem:     make(chan struct{}, cfg.Concurrency),
+		pending: make(map[batchKey][]Message),
+	}
+	go p.run()
+
+	return p
+}
+
+func (p *asyncPublisher) Publish(msg Message) error {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrPublisherClosed
+	}
+	p.inflight.Add(1)
+	p.mu.RUnlock()
+	defer p.inflight.Done()
+
+	if p.cfg.Overflow == Drop {
+		select {
+		case p.queue <- msg:
+			return nil
+		default:
+			return ErrQueueFull
+		}
+	}
+
+	// The lock isn't held while waiting for room in the queue, so Close
+	// isn't blocked by the full queue and releases the waiting calls.
+	select {
+	case p.queue <- msg:
+		return nil
+	case <-p.stop:
+		return ErrPublisherClosed
+	}
+}
+
+func (p *asyncPublisher) Flush() error {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return ErrPublisherClosed
+	}
+	ack := make(chan struct{})
+	p.flushes <- ack
+	p.mu.RUnlock()
+
+	<-ack
+	return nil
+}
+
+func (p *asyncPublisher) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPublisherClosed
+	}
+	p.closed = true
+	close(p.stop)
+	p.mu.Unlock()
+
+	p.inflight.Wait()
+	close(p.queue)
+	<-p.done
+	return nil
+}
+
+func (p *asyncPublisher) run() {
+	ticker := time.NewTicker(p.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg, ok := <-p.queue:
+			if !ok {
+				p.dispatchAll()
+				p.wg.Wait()
+				close(p.done)
+				return
+			}
+			p.add(msg)
+		case <-ticker.C:
+			p.dispatchAll()
+		case ack := <-p.flushes:
+			for n := len(p.queue); n > 0; n-- {
+				p.add(<-p.queue)
+			}
+			p.dispatchAll()
+			p.wg.Wait()
+			close(ack)
+		}
+	}
+}
+
+func (p *asyncPublisher) add(msg Message) {
+	key := batchKey{channel: msg.Channel, token: msg.Token}
+	p.pending[key] = append(p.pending[key], msg)
+	if len(p.pending[key]) >= p.cfg.BatchSize {
+		p.dispatch(key)
+	}
+}
+
+func (p *asyncPublisher) dispatchAll() {
+	for key := range p.pending {
+		p.dispatch(key)
+	}
+}
+
+func (p *asyncPublisher) dispatch(key batchKey) {
+	batch := p.pending[key]
+	delete(p.pending, key)
+	if len(batch) == 0 {
+		return
+	}
+
+	p.sem <- struct{}{}
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			<-p.sem
+			p.wg.Done()
+		}()
+		p.send(key, batch)
+	}()
+}
+
+func (p *asyncPublisher) send(key batchKey, batch []Message) {
+	if len(batch) > 1 {
+		if payload, ok := merge(batch); ok {
+			err := p.sdk.SendMessage(key.channel, payload, key.token)
+			for _, msg := range batch {
+				p.report(msg, err)
+			}
+			return
+		}
+	}
+
+	for _, msg := range batch {
+		p.report(msg, p.sdk.SendMessage(msg.Channel, msg.Payload, msg.Token))
+	}
+}
+
+func (p *asyncPublisher) report(msg Message, err error) {
+	if p.cfg.OnResult != nil {
+		p.cfg.OnResult(PublishResult{Message: msg, Err: err})
+	}
+}
+
+// merge combines JSON array payloads into a single array. If any of the
+// payloads is not a JSON array, messages can't be merged.
+func merge(batch []Message) (string, bool) {
+	var merged []json.RawMessage
+	for _, msg := range batch {
+		var pack []json.RawMessage
+		if err := json.Unmarshal([]byte(msg.Payload), &pack); err != nil {
+			return "", false
+		}
+		merged = append(merged, pack...)
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return "", false
+	}
+
+	return string(data), true
+}
@@ -0,0 +1,261 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	bodies  map[string][]string
+	results []sdk.PublishResult
+	// received, if set, is notified of every request before it's released.
+	received chan struct{}
+	release  chan struct{}
+}
+
+func newRecorder() *recorder {
+	return &recorder{bodies: make(map[string][]string)}
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.received != nil {
+		r.received <- struct{}{}
+	}
+	if r.release != nil {
+		<-r.release
+	}
+	body, _ := ioutil.ReadAll(req.Body)
+	r.mu.Lock()
+	r.bodies[req.URL.Path] = append(r.bodies[req.URL.Path], string(body))
+	r.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (r *recorder) onResult(res sdk.PublishResult) {
+	r.mu.Lock()
+	r.results = append(r.results, res)
+	r.mu.Unlock()
+}
+
+func (r *recorder) requests(path string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.bodies[path]
+}
+
+func newAsyncSDK(url string) sdk.SDK {
+	return sdk.NewSDK(sdk.Config{
+		BaseURL:         url,
+		MsgContentType:  contentType,
+		TLSVerification: false,
+	})
+}
+
+func TestAsyncPublisherBatching(t *testing.T) {
+	rec := newRecorder()
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+
+	pub := sdk.NewAsyncPublisher(newAsyncSDK(ts.URL), sdk.AsyncConfig{
+		BatchSize:     3,
+		FlushInterval: time.Hour,
+		Concurrency:   2,
+		OnResult:      rec.onResult,
+	})
+
+	for i := 0; i < 6; i++ {
+		msg := sdk.Message{Channel: "1", Payload: fmt.Sprintf(`[{"n":"m%d","v":%d}]`, i, i), Token: token}
+		err := pub.Publish(msg)
+		require.Nil(t, err, fmt.Sprintf("unexpected error publishing message %d: %s", i, err))
+	}
+	err := pub.Publish(sdk.Message{Channel: "2", Payload: `[{"n":"m","v":1}]`, Token: token})
+	require.Nil(t, err, fmt.Sprintf("unexpected error publishing message: %s", err))
+
+	err = pub.Flush()
+	assert.Nil(t, err, fmt.Sprintf("flush: expected no error got %s", err))
+
+	reqs := rec.requests("/channels/1/messages")
+	assert.Len(t, reqs, 2, fmt.Sprintf("batching: expected 2 requests got %d", len(reqs)))
+	for _, body := range reqs {
+		var pack []map[string]interface{}
+		err := json.Unmarshal([]byte(body), &pack)
+		assert.Nil(t, err, fmt.Sprintf("batching: unexpected error decoding body: %s", err))
+		assert.Len(t, pack, 3, fmt.Sprintf("batching: expected 3 records got %d", len(pack)))
+	}
+	reqs = rec.requests("/channels/2/messages")
+	assert.Len(t, reqs, 1, fmt.Sprintf("batching: expected 1 request got %d", len(reqs)))
+
+	rec.mu.Lock()
+	assert.Len(t, rec.results, 7, fmt.Sprintf("batching: expected 7 results got %d", len(rec.results)))
+	for _, res := range rec.results {
+		assert.Nil(t, res.Err, fmt.Sprintf("batching: unexpected error for message %v: %s", res.Message, res.Err))
+	}
+	rec.mu.Unlock()
+
+	err = pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: expected no error got %s", err))
+}
+
+func TestAsyncPublisherNonJSONPayload(t *testing.T) {
+	rec := newRecorder()
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+
+	pub := sdk.NewAsyncPublisher(newAsyncSDK(ts.URL), sdk.AsyncConfig{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		OnResult:      rec.onResult,
+	})
+
+	pub.Publish(sdk.Message{Channel: "1", Payload: "text", Token: token})
+	pub.Publish(sdk.Message{Channel: "1", Payload: `[{"n":"m","v":1}]`, Token: token})
+	pub.Flush()
+
+	reqs := rec.requests("/channels/1/messages")
+	assert.Equal(t, []string{"text", `[{"n":"m","v":1}]`}, reqs, fmt.Sprintf("expected messages to be sent separately got %v", reqs))
+	pub.Close()
+}
+
+func TestAsyncPublisherClose(t *testing.T) {
+	rec := newRecorder()
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+
+	pub := sdk.NewAsyncPublisher(newAsyncSDK(ts.URL), sdk.AsyncConfig{
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+		OnResult:      rec.onResult,
+	})
+
+	for i := 0; i < 5; i++ {
+		err := pub.Publish(sdk.Message{Channel: "1", Payload: `[{"n":"m","v":1}]`, Token: token})
+		require.Nil(t, err, fmt.Sprintf("unexpected error publishing message %d: %s", i, err))
+	}
+
+	err := pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: expected no error got %s", err))
+
+	reqs := rec.requests("/channels/1/messages")
+	assert.Len(t, reqs, 1, fmt.Sprintf("close: expected 1 request got %d", len(reqs)))
+	rec.mu.Lock()
+	assert.Len(t, rec.results, 5, fmt.Sprintf("close: expected 5 results got %d", len(rec.results)))
+	rec.mu.Unlock()
+
+	cases := []struct {
+		desc string
+		op   func() error
+	}{
+		{desc: "publish after close", op: func() error { return pub.Publish(sdk.Message{Channel: "1"}) }},
+		{desc: "flush after close", op: pub.Flush},
+		{desc: "close after close", op: pub.Close},
+	}
+	for _, tc := range cases {
+		err := tc.op()
+		assert.Equal(t, sdk.ErrPublisherClosed, err, fmt.Sprintf("%s: expected %s got %s", tc.desc, sdk.ErrPublisherClosed, err))
+	}
+}
+
+func TestAsyncPublisherOverflow(t *testing.T) {
+	rec := newRecorder()
+	rec.received = make(chan struct{}, 10)
+	rec.release = make(chan struct{})
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+
+	pub := sdk.NewAsyncPublisher(newAsyncSDK(ts.URL), sdk.AsyncConfig{
+		QueueSize:     2,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Concurrency:   1,
+		Overflow:      sdk.Drop,
+		OnResult:      rec.onResult,
+	})
+
+	err := pub.Publish(sdk.Message{Channel: "1", Payload: "data", Token: token})
+	require.Nil(t, err, fmt.Sprintf("overflow: unexpected error publishing first message: %s", err))
+	<-rec.received
+
+	// The first message occupies the only worker, so at most one more is
+	// held by the dispatcher and two by the queue. The rest are dropped.
+	dropped := 0
+	for i := 0; i < 9; i++ {
+		if err := pub.Publish(sdk.Message{Channel: "1", Payload: "data", Token: token}); err == sdk.ErrQueueFull {
+			dropped++
+		}
+	}
+	assert.GreaterOrEqual(t, dropped, 6, fmt.Sprintf("overflow: expected at least 6 dropped messages got %d", dropped))
+
+	close(rec.release)
+	err = pub.Close()
+	assert.Nil(t, err, fmt.Sprintf("close: expected no error got %s", err))
+
+	reqs := rec.requests("/channels/1/messages")
+	assert.Len(t, reqs, 10-dropped, fmt.Sprintf("overflow: expected %d requests got %d", 10-dropped, len(reqs)))
+}
+
+func TestAsyncPublisherCloseBlocked(t *testing.T) {
+	rec := newRecorder()
+	rec.received = make(chan struct{}, 10)
+	rec.release = make(chan struct{})
+	ts := httptest.NewServer(rec)
+	defer ts.Close()
+
+	pub := sdk.NewAsyncPublisher(newAsyncSDK(ts.URL), sdk.AsyncConfig{
+		QueueSize:     1,
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		Concurrency:   1,
+		Overflow:      sdk.Block,
+	})
+
+	err := pub.Publish(sdk.Message{Channel: "1", Payload: "data", Token: token})
+	require.Nil(t, err, fmt.Sprintf("close blocked: unexpected error publishing first message: %s", err))
+	<-rec.received
+
+	// With the worker busy, the dispatcher holds the second message and the
+	// queue the third one, so the fourth one waits for room in the queue.
+	for i := 0; i < 2; i++ {
+		err := pub.Publish(sdk.Message{Channel: "1", Payload: "data", Token: token})
+		require.Nil(t, err, fmt.Sprintf("close blocked: unexpected error publishing message %d: %s", i+2, err))
+	}
+	started := make(chan struct{})
+	errs := make(chan error)
+	go func() {
+		close(started)
+		errs <- pub.Publish(sdk.Message{Channel: "1", Payload: "data", Token: token})
+	}()
+	<-started
+	closed := make(chan error)
+	go func() {
+		closed <- pub.Close()
+	}()
+
+	select {
+	case err = <-errs:
+		assert.Equal(t, sdk.ErrPublisherClosed, err, fmt.Sprintf("close blocked: expected error %s got %s", sdk.ErrPublisherClosed, err))
+	case <-time.After(time.Second):
+		assert.Fail(t, "close blocked: publish waiting for the full queue not released by close")
+	}
+
+	close(rec.release)
+	select {
+	case err = <-closed:
+		assert.Nil(t, err, fmt.Sprintf("close blocked: expected no error got %s", err))
+	case <-time.After(time.Second):
+		require.Fail(t, "close blocked: close didn't return")
+	}
+}
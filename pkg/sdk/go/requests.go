@@ -14,3 +14,14 @@ type ConnectionIDs struct {
 	ChannelIDs []string `json:"channel_ids"`
 	ThingIDs   []string `json:"thing_ids"`
 }
+
+// MessagesQuery contains parameters used to filter messages read from the
+// reader. Time boundaries are expressed in seconds since the Unix epoch.
+type MessagesQuery struct {
+	Offset    uint64
+	Limit     uint64
+	Subtopic  string
+	Publisher string
+	From      float64
+	To        float64
+}
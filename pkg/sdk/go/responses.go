@@ -3,7 +3,11 @@
 
 package sdk
 
-import "github.com/mainflux/mainflux/pkg/transformers/senml"
+import (
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
 
 type tokenRes struct {
 	Token string `json:"token,omitempty"`
@@ -17,6 +21,10 @@ type createChannelsRes struct {
 	Channels []Channel `json:"channels"`
 }
 
+type revokeCertRes struct {
+	RevocationTime time.Time `json:"RevocationTime"`
+}
+
 type pageRes struct {
 	Total  uint64 `json:"total"`
 	Offset uint64 `json:"offset"`
@@ -50,3 +58,15 @@ type UsersPage struct {
 	Users []User `json:"users"`
 	pageRes
 }
+
+// UserEventsPage contains list of user security events in a page with proper metadata.
+type UserEventsPage struct {
+	Events []UserEvent `json:"events"`
+	pageRes
+}
+
+// BootstrapPage contains list of bootstrap configs in a page with proper metadata.
+type BootstrapPage struct {
+	Configs []BootstrapConfig `json:"configs"`
+	pageRes
+}
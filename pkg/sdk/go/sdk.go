@@ -4,10 +4,11 @@
 package sdk
 
 import (
-	"crypto/tls"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/mainflux/mainflux/auth"
 )
@@ -63,9 +64,24 @@ var (
 	// ErrFailedWhitelist failed to whitelist configs
 	ErrFailedWhitelist = errors.New("failed to whitelist")
 
+	// ErrInvalidEncKey indicates that bootstrap encryption key is invalid.
+	ErrInvalidEncKey = errors.New("invalid bootstrap encryption key")
+
+	// ErrFailedDecrypt indicates that bootstrap response can't be decrypted.
+	ErrFailedDecrypt = errors.New("failed to decrypt bootstrap config")
+
 	// ErrCerts indicates error fetching certificates.
 	ErrCerts = errors.New("failed to fetch certs data")
 
+	// ErrCertsRevoke indicates failure to revoke certificate.
+	ErrCertsRevoke = errors.New("failed to revoke certificate")
+
+	// ErrCertRevoked indicates that the certificate is already revoked.
+	ErrCertRevoked = errors.New("certificate already revoked")
+
+	// ErrNotFound indicates that the requested entity doesn't exist.
+	ErrNotFound = errors.New("entity not found")
+
 	// ErrCertsRemove indicates failure while cleaning up from the Certs service.
 	ErrCertsRemove = errors.New("failed to remove certificate")
 
@@ -74,6 +90,19 @@ var (
 
 	// ErrMemberAdd failed to add member to a group.
 	ErrMemberAdd = errors.New("failed to add member to group")
+
+	// ErrInvalidCACert indicates that CA certificates can't be loaded.
+	ErrInvalidCACert = errors.New("failed to load CA certificates")
+
+	// ErrInvalidClientCert indicates that client certificate can't be loaded.
+	ErrInvalidClientCert = errors.New("failed to load client certificate")
+
+	// ErrPublisherClosed indicates that the asynchronous publisher is closed.
+	ErrPublisherClosed = errors.New("publisher is closed")
+
+	// ErrQueueFull indicates that the message was dropped because the
+	// asynchronous publisher queue is full.
+	ErrQueueFull = errors.New("publisher queue is full")
 )
 
 // ContentType represents all possible content types.
@@ -88,6 +117,16 @@ type User struct {
 	Groups   []string               `json:"groups,omitempty"`
 	Password string                 `json:"password,omitempty"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Status   string                 `json:"status,omitempty"`
+}
+
+// UserEvent represents security event recorded for the user account.
+type UserEvent struct {
+	ID       string                 `json:"id,omitempty"`
+	UserID   string                 `json:"user_id,omitempty"`
+	Type     string                 `json:"type,omitempty"`
+	Time     time.Time              `json:"time,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
 // Group represents mainflux users group.
@@ -131,6 +170,20 @@ type SDK interface {
 	// UpdatePassword updates user password.
 	UpdatePassword(oldPass, newPass, token string) error
 
+	// Users returns page of users filtered by email and status. Listing
+	// users requires admin token.
+	Users(token string, offset, limit uint64, email, status string) (UsersPage, error)
+
+	// DisableUser disables user account using admin token.
+	DisableUser(id, token string) error
+
+	// EnableUser enables disabled user account using admin token.
+	EnableUser(id, token string) error
+
+	// UserEvents returns page of security events of the user recorded since
+	// the given time. Listing events requires admin token.
+	UserEvents(id, token string, from time.Time, offset, limit uint64) (UserEventsPage, error)
+
 	// CreateThing registers new thing and returns its id.
 	CreateThing(thing Thing, token string) (string, error)
 
@@ -189,6 +242,9 @@ type SDK interface {
 	// Connect bulk connects things to channels specified by id.
 	Connect(conns ConnectionIDs, token string) error
 
+	// Disconnect bulk disconnects things from channels specified by id.
+	Disconnect(conns ConnectionIDs, token string) error
+
 	// DisconnectThing disconnect thing from specified channel by id.
 	DisconnectThing(thingID, chanID, token string) error
 
@@ -220,6 +276,9 @@ type SDK interface {
 	// ReadMessages read messages of specified channel.
 	ReadMessages(chanID, token string) (MessagesPage, error)
 
+	// QueryMessages reads messages of specified channel matching the query.
+	QueryMessages(chanID, token string, q MessagesQuery) (MessagesPage, error)
+
 	// SetContentType sets message content type.
 	SetContentType(ct ContentType) error
 
@@ -232,6 +291,10 @@ type SDK interface {
 	// View returns Thing Config with given ID belonging to the user identified by the given token.
 	ViewBootstrap(token, id string) (BootstrapConfig, error)
 
+	// Bootstraps returns page of Configs belonging to the user identified by the given token,
+	// matching the filter. Filter keys are state, external_id, mainflux_id, mainflux_key and name.
+	Bootstraps(token string, offset, limit uint64, filter map[string]string) (BootstrapPage, error)
+
 	// Update updates editable fields of the provided Config.
 	UpdateBootstrap(token string, cfg BootstrapConfig) error
 
@@ -244,6 +307,10 @@ type SDK interface {
 	// Bootstrap returns Config to the Thing with provided external ID using external key.
 	Bootstrap(externalKey, externalID string) (BootstrapConfig, error)
 
+	// BootstrapSecure returns Config to the Thing with provided external ID using external key,
+	// encrypting the key and decrypting the response with the given encryption key.
+	BootstrapSecure(externalKey, externalID, encKey string) (BootstrapConfig, error)
+
 	// Whitelist updates Thing state Config with given ID belonging to the user identified by the given token.
 	Whitelist(token string, cfg BootstrapConfig) error
 
@@ -253,8 +320,23 @@ type SDK interface {
 	// RemoveCert removes a certificate
 	RemoveCert(id, token string) error
 
-	// RevokeCert revokes certificate with certID for thing with thingID
-	RevokeCert(thingID, certID, token string) error
+	// ViewCert returns certificate with the given serial.
+	ViewCert(serialID, token string) (Cert, error)
+
+	// ListCerts returns page of certificates issued for the thing.
+	ListCerts(thingID, token string, offset, limit uint64) (CertsPage, error)
+
+	// RevokeCert revokes the active certificate of the thing with thingID
+	// and returns the revocation time.
+	RevokeCert(thingID, token string) (time.Time, error)
+
+	// RevokeCertBySerial revokes the certificate with the given serial
+	// and returns the revocation time.
+	RevokeCertBySerial(serialID, token string) (time.Time, error)
+
+	// WithContext returns SDK sending requests that are canceled once the
+	// context is done.
+	WithContext(ctx context.Context) SDK
 }
 
 type mfSDK struct {
@@ -288,6 +370,25 @@ type Config struct {
 	BootstrapPrefix   string
 	MsgContentType    ContentType
 	TLSVerification   bool
+
+	// CACertPath and CACert specify PEM encoded CA certificates used to
+	// verify the server. If both are empty, system roots are used.
+	CACertPath string
+	CACert     []byte
+
+	// ClientCertPath/ClientKeyPath and ClientCert/ClientKey specify PEM
+	// encoded client certificate and key used for mTLS.
+	ClientCertPath string
+	ClientKeyPath  string
+	ClientCert     []byte
+	ClientKey      []byte
+
+	// ServerName overrides the server name used to verify the certificate.
+	ServerName string
+
+	// HTTPClient, if set, is used instead of the internally constructed
+	// client and all the TLS options are ignored.
+	HTTPClient *http.Client
 }
 
 // NewSDK returns new mainflux SDK instance.
@@ -304,13 +405,7 @@ func NewSDK(conf Config) SDK {
 		httpAdapterPrefix: conf.HTTPAdapterPrefix,
 		bootstrapPrefix:   conf.BootstrapPrefix,
 		msgContentType:    conf.MsgContentType,
-		client: &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: !conf.TLSVerification,
-				},
-			},
-		},
+		client:            newClient(conf),
 	}
 }
 
@@ -326,6 +421,27 @@ func (sdk mfSDK) sendRequest(req *http.Request, token, contentType string) (*htt
 	return sdk.client.Do(req)
 }
 
+func (sdk mfSDK) WithContext(ctx context.Context) SDK {
+	base := sdk.client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client := *sdk.client
+	client.Transport = ctxTransport{ctx: ctx, base: base}
+	sdk.client = &client
+	return &sdk
+}
+
+// ctxTransport sends requests using the context of the transport.
+type ctxTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (ct ctxTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return ct.base.RoundTrip(req.WithContext(ct.ctx))
+}
+
 func createURL(baseURL, prefix, endpoint string) string {
 	if prefix == "" {
 		return fmt.Sprintf("%s/%s", baseURL, endpoint)
@@ -4,13 +4,78 @@
 package sdk_test
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
 
 	"github.com/mainflux/mainflux/pkg/errors"
+	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
 )
 
 func createError(e error, statusCode int) error {
 	httpStatus := fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))
 	return errors.Wrap(e, errors.New(httpStatus))
 }
+
+func TestStatusCode(t *testing.T) {
+	cases := []struct {
+		desc string
+		err  error
+		code int
+	}{
+		{
+			desc: "response error",
+			err:  createError(sdk.ErrFailedFetch, http.StatusNotFound),
+			code: http.StatusNotFound,
+		},
+		{
+			desc: "nested response error",
+			err:  errors.Wrap(sdk.ErrCertsRemove, createError(sdk.ErrFailedRemoval, http.StatusConflict)),
+			code: http.StatusConflict,
+		},
+		{
+			desc: "error without response",
+			err:  errors.Wrap(sdk.ErrFailedFetch, errors.New("connection refused")),
+			code: 0,
+		},
+		{
+			desc: "error resembling response status",
+			err:  errors.New("404 entities"),
+			code: 0,
+		},
+		{
+			desc: "nil error",
+			err:  nil,
+			code: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		code := sdk.StatusCode(tc.err)
+		assert.Equal(t, tc.code, code, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.code, code))
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"0.11.0"}`))
+	}))
+	defer ts.Close()
+	mainfluxSDK := sdk.NewSDK(sdk.Config{BaseURL: ts.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ver, err := mainfluxSDK.WithContext(ctx).Version()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, "0.11.0", ver, fmt.Sprintf("expected version 0.11.0 got %s", ver))
+
+	cancel()
+	_, err = mainfluxSDK.WithContext(ctx).Version()
+	assert.True(t, err != nil && strings.Contains(err.Error(), context.Canceled.Error()), fmt.Sprintf("expected error %s got %s", context.Canceled, err))
+
+	ver, err = mainfluxSDK.Version()
+	assert.Nil(t, err, fmt.Sprintf("expected SDK without context to be unaffected, got error %s", err))
+}
@@ -0,0 +1,43 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// StatusCode returns HTTP status code of the unexpected response the SDK
+// error was caused by, or 0 if the error wasn't caused by a response.
+func StatusCode(err error) int {
+	for err != nil {
+		e, ok := err.(errors.Error)
+		if !ok {
+			return statusCode(err.Error())
+		}
+		if code := statusCode(e.Msg()); code != 0 {
+			return code
+		}
+		if e.Err() == nil {
+			return 0
+		}
+		err = e.Err()
+	}
+	return 0
+}
+
+// statusCode parses status line of the response, e.g. "404 Not Found", as
+// set by the HTTP client to the response status.
+func statusCode(status string) int {
+	var code int
+	if _, err := fmt.Sscanf(status, "%d", &code); err != nil {
+		return 0
+	}
+	if text := http.StatusText(code); text == "" || status != fmt.Sprintf("%d %s", code, text) {
+		return 0
+	}
+	return code
+}
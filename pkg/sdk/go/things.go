@@ -16,6 +16,7 @@ import (
 
 const thingsEndpoint = "things"
 const connectEndpoint = "connect"
+const disconnectEndpoint = "disconnect"
 
 func (sdk mfSDK) CreateThing(t Thing, token string) (string, error) {
 	data, err := json.Marshal(t)
@@ -248,6 +249,31 @@ func (sdk mfSDK) Connect(connIDs ConnectionIDs, token string) error {
 	return nil
 }
 
+func (sdk mfSDK) Disconnect(connIDs ConnectionIDs, token string) error {
+	data, err := json.Marshal(connIDs)
+	if err != nil {
+		return err
+	}
+
+	url := createURL(sdk.baseURL, sdk.thingsPrefix, disconnectEndpoint)
+	req, err := http.NewRequest(http.MethodDelete, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Wrap(ErrFailedDisconnect, errors.New(resp.Status))
+	}
+
+	return nil
+}
+
 func (sdk mfSDK) DisconnectThing(thingID, chanID, token string) error {
 	endpoint := fmt.Sprintf("%s/%s/%s/%s", channelsEndpoint, chanID, thingsEndpoint, thingID)
 	url := createURL(sdk.baseURL, sdk.thingsPrefix, endpoint)
@@ -48,11 +48,11 @@ func newThingsService(tokens map[string]string) things.Service {
 	thingCache := mocks.NewThingCache()
 	idProvider := uuid.NewMock()
 
-	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, idProvider)
+	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, mocks.NewStatsRepository(), idProvider, idProvider)
 }
 
 func newThingsServer(svc things.Service) *httptest.Server {
-	mux := httpapi.MakeHandler(mocktracer.New(), svc)
+	mux := httpapi.MakeHandler(mocktracer.New(), svc, nil)
 	return httptest.NewServer(mux)
 }
 
@@ -940,3 +940,89 @@ func TestDisconnectThing(t *testing.T) {
 		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
 	}
 }
+
+func TestDisconnect(t *testing.T) {
+	svc := newThingsService(map[string]string{
+		token:      email,
+		otherToken: otherEmail,
+	})
+
+	ts := newThingsServer(svc)
+	defer ts.Close()
+	sdkConf := sdk.Config{
+		BaseURL:           ts.URL,
+		UsersPrefix:       "",
+		GroupsPrefix:      "",
+		ThingsPrefix:      "",
+		HTTPAdapterPrefix: "",
+		MsgContentType:    contentType,
+		TLSVerification:   false,
+	}
+
+	mainfluxSDK := sdk.NewSDK(sdkConf)
+
+	thingID, err := mainfluxSDK.CreateThing(thing, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	chanID1, err := mainfluxSDK.CreateChannel(channel, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	chanID2, err := mainfluxSDK.CreateChannel(channel, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	conIDs := sdk.ConnectionIDs{
+		ChannelIDs: []string{chanID1, chanID2},
+		ThingIDs:   []string{thingID},
+	}
+	err = mainfluxSDK.Connect(conIDs, token)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	otherChanID, err := mainfluxSDK.CreateChannel(channel, otherToken)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc     string
+		thingIDs []string
+		chanIDs  []string
+		token    string
+		err      error
+	}{
+		{
+			desc:     "disconnect things from channels with invalid token",
+			thingIDs: []string{thingID},
+			chanIDs:  []string{chanID1, chanID2},
+			token:    wrongValue,
+			err:      createError(sdk.ErrFailedDisconnect, http.StatusUnauthorized),
+		},
+		{
+			desc:     "disconnect things from channels with invalid ID",
+			thingIDs: []string{thingID},
+			chanIDs:  []string{""},
+			token:    token,
+			err:      createError(sdk.ErrFailedDisconnect, http.StatusBadRequest),
+		},
+		{
+			desc:     "disconnect things from someone elses channel",
+			thingIDs: []string{thingID},
+			chanIDs:  []string{otherChanID},
+			token:    token,
+			err:      createError(sdk.ErrFailedDisconnect, http.StatusNotFound),
+		},
+		{
+			desc:     "disconnect connected things from channels",
+			thingIDs: []string{thingID},
+			chanIDs:  []string{chanID1, chanID2},
+			token:    token,
+			err:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		connIDs := sdk.ConnectionIDs{
+			ThingIDs:   tc.thingIDs,
+			ChannelIDs: tc.chanIDs,
+		}
+		err := mainfluxSDK.Disconnect(connIDs, tc.token)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+	}
+}
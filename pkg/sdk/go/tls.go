@@ -0,0 +1,87 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// TLSConfig returns TLS configuration built from the SDK configuration
+// parameters. It can be used to set up other clients, such as WebSocket
+// dialers, with the same credentials the SDK uses.
+func (conf Config) TLSConfig() (*tls.Config, error) {
+	tc := &tls.Config{
+		InsecureSkipVerify: !conf.TLSVerification,
+		ServerName:         conf.ServerName,
+	}
+
+	ca := conf.CACert
+	if conf.CACertPath != "" {
+		data, err := ioutil.ReadFile(conf.CACertPath)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidCACert, err)
+		}
+		ca = append(ca, data...)
+	}
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, ErrInvalidCACert
+		}
+		tc.RootCAs = pool
+	}
+
+	cert, key := conf.ClientCert, conf.ClientKey
+	if conf.ClientCertPath != "" || conf.ClientKeyPath != "" {
+		var err error
+		if cert, err = ioutil.ReadFile(conf.ClientCertPath); err != nil {
+			return nil, errors.Wrap(ErrInvalidClientCert, err)
+		}
+		if key, err = ioutil.ReadFile(conf.ClientKeyPath); err != nil {
+			return nil, errors.Wrap(ErrInvalidClientCert, err)
+		}
+	}
+	if len(cert) > 0 || len(key) > 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, errors.Wrap(ErrInvalidClientCert, err)
+		}
+		tc.Certificates = []tls.Certificate{pair}
+	}
+
+	return tc, nil
+}
+
+func newClient(conf Config) *http.Client {
+	if conf.HTTPClient != nil {
+		return conf.HTTPClient
+	}
+
+	tc, err := conf.TLSConfig()
+	if err != nil {
+		return &http.Client{Transport: errTransport{err: err}}
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tc,
+		},
+	}
+}
+
+// errTransport fails every request with the error that occurred
+// while building the TLS configuration.
+type errTransport struct {
+	err error
+}
+
+func (et errTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, et.err
+}
@@ -0,0 +1,253 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package sdk_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serverName = "mainflux.test"
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *rsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, tmpl *x509.Certificate, parent *testCert) testCert {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err, fmt.Sprintf("unexpected error generating key: %s", err))
+
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating certificate: %s", err))
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err, fmt.Sprintf("unexpected error parsing certificate: %s", err))
+
+	return testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}
+}
+
+func newTestPKI(t *testing.T) (ca, server, client testCert) {
+	now := time.Now()
+	ca = newTestCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Mainflux Test CA"},
+		NotBefore:             now,
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	server = newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: serverName},
+		NotBefore:    now,
+		NotAfter:     now.Add(time.Hour),
+		DNSNames:     []string{serverName},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	client = newTestCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    now,
+		NotAfter:     now.Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, &ca)
+
+	return ca, server, client
+}
+
+func newTLSServer(t *testing.T, ca, server testCert, mtls bool) *httptest.Server {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", string(sdk.CTJSON))
+		w.Write([]byte(`{"version":"0.0.0"}`))
+	}))
+
+	pair, err := tls.X509KeyPair(server.certPEM, server.keyPEM)
+	require.Nil(t, err, fmt.Sprintf("unexpected error loading server key pair: %s", err))
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{pair}}
+	if mtls {
+		pool := x509.NewCertPool()
+		pool.AddCert(ca.cert)
+		ts.TLS.ClientCAs = pool
+		ts.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	ts.StartTLS()
+
+	return ts
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, data, 0600)
+	require.Nil(t, err, fmt.Sprintf("unexpected error writing %s: %s", name, err))
+	return path
+}
+
+func TestTLS(t *testing.T) {
+	ca, server, client := newTestPKI(t)
+	_, _, otherClient := newTestPKI(t)
+
+	dir, err := ioutil.TempDir("", "sdk-tls")
+	require.Nil(t, err, fmt.Sprintf("unexpected error creating temp dir: %s", err))
+	defer os.RemoveAll(dir)
+	caPath := writeFile(t, dir, "ca.crt", ca.certPEM)
+	certPath := writeFile(t, dir, "client.crt", client.certPEM)
+	keyPath := writeFile(t, dir, "client.key", client.keyPEM)
+
+	ts := newTLSServer(t, ca, server, false)
+	defer ts.Close()
+	mts := newTLSServer(t, ca, server, true)
+	defer mts.Close()
+
+	cases := []struct {
+		desc string
+		url  string
+		conf sdk.Config
+		fail bool
+	}{
+		{
+			desc: "connect with unknown CA",
+			url:  ts.URL,
+			conf: sdk.Config{TLSVerification: true},
+			fail: true,
+		},
+		{
+			desc: "connect with unknown CA skipping verification",
+			url:  ts.URL,
+			conf: sdk.Config{TLSVerification: false},
+			fail: false,
+		},
+		{
+			desc: "connect with CA PEM",
+			url:  ts.URL,
+			conf: sdk.Config{TLSVerification: true, CACert: ca.certPEM},
+			fail: false,
+		},
+		{
+			desc: "connect with CA path",
+			url:  ts.URL,
+			conf: sdk.Config{TLSVerification: true, CACertPath: caPath},
+			fail: false,
+		},
+		{
+			desc: "connect with matching server name override",
+			url:  ts.URL,
+			conf: sdk.Config{TLSVerification: true, CACert: ca.certPEM, ServerName: serverName},
+			fail: false,
+		},
+		{
+			desc: "connect with wrong server name override",
+			url:  ts.URL,
+			conf: sdk.Config{TLSVerification: true, CACert: ca.certPEM, ServerName: "wrong.test"},
+			fail: true,
+		},
+		{
+			desc: "connect to mTLS server without client cert",
+			url:  mts.URL,
+			conf: sdk.Config{TLSVerification: true, CACert: ca.certPEM},
+			fail: true,
+		},
+		{
+			desc: "connect to mTLS server with client cert PEM",
+			url:  mts.URL,
+			conf: sdk.Config{TLSVerification: true, CACert: ca.certPEM, ClientCert: client.certPEM, ClientKey: client.keyPEM},
+			fail: false,
+		},
+		{
+			desc: "connect to mTLS server with client cert path",
+			url:  mts.URL,
+			conf: sdk.Config{TLSVerification: true, CACertPath: caPath, ClientCertPath: certPath, ClientKeyPath: keyPath},
+			fail: false,
+		},
+		{
+			desc: "connect to mTLS server with client cert signed by unknown CA",
+			url:  mts.URL,
+			conf: sdk.Config{TLSVerification: true, CACert: ca.certPEM, ClientCert: otherClient.certPEM, ClientKey: otherClient.keyPEM},
+			fail: true,
+		},
+	}
+
+	for _, tc := range cases {
+		tc.conf.BaseURL = tc.url
+		_, err := sdk.NewSDK(tc.conf).Version()
+		assert.Equal(t, tc.fail, err != nil, fmt.Sprintf("%s: expected failure %t got %s", tc.desc, tc.fail, err))
+	}
+}
+
+func TestTLSConfig(t *testing.T) {
+	ca, _, client := newTestPKI(t)
+
+	cases := []struct {
+		desc string
+		conf sdk.Config
+		err  error
+	}{
+		{
+			desc: "build config with valid CA and client cert",
+			conf: sdk.Config{CACert: ca.certPEM, ClientCert: client.certPEM, ClientKey: client.keyPEM},
+			err:  nil,
+		},
+		{
+			desc: "build config with invalid CA PEM",
+			conf: sdk.Config{CACert: []byte("invalid")},
+			err:  sdk.ErrInvalidCACert,
+		},
+		{
+			desc: "build config with non-existent CA path",
+			conf: sdk.Config{CACertPath: "non-existent"},
+			err:  sdk.ErrInvalidCACert,
+		},
+		{
+			desc: "build config with client cert without key",
+			conf: sdk.Config{ClientCert: client.certPEM},
+			err:  sdk.ErrInvalidClientCert,
+		},
+		{
+			desc: "build config with non-existent client cert path",
+			conf: sdk.Config{ClientCertPath: "non-existent", ClientKeyPath: "non-existent"},
+			err:  sdk.ErrInvalidClientCert,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := tc.conf.TLSConfig()
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+
+	_, err := sdk.NewSDK(sdk.Config{BaseURL: "https://localhost", CACert: []byte("invalid")}).Version()
+	assert.NotNil(t, err, "expected error using SDK with invalid TLS configuration")
+}
@@ -9,7 +9,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mainflux/mainflux/pkg/errors"
 )
@@ -19,6 +22,9 @@ const (
 	tokensEndpoint   = "tokens"
 	passwordEndpoint = "password"
 	membersEndpoint  = "members"
+	disableEndpoint  = "disable"
+	enableEndpoint   = "enable"
+	eventsEndpoint   = "events"
 )
 
 func (sdk mfSDK) CreateUser(u User) (string, error) {
@@ -157,3 +163,112 @@ func (sdk mfSDK) UpdatePassword(oldPass, newPass, token string) error {
 
 	return nil
 }
+
+func (sdk mfSDK) Users(token string, offset, limit uint64, email, status string) (UsersPage, error) {
+	q := url.Values{}
+	q.Set("offset", strconv.FormatUint(offset, 10))
+	q.Set("limit", strconv.FormatUint(limit, 10))
+	if email != "" {
+		q.Set("email", email)
+	}
+	if status != "" {
+		q.Set("status", status)
+	}
+	endpoint := fmt.Sprintf("%s?%s", usersEndpoint, q.Encode())
+	url := createURL(sdk.baseURL, sdk.usersPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return UsersPage{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return UsersPage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return UsersPage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return UsersPage{}, errors.Wrap(ErrFailedFetch, errors.New(resp.Status))
+	}
+
+	var up UsersPage
+	if err := json.Unmarshal(body, &up); err != nil {
+		return UsersPage{}, err
+	}
+
+	return up, nil
+}
+
+func (sdk mfSDK) DisableUser(id, token string) error {
+	return sdk.updateUserStatus(id, disableEndpoint, token)
+}
+
+func (sdk mfSDK) EnableUser(id, token string) error {
+	return sdk.updateUserStatus(id, enableEndpoint, token)
+}
+
+func (sdk mfSDK) updateUserStatus(id, action, token string) error {
+	endpoint := fmt.Sprintf("%s/%s/%s", usersEndpoint, id, action)
+	url := createURL(sdk.baseURL, sdk.usersPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Wrap(ErrFailedUpdate, errors.New(resp.Status))
+	}
+
+	return nil
+}
+
+func (sdk mfSDK) UserEvents(id, token string, from time.Time, offset, limit uint64) (UserEventsPage, error) {
+	q := url.Values{}
+	q.Set("offset", strconv.FormatUint(offset, 10))
+	q.Set("limit", strconv.FormatUint(limit, 10))
+	if !from.IsZero() {
+		q.Set("from", from.UTC().Format(time.RFC3339Nano))
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s?%s", usersEndpoint, id, eventsEndpoint, q.Encode())
+	url := createURL(sdk.baseURL, sdk.usersPrefix, endpoint)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return UserEventsPage{}, err
+	}
+
+	resp, err := sdk.sendRequest(req, token, string(CTJSON))
+	if err != nil {
+		return UserEventsPage{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return UserEventsPage{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return UserEventsPage{}, errors.Wrap(ErrFailedFetch, errors.New(resp.Status))
+	}
+
+	var ep UserEventsPage
+	if err := json.Unmarshal(body, &ep); err != nil {
+		return UserEventsPage{}, err
+	}
+
+	return ep, nil
+}
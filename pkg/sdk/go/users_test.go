@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/mainflux/mainflux"
 	sdk "github.com/mainflux/mainflux/pkg/sdk/go"
@@ -23,6 +24,7 @@ import (
 
 const (
 	invalidEmail = "userexample.com"
+	adminEmail   = "admin@example.com"
 )
 
 var (
@@ -32,11 +34,11 @@ var (
 func newUserService() users.Service {
 	usersRepo := mocks.NewUserRepository()
 	hasher := mocks.NewHasher()
-	auth := mocks.NewAuthService(map[string]string{"user@example.com": "user@example.com"})
+	auth := mocks.NewAuthService(map[string]string{"user@example.com": "user@example.com", adminEmail: adminEmail})
 	emailer := mocks.NewEmailer()
 	idProvider := uuid.New()
 
-	return users.New(usersRepo, hasher, auth, emailer, idProvider, passRegex)
+	return users.New(usersRepo, mocks.NewEventRepository(), hasher, auth, emailer, idProvider, passRegex, adminEmail)
 }
 
 func newUserServer(svc users.Service) *httptest.Server {
@@ -159,3 +161,162 @@ func TestCreateToken(t *testing.T) {
 		assert.Equal(t, tc.token, token, fmt.Sprintf("%s: expected response: %s, got:  %s", tc.desc, token, tc.token))
 	}
 }
+
+func TestUsers(t *testing.T) {
+	svc := newUserService()
+	ts := newUserServer(svc)
+	defer ts.Close()
+	sdkConf := sdk.Config{
+		BaseURL:         ts.URL,
+		MsgContentType:  contentType,
+		TLSVerification: false,
+	}
+
+	mainfluxSDK := sdk.NewSDK(sdkConf)
+	user := sdk.User{Email: "user@example.com", Password: "password"}
+	_, err := mainfluxSDK.CreateUser(user)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating user: %s", err))
+	token, err := mainfluxSDK.CreateToken(user)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating token: %s", err))
+	admin := sdk.User{Email: adminEmail, Password: "password"}
+	_, err = mainfluxSDK.CreateUser(admin)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating admin: %s", err))
+	adminToken, err := mainfluxSDK.CreateToken(admin)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating token: %s", err))
+
+	cases := []struct {
+		desc  string
+		token string
+		email string
+		total uint64
+		err   error
+	}{
+		{
+			desc:  "list users",
+			token: adminToken,
+			total: 2,
+			err:   nil,
+		},
+		{
+			desc:  "list users filtered by email",
+			token: adminToken,
+			email: "user@",
+			total: 1,
+			err:   nil,
+		},
+		{
+			desc:  "list users with non-admin token",
+			token: token,
+			err:   createError(sdk.ErrFailedFetch, http.StatusForbidden),
+		},
+		{
+			desc:  "list users with invalid token",
+			token: wrongValue,
+			err:   createError(sdk.ErrFailedFetch, http.StatusForbidden),
+		},
+	}
+	for _, tc := range cases {
+		page, err := mainfluxSDK.Users(tc.token, 0, 10, tc.email, "")
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d, got %d", tc.desc, tc.total, page.Total))
+	}
+}
+
+func TestUserStatus(t *testing.T) {
+	svc := newUserService()
+	ts := newUserServer(svc)
+	defer ts.Close()
+	mainfluxSDK := sdk.NewSDK(sdk.Config{BaseURL: ts.URL, MsgContentType: contentType})
+
+	id, err := mainfluxSDK.CreateUser(sdk.User{Email: "user@example.com", Password: "password"})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating user: %s", err))
+	_, err = mainfluxSDK.CreateUser(sdk.User{Email: adminEmail, Password: "password"})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating admin: %s", err))
+
+	cases := []struct {
+		desc  string
+		id    string
+		token string
+		err   error
+	}{
+		{
+			desc:  "update status of existing user",
+			id:    id,
+			token: adminEmail,
+			err:   nil,
+		},
+		{
+			desc:  "update status of non-existing user",
+			id:    badID,
+			token: adminEmail,
+			err:   createError(sdk.ErrFailedUpdate, http.StatusNotFound),
+		},
+		{
+			desc:  "update status with non-admin token",
+			id:    id,
+			token: "user@example.com",
+			err:   createError(sdk.ErrFailedUpdate, http.StatusForbidden),
+		},
+	}
+	for _, tc := range cases {
+		err := mainfluxSDK.DisableUser(tc.id, tc.token)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("disable %s: expected error %s, got %s", tc.desc, tc.err, err))
+		err = mainfluxSDK.EnableUser(tc.id, tc.token)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("enable %s: expected error %s, got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestUserEvents(t *testing.T) {
+	svc := newUserService()
+	ts := newUserServer(svc)
+	defer ts.Close()
+	mainfluxSDK := sdk.NewSDK(sdk.Config{BaseURL: ts.URL, MsgContentType: contentType})
+
+	user := sdk.User{Email: "user@example.com", Password: "password"}
+	id, err := mainfluxSDK.CreateUser(user)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating user: %s", err))
+	_, err = mainfluxSDK.CreateUser(sdk.User{Email: adminEmail, Password: "password"})
+	assert.Nil(t, err, fmt.Sprintf("unexpected error creating admin: %s", err))
+	_, err = mainfluxSDK.CreateToken(sdk.User{Email: user.Email, Password: wrongValue})
+	assert.NotNil(t, err, "expected error creating token with wrong password")
+	from := time.Now()
+	err = mainfluxSDK.UpdatePassword(user.Password, "newpassword", user.Email)
+	assert.Nil(t, err, fmt.Sprintf("unexpected error updating password: %s", err))
+
+	cases := []struct {
+		desc  string
+		token string
+		from  time.Time
+		types []string
+		err   error
+	}{
+		{
+			desc:  "list user events",
+			token: adminEmail,
+			types: []string{"login_failed", "password_changed"},
+			err:   nil,
+		},
+		{
+			desc:  "list user events since the time",
+			token: adminEmail,
+			from:  from,
+			types: []string{"password_changed"},
+			err:   nil,
+		},
+		{
+			desc:  "list user events with non-admin token",
+			token: user.Email,
+			err:   createError(sdk.ErrFailedFetch, http.StatusForbidden),
+		},
+	}
+	for _, tc := range cases {
+		page, err := mainfluxSDK.UserEvents(id, tc.token, tc.from, 0, 10)
+		assert.Equal(t, tc.err, err, fmt.Sprintf("%s: expected error %s, got %s", tc.desc, tc.err, err))
+		var types []string
+		for _, e := range page.Events {
+			assert.Equal(t, id, e.UserID, fmt.Sprintf("%s: expected user %s, got %s", tc.desc, id, e.UserID))
+			types = append(types, e.Type)
+		}
+		assert.Equal(t, tc.types, types, fmt.Sprintf("%s: expected events %v, got %v", tc.desc, tc.types, types))
+	}
+}
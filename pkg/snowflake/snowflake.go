@@ -0,0 +1,107 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package snowflake provides a snowflake-style identity provider.
+package snowflake
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	// MaxNode is the maximum node ID.
+	MaxNode = 1<<nodeBits - 1
+
+	nodeBits = 10
+	seqBits  = 12
+	seqMask  = 1<<seqBits - 1
+	idLen    = 19
+)
+
+// Epoch is the start of the ID timestamps, 2020-01-01T00:00:00Z.
+var Epoch = time.Unix(1577836800, 0)
+
+var (
+	// ErrInvalidNode indicates that the node ID is out of range.
+	ErrInvalidNode = errors.New("invalid node id")
+
+	// ErrInvalidID indicates malformed snowflake ID.
+	ErrInvalidID = errors.New("invalid snowflake id")
+)
+
+var _ mainflux.IDProvider = (*snowflakeProvider)(nil)
+
+type snowflakeProvider struct {
+	mu   sync.Mutex
+	node int64
+	last int64
+	seq  int64
+}
+
+// New instantiates a snowflake provider. The ID consists of 41 bits of
+// milliseconds since the Epoch, 10 bits of node ID and 12 bits of sequence
+// number. Every instance generating IDs must use a distinct node ID, which
+// is in range [0, MaxNode]. IDs are encoded as zero padded decimal numbers,
+// so they are sorted lexicographically in the order of generation.
+func New(node int64) (mainflux.IDProvider, error) {
+	if node < 0 || node > MaxNode {
+		return nil, errors.Wrap(ErrInvalidNode, fmt.Errorf("node %d not in range [0, %d]", node, MaxNode))
+	}
+
+	return &snowflakeProvider{node: node}, nil
+}
+
+func (sp *snowflakeProvider) ID() (string, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	ms := since()
+	// Reuse the last timestamp if the clock moved backwards to keep IDs sorted.
+	if ms < sp.last {
+		ms = sp.last
+	}
+
+	if ms == sp.last {
+		sp.seq = (sp.seq + 1) & seqMask
+		if sp.seq == 0 {
+			// Sequence is exhausted, wait for the next millisecond.
+			for ms <= sp.last {
+				time.Sleep(100 * time.Microsecond)
+				ms = since()
+			}
+		}
+	} else {
+		sp.seq = 0
+	}
+	sp.last = ms
+
+	id := ms<<(nodeBits+seqBits) | sp.node<<seqBits | sp.seq
+	return fmt.Sprintf("%0*d", idLen, id), nil
+}
+
+// Validate checks if the given string is a valid snowflake ID.
+func Validate(id string) error {
+	if len(id) != idLen {
+		return ErrInvalidID
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return ErrInvalidID
+		}
+	}
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return errors.Wrap(ErrInvalidID, err)
+	}
+
+	return nil
+}
+
+func since() int64 {
+	return time.Since(Epoch).Milliseconds()
+}
@@ -0,0 +1,134 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package snowflake_test
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/snowflake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	workers = 16
+	burst   = 10000
+)
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		desc string
+		node int64
+		err  error
+	}{
+		{
+			desc: "create provider with the first node",
+			node: 0,
+		},
+		{
+			desc: "create provider with the last node",
+			node: snowflake.MaxNode,
+		},
+		{
+			desc: "create provider with negative node",
+			node: -1,
+			err:  snowflake.ErrInvalidNode,
+		},
+		{
+			desc: "create provider with too big node",
+			node: snowflake.MaxNode + 1,
+			err:  snowflake.ErrInvalidNode,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := snowflake.New(tc.node)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestIDMonotonic(t *testing.T) {
+	idp, err := snowflake.New(1)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	prev := ""
+	for i := 0; i < workers*burst; i++ {
+		id, err := idp.ID()
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		require.Nil(t, snowflake.Validate(id), fmt.Sprintf("expected valid snowflake ID got %s", id))
+		require.True(t, id > prev, fmt.Sprintf("expected %s to be greater than %s", id, prev))
+		prev = id
+	}
+}
+
+func TestIDBurst(t *testing.T) {
+	// Nodes of the same cluster generate IDs concurrently.
+	nodes := []int64{1, 2}
+	ids := make(chan string, len(nodes)*workers*burst)
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		idp, err := snowflake.New(node)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < burst; j++ {
+					id, err := idp.ID()
+					assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+					ids <- id
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(ids)
+
+	total := len(nodes) * workers * burst
+	seen := make(map[string]struct{}, total)
+	for id := range ids {
+		_, ok := seen[id]
+		assert.False(t, ok, fmt.Sprintf("expected unique IDs got duplicate %s", id))
+		seen[id] = struct{}{}
+	}
+	assert.Equal(t, total, len(seen), fmt.Sprintf("expected %d IDs got %d", total, len(seen)))
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		desc string
+		id   string
+		err  error
+	}{
+		{
+			desc: "validate valid ID",
+			id:   "0808493096173572096",
+		},
+		{
+			desc: "validate ID with invalid character",
+			id:   "080849309617357209a",
+			err:  snowflake.ErrInvalidID,
+		},
+		{
+			desc: "validate ID without padding",
+			id:   strconv.Itoa(808493096173572096),
+			err:  snowflake.ErrInvalidID,
+		},
+		{
+			desc: "validate ID out of range",
+			id:   "9999999999999999999",
+			err:  snowflake.ErrInvalidID,
+		},
+	}
+
+	for _, tc := range cases {
+		err := snowflake.Validate(tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package decompress contains the transformer inflating compressed payloads
+// before they are passed to the message transformers.
+package decompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+)
+
+const (
+	// Gzip is the content encoding of gzip compressed payloads.
+	Gzip = "gzip"
+	// Deflate is the content encoding of deflate compressed payloads, with or
+	// without the zlib wrapper.
+	Deflate = "deflate"
+	// Identity is the content encoding of uncompressed payloads.
+	Identity = "identity"
+)
+
+var (
+	// ErrTooLarge indicates that the decompressed payload exceeds the limit.
+	ErrTooLarge = errors.New("decompressed payload too large")
+
+	// ErrCorrupted indicates malformed compressed payload.
+	ErrCorrupted = errors.New("corrupted compressed payload")
+
+	// ErrUnsupportedEncoding indicates unknown content encoding.
+	ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+)
+
+type transformer struct {
+	limit int64
+	next  transformers.Transformer
+}
+
+// New returns a transformer inflating gzip and deflate compressed payloads
+// and passing them on to the next transformer. Compression is detected using
+// the content encoding header of the message or, if the header is missing,
+// using the gzip magic bytes. The zlib header is only two bytes long and
+// matches plain payloads, e.g. protobuf or text, so zlib and raw deflate
+// streams must be marked by the header. Decompressed payloads larger than
+// the limit in bytes are rejected.
+func New(limit int64, next transformers.Transformer) transformers.Transformer {
+	return transformer{
+		limit: limit,
+		next:  next,
+	}
+}
+
+func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
+	enc := strings.ToLower(strings.TrimSpace(msg.Headers[messaging.ContentEncodingHeader]))
+	if enc == "" {
+		enc = detect(msg.Payload)
+	}
+
+	var err error
+	switch enc {
+	case Identity:
+		return t.next.Transform(msg)
+	case Gzip, "x-gzip":
+		msg.Payload, err = t.gunzip(msg.Payload)
+	case Deflate:
+		msg.Payload, err = t.inflate(msg.Payload)
+	default:
+		return nil, errors.Wrap(ErrUnsupportedEncoding, errors.New(enc))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Content type is kept, only the encoding is removed.
+	var headers map[string]string
+	for k, v := range msg.Headers {
+		if k == messaging.ContentEncodingHeader {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[k] = v
+	}
+	msg.Headers = headers
+
+	return t.next.Transform(msg)
+}
+
+func (t transformer) gunzip(payload []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, errors.Wrap(ErrCorrupted, err)
+	}
+	defer r.Close()
+
+	return t.read(r)
+}
+
+// inflate decompresses the zlib wrapped deflate stream, as specified for the
+// HTTP deflate encoding, falling back to the raw deflate stream which many
+// clients send instead.
+func (t transformer) inflate(payload []byte) ([]byte, error) {
+	if isZlib(payload) {
+		r, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, errors.Wrap(ErrCorrupted, err)
+		}
+		defer r.Close()
+		return t.read(r)
+	}
+
+	r := flate.NewReader(bytes.NewReader(payload))
+	defer r.Close()
+	return t.read(r)
+}
+
+func (t transformer) read(r io.Reader) ([]byte, error) {
+	data, err := ioutil.ReadAll(io.LimitReader(r, t.limit+1))
+	if err != nil {
+		return nil, errors.Wrap(ErrCorrupted, err)
+	}
+	if int64(len(data)) > t.limit {
+		return nil, ErrTooLarge
+	}
+
+	return data, nil
+}
+
+// detect checks the gzip magic bytes followed by the deflate method, the only
+// one gzip defines (RFC 1952 section 2.3.1).
+func detect(payload []byte) string {
+	if len(payload) >= 3 && payload[0] == 0x1f && payload[1] == 0x8b && payload[2] == 8 {
+		return Gzip
+	}
+	return Identity
+}
+
+// isZlib checks zlib header, which consists of the deflate method with the
+// window size of at most 32K, no preset dictionary and the header checksum
+// (RFC 1950 section 2.2). It tells zlib streams from raw deflate ones only,
+// about one in 64 plain payloads matches it.
+func isZlib(payload []byte) bool {
+	if len(payload) < 2 {
+		return false
+	}
+	cmf, flg := payload[0], payload[1]
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && flg&0x20 == 0 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
@@ -0,0 +1,232 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package decompress_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/decompress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const limit = 1024
+
+var payload = []byte(`[{"bn":"some-base-name:","bt":1.276020076001e+09,"bu":"A","bver":5,"n":"voltage","u":"V","v":120.1}]`)
+
+// recorder is the transformer recording the transformed message.
+type recorder struct {
+	msg messaging.Message
+}
+
+func (r *recorder) Transform(msg messaging.Message) (interface{}, error) {
+	r.msg = msg
+	return msg, nil
+}
+
+func TestTransform(t *testing.T) {
+	rec := &recorder{}
+	tr := decompress.New(limit, rec)
+
+	gzipped := compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, payload)
+	zlibbed := compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, payload)
+	deflated := compress(t, func(w io.Writer) io.WriteCloser {
+		fw, err := flate.NewWriter(w, flate.DefaultCompression)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		return fw
+	}, payload)
+	large := compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, make([]byte, limit+1))
+	exact := compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, make([]byte, limit))
+
+	cases := []struct {
+		desc     string
+		payload  []byte
+		headers  map[string]string
+		expected []byte
+		err      error
+	}{
+		{
+			desc:     "transform gzip payload with header",
+			payload:  gzipped,
+			headers:  map[string]string{messaging.ContentEncodingHeader: "gzip", messaging.ContentTypeHeader: "application/senml+json"},
+			expected: payload,
+		},
+		{
+			desc:     "transform gzip payload without header",
+			payload:  gzipped,
+			expected: payload,
+		},
+		{
+			desc:     "transform zlib payload with header",
+			payload:  zlibbed,
+			headers:  map[string]string{messaging.ContentEncodingHeader: "deflate"},
+			expected: payload,
+		},
+		{
+			desc:     "transform raw deflate payload with header",
+			payload:  deflated,
+			headers:  map[string]string{messaging.ContentEncodingHeader: "Deflate", messaging.ContentTypeHeader: "application/json"},
+			expected: payload,
+		},
+		{
+			desc:     "transform payload with decompressed size equal to limit",
+			payload:  exact,
+			expected: make([]byte, limit),
+		},
+		{
+			desc:    "transform payload exceeding limit",
+			payload: large,
+			err:     decompress.ErrTooLarge,
+		},
+		{
+			desc:    "transform truncated gzip payload",
+			payload: gzipped[:len(gzipped)/2],
+			err:     decompress.ErrCorrupted,
+		},
+		{
+			desc:    "transform gzip payload with corrupted checksum",
+			payload: append(append([]byte{}, gzipped[:len(gzipped)-8]...), 0, 0, 0, 0, 0, 0, 0, 0),
+			err:     decompress.ErrCorrupted,
+		},
+		{
+			desc:    "transform uncompressed payload with gzip header",
+			payload: payload,
+			headers: map[string]string{messaging.ContentEncodingHeader: "gzip"},
+			err:     decompress.ErrCorrupted,
+		},
+		{
+			desc:    "transform uncompressed payload with deflate header",
+			payload: payload,
+			headers: map[string]string{messaging.ContentEncodingHeader: "deflate"},
+			err:     decompress.ErrCorrupted,
+		},
+		{
+			desc:    "transform payload with unsupported encoding",
+			payload: gzipped,
+			headers: map[string]string{messaging.ContentEncodingHeader: "br"},
+			err:     decompress.ErrUnsupportedEncoding,
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel:  "channel",
+			Subtopic: "subtopic",
+			Payload:  tc.payload,
+			Headers:  tc.headers,
+		}
+		rec.msg = messaging.Message{}
+		_, err := tr.Transform(msg)
+		if tc.err != nil {
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+			assert.Equal(t, messaging.Message{}, rec.msg, fmt.Sprintf("%s: expected message not to be passed on", tc.desc))
+			continue
+		}
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		expected := msg
+		expected.Payload = tc.expected
+		expected.Headers = nil
+		if ct, ok := tc.headers[messaging.ContentTypeHeader]; ok {
+			expected.Headers = map[string]string{messaging.ContentTypeHeader: ct}
+		}
+		assert.Equal(t, expected, rec.msg, fmt.Sprintf("%s: expected %v got %v", tc.desc, expected, rec.msg))
+	}
+}
+
+func TestTransformPassthrough(t *testing.T) {
+	rec := &recorder{}
+	tr := decompress.New(limit, rec)
+
+	zlibbed := compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, payload)
+
+	cases := []struct {
+		desc    string
+		payload []byte
+		headers map[string]string
+	}{
+		{
+			desc:    "pass JSON payload",
+			payload: payload,
+		},
+		{
+			desc:    "pass CBOR payload",
+			payload: []byte{0x81, 0xa2, 0x00, 0x61, 0x61, 0x02, 0x01},
+		},
+		{
+			desc:    "pass payload with content type",
+			payload: payload,
+			headers: map[string]string{messaging.ContentTypeHeader: "application/senml+json"},
+		},
+		{
+			desc:    "pass protobuf payload starting with bytes matching zlib header",
+			payload: []byte{0x08, 0x1d, 0x12, 0x04, 0x74, 0x65, 0x6d, 0x70},
+		},
+		{
+			desc:    "pass protobuf payload starting with other bytes matching zlib header",
+			payload: []byte{0x08, 0x5b, 0x10, 0x01},
+		},
+		{
+			desc:    "pass text payload starting with hC",
+			payload: []byte("hCelsius=21.5"),
+		},
+		{
+			desc:    "pass text payload starting with XG",
+			payload: []byte("XG-100 online"),
+		},
+		{
+			desc:    "pass text payload starting with HK",
+			payload: []byte("HK:42"),
+		},
+		{
+			desc:    "pass zlib payload without header",
+			payload: zlibbed,
+		},
+		{
+			desc:    "pass payload starting with gzip magic bytes without deflate method",
+			payload: []byte{0x1f, 0x8b, 0x00},
+		},
+		{
+			desc:    "pass payload with identity encoding",
+			payload: []byte{0x1f, 0x8b, 0x00},
+			headers: map[string]string{messaging.ContentEncodingHeader: "identity"},
+		},
+		{
+			desc:    "pass empty payload",
+			payload: []byte{},
+		},
+		{
+			desc:    "pass large payload",
+			payload: bytes.Repeat([]byte("a"), limit+1),
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel: "channel",
+			Payload: tc.payload,
+			Headers: tc.headers,
+		}
+		_, err := tr.Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, msg, rec.msg, fmt.Sprintf("%s: expected %v got %v", tc.desc, msg, rec.msg))
+	}
+}
+
+func compress(t *testing.T, writer func(io.Writer) io.WriteCloser, data []byte) []byte {
+	var buf bytes.Buffer
+	w := writer(&buf)
+	_, err := w.Write(data)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	require.Nil(t, w.Close(), "closing writer expected to succeed")
+	return buf.Bytes()
+}
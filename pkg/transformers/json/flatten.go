@@ -0,0 +1,183 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package json
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// Policies of flattening the arrays.
+const (
+	// ArrayKeep keeps arrays as they are.
+	ArrayKeep = "keep"
+	// ArrayIndex flattens array elements using the index as the key.
+	ArrayIndex = "index"
+	// ArrayExplode creates a separate message for each array element.
+	ArrayExplode = "explode"
+	// ArrayJSON keeps arrays as JSON encoded strings.
+	ArrayJSON = "json"
+)
+
+// maxExploded limits the number of messages created by exploding the arrays
+// of a single object.
+const maxExploded = 1000
+
+var errTooManyMessages = errors.New("too many messages created by exploding arrays")
+
+// FlattenConfig represents nested JSON objects flattening settings.
+type FlattenConfig struct {
+	// Separator joins the keys of nested objects. Default is "/".
+	Separator string `toml:"separator"`
+
+	// MaxDepth is the maximum depth of the flattened keys. Objects and
+	// arrays nested deeper are kept as JSON encoded strings. Zero value
+	// disables the limit.
+	MaxDepth int `toml:"max_depth"`
+
+	// Arrays is the array flattening policy. Default is ArrayKeep.
+	Arrays string `toml:"arrays"`
+}
+
+type flattener struct {
+	sep      string
+	maxDepth int
+	arrays   string
+}
+
+func newFlattener(cfg FlattenConfig) flattener {
+	f := flattener{
+		sep:      cfg.Separator,
+		maxDepth: cfg.MaxDepth,
+		arrays:   cfg.Arrays,
+	}
+	if f.sep == "" {
+		f.sep = sep
+	}
+	return f
+}
+
+// flatten returns flat objects created from the nested object. Unless
+// arrays are exploded, the result contains a single flat object.
+func (f flattener) flatten(m map[string]interface{}) ([]map[string]interface{}, error) {
+	return f.object("", 1, m, []map[string]interface{}{{}})
+}
+
+// object adds the fields of the object at the given depth to every flat object.
+func (f flattener) object(prefix string, depth int, obj map[string]interface{}, flats []map[string]interface{}) ([]map[string]interface{}, error) {
+	ks := make([]string, 0, len(obj))
+	for k := range obj {
+		if err := f.validateKey(k); err != nil {
+			return nil, err
+		}
+		ks = append(ks, k)
+	}
+	// Sort keys so that exploded messages are created in the same order.
+	sort.Strings(ks)
+
+	var err error
+	for _, k := range ks {
+		if flats, err = f.value(prefix+k, depth, obj[k], flats); err != nil {
+			return nil, err
+		}
+	}
+	return flats, nil
+}
+
+func (f flattener) value(key string, depth int, v interface{}, flats []map[string]interface{}) ([]map[string]interface{}, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if f.tooDeep(depth) {
+			return f.raw(key, val, flats)
+		}
+		return f.object(key+f.sep, depth+1, val, flats)
+	case []interface{}:
+		return f.array(key, depth, val, flats)
+	default:
+		return set(flats, key, v), nil
+	}
+}
+
+func (f flattener) array(key string, depth int, arr []interface{}, flats []map[string]interface{}) ([]map[string]interface{}, error) {
+	switch f.arrays {
+	case ArrayJSON:
+		return f.raw(key, arr, flats)
+	case ArrayIndex:
+		if f.tooDeep(depth) {
+			return f.raw(key, arr, flats)
+		}
+		var err error
+		for i, e := range arr {
+			if flats, err = f.value(key+f.sep+strconv.Itoa(i), depth+1, e, flats); err != nil {
+				return nil, err
+			}
+		}
+		return flats, nil
+	case ArrayExplode:
+		if len(arr) == 0 {
+			return set(flats, key, arr), nil
+		}
+		var res []map[string]interface{}
+		for _, e := range arr {
+			// Every element is added to its own copy of the flat objects.
+			exploded, err := f.value(key, depth, e, clone(flats))
+			if err != nil {
+				return nil, err
+			}
+			if res = append(res, exploded...); len(res) > maxExploded {
+				return nil, errTooManyMessages
+			}
+		}
+		return res, nil
+	default:
+		return set(flats, key, arr), nil
+	}
+}
+
+func (f flattener) tooDeep(depth int) bool {
+	return f.maxDepth > 0 && depth >= f.maxDepth
+}
+
+func (f flattener) raw(key string, v interface{}, flats []map[string]interface{}) ([]map[string]interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return set(flats, key, string(b)), nil
+}
+
+func (f flattener) validateKey(k string) error {
+	if strings.Contains(k, f.sep) {
+		return errInvalidKey
+	}
+	for _, key := range keys {
+		if k == key {
+			return errInvalidKey
+		}
+	}
+	return nil
+}
+
+func set(flats []map[string]interface{}, key string, v interface{}) []map[string]interface{} {
+	for _, m := range flats {
+		m[key] = v
+	}
+	return flats
+}
+
+func clone(flats []map[string]interface{}) []map[string]interface{} {
+	res := make([]map[string]interface{}, len(flats))
+	for i, m := range flats {
+		c := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			c[k] = v
+		}
+		res[i] = c
+	}
+	return res
+}
@@ -13,6 +13,8 @@ type Message struct {
 	Subtopic  string  `json:"subtopic,omitempty" db:"subtopic" bson:"subtopic,omitempty"`
 	Publisher string  `json:"publisher,omitempty" db:"publisher" bson:"publisher"`
 	Protocol  string  `json:"protocol,omitempty" db:"protocol" bson:"protocol"`
+	Tenant    string  `json:"tenant,omitempty" db:"tenant" bson:"tenant,omitempty"`
+	MessageID string  `json:"message_id,omitempty" db:"message_id" bson:"message_id,omitempty"`
 	Payload   Payload `json:"payload,omitempty" db:"payload" bson:"payload,omitempty"`
 }
 
@@ -0,0 +1,176 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package json
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// Supported time field formats. Any other format is used as Go time layout.
+const (
+	UnixFormat       = "unix"
+	UnixMillisFormat = "unix_ms"
+	UnixMicrosFormat = "unix_us"
+	UnixNanosFormat  = "unix_ns"
+	RFC3339Format    = "rfc3339"
+)
+
+// Reasons of falling back to the message arrival time.
+const (
+	missingTime    = "missing"
+	invalidTime    = "invalid"
+	outOfRangeTime = "out_of_range"
+)
+
+var (
+	errMissingTime = errors.New("missing message time")
+	errTimeFormat  = errors.New("invalid time field value")
+)
+
+// TimeField represents the payload field containing the message time.
+type TimeField struct {
+	// Name is the field name. Nested fields are specified using the
+	// composite key, e.g. "data/ts" for the field "ts" of the object "data".
+	Name string `toml:"name"`
+
+	// Format is one of the supported time formats or Go time layout.
+	Format string `toml:"format"`
+}
+
+// TimeConfig represents the settings of message time extraction. The first
+// of the time fields found in the payload is used as the message time.
+type TimeConfig struct {
+	Fields []TimeField `toml:"fields"`
+
+	// KeepField keeps the time field in the payload once it's used as the
+	// message time.
+	KeepField bool `toml:"keep_field"`
+
+	// FallbackToArrival uses message arrival time if the payload doesn't
+	// contain a valid time field. Otherwise, the message is rejected.
+	FallbackToArrival bool `toml:"fallback_to_arrival"`
+
+	// MaxPast and MaxFuture limit the difference between the message time
+	// and the arrival time. Messages out of the range fall back to the
+	// arrival time. Zero value disables the limit.
+	MaxPast   time.Duration `toml:"max_past"`
+	MaxFuture time.Duration `toml:"max_future"`
+}
+
+type timeExtractor struct {
+	cfg       TimeConfig
+	fallbacks metrics.Counter
+}
+
+// extract returns the message time in nanoseconds, falling back to the
+// arrival time if the payload doesn't contain the valid time.
+func (te timeExtractor) extract(payload map[string]interface{}, arrival int64) (int64, error) {
+	reason := missingTime
+	for _, f := range te.cfg.Fields {
+		val, ok := payload[f.Name]
+		if !ok {
+			continue
+		}
+		t, err := parseTime(val, f.Format)
+		if err != nil {
+			reason = invalidTime
+			continue
+		}
+		if !te.inRange(t, arrival) {
+			// Keep the field since its value is not used.
+			te.fallbacks.With("reason", outOfRangeTime).Add(1)
+			return arrival, nil
+		}
+		if !te.cfg.KeepField {
+			delete(payload, f.Name)
+		}
+		return t.UnixNano(), nil
+	}
+
+	if !te.cfg.FallbackToArrival {
+		if reason == invalidTime {
+			return 0, errTimeFormat
+		}
+		return 0, errMissingTime
+	}
+	te.fallbacks.With("reason", reason).Add(1)
+	return arrival, nil
+}
+
+func (te timeExtractor) inRange(t time.Time, arrival int64) bool {
+	ref := time.Unix(0, arrival)
+	if te.cfg.MaxPast > 0 && t.Before(ref.Add(-te.cfg.MaxPast)) {
+		return false
+	}
+	if te.cfg.MaxFuture > 0 && t.After(ref.Add(te.cfg.MaxFuture)) {
+		return false
+	}
+	return true
+}
+
+func parseTime(val interface{}, format string) (time.Time, error) {
+	switch format {
+	case UnixFormat, UnixMillisFormat, UnixMicrosFormat, UnixNanosFormat:
+		n, err := number(val)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return unixTime(n, format)
+	case RFC3339Format:
+		format = time.RFC3339Nano
+	}
+
+	s, ok := val.(string)
+	if !ok {
+		return time.Time{}, errors.Wrap(errTimeFormat, fmt.Errorf("expected string, got %T", val))
+	}
+	t, err := time.Parse(format, s)
+	if err != nil {
+		return time.Time{}, errors.Wrap(errTimeFormat, err)
+	}
+	return t, nil
+}
+
+func number(val interface{}) (float64, error) {
+	switch v := val.(type) {
+	case float64:
+		return v, nil
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, errors.Wrap(errTimeFormat, err)
+		}
+		return n, nil
+	default:
+		return 0, errors.Wrap(errTimeFormat, fmt.Errorf("expected number, got %T", val))
+	}
+}
+
+func unixTime(n float64, format string) (time.Time, error) {
+	var unit time.Duration
+	switch format {
+	case UnixFormat:
+		unit = time.Second
+	case UnixMillisFormat:
+		unit = time.Millisecond
+	case UnixMicrosFormat:
+		unit = time.Microsecond
+	default:
+		unit = time.Nanosecond
+	}
+
+	if math.IsNaN(n) || math.Abs(n*float64(unit)) > math.MaxInt64 {
+		return time.Time{}, errors.Wrap(errTimeFormat, fmt.Errorf("timestamp %v out of range", n))
+	}
+	// Convert whole and fractional part separately to avoid loss of precision.
+	whole, frac := math.Modf(n)
+	ns := int64(whole)*int64(unit) + int64(math.Round(frac*float64(unit)))
+	return time.Unix(0, ns), nil
+}
@@ -7,7 +7,9 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/go-kit/kit/metrics"
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/jsonschema"
 	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/transformers"
 )
@@ -18,29 +20,77 @@ var keys = [...]string{"publisher", "protocol", "channel", "subtopic"}
 
 var (
 	// ErrTransform reprents an error during parsing message.
-	ErrTransform         = errors.New("unable to parse JSON object")
+	ErrTransform = errors.New("unable to parse JSON object")
+
+	// ErrSchemaViolation indicates the payload not matching the JSON schema
+	// of the channel.
+	ErrSchemaViolation = errors.New("payload violates channel schema")
+
 	errInvalidKey        = errors.New("invalid object key")
 	errUnknownFormat     = errors.New("unknown format of JSON message")
 	errInvalidFormat     = errors.New("invalid JSON object")
 	errInvalidNestedJSON = errors.New("invalid nested JSON object")
 )
 
-type funcTransformer func(messaging.Message) (interface{}, error)
+type transformer struct {
+	flat    flattener
+	time    *timeExtractor
+	schemas Schemas
+}
+
+// Schemas provides the JSON schemas of the channels.
+type Schemas interface {
+	// Schema returns the schema of the channel, or nil if the channel
+	// messages are not validated.
+	Schema(channel string) *jsonschema.Schema
+}
 
-// New returns a new JSON transformer.
+// Config represents JSON transformer settings.
+type Config struct {
+	Flatten FlattenConfig `toml:"flatten"`
+	Time    TimeConfig    `toml:"time"`
+}
+
+// New returns a new JSON transformer, which flattens nested objects using
+// the default separator, keeps the arrays and uses the message arrival time
+// as the message time.
 func New() transformers.Transformer {
-	return funcTransformer(transformer)
+	return transformer{flat: newFlattener(FlattenConfig{})}
+}
+
+// NewWithConfig returns a new JSON transformer using the given flattening
+// settings, which extracts the message time from the payload. If there are
+// no time fields configured, the arrival time is used. Falling back to the
+// arrival time is counted by the fallbacks counter, labeled by the reason.
+func NewWithConfig(cfg Config, fallbacks metrics.Counter) transformers.Transformer {
+	return NewWithSchemas(cfg, fallbacks, nil)
 }
 
-func (fh funcTransformer) Transform(msg messaging.Message) (interface{}, error) {
-	return fh(msg)
+// NewWithSchemas returns a new JSON transformer using the given settings,
+// which validates the payloads against the schemas of their channels before
+// they are flattened. Payloads of the channels without a schema are not
+// validated, as well as all the payloads if schemas are nil.
+func NewWithSchemas(cfg Config, fallbacks metrics.Counter, schemas Schemas) transformers.Transformer {
+	t := transformer{
+		flat:    newFlattener(cfg.Flatten),
+		schemas: schemas,
+	}
+	if len(cfg.Time.Fields) > 0 {
+		t.time = &timeExtractor{
+			cfg:       cfg.Time,
+			fallbacks: fallbacks,
+		}
+	}
+	return t
 }
 
-func transformer(msg messaging.Message) (interface{}, error) {
+func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
 	ret := Message{
 		Publisher: msg.Publisher,
 		Created:   msg.Created,
 		Protocol:  msg.Protocol,
+		Tenant:    messaging.Tenant(msg),
+		MessageID: messaging.ID(msg),
 		Channel:   msg.Channel,
 		Subtopic:  msg.Subtopic,
 	}
@@ -56,14 +106,16 @@ func transformer(msg messaging.Message) (interface{}, error) {
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		return nil, errors.Wrap(ErrTransform, err)
 	}
+	if err := t.validate(msg.Channel, payload); err != nil {
+		return nil, err
+	}
 	switch p := payload.(type) {
 	case map[string]interface{}:
-		flat, err := Flatten(p)
+		res, err := t.messages(ret, p)
 		if err != nil {
 			return nil, errors.Wrap(ErrTransform, err)
 		}
-		ret.Payload = flat
-		return Messages{[]Message{ret}, format}, nil
+		return Messages{res, format}, nil
 	case []interface{}:
 		res := []Message{}
 		// Make an array of messages from the root array.
@@ -72,13 +124,11 @@ func transformer(msg messaging.Message) (interface{}, error) {
 			if !ok {
 				return nil, errors.Wrap(ErrTransform, errInvalidNestedJSON)
 			}
-			flat, err := Flatten(v)
+			msgs, err := t.messages(ret, v)
 			if err != nil {
 				return nil, errors.Wrap(ErrTransform, err)
 			}
-			newMsg := ret
-			newMsg.Payload = flat
-			res = append(res, newMsg)
+			res = append(res, msgs...)
 		}
 		return Messages{res, format}, nil
 	default:
@@ -86,6 +136,47 @@ func transformer(msg messaging.Message) (interface{}, error) {
 	}
 }
 
+func (t transformer) validate(channel string, payload interface{}) error {
+	if t.schemas == nil {
+		return nil
+	}
+	schema := t.schemas.Schema(channel)
+	if schema == nil {
+		return nil
+	}
+	if err := schema.Validate(payload); err != nil {
+		return errors.Wrap(ErrSchemaViolation, err)
+	}
+	return nil
+}
+
+// messages creates messages from the JSON object. There is more than one
+// message only if the arrays are exploded.
+func (t transformer) messages(msg Message, obj map[string]interface{}) ([]Message, error) {
+	flats, err := t.flat.flatten(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Message, len(flats))
+	for i, flat := range flats {
+		m := msg
+		m.Payload = flat
+		if m.Created, err = t.created(flat, msg.Created); err != nil {
+			return nil, err
+		}
+		res[i] = m
+	}
+	return res, nil
+}
+
+func (t transformer) created(payload map[string]interface{}, arrival int64) (int64, error) {
+	if t.time == nil {
+		return arrival, nil
+	}
+	return t.time.extract(payload, arrival)
+}
+
 // ParseFlat receives flat map that reprents complex JSON objects and returns
 // the corresponding complex JSON object with nested maps. It's the opposite
 // of the Flatten function.
@@ -121,29 +212,9 @@ func ParseFlat(flat interface{}) interface{} {
 
 // Flatten makes nested maps flat using composite keys created by concatenation of the nested keys.
 func Flatten(m map[string]interface{}) (map[string]interface{}, error) {
-	return flatten("", make(map[string]interface{}), m)
-}
-
-func flatten(prefix string, m, m1 map[string]interface{}) (map[string]interface{}, error) {
-	for k, v := range m1 {
-		if strings.Contains(k, sep) {
-			return nil, errInvalidKey
-		}
-		for _, key := range keys {
-			if k == key {
-				return nil, errInvalidKey
-			}
-		}
-		switch val := v.(type) {
-		case map[string]interface{}:
-			var err error
-			m, err = flatten(prefix+k+sep, m, val)
-			if err != nil {
-				return nil, err
-			}
-		default:
-			m[prefix+k] = v
-		}
+	flats, err := newFlattener(FlattenConfig{}).flatten(m)
+	if err != nil {
+		return nil, err
 	}
-	return m, nil
+	return flats[0], nil
 }
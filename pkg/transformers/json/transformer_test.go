@@ -5,13 +5,17 @@ package json_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-kit/kit/metrics"
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/jsonschema"
 	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const (
@@ -36,6 +40,7 @@ func TestTransformJSON(t *testing.T) {
 
 	listMsg := msg
 	listMsg.Payload = []byte(listPayload)
+	listMsg.Headers = map[string]string{messaging.TenantHeader: "tenant"}
 
 	jsonMsg := json.Messages{
 		Data: []json.Message{
@@ -44,6 +49,7 @@ func TestTransformJSON(t *testing.T) {
 				Subtopic:  msg.Subtopic,
 				Publisher: msg.Publisher,
 				Protocol:  msg.Protocol,
+				Tenant:    messaging.DefaultTenant,
 				Created:   msg.Created,
 				Payload: map[string]interface{}{
 					"key1":      "val1",
@@ -66,6 +72,7 @@ func TestTransformJSON(t *testing.T) {
 				Subtopic:  msg.Subtopic,
 				Publisher: msg.Publisher,
 				Protocol:  msg.Protocol,
+				Tenant:    "tenant",
 				Created:   msg.Created,
 				Payload: map[string]interface{}{
 					"key1":      "val1",
@@ -79,6 +86,7 @@ func TestTransformJSON(t *testing.T) {
 				Subtopic:  msg.Subtopic,
 				Publisher: msg.Publisher,
 				Protocol:  msg.Protocol,
+				Tenant:    "tenant",
 				Created:   msg.Created,
 				Payload: map[string]interface{}{
 					"key1":      "val1",
@@ -129,3 +137,477 @@ func TestTransformJSON(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s, got %s", tc.desc, tc.err, err))
 	}
 }
+
+type counter struct {
+	labels []string
+	values map[string]float64
+}
+
+func newCounter() *counter {
+	return &counter{values: make(map[string]float64)}
+}
+
+func (c *counter) With(labelValues ...string) metrics.Counter {
+	return &counter{labels: labelValues, values: c.values}
+}
+
+func (c *counter) Add(delta float64) {
+	c.values[c.labels[len(c.labels)-1]] += delta
+}
+
+func TestTransformTime(t *testing.T) {
+	arrival := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := arrival.Add(-2 * time.Hour)
+	sec := created.Unix()
+
+	tsField := []json.TimeField{{Name: "ts", Format: json.UnixFormat}}
+	cases := []struct {
+		desc    string
+		cfg     json.TimeConfig
+		payload string
+		created int64
+		fields  map[string]interface{}
+		reason  string
+		err     error
+	}{
+		{
+			desc:    "extract unix seconds",
+			cfg:     json.TimeConfig{Fields: tsField},
+			payload: fmt.Sprintf(`{"ts": %d, "v": 1}`, sec),
+			created: created.UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+		{
+			desc:    "extract fractional unix seconds",
+			cfg:     json.TimeConfig{Fields: tsField},
+			payload: fmt.Sprintf(`{"ts": %d.5, "v": 1}`, sec),
+			created: created.Add(500 * time.Millisecond).UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+		{
+			desc:    "extract unix milliseconds",
+			cfg:     json.TimeConfig{Fields: []json.TimeField{{Name: "ts", Format: json.UnixMillisFormat}}},
+			payload: fmt.Sprintf(`{"ts": %d123, "v": 1}`, sec),
+			created: created.Add(123 * time.Millisecond).UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+		{
+			desc:    "extract unix milliseconds as string",
+			cfg:     json.TimeConfig{Fields: []json.TimeField{{Name: "ts", Format: json.UnixMillisFormat}}},
+			payload: fmt.Sprintf(`{"ts": "%d000", "v": 1}`, sec),
+			created: created.UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+		{
+			desc:    "extract unix microseconds",
+			cfg:     json.TimeConfig{Fields: []json.TimeField{{Name: "ts", Format: json.UnixMicrosFormat}}},
+			payload: fmt.Sprintf(`{"ts": %d000001, "v": 1}`, sec),
+			created: created.Add(time.Microsecond).UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+		{
+			desc:    "extract unix nanoseconds",
+			cfg:     json.TimeConfig{Fields: []json.TimeField{{Name: "ts", Format: json.UnixNanosFormat}}},
+			payload: fmt.Sprintf(`{"ts": %d000000000, "v": 1}`, sec),
+			created: created.UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+		{
+			desc:    "extract RFC3339 time",
+			cfg:     json.TimeConfig{Fields: []json.TimeField{{Name: "ts", Format: json.RFC3339Format}}},
+			payload: `{"ts": "2021-06-01T10:00:00.25Z", "v": 1}`,
+			created: created.Add(250 * time.Millisecond).UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+		{
+			desc:    "extract time using Go layout",
+			cfg:     json.TimeConfig{Fields: []json.TimeField{{Name: "ts", Format: "2006-01-02 15:04:05"}}},
+			payload: `{"ts": "2021-06-01 10:00:00", "v": 1}`,
+			created: created.UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+		{
+			desc:    "extract nested time field",
+			cfg:     json.TimeConfig{Fields: []json.TimeField{{Name: "data/time", Format: json.RFC3339Format}}},
+			payload: `{"data": {"time": "2021-06-01T12:00:00+02:00", "v": 1}}`,
+			created: created.UnixNano(),
+			fields:  map[string]interface{}{"data/v": float64(1)},
+		},
+		{
+			desc: "extract first of the candidate fields",
+			cfg: json.TimeConfig{Fields: []json.TimeField{
+				{Name: "ts", Format: json.UnixFormat},
+				{Name: "timestamp", Format: json.UnixMillisFormat},
+				{Name: "time", Format: json.RFC3339Format},
+			}},
+			payload: `{"time": "2021-06-01T10:00:00Z", "timestamp": "invalid", "v": 1}`,
+			created: created.UnixNano(),
+			fields:  map[string]interface{}{"timestamp": "invalid", "v": float64(1)},
+		},
+		{
+			desc:    "extract time and keep the field",
+			cfg:     json.TimeConfig{Fields: tsField, KeepField: true},
+			payload: fmt.Sprintf(`{"ts": %d, "v": 1}`, sec),
+			created: created.UnixNano(),
+			fields:  map[string]interface{}{"ts": float64(sec), "v": float64(1)},
+		},
+		{
+			desc:    "fall back to arrival time if time is missing",
+			cfg:     json.TimeConfig{Fields: tsField, FallbackToArrival: true},
+			payload: `{"v": 1}`,
+			created: arrival.UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+			reason:  "missing",
+		},
+		{
+			desc:    "fall back to arrival time if time is invalid",
+			cfg:     json.TimeConfig{Fields: tsField, FallbackToArrival: true},
+			payload: `{"ts": true, "v": 1}`,
+			created: arrival.UnixNano(),
+			fields:  map[string]interface{}{"ts": true, "v": float64(1)},
+			reason:  "invalid",
+		},
+		{
+			desc:    "reject message without time",
+			cfg:     json.TimeConfig{Fields: tsField},
+			payload: `{"v": 1}`,
+			err:     json.ErrTransform,
+		},
+		{
+			desc:    "reject message with invalid time",
+			cfg:     json.TimeConfig{Fields: tsField},
+			payload: `{"ts": "yesterday", "v": 1}`,
+			err:     json.ErrTransform,
+		},
+		{
+			desc:    "fall back to arrival time if time is too far in the past",
+			cfg:     json.TimeConfig{Fields: tsField, MaxPast: time.Hour},
+			payload: fmt.Sprintf(`{"ts": %d, "v": 1}`, sec),
+			created: arrival.UnixNano(),
+			fields:  map[string]interface{}{"ts": float64(sec), "v": float64(1)},
+			reason:  "out_of_range",
+		},
+		{
+			desc:    "fall back to arrival time if time is too far in the future",
+			cfg:     json.TimeConfig{Fields: tsField, MaxFuture: time.Hour},
+			payload: fmt.Sprintf(`{"ts": %d, "v": 1}`, arrival.Add(2*time.Hour).Unix()),
+			created: arrival.UnixNano(),
+			fields:  map[string]interface{}{"ts": float64(arrival.Add(2 * time.Hour).Unix()), "v": float64(1)},
+			reason:  "out_of_range",
+		},
+		{
+			desc:    "fall back to arrival time if timestamp overflows",
+			cfg:     json.TimeConfig{Fields: tsField, FallbackToArrival: true},
+			payload: `{"ts": 1e300, "v": 1}`,
+			created: arrival.UnixNano(),
+			fields:  map[string]interface{}{"ts": 1e300, "v": float64(1)},
+			reason:  "invalid",
+		},
+		{
+			desc:    "extract time within bounds",
+			cfg:     json.TimeConfig{Fields: tsField, MaxPast: 3 * time.Hour, MaxFuture: time.Hour},
+			payload: fmt.Sprintf(`{"ts": %d, "v": 1}`, sec),
+			created: created.UnixNano(),
+			fields:  map[string]interface{}{"v": float64(1)},
+		},
+	}
+
+	for _, tc := range cases {
+		fallbacks := newCounter()
+		tr := json.NewWithConfig(json.Config{Time: tc.cfg}, fallbacks)
+		msg := messaging.Message{
+			Channel:  "channel-1",
+			Subtopic: "subtopic-1",
+			Payload:  []byte(tc.payload),
+			Created:  arrival.UnixNano(),
+		}
+
+		res, err := tr.Transform(msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+
+		msgs := res.(json.Messages).Data
+		require.Len(t, msgs, 1, fmt.Sprintf("%s: expected single message", tc.desc))
+		assert.Equal(t, tc.created, msgs[0].Created, fmt.Sprintf("%s: expected created %d got %d", tc.desc, tc.created, msgs[0].Created))
+		assert.Equal(t, json.Payload(tc.fields), msgs[0].Payload, fmt.Sprintf("%s: expected payload %v got %v", tc.desc, tc.fields, msgs[0].Payload))
+
+		expected := map[string]float64{}
+		if tc.reason != "" {
+			expected[tc.reason] = 1
+		}
+		assert.Equal(t, expected, fallbacks.values, fmt.Sprintf("%s: expected fallbacks %v got %v", tc.desc, expected, fallbacks.values))
+	}
+}
+
+func TestTransformTimeList(t *testing.T) {
+	arrival := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	cfg := json.TimeConfig{
+		Fields:            []json.TimeField{{Name: "ts", Format: json.RFC3339Format}},
+		FallbackToArrival: true,
+	}
+	fallbacks := newCounter()
+	tr := json.NewWithConfig(json.Config{Time: cfg}, fallbacks)
+	msg := messaging.Message{
+		Subtopic: "subtopic-1",
+		Payload:  []byte(`[{"ts": "2021-06-01T10:00:00Z", "v": 1}, {"ts": "2021-06-01T11:00:00Z", "v": 2}, {"v": 3}]`),
+		Created:  arrival.UnixNano(),
+	}
+
+	res, err := tr.Transform(msg)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	msgs := res.(json.Messages).Data
+	expected := []int64{
+		arrival.Add(-2 * time.Hour).UnixNano(),
+		arrival.Add(-time.Hour).UnixNano(),
+		arrival.UnixNano(),
+	}
+	require.Len(t, msgs, len(expected), fmt.Sprintf("expected %d messages", len(expected)))
+	for i, m := range msgs {
+		assert.Equal(t, expected[i], m.Created, fmt.Sprintf("message %d: expected created %d got %d", i, expected[i], m.Created))
+	}
+	assert.Equal(t, map[string]float64{"missing": 1}, fallbacks.values, fmt.Sprintf("expected single fallback got %v", fallbacks.values))
+}
+
+func TestTransformFlatten(t *testing.T) {
+	cases := []struct {
+		desc     string
+		cfg      json.FlattenConfig
+		payload  string
+		payloads []json.Payload
+		err      error
+	}{
+		{
+			desc:     "flatten using custom separator",
+			cfg:      json.FlattenConfig{Separator: "."},
+			payload:  `{"a": {"b": 1, "c/d": 2}}`,
+			payloads: []json.Payload{{"a.b": float64(1), "a.c/d": float64(2)}},
+		},
+		{
+			desc:    "flatten key containing custom separator",
+			cfg:     json.FlattenConfig{Separator: "."},
+			payload: `{"a.b": 1}`,
+			err:     json.ErrTransform,
+		},
+		{
+			desc:     "flatten up to max depth",
+			cfg:      json.FlattenConfig{MaxDepth: 2},
+			payload:  `{"a": {"b": {"c": {"d": 1}}, "e": 2}, "f": 3}`,
+			payloads: []json.Payload{{"a/b": `{"c":{"d":1}}`, "a/e": float64(2), "f": float64(3)}},
+		},
+		{
+			desc:     "flatten with max depth of top level keys",
+			cfg:      json.FlattenConfig{MaxDepth: 1},
+			payload:  `{"a": {"b": 1}, "c": [1, 2], "d": 3}`,
+			payloads: []json.Payload{{"a": `{"b":1}`, "c": []interface{}{float64(1), float64(2)}, "d": float64(3)}},
+		},
+		{
+			desc:     "keep arrays by default",
+			payload:  `{"a": [1, {"b": 2}]}`,
+			payloads: []json.Payload{{"a": []interface{}{float64(1), map[string]interface{}{"b": float64(2)}}}},
+		},
+		{
+			desc:     "flatten arrays using index",
+			cfg:      json.FlattenConfig{Arrays: json.ArrayIndex},
+			payload:  `{"readings": [{"v": 1}, {"v": 2}], "mixed": [1, "s", true, null, [3]]}`,
+			payloads: []json.Payload{{"readings/0/v": float64(1), "readings/1/v": float64(2), "mixed/0": float64(1), "mixed/1": "s", "mixed/2": true, "mixed/3": nil, "mixed/4/0": float64(3)}},
+		},
+		{
+			desc:     "flatten arrays using index up to max depth",
+			cfg:      json.FlattenConfig{Arrays: json.ArrayIndex, MaxDepth: 2},
+			payload:  `{"a": [[1, 2], {"b": {"c": 1}}]}`,
+			payloads: []json.Payload{{"a/0": "[1,2]", "a/1": `{"b":{"c":1}}`}},
+		},
+		{
+			desc:     "keep arrays as JSON",
+			cfg:      json.FlattenConfig{Arrays: json.ArrayJSON},
+			payload:  `{"a": {"b": [1, {"c": "d"}]}}`,
+			payloads: []json.Payload{{"a/b": `[1,{"c":"d"}]`}},
+		},
+		{
+			desc:    "explode array of objects",
+			cfg:     json.FlattenConfig{Arrays: json.ArrayExplode},
+			payload: `{"dev": "d1", "readings": [{"n": "temp", "v": 1}, {"n": "hum", "v": 2}]}`,
+			payloads: []json.Payload{
+				{"dev": "d1", "readings/n": "temp", "readings/v": float64(1)},
+				{"dev": "d1", "readings/n": "hum", "readings/v": float64(2)},
+			},
+		},
+		{
+			desc:    "explode mixed type array",
+			cfg:     json.FlattenConfig{Arrays: json.ArrayExplode},
+			payload: `{"a": [1, "s", {"b": true}, [2, 3]]}`,
+			payloads: []json.Payload{
+				{"a": float64(1)},
+				{"a": "s"},
+				{"a/b": true},
+				{"a": float64(2)},
+				{"a": float64(3)},
+			},
+		},
+		{
+			desc:    "explode multiple arrays",
+			cfg:     json.FlattenConfig{Arrays: json.ArrayExplode},
+			payload: `{"a": [1, 2], "b": [3, 4]}`,
+			payloads: []json.Payload{
+				{"a": float64(1), "b": float64(3)},
+				{"a": float64(2), "b": float64(3)},
+				{"a": float64(1), "b": float64(4)},
+				{"a": float64(2), "b": float64(4)},
+			},
+		},
+		{
+			desc:     "explode empty array",
+			cfg:      json.FlattenConfig{Arrays: json.ArrayExplode},
+			payload:  `{"a": [], "b": 1}`,
+			payloads: []json.Payload{{"a": []interface{}{}, "b": float64(1)}},
+		},
+		{
+			desc:    "explode arrays from root array",
+			cfg:     json.FlattenConfig{Arrays: json.ArrayExplode},
+			payload: `[{"a": [1, 2]}, {"a": [3]}]`,
+			payloads: []json.Payload{
+				{"a": float64(1)},
+				{"a": float64(2)},
+				{"a": float64(3)},
+			},
+		},
+		{
+			desc:    "explode arrays into too many messages",
+			cfg:     json.FlattenConfig{Arrays: json.ArrayExplode},
+			payload: fmt.Sprintf(`{"a": [%s], "b": [%s]}`, numbers(40), numbers(40)),
+			err:     json.ErrTransform,
+		},
+	}
+
+	for _, tc := range cases {
+		tr := json.NewWithConfig(json.Config{Flatten: tc.cfg}, newCounter())
+		msg := messaging.Message{
+			Subtopic: "subtopic-1",
+			Payload:  []byte(tc.payload),
+		}
+
+		res, err := tr.Transform(msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+
+		var payloads []json.Payload
+		for _, m := range res.(json.Messages).Data {
+			payloads = append(payloads, m.Payload)
+		}
+		assert.Equal(t, tc.payloads, payloads, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.payloads, payloads))
+	}
+}
+
+func TestTransformExplodeTime(t *testing.T) {
+	cfg := json.Config{
+		Flatten: json.FlattenConfig{Arrays: json.ArrayExplode},
+		Time:    json.TimeConfig{Fields: []json.TimeField{{Name: "readings/ts", Format: json.UnixFormat}}},
+	}
+	tr := json.NewWithConfig(cfg, newCounter())
+	msg := messaging.Message{
+		Subtopic: "subtopic-1",
+		Payload:  []byte(`{"dev": "d1", "readings": [{"ts": 1622541600, "v": 1}, {"ts": 1622541660, "v": 2}]}`),
+	}
+
+	res, err := tr.Transform(msg)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	msgs := res.(json.Messages).Data
+	require.Len(t, msgs, 2, "expected message per reading")
+	for i, ts := range []int64{1622541600, 1622541660} {
+		created := time.Unix(ts, 0).UnixNano()
+		assert.Equal(t, created, msgs[i].Created, fmt.Sprintf("reading %d: expected created %d got %d", i, created, msgs[i].Created))
+		assert.Equal(t, json.Payload{"dev": "d1", "readings/v": float64(i + 1)}, msgs[i].Payload, fmt.Sprintf("reading %d: unexpected payload %v", i, msgs[i].Payload))
+	}
+}
+
+// schemas is the static set of channel schemas.
+type schemas map[string]*jsonschema.Schema
+
+func (s schemas) Schema(channel string) *jsonschema.Schema {
+	return s[channel]
+}
+
+func TestTransformSchema(t *testing.T) {
+	schema, err := jsonschema.Compile([]byte(`{"type": "object", "required": ["key1"], "properties": {"key2": {"type": "number"}}}`))
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	tr := json.NewWithSchemas(json.Config{}, nil, schemas{"channel-1": schema})
+
+	cases := []struct {
+		desc    string
+		channel string
+		payload string
+		count   int
+		err     error
+	}{
+		{
+			desc:    "transform payload matching schema",
+			channel: "channel-1",
+			payload: validPayload,
+			count:   1,
+		},
+		{
+			desc:    "transform payload without required field",
+			channel: "channel-1",
+			payload: `{"key2": 123}`,
+			err:     json.ErrSchemaViolation,
+		},
+		{
+			desc:    "transform payload with field of wrong type",
+			channel: "channel-1",
+			payload: `{"key1": "val1", "key2": "123"}`,
+			err:     json.ErrSchemaViolation,
+		},
+		{
+			desc:    "transform list payload against object schema",
+			channel: "channel-1",
+			payload: listPayload,
+			err:     json.ErrSchemaViolation,
+		},
+		{
+			desc:    "transform malformed payload",
+			channel: "channel-1",
+			payload: `{"key1":`,
+			err:     json.ErrTransform,
+		},
+		{
+			desc:    "transform payload on channel without schema",
+			channel: "channel-2",
+			payload: `{"key2": "123"}`,
+			count:   1,
+		},
+		{
+			desc:    "transform list payload on channel without schema",
+			channel: "channel-2",
+			payload: listPayload,
+			count:   2,
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel:  tc.channel,
+			Subtopic: "subtopic-1",
+			Payload:  []byte(tc.payload),
+		}
+		res, err := tr.Transform(msg)
+		if tc.err != nil {
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+			continue
+		}
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		msgs := res.(json.Messages)
+		assert.Len(t, msgs.Data, tc.count, fmt.Sprintf("%s: expected %d messages got %d", tc.desc, tc.count, len(msgs.Data)))
+	}
+}
+
+func numbers(n int) string {
+	s := make([]string, n)
+	for i := range s {
+		s[i] = fmt.Sprint(i)
+	}
+	return strings.Join(s, ",")
+}
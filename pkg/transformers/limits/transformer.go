@@ -0,0 +1,158 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package limits contains the transformer rejecting the messages exceeding
+// the deployment size and field limits, so they fail in the transformer stage
+// instead of at write time.
+package limits
+
+import (
+	"fmt"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+)
+
+// Default limits are safely under the limits of all the writer backends:
+// InfluxDB 64 KiB string fields and line length, MongoDB 16 MiB documents,
+// Cassandra 16 MiB mutations and PostgreSQL jsonb.
+const (
+	DefMaxPayloadSize = 1048576
+	DefMaxRecords     = 1000
+	DefMaxNameLength  = 256
+	DefMaxFields      = 256
+)
+
+var (
+	// ErrPayloadTooLarge indicates that the payload exceeds the size limit.
+	ErrPayloadTooLarge = errors.New("payload too large")
+
+	// ErrTooManyRecords indicates that the message is transformed to more
+	// records than allowed.
+	ErrTooManyRecords = errors.New("too many records")
+
+	// ErrNameTooLong indicates that the record name or the field name
+	// exceeds the length limit.
+	ErrNameTooLong = errors.New("name too long")
+
+	// ErrTooManyFields indicates that the flattened JSON object has more
+	// fields than allowed.
+	ErrTooManyFields = errors.New("too many fields")
+)
+
+// Config represents the message limits. Zero or negative value disables
+// the limit.
+type Config struct {
+	// MaxPayloadSize is the maximum payload size in bytes, after the
+	// payload is decompressed.
+	MaxPayloadSize int `toml:"max_payload_size"`
+
+	// MaxRecords is the maximum number of SenML records in a pack, or the
+	// maximum number of JSON messages created from a payload.
+	MaxRecords int `toml:"max_records"`
+
+	// MaxNameLength is the maximum length in bytes of the SenML record
+	// name or the flattened JSON field name.
+	MaxNameLength int `toml:"max_name_length"`
+
+	// MaxFields is the maximum number of fields of a flattened JSON object.
+	MaxFields int `toml:"max_fields"`
+}
+
+// Default returns the default limits.
+func Default() Config {
+	return Config{
+		MaxPayloadSize: DefMaxPayloadSize,
+		MaxRecords:     DefMaxRecords,
+		MaxNameLength:  DefMaxNameLength,
+		MaxFields:      DefMaxFields,
+	}
+}
+
+type transformer struct {
+	cfg  Config
+	next transformers.Transformer
+}
+
+// New returns a transformer checking the payload size before passing the
+// message on to the next transformer, and the SenML or JSON messages it
+// returns. Messages exceeding the limits are rejected with the error of the
+// violated limit, while the compliant ones are returned untouched.
+func New(cfg Config, next transformers.Transformer) transformers.Transformer {
+	return transformer{
+		cfg:  cfg,
+		next: next,
+	}
+}
+
+func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
+	if exceeds(len(msg.Payload), t.cfg.MaxPayloadSize) {
+		return nil, errors.Wrap(ErrPayloadTooLarge, fmt.Errorf("%d bytes exceeds the limit of %d bytes", len(msg.Payload), t.cfg.MaxPayloadSize))
+	}
+
+	res, err := t.next.Transform(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch msgs := res.(type) {
+	case []senml.Message:
+		err = t.checkSenML(msgs)
+	case mfjson.Messages:
+		err = t.checkJSON(msgs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t transformer) checkSenML(msgs []senml.Message) error {
+	if err := t.checkRecords(len(msgs)); err != nil {
+		return err
+	}
+	for _, m := range msgs {
+		if err := t.checkName(m.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t transformer) checkJSON(msgs mfjson.Messages) error {
+	if err := t.checkRecords(len(msgs.Data)); err != nil {
+		return err
+	}
+	for _, m := range msgs.Data {
+		if exceeds(len(m.Payload), t.cfg.MaxFields) {
+			return errors.Wrap(ErrTooManyFields, fmt.Errorf("%d fields exceeds the limit of %d fields", len(m.Payload), t.cfg.MaxFields))
+		}
+		for k := range m.Payload {
+			if err := t.checkName(k); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t transformer) checkRecords(n int) error {
+	if exceeds(n, t.cfg.MaxRecords) {
+		return errors.Wrap(ErrTooManyRecords, fmt.Errorf("%d records exceeds the limit of %d records", n, t.cfg.MaxRecords))
+	}
+	return nil
+}
+
+func (t transformer) checkName(name string) error {
+	if exceeds(len(name), t.cfg.MaxNameLength) {
+		return errors.Wrap(ErrNameTooLong, fmt.Errorf("name of %d bytes exceeds the limit of %d bytes", len(name), t.cfg.MaxNameLength))
+	}
+	return nil
+}
+
+func exceeds(n, limit int) bool {
+	return limit > 0 && n > limit
+}
@@ -0,0 +1,170 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package limits_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	mfjson "github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/limits"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cfg = limits.Config{
+	MaxPayloadSize: 128,
+	MaxRecords:     2,
+	MaxNameLength:  8,
+	MaxFields:      3,
+}
+
+// records returns the SenML pack of n records named by the name.
+func records(n int, name string) []byte {
+	recs := make([]string, n)
+	for i := range recs {
+		recs[i] = fmt.Sprintf(`{"n":"%s","v":%d}`, name, i)
+	}
+	return []byte("[" + strings.Join(recs, ",") + "]")
+}
+
+// object returns the JSON object of n fields, the first one named by the name.
+func object(n int, name string) []byte {
+	fields := make([]string, n)
+	for i := range fields {
+		fields[i] = fmt.Sprintf(`"f%d":%d`, i, i)
+	}
+	if n > 0 {
+		fields[0] = fmt.Sprintf(`"%s":0`, name)
+	}
+	return []byte("{" + strings.Join(fields, ",") + "}")
+}
+
+// padded returns the payload padded by the trailing spaces to the size.
+func padded(payload []byte, size int) []byte {
+	return append(payload, []byte(strings.Repeat(" ", size-len(payload)))...)
+}
+
+func TestTransformSenML(t *testing.T) {
+	tr := limits.New(cfg, senml.New(senml.JSON))
+
+	cases := []struct {
+		desc    string
+		payload []byte
+		err     error
+	}{
+		{
+			desc:    "transform payload of the maximum size",
+			payload: padded(records(1, "temp"), cfg.MaxPayloadSize),
+			err:     nil,
+		},
+		{
+			desc:    "transform payload exceeding the maximum size",
+			payload: padded(records(1, "temp"), cfg.MaxPayloadSize+1),
+			err:     limits.ErrPayloadTooLarge,
+		},
+		{
+			desc:    "transform pack of the maximum number of records",
+			payload: records(cfg.MaxRecords, "temp"),
+			err:     nil,
+		},
+		{
+			desc:    "transform pack exceeding the maximum number of records",
+			payload: records(cfg.MaxRecords+1, "temp"),
+			err:     limits.ErrTooManyRecords,
+		},
+		{
+			desc:    "transform record name of the maximum length",
+			payload: records(1, strings.Repeat("n", cfg.MaxNameLength)),
+			err:     nil,
+		},
+		{
+			desc:    "transform record name exceeding the maximum length",
+			payload: records(1, strings.Repeat("n", cfg.MaxNameLength+1)),
+			err:     limits.ErrNameTooLong,
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{Channel: "ch", Payload: tc.payload}
+		res, err := tr.Transform(msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		expected, err := senml.New(senml.JSON).Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, expected, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, expected, res))
+	}
+}
+
+func TestTransformJSON(t *testing.T) {
+	tr := limits.New(cfg, mfjson.New())
+
+	cases := []struct {
+		desc    string
+		payload []byte
+		err     error
+	}{
+		{
+			desc:    "transform object of the maximum number of fields",
+			payload: object(cfg.MaxFields, "f0"),
+			err:     nil,
+		},
+		{
+			desc:    "transform object exceeding the maximum number of fields",
+			payload: object(cfg.MaxFields+1, "f0"),
+			err:     limits.ErrTooManyFields,
+		},
+		{
+			desc:    "transform nested object exceeding the maximum number of flattened fields",
+			payload: []byte(`{"a":{"b":1,"c":2},"d":{"e":3,"f":4}}`),
+			err:     limits.ErrTooManyFields,
+		},
+		{
+			desc:    "transform field name of the maximum length",
+			payload: object(1, strings.Repeat("n", cfg.MaxNameLength)),
+			err:     nil,
+		},
+		{
+			desc:    "transform field name exceeding the maximum length",
+			payload: object(1, strings.Repeat("n", cfg.MaxNameLength+1)),
+			err:     limits.ErrNameTooLong,
+		},
+		{
+			desc:    "transform array of the maximum number of objects",
+			payload: []byte(`[{"a":1},{"a":2}]`),
+			err:     nil,
+		},
+		{
+			desc:    "transform array exceeding the maximum number of objects",
+			payload: []byte(`[{"a":1},{"a":2},{"a":3}]`),
+			err:     limits.ErrTooManyRecords,
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{Channel: "ch", Subtopic: "json", Payload: tc.payload}
+		res, err := tr.Transform(msg)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		expected, err := mfjson.New().Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, expected, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, expected, res))
+	}
+}
+
+func TestTransformDisabled(t *testing.T) {
+	tr := limits.New(limits.Config{}, mfjson.New())
+	payload := padded(object(limits.DefMaxFields+1, strings.Repeat("n", limits.DefMaxNameLength+1)), limits.DefMaxPayloadSize+1)
+
+	_, err := tr.Transform(messaging.Message{Channel: "ch", Subtopic: "json", Payload: payload})
+	assert.Nil(t, err, fmt.Sprintf("transform with disabled limits: unexpected error: %s", err))
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package protobuf
+
+import (
+	"encoding/base64"
+	"math"
+	"strconv"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// unknownKey is the key of the object containing unknown fields, indexed by
+// the field number.
+const unknownKey = "_unknown"
+
+var errUnknownField = errors.New("malformed unknown field")
+
+// object converts the message to JSON object, keyed by the field names.
+// Scalar fields without presence are always set, so that zero values are
+// not lost, while unset messages, lists and maps are omitted.
+func object(m protoreflect.Message) (map[string]interface{}, error) {
+	obj := make(map[string]interface{})
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if !m.Has(fd) && (fd.HasPresence() || fd.IsList() || fd.IsMap()) {
+			continue
+		}
+		v, err := field(fd, m.Get(fd))
+		if err != nil {
+			return nil, err
+		}
+		obj[string(fd.Name())] = v
+	}
+
+	if raw := m.GetUnknown(); len(raw) > 0 {
+		unknown, err := unknownFields(raw)
+		if err != nil {
+			return nil, err
+		}
+		obj[unknownKey] = unknown
+	}
+
+	return obj, nil
+}
+
+func field(fd protoreflect.FieldDescriptor, v protoreflect.Value) (interface{}, error) {
+	switch {
+	case fd.IsList():
+		l := v.List()
+		res := make([]interface{}, l.Len())
+		for i := range res {
+			val, err := single(fd, l.Get(i))
+			if err != nil {
+				return nil, err
+			}
+			res[i] = val
+		}
+		return res, nil
+	case fd.IsMap():
+		res := make(map[string]interface{})
+		var err error
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			var val interface{}
+			if val, err = single(fd.MapValue(), v); err != nil {
+				return false
+			}
+			res[k.String()] = val
+			return true
+		})
+		return res, err
+	default:
+		return single(fd, v)
+	}
+}
+
+func single(fd protoreflect.FieldDescriptor, v protoreflect.Value) (interface{}, error) {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return object(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name()), nil
+		}
+		return int32(v.Enum()), nil
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return float(v.Float()), nil
+	default:
+		// Booleans, strings and integers are encoded as they are.
+		return v.Interface(), nil
+	}
+}
+
+// float encodes special values as strings, the same way protobuf JSON
+// mapping does, since they are not supported by JSON.
+func float(f float64) interface{} {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	default:
+		return f
+	}
+}
+
+// unknownFields decodes unknown fields by their wire type. Varint and fixed
+// size values are decoded as unsigned integers, while length delimited
+// values and groups are base64 encoded. Repeated fields result in a list.
+func unknownFields(b []byte) (map[string]interface{}, error) {
+	res := make(map[string]interface{})
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, errors.Wrap(errUnknownField, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return nil, errors.Wrap(errUnknownField, protowire.ParseError(n))
+		}
+		var v interface{}
+		switch typ {
+		case protowire.VarintType:
+			v, _ = protowire.ConsumeVarint(b)
+		case protowire.Fixed32Type:
+			v, _ = protowire.ConsumeFixed32(b)
+		case protowire.Fixed64Type:
+			v, _ = protowire.ConsumeFixed64(b)
+		case protowire.BytesType:
+			val, _ := protowire.ConsumeBytes(b)
+			v = base64.StdEncoding.EncodeToString(val)
+		default:
+			v = base64.StdEncoding.EncodeToString(b[:n])
+		}
+		b = b[n:]
+
+		key := strconv.Itoa(int(num))
+		switch prev := res[key].(type) {
+		case nil:
+			res[key] = v
+		case []interface{}:
+			res[key] = append(prev, v)
+		default:
+			res[key] = []interface{}{prev, v}
+		}
+	}
+
+	return res, nil
+}
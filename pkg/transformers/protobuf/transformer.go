@@ -0,0 +1,108 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package protobuf contains the transformer of protobuf encoded messages.
+package protobuf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var (
+	// ErrTransform represents an error during decoding protobuf message.
+	ErrTransform = errors.New("unable to decode protobuf message")
+
+	errLoadDescriptors = errors.New("unable to load descriptor set")
+	errUnknownType     = errors.New("unknown message type")
+)
+
+// Config represents protobuf transformer settings.
+type Config struct {
+	// Descriptors is the path to the compiled descriptor set, created using
+	// protoc --include_imports --descriptor_set_out.
+	Descriptors string `toml:"-"`
+
+	// Types maps subtopics to the full names of the message types.
+	Types map[string]string `toml:"types"`
+}
+
+type transformer struct {
+	types map[string]protoreflect.MessageDescriptor
+	next  transformers.Transformer
+}
+
+// New returns a transformer which decodes protobuf payloads published to the
+// subtopics listed in the config to JSON objects, and passes them on to the
+// next transformer. Messages published to other subtopics are passed on
+// untouched. Unknown fields are kept in the "_unknown" object.
+func New(cfg Config, next transformers.Transformer) (transformers.Transformer, error) {
+	files, err := loadDescriptors(cfg.Descriptors)
+	if err != nil {
+		return nil, errors.Wrap(errLoadDescriptors, err)
+	}
+
+	t := transformer{
+		types: make(map[string]protoreflect.MessageDescriptor),
+		next:  next,
+	}
+	for subtopic, name := range cfg.Types {
+		d, err := files.FindDescriptorByName(protoreflect.FullName(name))
+		if err != nil {
+			return nil, errors.Wrap(errUnknownType, err)
+		}
+		md, ok := d.(protoreflect.MessageDescriptor)
+		if !ok {
+			return nil, errors.Wrap(errUnknownType, errors.New(name))
+		}
+		t.types[subtopic] = md
+	}
+
+	return t, nil
+}
+
+func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
+	md, ok := t.types[msg.Subtopic]
+	if !ok {
+		return t.next.Transform(msg)
+	}
+
+	m := dynamicpb.NewMessage(md)
+	if err := proto.Unmarshal(msg.Payload, m); err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+	obj, err := object(m)
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+	payload, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(ErrTransform, err)
+	}
+
+	msg.Payload = payload
+	return t.next.Transform(msg)
+}
+
+func loadDescriptors(path string) (*protoregistry.Files, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+	return protodesc.NewFiles(&set)
+}
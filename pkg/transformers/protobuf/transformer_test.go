@@ -0,0 +1,322 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package protobuf_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/json"
+	"github.com/mainflux/mainflux/pkg/transformers/protobuf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	descriptors = "testdata/telemetry.pb"
+	subtopic    = "gateway.telemetry"
+	created     = int64(1600000000000000000)
+)
+
+var types = map[string]string{
+	subtopic:         "gateway.v1.Telemetry",
+	"gateway.sensor": "gateway.v1.Reading",
+}
+
+// recorder is the transformer recording the transformed message.
+type recorder struct {
+	msg messaging.Message
+}
+
+func (r *recorder) Transform(msg messaging.Message) (interface{}, error) {
+	r.msg = msg
+	return msg, nil
+}
+
+func TestNew(t *testing.T) {
+	cases := []struct {
+		desc string
+		cfg  protobuf.Config
+		err  bool
+	}{
+		{
+			desc: "create transformer",
+			cfg:  protobuf.Config{Descriptors: descriptors, Types: types},
+		},
+		{
+			desc: "create transformer without types",
+			cfg:  protobuf.Config{Descriptors: descriptors},
+		},
+		{
+			desc: "create transformer with missing descriptor set",
+			cfg:  protobuf.Config{Descriptors: "testdata/missing.pb", Types: types},
+			err:  true,
+		},
+		{
+			desc: "create transformer with invalid descriptor set",
+			cfg:  protobuf.Config{Descriptors: "testdata/telemetry.proto", Types: types},
+			err:  true,
+		},
+		{
+			desc: "create transformer with unknown type",
+			cfg:  protobuf.Config{Descriptors: descriptors, Types: map[string]string{subtopic: "gateway.v1.Unknown"}},
+			err:  true,
+		},
+		{
+			desc: "create transformer with enum type",
+			cfg:  protobuf.Config{Descriptors: descriptors, Types: map[string]string{subtopic: "gateway.v1.Status"}},
+			err:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := protobuf.New(tc.cfg, json.New())
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestTransform(t *testing.T) {
+	tr, err := protobuf.New(protobuf.Config{Descriptors: descriptors, Types: types}, json.New())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	reading := concat(
+		str(1, "humidity"),
+		double(2, 40.5),
+	)
+	telemetry := concat(
+		str(1, "gw-1"),
+		varint(2, 1600000000000),
+		double(3, 21.5),
+		varint(4, 1),
+		varint(5, 2),
+		bytes(6, concat(str(1, "temperature"), double(2, 21.5))),
+		bytes(7, reading),
+		bytes(7, concat(str(1, "pressure"))),
+		bytes(8, []byte{0xde, 0xad}),
+		bytes(9, concat(str(1, "site"), str(2, "roof"))),
+	)
+
+	cases := []struct {
+		desc     string
+		subtopic string
+		payload  []byte
+		expected json.Payload
+	}{
+		{
+			desc:     "transform message with all field types",
+			subtopic: subtopic,
+			payload:  telemetry,
+			expected: json.Payload{
+				"device":         "gw-1",
+				"ts":             float64(1600000000000),
+				"temperature":    21.5,
+				"online":         true,
+				"status":         "STATUS_FAULT",
+				"reading/sensor": "temperature",
+				"reading/value":  21.5,
+				"readings":       []interface{}{map[string]interface{}{"sensor": "humidity", "value": 40.5}, map[string]interface{}{"sensor": "pressure", "value": float64(0)}},
+				"raw":            "3q0=",
+				"labels/site":    "roof",
+			},
+		},
+		{
+			desc:     "transform message with zero values",
+			subtopic: subtopic,
+			payload:  []byte{},
+			expected: json.Payload{
+				"device":      "",
+				"ts":          float64(0),
+				"temperature": float64(0),
+				"online":      false,
+				"status":      "STATUS_UNKNOWN",
+				"raw":         "",
+			},
+		},
+		{
+			desc:     "transform message with unknown fields",
+			subtopic: "gateway.sensor",
+			payload: concat(
+				reading,
+				varint(15, 42),
+				varint(15, 43),
+				bytes(16, []byte("hi")),
+				fixed32(17, 7),
+				fixed64(18, math.Float64bits(1)),
+			),
+			expected: json.Payload{
+				"sensor":      "humidity",
+				"value":       40.5,
+				"_unknown/15": []interface{}{float64(42), float64(43)},
+				"_unknown/16": "aGk=",
+				"_unknown/17": float64(7),
+				"_unknown/18": float64(math.Float64bits(1)),
+			},
+		},
+		{
+			desc:     "transform message with unknown enum value",
+			subtopic: subtopic,
+			payload:  varint(5, 7),
+			expected: json.Payload{
+				"device":      "",
+				"ts":          float64(0),
+				"temperature": float64(0),
+				"online":      false,
+				"status":      float64(7),
+				"raw":         "",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel:   "channel",
+			Subtopic:  tc.subtopic,
+			Publisher: "publisher",
+			Protocol:  "mqtt",
+			Payload:   tc.payload,
+			Created:   created,
+		}
+		res, err := tr.Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		expected := json.Messages{
+			Data: []json.Message{{
+				Channel:   msg.Channel,
+				Subtopic:  msg.Subtopic,
+				Publisher: msg.Publisher,
+				Protocol:  msg.Protocol,
+				Tenant:    messaging.DefaultTenant,
+				Created:   msg.Created,
+				Payload:   tc.expected,
+			}},
+			Format: "telemetry",
+		}
+		if tc.subtopic == "gateway.sensor" {
+			expected.Format = "sensor"
+		}
+		assert.Equal(t, expected, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, expected, res))
+	}
+}
+
+func TestTransformPassthrough(t *testing.T) {
+	rec := &recorder{}
+	tr, err := protobuf.New(protobuf.Config{Descriptors: descriptors, Types: types}, rec)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc     string
+		subtopic string
+	}{
+		{
+			desc:     "pass message with unmapped subtopic",
+			subtopic: "gateway.status",
+		},
+		{
+			desc:     "pass message with mapped subtopic prefix",
+			subtopic: subtopic + ".raw",
+		},
+		{
+			desc:     "pass message without subtopic",
+			subtopic: "",
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel:  "channel",
+			Subtopic: tc.subtopic,
+			Payload:  []byte(`{"v": 1}`),
+			Created:  created,
+		}
+		res, err := tr.Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, msg, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, msg, res))
+		assert.Equal(t, msg, rec.msg, fmt.Sprintf("%s: expected %v got %v", tc.desc, msg, rec.msg))
+	}
+}
+
+func TestTransformCorrupted(t *testing.T) {
+	tr, err := protobuf.New(protobuf.Config{Descriptors: descriptors, Types: types}, json.New())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc    string
+		payload []byte
+	}{
+		{
+			desc:    "transform truncated string",
+			payload: str(1, "gw-1")[:3],
+		},
+		{
+			desc:    "transform truncated varint",
+			payload: []byte{0x10, 0xff},
+		},
+		{
+			desc:    "transform invalid tag",
+			payload: []byte{0x00, 0x01},
+		},
+		{
+			desc:    "transform invalid UTF-8 string",
+			payload: bytes(1, []byte{0xff, 0xfe}),
+		},
+		{
+			desc:    "transform truncated nested message",
+			payload: bytes(6, str(1, "temperature")[:4]),
+		},
+		{
+			desc:    "transform unterminated group",
+			payload: protowire.AppendTag(nil, 20, protowire.StartGroupType),
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel:  "channel",
+			Subtopic: subtopic,
+			Payload:  tc.payload,
+		}
+		_, err := tr.Transform(msg)
+		assert.True(t, errors.Contains(err, protobuf.ErrTransform), fmt.Sprintf("%s: expected error %s got %s", tc.desc, protobuf.ErrTransform, err))
+	}
+}
+
+func concat(fields ...[]byte) []byte {
+	var b []byte
+	for _, f := range fields {
+		b = append(b, f...)
+	}
+	return b
+}
+
+func varint(num protowire.Number, v uint64) []byte {
+	b := protowire.AppendTag(nil, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func fixed32(num protowire.Number, v uint32) []byte {
+	b := protowire.AppendTag(nil, num, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, v)
+}
+
+func fixed64(num protowire.Number, v uint64) []byte {
+	b := protowire.AppendTag(nil, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, v)
+}
+
+func double(num protowire.Number, v float64) []byte {
+	return fixed64(num, math.Float64bits(v))
+}
+
+func bytes(num protowire.Number, v []byte) []byte {
+	b := protowire.AppendTag(nil, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func str(num protowire.Number, v string) []byte {
+	return bytes(num, []byte(v))
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mainflux/senml"
+)
+
+// cborRecord is the CBOR representation of SenML record. Data value is
+// encoded as byte string in CBOR, unlike in JSON where it is base64 encoded
+// string (RFC 8428 section 6), so it's decoded separately.
+type cborRecord struct {
+	BaseName    string      `cbor:"-2,keyasint,omitempty"`
+	BaseTime    float64     `cbor:"-3,keyasint,omitempty"`
+	BaseUnit    string      `cbor:"-4,keyasint,omitempty"`
+	BaseVersion uint        `cbor:"-1,keyasint,omitempty"`
+	BaseValue   float64     `cbor:"-5,keyasint,omitempty"`
+	BaseSum     float64     `cbor:"-6,keyasint,omitempty"`
+	Name        string      `cbor:"0,keyasint,omitempty"`
+	Unit        string      `cbor:"1,keyasint,omitempty"`
+	Time        float64     `cbor:"6,keyasint,omitempty"`
+	UpdateTime  float64     `cbor:"7,keyasint,omitempty"`
+	Value       *float64    `cbor:"2,keyasint,omitempty"`
+	StringValue *string     `cbor:"3,keyasint,omitempty"`
+	DataValue   interface{} `cbor:"8,keyasint,omitempty"`
+	BoolValue   *bool       `cbor:"4,keyasint,omitempty"`
+	Sum         *float64    `cbor:"5,keyasint,omitempty"`
+}
+
+// decodeCBOR decodes CBOR encoded SenML pack. Data values are base64 encoded,
+// so the resulting pack is the same as the one decoded from JSON.
+func decodeCBOR(payload []byte) (senml.Pack, error) {
+	var records []cborRecord
+	if err := cbor.Unmarshal(payload, &records); err != nil {
+		return senml.Pack{}, err
+	}
+
+	p := senml.Pack{Records: make([]senml.Record, len(records))}
+	for i, r := range records {
+		data, err := dataValue(r.DataValue)
+		if err != nil {
+			return senml.Pack{}, err
+		}
+		p.Records[i] = senml.Record{
+			BaseName:    r.BaseName,
+			BaseTime:    r.BaseTime,
+			BaseUnit:    r.BaseUnit,
+			BaseVersion: r.BaseVersion,
+			BaseValue:   r.BaseValue,
+			BaseSum:     r.BaseSum,
+			Name:        r.Name,
+			Unit:        r.Unit,
+			Time:        r.Time,
+			UpdateTime:  r.UpdateTime,
+			Value:       r.Value,
+			StringValue: r.StringValue,
+			DataValue:   data,
+			BoolValue:   r.BoolValue,
+			Sum:         r.Sum,
+		}
+	}
+
+	return p, nil
+}
+
+func dataValue(v interface{}) (*string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		s := base64.RawURLEncoding.EncodeToString(v)
+		return &s, nil
+	case string:
+		// Some encoders put already encoded data value as text string.
+		return &v, nil
+	default:
+		return nil, fmt.Errorf("invalid data value type %T", v)
+	}
+}
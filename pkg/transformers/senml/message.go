@@ -6,6 +6,8 @@ type Message struct {
 	Subtopic    string   `json:"subtopic,omitempty" db:"subtopic" bson:"subtopic,omitempty"`
 	Publisher   string   `json:"publisher,omitempty" db:"publisher" bson:"publisher"`
 	Protocol    string   `json:"protocol,omitempty" db:"protocol" bson:"protocol"`
+	Tenant      string   `json:"tenant,omitempty" db:"tenant" bson:"tenant,omitempty"`
+	MessageID   string   `json:"message_id,omitempty" db:"message_id" bson:"message_id,omitempty"`
 	Name        string   `json:"name,omitempty" db:"name" bson:"name,omitempty"`
 	Unit        string   `json:"unit,omitempty" db:"unit" bson:"unit,omitempty"`
 	Time        float64  `json:"time,omitempty" db:"time" bson:"time,omitempty"`
@@ -0,0 +1,237 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/senml"
+)
+
+var errEncode = errors.New("failed to encode senml")
+
+// packRecord is the SenML record extended with the message metadata labels.
+// The labels are set on the first record of each group of the messages
+// sharing the metadata, and they apply to the records up to the next record
+// with the publisher label. They aren't registered SenML labels, so the
+// SenML tooling ignores them.
+type packRecord struct {
+	senml.Record
+	Publisher *string `json:"publisher,omitempty"`
+	Subtopic  string  `json:"subtopic,omitempty"`
+	Protocol  string  `json:"protocol,omitempty"`
+	Tenant    string  `json:"tenant,omitempty"`
+}
+
+// cborPackRecord is the CBOR representation of the pack record. The
+// metadata labels use the text keys, as the integer ones are reserved for
+// the registered labels (RFC 8428 section 6).
+type cborPackRecord struct {
+	BaseName    string      `cbor:"-2,keyasint,omitempty"`
+	BaseTime    float64     `cbor:"-3,keyasint,omitempty"`
+	Name        string      `cbor:"0,keyasint,omitempty"`
+	Unit        string      `cbor:"1,keyasint,omitempty"`
+	Time        float64     `cbor:"6,keyasint,omitempty"`
+	UpdateTime  float64     `cbor:"7,keyasint,omitempty"`
+	Value       *float64    `cbor:"2,keyasint,omitempty"`
+	StringValue *string     `cbor:"3,keyasint,omitempty"`
+	DataValue   interface{} `cbor:"8,keyasint,omitempty"`
+	BoolValue   *bool       `cbor:"4,keyasint,omitempty"`
+	Sum         *float64    `cbor:"5,keyasint,omitempty"`
+	Publisher   *string     `cbor:"publisher,omitempty"`
+	Subtopic    string      `cbor:"subtopic,omitempty"`
+	Protocol    string      `cbor:"protocol,omitempty"`
+	Tenant      string      `cbor:"tenant,omitempty"`
+}
+
+type groupKey struct {
+	publisher string
+	subtopic  string
+	protocol  string
+	tenant    string
+}
+
+type group struct {
+	key    groupKey
+	msgs   []Message
+	prefix string
+}
+
+// Encode encodes the messages as the SenML pack in JSON or CBOR, depending
+// on the content type. The pack resolves to the same records, and the
+// message metadata is kept in the extension labels. The records of the
+// same publisher are grouped, and the groups use the base name and the base
+// time if it makes the pack smaller.
+func Encode(msgs []Message, contentType string) ([]byte, error) {
+	records := pack(msgs)
+	var data []byte
+	var err error
+	switch formats[contentType] {
+	case senml.CBOR:
+		data, err = cbor.Marshal(toCBOR(records))
+	default:
+		data, err = json.Marshal(records)
+	}
+	if err != nil {
+		return nil, errors.Wrap(errEncode, err)
+	}
+	return data, nil
+}
+
+func pack(msgs []Message) []packRecord {
+	groups := groupMessages(msgs)
+	baseTime := useBaseTime(groups)
+
+	records := []packRecord{}
+	baseName := false
+	for _, g := range groups {
+		// Base name applies until it's replaced, so once it's set, all the
+		// following groups set it. The groups without the common prefix are
+		// put first for that reason.
+		bn := ""
+		if g.prefix != "" && (len(g.msgs) > 1 || baseName) {
+			bn = g.prefix
+			baseName = true
+		}
+		bt := 0.0
+		if baseTime {
+			bt = g.msgs[0].Time
+		}
+
+		for i, msg := range g.msgs {
+			r := packRecord{
+				Record: senml.Record{
+					Name:        msg.Name[len(bn):],
+					Unit:        msg.Unit,
+					Time:        msg.Time - bt,
+					UpdateTime:  msg.UpdateTime,
+					Value:       msg.Value,
+					StringValue: msg.StringValue,
+					DataValue:   msg.DataValue,
+					BoolValue:   msg.BoolValue,
+					Sum:         msg.Sum,
+				},
+			}
+			if i == 0 {
+				publisher := g.key.publisher
+				r.Publisher = &publisher
+				r.Subtopic = g.key.subtopic
+				r.Protocol = g.key.protocol
+				r.Tenant = g.key.tenant
+				r.BaseName = bn
+				r.BaseTime = bt
+			}
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// groupMessages groups the messages by the metadata, keeping the order of
+// the messages within the group, and puts the groups without the common
+// name prefix first.
+func groupMessages(msgs []Message) []group {
+	var groups []*group
+	index := map[groupKey]*group{}
+	for _, msg := range msgs {
+		key := groupKey{
+			publisher: msg.Publisher,
+			subtopic:  msg.Subtopic,
+			protocol:  msg.Protocol,
+			tenant:    msg.Tenant,
+		}
+		g, ok := index[key]
+		if !ok {
+			g = &group{key: key, prefix: msg.Name}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.msgs = append(g.msgs, msg)
+		g.prefix = commonPrefix(g.prefix, msg.Name)
+	}
+
+	ret := make([]group, 0, len(groups))
+	for _, g := range groups {
+		if g.prefix == "" {
+			ret = append(ret, *g)
+		}
+	}
+	for _, g := range groups {
+		if g.prefix != "" {
+			ret = append(ret, *g)
+		}
+	}
+	return ret
+}
+
+// useBaseTime reports whether the groups can use the base time of their
+// first record. Base time applies until it's replaced, so either all the
+// groups use it, or none. It's used only if the times are restored exactly,
+// and if some group has more than one record, since otherwise it doesn't
+// make the pack smaller.
+func useBaseTime(groups []group) bool {
+	multiple := false
+	for _, g := range groups {
+		bt := g.msgs[0].Time
+		if bt == 0 {
+			return false
+		}
+		for _, msg := range g.msgs {
+			if bt+(msg.Time-bt) != msg.Time {
+				return false
+			}
+		}
+		multiple = multiple || len(g.msgs) > 1
+	}
+	return multiple
+}
+
+func commonPrefix(a, b string) string {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return a[:i]
+		}
+	}
+	return a[:n]
+}
+
+// toCBOR converts the records to the CBOR ones. Data values are encoded as
+// byte strings, unless they aren't base64url encoded, in which case they're
+// kept as text strings, the same way they're decoded.
+func toCBOR(records []packRecord) []cborPackRecord {
+	ret := make([]cborPackRecord, len(records))
+	for i, r := range records {
+		ret[i] = cborPackRecord{
+			BaseName:    r.BaseName,
+			BaseTime:    r.BaseTime,
+			Name:        r.Name,
+			Unit:        r.Unit,
+			Time:        r.Time,
+			UpdateTime:  r.UpdateTime,
+			Value:       r.Value,
+			StringValue: r.StringValue,
+			BoolValue:   r.BoolValue,
+			Sum:         r.Sum,
+			Publisher:   r.Publisher,
+			Subtopic:    r.Subtopic,
+			Protocol:    r.Protocol,
+			Tenant:      r.Tenant,
+		}
+		if r.DataValue != nil {
+			ret[i].DataValue = *r.DataValue
+			data, err := base64.RawURLEncoding.DecodeString(*r.DataValue)
+			if err == nil && base64.RawURLEncoding.EncodeToString(data) == *r.DataValue {
+				ret[i].DataValue = data
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package senml_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// labels holds the metadata labels of the encoded record.
+type labels struct {
+	Publisher *string `json:"publisher" cbor:"publisher"`
+	Subtopic  string  `json:"subtopic" cbor:"subtopic"`
+	Protocol  string  `json:"protocol" cbor:"protocol"`
+	Tenant    string  `json:"tenant" cbor:"tenant"`
+}
+
+// publish returns the messages the pack is stored as.
+func publish(t *testing.T, pack string, publisher, subtopic string) []senml.Message {
+	msg := messaging.Message{
+		Channel:   "channel",
+		Subtopic:  subtopic,
+		Publisher: publisher,
+		Protocol:  "http",
+		Payload:   []byte(pack),
+	}
+	msgs, err := senml.New(senml.JSON).Transform(msg)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return msgs.([]senml.Message)
+}
+
+// decode resolves the encoded pack and restores the metadata of the
+// resolved records from the labels.
+func decode(t *testing.T, data []byte, contentType string) []senml.Message {
+	resolved, err := senml.New(contentType).Transform(messaging.Message{Channel: "channel", Payload: data})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	msgs := resolved.([]senml.Message)
+
+	var records []labels
+	if contentType == senml.CBOR {
+		err = cbor.Unmarshal(data, &records)
+	} else {
+		err = json.Unmarshal(data, &records)
+	}
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	require.Len(t, records, len(msgs), fmt.Sprintf("expected %d records got %d", len(msgs), len(records)))
+
+	var cur labels
+	for i := range msgs {
+		if records[i].Publisher != nil {
+			cur = records[i]
+		}
+		require.NotNil(t, cur.Publisher, "expected publisher label on the first record")
+		msgs[i].Publisher = *cur.Publisher
+		msgs[i].Subtopic = cur.Subtopic
+		msgs[i].Protocol = cur.Protocol
+		msgs[i].Tenant = cur.Tenant
+	}
+	return msgs
+}
+
+func TestEncode(t *testing.T) {
+	rfc := publish(t, rfcPackJSON, "publisher", "")
+	variants := publish(t, variantsPackJSON, "publisher2", "sensors")
+	var interleaved []senml.Message
+	for i := range rfc {
+		interleaved = append(interleaved, rfc[i])
+		if i < len(variants) {
+			interleaved = append(interleaved, variants[i])
+		}
+	}
+
+	v := 1.0
+	unprefixed := []senml.Message{
+		{Channel: "channel", Publisher: "publisher3", Name: "temperature", Time: 1600000000, Value: &v},
+		{Channel: "channel", Publisher: "publisher3", Name: "humidity", Time: 1600000001, Value: &v},
+	}
+	untimed := []senml.Message{
+		{Channel: "channel", Publisher: "publisher", Name: "temperature", Time: 1600000000, Value: &v},
+		{Channel: "channel", Publisher: "publisher", Name: "temperature", Value: &v},
+	}
+
+	cases := []struct {
+		desc string
+		msgs []senml.Message
+	}{
+		{
+			desc: "encode pack of single publisher",
+			msgs: rfc,
+		},
+		{
+			desc: "encode pack with all value variants",
+			msgs: variants,
+		},
+		{
+			desc: "encode packs of interleaved publishers",
+			msgs: interleaved,
+		},
+		{
+			desc: "encode packs with and without common name prefix",
+			msgs: append(append([]senml.Message{}, rfc...), unprefixed...),
+		},
+		{
+			desc: "encode pack without base time",
+			msgs: append(append([]senml.Message{}, untimed...), unprefixed...),
+		},
+		{
+			desc: "encode empty pack",
+			msgs: []senml.Message{},
+		},
+	}
+
+	for _, contentType := range []string{senml.JSON, senml.CBOR} {
+		for _, tc := range cases {
+			data, err := senml.Encode(tc.msgs, contentType)
+			require.Nil(t, err, fmt.Sprintf("%s as %s: unexpected error: %s", tc.desc, contentType, err))
+			msgs := decode(t, data, contentType)
+			assert.ElementsMatch(t, tc.msgs, msgs, fmt.Sprintf("%s as %s: expected %v got %v", tc.desc, contentType, tc.msgs, msgs))
+		}
+	}
+}
+
+func TestEncodeBaseFields(t *testing.T) {
+	msgs := publish(t, rfcPackJSON, "publisher", "")
+	data, err := senml.Encode(msgs, senml.JSON)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	var records []map[string]interface{}
+	err = json.Unmarshal(data, &records)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	require.Len(t, records, len(msgs), fmt.Sprintf("expected %d records got %d", len(msgs), len(records)))
+
+	bn := "urn:dev:ow:10e2073a01080063:"
+	assert.Equal(t, bn, records[0]["bn"], fmt.Sprintf("expected base name %s got %v", bn, records[0]["bn"]))
+	assert.Equal(t, msgs[0].Time, records[0]["bt"], fmt.Sprintf("expected base time %v got %v", msgs[0].Time, records[0]["bt"]))
+	for i, r := range records[1:] {
+		_, ok := r["publisher"]
+		assert.False(t, ok, fmt.Sprintf("expected no publisher label on record %d", i+1))
+		_, ok = r["bn"]
+		assert.False(t, ok, fmt.Sprintf("expected no base name on record %d", i+1))
+	}
+}
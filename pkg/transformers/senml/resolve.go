@@ -0,0 +1,141 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package senml
+
+import (
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/senml"
+)
+
+const (
+	// version is the SenML version supported by the transformer.
+	version = 10
+	// relativeTime is the threshold below which the time is considered
+	// relative to the current time (RFC 8428 section 4.5.3).
+	relativeTime = 1 << 28
+)
+
+// ErrUnsupportedVersion indicates SenML pack with the version newer than
+// the supported one.
+var ErrUnsupportedVersion = errors.New("unsupported senml version")
+
+// resolve converts records of the pack to resolved records as specified in
+// RFC 8428 section 4.6. Base fields apply to the record they are in and to
+// all the following records, until they are replaced. Relative times are
+// resolved against now, expressed in seconds. Records keep the pack order.
+func resolve(p senml.Pack, now float64) ([]senml.Record, error) {
+	var bn, bu string
+	var bt, bv, bs float64
+	var bver uint
+
+	res := make([]senml.Record, len(p.Records))
+	for i, r := range p.Records {
+		if r.BaseName != "" {
+			bn = r.BaseName
+		}
+		if r.BaseUnit != "" {
+			bu = r.BaseUnit
+		}
+		if r.BaseTime != 0 {
+			bt = r.BaseTime
+		}
+		if r.BaseValue != 0 {
+			bv = r.BaseValue
+		}
+		if r.BaseSum != 0 {
+			bs = r.BaseSum
+		}
+		if r.BaseVersion != 0 {
+			if r.BaseVersion > version {
+				return nil, ErrUnsupportedVersion
+			}
+			if bver != 0 && bver != r.BaseVersion {
+				return nil, senml.ErrVersionChange
+			}
+			bver = r.BaseVersion
+		}
+
+		if err := validateValues(r); err != nil {
+			return nil, err
+		}
+
+		name := bn + r.Name
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+
+		rec := senml.Record{
+			Name:        name,
+			Unit:        r.Unit,
+			Time:        bt + r.Time,
+			UpdateTime:  r.UpdateTime,
+			StringValue: r.StringValue,
+			DataValue:   r.DataValue,
+			BoolValue:   r.BoolValue,
+		}
+		if rec.Unit == "" {
+			rec.Unit = bu
+		}
+		if rec.Time < relativeTime {
+			rec.Time += now
+		}
+		// Base value and base sum apply only to the records carrying value
+		// and sum respectively.
+		if r.Value != nil {
+			v := bv + *r.Value
+			rec.Value = &v
+		}
+		if r.Sum != nil {
+			s := bs + *r.Sum
+			rec.Sum = &s
+		}
+		res[i] = rec
+	}
+
+	return res, nil
+}
+
+func validateValues(r senml.Record) error {
+	values := 0
+	if r.Value != nil {
+		values++
+	}
+	if r.StringValue != nil {
+		values++
+	}
+	if r.BoolValue != nil {
+		values++
+	}
+	if r.DataValue != nil {
+		values++
+	}
+
+	switch {
+	case values > 1:
+		return senml.ErrTooManyValues
+	case values == 0 && r.Sum == nil:
+		return senml.ErrNoValues
+	default:
+		return nil
+	}
+}
+
+// validateName checks the name against RFC 8428 section 4.5.1: it must
+// start with a letter or a digit and contain only letters, digits and the
+// characters "-", ":", ".", "/" and "_".
+func validateName(name string) error {
+	if name == "" {
+		return senml.ErrEmptyName
+	}
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case i > 0 && (c == '-' || c == ':' || c == '.' || c == '/' || c == '_'):
+		default:
+			return senml.ErrBadChar
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package senml_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	mfsenml "github.com/mainflux/senml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// arrival is the reception time of the message in seconds.
+	arrival = 1.6e9
+	rfcBN   = "urn:dev:ow:10e2073a01080063:"
+	rfcBT   = 1.276020076001e+09
+	rfcBT3  = 1.320067464e+09
+)
+
+var resolveCases = []struct {
+	desc     string
+	pack     string
+	expected []senml.Message
+	err      error
+}{
+	{
+		desc: "resolve RFC 8428 single datapoint",
+		pack: `[{"n":"urn:dev:ow:10e2073a01080063","u":"Cel","v":23.1}]`,
+		expected: []senml.Message{
+			{Name: "urn:dev:ow:10e2073a01080063", Unit: "Cel", Time: arrival, Value: num(23.1)},
+		},
+	},
+	{
+		desc: "resolve RFC 8428 multiple datapoints",
+		pack: `[{"bn":"urn:dev:ow:10e2073a01080063:","bt":1.276020076001e+09,"bu":"A","bver":5,"n":"voltage","u":"V","v":120.1},
+			{"n":"current","t":-5,"v":1.2},{"n":"current","t":-4,"v":1.3},{"n":"current","t":-3,"v":1.4},
+			{"n":"current","t":-2,"v":1.5},{"n":"current","t":-1,"v":1.6},{"n":"current","v":1.7}]`,
+		expected: []senml.Message{
+			{Name: rfcBN + "voltage", Unit: "V", Time: rfcBT, Value: num(120.1)},
+			{Name: rfcBN + "current", Unit: "A", Time: rfcBT - 5, Value: num(1.2)},
+			{Name: rfcBN + "current", Unit: "A", Time: rfcBT - 4, Value: num(1.3)},
+			{Name: rfcBN + "current", Unit: "A", Time: rfcBT - 3, Value: num(1.4)},
+			{Name: rfcBN + "current", Unit: "A", Time: rfcBT - 2, Value: num(1.5)},
+			{Name: rfcBN + "current", Unit: "A", Time: rfcBT - 1, Value: num(1.6)},
+			{Name: rfcBN + "current", Unit: "A", Time: rfcBT, Value: num(1.7)},
+		},
+	},
+	{
+		desc: "resolve RFC 8428 multiple measurements",
+		pack: `[{"bn":"urn:dev:ow:10e2073a01080063:","bt":1.320067464e+09,"bu":"%RH","v":20},
+			{"u":"lon","v":24.30621},{"u":"lat","v":60.07965},
+			{"t":60,"v":20.3},{"u":"lon","t":60,"v":24.30622},{"u":"lat","t":60,"v":60.07965}]`,
+		expected: []senml.Message{
+			{Name: rfcBN, Unit: "%RH", Time: rfcBT3, Value: num(20)},
+			{Name: rfcBN, Unit: "lon", Time: rfcBT3, Value: num(24.30621)},
+			{Name: rfcBN, Unit: "lat", Time: rfcBT3, Value: num(60.07965)},
+			{Name: rfcBN, Unit: "%RH", Time: rfcBT3 + 60, Value: num(20.3)},
+			{Name: rfcBN, Unit: "lon", Time: rfcBT3 + 60, Value: num(24.30622)},
+			{Name: rfcBN, Unit: "lat", Time: rfcBT3 + 60, Value: num(60.07965)},
+		},
+	},
+	{
+		desc: "resolve RFC 8428 resolved data",
+		pack: `[{"n":"urn:dev:ow:10e2073a01080063:voltage","t":1.276020076001e+09,"u":"V","v":120.1},
+			{"n":"urn:dev:ow:10e2073a01080063:current","t":1.276020071001e+09,"u":"A","v":1.2}]`,
+		expected: []senml.Message{
+			{Name: rfcBN + "voltage", Unit: "V", Time: rfcBT, Value: num(120.1)},
+			{Name: rfcBN + "current", Unit: "A", Time: rfcBT - 5, Value: num(1.2)},
+		},
+	},
+	{
+		desc: "resolve RFC 8428 collection of resources",
+		pack: `[{"bn":"urn:dev:ow:10e2073a01080063:","bt":1.320067464e+09,"n":"temperature","u":"Cel","v":23.1},
+			{"n":"label","vs":"Machine Room"},{"n":"open","vb":false},{"n":"nfc-reader","vd":"aGkgCg"}]`,
+		expected: []senml.Message{
+			{Name: rfcBN + "temperature", Unit: "Cel", Time: rfcBT3, Value: num(23.1)},
+			{Name: rfcBN + "label", Time: rfcBT3, StringValue: str("Machine Room")},
+			{Name: rfcBN + "open", Time: rfcBT3, BoolValue: boolean(false)},
+			{Name: rfcBN + "nfc-reader", Time: rfcBT3, DataValue: str("aGkgCg")},
+		},
+	},
+	{
+		desc: "resolve base value only for records with value",
+		pack: `[{"bn":"dev:","bv":10,"n":"a","v":1},{"n":"b","v":-2},{"n":"c","vs":"on"},{"n":"d","s":3}]`,
+		expected: []senml.Message{
+			{Name: "dev:a", Time: arrival, Value: num(11)},
+			{Name: "dev:b", Time: arrival, Value: num(8)},
+			{Name: "dev:c", Time: arrival, StringValue: str("on")},
+			{Name: "dev:d", Time: arrival, Sum: num(3)},
+		},
+	},
+	{
+		desc: "resolve replaced base value",
+		pack: `[{"bn":"dev:","bv":10,"n":"a","v":1},{"bv":20,"n":"b","v":1},{"n":"c","v":1}]`,
+		expected: []senml.Message{
+			{Name: "dev:a", Time: arrival, Value: num(11)},
+			{Name: "dev:b", Time: arrival, Value: num(21)},
+			{Name: "dev:c", Time: arrival, Value: num(21)},
+		},
+	},
+	{
+		desc: "resolve base sum only for records with sum",
+		pack: `[{"bn":"dev:","bs":100,"n":"a","s":1},{"n":"b","s":2},{"n":"c","v":1}]`,
+		expected: []senml.Message{
+			{Name: "dev:a", Time: arrival, Sum: num(101)},
+			{Name: "dev:b", Time: arrival, Sum: num(102)},
+			{Name: "dev:c", Time: arrival, Value: num(1)},
+		},
+	},
+	{
+		desc: "resolve base unit only for records without unit",
+		pack: `[{"bn":"dev:","bu":"Cel","n":"a","v":1},{"n":"b","u":"K","v":2},{"n":"c","v":3},{"bu":"%RH","n":"d","v":4}]`,
+		expected: []senml.Message{
+			{Name: "dev:a", Unit: "Cel", Time: arrival, Value: num(1)},
+			{Name: "dev:b", Unit: "K", Time: arrival, Value: num(2)},
+			{Name: "dev:c", Unit: "Cel", Time: arrival, Value: num(3)},
+			{Name: "dev:d", Unit: "%RH", Time: arrival, Value: num(4)},
+		},
+	},
+	{
+		desc: "resolve relative times without base time",
+		pack: `[{"n":"a","t":-5,"v":1},{"n":"b","v":2},{"n":"c","t":10,"v":3}]`,
+		expected: []senml.Message{
+			{Name: "a", Time: arrival - 5, Value: num(1)},
+			{Name: "b", Time: arrival, Value: num(2)},
+			{Name: "c", Time: arrival + 10, Value: num(3)},
+		},
+	},
+	{
+		desc: "resolve relative base time",
+		pack: `[{"bt":-10,"n":"a","t":2,"v":1},{"n":"b","t":-2.5,"v":2}]`,
+		expected: []senml.Message{
+			{Name: "a", Time: arrival - 8, Value: num(1)},
+			{Name: "b", Time: arrival - 12.5, Value: num(2)},
+		},
+	},
+	{
+		desc: "resolve sub-second times",
+		pack: `[{"bt":1622541600.125,"n":"a","t":0.0005,"v":1},{"n":"b","t":-0.25,"v":2}]`,
+		expected: []senml.Message{
+			{Name: "a", Time: 1622541600.1255, Value: num(1)},
+			{Name: "b", Time: 1622541599.875, Value: num(2)},
+		},
+	},
+	{
+		desc: "resolve absolute time with base time",
+		pack: `[{"bt":100,"n":"a","t":1.6e9,"v":1}]`,
+		expected: []senml.Message{
+			{Name: "a", Time: 1.6e9 + 100, Value: num(1)},
+		},
+	},
+	{
+		desc: "resolve update time and replaced base name",
+		pack: `[{"bn":"a:","n":"x","ut":30,"v":1},{"bn":"b:","n":"x","v":2}]`,
+		expected: []senml.Message{
+			{Name: "a:x", Time: arrival, UpdateTime: 30, Value: num(1)},
+			{Name: "b:x", Time: arrival, Value: num(2)},
+		},
+	},
+	{
+		desc: "resolve pack with repeated version",
+		pack: `[{"bver":10,"n":"a","v":1},{"bver":10,"n":"b","v":2},{"n":"c","v":3}]`,
+		expected: []senml.Message{
+			{Name: "a", Time: arrival, Value: num(1)},
+			{Name: "b", Time: arrival, Value: num(2)},
+			{Name: "c", Time: arrival, Value: num(3)},
+		},
+	},
+	{
+		desc:     "resolve empty pack",
+		pack:     `[]`,
+		expected: []senml.Message{},
+	},
+	{
+		desc: "resolve pack with version change",
+		pack: `[{"bver":5,"n":"a","v":1},{"bver":10,"n":"b","v":2}]`,
+		err:  mfsenml.ErrVersionChange,
+	},
+	{
+		desc: "resolve pack with unsupported version",
+		pack: `[{"bver":11,"n":"a","v":1}]`,
+		err:  senml.ErrUnsupportedVersion,
+	},
+	{
+		desc: "resolve record with value and string value",
+		pack: `[{"n":"a","v":1,"vs":"on"}]`,
+		err:  mfsenml.ErrTooManyValues,
+	},
+	{
+		desc: "resolve record with bool value and data value",
+		pack: `[{"n":"a","vb":true,"vd":"aGkgCg"}]`,
+		err:  mfsenml.ErrTooManyValues,
+	},
+	{
+		desc: "resolve record with base value and without value",
+		pack: `[{"bn":"dev:","bv":10,"n":"a"}]`,
+		err:  mfsenml.ErrNoValues,
+	},
+	{
+		desc: "resolve record without name",
+		pack: `[{"v":1}]`,
+		err:  mfsenml.ErrEmptyName,
+	},
+	{
+		desc: "resolve record with name starting with separator",
+		pack: `[{"n":"_a","v":1}]`,
+		err:  mfsenml.ErrBadChar,
+	},
+	{
+		desc: "resolve record with invalid name character",
+		pack: `[{"bn":"dev ","n":"a","v":1}]`,
+		err:  mfsenml.ErrBadChar,
+	},
+}
+
+func TestResolve(t *testing.T) {
+	tr := senml.New(senml.JSON)
+
+	for _, tc := range resolveCases {
+		msg := messaging.Message{
+			Channel: "channel",
+			Payload: []byte(tc.pack),
+			Created: int64(arrival * 1e9),
+		}
+		res, err := tr.Transform(msg)
+		if tc.err != nil {
+			assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+			continue
+		}
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		msgs := res.([]senml.Message)
+		require.Len(t, msgs, len(tc.expected), fmt.Sprintf("%s: expected %d messages got %d", tc.desc, len(tc.expected), len(msgs)))
+		for i, m := range msgs {
+			e := tc.expected[i]
+			e.Channel = msg.Channel
+			e.Tenant = messaging.DefaultTenant
+			assert.InDelta(t, e.Time, m.Time, 1e-6, fmt.Sprintf("%s: expected time %f got %f", tc.desc, e.Time, m.Time))
+			e.Time = m.Time
+			assert.Equal(t, e, m, fmt.Sprintf("%s: expected %v got %v", tc.desc, e, m))
+		}
+	}
+}
+
+func TestResolveMalformed(t *testing.T) {
+	tr := senml.New(senml.JSON)
+	r := rand.New(rand.NewSource(1))
+	alphabet := []byte(`[]{}":,.-+e0123456789nvbtsu `)
+
+	for _, tc := range resolveCases {
+		pld := []byte(tc.pack)
+		for i := 0; i < len(pld); i++ {
+			b := append([]byte{}, pld[:i]...)
+			transformMalformed(t, tr, b)
+		}
+		for i := 0; i < 100; i++ {
+			b := append([]byte{}, pld...)
+			b[r.Intn(len(b))] = alphabet[r.Intn(len(alphabet))]
+			transformMalformed(t, tr, b)
+		}
+	}
+}
+
+// transformMalformed checks that the payload is either rejected or resolved
+// to the valid records.
+func transformMalformed(t *testing.T, tr transformers.Transformer, pld []byte) {
+	var res interface{}
+	var err error
+	assert.NotPanics(t, func() {
+		res, err = tr.Transform(messaging.Message{Payload: pld})
+	}, fmt.Sprintf("transform of %s expected not to panic", pld))
+	if err != nil {
+		return
+	}
+	for _, m := range res.([]senml.Message) {
+		assert.NotEmpty(t, m.Name, fmt.Sprintf("transform of %s expected to resolve name", pld))
+	}
+}
+
+func num(v float64) *float64 {
+	return &v
+}
+
+func str(v string) *string {
+	return &v
+}
+
+func boolean(v bool) *bool {
+	return &v
+}
@@ -4,6 +4,9 @@
 package senml
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/transformers"
@@ -15,6 +18,8 @@ const (
 	JSON = "application/senml+json"
 	// CBOR represents SenML in CBOR format content type.
 	CBOR = "application/senml+cbor"
+	// PlainCBOR represents CBOR content type, with SenML pack as payload.
+	PlainCBOR = "application/cbor"
 )
 
 var (
@@ -23,8 +28,9 @@ var (
 )
 
 var formats = map[string]senml.Format{
-	JSON: senml.JSON,
-	CBOR: senml.CBOR,
+	JSON:      senml.JSON,
+	CBOR:      senml.CBOR,
+	PlainCBOR: senml.CBOR,
 }
 
 type transformer struct {
@@ -43,34 +49,38 @@ func New(contentFormat string) transformers.Transformer {
 	}
 }
 
-func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
-	raw, err := senml.Decode(msg.Payload, t.format)
+func (t transformer) Transform(msg messaging.Message) (msgs interface{}, err error) {
+	// Malformed payload must result in an error, not crash the consumer.
+	defer func() {
+		if r := recover(); r != nil {
+			msgs, err = nil, errors.Wrap(errDecode, fmt.Errorf("%v", r))
+		}
+	}()
+
+	raw, err := t.decode(msg.Payload)
 	if err != nil {
 		return nil, errors.Wrap(errDecode, err)
 	}
 
-	normalized, err := senml.Normalize(raw)
+	// Convert the Unix timestamp in nanoseconds to float64, so that messages
+	// with missing or relative time get resolved against the reception time.
+	records, err := resolve(raw, float64(msg.Created)/float64(1e9))
 	if err != nil {
 		return nil, errors.Wrap(errNormalize, err)
 	}
 
-	msgs := make([]Message, len(normalized.Records))
-	for i, v := range normalized.Records {
-		// Use reception timestamp if SenML messsage Time is missing
-		t := v.Time
-		if t == 0 {
-			// Convert the Unix timestamp in nanoseconds to float64
-			t = float64(msg.Created) / float64(1e9)
-		}
-
-		msgs[i] = Message{
+	res := make([]Message, len(records))
+	for i, v := range records {
+		res[i] = Message{
 			Channel:     msg.Channel,
 			Subtopic:    msg.Subtopic,
 			Publisher:   msg.Publisher,
 			Protocol:    msg.Protocol,
+			Tenant:      messaging.Tenant(msg),
+			MessageID:   messaging.ID(msg),
 			Name:        v.Name,
 			Unit:        v.Unit,
-			Time:        t,
+			Time:        v.Time,
 			UpdateTime:  v.UpdateTime,
 			Value:       v.Value,
 			BoolValue:   v.BoolValue,
@@ -80,5 +90,18 @@ func (t transformer) Transform(msg messaging.Message) (interface{}, error) {
 		}
 	}
 
-	return msgs, nil
+	return res, nil
+}
+
+func (t transformer) decode(payload []byte) (senml.Pack, error) {
+	if t.format == senml.CBOR {
+		return decodeCBOR(payload)
+	}
+
+	// Records are validated during resolution, after the base fields are applied.
+	var p senml.Pack
+	if err := json.Unmarshal(payload, &p.Records); err != nil {
+		return senml.Pack{}, err
+	}
+	return p, nil
 }
@@ -6,6 +6,7 @@ package senml_test
 import (
 	"encoding/hex"
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/mainflux/mainflux/pkg/errors"
@@ -37,6 +38,9 @@ func TestTransformJSON(t *testing.T) {
 	jsonPld := msg
 	jsonPld.Payload = jsonBytes
 
+	tenantPld := msg
+	tenantPld.Headers = map[string]string{messaging.TenantHeader: "tenant"}
+
 	val := 52.0
 	sum := 110.0
 	msgs := []senml.Message{
@@ -45,6 +49,7 @@ func TestTransformJSON(t *testing.T) {
 			Subtopic:   "subtopic",
 			Publisher:  "publisher",
 			Protocol:   "protocol",
+			Tenant:     messaging.DefaultTenant,
 			Name:       "base-namename",
 			Unit:       "unit",
 			Time:       400,
@@ -54,6 +59,9 @@ func TestTransformJSON(t *testing.T) {
 		},
 	}
 
+	tenantMsgs := []senml.Message{msgs[0]}
+	tenantMsgs[0].Tenant = "tenant"
+
 	cases := []struct {
 		desc string
 		msg  messaging.Message
@@ -66,6 +74,12 @@ func TestTransformJSON(t *testing.T) {
 			msgs: msgs,
 			err:  nil,
 		},
+		{
+			desc: "test normalize JSON with tenant",
+			msg:  tenantPld,
+			msgs: tenantMsgs,
+			err:  nil,
+		},
 		{
 			desc: "test normalize defaults to JSON",
 			msg:  msg,
@@ -116,6 +130,7 @@ func TestTransformCBOR(t *testing.T) {
 			Subtopic:   "subtopic",
 			Publisher:  "publisher",
 			Protocol:   "protocol",
+			Tenant:     messaging.DefaultTenant,
 			Name:       "base-namename",
 			Unit:       "unit",
 			Time:       400,
@@ -151,3 +166,146 @@ func TestTransformCBOR(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s expected %s, got %s", tc.desc, tc.err, err))
 	}
 }
+
+// Following hex-encoded bytes correspond to the CBOR encoding of the
+// multiple datapoints example from RFC 8428 section 5.1.2:
+// [{-2: "urn:dev:ow:10e2073a01080063:", -3: 1.276020076001e+09, -4: "A", -1: 5, 0: "voltage", 1: "V", 2: 120.1},
+// {0: "current", 6: -5, 2: 1.2}, {0: "current", 6: -4, 2: 1.3}, {0: "current", 6: -3, 2: 1.4},
+// {0: "current", 6: -2, 2: 1.5}, {0: "current", 6: -1, 2: 1.6}, {0: "current", 2: 1.7}]
+const rfcPack = "87a720050067766f6c7461676501615602fb405e06666666666621781c75726e3a6465763a6f773a313065323037336130313038303036333a22fb41d303a15b001062236141a302fb3ff3333333333333006763757272656e740624a3006763757272656e74062302fb3ff4cccccccccccda3006763757272656e74062202fb3ff6666666666666a3006763757272656e74062102fb3ff8000000000000a3006763757272656e74062002fb3ff999999999999aa2006763757272656e7402fb3ffb333333333333"
+
+const rfcPackJSON = `[{"bn":"urn:dev:ow:10e2073a01080063:","bt":1.276020076001e+09,"bu":"A","bver":5,"n":"voltage","u":"V","v":120.1},
+{"n":"current","t":-5,"v":1.2},{"n":"current","t":-4,"v":1.3},{"n":"current","t":-3,"v":1.4},
+{"n":"current","t":-2,"v":1.5},{"n":"current","t":-1,"v":1.6},{"n":"current","v":1.7}]`
+
+// Following hex-encoded bytes correspond to the CBOR encoding of the pack
+// containing all the value variants, with data value as byte string:
+// [{-2: "urn:dev:ow:10e2073a0108006:", -3: 1.320067464e+09, -6: 10.0, 0: "temperature", 1: "Cel", 2: 23.1},
+// {0: "door", 6: 1, 4: true}, {0: "label", 6: 2, 3: "kitchen"}, {0: "raw", 6: 3, 8: h'deadbeef'},
+// {0: "energy", 6: 4, 1: "J", 5: 5.0}]
+const variantsPack = "85a621781b75726e3a6465763a6f773a3130653230373361303130383030363a22fb41d3aba86200000025fb4024000000000000006b74656d7065726174757265016343656c02fb403719999999999aa30064646f6f72060104f5a3060203676b69746368656e00656c6162656ca3006372617706030844deadbeefa40066656e65726779060401614a05fb4014000000000000"
+
+const variantsPackJSON = `[{"bn":"urn:dev:ow:10e2073a0108006:","bt":1.320067464e+09,"bs":10,"n":"temperature","u":"Cel","v":23.1},
+{"n":"door","t":1,"vb":true},{"n":"label","t":2,"vs":"kitchen"},{"n":"raw","t":3,"vd":"3q2-7w"},
+{"n":"energy","t":4,"u":"J","s":5}]`
+
+func TestTransformCBORPacks(t *testing.T) {
+	rfcBytes, err := hex.DecodeString(rfcPack)
+	require.Nil(t, err, "Decoding CBOR expected to succeed")
+	variantsBytes, err := hex.DecodeString(variantsPack)
+	require.Nil(t, err, "Decoding CBOR expected to succeed")
+
+	cases := []struct {
+		desc        string
+		contentType string
+		cbor        []byte
+		json        string
+		records     int
+	}{
+		{
+			desc:        "transform RFC 8428 pack",
+			contentType: senml.CBOR,
+			cbor:        rfcBytes,
+			json:        rfcPackJSON,
+			records:     7,
+		},
+		{
+			desc:        "transform pack with all value variants",
+			contentType: senml.CBOR,
+			cbor:        variantsBytes,
+			json:        variantsPackJSON,
+			records:     5,
+		},
+		{
+			desc:        "transform pack with plain CBOR content type",
+			contentType: senml.PlainCBOR,
+			cbor:        variantsBytes,
+			json:        variantsPackJSON,
+			records:     5,
+		},
+	}
+
+	for _, tc := range cases {
+		msg := messaging.Message{
+			Channel:   "channel",
+			Publisher: "publisher",
+			Protocol:  "coap",
+			Payload:   tc.cbor,
+		}
+		cborMsgs, err := senml.New(tc.contentType).Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		msg.Payload = []byte(tc.json)
+		jsonMsgs, err := senml.New(senml.JSON).Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		assert.Len(t, cborMsgs, tc.records, fmt.Sprintf("%s: expected %d messages got %v", tc.desc, tc.records, cborMsgs))
+		assert.ElementsMatch(t, jsonMsgs, cborMsgs, fmt.Sprintf("%s: expected %v got %v", tc.desc, jsonMsgs, cborMsgs))
+	}
+}
+
+func TestTransformCBORVariants(t *testing.T) {
+	variantsBytes, err := hex.DecodeString(variantsPack)
+	require.Nil(t, err, "Decoding CBOR expected to succeed")
+
+	res, err := senml.New(senml.CBOR).Transform(messaging.Message{Payload: variantsBytes})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	msgs := map[string]senml.Message{}
+	for _, m := range res.([]senml.Message) {
+		msgs[m.Name] = m
+	}
+
+	bn := "urn:dev:ow:10e2073a0108006:"
+	bt := 1.320067464e+09
+	val, sum := 23.1, 15.0
+	vb, vs, vd := true, "kitchen", "3q2-7w"
+	expected := []senml.Message{
+		{Name: bn + "temperature", Unit: "Cel", Time: bt, Value: &val},
+		{Name: bn + "door", Time: bt + 1, BoolValue: &vb},
+		{Name: bn + "label", Time: bt + 2, StringValue: &vs},
+		{Name: bn + "raw", Time: bt + 3, DataValue: &vd},
+		{Name: bn + "energy", Unit: "J", Time: bt + 4, Sum: &sum},
+	}
+
+	for _, e := range expected {
+		e.Tenant = messaging.DefaultTenant
+		m, ok := msgs[e.Name]
+		require.True(t, ok, fmt.Sprintf("expected message %s", e.Name))
+		assert.Equal(t, e, m, fmt.Sprintf("%s: expected %v got %v", e.Name, e, m))
+	}
+}
+
+func TestTransformCBORMalformed(t *testing.T) {
+	tr := senml.New(senml.CBOR)
+
+	var corpus [][]byte
+	for _, pack := range []string{rfcPack, variantsPack} {
+		b, err := hex.DecodeString(pack)
+		require.Nil(t, err, "Decoding CBOR expected to succeed")
+		for i := 0; i < len(b); i++ {
+			corpus = append(corpus, b[:i])
+		}
+	}
+
+	// Every truncated pack must produce an error.
+	for _, pld := range corpus {
+		var err error
+		assert.NotPanics(t, func() {
+			_, err = tr.Transform(messaging.Message{Payload: pld})
+		}, fmt.Sprintf("transform of %x expected not to panic", pld))
+		assert.NotNil(t, err, fmt.Sprintf("transform of %x expected to fail", pld))
+	}
+
+	// Randomly corrupted packs must not crash the transformer.
+	r := rand.New(rand.NewSource(1))
+	for _, pld := range corpus {
+		if len(pld) == 0 {
+			continue
+		}
+		b := append([]byte{}, pld...)
+		b[r.Intn(len(b))] ^= byte(r.Intn(255) + 1)
+		assert.NotPanics(t, func() {
+			tr.Transform(messaging.Message{Payload: b})
+		}, fmt.Sprintf("transform of %x expected not to panic", b))
+	}
+}
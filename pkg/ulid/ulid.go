@@ -5,6 +5,7 @@
 package ulid
 
 import (
+	"sync"
 	"time"
 
 	"github.com/mainflux/mainflux"
@@ -20,23 +21,35 @@ var ErrGeneratingID = errors.New("generating id failed")
 var _ mainflux.IDProvider = (*ulidProvider)(nil)
 
 type ulidProvider struct {
-	entropy *mathrand.Rand
+	mu      sync.Mutex
+	entropy *ulid.MonotonicEntropy
 }
 
-// New instantiates a ULID provider.
+// New instantiates a ULID provider. IDs generated within the same
+// millisecond use monotonically increasing entropy, so they are unique
+// and sorted in the order of generation.
 func New() mainflux.IDProvider {
 	seed := time.Now().UnixNano()
 	source := mathrand.NewSource(seed)
 	return &ulidProvider{
-		entropy: mathrand.New(source),
+		entropy: ulid.Monotonic(mathrand.New(source), 0),
 	}
 }
 
 func (up *ulidProvider) ID() (string, error) {
+	up.mu.Lock()
+	defer up.mu.Unlock()
+
 	id, err := ulid.New(ulid.Timestamp(time.Now()), up.entropy)
 	if err != nil {
-		return "", err
+		return "", errors.Wrap(ErrGeneratingID, err)
 	}
 
 	return id.String(), nil
 }
+
+// Validate checks if the given string is a valid ULID.
+func Validate(id string) error {
+	_, err := ulid.ParseStrict(id)
+	return err
+}
@@ -0,0 +1,88 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package ulid_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mainflux/mainflux/pkg/ulid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	workers = 16
+	burst   = 10000
+)
+
+func TestIDMonotonic(t *testing.T) {
+	idp := ulid.New()
+
+	prev := ""
+	for i := 0; i < workers*burst; i++ {
+		id, err := idp.ID()
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		require.Nil(t, ulid.Validate(id), fmt.Sprintf("expected valid ULID got %s", id))
+		require.True(t, id > prev, fmt.Sprintf("expected %s to be greater than %s", id, prev))
+		prev = id
+	}
+}
+
+func TestIDBurst(t *testing.T) {
+	idp := ulid.New()
+	ids := make(chan string, workers*burst)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < burst; j++ {
+				id, err := idp.ID()
+				assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+				ids <- id
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]struct{}, workers*burst)
+	for id := range ids {
+		_, ok := seen[id]
+		assert.False(t, ok, fmt.Sprintf("expected unique IDs got duplicate %s", id))
+		seen[id] = struct{}{}
+	}
+	assert.Equal(t, workers*burst, len(seen), fmt.Sprintf("expected %d IDs got %d", workers*burst, len(seen)))
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		desc string
+		id   string
+		err  bool
+	}{
+		{
+			desc: "validate valid ULID",
+			id:   "01EWW6K5KSA4YV1BXBQ0YQZEDK",
+		},
+		{
+			desc: "validate ULID with invalid character",
+			id:   "01EWW6K5KSA4YV1BXBQ0YQZEDU",
+			err:  true,
+		},
+		{
+			desc: "validate too short ULID",
+			id:   "01EWW6K5KSA4YV1BXBQ0YQZED",
+			err:  true,
+		},
+	}
+
+	for _, tc := range cases {
+		err := ulid.Validate(tc.id)
+		assert.Equal(t, tc.err, err != nil, fmt.Sprintf("%s: expected error %t got %s", tc.desc, tc.err, err))
+	}
+}
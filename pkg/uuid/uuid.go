@@ -30,3 +30,9 @@ func (up *uuidProvider) ID() (string, error) {
 
 	return id.String(), nil
 }
+
+// Validate checks if the given string is a valid UUID.
+func Validate(id string) error {
+	_, err := uuid.FromString(id)
+	return err
+}
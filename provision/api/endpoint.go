@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/provision"
 )
 
@@ -16,22 +17,54 @@ func doProvision(svc provision.Service) endpoint.Endpoint {
 		token := req.token
 
 		res, err := svc.Provision(token, req.Name, req.ExternalID, req.ExternalKey)
+		if errors.Contains(err, provision.ErrConflict) {
+			return nil, err
+		}
 
 		if err != nil {
-			return provisionRes{Error: err.Error()}, nil
+			return provisionRes{Error: err.Error(), Dirty: res.Dirty}, nil
 		}
 
-		provisionResponse := provisionRes{
-			Things:      res.Things,
-			Channels:    res.Channels,
-			ClientCert:  res.ClientCert,
-			ClientKey:   res.ClientKey,
-			CACert:      res.CACert,
-			Whitelisted: res.Whitelisted,
+		return toProvisionRes(res), nil
+	}
+}
+
+func doBulkProvision(svc provision.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(bulkProvisionReq)
+		if err := req.validate(); err != nil {
+			return nil, err
 		}
 
-		return provisionResponse, nil
+		reqs := make([]provision.Request, len(req.requests))
+		for i, r := range req.requests {
+			reqs[i] = provision.Request{
+				Name:        r.Name,
+				ExternalID:  r.ExternalID,
+				ExternalKey: r.ExternalKey,
+			}
+		}
 
+		results, err := svc.ProvisionBulk(ctx, req.token, reqs)
+		if err != nil {
+			return nil, err
+		}
+
+		res := make(bulkProvisionRes, len(results))
+		for i, r := range results {
+			item := bulkItemRes{
+				ExternalID:   r.ExternalID,
+				provisionRes: toProvisionRes(r.Result),
+			}
+			if r.Err != nil {
+				// Entities of the failed entry are removed, so only
+				// the error and the remaining entities are returned.
+				item.provisionRes = provisionRes{Error: r.Err.Error(), Dirty: r.Result.Dirty}
+			}
+			res[i] = item
+		}
+
+		return res, nil
 	}
 }
 
@@ -44,3 +77,16 @@ func getMapping(svc provision.Service) endpoint.Endpoint {
 		return svc.Mapping(req.token)
 	}
 }
+
+func toProvisionRes(res provision.Result) provisionRes {
+	return provisionRes{
+		Things:      res.Things,
+		Channels:    res.Channels,
+		ClientCert:  res.ClientCert,
+		ClientKey:   res.ClientKey,
+		CACert:      res.CACert,
+		Whitelisted: res.Whitelisted,
+		Error:       res.Error,
+		Dirty:       res.Dirty,
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -33,6 +34,25 @@ func (lm *loggingMiddleware) Provision(token, name, externalID, externalKey stri
 	return lm.svc.Provision(token, name, externalID, externalKey)
 }
 
+func (lm *loggingMiddleware) ProvisionBulk(ctx context.Context, token string, reqs []provision.Request) (res []provision.BulkResult, err error) {
+	defer func(begin time.Time) {
+		failed := 0
+		for _, r := range res {
+			if r.Err != nil {
+				failed++
+			}
+		}
+		message := fmt.Sprintf("Method provision_bulk for token: %s and %d entries with %d failed took %s to complete", token, len(reqs), failed, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors", message))
+	}(time.Now())
+
+	return lm.svc.ProvisionBulk(ctx, token, reqs)
+}
+
 func (lm *loggingMiddleware) Cert(token, thingID, duration string, keyBits int) (cert string, key string, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method cert for token: %s and thing: %v took %s to complete", token, thingID, time.Since(begin))
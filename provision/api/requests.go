@@ -16,6 +16,23 @@ func (req provisionReq) validate() error {
 	return nil
 }
 
+type bulkProvisionReq struct {
+	token    string
+	requests []provisionReq
+}
+
+func (req bulkProvisionReq) validate() error {
+	if len(req.requests) == 0 {
+		return errors.ErrMalformedEntity
+	}
+	for _, r := range req.requests {
+		if err := r.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type mappingReq struct {
 	token string
 }
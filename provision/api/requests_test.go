@@ -35,3 +35,31 @@ func TestValidate(t *testing.T) {
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected `%v` got `%v`", desc, tc.err, err))
 	}
 }
+
+func TestValidateBulk(t *testing.T) {
+	valid := provisionReq{ExternalID: "11:22:33:44:55:66", ExternalKey: "key12345678"}
+
+	cases := map[string]struct {
+		requests []provisionReq
+		err      error
+	}{
+		"bulk request with valid entries": {
+			requests: []provisionReq{valid, valid},
+			err:      nil,
+		},
+		"empty bulk request": {
+			err: errors.ErrMalformedEntity,
+		},
+		"bulk request with invalid entry": {
+			requests: []provisionReq{valid, {}},
+			err:      errors.ErrMalformedEntity,
+		},
+	}
+
+	for desc, tc := range cases {
+		req := bulkProvisionReq{requests: tc.requests}
+
+		err := req.validate()
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected `%v` got `%v`", desc, tc.err, err))
+	}
+}
@@ -4,16 +4,18 @@ import (
 	"net/http"
 
 	SDK "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/provision"
 )
 
 type provisionRes struct {
-	Things      []SDK.Thing       `json:"things"`
-	Channels    []SDK.Channel     `json:"channels"`
-	ClientCert  map[string]string `json:"client_cert,omitempty"`
-	ClientKey   map[string]string `json:"client_key,omitempty"`
-	CACert      string            `json:"ca_cert,omitempty"`
-	Whitelisted map[string]bool   `json:"whitelisted,omitempty"`
-	Error       string            `json:"error,omitempty"`
+	Things      []SDK.Thing        `json:"things"`
+	Channels    []SDK.Channel      `json:"channels"`
+	ClientCert  map[string]string  `json:"client_cert,omitempty"`
+	ClientKey   map[string]string  `json:"client_key,omitempty"`
+	CACert      string             `json:"ca_cert,omitempty"`
+	Whitelisted map[string]bool    `json:"whitelisted,omitempty"`
+	Error       string             `json:"error,omitempty"`
+	Dirty       []provision.Entity `json:"dirty,omitempty"`
 }
 
 func (res provisionRes) Code() int {
@@ -27,3 +29,22 @@ func (res provisionRes) Headers() map[string]string {
 func (res provisionRes) Empty() bool {
 	return false
 }
+
+type bulkItemRes struct {
+	ExternalID string `json:"external_id"`
+	provisionRes
+}
+
+type bulkProvisionRes []bulkItemRes
+
+func (res bulkProvisionRes) Code() int {
+	return http.StatusCreated
+}
+
+func (res bulkProvisionRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res bulkProvisionRes) Empty() bool {
+	return false
+}
@@ -21,6 +21,7 @@ const (
 var (
 	errUnauthorized = errors.New("missing or invalid credentials provided")
 	errConflict     = errors.New("entity already exists")
+	errBatchSize    = errors.New("too many entries in bulk request")
 )
 
 // MakeHandler returns a HTTP handler for API endpoints.
@@ -39,6 +40,13 @@ func MakeHandler(svc provision.Service) http.Handler {
 		opts...,
 	))
 
+	r.Post("/mapping/bulk", kithttp.NewServer(
+		doBulkProvision(svc),
+		decodeBulkProvisionRequest,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Get("/mapping", kithttp.NewServer(
 		getMapping(svc),
 		decodeMappingRequest,
@@ -83,6 +91,19 @@ func decodeProvisionRequest(_ context.Context, r *http.Request) (interface{}, er
 	return req, nil
 }
 
+func decodeBulkProvisionRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	if r.Header.Get("Content-Type") != contentType {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := bulkProvisionReq{token: r.Header.Get("Authorization")}
+	if err := json.NewDecoder(r.Body).Decode(&req.requests); err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
 func decodeMappingRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	if r.Header.Get("Content-Type") != contentType {
 		return nil, errors.ErrUnsupportedContentType
@@ -96,6 +117,13 @@ func decodeMappingRequest(_ context.Context, r *http.Request) (interface{}, erro
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", contentType)
 
+	switch {
+	case errors.Contains(err, provision.ErrConflict):
+		err = errConflict
+	case errors.Contains(err, provision.ErrBatchSize):
+		err = errBatchSize
+	}
+
 	switch err {
 	case errors.ErrUnsupportedContentType:
 		w.WriteHeader(http.StatusUnsupportedMediaType)
@@ -103,6 +131,8 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		w.WriteHeader(http.StatusBadRequest)
 	case errConflict:
 		w.WriteHeader(http.StatusConflict)
+	case errBatchSize:
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
 	default:
 		switch err.(type) {
 		case *json.SyntaxError:
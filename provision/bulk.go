@@ -0,0 +1,106 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package provision
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	SDK "github.com/mainflux/mainflux/pkg/sdk/go"
+)
+
+var (
+	// ErrBatchSize indicates that the bulk request contains too many entries.
+	ErrBatchSize = errors.New("batch size exceeds the limit")
+
+	// ErrBulkDeadline indicates that the entry has not been provisioned
+	// before the bulk request deadline.
+	ErrBulkDeadline = errors.New("bulk provisioning deadline exceeded")
+)
+
+// Request represents the single entry of the bulk provisioning request.
+type Request struct {
+	Name        string
+	ExternalID  string
+	ExternalKey string
+}
+
+// BulkResult represents the result of the single bulk provisioning entry.
+type BulkResult struct {
+	ExternalID string
+	Result     Result
+	Err        error
+}
+
+func (ps *provisionService) ProvisionBulk(ctx context.Context, token string, reqs []Request) ([]BulkResult, error) {
+	if ps.conf.Bulk.MaxSize > 0 && len(reqs) > ps.conf.Bulk.MaxSize {
+		return nil, errors.Wrap(ErrBatchSize, fmt.Errorf("%d entries, limit is %d", len(reqs), ps.conf.Bulk.MaxSize))
+	}
+
+	// Create the token once instead of doing it for every entry.
+	token, err := ps.createTokenIfEmpty(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if ps.conf.Bulk.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ps.conf.Bulk.Timeout)
+		defer cancel()
+	}
+	svc := ps.withSDK(ps.sdk.WithContext(ctx))
+
+	workers := ps.conf.Bulk.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	results := make([]BulkResult, len(reqs))
+
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		results[i].ExternalID = req.ExternalID
+		if err := acquire(ctx, sem); err != nil {
+			results[i].Err = errors.Wrap(ErrBulkDeadline, err)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, req Request) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i].Result, results[i].Err = svc.Provision(token, req.Name, req.ExternalID, req.ExternalKey)
+		}(i, req)
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// withSDK returns the copy of the service which uses the given SDK. Results
+// and locks are shared with the original service.
+func (ps *provisionService) withSDK(sdk SDK.SDK) *provisionService {
+	svc := *ps
+	svc.sdk = sdk
+	return &svc
+}
+
+func acquire(ctx context.Context, sem chan struct{}) error {
+	// Don't start new entries once the deadline is exceeded, even if
+	// there is a free worker.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
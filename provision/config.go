@@ -6,6 +6,7 @@ package provision
 import (
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/pelletier/go-toml"
@@ -31,10 +32,11 @@ type ServiceConf struct {
 }
 
 type Bootstrap struct {
-	X509Provision bool                   `toml:"x509_provision"`
-	Provision     bool                   `toml:"provision"`
-	AutoWhiteList bool                   `toml:"autowhite_list"`
-	Content       map[string]interface{} `toml:"content"`
+	X509Provision      bool                   `toml:"x509_provision"`
+	X509PartialSuccess bool                   `toml:"x509_partial_success"`
+	Provision          bool                   `toml:"provision"`
+	AutoWhiteList      bool                   `toml:"autowhite_list"`
+	Content            map[string]interface{} `toml:"content"`
 }
 type Channel struct {
 	Name     string                 `toml:"name"`
@@ -61,14 +63,46 @@ type Certs struct {
 	KeyType    string `json:"key_type"`
 }
 
+// Idempotency represents provisioning replay settings.
+type Idempotency struct {
+	// TTL is the period during which the result of provisioning is
+	// replayed for the repeated requests with the same external ID.
+	TTL time.Duration `toml:"ttl"`
+}
+
+// Bulk represents bulk provisioning limits.
+type Bulk struct {
+	// MaxSize is the maximum number of entries in the bulk request.
+	MaxSize int `toml:"max_size"`
+
+	// Workers is the number of entries provisioned concurrently.
+	Workers int `toml:"workers"`
+
+	// Timeout is the deadline of the whole bulk request.
+	Timeout time.Duration `toml:"timeout"`
+}
+
+// Rollback represents settings of removal of the entities created during
+// failed provisioning.
+type Rollback struct {
+	// Retries is the number of times the removal of the entity is retried.
+	Retries int `toml:"retries"`
+
+	// Backoff is the delay between the removal retries.
+	Backoff time.Duration `toml:"backoff"`
+}
+
 // Config struct of Provision
 type Config struct {
-	File      string      `toml:"file"`
-	Server    ServiceConf `toml:"server" mapstructure:"server"`
-	Bootstrap Bootstrap   `toml:"bootstrap" mapstructure:"bootstrap"`
-	Things    []Thing     `toml:"things" mapstructure:"things"`
-	Channels  []Channel   `toml:"channels" mapstructure:"channels"`
-	Certs     Certs       `toml:"certs" mapstructure:"certs"`
+	File        string      `toml:"file"`
+	Server      ServiceConf `toml:"server" mapstructure:"server"`
+	Bootstrap   Bootstrap   `toml:"bootstrap" mapstructure:"bootstrap"`
+	Things      []Thing     `toml:"things" mapstructure:"things"`
+	Channels    []Channel   `toml:"channels" mapstructure:"channels"`
+	Certs       Certs       `toml:"certs" mapstructure:"certs"`
+	Idempotency Idempotency `toml:"idempotency" mapstructure:"idempotency"`
+	Bulk        Bulk        `toml:"bulk" mapstructure:"bulk"`
+	Rollback    Rollback    `toml:"rollback" mapstructure:"rollback"`
 }
 
 // Save - store config in a file
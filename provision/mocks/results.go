@@ -0,0 +1,60 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/provision"
+)
+
+var _ provision.ResultCache = (*resultCache)(nil)
+
+type entry struct {
+	rec     provision.Record
+	expires time.Time
+}
+
+type resultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]entry
+}
+
+// NewResultCache returns the in-memory result cache which keeps records for
+// the given TTL the same way the Redis cache does.
+func NewResultCache(ttl time.Duration) provision.ResultCache {
+	return &resultCache{
+		ttl:     ttl,
+		entries: make(map[string]entry),
+	}
+}
+
+func (rc *resultCache) Save(_ context.Context, externalID string, rec provision.Record) error {
+	if rc.ttl <= 0 {
+		return nil
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.entries[externalID] = entry{
+		rec:     rec,
+		expires: time.Now().Add(rc.ttl),
+	}
+	return nil
+}
+
+func (rc *resultCache) Retrieve(_ context.Context, externalID string) (provision.Record, bool, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	e, ok := rc.entries[externalID]
+	if !ok || time.Now().After(e.expires) {
+		return provision.Record{}, false, nil
+	}
+	return e.rec, true, nil
+}
@@ -0,0 +1,344 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	SDK "github.com/mainflux/mainflux/pkg/sdk/go"
+)
+
+var _ SDK.SDK = (*SDKMock)(nil)
+
+// State contains the entities created using the SDK mock.
+type State struct {
+	Things     map[string]SDK.Thing
+	Channels   map[string]SDK.Channel
+	Bootstraps map[string]SDK.BootstrapConfig
+	Certs      map[string]SDK.Cert
+}
+
+// SDKMock is the stateful mock of the SDK methods used by the provision
+// service. Calling any other SDK method panics.
+type SDKMock struct {
+	SDK.SDK
+
+	mu         sync.Mutex
+	counter    int
+	things     map[string]SDK.Thing
+	channels   map[string]SDK.Channel
+	bootstraps map[string]SDK.BootstrapConfig
+	certs      map[string]SDK.Cert
+	failures   map[string]failure
+	rejected   map[string]error
+}
+
+// NewSDK returns SDK mock without any entities.
+func NewSDK() *SDKMock {
+	return &SDKMock{
+		things:     make(map[string]SDK.Thing),
+		channels:   make(map[string]SDK.Channel),
+		bootstraps: make(map[string]SDK.BootstrapConfig),
+		certs:      make(map[string]SDK.Cert),
+		failures:   make(map[string]failure),
+		rejected:   make(map[string]error),
+	}
+}
+
+type failure struct {
+	err   error
+	times int
+}
+
+// Fail makes every call of the SDK method with the given name return the error.
+func (sdk *SDKMock) Fail(method string, err error) {
+	sdk.FailTimes(method, err, 0)
+}
+
+// FailTimes makes the given number of calls of the SDK method with the given
+// name return the error. If times is 0, every call returns the error.
+func (sdk *SDKMock) FailTimes(method string, err error, times int) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err == nil {
+		delete(sdk.failures, method)
+		return
+	}
+	sdk.failures[method] = failure{err: err, times: times}
+}
+
+// RejectThing makes creation of the things with the given name return the error.
+func (sdk *SDKMock) RejectThing(name string, err error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	sdk.rejected[name] = err
+}
+
+// State returns the copy of the entities created using the mock.
+func (sdk *SDKMock) State() State {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	st := State{
+		Things:     make(map[string]SDK.Thing),
+		Channels:   make(map[string]SDK.Channel),
+		Bootstraps: make(map[string]SDK.BootstrapConfig),
+		Certs:      make(map[string]SDK.Cert),
+	}
+	for k, v := range sdk.things {
+		st.Things[k] = v
+	}
+	for k, v := range sdk.channels {
+		st.Channels[k] = v
+	}
+	for k, v := range sdk.bootstraps {
+		st.Bootstraps[k] = v
+	}
+	for k, v := range sdk.certs {
+		st.Certs[k] = v
+	}
+	return st
+}
+
+func (sdk *SDKMock) failure(method string) error {
+	f, ok := sdk.failures[method]
+	if !ok {
+		return nil
+	}
+	if f.times > 0 {
+		f.times--
+		sdk.failures[method] = f
+		if f.times == 0 {
+			delete(sdk.failures, method)
+		}
+	}
+	return f.err
+}
+
+func (sdk *SDKMock) User(token string) (SDK.User, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("User"); err != nil {
+		return SDK.User{}, err
+	}
+	return SDK.User{Email: token}, nil
+}
+
+func (sdk *SDKMock) CreateToken(user SDK.User) (string, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("CreateToken"); err != nil {
+		return "", err
+	}
+	return user.Email, nil
+}
+
+func (sdk *SDKMock) CreateThing(thing SDK.Thing, token string) (string, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("CreateThing"); err != nil {
+		return "", err
+	}
+	if err := sdk.rejected[thing.Name]; err != nil {
+		return "", err
+	}
+	sdk.counter++
+	thing.ID = fmt.Sprintf("thing-%d", sdk.counter)
+	thing.Key = fmt.Sprintf("key-%d", sdk.counter)
+	sdk.things[thing.ID] = thing
+	return thing.ID, nil
+}
+
+func (sdk *SDKMock) Thing(id, token string) (SDK.Thing, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("Thing"); err != nil {
+		return SDK.Thing{}, err
+	}
+	th, ok := sdk.things[id]
+	if !ok {
+		return SDK.Thing{}, SDK.ErrFailedFetch
+	}
+	return th, nil
+}
+
+func (sdk *SDKMock) UpdateThing(thing SDK.Thing, token string) error {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("UpdateThing"); err != nil {
+		return err
+	}
+	if _, ok := sdk.things[thing.ID]; !ok {
+		return SDK.ErrFailedUpdate
+	}
+	sdk.things[thing.ID] = thing
+	return nil
+}
+
+func (sdk *SDKMock) DeleteThing(id, token string) error {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("DeleteThing"); err != nil {
+		return err
+	}
+	delete(sdk.things, id)
+	return nil
+}
+
+func (sdk *SDKMock) CreateChannel(channel SDK.Channel, token string) (string, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("CreateChannel"); err != nil {
+		return "", err
+	}
+	sdk.counter++
+	channel.ID = fmt.Sprintf("channel-%d", sdk.counter)
+	sdk.channels[channel.ID] = channel
+	return channel.ID, nil
+}
+
+func (sdk *SDKMock) Channel(id, token string) (SDK.Channel, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("Channel"); err != nil {
+		return SDK.Channel{}, err
+	}
+	ch, ok := sdk.channels[id]
+	if !ok {
+		return SDK.Channel{}, SDK.ErrFailedFetch
+	}
+	return ch, nil
+}
+
+func (sdk *SDKMock) DeleteChannel(id, token string) error {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("DeleteChannel"); err != nil {
+		return err
+	}
+	delete(sdk.channels, id)
+	return nil
+}
+
+func (sdk *SDKMock) AddBootstrap(token string, cfg SDK.BootstrapConfig) (string, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("AddBootstrap"); err != nil {
+		return "", err
+	}
+	cfg.MFThing = cfg.ThingID
+	sdk.bootstraps[cfg.ThingID] = cfg
+	return cfg.ThingID, nil
+}
+
+func (sdk *SDKMock) ViewBootstrap(token, id string) (SDK.BootstrapConfig, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("ViewBootstrap"); err != nil {
+		return SDK.BootstrapConfig{}, err
+	}
+	cfg, ok := sdk.bootstraps[id]
+	if !ok {
+		return SDK.BootstrapConfig{}, SDK.ErrFailedFetch
+	}
+	return cfg, nil
+}
+
+func (sdk *SDKMock) UpdateBootstrapCerts(token string, id string, clientCert, clientKey, ca string) error {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("UpdateBootstrapCerts"); err != nil {
+		return err
+	}
+	cfg, ok := sdk.bootstraps[id]
+	if !ok {
+		return SDK.ErrFailedCertUpdate
+	}
+	cfg.ClientCert, cfg.ClientKey, cfg.CACert = clientCert, clientKey, ca
+	sdk.bootstraps[id] = cfg
+	return nil
+}
+
+func (sdk *SDKMock) RemoveBootstrap(token, id string) error {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("RemoveBootstrap"); err != nil {
+		return err
+	}
+	delete(sdk.bootstraps, id)
+	return nil
+}
+
+func (sdk *SDKMock) Whitelist(token string, cfg SDK.BootstrapConfig) error {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("Whitelist"); err != nil {
+		return err
+	}
+	bs, ok := sdk.bootstraps[cfg.MFThing]
+	if !ok {
+		return SDK.ErrFailedWhitelist
+	}
+	bs.State = cfg.State
+	sdk.bootstraps[cfg.MFThing] = bs
+	return nil
+}
+
+func (sdk *SDKMock) IssueCert(thingID string, keyBits int, keyType, valid, token string) (SDK.Cert, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("IssueCert"); err != nil {
+		return SDK.Cert{}, err
+	}
+	sdk.counter++
+	cert := SDK.Cert{
+		ThingID:    thingID,
+		Serial:     fmt.Sprintf("serial-%d", sdk.counter),
+		ClientCert: fmt.Sprintf("cert-%s", thingID),
+		ClientKey:  fmt.Sprintf("cert-key-%s", thingID),
+		CACert:     "ca-cert",
+	}
+	sdk.certs[thingID] = cert
+	return cert, nil
+}
+
+func (sdk *SDKMock) RevokeCertBySerial(serialID, token string) (time.Time, error) {
+	sdk.mu.Lock()
+	defer sdk.mu.Unlock()
+
+	if err := sdk.failure("RevokeCertBySerial"); err != nil {
+		return time.Time{}, err
+	}
+	for thingID, cert := range sdk.certs {
+		if cert.Serial == serialID {
+			delete(sdk.certs, thingID)
+			return time.Now(), nil
+		}
+	}
+	return time.Time{}, SDK.ErrCertsRevoke
+}
+
+func (sdk *SDKMock) WithContext(ctx context.Context) SDK.SDK {
+	return sdk
+}
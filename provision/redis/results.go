@@ -0,0 +1,65 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package redis contains the provisioning results cache implementation using
+// Redis, shared by the service instances.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/provision"
+)
+
+const keyPrefix = "provision:result"
+
+var _ provision.ResultCache = (*resultCache)(nil)
+
+type resultCache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewResultCache returns Redis result cache which keeps records for the
+// given TTL. Records are not stored if TTL is not positive.
+func NewResultCache(client *redis.Client, ttl time.Duration) provision.ResultCache {
+	return resultCache{client: client, ttl: ttl}
+}
+
+func (rc resultCache) Save(ctx context.Context, externalID string, rec provision.Record) error {
+	if rc.ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	return rc.client.Set(ctx, key(externalID), data, rc.ttl).Err()
+}
+
+func (rc resultCache) Retrieve(ctx context.Context, externalID string) (provision.Record, bool, error) {
+	data, err := rc.client.Get(ctx, key(externalID)).Bytes()
+	if err == redis.Nil {
+		return provision.Record{}, false, nil
+	}
+	if err != nil {
+		return provision.Record{}, false, err
+	}
+
+	var rec provision.Record
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return provision.Record{}, false, err
+	}
+
+	return rec, true, nil
+}
+
+func key(externalID string) string {
+	return fmt.Sprintf("%s:%s", keyPrefix, externalID)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	SDK "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/provision"
+	"github.com/mainflux/mainflux/provision/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const externalID = "11:22:33:44:55:66"
+
+func TestResultCache(t *testing.T) {
+	rec := provision.Record{
+		ExternalKey: "key",
+		Result: provision.Result{
+			Things:   []SDK.Thing{{ID: "thing", Name: "thing", Key: "thing-key"}},
+			Channels: []SDK.Channel{{ID: "channel", Name: "channel"}},
+			CACert:   "ca",
+		},
+	}
+
+	cases := []struct {
+		desc  string
+		ttl   time.Duration
+		wait  time.Duration
+		found bool
+	}{
+		{
+			desc:  "retrieve saved record",
+			ttl:   time.Hour,
+			found: true,
+		},
+		{
+			desc: "retrieve expired record",
+			ttl:  10 * time.Millisecond,
+			wait: 20 * time.Millisecond,
+		},
+		{
+			desc: "retrieve record with caching disabled",
+			ttl:  0,
+		},
+	}
+
+	for _, tc := range cases {
+		err := redisClient.FlushAll(context.Background()).Err()
+		require.Nil(t, err, fmt.Sprintf("%s: got unexpected error: %s", tc.desc, err))
+
+		rc := redis.NewResultCache(redisClient, tc.ttl)
+		err = rc.Save(context.Background(), externalID, rec)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		time.Sleep(tc.wait)
+
+		res, ok, err := rc.Retrieve(context.Background(), externalID)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		assert.Equal(t, tc.found, ok, fmt.Sprintf("%s: expected found %t got %t", tc.desc, tc.found, ok))
+		if tc.found {
+			assert.Equal(t, rec, res, fmt.Sprintf("%s: expected %v got %v", tc.desc, rec, res))
+		}
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package provision
+
+import (
+	"context"
+	"sync"
+)
+
+// Record represents the result of the successful provisioning.
+type Record struct {
+	ExternalKey string
+	Result      Result
+}
+
+// ResultCache stores the results of the successful provisioning by external
+// ID, so that the repeated requests could be replayed by any of the service
+// instances.
+type ResultCache interface {
+	// Save stores the provisioning record of the given external ID.
+	Save(ctx context.Context, externalID string, rec Record) error
+
+	// Retrieve returns the provisioning record of the given external ID.
+	// False is returned if there is no record or it expired.
+	Retrieve(ctx context.Context, externalID string) (Record, bool, error)
+}
+
+// keyedMutex serializes provisioning of the same external ID, so that
+// concurrent duplicate requests don't create the entities twice.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: make(map[string]*keyLock)}
+}
+
+// lock locks the given key and returns the function which unlocks it.
+func (km *keyedMutex) lock(key string) func() {
+	km.mu.Lock()
+	l, ok := km.locks[key]
+	if !ok {
+		l = &keyLock{}
+		km.locks[key] = l
+	}
+	l.refs++
+	km.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+
+		km.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(km.locks, key)
+		}
+		km.mu.Unlock()
+	}
+}
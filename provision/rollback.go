@@ -0,0 +1,94 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package provision
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+// Types of the entities created during provisioning.
+const (
+	ThingEntity     = "thing"
+	ChannelEntity   = "channel"
+	CertEntity      = "cert"
+	BootstrapEntity = "bootstrap"
+)
+
+// ErrIncompleteRollback indicates that some of the entities created during
+// failed provisioning couldn't be removed.
+var ErrIncompleteRollback = errors.New("failed to remove provisioned entities")
+
+// Entity identifies the entity created during provisioning.
+type Entity struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type compensation struct {
+	entity Entity
+	undo   func() error
+}
+
+// rollback tracks the entities created during provisioning, so that they
+// can be removed in the reverse order of creation if provisioning fails.
+type rollback struct {
+	retries int
+	backoff time.Duration
+	logger  logger.Logger
+	steps   []compensation
+}
+
+func newRollback(cfg Rollback, logger logger.Logger) *rollback {
+	return &rollback{
+		retries: cfg.Retries,
+		backoff: cfg.Backoff,
+		logger:  logger,
+	}
+}
+
+// add records the created entity and the function which removes it.
+func (r *rollback) add(typ, id string, undo func() error) {
+	r.steps = append(r.steps, compensation{
+		entity: Entity{Type: typ, ID: id},
+		undo:   undo,
+	})
+}
+
+// run removes the recorded entities and returns the ones which couldn't be
+// removed, so that they could be removed manually. If the context is done,
+// the entities are not retried anymore.
+func (r *rollback) run(ctx context.Context) []Entity {
+	var dirty []Entity
+	for i := len(r.steps) - 1; i >= 0; i-- {
+		c := r.steps[i]
+		if err := r.retry(ctx, c.undo); err != nil {
+			r.logger.Error(fmt.Sprintf("Failed to remove %s %s, it has to be removed manually: %s", c.entity.Type, c.entity.ID, err))
+			dirty = append(dirty, c.entity)
+		}
+	}
+	r.steps = nil
+
+	return dirty
+}
+
+func (r *rollback) retry(ctx context.Context, undo func() error) error {
+	err := undo()
+	for i := 0; err != nil && i < r.retries; i++ {
+		t := time.NewTimer(r.backoff)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		err = undo()
+	}
+
+	return err
+}
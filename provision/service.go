@@ -1,6 +1,8 @@
 package provision
 
 import (
+	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 
@@ -11,6 +13,7 @@ import (
 
 const (
 	externalIDKey = "external_id"
+	x509Key       = "x509"
 	gateway       = "gateway"
 	Active        = 1
 
@@ -34,6 +37,8 @@ var (
 	ErrFailedBootstrap          = errors.New("failed to create bootstrap config")
 	ErrFailedBootstrapValidate  = errors.New("failed to validate bootstrap config creation")
 	ErrGatewayUpdate            = errors.New("failed to updated gateway metadata")
+	ErrConflict                 = errors.New("external id already provisioned with different external key")
+	ErrFailedResultRetrieval    = errors.New("failed to retrieve provisioning result")
 )
 
 var _ Service = (*provisionService)(nil)
@@ -46,8 +51,18 @@ type Service interface {
 	// - create multiple Channels
 	// - create Bootstrap configuration
 	// - whitelist Thing in Bootstrap configuration == connect Thing to Channels
+	// If any of the actions fails, the created entities are removed in the
+	// reverse order and the ones that couldn't be removed are reported.
+	// Repeated requests with the same external ID return the result of the
+	// first successful one.
 	Provision(token, name, externalID, externalKey string) (Result, error)
 
+	// ProvisionBulk provisions the entries concurrently, the same way as
+	// Provision does, and returns the results in the order of the entries.
+	// Failure of the single entry doesn't fail the others. Since every entry
+	// is provisioned idempotently, the batch is resumed by sending it again.
+	ProvisionBulk(ctx context.Context, token string, reqs []Request) ([]BulkResult, error)
+
 	// Mapping returns current configuration used for provision
 	// useful for using in ui to create configuration that matches
 	// one created with Provision method.
@@ -62,9 +77,14 @@ type Service interface {
 }
 
 type provisionService struct {
-	logger logger.Logger
-	sdk    SDK.SDK
-	conf   Config
+	logger  logger.Logger
+	sdk     SDK.SDK
+	conf    Config
+	results ResultCache
+	locks   *keyedMutex
+
+	// rollbackSDK is used to remove the entities of failed provisioning.
+	rollbackSDK SDK.SDK
 }
 
 // Result represent what is created with additional info.
@@ -76,14 +96,20 @@ type Result struct {
 	CACert      string            `json:"ca_cert,omitempty"`
 	Whitelisted map[string]bool   `json:"whitelisted,omitempty"`
 	Error       string            `json:"error,omitempty"`
+	// Dirty lists the entities of failed provisioning which couldn't be
+	// removed.
+	Dirty []Entity `json:"dirty,omitempty"`
 }
 
 // New returns new provision service.
-func New(cfg Config, sdk SDK.SDK, logger logger.Logger) Service {
+func New(cfg Config, sdk SDK.SDK, results ResultCache, logger logger.Logger) Service {
 	return &provisionService{
-		logger: logger,
-		conf:   cfg,
-		sdk:    sdk,
+		logger:      logger,
+		conf:        cfg,
+		sdk:         sdk,
+		results:     results,
+		locks:       newKeyedMutex(),
+		rollbackSDK: sdk,
 	}
 }
 
@@ -96,12 +122,41 @@ func (ps *provisionService) Mapping(token string) (map[string]interface{}, error
 }
 
 // Provision is provision method for creating setup according to
-// provision layout specified in config.toml
-func (ps *provisionService) Provision(token, name, externalID, externalKey string) (res Result, err error) {
-	var channels []SDK.Channel
-	var things []SDK.Thing
-	defer ps.recover(&err, &things, &channels, &token)
+// provision layout specified in config.toml. Provisioning is idempotent,
+// so repeated requests with the same external ID replay the result of the
+// first successful one instead of creating the entities again.
+func (ps *provisionService) Provision(token, name, externalID, externalKey string) (Result, error) {
+	unlock := ps.locks.lock(externalID)
+	defer unlock()
+
+	ctx := context.Background()
+	rec, ok, err := ps.results.Retrieve(ctx, externalID)
+	if err != nil {
+		return Result{}, errors.Wrap(ErrFailedResultRetrieval, err)
+	}
+	if ok {
+		// Replaying the result requires only the external key, since the
+		// external key is enough to fetch the same data from bootstrap service.
+		if subtle.ConstantTimeCompare([]byte(rec.ExternalKey), []byte(externalKey)) != 1 {
+			return Result{}, ErrConflict
+		}
+		return rec.Result, nil
+	}
 
+	res, err := ps.provision(token, name, externalID, externalKey)
+	if err != nil {
+		return res, err
+	}
+	// The entities are already created, so the result is returned even if
+	// it couldn't be stored for replaying.
+	if err := ps.results.Save(ctx, externalID, Record{ExternalKey: externalKey, Result: res}); err != nil {
+		ps.logger.Warn(fmt.Sprintf("Failed to store provisioning result of %s: %s", externalID, err))
+	}
+
+	return res, nil
+}
+
+func (ps *provisionService) provision(token, name, externalID, externalKey string) (res Result, err error) {
 	token, err = ps.createTokenIfEmpty(token)
 	if err != nil {
 		return res, err
@@ -113,15 +168,36 @@ func (ps *provisionService) Provision(token, name, externalID, externalKey strin
 	if len(ps.conf.Channels) == 0 {
 		return res, ErrEmptyChannelsList
 	}
+
+	// Entities are removed using the SDK without the request deadline, so
+	// that the rollback is not canceled together with the request.
+	rb := newRollback(ps.conf.Rollback, ps.logger)
+	defer func() {
+		if err == nil {
+			return
+		}
+		if res.Dirty = rb.run(context.Background()); len(res.Dirty) > 0 {
+			err = errors.Wrap(ErrIncompleteRollback, err)
+		}
+	}()
+
+	var channels []SDK.Channel
+	var things []SDK.Thing
 	for _, thing := range ps.conf.Things {
 		// If thing in configs contains metadata with external_id
-		// set value for it from the provision request
-		if _, ok := thing.Metadata[externalIDKey]; ok {
-			thing.Metadata[externalIDKey] = externalID
+		// set value for it from the provision request.
+		// Metadata is copied since the configuration is shared by the
+		// concurrent requests.
+		metadata := make(map[string]interface{}, len(thing.Metadata))
+		for k, v := range thing.Metadata {
+			metadata[k] = v
+		}
+		if _, ok := metadata[externalIDKey]; ok {
+			metadata[externalIDKey] = externalID
 		}
 
 		th := SDK.Thing{
-			Metadata: thing.Metadata,
+			Metadata: metadata,
 		}
 		if name == "" {
 			name = thing.Name
@@ -132,6 +208,9 @@ func (ps *provisionService) Provision(token, name, externalID, externalKey strin
 			res.Error = err.Error()
 			return res, errors.Wrap(ErrFailedThingCreation, err)
 		}
+		rb.add(ThingEntity, thID, func() error {
+			return ps.rollbackSDK.DeleteThing(thID, token)
+		})
 
 		// Get newly created thing (in order to get the key).
 		th, err = ps.sdk.Thing(thID, token)
@@ -147,13 +226,17 @@ func (ps *provisionService) Provision(token, name, externalID, externalKey strin
 			Name:     channel.Name,
 			Metadata: channel.Metadata,
 		}
-		chCreated, err := ps.sdk.CreateChannel(ch, token)
+		chID, err := ps.sdk.CreateChannel(ch, token)
 		if err != nil {
-			return res, err
+			return res, errors.Wrap(ErrFailedChannelCreation, err)
 		}
-		ch, err = ps.sdk.Channel(chCreated, token)
+		rb.add(ChannelEntity, chID, func() error {
+			return ps.rollbackSDK.DeleteChannel(chID, token)
+		})
+
+		ch, err = ps.sdk.Channel(chID, token)
 		if err != nil {
-			e := errors.Wrap(err, fmt.Errorf("channel id: %s", chCreated))
+			e := errors.Wrap(err, fmt.Errorf("channel id: %s", chID))
 			return res, errors.Wrap(ErrFailedChannelRetrieval, e)
 		}
 		channels = append(channels, ch)
@@ -167,7 +250,6 @@ func (ps *provisionService) Provision(token, name, externalID, externalKey strin
 		ClientKey:   map[string]string{},
 	}
 
-	var cert SDK.Cert
 	var bsConfig SDK.BootstrapConfig
 	for _, thing := range things {
 		var chanIDs []string
@@ -175,7 +257,32 @@ func (ps *provisionService) Provision(token, name, externalID, externalKey strin
 		for _, ch := range channels {
 			chanIDs = append(chanIDs, ch.ID)
 		}
-		content, err := json.Marshal(ps.conf.Bootstrap.Content)
+
+		var cert SDK.Cert
+		if ps.conf.Bootstrap.X509Provision {
+			cert, err = ps.sdk.IssueCert(thing.ID, ps.conf.Certs.KeyBits, ps.conf.Certs.KeyType, ps.conf.Certs.HoursValid, token)
+			if err != nil {
+				e := errors.Wrap(ErrFailedCertCreation, errors.Wrap(err, fmt.Errorf("thing id: %s", thing.ID)))
+				if !ps.conf.Bootstrap.X509PartialSuccess {
+					return res, e
+				}
+				// Provision the thing without certificate and report the failure.
+				ps.logger.Warn(fmt.Sprintf("Continue provisioning without certificate: %s", e))
+				res.Error = e.Error()
+				err = nil
+			} else {
+				serial := cert.Serial
+				rb.add(CertEntity, thing.ID, func() error {
+					_, err := ps.rollbackSDK.RevokeCertBySerial(serial, token)
+					return err
+				})
+				res.ClientCert[thing.ID] = cert.ClientCert
+				res.ClientKey[thing.ID] = cert.ClientKey
+				res.CACert = cert.CACert
+			}
+		}
+
+		content, err := bootstrapContent(ps.conf.Bootstrap.Content, cert)
 		if err != nil {
 			return Result{}, errors.Wrap(ErrFailedBootstrap, err)
 		}
@@ -186,42 +293,25 @@ func (ps *provisionService) Provision(token, name, externalID, externalKey strin
 				ExternalID:  externalID,
 				ExternalKey: externalKey,
 				Channels:    chanIDs,
-				CACert:      res.CACert,
+				CACert:      cert.CACert,
 				ClientCert:  cert.ClientCert,
 				ClientKey:   cert.ClientKey,
-				Content:     string(content),
+				Content:     content,
 			}
-			bsid, err := ps.sdk.AddBootstrap(token, bsReq)
+			bsID, err := ps.sdk.AddBootstrap(token, bsReq)
 			if err != nil {
 				return Result{}, errors.Wrap(ErrFailedBootstrap, err)
 			}
+			rb.add(BootstrapEntity, bsID, func() error {
+				return ps.rollbackSDK.RemoveBootstrap(token, bsID)
+			})
 
-			bsConfig, err = ps.sdk.ViewBootstrap(token, bsid)
+			bsConfig, err = ps.sdk.ViewBootstrap(token, bsID)
 			if err != nil {
 				return Result{}, errors.Wrap(ErrFailedBootstrapValidate, err)
 			}
 		}
 
-		if ps.conf.Bootstrap.X509Provision {
-			var cert SDK.Cert
-
-			cert, err = ps.sdk.IssueCert(thing.ID, ps.conf.Certs.KeyBits, ps.conf.Certs.KeyType, ps.conf.Certs.HoursValid, token)
-			if err != nil {
-				e := errors.Wrap(err, fmt.Errorf("thing id: %s", thing.ID))
-				return res, errors.Wrap(ErrFailedCertCreation, e)
-			}
-
-			res.ClientCert[thing.ID] = cert.ClientCert
-			res.ClientKey[thing.ID] = cert.ClientKey
-			res.CACert = cert.CACert
-
-			if needsBootstrap(thing) {
-				if err = ps.sdk.UpdateBootstrapCerts(token, bsConfig.MFThing, cert.ClientCert, cert.ClientKey, cert.CACert); err != nil {
-					return Result{}, errors.Wrap(ErrFailedCertCreation, err)
-				}
-			}
-		}
-
 		if ps.conf.Bootstrap.AutoWhiteList {
 			wlReq := SDK.BootstrapConfig{
 				MFThing: thing.ID,
@@ -229,7 +319,7 @@ func (ps *provisionService) Provision(token, name, externalID, externalKey strin
 			}
 			if err := ps.sdk.Whitelist(token, wlReq); err != nil {
 				res.Error = err.Error()
-				return res, SDK.ErrFailedWhitelist
+				return res, errors.Wrap(SDK.ErrFailedWhitelist, err)
 			}
 			res.Whitelisted[thing.ID] = true
 		}
@@ -318,74 +408,26 @@ func (ps *provisionService) updateGateway(token string, bs SDK.BootstrapConfig,
 	return nil
 }
 
-func (ps *provisionService) errLog(err error) {
-	if err != nil {
-		ps.logger.Error(fmt.Sprintf("Error recovering: %s", err))
-	}
-}
-
-func clean(ps *provisionService, things []SDK.Thing, channels []SDK.Channel, token string) {
-	for _, t := range things {
-		ps.errLog(ps.sdk.DeleteThing(t.ID, token))
-	}
-	for _, c := range channels {
-		ps.errLog(ps.sdk.DeleteChannel(c.ID, token))
-	}
-}
-
-func (ps *provisionService) recover(e *error, ths *[]SDK.Thing, chs *[]SDK.Channel, tkn *string) {
-	things, channels, token, err := *ths, *chs, *tkn, *e
-	if e == nil {
-		return
-	}
-	if errors.Contains(err, ErrFailedThingRetrieval) || errors.Contains(err, ErrFailedChannelCreation) {
-		for _, th := range things {
-			ps.errLog(ps.sdk.DeleteThing(th.ID, token))
-		}
-		return
-	}
-
-	if errors.Contains(err, ErrFailedBootstrap) || errors.Contains(err, ErrFailedChannelRetrieval) {
-		clean(ps, things, channels, token)
-		return
-	}
-
-	if errors.Contains(err, ErrFailedBootstrapValidate) || errors.Contains(err, ErrFailedCertCreation) {
-		clean(ps, things, channels, token)
-		for _, th := range things {
-			if needsBootstrap(th) == true {
-				ps.errLog(ps.sdk.RemoveBootstrap(token, th.ID))
-			}
-
+// bootstrapContent returns the bootstrap config content, including the
+// certificate issued for the thing, if any.
+func bootstrapContent(content map[string]interface{}, cert SDK.Cert) (string, error) {
+	c := make(map[string]interface{}, len(content)+1)
+	for k, v := range content {
+		c[k] = v
+	}
+	if cert.ClientCert != "" {
+		c[x509Key] = map[string]string{
+			"client_cert": cert.ClientCert,
+			"client_key":  cert.ClientKey,
+			"ca_cert":     cert.CACert,
 		}
-		return
 	}
 
-	if errors.Contains(err, ErrFailedBootstrapValidate) || errors.Contains(err, ErrFailedCertCreation) {
-		clean(ps, things, channels, token)
-		for _, th := range things {
-			if needsBootstrap(th) == true {
-				bs, err := ps.sdk.ViewBootstrap(token, th.ID)
-				ps.errLog(errors.Wrap(ErrFailedBootstrapRetrieval, err))
-				ps.errLog(ps.sdk.RemoveBootstrap(token, bs.MFThing))
-			}
-		}
-	}
-
-	if errors.Contains(err, SDK.ErrFailedWhitelist) || errors.Contains(err, ErrGatewayUpdate) {
-		clean(ps, things, channels, token)
-		for _, th := range things {
-			if ps.conf.Bootstrap.X509Provision && needsBootstrap(th) {
-				ps.errLog(ps.sdk.RemoveCert(th.ID, token))
-			}
-			if needsBootstrap(th) == true {
-				bs, err := ps.sdk.ViewBootstrap(token, th.ID)
-				ps.errLog(errors.Wrap(ErrFailedBootstrapRetrieval, err))
-				ps.errLog(ps.sdk.RemoveBootstrap(token, bs.MFThing))
-			}
-		}
-		return
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
 	}
+	return string(b), nil
 }
 
 func needsBootstrap(th SDK.Thing) bool {
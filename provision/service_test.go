@@ -0,0 +1,472 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package provision_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	SDK "github.com/mainflux/mainflux/pkg/sdk/go"
+	"github.com/mainflux/mainflux/provision"
+	"github.com/mainflux/mainflux/provision/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	token       = "token"
+	externalID  = "11:22:33:44:55:66"
+	externalKey = "key12345678"
+)
+
+var errService = errors.New("service unavailable")
+
+func newConfig() provision.Config {
+	return provision.Config{
+		Bootstrap: provision.Bootstrap{
+			Provision:     true,
+			X509Provision: true,
+			AutoWhiteList: true,
+			Content:       map[string]interface{}{"agent": map[string]interface{}{"interval": 10}},
+		},
+		Things: []provision.Thing{
+			{
+				Name:     "gateway",
+				Metadata: map[string]interface{}{"external_id": ""},
+			},
+		},
+		Channels: []provision.Channel{
+			{
+				Name:     "control-channel",
+				Metadata: map[string]interface{}{"type": "control"},
+			},
+			{
+				Name:     "data-channel",
+				Metadata: map[string]interface{}{"type": "data"},
+			},
+		},
+		Certs: provision.Certs{
+			HoursValid: "2400h",
+			KeyBits:    2048,
+		},
+	}
+}
+
+func newService(cfg provision.Config, sdk *mocks.SDKMock) provision.Service {
+	logger, _ := logger.New(os.Stdout, logger.Error.String())
+	return provision.New(cfg, sdk, mocks.NewResultCache(time.Hour), logger)
+}
+
+func TestProvisionCerts(t *testing.T) {
+	sdk := mocks.NewSDK()
+	svc := newService(newConfig(), sdk)
+
+	res, err := svc.Provision(token, "", externalID, externalKey)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	require.Len(t, res.Things, 1, "expected single thing to be provisioned")
+	th := res.Things[0]
+
+	st := sdk.State()
+	cert := st.Certs[th.ID]
+	assert.Equal(t, cert.ClientCert, res.ClientCert[th.ID], fmt.Sprintf("expected client cert %s got %s", cert.ClientCert, res.ClientCert[th.ID]))
+	assert.Equal(t, cert.ClientKey, res.ClientKey[th.ID], fmt.Sprintf("expected client key %s got %s", cert.ClientKey, res.ClientKey[th.ID]))
+	assert.Equal(t, cert.CACert, res.CACert, fmt.Sprintf("expected CA cert %s got %s", cert.CACert, res.CACert))
+
+	bs, ok := st.Bootstraps[th.ID]
+	require.True(t, ok, "expected bootstrap config to be created")
+	assert.Equal(t, cert.ClientCert, bs.ClientCert, fmt.Sprintf("expected bootstrap client cert %s got %s", cert.ClientCert, bs.ClientCert))
+	assert.Equal(t, cert.ClientKey, bs.ClientKey, fmt.Sprintf("expected bootstrap client key %s got %s", cert.ClientKey, bs.ClientKey))
+	assert.Equal(t, cert.CACert, bs.CACert, fmt.Sprintf("expected bootstrap CA cert %s got %s", cert.CACert, bs.CACert))
+
+	var content map[string]interface{}
+	err = json.Unmarshal([]byte(bs.Content), &content)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	expected := map[string]interface{}{
+		"agent": map[string]interface{}{"interval": float64(10)},
+		"x509": map[string]interface{}{
+			"client_cert": cert.ClientCert,
+			"client_key":  cert.ClientKey,
+			"ca_cert":     cert.CACert,
+		},
+	}
+	assert.Equal(t, expected, content, fmt.Sprintf("expected bootstrap content %v got %v", expected, content))
+}
+
+func TestProvisionCertsFailure(t *testing.T) {
+	cases := []struct {
+		desc    string
+		partial bool
+		method  string
+		err     error
+		clean   bool
+	}{
+		{
+			desc:   "roll back provisioning when certificate issuing fails",
+			method: "IssueCert",
+			err:    provision.ErrFailedCertCreation,
+			clean:  true,
+		},
+		{
+			desc:   "roll back issued certificate when bootstrap config creation fails",
+			method: "AddBootstrap",
+			err:    provision.ErrFailedBootstrap,
+			clean:  true,
+		},
+		{
+			desc:    "provision without certificate when partial success is allowed",
+			partial: true,
+			method:  "IssueCert",
+		},
+	}
+
+	for _, tc := range cases {
+		cfg := newConfig()
+		cfg.Bootstrap.X509PartialSuccess = tc.partial
+		sdk := mocks.NewSDK()
+		sdk.Fail(tc.method, errService)
+		svc := newService(cfg, sdk)
+
+		res, err := svc.Provision(token, "", externalID, externalKey)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+
+		st := sdk.State()
+		if tc.clean {
+			assert.Empty(t, st.Things, fmt.Sprintf("%s: expected things to be removed got %v", tc.desc, st.Things))
+			assert.Empty(t, st.Channels, fmt.Sprintf("%s: expected channels to be removed got %v", tc.desc, st.Channels))
+			assert.Empty(t, st.Bootstraps, fmt.Sprintf("%s: expected bootstrap configs to be removed got %v", tc.desc, st.Bootstraps))
+			assert.Empty(t, st.Certs, fmt.Sprintf("%s: expected certificates to be removed got %v", tc.desc, st.Certs))
+			continue
+		}
+
+		require.Len(t, res.Things, 1, fmt.Sprintf("%s: expected single thing to be provisioned", tc.desc))
+		th := res.Things[0]
+		assert.NotEmpty(t, res.Error, fmt.Sprintf("%s: expected certificate failure to be reported", tc.desc))
+		assert.Empty(t, res.ClientCert, fmt.Sprintf("%s: expected no client cert got %v", tc.desc, res.ClientCert))
+		bs, ok := st.Bootstraps[th.ID]
+		assert.True(t, ok, fmt.Sprintf("%s: expected bootstrap config to be created", tc.desc))
+		assert.Empty(t, bs.ClientCert, fmt.Sprintf("%s: expected bootstrap config without certificate got %s", tc.desc, bs.ClientCert))
+		assert.NotContains(t, bs.Content, "x509", fmt.Sprintf("%s: expected bootstrap content without certificate got %s", tc.desc, bs.Content))
+	}
+}
+
+func TestProvisionIdempotent(t *testing.T) {
+	sdk := mocks.NewSDK()
+	svc := newService(newConfig(), sdk)
+
+	var wg sync.WaitGroup
+	results := make([]provision.Result, 2)
+	errs := make([]error, 2)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = svc.Provision(token, "", externalID, externalKey)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+	assert.Equal(t, results[0], results[1], fmt.Sprintf("expected identical responses got %v and %v", results[0], results[1]))
+
+	st := sdk.State()
+	assert.Len(t, st.Things, 1, fmt.Sprintf("expected single thing got %v", st.Things))
+	assert.Len(t, st.Channels, 2, fmt.Sprintf("expected single set of channels got %v", st.Channels))
+	assert.Len(t, st.Bootstraps, 1, fmt.Sprintf("expected single bootstrap config got %v", st.Bootstraps))
+	assert.Len(t, st.Certs, 1, fmt.Sprintf("expected single certificate got %v", st.Certs))
+
+	cases := []struct {
+		desc        string
+		externalID  string
+		externalKey string
+		err         error
+		replayed    bool
+	}{
+		{
+			desc:        "replay provisioning with the same external ID",
+			externalID:  externalID,
+			externalKey: externalKey,
+			replayed:    true,
+		},
+		{
+			desc:        "replay provisioning with different external key",
+			externalID:  externalID,
+			externalKey: "wrong",
+			err:         provision.ErrConflict,
+		},
+		{
+			desc:        "provision different external ID",
+			externalID:  "66:55:44:33:22:11",
+			externalKey: externalKey,
+		},
+	}
+
+	for _, tc := range cases {
+		res, err := svc.Provision(token, "", tc.externalID, tc.externalKey)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		if tc.replayed {
+			assert.Equal(t, results[0], res, fmt.Sprintf("%s: expected replayed response %v got %v", tc.desc, results[0], res))
+			continue
+		}
+		assert.NotEqual(t, results[0].Things, res.Things, fmt.Sprintf("%s: expected new things got %v", tc.desc, res.Things))
+	}
+}
+
+func TestProvisionRetryAfterFailure(t *testing.T) {
+	sdk := mocks.NewSDK()
+	svc := newService(newConfig(), sdk)
+
+	sdk.Fail("AddBootstrap", errService)
+	_, err := svc.Provision(token, "", externalID, externalKey)
+	assert.True(t, errors.Contains(err, provision.ErrFailedBootstrap), fmt.Sprintf("expected %s got %s", provision.ErrFailedBootstrap, err))
+
+	sdk.Fail("AddBootstrap", nil)
+	res, err := svc.Provision(token, "", externalID, externalKey)
+	assert.Nil(t, err, fmt.Sprintf("expected failed provisioning not to be replayed got %s", err))
+	assert.Len(t, res.Things, 1, fmt.Sprintf("expected thing to be provisioned got %v", res.Things))
+}
+
+func TestProvisionBulk(t *testing.T) {
+	cfg := newConfig()
+	cfg.Bulk = provision.Bulk{MaxSize: 10, Workers: 3, Timeout: time.Minute}
+	sdk := mocks.NewSDK()
+	sdk.RejectThing("rejected", errService)
+	svc := newService(cfg, sdk)
+
+	var reqs []provision.Request
+	for i := 0; i < 6; i++ {
+		name := "accepted"
+		if i%2 == 1 {
+			name = "rejected"
+		}
+		reqs = append(reqs, provision.Request{
+			Name:        name,
+			ExternalID:  fmt.Sprintf("external-id-%d", i),
+			ExternalKey: fmt.Sprintf("external-key-%d", i),
+		})
+	}
+
+	results, err := svc.ProvisionBulk(context.Background(), token, reqs)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	require.Len(t, results, len(reqs), fmt.Sprintf("expected %d results got %d", len(reqs), len(results)))
+
+	keys := map[string]bool{}
+	for i, res := range results {
+		assert.Equal(t, reqs[i].ExternalID, res.ExternalID, fmt.Sprintf("expected result for %s got %s", reqs[i].ExternalID, res.ExternalID))
+		if reqs[i].Name == "rejected" {
+			assert.True(t, errors.Contains(res.Err, provision.ErrFailedThingCreation), fmt.Sprintf("%s: expected %s got %s", res.ExternalID, provision.ErrFailedThingCreation, res.Err))
+			continue
+		}
+		require.Nil(t, res.Err, fmt.Sprintf("%s: unexpected error: %s", res.ExternalID, res.Err))
+		require.Len(t, res.Result.Things, 1, fmt.Sprintf("%s: expected single thing", res.ExternalID))
+		th := res.Result.Things[0]
+		assert.Equal(t, reqs[i].ExternalID, th.Metadata["external_id"], fmt.Sprintf("%s: expected thing external id %s got %v", res.ExternalID, reqs[i].ExternalID, th.Metadata["external_id"]))
+		assert.NotEmpty(t, res.Result.ClientCert[th.ID], fmt.Sprintf("%s: expected client cert", res.ExternalID))
+		keys[th.Key] = true
+	}
+	assert.Len(t, keys, 3, fmt.Sprintf("expected distinct credentials for accepted entries got %v", keys))
+	assert.Len(t, sdk.State().Things, 3, fmt.Sprintf("expected things of accepted entries only got %v", sdk.State().Things))
+
+	// Resubmit the batch once the things service accepts all the entries.
+	sdk.RejectThing("rejected", nil)
+	resumed, err := svc.ProvisionBulk(context.Background(), token, reqs)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	for i, res := range resumed {
+		assert.Nil(t, res.Err, fmt.Sprintf("%s: unexpected error: %s", res.ExternalID, res.Err))
+		if reqs[i].Name == "accepted" {
+			assert.Equal(t, results[i].Result, res.Result, fmt.Sprintf("%s: expected replayed result %v got %v", res.ExternalID, results[i].Result, res.Result))
+		}
+	}
+	assert.Len(t, sdk.State().Things, len(reqs), fmt.Sprintf("expected single thing per entry got %v", sdk.State().Things))
+}
+
+func TestProvisionBulkLimits(t *testing.T) {
+	reqs := []provision.Request{
+		{ExternalID: "external-id-1", ExternalKey: externalKey},
+		{ExternalID: "external-id-2", ExternalKey: externalKey},
+	}
+
+	cases := []struct {
+		desc    string
+		bulk    provision.Bulk
+		err     error
+		itemErr error
+	}{
+		{
+			desc: "provision batch exceeding max size",
+			bulk: provision.Bulk{MaxSize: 1, Workers: 1},
+			err:  provision.ErrBatchSize,
+		},
+		{
+			desc:    "provision batch after deadline",
+			bulk:    provision.Bulk{MaxSize: 10, Workers: 1, Timeout: time.Nanosecond},
+			itemErr: provision.ErrBulkDeadline,
+		},
+	}
+
+	for _, tc := range cases {
+		cfg := newConfig()
+		cfg.Bulk = tc.bulk
+		sdk := mocks.NewSDK()
+		svc := newService(cfg, sdk)
+
+		results, err := svc.ProvisionBulk(context.Background(), token, reqs)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		for _, res := range results {
+			assert.True(t, errors.Contains(res.Err, tc.itemErr), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.itemErr, res.Err))
+		}
+		assert.Empty(t, sdk.State().Things, fmt.Sprintf("%s: expected no things got %v", tc.desc, sdk.State().Things))
+	}
+}
+
+func TestProvisionRollback(t *testing.T) {
+	cases := []struct {
+		desc   string
+		method string
+		err    error
+	}{
+		{
+			desc:   "roll back when thing creation fails",
+			method: "CreateThing",
+			err:    provision.ErrFailedThingCreation,
+		},
+		{
+			desc:   "roll back when thing retrieval fails",
+			method: "Thing",
+			err:    provision.ErrFailedThingRetrieval,
+		},
+		{
+			desc:   "roll back when channel creation fails",
+			method: "CreateChannel",
+			err:    provision.ErrFailedChannelCreation,
+		},
+		{
+			desc:   "roll back when channel retrieval fails",
+			method: "Channel",
+			err:    provision.ErrFailedChannelRetrieval,
+		},
+		{
+			desc:   "roll back when certificate issuing fails",
+			method: "IssueCert",
+			err:    provision.ErrFailedCertCreation,
+		},
+		{
+			desc:   "roll back when bootstrap config creation fails",
+			method: "AddBootstrap",
+			err:    provision.ErrFailedBootstrap,
+		},
+		{
+			desc:   "roll back when bootstrap config retrieval fails",
+			method: "ViewBootstrap",
+			err:    provision.ErrFailedBootstrapValidate,
+		},
+		{
+			desc:   "roll back when whitelisting fails",
+			method: "Whitelist",
+			err:    SDK.ErrFailedWhitelist,
+		},
+		{
+			desc:   "roll back when gateway update fails",
+			method: "UpdateThing",
+			err:    provision.ErrGatewayUpdate,
+		},
+	}
+
+	for _, tc := range cases {
+		sdk := mocks.NewSDK()
+		sdk.Fail(tc.method, errService)
+		svc := newService(newConfig(), sdk)
+
+		res, err := svc.Provision(token, "", externalID, externalKey)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+		assert.False(t, errors.Contains(err, provision.ErrIncompleteRollback), fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Empty(t, res.Dirty, fmt.Sprintf("%s: expected no dirty entities got %v", tc.desc, res.Dirty))
+
+		st := sdk.State()
+		assert.Empty(t, st.Things, fmt.Sprintf("%s: expected things to be removed got %v", tc.desc, st.Things))
+		assert.Empty(t, st.Channels, fmt.Sprintf("%s: expected channels to be removed got %v", tc.desc, st.Channels))
+		assert.Empty(t, st.Bootstraps, fmt.Sprintf("%s: expected bootstrap configs to be removed got %v", tc.desc, st.Bootstraps))
+		assert.Empty(t, st.Certs, fmt.Sprintf("%s: expected certificates to be removed got %v", tc.desc, st.Certs))
+	}
+}
+
+func TestProvisionRollbackFailure(t *testing.T) {
+	cases := []struct {
+		desc     string
+		method   string
+		undo     string
+		times    int
+		err      error
+		dirty    []string
+		channels int
+		bs       int
+	}{
+		{
+			desc:   "retry removal of channels",
+			method: "AddBootstrap",
+			undo:   "DeleteChannel",
+			times:  2,
+			err:    provision.ErrFailedBootstrap,
+		},
+		{
+			desc:     "report channels which couldn't be removed",
+			method:   "AddBootstrap",
+			undo:     "DeleteChannel",
+			err:      provision.ErrIncompleteRollback,
+			dirty:    []string{provision.ChannelEntity, provision.ChannelEntity},
+			channels: 2,
+		},
+		{
+			desc:   "report bootstrap config which couldn't be removed",
+			method: "Whitelist",
+			undo:   "RemoveBootstrap",
+			err:    provision.ErrIncompleteRollback,
+			dirty:  []string{provision.BootstrapEntity},
+			bs:     1,
+		},
+	}
+
+	for _, tc := range cases {
+		cfg := newConfig()
+		cfg.Rollback = provision.Rollback{Retries: 2}
+		sdk := mocks.NewSDK()
+		sdk.Fail(tc.method, errService)
+		sdk.FailTimes(tc.undo, errService, tc.times)
+		svc := newService(cfg, sdk)
+
+		res, err := svc.Provision(token, "", externalID, externalKey)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s", tc.desc, tc.err, err))
+
+		var dirty []string
+		for _, e := range res.Dirty {
+			dirty = append(dirty, e.Type)
+		}
+		assert.Equal(t, tc.dirty, dirty, fmt.Sprintf("%s: expected dirty entities %v got %v", tc.desc, tc.dirty, res.Dirty))
+
+		st := sdk.State()
+		assert.Empty(t, st.Things, fmt.Sprintf("%s: expected things to be removed got %v", tc.desc, st.Things))
+		assert.Empty(t, st.Certs, fmt.Sprintf("%s: expected certificates to be removed got %v", tc.desc, st.Certs))
+		assert.Len(t, st.Channels, tc.channels, fmt.Sprintf("%s: expected %d channels got %v", tc.desc, tc.channels, st.Channels))
+		assert.Len(t, st.Bootstraps, tc.bs, fmt.Sprintf("%s: expected %d bootstrap configs got %v", tc.desc, tc.bs, st.Bootstraps))
+		for _, e := range res.Dirty {
+			switch e.Type {
+			case provision.ChannelEntity:
+				assert.Contains(t, st.Channels, e.ID, fmt.Sprintf("%s: expected dirty channel %s to exist", tc.desc, e.ID))
+			case provision.BootstrapEntity:
+				assert.Contains(t, st.Bootstraps, e.ID, fmt.Sprintf("%s: expected dirty bootstrap config %s to exist", tc.desc, e.ID))
+			}
+		}
+	}
+}
@@ -7,26 +7,29 @@ import (
 	"context"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/mainflux/mainflux/internal/httputil"
 	"github.com/mainflux/mainflux/readers"
 )
 
 func listMessagesEndpoint(svc readers.MessageRepository) endpoint.Endpoint {
-	return func(_ context.Context, request interface{}) (interface{}, error) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listMessagesReq)
 
 		if err := req.validate(); err != nil {
 			return nil, err
 		}
 
-		page, err := svc.ReadAll(req.chanID, req.pageMeta)
+		page, err := svc.ReadAll(ctx, req.chanID, req.pageMeta)
 		if err != nil {
 			return nil, err
 		}
 
 		return pageRes{
 			PageMetadata: page.PageMetadata,
+			Links:        httputil.PageLinks(ctx, page.Total, page.Offset, page.Limit),
 			Total:        page.Total,
 			Messages:     page.Messages,
+			accept:       req.accept,
 		}, nil
 	}
 }
@@ -7,17 +7,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/mainflux/mainflux/readers"
 	"github.com/mainflux/mainflux/readers/api"
 	"github.com/mainflux/mainflux/readers/mocks"
+	"github.com/mainflux/mainflux/readers/tracing"
+	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +29,10 @@ import (
 const (
 	svcName       = "test-service"
 	token         = "1"
+	adminToken    = "admin-token"
+	userToken     = "user-token"
+	adminEmail    = "admin@example.com"
+	tenant        = "tenant"
 	invalid       = "invalid"
 	numOfMessages = 100
 	valueFields   = 5
@@ -42,10 +50,14 @@ var (
 	sum float64 = 42
 
 	idProvider = uuid.New()
+	admin      = api.Admin{
+		Auth:  mocks.NewAuthService(map[string]string{adminToken: adminEmail, userToken: "user@example.com"}),
+		Email: adminEmail,
+	}
 )
 
 func newServer(repo readers.MessageRepository, tc mainflux.ThingsServiceClient) *httptest.Server {
-	mux := api.MakeHandler(repo, tc, svcName)
+	mux := api.MakeHandler(repo, mocktracer.New(), tc, admin, svcName)
 	return httptest.NewServer(mux)
 }
 
@@ -54,6 +66,7 @@ type testRequest struct {
 	method string
 	url    string
 	token  string
+	accept string
 	body   io.Reader
 }
 
@@ -65,6 +78,9 @@ func (tr testRequest) make() (*http.Response, error) {
 	if tr.token != "" {
 		req.Header.Set("Authorization", tr.token)
 	}
+	if tr.accept != "" {
+		req.Header.Set("Accept", tr.accept)
+	}
 
 	return tr.client.Do(req)
 }
@@ -85,6 +101,7 @@ func TestReadAll(t *testing.T) {
 	var boolMsgs []senml.Message
 	var stringMsgs []senml.Message
 	var dataMsgs []senml.Message
+	var defTenantMsgs []senml.Message
 
 	for i := 0; i < numOfMessages; i++ {
 		// Mix possible values as well as value sum.
@@ -92,6 +109,7 @@ func TestReadAll(t *testing.T) {
 			Channel:   chanID,
 			Publisher: pubID,
 			Protocol:  mqttProt,
+			Tenant:    messaging.DefaultTenant,
 			Time:      float64(now - int64(i)),
 			Name:      "name",
 		}
@@ -116,8 +134,12 @@ func TestReadAll(t *testing.T) {
 			msg.Protocol = httpProt
 			msg.Publisher = pubID2
 			msg.Name = msgName
+			msg.Tenant = tenant
 			queryMsgs = append(queryMsgs, msg)
 		}
+		if msg.Tenant == messaging.DefaultTenant {
+			defTenantMsgs = append(defTenantMsgs, msg)
+		}
 
 		messages = append(messages, msg)
 	}
@@ -406,6 +428,44 @@ func TestReadAll(t *testing.T) {
 			token:  token,
 			status: http.StatusBadRequest,
 		},
+		{
+			desc:   "read page with tenant using admin token",
+			url:    fmt.Sprintf("%s/channels/%s/messages?offset=0&limit=10&tenant=%s", ts.URL, chanID, tenant),
+			token:  adminToken,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    uint64(len(queryMsgs)),
+				Messages: queryMsgs[0:10],
+			},
+		},
+		{
+			desc:   "read page with default tenant using admin token",
+			url:    fmt.Sprintf("%s/channels/%s/messages?offset=0&limit=10&tenant=%s", ts.URL, chanID, messaging.DefaultTenant),
+			token:  adminToken,
+			status: http.StatusOK,
+			res: pageRes{
+				Total:    uint64(len(defTenantMsgs)),
+				Messages: defTenantMsgs[0:10],
+			},
+		},
+		{
+			desc:   "read page with tenant using thing key",
+			url:    fmt.Sprintf("%s/channels/%s/messages?offset=0&limit=10&tenant=%s", ts.URL, chanID, tenant),
+			token:  token,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "read page with tenant using non-admin user token",
+			url:    fmt.Sprintf("%s/channels/%s/messages?offset=0&limit=10&tenant=%s", ts.URL, chanID, tenant),
+			token:  userToken,
+			status: http.StatusForbidden,
+		},
+		{
+			desc:   "read page with multiple tenant",
+			url:    fmt.Sprintf("%s/channels/%s/messages?offset=0&limit=10&tenant=%s&tenant=%s", ts.URL, chanID, tenant, tenant),
+			token:  adminToken,
+			status: http.StatusBadRequest,
+		},
 		{
 			desc:   "read page with from/to",
 			url:    fmt.Sprintf("%s/channels/%s/messages?from=%f&to=%f", ts.URL, chanID, messages[19].Time, messages[4].Time),
@@ -437,6 +497,264 @@ func TestReadAll(t *testing.T) {
 	}
 }
 
+func TestReadAllTracing(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	var low float64 = 1
+	messages := fromSenml([]senml.Message{
+		{Channel: chanID, Name: msgName, Value: &v},
+		{Channel: chanID, Name: msgName, Value: &v},
+		{Channel: chanID, Name: msgName, Value: &low},
+		{Channel: chanID, Name: "name", Value: &v},
+	})
+
+	cases := []struct {
+		desc   string
+		token  string
+		status int
+		ops    []string
+		tags   map[string]interface{}
+	}{
+		{
+			desc:   "trace filtered query",
+			token:  token,
+			status: http.StatusOK,
+			ops:    []string{"authorize", "read_all", "encode_response"},
+			tags: map[string]interface{}{
+				"channel_id": chanID,
+				"filter":     fmt.Sprintf("name=%s,v>=%v", msgName, v),
+				"rows":       2,
+				"total":      uint64(2),
+			},
+		},
+		{
+			desc:   "trace unauthorized query",
+			token:  invalid,
+			status: http.StatusForbidden,
+			ops:    []string{"authorize"},
+		},
+	}
+
+	for _, tc := range cases {
+		tracer := mocktracer.New()
+		repo := tracing.MessageRepositoryMiddleware(tracer, mocks.NewMessageRepository(chanID, messages))
+		ts := httptest.NewServer(api.MakeHandler(repo, tracer, mocks.NewThingsService(), admin, svcName))
+
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/%s/messages?name=%s&v=%v&comparator=%s", ts.URL, chanID, msgName, v, readers.GreaterThanEqualKey),
+			token:  tc.token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		ts.Close()
+
+		spans := map[string]*mocktracer.MockSpan{}
+		for _, span := range tracer.FinishedSpans() {
+			spans[span.OperationName] = span
+		}
+		require.Len(t, spans, len(tc.ops)+1, fmt.Sprintf("%s: expected %d spans got %d", tc.desc, len(tc.ops)+1, len(spans)))
+
+		root, ok := spans["list_messages"]
+		require.True(t, ok, fmt.Sprintf("%s: expected request span", tc.desc))
+		assert.Equal(t, 0, root.ParentID, fmt.Sprintf("%s: expected root request span got parent %d", tc.desc, root.ParentID))
+		assert.Equal(t, uint16(tc.status), root.Tag("http.status_code"), fmt.Sprintf("%s: expected status tag %d got %v", tc.desc, tc.status, root.Tag("http.status_code")))
+
+		for _, op := range tc.ops {
+			span, ok := spans[op]
+			require.True(t, ok, fmt.Sprintf("%s: expected %s span", tc.desc, op))
+			assert.Equal(t, root.SpanContext.SpanID, span.ParentID, fmt.Sprintf("%s: expected %s span child of request span", tc.desc, op))
+		}
+
+		for k, val := range tc.tags {
+			tag := spans["read_all"].Tag(k)
+			assert.Equal(t, val, tag, fmt.Sprintf("%s: expected tag %s %v got %v", tc.desc, k, val, tag))
+		}
+	}
+}
+
+func TestReadAllSenML(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	pack := `[{"bn":"urn:dev:ow:10e2073a01080063:","bt":1.276020076001e+09,"bu":"A","n":"voltage","u":"V","v":120.1},
+{"n":"current","t":-5,"v":1.2},{"n":"current","t":-4,"v":1.3},{"n":"door","t":-3,"vb":true},
+{"n":"label","t":-2,"vs":"kitchen"},{"n":"raw","t":-1,"vd":"3q2-7w"},{"n":"energy","u":"J","s":5}]`
+	msg := messaging.Message{
+		Channel:   chanID,
+		Publisher: pubID,
+		Protocol:  httpProt,
+		Payload:   []byte(pack),
+	}
+	published, err := senml.New(senml.JSON).Transform(msg)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	messages := published.([]senml.Message)
+
+	ts := newServer(mocks.NewMessageRepository(chanID, fromSenml(messages)), mocks.NewThingsService())
+	defer ts.Close()
+
+	cases := []struct {
+		desc        string
+		accept      string
+		format      string
+		status      int
+		contentType string
+	}{
+		{
+			desc:        "read messages as SenML JSON pack",
+			accept:      senml.JSON,
+			status:      http.StatusOK,
+			contentType: senml.JSON,
+		},
+		{
+			desc:        "read messages as SenML CBOR pack",
+			accept:      senml.CBOR,
+			status:      http.StatusOK,
+			contentType: senml.CBOR,
+		},
+		{
+			desc:        "read messages as preferred SenML pack",
+			accept:      fmt.Sprintf("%s;q=0.5, %s", senml.CBOR, senml.JSON),
+			status:      http.StatusOK,
+			contentType: senml.JSON,
+		},
+		{
+			desc:        "read messages accepting any content type",
+			accept:      "*/*",
+			status:      http.StatusOK,
+			contentType: "application/json",
+		},
+		{
+			desc:   "read messages with unsupported content type",
+			accept: "text/plain",
+			status: http.StatusNotAcceptable,
+		},
+		{
+			desc:   "read messages with rejected content type",
+			accept: fmt.Sprintf("%s;q=0", senml.JSON),
+			status: http.StatusNotAcceptable,
+		},
+		{
+			desc:   "read JSON messages as SenML pack",
+			accept: senml.JSON,
+			format: "json",
+			status: http.StatusNotAcceptable,
+		},
+	}
+
+	for _, tc := range cases {
+		url := fmt.Sprintf("%s/channels/%s/messages?limit=%d", ts.URL, chanID, len(messages))
+		if tc.format != "" {
+			url = fmt.Sprintf("%s&format=%s", url, tc.format)
+		}
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    url,
+			token:  token,
+			accept: tc.accept,
+		}
+		res, err := req.make()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusOK {
+			continue
+		}
+		ct := res.Header.Get("Content-Type")
+		assert.Equal(t, tc.contentType, ct, fmt.Sprintf("%s: expected content type %s got %s", tc.desc, tc.contentType, ct))
+		if ct == "application/json" {
+			continue
+		}
+
+		// The pack read back resolves to the published records.
+		body, err := ioutil.ReadAll(res.Body)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		msg.Payload = body
+		read, err := senml.New(ct).Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.ElementsMatch(t, messages, read, fmt.Sprintf("%s: expected %v got %v", tc.desc, messages, read))
+	}
+}
+
+func TestReadAllWaitFor(t *testing.T) {
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	msgID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	missingID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	now := time.Now().UnixNano()
+	messages := []senml.Message{{Channel: chanID, MessageID: msgID, Name: msgName, Value: &v, Time: float64(now) / 1e9}}
+	stored := messaging.Token{ID: msgID, Created: now}.String()
+	missing := messaging.Token{ID: missingID, Created: now}.String()
+
+	ts := newServer(mocks.NewMessageRepository(chanID, fromSenml(messages)), mocks.NewThingsService())
+	defer ts.Close()
+
+	cases := []struct {
+		desc   string
+		query  string
+		status int
+		res    pageRes
+	}{
+		{
+			desc:   "read page waiting for stored message",
+			query:  fmt.Sprintf("wait_for=%s", stored),
+			status: http.StatusOK,
+			res:    pageRes{Total: 1, Messages: messages},
+		},
+		{
+			desc:   "read page waiting for stored message with timeout",
+			query:  fmt.Sprintf("wait_for=%s&timeout=500ms", stored),
+			status: http.StatusOK,
+			res:    pageRes{Total: 1, Messages: messages},
+		},
+		{
+			desc:   "read page waiting for message not stored",
+			query:  fmt.Sprintf("wait_for=%s&timeout=100ms", missing),
+			status: http.StatusGatewayTimeout,
+		},
+		{
+			desc:   "read page waiting for malformed token",
+			query:  fmt.Sprintf("wait_for=%s", invalid),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page waiting with invalid timeout",
+			query:  fmt.Sprintf("wait_for=%s&timeout=%s", stored, invalid),
+			status: http.StatusBadRequest,
+		},
+		{
+			desc:   "read page waiting with negative timeout",
+			query:  fmt.Sprintf("wait_for=%s&timeout=-1s", stored),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/%s/messages?%s", ts.URL, chanID, tc.query),
+			token:  token,
+		}
+		res, err := req.make()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+
+		var page pageRes
+		json.NewDecoder(res.Body).Decode(&page)
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res.Total, page.Total, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.res.Total, page.Total))
+		assert.ElementsMatch(t, tc.res.Messages, page.Messages, fmt.Sprintf("%s: expected body %v got %v", tc.desc, tc.res.Messages, page.Messages))
+	}
+}
+
 type pageRes struct {
 	readers.PageMetadata
 	Total    uint64          `json:"total"`
@@ -14,6 +14,7 @@ type apiReq interface {
 
 type listMessagesReq struct {
 	chanID   string
+	accept   string
 	pageMeta readers.PageMetadata
 }
 
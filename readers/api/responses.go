@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/httputil"
 	"github.com/mainflux/mainflux/readers"
 )
 
@@ -14,12 +15,16 @@ var _ mainflux.Response = (*pageRes)(nil)
 
 type pageRes struct {
 	readers.PageMetadata
+	httputil.Links
 	Total    uint64            `json:"total"`
 	Messages []readers.Message `json:"messages,omitempty"`
+
+	// accept is the content type the page is encoded as.
+	accept string
 }
 
 func (res pageRes) Headers() map[string]string {
-	return map[string]string{}
+	return res.Links.Headers()
 }
 
 func (res pageRes) Code() int {
@@ -8,14 +8,21 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	kitlog "github.com/go-kit/kit/log"
+	kitot "github.com/go-kit/kit/tracing/opentracing"
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/internal/httputil"
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/readers"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -29,6 +36,7 @@ const (
 	subtopicKey    = "subtopic"
 	publisherKey   = "publisher"
 	protocolKey    = "protocol"
+	tenantKey      = "tenant"
 	nameKey        = "name"
 	valueKey       = "v"
 	stringValueKey = "vs"
@@ -36,29 +44,64 @@ const (
 	comparatorKey  = "comparator"
 	fromKey        = "from"
 	toKey          = "to"
+	waitForKey     = "wait_for"
+	timeoutKey     = "timeout"
 	defLimit       = 10
 	defOffset      = 0
 	defFormat      = "messages"
+	defTimeout     = 2 * time.Second
+	maxTimeout     = 10 * time.Second
+
+	listMessagesOp   = "list_messages"
+	authorizeOp      = "authorize"
+	encodeResponseOp = "encode_response"
 )
 
 var (
 	errUnauthorizedAccess = errors.New("missing or invalid credentials provided")
-	auth                  mainflux.ThingsServiceClient
+	errNotAcceptable      = errors.New("unsupported accepted content type")
+	errEncodeSenML        = errors.New("failed to encode messages as senml")
 )
 
-// MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler(svc readers.MessageRepository, tc mainflux.ThingsServiceClient, svcName string) http.Handler {
-	auth = tc
+// Admin identifies the reader admin, the only user allowed to filter the
+// messages by tenant.
+type Admin struct {
+	// Auth is the auth service client identifying the users.
+	Auth mainflux.AuthServiceClient
+
+	// Email is the email of the admin user. Empty email disables the
+	// filtering by tenant.
+	Email string
+}
+
+type authorizer struct {
+	things mainflux.ThingsServiceClient
+	admin  Admin
+	tracer opentracing.Tracer
+}
+
+// MakeHandler returns a HTTP handler for API endpoints. The messages are read
+// using the thing key granting access to the channel, while the tenant filter
+// requires the token of the admin user instead. The span of the request
+// covers the authorization, the repository query and the response encoding.
+func MakeHandler(svc readers.MessageRepository, tracer opentracing.Tracer, tc mainflux.ThingsServiceClient, admin Admin, svcName string) http.Handler {
+	az := authorizer{
+		things: tc,
+		admin:  admin,
+		tracer: tracer,
+	}
 
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerBefore(httputil.WithRequestURL, kitot.HTTPToContext(tracer, listMessagesOp, kitlog.NewNopLogger())),
+		kithttp.ServerFinalizer(finishSpan),
 	}
 
 	mux := bone.New()
 	mux.Get("/channels/:chanID/messages", kithttp.NewServer(
 		listMessagesEndpoint(svc),
-		decodeList,
-		encodeResponse,
+		az.decodeList,
+		encodeResponse(tracer),
 		opts...,
 	))
 
@@ -68,13 +111,23 @@ func MakeHandler(svc readers.MessageRepository, tc mainflux.ThingsServiceClient,
 	return mux
 }
 
-func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
+func (az authorizer) decodeList(ctx context.Context, r *http.Request) (interface{}, error) {
 	chanID := bone.GetValue(r, "chanID")
 	if chanID == "" {
 		return nil, errors.ErrInvalidQueryParams
 	}
 
-	if err := authorize(r, chanID); err != nil {
+	accept, err := negotiate(r.Header.Get("Accept"))
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := httputil.ReadStringQuery(r, tenantKey, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := az.authorize(ctx, r, chanID, tenant != ""); err != nil {
 		return nil, err
 	}
 
@@ -92,6 +145,10 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Only the SenML messages can be encoded as the SenML pack.
+	if accept != contentType && format != defFormat {
+		return nil, errNotAcceptable
+	}
 
 	subtopic, err := httputil.ReadStringQuery(r, subtopicKey, "")
 	if err != nil {
@@ -143,8 +200,24 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 		return nil, err
 	}
 
+	waitFor, err := httputil.ReadStringQuery(r, waitForKey, "")
+	if err != nil {
+		return nil, err
+	}
+	if waitFor != "" {
+		if _, err := messaging.ParseToken(waitFor); err != nil {
+			return nil, errors.Wrap(errors.ErrInvalidQueryParams, err)
+		}
+	}
+
+	timeout, err := readTimeoutQuery(r, timeoutKey, defTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	req := listMessagesReq{
 		chanID: chanID,
+		accept: accept,
 		pageMeta: readers.PageMetadata{
 			Offset:      offset,
 			Limit:       limit,
@@ -152,6 +225,7 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 			Subtopic:    subtopic,
 			Publisher:   publisher,
 			Protocol:    protocol,
+			Tenant:      tenant,
 			Name:        name,
 			Value:       v,
 			Comparator:  comparator,
@@ -159,6 +233,8 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 			DataValue:   vd,
 			From:        from,
 			To:          to,
+			WaitFor:     waitFor,
+			Timeout:     timeout,
 		},
 	}
 
@@ -173,22 +249,56 @@ func decodeList(_ context.Context, r *http.Request) (interface{}, error) {
 	return req, nil
 }
 
-func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
-	w.Header().Set("Content-Type", contentType)
+func encodeResponse(tracer opentracing.Tracer) kithttp.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		span, _ := opentracing.StartSpanFromContextWithTracer(ctx, tracer, encodeResponseOp)
+		defer span.Finish()
+
+		if res, ok := response.(pageRes); ok && res.accept != contentType {
+			return encodeSenML(w, res)
+		}
+
+		w.Header().Set("Content-Type", contentType)
+
+		if ar, ok := response.(mainflux.Response); ok {
+			for k, v := range ar.Headers() {
+				w.Header().Set(k, v)
+			}
+
+			w.WriteHeader(ar.Code())
 
-	if ar, ok := response.(mainflux.Response); ok {
-		for k, v := range ar.Headers() {
-			w.Header().Set(k, v)
+			if ar.Empty() {
+				return nil
+			}
 		}
 
-		w.WriteHeader(ar.Code())
+		return json.NewEncoder(w).Encode(response)
+	}
+}
 
-		if ar.Empty() {
-			return nil
+// encodeSenML encodes the page messages as the SenML pack. The pagination
+// is available in the Link header only.
+func encodeSenML(w http.ResponseWriter, res pageRes) error {
+	msgs := make([]senml.Message, len(res.Messages))
+	for i, m := range res.Messages {
+		msg, ok := m.(senml.Message)
+		if !ok {
+			return errEncodeSenML
 		}
+		msgs[i] = msg
+	}
+	data, err := senml.Encode(msgs, res.accept)
+	if err != nil {
+		return errors.Wrap(errEncodeSenML, err)
 	}
 
-	return json.NewEncoder(w).Encode(response)
+	w.Header().Set("Content-Type", res.accept)
+	for k, v := range res.Headers() {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(res.Code())
+	_, err = w.Write(data)
+	return err
 }
 
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
@@ -198,6 +308,12 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 		w.WriteHeader(http.StatusBadRequest)
 	case errors.Contains(err, errUnauthorizedAccess):
 		w.WriteHeader(http.StatusForbidden)
+	case errors.Contains(err, errNotAcceptable):
+		w.WriteHeader(http.StatusNotAcceptable)
+	case errors.Contains(err, readers.ErrWaitNotSupported):
+		w.WriteHeader(http.StatusNotImplemented)
+	case errors.Contains(err, readers.ErrWaitTimeout):
+		w.WriteHeader(http.StatusGatewayTimeout)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -210,17 +326,33 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	}
 }
 
-func authorize(r *http.Request, chanID string) error {
+// authorize checks if the token grants access to the channel. If the admin
+// access is required, i.e. the messages are filtered by tenant, only the
+// token of the admin user is accepted instead.
+func (az authorizer) authorize(ctx context.Context, r *http.Request, chanID string, admin bool) error {
+	span, ctx := opentracing.StartSpanFromContextWithTracer(ctx, az.tracer, authorizeOp)
+	defer span.Finish()
+	span.SetTag("channel_id", chanID)
+
 	token := r.Header.Get("Authorization")
 	if token == "" {
 		return errUnauthorizedAccess
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
 
-	_, err := auth.CanAccessByKey(ctx, &mainflux.AccessByKeyReq{Token: token, ChanID: chanID})
+	if admin {
+		if err := az.identifyAdmin(ctx, token); err != nil {
+			ext.Error.Set(span, true)
+			return err
+		}
+		return nil
+	}
+
+	_, err := az.things.CanAccessByKey(ctx, &mainflux.AccessByKeyReq{Token: token, ChanID: chanID})
 	if err != nil {
+		ext.Error.Set(span, true)
 		e, ok := status.FromError(err)
 		if ok && e.Code() == codes.PermissionDenied {
 			return errUnauthorizedAccess
@@ -231,6 +363,81 @@ func authorize(r *http.Request, chanID string) error {
 	return nil
 }
 
+// identifyAdmin checks if the token belongs to the admin user.
+func (az authorizer) identifyAdmin(ctx context.Context, token string) error {
+	if az.admin.Auth == nil || az.admin.Email == "" {
+		return errUnauthorizedAccess
+	}
+
+	id, err := az.admin.Auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		e, ok := status.FromError(err)
+		if ok && (e.Code() == codes.Unauthenticated || e.Code() == codes.PermissionDenied) {
+			return errUnauthorizedAccess
+		}
+		return err
+	}
+	if id.GetEmail() != az.admin.Email {
+		return errUnauthorizedAccess
+	}
+
+	return nil
+}
+
+// finishSpan finishes the request span once the response is sent, so the
+// failed requests are traced as well.
+func finishSpan(ctx context.Context, code int, _ *http.Request) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		ext.HTTPStatusCode.Set(span, uint16(code))
+		if code >= http.StatusInternalServerError {
+			ext.Error.Set(span, true)
+		}
+		span.Finish()
+	}
+}
+
+// negotiate returns the response content type accepted by the client, i.e.
+// the supported media range of the Accept header with the highest quality,
+// or the first one of such. Missing header and wildcards accept JSON.
+func negotiate(accept string) (string, error) {
+	if strings.TrimSpace(accept) == "" {
+		return contentType, nil
+	}
+
+	ret, best := "", 0.0
+	for _, r := range strings.Split(accept, ",") {
+		params := strings.Split(r, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		q := 1.0
+		for _, p := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) == 2 && strings.TrimSpace(kv[0]) == "q" {
+				v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+				if err != nil {
+					return "", errNotAcceptable
+				}
+				q = v
+			}
+		}
+
+		switch mediaType {
+		case "*/*", "application/*":
+			mediaType = contentType
+		case contentType, senml.JSON, senml.CBOR:
+		default:
+			continue
+		}
+		if q > best {
+			ret, best = mediaType, q
+		}
+	}
+
+	if ret == "" {
+		return "", errNotAcceptable
+	}
+	return ret, nil
+}
+
 func readBoolValueQuery(r *http.Request, key string) (bool, error) {
 	vals := bone.GetQuery(r, key)
 	if len(vals) > 1 {
@@ -248,3 +455,25 @@ func readBoolValueQuery(r *http.Request, key string) (bool, error) {
 
 	return b, nil
 }
+
+// readTimeoutQuery reads the wait timeout, e.g. 500ms. The timeout is
+// capped, so the waiting reads don't hold the connections for too long.
+func readTimeoutQuery(r *http.Request, key string, def time.Duration) (time.Duration, error) {
+	s, err := httputil.ReadStringQuery(r, key, "")
+	if err != nil {
+		return 0, err
+	}
+	if s == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, errors.ErrInvalidQueryParams
+	}
+	if d > maxTimeout {
+		d = maxTimeout
+	}
+
+	return d, nil
+}
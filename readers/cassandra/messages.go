@@ -4,6 +4,7 @@
 package cassandra
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -12,6 +13,8 @@ import (
 	jsont "github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/readers"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 )
 
 var errReadMessages = errors.New("failed to read messages from cassandra database")
@@ -38,27 +41,36 @@ func New(session *gocql.Session) readers.MessageRepository {
 	}
 }
 
-func (cr cassandraRepository) ReadAll(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+func (cr cassandraRepository) ReadAll(ctx context.Context, chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	if rpm.WaitFor != "" {
+		return readers.MessagesPage{}, readers.ErrWaitNotSupported
+	}
+
 	format := defTable
 	if rpm.Format != "" {
 		format = rpm.Format
 	}
 
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		ext.DBType.Set(span, "cassandra")
+		span.SetTag("db.table", format)
+	}
+
 	q, vals := buildQuery(chanID, rpm)
 
-	selectCQL := fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, name, unit,
+	selectCQL := fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, tenant, name, unit,
 		value, string_value, bool_value, data_value, sum, time,
 		update_time FROM messages WHERE channel = ? %s LIMIT ?
 		ALLOW FILTERING`, q)
 	countCQL := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE channel = ? %s ALLOW FILTERING`, format, q)
 
 	if format != defTable {
-		selectCQL = fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, created, payload FROM %s WHERE channel = ? %s LIMIT ?
+		selectCQL = fmt.Sprintf(`SELECT channel, subtopic, publisher, protocol, tenant, created, payload FROM %s WHERE channel = ? %s LIMIT ?
 			ALLOW FILTERING`, format, q)
 		countCQL = fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE channel = ? %s ALLOW FILTERING`, format, q)
 	}
 
-	iter := cr.session.Query(selectCQL, vals...).Iter()
+	iter := cr.session.Query(selectCQL, vals...).WithContext(ctx).Iter()
 	defer iter.Close()
 	scanner := iter.Scanner()
 
@@ -79,7 +91,7 @@ func (cr cassandraRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 		for scanner.Next() {
 			var msg senml.Message
 			err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol,
-				&msg.Name, &msg.Unit, &msg.Value, &msg.StringValue, &msg.BoolValue,
+				&msg.Tenant, &msg.Name, &msg.Unit, &msg.Value, &msg.StringValue, &msg.BoolValue,
 				&msg.DataValue, &msg.Sum, &msg.Time, &msg.UpdateTime)
 			if err != nil {
 				if e, ok := err.(gocql.RequestError); ok {
@@ -94,7 +106,7 @@ func (cr cassandraRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 	default:
 		for scanner.Next() {
 			var msg jsonMessage
-			err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol, &msg.Created, &msg.Payload)
+			err := scanner.Scan(&msg.Channel, &msg.Subtopic, &msg.Publisher, &msg.Protocol, &msg.Tenant, &msg.Created, &msg.Payload)
 			if err != nil {
 				if e, ok := err.(gocql.RequestError); ok {
 					if e.Code() == undefinedTableCode {
@@ -112,7 +124,7 @@ func (cr cassandraRepository) ReadAll(chanID string, rpm readers.PageMetadata) (
 		}
 	}
 
-	if err := cr.session.Query(countCQL, vals[:len(vals)-1]...).Scan(&page.Total); err != nil {
+	if err := cr.session.Query(countCQL, vals[:len(vals)-1]...).WithContext(ctx).Scan(&page.Total); err != nil {
 		if e, ok := err.(gocql.RequestError); ok {
 			if e.Code() == undefinedTableCode {
 				return readers.MessagesPage{}, nil
@@ -142,7 +154,8 @@ func buildQuery(chanID string, rpm readers.PageMetadata) (string, []interface{})
 			"subtopic",
 			"publisher",
 			"name",
-			"protocol":
+			"protocol",
+			"tenant":
 			vals = append(vals, val)
 			condCQL = fmt.Sprintf(`%s AND %s = ?`, condCQL, name)
 		case "v":
@@ -178,6 +191,7 @@ type jsonMessage struct {
 	Subtopic  string
 	Publisher string
 	Protocol  string
+	Tenant    string
 	Payload   string
 }
 
@@ -189,6 +203,7 @@ func (msg jsonMessage) toMap() (map[string]interface{}, error) {
 		"subtopic":  msg.Subtopic,
 		"publisher": msg.Publisher,
 		"protocol":  msg.Protocol,
+		"tenant":    msg.Tenant,
 		"payload":   map[string]interface{}{},
 	}
 	pld := make(map[string]interface{})
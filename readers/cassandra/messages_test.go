@@ -4,6 +4,7 @@
 package cassandra_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -377,7 +378,7 @@ func TestReadSenml(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		result, err := reader.ReadAll(tc.chanID, tc.pageMeta)
+		result, err := reader.ReadAll(context.Background(), tc.chanID, tc.pageMeta)
 		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
 		assert.ElementsMatch(t, tc.page.Messages, result.Messages, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Messages, result.Messages))
 		assert.Equal(t, tc.page.Total, result.Total, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Total, result.Total))
@@ -520,7 +521,7 @@ func TestReadJSON(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		result, err := reader.ReadAll(tc.chanID, tc.pageMeta)
+		result, err := reader.ReadAll(context.Background(), tc.chanID, tc.pageMeta)
 		for i := 0; i < len(result.Messages); i++ {
 			m := result.Messages[i]
 			// Remove id as it is not sent by the client.
@@ -556,6 +557,7 @@ func toMap(msg json.Message) map[string]interface{} {
 		"subtopic":  msg.Subtopic,
 		"publisher": msg.Publisher,
 		"protocol":  msg.Protocol,
+		"tenant":    msg.Tenant,
 		"payload":   map[string]interface{}(msg.Payload),
 	}
 }
@@ -1,6 +1,7 @@
 package influxdb
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -14,6 +15,8 @@ import (
 	influxdata "github.com/influxdata/influxdb/client/v2"
 	jsont "github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 )
 
 const (
@@ -39,12 +42,21 @@ func New(client influxdata.Client, database string) readers.MessageRepository {
 	}
 }
 
-func (repo *influxRepository) ReadAll(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+func (repo *influxRepository) ReadAll(ctx context.Context, chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	if rpm.WaitFor != "" {
+		return readers.MessagesPage{}, readers.ErrWaitNotSupported
+	}
+
 	format := defMeasurement
 	if rpm.Format != "" {
 		format = rpm.Format
 	}
 
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		ext.DBType.Set(span, "influxdb")
+		span.SetTag("db.measurement", format)
+	}
+
 	condition := fmtCondition(chanID, rpm)
 
 	cmd := fmt.Sprintf(`SELECT * FROM %s WHERE %s ORDER BY time DESC LIMIT %d OFFSET %d`, format, condition, rpm.Limit, rpm.Offset)
@@ -149,7 +161,8 @@ func fmtCondition(chanID string, rpm readers.PageMetadata) string {
 			"subtopic",
 			"publisher",
 			"name",
-			"protocol":
+			"protocol",
+			"tenant":
 			condition = fmt.Sprintf(`%s AND "%s"='%s'`, condition, name, value)
 		case "v":
 			comparator := readers.ParseValueComparator(query)
@@ -263,7 +276,7 @@ func parseJSON(names []string, fields []interface{}) (interface{}, error) {
 	pld := make(map[string]interface{})
 	for i, n := range names {
 		switch n {
-		case "channel", "created", "subtopic", "publisher", "protocol", "time":
+		case "channel", "created", "subtopic", "publisher", "protocol", "tenant", "time":
 			ret[n] = fields[i]
 		default:
 			v := fields[i]
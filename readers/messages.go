@@ -3,7 +3,12 @@
 
 package readers
 
-import "errors"
+import (
+	"context"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
 
 const (
 	// EqualKey represents the equal comparison operator key.
@@ -18,14 +23,24 @@ const (
 	GreaterThanEqualKey = "ge"
 )
 
-// ErrNotFound indicates that requested entity doesn't exist.
-var ErrNotFound = errors.New("entity not found")
+var (
+	// ErrNotFound indicates that requested entity doesn't exist.
+	ErrNotFound = errors.New("entity not found")
+
+	// ErrWaitTimeout indicates that the awaited message isn't stored before
+	// the wait timeout.
+	ErrWaitTimeout = errors.New("message not stored before the timeout")
+
+	// ErrWaitNotSupported indicates that the repository can't wait for the
+	// message to be stored.
+	ErrWaitNotSupported = errors.New("waiting for message not supported")
+)
 
 // MessageRepository specifies message reader API.
 type MessageRepository interface {
 	// ReadAll skips given number of messages for given channel and returns next
 	// limited number of messages.
-	ReadAll(chanID string, pm PageMetadata) (MessagesPage, error)
+	ReadAll(ctx context.Context, chanID string, pm PageMetadata) (MessagesPage, error)
 }
 
 // Message represents any message format.
@@ -46,6 +61,7 @@ type PageMetadata struct {
 	Subtopic    string  `json:"subtopic,omitempty"`
 	Publisher   string  `json:"publisher,omitempty"`
 	Protocol    string  `json:"protocol,omitempty"`
+	Tenant      string  `json:"tenant,omitempty"`
 	Name        string  `json:"name,omitempty"`
 	Value       float64 `json:"v,omitempty"`
 	Comparator  string  `json:"comparator,omitempty"`
@@ -55,6 +71,12 @@ type PageMetadata struct {
 	From        float64 `json:"from,omitempty"`
 	To          float64 `json:"to,omitempty"`
 	Format      string  `json:"format,omitempty"`
+
+	// WaitFor is the consistency token of the message that has to be stored
+	// before the messages are read. The read waits for it at most for the
+	// Timeout, and fails with ErrWaitTimeout once it expires.
+	WaitFor string        `json:"wait_for,omitempty"`
+	Timeout time.Duration `json:"-"`
 }
 
 // ParseValueComparator convert comparison operator keys into mathematic anotation
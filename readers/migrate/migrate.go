@@ -0,0 +1,266 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package migrate contains the migration of the SenML messages between the
+// message stores. The messages are read using the reader repository of the
+// source store and written using the writer of the destination one, so any
+// pair of the stores having both can be migrated.
+package migrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/mainflux/mainflux/consumers"
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+)
+
+const defBatchSize = 1000
+
+var (
+	// ErrMigrate indicates failure to read or write the messages.
+	ErrMigrate = errors.New("failed to migrate messages")
+
+	errUnsupportedMessage = errors.New("unsupported message type")
+)
+
+// Config represents the migration parameters.
+type Config struct {
+	// Channels lists the channels to migrate.
+	Channels []string
+
+	// BatchSize is the number of messages read and written at once.
+	BatchSize uint64
+
+	// DryRun only counts the messages left to migrate.
+	DryRun bool
+}
+
+// Migrator migrates the messages of the channels in batches, checkpointing
+// the progress after each batch, so the interrupted migration resumes from
+// the last batch.
+type Migrator struct {
+	src       readers.MessageRepository
+	dst       consumers.Consumer
+	dstReader readers.MessageRepository
+	state     StateStore
+	cfg       Config
+	logger    logger.Logger
+}
+
+// New returns the migrator from the source repository to the destination
+// writer. The destination reader is used to skip the messages of the batch
+// that may have been written before the migration stopped. If it's nil, such
+// batch is written again on resume.
+func New(src readers.MessageRepository, dst consumers.Consumer, dstReader readers.MessageRepository, state StateStore, cfg Config, logger logger.Logger) *Migrator {
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = defBatchSize
+	}
+	return &Migrator{
+		src:       src,
+		dst:       dst,
+		dstReader: dstReader,
+		state:     state,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+// Run migrates the channels one by one, skipping the ones already migrated,
+// and returns the number of messages migrated, or left to migrate in the dry
+// run mode.
+func (m *Migrator) Run(ctx context.Context) (uint64, error) {
+	state, err := m.state.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	total := uint64(0)
+	for _, ch := range m.cfg.Channels {
+		var n uint64
+		if m.cfg.DryRun {
+			n, err = m.count(ctx, ch, state.Channels[ch])
+		} else {
+			n, err = m.migrate(ctx, ch, state)
+		}
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+func (m *Migrator) count(ctx context.Context, ch string, cp Checkpoint) (uint64, error) {
+	if cp.Done {
+		return 0, nil
+	}
+	page, err := m.src.ReadAll(ctx, ch, readers.PageMetadata{Limit: 1, To: cp.Cursor})
+	if err != nil {
+		return 0, errors.Wrap(ErrMigrate, err)
+	}
+	m.logger.Info(fmt.Sprintf("Channel %s has %d messages to migrate", ch, page.Total))
+	return page.Total, nil
+}
+
+func (m *Migrator) migrate(ctx context.Context, ch string, state State) (uint64, error) {
+	cp := state.Channels[ch]
+	migrated := uint64(0)
+	for !cp.Done {
+		batch, next, done, err := m.next(ctx, ch, cp.Cursor)
+		if err != nil {
+			return migrated, err
+		}
+
+		if len(batch) > 0 {
+			w := Window{From: minTime(batch), To: cp.Cursor}
+			if cp.Pending != nil {
+				if batch, err = m.dedup(ctx, ch, batch, w); err != nil {
+					return migrated, err
+				}
+			}
+
+			cp.Pending = &w
+			state.Channels[ch] = cp
+			if err := m.state.Save(state); err != nil {
+				return migrated, err
+			}
+			if len(batch) > 0 {
+				if err := m.dst.Consume(batch); err != nil {
+					return migrated, errors.Wrap(ErrMigrate, err)
+				}
+			}
+			cp.Migrated += uint64(len(batch))
+			migrated += uint64(len(batch))
+		}
+
+		cp.Cursor, cp.Done, cp.Pending = next, done, nil
+		state.Channels[ch] = cp
+		if err := m.state.Save(state); err != nil {
+			return migrated, err
+		}
+		m.logger.Info(fmt.Sprintf("Migrated %d messages of channel %s", cp.Migrated, ch))
+	}
+	return migrated, nil
+}
+
+// next returns the next batch of the messages older than the cursor, along
+// with the cursor after the batch and whether it's the last batch.
+func (m *Migrator) next(ctx context.Context, ch string, cursor float64) ([]senml.Message, float64, bool, error) {
+	page, err := m.src.ReadAll(ctx, ch, readers.PageMetadata{Limit: m.cfg.BatchSize, To: cursor})
+	if err != nil {
+		return nil, 0, false, errors.Wrap(ErrMigrate, err)
+	}
+	msgs, err := toSenML(page.Messages)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if uint64(len(msgs)) < m.cfg.BatchSize {
+		return msgs, cursor, true, nil
+	}
+
+	// The page may cut the messages with the oldest time in half, so they're
+	// left for the next batch.
+	oldest := minTime(msgs)
+	batch := []senml.Message{}
+	for _, msg := range msgs {
+		if msg.Time > oldest {
+			batch = append(batch, msg)
+		}
+	}
+	if len(batch) > 0 {
+		return batch, math.Nextafter(oldest, math.Inf(1)), false, nil
+	}
+
+	// All the messages of the page have the same time, so the ones with that
+	// time are read using the offset. Since zero cursor means no bound,
+	// the messages are expected to have positive time.
+	batch, err = m.readWindow(ctx, m.src, ch, Window{From: oldest, To: math.Nextafter(oldest, math.Inf(1))})
+	if err != nil {
+		return nil, 0, false, err
+	}
+	return batch, oldest, oldest <= 0, nil
+}
+
+// dedup removes the messages already written to the destination.
+func (m *Migrator) dedup(ctx context.Context, ch string, batch []senml.Message, w Window) ([]senml.Message, error) {
+	if m.dstReader == nil {
+		return batch, nil
+	}
+	written, err := m.readWindow(ctx, m.dstReader, ch, w)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]int{}
+	for _, msg := range written {
+		seen[key(msg)]++
+	}
+	fresh := []senml.Message{}
+	for _, msg := range batch {
+		k := key(msg)
+		if seen[k] > 0 {
+			seen[k]--
+			continue
+		}
+		fresh = append(fresh, msg)
+	}
+	if skipped := len(batch) - len(fresh); skipped > 0 {
+		m.logger.Info(fmt.Sprintf("Skipped %d messages of channel %s written before the migration stopped", skipped, ch))
+	}
+	return fresh, nil
+}
+
+// readWindow reads all the channel messages of the time window.
+func (m *Migrator) readWindow(ctx context.Context, repo readers.MessageRepository, ch string, w Window) ([]senml.Message, error) {
+	ret := []senml.Message{}
+	for offset := uint64(0); ; offset += m.cfg.BatchSize {
+		pm := readers.PageMetadata{Offset: offset, Limit: m.cfg.BatchSize, From: w.From, To: w.To}
+		page, err := repo.ReadAll(ctx, ch, pm)
+		if err != nil {
+			return nil, errors.Wrap(ErrMigrate, err)
+		}
+		msgs, err := toSenML(page.Messages)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, msgs...)
+		if uint64(len(msgs)) < m.cfg.BatchSize {
+			return ret, nil
+		}
+	}
+}
+
+func toSenML(msgs []readers.Message) ([]senml.Message, error) {
+	ret := make([]senml.Message, len(msgs))
+	for i, msg := range msgs {
+		m, ok := msg.(senml.Message)
+		if !ok {
+			return nil, errors.Wrap(ErrMigrate, errors.Wrap(errUnsupportedMessage, fmt.Errorf("%T", msg)))
+		}
+		ret[i] = m
+	}
+	return ret, nil
+}
+
+func minTime(msgs []senml.Message) float64 {
+	min := msgs[0].Time
+	for _, msg := range msgs[1:] {
+		if msg.Time < min {
+			min = msg.Time
+		}
+	}
+	return min
+}
+
+// key identifies the message by its content, since the stores don't keep
+// the same IDs.
+func key(msg senml.Message) string {
+	data, _ := json.Marshal(msg)
+	return string(data)
+}
@@ -0,0 +1,239 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+	"github.com/mainflux/mainflux/readers/migrate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	chanID  = "50e6b371-60ff-45cf-bb52-8200e7cde536"
+	chanID2 = "a5c5b2c1-4d3a-44a3-a7c2-3af1e6fd3e82"
+)
+
+var errCrash = errors.New("crash")
+
+// store keeps the messages in memory, reading them from the newest to the
+// oldest like the message readers do.
+type store struct {
+	mu   sync.Mutex
+	msgs map[string][]senml.Message
+
+	// crashAfter is the number of the writes after which every write
+	// stores the messages, but fails as if the migration stopped.
+	crashAfter int
+	writes     int
+}
+
+func newStore(msgs ...senml.Message) *store {
+	s := &store{msgs: map[string][]senml.Message{}}
+	for _, msg := range msgs {
+		s.msgs[msg.Channel] = append(s.msgs[msg.Channel], msg)
+	}
+	return s
+}
+
+func (s *store) Consume(messages interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, msg := range messages.([]senml.Message) {
+		s.msgs[msg.Channel] = append(s.msgs[msg.Channel], msg)
+	}
+	s.writes++
+	if s.crashAfter > 0 && s.writes > s.crashAfter {
+		return errCrash
+	}
+	return nil
+}
+
+func (s *store) ReadAll(_ context.Context, chanID string, pm readers.PageMetadata) (readers.MessagesPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := []senml.Message{}
+	for _, msg := range s.msgs[chanID] {
+		if msg.Time < pm.From || (pm.To != 0 && msg.Time >= pm.To) {
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	sort.SliceStable(msgs, func(i, j int) bool { return msgs[i].Time > msgs[j].Time })
+
+	page := readers.MessagesPage{PageMetadata: pm, Total: uint64(len(msgs))}
+	for i := pm.Offset; i < uint64(len(msgs)) && i < pm.Offset+pm.Limit; i++ {
+		page.Messages = append(page.Messages, msgs[i])
+	}
+	return page, nil
+}
+
+func (s *store) count(ch string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.msgs[ch])
+}
+
+// seed returns the channel messages, some of them sharing the time.
+func seed(ch string, n int) []senml.Message {
+	msgs := []senml.Message{}
+	for i := 0; i < n; i++ {
+		v := float64(i)
+		msgs = append(msgs, senml.Message{
+			Channel:   ch,
+			Publisher: "1",
+			Protocol:  "mqtt",
+			Name:      fmt.Sprintf("sensor-%d", i),
+			Time:      float64(1600000000 + i/4),
+			Value:     &v,
+		})
+	}
+	return msgs
+}
+
+// multiset returns the number of occurrences of each message.
+func multiset(msgs []senml.Message) map[string]int {
+	ret := map[string]int{}
+	for _, msg := range msgs {
+		data, _ := json.Marshal(msg)
+		ret[string(data)]++
+	}
+	return ret
+}
+
+func newLogger(t *testing.T) logger.Logger {
+	l, err := logger.New(ioutil.Discard, "error")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return l
+}
+
+func newStateStore(t *testing.T) (migrate.StateStore, func()) {
+	dir, err := ioutil.TempDir("", "migrate")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	return migrate.NewFileStore(filepath.Join(dir, "state.json")), func() { os.RemoveAll(dir) }
+}
+
+func TestMigrate(t *testing.T) {
+	msgs := append(seed(chanID, 50), seed(chanID2, 7)...)
+
+	cases := []struct {
+		desc      string
+		batchSize uint64
+	}{
+		{desc: "migrate messages one by one", batchSize: 1},
+		{desc: "migrate messages in batches cutting same time messages", batchSize: 3},
+		{desc: "migrate messages in batches of same time messages", batchSize: 4},
+		{desc: "migrate messages in batches", batchSize: 10},
+		{desc: "migrate messages in single batch", batchSize: 100},
+	}
+
+	for _, tc := range cases {
+		src, dst := newStore(msgs...), newStore()
+		state, cleanup := newStateStore(t)
+		m := migrate.New(src, dst, dst, state, migrate.Config{Channels: []string{chanID, chanID2}, BatchSize: tc.batchSize}, newLogger(t))
+
+		n, err := m.Run(context.Background())
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, uint64(len(msgs)), n, fmt.Sprintf("%s: expected %d migrated got %d", tc.desc, len(msgs), n))
+		assert.Equal(t, multiset(src.msgs[chanID]), multiset(dst.msgs[chanID]), fmt.Sprintf("%s: expected same messages of channel %s", tc.desc, chanID))
+		assert.Equal(t, multiset(src.msgs[chanID2]), multiset(dst.msgs[chanID2]), fmt.Sprintf("%s: expected same messages of channel %s", tc.desc, chanID2))
+
+		s, err := state.Load()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		for _, ch := range []string{chanID, chanID2} {
+			cp := s.Channels[ch]
+			assert.True(t, cp.Done, fmt.Sprintf("%s: expected channel %s done", tc.desc, ch))
+			assert.Nil(t, cp.Pending, fmt.Sprintf("%s: expected no pending batch of channel %s", tc.desc, ch))
+			assert.Equal(t, uint64(src.count(ch)), cp.Migrated, fmt.Sprintf("%s: expected %d migrated of channel %s got %d", tc.desc, src.count(ch), ch, cp.Migrated))
+		}
+
+		// Migrated channels are skipped.
+		n, err = m.Run(context.Background())
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, uint64(0), n, fmt.Sprintf("%s: expected nothing migrated again got %d", tc.desc, n))
+		assert.Equal(t, len(msgs), dst.count(chanID)+dst.count(chanID2), fmt.Sprintf("%s: expected no duplicates", tc.desc))
+		cleanup()
+	}
+}
+
+func TestMigrateResume(t *testing.T) {
+	msgs := seed(chanID, 50)
+
+	cases := []struct {
+		desc       string
+		crashAfter int
+		dedup      bool
+		written    int
+	}{
+		{desc: "resume after first batch", crashAfter: 1, dedup: true, written: 50},
+		{desc: "resume in the middle", crashAfter: 4, dedup: true, written: 50},
+		{desc: "resume in the middle without destination reader", crashAfter: 4, dedup: false, written: 50 + 4},
+	}
+
+	for _, tc := range cases {
+		src, dst := newStore(msgs...), newStore()
+		state, cleanup := newStateStore(t)
+		cfg := migrate.Config{Channels: []string{chanID}, BatchSize: 7}
+
+		// The batch after the crashAfter ones is written, but the migration
+		// stops before it's checkpointed.
+		dst.crashAfter = tc.crashAfter
+		_, err := migrate.New(src, dst, dst, state, cfg, newLogger(t)).Run(context.Background())
+		assert.True(t, errors.Contains(err, errCrash), fmt.Sprintf("%s: expected error %s got %s", tc.desc, errCrash, err))
+		s, err := state.Load()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.NotNil(t, s.Channels[chanID].Pending, fmt.Sprintf("%s: expected pending batch", tc.desc))
+
+		dst.crashAfter = 0
+		var dstReader readers.MessageRepository
+		if tc.dedup {
+			dstReader = dst
+		}
+		_, err = migrate.New(src, dst, dstReader, state, cfg, newLogger(t)).Run(context.Background())
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.written, dst.count(chanID), fmt.Sprintf("%s: expected %d written got %d", tc.desc, tc.written, dst.count(chanID)))
+		if tc.dedup {
+			assert.Equal(t, multiset(msgs), multiset(dst.msgs[chanID]), fmt.Sprintf("%s: expected same messages", tc.desc))
+		}
+		cleanup()
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	src, dst := newStore(append(seed(chanID, 20), seed(chanID2, 5)...)...), newStore()
+	state, cleanup := newStateStore(t)
+	defer cleanup()
+
+	// Migrate the first channel partially.
+	dst.crashAfter = 1
+	_, err := migrate.New(src, dst, dst, state, migrate.Config{Channels: []string{chanID}, BatchSize: 8}, newLogger(t)).Run(context.Background())
+	require.True(t, errors.Contains(err, errCrash), fmt.Sprintf("expected error %s got %s", errCrash, err))
+	written := dst.count(chanID)
+
+	before, err := state.Load()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	m := migrate.New(src, dst, dst, state, migrate.Config{Channels: []string{chanID, chanID2}, BatchSize: 8, DryRun: true}, newLogger(t))
+	n, err := m.Run(context.Background())
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	// Messages of the pending batch are counted since they might not be written.
+	assert.Equal(t, uint64(20-4+5), n, fmt.Sprintf("expected %d to migrate got %d", 20-4+5, n))
+	assert.Equal(t, written, dst.count(chanID), "expected nothing written in dry run")
+	after, err := state.Load()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	assert.Equal(t, before, after, "expected state unchanged in dry run")
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mainflux/mainflux/consumers/writers/postgres"
+	dockertest "github.com/ory/dockertest/v3"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	pgDB    *sqlx.DB
+	mongoDB *mongo.Database
+)
+
+// TestMain starts the PostgreSQL and MongoDB containers the stores are
+// migrated between. If Docker isn't available, only the tests using the
+// in-memory stores run.
+func TestMain(m *testing.M) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Printf("Could not connect to docker: %s", err)
+		os.Exit(m.Run())
+	}
+
+	pgContainer, err := pool.Run("postgres", "13.3-alpine", []string{
+		"POSTGRES_USER=test",
+		"POSTGRES_PASSWORD=test",
+		"POSTGRES_DB=test",
+	})
+	if err != nil {
+		log.Printf("Could not start container: %s", err)
+		os.Exit(m.Run())
+	}
+	mongoContainer, err := pool.Run("mongo", "4.4.3-bionic", []string{"MONGO_INITDB_DATABASE=test"})
+	if err != nil {
+		pool.Purge(pgContainer)
+		log.Fatalf("Could not start container: %s", err)
+	}
+
+	port := pgContainer.GetPort("5432/tcp")
+	if err := pool.Retry(func() error {
+		url := fmt.Sprintf("host=localhost port=%s user=test dbname=test password=test sslmode=disable", port)
+		db, err := sqlx.Open("postgres", url)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+		return db.Ping()
+	}); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+	pgDB, err = postgres.Connect(postgres.Config{
+		Host:    "localhost",
+		Port:    port,
+		User:    "test",
+		Pass:    "test",
+		Name:    "test",
+		SSLMode: "disable",
+	})
+	if err != nil {
+		log.Fatalf("Could not setup test DB connection: %s", err)
+	}
+
+	addr := fmt.Sprintf("mongodb://localhost:%s", mongoContainer.GetPort("27017/tcp"))
+	var client *mongo.Client
+	if err := pool.Retry(func() error {
+		client, err = mongo.Connect(context.Background(), options.Client().ApplyURI(addr))
+		if err != nil {
+			return err
+		}
+		return client.Ping(context.Background(), nil)
+	}); err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+	mongoDB = client.Database("test")
+
+	code := m.Run()
+
+	// Defers will not be run when using os.Exit
+	pgDB.Close()
+	if err := pool.Purge(pgContainer); err != nil {
+		log.Fatalf("Could not purge container: %s", err)
+	}
+	if err := pool.Purge(mongoContainer); err != nil {
+		log.Fatalf("Could not purge container: %s", err)
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+var (
+	errLoadState = errors.New("failed to load migration state")
+	errSaveState = errors.New("failed to save migration state")
+)
+
+// Window represents the messages with the time in the [From, To) range.
+// Zero To means there's no upper bound.
+type Window struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to,omitempty"`
+}
+
+// Checkpoint represents the migration progress of a channel. The channel
+// messages are migrated from the newest to the oldest one, so the cursor
+// is the exclusive upper bound of the time of the messages not migrated
+// yet, and it's zero before the first batch is migrated.
+type Checkpoint struct {
+	Cursor   float64 `json:"cursor,omitempty"`
+	Migrated uint64  `json:"migrated"`
+	Done     bool    `json:"done,omitempty"`
+
+	// Pending is the window of the batch being written. It's set if the
+	// migration stopped before the batch was checkpointed, in which case
+	// the batch may have been written, so it's deduplicated on resume.
+	Pending *Window `json:"pending,omitempty"`
+}
+
+// State represents the migration checkpoints by channel.
+type State struct {
+	Channels map[string]Checkpoint `json:"channels"`
+}
+
+// StateStore persists the migration state.
+type StateStore interface {
+	// Load returns the saved state, or the empty one if nothing is saved.
+	Load() (State, error)
+
+	// Save replaces the saved state.
+	Save(State) error
+}
+
+var _ StateStore = (*fileStore)(nil)
+
+type fileStore struct {
+	path string
+}
+
+// NewFileStore returns the state store keeping the state in the JSON file.
+// The file is replaced atomically, so the saved state survives the crash
+// in the middle of saving.
+func NewFileStore(path string) StateStore {
+	return fileStore{path: path}
+}
+
+func (fs fileStore) Load() (State, error) {
+	state := State{Channels: map[string]Checkpoint{}}
+	data, err := ioutil.ReadFile(fs.path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return State{}, errors.Wrap(errLoadState, err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return State{}, errors.Wrap(errLoadState, err)
+	}
+	if state.Channels == nil {
+		state.Channels = map[string]Checkpoint{}
+	}
+	return state, nil
+}
+
+func (fs fileStore) Save(state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return errors.Wrap(errSaveState, err)
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(fs.path), filepath.Base(fs.path)+".*")
+	if err != nil {
+		return errors.Wrap(errSaveState, err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrap(errSaveState, err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(errSaveState, err)
+	}
+	if err := os.Rename(f.Name(), fs.path); err != nil {
+		return errors.Wrap(errSaveState, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mainflux/mainflux/consumers"
+	mongowriter "github.com/mainflux/mainflux/consumers/writers/mongodb"
+	pgwriter "github.com/mainflux/mainflux/consumers/writers/postgres"
+	"github.com/mainflux/mainflux/pkg/transformers/senml"
+	"github.com/mainflux/mainflux/readers"
+	"github.com/mainflux/mainflux/readers/migrate"
+	mongoreader "github.com/mainflux/mainflux/readers/mongodb"
+	pgreader "github.com/mainflux/mainflux/readers/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, repo readers.MessageRepository, ch string) []senml.Message {
+	page, err := repo.ReadAll(context.Background(), ch, readers.PageMetadata{Limit: 1000})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	msgs := []senml.Message{}
+	for _, msg := range page.Messages {
+		msgs = append(msgs, msg.(senml.Message))
+	}
+	return msgs
+}
+
+func TestMigrateStores(t *testing.T) {
+	if pgDB == nil || mongoDB == nil {
+		t.Skip("Docker isn't available")
+	}
+
+	pg := struct {
+		reader readers.MessageRepository
+		writer consumers.Consumer
+	}{pgreader.New(pgDB), pgwriter.New(pgDB)}
+	mongo := struct {
+		reader readers.MessageRepository
+		writer consumers.Consumer
+	}{mongoreader.New(mongoDB), mongowriter.New(mongoDB)}
+
+	cases := []struct {
+		desc      string
+		ch        string
+		src       readers.MessageRepository
+		seed      consumers.Consumer
+		dst       consumers.Consumer
+		dstReader readers.MessageRepository
+	}{
+		{
+			desc:      "migrate messages from mongodb to postgres",
+			ch:        chanID,
+			src:       mongo.reader,
+			seed:      mongo.writer,
+			dst:       pg.writer,
+			dstReader: pg.reader,
+		},
+		{
+			desc:      "migrate messages from postgres to mongodb",
+			ch:        chanID2,
+			src:       pg.reader,
+			seed:      pg.writer,
+			dst:       mongo.writer,
+			dstReader: mongo.reader,
+		},
+	}
+
+	for _, tc := range cases {
+		msgs := seed(tc.ch, 101)
+		err := tc.seed.Consume(msgs)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+
+		state, cleanup := newStateStore(t)
+		m := migrate.New(tc.src, tc.dst, tc.dstReader, state, migrate.Config{Channels: []string{tc.ch}, BatchSize: 10}, newLogger(t))
+
+		n, err := m.Run(context.Background())
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, uint64(len(msgs)), n, fmt.Sprintf("%s: expected %d migrated got %d", tc.desc, len(msgs), n))
+
+		migrated := readAll(t, tc.dstReader, tc.ch)
+		assert.Equal(t, len(msgs), len(migrated), fmt.Sprintf("%s: expected %d messages got %d", tc.desc, len(msgs), len(migrated)))
+		assert.Equal(t, multiset(msgs), multiset(migrated), fmt.Sprintf("%s: expected same messages", tc.desc))
+		// Spot check the newest and the oldest message.
+		assert.Equal(t, msgs[len(msgs)-1].Time, migrated[0].Time, fmt.Sprintf("%s: expected newest message time %f got %f", tc.desc, msgs[len(msgs)-1].Time, migrated[0].Time))
+		assert.Equal(t, *msgs[0].Value, *migrated[len(migrated)-1].Value, fmt.Sprintf("%s: expected oldest message value %f got %f", tc.desc, *msgs[0].Value, *migrated[len(migrated)-1].Value))
+
+		// Migrated messages aren't duplicated by another run.
+		_, err = m.Run(context.Background())
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		again := readAll(t, tc.dstReader, tc.ch)
+		assert.Equal(t, len(msgs), len(again), fmt.Sprintf("%s: expected %d messages got %d", tc.desc, len(msgs), len(again)))
+		cleanup()
+	}
+}
@@ -4,9 +4,11 @@
 package mocks
 
 import (
+	"context"
 	"encoding/json"
 	"sync"
 
+	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/readers"
 )
@@ -30,7 +32,7 @@ func NewMessageRepository(chanID string, messages []readers.Message) readers.Mes
 	}
 }
 
-func (repo *messageRepositoryMock) ReadAll(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+func (repo *messageRepositoryMock) ReadAll(_ context.Context, chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
 	repo.mutex.Lock()
 	defer repo.mutex.Unlock()
 
@@ -38,6 +40,10 @@ func (repo *messageRepositoryMock) ReadAll(chanID string, rpm readers.PageMetada
 		return readers.MessagesPage{}, nil
 	}
 
+	if rpm.WaitFor != "" && !repo.stored(chanID, rpm.WaitFor) {
+		return readers.MessagesPage{}, readers.ErrWaitTimeout
+	}
+
 	var query map[string]interface{}
 	meta, _ := json.Marshal(rpm)
 	json.Unmarshal(meta, &query)
@@ -66,6 +72,10 @@ func (repo *messageRepositoryMock) ReadAll(chanID string, rpm readers.PageMetada
 				if rpm.Protocol != senml.Protocol {
 					ok = false
 				}
+			case "tenant":
+				if rpm.Tenant != senml.Tenant {
+					ok = false
+				}
 			case "v":
 				if senml.Value == nil {
 					ok = false
@@ -161,3 +171,18 @@ func (repo *messageRepositoryMock) ReadAll(chanID string, rpm readers.PageMetada
 		Messages:     msgs[rpm.Offset:end],
 	}, nil
 }
+
+// stored reports whether the message of the consistency token is stored.
+// The mock doesn't wait, the messages are stored up front.
+func (repo *messageRepositoryMock) stored(chanID, token string) bool {
+	t, err := messaging.ParseToken(token)
+	if err != nil {
+		return false
+	}
+	for _, m := range repo.messages[chanID] {
+		if m.(senml.Message).MessageID == t.ID {
+			return true
+		}
+	}
+	return false
+}
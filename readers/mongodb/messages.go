@@ -11,6 +11,8 @@ import (
 	jsont "github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/readers"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -37,7 +39,11 @@ func New(db *mongo.Database) readers.MessageRepository {
 	}
 }
 
-func (repo mongoRepository) ReadAll(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+func (repo mongoRepository) ReadAll(ctx context.Context, chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	if rpm.WaitFor != "" {
+		return readers.MessagesPage{}, readers.ErrWaitNotSupported
+	}
+
 	format := defCollection
 	order := "time"
 	if rpm.Format != "" && rpm.Format != defCollection {
@@ -46,22 +52,26 @@ func (repo mongoRepository) ReadAll(chanID string, rpm readers.PageMetadata) (re
 	}
 
 	col := repo.db.Collection(format)
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		ext.DBType.Set(span, "mongodb")
+		span.SetTag("db.collection", format)
+	}
 
 	sortMap := map[string]interface{}{
 		order: -1,
 	}
 	// Remove format filter and format the rest properly.
 	filter := fmtCondition(chanID, rpm)
-	cursor, err := col.Find(context.Background(), filter, options.Find().SetSort(sortMap).SetLimit(int64(rpm.Limit)).SetSkip(int64(rpm.Offset)))
+	cursor, err := col.Find(ctx, filter, options.Find().SetSort(sortMap).SetLimit(int64(rpm.Limit)).SetSkip(int64(rpm.Offset)))
 	if err != nil {
 		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
 	var messages []readers.Message
 	switch format {
 	case defCollection:
-		for cursor.Next(context.Background()) {
+		for cursor.Next(ctx) {
 			var m senml.Message
 			if err := cursor.Decode(&m); err != nil {
 				return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
@@ -70,7 +80,7 @@ func (repo mongoRepository) ReadAll(chanID string, rpm readers.PageMetadata) (re
 			messages = append(messages, m)
 		}
 	default:
-		for cursor.Next(context.Background()) {
+		for cursor.Next(ctx) {
 			var m map[string]interface{}
 			if err := cursor.Decode(&m); err != nil {
 				return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
@@ -81,7 +91,7 @@ func (repo mongoRepository) ReadAll(chanID string, rpm readers.PageMetadata) (re
 		}
 	}
 
-	total, err := col.CountDocuments(context.Background(), filter)
+	total, err := col.CountDocuments(ctx, filter)
 	if err != nil {
 		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
 	}
@@ -117,7 +127,8 @@ func fmtCondition(chanID string, rpm readers.PageMetadata) bson.D {
 			"subtopic",
 			"publisher",
 			"name",
-			"protocol":
+			"protocol",
+			"tenant":
 			filter = append(filter, bson.E{Key: name, Value: value})
 		case "v":
 			bsonFilter := value
@@ -375,7 +375,7 @@ func TestReadSenml(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		result, err := reader.ReadAll(tc.chanID, tc.pageMeta)
+		result, err := reader.ReadAll(context.Background(), tc.chanID, tc.pageMeta)
 		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
 		assert.ElementsMatch(t, tc.page.Messages, result.Messages, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Messages, result.Messages))
 		assert.Equal(t, tc.page.Total, result.Total, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Total, result.Total))
@@ -515,7 +515,7 @@ func TestReadJSON(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		result, err := reader.ReadAll(tc.chanID, tc.pageMeta)
+		result, err := reader.ReadAll(context.Background(), tc.chanID, tc.pageMeta)
 
 		for i := 0; i < len(result.Messages); i++ {
 			m := result.Messages[i]
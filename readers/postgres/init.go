@@ -70,6 +70,28 @@ func migrateDB(db *sqlx.DB) error {
 					"DROP TABLE messages",
 				},
 			},
+			{
+				Id: "messages_2",
+				Up: []string{
+					`ALTER TABLE messages ADD COLUMN IF NOT EXISTS tenant TEXT NOT NULL DEFAULT 'default'`,
+					`CREATE INDEX IF NOT EXISTS messages_tenant_idx ON messages (tenant)`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS messages_tenant_idx`,
+					`ALTER TABLE messages DROP COLUMN IF EXISTS tenant`,
+				},
+			},
+			{
+				Id: "messages_3",
+				Up: []string{
+					`ALTER TABLE messages ADD COLUMN IF NOT EXISTS message_id TEXT NOT NULL DEFAULT ''`,
+					`CREATE INDEX IF NOT EXISTS messages_message_id_idx ON messages (message_id) WHERE message_id <> ''`,
+				},
+				Down: []string{
+					`DROP INDEX IF EXISTS messages_message_id_idx`,
+					`ALTER TABLE messages DROP COLUMN IF EXISTS message_id`,
+				},
+			},
 		},
 	}
 
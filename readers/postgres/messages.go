@@ -4,19 +4,28 @@
 package postgres
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx" // required for DB access
 	"github.com/lib/pq"
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
 	jsont "github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/readers"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 )
 
 const errInvalid = "invalid_text_representation"
 
+// pollInterval is the period the table is polled with while waiting for
+// the message to be stored.
+const pollInterval = 50 * time.Millisecond
+
 const (
 	format = "format"
 	// Table for SenML messages
@@ -41,7 +50,7 @@ func New(db *sqlx.DB) readers.MessageRepository {
 	}
 }
 
-func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+func (tr postgresRepository) ReadAll(ctx context.Context, chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
 	order := "time"
 	format := defTable
 
@@ -50,6 +59,17 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 		format = rpm.Format
 	}
 
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		ext.DBType.Set(span, "postgres")
+		span.SetTag("db.table", format)
+	}
+
+	if rpm.WaitFor != "" {
+		if err := tr.waitFor(ctx, format, chanID, rpm); err != nil {
+			return readers.MessagesPage{}, err
+		}
+	}
+
 	q := fmt.Sprintf(`SELECT * FROM %s
     WHERE %s ORDER BY %s DESC
 	LIMIT :limit OFFSET :offset;`, format, fmtCondition(chanID, rpm), order)
@@ -62,6 +82,7 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 		"publisher":    rpm.Publisher,
 		"name":         rpm.Name,
 		"protocol":     rpm.Protocol,
+		"tenant":       rpm.Tenant,
 		"value":        rpm.Value,
 		"bool_value":   rpm.BoolValue,
 		"string_value": rpm.StringValue,
@@ -70,7 +91,7 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 		"to":           rpm.To,
 	}
 
-	rows, err := tr.db.NamedQuery(q, params)
+	rows, err := tr.db.NamedQueryContext(ctx, q, params)
 	if err != nil {
 		if e, ok := err.(*pq.Error); ok {
 			if e.Code == undefinedTableCode {
@@ -112,7 +133,7 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 	}
 
 	q = fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s;`, format, fmtCondition(chanID, rpm))
-	rows, err = tr.db.NamedQuery(q, params)
+	rows, err = tr.db.NamedQueryContext(ctx, q, params)
 	if err != nil {
 		return readers.MessagesPage{}, errors.Wrap(errReadMessages, err)
 	}
@@ -129,6 +150,44 @@ func (tr postgresRepository) ReadAll(chanID string, rpm readers.PageMetadata) (r
 	return page, nil
 }
 
+// waitFor polls the table until the message of the consistency token is
+// stored, or the timeout expires. The table that doesn't exist yet is
+// polled until it's created by the writer.
+func (tr postgresRepository) waitFor(ctx context.Context, table, chanID string, rpm readers.PageMetadata) error {
+	token, err := messaging.ParseToken(rpm.WaitFor)
+	if err != nil {
+		return errors.Wrap(errors.ErrInvalidQueryParams, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rpm.Timeout)
+	defer cancel()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	q := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE channel = $1 AND message_id = $2);`, table)
+	for {
+		var stored bool
+		err := tr.db.QueryRowxContext(ctx, q, chanID, token.ID).Scan(&stored)
+		if e, ok := err.(*pq.Error); ok && e.Code == undefinedTableCode {
+			err = nil
+		}
+		switch {
+		case ctx.Err() != nil:
+			return readers.ErrWaitTimeout
+		case err != nil:
+			return errors.Wrap(errReadMessages, err)
+		case stored:
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return readers.ErrWaitTimeout
+		case <-ticker.C:
+		}
+	}
+}
+
 func fmtCondition(chanID string, rpm readers.PageMetadata) string {
 	condition := `channel = :channel`
 
@@ -145,7 +204,8 @@ func fmtCondition(chanID string, rpm readers.PageMetadata) string {
 			"subtopic",
 			"publisher",
 			"name",
-			"protocol":
+			"protocol",
+			"tenant":
 			condition = fmt.Sprintf(`%s AND %s = :%s`, condition, name, name)
 		case "v":
 			comparator := readers.ParseValueComparator(query)
@@ -177,6 +237,8 @@ type jsonMessage struct {
 	Subtopic  string `db:"subtopic"`
 	Publisher string `db:"publisher"`
 	Protocol  string `db:"protocol"`
+	Tenant    string `db:"tenant"`
+	MessageID string `db:"message_id"`
 	Payload   []byte `db:"payload"`
 }
 
@@ -188,8 +250,12 @@ func (msg jsonMessage) toMap() (map[string]interface{}, error) {
 		"subtopic":  msg.Subtopic,
 		"publisher": msg.Publisher,
 		"protocol":  msg.Protocol,
+		"tenant":    msg.Tenant,
 		"payload":   map[string]interface{}{},
 	}
+	if msg.MessageID != "" {
+		ret["message_id"] = msg.MessageID
+	}
 	pld := make(map[string]interface{})
 	if err := json.Unmarshal(msg.Payload, &pld); err != nil {
 		return nil, err
@@ -4,11 +4,14 @@
 package postgres_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
 
 	pwriter "github.com/mainflux/mainflux/consumers/writers/postgres"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/transformers/json"
 	"github.com/mainflux/mainflux/pkg/transformers/senml"
 	"github.com/mainflux/mainflux/pkg/uuid"
@@ -369,7 +372,7 @@ func TestReadSenml(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		result, err := reader.ReadAll(tc.chanID, tc.pageMeta)
+		result, err := reader.ReadAll(context.Background(), tc.chanID, tc.pageMeta)
 		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", desc, err))
 		assert.ElementsMatch(t, tc.page.Messages, result.Messages, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Messages, result.Messages))
 		assert.Equal(t, tc.page.Total, result.Total, fmt.Sprintf("%s: expected %v got %v", desc, tc.page.Total, result.Total))
@@ -504,7 +507,7 @@ func TestReadJSON(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		result, err := reader.ReadAll(tc.chanID, tc.pageMeta)
+		result, err := reader.ReadAll(context.Background(), tc.chanID, tc.pageMeta)
 		for i := 0; i < len(result.Messages); i++ {
 			m := result.Messages[i]
 			// Remove id as it is not sent by the client.
@@ -517,6 +520,162 @@ func TestReadJSON(t *testing.T) {
 	}
 }
 
+func TestReadTenant(t *testing.T) {
+	writer := pwriter.New(db)
+	tr := senml.New(senml.JSON)
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	pubID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// Messages published without the tenant header belong to the default
+	// tenant.
+	tenants := []string{"acme", "globex", ""}
+	perTenant := 10
+	now := time.Now().Unix()
+	for i := 0; i < perTenant*len(tenants); i++ {
+		msg := messaging.Message{
+			Channel:   chanID,
+			Publisher: pubID,
+			Protocol:  httpProt,
+			Payload:   []byte(fmt.Sprintf(`[{"n":"%s","v":%d,"t":%d}]`, msgName, i, now-int64(i))),
+		}
+		if tenant := tenants[i%len(tenants)]; tenant != "" {
+			msg.Headers = map[string]string{messaging.TenantHeader: tenant}
+		}
+		res, err := tr.Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+		err = writer.Consume(res)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+
+	reader := preader.New(db)
+
+	cases := []struct {
+		desc   string
+		tenant string
+		total  uint64
+	}{
+		{
+			desc:   "read messages of tenant",
+			tenant: "acme",
+			total:  uint64(perTenant),
+		},
+		{
+			desc:   "read messages of other tenant",
+			tenant: "globex",
+			total:  uint64(perTenant),
+		},
+		{
+			desc:   "read messages of default tenant",
+			tenant: messaging.DefaultTenant,
+			total:  uint64(perTenant),
+		},
+		{
+			desc:   "read messages of unknown tenant",
+			tenant: "unknown",
+			total:  0,
+		},
+		{
+			desc:   "read messages of all tenants",
+			tenant: "",
+			total:  uint64(perTenant * len(tenants)),
+		},
+	}
+
+	for _, tc := range cases {
+		pm := readers.PageMetadata{
+			Offset: 0,
+			Limit:  uint64(perTenant * len(tenants)),
+			Tenant: tc.tenant,
+		}
+		page, err := reader.ReadAll(context.Background(), chanID, pm)
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %s", tc.desc, err))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected %d got %d", tc.desc, tc.total, page.Total))
+		assert.Len(t, page.Messages, int(tc.total), fmt.Sprintf("%s: expected %d messages got %d", tc.desc, tc.total, len(page.Messages)))
+		for _, m := range page.Messages {
+			msg := m.(senml.Message)
+			if tc.tenant != "" {
+				assert.Equal(t, tc.tenant, msg.Tenant, fmt.Sprintf("%s: expected tenant %s got %s", tc.desc, tc.tenant, msg.Tenant))
+			}
+			assert.NotEmpty(t, msg.Tenant, fmt.Sprintf("%s: expected tenant to be set", tc.desc))
+		}
+	}
+}
+
+func TestReadWaitFor(t *testing.T) {
+	writer := pwriter.New(db)
+	tr := senml.New(senml.JSON)
+
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	// publish writes the message with the ID after the delay, returning
+	// the consistency token of the message.
+	publish := func(delay time.Duration) string {
+		id, err := idProvider.ID()
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+		msg := messaging.Message{
+			Channel:  chanID,
+			Protocol: httpProt,
+			Created:  time.Now().UnixNano(),
+			Payload:  []byte(fmt.Sprintf(`[{"n":"%s","v":1}]`, msgName)),
+			Headers:  map[string]string{messaging.IDHeader: id},
+		}
+		res, err := tr.Transform(msg)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+		go func() {
+			time.Sleep(delay)
+			writer.Consume(res)
+		}()
+		return messaging.NewToken(msg).String()
+	}
+
+	stored := publish(0)
+	time.Sleep(100 * time.Millisecond)
+	delayed := publish(300 * time.Millisecond)
+	missing := messaging.Token{ID: wrongID, Created: time.Now().UnixNano()}.String()
+
+	cases := []struct {
+		desc    string
+		token   string
+		timeout time.Duration
+		err     error
+	}{
+		{
+			desc:    "read messages waiting for stored message",
+			token:   stored,
+			timeout: time.Second,
+			err:     nil,
+		},
+		{
+			desc:    "read messages waiting for message stored before timeout",
+			token:   delayed,
+			timeout: 2 * time.Second,
+			err:     nil,
+		},
+		{
+			desc:    "read messages waiting for message not stored",
+			token:   missing,
+			timeout: 200 * time.Millisecond,
+			err:     readers.ErrWaitTimeout,
+		},
+	}
+
+	reader := preader.New(db)
+	for _, tc := range cases {
+		pm := readers.PageMetadata{
+			Offset:  0,
+			Limit:   limit,
+			WaitFor: tc.token,
+			Timeout: tc.timeout,
+		}
+		_, err := reader.ReadAll(context.Background(), chanID, pm)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+}
+
 func fromSenml(msg []senml.Message) []readers.Message {
 	var ret []readers.Message
 	for _, m := range msg {
@@ -540,6 +699,7 @@ func toMap(msg json.Message) map[string]interface{} {
 		"subtopic":  msg.Subtopic,
 		"publisher": msg.Publisher,
 		"protocol":  msg.Protocol,
+		"tenant":    msg.Tenant,
 		"payload":   map[string]interface{}(msg.Payload),
 	}
 }
@@ -0,0 +1,98 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracing contains middlewares that will add spans
+// to existing traces.
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mainflux/mainflux/readers"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+)
+
+const readAllOp = "read_all"
+
+var _ readers.MessageRepository = (*messageRepositoryMiddleware)(nil)
+
+type messageRepositoryMiddleware struct {
+	tracer opentracing.Tracer
+	repo   readers.MessageRepository
+}
+
+// MessageRepositoryMiddleware tracks request and their latency, and adds
+// spans to context. The span is tagged with the channel, the filter summary
+// and the number of returned rows, while the backend specific tags are added
+// by the repository.
+func MessageRepositoryMiddleware(tracer opentracing.Tracer, repo readers.MessageRepository) readers.MessageRepository {
+	return messageRepositoryMiddleware{
+		tracer: tracer,
+		repo:   repo,
+	}
+}
+
+func (mrm messageRepositoryMiddleware) ReadAll(ctx context.Context, chanID string, rpm readers.PageMetadata) (readers.MessagesPage, error) {
+	span := createSpan(ctx, mrm.tracer, readAllOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	span.SetTag("channel_id", chanID)
+	span.SetTag("filter", filter(rpm))
+	span.SetTag("offset", rpm.Offset)
+	span.SetTag("limit", rpm.Limit)
+
+	page, err := mrm.repo.ReadAll(ctx, chanID, rpm)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.SetTag("error.message", err.Error())
+		return page, err
+	}
+
+	span.SetTag("rows", len(page.Messages))
+	span.SetTag("total", page.Total)
+	return page, nil
+}
+
+// filter returns the summary of the filters applied by the page metadata,
+// e.g. "name=temperature,subtopic=room,v>=5". Paging and format are left out.
+func filter(rpm readers.PageMetadata) string {
+	var query map[string]interface{}
+	meta, err := json.Marshal(rpm)
+	if err != nil {
+		return ""
+	}
+	if err := json.Unmarshal(meta, &query); err != nil {
+		return ""
+	}
+
+	var filters []string
+	for name, value := range query {
+		switch name {
+		case "offset", "limit", "format", "comparator":
+			continue
+		case "v":
+			filters = append(filters, fmt.Sprintf("%s%s%v", name, readers.ParseValueComparator(query), value))
+		default:
+			filters = append(filters, fmt.Sprintf("%s=%v", name, value))
+		}
+	}
+	sort.Strings(filters)
+
+	return strings.Join(filters, ",")
+}
+
+func createSpan(ctx context.Context, tracer opentracing.Tracer, opName string) opentracing.Span {
+	if parentSpan := opentracing.SpanFromContext(ctx); parentSpan != nil {
+		return tracer.StartSpan(
+			opName,
+			opentracing.ChildOf(parentSpan.Context()),
+		)
+	}
+	return tracer.StartSpan(opName)
+}
@@ -12,6 +12,8 @@ import (
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/things/authz"
 	opentracing "github.com/opentracing/opentracing-go"
 	"google.golang.org/grpc"
 )
@@ -39,6 +41,8 @@ func NewClient(conn *grpc.ClientConn, tracer opentracing.Tracer, timeout time.Du
 			encodeCanAccessByKeyRequest,
 			decodeIdentityResponse,
 			mainflux.ThingID{},
+			kitgrpc.ClientBefore(reqctx.ContextToGRPC, authz.ContextToGRPC),
+			kitgrpc.ClientAfter(authz.GRPCToPolicyVersion),
 		).Endpoint()),
 		canAccessByID: kitot.TraceClient(tracer, "can_access_by_id")(kitgrpc.NewClient(
 			conn,
@@ -47,6 +51,8 @@ func NewClient(conn *grpc.ClientConn, tracer opentracing.Tracer, timeout time.Du
 			encodeCanAccessByIDRequest,
 			decodeEmptyResponse,
 			empty.Empty{},
+			kitgrpc.ClientBefore(reqctx.ContextToGRPC, authz.ContextToGRPC),
+			kitgrpc.ClientAfter(authz.GRPCToPolicyVersion),
 		).Endpoint()),
 		isChannelOwner: kitot.TraceClient(tracer, "is_channel_owner")(kitgrpc.NewClient(
 			conn,
@@ -55,6 +61,7 @@ func NewClient(conn *grpc.ClientConn, tracer opentracing.Tracer, timeout time.Du
 			encodeIsChannelOwner,
 			decodeEmptyResponse,
 			empty.Empty{},
+			kitgrpc.ClientBefore(reqctx.ContextToGRPC),
 		).Endpoint()),
 		identify: kitot.TraceClient(tracer, "identify")(kitgrpc.NewClient(
 			conn,
@@ -63,6 +70,7 @@ func NewClient(conn *grpc.ClientConn, tracer opentracing.Tracer, timeout time.Du
 			encodeIdentifyRequest,
 			decodeIdentityResponse,
 			mainflux.ThingID{},
+			kitgrpc.ClientBefore(reqctx.ContextToGRPC),
 		).Endpoint()),
 	}
 }
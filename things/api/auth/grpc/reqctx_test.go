@@ -0,0 +1,248 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux"
+	adapter "github.com/mainflux/mainflux/http"
+	adapterapi "github.com/mainflux/mainflux/http/api"
+	httpmocks "github.com/mainflux/mainflux/http/mocks"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/internal/topics"
+	"github.com/mainflux/mainflux/things"
+	grpcapi "github.com/mainflux/mainflux/things/api/auth/grpc"
+	"github.com/mainflux/mainflux/things/authz"
+	"github.com/opentracing/opentracing-go/mocktracer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const requestID = "adapter-request-1"
+
+// ctxService records the request info seen by the things service.
+type ctxService struct {
+	things.Service
+	infos chan reqctx.Info
+}
+
+func (cs ctxService) CanAccessByKey(ctx context.Context, chanID, key string) (string, error) {
+	id, err := cs.Service.CanAccessByKey(ctx, chanID, key)
+	info, _ := reqctx.FromContext(ctx)
+	cs.infos <- info
+	return id, err
+}
+
+func TestRequestPropagation(t *testing.T) {
+	ths, err := svc.CreateThings(context.Background(), token, thing)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	th := ths[0]
+	chs, err := svc.CreateChannels(context.Background(), token, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch := chs[0]
+	err = svc.Connect(context.Background(), token, []string{ch.ID}, []string{th.ID})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cs := ctxService{Service: svc, infos: make(chan reqctx.Info, 1)}
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	server := grpc.NewServer()
+	mainflux.RegisterThingsServiceServer(server, grpcapi.NewServer(mocktracer.New(), cs))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	defer conn.Close()
+
+	// HTTP adapter calls things service over gRPC to authorize publishing.
+	// The test client connects over loopback, acting as the trusted proxy.
+	tc := grpcapi.NewClient(conn, mocktracer.New(), time.Second)
+	proxies, err := reqctx.ParseProxies([]string{"127.0.0.1"})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ts := httptest.NewServer(adapterapi.MakeHandler(adapter.New(httpmocks.NewPublisher(), tc), mocktracer.New(), "", topics.Default(), nil, proxies))
+	defer ts.Close()
+
+	cases := []struct {
+		desc      string
+		requestID string
+	}{
+		{
+			desc:      "propagate request ID sent to the adapter",
+			requestID: requestID,
+		},
+		{
+			desc: "propagate request ID generated by the adapter",
+		},
+	}
+
+	for _, tc := range cases {
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/channels/%s/messages", ts.URL, ch.ID), strings.NewReader(`[{"n":"temp","v":20}]`))
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		req.Header.Set("Authorization", th.Key)
+		req.Header.Set("X-Forwarded-For", "192.168.1.10")
+		if tc.requestID != "" {
+			req.Header.Set(reqctx.RequestIDHeader, tc.requestID)
+		}
+
+		res, err := ts.Client().Do(req)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, http.StatusAccepted, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusAccepted, res.StatusCode))
+
+		id := res.Header.Get(reqctx.RequestIDHeader)
+		if tc.requestID != "" {
+			assert.Equal(t, tc.requestID, id, fmt.Sprintf("%s: expected request ID %s got %s", tc.desc, tc.requestID, id))
+		}
+		assert.NotEmpty(t, id, fmt.Sprintf("%s: expected request ID in response", tc.desc))
+
+		info := <-cs.infos
+		assert.Equal(t, id, info.RequestID, fmt.Sprintf("%s: expected things service to see request ID %s got %s", tc.desc, id, info.RequestID))
+		assert.Equal(t, "192.168.1.10", info.RemoteAddr, fmt.Sprintf("%s: expected things service to see originating address got %s", tc.desc, info.RemoteAddr))
+		assert.Equal(t, reqctx.Thing(th.ID), info.Subject, fmt.Sprintf("%s: expected things service to authenticate thing %s got %v", tc.desc, th.ID, info.Subject))
+	}
+}
+
+// accessService records the channel access seen by the things service.
+type accessService struct {
+	things.Service
+	accesses chan authz.Access
+}
+
+func (as accessService) CanAccessByID(ctx context.Context, chanID, thingID string) error {
+	as.accesses <- authz.AccessFromContext(ctx)
+	return as.Service.CanAccessByID(ctx, chanID, thingID)
+}
+
+func TestAccessPropagation(t *testing.T) {
+	ths, err := svc.CreateThings(context.Background(), token, thing)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	th := ths[0]
+	chs, err := svc.CreateChannels(context.Background(), token, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch := chs[0]
+	err = svc.Connect(context.Background(), token, []string{ch.ID}, []string{th.ID})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	as := accessService{Service: svc, accesses: make(chan authz.Access, 1)}
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	server := grpc.NewServer()
+	mainflux.RegisterThingsServiceServer(server, grpcapi.NewServer(mocktracer.New(), as))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	defer conn.Close()
+	client := grpcapi.NewClient(conn, mocktracer.New(), time.Second)
+
+	cases := []struct {
+		desc   string
+		access authz.Access
+	}{
+		{
+			desc:   "propagate publish access",
+			access: authz.Access{Action: authz.Publish, Subtopic: "a.b"},
+		},
+		{
+			desc:   "propagate subscribe access without subtopic",
+			access: authz.Access{Action: authz.Subscribe},
+		},
+		{
+			desc: "propagate missing access",
+		},
+	}
+
+	for _, tc := range cases {
+		ctx := authz.WithAccess(context.Background(), tc.access)
+		_, err := client.CanAccessByID(ctx, &mainflux.AccessByIDReq{ThingID: th.ID, ChanID: ch.ID})
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		access := <-as.accesses
+		assert.Equal(t, tc.access, access, fmt.Sprintf("%s: expected things service to see access %v got %v", tc.desc, tc.access, access))
+	}
+}
+
+// versionService records the policy version of the access decisions.
+type versionService struct {
+	things.Service
+	version uint64
+}
+
+func (vs versionService) CanAccessByKey(ctx context.Context, chanID, key string) (string, error) {
+	authz.SetPolicyVersion(ctx, vs.version)
+	return vs.Service.CanAccessByKey(ctx, chanID, key)
+}
+
+func (vs versionService) CanAccessByID(ctx context.Context, chanID, thingID string) error {
+	authz.SetPolicyVersion(ctx, vs.version)
+	return vs.Service.CanAccessByID(ctx, chanID, thingID)
+}
+
+func TestPolicyVersionPropagation(t *testing.T) {
+	ths, err := svc.CreateThings(context.Background(), token, thing)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	th := ths[0]
+	chs, err := svc.CreateChannels(context.Background(), token, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch := chs[0]
+	err = svc.Connect(context.Background(), token, []string{ch.ID}, []string{th.ID})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cases := []struct {
+		desc     string
+		version  uint64
+		byKey    bool
+		expected uint64
+	}{
+		{
+			desc:     "propagate policy version of access by ID",
+			version:  42,
+			expected: 42,
+		},
+		{
+			desc:     "propagate policy version of access by key",
+			version:  43,
+			byKey:    true,
+			expected: 43,
+		},
+		{
+			desc:     "propagate missing policy version",
+			expected: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		server := grpc.NewServer()
+		mainflux.RegisterThingsServiceServer(server, grpcapi.NewServer(mocktracer.New(), versionService{Service: svc, version: tc.version}))
+		go server.Serve(listener)
+
+		conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		client := grpcapi.NewClient(conn, mocktracer.New(), time.Second)
+
+		ctx := authz.WithPolicyVersion(context.Background())
+		if tc.byKey {
+			_, err = client.CanAccessByKey(ctx, &mainflux.AccessByKeyReq{Token: th.Key, ChanID: ch.ID})
+		} else {
+			_, err = client.CanAccessByID(ctx, &mainflux.AccessByIDReq{ThingID: th.ID, ChanID: ch.ID})
+		}
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		version := authz.PolicyVersionFromContext(ctx)
+		assert.Equal(t, tc.expected, version, fmt.Sprintf("%s: expected policy version %d got %d", tc.desc, tc.expected, version))
+
+		conn.Close()
+		server.Stop()
+	}
+}
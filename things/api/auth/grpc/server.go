@@ -10,7 +10,11 @@ import (
 	kitgrpc "github.com/go-kit/kit/transport/grpc"
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/internal/reqctx"
+	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/authz"
 	opentracing "github.com/opentracing/opentracing-go"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -32,21 +36,27 @@ func NewServer(tracer opentracing.Tracer, svc things.Service) mainflux.ThingsSer
 			kitot.TraceServer(tracer, "can_access")(canAccessEndpoint(svc)),
 			decodeCanAccessByKeyRequest,
 			encodeIdentityResponse,
+			kitgrpc.ServerBefore(reqctx.GRPCToContext, authz.GRPCToContext, authz.RecordPolicyVersion),
+			kitgrpc.ServerAfter(authz.PolicyVersionToGRPC),
 		),
 		canAccessByID: kitgrpc.NewServer(
 			canAccessByIDEndpoint(svc),
 			decodeCanAccessByIDRequest,
 			encodeEmptyResponse,
+			kitgrpc.ServerBefore(reqctx.GRPCToContext, authz.GRPCToContext, authz.RecordPolicyVersion),
+			kitgrpc.ServerAfter(authz.PolicyVersionToGRPC),
 		),
 		isChannelOwner: kitgrpc.NewServer(
 			isChannelOwnerEndpoint(svc),
 			decodeIsChannelOwnerRequest,
 			encodeEmptyResponse,
+			kitgrpc.ServerBefore(reqctx.GRPCToContext),
 		),
 		identify: kitgrpc.NewServer(
 			kitot.TraceServer(tracer, "identify")(identifyEndpoint(svc)),
 			decodeIdentifyRequest,
 			encodeIdentityResponse,
+			kitgrpc.ServerBefore(reqctx.GRPCToContext),
 		),
 	}
 }
@@ -118,17 +128,31 @@ func encodeEmptyResponse(_ context.Context, grpcRes interface{}) (interface{}, e
 }
 
 func encodeError(err error) error {
-	switch err {
-	case nil:
+	if err == nil {
 		return nil
-	case things.ErrMalformedEntity:
-		return status.Error(codes.InvalidArgument, "received invalid can access request")
-	case things.ErrUnauthorizedAccess:
+	}
+	// Wrapped errors are reported as internal server errors.
+	if e, ok := err.(errors.Error); ok && e.Err() != nil {
+		return status.Error(codes.Internal, "internal server error")
+	}
+
+	switch code := apierrors.GRPCCode(err); code {
+	case codes.InvalidArgument:
+		if errors.Contains(err, things.ErrMalformedEntity) {
+			return status.Error(code, "received invalid can access request")
+		}
+		// Only the category is exposed, since the message may carry
+		// details of the internal error.
+		return status.Error(code, string(apierrors.CodeOf(err)))
+	case codes.Unauthenticated:
+		// Clients expect invalid credentials to deny the access.
 		return status.Error(codes.PermissionDenied, "missing or invalid credentials provided")
-	case things.ErrEntityConnected:
-		return status.Error(codes.PermissionDenied, "entities are not connected")
-	case things.ErrNotFound:
-		return status.Error(codes.NotFound, "entity does not exist")
+	case codes.PermissionDenied:
+		return status.Error(code, "entities are not connected")
+	case codes.NotFound:
+		return status.Error(code, "entity does not exist")
+	case codes.Unavailable:
+		return status.Error(code, "access decision unavailable")
 	default:
 		return status.Error(codes.Internal, "internal server error")
 	}
@@ -0,0 +1,91 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+)
+
+// ErrUnexpectedStatus indicates the things service responded with the
+// unexpected status code.
+var ErrUnexpectedStatus = errors.New("unexpected things service response status")
+
+// Client lists the channels over the internal things auth HTTP API.
+type Client interface {
+	// ListChannelsByMetadataKey retrieves the page of the channels of all
+	// the users having the specified metadata key set.
+	ListChannelsByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error)
+}
+
+var _ Client = (*httpClient)(nil)
+
+type httpClient struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewClient returns the client of the things auth HTTP API served on the
+// URL, e.g. http://things:8989, authenticating with the things service
+// secret.
+func NewClient(url, secret string, timeout time.Duration) Client {
+	return &httpClient{
+		url:    strings.TrimSuffix(url, "/"),
+		secret: secret,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *httpClient) ListChannelsByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	q := url.Values{}
+	q.Set(keyKey, key)
+	q.Set(offsetKey, fmt.Sprint(pm.Offset))
+	q.Set(limitKey, fmt.Sprint(pm.Limit))
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/channels?%s", c.url, q.Encode()), nil)
+	if err != nil {
+		return things.ChannelsPage{}, err
+	}
+	req.Header.Set("Authorization", c.secret)
+	res, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return things.ChannelsPage{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return things.ChannelsPage{}, errors.Wrap(ErrUnexpectedStatus, errors.New(res.Status))
+	}
+
+	var cpr channelsPageRes
+	if err := json.NewDecoder(res.Body).Decode(&cpr); err != nil {
+		return things.ChannelsPage{}, err
+	}
+
+	page := things.ChannelsPage{
+		PageMetadata: things.PageMetadata{
+			Total:  cpr.Total,
+			Offset: cpr.Offset,
+			Limit:  cpr.Limit,
+		},
+	}
+	for _, ch := range cpr.Channels {
+		page.Channels = append(page.Channels, things.Channel{
+			ID:       ch.ID,
+			Owner:    ch.Owner,
+			Metadata: ch.Metadata,
+		})
+	}
+
+	return page, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package http_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+	httpapi "github.com/mainflux/mainflux/things/api/auth/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientListChannelsByMetadataKey(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	schema := map[string]interface{}{"type": "object"}
+	var chs []things.Channel
+	for i := 0; i < 3; i++ {
+		ch := channel
+		ch.Metadata = map[string]interface{}{things.SchemaKey: schema}
+		chs = append(chs, ch)
+	}
+	chs, err := svc.CreateChannels(context.Background(), token, append(chs, channel)...)
+	require.Nil(t, err, fmt.Sprintf("failed to create channels: %s", err))
+
+	cases := []struct {
+		desc   string
+		secret string
+		key    string
+		pm     things.PageMetadata
+		ids    []string
+		total  uint64
+		err    error
+	}{
+		{
+			desc:   "list channels with key",
+			secret: secret,
+			key:    things.SchemaKey,
+			pm:     things.PageMetadata{Offset: 0, Limit: 10},
+			ids:    []string{chs[0].ID, chs[1].ID, chs[2].ID},
+			total:  3,
+			err:    nil,
+		},
+		{
+			desc:   "list page of channels with key",
+			secret: secret,
+			key:    things.SchemaKey,
+			pm:     things.PageMetadata{Offset: 2, Limit: 10},
+			ids:    []string{chs[2].ID},
+			total:  3,
+			err:    nil,
+		},
+		{
+			desc:   "list channels without key",
+			secret: secret,
+			key:    "",
+			pm:     things.PageMetadata{Offset: 0, Limit: 10},
+			err:    httpapi.ErrUnexpectedStatus,
+		},
+		{
+			desc:   "list channels with invalid secret",
+			secret: wrong,
+			key:    things.SchemaKey,
+			pm:     things.PageMetadata{Offset: 0, Limit: 10},
+			err:    httpapi.ErrUnexpectedStatus,
+		},
+	}
+
+	for _, tc := range cases {
+		client := httpapi.NewClient(ts.URL, tc.secret, time.Second)
+		page, err := client.ListChannelsByMetadataKey(context.Background(), tc.key, tc.pm)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		var ids []string
+		for _, ch := range page.Channels {
+			ids = append(ids, ch.ID)
+			assert.Equal(t, schema, ch.Metadata[things.SchemaKey], fmt.Sprintf("%s: expected schema %v got %v", tc.desc, schema, ch.Metadata[things.SchemaKey]))
+		}
+		assert.Equal(t, tc.ids, ids, fmt.Sprintf("%s: expected channels %v got %v", tc.desc, tc.ids, ids))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d", tc.desc, tc.total, page.Total))
+	}
+}
@@ -65,3 +65,35 @@ func canAccessByIDEndpoint(svc things.Service) endpoint.Endpoint {
 		return res, nil
 	}
 }
+
+func listChannelsByMetadataKeyEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listChannelsByMetadataKeyReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ListChannelsByMetadataKey(ctx, req.key, req.pageMetadata)
+		if err != nil {
+			return nil, err
+		}
+
+		res := channelsPageRes{
+			Total:    page.Total,
+			Offset:   page.Offset,
+			Limit:    page.Limit,
+			Channels: []channelRes{},
+		}
+		// Only the requested key is exposed, the rest of the metadata
+		// belongs to the channel owner.
+		for _, ch := range page.Channels {
+			res.Channels = append(res.Channels, channelRes{
+				ID:       ch.ID,
+				Owner:    ch.Owner,
+				Metadata: map[string]interface{}{req.key: ch.Metadata[req.key]},
+			})
+		}
+
+		return res, nil
+	}
+}
@@ -27,6 +27,7 @@ const (
 	contentType = "application/json"
 	email       = "user@example.com"
 	token       = "token"
+	secret      = "secret"
 	wrong       = "wrong_value"
 )
 
@@ -46,6 +47,7 @@ type testRequest struct {
 	method      string
 	url         string
 	contentType string
+	token       string
 	body        io.Reader
 }
 
@@ -57,6 +59,9 @@ func (tr testRequest) make() (*http.Response, error) {
 	if tr.contentType != "" {
 		req.Header.Set("Content-Type", tr.contentType)
 	}
+	if tr.token != "" {
+		req.Header.Set("Authorization", tr.token)
+	}
 	return tr.client.Do(req)
 }
 
@@ -74,11 +79,11 @@ func newService(tokens map[string]string) things.Service {
 	thingCache := mocks.NewThingCache()
 	idProvider := uuid.NewMock()
 
-	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, idProvider)
+	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, mocks.NewStatsRepository(), idProvider, idProvider)
 }
 
 func newServer(svc things.Service) *httptest.Server {
-	mux := httpapi.MakeHandler(mocktracer.New(), svc)
+	mux := httpapi.MakeHandler(mocktracer.New(), svc, secret)
 	return httptest.NewServer(mux)
 }
 
@@ -298,6 +303,106 @@ func TestCanAccessByID(t *testing.T) {
 	}
 }
 
+func TestListChannelsByMetadataKey(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	schema := map[string]interface{}{"type": "object"}
+	ch := channel
+	ch.Metadata = map[string]interface{}{things.SchemaKey: schema, "private": "data"}
+	_, err := svc.CreateChannels(context.Background(), token, ch, channel)
+	require.Nil(t, err, fmt.Sprintf("failed to create channels: %s", err))
+
+	cases := map[string]struct {
+		query  string
+		token  string
+		status int
+		total  uint64
+	}{
+		"list channels with key": {
+			query:  fmt.Sprintf("key=%s", things.SchemaKey),
+			token:  secret,
+			status: http.StatusOK,
+			total:  1,
+		},
+		"list channels with non-existing key": {
+			query:  fmt.Sprintf("key=%s", wrong),
+			token:  secret,
+			status: http.StatusOK,
+			total:  0,
+		},
+		"list channels without key": {
+			query:  "",
+			token:  secret,
+			status: http.StatusBadRequest,
+		},
+		"list channels with limit over max": {
+			query:  fmt.Sprintf("key=%s&limit=%d", things.SchemaKey, 101),
+			token:  secret,
+			status: http.StatusBadRequest,
+		},
+		"list channels with invalid offset": {
+			query:  fmt.Sprintf("key=%s&offset=%s", things.SchemaKey, wrong),
+			token:  secret,
+			status: http.StatusBadRequest,
+		},
+		"list channels with invalid secret": {
+			query:  fmt.Sprintf("key=%s", things.SchemaKey),
+			token:  wrong,
+			status: http.StatusUnauthorized,
+		},
+		"list channels without secret": {
+			query:  fmt.Sprintf("key=%s", things.SchemaKey),
+			token:  "",
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for desc, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels?%s", ts.URL, tc.query),
+			token:  tc.token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusOK {
+			continue
+		}
+		var body struct {
+			Total    uint64 `json:"total"`
+			Channels []struct {
+				Metadata map[string]interface{} `json:"metadata"`
+			} `json:"channels"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", desc, err))
+		assert.Equal(t, tc.total, body.Total, fmt.Sprintf("%s: expected total %d got %d", desc, tc.total, body.Total))
+		for _, ch := range body.Channels {
+			expected := map[string]interface{}{things.SchemaKey: schema}
+			assert.Equal(t, expected, ch.Metadata, fmt.Sprintf("%s: expected metadata %v got %v", desc, expected, ch.Metadata))
+		}
+	}
+}
+
+func TestListChannelsByMetadataKeyWithoutSecret(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := httptest.NewServer(httpapi.MakeHandler(mocktracer.New(), svc, ""))
+	defer ts.Close()
+
+	req := testRequest{
+		client: ts.Client(),
+		method: http.MethodGet,
+		url:    fmt.Sprintf("%s/channels?key=%s", ts.URL, things.SchemaKey),
+	}
+	res, err := req.make()
+	assert.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	assert.Equal(t, http.StatusNotFound, res.StatusCode, fmt.Sprintf("expected status code %d got %d", http.StatusNotFound, res.StatusCode))
+}
+
 type identifyReq struct {
 	Token string `json:"token"`
 }
@@ -5,6 +5,8 @@ package http
 
 import "github.com/mainflux/mainflux/things"
 
+const maxLimitSize = 100
+
 type identifyReq struct {
 	Token string `json:"token"`
 }
@@ -42,3 +44,20 @@ func (req canAccessByIDReq) validate() error {
 
 	return nil
 }
+
+type listChannelsByMetadataKeyReq struct {
+	key          string
+	pageMetadata things.PageMetadata
+}
+
+func (req *listChannelsByMetadataKeyReq) validate() error {
+	if req.key == "" {
+		return things.ErrMalformedEntity
+	}
+
+	if req.pageMetadata.Limit == 0 || req.pageMetadata.Limit > maxLimitSize {
+		return things.ErrMalformedEntity
+	}
+
+	return nil
+}
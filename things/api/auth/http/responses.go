@@ -34,3 +34,28 @@ func (res canAccessByIDRes) Headers() map[string]string {
 func (res canAccessByIDRes) Empty() bool {
 	return true
 }
+
+type channelRes struct {
+	ID       string                 `json:"id"`
+	Owner    string                 `json:"owner"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type channelsPageRes struct {
+	Total    uint64       `json:"total"`
+	Offset   uint64       `json:"offset"`
+	Limit    uint64       `json:"limit"`
+	Channels []channelRes `json:"channels"`
+}
+
+func (res channelsPageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res channelsPageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res channelsPageRes) Empty() bool {
+	return false
+}
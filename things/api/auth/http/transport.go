@@ -5,8 +5,8 @@ package http
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
-	"io"
 	"net/http"
 	"strings"
 
@@ -14,15 +14,27 @@ import (
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/internal/httputil"
+	"github.com/mainflux/mainflux/internal/reqctx"
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/things"
 	opentracing "github.com/opentracing/opentracing-go"
 )
 
-const contentType = "application/json"
+const (
+	contentType = "application/json"
+	keyKey      = "key"
+	offsetKey   = "offset"
+	limitKey    = "limit"
+	defOffset   = 0
+	defLimit    = 100
+)
 
-// MakeHandler returns a HTTP handler for auth API endpoints.
-func MakeHandler(tracer opentracing.Tracer, svc things.Service) http.Handler {
+// MakeHandler returns a HTTP handler for auth API endpoints. The channels
+// of all the users are listed only to the services presenting the secret in
+// the Authorization header, and not at all if the secret is empty.
+func MakeHandler(tracer opentracing.Tracer, svc things.Service, secret string) http.Handler {
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
 	}
@@ -50,7 +62,16 @@ func MakeHandler(tracer opentracing.Tracer, svc things.Service) http.Handler {
 		opts...,
 	))
 
-	return r
+	if secret != "" {
+		r.Get("/channels", kithttp.NewServer(
+			kitot.TraceServer(tracer, "list_channels_by_metadata_key")(listChannelsByMetadataKeyEndpoint(svc)),
+			decodeListChannelsByMetadataKey(secret),
+			encodeResponse,
+			opts...,
+		))
+	}
+
+	return reqctx.Middleware(r, nil)
 }
 
 func decodeIdentify(_ context.Context, r *http.Request) (interface{}, error) {
@@ -96,6 +117,39 @@ func decodeCanAccessByID(_ context.Context, r *http.Request) (interface{}, error
 	return req, nil
 }
 
+func decodeListChannelsByMetadataKey(secret string) kithttp.DecodeRequestFunc {
+	return func(_ context.Context, r *http.Request) (interface{}, error) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(secret)) != 1 {
+			return nil, things.ErrUnauthorizedAccess
+		}
+
+		k, err := httputil.ReadStringQuery(r, keyKey, "")
+		if err != nil {
+			return nil, err
+		}
+
+		o, err := httputil.ReadUintQuery(r, offsetKey, defOffset)
+		if err != nil {
+			return nil, err
+		}
+
+		l, err := httputil.ReadUintQuery(r, limitKey, defLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		req := listChannelsByMetadataKeyReq{
+			key: k,
+			pageMetadata: things.PageMetadata{
+				Offset: o,
+				Limit:  l,
+			},
+		}
+
+		return req, nil
+	}
+}
+
 func encodeResponse(_ context.Context, w http.ResponseWriter, response interface{}) error {
 	if ar, ok := response.(mainflux.Response); ok {
 		for k, v := range ar.Headers() {
@@ -115,29 +169,10 @@ func encodeResponse(_ context.Context, w http.ResponseWriter, response interface
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", contentType)
 
-	switch err {
-	case things.ErrUnauthorizedAccess:
-		w.WriteHeader(http.StatusUnauthorized)
-	case things.ErrNotFound:
-		w.WriteHeader(http.StatusNotFound)
-	case things.ErrEntityConnected:
-		w.WriteHeader(http.StatusForbidden)
-
-	case errors.ErrUnsupportedContentType:
-		w.WriteHeader(http.StatusUnsupportedMediaType)
-
-	case io.ErrUnexpectedEOF:
-		w.WriteHeader(http.StatusBadRequest)
-	case io.EOF:
-		w.WriteHeader(http.StatusBadRequest)
-	default:
-		switch err.(type) {
-		case *json.SyntaxError:
-			w.WriteHeader(http.StatusBadRequest)
-		case *json.UnmarshalTypeError:
-			w.WriteHeader(http.StatusBadRequest)
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-		}
+	// Wrapped errors are reported as internal server errors.
+	if e, ok := err.(errors.Error); ok && e.Err() != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
+	w.WriteHeader(apierrors.HTTPStatus(err))
 }
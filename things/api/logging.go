@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/mainflux/mainflux/internal/reqctx"
 	log "github.com/mainflux/mainflux/logger"
 	"github.com/mainflux/mainflux/things"
 )
@@ -29,6 +30,7 @@ func LoggingMiddleware(svc things.Service, logger log.Logger) things.Service {
 func (lm *loggingMiddleware) CreateThings(ctx context.Context, token string, ths ...things.Thing) (saved []things.Thing, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method create_things for token %s and things %s took %s to complete", token, saved, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -42,6 +44,7 @@ func (lm *loggingMiddleware) CreateThings(ctx context.Context, token string, ths
 func (lm *loggingMiddleware) UpdateThing(ctx context.Context, token string, thing things.Thing) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method update_thing for token %s and thing %s took %s to complete", token, thing.ID, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -55,6 +58,7 @@ func (lm *loggingMiddleware) UpdateThing(ctx context.Context, token string, thin
 func (lm *loggingMiddleware) UpdateKey(ctx context.Context, token, id, key string) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method update_key for thing %s and key %s took %s to complete", id, key, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -68,6 +72,7 @@ func (lm *loggingMiddleware) UpdateKey(ctx context.Context, token, id, key strin
 func (lm *loggingMiddleware) ViewThing(ctx context.Context, token, id string) (thing things.Thing, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method view_thing for token %s and thing %s took %s to complete", token, id, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -85,6 +90,7 @@ func (lm *loggingMiddleware) ListThings(ctx context.Context, token string, pm th
 			nlog = fmt.Sprintf("with name %s", pm.Name)
 		}
 		message := fmt.Sprintf("Method list_things %s for token %s took %s to complete", nlog, token, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -98,6 +104,7 @@ func (lm *loggingMiddleware) ListThings(ctx context.Context, token string, pm th
 func (lm *loggingMiddleware) ListThingsByChannel(ctx context.Context, token, chID string, pm things.PageMetadata) (_ things.Page, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method list_things_by_channel for channel %s took %s to complete", chID, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s", message, err))
 		}
@@ -110,6 +117,7 @@ func (lm *loggingMiddleware) ListThingsByChannel(ctx context.Context, token, chI
 func (lm *loggingMiddleware) RemoveThing(ctx context.Context, token, id string) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method remove_thing for token %s and thing %s took %s to complete", token, id, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -123,6 +131,7 @@ func (lm *loggingMiddleware) RemoveThing(ctx context.Context, token, id string)
 func (lm *loggingMiddleware) CreateChannels(ctx context.Context, token string, channels ...things.Channel) (saved []things.Channel, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method create_channels for token %s and channels %s took %s to complete", token, saved, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -136,6 +145,7 @@ func (lm *loggingMiddleware) CreateChannels(ctx context.Context, token string, c
 func (lm *loggingMiddleware) UpdateChannel(ctx context.Context, token string, channel things.Channel) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method update_channel for token %s and channel %s took %s to complete", token, channel.ID, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -149,6 +159,7 @@ func (lm *loggingMiddleware) UpdateChannel(ctx context.Context, token string, ch
 func (lm *loggingMiddleware) ViewChannel(ctx context.Context, token, id string) (channel things.Channel, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method view_channel for token %s and channel %s took %s to complete", token, id, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -166,6 +177,7 @@ func (lm *loggingMiddleware) ListChannels(ctx context.Context, token string, pm
 			nlog = fmt.Sprintf("with name %s", pm.Name)
 		}
 		message := fmt.Sprintf("Method list_channels %s for token %s took %s to complete", nlog, token, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -176,9 +188,24 @@ func (lm *loggingMiddleware) ListChannels(ctx context.Context, token string, pm
 	return lm.svc.ListChannels(ctx, token, pm)
 }
 
+func (lm *loggingMiddleware) ListChannelsByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (_ things.ChannelsPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_channels_by_metadata_key for key %s took %s to complete", key, time.Since(begin))
+		message += reqctx.Describe(ctx)
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListChannelsByMetadataKey(ctx, key, pm)
+}
+
 func (lm *loggingMiddleware) ListChannelsByThing(ctx context.Context, token, thID string, pm things.PageMetadata) (_ things.ChannelsPage, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method list_channels_by_thing for thing %s took %s to complete", thID, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s", message, err))
 		}
@@ -191,6 +218,7 @@ func (lm *loggingMiddleware) ListChannelsByThing(ctx context.Context, token, thI
 func (lm *loggingMiddleware) RemoveChannel(ctx context.Context, token, id string) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method remove_channel for token %s and channel %s took %s to complete", token, id, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -201,9 +229,24 @@ func (lm *loggingMiddleware) RemoveChannel(ctx context.Context, token, id string
 	return lm.svc.RemoveChannel(ctx, token, id)
 }
 
+func (lm *loggingMiddleware) UpdateChannelSchema(ctx context.Context, token, id string, schema map[string]interface{}) (_ things.Channel, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method update_channel_schema for token %s and channel %s took %s to complete", token, id, time.Since(begin))
+		message += reqctx.Describe(ctx)
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.UpdateChannelSchema(ctx, token, id, schema)
+}
+
 func (lm *loggingMiddleware) Connect(ctx context.Context, token string, chIDs, thIDs []string) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method connect for token %s, channels %s and things %s took %s to complete", token, chIDs, thIDs, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -217,6 +260,7 @@ func (lm *loggingMiddleware) Connect(ctx context.Context, token string, chIDs, t
 func (lm *loggingMiddleware) Disconnect(ctx context.Context, token string, chIDs, thIDs []string) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method disconnect for token %s, channels %v and things %v took %s to complete", token, chIDs, thIDs, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -230,6 +274,7 @@ func (lm *loggingMiddleware) Disconnect(ctx context.Context, token string, chIDs
 func (lm *loggingMiddleware) CanAccessByKey(ctx context.Context, id, key string) (thing string, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method can_access for channel %s and thing %s took %s to complete", id, thing, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -243,6 +288,7 @@ func (lm *loggingMiddleware) CanAccessByKey(ctx context.Context, id, key string)
 func (lm *loggingMiddleware) CanAccessByID(ctx context.Context, chanID, thingID string) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method can_access_by_id for channel %s and thing %s took %s to complete", chanID, thingID, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -256,6 +302,7 @@ func (lm *loggingMiddleware) CanAccessByID(ctx context.Context, chanID, thingID
 func (lm *loggingMiddleware) IsChannelOwner(ctx context.Context, owner, chanID string) (err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method is_channel_owner for channel %s and user %s took %s to complete", chanID, owner, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -269,6 +316,7 @@ func (lm *loggingMiddleware) IsChannelOwner(ctx context.Context, owner, chanID s
 func (lm *loggingMiddleware) Identify(ctx context.Context, key string) (id string, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method identify for token %s and thing %s took %s to complete", key, id, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -282,6 +330,7 @@ func (lm *loggingMiddleware) Identify(ctx context.Context, key string) (id strin
 func (lm *loggingMiddleware) ListMembers(ctx context.Context, token, groupID string, pm things.PageMetadata) (tp things.Page, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method list_members for token %s and group id %s took %s to complete", token, groupID, time.Since(begin))
+		message += reqctx.Describe(ctx)
 		if err != nil {
 			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
 			return
@@ -291,3 +340,31 @@ func (lm *loggingMiddleware) ListMembers(ctx context.Context, token, groupID str
 
 	return lm.svc.ListMembers(ctx, token, groupID, pm)
 }
+
+func (lm *loggingMiddleware) ViewThingStats(ctx context.Context, token, id string) (_ things.Stats, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method view_thing_stats for token %s and thing %s took %s to complete", token, id, time.Since(begin))
+		message += reqctx.Describe(ctx)
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ViewThingStats(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) ViewChannelStats(ctx context.Context, token, id string) (_ things.Stats, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method view_channel_stats for token %s and channel %s took %s to complete", token, id, time.Since(begin))
+		message += reqctx.Describe(ctx)
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ViewChannelStats(ctx, token, id)
+}
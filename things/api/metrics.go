@@ -129,6 +129,15 @@ func (ms *metricsMiddleware) ListChannels(ctx context.Context, token string, pm
 	return ms.svc.ListChannels(ctx, token, pm)
 }
 
+func (ms *metricsMiddleware) ListChannelsByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_channels_by_metadata_key").Add(1)
+		ms.latency.With("method", "list_channels_by_metadata_key").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListChannelsByMetadataKey(ctx, key, pm)
+}
+
 func (ms *metricsMiddleware) ListChannelsByThing(ctx context.Context, token, thID string, pm things.PageMetadata) (things.ChannelsPage, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_channels_by_thing").Add(1)
@@ -147,6 +156,15 @@ func (ms *metricsMiddleware) RemoveChannel(ctx context.Context, token, id string
 	return ms.svc.RemoveChannel(ctx, token, id)
 }
 
+func (ms *metricsMiddleware) UpdateChannelSchema(ctx context.Context, token, id string, schema map[string]interface{}) (things.Channel, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "update_channel_schema").Add(1)
+		ms.latency.With("method", "update_channel_schema").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.UpdateChannelSchema(ctx, token, id, schema)
+}
+
 func (ms *metricsMiddleware) Connect(ctx context.Context, token string, chIDs, thIDs []string) error {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "connect").Add(1)
@@ -209,3 +227,21 @@ func (ms *metricsMiddleware) ListMembers(ctx context.Context, token, groupID str
 
 	return ms.svc.ListMembers(ctx, token, groupID, pm)
 }
+
+func (ms *metricsMiddleware) ViewThingStats(ctx context.Context, token, id string) (things.Stats, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "view_thing_stats").Add(1)
+		ms.latency.With("method", "view_thing_stats").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ViewThingStats(ctx, token, id)
+}
+
+func (ms *metricsMiddleware) ViewChannelStats(ctx context.Context, token, id string) (things.Stats, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "view_channel_stats").Add(1)
+		ms.latency.With("method", "view_channel_stats").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ViewChannelStats(ctx, token, id)
+}
@@ -7,7 +7,7 @@ import (
 	"context"
 
 	"github.com/go-kit/kit/endpoint"
-	"github.com/mainflux/mainflux/auth"
+	"github.com/mainflux/mainflux/internal/httputil"
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/things"
 )
@@ -159,12 +159,13 @@ func listThingsEndpoint(svc things.Service) endpoint.Endpoint {
 		}
 
 		res := thingsPageRes{
-			pageRes: pageRes{
+			PageRes: httputil.PageRes{
 				Total:  page.Total,
 				Offset: page.Offset,
 				Limit:  page.Limit,
 				Order:  page.Order,
 				Dir:    page.Dir,
+				Links:  httputil.PageLinks(ctx, page.Total, page.Offset, page.Limit),
 			},
 			Things: []viewThingRes{},
 		}
@@ -197,10 +198,11 @@ func listThingsByChannelEndpoint(svc things.Service) endpoint.Endpoint {
 		}
 
 		res := thingsPageRes{
-			pageRes: pageRes{
+			PageRes: httputil.PageRes{
 				Total:  page.Total,
 				Offset: page.Offset,
 				Limit:  page.Limit,
+				Links:  httputil.PageLinks(ctx, page.Total, page.Offset, page.Limit),
 			},
 			Things: []viewThingRes{},
 		}
@@ -327,6 +329,42 @@ func updateChannelEndpoint(svc things.Service) endpoint.Endpoint {
 	}
 }
 
+func updateChannelSchemaEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateChannelSchemaReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if _, err := svc.UpdateChannelSchema(ctx, req.token, req.id, req.schema); err != nil {
+			return nil, err
+		}
+
+		res := channelRes{
+			ID:      req.id,
+			created: false,
+		}
+		return res, nil
+	}
+}
+
+func removeChannelSchemaEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		if _, err := svc.UpdateChannelSchema(ctx, req.token, req.id, nil); err != nil {
+			return nil, err
+		}
+
+		return removeRes{}, nil
+	}
+}
+
 func viewChannelEndpoint(svc things.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(viewResourceReq)
@@ -351,6 +389,58 @@ func viewChannelEndpoint(svc things.Service) endpoint.Endpoint {
 	}
 }
 
+func viewThingStatsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		stats, err := svc.ViewThingStats(ctx, req.token, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		res := toStatsRes(stats)
+		for _, ch := range stats.Channels {
+			res.Channels = append(res.Channels, channelActivityRes{ID: ch.ID, LastSeen: ch.LastSeen})
+		}
+		return res, nil
+	}
+}
+
+func viewChannelStatsEndpoint(svc things.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(viewResourceReq)
+
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		stats, err := svc.ViewChannelStats(ctx, req.token, req.id)
+		if err != nil {
+			return nil, err
+		}
+
+		return toStatsRes(stats), nil
+	}
+}
+
+func toStatsRes(stats things.Stats) statsRes {
+	res := statsRes{
+		ID:         stats.ID,
+		Stale:      stats.Stale,
+		Protocol:   stats.Protocol,
+		DailyCount: stats.DailyCount,
+	}
+	if !stats.Stale {
+		lastSeen := stats.LastSeen
+		res.LastSeen = &lastSeen
+	}
+	return res
+}
+
 func listChannelsEndpoint(svc things.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listResourcesReq)
@@ -365,12 +455,13 @@ func listChannelsEndpoint(svc things.Service) endpoint.Endpoint {
 		}
 
 		res := channelsPageRes{
-			pageRes: pageRes{
+			PageRes: httputil.PageRes{
 				Total:  page.Total,
 				Offset: page.Offset,
 				Limit:  page.Limit,
 				Order:  page.Order,
 				Dir:    page.Dir,
+				Links:  httputil.PageLinks(ctx, page.Total, page.Offset, page.Limit),
 			},
 			Channels: []viewChannelRes{},
 		}
@@ -404,10 +495,11 @@ func listChannelsByThingEndpoint(svc things.Service) endpoint.Endpoint {
 		}
 
 		res := channelsPageRes{
-			pageRes: pageRes{
+			PageRes: httputil.PageRes{
 				Total:  page.Total,
 				Offset: page.Offset,
 				Limit:  page.Limit,
+				Links:  httputil.PageLinks(ctx, page.Total, page.Offset, page.Limit),
 			},
 			Channels: []viewChannelRes{},
 		}
@@ -511,7 +603,7 @@ func listMembersEndpoint(svc things.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(listThingsGroupReq)
 		if err := req.validate(); err != nil {
-			return thingsPageRes{}, errors.Wrap(auth.ErrMalformedEntity, err)
+			return thingsPageRes{}, errors.Wrap(things.ErrMalformedEntity, err)
 		}
 
 		page, err := svc.ListMembers(ctx, req.token, req.groupID, req.pageMetadata)
@@ -519,16 +611,17 @@ func listMembersEndpoint(svc things.Service) endpoint.Endpoint {
 			return thingsPageRes{}, err
 		}
 
-		return buildThingsResponse(page), nil
+		return buildThingsResponse(ctx, page), nil
 	}
 }
 
-func buildThingsResponse(up things.Page) thingsPageRes {
+func buildThingsResponse(ctx context.Context, up things.Page) thingsPageRes {
 	res := thingsPageRes{
-		pageRes: pageRes{
+		PageRes: httputil.PageRes{
 			Total:  up.Total,
 			Offset: up.Offset,
 			Limit:  up.Limit,
+			Links:  httputil.PageLinks(ctx, up.Total, up.Offset, up.Limit),
 		},
 		Things: []viewThingRes{},
 	}
@@ -11,11 +11,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/mainflux/mainflux/pkg/messaging"
 	"github.com/mainflux/mainflux/pkg/uuid"
 	"github.com/mainflux/mainflux/things"
 	httpapi "github.com/mainflux/mainflux/things/api/things/http"
@@ -78,7 +80,25 @@ func (tr testRequest) make() (*http.Response, error) {
 	return tr.client.Do(req)
 }
 
+// publish records the message as published by the thing to the channel at
+// the given time.
+func publish(t *testing.T, repo things.StatsRepository, thingID, chanID, protocol string, at time.Time) {
+	handle := things.NewStatsHandler(repo)
+	msg := messaging.Message{
+		Channel:   chanID,
+		Publisher: thingID,
+		Protocol:  protocol,
+		Created:   at.UnixNano(),
+	}
+	err := handle(msg)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+}
+
 func newService(tokens map[string]string) things.Service {
+	return newServiceWithStats(tokens, mocks.NewStatsRepository())
+}
+
+func newServiceWithStats(tokens map[string]string, stats things.StatsRepository) things.Service {
 	auth := mocks.NewAuthService(tokens)
 	conns := make(chan mocks.Connection)
 	thingsRepo := mocks.NewThingRepository(conns)
@@ -87,11 +107,11 @@ func newService(tokens map[string]string) things.Service {
 	thingCache := mocks.NewThingCache()
 	idProvider := uuid.NewMock()
 
-	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, idProvider)
+	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, stats, idProvider, idProvider)
 }
 
 func newServer(svc things.Service) *httptest.Server {
-	mux := httpapi.MakeHandler(mocktracer.New(), svc)
+	mux := httpapi.MakeHandler(mocktracer.New(), svc, nil)
 	return httptest.NewServer(mux)
 }
 
@@ -637,6 +657,99 @@ func TestViewThing(t *testing.T) {
 	}
 }
 
+func TestViewThingStats(t *testing.T) {
+	stats := mocks.NewStatsRepository()
+	svc := newServiceWithStats(map[string]string{token: email}, stats)
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ths, err := svc.CreateThings(context.Background(), token, thing, thing)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	th, idle := ths[0], ths[1]
+	chs, err := svc.CreateChannels(context.Background(), token, channel, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch1, ch2 := chs[0], chs[1]
+
+	now := time.Unix(0, time.Now().UnixNano())
+	first, last := now.Add(-2*time.Hour), now.Add(-time.Hour)
+	publish(t, stats, th.ID, ch1.ID, "http", now.Add(-48*time.Hour))
+	publish(t, stats, th.ID, ch1.ID, "http", first)
+	publish(t, stats, th.ID, ch2.ID, "mqtt", last)
+	publish(t, stats, "", ch2.ID, "mqtt", now)
+
+	data := toJSON(statsRes{
+		ID:         th.ID,
+		LastSeen:   &last,
+		Protocol:   "mqtt",
+		DailyCount: 2,
+		Channels: []channelActivityRes{
+			{ID: ch2.ID, LastSeen: last},
+			{ID: ch1.ID, LastSeen: first},
+		},
+	})
+	staleData := toJSON(statsRes{ID: idle.ID, Stale: true})
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+		res    string
+	}{
+		{
+			desc:   "view stats of thing that published",
+			id:     th.ID,
+			auth:   token,
+			status: http.StatusOK,
+			res:    data,
+		},
+		{
+			desc:   "view stats of thing that didn't publish",
+			id:     idle.ID,
+			auth:   token,
+			status: http.StatusOK,
+			res:    staleData,
+		},
+		{
+			desc:   "view stats of non-existent thing",
+			id:     strconv.FormatUint(wrongID, 10),
+			auth:   token,
+			status: http.StatusNotFound,
+			res:    notFoundRes,
+		},
+		{
+			desc:   "view thing stats with invalid token",
+			id:     th.ID,
+			auth:   wrongValue,
+			status: http.StatusUnauthorized,
+			res:    unauthRes,
+		},
+		{
+			desc:   "view thing stats with empty token",
+			id:     th.ID,
+			auth:   "",
+			status: http.StatusUnauthorized,
+			res:    unauthRes,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things/%s/stats", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		data, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body := strings.Trim(string(data), "\n")
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res, body, fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, body))
+	}
+}
+
 func TestListThings(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
@@ -842,6 +955,81 @@ func TestListThings(t *testing.T) {
 	}
 }
 
+func TestListThingsLinks(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	for i := 0; i < 25; i++ {
+		_, err := svc.CreateThings(context.Background(), token, thing)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	filters := url.Values{
+		"name":     []string{thing.Name},
+		"metadata": []string{`{"test":"data"}`},
+		"order":    []string{"name"},
+		"dir":      []string{"desc"},
+	}
+	cases := []struct {
+		desc   string
+		offset uint64
+		next   string
+		prev   string
+	}{
+		{
+			desc:   "get links of the first page",
+			offset: 0,
+			next:   "10",
+		},
+		{
+			desc:   "get links of the middle page",
+			offset: 10,
+			next:   "20",
+			prev:   "0",
+		},
+		{
+			desc:   "get links of the last page",
+			offset: 20,
+			prev:   "10",
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/things?%s&offset=%d&limit=%d", ts.URL, filters.Encode(), tc.offset, 10),
+			token:  token,
+		}
+		res, err := req.make()
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var data thingsPageRes
+		json.NewDecoder(res.Body).Decode(&data)
+		assert.Equal(t, http.StatusOK, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, http.StatusOK, res.StatusCode))
+
+		for rel, link := range map[string]struct{ offset, got string }{
+			"next": {tc.next, data.Next},
+			"prev": {tc.prev, data.Prev},
+		} {
+			if link.offset == "" {
+				assert.Empty(t, link.got, fmt.Sprintf("%s: expected no %s link got %s", tc.desc, rel, link.got))
+				assert.NotContains(t, res.Header.Get("Link"), fmt.Sprintf(`rel="%s"`, rel), fmt.Sprintf("%s: expected no %s link header", tc.desc, rel))
+				continue
+			}
+			u, err := url.Parse(link.got)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+			expected := url.Values{"offset": []string{link.offset}, "limit": []string{"10"}}
+			for k, v := range filters {
+				expected[k] = v
+			}
+			assert.Equal(t, "/things", u.Path, fmt.Sprintf("%s: expected %s link path /things got %s", tc.desc, rel, u.Path))
+			assert.Equal(t, expected, u.Query(), fmt.Sprintf("%s: expected %s link query %v got %v", tc.desc, rel, expected, u.Query()))
+			assert.Contains(t, res.Header.Get("Link"), fmt.Sprintf(`<%s>; rel="%s"`, link.got, rel), fmt.Sprintf("%s: expected %s link header", tc.desc, rel))
+		}
+	}
+}
+
 func TestSearchThings(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
@@ -1593,6 +1781,175 @@ func TestUpdateChannel(t *testing.T) {
 	}
 }
 
+func TestUpdateChannelSchema(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	chs, err := svc.CreateChannels(context.Background(), token, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch := chs[0]
+
+	schema := `{"type": "object", "required": ["temperature"], "properties": {"temperature": {"type": "number"}}}`
+
+	cases := []struct {
+		desc        string
+		req         string
+		id          string
+		contentType string
+		auth        string
+		status      int
+	}{
+		{
+			desc:        "update channel schema",
+			req:         schema,
+			id:          ch.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusOK,
+		},
+		{
+			desc:        "update non-existing channel schema",
+			req:         schema,
+			id:          strconv.FormatUint(wrongID, 10),
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusNotFound,
+		},
+		{
+			desc:        "update channel schema with invalid token",
+			req:         schema,
+			id:          ch.ID,
+			contentType: contentType,
+			auth:        wrongValue,
+			status:      http.StatusUnauthorized,
+		},
+		{
+			desc:        "update channel schema with empty token",
+			req:         schema,
+			id:          ch.ID,
+			contentType: contentType,
+			auth:        "",
+			status:      http.StatusUnauthorized,
+		},
+		{
+			desc:        "update channel with invalid schema",
+			req:         `{"type": "float"}`,
+			id:          ch.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "update channel with schema using unsupported keyword",
+			req:         `{"anyOf": [{"type": "string"}, {"type": "number"}]}`,
+			id:          ch.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "update channel schema with invalid data format",
+			req:         "}",
+			id:          ch.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "update channel schema with null schema",
+			req:         "null",
+			id:          ch.ID,
+			contentType: contentType,
+			auth:        token,
+			status:      http.StatusBadRequest,
+		},
+		{
+			desc:        "update channel schema with missing content type",
+			req:         schema,
+			id:          ch.ID,
+			contentType: "",
+			auth:        token,
+			status:      http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client:      ts.Client(),
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("%s/channels/%s/schema", ts.URL, tc.id),
+			contentType: tc.contentType,
+			token:       tc.auth,
+			body:        strings.NewReader(tc.req),
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+}
+
+func TestRemoveChannelSchema(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ts := newServer(svc)
+	defer ts.Close()
+
+	chs, err := svc.CreateChannels(context.Background(), token, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch := chs[0]
+	_, err = svc.UpdateChannelSchema(context.Background(), token, ch.ID, map[string]interface{}{"type": "object"})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+	}{
+		{
+			desc:   "remove channel schema with invalid token",
+			id:     ch.ID,
+			auth:   wrongValue,
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "remove channel schema",
+			id:     ch.ID,
+			auth:   token,
+			status: http.StatusNoContent,
+		},
+		{
+			desc:   "remove removed channel schema",
+			id:     ch.ID,
+			auth:   token,
+			status: http.StatusNoContent,
+		},
+		{
+			desc:   "remove non-existing channel schema",
+			id:     strconv.FormatUint(wrongID, 10),
+			auth:   token,
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("%s/channels/%s/schema", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+	}
+
+	saved, err := svc.ViewChannel(context.Background(), token, ch.ID)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	_, ok := saved.Metadata[things.SchemaKey]
+	assert.False(t, ok, "expected channel schema to be removed")
+}
+
 func TestViewChannel(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
@@ -1674,6 +2031,93 @@ func TestViewChannel(t *testing.T) {
 	}
 }
 
+func TestViewChannelStats(t *testing.T) {
+	stats := mocks.NewStatsRepository()
+	svc := newServiceWithStats(map[string]string{token: email}, stats)
+	ts := newServer(svc)
+	defer ts.Close()
+
+	ths, err := svc.CreateThings(context.Background(), token, thing, thing)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	th1, th2 := ths[0], ths[1]
+	chs, err := svc.CreateChannels(context.Background(), token, channel, channel)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch, idle := chs[0], chs[1]
+
+	now := time.Unix(0, time.Now().UnixNano())
+	last := now.Add(-time.Hour)
+	publish(t, stats, th1.ID, ch.ID, "http", now.Add(-2*time.Hour))
+	publish(t, stats, th2.ID, ch.ID, "coap", last)
+
+	data := toJSON(statsRes{
+		ID:         ch.ID,
+		LastSeen:   &last,
+		Protocol:   "coap",
+		DailyCount: 2,
+	})
+	staleData := toJSON(statsRes{ID: idle.ID, Stale: true})
+
+	cases := []struct {
+		desc   string
+		id     string
+		auth   string
+		status int
+		res    string
+	}{
+		{
+			desc:   "view stats of channel published to",
+			id:     ch.ID,
+			auth:   token,
+			status: http.StatusOK,
+			res:    data,
+		},
+		{
+			desc:   "view stats of channel not published to",
+			id:     idle.ID,
+			auth:   token,
+			status: http.StatusOK,
+			res:    staleData,
+		},
+		{
+			desc:   "view stats of non-existent channel",
+			id:     strconv.FormatUint(wrongID, 10),
+			auth:   token,
+			status: http.StatusNotFound,
+			res:    notFoundRes,
+		},
+		{
+			desc:   "view channel stats with invalid token",
+			id:     ch.ID,
+			auth:   wrongValue,
+			status: http.StatusUnauthorized,
+			res:    unauthRes,
+		},
+		{
+			desc:   "view channel stats with empty token",
+			id:     ch.ID,
+			auth:   "",
+			status: http.StatusUnauthorized,
+			res:    unauthRes,
+		},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: ts.Client(),
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/channels/%s/stats", ts.URL, tc.id),
+			token:  tc.auth,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		data, err := ioutil.ReadAll(res.Body)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		body := strings.Trim(string(data), "\n")
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		assert.Equal(t, tc.res, body, fmt.Sprintf("%s: expected body %s got %s", tc.desc, tc.res, body))
+	}
+}
+
 func TestListChannels(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	ts := newServer(svc)
@@ -2720,6 +3164,8 @@ type thingsPageRes struct {
 	Total  uint64     `json:"total"`
 	Offset uint64     `json:"offset"`
 	Limit  uint64     `json:"limit"`
+	Next   string     `json:"next"`
+	Prev   string     `json:"prev"`
 }
 
 type channelsPageRes struct {
@@ -2729,6 +3175,20 @@ type channelsPageRes struct {
 	Limit    uint64       `json:"limit"`
 }
 
+type channelActivityRes struct {
+	ID       string    `json:"id"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+type statsRes struct {
+	ID         string               `json:"id"`
+	Stale      bool                 `json:"stale"`
+	LastSeen   *time.Time           `json:"last_seen,omitempty"`
+	Protocol   string               `json:"protocol,omitempty"`
+	DailyCount uint64               `json:"daily_count"`
+	Channels   []channelActivityRes `json:"channels,omitempty"`
+}
+
 type errorRes struct {
 	Err string `json:"error"`
 }
@@ -164,6 +164,24 @@ func (req updateChannelReq) validate() error {
 	return nil
 }
 
+type updateChannelSchemaReq struct {
+	token  string
+	id     string
+	schema map[string]interface{}
+}
+
+func (req updateChannelSchemaReq) validate() error {
+	if req.token == "" {
+		return things.ErrUnauthorizedAccess
+	}
+
+	if req.id == "" || req.schema == nil {
+		return things.ErrMalformedEntity
+	}
+
+	return nil
+}
+
 type viewResourceReq struct {
 	token string
 	id    string
@@ -6,8 +6,10 @@ package http
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/mainflux/mainflux"
+	"github.com/mainflux/mainflux/internal/httputil"
 )
 
 var (
@@ -22,6 +24,7 @@ var (
 	_ mainflux.Response = (*connectRes)(nil)
 	_ mainflux.Response = (*disconnectThingRes)(nil)
 	_ mainflux.Response = (*disconnectRes)(nil)
+	_ mainflux.Response = (*statsRes)(nil)
 )
 
 type removeRes struct{}
@@ -111,7 +114,7 @@ func (res viewThingRes) Empty() bool {
 }
 
 type thingsPageRes struct {
-	pageRes
+	httputil.PageRes
 	Things []viewThingRes `json:"things"`
 }
 
@@ -120,7 +123,7 @@ func (res thingsPageRes) Code() int {
 }
 
 func (res thingsPageRes) Headers() map[string]string {
-	return map[string]string{}
+	return res.Links.Headers()
 }
 
 func (res thingsPageRes) Empty() bool {
@@ -199,7 +202,7 @@ func (res viewChannelRes) Empty() bool {
 }
 
 type channelsPageRes struct {
-	pageRes
+	httputil.PageRes
 	Channels []viewChannelRes `json:"channels"`
 }
 
@@ -208,7 +211,7 @@ func (res channelsPageRes) Code() int {
 }
 
 func (res channelsPageRes) Headers() map[string]string {
-	return map[string]string{}
+	return res.Links.Headers()
 }
 
 func (res channelsPageRes) Empty() bool {
@@ -273,14 +276,34 @@ func (res disconnectThingRes) Empty() bool {
 	return true
 }
 
-type pageRes struct {
-	Total  uint64 `json:"total"`
-	Offset uint64 `json:"offset"`
-	Limit  uint64 `json:"limit"`
-	Order  string `json:"order"`
-	Dir    string `json:"direction"`
-}
-
 type errorRes struct {
 	Err string `json:"error"`
 }
+
+type channelActivityRes struct {
+	ID       string    `json:"id"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// statsRes is stale if no statistics are recorded, in which case the last
+// seen time is omitted.
+type statsRes struct {
+	ID         string               `json:"id"`
+	Stale      bool                 `json:"stale"`
+	LastSeen   *time.Time           `json:"last_seen,omitempty"`
+	Protocol   string               `json:"protocol,omitempty"`
+	DailyCount uint64               `json:"daily_count"`
+	Channels   []channelActivityRes `json:"channels,omitempty"`
+}
+
+func (res statsRes) Code() int {
+	return http.StatusOK
+}
+
+func (res statsRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res statsRes) Empty() bool {
+	return false
+}
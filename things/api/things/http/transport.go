@@ -6,7 +6,6 @@ package http
 import (
 	"context"
 	"encoding/json"
-	"io"
 	"net/http"
 	"strings"
 
@@ -14,8 +13,9 @@ import (
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/go-zoo/bone"
 	"github.com/mainflux/mainflux"
-	"github.com/mainflux/mainflux/auth"
+	"github.com/mainflux/mainflux/internal/apierrors"
 	"github.com/mainflux/mainflux/internal/httputil"
+	"github.com/mainflux/mainflux/internal/reqctx"
 	"github.com/mainflux/mainflux/pkg/errors"
 	"github.com/mainflux/mainflux/things"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -35,10 +35,13 @@ const (
 	defLimit    = 10
 )
 
-// MakeHandler returns a HTTP handler for API endpoints.
-func MakeHandler(tracer opentracing.Tracer, svc things.Service) http.Handler {
+// MakeHandler returns a HTTP handler for API endpoints. The remote address
+// of the requests sent by the trusted proxies is taken from the
+// X-Forwarded-For header.
+func MakeHandler(tracer opentracing.Tracer, svc things.Service, proxies reqctx.Proxies) http.Handler {
 	opts := []kithttp.ServerOption{
 		kithttp.ServerErrorEncoder(encodeError),
+		kithttp.ServerBefore(httputil.WithRequestURL),
 	}
 
 	r := bone.New()
@@ -85,6 +88,13 @@ func MakeHandler(tracer opentracing.Tracer, svc things.Service) http.Handler {
 		opts...,
 	))
 
+	r.Get("/things/:id/stats", kithttp.NewServer(
+		kitot.TraceServer(tracer, "view_thing_stats")(viewThingStatsEndpoint(svc)),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Get("/things/:id/channels", kithttp.NewServer(
 		kitot.TraceServer(tracer, "list_channels_by_thing")(listChannelsByThingEndpoint(svc)),
 		decodeListByConnection,
@@ -141,6 +151,27 @@ func MakeHandler(tracer opentracing.Tracer, svc things.Service) http.Handler {
 		opts...,
 	))
 
+	r.Put("/channels/:id/schema", kithttp.NewServer(
+		kitot.TraceServer(tracer, "update_channel_schema")(updateChannelSchemaEndpoint(svc)),
+		decodeChannelSchema,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Delete("/channels/:id/schema", kithttp.NewServer(
+		kitot.TraceServer(tracer, "remove_channel_schema")(removeChannelSchemaEndpoint(svc)),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
+	r.Get("/channels/:id/stats", kithttp.NewServer(
+		kitot.TraceServer(tracer, "view_channel_stats")(viewChannelStatsEndpoint(svc)),
+		decodeView,
+		encodeResponse,
+		opts...,
+	))
+
 	r.Get("/channels/:id/things", kithttp.NewServer(
 		kitot.TraceServer(tracer, "list_things_by_channel")(listThingsByChannelEndpoint(svc)),
 		decodeListByConnection,
@@ -193,7 +224,7 @@ func MakeHandler(tracer opentracing.Tracer, svc things.Service) http.Handler {
 	r.GetFunc("/version", mainflux.Version("things"))
 	r.Handle("/metrics", promhttp.Handler())
 
-	return r
+	return reqctx.Middleware(r, proxies)
 }
 
 func decodeThingCreation(_ context.Context, r *http.Request) (interface{}, error) {
@@ -297,6 +328,22 @@ func decodeChannelUpdate(_ context.Context, r *http.Request) (interface{}, error
 	return req, nil
 }
 
+func decodeChannelSchema(_ context.Context, r *http.Request) (interface{}, error) {
+	if !strings.Contains(r.Header.Get("Content-Type"), contentType) {
+		return nil, errors.ErrUnsupportedContentType
+	}
+
+	req := updateChannelSchemaReq{
+		token: r.Header.Get("Authorization"),
+		id:    bone.GetValue(r, "id"),
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req.schema); err != nil {
+		return nil, errors.Wrap(things.ErrMalformedEntity, err)
+	}
+
+	return req, nil
+}
+
 func decodeView(_ context.Context, r *http.Request) (interface{}, error) {
 	req := viewResourceReq{
 		token: r.Header.Get("Authorization"),
@@ -475,46 +522,7 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	switch errorVal := err.(type) {
 	case errors.Error:
 		w.Header().Set("Content-Type", contentType)
-		switch {
-		case errors.Contains(errorVal, things.ErrUnauthorizedAccess),
-			errors.Contains(errorVal, things.ErrEntityConnected):
-			w.WriteHeader(http.StatusUnauthorized)
-
-		case errors.Contains(errorVal, errors.ErrInvalidQueryParams):
-			w.WriteHeader(http.StatusBadRequest)
-		case errors.Contains(errorVal, errors.ErrUnsupportedContentType):
-			w.WriteHeader(http.StatusUnsupportedMediaType)
-
-		case errors.Contains(errorVal, things.ErrMalformedEntity):
-			w.WriteHeader(http.StatusBadRequest)
-		case errors.Contains(errorVal, things.ErrNotFound):
-			w.WriteHeader(http.StatusNotFound)
-		case errors.Contains(errorVal, things.ErrConflict):
-			w.WriteHeader(http.StatusConflict)
-
-		case errors.Contains(errorVal, things.ErrScanMetadata),
-			errors.Contains(errorVal, things.ErrSelectEntity):
-			w.WriteHeader(http.StatusUnprocessableEntity)
-
-		case errors.Contains(errorVal, things.ErrCreateEntity),
-			errors.Contains(errorVal, things.ErrUpdateEntity),
-			errors.Contains(errorVal, things.ErrViewEntity),
-			errors.Contains(errorVal, things.ErrRemoveEntity),
-			errors.Contains(errorVal, things.ErrConnect),
-			errors.Contains(errorVal, things.ErrDisconnect),
-			errors.Contains(errorVal, auth.ErrCreateGroup):
-			w.WriteHeader(http.StatusBadRequest)
-
-		case errors.Contains(errorVal, io.ErrUnexpectedEOF),
-			errors.Contains(errorVal, io.EOF):
-			w.WriteHeader(http.StatusBadRequest)
-
-		case errors.Contains(errorVal, things.ErrCreateUUID):
-			w.WriteHeader(http.StatusInternalServerError)
-
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-		}
+		w.WriteHeader(apierrors.HTTPStatus(errorVal))
 		if errorVal.Msg() != "" {
 			if err := json.NewEncoder(w).Encode(errorRes{Err: errorVal.Msg()}); err != nil {
 				w.WriteHeader(http.StatusInternalServerError)
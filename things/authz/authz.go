@@ -0,0 +1,239 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package authz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	defTimeout          = 100 * time.Millisecond
+	defBreakerThreshold = 5
+	defBreakerTimeout   = 30 * time.Second
+	maxCacheSize        = 10000
+)
+
+// Mode represents the way the local connection check and the external
+// decision are combined.
+type Mode string
+
+const (
+	// DenyOverrides allows the access only if the thing is connected to
+	// the channel and the external authorizer allows it.
+	DenyOverrides Mode = "deny-overrides"
+
+	// ExternalOnly ignores the connections, so the access is decided by the
+	// external authorizer. The thing key is still verified locally.
+	ExternalOnly Mode = "external-only"
+)
+
+var (
+	// ErrAccessDenied indicates the access denied by the external authorizer.
+	ErrAccessDenied = apierrors.Authorization("access denied by external authorizer")
+
+	// ErrUnavailable indicates the external authorizer that didn't respond
+	// with the decision.
+	ErrUnavailable = apierrors.Unavailable("external authorizer unavailable")
+
+	// ErrBreakerOpen indicates the external authorizer not called, since it
+	// failed too many times in a row.
+	ErrBreakerOpen = errors.New("external authorizer circuit breaker is open")
+
+	// ErrInvalidMode indicates unsupported decisions combining mode.
+	ErrInvalidMode = errors.New("invalid external authorization mode")
+)
+
+// Request represents the access decision sent to the external authorizer.
+type Request struct {
+	Subject  string `json:"subject"`
+	Channel  string `json:"channel"`
+	Action   string `json:"action,omitempty"`
+	Subtopic string `json:"subtopic,omitempty"`
+}
+
+type decision struct {
+	Allow bool `json:"allow"`
+}
+
+// Config represents the external authorizer settings.
+type Config struct {
+	// URL is the endpoint the requests are POSTed to. It's expected to
+	// respond with 2xx status and {"allow": <bool>} body, any other response
+	// is considered a failure.
+	URL string
+
+	// Mode is the way local and external decisions are combined.
+	Mode Mode
+
+	// Timeout is the latency budget of the single decision.
+	Timeout time.Duration
+
+	// CacheTTL is the period the decisions are reused for. Zero disables
+	// the cache. Failures are never cached.
+	CacheTTL time.Duration
+
+	// FailOpen allows the access, subject to the local check in the
+	// deny-overrides mode, when the decision can't be obtained.
+	FailOpen bool
+
+	// BreakerThreshold is the number of consecutive failures that opens the
+	// breaker, so the authorizer isn't called until the BreakerTimeout
+	// passes.
+	BreakerThreshold int
+	BreakerTimeout   time.Duration
+
+	// HTTPClient is used to call the authorizer, http.DefaultClient if nil.
+	HTTPClient *http.Client
+}
+
+// Authorizer specifies the external access decision API.
+type Authorizer interface {
+	// Authorize returns nil if the access is allowed, ErrAccessDenied if
+	// it's denied, or the error wrapping ErrUnavailable if the decision
+	// can't be obtained.
+	Authorize(ctx context.Context, req Request) error
+}
+
+var _ Authorizer = (*webhook)(nil)
+
+type cacheEntry struct {
+	allow   bool
+	expires time.Time
+}
+
+type webhook struct {
+	cfg     Config
+	client  *http.Client
+	breaker *breaker
+
+	mu    sync.Mutex
+	cache map[Request]cacheEntry
+}
+
+// NewAuthorizer returns the authorizer POSTing requests to the configured
+// URL. The decisions are cached and the calls are guarded by the timeout and
+// the circuit breaker, since they're made on every publish.
+func NewAuthorizer(cfg Config) Authorizer {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defTimeout
+	}
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = defBreakerThreshold
+	}
+	if cfg.BreakerTimeout <= 0 {
+		cfg.BreakerTimeout = defBreakerTimeout
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &webhook{
+		cfg:     cfg,
+		client:  client,
+		breaker: newBreaker(cfg.BreakerThreshold, cfg.BreakerTimeout),
+		cache:   make(map[Request]cacheEntry),
+	}
+}
+
+func (wh *webhook) Authorize(ctx context.Context, req Request) error {
+	if allow, ok := wh.cached(req); ok {
+		return result(allow)
+	}
+
+	if !wh.breaker.allow() {
+		return errors.Wrap(ErrUnavailable, ErrBreakerOpen)
+	}
+	allow, err := wh.call(ctx, req)
+	if err != nil {
+		wh.breaker.failure()
+		return errors.Wrap(ErrUnavailable, err)
+	}
+	wh.breaker.success()
+
+	wh.save(req, allow)
+	return result(allow)
+}
+
+func (wh *webhook) call(ctx context.Context, req Request) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, wh.cfg.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return false, err
+	}
+	r, err := http.NewRequest(http.MethodPost, wh.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	res, err := wh.client.Do(r.WithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return false, fmt.Errorf("unexpected response status %d", res.StatusCode)
+	}
+	var d decision
+	if err := json.NewDecoder(res.Body).Decode(&d); err != nil {
+		return false, err
+	}
+	return d.Allow, nil
+}
+
+func (wh *webhook) cached(req Request) (bool, bool) {
+	if wh.cfg.CacheTTL <= 0 {
+		return false, false
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	e, ok := wh.cache[req]
+	if !ok || time.Now().After(e.expires) {
+		return false, false
+	}
+	return e.allow, true
+}
+
+func (wh *webhook) save(req Request, allow bool) {
+	if wh.cfg.CacheTTL <= 0 {
+		return
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	now := time.Now()
+	if len(wh.cache) >= maxCacheSize {
+		for k, e := range wh.cache {
+			if now.After(e.expires) {
+				delete(wh.cache, k)
+			}
+		}
+	}
+	// Too many live decisions are dropped rather than growing the cache.
+	if len(wh.cache) >= maxCacheSize {
+		wh.cache = make(map[Request]cacheEntry)
+	}
+	wh.cache[req] = cacheEntry{allow: allow, expires: now.Add(wh.cfg.CacheTTL)}
+}
+
+func result(allow bool) error {
+	if !allow {
+		return ErrAccessDenied
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package authz_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things/authz"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	allowed = "allowed"
+	denied  = "denied"
+	slow    = "slow"
+	broken  = "broken"
+)
+
+// policy is the external authorizer allowing the allowed subject, delaying
+// the decision for the slow one and failing for the broken one.
+type policy struct {
+	mu       sync.Mutex
+	requests []authz.Request
+	delay    time.Duration
+}
+
+func (p *policy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req authz.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	p.mu.Lock()
+	p.requests = append(p.requests, req)
+	p.mu.Unlock()
+
+	switch req.Subject {
+	case slow:
+		time.Sleep(p.delay)
+	case broken:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"allow": req.Subject == allowed || req.Subject == slow})
+}
+
+func (p *policy) calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.requests)
+}
+
+func (p *policy) last() authz.Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.requests) == 0 {
+		return authz.Request{}
+	}
+	return p.requests[len(p.requests)-1]
+}
+
+func newPolicy(t *testing.T) (*policy, *httptest.Server) {
+	p := &policy{delay: 200 * time.Millisecond}
+	ts := httptest.NewServer(p)
+	t.Cleanup(ts.Close)
+	return p, ts
+}
+
+func TestAuthorize(t *testing.T) {
+	p, ts := newPolicy(t)
+	az := authz.NewAuthorizer(authz.Config{
+		URL:              ts.URL,
+		Timeout:          50 * time.Millisecond,
+		BreakerThreshold: 100,
+	})
+
+	cases := []struct {
+		desc string
+		req  authz.Request
+		err  error
+	}{
+		{
+			desc: "authorize allowed access",
+			req:  authz.Request{Subject: allowed, Channel: "1", Action: authz.Publish, Subtopic: "a.b"},
+			err:  nil,
+		},
+		{
+			desc: "authorize denied access",
+			req:  authz.Request{Subject: denied, Channel: "1", Action: authz.Publish},
+			err:  authz.ErrAccessDenied,
+		},
+		{
+			desc: "authorize access exceeding timeout",
+			req:  authz.Request{Subject: slow, Channel: "1", Action: authz.Subscribe},
+			err:  authz.ErrUnavailable,
+		},
+		{
+			desc: "authorize access with failed authorizer",
+			req:  authz.Request{Subject: broken, Channel: "1"},
+			err:  authz.ErrUnavailable,
+		},
+	}
+
+	for _, tc := range cases {
+		start := time.Now()
+		err := az.Authorize(context.Background(), tc.req)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.req, p.last(), fmt.Sprintf("%s: expected request %v got %v", tc.desc, tc.req, p.last()))
+		assert.Less(t, int64(time.Since(start)), int64(p.delay), fmt.Sprintf("%s: expected decision within timeout", tc.desc))
+	}
+}
+
+func TestAuthorizeUnreachable(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+
+	az := authz.NewAuthorizer(authz.Config{URL: url})
+	err := az.Authorize(context.Background(), authz.Request{Subject: allowed, Channel: "1"})
+	assert.True(t, errors.Contains(err, authz.ErrUnavailable), fmt.Sprintf("expected error %s got %s", authz.ErrUnavailable, err))
+}
+
+func TestAuthorizeCache(t *testing.T) {
+	p, ts := newPolicy(t)
+	az := authz.NewAuthorizer(authz.Config{
+		URL:      ts.URL,
+		CacheTTL: 100 * time.Millisecond,
+	})
+
+	cases := []struct {
+		desc  string
+		req   authz.Request
+		err   error
+		calls int
+	}{
+		{
+			desc:  "authorize allowed access",
+			req:   authz.Request{Subject: allowed, Channel: "1", Action: authz.Publish},
+			err:   nil,
+			calls: 1,
+		},
+		{
+			desc:  "authorize cached allowed access",
+			req:   authz.Request{Subject: allowed, Channel: "1", Action: authz.Publish},
+			err:   nil,
+			calls: 1,
+		},
+		{
+			desc:  "authorize allowed access with different action",
+			req:   authz.Request{Subject: allowed, Channel: "1", Action: authz.Subscribe},
+			err:   nil,
+			calls: 2,
+		},
+		{
+			desc:  "authorize denied access",
+			req:   authz.Request{Subject: denied, Channel: "1"},
+			err:   authz.ErrAccessDenied,
+			calls: 3,
+		},
+		{
+			desc:  "authorize cached denied access",
+			req:   authz.Request{Subject: denied, Channel: "1"},
+			err:   authz.ErrAccessDenied,
+			calls: 3,
+		},
+		{
+			desc:  "authorize access with failed authorizer",
+			req:   authz.Request{Subject: broken, Channel: "1"},
+			err:   authz.ErrUnavailable,
+			calls: 4,
+		},
+		{
+			desc:  "authorize access with failed authorizer again",
+			req:   authz.Request{Subject: broken, Channel: "1"},
+			err:   authz.ErrUnavailable,
+			calls: 5,
+		},
+	}
+
+	for _, tc := range cases {
+		err := az.Authorize(context.Background(), tc.req)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.calls, p.calls(), fmt.Sprintf("%s: expected %d calls got %d", tc.desc, tc.calls, p.calls()))
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	err := az.Authorize(context.Background(), authz.Request{Subject: allowed, Channel: "1", Action: authz.Publish})
+	assert.Nil(t, err, fmt.Sprintf("authorize expired allowed access: unexpected error %s", err))
+	assert.Equal(t, 6, p.calls(), fmt.Sprintf("authorize expired allowed access: expected %d calls got %d", 6, p.calls()))
+}
+
+func TestAuthorizeBreaker(t *testing.T) {
+	p, ts := newPolicy(t)
+	az := authz.NewAuthorizer(authz.Config{
+		URL:              ts.URL,
+		BreakerThreshold: 2,
+		BreakerTimeout:   100 * time.Millisecond,
+	})
+
+	cases := []struct {
+		desc  string
+		req   authz.Request
+		err   error
+		calls int
+	}{
+		{
+			desc:  "authorize access with failed authorizer",
+			req:   authz.Request{Subject: broken, Channel: "1"},
+			err:   authz.ErrUnavailable,
+			calls: 1,
+		},
+		{
+			desc:  "authorize access with failed authorizer opening breaker",
+			req:   authz.Request{Subject: broken, Channel: "1"},
+			err:   authz.ErrUnavailable,
+			calls: 2,
+		},
+		{
+			desc:  "authorize allowed access with open breaker",
+			req:   authz.Request{Subject: allowed, Channel: "1"},
+			err:   authz.ErrBreakerOpen,
+			calls: 2,
+		},
+	}
+
+	for _, tc := range cases {
+		err := az.Authorize(context.Background(), tc.req)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.calls, p.calls(), fmt.Sprintf("%s: expected %d calls got %d", tc.desc, tc.calls, p.calls()))
+	}
+
+	// Once the breaker timeout passes, the failed probe opens it again and
+	// the successful one closes it.
+	time.Sleep(150 * time.Millisecond)
+	err := az.Authorize(context.Background(), authz.Request{Subject: broken, Channel: "1"})
+	require.True(t, errors.Contains(err, authz.ErrUnavailable), fmt.Sprintf("expected error %s got %s", authz.ErrUnavailable, err))
+	err = az.Authorize(context.Background(), authz.Request{Subject: allowed, Channel: "1"})
+	assert.True(t, errors.Contains(err, authz.ErrBreakerOpen), fmt.Sprintf("expected error %s got %s", authz.ErrBreakerOpen, err))
+	assert.Equal(t, 3, p.calls(), fmt.Sprintf("expected %d calls got %d", 3, p.calls()))
+
+	time.Sleep(150 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		err = az.Authorize(context.Background(), authz.Request{Subject: allowed, Channel: "1"})
+		assert.Nil(t, err, fmt.Sprintf("authorize allowed access with closed breaker: unexpected error %s", err))
+	}
+	assert.Equal(t, 5, p.calls(), fmt.Sprintf("expected %d calls got %d", 5, p.calls()))
+}
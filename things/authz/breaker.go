@@ -0,0 +1,62 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package authz
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker stops calling the authorizer after the threshold of consecutive
+// failures. Once the timeout passes, a single call is let through, closing
+// the breaker if it succeeds and opening it again otherwise.
+type breaker struct {
+	threshold int
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(threshold int, timeout time.Duration) *breaker {
+	return &breaker{
+		threshold: threshold,
+		timeout:   timeout,
+	}
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.probing || time.Since(b.openedAt) < b.timeout {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = time.Now()
+	}
+	b.probing = false
+}
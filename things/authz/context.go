@@ -0,0 +1,76 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package authz
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// Publish is the action of publishing messages to the channel.
+	Publish = "publish"
+
+	// Subscribe is the action of subscribing to the channel messages.
+	Subscribe = "subscribe"
+
+	actionKey   = "x-access-action"
+	subtopicKey = "x-access-subtopic"
+)
+
+// Access represents the way the channel is accessed. It's not a part of
+// the access request message, so it's passed along in gRPC metadata.
+type Access struct {
+	Action   string
+	Subtopic string
+}
+
+type accessKey struct{}
+
+// WithAccess returns context carrying the channel access.
+func WithAccess(ctx context.Context, a Access) context.Context {
+	return context.WithValue(ctx, accessKey{}, a)
+}
+
+// AccessFromContext returns the channel access carried by the context.
+func AccessFromContext(ctx context.Context) Access {
+	a, _ := ctx.Value(accessKey{}).(Access)
+	return a
+}
+
+// ContextToGRPC adds the channel access carried by the context to the
+// outgoing gRPC metadata. It's intended to be used as go-kit gRPC client
+// before function.
+func ContextToGRPC(ctx context.Context, md *metadata.MD) context.Context {
+	a := AccessFromContext(ctx)
+	if a.Action != "" {
+		md.Set(actionKey, a.Action)
+	}
+	if a.Subtopic != "" {
+		md.Set(subtopicKey, a.Subtopic)
+	}
+	return ctx
+}
+
+// GRPCToContext populates the context with the channel access received in
+// the incoming gRPC metadata. It's intended to be used as go-kit gRPC server
+// before function.
+func GRPCToContext(ctx context.Context, md metadata.MD) context.Context {
+	a := Access{
+		Action:   first(md, actionKey),
+		Subtopic: first(md, subtopicKey),
+	}
+	if a == (Access{}) {
+		return ctx
+	}
+	return WithAccess(ctx, a)
+}
+
+func first(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
@@ -0,0 +1,6 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+// Package authz contains the things service middleware consulting the
+// external policy engine, such as OPA, on every channel access decision.
+package authz
@@ -0,0 +1,181 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package authz
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+)
+
+var _ things.Service = (*authzMiddleware)(nil)
+
+type authzMiddleware struct {
+	svc      things.Service
+	az       Authorizer
+	mode     Mode
+	failOpen bool
+	logger   logger.Logger
+}
+
+// NewMiddleware returns wrapper around things service that consults the
+// external authorizer when the channel access is checked. The decisions are
+// combined using the mode, and the failed ones are resolved by failOpen.
+func NewMiddleware(svc things.Service, az Authorizer, mode Mode, failOpen bool, logger logger.Logger) (things.Service, error) {
+	if mode != DenyOverrides && mode != ExternalOnly {
+		return nil, ErrInvalidMode
+	}
+
+	return &authzMiddleware{
+		svc:      svc,
+		az:       az,
+		mode:     mode,
+		failOpen: failOpen,
+		logger:   logger,
+	}, nil
+}
+
+func (am *authzMiddleware) CreateThings(ctx context.Context, token string, ths ...things.Thing) ([]things.Thing, error) {
+	return am.svc.CreateThings(ctx, token, ths...)
+}
+
+func (am *authzMiddleware) UpdateThing(ctx context.Context, token string, thing things.Thing) error {
+	return am.svc.UpdateThing(ctx, token, thing)
+}
+
+func (am *authzMiddleware) UpdateKey(ctx context.Context, token, id, key string) error {
+	return am.svc.UpdateKey(ctx, token, id, key)
+}
+
+func (am *authzMiddleware) ViewThing(ctx context.Context, token, id string) (things.Thing, error) {
+	return am.svc.ViewThing(ctx, token, id)
+}
+
+func (am *authzMiddleware) ListThings(ctx context.Context, token string, pm things.PageMetadata) (things.Page, error) {
+	return am.svc.ListThings(ctx, token, pm)
+}
+
+func (am *authzMiddleware) ListThingsByChannel(ctx context.Context, token, chID string, pm things.PageMetadata) (things.Page, error) {
+	return am.svc.ListThingsByChannel(ctx, token, chID, pm)
+}
+
+func (am *authzMiddleware) RemoveThing(ctx context.Context, token, id string) error {
+	return am.svc.RemoveThing(ctx, token, id)
+}
+
+func (am *authzMiddleware) CreateChannels(ctx context.Context, token string, channels ...things.Channel) ([]things.Channel, error) {
+	return am.svc.CreateChannels(ctx, token, channels...)
+}
+
+func (am *authzMiddleware) UpdateChannel(ctx context.Context, token string, channel things.Channel) error {
+	return am.svc.UpdateChannel(ctx, token, channel)
+}
+
+func (am *authzMiddleware) ViewChannel(ctx context.Context, token, id string) (things.Channel, error) {
+	return am.svc.ViewChannel(ctx, token, id)
+}
+
+func (am *authzMiddleware) ListChannels(ctx context.Context, token string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	return am.svc.ListChannels(ctx, token, pm)
+}
+
+func (am *authzMiddleware) ListChannelsByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	return am.svc.ListChannelsByMetadataKey(ctx, key, pm)
+}
+
+func (am *authzMiddleware) ListChannelsByThing(ctx context.Context, token, thID string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	return am.svc.ListChannelsByThing(ctx, token, thID, pm)
+}
+
+func (am *authzMiddleware) RemoveChannel(ctx context.Context, token, id string) error {
+	return am.svc.RemoveChannel(ctx, token, id)
+}
+
+func (am *authzMiddleware) UpdateChannelSchema(ctx context.Context, token, id string, schema map[string]interface{}) (things.Channel, error) {
+	return am.svc.UpdateChannelSchema(ctx, token, id, schema)
+}
+
+func (am *authzMiddleware) Connect(ctx context.Context, token string, chIDs, thIDs []string) error {
+	return am.svc.Connect(ctx, token, chIDs, thIDs)
+}
+
+func (am *authzMiddleware) Disconnect(ctx context.Context, token string, chIDs, thIDs []string) error {
+	return am.svc.Disconnect(ctx, token, chIDs, thIDs)
+}
+
+func (am *authzMiddleware) CanAccessByKey(ctx context.Context, chanID, key string) (string, error) {
+	thingID, err := am.svc.CanAccessByKey(ctx, chanID, key)
+	if err != nil {
+		if am.mode != ExternalOnly {
+			return "", err
+		}
+		// The thing must exist, even if it's not connected to the channel.
+		if thingID, err = am.svc.Identify(ctx, key); err != nil {
+			return "", err
+		}
+	}
+
+	if err := am.authorize(ctx, chanID, thingID); err != nil {
+		return "", err
+	}
+	return thingID, nil
+}
+
+func (am *authzMiddleware) CanAccessByID(ctx context.Context, chanID, thingID string) error {
+	if am.mode != ExternalOnly {
+		if err := am.svc.CanAccessByID(ctx, chanID, thingID); err != nil {
+			return err
+		}
+	}
+
+	return am.authorize(ctx, chanID, thingID)
+}
+
+func (am *authzMiddleware) IsChannelOwner(ctx context.Context, owner, chanID string) error {
+	return am.svc.IsChannelOwner(ctx, owner, chanID)
+}
+
+func (am *authzMiddleware) Identify(ctx context.Context, key string) (string, error) {
+	return am.svc.Identify(ctx, key)
+}
+
+func (am *authzMiddleware) ListMembers(ctx context.Context, token, groupID string, pm things.PageMetadata) (things.Page, error) {
+	return am.svc.ListMembers(ctx, token, groupID, pm)
+}
+
+func (am *authzMiddleware) ViewThingStats(ctx context.Context, token, id string) (things.Stats, error) {
+	return am.svc.ViewThingStats(ctx, token, id)
+}
+
+func (am *authzMiddleware) ViewChannelStats(ctx context.Context, token, id string) (things.Stats, error) {
+	return am.svc.ViewChannelStats(ctx, token, id)
+}
+
+func (am *authzMiddleware) authorize(ctx context.Context, chanID, thingID string) error {
+	a := AccessFromContext(ctx)
+	req := Request{
+		Subject:  thingID,
+		Channel:  chanID,
+		Action:   a.Action,
+		Subtopic: a.Subtopic,
+	}
+
+	err := am.az.Authorize(ctx, req)
+	switch err {
+	case nil, ErrAccessDenied:
+		return err
+	}
+
+	// The open breaker is logged once, when the last call fails.
+	if !errors.Contains(err, ErrBreakerOpen) {
+		am.logger.Warn(fmt.Sprintf("Failed to authorize thing %s access to channel %s: %s", thingID, chanID, err))
+	}
+	if am.failOpen {
+		return nil
+	}
+	return ErrUnavailable
+}
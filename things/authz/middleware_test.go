@@ -0,0 +1,225 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package authz_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	log "github.com/mainflux/mainflux/logger"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/uuid"
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/authz"
+	"github.com/mainflux/mainflux/things/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	token = "token"
+	email = "user@example.com"
+)
+
+var testLog, _ = log.New(os.Stdout, log.Info.String())
+
+// authorizer returns the decision configured for the subject, allowing the
+// unknown ones.
+type authorizer struct {
+	decisions map[string]error
+	last      authz.Request
+}
+
+func (a *authorizer) Authorize(_ context.Context, req authz.Request) error {
+	a.last = req
+	return a.decisions[req.Subject]
+}
+
+type fixture struct {
+	svc       things.Service
+	connected things.Thing
+	allowed   things.Thing
+	denied    things.Thing
+	failed    things.Thing
+	channel   things.Channel
+}
+
+func newFixture(t *testing.T) fixture {
+	auth := mocks.NewAuthService(map[string]string{token: email})
+	conns := make(chan mocks.Connection)
+	thingsRepo := mocks.NewThingRepository(conns)
+	channelsRepo := mocks.NewChannelRepository(thingsRepo, conns)
+	idProvider := uuid.NewMock()
+	svc := things.New(auth, thingsRepo, channelsRepo, mocks.NewChannelCache(), mocks.NewThingCache(), mocks.NewStatsRepository(), idProvider, idProvider)
+
+	ths, err := svc.CreateThings(context.Background(), token, things.Thing{Name: "a"}, things.Thing{Name: "b"}, things.Thing{Name: "c"}, things.Thing{Name: "d"})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	chs, err := svc.CreateChannels(context.Background(), token, things.Channel{Name: "a"})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = svc.Connect(context.Background(), token, []string{chs[0].ID}, []string{ths[0].ID, ths[2].ID, ths[3].ID})
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	// The second thing isn't connected, but it's allowed by the authorizer.
+	return fixture{
+		svc:       svc,
+		connected: ths[0],
+		allowed:   ths[1],
+		denied:    ths[2],
+		failed:    ths[3],
+		channel:   chs[0],
+	}
+}
+
+func (f fixture) authorizer() *authorizer {
+	return &authorizer{
+		decisions: map[string]error{
+			f.denied.ID: authz.ErrAccessDenied,
+			f.failed.ID: errors.Wrap(authz.ErrUnavailable, authz.ErrBreakerOpen),
+		},
+	}
+}
+
+func TestCanAccessByKey(t *testing.T) {
+	f := newFixture(t)
+
+	cases := []struct {
+		desc     string
+		mode     authz.Mode
+		failOpen bool
+		key      string
+		err      error
+	}{
+		{
+			desc: "access by connected thing in deny-overrides mode",
+			mode: authz.DenyOverrides,
+			key:  f.connected.Key,
+			err:  nil,
+		},
+		{
+			desc: "access by not connected thing in deny-overrides mode",
+			mode: authz.DenyOverrides,
+			key:  f.allowed.Key,
+			err:  things.ErrEntityConnected,
+		},
+		{
+			desc: "access by denied thing in deny-overrides mode",
+			mode: authz.DenyOverrides,
+			key:  f.denied.Key,
+			err:  authz.ErrAccessDenied,
+		},
+		{
+			desc: "access with failed decision failing closed",
+			mode: authz.DenyOverrides,
+			key:  f.failed.Key,
+			err:  authz.ErrUnavailable,
+		},
+		{
+			desc:     "access with failed decision failing open",
+			mode:     authz.DenyOverrides,
+			failOpen: true,
+			key:      f.failed.Key,
+			err:      nil,
+		},
+		{
+			desc: "access by not connected thing in external-only mode",
+			mode: authz.ExternalOnly,
+			key:  f.allowed.Key,
+			err:  nil,
+		},
+		{
+			desc: "access by denied thing in external-only mode",
+			mode: authz.ExternalOnly,
+			key:  f.denied.Key,
+			err:  authz.ErrAccessDenied,
+		},
+		{
+			desc: "access by non-existing thing in external-only mode",
+			mode: authz.ExternalOnly,
+			key:  "wrong-value",
+			err:  things.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		svc, err := authz.NewMiddleware(f.svc, f.authorizer(), tc.mode, tc.failOpen, testLog)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		_, err = svc.CanAccessByKey(context.Background(), f.channel.ID, tc.key)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+	}
+}
+
+func TestCanAccessByID(t *testing.T) {
+	f := newFixture(t)
+
+	cases := []struct {
+		desc     string
+		mode     authz.Mode
+		failOpen bool
+		thingID  string
+		access   authz.Access
+		err      error
+	}{
+		{
+			desc:    "access by connected thing in deny-overrides mode",
+			mode:    authz.DenyOverrides,
+			thingID: f.connected.ID,
+			access:  authz.Access{Action: authz.Publish, Subtopic: "a.b"},
+			err:     nil,
+		},
+		{
+			desc:    "access by not connected thing in deny-overrides mode",
+			mode:    authz.DenyOverrides,
+			thingID: f.allowed.ID,
+			err:     things.ErrEntityConnected,
+		},
+		{
+			desc:    "access by denied thing in deny-overrides mode",
+			mode:    authz.DenyOverrides,
+			thingID: f.denied.ID,
+			access:  authz.Access{Action: authz.Subscribe},
+			err:     authz.ErrAccessDenied,
+		},
+		{
+			desc:    "access with failed decision failing closed",
+			mode:    authz.ExternalOnly,
+			thingID: f.failed.ID,
+			err:     authz.ErrUnavailable,
+		},
+		{
+			desc:     "access with failed decision failing open",
+			mode:     authz.ExternalOnly,
+			failOpen: true,
+			thingID:  f.failed.ID,
+			err:      nil,
+		},
+		{
+			desc:    "access by not connected thing in external-only mode",
+			mode:    authz.ExternalOnly,
+			thingID: f.allowed.ID,
+			access:  authz.Access{Action: authz.Publish},
+			err:     nil,
+		},
+	}
+
+	for _, tc := range cases {
+		az := f.authorizer()
+		svc, err := authz.NewMiddleware(f.svc, az, tc.mode, tc.failOpen, testLog)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		ctx := authz.WithAccess(context.Background(), tc.access)
+		err = svc.CanAccessByID(ctx, f.channel.ID, tc.thingID)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		if az.last.Subject == "" {
+			continue
+		}
+		req := authz.Request{Subject: tc.thingID, Channel: f.channel.ID, Action: tc.access.Action, Subtopic: tc.access.Subtopic}
+		assert.Equal(t, req, az.last, fmt.Sprintf("%s: expected request %v got %v", tc.desc, req, az.last))
+	}
+}
+
+func TestNewMiddleware(t *testing.T) {
+	_, err := authz.NewMiddleware(nil, &authorizer{}, authz.Mode("permit-overrides"), false, testLog)
+	assert.Equal(t, authz.ErrInvalidMode, err, fmt.Sprintf("expected error %s got %s", authz.ErrInvalidMode, err))
+}
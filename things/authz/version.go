@@ -0,0 +1,77 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package authz
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const policyVersionKey = "x-policy-version"
+
+// The version is recorded by the service further down the call chain than
+// the context is created, so the context carries the pointer to it.
+type versionKey struct{}
+
+// WithPolicyVersion returns context recording the policy version the access
+// decision is made at.
+func WithPolicyVersion(ctx context.Context) context.Context {
+	return context.WithValue(ctx, versionKey{}, new(uint64))
+}
+
+// PolicyVersionRequested returns whether the context records the policy
+// version.
+func PolicyVersionRequested(ctx context.Context) bool {
+	_, ok := ctx.Value(versionKey{}).(*uint64)
+	return ok
+}
+
+// SetPolicyVersion records the policy version in the context returned by
+// WithPolicyVersion. It's a no-op for any other context.
+func SetPolicyVersion(ctx context.Context, version uint64) {
+	if v, ok := ctx.Value(versionKey{}).(*uint64); ok {
+		*v = version
+	}
+}
+
+// PolicyVersionFromContext returns the policy version recorded in the
+// context, or zero if it isn't recorded.
+func PolicyVersionFromContext(ctx context.Context) uint64 {
+	if v, ok := ctx.Value(versionKey{}).(*uint64); ok {
+		return *v
+	}
+	return 0
+}
+
+// RecordPolicyVersion populates the context with the policy version record.
+// It's intended to be used as go-kit gRPC server before function, along
+// with PolicyVersionToGRPC.
+func RecordPolicyVersion(ctx context.Context, _ metadata.MD) context.Context {
+	return WithPolicyVersion(ctx)
+}
+
+// PolicyVersionToGRPC adds the policy version recorded in the context to
+// the outgoing gRPC header. It's intended to be used as go-kit gRPC server
+// after function.
+func PolicyVersionToGRPC(ctx context.Context, header *metadata.MD, _ *metadata.MD) context.Context {
+	if v := PolicyVersionFromContext(ctx); v > 0 {
+		if *header == nil {
+			*header = metadata.MD{}
+		}
+		header.Set(policyVersionKey, strconv.FormatUint(v, 10))
+	}
+	return ctx
+}
+
+// GRPCToPolicyVersion records the policy version received in the incoming
+// gRPC header in the context returned by WithPolicyVersion. It's intended to
+// be used as go-kit gRPC client after function.
+func GRPCToPolicyVersion(ctx context.Context, header metadata.MD, _ metadata.MD) context.Context {
+	if v, err := strconv.ParseUint(first(header, policyVersionKey), 10, 64); err == nil {
+		SetPolicyVersion(ctx, v)
+	}
+	return ctx
+}
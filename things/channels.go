@@ -5,6 +5,36 @@ package things
 
 import (
 	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+)
+
+const (
+	// SchemaKey is the channel metadata key holding the JSON schema of the
+	// messages published to the channel.
+	SchemaKey = "schema"
+
+	// RetentionKey is the channel metadata key holding the duration the
+	// writers keep the messages of the channel for, e.g. "7d" or "36h".
+	RetentionKey = "retention"
+
+	// QuotaKey is the channel metadata key holding the monthly number of
+	// messages the adapters accept for the channel. Zero is unlimited.
+	QuotaKey = "quota"
+)
+
+var (
+	// ErrInvalidRetention indicates the channel retention that isn't a
+	// positive duration.
+	ErrInvalidRetention = errors.New("invalid channel retention")
+
+	// ErrInvalidQuota indicates the channel quota that isn't a non-negative
+	// integer.
+	ErrInvalidQuota = errors.New("invalid channel quota")
 )
 
 // Channel represents a Mainflux "communication group". This group contains the
@@ -41,6 +71,10 @@ type ChannelRepository interface {
 	// RetrieveAll retrieves the subset of channels owned by the specified user.
 	RetrieveAll(ctx context.Context, owner string, pm PageMetadata) (ChannelsPage, error)
 
+	// RetrieveByMetadataKey retrieves the subset of channels of all the users
+	// having the specified metadata key set.
+	RetrieveByMetadataKey(ctx context.Context, key string, pm PageMetadata) (ChannelsPage, error)
+
 	// RetrieveByThing retrieves the subset of channels owned by the specified
 	// user and have specified thing connected or not connected to them.
 	RetrieveByThing(ctx context.Context, owner, thID string, pm PageMetadata) (ChannelsPage, error)
@@ -80,4 +114,70 @@ type ChannelCache interface {
 
 	// Removes channel from cache.
 	Remove(context.Context, string) error
+
+	// SaveQuota sets the monthly quota of the channel read by the adapters.
+	SaveQuota(ctx context.Context, chanID string, quota uint64) error
+
+	// RemoveQuota removes the quota of the channel, so the adapters apply
+	// the default one.
+	RemoveQuota(ctx context.Context, chanID string) error
+}
+
+// ParseRetention parses the channel retention from the metadata value. The
+// value is a duration string accepted by time.ParseDuration, or a number of
+// days with the "d" suffix.
+func ParseRetention(v interface{}) (time.Duration, error) {
+	s, ok := v.(string)
+	if !ok {
+		return 0, ErrInvalidRetention
+	}
+
+	var d time.Duration
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, errors.Wrap(ErrInvalidRetention, err)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, errors.Wrap(ErrInvalidRetention, err)
+		}
+	}
+
+	if d <= 0 {
+		return 0, ErrInvalidRetention
+	}
+	return d, nil
+}
+
+// validateRetention checks the retention of the channel metadata, if set.
+func validateRetention(metadata map[string]interface{}) error {
+	v, ok := metadata[RetentionKey]
+	if !ok {
+		return nil
+	}
+	_, err := ParseRetention(v)
+	return err
+}
+
+// ParseQuota parses the channel quota from the metadata value, which is a
+// non-negative integer.
+func ParseQuota(v interface{}) (uint64, error) {
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) || f >= math.MaxUint64 {
+		return 0, ErrInvalidQuota
+	}
+	return uint64(f), nil
+}
+
+// validateQuota checks the quota of the channel metadata, if set.
+func validateQuota(metadata map[string]interface{}) error {
+	v, ok := metadata[QuotaKey]
+	if !ok {
+		return nil
+	}
+	_, err := ParseQuota(v)
+	return err
 }
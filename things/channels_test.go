@@ -0,0 +1,58 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package things_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetention(t *testing.T) {
+	cases := []struct {
+		desc      string
+		retention interface{}
+		duration  time.Duration
+		err       error
+	}{
+		{desc: "parse days", retention: "7d", duration: 7 * 24 * time.Hour},
+		{desc: "parse duration", retention: "36h30m", duration: 36*time.Hour + 30*time.Minute},
+		{desc: "parse zero duration", retention: "0s", err: things.ErrInvalidRetention},
+		{desc: "parse negative days", retention: "-1d", err: things.ErrInvalidRetention},
+		{desc: "parse fractional days", retention: "1.5d", err: things.ErrInvalidRetention},
+		{desc: "parse unknown unit", retention: "1w", err: things.ErrInvalidRetention},
+		{desc: "parse number", retention: 7, err: things.ErrInvalidRetention},
+	}
+
+	for _, tc := range cases {
+		d, err := things.ParseRetention(tc.retention)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.duration, d, fmt.Sprintf("%s: expected duration %s got %s", tc.desc, tc.duration, d))
+	}
+}
+
+func TestParseQuota(t *testing.T) {
+	cases := []struct {
+		desc  string
+		quota interface{}
+		value uint64
+		err   error
+	}{
+		{desc: "parse quota", quota: float64(100000), value: 100000},
+		{desc: "parse unlimited quota", quota: float64(0), value: 0},
+		{desc: "parse negative quota", quota: float64(-1), err: things.ErrInvalidQuota},
+		{desc: "parse fractional quota", quota: 1.5, err: things.ErrInvalidQuota},
+		{desc: "parse string", quota: "100", err: things.ErrInvalidQuota},
+	}
+
+	for _, tc := range cases {
+		v, err := things.ParseQuota(tc.quota)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected error %s got %s", tc.desc, tc.err, err))
+		assert.Equal(t, tc.value, v, fmt.Sprintf("%s: expected quota %d got %d", tc.desc, tc.value, v))
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package things_test
+
+import (
+	"database/sql"
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStatus(t *testing.T) {
+	cases := []struct {
+		desc   string
+		err    error
+		status int
+	}{
+		{desc: "unauthorized access", err: things.ErrUnauthorizedAccess, status: http.StatusUnauthorized},
+		{desc: "entity connected", err: things.ErrEntityConnected, status: http.StatusForbidden},
+		{desc: "malformed entity", err: things.ErrMalformedEntity, status: http.StatusBadRequest},
+		{desc: "not found", err: things.ErrNotFound, status: http.StatusNotFound},
+		{desc: "conflict", err: things.ErrConflict, status: http.StatusConflict},
+		{desc: "scan metadata", err: things.ErrScanMetadata, status: http.StatusUnprocessableEntity},
+		{desc: "select entity", err: things.ErrSelectEntity, status: http.StatusUnprocessableEntity},
+		{desc: "create entity", err: things.ErrCreateEntity, status: http.StatusBadRequest},
+		{desc: "update entity", err: things.ErrUpdateEntity, status: http.StatusBadRequest},
+		{desc: "view entity", err: things.ErrViewEntity, status: http.StatusBadRequest},
+		{desc: "remove entity", err: things.ErrRemoveEntity, status: http.StatusBadRequest},
+		{desc: "connect", err: things.ErrConnect, status: http.StatusBadRequest},
+		{desc: "disconnect", err: things.ErrDisconnect, status: http.StatusBadRequest},
+		{desc: "create UUID", err: things.ErrCreateUUID, status: http.StatusInternalServerError},
+		{desc: "retrieve things", err: things.ErrFailedToRetrieveThings, status: http.StatusInternalServerError},
+		{desc: "save quota", err: things.ErrSaveQuota, status: http.StatusInternalServerError},
+		{desc: "wrapped unauthorized access", err: errors.Wrap(things.ErrUnauthorizedAccess, things.ErrNotFound), status: http.StatusUnauthorized},
+		{desc: "wrapped not found", err: errors.Wrap(things.ErrNotFound, sql.ErrNoRows), status: http.StatusNotFound},
+		{desc: "create entity wrapping conflict", err: errors.Wrap(things.ErrCreateEntity, things.ErrConflict), status: http.StatusConflict},
+		{desc: "view entity wrapping not found", err: errors.Wrap(things.ErrViewEntity, things.ErrNotFound), status: http.StatusNotFound},
+	}
+
+	for _, tc := range cases {
+		status := apierrors.HTTPStatus(tc.err)
+		assert.Equal(t, tc.status, status, fmt.Sprintf("%s: expected status %d got %d\n", tc.desc, tc.status, status))
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	cases := []struct {
+		desc     string
+		err      error
+		sentinel error
+	}{
+		{desc: "wrapped not found", err: errors.Wrap(things.ErrNotFound, sql.ErrNoRows), sentinel: things.ErrNotFound},
+		{desc: "wrapped entity connected", err: errors.Wrap(things.ErrEntityConnected, sql.ErrNoRows), sentinel: things.ErrEntityConnected},
+		{desc: "wrapped unauthorized access", err: errors.Wrap(things.ErrUnauthorizedAccess, sql.ErrNoRows), sentinel: things.ErrUnauthorizedAccess},
+		{desc: "nested malformed entity", err: errors.Wrap(things.ErrCreateEntity, errors.Wrap(things.ErrMalformedEntity, sql.ErrNoRows)), sentinel: things.ErrMalformedEntity},
+	}
+
+	for _, tc := range cases {
+		assert.True(t, goerrors.Is(tc.err, tc.sentinel), fmt.Sprintf("%s: expected errors.Is to match the sentinel\n", tc.desc))
+		assert.True(t, goerrors.Is(tc.err, sql.ErrNoRows), fmt.Sprintf("%s: expected errors.Is to match the cause\n", tc.desc))
+		assert.True(t, errors.Contains(tc.err, tc.sentinel), fmt.Sprintf("%s: expected errors.Contains to match the sentinel\n", tc.desc))
+	}
+	assert.True(t, errors.Contains(things.ErrMalformedEntity, errors.ErrMalformedEntity), "expected errors.Contains to match the legacy error with the same message")
+}
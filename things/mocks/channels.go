@@ -6,6 +6,7 @@ package mocks
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -122,6 +123,38 @@ func (crm *channelRepositoryMock) RetrieveAll(_ context.Context, owner string, p
 	return page, nil
 }
 
+func (crm *channelRepositoryMock) RetrieveByMetadataKey(_ context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	crm.mu.Lock()
+	defer crm.mu.Unlock()
+
+	var chs []things.Channel
+	for _, ch := range crm.channels {
+		if _, ok := ch.Metadata[key]; ok {
+			chs = append(chs, ch)
+		}
+	}
+	sort.Slice(chs, func(i, j int) bool { return chs[i].ID < chs[j].ID })
+
+	page := things.ChannelsPage{
+		Channels: []things.Channel{},
+		PageMetadata: things.PageMetadata{
+			Total:  uint64(len(chs)),
+			Offset: pm.Offset,
+			Limit:  pm.Limit,
+		},
+	}
+	if pm.Offset >= page.Total {
+		return page, nil
+	}
+	last := pm.Offset + pm.Limit
+	if last > page.Total {
+		last = page.Total
+	}
+	page.Channels = chs[pm.Offset:last]
+
+	return page, nil
+}
+
 func (crm *channelRepositoryMock) RetrieveByThing(_ context.Context, owner, thID string, pm things.PageMetadata) (things.ChannelsPage, error) {
 	if pm.Limit <= 0 {
 		return things.ChannelsPage{}, nil
@@ -273,12 +306,14 @@ func (crm *channelRepositoryMock) HasThingByID(_ context.Context, chanID, thingI
 type channelCacheMock struct {
 	mu       sync.Mutex
 	channels map[string]string
+	quotas   map[string]uint64
 }
 
 // NewChannelCache returns mock cache instance.
 func NewChannelCache() things.ChannelCache {
 	return &channelCacheMock{
 		channels: make(map[string]string),
+		quotas:   make(map[string]uint64),
 	}
 }
 
@@ -310,5 +345,22 @@ func (ccm *channelCacheMock) Remove(_ context.Context, chanID string) error {
 	defer ccm.mu.Unlock()
 
 	delete(ccm.channels, chanID)
+	delete(ccm.quotas, chanID)
+	return nil
+}
+
+func (ccm *channelCacheMock) SaveQuota(_ context.Context, chanID string, quota uint64) error {
+	ccm.mu.Lock()
+	defer ccm.mu.Unlock()
+
+	ccm.quotas[chanID] = quota
+	return nil
+}
+
+func (ccm *channelCacheMock) RemoveQuota(_ context.Context, chanID string) error {
+	ccm.mu.Lock()
+	defer ccm.mu.Unlock()
+
+	delete(ccm.quotas, chanID)
 	return nil
 }
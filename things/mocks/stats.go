@@ -0,0 +1,80 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+)
+
+var _ things.StatsRepository = (*statsRepositoryMock)(nil)
+
+type statsRepositoryMock struct {
+	mu         sync.Mutex
+	activities []things.Activity
+}
+
+// NewStatsRepository creates in-memory stats repository. The daily count is
+// the exact number of the messages published in the last 24 hours.
+func NewStatsRepository() things.StatsRepository {
+	return &statsRepositoryMock{}
+}
+
+func (srm *statsRepositoryMock) Save(_ context.Context, a things.Activity) error {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	srm.activities = append(srm.activities, a)
+	return nil
+}
+
+func (srm *statsRepositoryMock) RetrieveThingStats(_ context.Context, id string) (things.Stats, error) {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	stats := srm.stats(id, func(a things.Activity) bool { return a.ThingID == id })
+	channels := map[string]time.Time{}
+	for _, a := range srm.activities {
+		if a.ThingID == id && a.Time.After(channels[a.ChanID]) {
+			channels[a.ChanID] = a.Time
+		}
+	}
+	for chanID, t := range channels {
+		stats.Channels = append(stats.Channels, things.ChannelActivity{ID: chanID, LastSeen: t})
+	}
+	sort.Slice(stats.Channels, func(i, j int) bool {
+		return stats.Channels[i].LastSeen.After(stats.Channels[j].LastSeen)
+	})
+	return stats, nil
+}
+
+func (srm *statsRepositoryMock) RetrieveChannelStats(_ context.Context, id string) (things.Stats, error) {
+	srm.mu.Lock()
+	defer srm.mu.Unlock()
+
+	return srm.stats(id, func(a things.Activity) bool { return a.ChanID == id }), nil
+}
+
+func (srm *statsRepositoryMock) stats(id string, match func(things.Activity) bool) things.Stats {
+	stats := things.Stats{ID: id, Stale: true}
+	since := time.Now().Add(-24 * time.Hour)
+	for _, a := range srm.activities {
+		if !match(a) {
+			continue
+		}
+		stats.Stale = false
+		if !a.Time.Before(stats.LastSeen) {
+			stats.LastSeen = a.Time
+			stats.Protocol = a.Protocol
+		}
+		if a.Time.After(since) {
+			stats.DailyCount++
+		}
+	}
+	return stats
+}
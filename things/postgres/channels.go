@@ -11,9 +11,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/gofrs/uuid"
 	"github.com/lib/pq"
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/idprovider"
 	"github.com/mainflux/mainflux/things"
 )
 
@@ -177,12 +177,55 @@ func (cr channelRepository) RetrieveAll(ctx context.Context, owner string, pm th
 	return page, nil
 }
 
+func (cr channelRepository) RetrieveByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	q := `SELECT id, owner, name, metadata FROM channels
+	      WHERE metadata ? :key ORDER BY id LIMIT :limit OFFSET :offset;`
+
+	params := map[string]interface{}{
+		"key":    key,
+		"limit":  pm.Limit,
+		"offset": pm.Offset,
+	}
+	rows, err := cr.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		return things.ChannelsPage{}, errors.Wrap(things.ErrSelectEntity, err)
+	}
+	defer rows.Close()
+
+	items := []things.Channel{}
+	for rows.Next() {
+		dbch := dbChannel{}
+		if err := rows.StructScan(&dbch); err != nil {
+			return things.ChannelsPage{}, errors.Wrap(things.ErrSelectEntity, err)
+		}
+		items = append(items, toChannel(dbch))
+	}
+
+	cq := `SELECT COUNT(*) FROM channels WHERE metadata ? :key;`
+
+	total, err := total(ctx, cr.db, cq, params)
+	if err != nil {
+		return things.ChannelsPage{}, errors.Wrap(things.ErrSelectEntity, err)
+	}
+
+	page := things.ChannelsPage{
+		Channels: items,
+		PageMetadata: things.PageMetadata{
+			Total:  total,
+			Offset: pm.Offset,
+			Limit:  pm.Limit,
+		},
+	}
+
+	return page, nil
+}
+
 func (cr channelRepository) RetrieveByThing(ctx context.Context, owner, thID string, pm things.PageMetadata) (things.ChannelsPage, error) {
 	oq := getConnOrderQuery(pm.Order, "ch")
 	dq := getDirQuery(pm.Dir)
 
-	// Verify if UUID format is valid to avoid internal Postgres error
-	if _, err := uuid.FromString(thID); err != nil {
+	// Verify if ID format is valid to avoid needless database query
+	if err := idprovider.Validate(thID); err != nil {
 		return things.ChannelsPage{}, errors.Wrap(things.ErrNotFound, err)
 	}
 
@@ -367,6 +367,78 @@ func TestMultiChannelRetrieval(t *testing.T) {
 	}
 }
 
+func TestRetrieveByMetadataKey(t *testing.T) {
+	dbMiddleware := postgres.NewDatabase(db)
+	chanRepo := postgres.NewChannelRepository(dbMiddleware)
+
+	key := "retrieval-by-key"
+	owners := []string{"channel-retrieval-by-key@example.com", "other-channel-retrieval-by-key@example.com"}
+
+	n := uint64(10)
+	keyNum := uint64(6)
+	for i := uint64(0); i < n; i++ {
+		chID, err := idProvider.ID()
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+		ch := things.Channel{
+			ID:       chID,
+			Owner:    owners[i%2],
+			Metadata: things.Metadata{"field": "value"},
+		}
+		// Create Channels of both the owners with the key.
+		if i < keyNum {
+			ch.Metadata = things.Metadata{key: map[string]interface{}{"type": "object"}}
+		}
+		_, err = chanRepo.Save(context.Background(), ch)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+
+	cases := map[string]struct {
+		key          string
+		size         uint64
+		pageMetadata things.PageMetadata
+	}{
+		"retrieve all channels with existing key": {
+			key: key,
+			pageMetadata: things.PageMetadata{
+				Offset: 0,
+				Limit:  n,
+				Total:  keyNum,
+			},
+			size: keyNum,
+		},
+		"retrieve subset of channels with existing key": {
+			key: key,
+			pageMetadata: things.PageMetadata{
+				Offset: keyNum / 2,
+				Limit:  n,
+				Total:  keyNum,
+			},
+			size: keyNum / 2,
+		},
+		"retrieve channels with non-existing key": {
+			key: "non-existing",
+			pageMetadata: things.PageMetadata{
+				Offset: 0,
+				Limit:  n,
+				Total:  0,
+			},
+			size: 0,
+		},
+	}
+
+	for desc, tc := range cases {
+		page, err := chanRepo.RetrieveByMetadataKey(context.Background(), tc.key, tc.pageMetadata)
+		size := uint64(len(page.Channels))
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected size %d got %d\n", desc, tc.size, size))
+		assert.Equal(t, tc.pageMetadata.Total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", desc, tc.pageMetadata.Total, page.Total))
+		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %d\n", desc, err))
+		for _, ch := range page.Channels {
+			assert.Contains(t, ch.Metadata, tc.key, fmt.Sprintf("%s: expected metadata key %s got %v\n", desc, tc.key, ch.Metadata))
+		}
+	}
+}
+
 func TestRetrieveByThing(t *testing.T) {
 	email := "channel-multi-retrieval-by-thing@example.com"
 	dbMiddleware := postgres.NewDatabase(db)
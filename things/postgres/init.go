@@ -24,13 +24,16 @@ type Config struct {
 	SSLRootCert string
 }
 
+// URL returns the connection string of the PostgreSQL instance.
+func (cfg Config) URL() string {
+	return fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
+}
+
 // Connect creates a connection to the PostgreSQL instance and applies any
 // unapplied database migrations. A non-nil error is returned to indicate
 // failure.
 func Connect(cfg Config) (*sqlx.DB, error) {
-	url := fmt.Sprintf("host=%s port=%s user=%s dbname=%s password=%s sslmode=%s sslcert=%s sslkey=%s sslrootcert=%s", cfg.Host, cfg.Port, cfg.User, cfg.Name, cfg.Pass, cfg.SSLMode, cfg.SSLCert, cfg.SSLKey, cfg.SSLRootCert)
-
-	db, err := sqlx.Open("postgres", url)
+	db, err := sqlx.Open("postgres", cfg.URL())
 	if err != nil {
 		return nil, err
 	}
@@ -99,6 +102,22 @@ func migrateDB(db *sqlx.DB) error {
 					`ALTER TABLE IF EXISTS things ADD CONSTRAINT things_id_key UNIQUE (id)`,
 				},
 			},
+			{
+				Id: "things_5",
+				Up: []string{
+					`ALTER TABLE IF EXISTS connections
+					 DROP CONSTRAINT IF EXISTS connections_channel_id_channel_owner_fkey,
+					 DROP CONSTRAINT IF EXISTS connections_thing_id_thing_owner_fkey`,
+					`ALTER TABLE IF EXISTS things ALTER COLUMN id TYPE VARCHAR(254)`,
+					`ALTER TABLE IF EXISTS channels ALTER COLUMN id TYPE VARCHAR(254)`,
+					`ALTER TABLE IF EXISTS connections
+					 ALTER COLUMN channel_id TYPE VARCHAR(254),
+					 ALTER COLUMN thing_id TYPE VARCHAR(254)`,
+					`ALTER TABLE IF EXISTS connections
+					 ADD CONSTRAINT connections_channel_id_channel_owner_fkey FOREIGN KEY (channel_id, channel_owner) REFERENCES channels (id, owner) ON DELETE CASCADE ON UPDATE CASCADE,
+					 ADD CONSTRAINT connections_thing_id_thing_owner_fkey FOREIGN KEY (thing_id, thing_owner) REFERENCES things (id, owner) ON DELETE CASCADE ON UPDATE CASCADE`,
+				},
+			},
 		},
 	}
 
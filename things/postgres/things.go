@@ -10,9 +10,9 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/gofrs/uuid"
 	"github.com/lib/pq" // required for DB access
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/idprovider"
 	"github.com/mainflux/mainflux/things"
 )
 
@@ -309,8 +309,8 @@ func (tr thingRepository) RetrieveByChannel(ctx context.Context, owner, chID str
 	oq := getConnOrderQuery(pm.Order, "th")
 	dq := getDirQuery(pm.Dir)
 
-	// Verify if UUID format is valid to avoid internal Postgres error
-	if _, err := uuid.FromString(chID); err != nil {
+	// Verify if ID format is valid to avoid needless database query
+	if err := idprovider.Validate(chID); err != nil {
 		return things.Page{}, errors.Wrap(things.ErrNotFound, err)
 	}
 
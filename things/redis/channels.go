@@ -9,6 +9,7 @@ import (
 
 	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux/pkg/errors"
+	quotaredis "github.com/mainflux/mainflux/pkg/quota/redis"
 	"github.com/mainflux/mainflux/things"
 )
 
@@ -48,12 +49,26 @@ func (cc channelCache) Disconnect(ctx context.Context, chanID, thingID string) e
 
 func (cc channelCache) Remove(ctx context.Context, chanID string) error {
 	cid, _ := kv(chanID, "0")
-	if err := cc.client.Del(ctx, cid).Err(); err != nil {
+	if err := cc.client.Del(ctx, cid, quotaredis.QuotaKey(chanID)).Err(); err != nil {
 		return errors.Wrap(things.ErrRemoveEntity, err)
 	}
 	return nil
 }
 
+func (cc channelCache) SaveQuota(ctx context.Context, chanID string, quota uint64) error {
+	if err := cc.client.Set(ctx, quotaredis.QuotaKey(chanID), quota, 0).Err(); err != nil {
+		return errors.Wrap(things.ErrSaveQuota, err)
+	}
+	return nil
+}
+
+func (cc channelCache) RemoveQuota(ctx context.Context, chanID string) error {
+	if err := cc.client.Del(ctx, quotaredis.QuotaKey(chanID)).Err(); err != nil {
+		return errors.Wrap(things.ErrSaveQuota, err)
+	}
+	return nil
+}
+
 // Generates key-value pair
 func kv(chanID, thingID string) (string, string) {
 	cid := fmt.Sprintf("%s:%s", chanPrefix, chanID)
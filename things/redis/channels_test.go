@@ -7,7 +7,9 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	quotaredis "github.com/mainflux/mainflux/pkg/quota/redis"
 	"github.com/mainflux/mainflux/things/redis"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -155,3 +157,52 @@ func TestRemove(t *testing.T) {
 		assert.Equal(t, tc.hasAccess, hasAcces, "%s - check access after removing channel: expected %t got %t\n", tc.desc, tc.hasAccess, hasAcces)
 	}
 }
+
+func TestSaveQuota(t *testing.T) {
+	channelCache := redis.NewChannelCache(redisClient)
+	store := quotaredis.NewStore(redisClient)
+	month := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		desc   string
+		cid    string
+		quota  uint64
+		remove bool
+		n      uint64
+	}{
+		{
+			desc:  "save channel quota",
+			cid:   "quota",
+			quota: 5,
+			n:     5,
+		},
+		{
+			desc:  "save unlimited channel quota",
+			cid:   "unlimited",
+			quota: 0,
+			n:     10,
+		},
+		{
+			desc:   "remove channel quota",
+			cid:    "default",
+			quota:  5,
+			remove: true,
+			n:      2,
+		},
+	}
+
+	for _, tc := range cases {
+		err := channelCache.SaveQuota(context.Background(), tc.cid, tc.quota)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		if tc.remove {
+			err := channelCache.RemoveQuota(context.Background(), tc.cid)
+			require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		}
+
+		// The adapters reserve the messages up to the quota, or up to the
+		// default one if the channel doesn't set it.
+		n, err := store.Reserve(context.Background(), tc.cid, month, 10, 2)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.n, n, fmt.Sprintf("%s: expected %d messages reserved got %d\n", tc.desc, tc.n, n))
+	}
+}
@@ -90,14 +90,16 @@ func (ute updateThingEvent) Encode() map[string]interface{} {
 }
 
 type removeThingEvent struct {
-	id string
+	id            string
+	policyVersion uint64
 }
 
 func (rte removeThingEvent) Encode() map[string]interface{} {
-	return map[string]interface{}{
+	val := map[string]interface{}{
 		"id":        rte.id,
 		"operation": thingRemove,
 	}
+	return withPolicyVersion(val, rte.policyVersion)
 }
 
 type createChannelEvent struct {
@@ -159,38 +161,53 @@ func (uce updateChannelEvent) Encode() map[string]interface{} {
 }
 
 type removeChannelEvent struct {
-	id string
+	id            string
+	policyVersion uint64
 }
 
 func (rce removeChannelEvent) Encode() map[string]interface{} {
-	return map[string]interface{}{
+	val := map[string]interface{}{
 		"id":        rce.id,
 		"operation": channelRemove,
 	}
+	return withPolicyVersion(val, rce.policyVersion)
 }
 
 type connectThingEvent struct {
-	chanID  string
-	thingID string
+	chanID        string
+	thingID       string
+	policyVersion uint64
 }
 
 func (cte connectThingEvent) Encode() map[string]interface{} {
-	return map[string]interface{}{
+	val := map[string]interface{}{
 		"chan_id":   cte.chanID,
 		"thing_id":  cte.thingID,
 		"operation": thingConnect,
 	}
+	return withPolicyVersion(val, cte.policyVersion)
 }
 
 type disconnectThingEvent struct {
-	chanID  string
-	thingID string
+	chanID        string
+	thingID       string
+	policyVersion uint64
 }
 
 func (dte disconnectThingEvent) Encode() map[string]interface{} {
-	return map[string]interface{}{
+	val := map[string]interface{}{
 		"chan_id":   dte.chanID,
 		"thing_id":  dte.thingID,
 		"operation": thingDisconnect,
 	}
+	return withPolicyVersion(val, dte.policyVersion)
+}
+
+// withPolicyVersion adds the policy version to the events changing the
+// channel access, unless the version couldn't be obtained.
+func withPolicyVersion(val map[string]interface{}, version uint64) map[string]interface{} {
+	if version > 0 {
+		val["policy_version"] = version
+	}
+	return val
 }
@@ -0,0 +1,178 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/things"
+)
+
+const (
+	statsPrefix = "mainflux.things.stats"
+	dayFormat   = "20060102"
+	day         = 24 * time.Hour
+
+	lastSeenField = "last_seen"
+	protocolField = "protocol"
+)
+
+// saveScript records the message in the thing and the channel hashes, and
+// in their counters of the day. The last seen time is only moved forward,
+// so the messages processed out of order don't hide the newer ones.
+//
+// KEYS: thing hash, thing channels hash, thing counter, channel hash,
+// channel counter. ARGV: time in milliseconds, protocol, channel ID, TTL
+// and counter TTL in seconds.
+var saveScript = redis.NewScript(`
+local t = tonumber(ARGV[1])
+local function expire(key)
+	if tonumber(ARGV[4]) > 0 then
+		redis.call('EXPIRE', key, ARGV[4])
+	end
+end
+local function seen(key)
+	local last = tonumber(redis.call('HGET', key, 'last_seen') or '0')
+	if t >= last then
+		redis.call('HSET', key, 'last_seen', ARGV[1], 'protocol', ARGV[2])
+	end
+	expire(key)
+end
+local function count(key)
+	redis.call('INCR', key)
+	redis.call('EXPIRE', key, ARGV[5])
+end
+seen(KEYS[1])
+seen(KEYS[4])
+local last = tonumber(redis.call('HGET', KEYS[2], ARGV[3]) or '0')
+if t > last then
+	redis.call('HSET', KEYS[2], ARGV[3], ARGV[1])
+end
+expire(KEYS[2])
+count(KEYS[3])
+count(KEYS[5])
+return 1
+`)
+
+var _ things.StatsRepository = (*statsRepository)(nil)
+
+type statsRepository struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewStatsRepository returns redis stats repository implementation. The
+// statistics are kept for the TTL after the last message, or indefinitely if
+// it isn't positive, and the message counts are kept by the day.
+func NewStatsRepository(client *redis.Client, ttl time.Duration) things.StatsRepository {
+	return statsRepository{client: client, ttl: ttl}
+}
+
+func (sr statsRepository) Save(ctx context.Context, a things.Activity) error {
+	d := dayOf(a.Time)
+	keys := []string{
+		statsKey("thing", a.ThingID),
+		statsKey("thing", a.ThingID, "channels"),
+		statsKey("thing", a.ThingID, d),
+		statsKey("channel", a.ChanID),
+		statsKey("channel", a.ChanID, d),
+	}
+	// The counter of the day is read until the end of the next day.
+	args := []interface{}{
+		a.Time.UnixNano() / int64(time.Millisecond),
+		a.Protocol,
+		a.ChanID,
+		int64(sr.ttl.Seconds()),
+		int64((2 * day).Seconds()),
+	}
+	if err := saveScript.Run(ctx, sr.client, keys, args...).Err(); err != nil {
+		return errors.Wrap(things.ErrSaveStats, err)
+	}
+	return nil
+}
+
+func (sr statsRepository) RetrieveThingStats(ctx context.Context, id string) (things.Stats, error) {
+	now := time.Now()
+	pipe := sr.client.Pipeline()
+	seen := pipe.HGetAll(ctx, statsKey("thing", id))
+	channels := pipe.HGetAll(ctx, statsKey("thing", id, "channels"))
+	today := pipe.Get(ctx, statsKey("thing", id, dayOf(now)))
+	yesterday := pipe.Get(ctx, statsKey("thing", id, dayOf(now.Add(-day))))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return things.Stats{}, errors.Wrap(things.ErrRetrieveStats, err)
+	}
+
+	stats := toStats(id, seen.Val(), now, today, yesterday)
+	if stats.Stale {
+		return stats, nil
+	}
+	for chanID, v := range channels.Val() {
+		stats.Channels = append(stats.Channels, things.ChannelActivity{ID: chanID, LastSeen: parseTime(v)})
+	}
+	sort.Slice(stats.Channels, func(i, j int) bool {
+		return stats.Channels[i].LastSeen.After(stats.Channels[j].LastSeen)
+	})
+	return stats, nil
+}
+
+func (sr statsRepository) RetrieveChannelStats(ctx context.Context, id string) (things.Stats, error) {
+	now := time.Now()
+	pipe := sr.client.Pipeline()
+	seen := pipe.HGetAll(ctx, statsKey("channel", id))
+	today := pipe.Get(ctx, statsKey("channel", id, dayOf(now)))
+	yesterday := pipe.Get(ctx, statsKey("channel", id, dayOf(now.Add(-day))))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return things.Stats{}, errors.Wrap(things.ErrRetrieveStats, err)
+	}
+
+	return toStats(id, seen.Val(), now, today, yesterday), nil
+}
+
+// toStats returns the stats of the hash, which is empty if nothing was
+// recorded. The daily count is approximated by the count of today and the
+// part of yesterday's count proportional to the part of the day left, as
+// if the messages were published at the constant rate.
+func toStats(id string, seen map[string]string, now time.Time, today, yesterday *redis.StringCmd) things.Stats {
+	if len(seen) == 0 {
+		return things.Stats{ID: id, Stale: true}
+	}
+
+	t, _ := today.Uint64()
+	y, _ := yesterday.Uint64()
+	midnight := now.UTC().Truncate(day)
+	left := 1 - float64(now.Sub(midnight))/float64(day)
+
+	return things.Stats{
+		ID:         id,
+		LastSeen:   parseTime(seen[lastSeenField]),
+		Protocol:   seen[protocolField],
+		DailyCount: t + uint64(float64(y)*left),
+	}
+}
+
+func statsKey(parts ...string) string {
+	key := statsPrefix
+	for _, p := range parts {
+		key = fmt.Sprintf("%s:%s", key, p)
+	}
+	return key
+}
+
+func dayOf(t time.Time) string {
+	return t.UTC().Format(dayFormat)
+}
+
+func parseTime(ms string) time.Time {
+	v, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, v*int64(time.Millisecond)).UTC()
+}
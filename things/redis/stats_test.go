@@ -0,0 +1,140 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsSave(t *testing.T) {
+	repo := redis.NewStatsRepository(redisClient, time.Hour)
+	thingID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	cases := []struct {
+		desc string
+		a    things.Activity
+	}{
+		{
+			desc: "save activity",
+			a:    things.Activity{ThingID: thingID, ChanID: chanID, Protocol: "mqtt", Time: now},
+		},
+		{
+			desc: "save activity out of order",
+			a:    things.Activity{ThingID: thingID, ChanID: chanID, Protocol: "http", Time: now.Add(-time.Minute)},
+		},
+	}
+
+	for _, tc := range cases {
+		err := repo.Save(context.Background(), tc.a)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+	}
+
+	stats, err := repo.RetrieveThingStats(context.Background(), thingID)
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	assert.Equal(t, now, stats.LastSeen, fmt.Sprintf("expected last seen %s got %s", now, stats.LastSeen))
+	assert.Equal(t, "mqtt", stats.Protocol, fmt.Sprintf("expected protocol mqtt got %s", stats.Protocol))
+}
+
+func TestRetrieveThingStats(t *testing.T) {
+	repo := redis.NewStatsRepository(redisClient, time.Hour)
+	thingID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	idle, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	chanID2, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	first, last := now.Add(-2*time.Second), now.Add(-time.Second)
+	for _, a := range []things.Activity{
+		{ThingID: thingID, ChanID: chanID, Protocol: "http", Time: first},
+		{ThingID: thingID, ChanID: chanID2, Protocol: "coap", Time: last},
+	} {
+		err := repo.Save(context.Background(), a)
+		require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc  string
+		id    string
+		stats things.Stats
+	}{
+		{
+			desc: "retrieve stats of thing that published",
+			id:   thingID,
+			stats: things.Stats{
+				ID:         thingID,
+				LastSeen:   last,
+				Protocol:   "coap",
+				DailyCount: 2,
+				Channels: []things.ChannelActivity{
+					{ID: chanID2, LastSeen: last},
+					{ID: chanID, LastSeen: first},
+				},
+			},
+		},
+		{
+			desc:  "retrieve stats of thing that didn't publish",
+			id:    idle,
+			stats: things.Stats{ID: idle, Stale: true},
+		},
+	}
+
+	for _, tc := range cases {
+		stats, err := repo.RetrieveThingStats(context.Background(), tc.id)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.stats, stats, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.stats, stats))
+	}
+}
+
+func TestRetrieveChannelStats(t *testing.T) {
+	repo := redis.NewStatsRepository(redisClient, time.Hour)
+	thingID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	chanID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+	idle, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	err = repo.Save(context.Background(), things.Activity{ThingID: thingID, ChanID: chanID, Protocol: "mqtt", Time: now})
+	require.Nil(t, err, fmt.Sprintf("got unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		id    string
+		stats things.Stats
+	}{
+		{
+			desc:  "retrieve stats of channel published to",
+			id:    chanID,
+			stats: things.Stats{ID: chanID, LastSeen: now, Protocol: "mqtt", DailyCount: 1},
+		},
+		{
+			desc:  "retrieve stats of channel not published to",
+			id:    idle,
+			stats: things.Stats{ID: idle, Stale: true},
+		},
+	}
+
+	for _, tc := range cases {
+		stats, err := repo.RetrieveChannelStats(context.Background(), tc.id)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.stats, stats, fmt.Sprintf("%s: expected %v got %v", tc.desc, tc.stats, stats))
+	}
+}
@@ -8,11 +8,17 @@ import (
 
 	"github.com/go-redis/redis/v8"
 	"github.com/mainflux/mainflux/things"
+	"github.com/mainflux/mainflux/things/authz"
 )
 
 const (
 	streamID  = "mainflux.things"
 	streamLen = 1000
+
+	// policyVersionKey is the counter incremented on each change of the
+	// channel access, so the adapters caching the access decisions tell
+	// the stale ones apart.
+	policyVersionKey = "mainflux.things.policy_version"
 )
 
 var _ things.Service = (*eventStore)(nil)
@@ -100,7 +106,8 @@ func (es eventStore) RemoveThing(ctx context.Context, token, id string) error {
 	}
 
 	event := removeThingEvent{
-		id: id,
+		id:            id,
+		policyVersion: es.bumpPolicyVersion(ctx),
 	}
 	record := &redis.XAddArgs{
 		Stream:       streamID,
@@ -164,6 +171,10 @@ func (es eventStore) ListChannels(ctx context.Context, token string, pm things.P
 	return es.svc.ListChannels(ctx, token, pm)
 }
 
+func (es eventStore) ListChannelsByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	return es.svc.ListChannelsByMetadataKey(ctx, key, pm)
+}
+
 func (es eventStore) ListChannelsByThing(ctx context.Context, token, thID string, pm things.PageMetadata) (things.ChannelsPage, error) {
 	return es.svc.ListChannelsByThing(ctx, token, thID, pm)
 }
@@ -174,7 +185,8 @@ func (es eventStore) RemoveChannel(ctx context.Context, token, id string) error
 	}
 
 	event := removeChannelEvent{
-		id: id,
+		id:            id,
+		policyVersion: es.bumpPolicyVersion(ctx),
 	}
 	record := &redis.XAddArgs{
 		Stream:       streamID,
@@ -186,16 +198,41 @@ func (es eventStore) RemoveChannel(ctx context.Context, token, id string) error
 	return nil
 }
 
+func (es eventStore) UpdateChannelSchema(ctx context.Context, token, id string, schema map[string]interface{}) (things.Channel, error) {
+	channel, err := es.svc.UpdateChannelSchema(ctx, token, id, schema)
+	if err != nil {
+		return channel, err
+	}
+
+	// The whole metadata is sent, so the schema removal is propagated to
+	// the consumers as well.
+	event := updateChannelEvent{
+		id:       channel.ID,
+		name:     channel.Name,
+		metadata: channel.Metadata,
+	}
+	record := &redis.XAddArgs{
+		Stream:       streamID,
+		MaxLenApprox: streamLen,
+		Values:       event.Encode(),
+	}
+	es.client.XAdd(ctx, record).Err()
+
+	return channel, nil
+}
+
 func (es eventStore) Connect(ctx context.Context, token string, chIDs, thIDs []string) error {
 	if err := es.svc.Connect(ctx, token, chIDs, thIDs); err != nil {
 		return err
 	}
 
+	version := es.bumpPolicyVersion(ctx)
 	for _, chID := range chIDs {
 		for _, thID := range thIDs {
 			event := connectThingEvent{
-				chanID:  chID,
-				thingID: thID,
+				chanID:        chID,
+				thingID:       thID,
+				policyVersion: version,
 			}
 			record := &redis.XAddArgs{
 				Stream:       streamID,
@@ -214,11 +251,13 @@ func (es eventStore) Disconnect(ctx context.Context, token string, chIDs, thIDs
 		return err
 	}
 
+	version := es.bumpPolicyVersion(ctx)
 	for _, chID := range chIDs {
 		for _, thID := range thIDs {
 			event := disconnectThingEvent{
-				chanID:  chID,
-				thingID: thID,
+				chanID:        chID,
+				thingID:       thID,
+				policyVersion: version,
 			}
 			record := &redis.XAddArgs{
 				Stream:       streamID,
@@ -233,10 +272,12 @@ func (es eventStore) Disconnect(ctx context.Context, token string, chIDs, thIDs
 }
 
 func (es eventStore) CanAccessByKey(ctx context.Context, chanID string, key string) (string, error) {
+	es.recordPolicyVersion(ctx)
 	return es.svc.CanAccessByKey(ctx, chanID, key)
 }
 
 func (es eventStore) CanAccessByID(ctx context.Context, chanID string, thingID string) error {
+	es.recordPolicyVersion(ctx)
 	return es.svc.CanAccessByID(ctx, chanID, thingID)
 }
 
@@ -251,3 +292,35 @@ func (es eventStore) Identify(ctx context.Context, key string) (string, error) {
 func (es eventStore) ListMembers(ctx context.Context, token, groupID string, pm things.PageMetadata) (things.Page, error) {
 	return es.svc.ListMembers(ctx, token, groupID, pm)
 }
+
+func (es eventStore) ViewThingStats(ctx context.Context, token, id string) (things.Stats, error) {
+	return es.svc.ViewThingStats(ctx, token, id)
+}
+
+func (es eventStore) ViewChannelStats(ctx context.Context, token, id string) (things.Stats, error) {
+	return es.svc.ViewChannelStats(ctx, token, id)
+}
+
+// bumpPolicyVersion increments the policy version after the channel access
+// is changed. Zero is returned if the version couldn't be incremented.
+func (es eventStore) bumpPolicyVersion(ctx context.Context) uint64 {
+	v, err := es.client.Incr(ctx, policyVersionKey).Uint64()
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// recordPolicyVersion records the policy version the access decision is
+// made at. It's read before the decision, so the decision is at least as
+// recent as the version.
+func (es eventStore) recordPolicyVersion(ctx context.Context) {
+	if !authz.PolicyVersionRequested(ctx) {
+		return
+	}
+	v, err := es.client.Get(ctx, policyVersionKey).Uint64()
+	if err != nil {
+		return
+	}
+	authz.SetPolicyVersion(ctx, v)
+}
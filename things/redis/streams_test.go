@@ -47,7 +47,7 @@ func newService(tokens map[string]string) things.Service {
 	thingCache := mocks.NewThingCache()
 	idProvider := uuid.NewMock()
 
-	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, idProvider)
+	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, mocks.NewStatsRepository(), idProvider, idProvider)
 }
 
 func TestCreateThings(t *testing.T) {
@@ -244,8 +244,9 @@ func TestRemoveThing(t *testing.T) {
 			key:  token,
 			err:  nil,
 			event: map[string]interface{}{
-				"id":        sth.ID,
-				"operation": thingRemove,
+				"id":             sth.ID,
+				"operation":      thingRemove,
+				"policy_version": "1",
 			},
 		},
 		{
@@ -404,6 +405,79 @@ func TestUpdateChannel(t *testing.T) {
 	}
 }
 
+func TestUpdateChannelSchema(t *testing.T) {
+	_ = redisClient.FlushAll(context.Background()).Err()
+
+	svc := newService(map[string]string{token: email})
+	// Create channel without sending event.
+	schs, err := svc.CreateChannels(context.Background(), token, things.Channel{Name: "a"})
+	require.Nil(t, err, fmt.Sprintf("unexpected error %s", err))
+	sch := schs[0]
+
+	svc = redis.NewEventStoreMiddleware(svc, redisClient)
+
+	cases := []struct {
+		desc   string
+		id     string
+		schema map[string]interface{}
+		err    error
+		event  map[string]interface{}
+	}{
+		{
+			desc:   "set channel schema successfully",
+			id:     sch.ID,
+			schema: map[string]interface{}{"type": "object"},
+			err:    nil,
+			event: map[string]interface{}{
+				"id":        sch.ID,
+				"name":      "a",
+				"metadata":  "{\"schema\":{\"type\":\"object\"}}",
+				"operation": channelUpdate,
+			},
+		},
+		{
+			desc:   "remove channel schema successfully",
+			id:     sch.ID,
+			schema: nil,
+			err:    nil,
+			event: map[string]interface{}{
+				"id":        sch.ID,
+				"name":      "a",
+				"metadata":  "{}",
+				"operation": channelUpdate,
+			},
+		},
+		{
+			desc:   "set invalid channel schema",
+			id:     sch.ID,
+			schema: map[string]interface{}{"type": "float"},
+			err:    things.ErrMalformedEntity,
+			event:  nil,
+		},
+	}
+
+	lastID := "0"
+	for _, tc := range cases {
+		_, err := svc.UpdateChannelSchema(context.Background(), token, tc.id, tc.schema)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+
+		streams := redisClient.XRead(context.Background(), &r.XReadArgs{
+			Streams: []string{streamID, lastID},
+			Count:   1,
+			Block:   time.Second,
+		}).Val()
+
+		var event map[string]interface{}
+		if len(streams) > 0 && len(streams[0].Messages) > 0 {
+			msg := streams[0].Messages[0]
+			event = msg.Values
+			lastID = msg.ID
+		}
+
+		assert.Equal(t, tc.event, event, fmt.Sprintf("%s: expected %v got %v\n", tc.desc, tc.event, event))
+	}
+}
+
 func TestViewChannel(t *testing.T) {
 	_ = redisClient.FlushAll(context.Background()).Err()
 
@@ -480,8 +554,9 @@ func TestRemoveChannel(t *testing.T) {
 			key:  token,
 			err:  nil,
 			event: map[string]interface{}{
-				"id":        sch.ID,
-				"operation": channelRemove,
+				"id":             sch.ID,
+				"operation":      channelRemove,
+				"policy_version": "1",
 			},
 		},
 		{
@@ -544,9 +619,10 @@ func TestConnectEvent(t *testing.T) {
 			key:     token,
 			err:     nil,
 			event: map[string]interface{}{
-				"chan_id":   sch.ID,
-				"thing_id":  sth.ID,
-				"operation": thingConnect,
+				"chan_id":        sch.ID,
+				"thing_id":       sth.ID,
+				"operation":      thingConnect,
+				"policy_version": "1",
 			},
 		},
 		{
@@ -612,9 +688,10 @@ func TestDisconnectEvent(t *testing.T) {
 			key:     token,
 			err:     nil,
 			event: map[string]interface{}{
-				"chan_id":   sch.ID,
-				"thing_id":  sth.ID,
-				"operation": thingDisconnect,
+				"chan_id":        sch.ID,
+				"thing_id":       sth.ID,
+				"operation":      thingDisconnect,
+				"policy_version": "1",
 			},
 		},
 		{
@@ -5,41 +5,50 @@ package things
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/internal/reqctx"
 	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/pkg/jsonschema"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/pkg/ulid"
+	"google.golang.org/grpc"
 )
 
 var (
 	// ErrUnauthorizedAccess indicates missing or invalid credentials provided
 	// when accessing a protected resource.
-	ErrUnauthorizedAccess = errors.New("missing or invalid credentials provided")
+	ErrUnauthorizedAccess = apierrors.Authentication("missing or invalid credentials provided")
 
 	// ErrCreateUUID indicates error in creating uuid for entity creation
-	ErrCreateUUID = errors.New("uuid creation failed")
+	ErrCreateUUID = apierrors.Internal("uuid creation failed")
 
 	// ErrCreateEntity indicates error in creating entity or entities
-	ErrCreateEntity = errors.New("create entity failed")
+	ErrCreateEntity = apierrors.InvalidRequest("create entity failed")
 
 	// ErrUpdateEntity indicates error in updating entity or entities
-	ErrUpdateEntity = errors.New("update entity failed")
+	ErrUpdateEntity = apierrors.InvalidRequest("update entity failed")
 
 	// ErrViewEntity indicates error in viewing entity or entities
-	ErrViewEntity = errors.New("view entity failed")
+	ErrViewEntity = apierrors.InvalidRequest("view entity failed")
 
 	// ErrRemoveEntity indicates error in removing entity
-	ErrRemoveEntity = errors.New("remove entity failed")
+	ErrRemoveEntity = apierrors.InvalidRequest("remove entity failed")
 
 	// ErrConnect indicates error in adding connection
-	ErrConnect = errors.New("add connection failed")
+	ErrConnect = apierrors.InvalidRequest("add connection failed")
 
 	// ErrDisconnect indicates error in removing connection
-	ErrDisconnect = errors.New("remove connection failed")
+	ErrDisconnect = apierrors.InvalidRequest("remove connection failed")
 
 	// ErrFailedToRetrieveThings failed to retrieve things.
-	ErrFailedToRetrieveThings = errors.New("failed to retrieve group members")
+	ErrFailedToRetrieveThings = apierrors.Internal("failed to retrieve group members")
+
+	// ErrSaveQuota indicates failure to set the channel quota read by the
+	// adapters.
+	ErrSaveQuota = apierrors.Internal("failed to save channel quota")
 )
 
 // Service specifies an API that must be fullfiled by the domain service
@@ -88,6 +97,12 @@ type Service interface {
 	// user identified by the provided key.
 	ListChannels(ctx context.Context, token string, pm PageMetadata) (ChannelsPage, error)
 
+	// ListChannelsByMetadataKey retrieves data about subset of channels of all
+	// the users having the specified metadata key set, e.g. the channels having
+	// the schema. It's intended for the internal services, so it isn't
+	// authorized, and it's served by the internal API only.
+	ListChannelsByMetadataKey(ctx context.Context, key string, pm PageMetadata) (ChannelsPage, error)
+
 	// ListChannelsByThing retrieves data about subset of channels that have
 	// specified thing connected or not connected to them and belong to the user identified by
 	// the provided key.
@@ -97,6 +112,12 @@ type Service interface {
 	// belongs to the user identified by the provided key.
 	RemoveChannel(ctx context.Context, token, id string) error
 
+	// UpdateChannelSchema sets the JSON schema that the messages published
+	// to the channel identified by the provided ID must match, and returns
+	// the updated channel. The schema is stored in the channel metadata
+	// under the SchemaKey. Nil schema removes it.
+	UpdateChannelSchema(ctx context.Context, token, id string, schema map[string]interface{}) (Channel, error)
+
 	// Connect adds things to the channels list of connected things.
 	Connect(ctx context.Context, token string, chIDs, thIDs []string) error
 
@@ -121,6 +142,16 @@ type Service interface {
 
 	// ListMembers retrieves everything that is assigned to a group identified by groupID.
 	ListMembers(ctx context.Context, token, groupID string, pm PageMetadata) (Page, error)
+
+	// ViewThingStats retrieves the publishing statistics of the thing
+	// identified by the provided ID, that belongs to the user identified by
+	// the provided key.
+	ViewThingStats(ctx context.Context, token, id string) (Stats, error)
+
+	// ViewChannelStats retrieves the publishing statistics of the channel
+	// identified by the provided ID, that belongs to the user identified by
+	// the provided key.
+	ViewChannelStats(ctx context.Context, token, id string) (Stats, error)
 }
 
 // PageMetadata contains page metadata that helps navigation.
@@ -143,19 +174,25 @@ type thingsService struct {
 	channels     ChannelRepository
 	channelCache ChannelCache
 	thingCache   ThingCache
+	stats        StatsRepository
 	idProvider   mainflux.IDProvider
+	keyProvider  mainflux.IDProvider
 	ulidProvider mainflux.IDProvider
 }
 
-// New instantiates the things service implementation.
-func New(auth mainflux.AuthServiceClient, things ThingRepository, channels ChannelRepository, ccache ChannelCache, tcache ThingCache, idp mainflux.IDProvider) Service {
+// New instantiates the things service implementation. Thing keys are
+// secrets, so key provider should generate unpredictable IDs, unlike
+// the ID provider, which may generate sortable ones.
+func New(auth mainflux.AuthServiceClient, things ThingRepository, channels ChannelRepository, ccache ChannelCache, tcache ThingCache, stats StatsRepository, idp, kp mainflux.IDProvider) Service {
 	return &thingsService{
-		auth:         auth,
+		auth:         subjectAuth{auth},
 		things:       things,
 		channels:     channels,
 		channelCache: ccache,
 		thingCache:   tcache,
+		stats:        stats,
 		idProvider:   idp,
+		keyProvider:  kp,
 		ulidProvider: ulid.New(),
 	}
 }
@@ -175,7 +212,7 @@ func (ts *thingsService) CreateThings(ctx context.Context, token string, things
 		things[i].Owner = res.GetEmail()
 
 		if things[i].Key == "" {
-			things[i].Key, err = ts.idProvider.ID()
+			things[i].Key, err = ts.keyProvider.ID()
 			if err != nil {
 				return []Thing{}, errors.Wrap(ErrCreateUUID, err)
 			}
@@ -253,6 +290,13 @@ func (ts *thingsService) CreateChannels(ctx context.Context, token string, chann
 	}
 
 	for i := range channels {
+		if err := validateRetention(channels[i].Metadata); err != nil {
+			return []Channel{}, errors.Wrap(ErrMalformedEntity, err)
+		}
+		if err := validateQuota(channels[i].Metadata); err != nil {
+			return []Channel{}, errors.Wrap(ErrMalformedEntity, err)
+		}
+
 		channels[i].ID, err = ts.idProvider.ID()
 		if err != nil {
 			return []Channel{}, errors.Wrap(ErrCreateUUID, err)
@@ -261,7 +305,19 @@ func (ts *thingsService) CreateChannels(ctx context.Context, token string, chann
 		channels[i].Owner = res.GetEmail()
 	}
 
-	return ts.channels.Save(ctx, channels...)
+	chs, err := ts.channels.Save(ctx, channels...)
+	if err != nil {
+		return []Channel{}, err
+	}
+	for _, ch := range chs {
+		if _, ok := ch.Metadata[QuotaKey]; !ok {
+			continue
+		}
+		if err := ts.saveQuota(ctx, ch); err != nil {
+			return []Channel{}, err
+		}
+	}
+	return chs, nil
 }
 
 func (ts *thingsService) UpdateChannel(ctx context.Context, token string, channel Channel) error {
@@ -270,8 +326,32 @@ func (ts *thingsService) UpdateChannel(ctx context.Context, token string, channe
 		return errors.Wrap(ErrUnauthorizedAccess, err)
 	}
 
+	if err := validateRetention(channel.Metadata); err != nil {
+		return errors.Wrap(ErrMalformedEntity, err)
+	}
+	if err := validateQuota(channel.Metadata); err != nil {
+		return errors.Wrap(ErrMalformedEntity, err)
+	}
+
 	channel.Owner = res.GetEmail()
-	return ts.channels.Update(ctx, channel)
+	if err := ts.channels.Update(ctx, channel); err != nil {
+		return err
+	}
+	return ts.saveQuota(ctx, channel)
+}
+
+// saveQuota sets the quota of the channel in the cache read by the adapters,
+// or removes it if the channel doesn't set one.
+func (ts *thingsService) saveQuota(ctx context.Context, ch Channel) error {
+	v, ok := ch.Metadata[QuotaKey]
+	if !ok {
+		return ts.channelCache.RemoveQuota(ctx, ch.ID)
+	}
+	quota, err := ParseQuota(v)
+	if err != nil {
+		return errors.Wrap(ErrMalformedEntity, err)
+	}
+	return ts.channelCache.SaveQuota(ctx, ch.ID, quota)
 }
 
 func (ts *thingsService) ViewChannel(ctx context.Context, token, id string) (Channel, error) {
@@ -292,6 +372,14 @@ func (ts *thingsService) ListChannels(ctx context.Context, token string, pm Page
 	return ts.channels.RetrieveAll(ctx, res.GetEmail(), pm)
 }
 
+func (ts *thingsService) ListChannelsByMetadataKey(ctx context.Context, key string, pm PageMetadata) (ChannelsPage, error) {
+	if key == "" {
+		return ChannelsPage{}, ErrMalformedEntity
+	}
+
+	return ts.channels.RetrieveByMetadataKey(ctx, key, pm)
+}
+
 func (ts *thingsService) ListChannelsByThing(ctx context.Context, token, thID string, pm PageMetadata) (ChannelsPage, error) {
 	res, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
 	if err != nil {
@@ -314,6 +402,43 @@ func (ts *thingsService) RemoveChannel(ctx context.Context, token, id string) er
 	return ts.channels.Remove(ctx, res.GetEmail(), id)
 }
 
+func (ts *thingsService) UpdateChannelSchema(ctx context.Context, token, id string, schema map[string]interface{}) (Channel, error) {
+	res, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return Channel{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+
+	if schema != nil {
+		data, err := json.Marshal(schema)
+		if err != nil {
+			return Channel{}, errors.Wrap(ErrMalformedEntity, err)
+		}
+		if _, err := jsonschema.Compile(data); err != nil {
+			return Channel{}, errors.Wrap(ErrMalformedEntity, err)
+		}
+	}
+
+	channel, err := ts.channels.RetrieveByID(ctx, res.GetEmail(), id)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	metadata := make(map[string]interface{}, len(channel.Metadata)+1)
+	for k, v := range channel.Metadata {
+		metadata[k] = v
+	}
+	delete(metadata, SchemaKey)
+	if schema != nil {
+		metadata[SchemaKey] = schema
+	}
+	channel.Metadata = metadata
+
+	if err := ts.channels.Update(ctx, channel); err != nil {
+		return Channel{}, err
+	}
+	return channel, nil
+}
+
 func (ts *thingsService) Connect(ctx context.Context, token string, chIDs, thIDs []string) error {
 	res, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
 	if err != nil {
@@ -343,6 +468,7 @@ func (ts *thingsService) Disconnect(ctx context.Context, token string, chIDs, th
 func (ts *thingsService) CanAccessByKey(ctx context.Context, chanID, thingKey string) (string, error) {
 	thingID, err := ts.hasThing(ctx, chanID, thingKey)
 	if err == nil {
+		reqctx.SetSubject(ctx, reqctx.Thing(thingID))
 		return thingID, nil
 	}
 
@@ -350,6 +476,7 @@ func (ts *thingsService) CanAccessByKey(ctx context.Context, chanID, thingKey st
 	if err != nil {
 		return "", err
 	}
+	reqctx.SetSubject(ctx, reqctx.Thing(thingID))
 
 	if err := ts.thingCache.Save(ctx, thingKey, thingID); err != nil {
 		return "", err
@@ -385,6 +512,7 @@ func (ts *thingsService) IsChannelOwner(ctx context.Context, owner, chanID strin
 func (ts *thingsService) Identify(ctx context.Context, key string) (string, error) {
 	id, err := ts.thingCache.ID(ctx, key)
 	if err == nil {
+		reqctx.SetSubject(ctx, reqctx.Thing(id))
 		return id, nil
 	}
 
@@ -392,6 +520,7 @@ func (ts *thingsService) Identify(ctx context.Context, key string) (string, erro
 	if err != nil {
 		return "", err
 	}
+	reqctx.SetSubject(ctx, reqctx.Thing(id))
 
 	if err := ts.thingCache.Save(ctx, key, id); err != nil {
 		return "", err
@@ -439,3 +568,46 @@ func (ts *thingsService) members(ctx context.Context, token, groupID, groupType
 	}
 	return res.Members, nil
 }
+
+func (ts *thingsService) ViewThingStats(ctx context.Context, token, id string) (Stats, error) {
+	res, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return Stats{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+
+	if _, err := ts.things.RetrieveByID(ctx, res.GetEmail(), id); err != nil {
+		return Stats{}, err
+	}
+	return ts.stats.RetrieveThingStats(ctx, id)
+}
+
+func (ts *thingsService) ViewChannelStats(ctx context.Context, token, id string) (Stats, error) {
+	res, err := ts.auth.Identify(ctx, &mainflux.Token{Value: token})
+	if err != nil {
+		return Stats{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+
+	if _, err := ts.channels.RetrieveByID(ctx, res.GetEmail(), id); err != nil {
+		return Stats{}, err
+	}
+	return ts.stats.RetrieveChannelStats(ctx, id)
+}
+
+// subjectAuth sets the user identified by the auth service as the subject
+// of the request.
+type subjectAuth struct {
+	mainflux.AuthServiceClient
+}
+
+func (sa subjectAuth) Identify(ctx context.Context, in *mainflux.Token, opts ...grpc.CallOption) (*mainflux.UserIdentity, error) {
+	res, err := sa.AuthServiceClient.Identify(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	id := res.GetId()
+	if id == "" {
+		id = res.GetEmail()
+	}
+	reqctx.SetSubject(ctx, reqctx.User(id))
+	return res, nil
+}
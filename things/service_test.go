@@ -40,7 +40,7 @@ func newService(tokens map[string]string) things.Service {
 	thingCache := mocks.NewThingCache()
 	idProvider := uuid.NewMock()
 
-	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, idProvider)
+	return things.New(auth, thingsRepo, channelsRepo, chanCache, thingCache, mocks.NewStatsRepository(), idProvider, idProvider)
 }
 
 func TestCreateThings(t *testing.T) {
@@ -557,6 +557,36 @@ func TestCreateChannels(t *testing.T) {
 			token:    wrongValue,
 			err:      things.ErrUnauthorizedAccess,
 		},
+		{
+			desc:     "create channel with retention",
+			channels: []things.Channel{{Name: "f", Metadata: map[string]interface{}{things.RetentionKey: "7d"}}},
+			token:    token,
+			err:      nil,
+		},
+		{
+			desc:     "create channel with invalid retention",
+			channels: []things.Channel{{Name: "g", Metadata: map[string]interface{}{things.RetentionKey: "week"}}},
+			token:    token,
+			err:      things.ErrMalformedEntity,
+		},
+		{
+			desc:     "create channel with quota",
+			channels: []things.Channel{{Name: "h", Metadata: map[string]interface{}{things.QuotaKey: float64(100000)}}},
+			token:    token,
+			err:      nil,
+		},
+		{
+			desc:     "create channel with fractional quota",
+			channels: []things.Channel{{Name: "i", Metadata: map[string]interface{}{things.QuotaKey: 1.5}}},
+			token:    token,
+			err:      things.ErrMalformedEntity,
+		},
+		{
+			desc:     "create channel with string quota",
+			channels: []things.Channel{{Name: "j", Metadata: map[string]interface{}{things.QuotaKey: "100"}}},
+			token:    token,
+			err:      things.ErrMalformedEntity,
+		},
 	}
 
 	for _, cc := range cases {
@@ -596,6 +626,30 @@ func TestUpdateChannel(t *testing.T) {
 			token:   token,
 			err:     things.ErrNotFound,
 		},
+		{
+			desc:    "update channel retention",
+			channel: withRetention(ch, "36h"),
+			token:   token,
+			err:     nil,
+		},
+		{
+			desc:    "update channel with negative retention",
+			channel: withRetention(ch, "-1h"),
+			token:   token,
+			err:     things.ErrMalformedEntity,
+		},
+		{
+			desc:    "update channel quota",
+			channel: withQuota(ch, 0),
+			token:   token,
+			err:     nil,
+		},
+		{
+			desc:    "update channel with negative quota",
+			channel: withQuota(ch, -1),
+			token:   token,
+			err:     things.ErrMalformedEntity,
+		},
 	}
 
 	for _, tc := range cases {
@@ -604,6 +658,90 @@ func TestUpdateChannel(t *testing.T) {
 	}
 }
 
+func withRetention(ch things.Channel, retention string) things.Channel {
+	ch.Metadata = map[string]interface{}{things.RetentionKey: retention}
+	return ch
+}
+
+func withQuota(ch things.Channel, quota float64) things.Channel {
+	ch.Metadata = map[string]interface{}{things.QuotaKey: quota}
+	return ch
+}
+
+func TestUpdateChannelSchema(t *testing.T) {
+	svc := newService(map[string]string{token: email})
+	ch := channel
+	ch.Metadata = map[string]interface{}{"location": "lab"}
+	chs, err := svc.CreateChannels(context.Background(), token, ch)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s\n", err))
+	ch = chs[0]
+
+	schema := map[string]interface{}{
+		"type":     "object",
+		"required": []interface{}{"temperature"},
+	}
+
+	cases := []struct {
+		desc     string
+		id       string
+		token    string
+		schema   map[string]interface{}
+		metadata map[string]interface{}
+		err      error
+	}{
+		{
+			desc:     "set channel schema",
+			id:       ch.ID,
+			token:    token,
+			schema:   schema,
+			metadata: map[string]interface{}{"location": "lab", things.SchemaKey: schema},
+			err:      nil,
+		},
+		{
+			desc:     "set invalid channel schema",
+			id:       ch.ID,
+			token:    token,
+			schema:   map[string]interface{}{"type": "float"},
+			metadata: map[string]interface{}{"location": "lab", things.SchemaKey: schema},
+			err:      things.ErrMalformedEntity,
+		},
+		{
+			desc:     "set channel schema with wrong credentials",
+			id:       ch.ID,
+			token:    wrongValue,
+			schema:   schema,
+			metadata: map[string]interface{}{"location": "lab", things.SchemaKey: schema},
+			err:      things.ErrUnauthorizedAccess,
+		},
+		{
+			desc:   "set non-existing channel schema",
+			id:     wrongID,
+			token:  token,
+			schema: schema,
+			err:    things.ErrNotFound,
+		},
+		{
+			desc:     "remove channel schema",
+			id:       ch.ID,
+			token:    token,
+			schema:   nil,
+			metadata: map[string]interface{}{"location": "lab"},
+			err:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		_, err := svc.UpdateChannelSchema(context.Background(), tc.token, tc.id, tc.schema)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if tc.metadata == nil {
+			continue
+		}
+		saved, err := svc.ViewChannel(context.Background(), token, tc.id)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s\n", tc.desc, err))
+		assert.Equal(t, tc.metadata, saved.Metadata, fmt.Sprintf("%s: expected metadata %v got %v\n", tc.desc, tc.metadata, saved.Metadata))
+	}
+}
+
 func TestViewChannel(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 	chs, err := svc.CreateChannels(context.Background(), token, channel)
@@ -785,6 +923,77 @@ func TestListChannels(t *testing.T) {
 	}
 }
 
+func TestListChannelsByMetadataKey(t *testing.T) {
+	svc := newService(map[string]string{token: email, token2: "other@example.com"})
+	schema := map[string]interface{}{"type": "object"}
+
+	// Create the channels of both the users, the half of them with schema.
+	for i := uint64(0); i < n; i++ {
+		ch := channel
+		ch.Metadata = map[string]interface{}{"location": "lab"}
+		if i%2 == 0 {
+			ch.Metadata = map[string]interface{}{things.SchemaKey: schema}
+		}
+		tkn := token
+		if i >= n/2 {
+			tkn = token2
+		}
+		_, err := svc.CreateChannels(context.Background(), tkn, ch)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	cases := map[string]struct {
+		key          string
+		pageMetadata things.PageMetadata
+		size         uint64
+		err          error
+	}{
+		"list all channels with key": {
+			key: things.SchemaKey,
+			pageMetadata: things.PageMetadata{
+				Offset: 0,
+				Limit:  n,
+			},
+			size: n / 2,
+			err:  nil,
+		},
+		"list last channel with key": {
+			key: things.SchemaKey,
+			pageMetadata: things.PageMetadata{
+				Offset: n/2 - 1,
+				Limit:  n,
+			},
+			size: 1,
+			err:  nil,
+		},
+		"list channels with non-existing key": {
+			key: things.RetentionKey,
+			pageMetadata: things.PageMetadata{
+				Offset: 0,
+				Limit:  n,
+			},
+			size: 0,
+			err:  nil,
+		},
+		"list channels with empty key": {
+			key: "",
+			pageMetadata: things.PageMetadata{
+				Offset: 0,
+				Limit:  n,
+			},
+			size: 0,
+			err:  things.ErrMalformedEntity,
+		},
+	}
+
+	for desc, tc := range cases {
+		page, err := svc.ListChannelsByMetadataKey(context.Background(), tc.key, tc.pageMetadata)
+		size := uint64(len(page.Channels))
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected %d got %d\n", desc, tc.size, size))
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
+	}
+}
+
 func TestListChannelsByThing(t *testing.T) {
 	svc := newService(map[string]string{token: email})
 
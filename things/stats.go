@@ -0,0 +1,89 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package things
+
+import (
+	"context"
+	"time"
+
+	"github.com/mainflux/mainflux/internal/apierrors"
+	"github.com/mainflux/mainflux/pkg/messaging"
+)
+
+var (
+	// ErrSaveStats indicates failure to record the published message.
+	ErrSaveStats = apierrors.Internal("failed to save stats")
+
+	// ErrRetrieveStats indicates failure to retrieve the statistics.
+	ErrRetrieveStats = apierrors.Internal("failed to retrieve stats")
+)
+
+// Activity represents the message published by the thing to the channel.
+type Activity struct {
+	ThingID  string
+	ChanID   string
+	Protocol string
+	Time     time.Time
+}
+
+// ChannelActivity represents the time the thing last published to the
+// channel.
+type ChannelActivity struct {
+	ID       string
+	LastSeen time.Time
+}
+
+// Stats represents the publishing statistics of the thing or the channel.
+type Stats struct {
+	ID string
+
+	// Stale indicates that no statistics are recorded, either because
+	// nothing was published in the retention period, or because the
+	// statistics aren't collected, so the rest of the fields are empty.
+	Stale bool
+
+	LastSeen time.Time
+	Protocol string
+
+	// DailyCount is the approximate number of the messages published in
+	// the last 24 hours.
+	DailyCount uint64
+
+	// Channels lists the channels the thing published to, the most recent
+	// one first. It's empty for the channel statistics.
+	Channels []ChannelActivity
+}
+
+// StatsRepository specifies the publishing statistics persistence API.
+type StatsRepository interface {
+	// Save records the published message.
+	Save(ctx context.Context, a Activity) error
+
+	// RetrieveThingStats retrieves the statistics of the thing.
+	RetrieveThingStats(ctx context.Context, id string) (Stats, error)
+
+	// RetrieveChannelStats retrieves the statistics of the channel.
+	RetrieveChannelStats(ctx context.Context, id string) (Stats, error)
+}
+
+// NewStatsHandler returns the message handler recording the messages
+// published by the adapters. The messages without the publisher are
+// skipped.
+func NewStatsHandler(repo StatsRepository) messaging.MessageHandler {
+	return func(msg messaging.Message) error {
+		if msg.Publisher == "" || msg.Channel == "" {
+			return nil
+		}
+		t := time.Now()
+		if msg.Created > 0 {
+			t = time.Unix(0, msg.Created)
+		}
+		return repo.Save(context.Background(), Activity{
+			ThingID:  msg.Publisher,
+			ChanID:   msg.Channel,
+			Protocol: msg.Protocol,
+			Time:     t,
+		})
+	}
+}
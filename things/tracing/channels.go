@@ -15,12 +15,15 @@ const (
 	updateChannelOp           = "update_channel"
 	retrieveChannelByIDOp     = "retrieve_channel_by_id"
 	retrieveAllChannelsOp     = "retrieve_all_channels"
+	retrieveChannelsByKeyOp   = "retrieve_channels_by_metadata_key"
 	retrieveChannelsByThingOp = "retrieve_channels_by_thing"
 	removeChannelOp           = "retrieve_channel"
 	connectOp                 = "connect"
 	disconnectOp              = "disconnect"
 	hasThingOp                = "has_thing"
 	hasThingByIDOp            = "has_thing_by_id"
+	saveQuotaOp               = "save_quota"
+	removeQuotaOp             = "remove_quota"
 )
 
 var (
@@ -74,6 +77,14 @@ func (crm channelRepositoryMiddleware) RetrieveAll(ctx context.Context, owner st
 	return crm.repo.RetrieveAll(ctx, owner, pm)
 }
 
+func (crm channelRepositoryMiddleware) RetrieveByMetadataKey(ctx context.Context, key string, pm things.PageMetadata) (things.ChannelsPage, error) {
+	span := createSpan(ctx, crm.tracer, retrieveChannelsByKeyOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return crm.repo.RetrieveByMetadataKey(ctx, key, pm)
+}
+
 func (crm channelRepositoryMiddleware) RetrieveByThing(ctx context.Context, owner, thID string, pm things.PageMetadata) (things.ChannelsPage, error) {
 	span := createSpan(ctx, crm.tracer, retrieveChannelsByThingOp)
 	defer span.Finish()
@@ -167,3 +178,19 @@ func (ccm channelCacheMiddleware) Remove(ctx context.Context, chanID string) err
 
 	return ccm.cache.Remove(ctx, chanID)
 }
+
+func (ccm channelCacheMiddleware) SaveQuota(ctx context.Context, chanID string, quota uint64) error {
+	span := createSpan(ctx, ccm.tracer, saveQuotaOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return ccm.cache.SaveQuota(ctx, chanID, quota)
+}
+
+func (ccm channelCacheMiddleware) RemoveQuota(ctx context.Context, chanID string) error {
+	span := createSpan(ctx, ccm.tracer, removeQuotaOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return ccm.cache.RemoveQuota(ctx, chanID)
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"context"
+
+	"github.com/mainflux/mainflux/things"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+const (
+	saveStatsOp            = "save_stats"
+	retrieveThingStatsOp   = "retrieve_thing_stats"
+	retrieveChannelStatsOp = "retrieve_channel_stats"
+)
+
+var _ things.StatsRepository = (*statsRepositoryMiddleware)(nil)
+
+type statsRepositoryMiddleware struct {
+	tracer opentracing.Tracer
+	repo   things.StatsRepository
+}
+
+// StatsRepositoryMiddleware tracks request and their latency, and adds spans
+// to context.
+func StatsRepositoryMiddleware(tracer opentracing.Tracer, repo things.StatsRepository) things.StatsRepository {
+	return statsRepositoryMiddleware{
+		tracer: tracer,
+		repo:   repo,
+	}
+}
+
+func (srm statsRepositoryMiddleware) Save(ctx context.Context, a things.Activity) error {
+	span := createSpan(ctx, srm.tracer, saveStatsOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return srm.repo.Save(ctx, a)
+}
+
+func (srm statsRepositoryMiddleware) RetrieveThingStats(ctx context.Context, id string) (things.Stats, error) {
+	span := createSpan(ctx, srm.tracer, retrieveThingStatsOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return srm.repo.RetrieveThingStats(ctx, id)
+}
+
+func (srm statsRepositoryMiddleware) RetrieveChannelStats(ctx context.Context, id string) (things.Stats, error) {
+	span := createSpan(ctx, srm.tracer, retrieveChannelStatsOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return srm.repo.RetrieveChannelStats(ctx, id)
+}
@@ -90,6 +90,7 @@ func viewUserEndpoint(svc users.Service) endpoint.Endpoint {
 			ID:       u.ID,
 			Email:    u.Email,
 			Metadata: u.Metadata,
+			Status:   u.Status,
 		}, nil
 	}
 }
@@ -109,6 +110,7 @@ func viewProfileEndpoint(svc users.Service) endpoint.Endpoint {
 			ID:       u.ID,
 			Email:    u.Email,
 			Metadata: u.Metadata,
+			Status:   u.Status,
 		}, nil
 	}
 }
@@ -119,7 +121,7 @@ func listUsersEndpoint(svc users.Service) endpoint.Endpoint {
 		if err := req.validate(); err != nil {
 			return users.UserPage{}, err
 		}
-		up, err := svc.ListUsers(ctx, req.token, req.offset, req.limit, req.email, req.metadata)
+		up, err := svc.ListUsers(ctx, req.token, req.offset, req.limit, req.email, req.status, req.metadata)
 		if err != nil {
 			return users.UserPage{}, err
 		}
@@ -189,6 +191,65 @@ func listMembersEndpoint(svc users.Service) endpoint.Endpoint {
 	}
 }
 
+func disableUserEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(changeUserStatusReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+		if err := svc.DisableUser(ctx, req.token, req.userID); err != nil {
+			return nil, err
+		}
+		return changeUserStatusRes{}, nil
+	}
+}
+
+func enableUserEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(changeUserStatusReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+		if err := svc.EnableUser(ctx, req.token, req.userID); err != nil {
+			return nil, err
+		}
+		return changeUserStatusRes{}, nil
+	}
+}
+
+func listEventsEndpoint(svc users.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listEventsReq)
+		if err := req.validate(); err != nil {
+			return nil, err
+		}
+
+		page, err := svc.ListEvents(ctx, req.token, req.userID, req.from, req.offset, req.limit)
+		if err != nil {
+			return nil, err
+		}
+
+		res := eventsPageRes{
+			pageRes: pageRes{
+				Total:  page.Total,
+				Offset: page.Offset,
+				Limit:  page.Limit,
+			},
+			Events: []eventRes{},
+		}
+		for _, e := range page.Events {
+			res.Events = append(res.Events, eventRes{
+				ID:       e.ID,
+				UserID:   e.UserID,
+				Type:     e.Type,
+				Time:     e.Time,
+				Metadata: e.Metadata,
+			})
+		}
+		return res, nil
+	}
+}
+
 func buildUsersResponse(up users.UserPage) userPageRes {
 	res := userPageRes{
 		pageRes: pageRes{
@@ -203,6 +264,7 @@ func buildUsersResponse(up users.UserPage) userPageRes {
 			ID:       user.ID,
 			Email:    user.Email,
 			Metadata: user.Metadata,
+			Status:   user.Status,
 		}
 		res.Users = append(res.Users, view)
 	}
@@ -37,6 +37,7 @@ const (
 
 var (
 	user           = users.User{Email: validEmail, Password: validPass}
+	admin          = users.User{Email: "admin@example.com", Password: validPass}
 	notFoundRes    = toJSON(errorRes{users.ErrUserNotFound.Error()})
 	unauthRes      = toJSON(errorRes{users.ErrUnauthorizedAccess.Error()})
 	malformedRes   = toJSON(errorRes{users.ErrMalformedEntity.Error()})
@@ -74,11 +75,11 @@ func (tr testRequest) make() (*http.Response, error) {
 func newService() users.Service {
 	usersRepo := mocks.NewUserRepository()
 	hasher := bcrypt.New()
-	auth := mocks.NewAuthService(map[string]string{user.Email: user.Email})
+	auth := mocks.NewAuthService(map[string]string{user.Email: user.Email, admin.Email: admin.Email})
 	email := mocks.NewEmailer()
 	idProvider := uuid.New()
 
-	return users.New(usersRepo, hasher, auth, email, idProvider, passRegex)
+	return users.New(usersRepo, mocks.NewEventRepository(), hasher, auth, email, idProvider, passRegex, admin.Email)
 }
 
 func newServer(svc users.Service) *httptest.Server {
@@ -438,3 +439,164 @@ func TestPasswordChange(t *testing.T) {
 type errorRes struct {
 	Err string `json:"error"`
 }
+
+func TestChangeStatus(t *testing.T) {
+	svc := newService()
+	ts := newServer(svc)
+	defer ts.Close()
+	client := ts.Client()
+	userID, err := svc.Register(context.Background(), user)
+	require.Nil(t, err, fmt.Sprintf("register user got unexpected error: %s", err))
+	_, err = svc.Register(context.Background(), admin)
+	require.Nil(t, err, fmt.Sprintf("register admin got unexpected error: %s", err))
+
+	cases := []struct {
+		desc   string
+		action string
+		id     string
+		token  string
+		status int
+		login  int
+	}{
+		{"disable user", "disable", userID, admin.Email, http.StatusOK, http.StatusForbidden},
+		{"enable user with non-admin token", "enable", userID, user.Email, http.StatusForbidden, http.StatusForbidden},
+		{"enable user without token", "enable", userID, "", http.StatusForbidden, http.StatusForbidden},
+		{"enable non-existing user", "enable", "non-existing", admin.Email, http.StatusNotFound, http.StatusForbidden},
+		{"enable user", "enable", userID, admin.Email, http.StatusOK, http.StatusCreated},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: client,
+			method: http.MethodPost,
+			url:    fmt.Sprintf("%s/users/%s/%s", ts.URL, tc.id, tc.action),
+			token:  tc.token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+
+		req = testRequest{
+			client:      client,
+			method:      http.MethodPost,
+			url:         fmt.Sprintf("%s/tokens", ts.URL),
+			contentType: contentType,
+			body:        strings.NewReader(toJSON(user)),
+		}
+		res, err = req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.login, res.StatusCode, fmt.Sprintf("%s: expected login status code %d got %d", tc.desc, tc.login, res.StatusCode))
+	}
+}
+
+func TestListUsersByStatus(t *testing.T) {
+	svc := newService()
+	ts := newServer(svc)
+	defer ts.Close()
+	client := ts.Client()
+	userID, err := svc.Register(context.Background(), user)
+	require.Nil(t, err, fmt.Sprintf("register user got unexpected error: %s", err))
+	_, err = svc.Register(context.Background(), admin)
+	require.Nil(t, err, fmt.Sprintf("register admin got unexpected error: %s", err))
+	err = svc.DisableUser(context.Background(), admin.Email, userID)
+	require.Nil(t, err, fmt.Sprintf("disable user got unexpected error: %s", err))
+
+	cases := []struct {
+		desc   string
+		query  string
+		status int
+		emails []string
+	}{
+		{"list disabled users", "status=disabled", http.StatusOK, []string{user.Email}},
+		{"list enabled users", "status=enabled", http.StatusOK, []string{admin.Email}},
+		{"list all users", "", http.StatusOK, []string{admin.Email, user.Email}},
+		{"list users with invalid status", "status=invalid", http.StatusBadRequest, nil},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: client,
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/users?%s", ts.URL, tc.query),
+			token:  admin.Email,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusOK {
+			continue
+		}
+		var page struct {
+			Users []struct {
+				Email  string `json:"email"`
+				Status string `json:"status"`
+			} `json:"users"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&page)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var emails []string
+		for _, u := range page.Users {
+			emails = append(emails, u.Email)
+		}
+		assert.Equal(t, tc.emails, emails, fmt.Sprintf("%s: expected users %v got %v", tc.desc, tc.emails, emails))
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	svc := newService()
+	ts := newServer(svc)
+	defer ts.Close()
+	client := ts.Client()
+	userID, err := svc.Register(context.Background(), user)
+	require.Nil(t, err, fmt.Sprintf("register user got unexpected error: %s", err))
+	_, err = svc.Register(context.Background(), admin)
+	require.Nil(t, err, fmt.Sprintf("register admin got unexpected error: %s", err))
+	_, err = svc.Login(context.Background(), user)
+	require.Nil(t, err, fmt.Sprintf("login got unexpected error: %s", err))
+	err = svc.DisableUser(context.Background(), admin.Email, userID)
+	require.Nil(t, err, fmt.Sprintf("disable user got unexpected error: %s", err))
+
+	cases := []struct {
+		desc   string
+		id     string
+		token  string
+		query  string
+		status int
+		types  []string
+	}{
+		{"list user events", userID, admin.Email, "", http.StatusOK, []string{users.LoginEvent, users.DisabledEvent}},
+		{"list user events since the future", userID, admin.Email, "from=2100-01-01T00:00:00Z", http.StatusOK, nil},
+		{"list user events with invalid start time", userID, admin.Email, "from=yesterday", http.StatusBadRequest, nil},
+		{"list user events with limit over max", userID, admin.Email, "limit=101", http.StatusBadRequest, nil},
+		{"list user events with non-admin token", userID, user.Email, "", http.StatusForbidden, nil},
+		{"list events of non-existing user", "non-existing", admin.Email, "", http.StatusNotFound, nil},
+	}
+
+	for _, tc := range cases {
+		req := testRequest{
+			client: client,
+			method: http.MethodGet,
+			url:    fmt.Sprintf("%s/users/%s/events?%s", ts.URL, tc.id, tc.query),
+			token:  tc.token,
+		}
+		res, err := req.make()
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		assert.Equal(t, tc.status, res.StatusCode, fmt.Sprintf("%s: expected status code %d got %d", tc.desc, tc.status, res.StatusCode))
+		if tc.status != http.StatusOK {
+			continue
+		}
+		var page struct {
+			Events []struct {
+				UserID string `json:"user_id"`
+				Type   string `json:"type"`
+			} `json:"events"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&page)
+		assert.Nil(t, err, fmt.Sprintf("%s: unexpected error %s", tc.desc, err))
+		var types []string
+		for _, e := range page.Events {
+			types = append(types, e.Type)
+		}
+		assert.Equal(t, tc.types, types, fmt.Sprintf("%s: expected events %v got %v", tc.desc, tc.types, types))
+	}
+}
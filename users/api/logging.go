@@ -77,7 +77,7 @@ func (lm *loggingMiddleware) ViewProfile(ctx context.Context, token string) (u u
 	return lm.svc.ViewProfile(ctx, token)
 }
 
-func (lm *loggingMiddleware) ListUsers(ctx context.Context, token string, offset, limit uint64, email string, um users.Metadata) (e users.UserPage, err error) {
+func (lm *loggingMiddleware) ListUsers(ctx context.Context, token string, offset, limit uint64, email, status string, um users.Metadata) (e users.UserPage, err error) {
 	defer func(begin time.Time) {
 		message := fmt.Sprintf("Method list_users for token %s took %s to complete", token, time.Since(begin))
 		if err != nil {
@@ -87,7 +87,7 @@ func (lm *loggingMiddleware) ListUsers(ctx context.Context, token string, offset
 		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
 	}(time.Now())
 
-	return lm.svc.ListUsers(ctx, token, offset, limit, email, um)
+	return lm.svc.ListUsers(ctx, token, offset, limit, email, status, um)
 }
 
 func (lm *loggingMiddleware) UpdateUser(ctx context.Context, token string, u users.User) (err error) {
@@ -167,3 +167,42 @@ func (lm *loggingMiddleware) ListMembers(ctx context.Context, token, groupID str
 
 	return lm.svc.ListMembers(ctx, token, groupID, offset, limit, m)
 }
+
+func (lm *loggingMiddleware) DisableUser(ctx context.Context, token, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method disable_user for user %s took %s to complete", id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.DisableUser(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) EnableUser(ctx context.Context, token, id string) (err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method enable_user for user %s took %s to complete", id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.EnableUser(ctx, token, id)
+}
+
+func (lm *loggingMiddleware) ListEvents(ctx context.Context, token, id string, from time.Time, offset, limit uint64) (ep users.EventPage, err error) {
+	defer func(begin time.Time) {
+		message := fmt.Sprintf("Method list_events for user %s took %s to complete", id, time.Since(begin))
+		if err != nil {
+			lm.logger.Warn(fmt.Sprintf("%s with error: %s.", message, err))
+			return
+		}
+		lm.logger.Info(fmt.Sprintf("%s without errors.", message))
+	}(time.Now())
+
+	return lm.svc.ListEvents(ctx, token, id, from, offset, limit)
+}
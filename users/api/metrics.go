@@ -64,13 +64,13 @@ func (ms *metricsMiddleware) ViewProfile(ctx context.Context, token string) (use
 	return ms.svc.ViewProfile(ctx, token)
 }
 
-func (ms *metricsMiddleware) ListUsers(ctx context.Context, token string, offset, limit uint64, email string, um users.Metadata) (users.UserPage, error) {
+func (ms *metricsMiddleware) ListUsers(ctx context.Context, token string, offset, limit uint64, email, status string, um users.Metadata) (users.UserPage, error) {
 	defer func(begin time.Time) {
 		ms.counter.With("method", "list_users").Add(1)
 		ms.latency.With("method", "list_users").Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	return ms.svc.ListUsers(ctx, token, offset, limit, email, um)
+	return ms.svc.ListUsers(ctx, token, offset, limit, email, status, um)
 }
 
 func (ms *metricsMiddleware) UpdateUser(ctx context.Context, token string, u users.User) (err error) {
@@ -126,3 +126,30 @@ func (ms *metricsMiddleware) ListMembers(ctx context.Context, token, groupID str
 
 	return ms.svc.ListMembers(ctx, token, groupID, offset, limit, gm)
 }
+
+func (ms *metricsMiddleware) DisableUser(ctx context.Context, token, id string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "disable_user").Add(1)
+		ms.latency.With("method", "disable_user").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.DisableUser(ctx, token, id)
+}
+
+func (ms *metricsMiddleware) EnableUser(ctx context.Context, token, id string) error {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "enable_user").Add(1)
+		ms.latency.With("method", "enable_user").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.EnableUser(ctx, token, id)
+}
+
+func (ms *metricsMiddleware) ListEvents(ctx context.Context, token, id string, from time.Time, offset, limit uint64) (users.EventPage, error) {
+	defer func(begin time.Time) {
+		ms.counter.With("method", "list_events").Add(1)
+		ms.latency.With("method", "list_events").Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	return ms.svc.ListEvents(ctx, token, id, from, offset, limit)
+}
@@ -4,10 +4,14 @@
 package api
 
 import (
+	"time"
+
 	groups "github.com/mainflux/mainflux/auth"
 	"github.com/mainflux/mainflux/users"
 )
 
+const maxLimitSize = 100
+
 type userReq struct {
 	user users.User
 }
@@ -33,6 +37,7 @@ type listUsersReq struct {
 	offset   uint64
 	limit    uint64
 	email    string
+	status   string
 	metadata users.Metadata
 }
 
@@ -40,6 +45,44 @@ func (req listUsersReq) validate() error {
 	if req.token == "" {
 		return users.ErrUnauthorizedAccess
 	}
+	switch req.status {
+	case "", users.EnabledStatusKey, users.DisabledStatusKey:
+		return nil
+	default:
+		return users.ErrMalformedEntity
+	}
+}
+
+type changeUserStatusReq struct {
+	token  string
+	userID string
+}
+
+func (req changeUserStatusReq) validate() error {
+	if req.token == "" {
+		return users.ErrUnauthorizedAccess
+	}
+	if req.userID == "" {
+		return users.ErrMalformedEntity
+	}
+	return nil
+}
+
+type listEventsReq struct {
+	token  string
+	userID string
+	from   time.Time
+	offset uint64
+	limit  uint64
+}
+
+func (req listEventsReq) validate() error {
+	if req.token == "" {
+		return users.ErrUnauthorizedAccess
+	}
+	if req.userID == "" || req.limit == 0 || req.limit > maxLimitSize {
+		return users.ErrMalformedEntity
+	}
 	return nil
 }
 
@@ -6,6 +6,7 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/mainflux/mainflux"
 )
@@ -21,6 +22,8 @@ var (
 	_ mainflux.Response = (*deleteRes)(nil)
 	_ mainflux.Response = (*assignUserToGroupRes)(nil)
 	_ mainflux.Response = (*removeUserFromGroupRes)(nil)
+	_ mainflux.Response = (*changeUserStatusRes)(nil)
+	_ mainflux.Response = (*eventsPageRes)(nil)
 )
 
 // MailSent message response when link is sent
@@ -93,6 +96,7 @@ type viewUserRes struct {
 	ID       string                 `json:"id"`
 	Email    string                 `json:"email"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Status   string                 `json:"status,omitempty"`
 }
 
 func (res viewUserRes) Code() int {
@@ -124,6 +128,45 @@ func (res userPageRes) Empty() bool {
 	return false
 }
 
+type changeUserStatusRes struct{}
+
+func (res changeUserStatusRes) Code() int {
+	return http.StatusOK
+}
+
+func (res changeUserStatusRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res changeUserStatusRes) Empty() bool {
+	return true
+}
+
+type eventRes struct {
+	ID       string                 `json:"id"`
+	UserID   string                 `json:"user_id"`
+	Type     string                 `json:"type"`
+	Time     time.Time              `json:"time"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type eventsPageRes struct {
+	pageRes
+	Events []eventRes `json:"events"`
+}
+
+func (res eventsPageRes) Code() int {
+	return http.StatusOK
+}
+
+func (res eventsPageRes) Headers() map[string]string {
+	return map[string]string{}
+}
+
+func (res eventsPageRes) Empty() bool {
+	return false
+}
+
 type createGroupRes struct {
 	ID          string                 `json:"id"`
 	Name        string                 `json:"name,omitempty"`
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	kitot "github.com/go-kit/kit/tracing/opentracing"
 	kithttp "github.com/go-kit/kit/transport/http"
@@ -26,6 +27,8 @@ const (
 	offsetKey   = "offset"
 	limitKey    = "limit"
 	emailKey    = "email"
+	statusKey   = "status"
+	fromKey     = "from"
 	metadataKey = "metadata"
 	defOffset   = 0
 	defLimit    = 10
@@ -60,6 +63,27 @@ func MakeHandler(svc users.Service, tracer opentracing.Tracer) http.Handler {
 		opts...,
 	))
 
+	mux.Post("/users/:userID/disable", kithttp.NewServer(
+		kitot.TraceServer(tracer, "disable_user")(disableUserEndpoint(svc)),
+		decodeChangeUserStatus,
+		encodeResponse,
+		opts...,
+	))
+
+	mux.Post("/users/:userID/enable", kithttp.NewServer(
+		kitot.TraceServer(tracer, "enable_user")(enableUserEndpoint(svc)),
+		decodeChangeUserStatus,
+		encodeResponse,
+		opts...,
+	))
+
+	mux.Get("/users/:userID/events", kithttp.NewServer(
+		kitot.TraceServer(tracer, "list_events")(listEventsEndpoint(svc)),
+		decodeListEvents,
+		encodeResponse,
+		opts...,
+	))
+
 	mux.Get("/users", kithttp.NewServer(
 		kitot.TraceServer(tracer, "list_users")(listUsersEndpoint(svc)),
 		decodeListUsers,
@@ -146,6 +170,11 @@ func decodeListUsers(_ context.Context, r *http.Request) (interface{}, error) {
 		return nil, err
 	}
 
+	s, err := httputil.ReadStringQuery(r, statusKey, "")
+	if err != nil {
+		return nil, err
+	}
+
 	m, err := httputil.ReadMetadataQuery(r, metadataKey, nil)
 	if err != nil {
 		return nil, err
@@ -156,11 +185,52 @@ func decodeListUsers(_ context.Context, r *http.Request) (interface{}, error) {
 		offset:   o,
 		limit:    l,
 		email:    e,
+		status:   s,
 		metadata: m,
 	}
 	return req, nil
 }
 
+func decodeChangeUserStatus(_ context.Context, r *http.Request) (interface{}, error) {
+	req := changeUserStatusReq{
+		token:  r.Header.Get("Authorization"),
+		userID: bone.GetValue(r, "userID"),
+	}
+	return req, nil
+}
+
+func decodeListEvents(_ context.Context, r *http.Request) (interface{}, error) {
+	o, err := httputil.ReadUintQuery(r, offsetKey, defOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := httputil.ReadUintQuery(r, limitKey, defLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := httputil.ReadStringQuery(r, fromKey, "")
+	if err != nil {
+		return nil, err
+	}
+	var from time.Time
+	if f != "" {
+		if from, err = time.Parse(time.RFC3339, f); err != nil {
+			return nil, errors.Wrap(errors.ErrInvalidQueryParams, err)
+		}
+	}
+
+	req := listEventsReq{
+		token:  r.Header.Get("Authorization"),
+		userID: bone.GetValue(r, "userID"),
+		from:   from,
+		offset: o,
+		limit:  l,
+	}
+	return req, nil
+}
+
 func decodeUpdateUser(_ context.Context, r *http.Request) (interface{}, error) {
 	var req updateUserReq
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -294,6 +364,8 @@ func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 			w.WriteHeader(http.StatusBadRequest)
 		case errors.Contains(errorVal, users.ErrUserNotFound):
 			w.WriteHeader(http.StatusBadRequest)
+		case errors.Contains(errorVal, users.ErrNotFound):
+			w.WriteHeader(http.StatusNotFound)
 		case errors.Contains(errorVal, users.ErrRecoveryToken):
 			w.WriteHeader(http.StatusNotFound)
 		case errors.Contains(errorVal, users.ErrPasswordFormat):
@@ -0,0 +1,45 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package users
+
+import (
+	"context"
+	"time"
+)
+
+// Security event types recorded for the user account.
+const (
+	LoginEvent           = "login"
+	LoginFailedEvent     = "login_failed"
+	PasswordChangedEvent = "password_changed"
+	PasswordResetEvent   = "password_reset"
+	DisabledEvent        = "disabled"
+	EnabledEvent         = "enabled"
+)
+
+// Event represents the security event of the user account, e.g. the failed
+// login or the account disabled by the admin.
+type Event struct {
+	ID       string
+	UserID   string
+	Type     string
+	Time     time.Time
+	Metadata Metadata
+}
+
+// EventPage contains a page of the user events.
+type EventPage struct {
+	PageMetadata
+	Events []Event
+}
+
+// EventRepository specifies the user events persistence API.
+type EventRepository interface {
+	// Save persists the user event.
+	Save(ctx context.Context, e Event) error
+
+	// RetrieveAll retrieves the events of the user recorded since the given
+	// time, the oldest event first.
+	RetrieveAll(ctx context.Context, userID string, from time.Time, offset, limit uint64) (EventPage, error)
+}
@@ -0,0 +1,58 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mocks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mainflux/mainflux/users"
+)
+
+var _ users.EventRepository = (*eventRepositoryMock)(nil)
+
+type eventRepositoryMock struct {
+	mu     sync.Mutex
+	events map[string][]users.Event
+}
+
+// NewEventRepository creates in-memory user events repository.
+func NewEventRepository() users.EventRepository {
+	return &eventRepositoryMock{
+		events: make(map[string][]users.Event),
+	}
+}
+
+func (erm *eventRepositoryMock) Save(_ context.Context, e users.Event) error {
+	erm.mu.Lock()
+	defer erm.mu.Unlock()
+
+	erm.events[e.UserID] = append(erm.events[e.UserID], e)
+	return nil
+}
+
+func (erm *eventRepositoryMock) RetrieveAll(_ context.Context, userID string, from time.Time, offset, limit uint64) (users.EventPage, error) {
+	erm.mu.Lock()
+	defer erm.mu.Unlock()
+
+	page := users.EventPage{
+		Events: []users.Event{},
+		PageMetadata: users.PageMetadata{
+			Offset: offset,
+			Limit:  limit,
+		},
+	}
+	for _, e := range erm.events[userID] {
+		if e.Time.Before(from) {
+			continue
+		}
+		if page.Total >= offset && page.Total < offset+limit {
+			page.Events = append(page.Events, e)
+		}
+		page.Total++
+	}
+
+	return page, nil
+}
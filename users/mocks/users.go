@@ -5,6 +5,8 @@ package mocks
 
 import (
 	"context"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/mainflux/mainflux/users"
@@ -89,14 +91,24 @@ func (urm *userRepositoryMock) RetrieveByID(ctx context.Context, id string) (use
 	return val, nil
 }
 
-func (urm *userRepositoryMock) RetrieveAll(ctx context.Context, offset, limit uint64, ids []string, email string, um users.Metadata) (users.UserPage, error) {
+func (urm *userRepositoryMock) RetrieveAll(ctx context.Context, offset, limit uint64, ids []string, email, status string, um users.Metadata) (users.UserPage, error) {
 	urm.mu.Lock()
 	defer urm.mu.Unlock()
 
 	up := users.UserPage{}
 	i := uint64(0)
 
-	for _, u := range urm.users {
+	var emails []string
+	for e := range urm.users {
+		emails = append(emails, e)
+	}
+	sort.Strings(emails)
+
+	for _, e := range emails {
+		u := urm.users[e]
+		if !strings.Contains(u.Email, email) || (status != "" && u.Status != status) {
+			continue
+		}
 		if i >= offset && i < (limit+offset) {
 			up.Users = append(up.Users, u)
 		}
@@ -119,3 +131,17 @@ func (urm *userRepositoryMock) UpdatePassword(_ context.Context, token, password
 	}
 	return nil
 }
+
+func (urm *userRepositoryMock) ChangeStatus(_ context.Context, id, status string) error {
+	urm.mu.Lock()
+	defer urm.mu.Unlock()
+
+	u, ok := urm.usersByID[id]
+	if !ok {
+		return users.ErrNotFound
+	}
+	u.Status = status
+	urm.usersByID[id] = u
+	urm.users[u.Email] = u
+	return nil
+}
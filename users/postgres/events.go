@@ -0,0 +1,145 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/users"
+)
+
+var (
+	errSaveEventDB      = errors.New("Save user event to DB failed")
+	errRetrieveEventsDB = errors.New("Retrieving user events from DB failed")
+)
+
+var _ users.EventRepository = (*eventRepository)(nil)
+
+type eventRepository struct {
+	db Database
+}
+
+// NewEventRepository instantiates a PostgreSQL implementation of the user
+// events repository.
+func NewEventRepository(db Database) users.EventRepository {
+	return &eventRepository{
+		db: db,
+	}
+}
+
+func (er eventRepository) Save(ctx context.Context, e users.Event) error {
+	q := `INSERT INTO user_events (id, user_id, type, time, metadata) VALUES (:id, :user_id, :type, :time, :metadata)`
+
+	dbe, err := toDBEvent(e)
+	if err != nil {
+		return errors.Wrap(errSaveEventDB, err)
+	}
+
+	if _, err := er.db.NamedExecContext(ctx, q, dbe); err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code.Name() == errInvalid {
+			return errors.Wrap(users.ErrMalformedEntity, err)
+		}
+		return errors.Wrap(errSaveEventDB, err)
+	}
+
+	return nil
+}
+
+func (er eventRepository) RetrieveAll(ctx context.Context, userID string, from time.Time, offset, limit uint64) (users.EventPage, error) {
+	q := `SELECT id, user_id, type, time, metadata FROM user_events
+	      WHERE user_id = :user_id AND time >= :from ORDER BY time LIMIT :limit OFFSET :offset`
+	params := map[string]interface{}{
+		"user_id": userID,
+		"from":    from,
+		"limit":   limit,
+		"offset":  offset,
+	}
+
+	rows, err := er.db.NamedQueryContext(ctx, q, params)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code.Name() == errInvalid {
+			return users.EventPage{}, errors.Wrap(users.ErrMalformedEntity, err)
+		}
+		return users.EventPage{}, errors.Wrap(errRetrieveEventsDB, err)
+	}
+	defer rows.Close()
+
+	items := []users.Event{}
+	for rows.Next() {
+		dbe := dbEvent{}
+		if err := rows.StructScan(&dbe); err != nil {
+			return users.EventPage{}, errors.Wrap(errRetrieveEventsDB, err)
+		}
+		e, err := toEvent(dbe)
+		if err != nil {
+			return users.EventPage{}, errors.Wrap(errRetrieveEventsDB, err)
+		}
+		items = append(items, e)
+	}
+
+	cq := `SELECT COUNT(*) FROM user_events WHERE user_id = :user_id AND time >= :from`
+	total, err := total(ctx, er.db, cq, params)
+	if err != nil {
+		return users.EventPage{}, errors.Wrap(errRetrieveEventsDB, err)
+	}
+
+	return users.EventPage{
+		Events: items,
+		PageMetadata: users.PageMetadata{
+			Total:  total,
+			Offset: offset,
+			Limit:  limit,
+		},
+	}, nil
+}
+
+type dbEvent struct {
+	ID       string    `db:"id"`
+	UserID   string    `db:"user_id"`
+	Type     string    `db:"type"`
+	Time     time.Time `db:"time"`
+	Metadata []byte    `db:"metadata"`
+}
+
+func toDBEvent(e users.Event) (dbEvent, error) {
+	var data []byte
+	if len(e.Metadata) > 0 {
+		b, err := json.Marshal(e.Metadata)
+		if err != nil {
+			return dbEvent{}, errors.Wrap(errMarshal, err)
+		}
+		data = b
+	}
+
+	return dbEvent{
+		ID:       e.ID,
+		UserID:   e.UserID,
+		Type:     e.Type,
+		Time:     e.Time,
+		Metadata: data,
+	}, nil
+}
+
+func toEvent(dbe dbEvent) (users.Event, error) {
+	var metadata map[string]interface{}
+	if dbe.Metadata != nil {
+		if err := json.Unmarshal(dbe.Metadata, &metadata); err != nil {
+			return users.Event{}, errors.Wrap(errUnmarshal, err)
+		}
+	}
+
+	return users.Event{
+		ID:       dbe.ID,
+		UserID:   dbe.UserID,
+		Type:     dbe.Type,
+		Time:     dbe.Time,
+		Metadata: metadata,
+	}, nil
+}
@@ -0,0 +1,133 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mainflux/mainflux/pkg/errors"
+	"github.com/mainflux/mainflux/users"
+	"github.com/mainflux/mainflux/users/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventSave(t *testing.T) {
+	repo := postgres.NewEventRepository(postgres.NewDatabase(db))
+
+	id, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	userID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		event users.Event
+		err   error
+	}{
+		{
+			desc: "save event",
+			event: users.Event{
+				ID:       id,
+				UserID:   userID,
+				Type:     users.DisabledEvent,
+				Time:     time.Now().UTC(),
+				Metadata: users.Metadata{"admin": "admin@example.com"},
+			},
+			err: nil,
+		},
+		{
+			desc: "save event with invalid user id",
+			event: users.Event{
+				ID:     userID,
+				UserID: "invalid",
+				Type:   users.LoginEvent,
+				Time:   time.Now().UTC(),
+			},
+			err: users.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		err := repo.Save(context.Background(), tc.event)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+	}
+}
+
+func TestEventRetrieveAll(t *testing.T) {
+	repo := postgres.NewEventRepository(postgres.NewDatabase(db))
+
+	userID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	n := uint64(10)
+	start := time.Now().UTC().Truncate(time.Second)
+	for i := uint64(0); i < n; i++ {
+		id, err := idProvider.ID()
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+		e := users.Event{
+			ID:     id,
+			UserID: userID,
+			Type:   users.LoginEvent,
+			Time:   start.Add(time.Duration(i) * time.Minute),
+		}
+		err = repo.Save(context.Background(), e)
+		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	}
+
+	cases := []struct {
+		desc   string
+		userID string
+		from   time.Time
+		offset uint64
+		limit  uint64
+		size   uint64
+		total  uint64
+		err    error
+	}{
+		{
+			desc:   "retrieve all events",
+			userID: userID,
+			offset: 0,
+			limit:  n,
+			size:   n,
+			total:  n,
+		},
+		{
+			desc:   "retrieve events with offset and limit",
+			userID: userID,
+			offset: 8,
+			limit:  5,
+			size:   2,
+			total:  n,
+		},
+		{
+			desc:   "retrieve events since the time",
+			userID: userID,
+			from:   start.Add(6 * time.Minute),
+			offset: 0,
+			limit:  n,
+			size:   4,
+			total:  4,
+		},
+		{
+			desc:   "retrieve events of invalid user",
+			userID: "invalid",
+			offset: 0,
+			limit:  n,
+			err:    users.ErrMalformedEntity,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := repo.RetrieveAll(context.Background(), tc.userID, tc.from, tc.offset, tc.limit)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		size := uint64(len(page.Events))
+		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected size %d got %d\n", tc.desc, tc.size, size))
+		assert.Equal(t, tc.total, page.Total, fmt.Sprintf("%s: expected total %d got %d\n", tc.desc, tc.total, page.Total))
+	}
+}
@@ -78,6 +78,25 @@ func migrateDB(db *sqlx.DB) error {
 					`ALTER TABLE IF EXISTS users ADD PRIMARY KEY (id)`,
 				},
 			},
+			{
+				Id: "users_5",
+				Up: []string{
+					`ALTER TABLE IF EXISTS users ADD COLUMN IF NOT EXISTS
+					 status VARCHAR(254) NOT NULL DEFAULT 'enabled'`,
+					`CREATE TABLE IF NOT EXISTS user_events (
+					 id       UUID         PRIMARY KEY,
+					 user_id  UUID         NOT NULL,
+					 type     VARCHAR(254) NOT NULL,
+					 time     TIMESTAMPTZ  NOT NULL,
+					 metadata JSONB
+					)`,
+					`CREATE INDEX IF NOT EXISTS user_events_user_id_time ON user_events (user_id, time)`,
+				},
+				Down: []string{
+					"DROP TABLE user_events",
+					"ALTER TABLE users DROP COLUMN status",
+				},
+			},
 		},
 	}
 
@@ -29,6 +29,7 @@ var (
 	errUpdateUserDB     = errors.New("Update user metadata to DB failed")
 	errRetrieveDB       = errors.New("Retreiving from DB failed")
 	errUpdatePasswordDB = errors.New("Update password to DB failed")
+	errUpdateStatusDB   = errors.New("Update user status to DB failed")
 	errMarshal          = errors.New("Failed to marshal metadata")
 	errUnmarshal        = errors.New("Failed to unmarshal metadata")
 )
@@ -50,10 +51,13 @@ func NewUserRepo(db Database) users.UserRepository {
 }
 
 func (ur userRepository) Save(ctx context.Context, user users.User) (string, error) {
-	q := `INSERT INTO users (email, password, id, metadata) VALUES (:email, :password, :id, :metadata) RETURNING id`
+	q := `INSERT INTO users (email, password, id, metadata, status) VALUES (:email, :password, :id, :metadata, :status) RETURNING id`
 	if user.ID == "" || user.Email == "" {
 		return "", users.ErrMalformedEntity
 	}
+	if user.Status == "" {
+		user.Status = users.EnabledStatusKey
+	}
 
 	dbu, err := toDBUser(user)
 	if err != nil {
@@ -114,7 +118,7 @@ func (ur userRepository) UpdateUser(ctx context.Context, user users.User) error
 }
 
 func (ur userRepository) RetrieveByEmail(ctx context.Context, email string) (users.User, error) {
-	q := `SELECT id, password, metadata FROM users WHERE email = $1`
+	q := `SELECT id, password, metadata, status FROM users WHERE email = $1`
 
 	dbu := dbUser{
 		Email: email,
@@ -132,7 +136,7 @@ func (ur userRepository) RetrieveByEmail(ctx context.Context, email string) (use
 }
 
 func (ur userRepository) RetrieveByID(ctx context.Context, id string) (users.User, error) {
-	q := `SELECT email, password, metadata FROM users WHERE id = $1`
+	q := `SELECT email, password, metadata, status FROM users WHERE id = $1`
 
 	dbu := dbUser{
 		ID: id,
@@ -149,7 +153,7 @@ func (ur userRepository) RetrieveByID(ctx context.Context, id string) (users.Use
 	return toUser(dbu)
 }
 
-func (ur userRepository) RetrieveAll(ctx context.Context, offset, limit uint64, userIDs []string, email string, um users.Metadata) (users.UserPage, error) {
+func (ur userRepository) RetrieveAll(ctx context.Context, offset, limit uint64, userIDs []string, email, status string, um users.Metadata) (users.UserPage, error) {
 	eq, ep, err := createEmailQuery("", email)
 	if err != nil {
 		return users.UserPage{}, errors.Wrap(errRetrieveDB, err)
@@ -168,8 +172,12 @@ func (ur userRepository) RetrieveAll(ctx context.Context, offset, limit uint64,
 	if mq != "" {
 		query = append(query, mq)
 	}
+	if status != "" {
+		query = append(query, "status = :status")
+	}
 
-	if len(userIDs) == 0 {
+	// The nil IDs list all the users, while the empty IDs list none.
+	if userIDs != nil && len(userIDs) == 0 {
 		return users.UserPage{
 			Users: []users.User{},
 			PageMetadata: users.PageMetadata{
@@ -180,15 +188,20 @@ func (ur userRepository) RetrieveAll(ctx context.Context, offset, limit uint64,
 		}, nil
 	}
 
-	query = append(query, fmt.Sprintf("id IN ('%s')", strings.Join(userIDs, "','")))
-	emq = fmt.Sprintf(" WHERE %s", strings.Join(query, " AND "))
+	if userIDs != nil {
+		query = append(query, fmt.Sprintf("id IN ('%s')", strings.Join(userIDs, "','")))
+	}
+	if len(query) > 0 {
+		emq = fmt.Sprintf(" WHERE %s", strings.Join(query, " AND "))
+	}
 
-	q := fmt.Sprintf(`SELECT id, email, metadata FROM users %s ORDER BY email LIMIT :limit OFFSET :offset;`, emq)
+	q := fmt.Sprintf(`SELECT id, email, metadata, status FROM users %s ORDER BY email LIMIT :limit OFFSET :offset;`, emq)
 	params := map[string]interface{}{
 		"limit":    limit,
 		"offset":   offset,
 		"email":    ep,
 		"metadata": mp,
+		"status":   status,
 	}
 
 	rows, err := ur.db.NamedQueryContext(ctx, q, params)
@@ -246,6 +259,34 @@ func (ur userRepository) UpdatePassword(ctx context.Context, email, password str
 	return nil
 }
 
+func (ur userRepository) ChangeStatus(ctx context.Context, id, status string) error {
+	q := `UPDATE users SET status = :status WHERE id = :id`
+
+	dbu := dbUser{
+		ID:     id,
+		Status: status,
+	}
+
+	res, err := ur.db.NamedExecContext(ctx, q, dbu)
+	if err != nil {
+		pqErr, ok := err.(*pq.Error)
+		if ok && pqErr.Code.Name() == errInvalid {
+			return errors.Wrap(users.ErrNotFound, err)
+		}
+		return errors.Wrap(errUpdateStatusDB, err)
+	}
+
+	cnt, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(errUpdateStatusDB, err)
+	}
+	if cnt != 1 {
+		return users.ErrNotFound
+	}
+
+	return nil
+}
+
 // dbMetadata type for handling metadata properly in database/sql
 type dbMetadata map[string]interface{}
 
@@ -285,6 +326,7 @@ type dbUser struct {
 	Email    string       `db:"email"`
 	Password string       `db:"password"`
 	Metadata []byte       `db:"metadata"`
+	Status   string       `db:"status"`
 	Groups   []auth.Group `db:"groups"`
 }
 
@@ -303,6 +345,7 @@ func toDBUser(u users.User) (dbUser, error) {
 		Email:    u.Email,
 		Password: u.Password,
 		Metadata: data,
+		Status:   u.Status,
 	}, nil
 }
 
@@ -334,6 +377,7 @@ func toUser(dbu dbUser) (users.User, error) {
 		Email:    dbu.Email,
 		Password: dbu.Password,
 		Metadata: metadata,
+		Status:   dbu.Status,
 	}, nil
 }
 
@@ -94,6 +94,7 @@ func TestRetrieveAll(t *testing.T) {
 	dbMiddleware := postgres.NewDatabase(db)
 	userRepo := postgres.NewUserRepo(dbMiddleware)
 	metaNum := uint64(2)
+	disabledNum := uint64(3)
 	var nUsers = uint64(10)
 
 	meta := users.Metadata{
@@ -117,6 +118,9 @@ func TestRetrieveAll(t *testing.T) {
 		if i < metaNum {
 			user.Metadata = meta
 		}
+		if i >= nUsers-disabledNum {
+			user.Status = users.DisabledStatusKey
+		}
 		ids = append(ids, uid)
 		_, err = userRepo.Save(context.Background(), user)
 		require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
@@ -124,6 +128,7 @@ func TestRetrieveAll(t *testing.T) {
 
 	cases := map[string]struct {
 		email    string
+		status   string
 		offset   uint64
 		limit    uint64
 		size     uint64
@@ -131,6 +136,30 @@ func TestRetrieveAll(t *testing.T) {
 		ids      []string
 		metadata users.Metadata
 	}{
+		"retrieve all users without ids": {
+			email:  "TestRetrieveAll",
+			offset: 0,
+			limit:  nUsers,
+			size:   nUsers,
+			total:  nUsers,
+		},
+		"retrieve disabled users": {
+			email:  "All",
+			status: users.DisabledStatusKey,
+			offset: 0,
+			limit:  nUsers,
+			size:   disabledNum,
+			total:  disabledNum,
+			ids:    ids,
+		},
+		"retrieve enabled users without ids": {
+			email:  "TestRetrieveAll",
+			status: users.EnabledStatusKey,
+			offset: 0,
+			limit:  nUsers,
+			size:   nUsers - disabledNum,
+			total:  nUsers - disabledNum,
+		},
 		"retrieve all users filtered by email": {
 			email:  "All",
 			offset: 0,
@@ -211,9 +240,70 @@ func TestRetrieveAll(t *testing.T) {
 		},
 	}
 	for desc, tc := range cases {
-		page, err := userRepo.RetrieveAll(context.Background(), tc.offset, tc.limit, tc.ids, tc.email, tc.metadata)
+		page, err := userRepo.RetrieveAll(context.Background(), tc.offset, tc.limit, tc.ids, tc.email, tc.status, tc.metadata)
 		size := uint64(len(page.Users))
 		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected size %d got %d\n", desc, tc.size, size))
 		assert.Nil(t, err, fmt.Sprintf("%s: expected no error got %d\n", desc, err))
 	}
 }
+
+func TestChangeStatus(t *testing.T) {
+	dbMiddleware := postgres.NewDatabase(db)
+	repo := postgres.NewUserRepo(dbMiddleware)
+
+	uid, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	user := users.User{
+		ID:       uid,
+		Email:    "user-change-status@example.com",
+		Password: "pass",
+	}
+	_, err = repo.Save(context.Background(), user)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	unknownID, err := idProvider.ID()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc   string
+		id     string
+		status string
+		err    error
+	}{
+		{
+			desc:   "disable user",
+			id:     uid,
+			status: users.DisabledStatusKey,
+			err:    nil,
+		},
+		{
+			desc:   "enable user",
+			id:     uid,
+			status: users.EnabledStatusKey,
+			err:    nil,
+		},
+		{
+			desc:   "disable non-existing user",
+			id:     unknownID,
+			status: users.DisabledStatusKey,
+			err:    users.ErrNotFound,
+		},
+		{
+			desc:   "disable user with invalid id",
+			id:     "invalid",
+			status: users.DisabledStatusKey,
+			err:    users.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		err := repo.ChangeStatus(context.Background(), tc.id, tc.status)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		if tc.err != nil {
+			continue
+		}
+		u, err := repo.RetrieveByID(context.Background(), tc.id)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		assert.Equal(t, tc.status, u.Status, fmt.Sprintf("%s: expected status %s got %s\n", tc.desc, tc.status, u.Status))
+	}
+}
@@ -6,6 +6,7 @@ package users
 import (
 	"context"
 	"regexp"
+	"time"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/auth"
@@ -55,6 +56,12 @@ var (
 
 	// ErrPasswordFormat indicates weak password.
 	ErrPasswordFormat = errors.New("password does not meet the requirements")
+
+	// ErrDisabledUser indicates the login of the user disabled by the admin.
+	ErrDisabledUser = errors.New("user is disabled")
+
+	// ErrSaveEvent indicates error in recording the user event.
+	ErrSaveEvent = errors.New("failed to save user event")
 )
 
 // Service specifies an API that must be fullfiled by the domain service
@@ -75,8 +82,9 @@ type Service interface {
 	// ViewProfile retrieves user info for a given token.
 	ViewProfile(ctx context.Context, token string) (User, error)
 
-	// ListUsers retrieves users list for a valid admin token.
-	ListUsers(ctx context.Context, token string, offset, limit uint64, email string, meta Metadata) (UserPage, error)
+	// ListUsers retrieves users list for a valid admin token. The users are
+	// filtered by the status unless it's empty.
+	ListUsers(ctx context.Context, token string, offset, limit uint64, email, status string, meta Metadata) (UserPage, error)
 
 	// UpdateUser updates the user metadata.
 	UpdateUser(ctx context.Context, token string, user User) error
@@ -97,6 +105,17 @@ type Service interface {
 
 	// ListMembers retrieves everything that is assigned to a group identified by groupID.
 	ListMembers(ctx context.Context, token, groupID string, offset, limit uint64, meta Metadata) (UserPage, error)
+
+	// DisableUser disables the user with the given ID, so the user can't log
+	// in until enabled again. Only the admin can disable users.
+	DisableUser(ctx context.Context, token, id string) error
+
+	// EnableUser enables the user disabled by the admin.
+	EnableUser(ctx context.Context, token, id string) error
+
+	// ListEvents retrieves the security events of the user recorded since the
+	// given time. Only the admin can list the events.
+	ListEvents(ctx context.Context, token, id string, from time.Time, offset, limit uint64) (EventPage, error)
 }
 
 // PageMetadata contains page metadata that helps navigation.
@@ -123,22 +142,28 @@ var _ Service = (*usersService)(nil)
 
 type usersService struct {
 	users      UserRepository
+	events     EventRepository
 	hasher     Hasher
 	email      Emailer
 	auth       mainflux.AuthServiceClient
 	idProvider mainflux.IDProvider
 	passRegex  *regexp.Regexp
+	adminEmail string
 }
 
-// New instantiates the users service implementation
-func New(users UserRepository, hasher Hasher, auth mainflux.AuthServiceClient, e Emailer, idp mainflux.IDProvider, passRegex *regexp.Regexp) Service {
+// New instantiates the users service implementation. The user with the
+// admin email is allowed to disable users and list their events, no one is
+// if it's empty.
+func New(users UserRepository, events EventRepository, hasher Hasher, auth mainflux.AuthServiceClient, e Emailer, idp mainflux.IDProvider, passRegex *regexp.Regexp, adminEmail string) Service {
 	return &usersService{
 		users:      users,
+		events:     events,
 		hasher:     hasher,
 		auth:       auth,
 		email:      e,
 		idProvider: idp,
 		passRegex:  passRegex,
+		adminEmail: adminEmail,
 	}
 }
 
@@ -159,6 +184,7 @@ func (svc usersService) Register(ctx context.Context, user User) (string, error)
 		return "", errors.Wrap(ErrCreateUser, err)
 	}
 	user.ID = uid
+	user.Status = EnabledStatusKey
 	uid, err = svc.users.Save(ctx, user)
 	if err != nil {
 		return "", err
@@ -167,12 +193,12 @@ func (svc usersService) Register(ctx context.Context, user User) (string, error)
 }
 
 func (svc usersService) Login(ctx context.Context, user User) (string, error) {
-	dbUser, err := svc.users.RetrieveByEmail(ctx, user.Email)
+	dbUser, err := svc.authenticate(ctx, user.Email, user.Password)
 	if err != nil {
-		return "", errors.Wrap(ErrUnauthorizedAccess, err)
+		return "", err
 	}
-	if err := svc.hasher.Compare(user.Password, dbUser.Password); err != nil {
-		return "", errors.Wrap(ErrUnauthorizedAccess, err)
+	if err := svc.record(ctx, dbUser.ID, LoginEvent, nil); err != nil {
+		return "", err
 	}
 	return svc.issue(ctx, dbUser.ID, dbUser.Email, auth.UserKey)
 }
@@ -215,13 +241,12 @@ func (svc usersService) ViewProfile(ctx context.Context, token string) (User, er
 	}, nil
 }
 
-func (svc usersService) ListUsers(ctx context.Context, token string, offset, limit uint64, email string, m Metadata) (UserPage, error) {
-	_, err := svc.identify(ctx, token)
-	if err != nil {
+func (svc usersService) ListUsers(ctx context.Context, token string, offset, limit uint64, email, status string, m Metadata) (UserPage, error) {
+	if _, err := svc.authorizeAdmin(ctx, token); err != nil {
 		return UserPage{}, err
 	}
 
-	return svc.users.RetrieveAll(ctx, offset, limit, nil, email, m)
+	return svc.users.RetrieveAll(ctx, offset, limit, nil, email, status, m)
 }
 
 func (svc usersService) UpdateUser(ctx context.Context, token string, u User) error {
@@ -264,7 +289,10 @@ func (svc usersService) ResetPassword(ctx context.Context, resetToken, password
 	if err != nil {
 		return err
 	}
-	return svc.users.UpdatePassword(ctx, email, password)
+	if err := svc.users.UpdatePassword(ctx, email, password); err != nil {
+		return err
+	}
+	return svc.record(ctx, u.ID, PasswordResetEvent, nil)
 }
 
 func (svc usersService) ChangePassword(ctx context.Context, authToken, password, oldPassword string) error {
@@ -275,23 +303,19 @@ func (svc usersService) ChangePassword(ctx context.Context, authToken, password,
 	if !svc.passRegex.MatchString(password) {
 		return ErrPasswordFormat
 	}
-	u := User{
-		Email:    email,
-		Password: oldPassword,
-	}
-	if _, err := svc.Login(ctx, u); err != nil {
+	u, err := svc.authenticate(ctx, email, oldPassword)
+	if err != nil {
 		return ErrUnauthorizedAccess
 	}
-	u, err = svc.users.RetrieveByEmail(ctx, email)
-	if err != nil || u.Email == "" {
-		return ErrUserNotFound
-	}
 
 	password, err = svc.hasher.Hash(password)
 	if err != nil {
 		return err
 	}
-	return svc.users.UpdatePassword(ctx, email, password)
+	if err := svc.users.UpdatePassword(ctx, email, password); err != nil {
+		return err
+	}
+	return svc.record(ctx, u.ID, PasswordChangedEvent, nil)
 }
 
 func (svc usersService) SendPasswordReset(_ context.Context, host, email, token string) error {
@@ -308,8 +332,89 @@ func (svc usersService) ListMembers(ctx context.Context, token, groupID string,
 	if err != nil {
 		return UserPage{}, err
 	}
+	// The repository lists all the users for the nil IDs.
+	if len(userIDs) == 0 {
+		return UserPage{
+			Users: []User{},
+			PageMetadata: PageMetadata{
+				Offset: offset,
+				Limit:  limit,
+			},
+		}, nil
+	}
+
+	return svc.users.RetrieveAll(ctx, offset, limit, userIDs, "", "", m)
+}
+
+func (svc usersService) DisableUser(ctx context.Context, token, id string) error {
+	return svc.changeStatus(ctx, token, id, DisabledStatusKey, DisabledEvent)
+}
+
+func (svc usersService) EnableUser(ctx context.Context, token, id string) error {
+	return svc.changeStatus(ctx, token, id, EnabledStatusKey, EnabledEvent)
+}
+
+func (svc usersService) ListEvents(ctx context.Context, token, id string, from time.Time, offset, limit uint64) (EventPage, error) {
+	if _, err := svc.authorizeAdmin(ctx, token); err != nil {
+		return EventPage{}, err
+	}
+	if _, err := svc.users.RetrieveByID(ctx, id); err != nil {
+		return EventPage{}, err
+	}
+
+	return svc.events.RetrieveAll(ctx, id, from, offset, limit)
+}
+
+func (svc usersService) changeStatus(ctx context.Context, token, id, status, event string) error {
+	admin, err := svc.authorizeAdmin(ctx, token)
+	if err != nil {
+		return err
+	}
+	if err := svc.users.ChangeStatus(ctx, id, status); err != nil {
+		return err
+	}
+
+	return svc.record(ctx, id, event, Metadata{"admin": admin})
+}
+
+// authenticate checks the user credentials and status, and records the
+// failed login of the existing user.
+func (svc usersService) authenticate(ctx context.Context, email, password string) (User, error) {
+	dbUser, err := svc.users.RetrieveByEmail(ctx, email)
+	if err != nil {
+		return User{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
+	err = svc.hasher.Compare(password, dbUser.Password)
+	if err == nil && dbUser.Status == DisabledStatusKey {
+		err = ErrDisabledUser
+	}
+	if err != nil {
+		if err := svc.record(ctx, dbUser.ID, LoginFailedEvent, nil); err != nil {
+			return User{}, err
+		}
+		return User{}, errors.Wrap(ErrUnauthorizedAccess, err)
+	}
 
-	return svc.users.RetrieveAll(ctx, offset, limit, userIDs, "", m)
+	return dbUser, nil
+}
+
+func (svc usersService) record(ctx context.Context, userID, typ string, m Metadata) error {
+	id, err := svc.idProvider.ID()
+	if err != nil {
+		return errors.Wrap(ErrSaveEvent, err)
+	}
+	e := Event{
+		ID:       id,
+		UserID:   userID,
+		Type:     typ,
+		Time:     time.Now().UTC(),
+		Metadata: m,
+	}
+	if err := svc.events.Save(ctx, e); err != nil {
+		return errors.Wrap(ErrSaveEvent, err)
+	}
+
+	return nil
 }
 
 // Auth helpers
@@ -329,6 +434,19 @@ func (svc usersService) identify(ctx context.Context, token string) (string, err
 	return identity.GetEmail(), nil
 }
 
+// authorizeAdmin returns the email of the admin identified by the token.
+func (svc usersService) authorizeAdmin(ctx context.Context, token string) (string, error) {
+	email, err := svc.identify(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if svc.adminEmail == "" || email != svc.adminEmail {
+		return "", ErrUnauthorizedAccess
+	}
+
+	return email, nil
+}
+
 func (svc usersService) members(ctx context.Context, token, groupID string, limit, offset uint64) ([]string, error) {
 	req := mainflux.MembersReq{
 		Token:   token,
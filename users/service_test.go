@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/mainflux/mainflux"
 	"github.com/mainflux/mainflux/pkg/errors"
@@ -24,6 +25,7 @@ const wrong string = "wrong-value"
 var (
 	user            = users.User{Email: "user@example.com", Password: "password", Metadata: map[string]interface{}{"role": "user"}}
 	nonExistingUser = users.User{Email: "non-ex-user@example.com", Password: "password", Metadata: map[string]interface{}{"role": "user"}}
+	admin           = users.User{Email: "admin@example.com", Password: "password"}
 	host            = "example.com"
 
 	idProvider = uuid.New()
@@ -33,10 +35,10 @@ var (
 func newService() users.Service {
 	userRepo := mocks.NewUserRepository()
 	hasher := mocks.NewHasher()
-	auth := mocks.NewAuthService(map[string]string{user.Email: user.Email})
+	auth := mocks.NewAuthService(map[string]string{user.Email: user.Email, admin.Email: admin.Email})
 	e := mocks.NewEmailer()
 
-	return users.New(userRepo, hasher, auth, e, idProvider, passRegex)
+	return users.New(userRepo, mocks.NewEventRepository(), hasher, auth, e, idProvider, passRegex, admin.Email)
 }
 
 func TestRegister(t *testing.T) {
@@ -199,12 +201,18 @@ func TestListUsers(t *testing.T) {
 	_, err := svc.Register(context.Background(), user)
 	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
 
-	token, err := svc.Login(context.Background(), user)
+	_, err = svc.Register(context.Background(), admin)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	token, err := svc.Login(context.Background(), admin)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	userToken, err := svc.Login(context.Background(), user)
 	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
 
 	var nUsers = uint64(10)
 
-	for i := uint64(1); i < nUsers; i++ {
+	for i := uint64(2); i < nUsers; i++ {
 		email := fmt.Sprintf("TestListUsers%d@example.com", i)
 		user := users.User{
 			Email:    email,
@@ -232,6 +240,11 @@ func TestListUsers(t *testing.T) {
 			size:  0,
 			err:   users.ErrUnauthorizedAccess,
 		},
+		"list users with non-admin token": {
+			token: userToken,
+			size:  0,
+			err:   users.ErrUnauthorizedAccess,
+		},
 		"list users with offset and limit": {
 			token:  token,
 			offset: 6,
@@ -241,7 +254,7 @@ func TestListUsers(t *testing.T) {
 	}
 
 	for desc, tc := range cases {
-		page, err := svc.ListUsers(context.Background(), tc.token, tc.offset, tc.limit, tc.email, nil)
+		page, err := svc.ListUsers(context.Background(), tc.token, tc.offset, tc.limit, tc.email, "", nil)
 		size := uint64(len(page.Users))
 		assert.Equal(t, tc.size, size, fmt.Sprintf("%s: expected size %d got %d\n", desc, tc.size, size))
 		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", desc, tc.err, err))
@@ -367,3 +380,137 @@ func TestSendPasswordReset(t *testing.T) {
 
 	}
 }
+
+func TestChangeStatus(t *testing.T) {
+	svc := newService()
+	id, err := svc.Register(context.Background(), user)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	_, err = svc.Register(context.Background(), admin)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc     string
+		change   func(ctx context.Context, token, id string) error
+		token    string
+		id       string
+		status   string
+		loginErr error
+		err      error
+	}{
+		{
+			desc:     "disable user",
+			change:   svc.DisableUser,
+			token:    admin.Email,
+			id:       id,
+			status:   users.DisabledStatusKey,
+			loginErr: users.ErrUnauthorizedAccess,
+			err:      nil,
+		},
+		{
+			desc:     "enable user with non-admin token",
+			change:   svc.EnableUser,
+			token:    user.Email,
+			id:       id,
+			status:   users.DisabledStatusKey,
+			loginErr: users.ErrUnauthorizedAccess,
+			err:      users.ErrUnauthorizedAccess,
+		},
+		{
+			desc:     "enable non-existing user",
+			change:   svc.EnableUser,
+			token:    admin.Email,
+			id:       wrong,
+			status:   users.DisabledStatusKey,
+			loginErr: users.ErrUnauthorizedAccess,
+			err:      users.ErrNotFound,
+		},
+		{
+			desc:     "enable user",
+			change:   svc.EnableUser,
+			token:    admin.Email,
+			id:       id,
+			status:   users.EnabledStatusKey,
+			loginErr: nil,
+			err:      nil,
+		},
+	}
+
+	for _, tc := range cases {
+		err := tc.change(context.Background(), tc.token, tc.id)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		page, err := svc.ListUsers(context.Background(), admin.Email, 0, 10, "", tc.status, nil)
+		require.Nil(t, err, fmt.Sprintf("%s: unexpected error: %s", tc.desc, err))
+		var emails []string
+		for _, u := range page.Users {
+			emails = append(emails, u.Email)
+		}
+		assert.Contains(t, emails, user.Email, fmt.Sprintf("%s: expected user listed with status %s", tc.desc, tc.status))
+		_, err = svc.Login(context.Background(), user)
+		assert.True(t, errors.Contains(err, tc.loginErr), fmt.Sprintf("%s: expected login error %s got %s\n", tc.desc, tc.loginErr, err))
+	}
+}
+
+func TestListEvents(t *testing.T) {
+	svc := newService()
+	id, err := svc.Register(context.Background(), user)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	_, err = svc.Register(context.Background(), admin)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	_, err = svc.Login(context.Background(), users.User{Email: user.Email, Password: wrong})
+	require.NotNil(t, err, "expected login with wrong password to fail")
+	err = svc.DisableUser(context.Background(), admin.Email, id)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	from := time.Now().UTC()
+	err = svc.EnableUser(context.Background(), admin.Email, id)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	err = svc.ChangePassword(context.Background(), user.Email, "newpassword", user.Password)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	cases := []struct {
+		desc  string
+		token string
+		id    string
+		from  time.Time
+		types []string
+		err   error
+	}{
+		{
+			desc:  "list all user events",
+			token: admin.Email,
+			id:    id,
+			types: []string{users.LoginFailedEvent, users.DisabledEvent, users.EnabledEvent, users.PasswordChangedEvent},
+			err:   nil,
+		},
+		{
+			desc:  "list user events since the time",
+			token: admin.Email,
+			id:    id,
+			from:  from,
+			types: []string{users.EnabledEvent, users.PasswordChangedEvent},
+			err:   nil,
+		},
+		{
+			desc:  "list user events with non-admin token",
+			token: user.Email,
+			id:    id,
+			err:   users.ErrUnauthorizedAccess,
+		},
+		{
+			desc:  "list events of non-existing user",
+			token: admin.Email,
+			id:    wrong,
+			err:   users.ErrNotFound,
+		},
+	}
+
+	for _, tc := range cases {
+		page, err := svc.ListEvents(context.Background(), tc.token, tc.id, tc.from, 0, 10)
+		assert.True(t, errors.Contains(err, tc.err), fmt.Sprintf("%s: expected %s got %s\n", tc.desc, tc.err, err))
+		var types []string
+		for _, e := range page.Events {
+			types = append(types, e.Type)
+		}
+		assert.Equal(t, tc.types, types, fmt.Sprintf("%s: expected events %v got %v\n", tc.desc, tc.types, types))
+	}
+}
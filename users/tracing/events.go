@@ -0,0 +1,49 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/mainflux/mainflux/users"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+const (
+	saveEventOp      = "save_event"
+	retrieveEventsOp = "retrieve_events"
+)
+
+var _ users.EventRepository = (*eventRepositoryMiddleware)(nil)
+
+type eventRepositoryMiddleware struct {
+	tracer opentracing.Tracer
+	repo   users.EventRepository
+}
+
+// EventRepositoryMiddleware tracks request and their latency, and adds spans
+// to context.
+func EventRepositoryMiddleware(repo users.EventRepository, tracer opentracing.Tracer) users.EventRepository {
+	return eventRepositoryMiddleware{
+		tracer: tracer,
+		repo:   repo,
+	}
+}
+
+func (erm eventRepositoryMiddleware) Save(ctx context.Context, e users.Event) error {
+	span := createSpan(ctx, erm.tracer, saveEventOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return erm.repo.Save(ctx, e)
+}
+
+func (erm eventRepositoryMiddleware) RetrieveAll(ctx context.Context, userID string, from time.Time, offset, limit uint64) (users.EventPage, error) {
+	span := createSpan(ctx, erm.tracer, retrieveEventsOp)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return erm.repo.RetrieveAll(ctx, userID, from, offset, limit)
+}
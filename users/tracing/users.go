@@ -17,6 +17,7 @@ const (
 	retrieveByEmailOp = "retrieve_by_email"
 	updatePassword    = "update_password"
 	members           = "members"
+	changeStatus      = "change_status"
 )
 
 var _ users.UserRepository = (*userRepositoryMiddleware)(nil)
@@ -75,12 +76,20 @@ func (urm userRepositoryMiddleware) UpdatePassword(ctx context.Context, email, p
 	return urm.repo.UpdatePassword(ctx, email, password)
 }
 
-func (urm userRepositoryMiddleware) RetrieveAll(ctx context.Context, offset, limit uint64, ids []string, email string, um users.Metadata) (users.UserPage, error) {
+func (urm userRepositoryMiddleware) RetrieveAll(ctx context.Context, offset, limit uint64, ids []string, email, status string, um users.Metadata) (users.UserPage, error) {
 	span := createSpan(ctx, urm.tracer, members)
 	defer span.Finish()
 	ctx = opentracing.ContextWithSpan(ctx, span)
 
-	return urm.repo.RetrieveAll(ctx, offset, limit, ids, email, um)
+	return urm.repo.RetrieveAll(ctx, offset, limit, ids, email, status, um)
+}
+
+func (urm userRepositoryMiddleware) ChangeStatus(ctx context.Context, id, status string) error {
+	span := createSpan(ctx, urm.tracer, changeStatus)
+	defer span.Finish()
+	ctx = opentracing.ContextWithSpan(ctx, span)
+
+	return urm.repo.ChangeStatus(ctx, id, status)
 }
 
 func createSpan(ctx context.Context, tracer opentracing.Tracer, opName string) opentracing.Span {
@@ -19,6 +19,11 @@ const (
 
 	atSeparator  = "@"
 	dotSeparator = "."
+
+	// EnabledStatusKey is the status of the user allowed to log in.
+	EnabledStatusKey = "enabled"
+	// DisabledStatusKey is the status of the user disabled by the admin.
+	DisabledStatusKey = "disabled"
 )
 
 var (
@@ -38,6 +43,7 @@ type User struct {
 	Email    string
 	Password string
 	Metadata Metadata
+	Status   string
 }
 
 // Validate returns an error if user representation is invalid.
@@ -63,11 +69,16 @@ type UserRepository interface {
 	// RetrieveByID retrieves user by its unique identifier ID.
 	RetrieveByID(ctx context.Context, id string) (User, error)
 
-	// RetrieveAll retrieves all users for given array of userIDs.
-	RetrieveAll(ctx context.Context, offset, limit uint64, userIDs []string, email string, m Metadata) (UserPage, error)
+	// RetrieveAll retrieves all users for given array of userIDs, or all
+	// the users if userIDs is nil. The users are filtered by the status
+	// unless it's empty.
+	RetrieveAll(ctx context.Context, offset, limit uint64, userIDs []string, email, status string, m Metadata) (UserPage, error)
 
 	// UpdatePassword updates password for user with given email
 	UpdatePassword(ctx context.Context, email, password string) error
+
+	// ChangeStatus changes the status of the user with the given ID.
+	ChangeStatus(ctx context.Context, id, status string) error
 }
 
 func isEmail(email string) bool {
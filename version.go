@@ -6,9 +6,21 @@ package mainflux
 import (
 	"encoding/json"
 	"net/http"
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// The build values are injected at build time using the linker flags, e.g.
+// -ldflags "-X github.com/mainflux/mainflux.commit=$(git rev-parse HEAD)".
+// The values not injected are reported as unknown.
+var (
+	version = "0.12.1"
+	commit  = ""
+	builtAt = ""
 )
 
-const version string = "0.12.1"
+const unknown = "unknown"
 
 // VersionInfo contains version endpoint response.
 type VersionInfo struct {
@@ -17,15 +29,63 @@ type VersionInfo struct {
 
 	// Version contains service current version value.
 	Version string `json:"version"`
+
+	// Commit contains the commit the service is built from.
+	Commit string `json:"commit"`
+
+	// GoVersion contains the Go version the service is built with.
+	GoVersion string `json:"go_version"`
+
+	// BuiltAt contains the time the service is built at.
+	BuiltAt string `json:"built_at"`
+}
+
+// Build returns the build information of the service.
+func Build(service string) VersionInfo {
+	return VersionInfo{
+		Service:   service,
+		Version:   orUnknown(version),
+		Commit:    orUnknown(commit),
+		GoVersion: runtime.Version(),
+		BuiltAt:   orUnknown(builtAt),
+	}
 }
 
 // Version exposes an HTTP handler for retrieving service version.
 func Version(service string) http.HandlerFunc {
 	return http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
-		res := VersionInfo{service, version}
+		res := Build(service)
 
 		data, _ := json.Marshal(res)
 
 		rw.Write(data)
 	})
 }
+
+// BuildInfo returns the mainflux_build_info gauge, always set to 1, labeled
+// by the build information of the service, so the versions of all the
+// services are scraped from the same metric.
+func BuildInfo(service string) prometheus.Collector {
+	info := Build(service)
+	g := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "mainflux",
+		Name:      "build_info",
+		Help:      "Build information of the service, always set to 1.",
+		ConstLabels: prometheus.Labels{
+			"service":    info.Service,
+			"version":    info.Version,
+			"commit":     info.Commit,
+			"go_version": info.GoVersion,
+			"built_at":   info.BuiltAt,
+		},
+	})
+	g.Set(1)
+	return g
+}
+
+func orUnknown(v string) string {
+	if v == "" {
+		return unknown
+	}
+	return v
+}
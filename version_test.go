@@ -0,0 +1,64 @@
+// Copyright (c) Mainflux
+// SPDX-License-Identifier: Apache-2.0
+
+package mainflux_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/mainflux/mainflux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const service = "test"
+
+func TestBuildInfo(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	err := reg.Register(mainflux.BuildInfo(service))
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+
+	mfs, err := reg.Gather()
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	require.Len(t, mfs, 1, fmt.Sprintf("expected 1 metric family got %d", len(mfs)))
+	assert.Equal(t, "mainflux_build_info", mfs[0].GetName(), fmt.Sprintf("expected metric mainflux_build_info got %s", mfs[0].GetName()))
+	require.Len(t, mfs[0].GetMetric(), 1, fmt.Sprintf("expected 1 metric got %d", len(mfs[0].GetMetric())))
+
+	m := mfs[0].GetMetric()[0]
+	assert.Equal(t, float64(1), m.GetGauge().GetValue(), fmt.Sprintf("expected value 1 got %f", m.GetGauge().GetValue()))
+	labels := map[string]string{}
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	info := mainflux.Build(service)
+	expected := map[string]string{
+		"service":    service,
+		"version":    info.Version,
+		"commit":     "unknown",
+		"go_version": runtime.Version(),
+		"built_at":   "unknown",
+	}
+	assert.Equal(t, expected, labels, fmt.Sprintf("expected labels %v got %v", expected, labels))
+}
+
+func TestVersion(t *testing.T) {
+	ts := httptest.NewServer(mainflux.Version(service))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	defer res.Body.Close()
+
+	var info mainflux.VersionInfo
+	err = json.NewDecoder(res.Body).Decode(&info)
+	require.Nil(t, err, fmt.Sprintf("unexpected error: %s", err))
+	expected := mainflux.Build(service)
+	assert.Equal(t, expected, info, fmt.Sprintf("expected version %v got %v", expected, info))
+	assert.NotEmpty(t, info.Version, "expected non-empty version")
+}